@@ -0,0 +1,128 @@
+// Command msg2git-cli captures notes into a msg2git repository from the terminal.
+//
+// It reads stdin or the given files and posts each one to the bot's capture API,
+// authenticating with the API key created by the /apikey bot command:
+//
+//	echo "buy milk" | msg2git-cli -file todo
+//	msg2git-cli -file journal/2025.md notes.txt
+//
+// The server URL and API key default to the MSG2GIT_SERVER and MSG2GIT_API_KEY
+// environment variables.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const capturePath = "/api/v1/capture"
+
+type captureRequest struct {
+	Content string `json:"content"`
+	File    string `json:"file,omitempty"`
+}
+
+type captureResponse struct {
+	File      string `json:"file"`
+	CommitSHA string `json:"commit_sha"`
+	CommitURL string `json:"commit_url"`
+	Error     string `json:"error"`
+}
+
+func main() {
+	server := flag.String("server", os.Getenv("MSG2GIT_SERVER"), "msg2git server URL (env MSG2GIT_SERVER)")
+	apiKey := flag.String("key", os.Getenv("MSG2GIT_API_KEY"), "API key from /apikey (env MSG2GIT_API_KEY)")
+	file := flag.String("file", "", "target file in the repository (default inbox.md)")
+	timeout := flag.Duration("timeout", 60*time.Second, "request timeout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: msg2git-cli [flags] [file ...]\n\nCaptures stdin, or each file given, as a note.\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *server == "" || *apiKey == "" {
+		fmt.Fprintln(os.Stderr, "msg2git-cli: server and API key are required (-server/-key or MSG2GIT_SERVER/MSG2GIT_API_KEY)")
+		os.Exit(2)
+	}
+
+	client := &http.Client{Timeout: *timeout}
+
+	if flag.NArg() == 0 {
+		content, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fatal(fmt.Errorf("failed to read stdin: %w", err))
+		}
+		if err := capture(client, *server, *apiKey, *file, string(content)); err != nil {
+			fatal(err)
+		}
+		return
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		content, err := os.ReadFile(path)
+		if err == nil {
+			err = capture(client, *server, *apiKey, *file, string(content))
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "msg2git-cli: %s: %v\n", path, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// capture posts content to the capture API and prints where it was committed
+func capture(client *http.Client, server, apiKey, file, content string) error {
+	if strings.TrimSpace(content) == "" {
+		return fmt.Errorf("nothing to capture: content is empty")
+	}
+
+	body, err := json.Marshal(captureRequest{Content: content, File: file})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(server, "/")+capturePath, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+	req.Header.Set("User-Agent", "msg2git-cli")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result captureResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("unexpected response (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("server returned status %d: %s", resp.StatusCode, result.Error)
+	}
+
+	if result.CommitURL != "" {
+		fmt.Printf("Saved to %s: %s\n", result.File, result.CommitURL)
+	} else {
+		fmt.Printf("Saved to %s\n", result.File)
+	}
+	return nil
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "msg2git-cli: %v\n", err)
+	os.Exit(1)
+}
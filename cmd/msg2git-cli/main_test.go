@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCapturePostsContent(t *testing.T) {
+	var received captureRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != capturePath {
+			t.Errorf("path = %q, want %q", r.URL.Path, capturePath)
+		}
+		if r.Header.Get("Authorization") != "Bearer m2g_test" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(captureResponse{File: "inbox.md", CommitSHA: "abc"})
+	}))
+	defer server.Close()
+
+	if err := capture(server.Client(), server.URL+"/", "m2g_test", "inbox", "hello"); err != nil {
+		t.Fatalf("capture() error = %v", err)
+	}
+	if received.Content != "hello" || received.File != "inbox" {
+		t.Errorf("unexpected request body: %+v", received)
+	}
+}
+
+func TestCaptureReportsServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(captureResponse{Error: "invalid API key"})
+	}))
+	defer server.Close()
+
+	err := capture(server.Client(), server.URL, "m2g_bad", "", "hello")
+	if err == nil {
+		t.Fatal("capture() expected error for 401 response")
+	}
+}
+
+func TestCaptureRejectsEmptyContent(t *testing.T) {
+	if err := capture(http.DefaultClient, "http://unused", "m2g_test", "", "  \n"); err == nil {
+		t.Fatal("capture() expected error for empty content")
+	}
+}
@@ -0,0 +1,336 @@
+// Command msg2gitctl runs maintenance tasks of a msg2git deployment.
+//
+// Database tasks connect to the database of the bot's configuration (config file, .env and
+// environment, like the bot itself); tasks on the bot's clones call the admin API on WEBHOOK_PORT,
+// enabled by ADMIN_API_TOKEN:
+//
+//	msg2gitctl users -limit 20
+//	msg2gitctl rotate-key -new-password "$NEW_TOKEN_PASSWORD"
+//	msg2gitctl evict -chat 123456789
+//	msg2gitctl requeue-dead-letters -since 72h
+//	msg2gitctl recompute-insights
+//	msg2gitctl migrate
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+const evictPath = "/admin/v1/evict"
+
+type evictRequest struct {
+	Repo   string `json:"repo,omitempty"`
+	ChatID int64  `json:"chat_id,omitempty"`
+}
+
+type evictResponse struct {
+	Repo    string `json:"repo"`
+	Evicted bool   `json:"evicted"`
+	Error   string `json:"error"`
+}
+
+// command is a subcommand, run with its arguments
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+var commands = []command{
+	{"users", "list users with their premium level and last commit", runUsers},
+	{"rotate-key", "re-encrypt stored tokens and secrets with a new TOKEN_PASSWORD", runRotateKey},
+	{"evict", "remove the bot's clone of a repository so it is cloned afresh", runEvict},
+	{"requeue-dead-letters", "report background failures to their users again", runRequeue},
+	{"recompute-insights", "rebuild commit counts and streaks from the commit log", runRecomputeInsights},
+	{"migrate", "create missing tables, columns and indexes", runMigrate},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run runs the subcommand named by args[0] and returns the exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		usage(stderr)
+		return 2
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		if err := cmd.run(args[1:], stdout); err != nil {
+			if err == flag.ErrHelp {
+				return 2
+			}
+			fmt.Fprintf(stderr, "msg2gitctl %s: %v\n", cmd.name, err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "msg2gitctl: unknown command %q\n\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: msg2gitctl <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-22s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, "\nRun msg2gitctl <command> -h for the flags of a command.\n")
+}
+
+// newFlagSet creates the flag set of a subcommand, printing errors and help to stderr
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: msg2gitctl %s\n\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// loadDB loads the bot's configuration and connects to its database
+func loadDB() (*database.DB, error) {
+	cfg, err := config.LoadUnchecked()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if !cfg.HasDatabaseConfig() {
+		return nil, fmt.Errorf("no database configured (POSTGRE_DSN)")
+	}
+	return database.NewDB(cfg.PostgreDSN, cfg.TokenPassword)
+}
+
+func runUsers(args []string, stdout io.Writer) error {
+	fs := newFlagSet("users", "users [-limit N] [-json]")
+	limit := fs.Int("limit", 50, "maximum number of users, newest first")
+	asJSON := fs.Bool("json", false, "print JSON instead of a table")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	users, err := db.ListUsers(*limit)
+	if err != nil {
+		return err
+	}
+	return printUsers(stdout, users, *asJSON)
+}
+
+// printUsers writes users as a table or as JSON
+func printUsers(w io.Writer, users []*database.UserSummary, asJSON bool) error {
+	if asJSON {
+		if users == nil {
+			users = []*database.UserSummary{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(users)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "CHAT ID\tUSERNAME\tPREMIUM\tREPOSITORY\tLAST COMMIT\tCREATED")
+	for _, user := range users {
+		lastCommit := "-"
+		if !user.LastCommitAt.IsZero() {
+			lastCommit = user.LastCommitAt.Format("2006-01-02")
+		}
+		repo := user.GitHubRepo
+		if repo == "" {
+			repo = "-"
+		}
+		fmt.Fprintf(table, "%d\t%s\t%d\t%s\t%s\t%s\n", user.ChatID, user.Username, user.PremiumLevel, repo, lastCommit, user.CreatedAt.Format("2006-01-02"))
+	}
+	return table.Flush()
+}
+
+func runRotateKey(args []string, stdout io.Writer) error {
+	fs := newFlagSet("rotate-key", "rotate-key -new-password PASSWORD\n\n"+
+		"Stop the bot first: it keeps using the old password until it restarts with TOKEN_PASSWORD\n"+
+		"set to the new one.")
+	newPassword := fs.String("new-password", os.Getenv("NEW_TOKEN_PASSWORD"), "password to encrypt with from now on (env NEW_TOKEN_PASSWORD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *newPassword == "" {
+		return fmt.Errorf("-new-password is required")
+	}
+
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := db.RotateEncryptionKey(*newPassword)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Re-encrypted %d values", result.Rotated)
+	if result.Skipped > 0 {
+		fmt.Fprintf(stdout, ", skipped %d not encrypted with the current TOKEN_PASSWORD", result.Skipped)
+	}
+	fmt.Fprintf(stdout, ".\nSet TOKEN_PASSWORD to the new password before starting the bot.\n")
+	return nil
+}
+
+func runEvict(args []string, stdout io.Writer) error {
+	fs := newFlagSet("evict", "evict [-server URL] [-token TOKEN] (-chat CHAT_ID | REPO_URL)")
+	server := fs.String("server", os.Getenv("MSG2GIT_SERVER"), "bot server URL (env MSG2GIT_SERVER, default localhost on WEBHOOK_PORT)")
+	token := fs.String("token", "", "admin API token (default ADMIN_API_TOKEN of the configuration)")
+	chatID := fs.Int64("chat", 0, "evict the repository configured by this chat")
+	timeout := fs.Duration("timeout", 60*time.Second, "request timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	req := evictRequest{ChatID: *chatID, Repo: fs.Arg(0)}
+	if (req.Repo == "") == (req.ChatID == 0) {
+		return fmt.Errorf("give either -chat or a repository URL")
+	}
+
+	if *server == "" || *token == "" {
+		cfg, err := config.LoadUnchecked()
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		if *server == "" {
+			port := cfg.WebhookPort
+			if port == "" {
+				port = "8080"
+			}
+			*server = "http://localhost:" + port
+		}
+		if *token == "" {
+			*token = cfg.AdminAPIToken
+		}
+	}
+	if *token == "" {
+		return fmt.Errorf("admin API token is required (-token or ADMIN_API_TOKEN)")
+	}
+
+	result, err := evict(&http.Client{Timeout: *timeout}, *server, *token, req)
+	if err != nil {
+		return err
+	}
+	if result.Evicted {
+		fmt.Fprintf(stdout, "Evicted the clone of %s\n", result.Repo)
+	} else {
+		fmt.Fprintf(stdout, "No clone of %s\n", result.Repo)
+	}
+	return nil
+}
+
+// evict asks the admin API of the bot at server to remove a clone
+func evict(client *http.Client, server, token string, evictReq evictRequest) (*evictResponse, error) {
+	body, err := json.Marshal(evictReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", strings.TrimSuffix(server, "/")+evictPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", "msg2gitctl")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result evictResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("unexpected response (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d: %s", resp.StatusCode, result.Error)
+	}
+	return &result, nil
+}
+
+func runRequeue(args []string, stdout io.Writer) error {
+	fs := newFlagSet("requeue-dead-letters", "requeue-dead-letters [-chat CHAT_ID] [-since DURATION]")
+	chatID := fs.Int64("chat", 0, "only requeue the failures of this chat")
+	since := fs.Duration("since", 24*time.Hour, "only requeue failures recorded this recently")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	count, err := db.RequeueBackgroundFailures(*chatID, time.Now().Add(-*since))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Requeued %d background failures for the next failure digests\n", count)
+	return nil
+}
+
+func runRecomputeInsights(args []string, stdout io.Writer) error {
+	fs := newFlagSet("recompute-insights", "recompute-insights [-chat CHAT_ID]")
+	chatID := fs.Int64("chat", 0, "only recompute this chat")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	count, err := db.RecomputeInsights(*chatID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "Recomputed the insights of %d users\n", count)
+	return nil
+}
+
+func runMigrate(args []string, stdout io.Writer) error {
+	fs := newFlagSet("migrate", "migrate")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := loadDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Database schema is up to date")
+	return nil
+}
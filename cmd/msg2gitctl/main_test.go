@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"frobnicate"}, &stdout, &stderr); code != 2 {
+		t.Errorf("run() = %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown command") || !strings.Contains(stderr.String(), "recompute-insights") {
+		t.Errorf("stderr = %q, want the error and the command list", stderr.String())
+	}
+}
+
+func TestRunEvictRequiresTarget(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"evict", "-token", "t", "-server", "http://localhost"}, &stdout, &stderr); code != 1 {
+		t.Errorf("run() = %d, want 1", code)
+	}
+	if !strings.Contains(stderr.String(), "either -chat or a repository URL") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestEvictPostsRequest(t *testing.T) {
+	var received evictRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != evictPath {
+			t.Errorf("path = %q, want %q", r.URL.Path, evictPath)
+		}
+		if r.Header.Get("Authorization") != "Bearer operator-token" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		json.NewEncoder(w).Encode(evictResponse{Repo: "https://github.com/alice/notes", Evicted: true})
+	}))
+	defer server.Close()
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"evict", "-server", server.URL + "/", "-token", "operator-token", "-chat", "42"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("run() = %d: %s", code, stderr.String())
+	}
+	if received.ChatID != 42 || received.Repo != "" {
+		t.Errorf("unexpected request body: %+v", received)
+	}
+	if !strings.Contains(stdout.String(), "Evicted the clone of https://github.com/alice/notes") {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+}
+
+func TestEvictReportsServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(evictResponse{Error: "invalid admin token"})
+	}))
+	defer server.Close()
+
+	_, err := evict(server.Client(), server.URL, "wrong", evictRequest{Repo: "https://github.com/alice/notes"})
+	if err == nil || !strings.Contains(err.Error(), "invalid admin token") {
+		t.Errorf("evict() error = %v, want the server error", err)
+	}
+}
+
+func TestPrintUsers(t *testing.T) {
+	created := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	users := []*database.UserSummary{
+		{ChatID: 42, Username: "alice", GitHubRepo: "https://github.com/alice/notes", PremiumLevel: 2, LastCommitAt: created.AddDate(0, 1, 0), CreatedAt: created},
+		{ChatID: 43, Username: "bob", CreatedAt: created},
+	}
+
+	var table bytes.Buffer
+	if err := printUsers(&table, users, false); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "2026-02-02") || !strings.Contains(lines[2], "-") {
+		t.Errorf("table = %q", table.String())
+	}
+
+	var out bytes.Buffer
+	if err := printUsers(&out, nil, true); err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(out.String()) != "[]" {
+		t.Errorf("JSON of no users = %q, want []", out.String())
+	}
+}
@@ -2,9 +2,13 @@ package ratelimit
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrRateLimitExceeded is returned by ConsumeLimit when the limit is reached
+var ErrRateLimitExceeded = errors.New("rate limit exceeded")
+
 // RateLimiterInterface defines the common interface for all rate limiters
 type RateLimiterInterface interface {
 	// CheckLimit checks if a user is within their rate limit
@@ -50,7 +54,7 @@ type RateLimit struct {
 
 // Config holds rate limiter configuration
 type Config struct {
-	// For Redis-based limiter (deprecated in this experiment)
+	// For RedisRateLimiter, which shares limits between instances
 	RedisAddr     string
 	RedisPassword string
 	RedisDB       int
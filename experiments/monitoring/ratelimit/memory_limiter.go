@@ -134,7 +134,7 @@ func (rl *MemoryRateLimiter) ConsumeLimit(ctx context.Context, userID int64, lim
 	}
 	
 	if !allowed {
-		return fmt.Errorf("rate limit exceeded for user %d, limit type %s", userID, limitType)
+		return fmt.Errorf("%w for user %d, limit type %s", ErrRateLimitExceeded, userID, limitType)
 	}
 	
 	// Add current request to the sliding window
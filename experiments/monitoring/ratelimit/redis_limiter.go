@@ -0,0 +1,295 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/msg2git/msg2git/experiments/monitoring/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRateLimiter provides the sliding windows of MemoryRateLimiter in Redis, so every instance
+// of the bot sharing a Redis counts requests against the same limits. Each window is a sorted set
+// of request timestamps, trimmed and counted in one script so concurrent instances can't both take
+// the last request of a window.
+type RedisRateLimiter struct {
+	client  redis.UniversalClient
+	metrics *metrics.MetricsCollector
+	limits  map[LimitType]RateLimit
+
+	// Premium multipliers
+	premiumMultipliers map[int]float64
+
+	// Makes members of requests recorded in the same microsecond unique
+	sequence atomic.Uint64
+}
+
+// consumeScript trims the window of KEYS[1] to requests after ARGV[2] and records a request at
+// ARGV[1] as member ARGV[4] if fewer than ARGV[3] remain. Recorded requests are also counted in
+// the per-minute load counter KEYS[2]. Returns 1 if the request was recorded, 0 otherwise.
+var consumeScript = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[2])
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[3]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], ARGV[1], ARGV[4])
+redis.call("PEXPIRE", KEYS[1], ARGV[5])
+redis.call("INCR", KEYS[2])
+redis.call("PEXPIRE", KEYS[2], 120000)
+return 1
+`)
+
+// NewRedisRateLimiter connects to the Redis of config and creates a rate limiter using it
+func NewRedisRateLimiter(config Config, metricsCollector *metrics.MetricsCollector) (*RedisRateLimiter, error) {
+	client := redis.NewClient(&redis.Options{
+		Addr:     config.RedisAddr,
+		Password: config.RedisPassword,
+		DB:       config.RedisDB,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+
+	return NewRedisRateLimiterWithClient(client, config, metricsCollector), nil
+}
+
+// NewRedisRateLimiterWithClient creates a rate limiter using an existing Redis client. A nil
+// metricsCollector records no metrics.
+func NewRedisRateLimiterWithClient(client redis.UniversalClient, config Config, metricsCollector *metrics.MetricsCollector) *RedisRateLimiter {
+	// Set default premium multipliers if not provided
+	if config.PremiumMultipliers == nil {
+		config.PremiumMultipliers = DefaultConfig().PremiumMultipliers
+	}
+
+	return &RedisRateLimiter{
+		client:  client,
+		metrics: metricsCollector,
+		limits: map[LimitType]RateLimit{
+			LimitTypeCommand:    config.CommandLimit,
+			LimitTypeGitHubREST: config.GitHubRESTLimit,
+			LimitTypeGitHubQL:   config.GitHubQLLimit,
+			LimitTypeGlobal:     config.GlobalLimit,
+		},
+		premiumMultipliers: config.PremiumMultipliers,
+	}
+}
+
+// windowKey is the sorted set of the requests of a user and limit type
+func windowKey(userID int64, limitType LimitType) string {
+	return fmt.Sprintf("rate_limit:%s:%d", limitType, userID)
+}
+
+// loadKey counts the requests of all users in the minute of t
+func loadKey(t time.Time) string {
+	return "rate_limit:load:" + strconv.FormatInt(t.Unix()/60, 10)
+}
+
+// adjustedLimit returns the limit of limitType for premiumLevel
+func (rl *RedisRateLimiter) adjustedLimit(limitType LimitType, premiumLevel int) (RateLimit, int, error) {
+	limit, exists := rl.limits[limitType]
+	if !exists {
+		return RateLimit{}, 0, fmt.Errorf("unknown limit type: %s", limitType)
+	}
+
+	// Apply premium multiplier
+	multiplier := rl.premiumMultipliers[premiumLevel]
+	if multiplier == 0 {
+		multiplier = 1.0 // Default to free tier
+	}
+
+	return limit, int(float64(limit.Requests) * multiplier), nil
+}
+
+// recordCheck records a rate limit check in the metrics, if the limiter has a collector
+func (rl *RedisRateLimiter) recordCheck(userID int64, limitType LimitType, allowed bool) {
+	if rl.metrics == nil {
+		return
+	}
+	rl.metrics.RecordRateLimitCheck(userID, string(limitType), allowed)
+	if !allowed {
+		rl.metrics.RecordRateLimitViolation(userID, string(limitType))
+	}
+}
+
+// CheckLimit checks if a user is within their rate limit
+func (rl *RedisRateLimiter) CheckLimit(ctx context.Context, userID int64, limitType LimitType, premiumLevel int) (bool, error) {
+	_, adjustedLimit, err := rl.adjustedLimit(limitType, premiumLevel)
+	if err != nil {
+		return false, err
+	}
+
+	currentUsage, err := rl.GetCurrentUsage(ctx, userID, limitType)
+	if err != nil {
+		return false, err
+	}
+
+	allowed := currentUsage < adjustedLimit
+
+	// Record metrics
+	rl.recordCheck(userID, limitType, allowed)
+
+	return allowed, nil
+}
+
+// ConsumeLimit consumes one request from the rate limit, checking and recording it atomically
+func (rl *RedisRateLimiter) ConsumeLimit(ctx context.Context, userID int64, limitType LimitType, premiumLevel int) error {
+	limit, adjustedLimit, err := rl.adjustedLimit(limitType, premiumLevel)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	member := fmt.Sprintf("%d-%d", now.UnixMicro(), rl.sequence.Add(1))
+	keys := []string{windowKey(userID, limitType), loadKey(now)}
+	recorded, err := consumeScript.Run(ctx, rl.client, keys,
+		now.UnixMicro(), now.Add(-limit.Window).UnixMicro(), adjustedLimit, member, (limit.Window + time.Minute).Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to consume rate limit: %w", err)
+	}
+
+	allowed := recorded == 1
+
+	// Record metrics
+	rl.recordCheck(userID, limitType, allowed)
+
+	if !allowed {
+		return fmt.Errorf("%w for user %d, limit type %s", ErrRateLimitExceeded, userID, limitType)
+	}
+
+	return nil
+}
+
+// GetCurrentUsage returns the current usage for a user and limit type
+func (rl *RedisRateLimiter) GetCurrentUsage(ctx context.Context, userID int64, limitType LimitType) (int, error) {
+	limit, exists := rl.limits[limitType]
+	if !exists {
+		return 0, fmt.Errorf("unknown limit type: %s", limitType)
+	}
+
+	windowStart := time.Now().Add(-limit.Window).UnixMicro()
+	count, err := rl.client.ZCount(ctx, windowKey(userID, limitType), "("+strconv.FormatInt(windowStart, 10), "+inf").Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get current usage: %w", err)
+	}
+
+	return int(count), nil
+}
+
+// GetRemainingRequests returns the number of remaining requests for a user
+func (rl *RedisRateLimiter) GetRemainingRequests(ctx context.Context, userID int64, limitType LimitType, premiumLevel int) (int, error) {
+	_, adjustedLimit, err := rl.adjustedLimit(limitType, premiumLevel)
+	if err != nil {
+		return 0, err
+	}
+
+	currentUsage, err := rl.GetCurrentUsage(ctx, userID, limitType)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := adjustedLimit - currentUsage
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return remaining, nil
+}
+
+// GetResetTime returns when the rate limit will reset for a user
+func (rl *RedisRateLimiter) GetResetTime(ctx context.Context, userID int64, limitType LimitType) (time.Time, error) {
+	limit, exists := rl.limits[limitType]
+	if !exists {
+		return time.Time{}, fmt.Errorf("unknown limit type: %s", limitType)
+	}
+
+	windowStart := time.Now().Add(-limit.Window).UnixMicro()
+	oldest, err := rl.client.ZRangeByScoreWithScores(ctx, windowKey(userID, limitType), &redis.ZRangeBy{
+		Min:   "(" + strconv.FormatInt(windowStart, 10),
+		Max:   "+inf",
+		Count: 1,
+	}).Result()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get reset time: %w", err)
+	}
+
+	if len(oldest) == 0 {
+		// No requests in window, reset time is now
+		return time.Now(), nil
+	}
+
+	// Reset time is when the oldest request expires
+	return time.UnixMicro(int64(oldest[0].Score)).Add(limit.Window), nil
+}
+
+// ResetUserLimits resets all rate limits for a user
+func (rl *RedisRateLimiter) ResetUserLimits(ctx context.Context, userID int64) error {
+	keys := make([]string, 0, len(rl.limits))
+	for limitType := range rl.limits {
+		keys = append(keys, windowKey(userID, limitType))
+	}
+
+	if err := rl.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to reset user limits: %w", err)
+	}
+
+	return nil
+}
+
+// GetGlobalSystemLoad returns the current global system load factor (0-1), from the requests of
+// all instances in the last minute, estimated from the current and previous minute counters
+func (rl *RedisRateLimiter) GetGlobalSystemLoad(ctx context.Context) (float64, error) {
+	now := time.Now()
+	counts, err := rl.client.MGet(ctx, loadKey(now.Add(-time.Minute)), loadKey(now)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get system load: %w", err)
+	}
+
+	previous, current := parseCount(counts[0]), parseCount(counts[1])
+	elapsed := float64(now.Unix()%60) / 60
+	totalRequests := previous*(1-elapsed) + current
+
+	// Calculate load factor based on the same maximum as MemoryRateLimiter
+	maxRequestsPerMinute := 10000.0
+	loadFactor := totalRequests / maxRequestsPerMinute
+
+	if loadFactor > 1.0 {
+		loadFactor = 1.0
+	}
+
+	// Update Prometheus metric
+	if rl.metrics != nil {
+		rl.metrics.UpdateSystemLoadFactor(loadFactor)
+	}
+
+	return loadFactor, nil
+}
+
+// parseCount reads a counter returned by MGET, nil for a missing key
+func parseCount(value interface{}) float64 {
+	s, ok := value.(string)
+	if !ok {
+		return 0
+	}
+	count, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// Close closes the Redis client
+func (rl *RedisRateLimiter) Close() error {
+	if err := rl.client.Close(); err != nil && !errors.Is(err, redis.ErrClosed) {
+		return err
+	}
+	return nil
+}
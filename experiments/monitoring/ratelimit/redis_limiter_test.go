@@ -0,0 +1,126 @@
+//go:build experiments
+
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTestRedisLimiters returns two limiters sharing a fake Redis, like two bot instances
+func createTestRedisLimiters(t *testing.T) (*RedisRateLimiter, *RedisRateLimiter, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	config := Config{
+		CommandLimit: RateLimit{Requests: 3, Window: time.Minute},
+		PremiumMultipliers: map[int]float64{
+			0: 1.0,
+			1: 2.0,
+		},
+	}
+
+	first := NewRedisRateLimiterWithClient(redis.NewClient(&redis.Options{Addr: server.Addr()}), config, getTestMetricsCollector())
+	second := NewRedisRateLimiterWithClient(redis.NewClient(&redis.Options{Addr: server.Addr()}), config, getTestMetricsCollector())
+	t.Cleanup(func() {
+		first.Close()
+		second.Close()
+	})
+	return first, second, server
+}
+
+func TestNewRedisRateLimiter(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	limiter, err := NewRedisRateLimiter(Config{RedisAddr: server.Addr()}, getTestMetricsCollector())
+	require.NoError(t, err)
+	defer limiter.Close()
+	assert.Equal(t, 10.0, limiter.premiumMultipliers[3])
+
+	addr := server.Addr()
+	server.Close()
+	_, err = NewRedisRateLimiter(Config{RedisAddr: addr}, getTestMetricsCollector())
+	assert.Error(t, err)
+}
+
+func TestRedisRateLimiter_SharedAcrossInstances(t *testing.T) {
+	first, second, _ := createTestRedisLimiters(t)
+	ctx := context.Background()
+	userID := int64(12345)
+
+	require.NoError(t, first.ConsumeLimit(ctx, userID, LimitTypeCommand, 0))
+	require.NoError(t, second.ConsumeLimit(ctx, userID, LimitTypeCommand, 0))
+	require.NoError(t, first.ConsumeLimit(ctx, userID, LimitTypeCommand, 0))
+
+	// Both instances see the limit reached
+	assert.Error(t, second.ConsumeLimit(ctx, userID, LimitTypeCommand, 0))
+	allowed, err := first.CheckLimit(ctx, userID, LimitTypeCommand, 0)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	usage, err := second.GetCurrentUsage(ctx, userID, LimitTypeCommand)
+	require.NoError(t, err)
+	assert.Equal(t, 3, usage, "rejected requests are not counted")
+
+	// Premium users get the multiplied limit
+	remaining, err := first.GetRemainingRequests(ctx, userID, LimitTypeCommand, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, remaining)
+	assert.NoError(t, first.ConsumeLimit(ctx, userID, LimitTypeCommand, 1))
+
+	// Other users have their own windows
+	assert.NoError(t, second.ConsumeLimit(ctx, userID+1, LimitTypeCommand, 0))
+}
+
+func TestRedisRateLimiter_ResetTime(t *testing.T) {
+	limiter, _, _ := createTestRedisLimiters(t)
+	ctx := context.Background()
+
+	before := time.Now()
+	resetTime, err := limiter.GetResetTime(ctx, 1, LimitTypeCommand)
+	require.NoError(t, err)
+	assert.False(t, resetTime.Before(before), "an empty window resets now")
+
+	require.NoError(t, limiter.ConsumeLimit(ctx, 1, LimitTypeCommand, 0))
+	resetTime, err = limiter.GetResetTime(ctx, 1, LimitTypeCommand)
+	require.NoError(t, err)
+	assert.WithinDuration(t, before.Add(time.Minute), resetTime, time.Second)
+}
+
+func TestRedisRateLimiter_ResetUserLimits(t *testing.T) {
+	limiter, _, server := createTestRedisLimiters(t)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.ConsumeLimit(ctx, 7, LimitTypeCommand, 0))
+	}
+	require.NoError(t, limiter.ResetUserLimits(ctx, 7))
+
+	assert.False(t, server.Exists(windowKey(7, LimitTypeCommand)))
+	assert.NoError(t, limiter.ConsumeLimit(ctx, 7, LimitTypeCommand, 0))
+}
+
+func TestRedisRateLimiter_GlobalSystemLoad(t *testing.T) {
+	limiter, _, _ := createTestRedisLimiters(t)
+	ctx := context.Background()
+
+	load, err := limiter.GetGlobalSystemLoad(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, load)
+
+	for userID := int64(1); userID <= 50; userID++ {
+		require.NoError(t, limiter.ConsumeLimit(ctx, userID, LimitTypeCommand, 0))
+	}
+	load, err = limiter.GetGlobalSystemLoad(ctx)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.005, load, 0.0051, "50 of 10000 requests per minute")
+	assert.Greater(t, load, 0.0)
+
+	assert.Error(t, limiter.ConsumeLimit(ctx, 1, "unknown", 0))
+}
+
+var _ RateLimiterInterface = (*RedisRateLimiter)(nil)
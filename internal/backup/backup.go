@@ -0,0 +1,387 @@
+// Package backup keeps encrypted database backups in S3-compatible object storage. A backup is a
+// database dump (see database.DB.Dump), gzip-compressed and sealed with AES-256-GCM under a key
+// derived from the backup password with scrypt, stored as backups/msg2git-YYYYMMDD-HHMMSS.bak.
+// The compressed dump is sealed in chunks, so backups are written and read as streams: every
+// chunk has its own nonce, a counter, and the last one is marked so a truncated backup is noticed.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/objectstore"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keyPrefix  = "backups/"
+	nameFormat = "msg2git-20060102-150405.bak"
+	saltSize   = 16
+	keySize    = 32
+
+	// chunkSize is the plaintext sealed per chunk
+	chunkSize = 64 * 1024
+
+	// noncePrefixSize is the random part of the chunk nonces, followed by a 4 byte counter and
+	// the last chunk flag
+	noncePrefixSize = 7
+)
+
+// scrypt cost parameters, about 50ms and 32MB per derivation
+var scryptN, scryptR, scryptP = 1 << 15, 8, 1
+
+// magic starts every sealed backup
+var magic = []byte("msg2git-backup-2\n")
+
+var nameRegex = regexp.MustCompile(`^msg2git-\d{8}-\d{6}\.bak$`)
+
+// deriveKey derives the AES-256 key of a backup from its salt and the password
+func deriveKey(password string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return key, nil
+}
+
+// Seal returns a writer that compresses and encrypts a dump into w. The backup is complete once
+// the writer is closed.
+func Seal(w io.Writer, password string) (io.WriteCloser, error) {
+	if password == "" {
+		return nil, fmt.Errorf("backup password is required")
+	}
+
+	header := make([]byte, len(magic)+saltSize+noncePrefixSize)
+	copy(header, magic)
+	if _, err := io.ReadFull(rand.Reader, header[len(magic):]); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	salt := header[len(magic) : len(magic)+saltSize]
+	key, err := deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	chunks := &chunkWriter{w: w, gcm: gcm, noncePrefix: header[len(magic)+saltSize:], buf: make([]byte, 0, chunkSize)}
+	return &sealWriter{Writer: gzip.NewWriter(chunks), chunks: chunks}, nil
+}
+
+// sealWriter compresses into a chunkWriter
+type sealWriter struct {
+	*gzip.Writer
+	chunks *chunkWriter
+}
+
+// Close flushes the compressed stream and seals the last chunk
+func (s *sealWriter) Close() error {
+	if err := s.Writer.Close(); err != nil {
+		return fmt.Errorf("failed to compress backup: %w", err)
+	}
+	return s.chunks.flush(true)
+}
+
+// chunkWriter seals its input in chunks of chunkSize. A full chunk is only sealed once more input
+// follows, so the last chunk is always sealed by flush(true) and carries the last chunk flag.
+type chunkWriter struct {
+	w           io.Writer
+	gcm         cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	buf         []byte
+	sealed      []byte
+}
+
+func (c *chunkWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		if len(c.buf) == chunkSize {
+			if err := c.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(c.buf[len(c.buf):chunkSize], p)
+		c.buf = c.buf[:len(c.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// flush seals and writes the buffered chunk
+func (c *chunkWriter) flush(last bool) error {
+	if c.counter == ^uint32(0) {
+		return fmt.Errorf("backup is too large")
+	}
+	c.sealed = c.gcm.Seal(c.sealed[:0], chunkNonce(c.noncePrefix, c.counter, last), c.buf, magic)
+	if _, err := c.w.Write(c.sealed); err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+	c.counter++
+	c.buf = c.buf[:0]
+	return nil
+}
+
+// chunkNonce returns the nonce of chunk number counter
+func chunkNonce(prefix []byte, counter uint32, last bool) []byte {
+	nonce := make([]byte, noncePrefixSize+5)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint32(nonce[noncePrefixSize:], counter)
+	if last {
+		nonce[noncePrefixSize+4] = 1
+	}
+	return nonce
+}
+
+// Open returns a reader that decrypts and decompresses the sealed backup read from r
+func Open(r io.Reader, password string) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(magic))
+	if err != nil || !bytes.Equal(header, magic) {
+		return nil, fmt.Errorf("not a msg2git backup")
+	}
+	br.Discard(len(magic))
+
+	params := make([]byte, saltSize+noncePrefixSize)
+	if _, err := io.ReadFull(br, params); err != nil {
+		return nil, fmt.Errorf("backup is truncated")
+	}
+	key, err := deriveKey(password, params[:saltSize])
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := &chunkReader{r: br, gcm: gcm, noncePrefix: params[saltSize:], sealed: make([]byte, chunkSize+gcm.Overhead())}
+	gr, err := gzip.NewReader(chunks)
+	if err != nil {
+		return nil, decompressError(err)
+	}
+	return &openReader{Reader: gr, gzip: gr}, nil
+}
+
+// openReader decompresses a chunkReader, reporting a wrong password or corruption as such
+type openReader struct {
+	io.Reader
+	gzip *gzip.Reader
+}
+
+func (o *openReader) Read(p []byte) (int, error) {
+	n, err := o.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		err = decompressError(err)
+	}
+	return n, err
+}
+
+func (o *openReader) Close() error {
+	return o.gzip.Close()
+}
+
+// errDecrypt is returned for chunks that don't authenticate
+var errDecrypt = errors.New("failed to decrypt backup, wrong password or corrupted file")
+
+// decompressError passes errDecrypt on and wraps other failures of the compressed stream
+func decompressError(err error) error {
+	if errors.Is(err, errDecrypt) {
+		return err
+	}
+	return fmt.Errorf("failed to decompress backup: %w", err)
+}
+
+// chunkReader opens the chunks written by a chunkWriter
+type chunkReader struct {
+	r           *bufio.Reader
+	gcm         cipher.AEAD
+	noncePrefix []byte
+	counter     uint32
+	sealed      []byte
+	plain       []byte
+	done        bool
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.plain) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, c.plain)
+	c.plain = c.plain[n:]
+	return n, nil
+}
+
+// next reads and opens the next chunk, the last one is the one the input ends with
+func (c *chunkReader) next() error {
+	n, err := io.ReadFull(c.r, c.sealed)
+	last := err == io.EOF || err == io.ErrUnexpectedEOF
+	if err != nil && !last {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	if !last {
+		if _, err := c.r.Peek(1); err == io.EOF {
+			last = true
+		}
+	}
+
+	plain, err := c.gcm.Open(c.plain[:0], chunkNonce(c.noncePrefix, c.counter, last), c.sealed[:n], magic)
+	if err != nil {
+		return errDecrypt
+	}
+	c.plain = plain
+	c.counter++
+	c.done = last
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// Backup describes a stored backup
+type Backup struct {
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// Store saves and loads backups in a bucket
+type Store struct {
+	client   *objectstore.Client
+	password string
+}
+
+// NewStore creates a store sealing backups with password
+func NewStore(client *objectstore.Client, password string) *Store {
+	return &Store{client: client, password: password}
+}
+
+// Save seals the dump written by dump and stores it as the backup taken at now. The sealed
+// backup is spooled to a temporary file, the dump is never held in memory.
+func (s *Store) Save(now time.Time, dump func(io.Writer) error) (Backup, error) {
+	spool, err := os.CreateTemp("", "msg2git-backup-*")
+	if err != nil {
+		return Backup{}, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	sealer, err := Seal(spool, s.password)
+	if err != nil {
+		return Backup{}, err
+	}
+	if err := dump(sealer); err != nil {
+		return Backup{}, err
+	}
+	if err := sealer.Close(); err != nil {
+		return Backup{}, err
+	}
+
+	size, err := spool.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return Backup{}, fmt.Errorf("failed to read backup file: %w", err)
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return Backup{}, fmt.Errorf("failed to read backup file: %w", err)
+	}
+
+	name := now.UTC().Format(nameFormat)
+	if err := s.client.PutStream(keyPrefix+name, "application/octet-stream", spool, size); err != nil {
+		return Backup{}, fmt.Errorf("failed to upload backup: %w", err)
+	}
+	return Backup{Name: name, Size: size, CreatedAt: now.UTC()}, nil
+}
+
+// List returns the stored backups, newest first
+func (s *Store) List() ([]Backup, error) {
+	objects, err := s.client.List(keyPrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []Backup
+	for _, object := range objects {
+		name := strings.TrimPrefix(object.Key, keyPrefix)
+		createdAt, err := time.Parse(nameFormat, name)
+		if err != nil || !nameRegex.MatchString(name) {
+			continue // Not a backup
+		}
+		backups = append(backups, Backup{Name: name, Size: object.Size, CreatedAt: createdAt})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// Load downloads and opens the backup called name and passes the dump to restore as it's read,
+// the dump is never held in memory
+func (s *Store) Load(name string, restore func(io.Reader) error) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid backup name: %s", name)
+	}
+
+	body, err := s.client.Get(keyPrefix + name)
+	if err != nil {
+		return fmt.Errorf("failed to download backup: %w", err)
+	}
+	defer body.Close()
+
+	opened, err := Open(body, s.password)
+	if err != nil {
+		return err
+	}
+	defer opened.Close()
+	return restore(opened)
+}
+
+// Prune deletes all but the newest keep backups, returning how many were deleted
+func (s *Store) Prune(keep int) (int, error) {
+	backups, err := s.List()
+	if err != nil {
+		return 0, err
+	}
+	if keep < 1 || len(backups) <= keep {
+		return 0, nil
+	}
+
+	deleted := 0
+	for _, old := range backups[keep:] {
+		if err := s.client.Delete(keyPrefix + old.Name); err != nil {
+			return deleted, fmt.Errorf("failed to delete backup %s: %w", old.Name, err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,198 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/objectstore"
+)
+
+func init() {
+	// Keep key derivation cheap in tests
+	scryptN = 1 << 10
+}
+
+// sealBytes seals dump in memory
+func sealBytes(password string, dump []byte) ([]byte, error) {
+	var sealed bytes.Buffer
+	w, err := Seal(&sealed, password)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(dump); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return sealed.Bytes(), nil
+}
+
+// openBytes opens a sealed backup in memory
+func openBytes(password string, sealed []byte) ([]byte, error) {
+	r, err := Open(bytes.NewReader(sealed), password)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+func TestSealAndOpen(t *testing.T) {
+	dump := []byte(`{"msg2git_backup":1}` + "\n" + strings.Repeat(`{"table":"users","row":{}}`+"\n", 100))
+
+	sealed, err := sealBytes("secret", dump)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if bytes.Contains(sealed, []byte("users")) {
+		t.Error("sealed backup contains plaintext")
+	}
+
+	opened, err := openBytes("secret", sealed)
+	if err != nil || !bytes.Equal(opened, dump) {
+		t.Fatalf("Open() = %q, %v, want the dump", opened, err)
+	}
+
+	if _, err := openBytes("wrong", sealed); err == nil {
+		t.Error("Open(wrong password) error = nil, want an error")
+	}
+	if _, err := openBytes("secret", sealed[:len(magic)+4]); err == nil {
+		t.Error("Open(truncated) error = nil, want an error")
+	}
+	if _, err := sealBytes("", dump); err == nil {
+		t.Error("Seal(no password) error = nil, want an error")
+	}
+}
+
+func TestSealAndOpenChunks(t *testing.T) {
+	// Random data doesn't compress, so it spans several chunks
+	dump := make([]byte, 3*chunkSize+100)
+	rand.Read(dump)
+
+	sealed, err := sealBytes("secret", dump)
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	opened, err := openBytes("secret", sealed)
+	if err != nil || !bytes.Equal(opened, dump) {
+		t.Fatalf("Open() = %d bytes, %v, want the dump", len(opened), err)
+	}
+
+	// Dropping the last chunk leaves a backup that looks complete but isn't
+	header := len(magic) + saltSize + noncePrefixSize
+	sealedChunk := chunkSize + 16
+	whole := header + (len(sealed)-header)/sealedChunk*sealedChunk
+	if _, err := openBytes("secret", sealed[:whole]); err == nil {
+		t.Error("Open(without the last chunk) error = nil, want an error")
+	}
+	tampered := append([]byte(nil), sealed...)
+	tampered[header+10] ^= 1
+	if _, err := openBytes("secret", tampered); err == nil {
+		t.Error("Open(tampered) error = nil, want an error")
+	}
+}
+
+// fakeBucket is a minimal in-memory S3-compatible server for bucket "bucket"
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeBucket) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/bucket":
+		type content struct {
+			Key  string
+			Size int64
+		}
+		var result struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []content
+		}
+		for k, data := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				result.Contents = append(result.Contents, content{Key: k, Size: int64(len(data))})
+			}
+		}
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == "PUT":
+		f.objects[key], _ = io.ReadAll(r.Body)
+	case r.Method == "GET":
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == "DELETE":
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestStore(t *testing.T) {
+	fake := &fakeBucket{objects: map[string][]byte{"backups/notes.txt": []byte("not a backup")}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := objectstore.New(server.URL, "bucket", "", "access", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := NewStore(client, "password")
+
+	start := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		dump := func(w io.Writer) error {
+			_, err := w.Write([]byte{byte('a' + i)})
+			return err
+		}
+		if _, err := store.Save(start.Add(time.Duration(i)*time.Hour), dump); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+
+	backups, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(backups) != 3 || backups[0].Name != "msg2git-20260102-050405.bak" {
+		t.Fatalf("List() = %+v, want three backups newest first", backups)
+	}
+
+	var dump []byte
+	err = store.Load(backups[0].Name, func(r io.Reader) error {
+		dump, err = io.ReadAll(r)
+		return err
+	})
+	if err != nil || string(dump) != "c" {
+		t.Errorf("Load() = %q, %v", dump, err)
+	}
+	if err := store.Load("../secrets", func(io.Reader) error { return nil }); err == nil {
+		t.Error("Load(invalid name) error = nil, want an error")
+	}
+
+	deleted, err := store.Prune(2)
+	if err != nil || deleted != 1 {
+		t.Fatalf("Prune() = %d, %v, want 1 deleted", deleted, err)
+	}
+	if backups, _ := store.List(); len(backups) != 2 || backups[1].Name != "msg2git-20260102-040405.bak" {
+		t.Errorf("List() after Prune = %+v", backups)
+	}
+	if _, ok := fake.objects["backups/notes.txt"]; !ok {
+		t.Error("Prune deleted an object that isn't a backup")
+	}
+}
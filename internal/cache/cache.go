@@ -4,6 +4,8 @@ import (
 	"context"
 	"sync"
 	"time"
+
+	"github.com/redis/go-redis/v9"
 )
 
 const (
@@ -26,7 +28,8 @@ func (i *Item) IsExpired() bool {
 	return time.Now().After(i.ExpiresAt)
 }
 
-// Cache represents an in-memory cache with size limits and expiration
+// Cache represents an in-memory cache with size limits and expiration, optionally shared with
+// other instances through Redis (see NewRedis)
 type Cache struct {
 	mu              sync.RWMutex
 	items           map[string]*Item
@@ -35,6 +38,10 @@ type Cache struct {
 	cleanupInterval time.Duration
 	stopCleanup     chan struct{}
 	cleanupStarted  bool
+
+	// Shared backend, nil for a cache local to this instance
+	redis  redis.UniversalClient
+	prefix string
 }
 
 // New creates a new cache with default settings
@@ -66,6 +73,19 @@ func (c *Cache) Set(key string, value interface{}) {
 
 // SetWithExpiry stores an item in the cache with custom expiry
 func (c *Cache) SetWithExpiry(key string, value interface{}, expiry time.Duration) {
+	if c.redis != nil {
+		if c.setShared(key, value, expiry) {
+			c.deleteLocal(key)
+			return
+		}
+		c.deleteShared(key)
+	}
+	
+	c.setLocal(key, value, expiry)
+}
+
+// setLocal stores an item in the memory of this instance
+func (c *Cache) setLocal(key string, value interface{}, expiry time.Duration) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
@@ -87,6 +107,9 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 	c.mu.RUnlock()
 	
 	if !exists {
+		if c.redis != nil {
+			return c.getShared(key)
+		}
 		return nil, false
 	}
 	
@@ -103,30 +126,65 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 
 // Delete removes an item from the cache
 func (c *Cache) Delete(key string) {
+	c.deleteLocal(key)
+	if c.redis != nil {
+		c.deleteShared(key)
+	}
+}
+
+// deleteLocal removes an item from the memory of this instance
+func (c *Cache) deleteLocal(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	delete(c.items, key)
 }
 
-// Clear removes all items from the cache
-func (c *Cache) Clear() {
+// SetIfAbsent stores an item unless the key holds one that hasn't expired, and reports whether it
+// stored it. With a shared backend exactly one instance stores a shareable value.
+func (c *Cache) SetIfAbsent(key string, value interface{}, expiry time.Duration) bool {
+	if c.redis != nil {
+		if stored, ok := c.setSharedIfAbsent(key, value, expiry); ok {
+			return stored
+		}
+	}
+	
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	
+	if item, exists := c.items[key]; exists && !item.IsExpired() {
+		return false
+	}
+	if len(c.items) >= c.maxSize {
+		c.evictLRU()
+	}
+	c.items[key] = &Item{
+		Value:     value,
+		ExpiresAt: time.Now().Add(expiry),
+	}
+	return true
+}
+
+// Clear removes all items from the cache, including shared ones
+func (c *Cache) Clear() {
+	c.mu.Lock()
 	c.items = make(map[string]*Item)
+	c.mu.Unlock()
+	
+	if c.redis != nil {
+		c.clearShared()
+	}
 }
 
-// Size returns the current number of items in the cache
+// Size returns the current number of items in the memory of this instance
 func (c *Cache) Size() int {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return len(c.items)
 }
 
-// Keys returns all keys in the cache (excluding expired items)
+// Keys returns all keys (excluding expired items), including those shared through Redis
 func (c *Cache) Keys() []string {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-	
 	var keys []string
 	now := time.Now()
 	
@@ -135,7 +193,12 @@ func (c *Cache) Keys() []string {
 			keys = append(keys, key)
 		}
 	}
+	c.mu.RUnlock()
 	
+	if c.redis != nil {
+		// A key is either local or shared, SetWithExpiry removes the other copy
+		keys = append(keys, c.keysShared()...)
+	}
 	return keys
 }
 
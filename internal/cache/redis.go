@@ -0,0 +1,196 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// Shared cache: with a Redis client, values the instances of the bot can exchange are stored in
+// Redis instead of memory, so whichever instance handles the next update of a chat finds what
+// another one cached. Values are gob encoded, which shares strings, numbers, bools and types
+// passed to Register. Other values, such as providers holding a clone or connections, stay in the
+// memory of the instance that cached them. A failing Redis degrades to the local cache.
+
+// redisTimeout bounds every Redis round trip of the cache
+const redisTimeout = 2 * time.Second
+
+// registered holds the types passed to Register
+var registered sync.Map // reflect.Type -> struct{}
+
+// Register makes values of the type of value shared through Redis. Only its exported fields are
+// shared, so register types made of exported fields only, and store them again after changing them.
+func Register(value interface{}) {
+	gob.Register(value)
+	registered.Store(reflect.TypeOf(value), struct{}{})
+}
+
+// shareable reports whether value can be stored in Redis
+func shareable(value interface{}) bool {
+	switch value.(type) {
+	case string, bool, int, int64, float64, []string:
+		return true
+	}
+	_, ok := registered.Load(reflect.TypeOf(value))
+	return ok
+}
+
+// NewRedis creates a cache shared through client under prefix, with the local cache of the
+// instance for values that can't be shared
+func NewRedis(client redis.UniversalClient, prefix string, maxSize int, defaultExpiry, cleanupInterval time.Duration) *Cache {
+	c := NewWithConfig(maxSize, defaultExpiry, cleanupInterval)
+	c.redis = client
+	c.prefix = prefix
+	return c
+}
+
+func encodeValue(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// setShared stores value in Redis, reporting false if it isn't shareable or Redis failed
+func (c *Cache) setShared(key string, value interface{}, expiry time.Duration) bool {
+	if !shareable(value) {
+		return false
+	}
+	data, err := encodeValue(value)
+	if err != nil {
+		c.logFailure("encode", key, err)
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := c.redis.Set(ctx, c.prefix+key, data, expiry).Err(); err != nil {
+		c.logFailure("set", key, err)
+		return false
+	}
+	return true
+}
+
+// setSharedIfAbsent stores value in Redis unless key exists. ok is false if the value isn't
+// shareable or Redis failed, leaving the decision to the local cache.
+func (c *Cache) setSharedIfAbsent(key string, value interface{}, expiry time.Duration) (stored, ok bool) {
+	if !shareable(value) {
+		return false, false
+	}
+	data, err := encodeValue(value)
+	if err != nil {
+		c.logFailure("encode", key, err)
+		return false, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	stored, err = c.redis.SetNX(ctx, c.prefix+key, data, expiry).Result()
+	if err != nil {
+		c.logFailure("set", key, err)
+		return false, false
+	}
+	return stored, true
+}
+
+// getShared reads key from Redis
+func (c *Cache) getShared(key string) (interface{}, bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	data, err := c.redis.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false
+	}
+	if err != nil {
+		c.logFailure("get", key, err)
+		return nil, false
+	}
+
+	value, err := decodeValue(data)
+	if err != nil {
+		c.logFailure("decode", key, err)
+		return nil, false
+	}
+	return value, true
+}
+
+// deleteShared removes key from Redis
+func (c *Cache) deleteShared(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisTimeout)
+	defer cancel()
+	if err := c.redis.Del(ctx, c.prefix+key).Err(); err != nil {
+		c.logFailure("delete", key, err)
+	}
+}
+
+// scanShared returns the Redis keys under the prefix
+func (c *Cache) scanShared(ctx context.Context) ([]string, error) {
+	iter := c.redis.Scan(ctx, 0, c.prefix+"*", 100).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		c.logFailure("scan", c.prefix+"*", err)
+		return nil, err
+	}
+	return keys, nil
+}
+
+// keysShared returns the keys of the values stored in Redis
+func (c *Cache) keysShared() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*redisTimeout)
+	defer cancel()
+
+	keys, err := c.scanShared(ctx)
+	if err != nil {
+		return nil
+	}
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, c.prefix)
+	}
+	return keys
+}
+
+// clearShared removes every key under the prefix from Redis
+func (c *Cache) clearShared() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*redisTimeout)
+	defer cancel()
+
+	keys, err := c.scanShared(ctx)
+	if err != nil {
+		return
+	}
+	for start := 0; start < len(keys); start += 100 {
+		end := min(start+100, len(keys))
+		if err := c.redis.Del(ctx, keys[start:end]...).Err(); err != nil {
+			c.logFailure("delete", c.prefix+"*", err)
+			return
+		}
+	}
+}
+
+func (c *Cache) logFailure(operation, key string, err error) {
+	logger.Warn("Shared cache operation failed", map[string]interface{}{
+		"operation": operation,
+		"key":       key,
+		"error":     err.Error(),
+	})
+}
@@ -0,0 +1,167 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type sharedState struct {
+	Query   string
+	Matches []string
+}
+
+func init() {
+	Register(&sharedState{})
+}
+
+// newSharedCaches returns two caches sharing a fake Redis, like two instances of the bot
+func newSharedCaches(t *testing.T) (*Cache, *Cache, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	first := NewRedis(client, "test:", 100, time.Minute, time.Minute)
+	second := NewRedis(client, "test:", 100, time.Minute, time.Minute)
+	t.Cleanup(first.Close)
+	t.Cleanup(second.Close)
+	return first, second, server
+}
+
+func TestRedisCache_SharedValues(t *testing.T) {
+	first, second, server := newSharedCaches(t)
+
+	first.SetWithExpiry("folder", "notes/imported", time.Hour)
+	first.Set("state", &sharedState{Query: "todo", Matches: []string{"inbox.md"}})
+
+	if value, ok := second.Get("folder"); !ok || value != "notes/imported" {
+		t.Errorf("Get() = %v, %v, want the string cached by the other instance", value, ok)
+	}
+	value, ok := second.Get("state")
+	state, isState := value.(*sharedState)
+	if !ok || !isState || state.Query != "todo" || len(state.Matches) != 1 {
+		t.Errorf("Get() = %#v, %v, want the registered type cached by the other instance", value, ok)
+	}
+	if ttl := server.TTL("test:folder"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want the expiry of the item", ttl)
+	}
+
+	second.Delete("folder")
+	if _, ok := first.Get("folder"); ok {
+		t.Error("Expected a delete to reach every instance")
+	}
+
+	server.FastForward(2 * time.Minute)
+	if _, ok := first.Get("state"); ok {
+		t.Error("Expected the shared item to expire")
+	}
+}
+
+func TestRedisCache_LocalValues(t *testing.T) {
+	first, second, server := newSharedCaches(t)
+
+	// Unregistered types stay in the memory of the instance
+	type provider struct{ name string }
+	local := &provider{name: "clone"}
+	first.Set("provider", local)
+
+	if value, ok := first.Get("provider"); !ok || value != local {
+		t.Errorf("Get() = %v, %v, want the same value from local memory", value, ok)
+	}
+	if _, ok := second.Get("provider"); ok {
+		t.Error("Expected an unshareable value to stay local")
+	}
+	if server.Exists("test:provider") {
+		t.Error("Expected an unshareable value not to be stored in Redis")
+	}
+
+	// Replacing it with a shareable value drops the local one
+	first.Set("provider", "name")
+	if value, ok := second.Get("provider"); !ok || value != "name" {
+		t.Errorf("Get() = %v, %v, want the shared value", value, ok)
+	}
+	if first.Size() != 0 {
+		t.Errorf("Size() = %d, want the local value replaced", first.Size())
+	}
+}
+
+func TestRedisCache_SetIfAbsent(t *testing.T) {
+	first, second, server := newSharedCaches(t)
+
+	if !first.SetIfAbsent("callback_1", true, 30*time.Second) {
+		t.Fatal("Expected the first instance to store the key")
+	}
+	if second.SetIfAbsent("callback_1", true, 30*time.Second) {
+		t.Error("Expected the other instance not to store a key that exists")
+	}
+
+	server.FastForward(time.Minute)
+	if !second.SetIfAbsent("callback_1", true, 30*time.Second) {
+		t.Error("Expected the key to be stored again after it expired")
+	}
+
+	// Falls back to memory when Redis fails
+	server.Close()
+	if !first.SetIfAbsent("callback_2", true, 30*time.Second) || first.SetIfAbsent("callback_2", true, 30*time.Second) {
+		t.Error("Expected SetIfAbsent to work locally without Redis")
+	}
+}
+
+func TestRedisCache_Keys(t *testing.T) {
+	first, second, server := newSharedCaches(t)
+	server.Set("other:key", "kept")
+
+	first.Set("a", "1")
+	second.Set("b", "2")
+	first.Set("local", make(chan int)) // not shareable
+
+	keys := map[string]bool{}
+	for _, key := range first.Keys() {
+		keys[key] = true
+	}
+	if len(keys) != 3 || !keys["a"] || !keys["b"] || !keys["local"] {
+		t.Errorf("Keys() = %v, want a, b and local", first.Keys())
+	}
+}
+
+func TestRedisCache_Clear(t *testing.T) {
+	first, second, server := newSharedCaches(t)
+	server.Set("other:key", "kept")
+
+	first.Set("a", "1")
+	second.Set("b", "2")
+	first.Clear()
+
+	if _, ok := second.Get("a"); ok {
+		t.Error("Expected Clear to remove shared items")
+	}
+	if server.Exists("test:b") {
+		t.Error("Expected Clear to remove items of every instance")
+	}
+	if !server.Exists("other:key") {
+		t.Error("Expected Clear to keep keys outside the prefix")
+	}
+}
+
+func TestCache_SetIfAbsent(t *testing.T) {
+	c := New()
+	defer c.Close()
+
+	if !c.SetIfAbsent("key", 1, time.Minute) {
+		t.Fatal("Expected the key to be stored")
+	}
+	if c.SetIfAbsent("key", 2, time.Minute) {
+		t.Error("Expected an existing key to be kept")
+	}
+	if value, _ := c.Get("key"); value != 1 {
+		t.Errorf("Get() = %v, want the first value", value)
+	}
+
+	c.SetWithExpiry("expired", 1, -time.Second)
+	if !c.SetIfAbsent("expired", 2, time.Minute) {
+		t.Error("Expected an expired key to be replaced")
+	}
+}
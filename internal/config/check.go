@@ -0,0 +1,344 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Config diagnostics: a validation pass over the loaded settings reporting broken values,
+// half-configured features and conflicting flags, optionally testing the endpoints too
+
+// Severity tells how serious a diagnostic is
+type Severity int
+
+const (
+	SeverityOK Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "ERROR"
+	case SeverityWarning:
+		return "WARN"
+	default:
+		return "OK"
+	}
+}
+
+// Diagnostic is a single finding about a setting
+type Diagnostic struct {
+	Severity Severity
+	Setting  string // Environment variable the finding is about
+	Message  string
+	Hint     string // How to fix it, empty for OK findings
+}
+
+// Report is the result of a config check, in the order the checks ran
+type Report struct {
+	Diagnostics []Diagnostic
+}
+
+func (r *Report) add(severity Severity, setting, message, hint string) {
+	r.Diagnostics = append(r.Diagnostics, Diagnostic{Severity: severity, Setting: setting, Message: message, Hint: hint})
+}
+
+// HasErrors reports whether the config is too broken to start with
+func (r *Report) HasErrors() bool {
+	for _, d := range r.Diagnostics {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter returns the diagnostics of the given severity
+func (r *Report) Filter(severity Severity) []Diagnostic {
+	var filtered []Diagnostic
+	for _, d := range r.Diagnostics {
+		if d.Severity == severity {
+			filtered = append(filtered, d)
+		}
+	}
+	return filtered
+}
+
+// Write prints the report, one line per finding with its hint below, followed by a summary
+func (r *Report) Write(w io.Writer) {
+	for _, d := range r.Diagnostics {
+		fmt.Fprintf(w, "%-5s  %s: %s\n", d.Severity, d.Setting, d.Message)
+		if d.Hint != "" {
+			fmt.Fprintf(w, "       → %s\n", d.Hint)
+		}
+	}
+	fmt.Fprintf(w, "\n%d error(s), %d warning(s)\n", len(r.Filter(SeverityError)), len(r.Filter(SeverityWarning)))
+}
+
+// InvalidConfigError is returned by Load when the config has errors, Report tells which
+type InvalidConfigError struct {
+	Report *Report
+}
+
+func (e *InvalidConfigError) Error() string {
+	var problems []string
+	for _, d := range e.Report.Filter(SeverityError) {
+		problems = append(problems, d.Setting+": "+d.Message)
+	}
+	return "invalid configuration: " + strings.Join(problems, "; ")
+}
+
+// CheckOptions selects the checks beyond the static ones
+type CheckOptions struct {
+	Connectivity bool                   // Test the Telegram, GitHub and LLM endpoints and the database
+	HTTPClient   *http.Client           // Client for endpoint tests, a 10s timeout client if nil
+	PingDatabase func(dsn string) error // Tests the database connection, the database is skipped if nil
+}
+
+var (
+	telegramTokenRegex = regexp.MustCompile(`^\d+:[A-Za-z0-9_-]{30,}$`)
+	commitAuthorRegex  = regexp.MustCompile(`^[^<>]+ <[^<>@\s]+@[^<>\s]+>$`)
+)
+
+// Check validates the config and returns the report, see CheckOptions for the optional checks
+func (c *Config) Check(opts CheckOptions) *Report {
+	report := &Report{}
+
+	for _, setting := range c.requiredSettings() {
+		if setting.value == "" {
+			report.add(SeverityError, setting.key, "not set", "set it in the environment, .env or the config file")
+		}
+	}
+
+	if c.TelegramBotToken != "" && !telegramTokenRegex.MatchString(c.TelegramBotToken) {
+		report.add(SeverityError, "TELEGRAM_BOT_TOKEN", "doesn't look like a bot token", `copy the token from @BotFather, it looks like "123456:ABC-DEF..."`)
+	}
+	if c.CommitAuthor != "" && !commitAuthorRegex.MatchString(c.CommitAuthor) {
+		report.add(SeverityWarning, "COMMIT_AUTHOR", fmt.Sprintf("%q is not in the \"Name <email>\" form", c.CommitAuthor), `use e.g. "Jane Doe <jane@example.com>" so commits are attributed`)
+	}
+	if _, err := logrus.ParseLevel(c.LogLevel); err != nil {
+		report.add(SeverityError, "LOG_LEVEL", fmt.Sprintf("unknown level %q", c.LogLevel), "use debug, info, warn or error")
+	}
+	if c.ContentRetention != "" && c.ContentRetention != RetentionFull && c.ContentRetention != RetentionNone {
+		report.add(SeverityError, "CONTENT_RETENTION", fmt.Sprintf("unknown policy %q", c.ContentRetention), "use full or none")
+	}
+
+	checkURL(report, "BASE_URL", c.BaseURL)
+	checkURL(report, "GITHUB_API_URL", c.GitHubAPIURL)
+	checkURL(report, "GITHUB_UPLOADS_URL", c.GitHubUploadsURL)
+	checkURL(report, "GITHUB_OAUTH_REDIRECT_URI", c.GitHubOAuthRedirectURI)
+	checkURL(report, "LLM_ENDPOINT", c.LLMEndpoint)
+	checkURL(report, "WORKSPACE_S3_ENDPOINT", c.WorkspaceS3Endpoint)
+	checkURL(report, "BACKUP_S3_ENDPOINT", c.BackupS3Endpoint)
+	checkURL(report, "MODERATION_ENDPOINT", c.ModerationEndpoint)
+	checkURL(report, "WEBHOOK_URL", c.WebhookURL)
+	if strings.HasPrefix(c.WebhookURL, "http://") {
+		report.add(SeverityError, "WEBHOOK_URL", "Telegram only delivers updates to https URLs", "use the https URL of your load balancer or set WEBHOOK_CERT_FILE and WEBHOOK_KEY_FILE")
+	}
+	if port, err := strconv.Atoi(c.WebhookPort); c.WebhookPort != "" && (err != nil || port < 1 || port > 65535) {
+		report.add(SeverityError, "WEBHOOK_PORT", fmt.Sprintf("%q is not a port number", c.WebhookPort), "use e.g. 8080")
+	}
+	if (c.WebhookCertFile == "") != (c.WebhookKeyFile == "") {
+		report.add(SeverityError, "WEBHOOK_CERT_FILE", "WEBHOOK_CERT_FILE and WEBHOOK_KEY_FILE must be set together", "set both to serve HTTPS directly, or neither behind a TLS-terminating load balancer")
+	}
+	if port, err := strconv.Atoi(c.MetricsPort); c.MetricsPort != "" && (err != nil || port < 1 || port > 65535) {
+		report.add(SeverityError, "METRICS_PORT", fmt.Sprintf("%q is not a port number", c.MetricsPort), "use e.g. 9090")
+	} else if c.MetricsPort != "" && c.MetricsPort == c.WebhookPort {
+		report.add(SeverityError, "METRICS_PORT", "METRICS_PORT and WEBHOOK_PORT must differ", "use e.g. 9090 for metrics")
+	}
+	if c.AdminAPIToken != "" && len(c.AdminAPIToken) < 32 {
+		report.add(SeverityWarning, "ADMIN_API_TOKEN", "shorter than 32 characters, the admin API can evict any clone", "generate one with e.g. openssl rand -hex 32")
+	}
+	if parsed, err := url.Parse(c.RedisURL); c.RedisURL != "" && (err != nil || parsed.Host == "" || (parsed.Scheme != "redis" && parsed.Scheme != "rediss")) {
+		report.add(SeverityError, "REDIS_URL", fmt.Sprintf("%q is not a redis:// URL", c.RedisURL), `use e.g. "redis://:password@localhost:6379/0", or rediss:// for TLS`)
+	}
+	if c.TelegramAPIEndpoint != "" && strings.Count(c.TelegramAPIEndpoint, "%s") != 2 {
+		report.add(SeverityError, "TELEGRAM_API_ENDPOINT", "must contain two %s placeholders, for the token and the method", `use e.g. "http://localhost:8081/bot%s/%s"`)
+	}
+
+	checkGroup(report, "LLM", "LLM features are disabled", map[string]string{
+		"LLM_PROVIDER": c.LLMProvider,
+		"LLM_ENDPOINT": c.LLMEndpoint,
+		"LLM_TOKEN":    c.LLMToken,
+		"LLM_MODEL":    c.LLMModel,
+	})
+	checkGroup(report, "GitHub OAuth", "users can only authenticate with personal access tokens", map[string]string{
+		"GITHUB_OAUTH_CLIENT_ID":     c.GitHubOAuthClientID,
+		"GITHUB_OAUTH_CLIENT_SECRET": c.GitHubOAuthClientSecret,
+		"GITHUB_OAUTH_REDIRECT_URI":  c.GitHubOAuthRedirectURI,
+	})
+	checkGroup(report, "Workspace storage", "repositories are only kept on local disk", map[string]string{
+		"WORKSPACE_S3_ENDPOINT":   c.WorkspaceS3Endpoint,
+		"WORKSPACE_S3_BUCKET":     c.WorkspaceS3Bucket,
+		"WORKSPACE_S3_ACCESS_KEY": c.WorkspaceS3AccessKey,
+		"WORKSPACE_S3_SECRET_KEY": c.WorkspaceS3SecretKey,
+	})
+	checkGroup(report, "Database backups", "the database is not backed up", map[string]string{
+		"BACKUP_S3_ENDPOINT":   c.BackupS3Endpoint,
+		"BACKUP_S3_BUCKET":     c.BackupS3Bucket,
+		"BACKUP_S3_ACCESS_KEY": c.BackupS3AccessKey,
+		"BACKUP_S3_SECRET_KEY": c.BackupS3SecretKey,
+		"BACKUP_PASSWORD":      c.BackupPassword,
+	})
+
+	// Conflicting or ineffective combinations
+	if c.HasDatabaseConfig() && c.TokenPassword == "" {
+		report.add(SeverityWarning, "TOKEN_PASSWORD", "not set, user tokens are stored unencrypted", "set a long random password before users add tokens")
+	}
+	if !c.HasDatabaseConfig() && c.TokenPassword != "" {
+		report.add(SeverityWarning, "TOKEN_PASSWORD", "set without POSTGRE_DSN, it has no effect", "configure the database or remove TOKEN_PASSWORD")
+	}
+	if c.HasGitHubOAuthConfig() && !c.HasDatabaseConfig() {
+		report.add(SeverityWarning, "GITHUB_OAUTH_CLIENT_ID", "OAuth needs a database to store user tokens", "set POSTGRE_DSN")
+	}
+	if c.GitHubUploadsURL != "" && c.GitHubAPIURL == "" {
+		report.add(SeverityWarning, "GITHUB_UPLOADS_URL", "set without GITHUB_API_URL, API calls still go to github.com", "set GITHUB_API_URL for GitHub Enterprise")
+	}
+	if c.ZeroRetention() && c.HasWorkspaceStoreConfig() {
+		report.add(SeverityWarning, "WORKSPACE_S3_ENDPOINT", "workspace storage is unused with CONTENT_RETENTION=none, repositories are never cloned", "remove the WORKSPACE_S3_* settings")
+	}
+	if c.ZeroRetention() && c.CloneSubmodules {
+		report.add(SeverityWarning, "CLONE_SUBMODULES", "has no effect with CONTENT_RETENTION=none, repositories are never cloned", "remove CLONE_SUBMODULES")
+	}
+	if c.ZeroRetention() && (len(c.ShallowCloneLevels) > 0 || len(c.SparseCheckoutLevels) > 0) {
+		report.add(SeverityWarning, "SHALLOW_CLONE_LEVELS", "shallow and sparse clones have no effect with CONTENT_RETENTION=none, repositories are never cloned", "remove SHALLOW_CLONE_LEVELS and SPARSE_CHECKOUT_LEVELS")
+	}
+	if c.ZeroRetention() && c.WarmFetchInterval > 0 {
+		report.add(SeverityWarning, "WARM_FETCH_INTERVAL", "has no effect with CONTENT_RETENTION=none, repositories are never cloned", "remove WARM_FETCH_INTERVAL")
+	}
+	if c.HasModerationConfig() && !c.HasLLMConfig() {
+		report.add(SeverityWarning, "MODERATION_KEYWORDS", "moderation is set without a shared LLM, it has no effect", "configure the LLM_* settings or remove the moderation settings")
+	}
+	if c.ModerationEndpoint != "" && c.ModerationToken == "" {
+		report.add(SeverityWarning, "MODERATION_TOKEN", "not set, moderation requests are sent without authorization", "set MODERATION_TOKEN to the moderation API key")
+	}
+	if c.SlowNotifyAdmins && len(c.AdminChatIDs) == 0 {
+		report.add(SeverityWarning, "SLOW_NOTIFY_ADMINS", "set without ADMIN_CHAT_IDS, slow operations are only logged", "set ADMIN_CHAT_IDS to the chats that should be notified")
+	}
+	for _, id := range c.BlockedChatIDs {
+		for _, allowed := range c.AllowedChatIDs {
+			if id == allowed {
+				report.add(SeverityWarning, "BLOCKED_CHAT_IDS", fmt.Sprintf("chat %d is both allowed and blocked, it is blocked", id), "remove it from one of ALLOWED_CHAT_IDS and BLOCKED_CHAT_IDS")
+			}
+		}
+	}
+	if c.HasBackupConfig() && !c.HasDatabaseConfig() {
+		report.add(SeverityWarning, "BACKUP_S3_ENDPOINT", "backups are configured without a database, there is nothing to back up", "set POSTGRE_DSN or remove the BACKUP_* settings")
+	}
+	if c.Sandbox {
+		report.add(SeverityWarning, "SANDBOX", "enabled, GitHub writes are only simulated", "unset SANDBOX before serving real users")
+	}
+	if !c.PaymentsDisabled && (c.PremiumDefaultLevel > 0 || len(c.PremiumOverrides) > 0) {
+		report.add(SeverityWarning, "PREMIUM_DEFAULT_LEVEL", "premium levels are granted by config while payments are enabled, users may pay for levels they already have", "set PAYMENTS_DISABLED=true for self-hosted premium")
+	}
+
+	if opts.Connectivity {
+		c.checkConnectivity(report, opts)
+	}
+
+	return report
+}
+
+// checkURL reports a set value that isn't an absolute http(s) URL
+func checkURL(report *Report, setting, value string) {
+	if value == "" {
+		return
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		report.add(SeverityError, setting, fmt.Sprintf("%q is not an absolute http(s) URL", value), "include the scheme, e.g. https://example.com")
+	}
+}
+
+// checkGroup reports settings that only work together when some but not all of them are set
+func checkGroup(report *Report, feature, consequence string, settings map[string]string) {
+	var set, missing []string
+	for key, value := range settings {
+		if value == "" {
+			missing = append(missing, key)
+		} else {
+			set = append(set, key)
+		}
+	}
+	if len(set) == 0 || len(missing) == 0 {
+		return
+	}
+	sort.Strings(missing)
+	report.add(SeverityWarning, strings.Join(missing, ", "), fmt.Sprintf("%s is only partly configured, %s", feature, consequence), "set the missing settings or remove the others")
+}
+
+func (c *Config) checkConnectivity(report *Report, opts CheckOptions) {
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	if telegramTokenRegex.MatchString(c.TelegramBotToken) {
+		endpoint := c.TelegramAPIEndpoint
+		if endpoint == "" {
+			endpoint = "https://api.telegram.org/bot%s/%s"
+		}
+		status, err := probe(client, fmt.Sprintf(endpoint, c.TelegramBotToken, "getMe"))
+		switch {
+		case err != nil:
+			report.add(SeverityError, "TELEGRAM_API_ENDPOINT", "Telegram is unreachable: "+err.Error(), "check the network and TELEGRAM_API_ENDPOINT")
+		case status == http.StatusUnauthorized || status == http.StatusNotFound:
+			report.add(SeverityError, "TELEGRAM_BOT_TOKEN", "rejected by Telegram", "the token was revoked or mistyped, get it again from @BotFather")
+		case status != http.StatusOK:
+			report.add(SeverityWarning, "TELEGRAM_API_ENDPOINT", fmt.Sprintf("Telegram answered with status %d", status), "")
+		default:
+			report.add(SeverityOK, "TELEGRAM_BOT_TOKEN", "accepted by Telegram", "")
+		}
+	}
+
+	githubAPI := c.GitHubAPIURL
+	if githubAPI == "" {
+		githubAPI = "https://api.github.com"
+	}
+	if _, err := probe(client, githubAPI); err != nil {
+		report.add(SeverityWarning, "GITHUB_API_URL", "GitHub API is unreachable: "+err.Error(), "check the network and GITHUB_API_URL")
+	} else {
+		report.add(SeverityOK, "GITHUB_API_URL", "GitHub API is reachable", "")
+	}
+
+	if c.HasLLMConfig() {
+		if _, err := probe(client, c.LLMEndpoint); err != nil {
+			report.add(SeverityWarning, "LLM_ENDPOINT", "LLM endpoint is unreachable: "+err.Error(), "check LLM_ENDPOINT")
+		} else {
+			report.add(SeverityOK, "LLM_ENDPOINT", "LLM endpoint is reachable", "")
+		}
+	}
+
+	if c.HasDatabaseConfig() && opts.PingDatabase != nil {
+		if err := opts.PingDatabase(c.PostgreDSN); err != nil {
+			report.add(SeverityError, "POSTGRE_DSN", "database connection failed: "+err.Error(), "check the DSN and that PostgreSQL is running")
+		} else {
+			report.add(SeverityOK, "POSTGRE_DSN", "database connection works", "")
+		}
+	}
+}
+
+// probe sends a GET request and returns the response status, any response counts as reachable
+func probe(client *http.Client, target string) (int, error) {
+	resp, err := client.Get(target)
+	if err != nil {
+		// The URL may contain the bot token, report the cause without it
+		if urlErr, ok := err.(*url.Error); ok {
+			return 0, urlErr.Err
+		}
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func validCheckConfig() *Config {
+	return &Config{
+		TelegramBotToken: "123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11",
+		GitHubUsername:   "user",
+		CommitAuthor:     "User <user@example.com>",
+		LogLevel:         "info",
+	}
+}
+
+func hasDiagnostic(report *Report, severity Severity, setting string) bool {
+	for _, d := range report.Diagnostics {
+		if d.Severity == severity && strings.Contains(d.Setting, setting) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		modify   func(c *Config)
+		severity Severity
+		setting  string
+	}{
+		{"missing token", func(c *Config) { c.TelegramBotToken = "" }, SeverityError, "TELEGRAM_BOT_TOKEN"},
+		{"malformed token", func(c *Config) { c.TelegramBotToken = "invalid" }, SeverityError, "TELEGRAM_BOT_TOKEN"},
+		{"committer without email", func(c *Config) { c.CommitAuthor = "User" }, SeverityWarning, "COMMIT_AUTHOR"},
+		{"unknown log level", func(c *Config) { c.LogLevel = "verbose" }, SeverityError, "LOG_LEVEL"},
+		{"relative base URL", func(c *Config) { c.BaseURL = "example.com" }, SeverityError, "BASE_URL"},
+		{"endpoint without placeholders", func(c *Config) { c.TelegramAPIEndpoint = "http://localhost:8081" }, SeverityError, "TELEGRAM_API_ENDPOINT"},
+		{"partial LLM", func(c *Config) { c.LLMProvider, c.LLMEndpoint = "deepseek", "https://api.deepseek.com" }, SeverityWarning, "LLM_MODEL"},
+		{"database without password", func(c *Config) { c.PostgreDSN = "postgres://localhost/db" }, SeverityWarning, "TOKEN_PASSWORD"},
+		{"premium with payments", func(c *Config) { c.PremiumDefaultLevel = 2 }, SeverityWarning, "PREMIUM_DEFAULT_LEVEL"},
+		{"unknown retention policy", func(c *Config) { c.ContentRetention = "minimal" }, SeverityError, "CONTENT_RETENTION"},
+		{"submodules without clones", func(c *Config) { c.ContentRetention, c.CloneSubmodules = RetentionNone, true }, SeverityWarning, "CLONE_SUBMODULES"},
+		{"shallow clones without clones", func(c *Config) { c.ContentRetention, c.SparseCheckoutLevels = RetentionNone, []int{0} }, SeverityWarning, "SHALLOW_CLONE_LEVELS"},
+		{"sandbox", func(c *Config) { c.Sandbox = true }, SeverityWarning, "SANDBOX"},
+		{"partial backups", func(c *Config) { c.BackupS3Bucket = "backups" }, SeverityWarning, "BACKUP_S3_ENDPOINT"},
+		{"allowed and blocked chat", func(c *Config) { c.AllowedChatIDs, c.BlockedChatIDs = []int64{1, 2}, []int64{2} }, SeverityWarning, "BLOCKED_CHAT_IDS"},
+		{"plain HTTP webhook", func(c *Config) { c.WebhookURL = "http://bot.example.com/telegram" }, SeverityError, "WEBHOOK_URL"},
+		{"Redis address without scheme", func(c *Config) { c.RedisURL = "localhost:6379" }, SeverityError, "REDIS_URL"},
+		{"metrics port not a number", func(c *Config) { c.MetricsPort = "metrics" }, SeverityError, "METRICS_PORT"},
+		{"metrics port of the webhook server", func(c *Config) { c.WebhookPort, c.MetricsPort = "8080", "8080" }, SeverityError, "METRICS_PORT"},
+		{"short admin API token", func(c *Config) { c.AdminAPIToken = "secret" }, SeverityWarning, "ADMIN_API_TOKEN"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validCheckConfig()
+			tt.modify(cfg)
+			report := cfg.Check(CheckOptions{})
+			if !hasDiagnostic(report, tt.severity, tt.setting) {
+				t.Errorf("Check() missing %s for %s, got %+v", tt.severity, tt.setting, report.Diagnostics)
+			}
+			if report.HasErrors() != (tt.severity == SeverityError) {
+				t.Errorf("HasErrors() = %v", report.HasErrors())
+			}
+		})
+	}
+}
+
+func TestCheckValidConfig(t *testing.T) {
+	report := validCheckConfig().Check(CheckOptions{})
+	if len(report.Diagnostics) != 0 {
+		t.Errorf("Check() = %+v, want no findings", report.Diagnostics)
+	}
+}
+
+func TestCheckConnectivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "getMe") {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	cfg := validCheckConfig()
+	cfg.TelegramAPIEndpoint = server.URL + "/bot%s/%s"
+	cfg.GitHubAPIURL = server.URL
+
+	report := cfg.Check(CheckOptions{Connectivity: true, HTTPClient: server.Client()})
+	if !hasDiagnostic(report, SeverityError, "TELEGRAM_BOT_TOKEN") {
+		t.Errorf("Check() should report the rejected token, got %+v", report.Diagnostics)
+	}
+	if !hasDiagnostic(report, SeverityOK, "GITHUB_API_URL") {
+		t.Errorf("Check() should report GitHub as reachable, got %+v", report.Diagnostics)
+	}
+
+	var out bytes.Buffer
+	report.Write(&out)
+	if !strings.Contains(out.String(), "1 error(s), 0 warning(s)") {
+		t.Errorf("Write() summary missing in:\n%s", out.String())
+	}
+}
@@ -1,12 +1,33 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// LLM tasks that can use their own models
+const (
+	LLMTaskTagging    = "tagging"    // Titles and hashtags of notes
+	LLMTaskSummary    = "summary"    // Digests, reviews and other summaries
+	LLMTaskMultimodal = "multimodal" // Image analysis
+)
+
+// LLMTasks lists the LLM tasks in display order
+var LLMTasks = []string{LLMTaskTagging, LLMTaskSummary, LLMTaskMultimodal}
+
+// Content retention policies
+const (
+	RetentionFull = "full" // Repositories may be cloned to disk and content may appear in logs
+	RetentionNone = "none" // Zero retention, see Config.ZeroRetention
+)
+
 type Config struct {
 	TelegramBotToken string
 	GitHubToken      string
@@ -17,6 +38,7 @@ type Config struct {
 	LLMEndpoint      string
 	LLMToken         string
 	LLMModel         string
+	LLMTaskModels    map[string][]string // Models per LLM task tried in order before LLMModel, see ParseTaskModels
 	PostgreDSN       string
 	TokenPassword    string
 	LogLevel         string
@@ -28,51 +50,360 @@ type Config struct {
 	
 	// Website configuration
 	BaseURL string // Base URL for website (e.g., "https://yourdomain.com")
+
+	// Workspace object storage (optional, S3-compatible) for persisting ./data repositories
+	WorkspaceS3Endpoint  string
+	WorkspaceS3Bucket    string
+	WorkspaceS3Region    string
+	WorkspaceS3AccessKey string
+	WorkspaceS3SecretKey string
+
+	// Database backups (optional) to S3-compatible storage, encrypted with BackupPassword
+	BackupS3Endpoint  string
+	BackupS3Bucket    string
+	BackupS3Region    string
+	BackupS3AccessKey string
+	BackupS3SecretKey string
+	BackupPassword    string
+	BackupInterval    time.Duration // How often the database is backed up
+	BackupRetention   int           // Number of backups kept, older ones are deleted
+
+	// Clone-based provider: also clone git submodules (skipped by default)
+	CloneSubmodules bool
+	// Clone-based provider: premium levels whose clones only fetch the latest commit, and premium
+	// levels whose clones only check out top-level files until others are used
+	ShallowCloneLevels   []int
+	SparseCheckoutLevels []int
+
+	// Warm clones: repositories are cloned in the background after setup, active ones optionally kept fetched
+	WarmFetchInterval time.Duration // How often active repositories are fetched (0 disables periodic fetches)
+	WarmDiskQuotaMB   int           // No background clones or fetches once ./data uses this much (0 disables them)
+
+	// API endpoints (optional): local Bot API servers, GitHub Enterprise or fake servers in tests
+	TelegramAPIEndpoint string // Bot API endpoint format, e.g. "http://localhost:8081/bot%s/%s"
+	GitHubAPIURL        string // REST and GraphQL base URL, e.g. "https://github.example.com/api/v3"
+	GitHubUploadsURL    string // Release asset upload base URL
+
+	// Telegram webhook mode (optional): Telegram pushes updates to WebhookURL instead of the bot
+	// long polling them, so several instances behind a load balancer can serve one bot
+	WebhookURL      string // Public HTTPS URL of the webhook, e.g. "https://bot.example.com/telegram/webhook"
+	WebhookPort     string // Port of the HTTP server for webhooks, health checks and the capture API
+	WebhookCertFile string // TLS certificate to serve HTTPS directly, unset behind a TLS-terminating load balancer
+	WebhookKeyFile  string // Private key of WebhookCertFile
+
+	// Shared state (optional): instances using the same Redis share the cache, callback
+	// deduplication and rate limits, e.g. "redis://:password@localhost:6379/0"
+	RedisURL string
+
+	// Prometheus metrics (optional): commands, commits, pushes and GitHub API requests are served
+	// on /metrics of this port, separate from WebhookPort so it can stay private
+	MetricsPort string
+
+	// Operator configuration
+	AdminChatIDs   []int64 // Chat IDs allowed to use /admin commands
+	AllowedChatIDs []int64 // Only these chats (and admins) may use the bot if set
+	BlockedChatIDs []int64 // Chats the bot refuses to serve
+	AdminAPIToken  string  // Bearer token of the admin API on WebhookPort used by msg2gitctl, disabled if unset
+
+	// Self-hosted premium: disable payments and grant premium levels from config instead
+	PaymentsDisabled    bool          // Never initialize Stripe or offer paid upgrades
+	PremiumDefaultLevel int           // Premium level every chat gets (0-3)
+	PremiumOverrides    map[int64]int // Per-chat premium levels, replacing the default
+
+	// Moderation of content sent to the shared LLM (optional): flagged notes are committed without AI processing
+	ModerationKeywords []string // Case-insensitive words or phrases that keep content away from the shared LLM
+	ModerationEndpoint string   // OpenAI-compatible moderation URL, e.g. "https://api.openai.com/v1/moderations"
+	ModerationToken    string   // Bearer token for ModerationEndpoint
+
+	// Slow operation watchdog: operations over these thresholds are logged and counted (0 uses the default)
+	SlowHandlerThreshold time.Duration // Telegram message and callback handlers
+	SlowGitThreshold     time.Duration // Clones, fetches and pushes
+	SlowQueryThreshold   time.Duration // Database queries
+	SlowNotifyAdmins     bool          // Also DM admins about slow operations
+
+	// Content retention policy: RetentionFull, or RetentionNone to never keep message content on the host
+	ContentRetention string
+
+	// Sandbox mode: GitHub writes are simulated and reported to the user instead of pushed
+	Sandbox bool
+
+	// ConfigFile is the structured config file this config was loaded from (empty if none)
+	ConfigFile string
 }
 
+// Load reads configuration from an optional YAML/TOML config file, then applies
+// environment variables (and .env) on top so env always wins over the file
 func Load() (*Config, error) {
-	if err := godotenv.Load(); err != nil {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return nil, fmt.Errorf("failed to load .env file: %w", err)
 	}
 
-	cfg := &Config{
-		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
-		GitHubUsername:   os.Getenv("GITHUB_USERNAME"),
-		CommitAuthor:     os.Getenv("COMMIT_AUTHOR"),
-		LLMProvider:      os.Getenv("LLM_PROVIDER"),
-		LLMEndpoint:      os.Getenv("LLM_ENDPOINT"),
-		LLMToken:         os.Getenv("LLM_TOKEN"),
-		LLMModel:         os.Getenv("LLM_MODEL"),
-		PostgreDSN:       os.Getenv("POSTGRE_DSN"),
-		TokenPassword:    os.Getenv("TOKEN_PASSWORD"),
-		LogLevel:         getEnvOrDefault("LOG_LEVEL", "info"),
-		
-		// GitHub OAuth configuration
-		GitHubOAuthClientID:     os.Getenv("GITHUB_OAUTH_CLIENT_ID"),
-		GitHubOAuthClientSecret: os.Getenv("GITHUB_OAUTH_CLIENT_SECRET"),
-		GitHubOAuthRedirectURI:  os.Getenv("GITHUB_OAUTH_REDIRECT_URI"),
-		
-		// Website configuration
-		BaseURL: os.Getenv("BASE_URL"),
+	cfg, err := loadFromSources()
+	if err != nil {
+		return nil, err
 	}
 
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
 
+	// Refuse clearly broken settings, warnings are left to the caller
+	if report := cfg.Check(CheckOptions{}); report.HasErrors() {
+		return nil, &InvalidConfigError{Report: report}
+	}
+
 	return cfg, nil
 }
 
-func (c *Config) validate() error {
-	required := map[string]string{
-		"TELEGRAM_BOT_TOKEN": c.TelegramBotToken,
-		"GITHUB_USERNAME":    c.GitHubUsername,
-		"COMMIT_AUTHOR":      c.CommitAuthor,
+// LoadUnchecked reads configuration like Load without validating it, for reporting what is wrong with it
+func LoadUnchecked() (*Config, error) {
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to load .env file: %w", err)
+	}
+
+	return loadFromSources()
+}
+
+// loadFromSources builds a Config from defaults, the config file and the environment
+func loadFromSources() (*Config, error) {
+	cfg := &Config{
+		LogLevel:          "info",
+		WorkspaceS3Region: "us-east-1",
+		BackupS3Region:    "us-east-1",
+		BackupInterval:    24 * time.Hour,
+		BackupRetention:   14,
+		WarmDiskQuotaMB:   768,
+		ContentRetention:  RetentionFull,
+		WebhookPort:       "8080",
+	}
+
+	if path := findConfigFile(); path != "" {
+		fc, err := parseConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := fc.apply(cfg); err != nil {
+			return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		cfg.ConfigFile = path
+	}
+
+	overrideFromEnv(&cfg.TelegramBotToken, "TELEGRAM_BOT_TOKEN")
+	overrideFromEnv(&cfg.GitHubUsername, "GITHUB_USERNAME")
+	overrideFromEnv(&cfg.CommitAuthor, "COMMIT_AUTHOR")
+	overrideFromEnv(&cfg.LLMProvider, "LLM_PROVIDER")
+	overrideFromEnv(&cfg.LLMEndpoint, "LLM_ENDPOINT")
+	overrideFromEnv(&cfg.LLMToken, "LLM_TOKEN")
+	overrideFromEnv(&cfg.LLMModel, "LLM_MODEL")
+	if value := os.Getenv("LLM_TASK_MODELS"); value != "" {
+		taskModels, err := ParseTaskModels(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_TASK_MODELS: %w", err)
+		}
+		cfg.LLMTaskModels = taskModels
+	}
+	overrideFromEnv(&cfg.PostgreDSN, "POSTGRE_DSN")
+	overrideFromEnv(&cfg.TokenPassword, "TOKEN_PASSWORD")
+	overrideFromEnv(&cfg.LogLevel, "LOG_LEVEL")
+	overrideFromEnv(&cfg.ContentRetention, "CONTENT_RETENTION")
+
+	// GitHub OAuth configuration
+	overrideFromEnv(&cfg.GitHubOAuthClientID, "GITHUB_OAUTH_CLIENT_ID")
+	overrideFromEnv(&cfg.GitHubOAuthClientSecret, "GITHUB_OAUTH_CLIENT_SECRET")
+	overrideFromEnv(&cfg.GitHubOAuthRedirectURI, "GITHUB_OAUTH_REDIRECT_URI")
+
+	// Website configuration
+	overrideFromEnv(&cfg.BaseURL, "BASE_URL")
+
+	// Workspace object storage configuration
+	overrideFromEnv(&cfg.WorkspaceS3Endpoint, "WORKSPACE_S3_ENDPOINT")
+	overrideFromEnv(&cfg.WorkspaceS3Bucket, "WORKSPACE_S3_BUCKET")
+	overrideFromEnv(&cfg.WorkspaceS3Region, "WORKSPACE_S3_REGION")
+	overrideFromEnv(&cfg.WorkspaceS3AccessKey, "WORKSPACE_S3_ACCESS_KEY")
+	overrideFromEnv(&cfg.WorkspaceS3SecretKey, "WORKSPACE_S3_SECRET_KEY")
+
+	// Database backup configuration
+	overrideFromEnv(&cfg.BackupS3Endpoint, "BACKUP_S3_ENDPOINT")
+	overrideFromEnv(&cfg.BackupS3Bucket, "BACKUP_S3_BUCKET")
+	overrideFromEnv(&cfg.BackupS3Region, "BACKUP_S3_REGION")
+	overrideFromEnv(&cfg.BackupS3AccessKey, "BACKUP_S3_ACCESS_KEY")
+	overrideFromEnv(&cfg.BackupS3SecretKey, "BACKUP_S3_SECRET_KEY")
+	overrideFromEnv(&cfg.BackupPassword, "BACKUP_PASSWORD")
+	if value := os.Getenv("BACKUP_INTERVAL"); value != "" {
+		interval, err := parseThreshold(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BACKUP_INTERVAL: %w", err)
+		}
+		cfg.BackupInterval = interval
+	}
+	if value := os.Getenv("BACKUP_RETENTION"); value != "" {
+		retention, err := strconv.Atoi(value)
+		if err != nil || retention < 1 {
+			return nil, fmt.Errorf("invalid BACKUP_RETENTION: %s", value)
+		}
+		cfg.BackupRetention = retention
+	}
+
+	// Moderation configuration
+	overrideFromEnv(&cfg.ModerationEndpoint, "MODERATION_ENDPOINT")
+	overrideFromEnv(&cfg.ModerationToken, "MODERATION_TOKEN")
+	if value := os.Getenv("MODERATION_KEYWORDS"); value != "" {
+		cfg.ModerationKeywords = parseKeywordList(value)
+	}
+
+	// Watchdog configuration
+	for key, target := range map[string]*time.Duration{
+		"SLOW_HANDLER_THRESHOLD": &cfg.SlowHandlerThreshold,
+		"SLOW_GIT_THRESHOLD":     &cfg.SlowGitThreshold,
+		"SLOW_QUERY_THRESHOLD":   &cfg.SlowQueryThreshold,
+	} {
+		if value := os.Getenv(key); value != "" {
+			threshold, err := parseThreshold(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			*target = threshold
+		}
+	}
+
+	if value := os.Getenv("SLOW_NOTIFY_ADMINS"); value != "" {
+		notifyAdmins, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLOW_NOTIFY_ADMINS: %w", err)
+		}
+		cfg.SlowNotifyAdmins = notifyAdmins
+	}
+
+	// Warm clone configuration
+	if value := os.Getenv("WARM_FETCH_INTERVAL"); value != "" {
+		interval, err := parseThreshold(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid WARM_FETCH_INTERVAL: %w", err)
+		}
+		cfg.WarmFetchInterval = interval
+	}
+
+	if value := os.Getenv("WARM_DISK_QUOTA_MB"); value != "" {
+		quota, err := strconv.Atoi(value)
+		if err != nil || quota < 0 {
+			return nil, fmt.Errorf("invalid WARM_DISK_QUOTA_MB: %s", value)
+		}
+		cfg.WarmDiskQuotaMB = quota
+	}
+
+	// API endpoint configuration
+	overrideFromEnv(&cfg.TelegramAPIEndpoint, "TELEGRAM_API_ENDPOINT")
+	overrideFromEnv(&cfg.GitHubAPIURL, "GITHUB_API_URL")
+	overrideFromEnv(&cfg.GitHubUploadsURL, "GITHUB_UPLOADS_URL")
+
+	// Telegram webhook configuration
+	overrideFromEnv(&cfg.WebhookURL, "WEBHOOK_URL")
+	overrideFromEnv(&cfg.WebhookPort, "WEBHOOK_PORT")
+	overrideFromEnv(&cfg.WebhookCertFile, "WEBHOOK_CERT_FILE")
+	overrideFromEnv(&cfg.WebhookKeyFile, "WEBHOOK_KEY_FILE")
+	overrideFromEnv(&cfg.RedisURL, "REDIS_URL")
+	overrideFromEnv(&cfg.MetricsPort, "METRICS_PORT")
+	overrideFromEnv(&cfg.AdminAPIToken, "ADMIN_API_TOKEN")
+
+	if value := os.Getenv("SANDBOX"); value != "" {
+		sandbox, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SANDBOX: %w", err)
+		}
+		cfg.Sandbox = sandbox
+	}
+
+	if value := os.Getenv("CLONE_SUBMODULES"); value != "" {
+		cloneSubmodules, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CLONE_SUBMODULES: %w", err)
+		}
+		cfg.CloneSubmodules = cloneSubmodules
+	}
+	if value := os.Getenv("SHALLOW_CLONE_LEVELS"); value != "" {
+		levels, err := parsePremiumLevelList(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SHALLOW_CLONE_LEVELS: %w", err)
+		}
+		cfg.ShallowCloneLevels = levels
+	}
+	if value := os.Getenv("SPARSE_CHECKOUT_LEVELS"); value != "" {
+		levels, err := parsePremiumLevelList(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SPARSE_CHECKOUT_LEVELS: %w", err)
+		}
+		cfg.SparseCheckoutLevels = levels
+	}
+
+	// Admin configuration
+	if adminIDs := os.Getenv("ADMIN_CHAT_IDS"); adminIDs != "" {
+		ids, err := parseChatIDList(adminIDs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ADMIN_CHAT_IDS: %w", err)
+		}
+		cfg.AdminChatIDs = ids
+	}
+	if allowedIDs := os.Getenv("ALLOWED_CHAT_IDS"); allowedIDs != "" {
+		ids, err := parseChatIDList(allowedIDs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ALLOWED_CHAT_IDS: %w", err)
+		}
+		cfg.AllowedChatIDs = ids
+	}
+	if blockedIDs := os.Getenv("BLOCKED_CHAT_IDS"); blockedIDs != "" {
+		ids, err := parseChatIDList(blockedIDs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BLOCKED_CHAT_IDS: %w", err)
+		}
+		cfg.BlockedChatIDs = ids
+	}
+
+	// Self-hosted premium configuration
+	if value := os.Getenv("PAYMENTS_DISABLED"); value != "" {
+		paymentsDisabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PAYMENTS_DISABLED: %w", err)
+		}
+		cfg.PaymentsDisabled = paymentsDisabled
+	}
+
+	if value := os.Getenv("PREMIUM_DEFAULT_LEVEL"); value != "" {
+		level, err := parsePremiumLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PREMIUM_DEFAULT_LEVEL: %w", err)
+		}
+		cfg.PremiumDefaultLevel = level
+	}
+
+	if value := os.Getenv("PREMIUM_OVERRIDES"); value != "" {
+		overrides, err := parsePremiumOverrides(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PREMIUM_OVERRIDES: %w", err)
+		}
+		cfg.PremiumOverrides = overrides
 	}
 
-	for key, value := range required {
-		if value == "" {
-			return fmt.Errorf("required environment variable %s is not set", key)
+	return cfg, nil
+}
+
+// requiredSetting is a setting the bot can't start without
+type requiredSetting struct {
+	key   string
+	value string
+}
+
+func (c *Config) requiredSettings() []requiredSetting {
+	return []requiredSetting{
+		{"TELEGRAM_BOT_TOKEN", c.TelegramBotToken},
+		{"GITHUB_USERNAME", c.GitHubUsername},
+		{"COMMIT_AUTHOR", c.CommitAuthor},
+	}
+}
+
+func (c *Config) validate() error {
+	for _, setting := range c.requiredSettings() {
+		if setting.value == "" {
+			return fmt.Errorf("required environment variable %s is not set (or set it in the config file)", setting.key)
 		}
 	}
 
@@ -91,10 +422,234 @@ func (c *Config) HasGitHubOAuthConfig() bool {
 	return c.GitHubOAuthClientID != "" && c.GitHubOAuthClientSecret != "" && c.GitHubOAuthRedirectURI != ""
 }
 
-// getEnvOrDefault returns the environment variable value or a default value
-func getEnvOrDefault(key, defaultValue string) string {
+func (c *Config) HasWorkspaceStoreConfig() bool {
+	return c.WorkspaceS3Endpoint != "" && c.WorkspaceS3Bucket != "" && c.WorkspaceS3AccessKey != "" && c.WorkspaceS3SecretKey != ""
+}
+
+func (c *Config) HasBackupConfig() bool {
+	return c.BackupS3Endpoint != "" && c.BackupS3Bucket != "" && c.BackupS3AccessKey != "" && c.BackupS3SecretKey != "" && c.BackupPassword != ""
+}
+
+// HasTelegramWebhookConfig reports whether updates are received through a webhook instead of long polling
+func (c *Config) HasTelegramWebhookConfig() bool {
+	return c.WebhookURL != ""
+}
+
+// HasRedisConfig reports whether instances share state through Redis
+func (c *Config) HasRedisConfig() bool {
+	return c.RedisURL != ""
+}
+
+// HasMetricsConfig reports whether Prometheus metrics are served
+func (c *Config) HasMetricsConfig() bool {
+	return c.MetricsPort != ""
+}
+
+// HasAdminAPIConfig reports whether the admin API is served
+func (c *Config) HasAdminAPIConfig() bool {
+	return c.AdminAPIToken != ""
+}
+
+// ZeroRetention reports whether message content must never be kept on the bot host: content goes
+// straight to GitHub through the API, is left out of logs and features storing it are disabled
+func (c *Config) ZeroRetention() bool {
+	return c.ContentRetention == RetentionNone
+}
+
+// IsAdmin reports whether chatID is listed as a bot operator
+func (c *Config) IsAdmin(chatID int64) bool {
+	for _, id := range c.AdminChatIDs {
+		if id == chatID {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfiguredPremiumLevel returns the premium level granted to chatID by config, 0 if none
+func (c *Config) ConfiguredPremiumLevel(chatID int64) int {
+	if level, ok := c.PremiumOverrides[chatID]; ok {
+		return level
+	}
+	return c.PremiumDefaultLevel
+}
+
+// ShallowClone reports whether clones of users on premiumLevel only fetch the latest commit
+func (c *Config) ShallowClone(premiumLevel int) bool {
+	return containsLevel(c.ShallowCloneLevels, premiumLevel)
+}
+
+// SparseCheckout reports whether clones of users on premiumLevel only check out top-level files
+func (c *Config) SparseCheckout(premiumLevel int) bool {
+	return containsLevel(c.SparseCheckoutLevels, premiumLevel)
+}
+
+func containsLevel(levels []int, level int) bool {
+	for _, l := range levels {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// overrideFromEnv replaces target with the environment variable value when it is set
+func overrideFromEnv(target *string, key string) {
 	if value := os.Getenv(key); value != "" {
-		return value
+		*target = value
+	}
+}
+
+// parseThreshold parses a watchdog threshold such as "500ms" or "2m", 0 uses the default
+func parseThreshold(value string) (time.Duration, error) {
+	threshold, err := time.ParseDuration(strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	if threshold < 0 {
+		return 0, fmt.Errorf("threshold %s is negative", value)
+	}
+	return threshold, nil
+}
+
+// parseChatIDList parses a comma-separated list of chat IDs
+func parseChatIDList(value string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid chat ID %q: %w", part, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// maxPremiumLevel is the highest premium level (Sponsor)
+const maxPremiumLevel = 3
+
+// parsePremiumLevel parses a premium level between 0 (free) and maxPremiumLevel
+func parsePremiumLevel(value string) (int, error) {
+	level, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid premium level %q: %w", value, err)
 	}
-	return defaultValue
-}
\ No newline at end of file
+	return level, checkPremiumLevel(level)
+}
+
+func checkPremiumLevel(level int) error {
+	if level < 0 || level > maxPremiumLevel {
+		return fmt.Errorf("premium level %d out of range 0-%d", level, maxPremiumLevel)
+	}
+	return nil
+}
+
+// parsePremiumLevelList parses a comma-separated list of premium levels
+func parsePremiumLevelList(value string) ([]int, error) {
+	var levels []int
+	for _, part := range strings.Split(value, ",") {
+		if strings.TrimSpace(part) == "" {
+			continue
+		}
+		level, err := parsePremiumLevel(part)
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, level)
+	}
+	return levels, nil
+}
+
+// parsePremiumOverrides parses a comma-separated list of chatID:level pairs
+func parsePremiumOverrides(value string) (map[int64]int, error) {
+	overrides := make(map[int64]int)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		chatID, level, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid premium override %q, expected chatID:level", part)
+		}
+		ids, err := parseChatIDList(chatID)
+		if err != nil || len(ids) != 1 {
+			return nil, fmt.Errorf("invalid chat ID in premium override %q", part)
+		}
+		parsed, err := parsePremiumLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		overrides[ids[0]] = parsed
+	}
+	return overrides, nil
+}
+
+// ParseTaskModels parses models per LLM task in the form "tagging=model-a,model-b;summary=model-c".
+// The models of a task are tried in order, the first one that answers is used.
+func ParseTaskModels(value string) (map[string][]string, error) {
+	taskModels := make(map[string][]string)
+	for _, part := range strings.Split(value, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		task, models, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid task models %q, expected task=model[,fallback...]", part)
+		}
+		task = strings.ToLower(strings.TrimSpace(task))
+		if !isLLMTask(task) {
+			return nil, fmt.Errorf("unknown LLM task %q, expected one of %s", task, strings.Join(LLMTasks, ", "))
+		}
+		for _, model := range strings.Split(models, ",") {
+			if model = strings.TrimSpace(model); model != "" {
+				taskModels[task] = append(taskModels[task], model)
+			}
+		}
+		if len(taskModels[task]) == 0 {
+			return nil, fmt.Errorf("no models for LLM task %q", task)
+		}
+	}
+	return taskModels, nil
+}
+
+// FormatTaskModels formats models per LLM task in the form ParseTaskModels reads
+func FormatTaskModels(taskModels map[string][]string) string {
+	var parts []string
+	for _, task := range LLMTasks {
+		if models := taskModels[task]; len(models) > 0 {
+			parts = append(parts, task+"="+strings.Join(models, ","))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+func isLLMTask(task string) bool {
+	for _, known := range LLMTasks {
+		if task == known {
+			return true
+		}
+	}
+	return false
+}
+
+// parseKeywordList parses a comma-separated list of keywords, skipping empty entries
+func parseKeywordList(value string) []string {
+	var keywords []string
+	for _, keyword := range strings.Split(value, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return keywords
+}
+
+// HasModerationConfig reports whether content is checked before it is sent to the shared LLM
+func (c *Config) HasModerationConfig() bool {
+	return len(c.ModerationKeywords) > 0 || c.ModerationEndpoint != ""
+}
@@ -0,0 +1,449 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfigFiles are searched in order when CONFIG_FILE is not set
+var defaultConfigFiles = []string{"config.yaml", "config.yml", "config.toml"}
+
+// fileConfig mirrors the structured config file layout (YAML or TOML)
+type fileConfig struct {
+	Telegram struct {
+		BotToken    string `yaml:"bot_token" toml:"bot_token"`
+		APIEndpoint string `yaml:"api_endpoint" toml:"api_endpoint"`
+		// Webhook mode, see Config.WebhookURL
+		WebhookURL      string `yaml:"webhook_url" toml:"webhook_url"`
+		WebhookPort     string `yaml:"webhook_port" toml:"webhook_port"`
+		WebhookCertFile string `yaml:"webhook_cert_file" toml:"webhook_cert_file"`
+		WebhookKeyFile  string `yaml:"webhook_key_file" toml:"webhook_key_file"`
+	} `yaml:"telegram" toml:"telegram"`
+
+	GitHub struct {
+		Username        string `yaml:"username" toml:"username"`
+		CommitAuthor    string `yaml:"commit_author" toml:"commit_author"`
+		CloneSubmodules bool   `yaml:"clone_submodules" toml:"clone_submodules"`
+		// Premium levels, see Config.ShallowCloneLevels
+		ShallowCloneLevels   []int  `yaml:"shallow_clone_levels" toml:"shallow_clone_levels"`
+		SparseCheckoutLevels []int  `yaml:"sparse_checkout_levels" toml:"sparse_checkout_levels"`
+		APIURL               string `yaml:"api_url" toml:"api_url"`
+		UploadsURL           string `yaml:"uploads_url" toml:"uploads_url"`
+		OAuth                struct {
+			ClientID     string `yaml:"client_id" toml:"client_id"`
+			ClientSecret string `yaml:"client_secret" toml:"client_secret"`
+			RedirectURI  string `yaml:"redirect_uri" toml:"redirect_uri"`
+		} `yaml:"oauth" toml:"oauth"`
+	} `yaml:"github" toml:"github"`
+
+	LLM struct {
+		Provider string `yaml:"provider" toml:"provider"`
+		Endpoint string `yaml:"endpoint" toml:"endpoint"`
+		Token    string `yaml:"token" toml:"token"`
+		Model    string `yaml:"model" toml:"model"`
+		// Models per task, tried in order before model: tagging, summary, multimodal
+		TaskModels map[string][]string `yaml:"task_models" toml:"task_models"`
+	} `yaml:"llm" toml:"llm"`
+
+	Database struct {
+		DSN           string `yaml:"dsn" toml:"dsn"`
+		TokenPassword string `yaml:"token_password" toml:"token_password"`
+	} `yaml:"database" toml:"database"`
+
+	// Shared state of several instances, see Config.RedisURL
+	Redis struct {
+		URL string `yaml:"url" toml:"url"`
+	} `yaml:"redis" toml:"redis"`
+
+	// Prometheus endpoint, see Config.MetricsPort
+	Metrics struct {
+		Port string `yaml:"port" toml:"port"`
+	} `yaml:"metrics" toml:"metrics"`
+
+	Workspace struct {
+		S3Endpoint  string `yaml:"s3_endpoint" toml:"s3_endpoint"`
+		S3Bucket    string `yaml:"s3_bucket" toml:"s3_bucket"`
+		S3Region    string `yaml:"s3_region" toml:"s3_region"`
+		S3AccessKey string `yaml:"s3_access_key" toml:"s3_access_key"`
+		S3SecretKey string `yaml:"s3_secret_key" toml:"s3_secret_key"`
+		// Background clones and fetches, see WarmFetchInterval and WarmDiskQuotaMB
+		WarmFetchInterval string `yaml:"warm_fetch_interval" toml:"warm_fetch_interval"`
+		WarmDiskQuotaMB   *int   `yaml:"warm_disk_quota_mb" toml:"warm_disk_quota_mb"`
+	} `yaml:"workspace" toml:"workspace"`
+
+	Backup struct {
+		S3Endpoint  string `yaml:"s3_endpoint" toml:"s3_endpoint"`
+		S3Bucket    string `yaml:"s3_bucket" toml:"s3_bucket"`
+		S3Region    string `yaml:"s3_region" toml:"s3_region"`
+		S3AccessKey string `yaml:"s3_access_key" toml:"s3_access_key"`
+		S3SecretKey string `yaml:"s3_secret_key" toml:"s3_secret_key"`
+		Password    string `yaml:"password" toml:"password"`
+		Interval    string `yaml:"interval" toml:"interval"` // Duration such as "24h"
+		Retention   *int   `yaml:"retention" toml:"retention"`
+	} `yaml:"backup" toml:"backup"`
+
+	Admin struct {
+		ChatIDs        []int64 `yaml:"chat_ids" toml:"chat_ids"`
+		AllowedChatIDs []int64 `yaml:"allowed_chat_ids" toml:"allowed_chat_ids"`
+		BlockedChatIDs []int64 `yaml:"blocked_chat_ids" toml:"blocked_chat_ids"`
+		APIToken       string  `yaml:"api_token" toml:"api_token"` // See Config.AdminAPIToken
+	} `yaml:"admin" toml:"admin"`
+
+	Moderation struct {
+		Keywords []string `yaml:"keywords" toml:"keywords"`
+		Endpoint string   `yaml:"endpoint" toml:"endpoint"`
+		Token    string   `yaml:"token" toml:"token"`
+	} `yaml:"moderation" toml:"moderation"`
+
+	Watchdog struct {
+		Handler      string `yaml:"handler" toml:"handler"` // Durations such as "10s" or "500ms"
+		Git          string `yaml:"git" toml:"git"`
+		Query        string `yaml:"query" toml:"query"`
+		NotifyAdmins bool   `yaml:"notify_admins" toml:"notify_admins"`
+	} `yaml:"watchdog" toml:"watchdog"`
+
+	Premium struct {
+		PaymentsDisabled bool           `yaml:"payments_disabled" toml:"payments_disabled"`
+		DefaultLevel     int            `yaml:"default_level" toml:"default_level"`
+		Overrides        map[string]int `yaml:"overrides" toml:"overrides"` // chat ID -> level
+	} `yaml:"premium" toml:"premium"`
+
+	LogLevel         string `yaml:"log_level" toml:"log_level"`
+	BaseURL          string `yaml:"base_url" toml:"base_url"`
+	ContentRetention string `yaml:"content_retention" toml:"content_retention"`
+	Sandbox          bool   `yaml:"sandbox" toml:"sandbox"`
+}
+
+// findConfigFile returns the config file path from CONFIG_FILE or the default locations
+func findConfigFile() string {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		return path
+	}
+
+	for _, candidate := range defaultConfigFiles {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+
+	return ""
+}
+
+// parseConfigFile decodes a YAML or TOML config file based on its extension
+func parseConfigFile(path string) (*fileConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	fc := &fileConfig{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, fc); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config file %s: %w", path, err)
+		}
+	case ".toml":
+		if err := toml.Unmarshal(data, fc); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML config file %s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported config file format: %s (use .yaml, .yml or .toml)", path)
+	}
+
+	return fc, nil
+}
+
+// apply copies the file values into cfg
+func (fc *fileConfig) apply(cfg *Config) error {
+	cfg.TelegramBotToken = fc.Telegram.BotToken
+	cfg.TelegramAPIEndpoint = fc.Telegram.APIEndpoint
+	cfg.WebhookURL = fc.Telegram.WebhookURL
+	cfg.WebhookCertFile = fc.Telegram.WebhookCertFile
+	cfg.WebhookKeyFile = fc.Telegram.WebhookKeyFile
+	cfg.GitHubUsername = fc.GitHub.Username
+	cfg.CommitAuthor = fc.GitHub.CommitAuthor
+	cfg.CloneSubmodules = fc.GitHub.CloneSubmodules
+	for _, level := range append(fc.GitHub.ShallowCloneLevels, fc.GitHub.SparseCheckoutLevels...) {
+		if err := checkPremiumLevel(level); err != nil {
+			return fmt.Errorf("github clone levels: %w", err)
+		}
+	}
+	cfg.ShallowCloneLevels = fc.GitHub.ShallowCloneLevels
+	cfg.SparseCheckoutLevels = fc.GitHub.SparseCheckoutLevels
+	cfg.GitHubAPIURL = fc.GitHub.APIURL
+	cfg.GitHubUploadsURL = fc.GitHub.UploadsURL
+	cfg.GitHubOAuthClientID = fc.GitHub.OAuth.ClientID
+	cfg.GitHubOAuthClientSecret = fc.GitHub.OAuth.ClientSecret
+	cfg.GitHubOAuthRedirectURI = fc.GitHub.OAuth.RedirectURI
+	cfg.LLMProvider = fc.LLM.Provider
+	cfg.LLMEndpoint = fc.LLM.Endpoint
+	cfg.LLMToken = fc.LLM.Token
+	cfg.LLMModel = fc.LLM.Model
+	cfg.PostgreDSN = fc.Database.DSN
+	cfg.TokenPassword = fc.Database.TokenPassword
+	cfg.RedisURL = fc.Redis.URL
+	cfg.MetricsPort = fc.Metrics.Port
+	cfg.WorkspaceS3Endpoint = fc.Workspace.S3Endpoint
+	cfg.WorkspaceS3Bucket = fc.Workspace.S3Bucket
+	cfg.WorkspaceS3AccessKey = fc.Workspace.S3AccessKey
+	cfg.WorkspaceS3SecretKey = fc.Workspace.S3SecretKey
+	cfg.BackupS3Endpoint = fc.Backup.S3Endpoint
+	cfg.BackupS3Bucket = fc.Backup.S3Bucket
+	cfg.BackupS3AccessKey = fc.Backup.S3AccessKey
+	cfg.BackupS3SecretKey = fc.Backup.S3SecretKey
+	cfg.BackupPassword = fc.Backup.Password
+	cfg.AdminChatIDs = fc.Admin.ChatIDs
+	cfg.AllowedChatIDs = fc.Admin.AllowedChatIDs
+	cfg.BlockedChatIDs = fc.Admin.BlockedChatIDs
+	cfg.AdminAPIToken = fc.Admin.APIToken
+	cfg.ModerationKeywords = parseKeywordList(strings.Join(fc.Moderation.Keywords, ","))
+	cfg.ModerationEndpoint = fc.Moderation.Endpoint
+	cfg.ModerationToken = fc.Moderation.Token
+	cfg.BaseURL = fc.BaseURL
+	cfg.Sandbox = fc.Sandbox
+
+	if fc.Telegram.WebhookPort != "" {
+		cfg.WebhookPort = fc.Telegram.WebhookPort
+	}
+	if fc.Workspace.S3Region != "" {
+		cfg.WorkspaceS3Region = fc.Workspace.S3Region
+	}
+	if fc.Workspace.WarmFetchInterval != "" {
+		interval, err := parseThreshold(fc.Workspace.WarmFetchInterval)
+		if err != nil {
+			return fmt.Errorf("workspace.warm_fetch_interval: %w", err)
+		}
+		cfg.WarmFetchInterval = interval
+	}
+	if fc.Backup.S3Region != "" {
+		cfg.BackupS3Region = fc.Backup.S3Region
+	}
+	if fc.Backup.Interval != "" {
+		interval, err := parseThreshold(fc.Backup.Interval)
+		if err != nil {
+			return fmt.Errorf("backup.interval: %w", err)
+		}
+		cfg.BackupInterval = interval
+	}
+	if fc.Backup.Retention != nil {
+		if *fc.Backup.Retention < 1 {
+			return fmt.Errorf("backup.retention: %d must be at least 1", *fc.Backup.Retention)
+		}
+		cfg.BackupRetention = *fc.Backup.Retention
+	}
+	if fc.Workspace.WarmDiskQuotaMB != nil {
+		if *fc.Workspace.WarmDiskQuotaMB < 0 {
+			return fmt.Errorf("workspace.warm_disk_quota_mb: %d is negative", *fc.Workspace.WarmDiskQuotaMB)
+		}
+		cfg.WarmDiskQuotaMB = *fc.Workspace.WarmDiskQuotaMB
+	}
+	if fc.LogLevel != "" {
+		cfg.LogLevel = fc.LogLevel
+	}
+	if fc.ContentRetention != "" {
+		cfg.ContentRetention = fc.ContentRetention
+	}
+
+	for name, setting := range map[string]struct {
+		value  string
+		target *time.Duration
+	}{
+		"watchdog.handler": {fc.Watchdog.Handler, &cfg.SlowHandlerThreshold},
+		"watchdog.git":     {fc.Watchdog.Git, &cfg.SlowGitThreshold},
+		"watchdog.query":   {fc.Watchdog.Query, &cfg.SlowQueryThreshold},
+	} {
+		if setting.value == "" {
+			continue
+		}
+		threshold, err := parseThreshold(setting.value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		*setting.target = threshold
+	}
+	cfg.SlowNotifyAdmins = fc.Watchdog.NotifyAdmins
+
+	cfg.PaymentsDisabled = fc.Premium.PaymentsDisabled
+	if err := checkPremiumLevel(fc.Premium.DefaultLevel); err != nil {
+		return fmt.Errorf("premium.default_level: %w", err)
+	}
+	cfg.PremiumDefaultLevel = fc.Premium.DefaultLevel
+	if len(fc.LLM.TaskModels) > 0 {
+		parts := make([]string, 0, len(fc.LLM.TaskModels))
+		for task, models := range fc.LLM.TaskModels {
+			parts = append(parts, task+"="+strings.Join(models, ","))
+		}
+		taskModels, err := ParseTaskModels(strings.Join(parts, ";"))
+		if err != nil {
+			return fmt.Errorf("llm.task_models: %w", err)
+		}
+		cfg.LLMTaskModels = taskModels
+	}
+
+	if len(fc.Premium.Overrides) > 0 {
+		pairs := make([]string, 0, len(fc.Premium.Overrides))
+		for chatID, level := range fc.Premium.Overrides {
+			pairs = append(pairs, fmt.Sprintf("%s:%d", chatID, level))
+		}
+		overrides, err := parsePremiumOverrides(strings.Join(pairs, ","))
+		if err != nil {
+			return fmt.Errorf("premium.overrides: %w", err)
+		}
+		cfg.PremiumOverrides = overrides
+	}
+
+	return nil
+}
+
+// Reload re-reads the config file and environment and returns a copy of current with their
+// non-secret values, leaving current untouched for the goroutines still reading it. Secrets
+// (tokens, passwords, DSNs) require a restart. Also returns the names of changed settings.
+func Reload(current *Config) (*Config, []string, error) {
+	fresh, err := loadFromSources()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := fresh.validate(); err != nil {
+		return nil, nil, err
+	}
+
+	next := *current
+	var changed []string
+	reloadString := func(name string, target *string, value string) {
+		if *target != value {
+			*target = value
+			changed = append(changed, name)
+		}
+	}
+
+	reloadString("log_level", &next.LogLevel, fresh.LogLevel)
+	reloadString("github.username", &next.GitHubUsername, fresh.GitHubUsername)
+	reloadString("github.commit_author", &next.CommitAuthor, fresh.CommitAuthor)
+	reloadString("github.oauth.redirect_uri", &next.GitHubOAuthRedirectURI, fresh.GitHubOAuthRedirectURI)
+	reloadString("llm.provider", &next.LLMProvider, fresh.LLMProvider)
+	reloadString("llm.endpoint", &next.LLMEndpoint, fresh.LLMEndpoint)
+	reloadString("llm.model", &next.LLMModel, fresh.LLMModel)
+	reloadString("base_url", &next.BaseURL, fresh.BaseURL)
+
+	if fmt.Sprint(next.LLMTaskModels) != fmt.Sprint(fresh.LLMTaskModels) {
+		next.LLMTaskModels = fresh.LLMTaskModels
+		changed = append(changed, "llm.task_models")
+	}
+
+	reloadString("moderation.endpoint", &next.ModerationEndpoint, fresh.ModerationEndpoint)
+	if fmt.Sprint(next.ModerationKeywords) != fmt.Sprint(fresh.ModerationKeywords) {
+		next.ModerationKeywords = fresh.ModerationKeywords
+		changed = append(changed, "moderation.keywords")
+	}
+
+	reloadDuration := func(name string, target *time.Duration, value time.Duration) {
+		if *target != value {
+			*target = value
+			changed = append(changed, name)
+		}
+	}
+	reloadDuration("watchdog.handler", &next.SlowHandlerThreshold, fresh.SlowHandlerThreshold)
+	reloadDuration("watchdog.git", &next.SlowGitThreshold, fresh.SlowGitThreshold)
+	reloadDuration("watchdog.query", &next.SlowQueryThreshold, fresh.SlowQueryThreshold)
+	if next.SlowNotifyAdmins != fresh.SlowNotifyAdmins {
+		next.SlowNotifyAdmins = fresh.SlowNotifyAdmins
+		changed = append(changed, "watchdog.notify_admins")
+	}
+
+	reloadDuration("workspace.warm_fetch_interval", &next.WarmFetchInterval, fresh.WarmFetchInterval)
+	if next.WarmDiskQuotaMB != fresh.WarmDiskQuotaMB {
+		next.WarmDiskQuotaMB = fresh.WarmDiskQuotaMB
+		changed = append(changed, "workspace.warm_disk_quota_mb")
+	}
+
+	if next.CloneSubmodules != fresh.CloneSubmodules {
+		next.CloneSubmodules = fresh.CloneSubmodules
+		changed = append(changed, "github.clone_submodules")
+	}
+	// Clone levels apply to clones made afterwards, existing clones keep their depth and checkout
+	if fmt.Sprint(next.ShallowCloneLevels) != fmt.Sprint(fresh.ShallowCloneLevels) {
+		next.ShallowCloneLevels = fresh.ShallowCloneLevels
+		changed = append(changed, "github.shallow_clone_levels")
+	}
+	if fmt.Sprint(next.SparseCheckoutLevels) != fmt.Sprint(fresh.SparseCheckoutLevels) {
+		next.SparseCheckoutLevels = fresh.SparseCheckoutLevels
+		changed = append(changed, "github.sparse_checkout_levels")
+	}
+
+	if fmt.Sprint(next.AdminChatIDs) != fmt.Sprint(fresh.AdminChatIDs) {
+		next.AdminChatIDs = fresh.AdminChatIDs
+		changed = append(changed, "admin.chat_ids")
+	}
+	if fmt.Sprint(next.AllowedChatIDs) != fmt.Sprint(fresh.AllowedChatIDs) {
+		next.AllowedChatIDs = fresh.AllowedChatIDs
+		changed = append(changed, "admin.allowed_chat_ids")
+	}
+	if fmt.Sprint(next.BlockedChatIDs) != fmt.Sprint(fresh.BlockedChatIDs) {
+		next.BlockedChatIDs = fresh.BlockedChatIDs
+		changed = append(changed, "admin.blocked_chat_ids")
+	}
+
+	// content_retention decides how providers and logging are set up, so it requires a restart
+	// premium.payments_disabled decides whether Stripe is initialized, so it requires a restart
+	// sandbox decides which provider factory the bot uses, so it requires a restart
+	// backup settings are read when the backup scheduler starts, so they require a restart
+	// telegram.webhook_* decide how updates are received, so they require a restart
+	// admin.api_token decides whether the admin API is served, so it requires a restart
+	// redis.url is connected to once at startup, so it requires a restart
+	// metrics.port is listened on once at startup, so it requires a restart
+	if next.PremiumDefaultLevel != fresh.PremiumDefaultLevel {
+		next.PremiumDefaultLevel = fresh.PremiumDefaultLevel
+		changed = append(changed, "premium.default_level")
+	}
+
+	if fmt.Sprint(next.PremiumOverrides) != fmt.Sprint(fresh.PremiumOverrides) {
+		next.PremiumOverrides = fresh.PremiumOverrides
+		changed = append(changed, "premium.overrides")
+	}
+
+	return &next, changed, nil
+}
+
+// WatchFile polls path for modifications and calls onChange when it changes.
+// The returned function stops the watcher.
+func WatchFile(path string, interval time.Duration, onChange func()) func() {
+	stop := make(chan struct{})
+
+	var lastModified time.Time
+	if info, err := os.Stat(path); err == nil {
+		lastModified = info.ModTime()
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil {
+					continue
+				}
+				if info.ModTime().After(lastModified) {
+					lastModified = info.ModTime()
+					onChange()
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(stop) })
+	}
+}
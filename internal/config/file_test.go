@@ -0,0 +1,589 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// clearConfigEnv unsets env vars that would override file values during a test
+func clearConfigEnv(t *testing.T) {
+	for _, key := range []string{"TELEGRAM_BOT_TOKEN", "GITHUB_USERNAME", "COMMIT_AUTHOR", "LLM_PROVIDER", "LLM_ENDPOINT", "LLM_MODEL", "LLM_TASK_MODELS", "LOG_LEVEL", "ADMIN_CHAT_IDS", "ALLOWED_CHAT_IDS", "BLOCKED_CHAT_IDS", "BASE_URL", "PAYMENTS_DISABLED", "PREMIUM_DEFAULT_LEVEL", "PREMIUM_OVERRIDES", "MODERATION_KEYWORDS", "MODERATION_ENDPOINT", "SLOW_HANDLER_THRESHOLD", "SLOW_GIT_THRESHOLD", "SLOW_QUERY_THRESHOLD", "SLOW_NOTIFY_ADMINS", "WARM_FETCH_INTERVAL", "WARM_DISK_QUOTA_MB", "SANDBOX", "BACKUP_S3_ENDPOINT", "BACKUP_S3_BUCKET", "BACKUP_S3_ACCESS_KEY", "BACKUP_S3_SECRET_KEY", "BACKUP_PASSWORD", "BACKUP_INTERVAL", "BACKUP_RETENTION", "REDIS_URL", "METRICS_PORT", "ADMIN_API_TOKEN", "SHALLOW_CLONE_LEVELS", "SPARSE_CHECKOUT_LEVELS"} {
+		if original, exists := os.LookupEnv(key); exists {
+			os.Unsetenv(key)
+			t.Cleanup(func() { os.Setenv(key, original) })
+		}
+	}
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoadFromSources_YAML(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: fileuser
+  commit_author: "File User <file@example.com>"
+llm:
+  provider: gemini
+  model: gemini-2.0-flash
+admin:
+  chat_ids: [42, 43]
+  allowed_chat_ids: [44]
+log_level: debug
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.TelegramBotToken != "123:abc" || cfg.GitHubUsername != "fileuser" || cfg.CommitAuthor != "File User <file@example.com>" {
+		t.Errorf("File values not applied: %+v", cfg)
+	}
+	if cfg.LLMProvider != "gemini" || cfg.LogLevel != "debug" {
+		t.Errorf("LLMProvider = %q, LogLevel = %q", cfg.LLMProvider, cfg.LogLevel)
+	}
+	if !cfg.IsAdmin(42) || !cfg.IsAdmin(43) || cfg.IsAdmin(1) {
+		t.Errorf("AdminChatIDs = %v", cfg.AdminChatIDs)
+	}
+	if len(cfg.AllowedChatIDs) != 1 || cfg.AllowedChatIDs[0] != 44 {
+		t.Errorf("AllowedChatIDs = %v", cfg.AllowedChatIDs)
+	}
+	if cfg.WorkspaceS3Region != "us-east-1" {
+		t.Errorf("Default WorkspaceS3Region should be kept, got %q", cfg.WorkspaceS3Region)
+	}
+}
+
+func TestLoadFromSources_TOML(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.toml", `
+log_level = "warn"
+
+[github]
+username = "tomluser"
+commit_author = "Toml User <toml@example.com>"
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.GitHubUsername != "tomluser" || cfg.LogLevel != "warn" {
+		t.Errorf("GitHubUsername = %q, LogLevel = %q", cfg.GitHubUsername, cfg.LogLevel)
+	}
+}
+
+func TestLoadFromSources_EnvOverridesFile(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", "github:\n  username: fileuser\nlog_level: debug\n")
+	t.Setenv("GITHUB_USERNAME", "envuser")
+	t.Setenv("ADMIN_CHAT_IDS", "7, 8")
+	t.Setenv("BLOCKED_CHAT_IDS", "9")
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.GitHubUsername != "envuser" {
+		t.Errorf("Env should override file, got %q", cfg.GitHubUsername)
+	}
+	if cfg.LogLevel != "debug" {
+		t.Errorf("File value should be kept when env is unset, got %q", cfg.LogLevel)
+	}
+	if len(cfg.AdminChatIDs) != 2 || cfg.AdminChatIDs[0] != 7 || cfg.AdminChatIDs[1] != 8 {
+		t.Errorf("AdminChatIDs = %v", cfg.AdminChatIDs)
+	}
+	if len(cfg.BlockedChatIDs) != 1 || cfg.BlockedChatIDs[0] != 9 {
+		t.Errorf("BlockedChatIDs = %v", cfg.BlockedChatIDs)
+	}
+}
+
+func TestLoadFromSources_UnsupportedFormat(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.ini", "foo=bar")
+
+	if _, err := loadFromSources(); err == nil {
+		t.Error("Expected error for unsupported config file format")
+	}
+}
+
+func TestReload_OnlyNonSecretValues(t *testing.T) {
+	clearConfigEnv(t)
+	path := writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+llm:
+  token: old-token
+  model: model-a
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+
+	if err := os.WriteFile(path, []byte(`
+telegram:
+  bot_token: "999:changed"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+llm:
+  token: new-token
+  model: model-b
+log_level: debug
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, changed, err := Reload(cfg)
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if reloaded.LLMModel != "model-b" || reloaded.LogLevel != "debug" {
+		t.Errorf("Non-secret values should be reloaded: model=%q level=%q", reloaded.LLMModel, reloaded.LogLevel)
+	}
+	if cfg.LLMModel == "model-b" || cfg.LogLevel == "debug" {
+		t.Errorf("Reload() must not modify the current config")
+	}
+	if reloaded.LLMToken != "old-token" || reloaded.TelegramBotToken != "123:abc" {
+		t.Errorf("Secrets must not be hot-reloaded")
+	}
+	if len(changed) != 2 {
+		t.Errorf("Expected 2 changed settings, got %v", changed)
+	}
+}
+
+func TestParseChatIDList(t *testing.T) {
+	ids, err := parseChatIDList("1, 2,,3")
+	if err != nil || len(ids) != 3 {
+		t.Errorf("parseChatIDList() = %v, %v", ids, err)
+	}
+	if _, err := parseChatIDList("abc"); err == nil {
+		t.Error("Expected error for non-numeric chat ID")
+	}
+}
+
+func TestLoadFromSources_PremiumSection(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+premium:
+  payments_disabled: true
+  default_level: 1
+  overrides:
+    "42": 3
+    "-100": 0
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.PaymentsDisabled {
+		t.Error("Expected payments to be disabled")
+	}
+	if got := cfg.ConfiguredPremiumLevel(42); got != 3 {
+		t.Errorf("ConfiguredPremiumLevel(42) = %d, want 3", got)
+	}
+	if got := cfg.ConfiguredPremiumLevel(-100); got != 0 {
+		t.Errorf("ConfiguredPremiumLevel(-100) = %d, want 0", got)
+	}
+	if got := cfg.ConfiguredPremiumLevel(7); got != 1 {
+		t.Errorf("ConfiguredPremiumLevel(7) = %d, want default 1", got)
+	}
+
+	t.Setenv("PREMIUM_DEFAULT_LEVEL", "4")
+	if _, err := loadFromSources(); err == nil {
+		t.Error("Expected error for out of range PREMIUM_DEFAULT_LEVEL")
+	}
+}
+
+func TestParsePremiumOverrides(t *testing.T) {
+	overrides, err := parsePremiumOverrides("1:2, -5:3,,")
+	if err != nil || len(overrides) != 2 || overrides[1] != 2 || overrides[-5] != 3 {
+		t.Errorf("parsePremiumOverrides() = %v, %v", overrides, err)
+	}
+	for _, invalid := range []string{"1", "abc:1", "1:x", "1:9"} {
+		if _, err := parsePremiumOverrides(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadFromSources_LLMTaskModels(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+llm:
+  model: gemini-2.0-flash
+  task_models:
+    tagging: [gemini-2.0-flash-lite]
+    summary: [gemini-2.5-pro, gemini-2.0-flash]
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if got := FormatTaskModels(cfg.LLMTaskModels); got != "tagging=gemini-2.0-flash-lite;summary=gemini-2.5-pro,gemini-2.0-flash" {
+		t.Errorf("LLMTaskModels = %q", got)
+	}
+
+	t.Setenv("LLM_TASK_MODELS", "multimodal=gemini-2.5-flash")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if got := FormatTaskModels(cfg.LLMTaskModels); got != "multimodal=gemini-2.5-flash" {
+		t.Errorf("LLM_TASK_MODELS should override the file, got %q", got)
+	}
+}
+
+func TestParseTaskModels(t *testing.T) {
+	taskModels, err := ParseTaskModels(" Tagging = a ; summary=b, c,;")
+	if err != nil {
+		t.Fatalf("ParseTaskModels() error = %v", err)
+	}
+	if got := FormatTaskModels(taskModels); got != "tagging=a;summary=b,c" {
+		t.Errorf("ParseTaskModels() = %q", got)
+	}
+	for _, invalid := range []string{"tagging", "review=a", "summary=,"} {
+		if _, err := ParseTaskModels(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
+
+func TestLoadFromSources_Moderation(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+moderation:
+  keywords: [spam, "bad phrase"]
+  endpoint: "https://moderation.example.com/v1/moderations"
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.HasModerationConfig() || len(cfg.ModerationKeywords) != 2 || cfg.ModerationEndpoint != "https://moderation.example.com/v1/moderations" {
+		t.Errorf("moderation = %v %q", cfg.ModerationKeywords, cfg.ModerationEndpoint)
+	}
+
+	t.Setenv("MODERATION_KEYWORDS", " scam, ,phishing ")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if len(cfg.ModerationKeywords) != 2 || cfg.ModerationKeywords[0] != "scam" || cfg.ModerationKeywords[1] != "phishing" {
+		t.Errorf("MODERATION_KEYWORDS should override the file, got %v", cfg.ModerationKeywords)
+	}
+}
+
+func TestLoadFromSources_Watchdog(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+watchdog:
+  handler: 5s
+  query: 250ms
+  notify_admins: true
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.SlowHandlerThreshold != 5*time.Second || cfg.SlowQueryThreshold != 250*time.Millisecond || cfg.SlowGitThreshold != 0 || !cfg.SlowNotifyAdmins {
+		t.Errorf("watchdog = %v %v %v %v", cfg.SlowHandlerThreshold, cfg.SlowGitThreshold, cfg.SlowQueryThreshold, cfg.SlowNotifyAdmins)
+	}
+
+	t.Setenv("SLOW_GIT_THRESHOLD", "2m")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.SlowGitThreshold != 2*time.Minute {
+		t.Errorf("SLOW_GIT_THRESHOLD = %v, want 2m", cfg.SlowGitThreshold)
+	}
+
+	for _, invalid := range []string{"fast", "-1s"} {
+		t.Setenv("SLOW_QUERY_THRESHOLD", invalid)
+		if _, err := loadFromSources(); err == nil {
+			t.Errorf("Expected error for SLOW_QUERY_THRESHOLD=%q", invalid)
+		}
+	}
+}
+
+func TestLoadFromSources_WarmClones(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.WarmFetchInterval != 0 || cfg.WarmDiskQuotaMB != 768 {
+		t.Errorf("defaults = %v %d, want periodic fetches off and 768 MB", cfg.WarmFetchInterval, cfg.WarmDiskQuotaMB)
+	}
+
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+workspace:
+  warm_fetch_interval: 10m
+  warm_disk_quota_mb: 0
+`)
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.WarmFetchInterval != 10*time.Minute || cfg.WarmDiskQuotaMB != 0 {
+		t.Errorf("workspace = %v %d, want 10m and 0 MB", cfg.WarmFetchInterval, cfg.WarmDiskQuotaMB)
+	}
+
+	t.Setenv("WARM_DISK_QUOTA_MB", "2048")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.WarmDiskQuotaMB != 2048 {
+		t.Errorf("WARM_DISK_QUOTA_MB = %d, want 2048", cfg.WarmDiskQuotaMB)
+	}
+
+	t.Setenv("WARM_DISK_QUOTA_MB", "-1")
+	if _, err := loadFromSources(); err == nil {
+		t.Error("Expected error for a negative WARM_DISK_QUOTA_MB")
+	}
+}
+
+func TestLoadFromSources_Sandbox(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  username: user
+  commit_author: "User <user@example.com>"
+sandbox: true
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.Sandbox {
+		t.Error("sandbox: true should enable sandbox mode")
+	}
+
+	t.Setenv("SANDBOX", "false")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.Sandbox {
+		t.Error("SANDBOX=false should override the file")
+	}
+
+	t.Setenv("SANDBOX", "maybe")
+	if _, err := loadFromSources(); err == nil {
+		t.Error("Expected error for an invalid SANDBOX")
+	}
+}
+
+func TestLoadFromSources_Backup(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+backup:
+  s3_endpoint: https://s3.example.com
+  s3_bucket: backups
+  s3_access_key: access
+  s3_secret_key: secret
+  password: hunter2
+  interval: 6h
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.HasBackupConfig() {
+		t.Error("backup section should configure backups")
+	}
+	if cfg.BackupInterval != 6*time.Hour || cfg.BackupRetention != 14 || cfg.BackupS3Region != "us-east-1" {
+		t.Errorf("backup interval, retention, region = %v, %d, %q", cfg.BackupInterval, cfg.BackupRetention, cfg.BackupS3Region)
+	}
+
+	t.Setenv("BACKUP_RETENTION", "3")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.BackupRetention != 3 {
+		t.Errorf("BACKUP_RETENTION=3 gave %d", cfg.BackupRetention)
+	}
+
+	t.Setenv("BACKUP_RETENTION", "0")
+	if _, err := loadFromSources(); err == nil {
+		t.Error("BACKUP_RETENTION=0 should be rejected")
+	}
+}
+
+func TestLoadFromSources_Redis(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+redis:
+  url: "redis://localhost:6379/1"
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.HasRedisConfig() || cfg.RedisURL != "redis://localhost:6379/1" {
+		t.Errorf("RedisURL = %q, want the URL of the config file", cfg.RedisURL)
+	}
+
+	t.Setenv("REDIS_URL", "rediss://:secret@redis.example.com:6380/0")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.RedisURL != "rediss://:secret@redis.example.com:6380/0" {
+		t.Errorf("REDIS_URL = %q, want the environment to win", cfg.RedisURL)
+	}
+}
+
+func TestLoadFromSources_Metrics(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+metrics:
+  port: "9090"
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.HasMetricsConfig() || cfg.MetricsPort != "9090" {
+		t.Errorf("MetricsPort = %q, want the port of the config file", cfg.MetricsPort)
+	}
+
+	t.Setenv("METRICS_PORT", "9100")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.MetricsPort != "9100" {
+		t.Errorf("METRICS_PORT = %q, want the environment to win", cfg.MetricsPort)
+	}
+}
+
+func TestLoadFromSources_AdminAPIToken(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+admin:
+  api_token: "from-file"
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.HasAdminAPIConfig() || cfg.AdminAPIToken != "from-file" {
+		t.Errorf("AdminAPIToken = %q, want the token of the config file", cfg.AdminAPIToken)
+	}
+
+	t.Setenv("ADMIN_API_TOKEN", "from-env")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.AdminAPIToken != "from-env" {
+		t.Errorf("ADMIN_API_TOKEN = %q, want the environment to win", cfg.AdminAPIToken)
+	}
+}
+
+func TestLoadFromSources_CloneLevels(t *testing.T) {
+	clearConfigEnv(t)
+	writeConfigFile(t, "config.yaml", `
+telegram:
+  bot_token: "123:abc"
+github:
+  shallow_clone_levels: [0, 1]
+  sparse_checkout_levels: [0]
+`)
+
+	cfg, err := loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if !cfg.ShallowClone(0) || !cfg.ShallowClone(1) || cfg.ShallowClone(2) {
+		t.Errorf("ShallowCloneLevels = %v, want [0 1]", cfg.ShallowCloneLevels)
+	}
+	if !cfg.SparseCheckout(0) || cfg.SparseCheckout(1) {
+		t.Errorf("SparseCheckoutLevels = %v, want [0]", cfg.SparseCheckoutLevels)
+	}
+
+	t.Setenv("SPARSE_CHECKOUT_LEVELS", "1, 2")
+	cfg, err = loadFromSources()
+	if err != nil {
+		t.Fatalf("loadFromSources() error = %v", err)
+	}
+	if cfg.SparseCheckout(0) || !cfg.SparseCheckout(2) {
+		t.Errorf("SPARSE_CHECKOUT_LEVELS = %v, want the environment to win", cfg.SparseCheckoutLevels)
+	}
+
+	t.Setenv("SHALLOW_CLONE_LEVELS", "4")
+	if _, err := loadFromSources(); err == nil {
+		t.Error("loadFromSources() accepted an out of range SHALLOW_CLONE_LEVELS")
+	}
+}
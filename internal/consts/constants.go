@@ -42,12 +42,16 @@ const (
 	ButtonReset              = "🔄 Usage Reset"
 	ButtonManageSubscription = "⚙️ Manage Subscription"
 
-	ButtonSetRepo      = "📁 Choose Repo"
-	ButtonSetRepoToken = "🔑 Manually Auth"
-	ButtonSetCommitter = "👤 Committer"
-	ButtonGitHubOAuth  = "🔐 GitHub OAuth"
-	ButtonRevokeAuth   = "🚫 Revoke Auth"
-	ButtonOAuthCancel  = "❌ Cancel"
+	ButtonSetRepo         = "📁 Choose Repo"
+	ButtonSetRepoToken    = "🔑 Manually Auth"
+	ButtonSetCommitter    = "👤 Committer"
+	ButtonGitHubOAuth     = "🔐 GitHub OAuth"
+	ButtonRevokeAuth      = "🚫 Revoke Auth"
+	ButtonOAuthCancel     = "❌ Cancel"
+	ButtonNoreplyOn       = "🔒 Use Noreply Email"
+	ButtonNoreplyOff      = "🔓 Use Public Email"
+	ButtonBotCommitterOn  = "🤖 Bot Commits"
+	ButtonBotCommitterOff = "👤 I Commit"
 )
 
 // Premium Tier Information
@@ -127,14 +131,40 @@ const (
 	CmdStart      = "/start - Show this welcome message"
 	CmdHelp       = "/help - Show detailed help and commands"
 	CmdRepo       = "/repo - View repository information and settings"
+	CmdWhoami     = "/whoami - Show your linked GitHub account"
 	CmdSync       = "/sync - Synchronize issue statuses"
+	CmdArchive    = "/archive - Choose when closed issues are archived"
+	CmdRebuild    = "/rebuildissues - Regenerate issue.md from GitHub"
 	CmdTodo       = "/todo - Show latest TODO items"
-	CmdIssue      = "/issue - Show latest open issues"
+	CmdIssue      = "/issue - Show latest open issues and their comments"
+	CmdCat        = "/cat - View a file from your repository"
+	CmdLs         = "/ls - Browse repository files"
+	CmdSearch     = "/search - Search the text of your notes"
+	CmdTo         = "/to - Save a note directly to any file path"
 	CmdCustomFile = "/customfile - Manage custom files"
+	CmdTrash      = "/trash - Restore or permanently delete trashed files"
+	CmdPrivate    = "/private - Set the repository for private entries"
+	CmdEnterprise = "/enterprise - Use a GitHub Enterprise Server"
+	CmdGitea      = "/gitea - Keep your notes on a Gitea instance"
+	CmdBranch     = "/branch - Commit notes to another branch"
+	CmdReadme     = "/readme - Keep a table of contents of your notes in README.md"
+	CmdWebhooks   = "/webhooks - Manage outgoing webhooks for automations"
+	CmdFeeds      = "/feeds - Follow RSS feeds and GitHub releases in a daily digest"
+	CmdChannel    = "/channel - Save every post of your channel to the repository"
+	CmdCanned     = "/canned - Manage canned replies for issue comments"
+	CmdPin        = "/pin - Pin a daily summary of yesterday's captures"
+	CmdTopics     = "/topics - Show the files of forum topics"
+	CmdSource     = "/source - Link notes back to their Telegram message"
+	CmdChangelog  = "/changelog - Open a weekly changelog issue in your repository"
+	CmdModels     = "/models - Choose AI models per task"
+	CmdAPIKey     = "/apikey - Create or revoke the API key for msg2git-cli"
 	CmdInsight    = "/insight - View usage statistics and insights"
 	CmdStats      = "/stats - View global bot statistics"
+	CmdLimits     = "/limits - View your GitHub API rate limits"
+	CmdTenant     = "/tenant - View your tenant's quotas and statistics"
 	CmdResetUsage = "/resetusage - Reset usage counters (paid service)"
 	CmdCoffee     = "/coffee - Support the project and unlock premium features"
+	CmdReceipts   = "/receipts - View payment history and receipts"
 )
 
 // Upgrade Messages
@@ -274,6 +304,19 @@ const (
 
 	// Issue Management Limits
 	IssueArchiveFile = "issue_archived.md" // Archive file name
+	IssueMarkerLabel = "msg2git"           // Label of the issues the bot lists in issue.md
+)
+
+// Trash
+const (
+	TrashFolder        = "trash" // Repository folder for deleted custom files
+	TrashRetentionDays = 30      // Days before trashed files are permanently deleted
+)
+
+// Feature Flags
+const (
+	FeatureCloneProvider = "clone_provider" // Use the clone-based GitHub provider instead of the API provider
+	FeatureCommitStatus  = "commit_status"  // Attach a GitHub commit status to bot-created commits
 )
 
 // Default Values
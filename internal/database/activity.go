@@ -0,0 +1,115 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Activity methods: events the daily summary counts besides commits, e.g. completed TODOs
+
+// Activity event kinds
+const (
+	ActivityTodoCompleted = "todo_done"
+	ActivityTag           = "tag"
+	ActivityMood          = "mood" // Value is the llm.Mood name of a note
+)
+
+// RecordActivity stores an activity event of the user
+func (db *DB) RecordActivity(chatID int64, kind, value string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `INSERT INTO activity_events (chat_id, kind, value, created_at) VALUES ($1, $2, $3, NOW())`
+	if _, err := db.conn.Exec(query, chatID, kind, value); err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+
+	return nil
+}
+
+// CountActivity counts the user's events of a kind in [from, to) by value
+func (db *DB) CountActivity(chatID int64, kind string, from, to time.Time) (map[string]int, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT value, COUNT(*) FROM activity_events
+	WHERE chat_id = $1 AND kind = $2 AND created_at >= $3 AND created_at < $4
+	GROUP BY value
+	`
+
+	return db.queryCounts(query, chatID, kind, from, to)
+}
+
+// CountCommitsByFile counts the user's commits in [from, to) by file
+func (db *DB) CountCommitsByFile(chatID int64, from, to time.Time) (map[string]int, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT filename, COUNT(*) FROM commit_log
+	WHERE chat_id = $1 AND created_at >= $2 AND created_at < $3
+	GROUP BY filename
+	`
+
+	return db.queryCounts(query, chatID, from, to)
+}
+
+// DeleteActivityBefore removes activity events of the given kinds older than the given time
+func (db *DB) DeleteActivityBefore(before time.Time, kinds ...string) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM activity_events WHERE created_at < $1 AND kind = ANY($2)`, before, pq.Array(kinds))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete activity: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// CountCommitsByRepo counts the user's commits in [from, to) by repository, "" for commits
+// recorded before repositories were logged
+func (db *DB) CountCommitsByRepo(chatID int64, from, to time.Time) (map[string]int, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT repo, COUNT(*) FROM commit_log
+	WHERE chat_id = $1 AND created_at >= $2 AND created_at < $3
+	GROUP BY repo
+	`
+
+	return db.queryCounts(query, chatID, from, to)
+}
+
+func (db *DB) queryCounts(query string, args ...interface{}) (map[string]int, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var key string
+		var count int
+		if err := rows.Scan(&key, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan count: %w", err)
+		}
+		counts[key] = count
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating counts: %w", err)
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// API key methods
+
+// ReplaceAPIKey stores a new API key hash for the user, revoking any previous key
+func (db *DB) ReplaceAPIKey(chatID int64, keyHash, keyPrefix string) (*APIKey, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO api_keys (chat_id, key_hash, key_prefix, created_at, last_used_at)
+	VALUES ($1, $2, $3, NOW(), NULL)
+	ON CONFLICT (chat_id) DO UPDATE SET
+		key_hash = EXCLUDED.key_hash,
+		key_prefix = EXCLUDED.key_prefix,
+		created_at = EXCLUDED.created_at,
+		last_used_at = NULL
+	RETURNING id, chat_id, key_hash, key_prefix, created_at, last_used_at
+	`
+
+	return db.scanAPIKey(db.conn.QueryRow(query, chatID, keyHash, keyPrefix))
+}
+
+// GetAPIKey retrieves the user's API key, returns nil if none exists
+func (db *DB) GetAPIKey(chatID int64) (*APIKey, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, key_hash, key_prefix, created_at, last_used_at
+	FROM api_keys
+	WHERE chat_id = $1
+	`
+
+	return db.scanAPIKey(db.conn.QueryRow(query, chatID))
+}
+
+// GetAPIKeyByHash looks up an API key by its hash, returns nil if it doesn't exist
+func (db *DB) GetAPIKeyByHash(keyHash string) (*APIKey, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, key_hash, key_prefix, created_at, last_used_at
+	FROM api_keys
+	WHERE key_hash = $1
+	`
+
+	return db.scanAPIKey(db.conn.QueryRow(query, keyHash))
+}
+
+// TouchAPIKey records that the key was just used
+func (db *DB) TouchAPIKey(id int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if _, err := db.conn.Exec(`UPDATE api_keys SET last_used_at = NOW() WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to update api key usage: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAPIKey revokes the user's API key
+func (db *DB) DeleteAPIKey(chatID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if _, err := db.conn.Exec(`DELETE FROM api_keys WHERE chat_id = $1`, chatID); err != nil {
+		return fmt.Errorf("failed to delete api key: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) scanAPIKey(row *sql.Row) (*APIKey, error) {
+	key := &APIKey{}
+	var lastUsedAt sql.NullTime
+
+	err := row.Scan(&key.ID, &key.ChatID, &key.KeyHash, &key.KeyPrefix, &key.CreatedAt, &lastUsedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return key, nil
+}
@@ -0,0 +1,121 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// Background failure methods
+
+const backgroundFailureColumns = `id, chat_id, source, detail, created_at, notified_at`
+
+// RecordBackgroundFailure stores a failed background operation for the user's next failure digest
+func (db *DB) RecordBackgroundFailure(chatID int64, source, detail string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `INSERT INTO background_failures (chat_id, source, detail, created_at) VALUES ($1, $2, $3, NOW())`
+	if _, err := db.conn.Exec(query, chatID, source, detail); err != nil {
+		return fmt.Errorf("failed to record background failure: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatsDueForFailureDigest retrieves users with failures not reported yet that recorded their first one
+// before settledBefore and haven't received a digest since lastDigestBefore
+func (db *DB) GetChatsDueForFailureDigest(settledBefore, lastDigestBefore time.Time) ([]int64, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT f.chat_id FROM background_failures f
+	WHERE f.notified_at IS NULL
+	GROUP BY f.chat_id
+	HAVING MIN(f.created_at) <= $1
+	AND NOT EXISTS (
+		SELECT 1 FROM background_failures n
+		WHERE n.chat_id = f.chat_id AND n.notified_at > $2
+	)
+	ORDER BY f.chat_id
+	`
+
+	rows, err := db.conn.Query(query, settledBefore, lastDigestBefore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query failure digest chats: %w", err)
+	}
+	defer rows.Close()
+
+	var chatIDs []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, fmt.Errorf("failed to scan failure digest chat: %w", err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating failure digest chats: %w", err)
+	}
+
+	return chatIDs, nil
+}
+
+// GetPendingBackgroundFailures retrieves a user's failures not reported yet, oldest first
+func (db *DB) GetPendingBackgroundFailures(chatID int64) ([]*BackgroundFailure, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+backgroundFailureColumns+` FROM background_failures WHERE chat_id = $1 AND notified_at IS NULL ORDER BY id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query background failures: %w", err)
+	}
+	defer rows.Close()
+
+	var failures []*BackgroundFailure
+	for rows.Next() {
+		failure := &BackgroundFailure{}
+		if err := rows.Scan(&failure.ID, &failure.ChatID, &failure.Source, &failure.Detail, &failure.CreatedAt, &failure.NotifiedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan background failure: %w", err)
+		}
+		failures = append(failures, failure)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating background failures: %w", err)
+	}
+
+	return failures, nil
+}
+
+// MarkBackgroundFailuresNotified marks a user's failures up to and including upToID as reported
+func (db *DB) MarkBackgroundFailuresNotified(chatID, upToID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `UPDATE background_failures SET notified_at = NOW() WHERE chat_id = $1 AND id <= $2 AND notified_at IS NULL`
+	if _, err := db.conn.Exec(query, chatID, upToID); err != nil {
+		return fmt.Errorf("failed to mark background failures notified: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteNotifiedBackgroundFailures removes failures reported before the given time and returns how many were removed
+func (db *DB) DeleteNotifiedBackgroundFailures(before time.Time) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM background_failures WHERE notified_at IS NOT NULL AND notified_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete background failures: %w", err)
+	}
+
+	return result.RowsAffected()
+}
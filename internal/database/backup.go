@@ -0,0 +1,226 @@
+package database
+
+import (
+	"bufio"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Backup methods. A dump is JSON lines: a header, then one line per row of every table in
+// backupTables. Rows are restored by column name, so a dump taken before columns were added
+// restores with the columns' defaults.
+
+// backupVersion is the dump format written by Dump
+const backupVersion = 1
+
+// backupTables are the tables a dump holds, parents before the tables referencing them
+var backupTables = []string{
+	"users", "premium_user", "user_topup_log", "user_insights", "user_usage", "reset_log",
+	"subscription_change_log", "feature_flags", "trashed_files", "commit_log", "webhooks", "feeds",
+	"api_keys", "quota_alerts", "tenants", "tenant_members", "channel_routes", "canned_replies",
+	"background_failures", "activity_events", "daily_pins", "forum_topics", "weekly_changelogs",
+	"compose_sessions", "operation_pauses", "quiet_hours", "deferred_messages", "leaderboard_consents",
+	"streak_reminders",
+	"custom_file_templates", "chat_access", "history_compressions", "handover_codes",
+	"photo_hashes", "insight_reports",
+}
+
+// maxBackupLine bounds a single row of a dump
+const maxBackupLine = 64 * 1024 * 1024
+
+type backupHeader struct {
+	Version   int       `json:"msg2git_backup"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type backupRow struct {
+	Table string          `json:"table"`
+	Row   json.RawMessage `json:"row"`
+}
+
+// Dump writes every table to w, returning the number of rows written
+func (db *DB) Dump(w io.Writer) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	encoder := json.NewEncoder(w)
+	if err := encoder.Encode(backupHeader{Version: backupVersion, CreatedAt: time.Now().UTC()}); err != nil {
+		return 0, fmt.Errorf("failed to write backup header: %w", err)
+	}
+
+	count := 0
+	for _, table := range backupTables {
+		rows, err := db.conn.Query(`SELECT row_to_json(t)::text FROM ` + pq.QuoteIdentifier(table) + ` t`)
+		if err != nil {
+			return count, fmt.Errorf("failed to dump %s: %w", table, err)
+		}
+		for rows.Next() {
+			var row string
+			if err := rows.Scan(&row); err != nil {
+				rows.Close()
+				return count, fmt.Errorf("failed to scan %s row: %w", table, err)
+			}
+			if err := encoder.Encode(backupRow{Table: table, Row: json.RawMessage(row)}); err != nil {
+				rows.Close()
+				return count, fmt.Errorf("failed to write %s row: %w", table, err)
+			}
+			count++
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return count, fmt.Errorf("failed to dump %s: %w", table, err)
+		}
+	}
+
+	return count, nil
+}
+
+// restoreColumns returns the columns of row the table has, sorted
+func restoreColumns(row map[string]json.RawMessage, tableColumns map[string]bool) []string {
+	columns := make([]string, 0, len(row))
+	for column := range row {
+		if tableColumns[column] {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// Restore replaces the content of every backed up table with the dump read from r, in one
+// transaction. Returns the number of rows restored.
+func (db *DB) Restore(r io.Reader) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBackupLine)
+	if !scanner.Scan() {
+		return 0, fmt.Errorf("empty backup")
+	}
+	var header backupHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version == 0 {
+		return 0, fmt.Errorf("not a msg2git backup")
+	}
+	if header.Version > backupVersion {
+		return 0, fmt.Errorf("backup format %d is newer than this version supports", header.Version)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin restore: %w", err)
+	}
+	defer tx.Rollback()
+
+	columns := make(map[string]map[string]bool, len(backupTables))
+	quoted := make([]string, len(backupTables))
+	for i, table := range backupTables {
+		if columns[table], err = tableColumns(tx, table); err != nil {
+			return 0, err
+		}
+		quoted[i] = pq.QuoteIdentifier(table)
+	}
+	if _, err := tx.Exec(`TRUNCATE ` + strings.Join(quoted, ", ")); err != nil {
+		return 0, fmt.Errorf("failed to clear tables: %w", err)
+	}
+
+	count := 0
+	for scanner.Scan() {
+		var line backupRow
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return count, fmt.Errorf("failed to parse backup line %d: %w", count+2, err)
+		}
+		tableCols, ok := columns[line.Table]
+		if !ok {
+			continue // A table this version doesn't back up
+		}
+		var row map[string]json.RawMessage
+		if err := json.Unmarshal(line.Row, &row); err != nil {
+			return count, fmt.Errorf("failed to parse %s row: %w", line.Table, err)
+		}
+
+		rowColumns := restoreColumns(row, tableCols)
+		if len(rowColumns) == 0 {
+			continue
+		}
+		for i, column := range rowColumns {
+			rowColumns[i] = pq.QuoteIdentifier(column)
+		}
+		list := strings.Join(rowColumns, ", ")
+		table := pq.QuoteIdentifier(line.Table)
+		query := `INSERT INTO ` + table + ` (` + list + `) SELECT ` + list + ` FROM json_populate_record(NULL::` + table + `, $1)`
+		if _, err := tx.Exec(query, string(line.Row)); err != nil {
+			return count, fmt.Errorf("failed to restore %s row: %w", line.Table, err)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return count, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	for _, table := range backupTables {
+		if err := resetSequences(tx, table); err != nil {
+			return count, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return count, fmt.Errorf("failed to commit restore: %w", err)
+	}
+	return count, nil
+}
+
+// tableColumns returns the columns of table
+func tableColumns(tx *sql.Tx, table string) (map[string]bool, error) {
+	rows, err := tx.Query(`SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns of %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			return nil, fmt.Errorf("failed to scan column of %s: %w", table, err)
+		}
+		columns[column] = true
+	}
+	return columns, rows.Err()
+}
+
+// resetSequences moves the serial sequences of table past its restored rows
+func resetSequences(tx *sql.Tx, table string) error {
+	rows, err := tx.Query(`SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = $1 AND column_default LIKE 'nextval(%'`, table)
+	if err != nil {
+		return fmt.Errorf("failed to get sequences of %s: %w", table, err)
+	}
+	var serials []string
+	for rows.Next() {
+		var column string
+		if err := rows.Scan(&column); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan sequence of %s: %w", table, err)
+		}
+		serials = append(serials, column)
+	}
+	rows.Close()
+
+	for _, column := range serials {
+		query := `SELECT setval(pg_get_serial_sequence($1, $2), COALESCE((SELECT MAX(` + pq.QuoteIdentifier(column) + `) FROM ` + pq.QuoteIdentifier(table) + `), 0) + 1, false)`
+		if _, err := tx.Exec(query, table, column); err != nil {
+			return fmt.Errorf("failed to reset sequence of %s.%s: %w", table, column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestBackupTablesCoverSchema(t *testing.T) {
+	source, err := os.ReadFile("database.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backedUp := make(map[string]bool)
+	for _, table := range backupTables {
+		backedUp[table] = true
+	}
+	for _, match := range regexp.MustCompile(`CREATE TABLE IF NOT EXISTS (\w+)`).FindAllStringSubmatch(string(source), -1) {
+		if !backedUp[match[1]] {
+			t.Errorf("table %s is missing from backupTables", match[1])
+		}
+	}
+}
+
+func TestRestoreColumns(t *testing.T) {
+	row := map[string]json.RawMessage{"chat_id": json.RawMessage("1"), "removed": json.RawMessage("2"), "username": json.RawMessage(`"a"`)}
+	got := restoreColumns(row, map[string]bool{"chat_id": true, "username": true, "added": true})
+	if want := []string{"chat_id", "username"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("restoreColumns() = %v, want %v", got, want)
+	}
+}
+
+func TestDB_DumpAndRestore(t *testing.T) {
+	dsn := getTestDSN()
+	if dsn == "" {
+		t.Skip("Skipping database tests - no TEST_POSTGRES_DSN environment variable set")
+	}
+
+	db, err := NewDB(dsn, "")
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	defer db.Close()
+
+	chatID := int64(987650001)
+	db.DeleteUser(chatID)
+	if _, err := db.CreateUser(chatID, "backuptest"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	var dump bytes.Buffer
+	count, err := db.Dump(&dump)
+	if err != nil || count == 0 {
+		t.Fatalf("Dump() = %d, %v", count, err)
+	}
+	if !strings.Contains(dump.String(), `"backuptest"`) {
+		t.Error("dump is missing the user")
+	}
+
+	db.DeleteUser(chatID)
+	restored, err := db.Restore(bytes.NewReader(dump.Bytes()))
+	if err != nil || restored != count {
+		t.Fatalf("Restore() = %d, %v, want %d rows", restored, err, count)
+	}
+	user, err := db.GetUserByChatID(chatID)
+	if err != nil || user == nil || user.Username != "backuptest" {
+		t.Errorf("restored user = %+v, %v", user, err)
+	}
+	db.DeleteUser(chatID)
+}
@@ -0,0 +1,102 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Canned reply methods
+
+const cannedReplyColumns = `id, chat_id, name, body, created_at`
+
+// SaveCannedReply saves a canned reply, replacing the body of an existing reply with the same name
+func (db *DB) SaveCannedReply(chatID int64, name, body string) (*CannedReply, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO canned_replies (chat_id, name, body, created_at)
+	VALUES ($1, $2, $3, NOW())
+	ON CONFLICT (chat_id, name) DO UPDATE SET body = EXCLUDED.body
+	RETURNING ` + cannedReplyColumns
+
+	reply := &CannedReply{}
+	err := db.conn.QueryRow(query, chatID, name, body).Scan(&reply.ID, &reply.ChatID, &reply.Name, &reply.Body, &reply.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save canned reply: %w", err)
+	}
+
+	return reply, nil
+}
+
+// GetCannedReplies retrieves a user's canned replies in the order they were created
+func (db *DB) GetCannedReplies(chatID int64) ([]*CannedReply, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+cannedReplyColumns+` FROM canned_replies WHERE chat_id = $1 ORDER BY id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query canned replies: %w", err)
+	}
+	defer rows.Close()
+
+	var replies []*CannedReply
+	for rows.Next() {
+		reply := &CannedReply{}
+		if err := rows.Scan(&reply.ID, &reply.ChatID, &reply.Name, &reply.Body, &reply.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan canned reply: %w", err)
+		}
+		replies = append(replies, reply)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating canned replies: %w", err)
+	}
+
+	return replies, nil
+}
+
+// GetCannedReply retrieves a canned reply owned by chatID, nil if it doesn't exist
+func (db *DB) GetCannedReply(id, chatID int64) (*CannedReply, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	reply := &CannedReply{}
+	err := db.conn.QueryRow(`SELECT `+cannedReplyColumns+` FROM canned_replies WHERE id = $1 AND chat_id = $2`, id, chatID).Scan(
+		&reply.ID, &reply.ChatID, &reply.Name, &reply.Body, &reply.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get canned reply: %w", err)
+	}
+
+	return reply, nil
+}
+
+// DeleteCannedReply removes a user's canned reply by name
+func (db *DB) DeleteCannedReply(chatID int64, name string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM canned_replies WHERE chat_id = $1 AND name = $2`, chatID, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete canned reply: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("canned reply not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Channel ingestion routing methods
+
+const channelRouteColumns = `id, chat_id, channel_id, title, target_path, skip_bots, created_at`
+
+// SaveChannelRoute routes a channel's posts to targetPath, updating the route if chatID already owns it.
+// A channel routed by another user is not taken over.
+func (db *DB) SaveChannelRoute(chatID, channelID int64, title, targetPath string) (*ChannelRoute, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO channel_routes (chat_id, channel_id, title, target_path, created_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (channel_id) DO UPDATE SET title = EXCLUDED.title, target_path = EXCLUDED.target_path
+	WHERE channel_routes.chat_id = EXCLUDED.chat_id
+	RETURNING ` + channelRouteColumns
+
+	route := &ChannelRoute{}
+	err := db.conn.QueryRow(query, chatID, channelID, title, targetPath).Scan(
+		&route.ID, &route.ChatID, &route.ChannelID, &route.Title, &route.TargetPath, &route.SkipBots, &route.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("channel is already linked to another user")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save channel route: %w", err)
+	}
+
+	return route, nil
+}
+
+// GetChannelRoute retrieves the route of a channel, nil if the channel is not routed
+func (db *DB) GetChannelRoute(channelID int64) (*ChannelRoute, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	routes, err := db.queryChannelRoutes(`SELECT `+channelRouteColumns+` FROM channel_routes WHERE channel_id = $1`, channelID)
+	if err != nil || len(routes) == 0 {
+		return nil, err
+	}
+	return routes[0], nil
+}
+
+// GetChannelRoutes retrieves the channels a user routes to their repository
+func (db *DB) GetChannelRoutes(chatID int64) ([]*ChannelRoute, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	return db.queryChannelRoutes(`SELECT `+channelRouteColumns+` FROM channel_routes WHERE chat_id = $1 ORDER BY id`, chatID)
+}
+
+// UpdateChannelRouteSkipBots sets whether posts sent via or forwarded from bots are ignored
+func (db *DB) UpdateChannelRouteSkipBots(id, chatID int64, skipBots bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`UPDATE channel_routes SET skip_bots = $3 WHERE id = $1 AND chat_id = $2`, id, chatID, skipBots)
+	if err != nil {
+		return fmt.Errorf("failed to update channel route: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel route not found")
+	}
+
+	return nil
+}
+
+// DeleteChannelRoute removes a channel route owned by chatID
+func (db *DB) DeleteChannelRoute(id, chatID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM channel_routes WHERE id = $1 AND chat_id = $2`, id, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to delete channel route: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("channel route not found")
+	}
+
+	return nil
+}
+
+func (db *DB) queryChannelRoutes(query string, args ...interface{}) ([]*ChannelRoute, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query channel routes: %w", err)
+	}
+	defer rows.Close()
+
+	var routes []*ChannelRoute
+	for rows.Next() {
+		route := &ChannelRoute{}
+		err := rows.Scan(
+			&route.ID, &route.ChatID, &route.ChannelID, &route.Title, &route.TargetPath, &route.SkipBots, &route.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan channel route: %w", err)
+		}
+		routes = append(routes, route)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating channel routes: %w", err)
+	}
+
+	return routes, nil
+}
@@ -0,0 +1,75 @@
+package database
+
+import "fmt"
+
+// Chat access rule methods
+
+const chatAccessColumns = `chat_id, access, updated_by, updated_at`
+
+// SetChatAccess allows or blocks a chat, replacing an earlier rule for it
+func (db *DB) SetChatAccess(chatID int64, access string, updatedBy int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+	if access != ChatAccessAllow && access != ChatAccessBlock {
+		return fmt.Errorf("invalid chat access %q", access)
+	}
+
+	query := `
+	INSERT INTO chat_access (chat_id, access, updated_by, updated_at)
+	VALUES ($1, $2, $3, NOW())
+	ON CONFLICT (chat_id) DO UPDATE SET access = EXCLUDED.access, updated_by = EXCLUDED.updated_by, updated_at = NOW()
+	`
+	if _, err := db.conn.Exec(query, chatID, access, updatedBy); err != nil {
+		return fmt.Errorf("failed to set chat access: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatAccessRules retrieves all chat access rules, ordered by chat
+func (db *DB) GetChatAccessRules() ([]*ChatAccess, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT ` + chatAccessColumns + ` FROM chat_access ORDER BY chat_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query chat access rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []*ChatAccess
+	for rows.Next() {
+		rule := &ChatAccess{}
+		if err := rows.Scan(&rule.ChatID, &rule.Access, &rule.UpdatedBy, &rule.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan chat access rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating chat access rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// DeleteChatAccess removes the rule of a chat, returning whether it had one
+func (db *DB) DeleteChatAccess(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM chat_access WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete chat access: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
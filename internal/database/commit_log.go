@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Commit log methods
+
+// RecordCommit appends a commit to the user's commit log. repo is the owner/repo pushed to.
+func (db *DB) RecordCommit(chatID int64, repo, filename, commitSHA, commitURL string, fileSize int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO commit_log (chat_id, repo, filename, commit_sha, commit_url, file_size, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`
+
+	if _, err := db.conn.Exec(query, chatID, repo, filename, commitSHA, commitURL, fileSize); err != nil {
+		return fmt.Errorf("failed to record commit: %w", err)
+	}
+
+	return nil
+}
+
+// CountCommitsSince counts the user's commits to a file since the given time
+func (db *DB) CountCommitsSince(chatID int64, filename string, since time.Time) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT COUNT(*) FROM commit_log
+	WHERE chat_id = $1 AND filename = $2 AND created_at >= $3
+	`
+
+	var count int
+	if err := db.conn.QueryRow(query, chatID, filename, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountCommitsBetween counts the user's commits in [from, to)
+func (db *DB) CountCommitsBetween(chatID int64, from, to time.Time) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT COUNT(*) FROM commit_log
+	WHERE chat_id = $1 AND created_at >= $2 AND created_at < $3
+	`
+
+	var count int
+	if err := db.conn.QueryRow(query, chatID, from, to).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count commits: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetCommitsBetween retrieves the user's commits in [from, to), oldest first
+func (db *DB) GetCommitsBetween(chatID int64, from, to time.Time) ([]*CommitLogEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, repo, filename, commit_sha, commit_url, file_size, created_at
+	FROM commit_log
+	WHERE chat_id = $1 AND created_at >= $2 AND created_at < $3
+	ORDER BY created_at, id
+	`
+
+	rows, err := db.conn.Query(query, chatID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query commits: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*CommitLogEntry
+	for rows.Next() {
+		entry := &CommitLogEntry{}
+		if err := rows.Scan(
+			&entry.ID, &entry.ChatID, &entry.Repo, &entry.Filename, &entry.CommitSHA,
+			&entry.CommitURL, &entry.FileSize, &entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating commits: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetLastCommit retrieves the user's most recent commit, returns nil if there is none
+func (db *DB) GetLastCommit(chatID int64) (*CommitLogEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, repo, filename, commit_sha, commit_url, file_size, created_at
+	FROM commit_log
+	WHERE chat_id = $1
+	ORDER BY created_at DESC, id DESC
+	LIMIT 1
+	`
+
+	entry := &CommitLogEntry{}
+	err := db.conn.QueryRow(query, chatID).Scan(
+		&entry.ID, &entry.ChatID, &entry.Repo, &entry.Filename, &entry.CommitSHA,
+		&entry.CommitURL, &entry.FileSize, &entry.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get last commit: %w", err)
+	}
+
+	return entry, nil
+}
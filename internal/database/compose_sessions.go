@@ -0,0 +1,126 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Compose session methods. Expired sessions are never returned and are purged with
+// DeleteExpiredComposeSessions.
+
+const composeSessionColumns = `chat_id, parts, started_at, expires_at`
+
+// StartComposeSession starts an empty compose session for the user, replacing any previous one
+func (db *DB) StartComposeSession(chatID int64, expiresAt time.Time) (*ComposeSession, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO compose_sessions (chat_id, parts, started_at, expires_at)
+	VALUES ($1, '[]', NOW(), $2)
+	ON CONFLICT (chat_id) DO UPDATE SET parts = '[]', started_at = NOW(), expires_at = EXCLUDED.expires_at
+	RETURNING ` + composeSessionColumns
+
+	session := &ComposeSession{}
+	err := db.conn.QueryRow(query, chatID, expiresAt).Scan(&session.ChatID, &session.Parts, &session.StartedAt, &session.ExpiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start compose session: %w", err)
+	}
+
+	return session, nil
+}
+
+// GetComposeSession retrieves the user's compose session, nil if there is none or it expired
+func (db *DB) GetComposeSession(chatID int64) (*ComposeSession, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	session := &ComposeSession{}
+	err := db.conn.QueryRow(`SELECT `+composeSessionColumns+` FROM compose_sessions WHERE chat_id = $1 AND expires_at > NOW()`, chatID).Scan(
+		&session.ChatID, &session.Parts, &session.StartedAt, &session.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get compose session: %w", err)
+	}
+
+	return session, nil
+}
+
+// AppendComposePart adds a message to the user's compose session and extends it until expiresAt.
+// Returns the number of parts in the session, 0 if there is no session to add to.
+func (db *DB) AppendComposePart(chatID int64, part ComposePart, expiresAt time.Time) (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	data, err := json.Marshal([]ComposePart{part})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode compose part: %w", err)
+	}
+
+	// Appending in SQL keeps parts of messages handled concurrently
+	query := `
+	UPDATE compose_sessions SET parts = (parts::jsonb || $2::jsonb)::text, expires_at = $3
+	WHERE chat_id = $1 AND expires_at > NOW()
+	RETURNING jsonb_array_length(parts::jsonb)`
+
+	var count int
+	err = db.conn.QueryRow(query, chatID, string(data), expiresAt).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to append compose part: %w", err)
+	}
+
+	return count, nil
+}
+
+// DeleteComposeSession ends the user's compose session and returns it, nil if there was none or it expired
+func (db *DB) DeleteComposeSession(chatID int64) (*ComposeSession, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	session := &ComposeSession{}
+	err := db.conn.QueryRow(`DELETE FROM compose_sessions WHERE chat_id = $1 RETURNING `+composeSessionColumns, chatID).Scan(
+		&session.ChatID, &session.Parts, &session.StartedAt, &session.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete compose session: %w", err)
+	}
+	if !session.ExpiresAt.After(time.Now()) {
+		return nil, nil
+	}
+
+	return session, nil
+}
+
+// DeleteExpiredComposeSessions deletes the compose sessions expired before now and returns how many
+func (db *DB) DeleteExpiredComposeSessions(now time.Time) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM compose_sessions WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired compose sessions: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Daily pin methods
+
+const dailyPinColumns = `chat_id, message_id, last_pinned_at, created_at`
+
+// EnableDailyPin opts the user into the daily pinned summary
+func (db *DB) EnableDailyPin(chatID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `INSERT INTO daily_pins (chat_id, created_at) VALUES ($1, NOW()) ON CONFLICT (chat_id) DO NOTHING`
+	if _, err := db.conn.Exec(query, chatID); err != nil {
+		return fmt.Errorf("failed to enable daily pin: %w", err)
+	}
+
+	return nil
+}
+
+// DisableDailyPin opts the user out and returns the daily pin it had, nil if it wasn't enabled
+func (db *DB) DisableDailyPin(chatID int64) (*DailyPin, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	pin := &DailyPin{}
+	err := db.conn.QueryRow(`DELETE FROM daily_pins WHERE chat_id = $1 RETURNING `+dailyPinColumns, chatID).Scan(
+		&pin.ChatID, &pin.MessageID, &pin.LastPinnedAt, &pin.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to disable daily pin: %w", err)
+	}
+
+	return pin, nil
+}
+
+// GetDailyPin retrieves the user's daily pin, nil if it isn't enabled
+func (db *DB) GetDailyPin(chatID int64) (*DailyPin, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	pin := &DailyPin{}
+	err := db.conn.QueryRow(`SELECT `+dailyPinColumns+` FROM daily_pins WHERE chat_id = $1`, chatID).Scan(
+		&pin.ChatID, &pin.MessageID, &pin.LastPinnedAt, &pin.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily pin: %w", err)
+	}
+
+	return pin, nil
+}
+
+// GetDueDailyPins retrieves the daily pins not posted since the given time
+func (db *DB) GetDueDailyPins(before time.Time) ([]*DailyPin, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+dailyPinColumns+` FROM daily_pins WHERE last_pinned_at IS NULL OR last_pinned_at < $1 ORDER BY chat_id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily pins: %w", err)
+	}
+	defer rows.Close()
+
+	var pins []*DailyPin
+	for rows.Next() {
+		pin := &DailyPin{}
+		if err := rows.Scan(&pin.ChatID, &pin.MessageID, &pin.LastPinnedAt, &pin.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan daily pin: %w", err)
+		}
+		pins = append(pins, pin)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating daily pins: %w", err)
+	}
+
+	return pins, nil
+}
+
+// UpdateDailyPin records the summary message pinned for the user
+func (db *DB) UpdateDailyPin(chatID int64, messageID int, pinnedAt time.Time) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`UPDATE daily_pins SET message_id = $2, last_pinned_at = $3 WHERE chat_id = $1`, chatID, messageID, pinnedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update daily pin: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("daily pin not enabled")
+	}
+
+	return nil
+}
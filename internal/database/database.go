@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -10,10 +11,27 @@ import (
 )
 
 type DB struct {
-	conn              *sql.DB
+	conn              *timedConn
 	encryptionManager *EncryptionManager
 }
 
+// Ping checks that the database at dsn accepts connections, without initializing any tables
+func Ping(dsn string) error {
+	conn, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return fmt.Errorf("failed to open database connection: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return nil
+}
+
 // NewDB creates a new database connection
 func NewDB(dsn, tokenPassword string) (*DB, error) {
 	if dsn == "" {
@@ -39,7 +57,7 @@ func NewDB(dsn, tokenPassword string) (*DB, error) {
 	}
 
 	db := &DB{
-		conn:              conn,
+		conn:              &timedConn{conn},
 		encryptionManager: encryptionManager,
 	}
 
@@ -153,6 +171,270 @@ func (db *DB) initTables() error {
 	CREATE INDEX IF NOT EXISTS idx_subscription_change_log_uid ON subscription_change_log(uid);
 	CREATE INDEX IF NOT EXISTS idx_subscription_change_log_subscription_id ON subscription_change_log(subscription_id);
 	CREATE INDEX IF NOT EXISTS idx_subscription_change_log_created_at ON subscription_change_log(created_at);
+
+	CREATE TABLE IF NOT EXISTS feature_flags (
+		name VARCHAR(100) PRIMARY KEY,
+		enabled BOOLEAN NOT NULL DEFAULT FALSE,
+		percentage INTEGER NOT NULL DEFAULT 0,
+		min_tier INTEGER NOT NULL DEFAULT 0,
+		user_ids TEXT NOT NULL DEFAULT '[]',
+		description TEXT NOT NULL DEFAULT '',
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS trashed_files (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		repo VARCHAR(255) NOT NULL DEFAULT '',
+		original_path TEXT NOT NULL,
+		trash_path TEXT NOT NULL,
+		trashed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_trashed_files_chat_id ON trashed_files(chat_id);
+	CREATE INDEX IF NOT EXISTS idx_trashed_files_expires_at ON trashed_files(expires_at);
+
+	CREATE TABLE IF NOT EXISTS commit_log (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		filename TEXT NOT NULL,
+		commit_sha VARCHAR(64) NOT NULL,
+		commit_url TEXT NOT NULL DEFAULT '',
+		file_size BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_commit_log_chat_id_created_at ON commit_log(chat_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		url TEXT NOT NULL,
+		secret TEXT NOT NULL,
+		events TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhooks_chat_id ON webhooks(chat_id);
+
+	CREATE TABLE IF NOT EXISTS feeds (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		url TEXT NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		summarize BOOLEAN NOT NULL DEFAULT FALSE,
+		last_item_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		last_fetched_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		UNIQUE(chat_id, url)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_feeds_last_fetched_at ON feeds(last_fetched_at);
+
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT UNIQUE NOT NULL,
+		key_hash VARCHAR(64) UNIQUE NOT NULL,
+		key_prefix VARCHAR(16) NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		last_used_at TIMESTAMP WITH TIME ZONE
+	);
+
+	CREATE TABLE IF NOT EXISTS quota_alerts (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		metric VARCHAR(20) NOT NULL,
+		threshold INTEGER NOT NULL,
+		quota_limit BIGINT NOT NULL,
+		sent_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		UNIQUE(chat_id, metric, threshold, quota_limit)
+	);
+
+	CREATE TABLE IF NOT EXISTS tenants (
+		id SERIAL PRIMARY KEY,
+		name VARCHAR(64) UNIQUE NOT NULL,
+		disk_quota_mb DECIMAL(10,2) NOT NULL DEFAULT 0.00,
+		token_quota BIGINT NOT NULL DEFAULT 0,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS tenant_members (
+		chat_id BIGINT PRIMARY KEY,
+		tenant_id INTEGER NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+		is_admin BOOLEAN NOT NULL DEFAULT FALSE,
+		added_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_tenant_members_tenant_id ON tenant_members(tenant_id);
+
+	CREATE TABLE IF NOT EXISTS channel_routes (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		channel_id BIGINT UNIQUE NOT NULL,
+		title TEXT NOT NULL DEFAULT '',
+		target_path TEXT NOT NULL,
+		skip_bots BOOLEAN NOT NULL DEFAULT TRUE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_channel_routes_chat_id ON channel_routes(chat_id);
+
+	CREATE TABLE IF NOT EXISTS canned_replies (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		name VARCHAR(32) NOT NULL,
+		body TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		UNIQUE(chat_id, name)
+	);
+
+	CREATE TABLE IF NOT EXISTS background_failures (
+		id BIGSERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		source VARCHAR(32) NOT NULL,
+		detail TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		notified_at TIMESTAMP WITH TIME ZONE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_background_failures_chat_id ON background_failures(chat_id);
+
+	CREATE TABLE IF NOT EXISTS activity_events (
+		id BIGSERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		kind VARCHAR(16) NOT NULL,
+		value TEXT NOT NULL DEFAULT '',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_activity_events_chat_created ON activity_events(chat_id, created_at);
+
+	CREATE TABLE IF NOT EXISTS daily_pins (
+		chat_id BIGINT PRIMARY KEY,
+		message_id INTEGER NOT NULL DEFAULT 0,
+		last_pinned_at TIMESTAMP WITH TIME ZONE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS forum_topics (
+		chat_id BIGINT NOT NULL,
+		thread_id INTEGER NOT NULL,
+		name VARCHAR(128) NOT NULL,
+		file_path VARCHAR(500) NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		PRIMARY KEY (chat_id, thread_id)
+	);
+
+	CREATE TABLE IF NOT EXISTS weekly_changelogs (
+		chat_id BIGINT PRIMARY KEY,
+		issue_number INTEGER NOT NULL DEFAULT 0,
+		week_start TIMESTAMP WITH TIME ZONE,
+		last_posted_at TIMESTAMP WITH TIME ZONE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS compose_sessions (
+		chat_id BIGINT PRIMARY KEY,
+		parts TEXT NOT NULL DEFAULT '[]',
+		started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_compose_sessions_expires_at ON compose_sessions(expires_at);
+
+	CREATE TABLE IF NOT EXISTS operation_pauses (
+		chat_id BIGINT PRIMARY KEY,
+		reason TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS quiet_hours (
+		chat_id BIGINT PRIMARY KEY,
+		start_minute INTEGER NOT NULL,
+		end_minute INTEGER NOT NULL,
+		timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS deferred_messages (
+		id SERIAL PRIMARY KEY,
+		chat_id BIGINT NOT NULL,
+		kind VARCHAR(50) NOT NULL,
+		text TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_deferred_messages_chat_id ON deferred_messages(chat_id);
+
+	CREATE TABLE IF NOT EXISTS leaderboard_consents (
+		chat_id BIGINT PRIMARY KEY,
+		display_name VARCHAR(64) NOT NULL,
+		consented_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS streak_reminders (
+		chat_id BIGINT PRIMARY KEY,
+		remind_minute INTEGER NOT NULL,
+		timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+		last_sent_on DATE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS custom_file_templates (
+		chat_id BIGINT NOT NULL,
+		filename VARCHAR(500) NOT NULL,
+		template TEXT NOT NULL,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		PRIMARY KEY (chat_id, filename)
+	);
+
+	CREATE TABLE IF NOT EXISTS chat_access (
+		chat_id BIGINT PRIMARY KEY,
+		access VARCHAR(10) NOT NULL,
+		updated_by BIGINT NOT NULL DEFAULT 0,
+		updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS history_compressions (
+		chat_id BIGINT PRIMARY KEY,
+		older_than_days INTEGER NOT NULL,
+		pull_number INTEGER NOT NULL DEFAULT 0,
+		last_run_at TIMESTAMP WITH TIME ZONE,
+		consented_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
+
+	CREATE TABLE IF NOT EXISTS handover_codes (
+		code_hash VARCHAR(64) PRIMARY KEY,
+		chat_id BIGINT UNIQUE NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+	);
+
+	CREATE TABLE IF NOT EXISTS photo_hashes (
+		chat_id BIGINT NOT NULL,
+		repo VARCHAR(255) NOT NULL DEFAULT '',
+		content_hash VARCHAR(64) NOT NULL,
+		url TEXT NOT NULL,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+		PRIMARY KEY (chat_id, repo, content_hash)
+	);
+
+	CREATE TABLE IF NOT EXISTS insight_reports (
+		chat_id BIGINT PRIMARY KEY,
+		period VARCHAR(10) NOT NULL DEFAULT 'weekly',
+		commit_to_repo BOOLEAN NOT NULL DEFAULT FALSE,
+		commit_cnt BIGINT NOT NULL DEFAULT 0,
+		issue_cnt BIGINT NOT NULL DEFAULT 0,
+		issue_close_cnt BIGINT NOT NULL DEFAULT 0,
+		token_input BIGINT NOT NULL DEFAULT 0,
+		token_output BIGINT NOT NULL DEFAULT 0,
+		repo_size DOUBLE PRECISION NOT NULL DEFAULT 0,
+		last_sent_at TIMESTAMP WITH TIME ZONE,
+		created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+	);
 	`
 
 	if _, err := db.conn.Exec(query); err != nil {
@@ -165,6 +447,24 @@ func (db *DB) initTables() error {
 	ALTER TABLE users ADD COLUMN IF NOT EXISTS llm_switch BOOLEAN NOT NULL DEFAULT FALSE;
 	ALTER TABLE users ADD COLUMN IF NOT EXISTS llm_multimodal_switch BOOLEAN NOT NULL DEFAULT TRUE;
 	ALTER TABLE users ADD COLUMN IF NOT EXISTS committer VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS private_repo VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS github_api_url VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS issue_archive_days INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS issue_archive_yearly BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS sync_commit_mode VARCHAR(20) NOT NULL DEFAULT 'squash';
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS noreply_email BOOLEAN NOT NULL DEFAULT TRUE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS github_login VARCHAR(100) NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS github_user_id BIGINT NOT NULL DEFAULT 0;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS bot_committer BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS source_footer BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS llm_task_models TEXT NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS mood_tracking BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS commit_branch VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS readme_toc BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS auto_route BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS plain_mode BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE users ADD COLUMN IF NOT EXISTS note_key TEXT NOT NULL DEFAULT '';
+	ALTER TABLE commit_log ADD COLUMN IF NOT EXISTS repo VARCHAR(255) NOT NULL DEFAULT '';
 	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS reset_cnt BIGINT NOT NULL DEFAULT 0;
 	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS issue_cmt_cnt BIGINT NOT NULL DEFAULT 0;
 	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS issue_close_cnt BIGINT NOT NULL DEFAULT 0;
@@ -172,17 +472,37 @@ func (db *DB) initTables() error {
 	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS insight_cmd_cnt BIGINT NOT NULL DEFAULT 0;
 	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS token_input BIGINT NOT NULL DEFAULT 0;
 	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS token_output BIGINT NOT NULL DEFAULT 0;
+	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS file_cnt BIGINT NOT NULL DEFAULT 0;
+	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS streak_days INTEGER NOT NULL DEFAULT 0;
+	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS last_commit_date DATE;
+	ALTER TABLE user_insights ADD COLUMN IF NOT EXISTS best_streak INTEGER NOT NULL DEFAULT 0;
 	ALTER TABLE user_usage ADD COLUMN IF NOT EXISTS token_input BIGINT NOT NULL DEFAULT 0;
 	ALTER TABLE user_usage ADD COLUMN IF NOT EXISTS token_output BIGINT NOT NULL DEFAULT 0;
+	ALTER TABLE user_usage ADD COLUMN IF NOT EXISTS file_cnt BIGINT NOT NULL DEFAULT 0;
 	ALTER TABLE premium_user ADD COLUMN IF NOT EXISTS subscription_id VARCHAR(255) NOT NULL DEFAULT '';
 	ALTER TABLE premium_user ADD COLUMN IF NOT EXISTS customer_id VARCHAR(255) NOT NULL DEFAULT '';
 	ALTER TABLE premium_user ADD COLUMN IF NOT EXISTS billing_period VARCHAR(50) NOT NULL DEFAULT '';
 	ALTER TABLE premium_user ADD COLUMN IF NOT EXISTS is_subscription BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE premium_user ADD COLUMN IF NOT EXISTS is_trial BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE premium_user ADD COLUMN IF NOT EXISTS trial_used BOOLEAN NOT NULL DEFAULT FALSE;
+	ALTER TABLE premium_user ADD COLUMN IF NOT EXISTS expiry_notice VARCHAR(20) NOT NULL DEFAULT '';
 	ALTER TABLE user_topup_log ADD COLUMN IF NOT EXISTS service VARCHAR(50) NOT NULL DEFAULT 'COFFEE';
 	ALTER TABLE user_topup_log ADD COLUMN IF NOT EXISTS transaction_id VARCHAR(255) NOT NULL DEFAULT '';
 	ALTER TABLE user_topup_log ADD COLUMN IF NOT EXISTS invoice_id VARCHAR(255) NOT NULL DEFAULT '';
 	ALTER TABLE reset_log ADD COLUMN IF NOT EXISTS token_input BIGINT NOT NULL DEFAULT 0;
 	ALTER TABLE reset_log ADD COLUMN IF NOT EXISTS token_output BIGINT NOT NULL DEFAULT 0;
+	ALTER TABLE photo_hashes ADD COLUMN IF NOT EXISTS repo VARCHAR(255) NOT NULL DEFAULT '';
+	ALTER TABLE trashed_files ADD COLUMN IF NOT EXISTS repo VARCHAR(255) NOT NULL DEFAULT '';
+	DO $$
+	BEGIN
+		-- Photo URLs are per repository, rows saved before that don't say which one they belong to
+		IF NOT EXISTS (SELECT 1 FROM information_schema.key_column_usage
+			WHERE table_name = 'photo_hashes' AND constraint_name = 'photo_hashes_pkey' AND column_name = 'repo') THEN
+			DELETE FROM photo_hashes WHERE repo = '';
+			ALTER TABLE photo_hashes DROP CONSTRAINT IF EXISTS photo_hashes_pkey;
+			ALTER TABLE photo_hashes ADD PRIMARY KEY (chat_id, repo, content_hash);
+		END IF;
+	END $$;
 	`
 
 	if _, err := db.conn.Exec(alterQuery); err != nil {
@@ -199,7 +519,7 @@ func (db *DB) GetUserByChatID(chatID int64) (*User, error) {
 	}
 
 	query := `
-	SELECT id, chat_id, username, github_token, github_repo, llm_token, llm_switch, llm_multimodal_switch, custom_files, committer, created_at, updated_at
+	SELECT id, chat_id, username, github_token, github_repo, llm_token, llm_switch, llm_multimodal_switch, custom_files, committer, private_repo, github_api_url, issue_archive_days, issue_archive_yearly, sync_commit_mode, noreply_email, github_login, github_user_id, bot_committer, source_footer, llm_task_models, mood_tracking, commit_branch, readme_toc, auto_route, plain_mode, created_at, updated_at
 	FROM users 
 	WHERE chat_id = $1
 	`
@@ -209,7 +529,7 @@ func (db *DB) GetUserByChatID(chatID int64) (*User, error) {
 
 	err := db.conn.QueryRow(query, chatID).Scan(
 		&user.ID, &user.ChatId, &user.Username,
-		&encryptedGitHubToken, &user.GitHubRepo, &encryptedLLMToken, &user.LLMSwitch, &user.LLMMultimodalSwitch, &user.CustomFiles, &user.Committer,
+		&encryptedGitHubToken, &user.GitHubRepo, &encryptedLLMToken, &user.LLMSwitch, &user.LLMMultimodalSwitch, &user.CustomFiles, &user.Committer, &user.PrivateRepo, &user.GitHubAPIURL, &user.IssueArchiveDays, &user.IssueArchiveYearly, &user.SyncCommitMode, &user.NoreplyEmail, &user.GitHubLogin, &user.GitHubUserID, &user.BotCommitter, &user.SourceFooter, &user.LLMTaskModels, &user.MoodTracking, &user.CommitBranch, &user.ReadmeTOC, &user.AutoRoute, &user.PlainMode,
 		&user.CreatedAt, &user.UpdatedAt,
 	)
 
@@ -265,7 +585,7 @@ func (db *DB) CreateUser(chatID int64, username string) (*User, error) {
 	query := `
 	INSERT INTO users (chat_id, username, created_at, updated_at)
 	VALUES ($1, $2, $3, $4)
-	RETURNING id, chat_id, username, github_token, github_repo, llm_token, llm_switch, llm_multimodal_switch, custom_files, committer, created_at, updated_at
+	RETURNING id, chat_id, username, github_token, github_repo, llm_token, llm_switch, llm_multimodal_switch, custom_files, committer, private_repo, github_api_url, issue_archive_days, issue_archive_yearly, sync_commit_mode, noreply_email, github_login, github_user_id, bot_committer, source_footer, llm_task_models, mood_tracking, commit_branch, readme_toc, auto_route, plain_mode, created_at, updated_at
 	`
 
 	user := &User{}
@@ -273,7 +593,7 @@ func (db *DB) CreateUser(chatID int64, username string) (*User, error) {
 
 	err := db.conn.QueryRow(query, chatID, username, now, now).Scan(
 		&user.ID, &user.ChatId, &user.Username,
-		&encryptedGitHubToken, &user.GitHubRepo, &encryptedLLMToken, &user.LLMSwitch, &user.LLMMultimodalSwitch, &user.CustomFiles, &user.Committer,
+		&encryptedGitHubToken, &user.GitHubRepo, &encryptedLLMToken, &user.LLMSwitch, &user.LLMMultimodalSwitch, &user.CustomFiles, &user.Committer, &user.PrivateRepo, &user.GitHubAPIURL, &user.IssueArchiveDays, &user.IssueArchiveYearly, &user.SyncCommitMode, &user.NoreplyEmail, &user.GitHubLogin, &user.GitHubUserID, &user.BotCommitter, &user.SourceFooter, &user.LLMTaskModels, &user.MoodTracking, &user.CommitBranch, &user.ReadmeTOC, &user.AutoRoute, &user.PlainMode,
 		&user.CreatedAt, &user.UpdatedAt,
 	)
 
@@ -413,7 +733,8 @@ func (db *DB) GetPremiumUser(uid int64) (*PremiumUser, error) {
 	}
 
 	query := `
-	SELECT id, uid, username, level, expire_at, subscription_id, customer_id, billing_period, is_subscription, created_at
+	SELECT id, uid, username, level, expire_at, subscription_id, customer_id, billing_period, is_subscription,
+		is_trial, trial_used, expiry_notice, created_at
 	FROM premium_user 
 	WHERE uid = $1
 	`
@@ -423,6 +744,7 @@ func (db *DB) GetPremiumUser(uid int64) (*PremiumUser, error) {
 		&premiumUser.ID, &premiumUser.UID, &premiumUser.Username,
 		&premiumUser.Level, &premiumUser.ExpireAt,
 		&premiumUser.SubscriptionID, &premiumUser.CustomerID, &premiumUser.BillingPeriod, &premiumUser.IsSubscription,
+		&premiumUser.IsTrial, &premiumUser.TrialUsed, &premiumUser.ExpiryNotice,
 		&premiumUser.CreatedAt,
 	)
 
@@ -446,7 +768,7 @@ func (db *DB) CreatePremiumUser(uid int64, username string, level int, expireAt
 	query := `
 	INSERT INTO premium_user (uid, username, level, expire_at, created_at, subscription_id, customer_id, billing_period, is_subscription)
 	VALUES ($1, $2, $3, $4, $5, '', '', '', false)
-	ON CONFLICT (uid) DO UPDATE SET username = $2, level = $3, expire_at = $4, is_subscription = false
+	ON CONFLICT (uid) DO UPDATE SET username = $2, level = $3, expire_at = $4, is_subscription = false, is_trial = false, expiry_notice = ''
 	RETURNING id, uid, username, level, expire_at, subscription_id, customer_id, billing_period, is_subscription, created_at
 	`
 
@@ -526,76 +848,531 @@ func (db *DB) CreateSubscriptionPremiumUser(uid int64, username string, level in
 		})
 	}
 
-	// For subscription creation, use default expiry (will be updated when first invoice is paid)
-	// Use a temporary default of 30 days to ensure subscription is active initially
-	defaultExpireAt := now.AddDate(0, 0, 30).Unix() // 30 days from now as default
+	// For subscription creation, use default expiry (will be updated when first invoice is paid)
+	// Use a temporary default of 30 days to ensure subscription is active initially
+	defaultExpireAt := now.AddDate(0, 0, 30).Unix() // 30 days from now as default
+
+	query := `
+	INSERT INTO premium_user (uid, username, level, expire_at, subscription_id, customer_id, billing_period, is_subscription, created_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8)
+	ON CONFLICT (uid) DO UPDATE SET 
+		username = $2, 
+		level = $3, 
+		subscription_id = $5, 
+		customer_id = $6, 
+		billing_period = $7, 
+		is_subscription = true,
+		is_trial = false,
+		expiry_notice = '',
+		expire_at = CASE 
+			WHEN premium_user.expire_at > $4 THEN premium_user.expire_at 
+			ELSE $4 
+		END
+	RETURNING id, uid, username, level, expire_at, subscription_id, customer_id, billing_period, is_subscription, created_at
+	`
+
+	premiumUser := &PremiumUser{}
+	err = db.conn.QueryRow(query, uid, username, level, defaultExpireAt, subscriptionID, customerID, billingPeriod, now).Scan(
+		&premiumUser.ID, &premiumUser.UID, &premiumUser.Username,
+		&premiumUser.Level, &premiumUser.ExpireAt,
+		&premiumUser.SubscriptionID, &premiumUser.CustomerID, &premiumUser.BillingPeriod, &premiumUser.IsSubscription,
+		&premiumUser.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription premium user: %w", err)
+	}
+
+	logger.Info("Created subscription premium user", map[string]interface{}{
+		"uid":             uid,
+		"username":        username,
+		"level":           level,
+		"subscription_id": subscriptionID,
+		"billing_period":  billingPeriod,
+	})
+
+	result := &SubscriptionResult{
+		PremiumUser:            premiumUser,
+		ReplacedSubscriptionID: replacedSubscriptionID,
+	}
+
+	logger.Info("Returning subscription result", map[string]interface{}{
+		"uid":                      uid,
+		"replaced_subscription_id": result.ReplacedSubscriptionID,
+		"has_replacement":          result.ReplacedSubscriptionID != "",
+	})
+
+	return result, nil
+}
+
+// CancelSubscriptionPremiumUser removes subscription data but keeps the user as free tier
+func (db *DB) CancelSubscriptionPremiumUser(uid int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE premium_user 
+	SET level = 0, subscription_id = '', billing_period = '', is_subscription = false
+	WHERE uid = $1 AND is_subscription = true
+	`
+
+	result, err := db.conn.Exec(query, uid)
+	if err != nil {
+		return fmt.Errorf("failed to cancel subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no active subscription found for user")
+	}
+
+	logger.Info("Cancelled subscription for user", map[string]interface{}{
+		"uid": uid,
+	})
+	return nil
+}
+
+// RenewSubscriptionPremiumUser extends the expiry date for a subscription user
+func (db *DB) RenewSubscriptionPremiumUser(uid int64, billingPeriod string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	// Calculate new expiry date based on billing period
+	now := time.Now()
+	var newExpireAt int64
+	switch billingPeriod {
+	case "monthly":
+		newExpireAt = now.AddDate(0, 1, 0).Unix() // 1 month from now
+	case "annually":
+		newExpireAt = now.AddDate(1, 0, 0).Unix() // 1 year from now
+	default:
+		// Default to monthly if billing period is unknown
+		newExpireAt = now.AddDate(0, 1, 0).Unix()
+	}
+
+	query := `
+	UPDATE premium_user 
+	SET expire_at = $2, expiry_notice = ''
+	WHERE uid = $1 AND is_subscription = true
+	`
+
+	result, err := db.conn.Exec(query, uid, newExpireAt)
+	if err != nil {
+		return fmt.Errorf("failed to renew subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no active subscription found for user")
+	}
+
+	logger.Info("Renewed subscription for user", map[string]interface{}{
+		"uid":            uid,
+		"billing_period": billingPeriod,
+		"new_expire_at":  newExpireAt,
+	})
+	return nil
+}
+
+// SetSubscriptionExpiry sets the expiry date for a subscription user to a specific timestamp
+func (db *DB) SetSubscriptionExpiry(uid int64, expireAt int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE premium_user 
+	SET expire_at = $2, expiry_notice = ''
+	WHERE uid = $1 AND is_subscription = true
+	`
+
+	result, err := db.conn.Exec(query, uid, expireAt)
+	if err != nil {
+		return fmt.Errorf("failed to set subscription expiry: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("no active subscription found for user")
+	}
+
+	logger.Info("Set subscription expiry for user", map[string]interface{}{
+		"uid":         uid,
+		"expire_at":   expireAt,
+		"expire_time": time.Unix(expireAt, 0).Format("2006-01-02 15:04:05"),
+	})
+	return nil
+}
+
+// UpdateUserCommitter updates the committer field for any user
+func (db *DB) UpdateUserCommitter(chatID int64, committer string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET committer = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, committer, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update committer: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user committer", map[string]interface{}{
+		"chat_id":   chatID,
+		"committer": committer,
+	})
+
+	return nil
+}
+
+// UpdateUserNoreplyEmail sets whether GitHub OAuth sets the committer to the user's GitHub noreply address
+func (db *DB) UpdateUserNoreplyEmail(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET noreply_email = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, enabled, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update noreply email setting: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user noreply email setting", map[string]interface{}{
+		"chat_id":       chatID,
+		"noreply_email": enabled,
+	})
+
+	return nil
+}
+
+// UpdateUserGitHubAccount stores the GitHub account the user's token belongs to, an empty login unlinks it
+func (db *DB) UpdateUserGitHubAccount(chatID int64, login string, githubUserID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET github_login = $2, github_user_id = $3, updated_at = $4
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, login, githubUserID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update GitHub account: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user GitHub account", map[string]interface{}{
+		"chat_id":      chatID,
+		"github_login": login,
+	})
+
+	return nil
+}
+
+// UpdateUserBotCommitter sets whether the user's commits are committed by the bot identity
+func (db *DB) UpdateUserBotCommitter(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET bot_committer = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, enabled, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update bot committer setting: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user bot committer setting", map[string]interface{}{
+		"chat_id":       chatID,
+		"bot_committer": enabled,
+	})
+
+	return nil
+}
+
+// UpdateUserSourceFooter sets whether notes end with a link back to the Telegram message
+func (db *DB) UpdateUserSourceFooter(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET source_footer = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, enabled, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update source footer setting: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user source footer setting", map[string]interface{}{
+		"chat_id":       chatID,
+		"source_footer": enabled,
+	})
+
+	return nil
+}
+
+// UpdateUserMoodTracking sets whether notes are tagged with a mood detected by the LLM
+func (db *DB) UpdateUserMoodTracking(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET mood_tracking = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, enabled, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update mood tracking setting: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user mood tracking setting", map[string]interface{}{
+		"chat_id":       chatID,
+		"mood_tracking": enabled,
+	})
+
+	return nil
+}
+
+// UpdateUserCommitBranch sets the branch notes are committed to, "" for the repository's default branch
+func (db *DB) UpdateUserCommitBranch(chatID int64, branch string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET commit_branch = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, branch, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update commit branch: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user commit branch", map[string]interface{}{
+		"chat_id":       chatID,
+		"commit_branch": branch,
+	})
+
+	return nil
+}
+
+// UpdateUserReadmeTOC turns maintenance of the README table of contents in the notes repository on or off
+func (db *DB) UpdateUserReadmeTOC(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET readme_toc = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, enabled, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update README table of contents setting: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user README table of contents setting", map[string]interface{}{
+		"chat_id":    chatID,
+		"readme_toc": enabled,
+	})
+
+	return nil
+}
+
+// UpdateUserAutoRoute sets whether notes are committed to the custom file the LLM picks for them
+func (db *DB) UpdateUserAutoRoute(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE users 
+	SET auto_route = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, enabled, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update auto-route setting: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user auto-route setting", map[string]interface{}{
+		"chat_id":    chatID,
+		"auto_route": enabled,
+	})
+
+	return nil
+}
+
+// UpdateUserPlainMode sets whether the bot replies in plain text, for screen readers
+func (db *DB) UpdateUserPlainMode(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
 
 	query := `
-	INSERT INTO premium_user (uid, username, level, expire_at, subscription_id, customer_id, billing_period, is_subscription, created_at)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, true, $8)
-	ON CONFLICT (uid) DO UPDATE SET 
-		username = $2, 
-		level = $3, 
-		subscription_id = $5, 
-		customer_id = $6, 
-		billing_period = $7, 
-		is_subscription = true,
-		expire_at = CASE 
-			WHEN premium_user.expire_at > $4 THEN premium_user.expire_at 
-			ELSE $4 
-		END
-	RETURNING id, uid, username, level, expire_at, subscription_id, customer_id, billing_period, is_subscription, created_at
+	UPDATE users 
+	SET plain_mode = $2, updated_at = $3
+	WHERE chat_id = $1
 	`
 
-	premiumUser := &PremiumUser{}
-	err = db.conn.QueryRow(query, uid, username, level, defaultExpireAt, subscriptionID, customerID, billingPeriod, now).Scan(
-		&premiumUser.ID, &premiumUser.UID, &premiumUser.Username,
-		&premiumUser.Level, &premiumUser.ExpireAt,
-		&premiumUser.SubscriptionID, &premiumUser.CustomerID, &premiumUser.BillingPeriod, &premiumUser.IsSubscription,
-		&premiumUser.CreatedAt,
-	)
-
+	result, err := db.conn.Exec(query, chatID, enabled, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to create subscription premium user: %w", err)
+		return fmt.Errorf("failed to update plain mode setting: %w", err)
 	}
 
-	logger.Info("Created subscription premium user", map[string]interface{}{
-		"uid":             uid,
-		"username":        username,
-		"level":           level,
-		"subscription_id": subscriptionID,
-		"billing_period":  billingPeriod,
-	})
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
 
-	result := &SubscriptionResult{
-		PremiumUser:            premiumUser,
-		ReplacedSubscriptionID: replacedSubscriptionID,
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
 	}
 
-	logger.Info("Returning subscription result", map[string]interface{}{
-		"uid":                      uid,
-		"replaced_subscription_id": result.ReplacedSubscriptionID,
-		"has_replacement":          result.ReplacedSubscriptionID != "",
+	logger.Info("Updated user plain mode setting", map[string]interface{}{
+		"chat_id":    chatID,
+		"plain_mode": enabled,
 	})
 
-	return result, nil
+	return nil
 }
 
-// CancelSubscriptionPremiumUser removes subscription data but keeps the user as free tier
-func (db *DB) CancelSubscriptionPremiumUser(uid int64) error {
+// UpdateUserLLMTaskModels sets the models of the user's personal LLM per task, "" for the default model
+func (db *DB) UpdateUserLLMTaskModels(chatID int64, taskModels string) error {
 	if db == nil {
 		return fmt.Errorf("database not configured")
 	}
 
 	query := `
-	UPDATE premium_user 
-	SET level = 0, subscription_id = '', billing_period = '', is_subscription = false
-	WHERE uid = $1 AND is_subscription = true
+	UPDATE users 
+	SET llm_task_models = $2, updated_at = $3
+	WHERE chat_id = $1
 	`
 
-	result, err := db.conn.Exec(query, uid)
+	result, err := db.conn.Exec(query, chatID, taskModels, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to cancel subscription: %w", err)
+		return fmt.Errorf("failed to update LLM task models: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -604,43 +1381,66 @@ func (db *DB) CancelSubscriptionPremiumUser(uid int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("no active subscription found for user")
+		return fmt.Errorf("user not found")
 	}
 
-	logger.Info("Cancelled subscription for user", map[string]interface{}{
-		"uid": uid,
+	logger.Info("Updated user LLM task models", map[string]interface{}{
+		"chat_id":         chatID,
+		"llm_task_models": taskModels,
 	})
+
 	return nil
 }
 
-// RenewSubscriptionPremiumUser extends the expiry date for a subscription user
-func (db *DB) RenewSubscriptionPremiumUser(uid int64, billingPeriod string) error {
+// UpdateUserPrivateRepo sets the repository that receives private entries, empty disables it
+func (db *DB) UpdateUserPrivateRepo(chatID int64, privateRepo string) error {
 	if db == nil {
 		return fmt.Errorf("database not configured")
 	}
 
-	// Calculate new expiry date based on billing period
-	now := time.Now()
-	var newExpireAt int64
-	switch billingPeriod {
-	case "monthly":
-		newExpireAt = now.AddDate(0, 1, 0).Unix() // 1 month from now
-	case "annually":
-		newExpireAt = now.AddDate(1, 0, 0).Unix() // 1 year from now
-	default:
-		// Default to monthly if billing period is unknown
-		newExpireAt = now.AddDate(0, 1, 0).Unix()
+	query := `
+	UPDATE users 
+	SET private_repo = $2, updated_at = $3
+	WHERE chat_id = $1
+	`
+
+	result, err := db.conn.Exec(query, chatID, privateRepo, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update private repo: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated user private repo", map[string]interface{}{
+		"chat_id":      chatID,
+		"private_repo": privateRepo,
+	})
+
+	return nil
+}
+
+// UpdateUserGitHubAPIURL sets the GitHub Enterprise Server API URL of a user, empty means github.com
+func (db *DB) UpdateUserGitHubAPIURL(chatID int64, apiURL string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
 	}
 
 	query := `
-	UPDATE premium_user 
-	SET expire_at = $2
-	WHERE uid = $1 AND is_subscription = true
+	UPDATE users 
+	SET github_api_url = $2, updated_at = $3
+	WHERE chat_id = $1
 	`
 
-	result, err := db.conn.Exec(query, uid, newExpireAt)
+	result, err := db.conn.Exec(query, chatID, apiURL, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to renew subscription: %w", err)
+		return fmt.Errorf("failed to update GitHub API URL: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -649,32 +1449,33 @@ func (db *DB) RenewSubscriptionPremiumUser(uid int64, billingPeriod string) erro
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("no active subscription found for user")
+		return fmt.Errorf("user not found")
 	}
 
-	logger.Info("Renewed subscription for user", map[string]interface{}{
-		"uid":            uid,
-		"billing_period": billingPeriod,
-		"new_expire_at":  newExpireAt,
+	logger.Info("Updated user GitHub API URL", map[string]interface{}{
+		"chat_id":        chatID,
+		"github_api_url": apiURL,
 	})
+
 	return nil
 }
 
-// SetSubscriptionExpiry sets the expiry date for a subscription user to a specific timestamp
-func (db *DB) SetSubscriptionExpiry(uid int64, expireAt int64) error {
+// UpdateUserIssueArchive sets how many days closed issues stay in issue.md before /sync archives them,
+// and whether they are archived into one file per year
+func (db *DB) UpdateUserIssueArchive(chatID int64, days int, yearly bool) error {
 	if db == nil {
 		return fmt.Errorf("database not configured")
 	}
 
 	query := `
-	UPDATE premium_user 
-	SET expire_at = $2
-	WHERE uid = $1 AND is_subscription = true
+	UPDATE users 
+	SET issue_archive_days = $2, issue_archive_yearly = $3, updated_at = $4
+	WHERE chat_id = $1
 	`
 
-	result, err := db.conn.Exec(query, uid, expireAt)
+	result, err := db.conn.Exec(query, chatID, days, yearly, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to set subscription expiry: %w", err)
+		return fmt.Errorf("failed to update issue archive settings: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -683,32 +1484,37 @@ func (db *DB) SetSubscriptionExpiry(uid int64, expireAt int64) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("no active subscription found for user")
+		return fmt.Errorf("user not found")
 	}
 
-	logger.Info("Set subscription expiry for user", map[string]interface{}{
-		"uid":         uid,
-		"expire_at":   expireAt,
-		"expire_time": time.Unix(expireAt, 0).Format("2006-01-02 15:04:05"),
+	logger.Info("Updated user issue archive settings", map[string]interface{}{
+		"chat_id":              chatID,
+		"issue_archive_days":   days,
+		"issue_archive_yearly": yearly,
 	})
+
 	return nil
 }
 
-// UpdateUserCommitter updates the committer field for any user
-func (db *DB) UpdateUserCommitter(chatID int64, committer string) error {
+// UpdateUserSyncCommitMode sets whether /sync commits all changed files together or one commit per file
+func (db *DB) UpdateUserSyncCommitMode(chatID int64, mode string) error {
 	if db == nil {
 		return fmt.Errorf("database not configured")
 	}
 
+	if mode != SyncCommitModeSquash && mode != SyncCommitModePerFile {
+		return fmt.Errorf("invalid sync commit mode: %s", mode)
+	}
+
 	query := `
 	UPDATE users 
-	SET committer = $2, updated_at = $3
+	SET sync_commit_mode = $2, updated_at = $3
 	WHERE chat_id = $1
 	`
 
-	result, err := db.conn.Exec(query, chatID, committer, time.Now())
+	result, err := db.conn.Exec(query, chatID, mode, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to update committer: %w", err)
+		return fmt.Errorf("failed to update sync commit mode: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -720,9 +1526,9 @@ func (db *DB) UpdateUserCommitter(chatID int64, committer string) error {
 		return fmt.Errorf("user not found")
 	}
 
-	logger.Info("Updated user committer", map[string]interface{}{
-		"chat_id":   chatID,
-		"committer": committer,
+	logger.Info("Updated user sync commit mode", map[string]interface{}{
+		"chat_id":          chatID,
+		"sync_commit_mode": mode,
 	})
 
 	return nil
@@ -807,7 +1613,7 @@ func (db *DB) GetUserInsights(uid int64) (*UserInsights, error) {
 	}
 
 	query := `
-	SELECT id, uid, commit_cnt, issue_cnt, image_cnt, repo_size, reset_cnt, issue_cmt_cnt, issue_close_cnt, sync_cmd_cnt, insight_cmd_cnt, token_input, token_output, update_time
+	SELECT id, uid, commit_cnt, issue_cnt, image_cnt, file_cnt, repo_size, reset_cnt, issue_cmt_cnt, issue_close_cnt, sync_cmd_cnt, insight_cmd_cnt, token_input, token_output, update_time
 	FROM user_insights 
 	WHERE uid = $1
 	`
@@ -815,7 +1621,7 @@ func (db *DB) GetUserInsights(uid int64) (*UserInsights, error) {
 	insights := &UserInsights{}
 	err := db.conn.QueryRow(query, uid).Scan(
 		&insights.ID, &insights.UID, &insights.CommitCnt,
-		&insights.IssueCnt, &insights.ImageCnt, &insights.RepoSize, &insights.ResetCnt,
+		&insights.IssueCnt, &insights.ImageCnt, &insights.FileCnt, &insights.RepoSize, &insights.ResetCnt,
 		&insights.IssueCmtCnt, &insights.IssueCloseCnt, &insights.SyncCmdCnt, &insights.InsightCmdCnt, &insights.TokenInput, &insights.TokenOutput, &insights.UpdateTime,
 	)
 
@@ -845,13 +1651,13 @@ func (db *DB) CreateOrUpdateUserInsights(uid int64, commitCnt, issueCnt, imageCn
 		image_cnt = $4, 
 		repo_size = $5, 
 		update_time = $6
-	RETURNING id, uid, commit_cnt, issue_cnt, image_cnt, repo_size, reset_cnt, issue_cmt_cnt, issue_close_cnt, sync_cmd_cnt, insight_cmd_cnt, token_input, token_output, update_time
+	RETURNING id, uid, commit_cnt, issue_cnt, image_cnt, file_cnt, repo_size, reset_cnt, issue_cmt_cnt, issue_close_cnt, sync_cmd_cnt, insight_cmd_cnt, token_input, token_output, update_time
 	`
 
 	insights := &UserInsights{}
 	err := db.conn.QueryRow(query, uid, commitCnt, issueCnt, imageCnt, repoSize, now).Scan(
 		&insights.ID, &insights.UID, &insights.CommitCnt,
-		&insights.IssueCnt, &insights.ImageCnt, &insights.RepoSize, &insights.ResetCnt,
+		&insights.IssueCnt, &insights.ImageCnt, &insights.FileCnt, &insights.RepoSize, &insights.ResetCnt,
 		&insights.IssueCmtCnt, &insights.IssueCloseCnt, &insights.SyncCmdCnt, &insights.InsightCmdCnt, &insights.TokenInput, &insights.TokenOutput, &insights.UpdateTime,
 	)
 
@@ -862,17 +1668,20 @@ func (db *DB) CreateOrUpdateUserInsights(uid int64, commitCnt, issueCnt, imageCn
 	return insights, nil
 }
 
-// IncrementCommitCount increments the commit count for a user
+// IncrementCommitCount increments the commit count for a user and extends their daily streak
 func (db *DB) IncrementCommitCount(uid int64) error {
 	if db == nil {
 		return fmt.Errorf("database not configured")
 	}
 
 	query := `
-	INSERT INTO user_insights (uid, commit_cnt, update_time)
-	VALUES ($1, 1, $2)
+	INSERT INTO user_insights (uid, commit_cnt, streak_days, best_streak, last_commit_date, update_time)
+	VALUES ($1, 1, 1, 1, CURRENT_DATE, $2)
 	ON CONFLICT (uid) DO UPDATE SET 
 		commit_cnt = user_insights.commit_cnt + 1,
+		streak_days = ` + nextStreakDays + `,
+		best_streak = GREATEST(user_insights.best_streak, ` + nextStreakDays + `),
+		last_commit_date = CURRENT_DATE,
 		update_time = $2
 	`
 
@@ -928,6 +1737,28 @@ func (db *DB) IncrementImageCount(uid int64) error {
 	return nil
 }
 
+// IncrementFileCount increments the attached file count for a user
+func (db *DB) IncrementFileCount(uid int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO user_insights (uid, file_cnt, update_time)
+	VALUES ($1, 1, $2)
+	ON CONFLICT (uid) DO UPDATE SET 
+		file_cnt = user_insights.file_cnt + 1,
+		update_time = $2
+	`
+
+	_, err := db.conn.Exec(query, uid, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to increment file count: %w", err)
+	}
+
+	return nil
+}
+
 // UpdateRepoSize updates the repository size for a user
 func (db *DB) UpdateRepoSize(uid int64, repoSize float64) error {
 	if db == nil {
@@ -979,58 +1810,6 @@ func (db *DB) UpdateUserCustomFiles(chatID int64, customFiles string) error {
 	return nil
 }
 
-// CheckIssueLimit checks if user can create more issues based on their premium level
-func (db *DB) CheckIssueLimit(uid int64, premiumLevel int) (bool, int64, int64, error) {
-	if db == nil {
-		return false, 0, 0, fmt.Errorf("database not configured")
-	}
-
-	// Get current issue count
-	insights, err := db.GetUserInsights(uid)
-	if err != nil {
-		return false, 0, 0, fmt.Errorf("failed to get user insights: %w", err)
-	}
-
-	currentCount := int64(0)
-	if insights != nil {
-		currentCount = insights.IssueCnt
-	}
-
-	// Get limit for premium level
-	limit := GetIssueLimit(premiumLevel)
-
-	// Check if user can create more issues
-	canCreate := currentCount < limit
-
-	return canCreate, currentCount, limit, nil
-}
-
-// CheckImageLimit checks if user can upload more images based on their premium level
-func (db *DB) CheckImageLimit(uid int64, premiumLevel int) (bool, int64, int64, error) {
-	if db == nil {
-		return false, 0, 0, fmt.Errorf("database not configured")
-	}
-
-	// Get current image count
-	insights, err := db.GetUserInsights(uid)
-	if err != nil {
-		return false, 0, 0, fmt.Errorf("failed to get user insights: %w", err)
-	}
-
-	currentCount := int64(0)
-	if insights != nil {
-		currentCount = insights.ImageCnt
-	}
-
-	// Get limit for premium level
-	limit := GetImageLimit(premiumLevel)
-
-	// Check if user can upload more images
-	canUpload := currentCount < limit
-
-	return canUpload, currentCount, limit, nil
-}
-
 // CreateResetLog creates a new reset log entry
 func (db *DB) CreateResetLog(uid int64, previousIssues, previousImages, previousTokenInput, previousTokenOutput int64, topupLogID int) (*ResetLog, error) {
 	if db == nil {
@@ -1141,7 +1920,7 @@ func (db *DB) GetUserUsage(uid int64) (*UserUsage, error) {
 	}
 
 	query := `
-	SELECT id, uid, issue_cnt, image_cnt, token_input, token_output, update_time
+	SELECT id, uid, issue_cnt, image_cnt, file_cnt, token_input, token_output, update_time
 	FROM user_usage 
 	WHERE uid = $1
 	`
@@ -1149,7 +1928,7 @@ func (db *DB) GetUserUsage(uid int64) (*UserUsage, error) {
 	usage := &UserUsage{}
 	err := db.conn.QueryRow(query, uid).Scan(
 		&usage.ID, &usage.UID, &usage.IssueCnt,
-		&usage.ImageCnt, &usage.TokenInput, &usage.TokenOutput, &usage.UpdateTime,
+		&usage.ImageCnt, &usage.FileCnt, &usage.TokenInput, &usage.TokenOutput, &usage.UpdateTime,
 	)
 
 	if err == sql.ErrNoRows {
@@ -1176,13 +1955,13 @@ func (db *DB) CreateOrUpdateUserUsage(uid int64, issueCnt, imageCnt int64) (*Use
 		issue_cnt = $2, 
 		image_cnt = $3, 
 		update_time = $4
-	RETURNING id, uid, issue_cnt, image_cnt, token_input, token_output, update_time
+	RETURNING id, uid, issue_cnt, image_cnt, file_cnt, token_input, token_output, update_time
 	`
 
 	usage := &UserUsage{}
 	err := db.conn.QueryRow(query, uid, issueCnt, imageCnt, now).Scan(
 		&usage.ID, &usage.UID, &usage.IssueCnt,
-		&usage.ImageCnt, &usage.TokenInput, &usage.TokenOutput, &usage.UpdateTime,
+		&usage.ImageCnt, &usage.FileCnt, &usage.TokenInput, &usage.TokenOutput, &usage.UpdateTime,
 	)
 
 	if err != nil {
@@ -1236,6 +2015,28 @@ func (db *DB) IncrementUsageImageCount(uid int64) error {
 	return nil
 }
 
+// IncrementUsageFileCount increments the attached file count in user_usage table
+func (db *DB) IncrementUsageFileCount(uid int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO user_usage (uid, file_cnt, update_time)
+	VALUES ($1, 1, $2)
+	ON CONFLICT (uid) DO UPDATE SET 
+		file_cnt = user_usage.file_cnt + 1,
+		update_time = $2
+	`
+
+	_, err := db.conn.Exec(query, uid, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to increment usage file count: %w", err)
+	}
+
+	return nil
+}
+
 // ResetUserUsage resets user usage counters to 0
 func (db *DB) ResetUserUsage(uid int64) error {
 	if db == nil {
@@ -1243,11 +2044,12 @@ func (db *DB) ResetUserUsage(uid int64) error {
 	}
 
 	query := `
-	INSERT INTO user_usage (uid, issue_cnt, image_cnt, token_input, token_output, update_time)
-	VALUES ($1, 0, 0, 0, 0, $2)
+	INSERT INTO user_usage (uid, issue_cnt, image_cnt, file_cnt, token_input, token_output, update_time)
+	VALUES ($1, 0, 0, 0, 0, 0, $2)
 	ON CONFLICT (uid) DO UPDATE SET 
 		issue_cnt = 0,
 		image_cnt = 0,
+		file_cnt = 0,
 		token_input = 0,
 		token_output = 0,
 		update_time = $2
@@ -1258,6 +2060,11 @@ func (db *DB) ResetUserUsage(uid int64) error {
 		return fmt.Errorf("failed to reset user usage: %w", err)
 	}
 
+	// A fresh usage period re-arms the usage quota alerts
+	if err := db.ClearQuotaAlerts(uid, QuotaMetricIssues, QuotaMetricImages, QuotaMetricFiles, QuotaMetricTokens); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -1489,58 +2296,6 @@ func (db *DB) IncrementTokenUsageAll(uid int64, inputTokens, outputTokens int64)
 	return nil
 }
 
-// CheckUsageIssueLimit checks if user can create more issues based on current usage
-func (db *DB) CheckUsageIssueLimit(uid int64, premiumLevel int) (bool, int64, int64, error) {
-	if db == nil {
-		return false, 0, 0, fmt.Errorf("database not configured")
-	}
-
-	// Get current usage count
-	usage, err := db.GetUserUsage(uid)
-	if err != nil {
-		return false, 0, 0, fmt.Errorf("failed to get user usage: %w", err)
-	}
-
-	currentCount := int64(0)
-	if usage != nil {
-		currentCount = usage.IssueCnt
-	}
-
-	// Get limit for premium level
-	limit := GetIssueLimit(premiumLevel)
-
-	// Check if user can create more issues
-	canCreate := currentCount < limit
-
-	return canCreate, currentCount, limit, nil
-}
-
-// CheckUsageImageLimit checks if user can upload more images based on current usage
-func (db *DB) CheckUsageImageLimit(uid int64, premiumLevel int) (bool, int64, int64, error) {
-	if db == nil {
-		return false, 0, 0, fmt.Errorf("database not configured")
-	}
-
-	// Get current usage count
-	usage, err := db.GetUserUsage(uid)
-	if err != nil {
-		return false, 0, 0, fmt.Errorf("failed to get user usage: %w", err)
-	}
-
-	currentCount := int64(0)
-	if usage != nil {
-		currentCount = usage.ImageCnt
-	}
-
-	// Get limit for premium level
-	limit := GetImageLimit(premiumLevel)
-
-	// Check if user can upload more images
-	canUpload := currentCount < limit
-
-	return canUpload, currentCount, limit, nil
-}
-
 // GlobalStats represents global bot statistics
 type GlobalStats struct {
 	TotalCommits       int64   `json:"total_commits"`
@@ -1780,40 +2535,3 @@ func (db *DB) UpdateUserLLMMultimodalSwitch(chatID int64, multimodalSwitch bool)
 	return nil
 }
 
-// CanUseDefaultLLM checks if a user can use default LLM processing based on their token usage and limits
-func (db *DB) CanUseDefaultLLM(chatID int64, estimatedTokens int64) (bool, error) {
-	if db == nil {
-		return false, fmt.Errorf("database not configured")
-	}
-
-	// Get user's current token usage
-	usage, err := db.GetUserUsage(chatID)
-	if err != nil {
-		return false, fmt.Errorf("failed to get user usage: %w", err)
-	}
-
-	// Get user's premium level
-	premiumLevel := 0
-	premiumUser, err := db.GetPremiumUser(chatID)
-	if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-		premiumLevel = premiumUser.Level
-	}
-
-	// Calculate token limit based on premium level
-	tokenLimit := GetTokenLimit(premiumLevel)
-
-	// Check if current usage + estimated tokens would exceed limit
-	if usage != nil {
-		totalUsage := usage.TokenInput + usage.TokenOutput
-		if totalUsage+estimatedTokens > tokenLimit {
-			return false, nil // Would exceed limit
-		}
-	} else {
-		// No usage record yet, check if estimated tokens exceed limit
-		if estimatedTokens > tokenLimit {
-			return false, nil // Would exceed limit
-		}
-	}
-
-	return true, nil
-}
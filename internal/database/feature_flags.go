@@ -0,0 +1,141 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Feature flag methods
+
+// GetFeatureFlags retrieves all feature flags
+func (db *DB) GetFeatureFlags() ([]*FeatureFlag, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT name, enabled, percentage, min_tier, user_ids, description, updated_at
+	FROM feature_flags
+	ORDER BY name
+	`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	var flags []*FeatureFlag
+	for rows.Next() {
+		flag := &FeatureFlag{}
+		err := rows.Scan(
+			&flag.Name, &flag.Enabled, &flag.Percentage, &flag.MinTier,
+			&flag.UserIDs, &flag.Description, &flag.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feature flags: %w", err)
+	}
+
+	return flags, nil
+}
+
+// GetFeatureFlag retrieves a single feature flag by name, returns nil if it doesn't exist
+func (db *DB) GetFeatureFlag(name string) (*FeatureFlag, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT name, enabled, percentage, min_tier, user_ids, description, updated_at
+	FROM feature_flags
+	WHERE name = $1
+	`
+
+	flag := &FeatureFlag{}
+	err := db.conn.QueryRow(query, name).Scan(
+		&flag.Name, &flag.Enabled, &flag.Percentage, &flag.MinTier,
+		&flag.UserIDs, &flag.Description, &flag.UpdatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+
+	return flag, nil
+}
+
+// UpsertFeatureFlag creates or updates a feature flag
+func (db *DB) UpsertFeatureFlag(flag *FeatureFlag) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if flag.Percentage < 0 || flag.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100")
+	}
+	if flag.UserIDs == "" {
+		flag.UserIDs = "[]"
+	}
+
+	query := `
+	INSERT INTO feature_flags (name, enabled, percentage, min_tier, user_ids, description, updated_at)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)
+	ON CONFLICT (name)
+	DO UPDATE SET
+		enabled = EXCLUDED.enabled,
+		percentage = EXCLUDED.percentage,
+		min_tier = EXCLUDED.min_tier,
+		user_ids = EXCLUDED.user_ids,
+		description = EXCLUDED.description,
+		updated_at = EXCLUDED.updated_at
+	`
+
+	flag.UpdatedAt = time.Now()
+	_, err := db.conn.Exec(query, flag.Name, flag.Enabled, flag.Percentage, flag.MinTier, flag.UserIDs, flag.Description, flag.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to upsert feature flag: %w", err)
+	}
+
+	logger.Info("Feature flag updated", map[string]interface{}{
+		"name":       flag.Name,
+		"enabled":    flag.Enabled,
+		"percentage": flag.Percentage,
+		"min_tier":   flag.MinTier,
+	})
+	return nil
+}
+
+// DeleteFeatureFlag removes a feature flag
+func (db *DB) DeleteFeatureFlag(name string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM feature_flags WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("feature flag not found")
+	}
+
+	return nil
+}
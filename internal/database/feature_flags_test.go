@@ -0,0 +1,57 @@
+package database
+
+import "testing"
+
+func TestFeatureFlag_IsEnabledFor(t *testing.T) {
+	allowlisted := &FeatureFlag{Name: "beta", Enabled: true, MinTier: 3}
+	allowlisted.SetUserIDs([]int64{42})
+
+	tests := []struct {
+		name         string
+		flag         *FeatureFlag
+		chatID       int64
+		premiumLevel int
+		expected     bool
+	}{
+		{"nil flag", nil, 1, 0, false},
+		{"disabled flag", &FeatureFlag{Name: "x", Enabled: false, Percentage: 100}, 1, 0, false},
+		{"full rollout", &FeatureFlag{Name: "x", Enabled: true, Percentage: 100}, 1, 0, true},
+		{"zero rollout", &FeatureFlag{Name: "x", Enabled: true, Percentage: 0}, 1, 0, false},
+		{"allowlisted bypasses tier", allowlisted, 42, 0, true},
+		{"not allowlisted below tier", allowlisted, 7, 0, false},
+		{"tier gate met", &FeatureFlag{Name: "x", Enabled: true, Percentage: 100, MinTier: 2}, 1, 2, true},
+		{"tier gate not met", &FeatureFlag{Name: "x", Enabled: true, Percentage: 100, MinTier: 2}, 1, 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.flag.IsEnabledFor(tt.chatID, tt.premiumLevel); result != tt.expected {
+				t.Errorf("IsEnabledFor(%d, %d) = %v, want %v", tt.chatID, tt.premiumLevel, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFeatureFlag_PercentageRollout(t *testing.T) {
+	flag := &FeatureFlag{Name: "rollout", Enabled: true, Percentage: 25}
+
+	enabled := 0
+	for chatID := int64(0); chatID < 10000; chatID++ {
+		if flag.IsEnabledFor(chatID, 0) {
+			enabled++
+		}
+	}
+
+	// Expect roughly 25% with a generous tolerance
+	if enabled < 2000 || enabled > 3000 {
+		t.Errorf("Expected ~2500 users enabled at 25%%, got %d", enabled)
+	}
+
+	// Raising the percentage must keep previously enabled users enabled
+	wider := &FeatureFlag{Name: "rollout", Enabled: true, Percentage: 50}
+	for chatID := int64(0); chatID < 1000; chatID++ {
+		if flag.IsEnabledFor(chatID, 0) && !wider.IsEnabledFor(chatID, 0) {
+			t.Fatalf("User %d lost the feature when rollout increased", chatID)
+		}
+	}
+}
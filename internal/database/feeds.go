@@ -0,0 +1,120 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// Feed subscription methods
+
+const feedColumns = `id, chat_id, url, title, summarize, last_item_at, last_fetched_at, created_at`
+
+// CreateFeed subscribes a user to a feed, only items published after now are digested
+func (db *DB) CreateFeed(chatID int64, url, title string, summarize bool) (*Feed, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO feeds (chat_id, url, title, summarize, last_item_at, last_fetched_at, created_at)
+	VALUES ($1, $2, $3, $4, NOW(), NOW(), NOW())
+	RETURNING ` + feedColumns
+
+	feed := &Feed{}
+	err := db.conn.QueryRow(query, chatID, url, title, summarize).Scan(
+		&feed.ID, &feed.ChatID, &feed.URL, &feed.Title, &feed.Summarize,
+		&feed.LastItemAt, &feed.LastFetchedAt, &feed.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create feed: %w", err)
+	}
+
+	return feed, nil
+}
+
+// GetFeeds retrieves a user's feed subscriptions
+func (db *DB) GetFeeds(chatID int64) ([]*Feed, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	return db.queryFeeds(`SELECT `+feedColumns+` FROM feeds WHERE chat_id = $1 ORDER BY id`, chatID)
+}
+
+// GetDueFeeds retrieves feeds of all users that were last fetched before the given time
+func (db *DB) GetDueFeeds(before time.Time) ([]*Feed, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	return db.queryFeeds(`SELECT `+feedColumns+` FROM feeds WHERE last_fetched_at <= $1 ORDER BY chat_id, id`, before)
+}
+
+// UpdateFeedFetched records a fetch and the publish time of the newest digested item
+func (db *DB) UpdateFeedFetched(id int64, title string, lastItemAt, fetchedAt time.Time) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE feeds
+	SET title = CASE WHEN $2 = '' THEN title ELSE $2 END, last_item_at = $3, last_fetched_at = $4
+	WHERE id = $1
+	`
+
+	if _, err := db.conn.Exec(query, id, title, lastItemAt, fetchedAt); err != nil {
+		return fmt.Errorf("failed to update feed: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteFeed removes a feed subscription owned by chatID
+func (db *DB) DeleteFeed(id, chatID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM feeds WHERE id = $1 AND chat_id = $2`, id, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to delete feed: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("feed not found")
+	}
+
+	return nil
+}
+
+func (db *DB) queryFeeds(query string, args ...interface{}) ([]*Feed, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []*Feed
+	for rows.Next() {
+		feed := &Feed{}
+		err := rows.Scan(
+			&feed.ID, &feed.ChatID, &feed.URL, &feed.Title, &feed.Summarize,
+			&feed.LastItemAt, &feed.LastFetchedAt, &feed.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feed: %w", err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating feeds: %w", err)
+	}
+
+	return feeds, nil
+}
@@ -0,0 +1,95 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Custom file template methods
+
+const customFileTemplateColumns = `chat_id, filename, template, updated_at`
+
+// SetCustomFileTemplate creates or replaces the template of one of the user's custom files
+func (db *DB) SetCustomFileTemplate(chatID int64, filename, template string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO custom_file_templates (chat_id, filename, template, updated_at)
+	VALUES ($1, $2, $3, NOW())
+	ON CONFLICT (chat_id, filename) DO UPDATE SET template = EXCLUDED.template, updated_at = NOW()
+	`
+	if _, err := db.conn.Exec(query, chatID, filename, template); err != nil {
+		return fmt.Errorf("failed to set custom file template: %w", err)
+	}
+
+	return nil
+}
+
+// GetCustomFileTemplate retrieves the template of a custom file, nil if it has none
+func (db *DB) GetCustomFileTemplate(chatID int64, filename string) (*CustomFileTemplate, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	template := &CustomFileTemplate{}
+	err := db.conn.QueryRow(`SELECT `+customFileTemplateColumns+` FROM custom_file_templates WHERE chat_id = $1 AND filename = $2`, chatID, filename).Scan(
+		&template.ChatID, &template.Filename, &template.Template, &template.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom file template: %w", err)
+	}
+
+	return template, nil
+}
+
+// GetCustomFileTemplates retrieves the templates of all the user's custom files, by filename
+func (db *DB) GetCustomFileTemplates(chatID int64) (map[string]*CustomFileTemplate, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+customFileTemplateColumns+` FROM custom_file_templates WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query custom file templates: %w", err)
+	}
+	defer rows.Close()
+
+	templates := make(map[string]*CustomFileTemplate)
+	for rows.Next() {
+		template := &CustomFileTemplate{}
+		if err := rows.Scan(&template.ChatID, &template.Filename, &template.Template, &template.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan custom file template: %w", err)
+		}
+		templates[template.Filename] = template
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating custom file templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+// DeleteCustomFileTemplate removes the template of a custom file, returning whether it had one
+func (db *DB) DeleteCustomFileTemplate(chatID int64, filename string) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM custom_file_templates WHERE chat_id = $1 AND filename = $2`, chatID, filename)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete custom file template: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
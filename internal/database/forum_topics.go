@@ -0,0 +1,77 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Forum topic mapping methods
+
+const forumTopicColumns = `chat_id, thread_id, name, file_path, created_at`
+
+// GetForumTopic retrieves the mapping of a forum topic, nil if the topic isn't mapped yet
+func (db *DB) GetForumTopic(chatID int64, threadID int) (*ForumTopic, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	topic := &ForumTopic{}
+	err := db.conn.QueryRow(`SELECT `+forumTopicColumns+` FROM forum_topics WHERE chat_id = $1 AND thread_id = $2`, chatID, threadID).Scan(
+		&topic.ChatID, &topic.ThreadID, &topic.Name, &topic.FilePath, &topic.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forum topic: %w", err)
+	}
+
+	return topic, nil
+}
+
+// CreateForumTopic maps a forum topic to filePath. A topic mapped concurrently keeps its first
+// mapping, which is returned.
+func (db *DB) CreateForumTopic(chatID int64, threadID int, name, filePath string) (*ForumTopic, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO forum_topics (chat_id, thread_id, name, file_path, created_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (chat_id, thread_id) DO NOTHING
+	`
+	if _, err := db.conn.Exec(query, chatID, threadID, name, filePath); err != nil {
+		return nil, fmt.Errorf("failed to create forum topic: %w", err)
+	}
+
+	return db.GetForumTopic(chatID, threadID)
+}
+
+// GetForumTopics retrieves the topic mappings of a forum group
+func (db *DB) GetForumTopics(chatID int64) ([]*ForumTopic, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+forumTopicColumns+` FROM forum_topics WHERE chat_id = $1 ORDER BY name`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query forum topics: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []*ForumTopic
+	for rows.Next() {
+		topic := &ForumTopic{}
+		if err := rows.Scan(&topic.ChatID, &topic.ThreadID, &topic.Name, &topic.FilePath, &topic.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan forum topic: %w", err)
+		}
+		topics = append(topics, topic)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating forum topics: %w", err)
+	}
+
+	return topics, nil
+}
@@ -0,0 +1,176 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Handover methods: a chat's configuration and history move to another chat, e.g. when a user
+// switches Telegram accounts. The old chat creates a one-time code and the new chat claims it,
+// moving every row of the old chat in one transaction.
+
+// handoverTable is a table holding rows of a chat in column. A unique table holds at most one row
+// per chat (or per chat and key), so the rows of the claiming chat are dropped for the old chat's.
+type handoverTable struct {
+	name   string
+	column string
+	unique bool
+}
+
+var handoverTables = []handoverTable{
+	{"users", "chat_id", true},
+	{"premium_user", "uid", true},
+	{"user_topup_log", "uid", false},
+	{"user_insights", "uid", true},
+	{"user_usage", "uid", true},
+	{"reset_log", "uid", false},
+	{"subscription_change_log", "uid", false},
+	{"trashed_files", "chat_id", false},
+	{"commit_log", "chat_id", false},
+	{"webhooks", "chat_id", false},
+	{"feeds", "chat_id", true},
+	{"api_keys", "chat_id", true},
+	{"quota_alerts", "chat_id", true},
+	{"tenant_members", "chat_id", true},
+	{"channel_routes", "chat_id", false},
+	{"canned_replies", "chat_id", true},
+	{"background_failures", "chat_id", false},
+	{"activity_events", "chat_id", false},
+	{"daily_pins", "chat_id", true},
+	{"forum_topics", "chat_id", true},
+	{"weekly_changelogs", "chat_id", true},
+	{"compose_sessions", "chat_id", true},
+	{"operation_pauses", "chat_id", true},
+	{"quiet_hours", "chat_id", true},
+	{"deferred_messages", "chat_id", false},
+	{"leaderboard_consents", "chat_id", true},
+	{"streak_reminders", "chat_id", true},
+	{"custom_file_templates", "chat_id", true},
+	{"history_compressions", "chat_id", true},
+	{"photo_hashes", "chat_id", true},
+	{"insight_reports", "chat_id", true},
+}
+
+// handoverExcluded are the tables a handover leaves alone: rows not owned by a chat, access set
+// by admins for that chat, and the codes themselves
+var handoverExcluded = []string{"feature_flags", "tenants", "chat_access", "handover_codes"}
+
+// CreateHandoverCode stores the hash of a new handover code of the chat, replacing its previous one
+func (db *DB) CreateHandoverCode(chatID int64, codeHash string, expiresAt time.Time) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO handover_codes (code_hash, chat_id, created_at, expires_at)
+	VALUES ($1, $2, NOW(), $3)
+	ON CONFLICT (chat_id) DO UPDATE SET code_hash = EXCLUDED.code_hash, created_at = NOW(), expires_at = EXCLUDED.expires_at
+	`
+
+	if _, err := db.conn.Exec(query, codeHash, chatID, expiresAt); err != nil {
+		return fmt.Errorf("failed to create handover code: %w", err)
+	}
+	return nil
+}
+
+// GetHandoverCode retrieves the chat's pending handover, nil if there is none or it expired
+func (db *DB) GetHandoverCode(chatID int64) (*HandoverCode, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	code := &HandoverCode{}
+	err := db.conn.QueryRow(`SELECT chat_id, created_at, expires_at FROM handover_codes WHERE chat_id = $1 AND expires_at > NOW()`, chatID).Scan(
+		&code.ChatID, &code.CreatedAt, &code.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get handover code: %w", err)
+	}
+	return code, nil
+}
+
+// CancelHandoverCode deletes the chat's handover code, returning whether one was pending
+func (db *DB) CancelHandoverCode(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM handover_codes WHERE chat_id = $1 AND expires_at > NOW()`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel handover code: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// ClaimHandover uses the handover code with the hash codeHash, moving the rows of the chat that
+// created it to toChatID in one transaction. Rows of toChatID in unique tables are replaced, its
+// history is kept. Returns the chat the rows came from, 0 if the code is unknown or expired.
+func (db *DB) ClaimHandover(codeHash string, toChatID int64) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Deleting the code makes it one-time even if two chats claim it at once
+	var fromChatID int64
+	var expiresAt time.Time
+	err = tx.QueryRow(`DELETE FROM handover_codes WHERE code_hash = $1 RETURNING chat_id, expires_at`, codeHash).Scan(&fromChatID, &expiresAt)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim handover code: %w", err)
+	}
+	if !time.Now().Before(expiresAt) {
+		// Expired codes are removed all the same
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("failed to remove expired handover code: %w", err)
+		}
+		return 0, nil
+	}
+	if fromChatID == toChatID {
+		return 0, fmt.Errorf("this code was created in this chat, send it from your new account")
+	}
+
+	// Renewals of a subscription are credited to the chat in the Stripe customer's metadata
+	var subscribed bool
+	err = tx.QueryRow(`SELECT is_subscription AND (expire_at = -1 OR expire_at > $2) FROM premium_user WHERE uid = $1`, fromChatID, time.Now().Unix()).Scan(&subscribed)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, fmt.Errorf("failed to check subscription: %w", err)
+	}
+	if subscribed {
+		return 0, fmt.Errorf("the old account has an active subscription, cancel it before handing over")
+	}
+
+	if _, err := tx.Exec(`DELETE FROM handover_codes WHERE chat_id = $1`, toChatID); err != nil {
+		return 0, fmt.Errorf("failed to remove handover code: %w", err)
+	}
+	for _, table := range handoverTables {
+		if table.unique {
+			if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE %s = $1`, table.name, table.column), toChatID); err != nil {
+				return 0, fmt.Errorf("failed to clear %s: %w", table.name, err)
+			}
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET %s = $2 WHERE %s = $1`, table.name, table.column, table.column), fromChatID, toChatID); err != nil {
+			return 0, fmt.Errorf("failed to move %s: %w", table.name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit handover: %w", err)
+	}
+	return fromChatID, nil
+}
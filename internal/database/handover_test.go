@@ -0,0 +1,96 @@
+package database
+
+import (
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestHandoverTablesCoverSchema(t *testing.T) {
+	source, err := os.ReadFile("database.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	covered := make(map[string]bool)
+	for _, table := range handoverTables {
+		covered[table.name] = true
+	}
+	for _, table := range handoverExcluded {
+		covered[table] = true
+	}
+	for _, match := range regexp.MustCompile(`CREATE TABLE IF NOT EXISTS (\w+)`).FindAllStringSubmatch(string(source), -1) {
+		if !covered[match[1]] {
+			t.Errorf("table %s is neither in handoverTables nor in handoverExcluded", match[1])
+		}
+	}
+}
+
+func TestDB_ClaimHandover(t *testing.T) {
+	dsn := getTestDSN()
+	if dsn == "" {
+		t.Skip("Skipping database tests - no TEST_POSTGRES_DSN environment variable set")
+	}
+
+	db, err := NewDB(dsn, "")
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	defer db.Close()
+
+	from, to := int64(987660001), int64(987660002)
+	for _, chatID := range []int64{from, to} {
+		db.DeleteUser(chatID)
+		db.conn.Exec(`DELETE FROM commit_log WHERE chat_id = $1`, chatID)
+		db.conn.Exec(`DELETE FROM handover_codes WHERE chat_id = $1`, chatID)
+	}
+	if _, err := db.CreateUser(from, "old"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateUserGitHubConfig(from, "ghp_token", "https://github.com/o/notes"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateUser(to, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.conn.Exec(`INSERT INTO commit_log (chat_id, filename, commit_sha) VALUES ($1, 'note.md', 'abc')`, from); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.CreateHandoverCode(from, "hash-expired", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err := db.ClaimHandover("hash-expired", to); err != nil || claimed != 0 {
+		t.Fatalf("ClaimHandover() of an expired code = %d, %v, want 0", claimed, err)
+	}
+
+	if err := db.CreateHandoverCode(from, "hash", time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.ClaimHandover("hash", from); err == nil {
+		t.Error("ClaimHandover() by the chat that created the code succeeded")
+	}
+	claimed, err := db.ClaimHandover("hash", to)
+	if err != nil || claimed != from {
+		t.Fatalf("ClaimHandover() = %d, %v, want %d", claimed, err, from)
+	}
+	if again, err := db.ClaimHandover("hash", to); err != nil || again != 0 {
+		t.Errorf("second ClaimHandover() = %d, %v, want the code used up", again, err)
+	}
+
+	user, err := db.GetUserByChatID(to)
+	if err != nil || user == nil || user.GitHubRepo != "https://github.com/o/notes" || user.GitHubToken != "ghp_token" {
+		t.Fatalf("GetUserByChatID(new) = %+v, %v, want the old configuration", user, err)
+	}
+	if old, err := db.GetUserByChatID(from); err != nil || old != nil {
+		t.Errorf("GetUserByChatID(old) = %+v, %v, want no user", old, err)
+	}
+	var commits int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM commit_log WHERE chat_id = $1`, to).Scan(&commits); err != nil || commits != 1 {
+		t.Errorf("commit log of the new chat = %d, %v, want the old history", commits, err)
+	}
+
+	db.DeleteUser(to)
+	db.conn.Exec(`DELETE FROM commit_log WHERE chat_id = $1`, to)
+}
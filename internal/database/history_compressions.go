@@ -0,0 +1,108 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// History compression methods. A row is the user's consent to rewrite the history of their
+// repository, it's deleted when they opt out.
+
+const historyCompressionColumns = `chat_id, older_than_days, pull_number, last_run_at, consented_at`
+
+// EnableHistoryCompression records the user's consent to compress commits older than olderThanDays,
+// updating the threshold if they already consented
+func (db *DB) EnableHistoryCompression(chatID int64, olderThanDays int) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `INSERT INTO history_compressions (chat_id, older_than_days, consented_at) VALUES ($1, $2, NOW())
+		ON CONFLICT (chat_id) DO UPDATE SET older_than_days = EXCLUDED.older_than_days, consented_at = NOW()`
+	if _, err := db.conn.Exec(query, chatID, olderThanDays); err != nil {
+		return fmt.Errorf("failed to enable history compression: %w", err)
+	}
+
+	return nil
+}
+
+// DisableHistoryCompression withdraws the user's consent, reporting whether they had given it
+func (db *DB) DisableHistoryCompression(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM history_compressions WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to disable history compression: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetHistoryCompression retrieves the user's history compression, nil if they didn't consent
+func (db *DB) GetHistoryCompression(chatID int64) (*HistoryCompression, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	compression := &HistoryCompression{}
+	err := db.conn.QueryRow(`SELECT `+historyCompressionColumns+` FROM history_compressions WHERE chat_id = $1`, chatID).Scan(
+		&compression.ChatID, &compression.OlderThanDays, &compression.PullNumber, &compression.LastRunAt, &compression.ConsentedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history compression: %w", err)
+	}
+
+	return compression, nil
+}
+
+// GetDueHistoryCompressions retrieves the history compressions that didn't run since the given time
+func (db *DB) GetDueHistoryCompressions(before time.Time) ([]*HistoryCompression, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+historyCompressionColumns+` FROM history_compressions WHERE last_run_at IS NULL OR last_run_at < $1 ORDER BY chat_id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history compressions: %w", err)
+	}
+	defer rows.Close()
+
+	var compressions []*HistoryCompression
+	for rows.Next() {
+		compression := &HistoryCompression{}
+		if err := rows.Scan(&compression.ChatID, &compression.OlderThanDays, &compression.PullNumber, &compression.LastRunAt, &compression.ConsentedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan history compression: %w", err)
+		}
+		compressions = append(compressions, compression)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating history compressions: %w", err)
+	}
+
+	return compressions, nil
+}
+
+// UpdateHistoryCompressionRun records a run and the pull request it opened, 0 if none
+func (db *DB) UpdateHistoryCompressionRun(chatID int64, pullNumber int, ranAt time.Time) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if _, err := db.conn.Exec(`UPDATE history_compressions SET pull_number = $2, last_run_at = $3 WHERE chat_id = $1`, chatID, pullNumber, ranAt); err != nil {
+		return fmt.Errorf("failed to update history compression: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Insight report methods
+
+const insightReportColumns = `chat_id, period, commit_to_repo, commit_cnt, issue_cnt, issue_close_cnt, token_input, token_output, repo_size, last_sent_at, created_at`
+
+// EnableInsightReport opts the user into weekly or monthly insight reports, or switches the
+// period. The first report covers the time since opting in, so the counts start at the user's
+// current insights.
+func (db *DB) EnableInsightReport(chatID int64, period string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO insight_reports (chat_id, period, commit_cnt, issue_cnt, issue_close_cnt, token_input, token_output, repo_size, last_sent_at, created_at)
+	SELECT $1, $2, COALESCE(i.commit_cnt, 0), COALESCE(i.issue_cnt, 0), COALESCE(i.issue_close_cnt, 0),
+		COALESCE(i.token_input, 0), COALESCE(i.token_output, 0), COALESCE(i.repo_size, 0), NOW(), NOW()
+	FROM (SELECT 1) AS one LEFT JOIN user_insights i ON i.uid = $1
+	ON CONFLICT (chat_id) DO UPDATE SET period = EXCLUDED.period
+	`
+	if _, err := db.conn.Exec(query, chatID, period); err != nil {
+		return fmt.Errorf("failed to enable insight report: %w", err)
+	}
+
+	return nil
+}
+
+// DisableInsightReport opts the user out, reporting whether reports were enabled
+func (db *DB) DisableInsightReport(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM insight_reports WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to disable insight report: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetInsightReport retrieves the user's insight report, nil if reports aren't enabled
+func (db *DB) GetInsightReport(chatID int64) (*InsightReport, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	report := &InsightReport{}
+	err := db.conn.QueryRow(`SELECT `+insightReportColumns+` FROM insight_reports WHERE chat_id = $1`, chatID).Scan(
+		&report.ChatID, &report.Period, &report.CommitToRepo, &report.CommitCnt, &report.IssueCnt, &report.IssueCloseCnt,
+		&report.TokenInput, &report.TokenOutput, &report.RepoSize, &report.LastSentAt, &report.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get insight report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetDueInsightReports retrieves the insight reports not sent since the given time
+func (db *DB) GetDueInsightReports(before time.Time) ([]*InsightReport, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+insightReportColumns+` FROM insight_reports WHERE last_sent_at IS NULL OR last_sent_at < $1 ORDER BY chat_id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query insight reports: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*InsightReport
+	for rows.Next() {
+		report := &InsightReport{}
+		if err := rows.Scan(
+			&report.ChatID, &report.Period, &report.CommitToRepo, &report.CommitCnt, &report.IssueCnt, &report.IssueCloseCnt,
+			&report.TokenInput, &report.TokenOutput, &report.RepoSize, &report.LastSentAt, &report.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan insight report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating insight reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+// SetInsightReportCommit sets whether the user's reports are also committed to the repository
+func (db *DB) SetInsightReportCommit(chatID int64, enabled bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`UPDATE insight_reports SET commit_to_repo = $2 WHERE chat_id = $1`, chatID, enabled)
+	if err != nil {
+		return fmt.Errorf("failed to update insight report: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("insight report not enabled")
+	}
+
+	return nil
+}
+
+// UpdateInsightReportSent records a report sent at sentAt, the next one counts from insights
+func (db *DB) UpdateInsightReportSent(chatID int64, insights *UserInsights, sentAt time.Time) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+	if insights == nil {
+		insights = &UserInsights{}
+	}
+
+	query := `
+	UPDATE insight_reports SET commit_cnt = $2, issue_cnt = $3, issue_close_cnt = $4, token_input = $5, token_output = $6, repo_size = $7, last_sent_at = $8
+	WHERE chat_id = $1
+	`
+	result, err := db.conn.Exec(query, chatID, insights.CommitCnt, insights.IssueCnt, insights.IssueCloseCnt, insights.TokenInput, insights.TokenOutput, insights.RepoSize, sentAt)
+	if err != nil {
+		return fmt.Errorf("failed to update insight report: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("insight report not enabled")
+	}
+
+	return nil
+}
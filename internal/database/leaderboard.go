@@ -0,0 +1,154 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Leaderboard methods. Only users with a consent row are ever listed, under their pseudonym.
+
+const leaderboardConsentColumns = `chat_id, display_name, consented_at`
+
+// Leaderboard rankings
+const (
+	LeaderboardByCommits = "commits"
+	LeaderboardByStreak  = "streak"
+)
+
+// leaderboardCommits and leaderboardStreak are the values ranked, 0 for users without insights.
+// A streak whose last commit was before yesterday is over.
+const (
+	leaderboardCommits = `COALESCE(i.commit_cnt, 0)`
+	leaderboardStreak  = `CASE WHEN i.last_commit_date >= CURRENT_DATE - 1 THEN i.streak_days ELSE 0 END`
+)
+
+// leaderboardOrders maps rankings to their ORDER BY clause
+var leaderboardOrders = map[string]string{
+	LeaderboardByCommits: leaderboardCommits + ` DESC, ` + leaderboardStreak + ` DESC, c.consented_at`,
+	LeaderboardByStreak:  leaderboardStreak + ` DESC, ` + leaderboardCommits + ` DESC, c.consented_at`,
+}
+
+// JoinLeaderboard records the user's consent to be listed as displayName. A user who already
+// joined keeps their pseudonym; returns whether the user joined now.
+func (db *DB) JoinLeaderboard(chatID int64, displayName string) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	query := `INSERT INTO leaderboard_consents (chat_id, display_name, consented_at) VALUES ($1, $2, NOW()) ON CONFLICT (chat_id) DO NOTHING`
+	result, err := db.conn.Exec(query, chatID, displayName)
+	if err != nil {
+		return false, fmt.Errorf("failed to join leaderboard: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// LeaveLeaderboard withdraws the user's consent, returning whether they had joined
+func (db *DB) LeaveLeaderboard(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM leaderboard_consents WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to leave leaderboard: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetLeaderboardConsent retrieves the user's consent, nil if they haven't joined
+func (db *DB) GetLeaderboardConsent(chatID int64) (*LeaderboardConsent, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	consent := &LeaderboardConsent{}
+	err := db.conn.QueryRow(`SELECT `+leaderboardConsentColumns+` FROM leaderboard_consents WHERE chat_id = $1`, chatID).Scan(
+		&consent.ChatID, &consent.DisplayName, &consent.ConsentedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard consent: %w", err)
+	}
+
+	return consent, nil
+}
+
+// GetLeaderboard retrieves the top opted-in users of a ranking
+func (db *DB) GetLeaderboard(ranking string, limit int) ([]*LeaderboardEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+	order, ok := leaderboardOrders[ranking]
+	if !ok {
+		return nil, fmt.Errorf("unknown leaderboard ranking: %s", ranking)
+	}
+
+	query := `
+	SELECT c.chat_id, c.display_name, ` + leaderboardCommits + `, ` + leaderboardStreak + `
+	FROM leaderboard_consents c
+	LEFT JOIN user_insights i ON i.uid = c.chat_id
+	ORDER BY ` + order + `
+	LIMIT $1
+	`
+
+	rows, err := db.conn.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*LeaderboardEntry
+	for rows.Next() {
+		entry := &LeaderboardEntry{}
+		if err := rows.Scan(&entry.ChatID, &entry.DisplayName, &entry.Commits, &entry.Streak); err != nil {
+			return nil, fmt.Errorf("failed to scan leaderboard entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating leaderboard: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetLeaderboardEntry retrieves the standing of an opted-in user, nil if they haven't joined
+func (db *DB) GetLeaderboardEntry(chatID int64) (*LeaderboardEntry, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT c.chat_id, c.display_name, ` + leaderboardCommits + `, ` + leaderboardStreak + `
+	FROM leaderboard_consents c
+	LEFT JOIN user_insights i ON i.uid = c.chat_id
+	WHERE c.chat_id = $1
+	`
+
+	entry := &LeaderboardEntry{}
+	err := db.conn.QueryRow(query, chatID).Scan(&entry.ChatID, &entry.DisplayName, &entry.Commits, &entry.Streak)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get leaderboard entry: %w", err)
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,201 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Maintenance methods, run by operators through cmd/msg2gitctl
+
+// encryptedColumns lists the columns encrypted with TOKEN_PASSWORD, as table and column
+var encryptedColumns = [][2]string{
+	{"users", "github_token"},
+	{"users", "llm_token"},
+	{"users", "note_key"},
+	{"webhooks", "secret"},
+}
+
+// Migrate creates missing tables, columns and indexes; NewDB already runs it on every start
+func (db *DB) Migrate() error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+	if err := db.initTables(); err != nil {
+		return fmt.Errorf("failed to migrate: %w", err)
+	}
+	return nil
+}
+
+// ListUsers retrieves up to limit users, newest first, with their active premium level
+func (db *DB) ListUsers(limit int) ([]*UserSummary, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT u.chat_id, u.username, u.github_repo,
+		COALESCE(CASE WHEN p.expire_at = -1 OR p.expire_at > EXTRACT(EPOCH FROM NOW()) THEN p.level END, 0),
+		(SELECT MAX(c.created_at) FROM commit_log c WHERE c.chat_id = u.chat_id),
+		u.created_at
+	FROM users u
+	LEFT JOIN premium_user p ON p.uid = u.chat_id
+	ORDER BY u.created_at DESC
+	LIMIT $1
+	`
+
+	rows, err := db.conn.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*UserSummary
+	for rows.Next() {
+		user := &UserSummary{}
+		var lastCommit sql.NullTime
+		if err := rows.Scan(&user.ChatID, &user.Username, &user.GitHubRepo, &user.PremiumLevel, &lastCommit, &user.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		if lastCommit.Valid {
+			user.LastCommitAt = lastCommit.Time
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// RotateEncryptionKey re-encrypts every secret with newPassword in one transaction and switches
+// to it. Values the current password can't decrypt are left alone and counted as skipped.
+func (db *DB) RotateEncryptionKey(newPassword string) (*KeyRotationResult, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	next := NewEncryptionManager(newPassword)
+	result := &KeyRotationResult{}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin key rotation: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, column := range encryptedColumns {
+		table, name := column[0], column[1]
+		rows, err := tx.Query(fmt.Sprintf(`SELECT id, %s FROM %s WHERE %s <> ''`, name, table, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s.%s: %w", table, name, err)
+		}
+
+		updates := make(map[int64]string)
+		for rows.Next() {
+			var id int64
+			var value string
+			if err := rows.Scan(&id, &value); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s.%s: %w", table, name, err)
+			}
+			rotated, ok, err := rotateSecret(db.encryptionManager, next, value)
+			if err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to re-encrypt %s.%s of row %d: %w", table, name, id, err)
+			}
+			if !ok {
+				result.Skipped++
+				continue
+			}
+			updates[id] = rotated
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read %s.%s: %w", table, name, err)
+		}
+
+		for id, value := range updates {
+			if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET %s = $2 WHERE id = $1`, table, name), id, value); err != nil {
+				return nil, fmt.Errorf("failed to update %s.%s: %w", table, name, err)
+			}
+			result.Rotated++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit key rotation: %w", err)
+	}
+	db.encryptionManager = next
+	return result, nil
+}
+
+// rotateSecret decrypts value with current and encrypts it with next, false if current can't
+// decrypt it
+func rotateSecret(current, next *EncryptionManager, value string) (string, bool, error) {
+	plaintext, err := current.Decrypt(value)
+	if err != nil {
+		return "", false, nil
+	}
+	rotated, err := next.Encrypt(plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return rotated, true, nil
+}
+
+// RequeueBackgroundFailures marks background failures recorded since since as not reported, so
+// the next failure digests report them again. A chatID of 0 requeues the failures of every user.
+func (db *DB) RequeueBackgroundFailures(chatID int64, since time.Time) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	UPDATE background_failures SET notified_at = NULL
+	WHERE notified_at IS NOT NULL AND created_at >= $2 AND ($1::bigint = 0 OR chat_id = $1)
+	`
+	result, err := db.conn.Exec(query, chatID, since)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue background failures: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// RecomputeInsights repairs the commit counts and streaks of user_insights from commit_log:
+// counts never go below the logged commits and streaks follow the logged commit days. A chatID of
+// 0 recomputes every user. Returns the number of users updated.
+func (db *DB) RecomputeInsights(chatID int64) (int64, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	WITH days AS (
+		SELECT chat_id, created_at::date AS day, COUNT(*) AS commits
+		FROM commit_log WHERE $1::bigint = 0 OR chat_id = $1
+		GROUP BY chat_id, created_at::date
+	), runs AS (
+		SELECT chat_id, day, commits, day - (ROW_NUMBER() OVER (PARTITION BY chat_id ORDER BY day))::int AS run
+		FROM days
+	), streaks AS (
+		SELECT chat_id, run, COUNT(*) AS length, MAX(day) AS last_day
+		FROM runs GROUP BY chat_id, run
+	), summary AS (
+		SELECT s.chat_id, MAX(s.length) AS best, MAX(s.last_day) AS last_day,
+			(SELECT c.length FROM streaks c WHERE c.chat_id = s.chat_id ORDER BY c.last_day DESC LIMIT 1) AS current,
+			(SELECT SUM(d.commits) FROM days d WHERE d.chat_id = s.chat_id) AS commits
+		FROM streaks s GROUP BY s.chat_id
+	)
+	UPDATE user_insights SET
+		commit_cnt = GREATEST(user_insights.commit_cnt, summary.commits),
+		streak_days = summary.current,
+		best_streak = GREATEST(user_insights.best_streak, summary.best),
+		last_commit_date = summary.last_day,
+		update_time = NOW()
+	FROM summary
+	WHERE user_insights.uid = summary.chat_id
+	`
+	result, err := db.conn.Exec(query, chatID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to recompute insights: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRotateSecret(t *testing.T) {
+	current := NewEncryptionManager("old-password")
+	next := NewEncryptionManager("new-password")
+
+	encrypted, err := current.Encrypt("ghp_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated, ok, err := rotateSecret(current, next, encrypted)
+	if err != nil || !ok {
+		t.Fatalf("rotateSecret() = %v, %v", ok, err)
+	}
+	if plaintext, err := next.Decrypt(rotated); err != nil || plaintext != "ghp_token" {
+		t.Errorf("Decrypt() with the new password = %q, %v", plaintext, err)
+	}
+
+	// A value encrypted with another password is skipped
+	foreign, err := NewEncryptionManager("other-password").Encrypt("ghp_token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, err := rotateSecret(current, next, foreign); ok || err != nil {
+		t.Errorf("rotateSecret() of a foreign value = %v, %v, want skipped", ok, err)
+	}
+
+	// Plaintext values get encrypted when encryption is turned on
+	rotated, ok, err = rotateSecret(nil, next, "ghp_plain")
+	if err != nil || !ok {
+		t.Fatalf("rotateSecret() of plaintext = %v, %v", ok, err)
+	}
+	if plaintext, err := next.Decrypt(rotated); err != nil || plaintext != "ghp_plain" {
+		t.Errorf("Decrypt() of encrypted plaintext = %q, %v", plaintext, err)
+	}
+}
+
+func TestDB_RotateEncryptionKey(t *testing.T) {
+	dsn := getTestDSN()
+	if dsn == "" {
+		t.Skip("Skipping database tests - no TEST_POSTGRES_DSN environment variable set")
+	}
+
+	db, err := NewDB(dsn, "old-password")
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	defer db.Close()
+
+	chatID := int64(987650101)
+	db.DeleteUser(chatID)
+	defer db.DeleteUser(chatID)
+	if _, err := db.CreateUser(chatID, "rotatetest"); err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	if err := db.UpdateUserGitHubConfig(chatID, "ghp_rotate", "https://github.com/test/repo"); err != nil {
+		t.Fatalf("Failed to set token: %v", err)
+	}
+
+	result, err := db.RotateEncryptionKey("new-password")
+	if err != nil || result.Rotated == 0 {
+		t.Fatalf("RotateEncryptionKey() = %+v, %v", result, err)
+	}
+
+	rotated, err := NewDB(dsn, "new-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rotated.Close()
+	user, err := rotated.GetUserByChatID(chatID)
+	if err != nil || user.GitHubToken != "ghp_rotate" {
+		t.Errorf("GitHubToken with the new password = %v, %v", user, err)
+	}
+}
+
+func TestDB_RequeueAndRecompute(t *testing.T) {
+	dsn := getTestDSN()
+	if dsn == "" {
+		t.Skip("Skipping database tests - no TEST_POSTGRES_DSN environment variable set")
+	}
+
+	db, err := NewDB(dsn, "")
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	defer db.Close()
+
+	chatID := int64(987650102)
+	if err := db.RecordBackgroundFailure(chatID, "feed", "timeout"); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := db.GetPendingBackgroundFailures(chatID)
+	if err != nil || len(pending) == 0 {
+		t.Fatalf("GetPendingBackgroundFailures() = %v, %v", pending, err)
+	}
+	if err := db.MarkBackgroundFailuresNotified(chatID, pending[len(pending)-1].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	count, err := db.RequeueBackgroundFailures(chatID, time.Now().Add(-time.Hour))
+	if err != nil || count == 0 {
+		t.Errorf("RequeueBackgroundFailures() = %d, %v", count, err)
+	}
+	if pending, err := db.GetPendingBackgroundFailures(chatID); err != nil || len(pending) == 0 {
+		t.Errorf("GetPendingBackgroundFailures() after requeue = %v, %v", pending, err)
+	}
+
+	if _, err := db.RecomputeInsights(chatID); err != nil {
+		t.Errorf("RecomputeInsights() error = %v", err)
+	}
+	if err := db.Migrate(); err != nil {
+		t.Errorf("Migrate() error = %v", err)
+	}
+}
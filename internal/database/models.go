@@ -3,6 +3,8 @@ package database
 import (
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"strings"
 	"time"
 )
 
@@ -16,12 +18,34 @@ type User struct {
 	LLMToken            string    `db:"llm_token" json:"llm_token"`
 	LLMSwitch           bool      `db:"llm_switch" json:"llm_switch"`
 	LLMMultimodalSwitch bool      `db:"llm_multimodal_switch" json:"llm_multimodal_switch"`
-	CustomFiles         string    `db:"custom_files" json:"custom_files"` // JSON array of custom file paths
-	Committer           string    `db:"committer" json:"committer"`       // Custom commit author
+	CustomFiles         string    `db:"custom_files" json:"custom_files"`                 // JSON array of custom file paths
+	Committer           string    `db:"committer" json:"committer"`                       // Custom commit author
+	PrivateRepo         string    `db:"private_repo" json:"private_repo"`                 // Repository for private entries
+	GitHubAPIURL        string    `db:"github_api_url" json:"github_api_url"`             // GitHub Enterprise Server API URL, empty for github.com
+	IssueArchiveDays    int       `db:"issue_archive_days" json:"issue_archive_days"`     // Days closed issues stay in issue.md, 0 archives on the next sync
+	IssueArchiveYearly  bool      `db:"issue_archive_yearly" json:"issue_archive_yearly"` // Archive closed issues into one file per year
+	SyncCommitMode      string    `db:"sync_commit_mode" json:"sync_commit_mode"`         // SyncCommitModeSquash or SyncCommitModePerFile
+	NoreplyEmail        bool      `db:"noreply_email" json:"noreply_email"`               // GitHub OAuth commits with the GitHub noreply address
+	GitHubLogin         string    `db:"github_login" json:"github_login"`                 // GitHub account of the token, empty if unknown
+	GitHubUserID        int64     `db:"github_user_id" json:"github_user_id"`             // GitHub account ID of the token, 0 if unknown
+	BotCommitter        bool      `db:"bot_committer" json:"bot_committer"`               // Commits are authored by the user but committed by the bot identity
+	SourceFooter        bool      `db:"source_footer" json:"source_footer"`               // Notes end with a link back to the Telegram message
+	LLMTaskModels       string    `db:"llm_task_models" json:"llm_task_models"`           // Personal LLM models per task, "tagging=a,b;summary=c", see config.ParseTaskModels
+	MoodTracking        bool      `db:"mood_tracking" json:"mood_tracking"`               // Notes are tagged with a mood detected by the LLM
+	CommitBranch        string    `db:"commit_branch" json:"commit_branch"`               // Branch notes are committed to, empty for the repository's default branch
+	ReadmeTOC           bool      `db:"readme_toc" json:"readme_toc"`                     // Whether README.md of the notes repository gets a generated table of contents
+	AutoRoute           bool      `db:"auto_route" json:"auto_route"`                     // Notes are committed to the custom file the LLM picks when it's confident enough
+	PlainMode           bool      `db:"plain_mode" json:"plain_mode"`                     // Replies are sent as plain text without emoji or glyph art, for screen readers
 	CreatedAt           time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt           time.Time `db:"updated_at" json:"updated_at"`
 }
 
+// Sync commit modes: how /sync commits the files it changes
+const (
+	SyncCommitModeSquash  = "squash"   // one commit for all files
+	SyncCommitModePerFile = "per_file" // one commit per file
+)
+
 // UserConfig represents the configuration that can be updated by users
 type UserConfig struct {
 	GitHubToken string `json:"github_token"`
@@ -34,6 +58,11 @@ func (u *User) HasGitHubConfig() bool {
 	return u.GitHubToken != "" && u.GitHubRepo != ""
 }
 
+// HasPrivateRepo checks if user has configured a repository for private entries
+func (u *User) HasPrivateRepo() bool {
+	return u.PrivateRepo != ""
+}
+
 // HasLLMConfig checks if user has complete LLM configuration
 func (u *User) HasLLMConfig() bool {
 	return u.LLMToken != ""
@@ -167,6 +196,346 @@ func (u *User) GetPinnedFiles() []string {
 	return pinned
 }
 
+// FeatureFlag controls gradual rollout of a bot feature
+type FeatureFlag struct {
+	Name        string    `db:"name" json:"name"`
+	Enabled     bool      `db:"enabled" json:"enabled"`       // Master switch, nothing is enabled when false
+	Percentage  int       `db:"percentage" json:"percentage"` // 0-100 rollout by stable chat ID hash
+	MinTier     int       `db:"min_tier" json:"min_tier"`     // Minimum premium level required (0 = everyone)
+	UserIDs     string    `db:"user_ids" json:"user_ids"`     // JSON array of chat IDs that always get the feature
+	Description string    `db:"description" json:"description"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// GetUserIDs returns the allowlisted chat IDs as a slice
+func (f *FeatureFlag) GetUserIDs() []int64 {
+	var ids []int64
+	if f.UserIDs == "" {
+		return ids
+	}
+
+	if err := json.Unmarshal([]byte(f.UserIDs), &ids); err != nil {
+		return []int64{}
+	}
+
+	return ids
+}
+
+// SetUserIDs sets the allowlisted chat IDs from a slice
+func (f *FeatureFlag) SetUserIDs(ids []int64) error {
+	if ids == nil {
+		ids = []int64{}
+	}
+
+	data, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	f.UserIDs = string(data)
+	return nil
+}
+
+// IsEnabledFor evaluates the flag for a user: allowlist first, then tier, then percentage rollout
+func (f *FeatureFlag) IsEnabledFor(chatID int64, premiumLevel int) bool {
+	if f == nil || !f.Enabled {
+		return false
+	}
+
+	for _, id := range f.GetUserIDs() {
+		if id == chatID {
+			return true
+		}
+	}
+
+	if premiumLevel < f.MinTier {
+		return false
+	}
+
+	if f.Percentage >= 100 {
+		return true
+	}
+	if f.Percentage <= 0 {
+		return false
+	}
+
+	return featureRolloutBucket(f.Name, chatID) < f.Percentage
+}
+
+// featureRolloutBucket maps a user to a stable 0-99 bucket per flag, so raising the
+// percentage only adds users and each flag rolls out to a different subset
+func featureRolloutBucket(flagName string, chatID int64) int {
+	h := fnv.New32a()
+	h.Write([]byte(fmt.Sprintf("%s:%d", flagName, chatID)))
+	return int(h.Sum32() % 100)
+}
+
+// TrashedFile records a custom file moved to the repository trash folder
+type TrashedFile struct {
+	ID           int64     `db:"id" json:"id"`
+	ChatID       int64     `db:"chat_id" json:"chat_id"`
+	Repo         string    `db:"repo" json:"repo"` // Repository the file was trashed in, empty for older records
+	OriginalPath string    `db:"original_path" json:"original_path"`
+	TrashPath    string    `db:"trash_path" json:"trash_path"`
+	TrashedAt    time.Time `db:"trashed_at" json:"trashed_at"`
+	ExpiresAt    time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// CommitLogEntry records a commit made by the bot on behalf of a user
+type CommitLogEntry struct {
+	ID        int64     `db:"id" json:"id"`
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Repo      string    `db:"repo" json:"repo"` // owner/repo pushed to, "" for old entries
+	Filename  string    `db:"filename" json:"filename"`
+	CommitSHA string    `db:"commit_sha" json:"commit_sha"`
+	CommitURL string    `db:"commit_url" json:"commit_url"`
+	FileSize  int64     `db:"file_size" json:"file_size"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Webhook is an outgoing webhook a user registered to receive bot events
+type Webhook struct {
+	ID        int64     `db:"id" json:"id"`
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	URL       string    `db:"url" json:"url"`
+	Secret    string    `db:"secret" json:"-"`      // HMAC signing secret, encrypted at rest
+	Events    string    `db:"events" json:"events"` // Comma separated event names, empty means all events
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// GetEvents returns the subscribed event names
+func (w *Webhook) GetEvents() []string {
+	var events []string
+	for _, event := range strings.Split(w.Events, ",") {
+		if event = strings.TrimSpace(event); event != "" {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Subscribes reports whether the webhook should receive the event
+func (w *Webhook) Subscribes(event string) bool {
+	events := w.GetEvents()
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Feed is an RSS/Atom feed a user subscribed to for daily digests
+type Feed struct {
+	ID            int64     `db:"id" json:"id"`
+	ChatID        int64     `db:"chat_id" json:"chat_id"`
+	URL           string    `db:"url" json:"url"`
+	Title         string    `db:"title" json:"title"`
+	Summarize     bool      `db:"summarize" json:"summarize"`       // Summarize new items with the LLM
+	LastItemAt    time.Time `db:"last_item_at" json:"last_item_at"` // Publish time of the newest item already digested
+	LastFetchedAt time.Time `db:"last_fetched_at" json:"last_fetched_at"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
+
+// ChannelRoute commits every post of a Telegram channel to a file or directory of its owner's repository
+type ChannelRoute struct {
+	ID         int64     `db:"id" json:"id"`
+	ChatID     int64     `db:"chat_id" json:"chat_id"`       // Owner whose repository receives the posts
+	ChannelID  int64     `db:"channel_id" json:"channel_id"` // Telegram channel chat ID
+	Title      string    `db:"title" json:"title"`
+	TargetPath string    `db:"target_path" json:"target_path"` // File, or directory ending in "/" for one file per post
+	SkipBots   bool      `db:"skip_bots" json:"skip_bots"`     // Ignore posts sent via or forwarded from bots
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// CannedReply is a reusable issue comment saved by a user
+type CannedReply struct {
+	ID        int64     `db:"id" json:"id"`
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Name      string    `db:"name" json:"name"` // Short label shown on the picker button
+	Body      string    `db:"body" json:"body"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// BackgroundFailure is a failed background operation, reported to the user in the next failure digest
+type BackgroundFailure struct {
+	ID         int64      `db:"id" json:"id"`
+	ChatID     int64      `db:"chat_id" json:"chat_id"`
+	Source     string     `db:"source" json:"source"` // What failed, e.g. "feed" or "webhook"
+	Detail     string     `db:"detail" json:"detail"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	NotifiedAt *time.Time `db:"notified_at" json:"notified_at"` // Set once the failure was included in a digest
+}
+
+// ForumTopic maps a topic of a forum group to the file its messages are committed to
+type ForumTopic struct {
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	ThreadID  int       `db:"thread_id" json:"thread_id"` // Telegram message_thread_id of the topic
+	Name      string    `db:"name" json:"name"`           // Topic name when the mapping was created
+	FilePath  string    `db:"file_path" json:"file_path"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// DailyPin is a user's opt-in to a pinned summary of the previous day
+type DailyPin struct {
+	ChatID       int64      `db:"chat_id" json:"chat_id"`
+	MessageID    int        `db:"message_id" json:"message_id"` // Currently pinned summary, 0 if none
+	LastPinnedAt *time.Time `db:"last_pinned_at" json:"last_pinned_at"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// OperationPause stops the user's GitHub operations after an access anomaly until it is
+// confirmed or expires
+type OperationPause struct {
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Reason    string    `db:"reason" json:"reason"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// LeaderboardConsent is a user's opt-in to the community leaderboard under a pseudonym
+type LeaderboardConsent struct {
+	ChatID      int64     `db:"chat_id" json:"chat_id"`
+	DisplayName string    `db:"display_name" json:"display_name"` // Random pseudonym, never the Telegram name
+	ConsentedAt time.Time `db:"consented_at" json:"consented_at"`
+}
+
+// LeaderboardEntry is an opted-in user's standing on the leaderboard
+type LeaderboardEntry struct {
+	ChatID      int64  `json:"-"`
+	DisplayName string `json:"display_name"`
+	Commits     int64  `json:"commits"`
+	Streak      int    `json:"streak"` // Consecutive days with commits, 0 once a day was missed
+}
+
+// Streak is a user's run of consecutive days with commits
+type Streak struct {
+	Current       int  `json:"current"`        // 0 once a day was missed
+	Best          int  `json:"best"`           // Longest streak so far
+	CapturedToday bool `json:"captured_today"` // Whether today already extended the streak
+}
+
+// StreakReminder is a user's opt-in to an evening reminder before their streak ends
+type StreakReminder struct {
+	ChatID       int64      `db:"chat_id" json:"chat_id"`
+	RemindMinute int        `db:"remind_minute" json:"remind_minute"` // Minutes after local midnight
+	Timezone     string     `db:"timezone" json:"timezone"`           // IANA name, e.g. Europe/Berlin
+	LastSentOn   *time.Time `db:"last_sent_on" json:"last_sent_on"`   // Local day of the last reminder
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// CustomFileTemplate is the template a custom file starts with when it is created
+type CustomFileTemplate struct {
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Filename  string    `db:"filename" json:"filename"`
+	Template  string    `db:"template" json:"template"` // Markdown with {{title}}, {{date}} and {{entries}} placeholders
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Chat access rules set at runtime with /admin access, on top of ALLOWED_CHAT_IDS / BLOCKED_CHAT_IDS
+const (
+	ChatAccessAllow = "allow"
+	ChatAccessBlock = "block"
+)
+
+// ChatAccess is an operator's rule allowing or blocking a chat
+type ChatAccess struct {
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Access    string    `db:"access" json:"access"`         // ChatAccessAllow or ChatAccessBlock
+	UpdatedBy int64     `db:"updated_by" json:"updated_by"` // Admin chat that set the rule
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// QuietHours is a user's daily window during which non-essential messages are deferred
+type QuietHours struct {
+	ChatID      int64     `db:"chat_id" json:"chat_id"`
+	StartMinute int       `db:"start_minute" json:"start_minute"` // Minutes after local midnight
+	EndMinute   int       `db:"end_minute" json:"end_minute"`     // Before StartMinute if the window spans midnight
+	Timezone    string    `db:"timezone" json:"timezone"`         // IANA name, e.g. Europe/Berlin
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// DeferredMessage is a non-essential message held back during the user's quiet hours
+type DeferredMessage struct {
+	ID        int64     `db:"id" json:"id"`
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Kind      string    `db:"kind" json:"kind"`
+	Text      string    `db:"text" json:"text"` // HTML
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// ComposeSession is a draft collecting several messages into one entry, see /compose
+type ComposeSession struct {
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	Parts     string    `db:"parts" json:"parts"` // JSON array of ComposePart
+	StartedAt time.Time `db:"started_at" json:"started_at"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// ComposePart is one message of a compose session
+type ComposePart struct {
+	Text        string `json:"text,omitempty"`          // Markdown text, or the caption of a photo
+	PhotoFileID string `json:"photo_file_id,omitempty"` // Telegram file ID of a photo
+}
+
+// GetParts returns the messages collected in the session
+func (s *ComposeSession) GetParts() []ComposePart {
+	var parts []ComposePart
+	if err := json.Unmarshal([]byte(s.Parts), &parts); err != nil {
+		return nil
+	}
+	return parts
+}
+
+// WeeklyChangelog is a user's opt-in to a weekly GitHub issue summarizing the week's captures
+type WeeklyChangelog struct {
+	ChatID       int64      `db:"chat_id" json:"chat_id"`
+	IssueNumber  int        `db:"issue_number" json:"issue_number"` // Issue of WeekStart, 0 if none was opened
+	WeekStart    *time.Time `db:"week_start" json:"week_start"`     // Week of the last posted issue
+	LastPostedAt *time.Time `db:"last_posted_at" json:"last_posted_at"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+}
+
+// InsightReport is a user's opt-in to a weekly or monthly report of their insights. The counts are
+// the user's insights when the last report was sent, the next report shows the difference.
+type InsightReport struct {
+	ChatID        int64      `db:"chat_id" json:"chat_id"`
+	Period        string     `db:"period" json:"period"`                 // "weekly" or "monthly"
+	CommitToRepo  bool       `db:"commit_to_repo" json:"commit_to_repo"` // Also commit each report to reports/
+	CommitCnt     int64      `db:"commit_cnt" json:"commit_cnt"`
+	IssueCnt      int64      `db:"issue_cnt" json:"issue_cnt"`
+	IssueCloseCnt int64      `db:"issue_close_cnt" json:"issue_close_cnt"`
+	TokenInput    int64      `db:"token_input" json:"token_input"`
+	TokenOutput   int64      `db:"token_output" json:"token_output"`
+	RepoSize      float64    `db:"repo_size" json:"repo_size"` // MB
+	LastSentAt    *time.Time `db:"last_sent_at" json:"last_sent_at"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// HistoryCompression is a user's consent to have old bot commits squashed into daily rollups,
+// proposed on a maintenance branch with a pull request
+type HistoryCompression struct {
+	ChatID        int64      `db:"chat_id" json:"chat_id"`
+	OlderThanDays int        `db:"older_than_days" json:"older_than_days"`
+	PullNumber    int        `db:"pull_number" json:"pull_number"` // Pull request of the last run, 0 if none was opened
+	LastRunAt     *time.Time `db:"last_run_at" json:"last_run_at"`
+	ConsentedAt   time.Time  `db:"consented_at" json:"consented_at"`
+}
+
+// APIKey authenticates a user's requests to the capture API. Only the SHA-256 hash of the key is stored.
+type APIKey struct {
+	ID         int64      `db:"id" json:"id"`
+	ChatID     int64      `db:"chat_id" json:"chat_id"`
+	KeyHash    string     `db:"key_hash" json:"-"`
+	KeyPrefix  string     `db:"key_prefix" json:"key_prefix"` // First characters of the key, for display
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at" json:"last_used_at"`
+}
+
 // GetCustomFileMultiplier returns the correct custom file multiplier for a premium level
 func GetCustomFileMultiplier(premiumLevel int) int {
 	switch premiumLevel {
@@ -227,6 +596,39 @@ func GetImageLimit(premiumLevel int) int64 {
 	return int64(baseImageLimit * GetImageMultiplier(premiumLevel))
 }
 
+// GetFileMultiplier returns the correct attached file multiplier for a premium level
+func GetFileMultiplier(premiumLevel int) int {
+	switch premiumLevel {
+	case 1:
+		return 2 // Coffee: 2x
+	case 2:
+		return 4 // Cake: 4x
+	case 3:
+		return 100 // Sponsor: 100x
+	default:
+		return 1 // Free: 1x
+	}
+}
+
+// GetFileLimit returns the maximum number of attached files based on premium level using multiplier
+func GetFileLimit(premiumLevel int) int64 {
+	const baseFileLimit = 30 // Free tier base: 30 files
+	return int64(baseFileLimit * GetFileMultiplier(premiumLevel))
+}
+
+// GetFileSizeLimit returns the maximum size of one attached file in bytes based on premium level.
+// Telegram doesn't let bots download files larger than 20 MB, so no tier goes beyond it.
+func GetFileSizeLimit(premiumLevel int) int64 {
+	switch premiumLevel {
+	case 1:
+		return 10 << 20 // Coffee: 10 MB
+	case 2, 3:
+		return 20 << 20 // Cake and Sponsor: 20 MB
+	default:
+		return 5 << 20 // Free: 5 MB
+	}
+}
+
 // GetTokenMultiplier returns the correct token multiplier for a premium level
 func GetTokenMultiplier(premiumLevel int) int {
 	switch premiumLevel {
@@ -258,6 +660,9 @@ type PremiumUser struct {
 	CustomerID     string    `db:"customer_id" json:"customer_id"`         // Stripe customer ID
 	BillingPeriod  string    `db:"billing_period" json:"billing_period"`   // monthly/annually
 	IsSubscription bool      `db:"is_subscription" json:"is_subscription"` // true for subscriptions, false for one-time
+	IsTrial        bool      `db:"is_trial" json:"is_trial"`               // true while the level comes from a free trial
+	TrialUsed      bool      `db:"trial_used" json:"trial_used"`           // a user gets one trial
+	ExpiryNotice   string    `db:"expiry_notice" json:"expiry_notice"`     // last tier transition notice sent: ending|expired
 	CreatedAt      time.Time `db:"created_at" json:"created_at"`
 }
 
@@ -286,6 +691,11 @@ func (pu *PremiumUser) IsPremiumUser() bool {
 	return pu.ID > 0 && pu.Level > 0 && !pu.IsExpired()
 }
 
+// IsLapsed reports whether the user had a premium tier (paid or trial) that has expired
+func (pu *PremiumUser) IsLapsed() bool {
+	return pu.ID > 0 && pu.Level > 0 && pu.IsExpired()
+}
+
 // UserInsights represents usage analytics for a user
 type UserInsights struct {
 	ID            int       `db:"id" json:"id"`
@@ -293,6 +703,7 @@ type UserInsights struct {
 	CommitCnt     int64     `db:"commit_cnt" json:"commit_cnt"`           // Count of total messages stored to GitHub
 	IssueCnt      int64     `db:"issue_cnt" json:"issue_cnt"`             // Count of created issues
 	ImageCnt      int64     `db:"image_cnt" json:"image_cnt"`             // Count of uploaded images
+	FileCnt       int64     `db:"file_cnt" json:"file_cnt"`               // Count of attached files
 	RepoSize      float64   `db:"repo_size" json:"repo_size"`             // Current repo actual size in MB
 	ResetCnt      int64     `db:"reset_cnt" json:"reset_cnt"`             // Count of usage resets
 	IssueCmtCnt   int64     `db:"issue_cmt_cnt" json:"issue_cmt_cnt"`     // Count of issue comments
@@ -310,6 +721,7 @@ type UserUsage struct {
 	UID         int64     `db:"uid" json:"uid"`                   // User chat ID
 	IssueCnt    int64     `db:"issue_cnt" json:"issue_cnt"`       // Count of created issues
 	ImageCnt    int64     `db:"image_cnt" json:"image_cnt"`       // Count of uploaded images
+	FileCnt     int64     `db:"file_cnt" json:"file_cnt"`         // Count of attached files
 	TokenInput  int64     `db:"token_input" json:"token_input"`   // Count of LLM input tokens consumed
 	TokenOutput int64     `db:"token_output" json:"token_output"` // Count of LLM output tokens consumed
 	UpdateTime  time.Time `db:"update_time" json:"update_time"`
@@ -341,3 +753,51 @@ type SubscriptionResult struct {
 	PremiumUser            *PremiumUser `json:"premium_user"`
 	ReplacedSubscriptionID string       `json:"replaced_subscription_id,omitempty"` // Set if a subscription was replaced
 }
+
+// Tenant groups chats of a community hosted on a shared bot, with aggregate quotas
+type Tenant struct {
+	ID          int       `db:"id" json:"id"`
+	Name        string    `db:"name" json:"name"`
+	DiskQuotaMB float64   `db:"disk_quota_mb" json:"disk_quota_mb"` // Combined repository size limit (0 = unlimited)
+	TokenQuota  int64     `db:"token_quota" json:"token_quota"`     // Combined default LLM token limit (0 = unlimited)
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+}
+
+// TenantMember assigns a chat to a tenant, a chat belongs to at most one tenant
+type TenantMember struct {
+	ChatID   int64     `db:"chat_id" json:"chat_id"`
+	TenantID int       `db:"tenant_id" json:"tenant_id"`
+	IsAdmin  bool      `db:"is_admin" json:"is_admin"` // Can manage the tenant's members with /tenant
+	AddedAt  time.Time `db:"added_at" json:"added_at"`
+}
+
+// TenantUsage is the combined usage of a tenant's members
+type TenantUsage struct {
+	Members    int64   `json:"members"`
+	RepoSizeMB float64 `json:"repo_size_mb"` // Sum of last known repository sizes
+	TokensUsed int64   `json:"tokens_used"`  // Default LLM tokens in the current usage period
+}
+
+// UserSummary is a user as listed by operators
+type UserSummary struct {
+	ChatID       int64     `db:"chat_id" json:"chat_id"`
+	Username     string    `db:"username" json:"username"`
+	GitHubRepo   string    `db:"github_repo" json:"github_repo"`
+	PremiumLevel int       `db:"premium_level" json:"premium_level"` // Active premium level, 0 if none or expired
+	LastCommitAt time.Time `json:"last_commit_at,omitempty"`         // Zero if no commit was logged
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+}
+
+// KeyRotationResult counts the secrets handled by a key rotation
+type KeyRotationResult struct {
+	Rotated int `json:"rotated"`
+	Skipped int `json:"skipped"` // Not decryptable with the current password, left unchanged
+}
+
+// HandoverCode is a pending handover of a chat's configuration and history to another chat; only
+// the hash of the code is stored
+type HandoverCode struct {
+	ChatID    int64     `db:"chat_id" json:"chat_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
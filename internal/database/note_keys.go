@@ -0,0 +1,75 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Note key methods. The key is derived from the user's /encrypt passphrase, which is never stored,
+// and is kept encrypted like the GitHub and LLM tokens. It stays out of User so it isn't loaded
+// with every lookup.
+
+// GetUserNoteKey retrieves the user's decrypted note key, "" if notes aren't encrypted
+func (db *DB) GetUserNoteKey(chatID int64) (string, error) {
+	if db == nil {
+		return "", fmt.Errorf("database not configured")
+	}
+
+	var encryptedKey string
+	err := db.conn.QueryRow(`SELECT note_key FROM users WHERE chat_id = $1`, chatID).Scan(&encryptedKey)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get note key: %w", err)
+	}
+	if encryptedKey == "" {
+		return "", nil
+	}
+
+	key, err := db.encryptionManager.Decrypt(encryptedKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt note key: %w", err)
+	}
+	return key, nil
+}
+
+// UpdateUserNoteKey stores the user's note key, "" turns note encryption off
+func (db *DB) UpdateUserNoteKey(chatID int64, key string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	encryptedKey := ""
+	if key != "" {
+		var err error
+		encryptedKey, err = db.encryptionManager.Encrypt(key)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt note key: %w", err)
+		}
+	}
+
+	result, err := db.conn.Exec(`UPDATE users SET note_key = $2, updated_at = $3 WHERE chat_id = $1`, chatID, encryptedKey, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to update note key: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	logger.Info("Updated note encryption", map[string]interface{}{
+		"chat_id": chatID,
+		"enabled": key != "",
+	})
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Operation pause methods. Expired pauses are never returned and are replaced by the next pause.
+
+const operationPauseColumns = `chat_id, reason, created_at, expires_at`
+
+// PauseOperations pauses the user's GitHub operations until expiresAt. An active pause is kept
+// as it is; returns whether a new pause was started.
+func (db *DB) PauseOperations(chatID int64, reason string, expiresAt time.Time) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO operation_pauses (chat_id, reason, created_at, expires_at)
+	VALUES ($1, $2, NOW(), $3)
+	ON CONFLICT (chat_id) DO UPDATE SET reason = EXCLUDED.reason, created_at = NOW(), expires_at = EXCLUDED.expires_at
+	WHERE operation_pauses.expires_at <= NOW()
+	`
+
+	result, err := db.conn.Exec(query, chatID, reason, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("failed to pause operations: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetOperationPause retrieves the user's active pause, nil if operations aren't paused
+func (db *DB) GetOperationPause(chatID int64) (*OperationPause, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	pause := &OperationPause{}
+	err := db.conn.QueryRow(`SELECT `+operationPauseColumns+` FROM operation_pauses WHERE chat_id = $1 AND expires_at > NOW()`, chatID).Scan(
+		&pause.ChatID, &pause.Reason, &pause.CreatedAt, &pause.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operation pause: %w", err)
+	}
+
+	return pause, nil
+}
+
+// ResumeOperations ends the user's pause, returning whether operations were paused
+func (db *DB) ResumeOperations(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM operation_pauses WHERE chat_id = $1 AND expires_at > NOW()`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to resume operations: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
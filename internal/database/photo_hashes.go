@@ -0,0 +1,44 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Photo hash methods: the CDN URL of every photo a user uploaded to a repository ("owner/repo"),
+// by the SHA-256 of its bytes, so uploading the same image to it again reuses the URL instead of
+// creating another asset.
+
+// GetPhotoURL retrieves the CDN URL of the user's photo in repo with the content hash, "" if it is new
+func (db *DB) GetPhotoURL(chatID int64, repo, contentHash string) (string, error) {
+	if db == nil {
+		return "", fmt.Errorf("database not configured")
+	}
+
+	var url string
+	err := db.conn.QueryRow(`SELECT url FROM photo_hashes WHERE chat_id = $1 AND repo = $2 AND content_hash = $3`, chatID, repo, contentHash).Scan(&url)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get photo url: %w", err)
+	}
+	return url, nil
+}
+
+// SavePhotoURL stores the CDN URL of the user's photo in repo with the content hash
+func (db *DB) SavePhotoURL(chatID int64, repo, contentHash, url string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO photo_hashes (chat_id, repo, content_hash, url, created_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (chat_id, repo, content_hash) DO UPDATE SET url = EXCLUDED.url, created_at = NOW()
+	`
+	if _, err := db.conn.Exec(query, chatID, repo, contentHash, url); err != nil {
+		return fmt.Errorf("failed to save photo url: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package database
+
+import "testing"
+
+func TestDB_PhotoURL(t *testing.T) {
+	dsn := getTestDSN()
+	if dsn == "" {
+		t.Skip("Skipping database tests - no TEST_POSTGRES_DSN environment variable set")
+	}
+
+	db, err := NewDB(dsn, "")
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	defer db.Close()
+
+	chatID := int64(987670001)
+	hash := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	db.conn.Exec(`DELETE FROM photo_hashes WHERE chat_id = $1`, chatID)
+
+	if url, err := db.GetPhotoURL(chatID, "o/r", hash); err != nil || url != "" {
+		t.Fatalf("GetPhotoURL() of a new photo = %q, %v", url, err)
+	}
+
+	if err := db.SavePhotoURL(chatID, "o/r", hash, "https://github.com/o/r/releases/download/photos/a.jpg"); err != nil {
+		t.Fatalf("SavePhotoURL() error = %v", err)
+	}
+	if url, err := db.GetPhotoURL(chatID, "o/r", hash); err != nil || url != "https://github.com/o/r/releases/download/photos/a.jpg" {
+		t.Errorf("GetPhotoURL() = %q, %v", url, err)
+	}
+	if url, _ := db.GetPhotoURL(chatID+1, "o/r", hash); url != "" {
+		t.Errorf("GetPhotoURL() of another user = %q, want none", url)
+	}
+	if url, _ := db.GetPhotoURL(chatID, "o/other", hash); url != "" {
+		t.Errorf("GetPhotoURL() of another repository = %q, want none", url)
+	}
+}
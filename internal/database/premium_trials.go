@@ -0,0 +1,100 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Premium trial and tier transition methods
+
+const (
+	ExpiryNoticeEnding  = "ending"
+	ExpiryNoticeExpired = "expired"
+)
+
+// StartPremiumTrial grants a time-limited premium level to a user who has never had a trial
+// and has no active premium. Returns nil, nil if the user isn't eligible.
+func (db *DB) StartPremiumTrial(uid int64, username string, level int, expireAt int64) (*PremiumUser, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	now := time.Now()
+	query := `
+	INSERT INTO premium_user (uid, username, level, expire_at, is_trial, trial_used, expiry_notice, created_at)
+	VALUES ($1, $2, $3, $4, true, true, '', $5)
+	ON CONFLICT (uid) DO UPDATE SET username = $2, level = $3, expire_at = $4, is_trial = true, trial_used = true, expiry_notice = ''
+	WHERE premium_user.trial_used = false
+		AND (premium_user.level = 0 OR (premium_user.expire_at <> -1 AND premium_user.expire_at < $6))
+	RETURNING id, uid, username, level, expire_at, is_trial, trial_used, created_at
+	`
+
+	premiumUser := &PremiumUser{}
+	err := db.conn.QueryRow(query, uid, username, level, expireAt, now, now.Unix()).Scan(
+		&premiumUser.ID, &premiumUser.UID, &premiumUser.Username,
+		&premiumUser.Level, &premiumUser.ExpireAt,
+		&premiumUser.IsTrial, &premiumUser.TrialUsed, &premiumUser.CreatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil // Trial already used or premium still active
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to start premium trial: %w", err)
+	}
+
+	logger.Info("Started premium trial", map[string]interface{}{
+		"uid":       uid,
+		"level":     level,
+		"expire_at": expireAt,
+	})
+	return premiumUser, nil
+}
+
+// GetPremiumUsersForExpiryNotice retrieves premium users expiring before the given unix time
+// that haven't been told about their expiry yet
+func (db *DB) GetPremiumUsersForExpiryNotice(expiringBefore int64) ([]*PremiumUser, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, uid, username, level, expire_at, is_subscription, is_trial, expiry_notice
+	FROM premium_user
+	WHERE level > 0 AND expire_at <> -1 AND expire_at < $1 AND expiry_notice <> $2
+	ORDER BY expire_at
+	`
+
+	rows, err := db.conn.Query(query, expiringBefore, ExpiryNoticeExpired)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring premium users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*PremiumUser
+	for rows.Next() {
+		pu := &PremiumUser{}
+		if err := rows.Scan(&pu.ID, &pu.UID, &pu.Username, &pu.Level, &pu.ExpireAt, &pu.IsSubscription, &pu.IsTrial, &pu.ExpiryNotice); err != nil {
+			return nil, fmt.Errorf("failed to scan premium user: %w", err)
+		}
+		users = append(users, pu)
+	}
+
+	return users, rows.Err()
+}
+
+// SetPremiumExpiryNotice records the last expiry notice sent to a user
+func (db *DB) SetPremiumExpiryNotice(uid int64, notice string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if _, err := db.conn.Exec(`UPDATE premium_user SET expiry_notice = $2 WHERE uid = $1`, uid, notice); err != nil {
+		return fmt.Errorf("failed to set expiry notice: %w", err)
+	}
+
+	return nil
+}
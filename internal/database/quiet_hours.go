@@ -0,0 +1,153 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Quiet hours and deferred message methods
+
+const quietHoursColumns = `chat_id, start_minute, end_minute, timezone, created_at, updated_at`
+
+const deferredMessageColumns = `id, chat_id, kind, text, created_at`
+
+// SetQuietHours creates or replaces the user's quiet hours
+func (db *DB) SetQuietHours(chatID int64, startMinute, endMinute int, timezone string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO quiet_hours (chat_id, start_minute, end_minute, timezone, created_at, updated_at)
+	VALUES ($1, $2, $3, $4, NOW(), NOW())
+	ON CONFLICT (chat_id) DO UPDATE SET start_minute = EXCLUDED.start_minute, end_minute = EXCLUDED.end_minute,
+		timezone = EXCLUDED.timezone, updated_at = NOW()
+	`
+	if _, err := db.conn.Exec(query, chatID, startMinute, endMinute, timezone); err != nil {
+		return fmt.Errorf("failed to set quiet hours: %w", err)
+	}
+
+	return nil
+}
+
+// GetQuietHours retrieves the user's quiet hours, nil if none are set
+func (db *DB) GetQuietHours(chatID int64) (*QuietHours, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	quiet := &QuietHours{}
+	err := db.conn.QueryRow(`SELECT `+quietHoursColumns+` FROM quiet_hours WHERE chat_id = $1`, chatID).Scan(
+		&quiet.ChatID, &quiet.StartMinute, &quiet.EndMinute, &quiet.Timezone, &quiet.CreatedAt, &quiet.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quiet hours: %w", err)
+	}
+
+	return quiet, nil
+}
+
+// DeleteQuietHours removes the user's quiet hours, returning whether any were set
+func (db *DB) DeleteQuietHours(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM quiet_hours WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete quiet hours: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// DeferMessage stores a message for delivery after the user's quiet hours
+func (db *DB) DeferMessage(chatID int64, kind, text string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `INSERT INTO deferred_messages (chat_id, kind, text, created_at) VALUES ($1, $2, $3, NOW())`
+	if _, err := db.conn.Exec(query, chatID, kind, text); err != nil {
+		return fmt.Errorf("failed to defer message: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatsWithDeferredMessages retrieves the users with deferred messages
+func (db *DB) GetChatsWithDeferredMessages() ([]int64, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT DISTINCT chat_id FROM deferred_messages ORDER BY chat_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deferred message chats: %w", err)
+	}
+	defer rows.Close()
+
+	var chatIDs []int64
+	for rows.Next() {
+		var chatID int64
+		if err := rows.Scan(&chatID); err != nil {
+			return nil, fmt.Errorf("failed to scan deferred message chat: %w", err)
+		}
+		chatIDs = append(chatIDs, chatID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deferred message chats: %w", err)
+	}
+
+	return chatIDs, nil
+}
+
+// GetDeferredMessages retrieves the user's deferred messages, oldest first
+func (db *DB) GetDeferredMessages(chatID int64) ([]*DeferredMessage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+deferredMessageColumns+` FROM deferred_messages WHERE chat_id = $1 ORDER BY id`, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deferred messages: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*DeferredMessage
+	for rows.Next() {
+		message := &DeferredMessage{}
+		if err := rows.Scan(&message.ID, &message.ChatID, &message.Kind, &message.Text, &message.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan deferred message: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating deferred messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// DeleteDeferredMessages removes the user's deferred messages up to and including lastID once delivered
+func (db *DB) DeleteDeferredMessages(chatID, lastID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if _, err := db.conn.Exec(`DELETE FROM deferred_messages WHERE chat_id = $1 AND id <= $2`, chatID, lastID); err != nil {
+		return fmt.Errorf("failed to delete deferred messages: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Quota alert bookkeeping: each threshold is announced once per limit and period
+
+const (
+	QuotaMetricRepo   = "repo"
+	QuotaMetricIssues = "issues"
+	QuotaMetricImages = "images"
+	QuotaMetricFiles  = "files"
+	QuotaMetricTokens = "tokens"
+)
+
+// RecordQuotaAlert marks a threshold alert as sent and reports whether it should be sent now.
+// It returns false if the same alert was already sent after since.
+func (db *DB) RecordQuotaAlert(chatID int64, metric string, threshold int, limit int64, since time.Time) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO quota_alerts (chat_id, metric, threshold, quota_limit, sent_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	ON CONFLICT (chat_id, metric, threshold, quota_limit) DO UPDATE SET sent_at = NOW()
+	WHERE quota_alerts.sent_at < $5
+	`
+
+	result, err := db.conn.Exec(query, chatID, metric, threshold, limit, since)
+	if err != nil {
+		return false, fmt.Errorf("failed to record quota alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// ClearQuotaAlerts forgets sent alerts for the given metrics so they can fire again
+func (db *DB) ClearQuotaAlerts(chatID int64, metrics ...string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	_, err := db.conn.Exec(`DELETE FROM quota_alerts WHERE chat_id = $1 AND metric = ANY($2)`, chatID, pq.Array(metrics))
+	if err != nil {
+		return fmt.Errorf("failed to clear quota alerts: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Streak methods. Streaks count days by the database's CURRENT_DATE.
+
+const streakReminderColumns = `chat_id, remind_minute, timezone, last_sent_on, created_at`
+
+// nextStreakDays is the streak of user_insights after a commit today: unchanged by a second
+// commit on the same day, extended by a commit the day after, restarted otherwise
+const nextStreakDays = `CASE
+			WHEN user_insights.last_commit_date = CURRENT_DATE THEN user_insights.streak_days
+			WHEN user_insights.last_commit_date = CURRENT_DATE - 1 THEN user_insights.streak_days + 1
+			ELSE 1
+		END`
+
+// GetStreak retrieves the user's streak, zero for users without commits
+func (db *DB) GetStreak(chatID int64) (*Streak, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT CASE WHEN last_commit_date >= CURRENT_DATE - 1 THEN streak_days ELSE 0 END,
+		best_streak, COALESCE(last_commit_date = CURRENT_DATE, FALSE)
+	FROM user_insights WHERE uid = $1
+	`
+
+	streak := &Streak{}
+	err := db.conn.QueryRow(query, chatID).Scan(&streak.Current, &streak.Best, &streak.CapturedToday)
+	if err == sql.ErrNoRows {
+		return streak, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak: %w", err)
+	}
+
+	return streak, nil
+}
+
+// SetStreakReminder creates or replaces the user's evening streak reminder
+func (db *DB) SetStreakReminder(chatID int64, remindMinute int, timezone string) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO streak_reminders (chat_id, remind_minute, timezone, created_at)
+	VALUES ($1, $2, $3, NOW())
+	ON CONFLICT (chat_id) DO UPDATE SET remind_minute = EXCLUDED.remind_minute, timezone = EXCLUDED.timezone
+	`
+	if _, err := db.conn.Exec(query, chatID, remindMinute, timezone); err != nil {
+		return fmt.Errorf("failed to set streak reminder: %w", err)
+	}
+
+	return nil
+}
+
+// GetStreakReminder retrieves the user's streak reminder, nil if it's off
+func (db *DB) GetStreakReminder(chatID int64) (*StreakReminder, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	reminder := &StreakReminder{}
+	err := db.conn.QueryRow(`SELECT `+streakReminderColumns+` FROM streak_reminders WHERE chat_id = $1`, chatID).Scan(
+		&reminder.ChatID, &reminder.RemindMinute, &reminder.Timezone, &reminder.LastSentOn, &reminder.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak reminder: %w", err)
+	}
+
+	return reminder, nil
+}
+
+// DeleteStreakReminder turns the user's streak reminder off, returning whether it was on
+func (db *DB) DeleteStreakReminder(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM streak_reminders WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete streak reminder: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetStreakReminders retrieves every streak reminder
+func (db *DB) GetStreakReminders() ([]*StreakReminder, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT ` + streakReminderColumns + ` FROM streak_reminders ORDER BY chat_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query streak reminders: %w", err)
+	}
+	defer rows.Close()
+
+	var reminders []*StreakReminder
+	for rows.Next() {
+		reminder := &StreakReminder{}
+		if err := rows.Scan(&reminder.ChatID, &reminder.RemindMinute, &reminder.Timezone, &reminder.LastSentOn, &reminder.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan streak reminder: %w", err)
+		}
+		reminders = append(reminders, reminder)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating streak reminders: %w", err)
+	}
+
+	return reminders, nil
+}
+
+// MarkStreakReminderSent records the local day the user was last reminded
+func (db *DB) MarkStreakReminderSent(chatID int64, day time.Time) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if _, err := db.conn.Exec(`UPDATE streak_reminders SET last_sent_on = $2 WHERE chat_id = $1`, chatID, day.Format("2006-01-02")); err != nil {
+		return fmt.Errorf("failed to mark streak reminder sent: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,299 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Tenant methods: operator-defined groups of chats with aggregate quotas
+
+// CreateTenant creates a tenant with unlimited quotas
+func (db *DB) CreateTenant(name string) (*Tenant, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO tenants (name)
+	VALUES ($1)
+	RETURNING id, name, disk_quota_mb, token_quota, created_at
+	`
+
+	tenant := &Tenant{}
+	err := db.conn.QueryRow(query, name).Scan(&tenant.ID, &tenant.Name, &tenant.DiskQuotaMB, &tenant.TokenQuota, &tenant.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+// GetTenants retrieves all tenants ordered by name
+func (db *DB) GetTenants() ([]*Tenant, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT id, name, disk_quota_mb, token_quota, created_at FROM tenants ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenants: %w", err)
+	}
+	defer rows.Close()
+
+	var tenants []*Tenant
+	for rows.Next() {
+		tenant := &Tenant{}
+		if err := rows.Scan(&tenant.ID, &tenant.Name, &tenant.DiskQuotaMB, &tenant.TokenQuota, &tenant.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant: %w", err)
+		}
+		tenants = append(tenants, tenant)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenants: %w", err)
+	}
+
+	return tenants, nil
+}
+
+// GetTenantByName retrieves a tenant by name, returns nil if it doesn't exist
+func (db *DB) GetTenantByName(name string) (*Tenant, error) {
+	return db.getTenant(`SELECT id, name, disk_quota_mb, token_quota, created_at FROM tenants WHERE name = $1`, name)
+}
+
+// GetTenantForChat retrieves the tenant a chat belongs to, returns nil if it has none
+func (db *DB) GetTenantForChat(chatID int64) (*Tenant, error) {
+	return db.getTenant(`
+	SELECT t.id, t.name, t.disk_quota_mb, t.token_quota, t.created_at
+	FROM tenants t
+	JOIN tenant_members m ON m.tenant_id = t.id
+	WHERE m.chat_id = $1
+	`, chatID)
+}
+
+func (db *DB) getTenant(query string, arg interface{}) (*Tenant, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	tenant := &Tenant{}
+	err := db.conn.QueryRow(query, arg).Scan(&tenant.ID, &tenant.Name, &tenant.DiskQuotaMB, &tenant.TokenQuota, &tenant.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+
+	return tenant, nil
+}
+
+// DeleteTenant removes a tenant and its memberships, the member chats themselves are untouched
+func (db *DB) DeleteTenant(tenantID int) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM tenants WHERE id = $1`, tenantID)
+	if err != nil {
+		return fmt.Errorf("failed to delete tenant: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("tenant not found")
+	}
+
+	return nil
+}
+
+// SetTenantQuotas updates a tenant's aggregate quotas (0 = unlimited)
+func (db *DB) SetTenantQuotas(tenantID int, diskQuotaMB float64, tokenQuota int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if diskQuotaMB < 0 || tokenQuota < 0 {
+		return fmt.Errorf("quotas must not be negative")
+	}
+
+	_, err := db.conn.Exec(`UPDATE tenants SET disk_quota_mb = $2, token_quota = $3 WHERE id = $1`, tenantID, diskQuotaMB, tokenQuota)
+	if err != nil {
+		return fmt.Errorf("failed to set tenant quotas: %w", err)
+	}
+
+	return nil
+}
+
+// GetTenantMember retrieves a chat's tenant membership, returns nil if it has none
+func (db *DB) GetTenantMember(chatID int64) (*TenantMember, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	member := &TenantMember{}
+	err := db.conn.QueryRow(`SELECT chat_id, tenant_id, is_admin, added_at FROM tenant_members WHERE chat_id = $1`, chatID).Scan(
+		&member.ChatID, &member.TenantID, &member.IsAdmin, &member.AddedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tenant member: %w", err)
+	}
+
+	return member, nil
+}
+
+// GetTenantMembers retrieves a tenant's members, admins first
+func (db *DB) GetTenantMembers(tenantID int) ([]*TenantMember, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT chat_id, tenant_id, is_admin, added_at
+	FROM tenant_members
+	WHERE tenant_id = $1
+	ORDER BY is_admin DESC, added_at
+	`
+
+	rows, err := db.conn.Query(query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tenant members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []*TenantMember
+	for rows.Next() {
+		member := &TenantMember{}
+		if err := rows.Scan(&member.ChatID, &member.TenantID, &member.IsAdmin, &member.AddedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan tenant member: %w", err)
+		}
+		members = append(members, member)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tenant members: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetTenantMember adds a chat to a tenant or updates its admin flag, moving it from any other tenant
+func (db *DB) SetTenantMember(tenantID int, chatID int64, isAdmin bool) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO tenant_members (chat_id, tenant_id, is_admin, added_at)
+	VALUES ($1, $2, $3, NOW())
+	ON CONFLICT (chat_id)
+	DO UPDATE SET
+		tenant_id = EXCLUDED.tenant_id,
+		is_admin = EXCLUDED.is_admin
+	`
+
+	if _, err := db.conn.Exec(query, chatID, tenantID, isAdmin); err != nil {
+		return fmt.Errorf("failed to set tenant member: %w", err)
+	}
+
+	return nil
+}
+
+// RemoveTenantMember removes a chat from a tenant
+func (db *DB) RemoveTenantMember(tenantID int, chatID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM tenant_members WHERE tenant_id = $1 AND chat_id = $2`, tenantID, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to remove tenant member: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("chat is not a member of this tenant")
+	}
+
+	return nil
+}
+
+// GetTenantUsage sums the repository sizes and current-period token usage of a tenant's members
+func (db *DB) GetTenantUsage(tenantID int) (*TenantUsage, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT
+		COUNT(m.chat_id),
+		COALESCE(SUM(i.repo_size), 0),
+		COALESCE(SUM(u.token_input + u.token_output), 0)
+	FROM tenant_members m
+	LEFT JOIN user_insights i ON i.uid = m.chat_id
+	LEFT JOIN user_usage u ON u.uid = m.chat_id
+	WHERE m.tenant_id = $1
+	`
+
+	usage := &TenantUsage{}
+	if err := db.conn.QueryRow(query, tenantID).Scan(&usage.Members, &usage.RepoSizeMB, &usage.TokensUsed); err != nil {
+		return nil, fmt.Errorf("failed to get tenant usage: %w", err)
+	}
+
+	return usage, nil
+}
+
+// GetTenantStats gets the same statistics as GetGlobalStats, restricted to a tenant's members
+func (db *DB) GetTenantStats(tenantID int) (*GlobalStats, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT
+		COALESCE(SUM(commit_cnt), 0) as total_commits,
+		COUNT(DISTINCT uid) as total_users,
+		COALESCE(SUM(issue_cnt), 0) as total_issues,
+		COALESCE(SUM(image_cnt), 0) as total_images,
+		COALESCE(SUM(issue_close_cnt), 0) as total_issue_closes,
+		COALESCE(SUM(issue_cmt_cnt), 0) as total_issue_comments,
+		COALESCE(SUM(sync_cmd_cnt), 0) as total_sync_cmds,
+		COALESCE(SUM(insight_cmd_cnt), 0) as total_insight_cmds,
+		COALESCE(SUM(token_input), 0) as total_token_input,
+		COALESCE(SUM(token_output), 0) as total_token_output,
+		COALESCE(SUM(repo_size), 0) as total_repo_size_mb
+	FROM user_insights
+	WHERE uid IN (SELECT chat_id FROM tenant_members WHERE tenant_id = $1)
+	`
+
+	stats := &GlobalStats{}
+	err := db.conn.QueryRow(query, tenantID).Scan(
+		&stats.TotalCommits,
+		&stats.TotalUsers,
+		&stats.TotalIssues,
+		&stats.TotalImages,
+		&stats.TotalIssueCloses,
+		&stats.TotalIssueComments,
+		&stats.TotalSyncCmds,
+		&stats.TotalInsightCmds,
+		&stats.TotalTokenInput,
+		&stats.TotalTokenOutput,
+		&stats.TotalRepoSizeMB,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant stats: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Trashed file methods
+
+// CreateTrashedFile records a file moved to the trash folder of repo
+func (db *DB) CreateTrashedFile(chatID int64, repo, originalPath, trashPath string, expiresAt time.Time) (*TrashedFile, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	INSERT INTO trashed_files (chat_id, repo, original_path, trash_path, trashed_at, expires_at)
+	VALUES ($1, $2, $3, $4, NOW(), $5)
+	RETURNING id, chat_id, repo, original_path, trash_path, trashed_at, expires_at
+	`
+
+	trashed := &TrashedFile{}
+	err := db.conn.QueryRow(query, chatID, repo, originalPath, trashPath, expiresAt).Scan(
+		&trashed.ID, &trashed.ChatID, &trashed.Repo, &trashed.OriginalPath, &trashed.TrashPath,
+		&trashed.TrashedAt, &trashed.ExpiresAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trashed file: %w", err)
+	}
+
+	return trashed, nil
+}
+
+// GetTrashedFiles retrieves a user's trashed files, most recent first
+func (db *DB) GetTrashedFiles(chatID int64) ([]*TrashedFile, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, repo, original_path, trash_path, trashed_at, expires_at
+	FROM trashed_files
+	WHERE chat_id = $1
+	ORDER BY trashed_at DESC
+	`
+
+	return db.queryTrashedFiles(query, chatID)
+}
+
+// GetTrashedFile retrieves a single trashed file owned by chatID, returns nil if it doesn't exist
+func (db *DB) GetTrashedFile(id, chatID int64) (*TrashedFile, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, repo, original_path, trash_path, trashed_at, expires_at
+	FROM trashed_files
+	WHERE id = $1 AND chat_id = $2
+	`
+
+	trashed := &TrashedFile{}
+	err := db.conn.QueryRow(query, id, chatID).Scan(
+		&trashed.ID, &trashed.ChatID, &trashed.Repo, &trashed.OriginalPath, &trashed.TrashPath,
+		&trashed.TrashedAt, &trashed.ExpiresAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get trashed file: %w", err)
+	}
+
+	return trashed, nil
+}
+
+// GetExpiredTrashedFiles retrieves trashed files past their retention period
+func (db *DB) GetExpiredTrashedFiles(now time.Time) ([]*TrashedFile, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, repo, original_path, trash_path, trashed_at, expires_at
+	FROM trashed_files
+	WHERE expires_at <= $1
+	ORDER BY expires_at
+	`
+
+	return db.queryTrashedFiles(query, now)
+}
+
+// DeleteTrashedFile removes a trashed file record
+func (db *DB) DeleteTrashedFile(id int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	if _, err := db.conn.Exec(`DELETE FROM trashed_files WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete trashed file: %w", err)
+	}
+
+	return nil
+}
+
+func (db *DB) queryTrashedFiles(query string, args ...interface{}) ([]*TrashedFile, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trashed files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*TrashedFile
+	for rows.Next() {
+		trashed := &TrashedFile{}
+		err := rows.Scan(
+			&trashed.ID, &trashed.ChatID, &trashed.Repo, &trashed.OriginalPath, &trashed.TrashPath,
+			&trashed.TrashedAt, &trashed.ExpiresAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trashed file: %w", err)
+		}
+		files = append(files, trashed)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trashed files: %w", err)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,53 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/watchdog"
+)
+
+// maxQueryNameLength caps how much of a query's text names it in slow query reports
+const maxQueryNameLength = 80
+
+// timedConn reports queries over the watchdog's query threshold, see internal/watchdog
+type timedConn struct {
+	*sql.DB
+}
+
+func (c *timedConn) Exec(query string, args ...interface{}) (sql.Result, error) {
+	defer observeQuery(query, args, time.Now())
+	return c.DB.Exec(query, args...)
+}
+
+func (c *timedConn) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	defer observeQuery(query, args, time.Now())
+	return c.DB.Query(query, args...)
+}
+
+func (c *timedConn) QueryRow(query string, args ...interface{}) *sql.Row {
+	defer observeQuery(query, args, time.Now())
+	return c.DB.QueryRow(query, args...)
+}
+
+func observeQuery(query string, args []interface{}, start time.Time) {
+	// Most queries are scoped to a chat passed as the first argument, which ties them to the
+	// chat's in-flight request; other int64 IDs are far smaller than chat IDs and match nothing
+	var chatID int64
+	if len(args) > 0 {
+		if id, ok := args[0].(int64); ok {
+			chatID = id
+		}
+	}
+	watchdog.Observe(watchdog.Query, queryName(query), chatID, time.Since(start))
+}
+
+// queryName shortens a query to a single line for reports, e.g. "SELECT id, name FROM users WHERE…"
+func queryName(query string) string {
+	name := strings.Join(strings.Fields(query), " ")
+	if len(name) > maxQueryNameLength {
+		name = strings.ToValidUTF8(name[:maxQueryNameLength], "") + "…"
+	}
+	return name
+}
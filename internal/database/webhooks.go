@@ -0,0 +1,109 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Webhook methods
+
+// CreateWebhook registers an outgoing webhook, the secret is encrypted before it is stored
+func (db *DB) CreateWebhook(chatID int64, url, secret, events string) (*Webhook, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	encryptedSecret, err := db.encryptionManager.Encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	query := `
+	INSERT INTO webhooks (chat_id, url, secret, events, created_at)
+	VALUES ($1, $2, $3, $4, NOW())
+	RETURNING id, chat_id, url, events, created_at
+	`
+
+	webhook := &Webhook{Secret: secret}
+	err = db.conn.QueryRow(query, chatID, url, encryptedSecret, events).Scan(
+		&webhook.ID, &webhook.ChatID, &webhook.URL, &webhook.Events, &webhook.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	return webhook, nil
+}
+
+// GetWebhooks retrieves a user's webhooks with decrypted secrets, oldest first
+func (db *DB) GetWebhooks(chatID int64) ([]*Webhook, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	query := `
+	SELECT id, chat_id, url, secret, events, created_at
+	FROM webhooks
+	WHERE chat_id = $1
+	ORDER BY id
+	`
+
+	rows, err := db.conn.Query(query, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []*Webhook
+	for rows.Next() {
+		webhook := &Webhook{}
+		var encryptedSecret string
+		err := rows.Scan(&webhook.ID, &webhook.ChatID, &webhook.URL, &encryptedSecret, &webhook.Events, &webhook.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook: %w", err)
+		}
+
+		decrypted, err := db.encryptionManager.Decrypt(encryptedSecret)
+		if err != nil {
+			logger.Warn("Failed to decrypt webhook secret", map[string]interface{}{
+				"chat_id":    chatID,
+				"webhook_id": webhook.ID,
+				"error":      err.Error(),
+			})
+			decrypted = encryptedSecret
+		}
+		webhook.Secret = decrypted
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+// DeleteWebhook removes a webhook owned by chatID
+func (db *DB) DeleteWebhook(id, chatID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM webhooks WHERE id = $1 AND chat_id = $2`, id, chatID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
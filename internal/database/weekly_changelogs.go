@@ -0,0 +1,115 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Weekly changelog methods
+
+const weeklyChangelogColumns = `chat_id, issue_number, week_start, last_posted_at, created_at`
+
+// EnableWeeklyChangelog opts the user into the weekly changelog issue. The first issue covers the
+// current week, so last_posted_at starts now.
+func (db *DB) EnableWeeklyChangelog(chatID int64) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	query := `INSERT INTO weekly_changelogs (chat_id, last_posted_at, created_at) VALUES ($1, NOW(), NOW()) ON CONFLICT (chat_id) DO NOTHING`
+	if _, err := db.conn.Exec(query, chatID); err != nil {
+		return fmt.Errorf("failed to enable weekly changelog: %w", err)
+	}
+
+	return nil
+}
+
+// DisableWeeklyChangelog opts the user out, reporting whether it was enabled
+func (db *DB) DisableWeeklyChangelog(chatID int64) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`DELETE FROM weekly_changelogs WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return false, fmt.Errorf("failed to disable weekly changelog: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// GetWeeklyChangelog retrieves the user's weekly changelog, nil if it isn't enabled
+func (db *DB) GetWeeklyChangelog(chatID int64) (*WeeklyChangelog, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	changelog := &WeeklyChangelog{}
+	err := db.conn.QueryRow(`SELECT `+weeklyChangelogColumns+` FROM weekly_changelogs WHERE chat_id = $1`, chatID).Scan(
+		&changelog.ChatID, &changelog.IssueNumber, &changelog.WeekStart, &changelog.LastPostedAt, &changelog.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get weekly changelog: %w", err)
+	}
+
+	return changelog, nil
+}
+
+// GetDueWeeklyChangelogs retrieves the weekly changelogs not posted since the given time
+func (db *DB) GetDueWeeklyChangelogs(before time.Time) ([]*WeeklyChangelog, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not configured")
+	}
+
+	rows, err := db.conn.Query(`SELECT `+weeklyChangelogColumns+` FROM weekly_changelogs WHERE last_posted_at IS NULL OR last_posted_at < $1 ORDER BY chat_id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query weekly changelogs: %w", err)
+	}
+	defer rows.Close()
+
+	var changelogs []*WeeklyChangelog
+	for rows.Next() {
+		changelog := &WeeklyChangelog{}
+		if err := rows.Scan(&changelog.ChatID, &changelog.IssueNumber, &changelog.WeekStart, &changelog.LastPostedAt, &changelog.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan weekly changelog: %w", err)
+		}
+		changelogs = append(changelogs, changelog)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating weekly changelogs: %w", err)
+	}
+
+	return changelogs, nil
+}
+
+// UpdateWeeklyChangelog records the issue posted for the week starting at weekStart
+func (db *DB) UpdateWeeklyChangelog(chatID int64, issueNumber int, weekStart, postedAt time.Time) error {
+	if db == nil {
+		return fmt.Errorf("database not configured")
+	}
+
+	result, err := db.conn.Exec(`UPDATE weekly_changelogs SET issue_number = $2, week_start = $3, last_posted_at = $4 WHERE chat_id = $1`, chatID, issueNumber, weekStart, postedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update weekly changelog: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("weekly changelog not enabled")
+	}
+
+	return nil
+}
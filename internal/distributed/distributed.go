@@ -0,0 +1,34 @@
+// Package distributed holds the Redis connection instances of the bot share when REDIS_URL is set,
+// so several of them can serve one bot behind a load balancer. The shared cache itself is a
+// backend of internal/cache, shared rate limits come from the ratelimit package of
+// experiments/monitoring.
+package distributed
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Timeout bounds every Redis round trip, a slow Redis must not stall message handling
+const Timeout = 2 * time.Second
+
+// Connect opens a client for a redis:// or rediss:// URL, e.g. "redis://:password@localhost:6379/0",
+// and checks that the server answers
+func Connect(redisURL string) (*redis.Client, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return client, nil
+}
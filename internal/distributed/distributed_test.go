@@ -0,0 +1,26 @@
+package distributed
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestConnect(t *testing.T) {
+	server := miniredis.RunT(t)
+
+	addr := server.Addr()
+	client, err := Connect("redis://" + addr + "/0")
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	client.Close()
+
+	if _, err := Connect("localhost:6379"); err == nil {
+		t.Error("Expected an error for a URL without the redis scheme")
+	}
+	server.Close()
+	if _, err := Connect("redis://" + addr); err == nil {
+		t.Error("Expected an error when Redis doesn't answer")
+	}
+}
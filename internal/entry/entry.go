@@ -0,0 +1,121 @@
+package entry
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Repository entries: notes, TODOs and issue links formatted the way they are stored, shared by
+// the Telegram bot and the public msg2git package
+
+// maxTitleLength is the length titles derived from content are cut to
+const maxTitleLength = 50
+
+// Title derives a title from the first line of content, cut near maxTitleLength on a word boundary
+func Title(content string) string {
+	lines := strings.Split(strings.TrimSpace(content), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		return "untitled"
+	}
+
+	firstLine := strings.TrimSpace(lines[0])
+	if firstLine == "" {
+		return "untitled"
+	}
+
+	if len(firstLine) > maxTitleLength {
+		// Try to cut at a word boundary near 50 chars
+		if cutIndex := strings.LastIndex(firstLine[:maxTitleLength], " "); cutIndex > 20 {
+			return firstLine[:cutIndex] + "..."
+		}
+		// If no good word boundary, just cut at 47 chars and add "..."
+		return firstLine[:maxTitleLength-3] + "..."
+	}
+
+	return firstLine
+}
+
+// MarkdownLineBreaks ends every line with two spaces so markdown renders the line breaks
+func MarkdownLineBreaks(content string) string {
+	lines := strings.Split(content, "\n")
+
+	for i, line := range lines {
+		// Don't add spaces to empty lines or the last line if it's empty
+		if line != "" || (i < len(lines)-1) {
+			lines[i] = strings.TrimRight(line, " ") + "  "
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Note formats a note entry: a metadata comment, the title, the tags if any, the content and a separator.
+// Extra metadata lines such as MoodMeta go into the comment after the message line.
+func Note(content string, messageID int, chatID int64, title, tags string, now time.Time, metadata ...string) string {
+	return noteComment(messageID, chatID, now, metadata) + NoteBody(content, title, tags) + "\n\n---\n\n"
+}
+
+// NoteBody formats the part of a note after its metadata comment: the title, the tags if any and
+// the content
+func NoteBody(content, title, tags string) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("## %s\n", title))
+
+	if cleanTags := strings.TrimSpace(tags); cleanTags != "" {
+		result.WriteString(fmt.Sprintf("%s\n", cleanTags))
+	}
+	result.WriteString("\n")
+
+	result.WriteString(MarkdownLineBreaks(content))
+
+	return result.String()
+}
+
+// SealedNote formats an encrypted note entry: the metadata comment followed by the sealed line of
+// its NoteBody instead of the title and content
+func SealedNote(sealed string, messageID int, chatID int64, now time.Time) string {
+	return noteComment(messageID, chatID, now, nil) + sealed + "\n\n---\n\n"
+}
+
+// noteComment formats the metadata comment starting a note
+func noteComment(messageID int, chatID int64, now time.Time, metadata []string) string {
+	var result strings.Builder
+
+	result.WriteString("<!--\n")
+	result.WriteString(fmt.Sprintf("[%d] [%d] [%s] \n", messageID, chatID, now.Format("2006-01-02 15:04")))
+	for _, line := range metadata {
+		result.WriteString(line + "\n")
+	}
+	result.WriteString("-->\n\n")
+
+	return result.String()
+}
+
+// MoodMeta formats the metadata line of a note's mood
+func MoodMeta(emoji, name string) string {
+	return fmt.Sprintf("mood: %s %s", emoji, name)
+}
+
+// Todo formats an open TODO line, "" for content with line breaks which todo.md can't hold
+func Todo(content string, messageID int, chatID int64, now time.Time) string {
+	if strings.Contains(content, "\n") {
+		return ""
+	}
+
+	return fmt.Sprintf("- [ ] <!--[%d] [%d]--> %s (%s)\n", messageID, chatID, content, now.Format("2006-01-02"))
+}
+
+// IssueLine formats the issue.md line of a newly created issue
+func IssueLine(owner, repo string, issueNumber int, title string) string {
+	return fmt.Sprintf("- 🟢 %s/%s#%d [%s]\n", owner, repo, issueNumber, title)
+}
+
+// Photo embeds an image with an optional caption below it
+func Photo(url, caption string) string {
+	if caption == "" {
+		return fmt.Sprintf("![Photo](%s)", url)
+	}
+	return fmt.Sprintf("![Photo](%s)\n\n%s", url, caption)
+}
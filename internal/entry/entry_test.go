@@ -0,0 +1,59 @@
+package entry
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTitle(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"Short title\nbody", "Short title"},
+		{"", "untitled"},
+		{"   \n", "untitled"},
+		{strings.Repeat("word ", 20), strings.TrimSpace(strings.Repeat("word ", 10)) + "..."},
+		{strings.Repeat("x", 60), strings.Repeat("x", 47) + "..."},
+	}
+
+	for _, tt := range tests {
+		if got := Title(tt.content); got != tt.want {
+			t.Errorf("Title(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestNote(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	want := "<!--\n[7] [42] [2025-03-04 05:06] \n-->\n\n## Title\n#tag\n\nline one  \nline two  \n\n---\n\n"
+	if got := Note("line one\nline two", 7, 42, "Title", " #tag ", now); got != want {
+		t.Errorf("Note() = %q, want %q", got, want)
+	}
+
+	want = "<!--\n[7] [42] [2025-03-04 05:06] \nmood: 🙂 good\n-->\n\n## Title\n\ntext  \n\n---\n\n"
+	if got := Note("text", 7, 42, "Title", "", now, MoodMeta("🙂", "good")); got != want {
+		t.Errorf("Note(mood) = %q, want %q", got, want)
+	}
+}
+
+func TestSealedNote(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	note := SealedNote("🔒 sealed", 7, 42, now)
+	if note != "<!--\n[7] [42] [2025-03-04 05:06] \n-->\n\n🔒 sealed\n\n---\n\n" {
+		t.Errorf("SealedNote() = %q", note)
+	}
+	if parsed := Parse("# Head\n\n" + note); len(parsed.Notes) != 1 || parsed.Notes[0] != note {
+		t.Errorf("Parse() of a sealed note = %+v", parsed)
+	}
+}
+
+func TestTodo(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	if got := Todo("buy milk", 7, 42, now); got != "- [ ] <!--[7] [42]--> buy milk (2025-03-04)\n" {
+		t.Errorf("Todo() = %q", got)
+	}
+	if got := Todo("two\nlines", 7, 42, now); got != "" {
+		t.Errorf("Todo(multi-line) = %q, want empty", got)
+	}
+}
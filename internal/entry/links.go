@@ -0,0 +1,202 @@
+package entry
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Note links: wiki-style [[note]], [[note|text]] and [[note#Heading]] links are resolved to
+// relative markdown links when a note is committed, and backlinks.md lists which files link to each note
+
+// BacklinksFile is the index of backlinks at the repository root
+const BacklinksFile = "backlinks.md"
+
+var (
+	wikiLinkRegex     = regexp.MustCompile(`\[\[([^\[\]|\n]+)(?:\|([^\[\]\n]+))?\]\]`)
+	headingSlugRegex  = regexp.MustCompile(`[^\p{L}\p{N}\s_-]`)
+	backlinkItemRegex = regexp.MustCompile(`^(##|-) \[[^\]]*\]\(([^)]*)\)`)
+)
+
+// WikiLinkTarget turns the target of a wiki link into a repository path and a heading anchor,
+// ok is false for targets outside the repository
+func WikiLinkTarget(target string) (notePath, anchor string, ok bool) {
+	target = strings.TrimSpace(target)
+	if i := strings.Index(target, "#"); i >= 0 {
+		target, anchor = strings.TrimSpace(target[:i]), headingAnchor(target[i+1:])
+	}
+	if target == "" {
+		return "", "", false
+	}
+
+	notePath = path.Clean(strings.TrimPrefix(target, "/"))
+	if notePath == "." || notePath == ".." || strings.HasPrefix(notePath, "../") {
+		return "", "", false
+	}
+	if path.Ext(notePath) == "" {
+		notePath += ".md"
+	}
+	return notePath, anchor, true
+}
+
+// headingAnchor converts a heading to the anchor GitHub generates for it
+func headingAnchor(heading string) string {
+	slug := strings.ToLower(strings.TrimSpace(heading))
+	slug = headingSlugRegex.ReplaceAllString(slug, "")
+	return strings.ReplaceAll(slug, " ", "-")
+}
+
+// relativeLink returns the link from a file in fromDir to notePath, escaped for markdown
+func relativeLink(fromDir, notePath string) string {
+	from := []string{}
+	if fromDir != "." && fromDir != "" {
+		from = strings.Split(fromDir, "/")
+	}
+	to := strings.Split(notePath, "/")
+
+	common := 0
+	for common < len(from) && common < len(to)-1 && from[common] == to[common] {
+		common++
+	}
+
+	parts := make([]string, 0, len(from)-common+len(to)-common)
+	for range from[common:] {
+		parts = append(parts, "..")
+	}
+	for _, part := range to[common:] {
+		parts = append(parts, (&url.URL{Path: part}).EscapedPath())
+	}
+	return strings.Join(parts, "/")
+}
+
+// ResolveWikiLinks replaces the wiki links of a note committed to file with relative markdown
+// links and returns the notes it links to. Links inside code are left alone.
+func ResolveWikiLinks(content, file string) (string, []string) {
+	if !strings.Contains(content, "[[") {
+		return content, nil
+	}
+
+	fromDir := path.Dir(file)
+	seen := make(map[string]bool)
+	var targets []string
+
+	resolve := func(text string) string {
+		return wikiLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+			groups := wikiLinkRegex.FindStringSubmatch(match)
+			notePath, anchor, ok := WikiLinkTarget(groups[1])
+			if !ok {
+				return match
+			}
+
+			label := strings.TrimSpace(groups[2])
+			if label == "" {
+				label = strings.TrimSpace(groups[1])
+			}
+			link := relativeLink(fromDir, notePath)
+			if anchor != "" {
+				link += "#" + anchor
+			}
+
+			if !seen[notePath] && notePath != file {
+				seen[notePath] = true
+				targets = append(targets, notePath)
+			}
+			return fmt.Sprintf("[%s](%s)", label, link)
+		})
+	}
+
+	lines := strings.Split(content, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		// Odd segments between backticks are inline code
+		segments := strings.Split(line, "`")
+		for j := 0; j < len(segments); j += 2 {
+			segments[j] = resolve(segments[j])
+		}
+		lines[i] = strings.Join(segments, "`")
+	}
+
+	return strings.Join(lines, "\n"), targets
+}
+
+// Backlinks maps every linked note to the files linking to it
+type Backlinks map[string][]string
+
+// ParseBacklinks reads a backlinks.md index
+func ParseBacklinks(content string) Backlinks {
+	backlinks := make(Backlinks)
+	target := ""
+	for _, line := range strings.Split(content, "\n") {
+		match := backlinkItemRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		linked, err := url.PathUnescape(match[2])
+		if err != nil {
+			continue
+		}
+		if match[1] == "##" {
+			target = linked
+			continue
+		}
+		if target != "" {
+			backlinks[target] = append(backlinks[target], linked)
+		}
+	}
+	return backlinks
+}
+
+// Add records that source links to each target and reports whether anything was new
+func (b Backlinks) Add(source string, targets []string) bool {
+	changed := false
+	for _, target := range targets {
+		if target == source {
+			continue
+		}
+		known := false
+		for _, existing := range b[target] {
+			if existing == source {
+				known = true
+				break
+			}
+		}
+		if !known {
+			b[target] = append(b[target], source)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Markdown renders the index, notes and the files linking to them in alphabetical order
+func (b Backlinks) Markdown() string {
+	targets := make([]string, 0, len(b))
+	for target := range b {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	var sb strings.Builder
+	sb.WriteString("# Backlinks\n\nFiles linking to each note, updated by msg2git when a note with [[links]] is saved.\n")
+	for _, target := range targets {
+		sources := append([]string(nil), b[target]...)
+		sort.Strings(sources)
+
+		sb.WriteString(fmt.Sprintf("\n## [%s](%s)\n\n", target, relativeLink(".", target)))
+		for _, source := range sources {
+			sb.WriteString(fmt.Sprintf("- [%s](%s)\n", source, relativeLink(".", source)))
+		}
+	}
+	return sb.String()
+}
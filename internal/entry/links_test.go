@@ -0,0 +1,61 @@
+package entry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveWikiLinks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		file    string
+		want    string
+		targets []string
+	}{
+		{"plain", "see [[ideas]]", "inbox.md", "see [ideas](ideas.md)", []string{"ideas.md"}},
+		{"alias", "see [[ideas|my ideas]]", "inbox.md", "see [my ideas](ideas.md)", []string{"ideas.md"}},
+		{"heading", "see [[ideas#Big Plan!]]", "inbox.md", "see [ideas#Big Plan!](ideas.md#big-plan)", []string{"ideas.md"}},
+		{"from subdirectory", "[[journal/2025]] and [[ideas]]", "journal/today.md", "[journal/2025](2025.md) and [ideas](../ideas.md)", []string{"journal/2025.md", "ideas.md"}},
+		{"spaces", "[[reading list]]", "inbox.md", "[reading list](reading%20list.md)", []string{"reading list.md"}},
+		{"duplicates and self", "[[ideas]] [[ideas]] [[inbox]]", "inbox.md", "[ideas](ideas.md) [ideas](ideas.md) [inbox](inbox.md)", []string{"ideas.md"}},
+		{"outside repository", "[[../secrets]]", "inbox.md", "[[../secrets]]", nil},
+		{"inline code", "`[[kept]]` but [[ideas]]", "inbox.md", "`[[kept]]` but [ideas](ideas.md)", []string{"ideas.md"}},
+		{"code block", "```\n[[kept]]\n```\n[[ideas]]", "inbox.md", "```\n[[kept]]\n```\n[ideas](ideas.md)", []string{"ideas.md"}},
+		{"no links", "nothing here", "inbox.md", "nothing here", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, targets := ResolveWikiLinks(tt.content, tt.file)
+			if got != tt.want {
+				t.Errorf("ResolveWikiLinks() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(targets, tt.targets) {
+				t.Errorf("ResolveWikiLinks() targets = %v, want %v", targets, tt.targets)
+			}
+		})
+	}
+}
+
+func TestBacklinksRoundTrip(t *testing.T) {
+	backlinks := ParseBacklinks("")
+	if !backlinks.Add("inbox.md", []string{"ideas.md", "reading list.md"}) {
+		t.Fatal("Add() should report new links")
+	}
+	if !backlinks.Add("journal/today.md", []string{"ideas.md"}) {
+		t.Fatal("Add() should report new links")
+	}
+	if backlinks.Add("inbox.md", []string{"ideas.md", "inbox.md"}) {
+		t.Error("Add() should ignore known and self links")
+	}
+
+	parsed := ParseBacklinks(backlinks.Markdown())
+	want := Backlinks{
+		"ideas.md":        {"inbox.md", "journal/today.md"},
+		"reading list.md": {"inbox.md"},
+	}
+	if !reflect.DeepEqual(parsed, want) {
+		t.Errorf("ParseBacklinks(Markdown()) = %v, want %v", parsed, want)
+	}
+}
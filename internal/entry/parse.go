@@ -0,0 +1,186 @@
+package entry
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Parsing stored notes: a file holds a head (anything before the first note) followed by notes,
+// newest first, each starting at its metadata comment. Notes are kept byte for byte, so joining
+// a parsed file gives back the original content.
+
+var noteMetaRegex = regexp.MustCompile(`^\[\d+\] \[-?\d+\] \[[^\]]+\]`)
+
+// File is a markdown file split into its notes
+type File struct {
+	Head  string
+	Notes []string
+}
+
+// Parse splits content into the notes it holds
+func Parse(content string) File {
+	var starts []int
+	offset := 0
+	lines := strings.SplitAfter(content, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "<!--" && i+1 < len(lines) && noteMetaRegex.MatchString(strings.TrimSpace(lines[i+1])) {
+			starts = append(starts, offset)
+		}
+		offset += len(line)
+	}
+
+	if len(starts) == 0 {
+		return File{Head: content}
+	}
+
+	file := File{Head: content[:starts[0]]}
+	for i, start := range starts {
+		end := len(content)
+		if i+1 < len(starts) {
+			end = starts[i+1]
+		}
+		file.Notes = append(file.Notes, content[start:end])
+	}
+	return file
+}
+
+// String joins the file back together
+func (f File) String() string {
+	return f.Head + strings.Join(f.Notes, "")
+}
+
+// noteTitleLine returns the index of a note's title line, -1 without one
+func noteTitleLine(lines []string) int {
+	inComment := true
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if inComment {
+			inComment = trimmed != "-->"
+			continue
+		}
+		if strings.HasPrefix(trimmed, "## ") {
+			return i
+		}
+		if trimmed != "" {
+			return -1
+		}
+	}
+	return -1
+}
+
+// NoteTitle returns the title of a note, "" without one
+func NoteTitle(note string) string {
+	lines := strings.Split(note, "\n")
+	if i := noteTitleLine(lines); i >= 0 {
+		return strings.TrimPrefix(strings.TrimSpace(lines[i]), "## ")
+	}
+	return ""
+}
+
+// SetNoteTitle replaces the title of a note, notes without one are returned unchanged
+func SetNoteTitle(note, title string) string {
+	lines := strings.Split(note, "\n")
+	i := noteTitleLine(lines)
+	if i < 0 {
+		return note
+	}
+	lines[i] = "## " + title
+	return strings.Join(lines, "\n")
+}
+
+// NoteContent returns the content of a note as it was sent: without the metadata comment, the
+// title, the tags and the separator, and without the markdown line breaks
+func NoteContent(note string) string {
+	lines := strings.Split(note, "\n")
+	i := noteTitleLine(lines)
+	if i < 0 {
+		return ""
+	}
+	lines = lines[i+1:]
+	if len(lines) > 0 && strings.TrimSpace(lines[0]) != "" {
+		lines = lines[1:] // Tags
+	}
+
+	body := strings.TrimSpace(strings.Join(lines, "\n"))
+	body = strings.TrimSpace(strings.TrimSuffix(body, "---"))
+	bodyLines := strings.Split(body, "\n")
+	for j, line := range bodyLines {
+		bodyLines[j] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(bodyLines, "\n")
+}
+
+// isTagRune reports whether r can be part of a hashtag
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '_' || r == '-'
+}
+
+// Tags returns the hashtags of text without their leading #, each once in order of appearance.
+// Like ReplaceTag, only standalone tags count, and issue references such as #12 aren't tags.
+func Tags(text string) []string {
+	var tags []string
+	seen := make(map[string]bool)
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' {
+			continue
+		}
+		if i > 0 {
+			if before := runes[i-1]; isTagRune(before) || before == '#' || before == '/' || before == '&' {
+				continue
+			}
+		}
+
+		end := i + 1
+		for end < len(runes) && isTagRune(runes[end]) {
+			end++
+		}
+		tag := string(runes[i+1 : end])
+		i = end - 1
+
+		if strings.IndexFunc(tag, unicode.IsLetter) < 0 || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// ReplaceTag replaces the hashtag oldTag with newTag, both with their leading #, and returns the
+// number of replacements. Only whole tags are replaced, so #go doesn't touch #golang, headings or
+// URL fragments.
+func ReplaceTag(text, oldTag, newTag string) (string, int) {
+	if len(oldTag) < 2 || oldTag[0] != '#' {
+		return text, 0
+	}
+
+	var sb strings.Builder
+	count := 0
+	rest := text
+	for {
+		i := strings.Index(rest, oldTag)
+		if i < 0 {
+			sb.WriteString(rest)
+			break
+		}
+
+		sb.WriteString(rest[:i])
+		before, _ := utf8.DecodeLastRuneInString(sb.String())
+		after, _ := utf8.DecodeRuneInString(rest[i+len(oldTag):])
+		standalone := (sb.Len() == 0 || !(isTagRune(before) || before == '#' || before == '/' || before == '&')) &&
+			(i+len(oldTag) == len(rest) || !isTagRune(after))
+
+		if standalone {
+			sb.WriteString(newTag)
+			count++
+		} else {
+			sb.WriteString(oldTag)
+		}
+		rest = rest[i+len(oldTag):]
+	}
+	return sb.String(), count
+}
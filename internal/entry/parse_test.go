@@ -0,0 +1,90 @@
+package entry
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	first := Note("newest", 2, 42, "Second", "#go", now)
+	second := Note("oldest\n---\nwith a rule", 1, 42, "First", "", now)
+	content := "# Notes\n\n" + first + second
+
+	file := Parse(content)
+	if file.Head != "# Notes\n\n" {
+		t.Errorf("Head = %q", file.Head)
+	}
+	if len(file.Notes) != 2 || file.Notes[0] != first || file.Notes[1] != second {
+		t.Fatalf("Notes = %q, want the two notes", file.Notes)
+	}
+	if file.String() != content {
+		t.Errorf("String() = %q, want the original content", file.String())
+	}
+
+	if plain := Parse("just text\n<!-- a comment -->\n"); len(plain.Notes) != 0 || plain.String() != "just text\n<!-- a comment -->\n" {
+		t.Errorf("Parse(plain) = %+v, want no notes", plain)
+	}
+}
+
+func TestNoteTitleAndContent(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	note := Note("line one\nline two", 7, 42, "Old title", "#tag", now, MoodMeta("🙂", "good"))
+
+	if got := NoteTitle(note); got != "Old title" {
+		t.Errorf("NoteTitle() = %q", got)
+	}
+	if got := NoteContent(note); got != "line one\nline two" {
+		t.Errorf("NoteContent() = %q", got)
+	}
+
+	retitled := SetNoteTitle(note, "New title")
+	if want := Note("line one\nline two", 7, 42, "New title", "#tag", now, MoodMeta("🙂", "good")); retitled != want {
+		t.Errorf("SetNoteTitle() = %q, want %q", retitled, want)
+	}
+
+	if got := NoteContent(Note("untagged", 7, 42, "Title", "", now)); got != "untagged" {
+		t.Errorf("NoteContent(untagged) = %q", got)
+	}
+}
+
+func TestReplaceTag(t *testing.T) {
+	tests := []struct {
+		text, want string
+		count      int
+	}{
+		{"#go #golang", "#rust #golang", 1},
+		{"#go #go", "#rust #rust", 2},
+		{"## go heading\n#go", "## go heading\n#rust", 1},
+		{"https://example.com/#go and page#go", "https://example.com/#go and page#go", 0},
+		{"(#go), #go.", "(#rust), #rust.", 2},
+		{"#go-lang #go_x", "#go-lang #go_x", 0},
+	}
+
+	for _, tt := range tests {
+		got, count := ReplaceTag(tt.text, "#go", "#rust")
+		if got != tt.want || count != tt.count {
+			t.Errorf("ReplaceTag(%q) = %q, %d, want %q, %d", tt.text, got, count, tt.want, tt.count)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Crash on login #bug #Bug #ui-v2", []string{"bug", "ui-v2"}},
+		{"## heading\nsee #12 and https://example.com/#idea", nil},
+		{"(#idea), #café.", []string{"idea", "café"}},
+		{"#2024plan ##double", []string{"2024plan"}},
+	}
+
+	for _, tt := range tests {
+		got := Tags(tt.text)
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("Tags(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
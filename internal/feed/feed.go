@@ -0,0 +1,211 @@
+package feed
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Item is a single entry from an RSS or Atom feed
+type Item struct {
+	Title     string
+	Link      string
+	Summary   string
+	Published time.Time
+}
+
+// Feed is a parsed RSS or Atom document
+type Feed struct {
+	Title string
+	Items []Item
+}
+
+const maxFeedSize = 5 << 20 // 5 MB
+
+var githubRepoRegex = regexp.MustCompile(`^github:([\w.-]+)/([\w.-]+)$`)
+
+// ResolveURL expands the "github:owner/repo" shorthand to the repository's releases feed
+func ResolveURL(input string) string {
+	input = strings.TrimSpace(input)
+	if matches := githubRepoRegex.FindStringSubmatch(input); matches != nil {
+		return fmt.Sprintf("https://github.com/%s/%s/releases.atom", matches[1], matches[2])
+	}
+	return input
+}
+
+// Fetch downloads and parses the feed at url
+func Fetch(client *http.Client, url string) (*Feed, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "msg2git-feeds")
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+
+	return Parse(data)
+}
+
+// Parse parses an RSS 2.0 or Atom document, items are returned newest first
+func Parse(data []byte) (*Feed, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("invalid feed XML: %w", err)
+	}
+
+	var feed *Feed
+	var err error
+	switch root.XMLName.Local {
+	case "rss":
+		feed, err = parseRSS(data)
+	case "feed":
+		feed, err = parseAtom(data)
+	default:
+		return nil, fmt.Errorf("unsupported feed format: %s", root.XMLName.Local)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(feed.Items, func(i, j int) bool {
+		return feed.Items[i].Published.After(feed.Items[j].Published)
+	})
+	return feed, nil
+}
+
+// NewItemsSince returns items published after since, newest first
+func (f *Feed) NewItemsSince(since time.Time) []Item {
+	var items []Item
+	for _, item := range f.Items {
+		if item.Published.After(since) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseRSS(data []byte) (*Feed, error) {
+	var doc struct {
+		Channel struct {
+			Title string `xml:"title"`
+			Items []struct {
+				Title       string `xml:"title"`
+				Link        string `xml:"link"`
+				Description string `xml:"description"`
+				PubDate     string `xml:"pubDate"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid RSS feed: %w", err)
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(doc.Channel.Title)}
+	for _, it := range doc.Channel.Items {
+		feed.Items = append(feed.Items, Item{
+			Title:     strings.TrimSpace(it.Title),
+			Link:      strings.TrimSpace(it.Link),
+			Summary:   cleanSummary(it.Description),
+			Published: parseTime(it.PubDate),
+		})
+	}
+	return feed, nil
+}
+
+func parseAtom(data []byte) (*Feed, error) {
+	var doc struct {
+		Title   string `xml:"title"`
+		Entries []struct {
+			Title string `xml:"title"`
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"link"`
+			Summary   string `xml:"summary"`
+			Content   string `xml:"content"`
+			Published string `xml:"published"`
+			Updated   string `xml:"updated"`
+		} `xml:"entry"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Atom feed: %w", err)
+	}
+
+	feed := &Feed{Title: strings.TrimSpace(doc.Title)}
+	for _, entry := range doc.Entries {
+		item := Item{Title: strings.TrimSpace(entry.Title)}
+		for _, link := range entry.Links {
+			if link.Rel == "" || link.Rel == "alternate" {
+				item.Link = link.Href
+				break
+			}
+		}
+		item.Summary = cleanSummary(entry.Summary)
+		if item.Summary == "" {
+			item.Summary = cleanSummary(entry.Content)
+		}
+		item.Published = parseTime(entry.Published)
+		if item.Published.IsZero() {
+			item.Published = parseTime(entry.Updated)
+		}
+		feed.Items = append(feed.Items, item)
+	}
+	return feed, nil
+}
+
+var timeLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2006-01-02T15:04:05Z",
+	"2006-01-02",
+}
+
+func parseTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+var htmlTagRegex = regexp.MustCompile(`<[^>]*>`)
+
+// cleanSummary strips HTML and truncates the summary to a single short paragraph
+func cleanSummary(s string) string {
+	s = htmlTagRegex.ReplaceAllString(s, " ")
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 280 {
+		cut := strings.LastIndex(s[:280], " ")
+		if cut < 200 {
+			cut = 277
+		}
+		s = s[:cut] + "..."
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+const rssSample = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+<title>Example Blog</title>
+<item><title>Older post</title><link>https://example.com/1</link><description>&lt;p&gt;First &lt;b&gt;post&lt;/b&gt;&lt;/p&gt;</description><pubDate>Mon, 02 Jun 2025 10:00:00 +0000</pubDate></item>
+<item><title>Newer post</title><link>https://example.com/2</link><description>Second post</description><pubDate>Tue, 03 Jun 2025 10:00:00 +0000</pubDate></item>
+</channel></rss>`
+
+const atomSample = `<?xml version="1.0" encoding="UTF-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+<title>Release notes from msg2git</title>
+<entry>
+<title>v1.2.0</title>
+<link rel="alternate" type="text/html" href="https://github.com/msg2git/msg2git/releases/tag/v1.2.0"/>
+<updated>2025-06-03T08:00:00Z</updated>
+<content type="html">&lt;p&gt;Adds feeds&lt;/p&gt;</content>
+</entry>
+</feed>`
+
+func TestParseRSS(t *testing.T) {
+	feed, err := Parse([]byte(rssSample))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if feed.Title != "Example Blog" || len(feed.Items) != 2 {
+		t.Fatalf("unexpected feed: %+v", feed)
+	}
+	if feed.Items[0].Title != "Newer post" {
+		t.Errorf("items not sorted newest first: %q", feed.Items[0].Title)
+	}
+	if feed.Items[1].Summary != "First post" {
+		t.Errorf("summary = %q, want HTML stripped", feed.Items[1].Summary)
+	}
+}
+
+func TestParseAtom(t *testing.T) {
+	feed, err := Parse([]byte(atomSample))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(feed.Items) != 1 {
+		t.Fatalf("got %d items, want 1", len(feed.Items))
+	}
+	item := feed.Items[0]
+	if item.Link != "https://github.com/msg2git/msg2git/releases/tag/v1.2.0" || item.Summary != "Adds feeds" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if !item.Published.Equal(time.Date(2025, 6, 3, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("published = %v, want updated time fallback", item.Published)
+	}
+}
+
+func TestNewItemsSince(t *testing.T) {
+	feed, _ := Parse([]byte(rssSample))
+	items := feed.NewItemsSince(time.Date(2025, 6, 2, 12, 0, 0, 0, time.UTC))
+	if len(items) != 1 || items[0].Title != "Newer post" {
+		t.Errorf("NewItemsSince() = %+v, want only the newer post", items)
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	if got := ResolveURL("github:msg2git/msg2git"); got != "https://github.com/msg2git/msg2git/releases.atom" {
+		t.Errorf("ResolveURL() = %q", got)
+	}
+	if got := ResolveURL("https://example.com/feed.xml"); got != "https://example.com/feed.xml" {
+		t.Errorf("ResolveURL() changed a plain URL: %q", got)
+	}
+}
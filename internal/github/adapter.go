@@ -1,6 +1,9 @@
 package github
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // CloneBasedAdapter adapts the existing Manager to implement GitHubProvider interface
 // This allows us to keep the current implementation while providing a clean interface
@@ -21,6 +24,22 @@ func (a *CloneBasedAdapter) NeedsClone() bool {
 	return a.manager.NeedsClone()
 }
 
+func (a *CloneBasedAdapter) Fetch() error {
+	return a.manager.Fetch()
+}
+
+func (a *CloneBasedAdapter) CommitHistory(since time.Time) ([]HistoryCommit, error) {
+	return a.manager.CommitHistory(since)
+}
+
+func (a *CloneBasedAdapter) SearchFiles(query string, limit int) ([]SearchMatch, error) {
+	return a.manager.SearchFiles(query, limit)
+}
+
+func (a *CloneBasedAdapter) RateLimits() (*RateLimits, error) {
+	return a.manager.RateLimits()
+}
+
 func (a *CloneBasedAdapter) GetRepoInfo() (owner, repo string, err error) {
 	return a.manager.GetRepoInfo()
 }
@@ -61,6 +80,10 @@ func (a *CloneBasedAdapter) GetGitHubFileURL(filename string) (string, error) {
 	return a.manager.GetGitHubFileURL(filename)
 }
 
+func (a *CloneBasedAdapter) CreateCommitStatus(sha string, status *CommitStatus) error {
+	return a.manager.CreateCommitStatus(sha, status)
+}
+
 func (a *CloneBasedAdapter) GetGitHubFileURLWithBranch(filename string) (string, error) {
 	return a.manager.GetGitHubFileURLWithBranch(filename)
 }
@@ -78,6 +101,10 @@ func (a *CloneBasedAdapter) CommitFileWithAuthorAndPremium(filename, content, co
 	return a.manager.CommitFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor, premiumLevel)
 }
 
+func (a *CloneBasedAdapter) CommitFileWithResult(filename, content, commitMessage, customAuthor string, premiumLevel int) (*CommitResult, error) {
+	return a.manager.CommitFileWithResult(filename, content, commitMessage, customAuthor, premiumLevel)
+}
+
 func (a *CloneBasedAdapter) ReplaceFile(filename, content, commitMessage string) error {
 	return a.manager.ReplaceFile(filename, content, commitMessage)
 }
@@ -102,6 +129,18 @@ func (a *CloneBasedAdapter) ReadFile(filename string) (string, error) {
 	return a.manager.ReadFile(filename)
 }
 
+func (a *CloneBasedAdapter) ListDirectory(path string) ([]DirectoryEntry, error) {
+	return a.manager.ListDirectory(path)
+}
+
+func (a *CloneBasedAdapter) MoveFile(oldPath, newPath, commitMessage, customAuthor string) error {
+	return a.manager.MoveFile(oldPath, newPath, commitMessage, customAuthor)
+}
+
+func (a *CloneBasedAdapter) DeleteFile(filename, commitMessage, customAuthor string) error {
+	return a.manager.DeleteFile(filename, commitMessage, customAuthor)
+}
+
 // IssueManager implementation
 func (a *CloneBasedAdapter) CreateIssue(title, body string) (string, int, error) {
 	return a.manager.CreateIssue(title, body)
@@ -119,10 +158,34 @@ func (a *CloneBasedAdapter) AddIssueComment(issueNumber int, commentText string)
 	return a.manager.AddIssueComment(issueNumber, commentText)
 }
 
+func (a *CloneBasedAdapter) GetIssueComments(issueNumber, limit int, before string) (*IssueCommentPage, error) {
+	return a.manager.GetIssueComments(issueNumber, limit, before)
+}
+
+func (a *CloneBasedAdapter) CreateIssueWithLabels(title, body string, labels []string) (string, int, error) {
+	return a.manager.CreateIssueWithLabels(title, body, labels)
+}
+
+func (a *CloneBasedAdapter) ListLabels() ([]string, error) {
+	return a.manager.ListLabels()
+}
+
+func (a *CloneBasedAdapter) ListIssuesByLabel(label string) ([]*IssueStatus, error) {
+	return a.manager.ListIssuesByLabel(label)
+}
+
+func (a *CloneBasedAdapter) AssignIssue(issueNumber int, assignees []string) error {
+	return a.manager.AssignIssue(issueNumber, assignees)
+}
+
 func (a *CloneBasedAdapter) CloseIssue(issueNumber int) error {
 	return a.manager.CloseIssue(issueNumber)
 }
 
+func (a *CloneBasedAdapter) UpdateIssueBody(issueNumber int, body string) error {
+	return a.manager.UpdateIssueBody(issueNumber, body)
+}
+
 // AssetManager implementation
 func (a *CloneBasedAdapter) UploadImageToCDN(filename string, data []byte) (string, error) {
 	return a.manager.UploadImageToCDN(filename, data)
@@ -65,6 +65,10 @@ type apiAssetResponse struct {
 
 // AssetManager implementation for API provider
 func (p *APIBasedProvider) UploadImageToCDN(filename string, data []byte) (string, error) {
+	if err := checkWriteQuota(p.config.WriteQuota); err != nil {
+		return "", err
+	}
+
 	// Step 1: Get or create a release for assets
 	release, err := p.getOrCreateAssetsRelease()
 	if err != nil {
@@ -230,9 +234,13 @@ func (p *APIBasedProvider) getAssetCount(releaseID int) (int, error) {
 
 // uploadAssetToRelease uploads a file as an asset to a GitHub release
 func (p *APIBasedProvider) uploadAssetToRelease(releaseID int, filename string, data []byte) (string, error) {
+	if p.gitea {
+		return p.uploadGiteaAttachment(releaseID, filename, data)
+	}
+
 	// GitHub upload URL needs to be modified for asset uploads
-	uploadURL := fmt.Sprintf("https://uploads.github.com/repos/%s/%s/releases/%d/assets?name=%s", 
-		p.repoOwner, p.repoName, releaseID, filename)
+	uploadURL := fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets?name=%s",
+		p.uploadsURL, p.repoOwner, p.repoName, releaseID, filename)
 
 	// Create the request
 	req, err := http.NewRequest("POST", uploadURL, bytes.NewReader(data))
@@ -343,6 +351,8 @@ func (p *APIBasedProvider) getContentType(filename string) string {
 		return "application/pdf"
 	} else if strings.HasSuffix(filename, ".txt") {
 		return "text/plain"
+	} else if strings.HasSuffix(filename, ".zip") {
+		return "application/zip"
 	} else {
 		return "application/octet-stream"
 	}
@@ -0,0 +1,139 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Commit branch: users may commit notes to a branch other than the repository's default one
+// (ProviderConfig.Branch). Reads ask the Contents API for that branch with ?ref=, writes name it in
+// the request body, and the branch is created from the default branch before the first write.
+
+type apiBranch struct {
+	Name   string `json:"name"`
+	Commit struct {
+		SHA string `json:"sha"`
+		ID  string `json:"id"` // Gitea names the commit SHA "id"
+	} `json:"commit"`
+}
+
+type apiCreateRefRequest struct {
+	Ref string `json:"ref"`
+	SHA string `json:"sha"`
+}
+
+// commitBranch returns the branch notes are committed to
+func (p *APIBasedProvider) commitBranch() (string, error) {
+	if p.config.Branch != "" {
+		return p.config.Branch, nil
+	}
+	return p.GetDefaultBranch()
+}
+
+// contentsEndpoint returns the Contents API endpoint of path for reads, on the commit branch
+func (p *APIBasedProvider) contentsEndpoint(path string) string {
+	endpoint := fmt.Sprintf("/repos/%s/%s/contents/%s", p.repoOwner, p.repoName, path)
+	if p.config.Branch != "" {
+		endpoint += "?ref=" + url.QueryEscape(p.config.Branch)
+	}
+	return endpoint
+}
+
+// getBranch retrieves a branch, nil if it doesn't exist
+func (p *APIBasedProvider) getBranch(name string) (*apiBranch, error) {
+	endpoint := fmt.Sprintf("/repos/%s/%s/branches/%s", p.repoOwner, p.repoName, url.PathEscape(name))
+
+	// Use a direct HTTP request as makeAPIRequest reports a missing branch like a missing repository
+	req, err := http.NewRequest("GET", p.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.Config.GetGitHubToken())
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get branch: GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var branch apiBranch
+	if err := json.NewDecoder(resp.Body).Decode(&branch); err != nil {
+		return nil, fmt.Errorf("failed to decode branch: %w", err)
+	}
+	if branch.Commit.SHA == "" {
+		branch.Commit.SHA = branch.Commit.ID
+	}
+	return &branch, nil
+}
+
+// EnsureBranch creates the branch from the head of the default branch if it doesn't exist
+func (p *APIBasedProvider) EnsureBranch(name string) (bool, error) {
+	branch, err := p.getBranch(name)
+	if err != nil {
+		return false, err
+	}
+	if branch != nil {
+		return false, nil
+	}
+
+	defaultBranch, err := p.GetDefaultBranch()
+	if err != nil {
+		return false, fmt.Errorf("failed to get default branch: %w", err)
+	}
+	base, err := p.getBranch(defaultBranch)
+	if err != nil {
+		return false, err
+	}
+	if base == nil {
+		return false, fmt.Errorf("default branch %s not found, is the repository empty?", defaultBranch)
+	}
+
+	if p.gitea {
+		if err := p.createGiteaBranch(name, defaultBranch); err != nil {
+			return false, err
+		}
+	} else {
+		endpoint := fmt.Sprintf("/repos/%s/%s/git/refs", p.repoOwner, p.repoName)
+		resp, err := p.makeAPIRequest("POST", endpoint, apiCreateRefRequest{
+			Ref: "refs/heads/" + name,
+			SHA: base.Commit.SHA,
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to create branch: %w", err)
+		}
+		resp.Body.Close()
+	}
+
+	logger.Info("Branch created via API", map[string]interface{}{
+		"branch":  name,
+		"from":    defaultBranch,
+		"user_id": p.config.UserID,
+	})
+
+	return true, nil
+}
+
+// ensureCommitBranch creates the configured commit branch once before the first write
+func (p *APIBasedProvider) ensureCommitBranch() error {
+	if p.config.Branch == "" || p.branchEnsured.Load() {
+		return nil
+	}
+	if _, err := p.EnsureBranch(p.config.Branch); err != nil {
+		return fmt.Errorf("failed to create branch %s: %w", p.config.Branch, err)
+	}
+	p.branchEnsured.Store(true)
+	return nil
+}
@@ -38,6 +38,14 @@ type apiFileUpdateRequest struct {
 	Author    *apiCommitterInfo      `json:"author,omitempty"`
 }
 
+type apiFileDeleteRequest struct {
+	Message   string            `json:"message"`
+	SHA       string            `json:"sha"`
+	Branch    string            `json:"branch,omitempty"`
+	Committer *apiCommitterInfo `json:"committer,omitempty"`
+	Author    *apiCommitterInfo `json:"author,omitempty"`
+}
+
 type apiCommitterInfo struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
@@ -53,7 +61,7 @@ type apiFileUpdateResponse struct {
 
 // FileManager implementation for API provider
 func (p *APIBasedProvider) ReadFile(filename string) (string, error) {
-	endpoint := fmt.Sprintf("/repos/%s/%s/contents/%s", p.repoOwner, p.repoName, filename)
+	endpoint := p.contentsEndpoint(filename)
 	
 	resp, err := p.makeAPIRequest("GET", endpoint, nil)
 	if err != nil {
@@ -70,16 +78,23 @@ func (p *APIBasedProvider) ReadFile(filename string) (string, error) {
 		return "", fmt.Errorf("failed to decode file content: %w", err)
 	}
 
-	if fileContent.Encoding != "base64" {
+	var contentBytes []byte
+	switch fileContent.Encoding {
+	case "base64":
+		contentBytes, err = base64.StdEncoding.DecodeString(fileContent.Content)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode base64 content: %w", err)
+		}
+	case "none":
+		// Files over 1 MB come without content, read their blob instead
+		contentBytes, err = p.readBlob(fileContent.SHA)
+		if err != nil {
+			return "", fmt.Errorf("failed to read large file: %w", err)
+		}
+	default:
 		return "", fmt.Errorf("unsupported file encoding: %s", fileContent.Encoding)
 	}
 
-	// Decode base64 content
-	contentBytes, err := base64.StdEncoding.DecodeString(fileContent.Content)
-	if err != nil {
-		return "", fmt.Errorf("failed to decode base64 content: %w", err)
-	}
-
 	logger.Debug("File read via API", map[string]interface{}{
 		"filename": filename,
 		"size":     fileContent.Size,
@@ -90,9 +105,37 @@ func (p *APIBasedProvider) ReadFile(filename string) (string, error) {
 	return string(contentBytes), nil
 }
 
+// ListDirectory lists the files and folders at path using the Contents API
+func (p *APIBasedProvider) ListDirectory(path string) ([]DirectoryEntry, error) {
+	endpoint := p.contentsEndpoint(strings.Trim(path, "/"))
+
+	resp, err := p.makeAPIRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var items []apiFileContent
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, fmt.Errorf("failed to decode directory listing (is %s a file?): %w", path, err)
+	}
+
+	entries := make([]DirectoryEntry, 0, len(items))
+	for _, item := range items {
+		entries = append(entries, DirectoryEntry{
+			Name: item.Name,
+			Path: item.Path,
+			Type: item.Type,
+			Size: int64(item.Size),
+		})
+	}
+
+	return entries, nil
+}
+
 // fileExists checks if a file exists in the repository
 func (p *APIBasedProvider) fileExists(filename string) bool {
-	endpoint := fmt.Sprintf("/repos/%s/%s/contents/%s", p.repoOwner, p.repoName, filename)
+	endpoint := p.contentsEndpoint(filename)
 	
 	// Use a direct HTTP request instead of makeAPIRequest to avoid error conversion
 	url := p.baseURL + endpoint
@@ -123,15 +166,22 @@ func (p *APIBasedProvider) CommitFileWithAuthor(filename, content, commitMessage
 	return p.CommitFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor, p.config.PremiumLevel)
 }
 
+// CommitFileWithResult prepends content to a file and returns details of the created commit
+func (p *APIBasedProvider) CommitFileWithResult(filename, content, commitMessage, customAuthor string, premiumLevel int) (*CommitResult, error) {
+	return p.updateFileContent(filename, content, commitMessage, customAuthor, true) // true = prepend mode
+}
+
 func (p *APIBasedProvider) CommitFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
 	// For msg2git's use case, CommitFile means "prepend" to existing file
-	return p.updateFileContent(filename, content, commitMessage, customAuthor, true) // true = prepend mode
+	_, err := p.updateFileContent(filename, content, commitMessage, customAuthor, true) // true = prepend mode
+	return err
 }
 
 // CommitFileWithAuthorAndPremiumLocked performs file commit with the assumption that the file is already locked
 func (p *APIBasedProvider) CommitFileWithAuthorAndPremiumLocked(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
 	// For msg2git's use case, CommitFile means "prepend" to existing file
-	return p.updateFileContentLocked(filename, content, commitMessage, customAuthor, true) // true = prepend mode
+	_, err := p.updateFileContentLocked(filename, content, commitMessage, customAuthor, true) // true = prepend mode
+	return err
 }
 
 func (p *APIBasedProvider) ReplaceFile(filename, content, commitMessage string) error {
@@ -144,21 +194,21 @@ func (p *APIBasedProvider) ReplaceFileWithAuthor(filename, content, commitMessag
 
 func (p *APIBasedProvider) ReplaceFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
 	// Replace mode - completely replace file content
-	return p.updateFileContent(filename, content, commitMessage, customAuthor, false) // false = replace mode
+	_, err := p.updateFileContent(filename, content, commitMessage, customAuthor, false) // false = replace mode
+	return err
 }
 
 // ReplaceFileWithAuthorAndPremiumLocked performs file replacement with the assumption that the file is already locked
 func (p *APIBasedProvider) ReplaceFileWithAuthorAndPremiumLocked(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
 	// Replace mode - completely replace file content
-	return p.updateFileContentLocked(filename, content, commitMessage, customAuthor, false) // false = replace mode
+	_, err := p.updateFileContentLocked(filename, content, commitMessage, customAuthor, false) // false = replace mode
+	return err
 }
 
 func (p *APIBasedProvider) CommitBinaryFile(filename string, data []byte, commitMessage string) error {
-	// Encode binary data as base64
-	content := base64.StdEncoding.EncodeToString(data)
-	
-	// Binary files are always replaced, not prepended
-	return p.updateFileContent(filename, content, commitMessage, p.config.Config.GetCommitAuthor(), false)
+	// Binary files are always replaced, not prepended. The content is base64 encoded for the request only.
+	_, err := p.updateFileContent(filename, string(data), commitMessage, p.config.Config.GetCommitAuthor(), false)
+	return err
 }
 
 func (p *APIBasedProvider) ReplaceMultipleFilesWithAuthorAndPremium(files map[string]string, commitMessage, customAuthor string, premiumLevel int) error {
@@ -224,41 +274,33 @@ func (p *APIBasedProvider) replaceMultipleFilesWithLocks(ctx context.Context, fl
 
 // ReplaceMultipleFilesWithAuthorAndPremiumLocked performs the actual file replacement with the assumption that all files are locked
 func (p *APIBasedProvider) ReplaceMultipleFilesWithAuthorAndPremiumLocked(files map[string]string, commitMessage, customAuthor string, premiumLevel int) error {
+	if err := checkWriteQuota(p.config.WriteQuota); err != nil {
+		return err
+	}
+
 	logger.Debug("Starting locked multiple file replacement via API", map[string]interface{}{
 		"file_count": len(files),
 		"user_id":    p.config.UserID,
 	})
 	
-	// For multiple files, we need to use Git Data API for atomic commits
-	// For now, we'll implement it as sequential operations with all locks held
-	// TODO: Implement true atomic multi-file commits using Git Data API
-	
-	logger.Info("Committing multiple files via API with file locks", map[string]interface{}{
-		"file_count": len(files),
-		"user_id":    p.config.UserID,
-	})
-
+	// All files go into a single commit through the Git Data API
+	changes := make(map[string][]byte, len(files))
 	for filename, content := range files {
-		// Use the locked version of the file operation
-		if err := p.updateFileContentLocked(filename, content, commitMessage, customAuthor, false); err != nil {
-			return fmt.Errorf("failed to commit file %s: %w", filename, err)
-		}
+		changes[filename] = []byte(content)
+	}
+	if _, err := p.commitChanges(changes, commitMessage, customAuthor); err != nil {
+		return fmt.Errorf("failed to commit files: %w", err)
 	}
-
-	logger.Info("Multiple files replaced via API with file locks", map[string]interface{}{
-		"file_count": len(files),
-		"user_id":    p.config.UserID,
-	})
 
 	return nil
 }
 
 // updateFileContent is the core method that handles both prepend and replace operations
-func (p *APIBasedProvider) updateFileContent(filename, newContent, commitMessage, customAuthor string, prependMode bool) error {
+func (p *APIBasedProvider) updateFileContent(filename, newContent, commitMessage, customAuthor string, prependMode bool) (*CommitResult, error) {
 	// Get user ID for file locking
 	userID, err := p.getUserIDForLocking()
 	if err != nil {
-		return fmt.Errorf("failed to get user ID for locking: %w", err)
+		return nil, fmt.Errorf("failed to get user ID for locking: %w", err)
 	}
 	
 	// Get repository URL for locking
@@ -269,13 +311,21 @@ func (p *APIBasedProvider) updateFileContent(filename, newContent, commitMessage
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 	
-	return flm.WithFileLock(ctx, userID, repoURL, filename, true, func() error {
-		return p.updateFileContentLocked(filename, newContent, commitMessage, customAuthor, prependMode)
+	var result *CommitResult
+	err = flm.WithFileLock(ctx, userID, repoURL, filename, true, func() error {
+		var lockedErr error
+		result, lockedErr = p.updateFileContentLocked(filename, newContent, commitMessage, customAuthor, prependMode)
+		return lockedErr
 	})
+	return result, err
 }
 
 // updateFileContentLocked performs the actual file update with the assumption that the file is locked
-func (p *APIBasedProvider) updateFileContentLocked(filename, newContent, commitMessage, customAuthor string, prependMode bool) error {
+func (p *APIBasedProvider) updateFileContentLocked(filename, newContent, commitMessage, customAuthor string, prependMode bool) (*CommitResult, error) {
+	if err := checkWriteQuota(p.config.WriteQuota); err != nil {
+		return nil, err
+	}
+
 	var finalContent string
 	var currentSHA string
 
@@ -285,6 +335,11 @@ func (p *APIBasedProvider) updateFileContentLocked(filename, newContent, commitM
 		"user_id":      p.config.UserID,
 	})
 
+	// A new commit branch is created before its files are read
+	if err := p.ensureCommitBranch(); err != nil {
+		return nil, err
+	}
+
 	// Check if file exists
 	fileExists := p.fileExists(filename)
 
@@ -295,24 +350,20 @@ func (p *APIBasedProvider) updateFileContentLocked(filename, newContent, commitM
 		if fileExists {
 			content, err := p.ReadFile(filename)
 			if err != nil {
-				return fmt.Errorf("failed to read existing file: %w", err)
+				return nil, fmt.Errorf("failed to read existing file: %w", err)
 			}
 			existingContent = content
 			
 			// Get current file SHA for update
 			sha, err := p.getFileSHA(filename)
 			if err != nil {
-				return fmt.Errorf("failed to get file SHA: %w", err)
+				return nil, fmt.Errorf("failed to get file SHA: %w", err)
 			}
 			currentSHA = sha
 		}
 
-		// Prepend new content to existing content
-		if existingContent == "" {
-			finalContent = newContent
-		} else {
-			finalContent = newContent + "\n" + existingContent
-		}
+		// Prepend new content to existing content, below the entries marker of templated files
+		finalContent = PrependEntry(existingContent, newContent, "\n")
 	} else {
 		// Replace mode - use new content as-is
 		finalContent = newContent
@@ -328,19 +379,19 @@ func (p *APIBasedProvider) updateFileContentLocked(filename, newContent, commitM
 	// Parse author information
 	author := parseCommitAuthor(customAuthor)
 
-	// Get the actual default branch
-	defaultBranch, err := p.GetDefaultBranch()
+	// Commit to the user's branch, or the actual default branch
+	branch, err := p.commitBranch()
 	if err != nil {
-		return fmt.Errorf("failed to get default branch: %w", err)
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
 	}
 
 	// Prepare the update request
 	updateRequest := apiFileUpdateRequest{
 		Message: commitMessage,
 		Content: base64.StdEncoding.EncodeToString([]byte(finalContent)),
-		Branch:  defaultBranch,
+		Branch:  branch,
 		Author:  author,
-		Committer: author,
+		Committer: p.apiCommitter(author),
 	}
 
 	// Include SHA if file exists (for updates)
@@ -357,15 +408,17 @@ func (p *APIBasedProvider) updateFileContentLocked(filename, newContent, commitM
 
 	// Make the API call
 	endpoint := fmt.Sprintf("/repos/%s/%s/contents/%s", p.repoOwner, p.repoName, filename)
-	resp, err := p.makeAPIRequest("PUT", endpoint, updateRequest)
+	start := time.Now()
+	resp, err := p.makeAPIRequest(p.contentsWriteMethod(fileExists), endpoint, updateRequest)
+	recordGitOperation("commit", metricsProviderAPI, start, err)
 	if err != nil {
-		return fmt.Errorf("failed to update file: %w", err)
+		return nil, fmt.Errorf("failed to update file: %w", err)
 	}
 	defer resp.Body.Close()
 
 	var updateResponse apiFileUpdateResponse
 	if err := json.NewDecoder(resp.Body).Decode(&updateResponse); err != nil {
-		return fmt.Errorf("failed to decode update response: %w", err)
+		return nil, fmt.Errorf("failed to decode update response: %w", err)
 	}
 
 	logger.Info("File updated via API with file lock", map[string]interface{}{
@@ -376,6 +429,122 @@ func (p *APIBasedProvider) updateFileContentLocked(filename, newContent, commitM
 		"user_id":      p.config.UserID,
 	})
 
+	return &CommitResult{
+		Filename: filename,
+		SHA:      updateResponse.Commit.SHA,
+		URL:      updateResponse.Commit.HTMLURL,
+		FileSize: int64(updateResponse.Content.Size),
+	}, nil
+}
+
+// MoveFile moves a file to newPath in a single commit.
+// The Contents API has no rename, the commit writes newPath and deletes oldPath through the Git Data API.
+func (p *APIBasedProvider) MoveFile(oldPath, newPath, commitMessage, customAuthor string) error {
+	userID, err := p.getUserIDForLocking()
+	if err != nil {
+		return fmt.Errorf("failed to get user ID for locking: %w", err)
+	}
+
+	repoURL := fmt.Sprintf("%s/%s", p.repoOwner, p.repoName)
+
+	flm := GetFileLockManager()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// Lock both paths in a deterministic order to prevent deadlocks
+	paths := []string{oldPath, newPath}
+	sort.Strings(paths)
+	for _, path := range paths {
+		handle, err := flm.AcquireFileLock(ctx, userID, repoURL, path, true)
+		if err != nil {
+			return fmt.Errorf("failed to acquire lock for file %s: %w", path, err)
+		}
+		defer handle.Release()
+	}
+
+	if !p.fileExists(oldPath) {
+		return fmt.Errorf("file %s does not exist", oldPath)
+	}
+	if p.fileExists(newPath) {
+		return fmt.Errorf("file %s already exists", newPath)
+	}
+
+	content, err := p.ReadFile(oldPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	changes := map[string][]byte{newPath: []byte(content), oldPath: nil}
+	if _, err := p.commitChanges(changes, commitMessage, customAuthor); err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+
+	logger.Info("File moved via API", map[string]interface{}{
+		"old_path": oldPath,
+		"new_path": newPath,
+		"user_id":  p.config.UserID,
+	})
+
+	return nil
+}
+
+// DeleteFile removes a file from the repository
+func (p *APIBasedProvider) DeleteFile(filename, commitMessage, customAuthor string) error {
+	userID, err := p.getUserIDForLocking()
+	if err != nil {
+		return fmt.Errorf("failed to get user ID for locking: %w", err)
+	}
+
+	repoURL := fmt.Sprintf("%s/%s", p.repoOwner, p.repoName)
+
+	flm := GetFileLockManager()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	return flm.WithFileLock(ctx, userID, repoURL, filename, true, func() error {
+		return p.deleteFileLocked(filename, commitMessage, customAuthor)
+	})
+}
+
+// deleteFileLocked performs the actual file deletion with the assumption that the file is locked
+func (p *APIBasedProvider) deleteFileLocked(filename, commitMessage, customAuthor string) error {
+	if err := p.ensureCommitBranch(); err != nil {
+		return err
+	}
+
+	sha, err := p.getFileSHA(filename)
+	if err != nil {
+		return fmt.Errorf("failed to get file SHA: %w", err)
+	}
+
+	branch, err := p.commitBranch()
+	if err != nil {
+		return fmt.Errorf("failed to get default branch: %w", err)
+	}
+
+	author := parseCommitAuthor(customAuthor)
+	deleteRequest := apiFileDeleteRequest{
+		Message:   commitMessage,
+		SHA:       sha,
+		Branch:    branch,
+		Author:    author,
+		Committer: p.apiCommitter(author),
+	}
+
+	endpoint := fmt.Sprintf("/repos/%s/%s/contents/%s", p.repoOwner, p.repoName, filename)
+	start := time.Now()
+	resp, err := p.makeAPIRequest("DELETE", endpoint, deleteRequest)
+	recordGitOperation("commit", metricsProviderAPI, start, err)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	logger.Info("File deleted via API", map[string]interface{}{
+		"filename": filename,
+		"user_id":  p.config.UserID,
+	})
+
 	return nil
 }
 
@@ -416,7 +585,7 @@ func (p *APIBasedProvider) getUserIDForLocking() (int64, error) {
 
 // getFileSHA retrieves the current SHA of a file (needed for updates)
 func (p *APIBasedProvider) getFileSHA(filename string) (string, error) {
-	endpoint := fmt.Sprintf("/repos/%s/%s/contents/%s", p.repoOwner, p.repoName, filename)
+	endpoint := p.contentsEndpoint(filename)
 	
 	resp, err := p.makeAPIRequest("GET", endpoint, nil)
 	if err != nil {
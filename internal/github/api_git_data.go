@@ -0,0 +1,336 @@
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// The Contents API commits one file per request. Changes to several files (replacing a set of
+// files, moving one) are committed at once through the Git Data API instead: blobs for the new
+// contents, a tree on top of the branch head's, a commit of that tree, then the branch ref is moved
+// to it. Gitea has no Git Data API for writing and takes the changes in one request (see
+// changeGiteaFiles).
+
+// gitDataCommitAttempts is how often a commit is rebuilt on the new head when the branch moved
+// while it was created
+const gitDataCommitAttempts = 3
+
+// apiHistoryMaxPages bounds the pages of commits a history read requests
+const apiHistoryMaxPages = 50
+
+// errEmptyRepository is returned by the Git Data API of a repository without commits
+var errEmptyRepository = errors.New("repository is empty")
+
+type apiGitRef struct {
+	Object struct {
+		SHA string `json:"sha"`
+	} `json:"object"`
+}
+
+type apiGitCommit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Tree    struct {
+		SHA string `json:"sha"`
+	} `json:"tree"`
+}
+
+type apiGitBlob struct {
+	SHA      string `json:"sha,omitempty"`
+	Content  string `json:"content"`
+	Encoding string `json:"encoding"`
+}
+
+// apiGitTreeEntry is a file of a new tree, a nil SHA deletes it
+type apiGitTreeEntry struct {
+	Path string  `json:"path"`
+	Mode string  `json:"mode"`
+	Type string  `json:"type"`
+	SHA  *string `json:"sha"`
+}
+
+type apiGitTreeRequest struct {
+	BaseTree string            `json:"base_tree"`
+	Tree     []apiGitTreeEntry `json:"tree"`
+}
+
+type apiGitCommitRequest struct {
+	Message   string            `json:"message"`
+	Tree      string            `json:"tree"`
+	Parents   []string          `json:"parents"`
+	Author    *apiCommitterInfo `json:"author,omitempty"`
+	Committer *apiCommitterInfo `json:"committer,omitempty"`
+}
+
+type apiGitRefUpdateRequest struct {
+	SHA   string `json:"sha"`
+	Force bool   `json:"force"`
+}
+
+type apiCommitListItem struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Author struct {
+			Name  string    `json:"name"`
+			Email string    `json:"email"`
+			Date  time.Time `json:"date"`
+		} `json:"author"`
+	} `json:"commit"`
+}
+
+// commitChanges commits the files in a single commit on the commit branch, a nil content deletes
+// the file. Callers hold the locks of the files.
+func (p *APIBasedProvider) commitChanges(files map[string][]byte, commitMessage, customAuthor string) (*CommitResult, error) {
+	if err := p.ensureCommitBranch(); err != nil {
+		return nil, err
+	}
+	branch, err := p.commitBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
+	}
+	author := parseCommitAuthor(customAuthor)
+
+	start := time.Now()
+	var result *CommitResult
+	if p.gitea {
+		result, err = p.changeGiteaFiles(branch, files, commitMessage, author)
+	} else {
+		result, err = p.createGitDataCommit(branch, files, commitMessage, author)
+	}
+	if errors.Is(err, errEmptyRepository) {
+		// Only the Contents API can make the first commit
+		return p.commitChangesSequentially(files, commitMessage, customAuthor)
+	}
+	recordGitOperation("commit", metricsProviderAPI, start, err)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Files committed via API", map[string]interface{}{
+		"file_count": len(files),
+		"commit_sha": result.SHA,
+		"user_id":    p.config.UserID,
+	})
+	return result, nil
+}
+
+// commitChangesSequentially commits the files one by one through the Contents API
+func (p *APIBasedProvider) commitChangesSequentially(files map[string][]byte, commitMessage, customAuthor string) (*CommitResult, error) {
+	var result *CommitResult
+	for _, path := range sortedPaths(files) {
+		if files[path] == nil {
+			if err := p.deleteFileLocked(path, commitMessage, customAuthor); err != nil {
+				return nil, fmt.Errorf("failed to delete file %s: %w", path, err)
+			}
+			continue
+		}
+		committed, err := p.updateFileContentLocked(path, string(files[path]), commitMessage, customAuthor, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit file %s: %w", path, err)
+		}
+		result = committed
+	}
+	if result == nil {
+		result = &CommitResult{}
+	}
+	return result, nil
+}
+
+// createGitDataCommit commits the files on branch through the Git Data API
+func (p *APIBasedProvider) createGitDataCommit(branch string, files map[string][]byte, commitMessage string, author *apiCommitterInfo) (*CommitResult, error) {
+	head, err := p.getRefSHA(branch)
+	if err != nil {
+		return nil, err
+	}
+
+	// Blobs don't depend on the head, a rebuilt commit reuses them
+	entries := make([]apiGitTreeEntry, 0, len(files))
+	for _, path := range sortedPaths(files) {
+		entry := apiGitTreeEntry{Path: path, Mode: "100644", Type: "blob"}
+		if files[path] != nil {
+			sha, err := p.createBlob(files[path])
+			if err != nil {
+				return nil, err
+			}
+			entry.SHA = &sha
+		}
+		entries = append(entries, entry)
+	}
+
+	for attempt := 1; ; attempt++ {
+		commit, err := p.createCommitOn(head, entries, commitMessage, author)
+		if err != nil {
+			return nil, err
+		}
+
+		endpoint := fmt.Sprintf("/repos/%s/%s/git/refs/heads/%s", p.repoOwner, p.repoName, branch)
+		resp, err := p.makeAPIRequest("PATCH", endpoint, apiGitRefUpdateRequest{SHA: commit.SHA})
+		if err == nil {
+			resp.Body.Close()
+			return &CommitResult{SHA: commit.SHA, URL: commit.HTMLURL}, nil
+		}
+		if attempt == gitDataCommitAttempts || !strings.Contains(err.Error(), "not a fast forward") {
+			return nil, fmt.Errorf("failed to update branch %s: %w", branch, err)
+		}
+
+		logger.Warn("Branch moved while committing via API, retrying on its new head", map[string]interface{}{
+			"branch":  branch,
+			"attempt": attempt,
+			"user_id": p.config.UserID,
+		})
+		if head, err = p.getRefSHA(branch); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// createCommitOn creates a commit changing the entries of the tree of the commit parent
+func (p *APIBasedProvider) createCommitOn(parent string, entries []apiGitTreeEntry, commitMessage string, author *apiCommitterInfo) (*apiGitCommit, error) {
+	var parentCommit apiGitCommit
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/%s/git/commits/%s", p.repoOwner, p.repoName, parent), &parentCommit); err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %w", parent, err)
+	}
+
+	var tree apiGitCommit
+	treeRequest := apiGitTreeRequest{BaseTree: parentCommit.Tree.SHA, Tree: entries}
+	if err := p.postJSON(fmt.Sprintf("/repos/%s/%s/git/trees", p.repoOwner, p.repoName), treeRequest, &tree); err != nil {
+		return nil, fmt.Errorf("failed to create tree: %w", err)
+	}
+
+	var commit apiGitCommit
+	commitRequest := apiGitCommitRequest{
+		Message:   commitMessage,
+		Tree:      tree.SHA,
+		Parents:   []string{parent},
+		Author:    author,
+		Committer: p.apiCommitter(author),
+	}
+	if err := p.postJSON(fmt.Sprintf("/repos/%s/%s/git/commits", p.repoOwner, p.repoName), commitRequest, &commit); err != nil {
+		return nil, fmt.Errorf("failed to create commit: %w", err)
+	}
+	return &commit, nil
+}
+
+// getRefSHA returns the commit the branch points to, errEmptyRepository if the repository has none
+func (p *APIBasedProvider) getRefSHA(branch string) (string, error) {
+	var ref apiGitRef
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/%s/git/ref/heads/%s", p.repoOwner, p.repoName, branch), &ref); err != nil {
+		if strings.Contains(err.Error(), "API error 409") {
+			return "", errEmptyRepository
+		}
+		return "", fmt.Errorf("failed to get branch %s: %w", branch, err)
+	}
+	return ref.Object.SHA, nil
+}
+
+// createBlob stores content in the repository, returning its SHA
+func (p *APIBasedProvider) createBlob(content []byte) (string, error) {
+	var blob apiGitBlob
+	request := apiGitBlob{Content: base64.StdEncoding.EncodeToString(content), Encoding: "base64"}
+	if err := p.postJSON(fmt.Sprintf("/repos/%s/%s/git/blobs", p.repoOwner, p.repoName), request, &blob); err != nil {
+		return "", fmt.Errorf("failed to create blob: %w", err)
+	}
+	return blob.SHA, nil
+}
+
+// readBlob returns the content of a blob, which the Contents API leaves out for files over 1 MB
+func (p *APIBasedProvider) readBlob(sha string) ([]byte, error) {
+	var blob apiGitBlob
+	if err := p.getJSON(fmt.Sprintf("/repos/%s/%s/git/blobs/%s", p.repoOwner, p.repoName, sha), &blob); err != nil {
+		return nil, fmt.Errorf("failed to read blob %s: %w", sha, err)
+	}
+	if blob.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported blob encoding: %s", blob.Encoding)
+	}
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(blob.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode blob %s: %w", sha, err)
+	}
+	return content, nil
+}
+
+// CommitHistory returns the commits of the commit branch authored since the given time, newest
+// first, from the latest apiHistoryMaxPages pages of the commit list
+func (p *APIBasedProvider) CommitHistory(since time.Time) ([]HistoryCommit, error) {
+	branch, err := p.commitBranch()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default branch: %w", err)
+	}
+
+	var commits []HistoryCommit
+	pageQuery, pageSize := p.pageQuery(100)
+	for page := 1; page <= apiHistoryMaxPages && len(commits) < maxHistoryCommits; page++ {
+		endpoint := fmt.Sprintf("/repos/%s/%s/commits?sha=%s&since=%s&%s&page=%d", p.repoOwner, p.repoName,
+			url.QueryEscape(branch), url.QueryEscape(since.UTC().Format(time.RFC3339)), pageQuery, page)
+
+		var pageCommits []apiCommitListItem
+		if err := p.getJSON(endpoint, &pageCommits); err != nil {
+			if strings.Contains(err.Error(), "API error 409") {
+				return nil, nil // No history yet
+			}
+			return nil, fmt.Errorf("failed to list commits: %w", err)
+		}
+
+		for _, item := range pageCommits {
+			// Gitea before 1.22 ignores since
+			if item.Commit.Author.Date.Before(since) {
+				continue
+			}
+			commits = append(commits, HistoryCommit{
+				Hash:        item.SHA,
+				AuthorName:  item.Commit.Author.Name,
+				AuthorEmail: item.Commit.Author.Email,
+				When:        item.Commit.Author.Date,
+			})
+		}
+		if len(pageCommits) < pageSize {
+			break
+		}
+	}
+
+	return commits, nil
+}
+
+// getJSON decodes the response to a GET of endpoint into v
+func (p *APIBasedProvider) getJSON(endpoint string, v interface{}) error {
+	resp, err := p.makeAPIRequest("GET", endpoint, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// postJSON posts body to endpoint and decodes the response into v
+func (p *APIBasedProvider) postJSON(endpoint string, body, v interface{}) error {
+	resp, err := p.makeAPIRequest("POST", endpoint, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// sortedPaths returns the paths of files in a deterministic order
+func sortedPaths(files map[string][]byte) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
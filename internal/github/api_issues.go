@@ -4,22 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/msg2git/msg2git/internal/logger"
 )
 
 // GitHub Issues API structures
 type apiIssueRequest struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
 }
 
 type apiIssueResponse struct {
-	ID      int    `json:"id"`
-	Number  int    `json:"number"`
-	Title   string `json:"title"`
-	State   string `json:"state"`
-	HTMLURL string `json:"html_url"`
+	ID       int        `json:"id"`
+	Number   int        `json:"number"`
+	Title    string     `json:"title"`
+	State    string     `json:"state"`
+	HTMLURL  string     `json:"html_url"`
+	ClosedAt *time.Time `json:"closed_at"`
 }
 
 type apiCommentRequest struct {
@@ -33,11 +36,24 @@ type apiCommentResponse struct {
 
 // IssueManager implementation for API provider
 func (p *APIBasedProvider) CreateIssue(title, body string) (string, int, error) {
+	return p.CreateIssueWithLabels(title, body, nil)
+}
+
+// CreateIssueWithLabels creates an issue with labels, GitHub creates labels missing in the repository
+func (p *APIBasedProvider) CreateIssueWithLabels(title, body string, labels []string) (string, int, error) {
 	endpoint := fmt.Sprintf("/repos/%s/%s/issues", p.repoOwner, p.repoName)
 	
-	issueRequest := apiIssueRequest{
-		Title: title,
-		Body:  body,
+	var issueRequest interface{} = apiIssueRequest{
+		Title:  title,
+		Body:   body,
+		Labels: labels,
+	}
+	if p.gitea {
+		giteaRequest, err := p.giteaIssueRequest(title, body, labels)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to create issue: %w", err)
+		}
+		issueRequest = giteaRequest
 	}
 
 	resp, err := p.makeAPIRequest("POST", endpoint, issueRequest)
@@ -55,6 +71,7 @@ func (p *APIBasedProvider) CreateIssue(title, body string) (string, int, error)
 		"issue_number": issueResponse.Number,
 		"issue_title":  issueResponse.Title,
 		"issue_url":    issueResponse.HTMLURL,
+		"labels":       labels,
 		"user_id":      p.config.UserID,
 	})
 
@@ -76,10 +93,11 @@ func (p *APIBasedProvider) GetIssueStatus(issueNumber int) (*IssueStatus, error)
 	}
 
 	status := &IssueStatus{
-		Number:  issueResponse.Number,
-		Title:   issueResponse.Title,
-		State:   issueResponse.State,
-		HTMLURL: issueResponse.HTMLURL,
+		Number:   issueResponse.Number,
+		Title:    issueResponse.Title,
+		State:    issueResponse.State,
+		HTMLURL:  issueResponse.HTMLURL,
+		ClosedAt: issueResponse.ClosedAt,
 	}
 
 	return status, nil
@@ -187,6 +205,34 @@ func (p *APIBasedProvider) SyncIssueStatusesGraphQL(issueNumbers []int) (map[int
 		return make(map[int]*IssueStatus), nil
 	}
 
+	// Query in batches to stay below GitHub's GraphQL query complexity limits
+	statuses := make(map[int]*IssueStatus)
+	batches := chunkIssueNumbers(issueNumbers, graphQLIssueBatchSize)
+	logGraphQLBudget(p.graphQLBudgetKey(), len(batches))
+	for _, batch := range batches {
+		if err := affordGraphQLQuery(p.graphQLBudgetKey()); err != nil {
+			return nil, err
+		}
+		batchStatuses, err := p.syncIssueStatusesGraphQLBatch(batch)
+		if err != nil {
+			return nil, err
+		}
+		for number, status := range batchStatuses {
+			statuses[number] = status
+		}
+	}
+
+	logger.Info("Issue statuses synced via GraphQL", map[string]interface{}{
+		"synced_count": len(statuses),
+		"total_count":  len(issueNumbers),
+		"user_id":      p.config.UserID,
+	})
+
+	return statuses, nil
+}
+
+// syncIssueStatusesGraphQLBatch fetches one batch of issues with a single aliased GraphQL query
+func (p *APIBasedProvider) syncIssueStatusesGraphQLBatch(issueNumbers []int) (map[int]*IssueStatus, error) {
 	// Build GraphQL query for batch issue fetching
 	var queryParts []string
 	for i, number := range issueNumbers {
@@ -196,6 +242,7 @@ func (p *APIBasedProvider) SyncIssueStatusesGraphQL(issueNumbers []int) (map[int
 				title
 				state
 				url
+				closedAt
 			}`, i, number)
 		queryParts = append(queryParts, queryPart)
 	}
@@ -221,10 +268,11 @@ func (p *APIBasedProvider) SyncIssueStatusesGraphQL(issueNumbers []int) (map[int
 	var graphqlResponse struct {
 		Data struct {
 			Repository map[string]struct {
-				Number int    `json:"number"`
-				Title  string `json:"title"`
-				State  string `json:"state"`
-				URL    string `json:"url"`
+				Number   int        `json:"number"`
+				Title    string     `json:"title"`
+				State    string     `json:"state"`
+				URL      string     `json:"url"`
+				ClosedAt *time.Time `json:"closedAt"`
 			} `json:"repository"`
 		} `json:"data"`
 		Errors []struct {
@@ -245,20 +293,15 @@ func (p *APIBasedProvider) SyncIssueStatusesGraphQL(issueNumbers []int) (map[int
 	for _, issue := range graphqlResponse.Data.Repository {
 		if issue.Number > 0 { // Valid issue
 			status := &IssueStatus{
-				Number:  issue.Number,
-				Title:   issue.Title,
-				State:   strings.ToLower(issue.State),
-				HTMLURL: issue.URL,
+				Number:   issue.Number,
+				Title:    issue.Title,
+				State:    strings.ToLower(issue.State),
+				HTMLURL:  issue.URL,
+				ClosedAt: issue.ClosedAt,
 			}
 			statuses[issue.Number] = status
 		}
 	}
 
-	logger.Info("Issue statuses synced via GraphQL", map[string]interface{}{
-		"synced_count": len(statuses),
-		"total_count":  len(issueNumbers),
-		"user_id":      p.config.UserID,
-	})
-
 	return statuses, nil
 }
\ No newline at end of file
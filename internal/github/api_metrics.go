@@ -0,0 +1,136 @@
+package github
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/metrics"
+	"github.com/msg2git/msg2git/internal/netguard"
+)
+
+// API metrics: every GitHub client of the bot is created by NewHTTPClient, whose transport records
+// each request's API type, endpoint, status and duration, and the rate limit GitHub reports in the
+// response headers (see metrics.Default). Commits and pushes are recorded by recordGitOperation.
+
+// Providers in the labels of git operation metrics
+const (
+	metricsProviderClone = "clone"
+	metricsProviderAPI   = "api"
+)
+
+// userEndpointTransport carries requests to hosts other than the deployment's endpoints: GitHub
+// Enterprise and Gitea instances users set themselves may only be on public addresses
+var userEndpointTransport http.RoundTripper = netguard.NewTransport()
+
+// NewHTTPClient creates an HTTP client for GitHub whose requests are recorded in the metrics.
+// Requests to hosts other than the deployment's endpoints go through userEndpointTransport.
+// A zero timeout means no timeout.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: metricsTransport{next: http.DefaultTransport},
+	}
+}
+
+// metricsTransport records the requests it passes to next
+type metricsTransport struct {
+	next http.RoundTripper
+}
+
+func (t metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	next := t.next
+	if !isDeploymentHost(req.URL.Host) {
+		next = userEndpointTransport
+	}
+
+	start := time.Now()
+	resp, err := next.RoundTrip(req)
+
+	apiType, endpoint := apiEndpointLabels(req.URL.Path)
+	metrics.Default.RecordGitHubAPIRequest(apiType, endpoint, metrics.HTTPStatus(resp, err), time.Since(start))
+	if err == nil {
+		recordRateLimitHeaders(resp.Header)
+	}
+	return resp, err
+}
+
+// isDeploymentHost reports whether host serves the deployment's GitHub endpoints, the defaults or
+// the ones set with SetAPIBaseURLs, as opposed to a user's own instance
+func isDeploymentHost(host string) bool {
+	for _, endpoint := range []string{DefaultAPIBaseURL, DefaultUploadsBaseURL, APIBaseURL(), UploadsBaseURL()} {
+		if u, err := url.Parse(endpoint); err == nil && strings.EqualFold(u.Host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRateLimitHeaders updates the rate limit metrics from the X-RateLimit headers GitHub sends
+// with REST and GraphQL responses
+func recordRateLimitHeaders(header http.Header) {
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return
+	}
+	resource := header.Get("X-RateLimit-Resource")
+	if resource == "" {
+		resource = "core"
+	}
+	metrics.Default.UpdateGitHubRateLimit(resource, remaining, time.Unix(reset, 0))
+}
+
+// apiEndpointLabels returns the API type ("REST" or "GraphQL") and the endpoint template of a
+// request path, e.g. "/repos/:owner/:repo/issues/:number/comments", so requests of all users and
+// files share a few series
+func apiEndpointLabels(path string) (string, string) {
+	// GitHub Enterprise serves the API under /api/v3 and /api/graphql, Gitea under /api/v1
+	for _, prefix := range []string{"/api/v3", "/api/v1", "/api"} {
+		if strings.HasPrefix(path, prefix+"/") {
+			path = strings.TrimPrefix(path, prefix)
+			break
+		}
+	}
+	if path == "/graphql" {
+		return "GraphQL", path
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case segments[0] == "repos" && len(segments) >= 3:
+		endpoint := "/repos/:owner/:repo"
+		if len(segments) > 3 {
+			// Keep the resource and, after a number, its sub-resource; drop file paths and refs
+			endpoint += "/" + segments[3]
+			if len(segments) > 4 && isNumber(segments[4]) {
+				endpoint += "/:number"
+				if len(segments) > 5 {
+					endpoint += "/" + segments[5]
+				}
+			}
+		}
+		return "REST", endpoint
+	case (segments[0] == "users" || segments[0] == "orgs") && len(segments) >= 2:
+		return "REST", "/" + segments[0] + "/:name"
+	case len(segments) > 2:
+		return "REST", "/" + segments[0] + "/" + segments[1]
+	default:
+		return "REST", "/" + strings.Join(segments, "/")
+	}
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// recordGitOperation records a commit or push of a provider started at start
+func recordGitOperation(operation, provider string, start time.Time, err error) {
+	metrics.Default.RecordGitOperation(operation, provider, metrics.Status(err), time.Since(start))
+}
@@ -0,0 +1,101 @@
+package github
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/metrics"
+	"github.com/msg2git/msg2git/internal/netguard"
+)
+
+func TestAPIEndpointLabels(t *testing.T) {
+	tests := []struct {
+		path     string
+		apiType  string
+		endpoint string
+	}{
+		{"/graphql", "GraphQL", "/graphql"},
+		{"/api/graphql", "GraphQL", "/graphql"},
+		{"/user", "REST", "/user"},
+		{"/user/repos", "REST", "/user/repos"},
+		{"/rate_limit", "REST", "/rate_limit"},
+		{"/users/octocat", "REST", "/users/:name"},
+		{"/repos/o/r", "REST", "/repos/:owner/:repo"},
+		{"/repos/o/r/contents/notes/inbox.md", "REST", "/repos/:owner/:repo/contents"},
+		{"/api/v3/repos/o/r/contents/a.md", "REST", "/repos/:owner/:repo/contents"},
+		{"/api/v1/repos/o/r/raw/a.md", "REST", "/repos/:owner/:repo/raw"},
+		{"/repos/o/r/issues/12", "REST", "/repos/:owner/:repo/issues/:number"},
+		{"/repos/o/r/issues/12/comments", "REST", "/repos/:owner/:repo/issues/:number/comments"},
+		{"/repos/o/r/git/refs/heads/main", "REST", "/repos/:owner/:repo/git"},
+	}
+
+	for _, tt := range tests {
+		apiType, endpoint := apiEndpointLabels(tt.path)
+		if apiType != tt.apiType || endpoint != tt.endpoint {
+			t.Errorf("apiEndpointLabels(%q) = %q, %q, want %q, %q", tt.path, apiType, endpoint, tt.apiType, tt.endpoint)
+		}
+	}
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "1700000000")
+		w.Header().Set("X-RateLimit-Resource", "core")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	SetAPIBaseURLs(server.URL, "")
+	defer SetAPIBaseURLs("", "")
+
+	resp, err := NewHTTPClient(0).Get(server.URL + "/repos/o/r/contents/a.md")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want the response of the server", resp.StatusCode)
+	}
+
+	recorder := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, want := range []string{
+		`github_api_requests_total{api_type="REST",endpoint="/repos/:owner/:repo/contents",status="success"}`,
+		`github_api_rate_limit_remaining{resource="core"} 42`,
+	} {
+		if !strings.Contains(recorder.Body.String(), want) {
+			t.Errorf("/metrics misses %s", want)
+		}
+	}
+}
+
+func TestNewHTTPClientGuardsUserEndpoints(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// A user's own instance on a loopback address is refused
+	if _, err := NewHTTPClient(0).Get(server.URL + "/user"); !errors.Is(err, netguard.ErrForbiddenAddress) {
+		t.Fatalf("Get() of a user endpoint error = %v, want ErrForbiddenAddress", err)
+	}
+
+	// The deployment's own endpoints may be anywhere
+	SetAPIBaseURLs(server.URL, "")
+	defer SetAPIBaseURLs("", "")
+	resp, err := NewHTTPClient(0).Get(server.URL + "/user")
+	if err != nil {
+		t.Fatalf("Get() of the deployment endpoint error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+// allowLocalEndpoints lets user endpoints reach test servers on loopback addresses
+func allowLocalEndpoints(t *testing.T) {
+	t.Helper()
+	userEndpointTransport = http.DefaultTransport
+	t.Cleanup(func() { userEndpointTransport = netguard.NewTransport() })
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/msg2git/msg2git/internal/consts"
@@ -18,6 +19,7 @@ type APIBasedProvider struct {
 	config     *ProviderConfig
 	httpClient *http.Client
 	baseURL    string
+	uploadsURL string
 	
 	// Repository information
 	repoOwner string
@@ -30,6 +32,12 @@ type APIBasedProvider struct {
 	// Caching for repository info
 	cachedRepoInfo *apiRepositoryInfo
 	cacheExpiry    time.Time
+
+	// Whether the commit branch exists, see ensureCommitBranch
+	branchEnsured atomic.Bool
+
+	// Whether baseURL is a Gitea API, see gitea.go
+	gitea bool
 }
 
 // Ensure APIBasedProvider implements GitHubProvider interface
@@ -47,15 +55,17 @@ func newAPIProvider(config *ProviderConfig) (*APIBasedProvider, error) {
 		return nil, fmt.Errorf("invalid repository URL: %w", err)
 	}
 
+	baseURL, uploadsURL := resolveEndpoints(config.APIBaseURL, config.UploadsBaseURL)
+
 	provider := &APIBasedProvider{
 		config: config,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL:   "https://api.github.com",
+		httpClient: NewHTTPClient(30 * time.Second),
+		baseURL:    baseURL,
+		uploadsURL: uploadsURL,
 		repoOwner: owner,
 		repoName:  repo,
 		lastReset: time.Now(),
+		gitea:     IsGiteaAPIURL(baseURL),
 	}
 
 	logger.Info("API-based GitHub provider initialized", map[string]interface{}{
@@ -63,6 +73,7 @@ func newAPIProvider(config *ProviderConfig) (*APIBasedProvider, error) {
 		"repo":          repo,
 		"user_id":       config.UserID,
 		"premium_level": config.PremiumLevel,
+		"gitea":         provider.gitea,
 	})
 
 	return provider, nil
@@ -90,6 +101,11 @@ func parseRepositoryURL(repoURL string) (owner, repo string, err error) {
 		return owner, repo, nil
 	}
 	
+	// Handle GitHub Enterprise Server URLs on other hosts
+	if strings.HasPrefix(repoURL, "https://") || strings.HasPrefix(repoURL, "git@") {
+		return parseOwnerRepo(repoURL)
+	}
+
 	// Handle simple "owner/repo" format
 	pathParts := strings.Split(repoURL, "/")
 	if len(pathParts) == 2 && pathParts[0] != "" && pathParts[1] != "" {
@@ -139,6 +155,10 @@ func (p *APIBasedProvider) makeAPIRequest(method, endpoint string, body interfac
 	})
 
 	resp, err := p.httpClient.Do(req)
+	recordGitHubResponse(p.baseURL, resp, err)
+	if err == nil && endpoint == "/graphql" {
+		recordGraphQLRateLimit(p.graphQLBudgetKey(), parseGraphQLRateLimit(resp.Header))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("API request failed: %w", err)
 	}
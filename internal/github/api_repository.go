@@ -145,14 +145,37 @@ func (p *APIBasedProvider) GetGitHubFileURL(filename string) (string, error) {
 }
 
 func (p *APIBasedProvider) GetGitHubFileURLWithBranch(filename string) (string, error) {
-	// Get the actual default branch
-	defaultBranch, err := p.GetDefaultBranch()
+	// Link to the user's branch, or the actual default branch
+	branch, err := p.commitBranch()
 	if err != nil {
 		return "", fmt.Errorf("failed to get default branch: %w", err)
 	}
 
-	// For API provider, we can construct the URL directly using the actual default branch
-	url := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", p.repoOwner, p.repoName, defaultBranch, filename)
+	// For API provider, we can construct the URL directly using the branch
+	blobPath := "blob"
+	if p.gitea {
+		blobPath = "src/branch"
+	}
+	url := fmt.Sprintf("%s/%s/%s/%s", repoWebURL(p.config.Config.GetGitHubRepo(), p.repoOwner, p.repoName), blobPath, branch, filename)
 	return url, nil
 }
 
+
+// CreateCommitStatus attaches a status to a commit so bot commits are recognizable on GitHub
+func (p *APIBasedProvider) CreateCommitStatus(sha string, status *CommitStatus) error {
+	endpoint := fmt.Sprintf("/repos/%s/%s/statuses/%s", p.repoOwner, p.repoName, sha)
+
+	resp, err := p.makeAPIRequest("POST", endpoint, status)
+	if err != nil {
+		return fmt.Errorf("failed to create commit status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	logger.Debug("Commit status created via API", map[string]interface{}{
+		"sha":     sha,
+		"context": status.Context,
+		"user_id": p.config.UserID,
+	})
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Atomic writes: files in a working copy are replaced through a synced temp file and a rename, so a
+// crash leaves either the old or the new content, never a truncated file that the next commit would push
+
+// atomicTempPrefix starts the names of temp files of writes in progress
+const atomicTempPrefix = ".msg2git-write-"
+
+// writeFileAtomic replaces filePath with data, creating parent directories as needed
+func writeFileAtomic(filePath string, data []byte, perm os.FileMode) error {
+	return writeFileAtomicFunc(filePath, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeFileAtomicFunc replaces filePath with what write writes, so content can be streamed
+func writeFileAtomicFunc(filePath string, perm os.FileMode, write func(w io.Writer) error) error {
+	dir := filepath.Dir(filePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create parent directories: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, atomicTempPrefix+"*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	renamed := false
+	defer func() {
+		if !renamed {
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		return fmt.Errorf("failed to set file permissions: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, filePath); err != nil {
+		return fmt.Errorf("failed to replace file: %w", err)
+	}
+	renamed = true
+
+	// Persist the rename itself; not every platform can sync a directory, which only weakens durability
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
+
+// isAtomicTempFile reports whether a worktree path is the temp file of an interrupted write
+func isAtomicTempFile(file string) bool {
+	name := path.Base(filepath.ToSlash(file))
+	return strings.HasPrefix(name, atomicTempPrefix) && strings.HasSuffix(name, ".tmp")
+}
+
+// verifyWorktree checks the working copy before files are staged: temp files of interrupted writes
+// are removed, and changes staged outside this commit, which it would pick up, are reported
+func (m *Manager) verifyWorktree(worktree *git.Worktree, filenames ...string) error {
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree status: %w", err)
+	}
+
+	committing := make(map[string]bool, len(filenames))
+	for _, filename := range filenames {
+		committing[filepath.ToSlash(filepath.Clean(filename))] = true
+	}
+
+	for file, fileStatus := range status {
+		if isAtomicTempFile(file) {
+			if err := m.files().Remove(file); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove leftover temp file %s: %w", file, err)
+			}
+			logger.Warn("Removed temp file of an interrupted write", map[string]interface{}{
+				"repo_path": m.repoPath,
+				"file":      file,
+			})
+			continue
+		}
+
+		if committing[file] {
+			continue
+		}
+		if fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			logger.Warn("Worktree has changes staged outside this commit", map[string]interface{}{
+				"repo_path": m.repoPath,
+				"file":      file,
+				"status":    string(fileStatus.Staging),
+			})
+		}
+	}
+
+	return nil
+}
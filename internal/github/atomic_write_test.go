@@ -0,0 +1,99 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "notes", "inbox.md")
+
+	if err := writeFileAtomic(filePath, []byte("first"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+	if err := writeFileAtomic(filePath, []byte("second"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "second" {
+		t.Errorf("content = %q, want second", data)
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0644 {
+		t.Errorf("mode = %v, want 0644", info.Mode().Perm())
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(filePath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the file", len(entries))
+	}
+}
+
+func TestIsAtomicTempFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{".msg2git-write-123.tmp", true},
+		{"notes/.msg2git-write-abc.tmp", true},
+		{"notes/inbox.md", false},
+		{".msg2git-write-123", false},
+		{"backup.tmp", false},
+	}
+
+	for _, tt := range tests {
+		if got := isAtomicTempFile(tt.file); got != tt.want {
+			t.Errorf("isAtomicTempFile(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyWorktreeRemovesLeftoverTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leftover := filepath.Join(dir, "notes", atomicTempPrefix+"1.tmp")
+	if err := os.MkdirAll(filepath.Dir(leftover), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(leftover, []byte("half a no"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, "notes", "inbox.md"), []byte("note"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &Manager{repoPath: dir, repo: repo}
+	if err := m.verifyWorktree(worktree, "notes/inbox.md"); err != nil {
+		t.Fatalf("verifyWorktree() error = %v", err)
+	}
+
+	if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+		t.Errorf("leftover temp file still exists, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes", "inbox.md")); err != nil {
+		t.Errorf("file being committed removed: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package github
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Commit branch of clone-based providers: clones are shared by every user of a repository, so
+// each pull checks out the branch of the user at hand (Manager.branch, the default branch if
+// empty) before syncing it. A branch missing on GitHub starts at the default branch and is
+// created there by the first push.
+
+// defaultBranches caches the default branch of each clone's remote, repoPath -> branch name
+var defaultBranches sync.Map
+
+// ValidateBranchName checks that name can be used as a branch name
+func ValidateBranchName(name string) error {
+	if name == "" || name == "HEAD" {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	if err := plumbing.NewBranchReferenceName(name).Validate(); err != nil {
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
+
+// commitBranch returns the branch notes are committed to
+func (m *Manager) commitBranch() string {
+	if m.branch != "" {
+		return m.branch
+	}
+	return m.defaultBranch()
+}
+
+// defaultBranch returns the default branch of the remote, asking GitHub once per clone unless the
+// clone only has the branch it was cloned with
+func (m *Manager) defaultBranch() string {
+	if cached, ok := defaultBranches.Load(m.repoPath); ok {
+		return cached.(string)
+	}
+
+	head, err := m.repo.Head()
+	if err != nil {
+		return "main"
+	}
+	if branches, err := m.repo.Branches(); err == nil {
+		count := 0
+		branches.ForEach(func(*plumbing.Reference) error {
+			count++
+			return nil
+		})
+		if count == 1 && head.Name().IsBranch() {
+			return head.Name().Short()
+		}
+	}
+
+	remote, err := m.repo.Remote("origin")
+	if err != nil {
+		return head.Name().Short()
+	}
+	refs, err := remote.List(&git.ListOptions{
+		Auth: &githttp.BasicAuth{
+			Username: m.cfg.GitHubUsername,
+			Password: m.cfg.GitHubToken,
+		},
+	})
+	if err != nil {
+		logger.Warn("Failed to look up default branch, using the checked out branch", map[string]interface{}{
+			"repo_path": m.repoPath,
+			"error":     err.Error(),
+		})
+		return head.Name().Short()
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference {
+			branch := ref.Target().Short()
+			defaultBranches.Store(m.repoPath, branch)
+			return branch
+		}
+	}
+	return head.Name().Short()
+}
+
+// checkoutBranch checks out the commit branch, creating the local branch at its remote head or,
+// for a new branch, at the head of the default branch. Returns whether the branch changed.
+func (m *Manager) checkoutBranch() (bool, error) {
+	head, err := m.repo.Head()
+	if err != nil {
+		return false, nil // Nothing committed yet
+	}
+
+	target := m.commitBranch()
+	local := plumbing.NewBranchReferenceName(target)
+	if head.Name() == local {
+		return false, nil
+	}
+
+	worktree, err := m.repo.Worktree()
+	if err != nil {
+		return false, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	options := &git.CheckoutOptions{Branch: local, Force: true}
+	if _, err := m.repo.Reference(local, false); err != nil {
+		options.Create = true
+		options.Hash = head.Hash()
+		if ref, err := m.repo.Reference(plumbing.NewRemoteReferenceName("origin", target), true); err == nil {
+			options.Hash = ref.Hash()
+		} else if ref, err := m.repo.Reference(plumbing.NewRemoteReferenceName("origin", m.defaultBranch()), true); err == nil {
+			options.Hash = ref.Hash()
+		}
+	}
+
+	if err := m.checkout(worktree, options); err != nil {
+		return false, fmt.Errorf("failed to check out branch %s: %w", target, err)
+	}
+
+	logger.Info("Checked out commit branch", map[string]interface{}{
+		"repo_path": m.repoPath,
+		"branch":    target,
+		"created":   options.Create,
+	})
+
+	return true, nil
+}
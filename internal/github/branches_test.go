@@ -0,0 +1,77 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+)
+
+func TestValidateBranchName(t *testing.T) {
+	for _, name := range []string{"notes", "inbox/2026", "feature-1"} {
+		if err := ValidateBranchName(name); err != nil {
+			t.Errorf("ValidateBranchName(%q) = %v, want valid", name, err)
+		}
+	}
+	for _, name := range []string{"", "HEAD", "bad name", "a..b", "ends.lock", "-x/"} {
+		if err := ValidateBranchName(name); err == nil {
+			t.Errorf("ValidateBranchName(%q) = nil, want an error", name)
+		}
+	}
+}
+
+func TestCheckoutBranch(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "inbox.md"), []byte("note\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add("inbox.md"); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit("note", &git.CommitOptions{Author: &object.Signature{Name: "alice", Email: "alice@example.com"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewRemoteReferenceName("origin", "master"), hash)); err != nil {
+		t.Fatal(err)
+	}
+	defaultBranches.Store(dir, "master")
+	t.Cleanup(func() { defaultBranches.Delete(dir) })
+
+	// A new branch starts at the default branch
+	m := &Manager{cfg: &gitconfig.Config{GitHubRepo: "https://github.com/alice/notes"}, repoPath: dir, repo: repo, branch: "notes"}
+	if switched, err := m.checkoutBranch(); err != nil || !switched {
+		t.Fatalf("checkoutBranch() = %v, %v, want a switch to notes", switched, err)
+	}
+	head, err := repo.Head()
+	if err != nil || head.Name().Short() != "notes" || head.Hash() != hash {
+		t.Fatalf("HEAD = %v, %v, want notes at the default branch", head, err)
+	}
+	if switched, err := m.checkoutBranch(); err != nil || switched {
+		t.Errorf("checkoutBranch() on notes = %v, %v, want no switch", switched, err)
+	}
+	if url, err := m.GetGitHubFileURLWithBranch("inbox.md"); err != nil || url != "https://github.com/alice/notes/blob/notes/inbox.md" {
+		t.Errorf("GetGitHubFileURLWithBranch() = %q, %v", url, err)
+	}
+
+	// Users without a branch get the default branch back
+	other := &Manager{cfg: &gitconfig.Config{}, repoPath: dir, repo: repo}
+	if switched, err := other.checkoutBranch(); err != nil || !switched {
+		t.Fatalf("checkoutBranch() = %v, %v, want a switch to master", switched, err)
+	}
+	if branch, _ := other.GetDefaultBranch(); branch != "master" {
+		t.Errorf("GetDefaultBranch() = %q, want master", branch)
+	}
+}
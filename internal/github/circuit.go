@@ -0,0 +1,108 @@
+package github
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// GitHub circuit: API requests report whether GitHub itself failed them (network errors and 5xx
+// responses, not per-user errors like bad tokens or missing repositories). After
+// circuitFailureThreshold consecutive failures the circuit opens; once circuitCooldown passed
+// without failures it is half open until the next request succeeds. The state is only reported
+// (see the bot's /status endpoint), requests are never rejected.
+
+// CircuitState is the health of GitHub as seen by the bot
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // GitHub requests succeed
+	CircuitOpen     CircuitState = "open"      // GitHub requests keep failing
+	CircuitHalfOpen CircuitState = "half_open" // Failures stopped, waiting for a success
+)
+
+const (
+	circuitFailureThreshold = 5
+	circuitCooldown         = time.Minute
+)
+
+// Circuit tracks consecutive GitHub failures
+type Circuit struct {
+	mu          sync.Mutex
+	failures    int
+	open        bool
+	lastFailure time.Time
+	lastOpened  time.Time
+	now         func() time.Time
+}
+
+// NewCircuit creates a closed circuit
+func NewCircuit() *Circuit {
+	return &Circuit{now: time.Now}
+}
+
+// Success records a request GitHub served, closing the circuit
+func (c *Circuit) Success() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = 0
+	c.open = false
+}
+
+// Failure records a request GitHub failed, opening the circuit after too many in a row
+func (c *Circuit) Failure() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.failures++
+	c.lastFailure = now
+	if !c.open && c.failures >= circuitFailureThreshold {
+		c.open = true
+		c.lastOpened = now
+	}
+}
+
+// State returns the current state of the circuit
+func (c *Circuit) State() CircuitState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case !c.open:
+		return CircuitClosed
+	case c.now().Sub(c.lastFailure) >= circuitCooldown:
+		return CircuitHalfOpen
+	default:
+		return CircuitOpen
+	}
+}
+
+// LastOpened returns when the circuit last opened, zero if it never did
+func (c *Circuit) LastOpened() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lastOpened
+}
+
+// circuit is the process-wide GitHub circuit
+var circuit = NewCircuit()
+
+// CircuitStatus returns the state of the process-wide GitHub circuit and when it last opened
+func CircuitStatus() (CircuitState, time.Time) {
+	return circuit.State(), circuit.LastOpened()
+}
+
+// recordGitHubResponse reports the outcome of an API request to baseURL to the process-wide
+// circuit. Requests to users' own GitHub Enterprise or Gitea instances say nothing about the
+// deployment's GitHub and aren't recorded.
+func recordGitHubResponse(baseURL string, resp *http.Response, err error) {
+	if baseURL != APIBaseURL() {
+		return
+	}
+	if err != nil || resp.StatusCode >= 500 {
+		circuit.Failure()
+		return
+	}
+	circuit.Success()
+}
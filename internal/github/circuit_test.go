@@ -0,0 +1,61 @@
+package github
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuit(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	c := NewCircuit()
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < circuitFailureThreshold-1; i++ {
+		c.Failure()
+	}
+	if state := c.State(); state != CircuitClosed {
+		t.Fatalf("State() after %d failures = %s, want closed", circuitFailureThreshold-1, state)
+	}
+
+	c.Failure()
+	if state := c.State(); state != CircuitOpen {
+		t.Fatalf("State() after threshold = %s, want open", state)
+	}
+	if !c.LastOpened().Equal(now) {
+		t.Errorf("LastOpened() = %v, want %v", c.LastOpened(), now)
+	}
+
+	now = now.Add(circuitCooldown)
+	if state := c.State(); state != CircuitHalfOpen {
+		t.Fatalf("State() after cooldown = %s, want half_open", state)
+	}
+
+	c.Success()
+	if state := c.State(); state != CircuitClosed {
+		t.Fatalf("State() after success = %s, want closed", state)
+	}
+	if c.LastOpened().IsZero() {
+		t.Error("LastOpened() was reset by a success")
+	}
+}
+
+func TestRecordGitHubResponseIgnoresUserEndpoints(t *testing.T) {
+	saved := circuit
+	circuit = NewCircuit()
+	defer func() { circuit = saved }()
+
+	for i := 0; i < circuitFailureThreshold; i++ {
+		recordGitHubResponse("https://git.example.com/api/v1", nil, errors.New("connection refused"))
+	}
+	if state := circuit.State(); state != CircuitClosed {
+		t.Fatalf("State() after failures of a user's instance = %s, want closed", state)
+	}
+
+	for i := 0; i < circuitFailureThreshold; i++ {
+		recordGitHubResponse(APIBaseURL(), nil, errors.New("connection refused"))
+	}
+	if state := circuit.State(); state != CircuitOpen {
+		t.Errorf("State() after failures of the deployment's GitHub = %s, want open", state)
+	}
+}
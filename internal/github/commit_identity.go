@@ -0,0 +1,37 @@
+package github
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Commit identity: users may have commits authored by them but committed by the bot identity, so
+// git history shows which commits msg2git made on their behalf (author ≠ committer)
+
+// splitIdentity parses "Name <email>", ok is false for other forms
+func splitIdentity(identity string) (name, email string, ok bool) {
+	parts := strings.Split(identity, " <")
+	if len(parts) != 2 || !strings.HasSuffix(parts[1], ">") {
+		return "", "", false
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ">"), true
+}
+
+// committerSignature returns the committer of a commit made at when, nil if the author commits
+func (m *Manager) committerSignature(when time.Time) *object.Signature {
+	name, email, ok := splitIdentity(m.committer)
+	if !ok {
+		return nil
+	}
+	return &object.Signature{Name: name, Email: email, When: when}
+}
+
+// apiCommitter returns the committer of an API commit by author
+func (p *APIBasedProvider) apiCommitter(author *apiCommitterInfo) *apiCommitterInfo {
+	if p.config.Committer == "" {
+		return author
+	}
+	return parseCommitAuthor(p.config.Committer)
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitIdentity(t *testing.T) {
+	tests := []struct {
+		identity    string
+		name, email string
+		ok          bool
+	}{
+		{"Jane Doe <jane@example.com>", "Jane Doe", "jane@example.com", true},
+		{"Msg2Git Bot <bot@msg2git.com>", "Msg2Git Bot", "bot@msg2git.com", true},
+		{"", "", "", false},
+		{"Jane Doe", "", "", false},
+		{"Jane <jane@example.com", "", "", false},
+	}
+
+	for _, tt := range tests {
+		name, email, ok := splitIdentity(tt.identity)
+		if name != tt.name || email != tt.email || ok != tt.ok {
+			t.Errorf("splitIdentity(%q) = %q, %q, %v, want %q, %q, %v", tt.identity, name, email, ok, tt.name, tt.email, tt.ok)
+		}
+	}
+}
+
+func TestCommitterSignature(t *testing.T) {
+	when := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+
+	m := &Manager{}
+	if sig := m.committerSignature(when); sig != nil {
+		t.Errorf("committerSignature() without committer = %+v, want nil so the author commits", sig)
+	}
+
+	m.committer = "Msg2Git Bot <bot@msg2git.com>"
+	sig := m.committerSignature(when)
+	if sig == nil || sig.Name != "Msg2Git Bot" || sig.Email != "bot@msg2git.com" || !sig.When.Equal(when) {
+		t.Errorf("committerSignature() = %+v, want the bot identity at %v", sig, when)
+	}
+}
+
+func TestAPICommitter(t *testing.T) {
+	author := parseCommitAuthor("Jane Doe <jane@example.com>")
+
+	p := &APIBasedProvider{config: &ProviderConfig{}}
+	if got := p.apiCommitter(author); got != author {
+		t.Errorf("apiCommitter() without committer = %+v, want the author", got)
+	}
+
+	p.config.Committer = "Msg2Git Bot <bot@msg2git.com>"
+	if got := p.apiCommitter(author); got.Name != "Msg2Git Bot" || got.Email != "bot@msg2git.com" {
+		t.Errorf("apiCommitter() = %+v, want the bot identity", got)
+	}
+}
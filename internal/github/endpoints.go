@@ -0,0 +1,158 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Default GitHub endpoints
+const (
+	DefaultAPIBaseURL     = "https://api.github.com"
+	DefaultUploadsBaseURL = "https://uploads.github.com"
+)
+
+// GitHub endpoints are package-level so tests (or GitHub Enterprise deployments) can redirect them
+var (
+	apiBaseURL     = DefaultAPIBaseURL
+	uploadsBaseURL = DefaultUploadsBaseURL
+	endpointsMu    sync.RWMutex
+)
+
+// SetAPIBaseURLs points REST, GraphQL and asset upload requests of the whole deployment at other
+// hosts, e.g. GitHub Enterprise Server or a fake server in tests. An empty uploads URL is derived
+// from the API URL, empty values for both restore the defaults.
+func SetAPIBaseURLs(api, uploads string) {
+	endpointsMu.Lock()
+	defer endpointsMu.Unlock()
+
+	apiBaseURL = DefaultAPIBaseURL
+	uploadsBaseURL = DefaultUploadsBaseURL
+	if api != "" {
+		apiBaseURL = strings.TrimSuffix(api, "/")
+		uploadsBaseURL = EnterpriseUploadsURL(apiBaseURL)
+	}
+	if uploads != "" {
+		uploadsBaseURL = strings.TrimSuffix(uploads, "/")
+	}
+}
+
+// APIBaseURL returns the base URL for GitHub REST and GraphQL requests
+func APIBaseURL() string {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	return apiBaseURL
+}
+
+// UploadsBaseURL returns the base URL for release asset uploads
+func UploadsBaseURL() string {
+	endpointsMu.RLock()
+	defer endpointsMu.RUnlock()
+	return uploadsBaseURL
+}
+
+// EnterpriseUploadsURL derives the asset upload URL of a GitHub Enterprise Server from its API URL
+// ("https://host/api/v3" -> "https://host/api/uploads"), other URLs are used as-is
+func EnterpriseUploadsURL(apiURL string) string {
+	apiURL = strings.TrimSuffix(apiURL, "/")
+	if strings.HasSuffix(apiURL, "/api/v3") {
+		return strings.TrimSuffix(apiURL, "/v3") + "/uploads"
+	}
+	return apiURL
+}
+
+// ResolveAPIBaseURL returns a user's own API URL (GitHub Enterprise) when set, the deployment's otherwise
+func ResolveAPIBaseURL(userAPIURL string) string {
+	api, _ := resolveEndpoints(userAPIURL, "")
+	return api
+}
+
+// resolveEndpoints returns the API and uploads URLs to use for a repository, preferring per-user values
+func resolveEndpoints(api, uploads string) (string, string) {
+	if api == "" {
+		if uploads == "" {
+			return APIBaseURL(), UploadsBaseURL()
+		}
+		return APIBaseURL(), strings.TrimSuffix(uploads, "/")
+	}
+
+	api = strings.TrimSuffix(api, "/")
+	if uploads == "" {
+		return api, EnterpriseUploadsURL(api)
+	}
+	return api, strings.TrimSuffix(uploads, "/")
+}
+
+// apiBaseURL returns the REST and GraphQL base URL for the manager's repository
+func (m *Manager) apiBaseURL() string {
+	api, _ := resolveEndpoints(m.cfg.GitHubAPIURL, m.cfg.GitHubUploadsURL)
+	return api
+}
+
+// uploadsBaseURL returns the release asset upload base URL for the manager's repository
+func (m *Manager) uploadsBaseURL() string {
+	_, uploads := resolveEndpoints(m.cfg.GitHubAPIURL, m.cfg.GitHubUploadsURL)
+	return uploads
+}
+
+// ValidateAPIURL checks that a GitHub Enterprise API URL is an absolute HTTPS URL
+func ValidateAPIURL(apiURL string) error {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("invalid URL, use e.g. https://github.example.com/api/v3")
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("the GitHub API URL must use https")
+	}
+	return nil
+}
+
+// WebHost returns the web host GitHub Enterprise Server serves repositories on for an API URL
+// ("https://host/api/v3" -> "host"), github.com when apiURL is empty or the default
+func WebHost(apiURL string) string {
+	u, err := url.Parse(apiURL)
+	if apiURL == "" || err != nil || u.Host == "" || strings.TrimSuffix(apiURL, "/") == DefaultAPIBaseURL {
+		return "github.com"
+	}
+	return u.Host
+}
+
+// repoWebURL returns the web URL of owner/repo on the host repoURL points at, github.com by default
+func repoWebURL(repoURL, owner, repo string) string {
+	host := "github.com"
+	repoURL = strings.TrimSpace(repoURL)
+	if strings.HasPrefix(repoURL, "git@") {
+		if h, _, ok := strings.Cut(strings.TrimPrefix(repoURL, "git@"), ":"); ok && h != "" {
+			host = h
+		}
+	} else if u, err := url.Parse(repoURL); err == nil && u.Scheme == "https" && u.Host != "" {
+		host = u.Host
+	}
+	return fmt.Sprintf("https://%s/%s/%s", host, owner, repo)
+}
+
+// parseOwnerRepo extracts owner and repository name from an HTTPS or SSH repository URL on any host
+func parseOwnerRepo(repoURL string) (owner, repo string, err error) {
+	repoURL = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(repoURL), "/"), ".git")
+
+	var path string
+	switch {
+	case strings.HasPrefix(repoURL, "git@"):
+		_, path, _ = strings.Cut(repoURL, ":")
+	case strings.HasPrefix(repoURL, "https://") || strings.HasPrefix(repoURL, "http://"):
+		u, parseErr := url.Parse(repoURL)
+		if parseErr != nil || u.Host == "" {
+			return "", "", fmt.Errorf("unsupported repository URL format: %s", repoURL)
+		}
+		path = strings.Trim(u.Path, "/")
+	default:
+		return "", "", fmt.Errorf("unsupported repository URL format: %s", repoURL)
+	}
+
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unsupported repository URL format: %s", repoURL)
+	}
+	return parts[0], parts[1], nil
+}
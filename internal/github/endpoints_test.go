@@ -0,0 +1,108 @@
+package github
+
+import (
+	"testing"
+
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/testutil"
+)
+
+func TestResolveEndpoints(t *testing.T) {
+	tests := []struct {
+		api, uploads         string
+		wantAPI, wantUploads string
+	}{
+		{"", "", DefaultAPIBaseURL, DefaultUploadsBaseURL},
+		{"https://ghe.example.com/api/v3/", "", "https://ghe.example.com/api/v3", "https://ghe.example.com/api/uploads"},
+		{"https://ghe.example.com/api/v3", "https://uploads.ghe.example.com", "https://ghe.example.com/api/v3", "https://uploads.ghe.example.com"},
+		{"http://127.0.0.1:8080", "", "http://127.0.0.1:8080", "http://127.0.0.1:8080"},
+	}
+
+	for _, tt := range tests {
+		api, uploads := resolveEndpoints(tt.api, tt.uploads)
+		if api != tt.wantAPI || uploads != tt.wantUploads {
+			t.Errorf("resolveEndpoints(%q, %q) = %q, %q, want %q, %q", tt.api, tt.uploads, api, uploads, tt.wantAPI, tt.wantUploads)
+		}
+	}
+}
+
+func TestParseOwnerRepoAndWebURL(t *testing.T) {
+	tests := []struct {
+		repoURL string
+		web     string
+	}{
+		{"https://ghe.example.com/alice/notes.git", "https://ghe.example.com/alice/notes"},
+		{"git@ghe.example.com:alice/notes.git", "https://ghe.example.com/alice/notes"},
+		{"https://github.com/alice/notes", "https://github.com/alice/notes"},
+	}
+
+	for _, tt := range tests {
+		owner, repo, err := parseOwnerRepo(tt.repoURL)
+		if err != nil || owner != "alice" || repo != "notes" {
+			t.Errorf("parseOwnerRepo(%q) = %q, %q, %v", tt.repoURL, owner, repo, err)
+		}
+		if web := repoWebURL(tt.repoURL, owner, repo); web != tt.web {
+			t.Errorf("repoWebURL(%q) = %q, want %q", tt.repoURL, web, tt.web)
+		}
+	}
+
+	for _, invalid := range []string{"notes", "https://ghe.example.com/alice", "ftp://ghe.example.com/alice/notes"} {
+		if _, _, err := parseOwnerRepo(invalid); err == nil {
+			t.Errorf("parseOwnerRepo(%q) expected error", invalid)
+		}
+	}
+}
+
+func TestWebHostAndValidateAPIURL(t *testing.T) {
+	if host := WebHost(""); host != "github.com" {
+		t.Errorf("WebHost(\"\") = %q", host)
+	}
+	if host := WebHost("https://ghe.example.com/api/v3"); host != "ghe.example.com" {
+		t.Errorf("WebHost(enterprise) = %q", host)
+	}
+
+	if err := ValidateAPIURL("https://ghe.example.com/api/v3"); err != nil {
+		t.Errorf("ValidateAPIURL() error = %v", err)
+	}
+	for _, invalid := range []string{"ghe.example.com", "http://ghe.example.com/api/v3"} {
+		if err := ValidateAPIURL(invalid); err == nil {
+			t.Errorf("ValidateAPIURL(%q) expected error", invalid)
+		}
+	}
+}
+
+// A per-user API URL is used instead of the deployment's, for both providers
+func TestProviders_PerUserAPIURL(t *testing.T) {
+	allowLocalEndpoints(t)
+	fake := testutil.NewFakeGitHub(t)
+	fake.AddRepo("alice", "notes")
+	fake.AddIssue("alice", "notes", "On enterprise", "open")
+
+	cfg := &gitconfig.Config{
+		GitHubToken:  "ghp_fake",
+		GitHubRepo:   "https://ghe.example.com/alice/notes",
+		CommitAuthor: "Test <test@example.com>",
+	}
+	providerConfig := NewProviderConfig(cfg, 0, "7")
+	providerConfig.APIBaseURL = fake.URL()
+
+	provider, err := NewAPIBasedProvider(providerConfig)
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+	status, err := provider.GetIssueStatus(1)
+	if err != nil || status.Title != "On enterprise" {
+		t.Fatalf("GetIssueStatus() = %+v, %v", status, err)
+	}
+	if url, err := provider.GetGitHubFileURL("note.md"); err != nil || url != "https://ghe.example.com/alice/notes/blob/main/note.md" {
+		t.Errorf("GetGitHubFileURL() = %q, %v", url, err)
+	}
+
+	manager, err := NewManager(&gitconfig.Config{GitHubToken: cfg.GitHubToken, GitHubRepo: cfg.GitHubRepo, GitHubAPIURL: fake.URL()}, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	if _, number, err := manager.CreateIssue("From manager", ""); err != nil || number != 2 {
+		t.Errorf("CreateIssue() = %d, %v", number, err)
+	}
+}
@@ -0,0 +1,67 @@
+package github
+
+import (
+	"bytes"
+	"io"
+	"strings"
+)
+
+// EntriesMarker marks where new entries go in files created from a template, so front matter and
+// headings above it stay at the top of the file. Prepending to a file without it puts entries first.
+const EntriesMarker = "<!-- msg2git:entries -->"
+
+// PrependEntry inserts entry at the top of existing, or right below its EntriesMarker line.
+// separator is put between the entry and the content following it.
+func PrependEntry(existing, entry, separator string) string {
+	head, rest := "", existing
+	if i := strings.Index(existing, EntriesMarker); i >= 0 {
+		at := i + len(EntriesMarker)
+		if at < len(existing) && existing[at] == '\n' {
+			at++
+		}
+		head, rest = existing[:at], existing[at:]
+	}
+
+	if rest == "" {
+		return head + entry
+	}
+	return head + entry + separator + rest
+}
+
+// entriesScanChunk is how much of a file entriesInsertOffset holds at a time
+const entriesScanChunk = 32 * 1024
+
+// entriesInsertOffset returns where PrependEntry inserts into the content of r: right below its
+// EntriesMarker line, or 0. r is scanned in chunks, so files are never loaded whole.
+func entriesInsertOffset(r io.Reader) (int64, error) {
+	marker := []byte(EntriesMarker)
+	buf := make([]byte, entriesScanChunk)
+	var start int64 // Offset of buf[0] in r
+	kept := 0       // Bytes carried over from the previous chunk, a marker may span both
+	for {
+		n, err := io.ReadFull(r, buf[kept:])
+		n += kept
+		if i := bytes.Index(buf[:n], marker); i >= 0 {
+			at := i + len(marker)
+			if at < n && buf[at] == '\n' {
+				at++
+			} else if at == n {
+				var next [1]byte
+				if read, _ := io.ReadFull(r, next[:]); read == 1 && next[0] == '\n' {
+					at++
+				}
+			}
+			return start + int64(at), nil
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		kept = len(marker) - 1
+		copy(buf, buf[n-kept:n])
+		start += int64(n - kept)
+	}
+}
@@ -0,0 +1,63 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPrependEntry(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{"empty file", "", "entry\n"},
+		{"plain file", "old\n", "entry\n\nold\n"},
+		{"template without entries", "---\ntitle: Log\n---\n\n" + EntriesMarker + "\n", "---\ntitle: Log\n---\n\n" + EntriesMarker + "\nentry\n"},
+		{"template with entries", "## Inbox\n" + EntriesMarker + "\nold\n", "## Inbox\n" + EntriesMarker + "\nentry\n\nold\n"},
+	}
+
+	for _, tt := range tests {
+		if got := PrependEntry(tt.existing, "entry\n", "\n"); got != tt.want {
+			t.Errorf("%s: PrependEntry() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEntriesInsertOffset(t *testing.T) {
+	// Markers spanning two chunks and ending one are found too
+	spanning := strings.Repeat("x", entriesScanChunk-5) + EntriesMarker + "\nold\n"
+	ending := strings.Repeat("x", entriesScanChunk-len(EntriesMarker)) + EntriesMarker + "\nold\n"
+
+	for _, existing := range []string{"", "old\n", "## Inbox\n" + EntriesMarker + "\nold\n", "## Inbox\n" + EntriesMarker, spanning, ending} {
+		at, err := entriesInsertOffset(strings.NewReader(existing))
+		if err != nil {
+			t.Fatalf("entriesInsertOffset() error = %v", err)
+		}
+		if got, want := existing[:at]+"entry\n"+existing[at:], PrependEntry(existing, "entry\n", ""); got != want {
+			t.Errorf("entriesInsertOffset() = %d, inserting there gives %q, want %q", at, got, want)
+		}
+	}
+}
+
+func TestManager_PrependToFile(t *testing.T) {
+	m := &Manager{repoPath: t.TempDir()}
+	filePath := filepath.Join(m.repoPath, "notes", "inbox.md")
+
+	if err := m.prependToFile("notes/inbox.md", "first\n"); err != nil {
+		t.Fatalf("prependToFile() error = %v", err)
+	}
+	if err := os.WriteFile(filePath, []byte("## Inbox\n"+EntriesMarker+"\nfirst\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.prependToFile("notes/inbox.md", "second\n"); err != nil {
+		t.Fatalf("prependToFile() error = %v", err)
+	}
+
+	content, _ := os.ReadFile(filePath)
+	if want := "## Inbox\n" + EntriesMarker + "\nsecond\nfirst\n"; string(content) != want {
+		t.Errorf("file = %q, want %q", content, want)
+	}
+}
@@ -31,10 +31,13 @@ func (f *DefaultProviderFactory) CreateProvider(providerType ProviderType, confi
 func NewCloneBasedProvider(config *ProviderConfig) (GitHubProvider, error) {
 	// Convert to existing config format
 	gitConfig := &gitconfig.Config{
-		GitHubUsername: config.Config.GetGitHubUsername(),
-		GitHubToken:    config.Config.GetGitHubToken(),
-		GitHubRepo:     config.Config.GetGitHubRepo(),
-		CommitAuthor:   config.Config.GetCommitAuthor(),
+		GitHubUsername:   config.Config.GetGitHubUsername(),
+		GitHubToken:      config.Config.GetGitHubToken(),
+		GitHubRepo:       config.Config.GetGitHubRepo(),
+		CommitAuthor:     config.Config.GetCommitAuthor(),
+		CloneSubmodules:  config.CloneSubmodules,
+		GitHubAPIURL:     config.APIBaseURL,
+		GitHubUploadsURL: config.UploadsBaseURL,
 	}
 	
 	manager, err := NewManager(gitConfig, config.PremiumLevel)
@@ -42,6 +45,13 @@ func NewCloneBasedProvider(config *ProviderConfig) (GitHubProvider, error) {
 		return nil, fmt.Errorf("failed to create clone-based provider: %w", err)
 	}
 	
+	manager.committer = config.Committer
+	manager.chatID = config.ChatID
+	manager.branch = config.Branch
+	manager.shallowClone = config.ShallowClone
+	manager.sparseCheckout = config.SparseCheckout
+	manager.writeQuota = config.WriteQuota
+
 	return &CloneBasedAdapter{
 		manager: manager,
 		config:  config,
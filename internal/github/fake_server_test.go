@@ -0,0 +1,360 @@
+package github
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/testutil"
+)
+
+// newFakeGitHub starts a fake GitHub with an empty owner/notes repository and points the package at it
+func newFakeGitHub(t *testing.T) (*testutil.FakeGitHub, *gitconfig.Config) {
+	t.Helper()
+
+	fake := testutil.NewFakeGitHub(t)
+	fake.Token = "ghp_fake"
+	fake.AddRepo("owner", "notes")
+
+	SetAPIBaseURLs(fake.URL(), fake.URL())
+	t.Cleanup(func() { SetAPIBaseURLs("", "") })
+
+	return fake, &gitconfig.Config{
+		GitHubToken:  "ghp_fake",
+		GitHubRepo:   "https://github.com/owner/notes",
+		CommitAuthor: "Test <test@example.com>",
+	}
+}
+
+func TestAPIProvider_FakeGitHubFiles(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetFile("owner", "notes", "note.md", "old entry")
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	if err := provider.CommitFileWithAuthor("note.md", "new entry", "Add note", cfg.CommitAuthor); err != nil {
+		t.Fatalf("CommitFileWithAuthor() error = %v", err)
+	}
+	if content, _ := fake.File("owner", "notes", "note.md"); content != "new entry\nold entry" {
+		t.Errorf("note.md = %q, want new entry prepended", content)
+	}
+
+	if err := provider.ReplaceFileWithAuthor("docs/todo.md", "- [ ] test", "Add todo", cfg.CommitAuthor); err != nil {
+		t.Fatalf("ReplaceFileWithAuthor() error = %v", err)
+	}
+	entries, err := provider.ListDirectory("")
+	if err != nil {
+		t.Fatalf("ListDirectory() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].Name != "docs" || entries[0].Type != "dir" || entries[1].Name != "note.md" {
+		t.Errorf("ListDirectory() = %+v", entries)
+	}
+
+	if err := provider.DeleteFile("note.md", "Remove note", cfg.CommitAuthor); err != nil {
+		t.Fatalf("DeleteFile() error = %v", err)
+	}
+	if _, exists := fake.File("owner", "notes", "note.md"); exists {
+		t.Error("Expected note.md to be deleted")
+	}
+
+	if commits := fake.Repo("owner", "notes").Commits; len(commits) != 3 {
+		t.Errorf("Expected 3 commits, got %d", len(commits))
+	}
+}
+
+func TestAPIProvider_FakeGitHubCommitBranch(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetFile("owner", "notes", "note.md", "old entry")
+
+	providerConfig := NewProviderConfig(cfg, 0, "42")
+	providerConfig.Branch = "notes"
+	provider, err := NewAPIBasedProvider(providerConfig)
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	// The missing branch is created before the first commit
+	if err := provider.CommitFileWithAuthor("note.md", "new entry", "Add note", cfg.CommitAuthor); err != nil {
+		t.Fatalf("CommitFileWithAuthor() error = %v", err)
+	}
+	if branches := fake.Repo("owner", "notes").Branches; len(branches) != 1 || branches[0] != "notes" {
+		t.Errorf("Branches = %v, want notes created", branches)
+	}
+
+	for _, req := range fake.Requests() {
+		switch {
+		case req.Method == "GET" && strings.Contains(req.Path, "/contents/") && req.Query != "ref=notes":
+			t.Errorf("GET %s?%s doesn't read the commit branch", req.Path, req.Query)
+		case req.Method == "PUT" && !strings.Contains(string(req.Body), `"branch":"notes"`):
+			t.Errorf("PUT %s doesn't commit to the commit branch: %s", req.Path, req.Body)
+		}
+	}
+
+	if url, err := provider.GetGitHubFileURLWithBranch("note.md"); err != nil || url != "https://github.com/owner/notes/blob/notes/note.md" {
+		t.Errorf("GetGitHubFileURLWithBranch() = %q, %v", url, err)
+	}
+
+	// Existing branches are left alone
+	if created, err := provider.(BranchCreator).EnsureBranch("notes"); err != nil || created {
+		t.Errorf("EnsureBranch(existing) = %v, %v", created, err)
+	}
+}
+
+func TestAPIProvider_FakeGitHubAtomicCommits(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetFile("owner", "notes", "note.md", "old entry")
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	files := map[string]string{"note.md": "replaced", "todo.md": "- [ ] test", "docs/idea.md": "idea"}
+	if err := provider.ReplaceMultipleFilesWithAuthorAndPremium(files, "Update files", cfg.CommitAuthor, 0); err != nil {
+		t.Fatalf("ReplaceMultipleFilesWithAuthorAndPremium() error = %v", err)
+	}
+	for path, want := range files {
+		if content, _ := fake.File("owner", "notes", path); content != want {
+			t.Errorf("%s = %q, want %q", path, content, want)
+		}
+	}
+
+	if err := provider.MoveFile("docs/idea.md", "ideas/idea.md", "Move idea", cfg.CommitAuthor); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+	if _, exists := fake.File("owner", "notes", "docs/idea.md"); exists {
+		t.Error("Expected docs/idea.md to be moved away")
+	}
+	if content, _ := fake.File("owner", "notes", "ideas/idea.md"); content != "idea" {
+		t.Errorf("ideas/idea.md = %q, want the moved content", content)
+	}
+
+	if commits := fake.Repo("owner", "notes").Commits; len(commits) != 2 {
+		t.Errorf("Expected one commit per operation, got %d", len(commits))
+	}
+	for _, req := range fake.Requests() {
+		if req.Method == "PUT" || req.Method == "DELETE" {
+			t.Errorf("%s %s went through the Contents API", req.Method, req.Path)
+		}
+	}
+
+	history, err := provider.(HistoryReader).CommitHistory(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("CommitHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[0].Hash != fake.Repo("owner", "notes").Commits[1].SHA {
+		t.Errorf("CommitHistory() = %+v, want both commits, newest first", history)
+	}
+	if history, err := provider.(HistoryReader).CommitHistory(time.Now().Add(time.Hour)); err != nil || len(history) != 0 {
+		t.Errorf("CommitHistory(future) = %+v, %v, want no commits", history, err)
+	}
+}
+
+func TestAPIProvider_FakeGitHubEmptyRepository(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	// The Git Data API can't make the first commit, the files are committed one by one
+	files := map[string]string{"note.md": "first", "todo.md": "- [ ] test"}
+	if err := provider.ReplaceMultipleFilesWithAuthorAndPremium(files, "Add files", cfg.CommitAuthor, 0); err != nil {
+		t.Fatalf("ReplaceMultipleFilesWithAuthorAndPremium() error = %v", err)
+	}
+	if commits := fake.Repo("owner", "notes").Commits; len(commits) != 2 {
+		t.Errorf("Expected a commit per file, got %d", len(commits))
+	}
+}
+
+func TestAPIProvider_FakeGitHubBinaryAndLargeFiles(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.LargeFileSize = 64
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	data := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	if err := provider.CommitBinaryFile("image.png", data, "Add image"); err != nil {
+		t.Fatalf("CommitBinaryFile() error = %v", err)
+	}
+	if content, _ := fake.File("owner", "notes", "image.png"); content != string(data) {
+		t.Errorf("image.png = %q, want the raw bytes", content)
+	}
+
+	large := strings.Repeat("## entry\n", 20)
+	fake.SetFile("owner", "notes", "large.md", large)
+	content, err := provider.ReadFile("large.md")
+	if err != nil {
+		t.Fatalf("ReadFile(large) error = %v", err)
+	}
+	if content != large {
+		t.Errorf("ReadFile(large) = %q, want the content of its blob", content)
+	}
+}
+
+func TestAPIProvider_FakeGitHubIssues(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.AddIssue("owner", "notes", "Existing", "closed")
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	url, number, err := provider.CreateIssue("Buy milk", "from the bot")
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if number != 2 || !strings.HasSuffix(url, "/owner/notes/issues/2") {
+		t.Errorf("CreateIssue() = %q, %d", url, number)
+	}
+
+	if _, err := provider.AddIssueComment(number, "done soon"); err != nil {
+		t.Fatalf("AddIssueComment() error = %v", err)
+	}
+	if err := provider.CloseIssue(number); err != nil {
+		t.Fatalf("CloseIssue() error = %v", err)
+	}
+	if issue := fake.Issue("owner", "notes", number); issue.State != "closed" || len(issue.Comments) != 1 {
+		t.Errorf("Issue after comment and close = %+v", issue)
+	}
+
+	statuses, err := provider.(*APIBasedProvider).SyncIssueStatusesGraphQL([]int{1, 2, 3})
+	if err != nil {
+		t.Fatalf("SyncIssueStatusesGraphQL() error = %v", err)
+	}
+	if len(statuses) != 2 || statuses[1].State != "closed" || statuses[2].Title != "Buy milk" {
+		t.Errorf("SyncIssueStatusesGraphQL() = %+v", statuses)
+	}
+}
+
+func TestAPIProvider_FakeGitHubAssets(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	url, err := provider.UploadImageToCDN("photo.jpg", []byte("jpeg"))
+	if err != nil {
+		t.Fatalf("UploadImageToCDN() error = %v", err)
+	}
+	if !strings.HasSuffix(url, "/photo.jpg") {
+		t.Errorf("UploadImageToCDN() = %q", url)
+	}
+
+	releases := fake.Repo("owner", "notes").Releases
+	if len(releases) != 1 || len(releases[0].Assets) != 1 || string(releases[0].Assets[0].Data) != "jpeg" {
+		t.Errorf("Expected one release with the uploaded asset, got %+v", releases)
+	}
+}
+
+func TestAPIProvider_FakeGitHubBadCredentials(t *testing.T) {
+	_, cfg := newFakeGitHub(t)
+	cfg.GitHubToken = "ghp_wrong"
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	if _, _, err := provider.CreateIssue("title", "body"); err == nil {
+		t.Error("Expected CreateIssue() to fail with a wrong token")
+	}
+}
+
+func TestManager_FakeGitHubIssues(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.AddIssue("owner", "notes", "Closed one", "closed")
+
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	_, number, err := manager.CreateIssue("From manager", "body")
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if number != 2 {
+		t.Errorf("CreateIssue() number = %d, want 2", number)
+	}
+
+	statuses, err := manager.SyncIssueStatuses([]int{1, 2})
+	if err != nil {
+		t.Fatalf("SyncIssueStatuses() error = %v", err)
+	}
+	if len(statuses) != 2 || statuses[1].State != "CLOSED" || statuses[2].State != "OPEN" {
+		t.Errorf("SyncIssueStatuses() = %+v", statuses)
+	}
+}
+
+func TestIssueLabels_FakeGitHub(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.Repo("owner", "notes").Labels = []string{"bug", "idea"}
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	for _, issues := range []IssueManager{provider, manager} {
+		labels, err := issues.ListLabels()
+		if err != nil {
+			t.Fatalf("ListLabels() error = %v", err)
+		}
+		if len(labels) != 2 || labels[0] != "bug" || labels[1] != "idea" {
+			t.Errorf("ListLabels() = %v", labels)
+		}
+
+		_, number, err := issues.CreateIssueWithLabels("Crash", "body", []string{"bug"})
+		if err != nil {
+			t.Fatalf("CreateIssueWithLabels() error = %v", err)
+		}
+		if issue := fake.Issue("owner", "notes", number); issue == nil || len(issue.Labels) != 1 || issue.Labels[0] != "bug" {
+			t.Errorf("Issue #%d = %+v, want labeled bug", number, issue)
+		}
+	}
+}
+
+func TestListIssuesByLabel_FakeGitHub(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	for i := 0; i < 150; i++ {
+		issue := fake.AddIssue("owner", "notes", "Issue", "open")
+		if i%2 == 0 {
+			issue.Labels = []string{"msg2git"}
+		}
+	}
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	for _, issues := range []IssueManager{provider, manager} {
+		labeled, err := issues.ListIssuesByLabel("msg2git")
+		if err != nil {
+			t.Fatalf("ListIssuesByLabel() error = %v", err)
+		}
+		if len(labeled) != 75 || labeled[0].Number != 149 || labeled[74].Number != 1 {
+			t.Errorf("ListIssuesByLabel() returned %d issues", len(labeled))
+		}
+	}
+}
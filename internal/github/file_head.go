@@ -0,0 +1,164 @@
+package github
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Capped reads: note files only grow, and features showing a file don't need all of a multi-MB
+// one. ReadFileHead reads at most maxBytes from the start of a file, where the newest notes are,
+// from the local clone or as raw content streamed from the API, so memory stays bounded however
+// large the file and however many users read at once.
+
+// HeadReader is implemented by providers that can read the start of a file without loading all of it
+type HeadReader interface {
+	// ReadFileHead returns up to maxBytes from the start of filename, cut at a line break when
+	// possible, and whether the file is longer. A missing file reads as empty.
+	ReadFileHead(filename string, maxBytes int) (string, bool, error)
+}
+
+// ReadFileHead reads the start of filename through provider, falling back to reading the whole
+// file for providers that can't read part of it
+func ReadFileHead(provider FileManager, filename string, maxBytes int) (string, bool, error) {
+	if reader, ok := provider.(HeadReader); ok {
+		return reader.ReadFileHead(filename, maxBytes)
+	}
+
+	content, err := provider.ReadFile(filename)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if len(content) <= maxBytes {
+		return content, false, nil
+	}
+	return cutHead(content[:maxBytes+1], maxBytes), true, nil
+}
+
+// readHead reads up to maxBytes of r, reading one byte more to tell whether r is longer
+func readHead(r io.Reader, maxBytes int) (string, bool, error) {
+	data, err := io.ReadAll(io.LimitReader(r, int64(maxBytes)+1))
+	if err != nil {
+		return "", false, err
+	}
+	if len(data) <= maxBytes {
+		return string(data), false, nil
+	}
+	return cutHead(string(data), maxBytes), true, nil
+}
+
+// cutHead cuts content to at most maxBytes, after the last line break if there is one in the
+// second half, otherwise at a rune boundary
+func cutHead(content string, maxBytes int) string {
+	if len(content) <= maxBytes {
+		return content
+	}
+	head := content[:maxBytes]
+	if i := strings.LastIndexByte(head, '\n'); i >= maxBytes/2 {
+		return head[:i+1]
+	}
+	for len(head) > 0 && !utf8.RuneStart(content[len(head)]) {
+		head = head[:len(head)-1]
+	}
+	return head
+}
+
+// ReadFileHead reads the start of a file of the local clone
+func (m *Manager) ReadFileHead(filename string, maxBytes int) (string, bool, error) {
+	if err := m.ensureRepositoryReadOnly(); err != nil {
+		return "", false, fmt.Errorf("failed to ensure repository: %w", err)
+	}
+	if err := m.syncWorktree(); err != nil {
+		logger.Warn("Failed to pull latest changes before reading file", map[string]interface{}{
+			"error":    err.Error(),
+			"filename": filename,
+		})
+	}
+
+	unlock, err := m.lockWorktree(false)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
+	file, err := m.files().Open(filename)
+	if os.IsNotExist(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to open file %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	content, truncated, err := readHead(file, maxBytes)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file %s: %w", filename, err)
+	}
+	return content, truncated, nil
+}
+
+func (a *CloneBasedAdapter) ReadFileHead(filename string, maxBytes int) (string, bool, error) {
+	return a.manager.ReadFileHead(filename, maxBytes)
+}
+
+// ReadFileHead streams the raw content of a file and stops reading after maxBytes. GitHub serves
+// it from the contents endpoint, Gitea from its raw endpoint.
+func (p *APIBasedProvider) ReadFileHead(filename string, maxBytes int) (string, bool, error) {
+	if err := p.checkRateLimit(); err != nil {
+		return "", false, err
+	}
+
+	endpoint := p.contentsEndpoint(filename)
+	if p.gitea {
+		endpoint = fmt.Sprintf("/repos/%s/%s/raw/%s", p.repoOwner, p.repoName, filename)
+		if p.config.Branch != "" {
+			endpoint += "?ref=" + url.QueryEscape(p.config.Branch)
+		}
+	}
+
+	req, err := http.NewRequest("GET", p.baseURL+endpoint, nil)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.Config.GetGitHubToken())
+	req.Header.Set("Accept", "application/vnd.github.raw")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	resp, err := p.httpClient.Do(req)
+	recordGitHubResponse(p.baseURL, resp, err)
+	if err != nil {
+		return "", false, fmt.Errorf("API request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	p.requestCount++
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// Like ReadFile, a missing file reads as empty
+		return "", false, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", false, fmt.Errorf("failed to read file: GitHub API error %d: %s", resp.StatusCode, string(body))
+	}
+
+	content, truncated, err := readHead(resp.Body, maxBytes)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read file: %w", err)
+	}
+	return content, truncated, nil
+}
+
+// ReadFileHead reads the start of a file of the wrapped provider
+func (p *SandboxProvider) ReadFileHead(filename string, maxBytes int) (string, bool, error) {
+	return ReadFileHead(p.GitHubProvider, filename, maxBytes)
+}
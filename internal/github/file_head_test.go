@@ -0,0 +1,48 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCutHead(t *testing.T) {
+	tests := []struct {
+		content  string
+		maxBytes int
+		want     string
+	}{
+		{"short", 10, "short"},
+		{"line one\nline two\n", 12, "line one\n"},
+		{"no line breaks here", 8, "no line "},
+		{"a\nbcdefghij", 8, "a\nbcdefg"}, // The only line break is too early to cut at
+		{"héllo", 2, "h"},                // é is two bytes
+	}
+	for _, tt := range tests {
+		if got := cutHead(tt.content, tt.maxBytes); got != tt.want {
+			t.Errorf("cutHead(%q, %d) = %q, want %q", tt.content, tt.maxBytes, got, tt.want)
+		}
+	}
+}
+
+func TestAPIProvider_ReadFileHead(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetFile("owner", "notes", "note.md", "newest\n"+strings.Repeat("older note\n", 100))
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	content, truncated, err := ReadFileHead(provider, "note.md", 40)
+	if err != nil {
+		t.Fatalf("ReadFileHead() error = %v", err)
+	}
+	if !truncated || content != "newest\nolder note\nolder note\nolder note\n" {
+		t.Errorf("ReadFileHead() = %q, %v", content, truncated)
+	}
+
+	content, truncated, err = ReadFileHead(provider, "missing.md", 40)
+	if err != nil || truncated || content != "" {
+		t.Errorf("ReadFileHead(missing) = %q, %v, %v, want empty", content, truncated, err)
+	}
+}
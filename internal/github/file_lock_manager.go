@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -84,7 +85,34 @@ func (flm *FileLockManager) generateLockKey(userID int64, repoURL, filename stri
 // AcquireFileLock acquires a lock for a specific file with timeout
 func (flm *FileLockManager) AcquireFileLock(ctx context.Context, userID int64, repoURL, filename string, exclusive bool) (*FileLockHandle, error) {
 	lockKey := flm.generateLockKey(userID, repoURL, filename)
+	handle, err := flm.acquireLock(ctx, userID, lockKey, exclusive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire file lock for %s: %w", filename, err)
+	}
+	return handle, nil
+}
+
+// generateRepoLockKey creates the key of the repository level lock
+// Format: owner/repo (e.g., "msg2git/mynote"), which never collides with file keys
+func (flm *FileLockManager) generateRepoLockKey(userID int64, repoURL string) string {
+	return strings.TrimSuffix(flm.generateLockKey(userID, repoURL, ""), ":")
+}
 
+// AcquireRepoLock acquires the repository level lock, which sits above the file locks: steps that
+// change the working copy as a whole (pull, hard reset, checkout, commit and push) hold it
+// exclusively, steps only reading files hold it shared. Always acquire the file locks an operation
+// needs first and the repository lock last, so operations can't deadlock.
+func (flm *FileLockManager) AcquireRepoLock(ctx context.Context, userID int64, repoURL string, exclusive bool) (*FileLockHandle, error) {
+	lockKey := flm.generateRepoLockKey(userID, repoURL)
+	handle, err := flm.acquireLock(ctx, userID, lockKey, exclusive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire repository lock for %s: %w", lockKey, err)
+	}
+	return handle, nil
+}
+
+// acquireLock acquires the lock of lockKey, giving up when ctx is done
+func (flm *FileLockManager) acquireLock(ctx context.Context, userID int64, lockKey string, exclusive bool) (*FileLockHandle, error) {
 	// Get or create the file lock
 	lock := flm.getOrCreateLock(lockKey)
 
@@ -145,7 +173,7 @@ func (flm *FileLockManager) AcquireFileLock(ctx context.Context, userID int64, r
 	case <-ctx.Done():
 		// Timeout or cancellation
 		flm.decrementRefCount(lockKey)
-		return nil, fmt.Errorf("failed to acquire file lock for %s: %w", filename, ctx.Err())
+		return nil, ctx.Err()
 	}
 }
 
@@ -432,3 +460,17 @@ func (flm *FileLockManager) WithFileLock(ctx context.Context, userID int64, repo
 	return fn()
 }
 
+// WithRepoLock is like WithFileLock for the repository level lock
+func (flm *FileLockManager) WithRepoLock(ctx context.Context, userID int64, repoURL string, exclusive bool, fn func() error) error {
+	lockCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	handle, err := flm.AcquireRepoLock(lockCtx, userID, repoURL, exclusive)
+	if err != nil {
+		return err
+	}
+	defer handle.Release()
+
+	return fn()
+}
+
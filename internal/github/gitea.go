@@ -0,0 +1,338 @@
+package github
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Gitea (and Forgejo) serve a REST API at https://host/api/v1 that mirrors GitHub's for contents,
+// issues, labels, releases and commit statuses. The API provider talks to it when the user's API
+// URL is a Gitea one, and only differs where Gitea does: files are created with POST, issues take
+// label IDs, branches and attachments have their own endpoints, lists page with "limit" and there
+// is neither GraphQL nor code search.
+
+const (
+	giteaAPIPath     = "/api/v1"
+	giteaMaxPageSize = 50 // Gitea's default MAX_RESPONSE_ITEMS
+	giteaLabelColor  = "#ededed"
+)
+
+type giteaCreateBranchRequest struct {
+	NewBranchName string `json:"new_branch_name"`
+	OldBranchName string `json:"old_branch_name"`
+}
+
+// giteaChangeFilesRequest creates, updates and deletes files in one commit (Gitea 1.20 and later)
+type giteaChangeFilesRequest struct {
+	Files     []giteaFileChange `json:"files"`
+	Message   string            `json:"message"`
+	Branch    string            `json:"branch"`
+	Author    *apiCommitterInfo `json:"author,omitempty"`
+	Committer *apiCommitterInfo `json:"committer,omitempty"`
+}
+
+type giteaFileChange struct {
+	Operation string `json:"operation"` // create, update or delete
+	Path      string `json:"path"`
+	Content   string `json:"content,omitempty"`
+	SHA       string `json:"sha,omitempty"`
+}
+
+type giteaLabel struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type giteaIssueRequest struct {
+	Title  string  `json:"title"`
+	Body   string  `json:"body"`
+	Labels []int64 `json:"labels,omitempty"`
+}
+
+type giteaComment struct {
+	User *struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	Body      string    `json:"body"`
+	HTMLURL   string    `json:"html_url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IsGiteaAPIURL reports whether apiURL is the API of a Gitea or Forgejo instance ("https://host/api/v1")
+func IsGiteaAPIURL(apiURL string) bool {
+	return strings.HasSuffix(strings.TrimSuffix(apiURL, "/"), giteaAPIPath)
+}
+
+// GiteaAPIURL returns the API URL of the Gitea instance at instanceURL, which may be given with or
+// without the API path ("https://git.example.com" -> "https://git.example.com/api/v1")
+func GiteaAPIURL(instanceURL string) string {
+	instanceURL = strings.TrimSuffix(strings.TrimSpace(instanceURL), "/")
+	if IsGiteaAPIURL(instanceURL) {
+		return instanceURL
+	}
+	return instanceURL + giteaAPIPath
+}
+
+// contentsWriteMethod returns the method creating or updating a file: Gitea creates files with POST
+// and only updates them with PUT, GitHub does both with PUT
+func (p *APIBasedProvider) contentsWriteMethod(exists bool) string {
+	if p.gitea && !exists {
+		return "POST"
+	}
+	return "PUT"
+}
+
+// pageQuery returns the query parameter asking for pages of size results and the size the server
+// will actually use, lower on Gitea
+func (p *APIBasedProvider) pageQuery(size int) (string, int) {
+	if p.gitea {
+		if size > giteaMaxPageSize {
+			size = giteaMaxPageSize
+		}
+		return fmt.Sprintf("limit=%d", size), size
+	}
+	return fmt.Sprintf("per_page=%d", size), size
+}
+
+// createGiteaBranch creates the branch from the head of another one
+func (p *APIBasedProvider) createGiteaBranch(name, from string) error {
+	endpoint := fmt.Sprintf("/repos/%s/%s/branches", p.repoOwner, p.repoName)
+	resp, err := p.makeAPIRequest("POST", endpoint, giteaCreateBranchRequest{
+		NewBranchName: name,
+		OldBranchName: from,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create branch: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// changeGiteaFiles commits the files in one commit on branch, a nil content deletes the file
+func (p *APIBasedProvider) changeGiteaFiles(branch string, files map[string][]byte, commitMessage string, author *apiCommitterInfo) (*CommitResult, error) {
+	request := giteaChangeFilesRequest{
+		Message:   commitMessage,
+		Branch:    branch,
+		Author:    author,
+		Committer: p.apiCommitter(author),
+	}
+	for _, path := range sortedPaths(files) {
+		change := giteaFileChange{Operation: "create", Path: path}
+		if p.fileExists(path) {
+			sha, err := p.getFileSHA(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get file SHA: %w", err)
+			}
+			change.Operation, change.SHA = "update", sha
+		}
+		if files[path] == nil {
+			if change.Operation == "create" {
+				continue // Already gone
+			}
+			change.Operation = "delete"
+		} else {
+			change.Content = base64.StdEncoding.EncodeToString(files[path])
+		}
+		request.Files = append(request.Files, change)
+	}
+
+	endpoint := fmt.Sprintf("/repos/%s/%s/contents", p.repoOwner, p.repoName)
+	var response apiFileUpdateResponse
+	if err := p.postJSON(endpoint, request, &response); err != nil {
+		return nil, fmt.Errorf("failed to change files: %w", err)
+	}
+	return &CommitResult{SHA: response.Commit.SHA, URL: response.Commit.HTMLURL}, nil
+}
+
+// giteaLabelIDs returns the IDs of the named labels, creating the missing ones the way GitHub does
+func (p *APIBasedProvider) giteaLabelIDs(names []string) ([]int64, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	endpoint := fmt.Sprintf("/repos/%s/%s/labels?limit=%d", p.repoOwner, p.repoName, giteaMaxPageSize)
+	resp, err := p.makeAPIRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	var labels []giteaLabel
+	err = json.NewDecoder(resp.Body).Decode(&labels)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+
+	existing := make(map[string]int64, len(labels))
+	for _, label := range labels {
+		existing[strings.ToLower(label.Name)] = label.ID
+	}
+
+	ids := make([]int64, 0, len(names))
+	for _, name := range names {
+		if id, ok := existing[strings.ToLower(name)]; ok {
+			ids = append(ids, id)
+			continue
+		}
+
+		endpoint := fmt.Sprintf("/repos/%s/%s/labels", p.repoOwner, p.repoName)
+		resp, err := p.makeAPIRequest("POST", endpoint, map[string]string{"name": name, "color": giteaLabelColor})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create label %s: %w", name, err)
+		}
+		var label giteaLabel
+		err = json.NewDecoder(resp.Body).Decode(&label)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode label: %w", err)
+		}
+		existing[strings.ToLower(name)] = label.ID
+		ids = append(ids, label.ID)
+	}
+	return ids, nil
+}
+
+// giteaIssueRequest builds the issue creation body, Gitea takes label IDs instead of names
+func (p *APIBasedProvider) giteaIssueRequest(title, body string, labels []string) (*giteaIssueRequest, error) {
+	ids, err := p.giteaLabelIDs(labels)
+	if err != nil {
+		return nil, err
+	}
+	return &giteaIssueRequest{Title: title, Body: body, Labels: ids}, nil
+}
+
+// assignGiteaIssue sets the assignees of an issue, Gitea has no endpoint adding them
+func (p *APIBasedProvider) assignGiteaIssue(issueNumber int, assignees []string) error {
+	endpoint := fmt.Sprintf("/repos/%s/%s/issues/%d", p.repoOwner, p.repoName, issueNumber)
+
+	resp, err := p.makeAPIRequest("PATCH", endpoint, map[string]interface{}{"assignees": assignees})
+	if err != nil {
+		return fmt.Errorf("failed to assign issue: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// getGiteaIssueComments pages an issue thread over REST as Gitea has no GraphQL. The cursor is
+// the index of the oldest comment already shown.
+func (p *APIBasedProvider) getGiteaIssueComments(issueNumber, limit int, before string) (*IssueCommentPage, error) {
+	issue, err := p.GetIssueStatus(issueNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("/repos/%s/%s/issues/%d/comments", p.repoOwner, p.repoName, issueNumber)
+	resp, err := p.makeAPIRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue comments: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var comments []giteaComment
+	if err := json.NewDecoder(resp.Body).Decode(&comments); err != nil {
+		return nil, fmt.Errorf("failed to decode issue comments: %w", err)
+	}
+	sort.SliceStable(comments, func(i, j int) bool {
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+
+	end := len(comments)
+	if before != "" {
+		index, err := strconv.Atoi(before)
+		if err != nil || index < 0 || index > len(comments) {
+			return nil, fmt.Errorf("invalid comment cursor %q", before)
+		}
+		end = index
+	}
+	start := end - limit
+	if start < 0 {
+		start = 0
+	}
+
+	page := &IssueCommentPage{
+		Number:     issue.Number,
+		Title:      issue.Title,
+		State:      strings.ToLower(issue.State),
+		URL:        issue.HTMLURL,
+		TotalCount: len(comments),
+	}
+	if start > 0 {
+		page.OlderCursor = strconv.Itoa(start)
+	}
+	for _, comment := range comments[start:end] {
+		author := "ghost"
+		if comment.User != nil {
+			author = comment.User.Login
+		}
+		page.Comments = append(page.Comments, IssueComment{
+			Author:    author,
+			Body:      comment.Body,
+			URL:       comment.HTMLURL,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+
+	return page, nil
+}
+
+// uploadGiteaAttachment uploads a file as a release attachment, which Gitea takes as a form upload
+// on the API host
+func (p *APIBasedProvider) uploadGiteaAttachment(releaseID int, filename string, data []byte) (string, error) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	part, err := writer.CreateFormFile("attachment", filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload form: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return "", fmt.Errorf("failed to write upload form: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to close upload form: %w", err)
+	}
+
+	uploadURL := fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets?name=%s",
+		p.baseURL, p.repoOwner, p.repoName, releaseID, url.QueryEscape(filename))
+	req, err := http.NewRequest("POST", uploadURL, &form)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+p.config.Config.GetGitHubToken())
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var asset apiAssetResponse
+	if err := json.NewDecoder(resp.Body).Decode(&asset); err != nil {
+		return "", fmt.Errorf("failed to decode upload response: %w", err)
+	}
+
+	logger.Debug("Attachment uploaded to Gitea release", map[string]interface{}{
+		"release_id": releaseID,
+		"filename":   filename,
+		"user_id":    p.config.UserID,
+	})
+
+	return asset.BrowserDownloadURL, nil
+}
@@ -0,0 +1,309 @@
+package github
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+)
+
+// fakeGitea serves the parts of Gitea's API that differ from GitHub's
+type fakeGitea struct {
+	mu       sync.Mutex
+	files    map[string]string
+	labels   map[string]int64
+	requests []string
+	issue    map[string]interface{}
+	assigned interface{}
+}
+
+func newFakeGitea(t *testing.T) (*fakeGitea, *APIBasedProvider) {
+	t.Helper()
+	allowLocalEndpoints(t)
+	fake := &fakeGitea{
+		files:  map[string]string{},
+		labels: map[string]int64{"todo": 7},
+	}
+	server := httptest.NewServer(http.HandlerFunc(fake.serve))
+	t.Cleanup(server.Close)
+
+	config := NewProviderConfig(&gitconfig.Config{
+		GitHubToken: "gitea_token",
+		GitHubRepo:  "owner/notes",
+	}, 0, "42")
+	config.APIBaseURL = server.URL + "/api/v1"
+
+	provider, err := newAPIProvider(config)
+	if err != nil {
+		t.Fatalf("newAPIProvider() error = %v", err)
+	}
+	return fake, provider
+}
+
+func (f *fakeGitea) serve(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/repos/owner/notes")
+	f.requests = append(f.requests, r.Method+" "+path)
+	w.Header().Set("Content-Type", "application/json")
+
+	var body map[string]interface{}
+	if r.Body != nil && strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case path == "" && r.Method == "GET":
+		json.NewEncoder(w).Encode(map[string]interface{}{"default_branch": "main", "size": 1})
+	case strings.HasPrefix(path, "/contents/"):
+		name := strings.TrimPrefix(path, "/contents/")
+		content, exists := f.files[name]
+		switch r.Method {
+		case "GET":
+			if !exists {
+				http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"name": name, "path": name, "sha": "sha-" + name, "type": "file",
+				"encoding": "base64", "content": base64.StdEncoding.EncodeToString([]byte(content)),
+			})
+		case "POST", "PUT":
+			// Gitea refuses to create files with PUT and to overwrite them with POST
+			if exists != (r.Method == "PUT") {
+				http.Error(w, `{"message":"wrong method"}`, http.StatusUnprocessableEntity)
+				return
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(body["content"].(string))
+			f.files[name] = string(decoded)
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"content": map[string]interface{}{"size": len(decoded)},
+				"commit":  map[string]interface{}{"sha": "c0ffee", "html_url": "https://git.example.com/owner/notes/commit/c0ffee"},
+			})
+		}
+	case path == "/contents" && r.Method == "POST":
+		for _, change := range body["files"].([]interface{}) {
+			change := change.(map[string]interface{})
+			name := change["path"].(string)
+			if _, exists := f.files[name]; exists != (change["operation"] != "create") {
+				http.Error(w, `{"message":"wrong operation"}`, http.StatusUnprocessableEntity)
+				return
+			}
+			if change["operation"] == "delete" {
+				delete(f.files, name)
+				continue
+			}
+			decoded, _ := base64.StdEncoding.DecodeString(change["content"].(string))
+			f.files[name] = string(decoded)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"commit": map[string]interface{}{"sha": "c0ffee", "html_url": "https://git.example.com/owner/notes/commit/c0ffee"},
+		})
+	case path == "/labels" && r.Method == "GET":
+		labels := []map[string]interface{}{}
+		for name, id := range f.labels {
+			labels = append(labels, map[string]interface{}{"id": id, "name": name})
+		}
+		json.NewEncoder(w).Encode(labels)
+	case path == "/labels" && r.Method == "POST":
+		id := int64(100 + len(f.labels))
+		f.labels[body["name"].(string)] = id
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "name": body["name"]})
+	case path == "/issues" && r.Method == "POST":
+		f.issue = body
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 1, "title": body["title"], "state": "open", "html_url": "https://git.example.com/owner/notes/issues/1"})
+	case path == "/issues/1" && r.Method == "PATCH":
+		f.assigned = body["assignees"]
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 1})
+	case path == "/issues/1" && r.Method == "GET":
+		json.NewEncoder(w).Encode(map[string]interface{}{"number": 1, "title": "Idea", "state": "open", "html_url": "https://git.example.com/owner/notes/issues/1"})
+	case path == "/issues/1/comments" && r.Method == "GET":
+		var comments []map[string]interface{}
+		start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		for i := 4; i >= 0; i-- {
+			comments = append(comments, map[string]interface{}{
+				"user":       map[string]string{"login": "alice"},
+				"body":       fmt.Sprintf("comment %d", i),
+				"created_at": start.Add(time.Duration(i) * time.Hour),
+			})
+		}
+		json.NewEncoder(w).Encode(comments)
+	case path == "/branches/notes" && r.Method == "GET":
+		if _, exists := f.files[".branch"]; !exists {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "notes", "commit": map[string]string{"id": "abc"}})
+	case path == "/branches/main" && r.Method == "GET":
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "main", "commit": map[string]string{"id": "abc"}})
+	case path == "/branches" && r.Method == "POST":
+		if body["new_branch_name"] != "notes" || body["old_branch_name"] != "main" {
+			http.Error(w, `{"message":"bad branch"}`, http.StatusUnprocessableEntity)
+			return
+		}
+		f.files[".branch"] = ""
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "notes"})
+	default:
+		http.Error(w, `{"message":"unexpected request"}`, http.StatusNotFound)
+	}
+}
+
+func TestGiteaAPIURL(t *testing.T) {
+	for input, want := range map[string]string{
+		"https://git.example.com":         "https://git.example.com/api/v1",
+		"https://git.example.com/":        "https://git.example.com/api/v1",
+		"https://git.example.com/api/v1/": "https://git.example.com/api/v1",
+		" https://git.example.com/gitea ": "https://git.example.com/gitea/api/v1",
+	} {
+		if got := GiteaAPIURL(input); got != want {
+			t.Errorf("GiteaAPIURL(%q) = %q, want %q", input, got, want)
+		}
+	}
+	if IsGiteaAPIURL("https://github.example.com/api/v3") || IsGiteaAPIURL("") {
+		t.Error("Expected GitHub API URLs not to be Gitea ones")
+	}
+}
+
+func TestGitea_CommitAndReadFile(t *testing.T) {
+	fake, provider := newFakeGitea(t)
+	if !provider.gitea {
+		t.Fatal("Expected the provider to speak Gitea")
+	}
+
+	if err := provider.CommitFile("note.md", "first", "Add note"); err != nil {
+		t.Fatalf("CommitFile() create error = %v", err)
+	}
+	if err := provider.CommitFile("note.md", "second", "Add note"); err != nil {
+		t.Fatalf("CommitFile() update error = %v", err)
+	}
+
+	content, err := provider.ReadFile("note.md")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !strings.HasPrefix(content, "second") || !strings.Contains(content, "first") {
+		t.Errorf("ReadFile() = %q, want both notes", content)
+	}
+
+	link, err := provider.GetGitHubFileURLWithBranch("note.md")
+	if err != nil {
+		t.Fatalf("GetGitHubFileURLWithBranch() error = %v", err)
+	}
+	if !strings.HasSuffix(link, "/owner/notes/src/branch/main/note.md") {
+		t.Errorf("GetGitHubFileURLWithBranch() = %q", link)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	requests := strings.Join(fake.requests, "\n")
+	if !strings.Contains(requests, "POST /contents/note.md") || !strings.Contains(requests, "PUT /contents/note.md") {
+		t.Errorf("Expected a POST creating and a PUT updating the file, got:\n%s", requests)
+	}
+}
+
+func TestGitea_ChangeFiles(t *testing.T) {
+	fake, provider := newFakeGitea(t)
+	fake.files["idea.md"] = "idea"
+	fake.files["note.md"] = "old"
+
+	files := map[string]string{"note.md": "new", "todo.md": "- [ ] test"}
+	if err := provider.ReplaceMultipleFilesWithAuthorAndPremium(files, "Update files", "", 0); err != nil {
+		t.Fatalf("ReplaceMultipleFilesWithAuthorAndPremium() error = %v", err)
+	}
+	if err := provider.MoveFile("idea.md", "ideas/idea.md", "Move idea", ""); err != nil {
+		t.Fatalf("MoveFile() error = %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	want := map[string]string{"note.md": "new", "todo.md": "- [ ] test", "ideas/idea.md": "idea"}
+	if len(fake.files) != len(want) {
+		t.Errorf("files = %v, want %v", fake.files, want)
+	}
+	for name, content := range want {
+		if fake.files[name] != content {
+			t.Errorf("%s = %q, want %q", name, fake.files[name], content)
+		}
+	}
+	for _, request := range fake.requests {
+		if strings.HasPrefix(request, "PUT ") || strings.HasPrefix(request, "POST /contents/") {
+			t.Errorf("%s went through the single file API", request)
+		}
+	}
+}
+
+func TestGitea_IssueWithLabels(t *testing.T) {
+	fake, provider := newFakeGitea(t)
+
+	url, number, err := provider.CreateIssueWithLabels("Idea", "body", []string{"TODO", "msg2git"})
+	if err != nil {
+		t.Fatalf("CreateIssueWithLabels() error = %v", err)
+	}
+	if number != 1 || !strings.HasSuffix(url, "/issues/1") {
+		t.Errorf("CreateIssueWithLabels() = %q, %d", url, number)
+	}
+	if err := provider.AssignIssue(1, []string{"alice"}); err != nil {
+		t.Fatalf("AssignIssue() error = %v", err)
+	}
+
+	fake.mu.Lock()
+	labels := fmt.Sprint(fake.issue["labels"])
+	created := fake.labels["msg2git"]
+	assigned := fmt.Sprint(fake.assigned)
+	fake.mu.Unlock()
+	if labels != fmt.Sprintf("[7 %d]", created) {
+		t.Errorf("Issue labels = %s, want the IDs of todo and the created msg2git label", labels)
+	}
+	if assigned != "[alice]" {
+		t.Errorf("Assignees = %s", assigned)
+	}
+}
+
+func TestGitea_IssueComments(t *testing.T) {
+	_, provider := newFakeGitea(t)
+
+	page, err := provider.GetIssueComments(1, 2, "")
+	if err != nil {
+		t.Fatalf("GetIssueComments() error = %v", err)
+	}
+	if page.Title != "Idea" || page.TotalCount != 5 || len(page.Comments) != 2 {
+		t.Fatalf("GetIssueComments() = %+v", page)
+	}
+	if page.Comments[0].Body != "comment 3" || page.Comments[1].Body != "comment 4" || page.OlderCursor != "3" {
+		t.Errorf("Latest page = %+v, cursor %q", page.Comments, page.OlderCursor)
+	}
+
+	page, err = provider.GetIssueComments(1, 2, "1")
+	if err != nil {
+		t.Fatalf("GetIssueComments(before) error = %v", err)
+	}
+	if len(page.Comments) != 1 || page.Comments[0].Body != "comment 0" || page.OlderCursor != "" {
+		t.Errorf("Oldest page = %+v, cursor %q", page.Comments, page.OlderCursor)
+	}
+}
+
+func TestGitea_EnsureBranch(t *testing.T) {
+	_, provider := newFakeGitea(t)
+
+	created, err := provider.EnsureBranch("notes")
+	if err != nil || !created {
+		t.Fatalf("EnsureBranch() = %v, %v", created, err)
+	}
+	created, err = provider.EnsureBranch("notes")
+	if err != nil || created {
+		t.Errorf("EnsureBranch() of an existing branch = %v, %v", created, err)
+	}
+}
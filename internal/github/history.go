@@ -0,0 +1,74 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// maxHistoryCommits bounds how many commits a history read walks
+const maxHistoryCommits = 20000
+
+// HistoryCommit is a commit of the repository history
+type HistoryCommit struct {
+	Hash        string
+	AuthorName  string
+	AuthorEmail string
+	When        time.Time
+}
+
+// CommitHistory returns the commits reachable from HEAD authored since the given time, newest
+// first. The repository is opened read-only, cloning it without size checks if needed. Shallow
+// clones only return the commits they have.
+func (m *Manager) CommitHistory(since time.Time) ([]HistoryCommit, error) {
+	if err := m.ensureRepositoryReadOnly(); err != nil {
+		return nil, fmt.Errorf("failed to ensure repository: %w", err)
+	}
+
+	if err := m.syncWorktree(); err != nil {
+		// Analyze the local history rather than failing
+		logger.Warn("Failed to pull latest changes before reading history", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	unlock, err := m.lockWorktree(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
+	iter, err := m.repo.Log(&git.LogOptions{Since: &since})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+	defer iter.Close()
+
+	var commits []HistoryCommit
+	err = iter.ForEach(func(commit *object.Commit) error {
+		if len(commits) >= maxHistoryCommits {
+			return storer.ErrStop
+		}
+		commits = append(commits, HistoryCommit{
+			Hash:        commit.Hash.String(),
+			AuthorName:  commit.Author.Name,
+			AuthorEmail: commit.Author.Email,
+			When:        commit.Author.When,
+		})
+		return nil
+	})
+	if errors.Is(err, plumbing.ErrObjectNotFound) && m.isShallow() {
+		err = nil // The history of a shallow clone ends at its boundary
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return commits, nil
+}
@@ -0,0 +1,483 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// History compression: the bot commits once per note, so busy repositories collect thousands of
+// tiny commits. CompressHistory rewrites the history of the commit branch in the local clone: each
+// run of consecutive bot commits of one day older than a cutoff becomes a single rollup commit with
+// the tree of the run's last commit, and later commits are replayed on top with their own trees,
+// authors and messages, so the files at the tip stay exactly the same. The result is pushed to a
+// maintenance branch and proposed in a pull request explaining the rewrite; nothing changes on the
+// commit branch until ApplyCompressedHistory force-pushes it there.
+
+const (
+	compressBranchPrefix = "msg2git/compress-"
+	rollupMaxSubjects    = 50   // Subjects of squashed commits listed in a rollup's message
+	applyMaxNewCommits   = 1000 // Commits made since compressing that ApplyCompressedHistory replays
+)
+
+var (
+	// ErrNoCompressedHistory is returned when there is no maintenance branch to apply
+	ErrNoCompressedHistory = errors.New("no compressed history to apply")
+	// ErrCompressedHistoryOutdated is returned when the commit branch no longer builds on the compressed history
+	ErrCompressedHistoryOutdated = errors.New("the compressed history is outdated, compress again")
+)
+
+// CompressOptions select the commits CompressHistory squashes
+type CompressOptions struct {
+	Before    time.Time      // Only commits authored before it are squashed
+	BotEmails []string       // Commits authored or committed with one of these emails are the bot's
+	Location  *time.Location // Days of the rollups, UTC if nil
+}
+
+// CompressResult describes a compressed history
+type CompressResult struct {
+	Branch     string // Maintenance branch holding the compressed history
+	Base       string // Commit branch it replaces
+	Before     time.Time
+	Commits    int // Commits of the original history
+	Squashed   int // Bot commits folded into rollups
+	Rollups    int // Daily rollup commits replacing them, 0 if there was nothing to compress
+	PullNumber int
+	PullURL    string
+}
+
+// CompressBranch returns the maintenance branch proposing a compressed history of branch base
+func CompressBranch(base string) string {
+	return compressBranchPrefix + base
+}
+
+// isBot reports whether the bot made commit
+func (o CompressOptions) isBot(commit *object.Commit) bool {
+	for _, email := range o.BotEmails {
+		if email != "" && (strings.EqualFold(commit.Author.Email, email) || strings.EqualFold(commit.Committer.Email, email)) {
+			return true
+		}
+	}
+	return false
+}
+
+// day returns the day commit was authored on, and whether it is old enough to be squashed
+func (o CompressOptions) day(commit *object.Commit) (string, bool) {
+	location := o.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return commit.Author.When.In(location).Format("2006-01-02"), commit.Author.When.Before(o.Before)
+}
+
+// firstParentChain returns the commits from the root to tip following first parents. Merges can't
+// be rewritten as a single line of commits, so histories with merges are refused.
+func firstParentChain(repo *git.Repository, tip plumbing.Hash, limit int) ([]*object.Commit, error) {
+	commit, err := repo.CommitObject(tip)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read commit %s: %w", tip, err)
+	}
+
+	var chain []*object.Commit
+	for {
+		if len(chain) >= limit {
+			return nil, fmt.Errorf("history has more than %d commits", limit)
+		}
+		if commit.NumParents() > 1 {
+			return nil, fmt.Errorf("history has merge commits, which can't be compressed")
+		}
+		chain = append(chain, commit)
+		if commit.NumParents() == 0 {
+			break
+		}
+		if commit, err = commit.Parent(0); err != nil {
+			return nil, fmt.Errorf("failed to read parent commit: %w", err)
+		}
+	}
+
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// storeCommit writes commit to repo's object storage
+func storeCommit(repo *git.Repository, commit *object.Commit) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store commit: %w", err)
+	}
+	return hash, nil
+}
+
+// replayCommit recreates commit on parent with its tree, authors and message, keeping it as is if
+// it already has that parent. Signatures are dropped since they don't cover the new parent.
+func replayCommit(repo *git.Repository, commit *object.Commit, parent plumbing.Hash) (plumbing.Hash, error) {
+	if len(commit.ParentHashes) == 1 && commit.ParentHashes[0] == parent {
+		return commit.Hash, nil
+	}
+	return storeCommit(repo, &object.Commit{
+		Author:       commit.Author,
+		Committer:    commit.Committer,
+		Message:      commit.Message,
+		TreeHash:     commit.TreeHash,
+		ParentHashes: []plumbing.Hash{parent},
+	})
+}
+
+// rollupCommit squashes run, consecutive bot commits of day, into one commit on parent
+func rollupCommit(run []*object.Commit, parent plumbing.Hash, day string) *object.Commit {
+	var message strings.Builder
+	message.WriteString(fmt.Sprintf("Daily rollup of %d commits from %s\n\n", len(run), day))
+	for i, commit := range run {
+		if i == rollupMaxSubjects {
+			message.WriteString(fmt.Sprintf("- ...and %d more\n", len(run)-i))
+			break
+		}
+		subject, _, _ := strings.Cut(strings.TrimSpace(commit.Message), "\n")
+		message.WriteString("- " + subject + "\n")
+	}
+
+	last := run[len(run)-1]
+	return &object.Commit{
+		Author:       last.Author,
+		Committer:    last.Committer,
+		Message:      message.String(),
+		TreeHash:     last.TreeHash,
+		ParentHashes: []plumbing.Hash{parent},
+	}
+}
+
+// compressChain writes the compressed history of chain, oldest first, and returns its tip. The
+// root commit is kept so both histories share it, which GitHub needs to compare them.
+func compressChain(repo *git.Repository, chain []*object.Commit, opts CompressOptions) (plumbing.Hash, *CompressResult, error) {
+	result := &CompressResult{Before: opts.Before, Commits: len(chain)}
+	tip := chain[0].Hash
+
+	for i := 1; i < len(chain); {
+		commit := chain[i]
+		end := i + 1
+		day, old := opts.day(commit)
+		if old && opts.isBot(commit) {
+			for end < len(chain) && opts.isBot(chain[end]) {
+				if nextDay, nextOld := opts.day(chain[end]); !nextOld || nextDay != day {
+					break
+				}
+				end++
+			}
+		}
+
+		var err error
+		if run := chain[i:end]; len(run) > 1 {
+			tip, err = storeCommit(repo, rollupCommit(run, tip, day))
+			result.Squashed += len(run)
+			result.Rollups++
+		} else {
+			tip, err = replayCommit(repo, commit, tip)
+		}
+		if err != nil {
+			return plumbing.ZeroHash, nil, err
+		}
+		i = end
+	}
+
+	return tip, result, nil
+}
+
+// CompressHistory pushes the compressed history of the commit branch to its maintenance branch and
+// opens or updates the pull request proposing it. The commit branch itself is left unchanged.
+func (m *Manager) CompressHistory(opts CompressOptions) (*CompressResult, error) {
+	if err := m.ensureRepository(); err != nil {
+		return nil, fmt.Errorf("failed to ensure repository: %w", err)
+	}
+
+	// Pull and build the branches without other operations changing the clone
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+	if err := m.pullLatest(); err != nil {
+		return nil, fmt.Errorf("failed to pull latest changes: %w", err)
+	}
+	if m.isShallow() {
+		return nil, ErrShallowClone
+	}
+
+	head, err := m.repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	chain, err := firstParentChain(m.repo, head.Hash(), maxHistoryCommits)
+	if err != nil {
+		return nil, err
+	}
+	tip, result, err := compressChain(m.repo, chain, opts)
+	if err != nil {
+		return nil, err
+	}
+	result.Base = m.commitBranch()
+	result.Branch = CompressBranch(result.Base)
+	if result.Rollups == 0 {
+		return result, nil
+	}
+
+	ref := plumbing.NewBranchReferenceName(result.Branch)
+	if err := m.repo.Storer.SetReference(plumbing.NewHashReference(ref, tip)); err != nil {
+		return nil, fmt.Errorf("failed to create branch %s: %w", result.Branch, err)
+	}
+	err = m.pushRefs(&git.PushOptions{
+		Auth:     m.basicAuth(),
+		RefSpecs: []config.RefSpec{config.RefSpec("+" + ref.String() + ":" + ref.String())},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, fmt.Errorf("failed to push %s: %w", result.Branch, err)
+	}
+
+	title := fmt.Sprintf("Compress %d bot commits into %d daily rollups", result.Squashed, result.Rollups)
+	pull, err := m.ensurePullRequest(result.Branch, result.Base, title, compressPullBody(result))
+	if err != nil {
+		return nil, err
+	}
+	result.PullNumber, result.PullURL = pull.Number, pull.HTMLURL
+
+	logger.Info("History compression proposed", map[string]interface{}{
+		"branch":   result.Branch,
+		"commits":  result.Commits,
+		"squashed": result.Squashed,
+		"rollups":  result.Rollups,
+		"pull":     result.PullNumber,
+	})
+	return result, nil
+}
+
+// compressPullBody explains the pull request of a compressed history
+func compressPullBody(result *CompressResult) string {
+	return fmt.Sprintf("Opened by msg2git because history compression is enabled with `/compress`. **Don't merge this pull request:** it proposes a rewritten history of `%[1]s`, which merging can't apply.\n\n"+
+		"`%[2]s` holds the history of `%[1]s` with %[3]d bot commits from before %[4]s squashed into %[5]d daily rollup commits, %[6]d commits instead of %[7]d. "+
+		"The files are exactly the same as on `%[1]s`, so this pull request shows no changes.\n\n"+
+		"### Rewriting history\n\n"+
+		"- Apply it with `/compress apply` in Telegram: `%[1]s` is force-pushed to this history, notes committed in the meantime are kept on top.\n"+
+		"- Every commit after the first compressed day gets a new hash. Links to the old commits, e.g. in issues, stop working once GitHub cleans them up.\n"+
+		"- Other clones of the repository have to be reset to the new history (`git fetch && git reset --hard origin/%[1]s`), pulling would merge both histories.\n"+
+		"- Signatures of rewritten commits are dropped, and branches protected against force pushes can't be compressed.\n\n"+
+		"To keep your history, close this pull request and turn compression off with `/compress off`.",
+		result.Base, result.Branch, result.Squashed, result.Before.Format("2006-01-02"), result.Rollups,
+		result.Commits-result.Squashed+result.Rollups, result.Commits)
+}
+
+// ApplyCompressedHistory force-pushes the commit branch to the compressed history of its
+// maintenance branch and returns how many commits made since compressing were replayed on top.
+// The push fails if the branch moves meanwhile, and the maintenance branch is deleted after.
+func (m *Manager) ApplyCompressedHistory() (int, error) {
+	if err := m.ensureRepository(); err != nil {
+		return 0, fmt.Errorf("failed to ensure repository: %w", err)
+	}
+
+	// Pull, reset and force-push without other operations changing the working copy
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return 0, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+	if err := m.pullLatest(); err != nil {
+		return 0, fmt.Errorf("failed to pull latest changes: %w", err)
+	}
+
+	base := m.commitBranch()
+	branch := CompressBranch(base)
+	compressedRef, err := m.repo.Reference(plumbing.NewRemoteReferenceName("origin", branch), true)
+	if err != nil {
+		return 0, ErrNoCompressedHistory
+	}
+	compressed, err := m.repo.CommitObject(compressedRef.Hash())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read compressed history: %w", err)
+	}
+
+	head, err := m.repo.Head()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	commit, err := m.repo.CommitObject(head.Hash())
+	if err != nil {
+		return 0, fmt.Errorf("failed to read HEAD commit: %w", err)
+	}
+
+	// Commits since compressing start after the one with the files of the compressed tip
+	var newer []*object.Commit
+	for commit.TreeHash != compressed.TreeHash {
+		if len(newer) >= applyMaxNewCommits || commit.NumParents() != 1 {
+			return 0, ErrCompressedHistoryOutdated
+		}
+		newer = append(newer, commit)
+		if commit, err = commit.Parent(0); err != nil {
+			return 0, fmt.Errorf("failed to read parent commit: %w", err)
+		}
+	}
+	tip := compressed.Hash
+	for i := len(newer) - 1; i >= 0; i-- {
+		if tip, err = replayCommit(m.repo, newer[i], tip); err != nil {
+			return 0, err
+		}
+	}
+
+	ref := plumbing.NewBranchReferenceName(base)
+	if err := m.repo.Storer.SetReference(plumbing.NewHashReference(ref, tip)); err != nil {
+		return 0, fmt.Errorf("failed to update branch %s: %w", base, err)
+	}
+	err = m.pushRefs(&git.PushOptions{
+		Auth:           m.basicAuth(),
+		RefSpecs:       []config.RefSpec{config.RefSpec("+" + ref.String() + ":" + ref.String())},
+		ForceWithLease: &git.ForceWithLease{RefName: ref, Hash: head.Hash()},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		m.repo.Storer.SetReference(plumbing.NewHashReference(ref, head.Hash()))
+		return 0, fmt.Errorf("failed to force-push %s: %w", base, err)
+	}
+
+	worktree, err := m.repo.Worktree()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := m.hardReset(worktree, tip); err != nil {
+		return 0, fmt.Errorf("failed to reset worktree: %w", err)
+	}
+	m.snapshotWorkspace(false)
+
+	// Deleting the maintenance branch closes its pull request
+	err = m.pushRefs(&git.PushOptions{
+		Auth:     m.basicAuth(),
+		RefSpecs: []config.RefSpec{config.RefSpec(":" + plumbing.NewBranchReferenceName(branch).String())},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		logger.Warn("Failed to delete history compression branch", map[string]interface{}{
+			"branch": branch,
+			"error":  err.Error(),
+		})
+	}
+
+	logger.Info("Compressed history applied", map[string]interface{}{
+		"branch":   base,
+		"replayed": len(newer),
+	})
+	return len(newer), nil
+}
+
+// basicAuth authenticates git operations with the user's token
+func (m *Manager) basicAuth() *githttp.BasicAuth {
+	return &githttp.BasicAuth{
+		Username: m.cfg.GitHubUsername,
+		Password: m.cfg.GitHubToken,
+	}
+}
+
+// pushRefs pushes the refs of options, recorded in the metrics like the pushes of commits
+func (m *Manager) pushRefs(options *git.PushOptions) error {
+	start := time.Now()
+	err := m.repo.Push(options)
+	if err == git.NoErrAlreadyUpToDate {
+		recordGitOperation("push", metricsProviderClone, start, nil)
+	} else {
+		recordGitOperation("push", metricsProviderClone, start, err)
+	}
+	return err
+}
+
+type apiPullRequest struct {
+	Number  int    `json:"number"`
+	HTMLURL string `json:"html_url"`
+}
+
+// ensurePullRequest opens a pull request from head into base, updating the open one if there is
+func (m *Manager) ensurePullRequest(head, base, title, body string) (*apiPullRequest, error) {
+	owner, repo, err := m.parseRepoURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	query := url.Values{"state": {"open"}, "head": {owner + ":" + head}, "base": {base}}
+	var open []apiPullRequest
+	if err := m.pullsRequest("GET", fmt.Sprintf("/repos/%s/%s/pulls?%s", owner, repo, query.Encode()), nil, http.StatusOK, &open); err != nil {
+		return nil, fmt.Errorf("failed to list pull requests: %w", err)
+	}
+	if len(open) > 0 {
+		pull := open[0]
+		update := map[string]string{"title": title, "body": body}
+		if err := m.pullsRequest("PATCH", fmt.Sprintf("/repos/%s/%s/pulls/%d", owner, repo, pull.Number), update, http.StatusOK, nil); err != nil {
+			return nil, fmt.Errorf("failed to update pull request: %w", err)
+		}
+		return &pull, nil
+	}
+
+	create := map[string]string{"title": title, "head": head, "base": base, "body": body}
+	var pull apiPullRequest
+	if err := m.pullsRequest("POST", fmt.Sprintf("/repos/%s/%s/pulls", owner, repo), create, http.StatusCreated, &pull); err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+	return &pull, nil
+}
+
+// pullsRequest sends a pull requests API request, decoding the response into out if set
+func (m *Manager) pullsRequest(method, endpoint string, body interface{}, wantStatus int, out interface{}) error {
+	var bodyReader io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		bodyReader = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequest(method, m.apiBaseURL()+endpoint, bodyReader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "msg2git-telegram-bot")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s (status: %d)", string(respBody), resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+func (a *CloneBasedAdapter) CompressHistory(opts CompressOptions) (*CompressResult, error) {
+	return a.manager.CompressHistory(opts)
+}
+
+func (a *CloneBasedAdapter) ApplyCompressedHistory() (int, error) {
+	return a.manager.ApplyCompressedHistory()
+}
@@ -0,0 +1,96 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCompressChain(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day1 := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+	commits := []struct {
+		email string
+		when  time.Time
+	}{
+		{"bot@example.com", day1},                    // Root, always kept
+		{"bot@example.com", day1.Add(time.Hour)},     // Rollup of day 1
+		{"bot@example.com", day1.Add(2 * time.Hour)}, // Rollup of day 1
+		{"me@example.com", day1.Add(3 * time.Hour)},  // Made by hand, kept
+		{"bot@example.com", day2},                    // Alone, kept
+		{"bot@example.com", day2.AddDate(0, 0, 10)},  // Too recent
+		{"bot@example.com", day2.AddDate(0, 0, 10)},  // Too recent
+	}
+	for i, c := range commits {
+		if err := os.WriteFile(filepath.Join(dir, "inbox.md"), []byte(strings.Repeat(fmt.Sprintf("note %d\n", i), i+1)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add("inbox.md"); err != nil {
+			t.Fatal(err)
+		}
+		signature := &object.Signature{Name: "n", Email: c.email, When: c.when}
+		if _, err := worktree.Commit(fmt.Sprintf("Add note %d", i), &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain, err := firstParentChain(repo, head.Hash(), 100)
+	if err != nil {
+		t.Fatalf("firstParentChain() error = %v", err)
+	}
+
+	opts := CompressOptions{Before: day2.AddDate(0, 0, 5), BotEmails: []string{"BOT@example.com"}}
+	tip, result, err := compressChain(repo, chain, opts)
+	if err != nil {
+		t.Fatalf("compressChain() error = %v", err)
+	}
+	if result.Commits != 7 || result.Squashed != 2 || result.Rollups != 1 {
+		t.Errorf("compressChain() = %+v, want 2 commits squashed into 1 rollup", result)
+	}
+
+	compressed, err := firstParentChain(repo, tip, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(compressed) != 6 {
+		t.Fatalf("compressed history has %d commits, want 6", len(compressed))
+	}
+	if compressed[0].Hash != chain[0].Hash {
+		t.Error("Expected the root commit to be kept")
+	}
+	if !strings.HasPrefix(compressed[1].Message, "Daily rollup of 2 commits from 2026-03-02\n\n- Add note 1\n- Add note 2") {
+		t.Errorf("rollup message = %q", compressed[1].Message)
+	}
+	if compressed[1].TreeHash != chain[2].TreeHash || compressed[5].TreeHash != chain[6].TreeHash {
+		t.Error("Expected the compressed history to keep the files of the original")
+	}
+	if compressed[5].Message != chain[6].Message || !compressed[5].Author.When.Equal(chain[6].Author.When) {
+		t.Error("Expected later commits to be replayed with their message and date")
+	}
+
+	// Nothing is old enough
+	opts.Before = day1
+	if _, result, err := compressChain(repo, chain, opts); err != nil || result.Rollups != 0 {
+		t.Errorf("compressChain() = %+v, %v, want nothing compressed", result, err)
+	}
+}
@@ -0,0 +1,54 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+)
+
+func TestCommitHistory(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	authors := []string{"alice", "bob", "alice"}
+	for i, author := range authors {
+		if err := os.WriteFile(filepath.Join(dir, "inbox.md"), []byte(fmt.Sprintf("%s %d\n", author, i)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add("inbox.md"); err != nil {
+			t.Fatal(err)
+		}
+		signature := &object.Signature{Name: author, Email: author + "@example.com", When: start.AddDate(0, 0, 7*i)}
+		if _, err := worktree.Commit("note", &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// No origin to pull from, the local history is read
+	m := &Manager{cfg: &gitconfig.Config{}, repoPath: dir, repo: repo}
+
+	commits, err := m.CommitHistory(start.AddDate(0, 0, 1))
+	if err != nil {
+		t.Fatalf("CommitHistory() error = %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("CommitHistory() returned %d commits, want the 2 since the first week", len(commits))
+	}
+	if commits[0].AuthorEmail != "alice@example.com" || commits[1].AuthorName != "bob" {
+		t.Errorf("CommitHistory() = %+v, want newest first", commits)
+	}
+}
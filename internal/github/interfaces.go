@@ -1,5 +1,7 @@
 package github
 
+import "time"
+
 // GitHubProvider defines the complete interface for GitHub operations
 // This allows for different implementations (clone-based, API-only, etc.)
 type GitHubProvider interface {
@@ -32,6 +34,44 @@ type RepositoryManager interface {
 	GetDefaultBranch() (string, error)
 	GetGitHubFileURL(filename string) (string, error)
 	GetGitHubFileURLWithBranch(filename string) (string, error)
+
+	// Commit metadata
+	CreateCommitStatus(sha string, status *CommitStatus) error
+}
+
+// Fetcher is implemented by providers keeping a local clone that can be updated in the background
+type Fetcher interface {
+	Fetch() error
+}
+
+// HistoryReader is implemented by providers keeping a local clone whose history can be analyzed
+type HistoryReader interface {
+	CommitHistory(since time.Time) ([]HistoryCommit, error)
+}
+
+// Searcher is implemented by providers that can search the contents of markdown files
+type Searcher interface {
+	SearchFiles(query string, limit int) ([]SearchMatch, error)
+}
+
+// RateLimitReader is implemented by providers that can report the token's GitHub API budgets
+type RateLimitReader interface {
+	RateLimits() (*RateLimits, error)
+}
+
+// HistoryCompressor is implemented by providers keeping a local clone whose history can be rewritten
+type HistoryCompressor interface {
+	// CompressHistory proposes the compressed history of the commit branch in a pull request
+	CompressHistory(opts CompressOptions) (*CompressResult, error)
+	// ApplyCompressedHistory force-pushes the commit branch to the proposed history
+	ApplyCompressedHistory() (int, error)
+}
+
+// BranchCreator is implemented by providers that can create a branch on GitHub up front.
+// Clone-based providers create the configured branch with its first push instead.
+type BranchCreator interface {
+	// EnsureBranch creates the branch from the default branch if missing, returning whether it did
+	EnsureBranch(name string) (bool, error)
 }
 
 // FileManager handles all file operations (read, write, commit)
@@ -40,6 +80,7 @@ type FileManager interface {
 	CommitFile(filename, content, commitMessage string) error
 	CommitFileWithAuthor(filename, content, commitMessage, customAuthor string) error
 	CommitFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor string, premiumLevel int) error
+	CommitFileWithResult(filename, content, commitMessage, customAuthor string, premiumLevel int) (*CommitResult, error)
 	
 	// Single file operations (replace mode)
 	ReplaceFile(filename, content, commitMessage string) error
@@ -54,16 +95,30 @@ type FileManager interface {
 	
 	// File reading
 	ReadFile(filename string) (string, error)
+	ListDirectory(path string) ([]DirectoryEntry, error)
+
+	// File removal and relocation
+	MoveFile(oldPath, newPath, commitMessage, customAuthor string) error
+	DeleteFile(filename, commitMessage, customAuthor string) error
 }
 
 // IssueManager handles GitHub issue operations
 type IssueManager interface {
 	// Issue creation and management
 	CreateIssue(title, body string) (string, int, error)
+	CreateIssueWithLabels(title, body string, labels []string) (string, int, error)
 	GetIssueStatus(issueNumber int) (*IssueStatus, error)
 	SyncIssueStatuses(issueNumbers []int) (map[int]*IssueStatus, error)
 	AddIssueComment(issueNumber int, commentText string) (string, error)
+	GetIssueComments(issueNumber, limit int, before string) (*IssueCommentPage, error)
+	AssignIssue(issueNumber int, assignees []string) error
 	CloseIssue(issueNumber int) error
+	UpdateIssueBody(issueNumber int, body string) error
+
+	// Labels defined in the repository
+	ListLabels() ([]string, error)
+	// All issues (open and closed, no pull requests) carrying a label, newest first
+	ListIssuesByLabel(label string) ([]*IssueStatus, error)
 }
 
 // AssetManager handles binary asset uploads (photos, files)
@@ -83,8 +138,19 @@ type GitHubConfig interface {
 // ProviderConfig contains all configuration needed to create a provider
 type ProviderConfig struct {
 	Config       GitHubConfig
-	PremiumLevel int
-	UserID       string // For identifying user-specific operations
+	PremiumLevel    int
+	UserID          string // For identifying user-specific operations
+	ChatID          int64  // Telegram chat the provider acts for, ties slow git operations to its request
+	CloneSubmodules bool   // Clone-based only: also clone git submodules
+	ShallowClone    bool   // Clone-based only: fetch only the latest commit of new clones
+	SparseCheckout  bool   // Clone-based only: check out only top-level files of new clones
+	Committer       string // Identity committing on behalf of the author as "Name <email>", the author commits if empty
+	Branch          string // Branch notes are committed to, the repository's default branch if empty
+	WriteQuota      func() error // Checked before commits and uploads adding content, nil allows all (see write_quota.go)
+
+	// GitHub Enterprise Server endpoints of this user (empty uses the deployment's)
+	APIBaseURL     string
+	UploadsBaseURL string
 }
 
 // ProviderType defines the implementation type
@@ -92,7 +158,7 @@ type ProviderType string
 
 const (
 	ProviderTypeClone ProviderType = "clone" // Current implementation
-	ProviderTypeAPI   ProviderType = "api"   // Future API-only implementation
+	ProviderTypeAPI   ProviderType = "api"   // Contents and Git Data APIs, no local clone
 	ProviderTypeHybrid ProviderType = "hybrid" // Mixed approach
 )
 
@@ -120,6 +186,38 @@ const (
 	FileModeBinary  FileMode = "binary"  // Binary file upload
 )
 
+// DirectoryEntry describes a single file or folder in a repository directory listing
+type DirectoryEntry struct {
+	Name string
+	Path string
+	Type string // "file" or "dir"
+	Size int64
+}
+
+// CommitResult describes the commit created by a file operation
+type CommitResult struct {
+	Filename string
+	SHA      string
+	URL      string // Commit page on GitHub
+	FileSize int64  // Size of the file after the commit, in bytes
+}
+
+// ShortSHA returns the abbreviated commit hash
+func (r *CommitResult) ShortSHA() string {
+	if len(r.SHA) > 7 {
+		return r.SHA[:7]
+	}
+	return r.SHA
+}
+
+// CommitStatus is a GitHub commit status attached to bot-created commits
+type CommitStatus struct {
+	State       string `json:"state"` // "success", "pending", "failure" or "error"
+	TargetURL   string `json:"target_url,omitempty"`
+	Description string `json:"description,omitempty"` // GitHub truncates at 140 characters
+	Context     string `json:"context"`
+}
+
 // CommitOptions provides options for commit operations
 type CommitOptions struct {
 	Message      string
@@ -0,0 +1,83 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// AssignIssue adds assignees to an existing GitHub issue. GitHub silently skips users who
+// can't be assigned in the repository, so a nil error doesn't guarantee the assignment.
+func (m *Manager) AssignIssue(issueNumber int, assignees []string) error {
+	owner, repo, err := m.parseRepoURL()
+	if err != nil {
+		return fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	assignBodyJSON, err := json.Marshal(map[string]interface{}{"assignees": assignees})
+	if err != nil {
+		return fmt.Errorf("failed to marshal assignees: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/assignees", m.apiBaseURL(), owner, repo, issueNumber)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(assignBodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "msg2git-telegram-bot")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s (status: %d)", string(body), resp.StatusCode)
+	}
+
+	logger.Info("Successfully assigned GitHub issue", map[string]interface{}{
+		"issue_number": issueNumber,
+		"repo":         fmt.Sprintf("%s/%s", owner, repo),
+		"assignees":    assignees,
+	})
+
+	return nil
+}
+
+// AssignIssue adds assignees to an existing GitHub issue. GitHub silently skips users who
+// can't be assigned in the repository, so a nil error doesn't guarantee the assignment.
+func (p *APIBasedProvider) AssignIssue(issueNumber int, assignees []string) error {
+	if p.gitea {
+		if err := p.assignGiteaIssue(issueNumber, assignees); err != nil {
+			return err
+		}
+	} else {
+		endpoint := fmt.Sprintf("/repos/%s/%s/issues/%d/assignees", p.repoOwner, p.repoName, issueNumber)
+
+		resp, err := p.makeAPIRequest("POST", endpoint, map[string]interface{}{"assignees": assignees})
+		if err != nil {
+			return fmt.Errorf("failed to assign issue: %w", err)
+		}
+		resp.Body.Close()
+	}
+
+	logger.Info("Issue assigned via API", map[string]interface{}{
+		"issue_number": issueNumber,
+		"assignees":    assignees,
+		"user_id":      p.config.UserID,
+	})
+
+	return nil
+}
@@ -0,0 +1,225 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Issue comment threads: the latest comments of an issue via GraphQL, paged backwards by cursor
+
+// IssueComment is a single comment of an issue thread
+type IssueComment struct {
+	Author    string
+	Body      string
+	URL       string
+	CreatedAt time.Time
+}
+
+// IssueCommentPage is a page of an issue thread, comments ordered oldest first
+type IssueCommentPage struct {
+	Number      int
+	Title       string
+	State       string // "open" or "closed"
+	URL         string
+	TotalCount  int
+	Comments    []IssueComment
+	OlderCursor string // Cursor to pass as "before" for the previous page, "" if there is none
+}
+
+const issueCommentsQuery = `query($owner: String!, $name: String!, $number: Int!, $last: Int!, $before: String) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) {
+      number
+      title
+      state
+      url
+      comments(last: $last, before: $before) {
+        totalCount
+        pageInfo { hasPreviousPage startCursor }
+        nodes {
+          author { login }
+          body
+          url
+          createdAt
+        }
+      }
+    }
+  }
+}`
+
+// issueCommentsRequest builds the GraphQL request body for a page of comments before the cursor
+func issueCommentsRequest(owner, repo string, issueNumber, limit int, before string) map[string]interface{} {
+	variables := map[string]interface{}{
+		"owner":  owner,
+		"name":   repo,
+		"number": issueNumber,
+		"last":   limit,
+	}
+	if before != "" {
+		variables["before"] = before
+	}
+	return map[string]interface{}{
+		"query":     issueCommentsQuery,
+		"variables": variables,
+	}
+}
+
+// parseIssueCommentsResponse converts a GraphQL issue comments response into a page
+func parseIssueCommentsResponse(body []byte) (*IssueCommentPage, error) {
+	var response struct {
+		Data struct {
+			Repository struct {
+				Issue *struct {
+					Number   int    `json:"number"`
+					Title    string `json:"title"`
+					State    string `json:"state"`
+					URL      string `json:"url"`
+					Comments struct {
+						TotalCount int `json:"totalCount"`
+						PageInfo   struct {
+							HasPreviousPage bool   `json:"hasPreviousPage"`
+							StartCursor     string `json:"startCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							Author *struct {
+								Login string `json:"login"`
+							} `json:"author"`
+							Body      string    `json:"body"`
+							URL       string    `json:"url"`
+							CreatedAt time.Time `json:"createdAt"`
+						} `json:"nodes"`
+					} `json:"comments"`
+				} `json:"issue"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode GraphQL response: %w", err)
+	}
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("GraphQL errors: %v", response.Errors)
+	}
+
+	issue := response.Data.Repository.Issue
+	if issue == nil {
+		return nil, fmt.Errorf("issue not found")
+	}
+
+	page := &IssueCommentPage{
+		Number:     issue.Number,
+		Title:      issue.Title,
+		State:      strings.ToLower(issue.State),
+		URL:        issue.URL,
+		TotalCount: issue.Comments.TotalCount,
+	}
+	if issue.Comments.PageInfo.HasPreviousPage {
+		page.OlderCursor = issue.Comments.PageInfo.StartCursor
+	}
+	for _, node := range issue.Comments.Nodes {
+		// Comments of deleted accounts have no author, GitHub shows them as "ghost"
+		author := "ghost"
+		if node.Author != nil {
+			author = node.Author.Login
+		}
+		page.Comments = append(page.Comments, IssueComment{
+			Author:    author,
+			Body:      node.Body,
+			URL:       node.URL,
+			CreatedAt: node.CreatedAt,
+		})
+	}
+
+	return page, nil
+}
+
+// GetIssueComments returns up to limit comments of an issue written before the cursor, latest if empty
+func (m *Manager) GetIssueComments(issueNumber, limit int, before string) (*IssueCommentPage, error) {
+	owner, repo, err := m.parseRepoURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	jsonData, err := json.Marshal(issueCommentsRequest(owner, repo, issueNumber, limit, before))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal GraphQL query: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", m.apiBaseURL()+"/graphql", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+m.cfg.GitHubToken)
+	req.Header.Set("Content-Type", "application/json")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GraphQL request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	recordGraphQLRateLimit(graphQLBudgetKey(m.apiBaseURL(), m.cfg.GitHubToken), parseGraphQLRateLimit(resp.Header))
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GraphQL response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GraphQL request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	page, err := parseIssueCommentsResponse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Fetched issue comments", map[string]interface{}{
+		"issue_number": issueNumber,
+		"count":        len(page.Comments),
+		"total_count":  page.TotalCount,
+	})
+
+	return page, nil
+}
+
+// GetIssueComments returns up to limit comments of an issue written before the cursor, latest if empty
+func (p *APIBasedProvider) GetIssueComments(issueNumber, limit int, before string) (*IssueCommentPage, error) {
+	if p.gitea {
+		return p.getGiteaIssueComments(issueNumber, limit, before)
+	}
+
+	resp, err := p.makeAPIRequest("POST", "/graphql", issueCommentsRequest(p.repoOwner, p.repoName, issueNumber, limit, before))
+	if err != nil {
+		return nil, fmt.Errorf("GraphQL query failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GraphQL response: %w", err)
+	}
+
+	page, err := parseIssueCommentsResponse(body)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Debug("Fetched issue comments via API", map[string]interface{}{
+		"issue_number": issueNumber,
+		"count":        len(page.Comments),
+		"total_count":  page.TotalCount,
+		"user_id":      p.config.UserID,
+	})
+
+	return page, nil
+}
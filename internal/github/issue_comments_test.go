@@ -0,0 +1,62 @@
+package github
+
+import (
+	"testing"
+)
+
+func TestIssueCommentsRequest(t *testing.T) {
+	request := issueCommentsRequest("owner", "notes", 7, 5, "")
+	variables := request["variables"].(map[string]interface{})
+	if _, ok := variables["before"]; ok {
+		t.Errorf("issueCommentsRequest() without cursor sets before = %v", variables["before"])
+	}
+	if variables["number"] != 7 || variables["last"] != 5 {
+		t.Errorf("issueCommentsRequest() variables = %v", variables)
+	}
+
+	request = issueCommentsRequest("owner", "notes", 7, 5, "Y3Vyc29yOnYyOpHOAAAB")
+	if before := request["variables"].(map[string]interface{})["before"]; before != "Y3Vyc29yOnYyOpHOAAAB" {
+		t.Errorf("issueCommentsRequest() before = %v", before)
+	}
+}
+
+func TestParseIssueCommentsResponse(t *testing.T) {
+	body := []byte(`{"data": {"repository": {"issue": {
+		"number": 7, "title": "Crash on start", "state": "OPEN", "url": "https://github.com/owner/notes/issues/7",
+		"comments": {
+			"totalCount": 12,
+			"pageInfo": {"hasPreviousPage": true, "startCursor": "Y3Vyc29y"},
+			"nodes": [
+				{"author": {"login": "alice"}, "body": "Seeing this too", "url": "https://github.com/owner/notes/issues/7#issuecomment-1", "createdAt": "2026-10-01T14:03:00Z"},
+				{"author": null, "body": "Fixed", "url": "https://github.com/owner/notes/issues/7#issuecomment-2", "createdAt": "2026-10-02T09:30:00Z"}
+			]
+		}
+	}}}}`)
+
+	page, err := parseIssueCommentsResponse(body)
+	if err != nil {
+		t.Fatalf("parseIssueCommentsResponse() error = %v", err)
+	}
+	if page.Number != 7 || page.State != "open" || page.TotalCount != 12 || page.OlderCursor != "Y3Vyc29y" {
+		t.Errorf("parseIssueCommentsResponse() = %+v", page)
+	}
+	if len(page.Comments) != 2 || page.Comments[0].Author != "alice" || page.Comments[1].Author != "ghost" {
+		t.Errorf("parseIssueCommentsResponse() comments = %+v", page.Comments)
+	}
+	if page.Comments[1].CreatedAt.Day() != 2 {
+		t.Errorf("parseIssueCommentsResponse() createdAt = %v", page.Comments[1].CreatedAt)
+	}
+
+	// The first page has no older comments, even if GitHub still reports a start cursor
+	page, err = parseIssueCommentsResponse([]byte(`{"data": {"repository": {"issue": {"number": 7, "comments": {"pageInfo": {"hasPreviousPage": false, "startCursor": "Y3Vyc29y"}}}}}}`))
+	if err != nil || page.OlderCursor != "" {
+		t.Errorf("parseIssueCommentsResponse(first page) = %+v, %v", page, err)
+	}
+
+	if _, err := parseIssueCommentsResponse([]byte(`{"data": {"repository": {"issue": null}}}`)); err == nil {
+		t.Error("parseIssueCommentsResponse(missing issue) expected error")
+	}
+	if _, err := parseIssueCommentsResponse([]byte(`{"errors": [{"message": "Bad credentials"}]}`)); err == nil {
+		t.Error("parseIssueCommentsResponse(errors) expected error")
+	}
+}
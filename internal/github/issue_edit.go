@@ -0,0 +1,73 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// UpdateIssueBody replaces the body of an existing GitHub issue
+func (m *Manager) UpdateIssueBody(issueNumber int, body string) error {
+	owner, repo, err := m.parseRepoURL()
+	if err != nil {
+		return fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	updateBodyJSON, err := json.Marshal(map[string]interface{}{"body": body})
+	if err != nil {
+		return fmt.Errorf("failed to marshal issue body: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", m.apiBaseURL(), owner, repo, issueNumber)
+	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(updateBodyJSON))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "msg2git-telegram-bot")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error: %s (status: %d)", string(respBody), resp.StatusCode)
+	}
+
+	logger.Info("Successfully updated GitHub issue", map[string]interface{}{
+		"issue_number": issueNumber,
+		"repo":         fmt.Sprintf("%s/%s", owner, repo),
+	})
+
+	return nil
+}
+
+// UpdateIssueBody replaces the body of an existing GitHub issue
+func (p *APIBasedProvider) UpdateIssueBody(issueNumber int, body string) error {
+	endpoint := fmt.Sprintf("/repos/%s/%s/issues/%d", p.repoOwner, p.repoName, issueNumber)
+
+	resp, err := p.makeAPIRequest("PATCH", endpoint, map[string]string{"body": body})
+	if err != nil {
+		return fmt.Errorf("failed to update issue: %w", err)
+	}
+	defer resp.Body.Close()
+
+	logger.Info("Issue updated via API", map[string]interface{}{
+		"issue_number": issueNumber,
+		"user_id":      p.config.UserID,
+	})
+
+	return nil
+}
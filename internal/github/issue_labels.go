@@ -0,0 +1,136 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// maxLabels is how many labels of a repository are listed, the first page of the labels API
+const maxLabels = 100
+
+type apiLabel struct {
+	Name string `json:"name"`
+}
+
+// ListLabels returns the names of the labels defined in the repository
+func (m *Manager) ListLabels() ([]string, error) {
+	owner, repo, err := m.parseRepoURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/labels?per_page=%d", m.apiBaseURL(), owner, repo, maxLabels)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "msg2git-telegram-bot")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API error: %s (status: %d)", string(body), resp.StatusCode)
+	}
+
+	return decodeLabels(resp.Body)
+}
+
+// ListLabels returns the names of the labels defined in the repository
+func (p *APIBasedProvider) ListLabels() ([]string, error) {
+	pageQuery, _ := p.pageQuery(maxLabels)
+	endpoint := fmt.Sprintf("/repos/%s/%s/labels?%s", p.repoOwner, p.repoName, pageQuery)
+
+	resp, err := p.makeAPIRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list labels: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeLabels(resp.Body)
+}
+
+// ListIssuesByLabel returns the open and closed issues carrying label, newest first, from the
+// latest restIssueMaxPages pages
+func (m *Manager) ListIssuesByLabel(label string) ([]*IssueStatus, error) {
+	owner, repo, err := m.parseRepoURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	var issues []*IssueStatus
+	pageURL := fmt.Sprintf("%s/repos/%s/%s/issues?state=all&labels=%s&per_page=100", m.apiBaseURL(), owner, repo, url.QueryEscape(label))
+	for page := 0; pageURL != "" && page < restIssueMaxPages; page++ {
+		pageIssues, nextURL, err := m.fetchIssuesPage(pageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues labeled %s: %w", label, err)
+		}
+		issues = appendIssues(issues, pageIssues)
+		pageURL = nextURL
+	}
+
+	return issues, nil
+}
+
+// ListIssuesByLabel returns the open and closed issues carrying label, newest first, from the
+// latest restIssueMaxPages pages
+func (p *APIBasedProvider) ListIssuesByLabel(label string) ([]*IssueStatus, error) {
+	var issues []*IssueStatus
+	pageQuery, pageSize := p.pageQuery(100)
+	for page := 1; page <= restIssueMaxPages; page++ {
+		endpoint := fmt.Sprintf("/repos/%s/%s/issues?state=all&labels=%s&%s&page=%d", p.repoOwner, p.repoName, url.QueryEscape(label), pageQuery, page)
+
+		resp, err := p.makeAPIRequest("GET", endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list issues labeled %s: %w", label, err)
+		}
+		var pageIssues []IssueStatus
+		err = json.NewDecoder(resp.Body).Decode(&pageIssues)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode issues: %w", err)
+		}
+
+		issues = appendIssues(issues, pageIssues)
+		if len(pageIssues) < pageSize {
+			break
+		}
+	}
+
+	return issues, nil
+}
+
+// appendIssues appends the issues of a REST issue list page, leaving out pull requests
+func appendIssues(issues []*IssueStatus, page []IssueStatus) []*IssueStatus {
+	for i := range page {
+		if page[i].PullRequest == nil {
+			issues = append(issues, &page[i])
+		}
+	}
+	return issues
+}
+
+func decodeLabels(body io.Reader) ([]string, error) {
+	var labels []apiLabel
+	if err := json.NewDecoder(body).Decode(&labels); err != nil {
+		return nil, fmt.Errorf("failed to decode labels: %w", err)
+	}
+
+	names := make([]string, 0, len(labels))
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,156 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Issue status sync: GraphQL alias queries in bounded batches, REST list pagination as fallback
+
+const (
+	// graphQLIssueBatchSize keeps each aliased query well below GitHub's query complexity limits
+	graphQLIssueBatchSize = 50
+	// graphQLMinRemaining is the rate limit budget left for the user's other GraphQL calls
+	graphQLMinRemaining = 10
+	// graphQLMaxRetryWait is the longest Retry-After (secondary rate limit) a sync waits for
+	graphQLMaxRetryWait = 10 * time.Second
+	// restIssueMaxPages bounds the REST fallback to the latest 3000 issues and pull requests
+	restIssueMaxPages = 30
+)
+
+// graphQLRateLimit is the rate limit state GitHub reports in GraphQL response headers
+type graphQLRateLimit struct {
+	Known      bool
+	Limit      int
+	Remaining  int
+	Used       int
+	Reset      time.Time
+	RetryAfter time.Duration
+}
+
+func parseGraphQLRateLimit(header http.Header) graphQLRateLimit {
+	var rateLimit graphQLRateLimit
+	if remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining")); err == nil {
+		rateLimit.Known = true
+		rateLimit.Remaining = remaining
+	}
+	if limit, err := strconv.Atoi(header.Get("X-RateLimit-Limit")); err == nil {
+		rateLimit.Limit = limit
+	}
+	if used, err := strconv.Atoi(header.Get("X-RateLimit-Used")); err == nil {
+		rateLimit.Used = used
+	}
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rateLimit.Reset = time.Unix(reset, 0)
+	}
+	if retryAfter, err := strconv.Atoi(header.Get("Retry-After")); err == nil {
+		rateLimit.RetryAfter = time.Duration(retryAfter) * time.Second
+	}
+	return rateLimit
+}
+
+// chunkIssueNumbers splits issue numbers into batches of at most size
+func chunkIssueNumbers(issueNumbers []int, size int) [][]int {
+	var chunks [][]int
+	for start := 0; start < len(issueNumbers); start += size {
+		end := start + size
+		if end > len(issueNumbers) {
+			end = len(issueNumbers)
+		}
+		chunks = append(chunks, issueNumbers[start:end])
+	}
+	return chunks
+}
+
+// fetchIssuesViaGraphQL fetches specific issues using GitHub's GraphQL API in batches.
+// On error the statuses fetched by earlier batches are returned along with it.
+func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int) (map[int]*IssueStatus, error) {
+	statuses := make(map[int]*IssueStatus)
+	budgetKey := graphQLBudgetKey(m.apiBaseURL(), m.cfg.GitHubToken)
+	batches := chunkIssueNumbers(issueNumbers, graphQLIssueBatchSize)
+	logGraphQLBudget(budgetKey, len(batches))
+
+	for i, batch := range batches {
+		// Stop before exhausting the user's GraphQL budget, the rest goes through REST
+		if err := affordGraphQLQuery(budgetKey); err != nil {
+			return statuses, err
+		}
+
+		batchStatuses, rateLimit, err := m.fetchIssuesGraphQLBatch(owner, repo, batch)
+		if err != nil && rateLimit.RetryAfter > 0 && rateLimit.RetryAfter <= graphQLMaxRetryWait {
+			logger.Debug("GraphQL secondary rate limit, retrying batch", map[string]interface{}{
+				"batch":       i,
+				"retry_after": rateLimit.RetryAfter.String(),
+			})
+			time.Sleep(rateLimit.RetryAfter)
+			batchStatuses, rateLimit, err = m.fetchIssuesGraphQLBatch(owner, repo, batch)
+		}
+		if err != nil {
+			return statuses, fmt.Errorf("GraphQL batch %d failed: %w", i, err)
+		}
+
+		for number, status := range batchStatuses {
+			statuses[number] = status
+		}
+	}
+
+	return statuses, nil
+}
+
+// fetchIssuesViaREST looks up specific issues by paging through the REST issue list, newest first
+func (m *Manager) fetchIssuesViaREST(owner, repo string, issueNumbers []int) (map[int]*IssueStatus, error) {
+	wanted := make(map[int]bool, len(issueNumbers))
+	oldest := 0
+	for _, number := range issueNumbers {
+		wanted[number] = true
+		if oldest == 0 || number < oldest {
+			oldest = number
+		}
+	}
+
+	statuses := make(map[int]*IssueStatus)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues?state=all&per_page=100", m.apiBaseURL(), owner, repo)
+
+	for page := 0; url != "" && page < restIssueMaxPages && len(statuses) < len(wanted); page++ {
+		issues, nextURL, err := m.fetchIssuesPage(url)
+		if err != nil {
+			return statuses, err
+		}
+
+		smallest := 0
+		for i := range issues {
+			issue := issues[i]
+			if wanted[issue.Number] && issue.PullRequest == nil {
+				statuses[issue.Number] = &issue
+			}
+			if smallest == 0 || issue.Number < smallest {
+				smallest = issue.Number
+			}
+		}
+
+		// The list is ordered by creation, so older pages can't contain the issues we still need
+		if smallest != 0 && smallest <= oldest {
+			break
+		}
+		url = nextURL
+	}
+
+	return statuses, nil
+}
+
+// missingIssueNumbers returns the requested issue numbers without a status, in ascending order
+func missingIssueNumbers(issueNumbers []int, statuses map[int]*IssueStatus) []int {
+	var missing []int
+	for _, number := range issueNumbers {
+		if _, ok := statuses[number]; !ok {
+			missing = append(missing, number)
+		}
+	}
+	sort.Ints(missing)
+	return missing
+}
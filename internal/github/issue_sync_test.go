@@ -0,0 +1,186 @@
+package github
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/testutil"
+)
+
+func TestChunkIssueNumbers(t *testing.T) {
+	tests := []struct {
+		count int
+		size  int
+		want  []int
+	}{
+		{0, 50, nil},
+		{3, 50, []int{3}},
+		{100, 50, []int{50, 50}},
+		{120, 50, []int{50, 50, 20}},
+	}
+
+	for _, tt := range tests {
+		numbers := make([]int, tt.count)
+		for i := range numbers {
+			numbers[i] = i + 1
+		}
+
+		var sizes []int
+		for _, chunk := range chunkIssueNumbers(numbers, tt.size) {
+			sizes = append(sizes, len(chunk))
+		}
+		if !reflect.DeepEqual(sizes, tt.want) {
+			t.Errorf("chunkIssueNumbers(%d, %d) sizes = %v, want %v", tt.count, tt.size, sizes, tt.want)
+		}
+	}
+}
+
+func TestParseGraphQLRateLimit(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "42")
+	header.Set("X-RateLimit-Reset", "1700000000")
+	header.Set("Retry-After", "3")
+
+	rateLimit := parseGraphQLRateLimit(header)
+	if !rateLimit.Known || rateLimit.Remaining != 42 || rateLimit.Reset.Unix() != 1700000000 || rateLimit.RetryAfter.Seconds() != 3 {
+		t.Errorf("parseGraphQLRateLimit() = %+v", rateLimit)
+	}
+
+	if rateLimit := parseGraphQLRateLimit(http.Header{}); rateLimit.Known {
+		t.Errorf("parseGraphQLRateLimit(empty) = %+v, want unknown", rateLimit)
+	}
+}
+
+// addFakeIssues creates count issues on owner/notes, every third one closed
+func addFakeIssues(fake *testutil.FakeGitHub, count int) []int {
+	numbers := make([]int, count)
+	for i := range numbers {
+		state := "open"
+		if i%3 == 0 {
+			state = "closed"
+		}
+		numbers[i] = fake.AddIssue("owner", "notes", "Issue", state).Number
+	}
+	return numbers
+}
+
+func graphQLRequestCount(fake *testutil.FakeGitHub) int {
+	count := 0
+	for _, req := range fake.Requests() {
+		if req.Path == "/graphql" {
+			count++
+		}
+	}
+	return count
+}
+
+func TestManager_SyncIssueStatusesChunked(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.GraphQLMaxAliases = graphQLIssueBatchSize
+	numbers := addFakeIssues(fake, 120)
+
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	statuses, err := manager.SyncIssueStatuses(numbers)
+	if err != nil {
+		t.Fatalf("SyncIssueStatuses() error = %v", err)
+	}
+	if len(statuses) != 120 || statuses[1].State != "CLOSED" || statuses[120].State != "OPEN" {
+		t.Errorf("SyncIssueStatuses() returned %d statuses", len(statuses))
+	}
+	if count := graphQLRequestCount(fake); count != 3 {
+		t.Errorf("Expected 3 GraphQL batches, got %d", count)
+	}
+}
+
+func TestAPIProvider_SyncIssueStatusesGraphQLChunked(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.GraphQLMaxAliases = graphQLIssueBatchSize
+	numbers := addFakeIssues(fake, 101)
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	statuses, err := provider.(*APIBasedProvider).SyncIssueStatusesGraphQL(numbers)
+	if err != nil {
+		t.Fatalf("SyncIssueStatusesGraphQL() error = %v", err)
+	}
+	if len(statuses) != 101 {
+		t.Errorf("SyncIssueStatusesGraphQL() returned %d statuses", len(statuses))
+	}
+}
+
+// Without GraphQL, issues are found by paging through the REST issue list
+func TestManager_SyncIssueStatusesRESTFallback(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.GraphQLUnavailable = true
+	addFakeIssues(fake, 250)
+
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	statuses, err := manager.SyncIssueStatuses([]int{130, 249, 4})
+	if err != nil {
+		t.Fatalf("SyncIssueStatuses() error = %v", err)
+	}
+	if len(statuses) != 3 || statuses[4].State != "closed" || statuses[249].State != "open" {
+		t.Errorf("SyncIssueStatuses() = %+v", statuses)
+	}
+
+	pages := 0
+	for _, req := range fake.Requests() {
+		if strings.HasSuffix(req.Path, "/issues") {
+			pages++
+		}
+	}
+	if pages != 3 {
+		t.Errorf("Expected 3 REST issue pages, got %d", pages)
+	}
+}
+
+// Once the GraphQL budget runs low, the remaining batches go through REST
+func TestManager_SyncIssueStatusesRateLimited(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetGraphQLRateLimit(graphQLMinRemaining)
+	numbers := addFakeIssues(fake, 120)
+
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	statuses, err := manager.SyncIssueStatuses(numbers)
+	if err != nil {
+		t.Fatalf("SyncIssueStatuses() error = %v", err)
+	}
+	if len(statuses) != 120 {
+		t.Errorf("SyncIssueStatuses() returned %d statuses", len(statuses))
+	}
+	if count := graphQLRequestCount(fake); count != 1 {
+		t.Errorf("Expected GraphQL to stop after 1 batch, got %d", count)
+	}
+}
+
+func TestManager_SyncIssueStatusesAllFail(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.GraphQLUnavailable = true
+
+	manager, err := NewManager(&gitconfig.Config{GitHubToken: cfg.GitHubToken, GitHubRepo: "https://github.com/owner/missing"}, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	if _, err := manager.SyncIssueStatuses([]int{1, 2}); err == nil {
+		t.Error("Expected an error when neither GraphQL nor REST can fetch issues")
+	}
+}
@@ -8,14 +8,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-git/go-git/v5"
@@ -26,6 +27,7 @@ import (
 	gitconfig "github.com/msg2git/msg2git/internal/config"
 	"github.com/msg2git/msg2git/internal/consts"
 	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/watchdog"
 )
 
 type Manager struct {
@@ -34,9 +36,22 @@ type Manager struct {
 	repo         *git.Repository
 	premiumLevel int // Add premiumLevel to the Manager struct
 	userID       string // For file locking support
+	committer    string // Commits on behalf of the author if set, see committerSignature
+	chatID       int64  // Chat whose request slow git operations are reported with
+	branch       string // Branch notes are committed to, the default branch if empty (see branches.go)
+	fs           WorktreeFS // Files of the worktree, created by files() (see worktree_fs.go)
+	backend      WorktreeFS // The layer of fs that stores the files, below quota and sparse checkout
+	filesOnce    sync.Once
+	shallowClone   bool // Clone only the latest commit (see shallow_clone.go)
+	sparseCheckout bool // Check out only top-level files of new clones, others on first use
+	writeQuota     func() error // Refuses commits adding content when set (see write_quota.go)
 }
 
 func NewManager(cfg *gitconfig.Config, premiumLevel int) (*Manager, error) {
+	if LocalClonesDisabled() {
+		return nil, ErrLocalClonesDisabled
+	}
+
 	// Generate a unique repository path based on the repository URL
 	repoPath := generateRepoPath(cfg.GitHubRepo)
 
@@ -102,6 +117,13 @@ func getDirectorySize(dirPath string) (int64, error) {
 	return size, err
 }
 
+// getRepositoryContentSize calculates the user-facing size of a cloned repository: the
+// worktree without .git history and submodule working trees. Quota decisions use this,
+// while data directory cleanup keeps using getDirectorySize for actual disk use
+func getRepositoryContentSize(repoPath string) (int64, error) {
+	return worktreeContentSize(diskFS{root: repoPath})
+}
+
 const maxRepoSize = 1 * 1024 * 1024
 
 // checkRepositorySize checks if repository size is within limits (0.2MB)
@@ -120,7 +142,9 @@ func (m *Manager) checkRepositorySizeWithPremium(premiumLevel int) error {
 		return nil // Repository doesn't exist yet
 	}
 
-	size, err := getDirectorySize(m.repoPath)
+	// The content size covers every tracked file whether or not the clone is shallow or sparse, so
+	// users can't outgrow their tier by leaving history or directories behind
+	size, err := m.contentSize()
 	if err != nil {
 		logger.Warn("Failed to check repository size", map[string]interface{}{
 			"error": err.Error(),
@@ -145,6 +169,8 @@ func (m *Manager) checkRepositorySizeWithPremium(premiumLevel int) error {
 	logger.Debug("Repository size check passed", map[string]interface{}{
 		"size_mb": float64(size) / 1024 / 1024,
 		"path":    m.repoPath,
+		"shallow": m.repo != nil && m.isShallow(),
+		"sparse":  m.sparseWorktree(),
 	})
 
 	return nil
@@ -289,6 +315,36 @@ func CleanupOldRepositories(currentRepoURL string) error {
 	return nil
 }
 
+// EvictClone removes the local clone of repoURL, so the next command clones it afresh. Commits
+// that weren't pushed yet are lost and their push retries cancelled. Returns false if there was
+// no clone.
+func EvictClone(repoURL string) (bool, error) {
+	repoPath := generateRepoPath(repoURL)
+	defer lockClone(repoPath)()
+
+	if _, err := os.Stat(repoPath); os.IsNotExist(err) {
+		return false, nil
+	}
+	if err := os.RemoveAll(repoPath); err != nil {
+		return false, fmt.Errorf("failed to remove clone: %w", err)
+	}
+	defaultBranches.Delete(repoPath)
+
+	pushQueue.Lock()
+	for key, queued := range pushQueue.pending {
+		if strings.HasPrefix(key, repoPath+"@") {
+			queued.timer.Stop()
+			delete(pushQueue.pending, key)
+		}
+	}
+	pushQueue.Unlock()
+
+	logger.Info("Evicted repository clone", map[string]interface{}{
+		"repo_path": repoPath,
+	})
+	return true, nil
+}
+
 func (m *Manager) ensureRepository() error {
 	return m.ensureRepositoryWithPremium(m.premiumLevel)
 }
@@ -319,6 +375,12 @@ func (m *Manager) ensureRepositoryWithPremium(premiumLevel int) error {
 		return nil
 	}
 
+	// A background pre-clone may be setting up the same path, wait for it and reuse its clone
+	defer lockClone(m.repoPath)()
+	if m.repo != nil {
+		return nil
+	}
+
 	// Run garbage collection before doing any repository operations
 	if err := cleanupDataDirectory(); err != nil {
 		logger.Warn("Failed to cleanup data directory", map[string]interface{}{
@@ -328,11 +390,14 @@ func (m *Manager) ensureRepositoryWithPremium(premiumLevel int) error {
 	}
 
 	if _, err := os.Stat(m.repoPath); os.IsNotExist(err) {
-		logger.Info("Repository directory doesn't exist, cloning", map[string]interface{}{
-			"repo_path": m.repoPath,
-		})
-		if err := m.cloneRepositoryWithPremium(premiumLevel); err != nil {
-			return fmt.Errorf("failed to clone repository: %w", err)
+		// Prefer restoring a snapshot from object storage over a full clone
+		if !m.restoreFromWorkspaceStore() {
+			logger.Info("Repository directory doesn't exist, cloning", map[string]interface{}{
+				"repo_path": m.repoPath,
+			})
+			if err := m.cloneRepositoryWithPremium(premiumLevel); err != nil {
+				return fmt.Errorf("failed to clone repository: %w", err)
+			}
 		}
 	} else {
 		logger.Debug("Repository directory exists, opening", map[string]interface{}{
@@ -360,6 +425,11 @@ func (m *Manager) ensureRepositoryReadOnly() error {
 		return nil
 	}
 
+	defer lockClone(m.repoPath)()
+	if m.repo != nil {
+		return nil
+	}
+
 	// Run garbage collection before doing any repository operations
 	if err := cleanupDataDirectory(); err != nil {
 		logger.Warn("Failed to cleanup data directory", map[string]interface{}{
@@ -369,6 +439,9 @@ func (m *Manager) ensureRepositoryReadOnly() error {
 	}
 
 	if _, err := os.Stat(m.repoPath); os.IsNotExist(err) {
+		if m.restoreFromWorkspaceStore() {
+			return nil
+		}
 		logger.Info("Repository directory doesn't exist, cloning for read-only access", map[string]interface{}{
 			"repo_path": m.repoPath,
 		})
@@ -390,6 +463,119 @@ func (m *Manager) ensureRepositoryReadOnly() error {
 	return nil
 }
 
+// cloneLocks serializes setting up the clone at each repository path, path -> *sync.Mutex
+var cloneLocks sync.Map
+
+// lockClone locks the clone at repoPath and returns the unlock function
+func lockClone(repoPath string) func() {
+	mu, _ := cloneLocks.LoadOrStore(repoPath, &sync.Mutex{})
+	mu.(*sync.Mutex).Lock()
+	return mu.(*sync.Mutex).Unlock
+}
+
+// lockWorktree acquires the repository lock of the clone and returns the unlock function:
+// exclusively to pull, reset, write, commit or push, shared to only read it. File locks must be
+// acquired before it (see FileLockManager.AcquireRepoLock).
+func (m *Manager) lockWorktree(exclusive bool) (func(), error) {
+	repoURL := m.repoPath
+	if m.cfg != nil && m.cfg.GitHubRepo != "" {
+		repoURL = m.cfg.GitHubRepo
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	handle, err := GetFileLockManager().AcquireRepoLock(ctx, m.getUserIDForLocking(), repoURL, exclusive)
+	if err != nil {
+		return nil, err
+	}
+	return handle.Release, nil
+}
+
+// syncWorktree pulls the latest changes holding the repository lock exclusively, for reads that
+// then hold it shared
+func (m *Manager) syncWorktree() error {
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return m.pullLatest()
+}
+
+// Fetch downloads new commits of an existing clone without touching its worktree, so the pull
+// before the next commit has little left to do. Repositories that aren't cloned are skipped.
+func (m *Manager) Fetch() error {
+	if m.NeedsClone() {
+		return nil
+	}
+	if err := m.ensureRepositoryReadOnly(); err != nil {
+		return err
+	}
+
+	// Fetching updates the refs a concurrent pull reads
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
+	auth := &githttp.BasicAuth{
+		Username: m.cfg.GitHubUsername,
+		Password: m.cfg.GitHubToken,
+	}
+
+	fetched := watchdog.Track(watchdog.Git, "fetch", m.chatID)
+	err = m.repo.Fetch(&git.FetchOptions{
+		RemoteName: "origin",
+		Auth:       auth,
+	})
+	fetched()
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to fetch: %w", err)
+	}
+	return nil
+}
+
+// DataDirectorySize returns the disk space used by all local clones
+func DataDirectorySize() (int64, error) {
+	if _, err := os.Stat("./data"); os.IsNotExist(err) {
+		return 0, nil
+	}
+	return getDirectorySize("./data")
+}
+
+// restoreFromWorkspaceStore restores the working copy from object storage (if configured)
+// and brings it up to date with the remote. Returns false if a regular clone is needed.
+func (m *Manager) restoreFromWorkspaceStore() bool {
+	if !restoreWorkspace(m.repoPath) {
+		return false
+	}
+
+	repo, err := git.PlainOpen(m.repoPath)
+	if err != nil {
+		logger.Warn("Restored workspace is not a valid repository, falling back to clone", map[string]interface{}{
+			"repo_path": m.repoPath,
+			"error":     err.Error(),
+		})
+		os.RemoveAll(m.repoPath)
+		return false
+	}
+	m.repo = repo
+
+	// The snapshot may be older than the remote, catch up before use
+	if err := m.pullLatest(); err != nil {
+		logger.Warn("Failed to sync restored workspace, falling back to clone", map[string]interface{}{
+			"repo_path": m.repoPath,
+			"error":     err.Error(),
+		})
+		m.repo = nil
+		os.RemoveAll(m.repoPath)
+		return false
+	}
+
+	return true
+}
+
 // GitHubRepo represents the repository information from GitHub API
 type GitHubRepo struct {
 	Size int `json:"size"` // Size in KB
@@ -415,8 +601,9 @@ func (m *Manager) cloneRepositoryWithPremium(premiumLevel int) error {
 	}
 	maxSizeBytes := int64(maxSizeMB * 1024 * 1024)
 
-	// Pre-clone size check
-	if remoteSizeBytes > maxSizeBytes {
+	// Pre-clone size check. GitHub's size includes the history, which a shallow clone leaves
+	// behind, so shallow clones rely on the post-clone check of their content instead
+	if remoteSizeBytes > maxSizeBytes && !m.shallowClone {
 		remoteSizeMB := float64(remoteSizeBytes) / 1024 / 1024
 		return fmt.Errorf("remote repository size (%.1fMB) exceeds your tier limit (%.1fMB). Upgrade with /coffee to access larger repositories", remoteSizeMB, maxSizeMB)
 	}
@@ -433,10 +620,15 @@ func (m *Manager) cloneRepositoryWithPremium(premiumLevel int) error {
 		Password: m.cfg.GitHubToken,
 	}
 
+	cloned := watchdog.Track(watchdog.Git, "clone", m.chatID)
 	repo, err := git.PlainClone(m.repoPath, false, &git.CloneOptions{
-		URL:  m.cfg.GitHubRepo,
-		Auth: auth,
+		URL:               m.cfg.GitHubRepo,
+		Auth:              auth,
+		RecurseSubmodules: submoduleRecursion(m.cfg.CloneSubmodules),
+		Depth:             m.cloneDepth(),
+		NoCheckout:        m.sparseCheckout,
 	})
+	cloned()
 	if err != nil {
 		if strings.Contains(err.Error(), "remote repository is empty") {
 			return m.initRepository()
@@ -445,9 +637,18 @@ func (m *Manager) cloneRepositoryWithPremium(premiumLevel int) error {
 	}
 
 	m.repo = repo
+	if m.sparseCheckout {
+		if err := m.setUpSparseCheckout(); err != nil {
+			os.RemoveAll(m.repoPath)
+			m.repo = nil
+			return fmt.Errorf("failed to check out repository: %w", err)
+		}
+	}
+	m.logShallowClone()
+	m.snapshotWorkspace(true)
 
 	// Step 3: Double confirmation - check actual cloned size
-	actualSize, err := getDirectorySize(m.repoPath)
+	actualSize, err := m.contentSize()
 	if err != nil {
 		logger.Warn("Failed to check actual cloned size, proceeding anyway", map[string]interface{}{
 			"error": err.Error(),
@@ -459,9 +660,16 @@ func (m *Manager) cloneRepositoryWithPremium(premiumLevel int) error {
 	actualSizeMB := float64(actualSize) / 1024 / 1024
 	remoteSizeMB := float64(remoteSizeBytes) / 1024 / 1024
 
+	// Total disk use (including .git) is only tracked for operators
+	var diskSizeMB float64
+	if diskSize, err := getDirectorySize(m.repoPath); err == nil {
+		diskSizeMB = float64(diskSize) / 1024 / 1024
+	}
+
 	logger.Info("Post-clone size verification", map[string]interface{}{
 		"remote_size_mb": remoteSizeMB,
 		"actual_size_mb": actualSizeMB,
+		"disk_size_mb":   diskSizeMB,
 		"max_size_mb":    maxSizeMB,
 		"size_diff_mb":   actualSizeMB - remoteSizeMB,
 		"premium_level":  premiumLevel,
@@ -517,11 +725,21 @@ func (m *Manager) initRepository() error {
 }
 
 func (m *Manager) CommitFile(filename, content, commitMessage string) error {
+	if err := checkWriteQuota(m.writeQuota); err != nil {
+		return err
+	}
+
 	// Ensure repository is initialized (lazy initialization)
 	if err := m.ensureRepository(); err != nil {
 		return fmt.Errorf("failed to ensure repository: %w", err)
 	}
 
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
 	// Pull latest changes before committing to avoid conflicts
 	logger.Debug("Pulling latest changes before committing file", map[string]interface{}{
 		"filename": filename,
@@ -537,9 +755,7 @@ func (m *Manager) CommitFile(filename, content, commitMessage string) error {
 		}
 	}
 
-	filePath := filepath.Join(m.repoPath, filename)
-
-	if err := m.prependToFile(filePath, content); err != nil {
+	if err := m.prependToFile(filename, content); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -555,6 +771,16 @@ func (m *Manager) CommitFileWithAuthor(filename, content, commitMessage, customA
 }
 
 func (m *Manager) CommitFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
+	_, err := m.CommitFileWithResult(filename, content, commitMessage, customAuthor, premiumLevel)
+	return err
+}
+
+// CommitFileWithResult prepends content to a file and returns details of the created commit
+func (m *Manager) CommitFileWithResult(filename, content, commitMessage, customAuthor string, premiumLevel int) (*CommitResult, error) {
+	if err := checkWriteQuota(m.writeQuota); err != nil {
+		return nil, err
+	}
+
 	// Get user ID for file locking
 	userID := m.getUserIDForLocking()
 	
@@ -566,13 +792,17 @@ func (m *Manager) CommitFileWithAuthorAndPremium(filename, content, commitMessag
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 	
-	return flm.WithFileLock(ctx, userID, repoURL, filename, true, func() error {
-		return m.commitFileWithAuthorAndPremiumLocked(filename, content, commitMessage, customAuthor, premiumLevel)
+	var result *CommitResult
+	err := flm.WithFileLock(ctx, userID, repoURL, filename, true, func() error {
+		var lockedErr error
+		result, lockedErr = m.commitFileWithAuthorAndPremiumLocked(filename, content, commitMessage, customAuthor, premiumLevel)
+		return lockedErr
 	})
+	return result, err
 }
 
 // commitFileWithAuthorAndPremiumLocked performs the actual file commit with the assumption that the file is locked
-func (m *Manager) commitFileWithAuthorAndPremiumLocked(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
+func (m *Manager) commitFileWithAuthorAndPremiumLocked(filename, content, commitMessage, customAuthor string, premiumLevel int) (*CommitResult, error) {
 	logger.Debug("Starting locked file commit", map[string]interface{}{
 		"filename": filename,
 		"author":   customAuthor,
@@ -580,9 +810,15 @@ func (m *Manager) commitFileWithAuthorAndPremiumLocked(filename, content, commit
 
 	// Ensure repository is initialized (lazy initialization)
 	if err := m.ensureRepositoryWithPremium(premiumLevel); err != nil {
-		return fmt.Errorf("failed to ensure repository: %w", err)
+		return nil, fmt.Errorf("failed to ensure repository: %w", err)
 	}
 
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
 	// Pull latest changes before committing to avoid conflicts
 	logger.Debug("Pulling latest changes before committing file with custom author", map[string]interface{}{
 		"filename": filename,
@@ -591,22 +827,21 @@ func (m *Manager) commitFileWithAuthorAndPremiumLocked(filename, content, commit
 	if err := m.pullLatest(); err != nil {
 		// If it's an auth error, return it immediately with helpful message
 		if strings.Contains(err.Error(), "GitHub authorization failed") {
-			return err
+			return nil, err
 		}
 		// For other errors, only fail if it's not an empty repo
 		if !strings.Contains(err.Error(), "remote repository is empty") {
-			return fmt.Errorf("failed to pull latest changes: %w", err)
+			return nil, fmt.Errorf("failed to pull latest changes: %w", err)
 		}
 	}
 
-	filePath := filepath.Join(m.repoPath, filename)
-
-	if err := m.prependToFile(filePath, content); err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+	if err := m.prependToFile(filename, content); err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
 	}
 
-	if err := m.commitAndPushWithAuthor(filename, commitMessage, customAuthor); err != nil {
-		return fmt.Errorf("failed to commit and push: %w", err)
+	hash, err := m.commitAndPushWithAuthor(filename, commitMessage, customAuthor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit and push: %w", err)
 	}
 
 	logger.Info("File committed with file lock", map[string]interface{}{
@@ -614,7 +849,25 @@ func (m *Manager) commitFileWithAuthorAndPremiumLocked(filename, content, commit
 		"author":   customAuthor,
 	})
 
-	return nil
+	return m.commitResult(filename, hash), nil
+}
+
+// commitResult builds the CommitResult for a pushed commit from the local working copy
+func (m *Manager) commitResult(filename, hash string) *CommitResult {
+	result := &CommitResult{
+		Filename: filename,
+		SHA:      hash,
+	}
+
+	if info, err := m.files().Stat(filename); err == nil {
+		result.FileSize = info.Size()
+	}
+
+	if owner, repo, err := m.parseRepoURL(); err == nil {
+		result.URL = fmt.Sprintf("%s/commit/%s", repoWebURL(m.cfg.GitHubRepo, owner, repo), hash)
+	}
+
+	return result
 }
 
 // getUserIDForLocking extracts user ID for file locking
@@ -657,40 +910,59 @@ func (m *Manager) getUserIDForLocking() int64 {
 	return 0 // Default fallback
 }
 
-func (m *Manager) prependToFile(filePath, content string) error {
-	// Ensure parent directories exist
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		return fmt.Errorf("failed to create parent directories: %w", err)
-	}
-
-	// Read existing content if file exists
-	var existingContent []byte
-	if _, err := os.Stat(filePath); err == nil {
-		existingContent, err = ioutil.ReadFile(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to read existing file: %w", err)
+// prependToFile streams the existing content of filename around content, which goes on top or
+// below the entries marker of templated files, so large files aren't loaded into memory
+func (m *Manager) prependToFile(filename, content string) error {
+	existing, err := m.files().Open(filename)
+	if os.IsNotExist(err) {
+		// Write the new file (implemented in worktree_fs.go)
+		if err := m.files().WriteFile(filename, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
 		}
+		return nil
 	}
+	if err != nil {
+		return fmt.Errorf("failed to open existing file: %w", err)
+	}
+	defer existing.Close()
 
-	// Create new content with prepended content
-	newContent := content + string(existingContent)
+	at, err := entriesInsertOffset(existing)
+	if err != nil {
+		return fmt.Errorf("failed to read existing file: %w", err)
+	}
+	if _, err := existing.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read existing file: %w", err)
+	}
 
-	// Write the combined content
-	if err := ioutil.WriteFile(filePath, []byte(newContent), 0644); err != nil {
+	err = m.files().WriteFileFunc(filename, 0644, func(w io.Writer) error {
+		if _, err := io.CopyN(w, existing, at); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, content); err != nil {
+			return err
+		}
+		_, err := io.Copy(w, existing)
+		return err
+	})
+	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
-
 	return nil
 }
 
-func (m *Manager) commitAndPushWithAuthor(filename, commitMessage, customAuthor string) error {
+// commitAndPushWithAuthor commits a single file, pushes it and returns the commit hash
+func (m *Manager) commitAndPushWithAuthor(filename, commitMessage, customAuthor string) (string, error) {
 	worktree, err := m.repo.Worktree()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err := m.verifyWorktree(worktree, filename); err != nil {
+		return "", err
 	}
 
 	if _, err := worktree.Add(filename); err != nil {
-		return fmt.Errorf("failed to add file: %w", err)
+		return "", fmt.Errorf("failed to add file: %w", err)
 	}
 
 	// Use custom author if provided, otherwise use default
@@ -701,26 +973,29 @@ func (m *Manager) commitAndPushWithAuthor(filename, commitMessage, customAuthor
 
 	authorParts := strings.Split(authorString, " <")
 	if len(authorParts) != 2 {
-		return fmt.Errorf("invalid commit author format, expected 'Name <email>'")
+		return "", fmt.Errorf("invalid commit author format, expected 'Name <email>'")
 	}
 
 	name := authorParts[0]
 	email := strings.TrimSuffix(authorParts[1], ">")
 
+	now := time.Now()
 	commit, err := worktree.Commit(commitMessage, &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  name,
 			Email: email,
-			When:  time.Now(),
+			When:  now,
 		},
+		Committer: m.committerSignature(now),
 	})
+	recordGitOperation("commit", metricsProviderClone, now, err)
 	if err != nil {
-		return fmt.Errorf("failed to commit: %w", err)
+		return "", fmt.Errorf("failed to commit: %w", err)
 	}
 
 	obj, err := m.repo.CommitObject(commit)
 	if err != nil {
-		return fmt.Errorf("failed to get commit object: %w", err)
+		return "", fmt.Errorf("failed to get commit object: %w", err)
 	}
 
 	logger.Info("Commit created with custom author", map[string]interface{}{
@@ -728,15 +1003,18 @@ func (m *Manager) commitAndPushWithAuthor(filename, commitMessage, customAuthor
 		"author": authorString,
 	})
 
+	// Never push stray files or broken notes (implemented in push_check.go)
+	if err := m.checkCommitBeforePush(worktree, obj.Hash, filename); err != nil {
+		return "", err
+	}
+
 	auth := &githttp.BasicAuth{
 		Username: m.cfg.GitHubUsername,
 		Password: m.cfg.GitHubToken,
 	}
 
-	if err := m.repo.Push(&git.PushOptions{
-		Auth: auth,
-	}); err != nil {
-		return fmt.Errorf("failed to push: %w", err)
+	if err := m.push(auth); err != nil {
+		return "", err
 	}
 
 	logger.Info("Changes pushed to repository", map[string]interface{}{
@@ -744,7 +1022,9 @@ func (m *Manager) commitAndPushWithAuthor(filename, commitMessage, customAuthor
 		"author":   authorString,
 	})
 
-	return nil
+	m.snapshotWorkspace(false)
+
+	return obj.Hash.String(), nil
 }
 
 func (m *Manager) commitAndPush(filename, commitMessage string) error {
@@ -753,6 +1033,10 @@ func (m *Manager) commitAndPush(filename, commitMessage string) error {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
+	if err := m.verifyWorktree(worktree, filename); err != nil {
+		return err
+	}
+
 	if _, err := worktree.Add(filename); err != nil {
 		return fmt.Errorf("failed to add file: %w", err)
 	}
@@ -765,13 +1049,16 @@ func (m *Manager) commitAndPush(filename, commitMessage string) error {
 	name := authorParts[0]
 	email := strings.TrimSuffix(authorParts[1], ">")
 
+	now := time.Now()
 	commit, err := worktree.Commit(commitMessage, &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  name,
 			Email: email,
-			When:  time.Now(),
+			When:  now,
 		},
+		Committer: m.committerSignature(now),
 	})
+	recordGitOperation("commit", metricsProviderClone, now, err)
 	if err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
@@ -785,32 +1072,80 @@ func (m *Manager) commitAndPush(filename, commitMessage string) error {
 		"hash": obj.Hash.String(),
 	})
 
+	// Never push stray files or broken notes (implemented in push_check.go)
+	if err := m.checkCommitBeforePush(worktree, obj.Hash, filename); err != nil {
+		return err
+	}
+
 	auth := &githttp.BasicAuth{
 		Username: m.cfg.GitHubUsername,
 		Password: m.cfg.GitHubToken,
 	}
 
-	if err := m.repo.Push(&git.PushOptions{
-		Auth: auth,
-	}); err != nil {
-		return fmt.Errorf("failed to push: %w", err)
+	if err := m.push(auth); err != nil {
+		return err
 	}
 
+	m.snapshotWorkspace(false)
+
 	return nil
 }
 
-// pullLatest fetches and resets to remote HEAD to ensure local repo is in sync
+// push pushes committed changes. A push rejected because the remote moved on is tried again
+// after replaying the commits onto it, other failures queue a retry (implemented in push_queue.go)
+// and return ErrPushQueued, since the commit is kept.
+func (m *Manager) push(auth *githttp.BasicAuth) error {
+	err := m.pushHead(auth)
+	if isRejectedPush(err) {
+		if pullErr := m.pullLatest(); pullErr == nil {
+			err = m.pushHead(auth)
+		}
+	}
+	if err == git.NoErrAlreadyUpToDate {
+		err = nil
+	}
+	if err != nil {
+		if m.queuePush(err) {
+			return fmt.Errorf("%w: %w", ErrPushQueued, err)
+		}
+		return fmt.Errorf("failed to push: %w", err)
+	}
+	m.dequeuePush()
+	return nil
+}
+
+// pushHead pushes the checked out branch, timed by the watchdog and recorded in the metrics
+func (m *Manager) pushHead(auth *githttp.BasicAuth) (err error) {
+	defer watchdog.Track(watchdog.Git, "push", m.chatID)()
+	defer func(start time.Time) { recordGitOperation("push", metricsProviderClone, start, err) }(time.Now())
+	options := &git.PushOptions{
+		Auth: auth,
+	}
+	// Push only the checked out branch, the clone may hold branches of other users
+	if head, err := m.repo.Head(); err == nil && head.Name().IsBranch() {
+		options.RefSpecs = []config.RefSpec{config.RefSpec(head.Name().String() + ":" + head.Name().String())}
+	}
+	return m.repo.Push(options)
+}
+
+// pullLatest fetches the remote and brings the local branch up to date with it, replaying local
+// commits that are not pushed yet onto the remote HEAD
 func (m *Manager) pullLatest() error {
+	// Replayed commits are written by git as well
+	defer m.worktreeChanged()
+
 	auth := &githttp.BasicAuth{
 		Username: m.cfg.GitHubUsername,
 		Password: m.cfg.GitHubToken,
 	}
 
 	// First, fetch the latest changes
+	fetched := watchdog.Track(watchdog.Git, "fetch", m.chatID)
 	err := m.repo.Fetch(&git.FetchOptions{
 		RemoteName: "origin",
 		Auth:       auth,
 	})
+	fetched()
 	if err != nil && err != git.NoErrAlreadyUpToDate {
 		// Handle various scenarios where fetch might fail but we can continue
 		if strings.Contains(err.Error(), "couldn't find remote ref") ||
@@ -829,9 +1164,16 @@ func (m *Manager) pullLatest() error {
 
 		return fmt.Errorf("failed to fetch: %w", err)
 	}
+	upToDate := err == git.NoErrAlreadyUpToDate
+
+	// The shared clone may be on another user's branch (implemented in branches.go)
+	switched, err := m.checkoutBranch()
+	if err != nil {
+		return err
+	}
 
 	// If fetch was successful, try to rebase
-	if err == git.NoErrAlreadyUpToDate {
+	if upToDate && !switched {
 		return nil // Already up to date, no need to rebase
 	}
 
@@ -851,7 +1193,9 @@ func (m *Manager) pullLatest() error {
 	remoteRef, err := m.repo.Reference(plumbing.NewRemoteReferenceName("origin", head.Name().Short()), true)
 	if err != nil {
 		// Try with master if main doesn't exist
-		if head.Name().Short() == "main" {
+		if m.branch != "" {
+			return nil // A new branch, created on GitHub by the next push
+		} else if head.Name().Short() == "main" {
 			remoteRef, err = m.repo.Reference(plumbing.NewRemoteReferenceName("origin", "master"), true)
 			if err != nil {
 				return fmt.Errorf("failed to find remote branch: %w", err)
@@ -861,8 +1205,6 @@ func (m *Manager) pullLatest() error {
 		}
 	}
 
-	// Simple approach: if there are remote changes, reset to remote HEAD to avoid conflicts
-	// This is safer for automation since we always want remote to win
 	currentCommit, err := m.repo.CommitObject(head.Hash())
 	if err != nil {
 		return fmt.Errorf("failed to get current commit: %w", err)
@@ -873,34 +1215,14 @@ func (m *Manager) pullLatest() error {
 		return fmt.Errorf("failed to get remote commit: %w", err)
 	}
 
-	// If remote is ahead, reset to remote HEAD
-	if currentCommit.Hash != remoteCommit.Hash {
-		logger.Info("Remote has changes, resetting local to remote HEAD", map[string]interface{}{
-			"local_commit":  currentCommit.Hash.String()[:8],
-			"remote_commit": remoteCommit.Hash.String()[:8],
-		})
-
-		err = worktree.Reset(&git.ResetOptions{
-			Commit: remoteRef.Hash(),
-			Mode:   git.HardReset,
-		})
-		if err != nil {
-			return fmt.Errorf("failed to reset to remote HEAD: %w", err)
-		}
-
-		logger.Info("Successfully synchronized with remote repository", map[string]interface{}{
-			"reset_to": remoteCommit.Hash.String()[:8],
-		})
-	} else {
-		logger.Debug("Local repository is already up to date with remote", nil)
-	}
-
-	return nil
+	// Keep local commits whose push failed (implemented in pull_merge.go)
+	return m.integrateRemote(worktree, currentCommit, remoteCommit)
 }
 
 type IssueRequest struct {
-	Title string `json:"title"`
-	Body  string `json:"body"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
 }
 
 type IssueResponse struct {
@@ -909,6 +1231,11 @@ type IssueResponse struct {
 }
 
 func (m *Manager) CreateIssue(title, body string) (string, int, error) {
+	return m.CreateIssueWithLabels(title, body, nil)
+}
+
+// CreateIssueWithLabels creates an issue with labels, GitHub creates labels missing in the repository
+func (m *Manager) CreateIssueWithLabels(title, body string, labels []string) (string, int, error) {
 	// Extract owner and repo from GitHub repo URL
 	owner, repo, err := m.parseRepoURL()
 	if err != nil {
@@ -916,8 +1243,9 @@ func (m *Manager) CreateIssue(title, body string) (string, int, error) {
 	}
 
 	logger.Info("Creating issue", map[string]interface{}{
-		"repo":  fmt.Sprintf("%s/%s", owner, repo),
-		"title": title,
+		"repo":   fmt.Sprintf("%s/%s", owner, repo),
+		"title":  title,
+		"labels": labels,
 	})
 
 	// Validate GitHub token format
@@ -932,8 +1260,9 @@ func (m *Manager) CreateIssue(title, body string) (string, int, error) {
 
 	// Create issue request
 	issueReq := IssueRequest{
-		Title: title,
-		Body:  body,
+		Title:  title,
+		Body:   body,
+		Labels: labels,
 	}
 
 	jsonData, err := json.Marshal(issueReq)
@@ -942,7 +1271,7 @@ func (m *Manager) CreateIssue(title, body string) (string, int, error) {
 	}
 
 	// Create HTTP request to GitHub API
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues", m.apiBaseURL(), owner, repo)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to create request: %w", err)
@@ -954,7 +1283,7 @@ func (m *Manager) CreateIssue(title, body string) (string, int, error) {
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
 	// Send request
-	client := &http.Client{}
+	client := NewHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", 0, fmt.Errorf("failed to send request: %w", err)
@@ -962,7 +1291,7 @@ func (m *Manager) CreateIssue(title, body string) (string, int, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		bodyBytes, _ := io.ReadAll(resp.Body)
 		logger.Error("GitHub API error", map[string]interface{}{
 			"status":   resp.StatusCode,
 			"response": string(bodyBytes),
@@ -995,6 +1324,7 @@ func (m *Manager) parseRepoURL() (owner, repo string, err error) {
 	// Support both HTTPS and SSH URLs
 	// HTTPS: https://github.com/owner/repo.git
 	// SSH: git@github.com:owner/repo.git
+	// GitHub Enterprise: https://github.example.com/owner/repo
 
 	repoURL := m.cfg.GitHubRepo
 
@@ -1025,7 +1355,8 @@ func (m *Manager) parseRepoURL() (owner, repo string, err error) {
 	re := regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+)`)
 	matches := re.FindStringSubmatch(repoURL)
 	if len(matches) != 3 {
-		return "", "", fmt.Errorf("unsupported repository URL format: %s", repoURL)
+		// GitHub Enterprise Server repositories live on other hosts
+		return parseOwnerRepo(repoURL)
 	}
 
 	return matches[1], matches[2], nil
@@ -1041,6 +1372,7 @@ type IssueStatus struct {
 	State       string                 `json:"state"` // "open" or "closed"
 	HTMLURL     string                 `json:"html_url"`
 	PullRequest map[string]interface{} `json:"pull_request,omitempty"` // Present if this is a PR
+	ClosedAt    *time.Time             `json:"closed_at,omitempty"`    // Set once the issue is closed
 }
 
 func (m *Manager) GetIssueStatus(issueNumber int) (*IssueStatus, error) {
@@ -1050,7 +1382,7 @@ func (m *Manager) GetIssueStatus(issueNumber int) (*IssueStatus, error) {
 	}
 
 	// Create HTTP request to GitHub API
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d", owner, repo, issueNumber)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", m.apiBaseURL(), owner, repo, issueNumber)
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -1061,7 +1393,7 @@ func (m *Manager) GetIssueStatus(issueNumber int) (*IssueStatus, error) {
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
 	// Send request
-	client := &http.Client{}
+	client := NewHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
@@ -1072,7 +1404,7 @@ func (m *Manager) GetIssueStatus(issueNumber int) (*IssueStatus, error) {
 		if resp.StatusCode == 404 {
 			return nil, fmt.Errorf("issue #%d not found", issueNumber)
 		}
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		bodyBytes, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
@@ -1135,7 +1467,7 @@ func (m *Manager) AddIssueComment(issueNumber int, commentText string) (string,
 	}
 
 	// Make API request to add comment
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d/comments", owner, repo, issueNumber)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d/comments", m.apiBaseURL(), owner, repo, issueNumber)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(commentBodyJSON))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
@@ -1146,7 +1478,7 @@ func (m *Manager) AddIssueComment(issueNumber int, commentText string) (string,
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "msg2git-telegram-bot")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to send request: %w", err)
@@ -1205,7 +1537,7 @@ func (m *Manager) CloseIssue(issueNumber int) error {
 	}
 
 	// Make API request to close issue
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d", owner, repo, issueNumber)
+	url := fmt.Sprintf("%s/repos/%s/%s/issues/%d", m.apiBaseURL(), owner, repo, issueNumber)
 	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(closeBodyJSON))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
@@ -1216,7 +1548,7 @@ func (m *Manager) CloseIssue(issueNumber int) error {
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 	req.Header.Set("User-Agent", "msg2git-telegram-bot")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
@@ -1255,11 +1587,24 @@ func (m *Manager) getBatchIssueStatuses(issueNumbers []int) (map[int]*IssueStatu
 	// Try to use GraphQL for efficient batch fetching
 	statuses, err := m.fetchIssuesViaGraphQL(owner, repo, issueNumbers)
 	if err != nil {
-		logger.Debug("GraphQL batch fetch failed", map[string]interface{}{
-			"error": err.Error(),
+		missing := missingIssueNumbers(issueNumbers, statuses)
+		logger.Debug("GraphQL batch fetch failed, falling back to REST issue list", map[string]interface{}{
+			"error":         err.Error(),
+			"fetched_count": len(statuses),
+			"missing_count": len(missing),
 		})
-		// Return a user-friendly error instead of fallback to inefficient individual calls
-		return nil, fmt.Errorf("unable to fetch issue statuses efficiently. This may be due to API limitations or network issues. Please try again later")
+
+		restStatuses, restErr := m.fetchIssuesViaREST(owner, repo, missing)
+		for number, status := range restStatuses {
+			statuses[number] = status
+		}
+		if restErr != nil && len(statuses) == 0 {
+			logger.Debug("REST issue list fallback failed", map[string]interface{}{
+				"error": restErr.Error(),
+			})
+			// Return a user-friendly error instead of fallback to inefficient individual calls
+			return nil, fmt.Errorf("unable to fetch issue statuses efficiently. This may be due to API limitations or network issues. Please try again later")
+		}
 	}
 
 	logger.Debug("Batch fetch completed successfully", map[string]interface{}{
@@ -1281,7 +1626,7 @@ func (m *Manager) fetchIssuesPage(url string) ([]IssueStatus, string, error) {
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
-	client := &http.Client{}
+	client := NewHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to send request: %w", err)
@@ -1289,7 +1634,7 @@ func (m *Manager) fetchIssuesPage(url string) ([]IssueStatus, string, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		bodyBytes, _ := io.ReadAll(resp.Body)
 		return nil, "", fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
@@ -1322,8 +1667,11 @@ func (m *Manager) fetchIssuesPage(url string) ([]IssueStatus, string, error) {
 	return issues, nextURL, nil
 }
 
-// fetchIssuesViaGraphQL fetches specific issues using GitHub's GraphQL API
-func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int) (map[int]*IssueStatus, error) {
+// fetchIssuesGraphQLBatch fetches one batch of specific issues using GitHub's GraphQL API,
+// along with the rate limit state reported in the response headers
+func (m *Manager) fetchIssuesGraphQLBatch(owner, repo string, issueNumbers []int) (map[int]*IssueStatus, graphQLRateLimit, error) {
+	var rateLimit graphQLRateLimit
+
 	// Build GraphQL query to fetch specific issues by number
 	// GraphQL allows us to fetch multiple specific issues in a single request
 	var queryParts []string
@@ -1334,6 +1682,7 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 		    title
 		    state
 		    url
+		    closedAt
 		  }`, i, num))
 	}
 
@@ -1358,7 +1707,7 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 
 	jsonData, err := json.Marshal(requestBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal GraphQL query: %w", err)
+		return nil, rateLimit, fmt.Errorf("failed to marshal GraphQL query: %w", err)
 	}
 
 	logger.Debug("GraphQL request body", map[string]interface{}{
@@ -1366,24 +1715,27 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 	})
 
 	// Send GraphQL request
-	req, err := http.NewRequest("POST", "https://api.github.com/graphql", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequest("POST", m.apiBaseURL()+"/graphql", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+		return nil, rateLimit, fmt.Errorf("failed to create GraphQL request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+m.cfg.GitHubToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("GraphQL request failed: %w", err)
+		return nil, rateLimit, fmt.Errorf("GraphQL request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
+	rateLimit = parseGraphQLRateLimit(resp.Header)
+	recordGraphQLRateLimit(graphQLBudgetKey(m.apiBaseURL(), m.cfg.GitHubToken), rateLimit)
+
 	if resp.StatusCode != http.StatusOK {
-		body, _ := ioutil.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GraphQL request failed with status %d: %s", resp.StatusCode, string(body))
+		body, _ := io.ReadAll(resp.Body)
+		return nil, rateLimit, fmt.Errorf("GraphQL request failed with status %d: %s", resp.StatusCode, string(body))
 	}
 
 	// Parse GraphQL response
@@ -1392,13 +1744,14 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 			Repository map[string]interface{} `json:"repository"`
 		} `json:"data"`
 		Errors []struct {
+			Type    string `json:"type"`
 			Message string `json:"message"`
 		} `json:"errors"`
 	}
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read GraphQL response: %w", err)
+		return nil, rateLimit, fmt.Errorf("failed to read GraphQL response: %w", err)
 	}
 
 	logger.Debug("GraphQL raw response", map[string]interface{}{
@@ -1406,14 +1759,17 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 	})
 
 	if err := json.Unmarshal(body, &response); err != nil {
-		return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
+		return nil, rateLimit, fmt.Errorf("failed to parse GraphQL response: %w", err)
 	}
 
-	if len(response.Errors) > 0 {
-		logger.Error("GraphQL returned errors", map[string]interface{}{
-			"errors": response.Errors,
-		})
-		return nil, fmt.Errorf("GraphQL errors: %v", response.Errors)
+	// Deleted or transferred issues come back as NOT_FOUND errors next to the other issues' data
+	for _, graphQLErr := range response.Errors {
+		if graphQLErr.Type != "NOT_FOUND" || response.Data.Repository == nil {
+			logger.Error("GraphQL returned errors", map[string]interface{}{
+				"errors": response.Errors,
+			})
+			return nil, rateLimit, fmt.Errorf("GraphQL errors: %v", response.Errors)
+		}
 	}
 
 	// Convert GraphQL response to our IssueStatus format
@@ -1430,7 +1786,7 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 			"owner": owner,
 			"repo":  repo,
 		})
-		return nil, fmt.Errorf("GraphQL returned no repository data - check repository name and token permissions")
+		return nil, rateLimit, fmt.Errorf("GraphQL returned no repository data - check repository name and token permissions")
 	}
 
 	// Parse each issue from the repository response
@@ -1465,13 +1821,20 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 				if urlVal, ok := issueMap["url"].(string); ok {
 					url = urlVal
 				}
+				var closedAt *time.Time
+				if closedVal, ok := issueMap["closedAt"].(string); ok {
+					if parsed, err := time.Parse(time.RFC3339, closedVal); err == nil {
+						closedAt = &parsed
+					}
+				}
 
 				if number > 0 {
 					statuses[number] = &IssueStatus{
-						Number:  number,
-						Title:   title,
-						State:   state,
-						HTMLURL: url,
+						Number:   number,
+						Title:    title,
+						State:    state,
+						HTMLURL:  url,
+						ClosedAt: closedAt,
 					}
 
 					logger.Debug("Parsed GraphQL issue", map[string]interface{}{
@@ -1502,7 +1865,7 @@ func (m *Manager) fetchIssuesViaGraphQL(owner, repo string, issueNumbers []int)
 		"requested_count": len(issueNumbers),
 	})
 
-	return statuses, nil
+	return statuses, rateLimit, nil
 }
 
 // getKeys is a helper function to extract keys from a map for debugging
@@ -1527,7 +1890,7 @@ func (m *Manager) ReadFile(filename string) (string, error) {
 	logger.Debug("Pulling latest changes before reading file", map[string]interface{}{
 		"filename": filename,
 	})
-	if err := m.pullLatest(); err != nil {
+	if err := m.syncWorktree(); err != nil {
 		// Log warning but don't fail the read operation for pull errors
 		logger.Warn("Failed to pull latest changes before reading file", map[string]interface{}{
 			"error":    err.Error(),
@@ -1535,15 +1898,19 @@ func (m *Manager) ReadFile(filename string) (string, error) {
 		})
 	}
 
-	filePath := filepath.Join(m.repoPath, filename)
+	unlock, err := m.lockWorktree(false)
+	if err != nil {
+		return "", fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
 
 	// Check if file exists
-	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+	if _, err := m.files().Stat(filename); os.IsNotExist(err) {
 		return "", fmt.Errorf("file %s does not exist", filename)
 	}
 
 	// Read file contents
-	content, err := ioutil.ReadFile(filePath)
+	content, err := m.files().ReadFile(filename)
 	if err != nil {
 		return "", fmt.Errorf("failed to read file %s: %w", filename, err)
 	}
@@ -1551,12 +1918,73 @@ func (m *Manager) ReadFile(filename string) (string, error) {
 	return string(content), nil
 }
 
+// ListDirectory lists the files and folders at path in the local working copy
+func (m *Manager) ListDirectory(path string) ([]DirectoryEntry, error) {
+	if err := m.ensureRepositoryReadOnly(); err != nil {
+		return nil, fmt.Errorf("failed to ensure repository: %w", err)
+	}
+
+	if err := m.syncWorktree(); err != nil {
+		logger.Warn("Failed to pull latest changes before listing directory", map[string]interface{}{
+			"error": err.Error(),
+			"path":  path,
+		})
+	}
+
+	unlock, err := m.lockWorktree(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
+	dirEntries, err := m.files().ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list directory %s: %w", path, err)
+	}
+
+	var entries []DirectoryEntry
+	for _, entry := range dirEntries {
+		if entry.Name() == ".git" {
+			continue
+		}
+
+		entryPath := entry.Name()
+		if path != "" {
+			entryPath = strings.TrimSuffix(path, "/") + "/" + entry.Name()
+		}
+
+		dirEntry := DirectoryEntry{
+			Name: entry.Name(),
+			Path: entryPath,
+			Type: "file",
+		}
+		if entry.IsDir() {
+			dirEntry.Type = "dir"
+		} else if info, err := entry.Info(); err == nil {
+			dirEntry.Size = info.Size()
+		}
+		entries = append(entries, dirEntry)
+	}
+
+	return entries, nil
+}
+
 func (m *Manager) ReplaceFile(filename, content, commitMessage string) error {
+	if err := checkWriteQuota(m.writeQuota); err != nil {
+		return err
+	}
+
 	// Ensure repository is initialized (lazy initialization)
 	if err := m.ensureRepositoryWithPremium(m.premiumLevel); err != nil {
 		return fmt.Errorf("failed to ensure repository: %w", err)
 	}
 
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
 	// Pull latest changes before replacing file to avoid conflicts
 	logger.Debug("Pulling latest changes before replacing file", map[string]interface{}{
 		"filename": filename,
@@ -1567,15 +1995,8 @@ func (m *Manager) ReplaceFile(filename, content, commitMessage string) error {
 		}
 	}
 
-	filePath := filepath.Join(m.repoPath, filename)
-
-	// Ensure parent directories exist
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		return fmt.Errorf("failed to create parent directories: %w", err)
-	}
-
 	// Write the new content (completely replace the file)
-	if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+	if err := m.files().WriteFile(filename, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -1591,6 +2012,10 @@ func (m *Manager) ReplaceFileWithAuthor(filename, content, commitMessage, custom
 }
 
 func (m *Manager) ReplaceFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
+	if err := checkWriteQuota(m.writeQuota); err != nil {
+		return err
+	}
+
 	// Get user ID for file locking
 	userID := m.getUserIDForLocking()
 	
@@ -1619,6 +2044,12 @@ func (m *Manager) replaceFileWithAuthorAndPremiumLocked(filename, content, commi
 		return fmt.Errorf("failed to ensure repository: %w", err)
 	}
 
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
 	// Pull latest changes before replacing file to avoid conflicts
 	logger.Debug("Pulling latest changes before replacing file with custom author", map[string]interface{}{
 		"filename": filename,
@@ -1630,19 +2061,12 @@ func (m *Manager) replaceFileWithAuthorAndPremiumLocked(filename, content, commi
 		}
 	}
 
-	filePath := filepath.Join(m.repoPath, filename)
-
-	// Ensure parent directories exist
-	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-		return fmt.Errorf("failed to create parent directories: %w", err)
-	}
-
 	// Write the new content (completely replace the file)
-	if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+	if err := m.files().WriteFile(filename, []byte(content), 0644); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	if err := m.commitAndPushWithAuthor(filename, commitMessage, customAuthor); err != nil {
+	if _, err := m.commitAndPushWithAuthor(filename, commitMessage, customAuthor); err != nil {
 		return fmt.Errorf("failed to commit and push: %w", err)
 	}
 
@@ -1654,8 +2078,121 @@ func (m *Manager) replaceFileWithAuthorAndPremiumLocked(filename, content, commi
 	return nil
 }
 
+// MoveFile renames a file in the repository in a single commit
+func (m *Manager) MoveFile(oldPath, newPath, commitMessage, customAuthor string) error {
+	userID := m.getUserIDForLocking()
+	repoURL := m.cfg.GitHubRepo
+
+	flm := GetFileLockManager()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	// Lock both paths in a deterministic order to prevent deadlocks
+	paths := []string{oldPath, newPath}
+	sort.Strings(paths)
+	for _, p := range paths {
+		handle, err := flm.AcquireFileLock(ctx, userID, repoURL, p, true)
+		if err != nil {
+			return fmt.Errorf("failed to acquire lock for file %s: %w", p, err)
+		}
+		defer handle.Release()
+	}
+
+	if err := m.ensureRepositoryWithPremium(m.premiumLevel); err != nil {
+		return fmt.Errorf("failed to ensure repository: %w", err)
+	}
+
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
+	if err := m.pullLatest(); err != nil {
+		if !strings.Contains(err.Error(), "remote repository is empty") {
+			return fmt.Errorf("failed to pull latest changes: %w", err)
+		}
+	}
+
+	if _, err := m.files().Stat(oldPath); err != nil {
+		return fmt.Errorf("file %s does not exist", oldPath)
+	}
+	if _, err := m.files().Stat(newPath); err == nil {
+		return fmt.Errorf("file %s already exists", newPath)
+	}
+
+	if err := m.files().MkdirAll(path.Dir(newPath), 0755); err != nil {
+		return fmt.Errorf("failed to create parent directories: %w", err)
+	}
+	if err := m.files().Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to move file: %w", err)
+	}
+
+	// Adding the old path stages its deletion
+	files := map[string]string{oldPath: "", newPath: ""}
+	if err := m.commitMultipleFilesAndPushWithAuthor(files, commitMessage, customAuthor); err != nil {
+		return fmt.Errorf("failed to commit and push: %w", err)
+	}
+
+	logger.Info("File moved", map[string]interface{}{
+		"old_path": oldPath,
+		"new_path": newPath,
+	})
+
+	return nil
+}
+
+// DeleteFile removes a file from the repository
+func (m *Manager) DeleteFile(filename, commitMessage, customAuthor string) error {
+	userID := m.getUserIDForLocking()
+	repoURL := m.cfg.GitHubRepo
+
+	flm := GetFileLockManager()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	return flm.WithFileLock(ctx, userID, repoURL, filename, true, func() error {
+		if err := m.ensureRepositoryWithPremium(m.premiumLevel); err != nil {
+			return fmt.Errorf("failed to ensure repository: %w", err)
+		}
+
+		unlock, err := m.lockWorktree(true)
+		if err != nil {
+			return fmt.Errorf("failed to lock repository: %w", err)
+		}
+		defer unlock()
+
+		if err := m.pullLatest(); err != nil {
+			if !strings.Contains(err.Error(), "remote repository is empty") {
+				return fmt.Errorf("failed to pull latest changes: %w", err)
+			}
+		}
+
+		if err := m.files().Remove(filename); err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("file %s does not exist", filename)
+			}
+			return fmt.Errorf("failed to delete file: %w", err)
+		}
+
+		if _, err := m.commitAndPushWithAuthor(filename, commitMessage, customAuthor); err != nil {
+			return fmt.Errorf("failed to commit and push: %w", err)
+		}
+
+		logger.Info("File deleted", map[string]interface{}{
+			"filename": filename,
+		})
+
+		return nil
+	})
+}
+
 // ReplaceMultipleFilesWithAuthorAndPremium replaces multiple files in a single commit
 func (m *Manager) ReplaceMultipleFilesWithAuthorAndPremium(files map[string]string, commitMessage, customAuthor string, premiumLevel int) error {
+	if err := checkWriteQuota(m.writeQuota); err != nil {
+		return err
+	}
+
 	// Get user ID for file locking
 	userID := m.getUserIDForLocking()
 	
@@ -1723,6 +2260,12 @@ func (m *Manager) replaceMultipleFilesWithAuthorAndPremiumLocked(files map[strin
 		return fmt.Errorf("failed to ensure repository: %w", err)
 	}
 
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
 	// Pull latest changes before replacing files to avoid conflicts
 	logger.Debug("Pulling latest changes before replacing multiple files", map[string]interface{}{
 		"file_count": len(files),
@@ -1736,15 +2279,8 @@ func (m *Manager) replaceMultipleFilesWithAuthorAndPremiumLocked(files map[strin
 
 	// Write all files first
 	for filename, content := range files {
-		filePath := filepath.Join(m.repoPath, filename)
-
-		// Ensure parent directories exist
-		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
-			return fmt.Errorf("failed to create parent directories for %s: %w", filename, err)
-		}
-
 		// Write the new content (completely replace the file)
-		if err := ioutil.WriteFile(filePath, []byte(content), 0644); err != nil {
+		if err := m.files().WriteFile(filename, []byte(content), 0644); err != nil {
 			return fmt.Errorf("failed to write file %s: %w", filename, err)
 		}
 	}
@@ -1769,6 +2305,14 @@ func (m *Manager) commitMultipleFilesAndPushWithAuthor(files map[string]string,
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
 
+	filenames := make([]string, 0, len(files))
+	for filename := range files {
+		filenames = append(filenames, filename)
+	}
+	if err := m.verifyWorktree(worktree, filenames...); err != nil {
+		return err
+	}
+
 	// Add all files to the commit
 	for filename := range files {
 		if _, err := worktree.Add(filename); err != nil {
@@ -1790,13 +2334,16 @@ func (m *Manager) commitMultipleFilesAndPushWithAuthor(files map[string]string,
 	name := authorParts[0]
 	email := strings.TrimSuffix(authorParts[1], ">")
 
+	now := time.Now()
 	commit, err := worktree.Commit(commitMessage, &git.CommitOptions{
 		Author: &object.Signature{
 			Name:  name,
 			Email: email,
-			When:  time.Now(),
+			When:  now,
 		},
+		Committer: m.committerSignature(now),
 	})
+	recordGitOperation("commit", metricsProviderClone, now, err)
 	if err != nil {
 		return fmt.Errorf("failed to commit: %w", err)
 	}
@@ -1812,16 +2359,19 @@ func (m *Manager) commitMultipleFilesAndPushWithAuthor(files map[string]string,
 		"author":      name,
 	})
 
+	// Never push stray files or broken notes (implemented in push_check.go)
+	if err := m.checkCommitBeforePush(worktree, obj.Hash, filenames...); err != nil {
+		return err
+	}
+
 	// Push to remote
 	auth := &githttp.BasicAuth{
 		Username: m.cfg.GitHubUsername,
 		Password: m.cfg.GitHubToken,
 	}
 
-	if err := m.repo.Push(&git.PushOptions{
-		Auth: auth,
-	}); err != nil {
-		return fmt.Errorf("failed to push: %w", err)
+	if err := m.push(auth); err != nil {
+		return err
 	}
 
 	logger.Info("Multiple files pushed to repository", map[string]interface{}{
@@ -1829,15 +2379,27 @@ func (m *Manager) commitMultipleFilesAndPushWithAuthor(files map[string]string,
 		"author":     name,
 	})
 
+	m.snapshotWorkspace(false)
+
 	return nil
 }
 
 func (m *Manager) CommitBinaryFile(filename string, data []byte, commitMessage string) error {
+	if err := checkWriteQuota(m.writeQuota); err != nil {
+		return err
+	}
+
 	// Ensure repository is initialized (lazy initialization)
 	if err := m.ensureRepositoryWithPremium(m.premiumLevel); err != nil {
 		return fmt.Errorf("failed to ensure repository: %w", err)
 	}
 
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
 	// Pull latest changes before committing binary file to avoid conflicts
 	logger.Debug("Pulling latest changes before committing binary file", map[string]interface{}{
 		"filename": filename,
@@ -1853,23 +2415,21 @@ func (m *Manager) CommitBinaryFile(filename string, data []byte, commitMessage s
 		}
 	}
 
-	filePath := filepath.Join(m.repoPath, filename)
-
 	// Create directory if it doesn't exist
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
+	dir := path.Dir(filename)
+	if err := m.files().MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create directory %s: %w", dir, err)
 	}
 
-	// Write binary data directly to file
-	if err := ioutil.WriteFile(filePath, data, 0644); err != nil {
+	// Write binary data to file
+	if err := m.files().WriteFile(filename, data, 0644); err != nil {
 		return fmt.Errorf("failed to write binary file: %w", err)
 	}
 
 	logger.Debug("Binary file written to repository", map[string]interface{}{
 		"filename": filename,
 		"size":     len(data),
-		"path":     filePath,
+		"path":     filepath.Join(m.repoPath, filename),
 	})
 
 	if err := m.commitAndPush(filename, commitMessage); err != nil {
@@ -1881,6 +2441,10 @@ func (m *Manager) CommitBinaryFile(filename string, data []byte, commitMessage s
 
 // UploadImageToCDN uploads an image to GitHub releases and returns the CDN URL
 func (m *Manager) UploadImageToCDN(filename string, data []byte) (string, error) {
+	if err := checkWriteQuota(m.writeQuota); err != nil {
+		return "", err
+	}
+
 	// Get repository info
 	owner, repo, err := m.GetRepoInfo()
 	if err != nil {
@@ -1894,7 +2458,7 @@ func (m *Manager) UploadImageToCDN(filename string, data []byte) (string, error)
 	}
 
 	// Upload asset to the release
-	uploadURL := fmt.Sprintf("https://uploads.github.com/repos/%s/%s/releases/%d/assets?name=%s", owner, repo, releaseID, filename)
+	uploadURL := fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets?name=%s", m.uploadsBaseURL(), owner, repo, releaseID, filename)
 
 	// Create HTTP request
 	req, err := http.NewRequest("POST", uploadURL, bytes.NewReader(data))
@@ -1908,7 +2472,7 @@ func (m *Manager) UploadImageToCDN(filename string, data []byte) (string, error)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
 	// Make the request
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to upload image: %w", err)
@@ -1916,7 +2480,7 @@ func (m *Manager) UploadImageToCDN(filename string, data []byte) (string, error)
 	defer resp.Body.Close()
 
 	// Read response
-	respBody, err := ioutil.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response: %w", err)
 	}
@@ -2001,7 +2565,7 @@ func (m *Manager) getOrCreateAssetRelease(owner, repo string) (int, error) {
 // findActiveAssetRelease finds the current active asset release
 func (m *Manager) findActiveAssetRelease(owner, repo string) (int, string, error) {
 	// Get all releases and find the latest asset release
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/releases", m.apiBaseURL(), owner, repo)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -2011,7 +2575,7 @@ func (m *Manager) findActiveAssetRelease(owner, repo string) (int, string, error
 	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := NewHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return 0, "", fmt.Errorf("failed to get releases: %w", err)
@@ -2028,7 +2592,7 @@ func (m *Manager) findActiveAssetRelease(owner, repo string) (int, string, error
 		Name    string `json:"name"`
 	}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return 0, "", fmt.Errorf("failed to read response: %w", err)
 	}
@@ -2058,7 +2622,7 @@ func (m *Manager) findActiveAssetRelease(owner, repo string) (int, string, error
 
 // getAssetCount gets the number of assets in a release
 func (m *Manager) getAssetCount(owner, repo string, releaseID int) (int, error) {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/%d/assets", owner, repo, releaseID)
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets", m.apiBaseURL(), owner, repo, releaseID)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -2068,7 +2632,7 @@ func (m *Manager) getAssetCount(owner, repo string, releaseID int) (int, error)
 	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := NewHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get assets: %w", err)
@@ -2083,7 +2647,7 @@ func (m *Manager) getAssetCount(owner, repo string, releaseID int) (int, error)
 		ID int `json:"id"`
 	}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return 0, fmt.Errorf("failed to read response: %w", err)
 	}
@@ -2098,7 +2662,7 @@ func (m *Manager) getAssetCount(owner, repo string, releaseID int) (int, error)
 // getNextAssetReleaseName determines the next release name to create
 func (m *Manager) getNextAssetReleaseName(owner, repo string) string {
 	// Get all releases to find the highest numbered asset release
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
+	url := fmt.Sprintf("%s/repos/%s/%s/releases", m.apiBaseURL(), owner, repo)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -2108,7 +2672,7 @@ func (m *Manager) getNextAssetReleaseName(owner, repo string) string {
 	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := NewHTTPClient(10 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "assets" // Default to first release
@@ -2123,7 +2687,7 @@ func (m *Manager) getNextAssetReleaseName(owner, repo string) string {
 		TagName string `json:"tag_name"`
 	}
 
-	respBody, err := ioutil.ReadAll(resp.Body)
+	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "assets" // Default to first release
 	}
@@ -2180,7 +2744,7 @@ func (m *Manager) isNewerAssetRelease(release1, release2 string) bool {
 
 // createAssetRelease creates a new asset release
 func (m *Manager) createAssetRelease(owner, repo, releaseName string) (int, error) {
-	createURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", owner, repo)
+	createURL := fmt.Sprintf("%s/repos/%s/%s/releases", m.apiBaseURL(), owner, repo)
 
 	releaseData := map[string]interface{}{
 		"tag_name":   releaseName,
@@ -2204,7 +2768,7 @@ func (m *Manager) createAssetRelease(owner, repo, releaseName string) (int, erro
 	createReq.Header.Set("Accept", "application/vnd.github.v3+json")
 	createReq.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := NewHTTPClient(10 * time.Second)
 	createResp, err := client.Do(createReq)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create release: %w", err)
@@ -2212,7 +2776,7 @@ func (m *Manager) createAssetRelease(owner, repo, releaseName string) (int, erro
 	defer createResp.Body.Close()
 
 	if createResp.StatusCode != http.StatusCreated {
-		createRespBody, _ := ioutil.ReadAll(createResp.Body)
+		createRespBody, _ := io.ReadAll(createResp.Body)
 		return 0, fmt.Errorf("failed to create release, status %d: %s", createResp.StatusCode, string(createRespBody))
 	}
 
@@ -2220,7 +2784,7 @@ func (m *Manager) createAssetRelease(owner, repo, releaseName string) (int, erro
 		ID int `json:"id"`
 	}
 
-	createRespBody, err := ioutil.ReadAll(createResp.Body)
+	createRespBody, err := io.ReadAll(createResp.Body)
 	if err != nil {
 		return 0, fmt.Errorf("failed to read create response: %w", err)
 	}
@@ -2245,8 +2809,8 @@ func (m *Manager) GetRepositorySize() (int64, error) {
 		return m.getRemoteRepositorySize()
 	}
 
-	// Repository exists locally, calculate actual size
-	size, err := getDirectorySize(m.repoPath)
+	// Repository exists locally, calculate actual size (submodules excluded)
+	size, err := m.contentSize()
 	if err != nil {
 		return 0, fmt.Errorf("failed to calculate repository size: %w", err)
 	}
@@ -2254,7 +2818,8 @@ func (m *Manager) GetRepositorySize() (int64, error) {
 	return size, nil
 }
 
-// getRemoteRepositorySize fetches repository size from GitHub API (returns size in bytes)
+// getRemoteRepositorySize fetches repository size from GitHub API (returns size in bytes).
+// Sizes are cached, see remote_size.go.
 func (m *Manager) getRemoteRepositorySize() (int64, error) {
 	// Extract owner and repo name from URL
 	owner, repo, err := m.GetRepoInfo()
@@ -2263,7 +2828,18 @@ func (m *Manager) getRemoteRepositorySize() (int64, error) {
 	}
 
 	// GitHub API endpoint for repository information
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s", m.apiBaseURL(), owner, repo)
+
+	// Reuse a recently fetched size, e.g. from the capacity check of the same operation
+	cacheKey := remoteSizeKey(apiURL, m.cfg.GitHubToken)
+	cached, hasCached := getCachedRemoteSize(cacheKey)
+	if hasCached && cached.isFresh(time.Now()) {
+		logger.Debug("Using cached remote repository size", map[string]interface{}{
+			"repo":       fmt.Sprintf("%s/%s", owner, repo),
+			"size_bytes": cached.sizeBytes,
+		})
+		return cached.sizeBytes, nil
+	}
 
 	// Create HTTP request
 	req, err := http.NewRequest("GET", apiURL, nil)
@@ -2274,15 +2850,29 @@ func (m *Manager) getRemoteRepositorySize() (int64, error) {
 	// Add authorization header
 	req.Header.Set("Authorization", fmt.Sprintf("token %s", m.cfg.GitHubToken))
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	if hasCached && cached.etag != "" {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
 
 	// Make the request
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := NewHTTPClient(30 * time.Second)
 	resp, err := client.Do(req)
 	if err != nil {
 		return 0, fmt.Errorf("failed to call GitHub API: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// The size hasn't changed since it was cached
+	if resp.StatusCode == http.StatusNotModified && hasCached {
+		cached.fetchedAt = time.Now()
+		storeRemoteSize(cacheKey, cached)
+		logger.Debug("Remote repository size revalidated", map[string]interface{}{
+			"repo":       fmt.Sprintf("%s/%s", owner, repo),
+			"size_bytes": cached.sizeBytes,
+		})
+		return cached.sizeBytes, nil
+	}
+
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
 		if resp.StatusCode == http.StatusNotFound {
@@ -2292,7 +2882,7 @@ func (m *Manager) getRemoteRepositorySize() (int64, error) {
 	}
 
 	// Parse response
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return 0, fmt.Errorf("failed to read API response: %w", err)
 	}
@@ -2304,6 +2894,11 @@ func (m *Manager) getRemoteRepositorySize() (int64, error) {
 
 	// GitHub API returns size in KB, convert to bytes
 	sizeBytes := int64(repoInfo.Size * 1024)
+	storeRemoteSize(cacheKey, remoteSizeEntry{
+		sizeBytes: sizeBytes,
+		etag:      resp.Header.Get("ETag"),
+		fetchedAt: time.Now(),
+	})
 
 	logger.Debug("Retrieved remote repository size", map[string]interface{}{
 		"repo":       fmt.Sprintf("%s/%s", owner, repo),
@@ -2379,8 +2974,13 @@ func (m *Manager) GetGitHubFileURL(filename string) (string, error) {
 		return "", fmt.Errorf("failed to get repo info: %w", err)
 	}
 
+	branch := "main"
+	if m.branch != "" {
+		branch = m.branch
+	}
+
 	// Format: https://github.com/owner/repo/blob/main/filename
-	url := fmt.Sprintf("https://github.com/%s/%s/blob/main/%s", owner, repo, filename)
+	url := fmt.Sprintf("%s/blob/%s/%s", repoWebURL(m.cfg.GitHubRepo, owner, repo), branch, filename)
 	return url, nil
 }
 
@@ -2406,15 +3006,8 @@ func (m *Manager) GetDefaultBranch() (string, error) {
 		return "main", nil // Default fallback
 	}
 
-	// Try to get the HEAD reference
-	head, err := m.repo.Head()
-	if err != nil {
-		// Fallback to common branch names
-		return "main", nil
-	}
-
-	// Extract branch name from reference
-	branchName := head.Name().Short()
+	// HEAD may be a user's commit branch, see branches.go
+	branchName := m.defaultBranch()
 	if branchName == "" {
 		return "main", nil
 	}
@@ -2429,16 +3022,56 @@ func (m *Manager) GetGitHubFileURLWithBranch(filename string) (string, error) {
 		return "", fmt.Errorf("failed to get repo info: %w", err)
 	}
 
-	branch, err := m.GetDefaultBranch()
-	if err != nil {
-		branch = "main" // Fallback
+	branch := m.branch
+	if branch == "" {
+		if branch, err = m.GetDefaultBranch(); err != nil {
+			branch = "main" // Fallback
+		}
 	}
 
 	// Format: https://github.com/owner/repo/blob/branch/filename
-	url := fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s", owner, repo, branch, filename)
+	url := fmt.Sprintf("%s/blob/%s/%s", repoWebURL(m.cfg.GitHubRepo, owner, repo), branch, filename)
 	return url, nil
 }
 
+// CreateCommitStatus attaches a status to a commit so bot commits are recognizable on GitHub
+func (m *Manager) CreateCommitStatus(sha string, status *CommitStatus) error {
+	owner, repo, err := m.parseRepoURL()
+	if err != nil {
+		return fmt.Errorf("failed to parse repository URL: %w", err)
+	}
+
+	jsonData, err := json.Marshal(status)
+	if err != nil {
+		return fmt.Errorf("failed to marshal commit status: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", m.apiBaseURL(), owner, repo, sha)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+m.cfg.GitHubToken)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API error (status %d): %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	return nil
+}
+
 // GetProviderType returns the provider type for the Manager
 func (m *Manager) GetProviderType() ProviderType {
 	return ProviderTypeClone
@@ -1,6 +1,9 @@
 package github
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // MockProvider implements GitHubProvider for testing
 type MockProvider struct {
@@ -112,6 +115,13 @@ func (m *MockProvider) GetGitHubFileURL(filename string) (string, error) {
 	return fmt.Sprintf("https://github.com/%s/%s/blob/main/%s", m.repoOwner, m.repoName, filename), nil
 }
 
+func (m *MockProvider) CreateCommitStatus(sha string, status *CommitStatus) error {
+	if m.shouldError {
+		return fmt.Errorf(m.errorMessage)
+	}
+	return nil
+}
+
 func (m *MockProvider) GetGitHubFileURLWithBranch(filename string) (string, error) {
 	if m.shouldError {
 		return "", fmt.Errorf(m.errorMessage)
@@ -144,6 +154,18 @@ func (m *MockProvider) CommitFileWithAuthorAndPremium(filename, content, commitM
 	return nil
 }
 
+func (m *MockProvider) CommitFileWithResult(filename, content, commitMessage, customAuthor string, premiumLevel int) (*CommitResult, error) {
+	if err := m.CommitFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor, premiumLevel); err != nil {
+		return nil, err
+	}
+	return &CommitResult{
+		Filename: filename,
+		SHA:      "0123456789abcdef0123456789abcdef01234567",
+		URL:      "https://github.com/test/repo/commit/0123456789abcdef0123456789abcdef01234567",
+		FileSize: int64(len(m.files[filename])),
+	}, nil
+}
+
 func (m *MockProvider) ReplaceFile(filename, content, commitMessage string) error {
 	if m.shouldError {
 		return fmt.Errorf(m.errorMessage)
@@ -197,6 +219,45 @@ func (m *MockProvider) ReadFile(filename string) (string, error) {
 	return content, nil
 }
 
+func (m *MockProvider) ListDirectory(path string) ([]DirectoryEntry, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf(m.errorMessage)
+	}
+	var entries []DirectoryEntry
+	for filename, content := range m.files {
+		if path == "" && !strings.Contains(filename, "/") {
+			entries = append(entries, DirectoryEntry{Name: filename, Path: filename, Type: "file", Size: int64(len(content))})
+		} else if path != "" && strings.HasPrefix(filename, path+"/") && !strings.Contains(strings.TrimPrefix(filename, path+"/"), "/") {
+			entries = append(entries, DirectoryEntry{Name: strings.TrimPrefix(filename, path+"/"), Path: filename, Type: "file", Size: int64(len(content))})
+		}
+	}
+	return entries, nil
+}
+
+func (m *MockProvider) MoveFile(oldPath, newPath, commitMessage, customAuthor string) error {
+	if m.shouldError {
+		return fmt.Errorf(m.errorMessage)
+	}
+	content, exists := m.files[oldPath]
+	if !exists {
+		return fmt.Errorf("file not found")
+	}
+	m.files[newPath] = content
+	delete(m.files, oldPath)
+	return nil
+}
+
+func (m *MockProvider) DeleteFile(filename, commitMessage, customAuthor string) error {
+	if m.shouldError {
+		return fmt.Errorf(m.errorMessage)
+	}
+	if _, exists := m.files[filename]; !exists {
+		return fmt.Errorf("file not found")
+	}
+	delete(m.files, filename)
+	return nil
+}
+
 // IssueManager implementation
 func (m *MockProvider) CreateIssue(title, body string) (string, int, error) {
 	if m.shouldError {
@@ -213,6 +274,28 @@ func (m *MockProvider) CreateIssue(title, body string) (string, int, error) {
 	return issue.HTMLURL, issueNumber, nil
 }
 
+func (m *MockProvider) CreateIssueWithLabels(title, body string, labels []string) (string, int, error) {
+	return m.CreateIssue(title, body)
+}
+
+func (m *MockProvider) ListLabels() ([]string, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf(m.errorMessage)
+	}
+	return []string{"bug", "enhancement"}, nil
+}
+
+func (m *MockProvider) ListIssuesByLabel(label string) ([]*IssueStatus, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf(m.errorMessage)
+	}
+	var issues []*IssueStatus
+	for _, issue := range m.issues {
+		issues = append(issues, issue)
+	}
+	return issues, nil
+}
+
 func (m *MockProvider) GetIssueStatus(issueNumber int) (*IssueStatus, error) {
 	if m.shouldError {
 		return nil, fmt.Errorf(m.errorMessage)
@@ -247,6 +330,27 @@ func (m *MockProvider) AddIssueComment(issueNumber int, commentText string) (str
 	return fmt.Sprintf("https://github.com/%s/%s/issues/%d#comment", m.repoOwner, m.repoName, issueNumber), nil
 }
 
+func (m *MockProvider) GetIssueComments(issueNumber, limit int, before string) (*IssueCommentPage, error) {
+	if m.shouldError {
+		return nil, fmt.Errorf(m.errorMessage)
+	}
+	issue, exists := m.issues[issueNumber]
+	if !exists {
+		return nil, fmt.Errorf("issue not found")
+	}
+	return &IssueCommentPage{Number: issue.Number, Title: issue.Title, State: issue.State, URL: issue.HTMLURL}, nil
+}
+
+func (m *MockProvider) AssignIssue(issueNumber int, assignees []string) error {
+	if m.shouldError {
+		return fmt.Errorf(m.errorMessage)
+	}
+	if _, exists := m.issues[issueNumber]; !exists {
+		return fmt.Errorf("issue not found")
+	}
+	return nil
+}
+
 func (m *MockProvider) CloseIssue(issueNumber int) error {
 	if m.shouldError {
 		return fmt.Errorf(m.errorMessage)
@@ -257,6 +361,16 @@ func (m *MockProvider) CloseIssue(issueNumber int) error {
 	return nil
 }
 
+func (m *MockProvider) UpdateIssueBody(issueNumber int, body string) error {
+	if m.shouldError {
+		return fmt.Errorf(m.errorMessage)
+	}
+	if _, exists := m.issues[issueNumber]; !exists {
+		return fmt.Errorf("issue not found")
+	}
+	return nil
+}
+
 // AssetManager implementation
 func (m *MockProvider) UploadImageToCDN(filename string, data []byte) (string, error) {
 	if m.shouldError {
@@ -0,0 +1,392 @@
+package github
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Pull merge: a clone may hold commits whose push failed, e.g. when GitHub was unreachable or
+// another device pushed first. Instead of resetting to the remote, pullLatest replays them onto
+// it like `git pull --rebase`. A file changed on one side only takes that change; a file both
+// sides inserted entries into keeps both insertions, as notes are only ever inserted (see
+// prependToFile). Any other conflict leaves the local commits on a conflict branch pushed to
+// GitHub before the clone follows the remote, so no edit is lost silently.
+
+// conflictBranchPrefix starts the branches holding local commits that could not be replayed
+const conflictBranchPrefix = "msg2git-conflict/"
+
+// replayedCommit is a local commit rewritten onto the remote: the files it writes (nil deletes)
+type replayedCommit struct {
+	commit *object.Commit
+	files  map[string][]byte
+	order  []string
+}
+
+// integrateRemote brings the checked out branch from local to the fetched remote commit,
+// keeping the local commits that are not on the remote
+func (m *Manager) integrateRemote(worktree *git.Worktree, local, remote *object.Commit) error {
+	if local.Hash == remote.Hash {
+		logger.Debug("Local repository is already up to date with remote", nil)
+		return nil
+	}
+
+	behind, err := m.isAncestor(local, remote)
+	if err != nil {
+		return fmt.Errorf("failed to compare with remote: %w", err)
+	}
+	if behind {
+		return m.resetTo(worktree, remote.Hash)
+	}
+
+	ahead, err := m.isAncestor(remote, local)
+	if err != nil {
+		return fmt.Errorf("failed to compare with remote: %w", err)
+	}
+	if ahead {
+		logger.Info("Keeping local commits not pushed yet", map[string]interface{}{
+			"repo_path":     m.repoPath,
+			"local_commit":  local.Hash.String()[:8],
+			"remote_commit": remote.Hash.String()[:8],
+		})
+		return nil
+	}
+
+	// Diverged: replay the local commits onto the remote
+	base, err := m.mergeBase(local, remote)
+	if err != nil {
+		return fmt.Errorf("failed to find merge base: %w", err)
+	}
+	replays, conflicts, err := replayLocalCommits(local, remote, base)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		if err := m.saveConflictBranch(local, conflicts); err != nil {
+			return err
+		}
+		return m.resetTo(worktree, remote.Hash)
+	}
+
+	if err := m.resetTo(worktree, remote.Hash); err != nil {
+		return err
+	}
+	for _, replay := range replays {
+		if err := m.commitReplay(worktree, replay); err != nil {
+			// After the reset only a conflict branch still points at the local commits
+			if branchErr := m.saveConflictBranch(local, nil); branchErr != nil {
+				logger.Error("Failed to save local commits after a failed replay", map[string]interface{}{
+					"repo_path": m.repoPath,
+					"error":     branchErr.Error(),
+				})
+			}
+			return err
+		}
+	}
+
+	logger.Info("Replayed local commits onto remote", map[string]interface{}{
+		"repo_path":     m.repoPath,
+		"commits":       len(replays),
+		"remote_commit": remote.Hash.String()[:8],
+	})
+	return nil
+}
+
+// resetTo hard resets the worktree to hash
+func (m *Manager) resetTo(worktree *git.Worktree, hash plumbing.Hash) error {
+	logger.Info("Resetting local to remote HEAD", map[string]interface{}{
+		"repo_path": m.repoPath,
+		"reset_to":  hash.String()[:8],
+	})
+	if err := m.hardReset(worktree, hash); err != nil {
+		return fmt.Errorf("failed to reset to remote HEAD: %w", err)
+	}
+	return nil
+}
+
+// replayLocalCommits rewrites the commits of local since their merge base (nil if none) with
+// remote onto remote, oldest first. Returns the paths that conflict instead if a commit can't be
+// replayed.
+func replayLocalCommits(local, remote, base *object.Commit) ([]replayedCommit, []string, error) {
+	if base == nil {
+		return nil, []string{"unrelated histories"}, nil
+	}
+
+	// Collect the local commits down to the merge base, they are linear unless merged by hand
+	var commits []*object.Commit
+	var err error
+	for commit := local; commit.Hash != base.Hash; {
+		if commit.NumParents() != 1 {
+			return nil, []string{"merge commit " + commit.Hash.String()[:8]}, nil
+		}
+		commits = append([]*object.Commit{commit}, commits...)
+		if commit, err = commit.Parent(0); err != nil {
+			return nil, nil, fmt.Errorf("failed to get parent commit: %w", err)
+		}
+	}
+
+	remoteTree, err := remote.Tree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get remote tree: %w", err)
+	}
+	written := make(map[string][]byte) // Contents after the replays so far, nil if deleted
+	current := func(path string) ([]byte, error) {
+		if content, ok := written[path]; ok {
+			return content, nil
+		}
+		return treeFileContent(remoteTree, path)
+	}
+
+	var replays []replayedCommit
+	var conflicts []string
+	for _, commit := range commits {
+		changes, err := commitChanges(commit)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		replay := replayedCommit{commit: commit, files: make(map[string][]byte)}
+		for _, change := range changes {
+			path := change.To.Name
+			if path == "" {
+				path = change.From.Name
+			}
+			before, after, err := changeContents(change)
+			if err != nil {
+				return nil, nil, err
+			}
+			theirs, err := current(path)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			var merged []byte
+			switch {
+			case sameContent(theirs, before):
+				merged = after
+			case sameContent(theirs, after):
+				continue
+			case before != nil && after != nil && theirs != nil:
+				var ok bool
+				if merged, ok = mergeInsertions(before, after, theirs); !ok {
+					conflicts = append(conflicts, path)
+					continue
+				}
+			default:
+				conflicts = append(conflicts, path)
+				continue
+			}
+
+			written[path] = merged
+			replay.files[path] = merged
+			replay.order = append(replay.order, path)
+		}
+		if len(replay.order) > 0 {
+			replays = append(replays, replay)
+		}
+	}
+
+	return replays, conflicts, nil
+}
+
+// commitChanges returns the changes of a commit to its parent
+func commitChanges(commit *object.Commit) (object.Changes, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+	parent, err := commit.Parent(0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent commit: %w", err)
+	}
+	parentTree, err := parent.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get parent tree: %w", err)
+	}
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commit: %w", err)
+	}
+	return changes, nil
+}
+
+// changeContents returns the contents of a changed file before and after, nil if it didn't exist
+func changeContents(change *object.Change) ([]byte, []byte, error) {
+	from, to, err := change.Files()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read changed file: %w", err)
+	}
+	before, err := fileContent(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := fileContent(to)
+	if err != nil {
+		return nil, nil, err
+	}
+	return before, after, nil
+}
+
+// treeFileContent returns the content of path in tree, nil if it doesn't exist
+func treeFileContent(tree *object.Tree, path string) ([]byte, error) {
+	file, err := tree.File(path)
+	if err == object.ErrFileNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return fileContent(file)
+}
+
+// fileContent returns the content of a file, nil for no file and an empty slice for an empty one
+func fileContent(file *object.File) ([]byte, error) {
+	if file == nil {
+		return nil, nil
+	}
+	reader, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", file.Name, err)
+	}
+	if content == nil {
+		content = []byte{}
+	}
+	return content, nil
+}
+
+// sameContent compares file contents, telling a missing file (nil) from an empty one
+func sameContent(a, b []byte) bool {
+	if (a == nil) != (b == nil) {
+		return false
+	}
+	return bytes.Equal(a, b)
+}
+
+// mergeInsertions merges ours and theirs, both base with text inserted. Insertions at the same
+// place keep theirs first, the remote usually holds the newer entry; the same entry inserted on
+// both sides, e.g. a push that reached GitHub but reported a failure, is kept once.
+func mergeInsertions(base, ours, theirs []byte) ([]byte, bool) {
+	oursAt, oursText, ok := insertion(base, ours)
+	if !ok {
+		return nil, false
+	}
+	theirsAt, theirsText, ok := insertion(base, theirs)
+	if !ok {
+		return nil, false
+	}
+	if oursAt == theirsAt && bytes.Equal(oursText, theirsText) {
+		return theirs, true
+	}
+
+	firstAt, first, secondAt, second := theirsAt, theirsText, oursAt, oursText
+	if oursAt < theirsAt {
+		firstAt, first, secondAt, second = oursAt, oursText, theirsAt, theirsText
+	}
+	merged := make([]byte, 0, len(base)+len(first)+len(second))
+	merged = append(merged, base[:firstAt]...)
+	merged = append(merged, first...)
+	merged = append(merged, base[firstAt:secondAt]...)
+	merged = append(merged, second...)
+	merged = append(merged, base[secondAt:]...)
+	return merged, true
+}
+
+// insertion returns where and what changed inserted into base, false if changed is not base
+// with whole lines inserted at a single place. The offset is moved back to a line start where the text allows it,
+// so "## b" turned into "## a\n## b" inserts "## a\n" before it rather than "a\n## " inside it.
+func insertion(base, changed []byte) (int, []byte, bool) {
+	if len(changed) <= len(base) {
+		return 0, nil, false
+	}
+	at := 0
+	for at < len(base) && base[at] == changed[at] {
+		at++
+	}
+	suffix := 0
+	for suffix < len(base)-at && base[len(base)-1-suffix] == changed[len(changed)-1-suffix] {
+		suffix++
+	}
+	if at+suffix != len(base) {
+		return 0, nil, false
+	}
+
+	text := append([]byte(nil), changed[at:len(changed)-suffix]...)
+	for at > 0 && base[at-1] != '\n' && text[len(text)-1] == base[at-1] {
+		text = append([]byte{base[at-1]}, text[:len(text)-1]...)
+		at--
+	}
+	// Only whole lines count as inserted entries, text added inside a line edits it
+	if (at > 0 && base[at-1] != '\n') || (at < len(base) && text[len(text)-1] != '\n') {
+		return 0, nil, false
+	}
+	return at, text, true
+}
+
+// commitReplay writes the files of a replayed commit and commits them with its author and message
+func (m *Manager) commitReplay(worktree *git.Worktree, replay replayedCommit) error {
+	for _, path := range replay.order {
+		content := replay.files[path]
+		if content == nil {
+			if _, err := worktree.Remove(path); err != nil {
+				return fmt.Errorf("failed to remove %s: %w", path, err)
+			}
+			continue
+		}
+
+		if err := m.files().WriteFile(path, content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		if _, err := worktree.Add(path); err != nil {
+			return fmt.Errorf("failed to add %s: %w", path, err)
+		}
+	}
+
+	author := replay.commit.Author
+	now := time.Now()
+	_, err := worktree.Commit(replay.commit.Message, &git.CommitOptions{
+		Author:    &author,
+		Committer: m.committerSignature(now),
+	})
+	recordGitOperation("commit", metricsProviderClone, now, err)
+	if err != nil {
+		return fmt.Errorf("failed to commit replayed changes: %w", err)
+	}
+	return nil
+}
+
+// saveConflictBranch keeps the local commits up to head on a conflict branch and pushes it, so
+// they can be merged by hand on GitHub. The clone must not drop them if the push fails.
+func (m *Manager) saveConflictBranch(head *object.Commit, conflicts []string) error {
+	branch := plumbing.NewBranchReferenceName(conflictBranchPrefix + head.Hash.String()[:8])
+	if err := m.repo.Storer.SetReference(plumbing.NewHashReference(branch, head.Hash)); err != nil {
+		return fmt.Errorf("failed to create conflict branch: %w", err)
+	}
+
+	err := m.pushRefs(&git.PushOptions{
+		Auth:     m.basicAuth(),
+		RefSpecs: []config.RefSpec{config.RefSpec(branch.String() + ":" + branch.String())},
+	})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return fmt.Errorf("failed to push local commits to %s: %w", branch.Short(), err)
+	}
+
+	logger.Warn("Local commits conflict with remote, saved them to a branch", map[string]interface{}{
+		"repo_path":    m.repoPath,
+		"branch":       branch.Short(),
+		"local_commit": head.Hash.String()[:8],
+		"conflicts":    conflicts,
+	})
+	return nil
+}
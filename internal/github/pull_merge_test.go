@@ -0,0 +1,246 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+)
+
+func TestMergeInsertions(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		ours   string
+		theirs string
+		want   string // Empty if the edits conflict
+	}{
+		{"same place", "## b\n", "## a\n## b\n", "## c\n## b\n", "## c\n## a\n## b\n"},
+		{"different places", "# Inbox\n\n## old\n", "# Inbox\n\n## a\n## old\n", "# Inbox\n\n## old\n## tail\n", "# Inbox\n\n## a\n## old\n## tail\n"},
+		{"same entry", "## b\n", "## a\n## b\n", "## a\n## b\n", "## a\n## b\n"},
+		{"edited entry", "## b\n", "## b edited\n", "## c\n## b\n", ""},
+		{"deleted entry", "## a\n## b\n", "## b\n", "## c\n## a\n## b\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, ok := mergeInsertions([]byte(tt.base), []byte(tt.ours), []byte(tt.theirs))
+			if tt.want == "" {
+				if ok {
+					t.Errorf("mergeInsertions() = %q, want a conflict", merged)
+				}
+				return
+			}
+			if !ok || string(merged) != tt.want {
+				t.Errorf("mergeInsertions() = %q, %v, want %q", merged, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestPushRetryDelay(t *testing.T) {
+	if got := pushRetryDelay(1); got != pushRetryBaseDelay {
+		t.Errorf("pushRetryDelay(1) = %v, want %v", got, pushRetryBaseDelay)
+	}
+	if got := pushRetryDelay(3); got != 4*pushRetryBaseDelay {
+		t.Errorf("pushRetryDelay(3) = %v, want %v", got, 4*pushRetryBaseDelay)
+	}
+	if got := pushRetryDelay(20); got != pushRetryMaxDelay {
+		t.Errorf("pushRetryDelay(20) = %v, want %v", got, pushRetryMaxDelay)
+	}
+}
+
+// cloneForTest clones the bare repository at remote into a new directory
+func cloneForTest(t *testing.T, remote string) (string, *git.Repository, *git.Worktree) {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainClone(dir, false, &git.CloneOptions{URL: remote})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, repo, worktree
+}
+
+// setUpDivergedClones returns a clone with an unpushed commit of ours to inbox.md while another
+// device pushed theirs to it
+func setUpDivergedClones(t *testing.T, ours, theirs string) (*Manager, *git.Repository, string) {
+	t.Helper()
+	remote := t.TempDir()
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatal(err)
+	}
+
+	seedDir := t.TempDir()
+	seedRepo, err := git.PlainInit(seedDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedWorktree, err := seedRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, seedDir, seedWorktree, map[string]string{"inbox.md": "## b\n"})
+	if _, err := seedRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := seedRepo.Push(&git.PushOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	dir, repo, worktree := cloneForTest(t, remote)
+	otherDir, otherRepo, otherWorktree := cloneForTest(t, remote)
+	commitFiles(t, otherDir, otherWorktree, map[string]string{"inbox.md": theirs})
+	if err := otherRepo.Push(&git.PushOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, dir, worktree, map[string]string{"inbox.md": ours})
+
+	t.Cleanup(func() { defaultBranches.Delete(dir) })
+	m := &Manager{cfg: &gitconfig.Config{}, repoPath: dir, repo: repo}
+	return m, repo, dir
+}
+
+func TestPullLatestReplaysLocalCommits(t *testing.T) {
+	m, repo, dir := setUpDivergedClones(t, "## a\n## b\n", "## c\n## b\n")
+
+	if err := m.pullLatest(); err != nil {
+		t.Fatalf("pullLatest() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "inbox.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "## c\n## a\n## b\n" {
+		t.Errorf("inbox.md = %q, want both notes", content)
+	}
+
+	// The replayed commit sits on top of the remote and pushes
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := repo.Reference(plumbing.NewRemoteReferenceName("origin", head.Name().Short()), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if commit.NumParents() != 1 || commit.ParentHashes[0] != remote.Hash() {
+		t.Errorf("HEAD parents = %v, want the remote %s", commit.ParentHashes, remote.Hash())
+	}
+	if err := m.push(m.basicAuth()); err != nil {
+		t.Errorf("push() error = %v", err)
+	}
+}
+
+func TestPullLatestSavesConflictingCommits(t *testing.T) {
+	m, repo, dir := setUpDivergedClones(t, "## b edited\n", "## c\n## b\n")
+	local, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.pullLatest(); err != nil {
+		t.Fatalf("pullLatest() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "inbox.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "## c\n## b\n" {
+		t.Errorf("inbox.md = %q, want the remote version", content)
+	}
+
+	// The local commit is kept on a conflict branch, locally and on the remote
+	branch := conflictBranchPrefix + local.Hash().String()[:8]
+	ref, err := repo.Reference(plumbing.NewBranchReferenceName(branch), true)
+	if err != nil || ref.Hash() != local.Hash() {
+		t.Fatalf("branch %s = %v, %v, want the local commit", branch, ref, err)
+	}
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	refs, err := remote.List(&git.ListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, ref := range refs {
+		found = found || ref.Name().Short() == branch
+	}
+	if !found {
+		t.Errorf("branch %s was not pushed", branch)
+	}
+}
+
+func TestPullLatestKeepsUnpushedCommits(t *testing.T) {
+	m, repo, _ := setUpDivergedClones(t, "## a\n## b\n", "## c\n## b\n")
+	if err := m.pullLatest(); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, m.repoPath, worktree, map[string]string{"inbox.md": "## d\n## c\n## a\n## b\n"})
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing new on the remote: a local commit ahead of it stays
+	if err := m.pullLatest(); err != nil {
+		t.Fatalf("pullLatest() error = %v", err)
+	}
+	after, err := repo.Head()
+	if err != nil || after.Hash() != head.Hash() {
+		t.Errorf("HEAD = %v, %v, want the unpushed commit %s", after, err, head.Hash())
+	}
+}
+
+func TestPushQueuesFailedPushes(t *testing.T) {
+	m, repo, _ := setUpDivergedClones(t, "## a\n## b\n", "## c\n## b\n")
+	if err := m.pullLatest(); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.push(m.basicAuth()); err != nil {
+		t.Fatalf("push() error = %v", err)
+	}
+	// Nothing left to push is not an error
+	if err := m.push(m.basicAuth()); err != nil {
+		t.Errorf("push() with nothing to push error = %v", err)
+	}
+
+	// The remote is unreachable: the commit stays and its push is queued
+	if err := repo.DeleteRemote("origin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{filepath.Join(t.TempDir(), "missing")}}); err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, m.repoPath, worktree, map[string]string{"inbox.md": "## d\n## c\n## a\n## b\n"})
+	t.Cleanup(m.dequeuePush)
+
+	if err := m.push(m.basicAuth()); !errors.Is(err, ErrPushQueued) {
+		t.Fatalf("push() error = %v, want ErrPushQueued", err)
+	}
+	if got := PendingPushes(); got != 1 {
+		t.Errorf("PendingPushes() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,144 @@
+package github
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Push checks: a local commit is compared with its parent before it is pushed. It must only touch
+// the files the operation meant to change, none of them stray temp or lock files, and every markdown
+// file it writes must be valid UTF-8. Otherwise the commit is dropped and nothing is pushed.
+
+// strayFileSuffixes end the names of files that never belong in a notes repository
+var strayFileSuffixes = []string{".tmp", ".lock", ".swp", ".part", "~"}
+
+// ConsistencyError lists why a commit was not pushed
+type ConsistencyError struct {
+	Problems []string
+}
+
+func (e *ConsistencyError) Error() string {
+	return fmt.Sprintf("commit failed consistency check: %s", strings.Join(e.Problems, "; "))
+}
+
+// isStrayFile reports whether a repository path is a temp, lock or editor file
+func isStrayFile(file string) bool {
+	name := path.Base(file)
+	if isAtomicTempFile(file) || name == ".DS_Store" {
+		return true
+	}
+	for _, suffix := range strayFileSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// commitProblems compares a commit with its first parent and lists what is wrong with it
+func commitProblems(commit *object.Commit, intended []string) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit tree: %w", err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent commit: %w", err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to get parent tree: %w", err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commit: %w", err)
+	}
+
+	allowed := make(map[string]bool, len(intended))
+	for _, file := range intended {
+		allowed[path.Clean(strings.ReplaceAll(file, "\\", "/"))] = true
+	}
+
+	var problems []string
+	for _, change := range changes {
+		name := change.To.Name
+		if name == "" {
+			name = change.From.Name // Deleted
+		}
+
+		if !allowed[name] {
+			problems = append(problems, fmt.Sprintf("unexpected file %s", name))
+			continue
+		}
+		if isStrayFile(name) {
+			problems = append(problems, fmt.Sprintf("temp or lock file %s", name))
+			continue
+		}
+		if change.To.Name == "" || !strings.EqualFold(path.Ext(name), ".md") {
+			continue
+		}
+
+		_, to, err := change.Files()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		content, err := to.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if !utf8.ValidString(content) {
+			problems = append(problems, fmt.Sprintf("%s is not valid UTF-8", name))
+		} else if strings.ContainsRune(content, 0) {
+			problems = append(problems, fmt.Sprintf("%s contains NUL bytes", name))
+		}
+	}
+
+	return problems, nil
+}
+
+// checkCommitBeforePush verifies a local commit touches exactly the intended files. A failing commit
+// is dropped with a hard reset to its parent and a *ConsistencyError is returned.
+func (m *Manager) checkCommitBeforePush(worktree *git.Worktree, hash plumbing.Hash, intended ...string) error {
+	commit, err := m.repo.CommitObject(hash)
+	if err != nil {
+		return fmt.Errorf("failed to get commit object: %w", err)
+	}
+
+	problems, err := commitProblems(commit, intended)
+	if err != nil {
+		return err
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+
+	logger.Error("Commit failed consistency check, not pushing", map[string]interface{}{
+		"repo_path": m.repoPath,
+		"commit":    hash.String(),
+		"intended":  intended,
+		"problems":  problems,
+	})
+
+	if commit.NumParents() > 0 {
+		if err := m.hardReset(worktree, commit.ParentHashes[0]); err != nil {
+			logger.Error("Failed to drop inconsistent commit", map[string]interface{}{
+				"repo_path": m.repoPath,
+				"commit":    hash.String(),
+				"error":     err.Error(),
+			})
+		}
+	}
+
+	return &ConsistencyError{Problems: problems}
+}
@@ -0,0 +1,123 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestIsStrayFile(t *testing.T) {
+	tests := []struct {
+		file string
+		want bool
+	}{
+		{"notes/inbox.md", false},
+		{"images/photo_1.jpg", false},
+		{"todo.md", false},
+		{".msg2git-write-1.tmp", true},
+		{"notes/index.lock", true},
+		{"notes/.inbox.md.swp", true},
+		{"inbox.md~", true},
+		{"download.part", true},
+		{"notes/.DS_Store", true},
+	}
+
+	for _, tt := range tests {
+		if got := isStrayFile(tt.file); got != tt.want {
+			t.Errorf("isStrayFile(%q) = %v, want %v", tt.file, got, tt.want)
+		}
+	}
+}
+
+// commitFiles writes files into the test repository and commits them
+func commitFiles(t *testing.T, dir string, worktree *git.Worktree, files map[string]string) plumbing.Hash {
+	t.Helper()
+	for name, content := range files {
+		if err := writeFileAtomic(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := worktree.Commit("test", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+func TestCheckCommitBeforePush(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		intended []string
+		problem  string // Empty if the commit is fine
+	}{
+		{"intended note", map[string]string{"inbox.md": "## Note\n"}, []string{"inbox.md"}, ""},
+		{"intended image", map[string]string{"images/a.jpg": "\xff\xd8\xff"}, []string{"images/a.jpg"}, ""},
+		{"stray staged file", map[string]string{"inbox.md": "note", "notes.tmp": "x"}, []string{"inbox.md"}, "unexpected file notes.tmp"},
+		{"lock file", map[string]string{"index.lock": "x"}, []string{"index.lock"}, "temp or lock file index.lock"},
+		{"invalid UTF-8", map[string]string{"inbox.md": "caf\xe9"}, []string{"inbox.md"}, "inbox.md is not valid UTF-8"},
+		{"NUL bytes", map[string]string{"inbox.md": "note\x00\x00"}, []string{"inbox.md"}, "inbox.md contains NUL bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			repo, err := git.PlainInit(dir, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			worktree, err := repo.Worktree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			parent := commitFiles(t, dir, worktree, map[string]string{"README.md": "# Notes\n"})
+			hash := commitFiles(t, dir, worktree, tt.files)
+
+			m := &Manager{repoPath: dir, repo: repo}
+			err = m.checkCommitBeforePush(worktree, hash, tt.intended...)
+
+			head, headErr := repo.Head()
+			if headErr != nil {
+				t.Fatal(headErr)
+			}
+
+			if tt.problem == "" {
+				if err != nil {
+					t.Fatalf("checkCommitBeforePush() error = %v", err)
+				}
+				if head.Hash() != hash {
+					t.Errorf("HEAD = %s, want the commit kept", head.Hash())
+				}
+				return
+			}
+
+			var consistencyErr *ConsistencyError
+			if !errors.As(err, &consistencyErr) {
+				t.Fatalf("checkCommitBeforePush() error = %v, want a ConsistencyError", err)
+			}
+			if !strings.Contains(err.Error(), tt.problem) {
+				t.Errorf("checkCommitBeforePush() error = %v, want %q", err, tt.problem)
+			}
+			if head.Hash() != parent {
+				t.Errorf("HEAD = %s, want the commit dropped", head.Hash())
+			}
+			for name := range tt.files {
+				if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+					t.Errorf("%s still in the worktree, stat error = %v", name, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package github
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Push queue: a commit whose push failed stays in the clone, pullLatest replays it onto the remote
+// instead of dropping it (see pull_merge.go). Its branch is queued and pushed again in the
+// background with exponential backoff, until the push succeeds or pushRetryAttempts ran out; the
+// next commit to the branch pushes it along in any case.
+
+const (
+	// pushRetryBaseDelay is the delay before the first retry, doubled for each following one
+	pushRetryBaseDelay = 30 * time.Second
+
+	// pushRetryMaxDelay caps the delay between retries
+	pushRetryMaxDelay = 30 * time.Minute
+
+	// pushRetryAttempts is how often a branch is retried before it waits for the next commit
+	pushRetryAttempts = 8
+)
+
+// ErrPushQueued is returned when a commit was saved in the clone but its push failed and is
+// retried in the background
+var ErrPushQueued = errors.New("saved, the push to GitHub failed and is retried in the background")
+
+// queuedPush is a branch of a clone waiting for a retry
+type queuedPush struct {
+	manager  *Manager
+	attempts int
+	timer    *time.Timer
+}
+
+// pushQueue holds the queued branches by pushQueueKey
+var pushQueue = struct {
+	sync.Mutex
+	pending map[string]*queuedPush
+}{pending: make(map[string]*queuedPush)}
+
+// pushQueueKey identifies the checked out branch of a clone
+func (m *Manager) pushQueueKey() string {
+	branch := m.branch
+	if head, err := m.repo.Head(); err == nil && head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	return m.repoPath + "@" + branch
+}
+
+// pushRetryDelay returns the delay before retry number attempts (starting at 1)
+func pushRetryDelay(attempts int) time.Duration {
+	delay := pushRetryBaseDelay
+	for i := 1; i < attempts && delay < pushRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > pushRetryMaxDelay {
+		delay = pushRetryMaxDelay
+	}
+	return delay
+}
+
+// isRejectedPush reports whether a push was rejected because the remote has commits the clone lacks
+func isRejectedPush(err error) bool {
+	return err != nil && (errors.Is(err, git.ErrForceNeeded) || strings.Contains(err.Error(), "non-fast-forward"))
+}
+
+// isAuthError reports whether err is a failed authorization, which retrying doesn't fix
+func isAuthError(err error) bool {
+	message := err.Error()
+	return strings.Contains(message, "authorization failed") ||
+		strings.Contains(message, "authentication required") ||
+		strings.Contains(message, "authentication failed") ||
+		strings.Contains(message, "401") ||
+		strings.Contains(message, "403")
+}
+
+// queuePush schedules a retry of the checked out branch after a failed push and reports whether
+// the branch is queued. Authorization failures aren't retried.
+func (m *Manager) queuePush(cause error) bool {
+	if isAuthError(cause) {
+		return false
+	}
+	key := m.pushQueueKey()
+
+	pushQueue.Lock()
+	defer pushQueue.Unlock()
+	if _, ok := pushQueue.pending[key]; ok {
+		return true // A retry is already scheduled
+	}
+	queued := &queuedPush{manager: m, attempts: 1}
+	queued.timer = time.AfterFunc(pushRetryDelay(1), func() { retryQueuedPush(key) })
+	pushQueue.pending[key] = queued
+
+	logger.Warn("Push failed, retrying in the background", map[string]interface{}{
+		"repo_path": m.repoPath,
+		"key":       key,
+		"error":     cause.Error(),
+		"retry_in":  pushRetryDelay(1).String(),
+	})
+	return true
+}
+
+// retryQueuedPush pulls and pushes a queued branch, scheduling the next retry if it fails again
+func retryQueuedPush(key string) {
+	pushQueue.Lock()
+	queued, ok := pushQueue.pending[key]
+	pushQueue.Unlock()
+	if !ok {
+		return
+	}
+
+	err := queued.manager.pushPending()
+
+	pushQueue.Lock()
+	defer pushQueue.Unlock()
+	if pushQueue.pending[key] != queued {
+		return // A commit pushed the branch meanwhile
+	}
+	if err == nil {
+		delete(pushQueue.pending, key)
+		logger.Info("Pushed queued commits", map[string]interface{}{
+			"key":      key,
+			"attempts": queued.attempts,
+		})
+		return
+	}
+	if queued.attempts >= pushRetryAttempts || isAuthError(err) {
+		delete(pushQueue.pending, key)
+		logger.Error("Giving up retrying push, commits stay in the clone until the next commit", map[string]interface{}{
+			"key":      key,
+			"attempts": queued.attempts,
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	queued.attempts++
+	delay := pushRetryDelay(queued.attempts)
+	queued.timer = time.AfterFunc(delay, func() { retryQueuedPush(key) })
+	logger.Warn("Queued push failed again", map[string]interface{}{
+		"key":      key,
+		"attempts": queued.attempts - 1,
+		"error":    err.Error(),
+		"retry_in": delay.String(),
+	})
+}
+
+// pushPending replays the branch onto the remote and pushes the commits not pushed yet
+func (m *Manager) pushPending() error {
+	unlock, err := m.lockWorktree(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := m.pullLatest(); err != nil {
+		return err
+	}
+	err = m.pushHead(m.basicAuth())
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	if err == nil {
+		m.snapshotWorkspace(false)
+	}
+	return err
+}
+
+// dequeuePush cancels the retry of the checked out branch once a push of it succeeded
+func (m *Manager) dequeuePush() {
+	key := m.pushQueueKey()
+
+	pushQueue.Lock()
+	defer pushQueue.Unlock()
+	if queued, ok := pushQueue.pending[key]; ok {
+		queued.timer.Stop()
+		delete(pushQueue.pending, key)
+	}
+}
+
+// PendingPushes returns the number of branches waiting for a push retry
+func PendingPushes() int {
+	pushQueue.Lock()
+	defer pushQueue.Unlock()
+	return len(pushQueue.pending)
+}
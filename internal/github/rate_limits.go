@@ -0,0 +1,231 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// GitHub rate limits: REST requests and GraphQL points are separate budgets. A GraphQL query costs
+// points depending on how many nodes it may return, so the GraphQL budget of each token is tracked
+// from response headers, including what the latest query cost (how much X-RateLimit-Used grew).
+// Issue syncs check it before every batch and leave the rest to REST when it runs low. /rate_limit,
+// which counts against neither budget, reports both for /limits.
+
+// RateLimit is the state of one of GitHub's rate limit budgets
+type RateLimit struct {
+	Limit     int
+	Remaining int
+	Used      int
+	Reset     time.Time
+}
+
+// RateLimits are the REST and GraphQL budgets of a token
+type RateLimits struct {
+	Core    RateLimit
+	GraphQL RateLimit
+	// LastGraphQLCost is the points the latest GraphQL query of the bot cost, 0 if unknown
+	LastGraphQLCost int
+}
+
+// graphQLUsage is the tracked GraphQL budget of a token
+type graphQLUsage struct {
+	rateLimit graphQLRateLimit
+	lastCost  int
+}
+
+var (
+	graphQLUsageMu sync.Mutex
+	graphQLUsages  = make(map[string]*graphQLUsage)
+)
+
+// graphQLBudgetKey identifies the GraphQL budget of a token on a GitHub instance, without keeping
+// the token itself
+func graphQLBudgetKey(baseURL, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return baseURL + "|" + hex.EncodeToString(sum[:8])
+}
+
+func (p *APIBasedProvider) graphQLBudgetKey() string {
+	return graphQLBudgetKey(p.baseURL, p.config.Config.GetGitHubToken())
+}
+
+// recordGraphQLRateLimit tracks the budget reported by a GraphQL response and returns what the
+// query cost, 0 if unknown (first query of a rate limit window)
+func recordGraphQLRateLimit(key string, rateLimit graphQLRateLimit) int {
+	if !rateLimit.Known {
+		return 0
+	}
+
+	graphQLUsageMu.Lock()
+	defer graphQLUsageMu.Unlock()
+
+	usage, ok := graphQLUsages[key]
+	if !ok {
+		usage = &graphQLUsage{}
+		graphQLUsages[key] = usage
+	}
+
+	cost := 0
+	previous := usage.rateLimit
+	if previous.Known && previous.Reset.Equal(rateLimit.Reset) {
+		if rateLimit.Used > 0 {
+			cost = rateLimit.Used - previous.Used
+		} else {
+			cost = previous.Remaining - rateLimit.Remaining
+		}
+	}
+
+	usage.rateLimit = rateLimit
+	if cost > 0 {
+		usage.lastCost = cost
+	}
+	return cost
+}
+
+// graphQLBudget returns the tracked budget of key and the cost of its latest query, as long as the
+// rate limit window it was reported for lasts
+func graphQLBudget(key string) (graphQLRateLimit, int, bool) {
+	graphQLUsageMu.Lock()
+	defer graphQLUsageMu.Unlock()
+
+	usage, ok := graphQLUsages[key]
+	if !ok || !usage.rateLimit.Reset.After(time.Now()) {
+		return graphQLRateLimit{}, 0, false
+	}
+	return usage.rateLimit, usage.lastCost, true
+}
+
+// affordGraphQLQuery returns an error if the tracked budget of key can't afford another query
+// costing as much as the latest one while keeping graphQLMinRemaining points
+func affordGraphQLQuery(key string) error {
+	rateLimit, cost, ok := graphQLBudget(key)
+	if !ok {
+		return nil
+	}
+	if cost < 1 {
+		cost = 1
+	}
+
+	if rateLimit.Remaining-cost < graphQLMinRemaining {
+		return fmt.Errorf("GraphQL rate limit nearly exhausted (%d points remaining, queries cost %d, resets %s)",
+			rateLimit.Remaining, cost, rateLimit.Reset.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// logGraphQLBudget logs whether the tracked budget of key covers a sync of batches queries
+func logGraphQLBudget(key string, batches int) {
+	rateLimit, cost, ok := graphQLBudget(key)
+	if !ok {
+		return
+	}
+	if cost < 1 {
+		cost = 1
+	}
+
+	affordable := (rateLimit.Remaining - graphQLMinRemaining) / cost
+	if affordable < 0 {
+		affordable = 0
+	}
+	logger.Debug("GraphQL budget for issue sync", map[string]interface{}{
+		"batches":            batches,
+		"estimated_cost":     batches * cost,
+		"remaining":          rateLimit.Remaining,
+		"affordable_batches": affordable,
+		"reset":              rateLimit.Reset.Format(time.RFC3339),
+	})
+}
+
+// rateLimitResponse is GitHub's /rate_limit response
+type rateLimitResponse struct {
+	Resources struct {
+		Core    apiRateLimit `json:"core"`
+		GraphQL apiRateLimit `json:"graphql"`
+	} `json:"resources"`
+}
+
+type apiRateLimit struct {
+	Limit     int   `json:"limit"`
+	Remaining int   `json:"remaining"`
+	Used      int   `json:"used"`
+	Reset     int64 `json:"reset"`
+}
+
+func (r apiRateLimit) rateLimit() RateLimit {
+	return RateLimit{Limit: r.Limit, Remaining: r.Remaining, Used: r.Used, Reset: time.Unix(r.Reset, 0)}
+}
+
+// decodeRateLimits decodes a /rate_limit response, tracking the GraphQL budget it reports
+func decodeRateLimits(body io.Reader, budgetKey string) (*RateLimits, error) {
+	var response rateLimitResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode rate limits: %w", err)
+	}
+
+	graphQL := response.Resources.GraphQL
+	recordGraphQLRateLimit(budgetKey, graphQLRateLimit{
+		Known:     true,
+		Limit:     graphQL.Limit,
+		Remaining: graphQL.Remaining,
+		Used:      graphQL.Used,
+		Reset:     time.Unix(graphQL.Reset, 0),
+	})
+
+	limits := &RateLimits{
+		Core:    response.Resources.Core.rateLimit(),
+		GraphQL: graphQL.rateLimit(),
+	}
+	if _, cost, ok := graphQLBudget(budgetKey); ok {
+		limits.LastGraphQLCost = cost
+	}
+	return limits, nil
+}
+
+// RateLimits returns the REST and GraphQL budgets of the token
+func (m *Manager) RateLimits() (*RateLimits, error) {
+	req, err := http.NewRequest("GET", m.apiBaseURL()+"/rate_limit", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "token "+m.cfg.GitHubToken)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("User-Agent", "msg2git-telegram-bot")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("GitHub API error: %s (status: %d)", string(body), resp.StatusCode)
+	}
+
+	return decodeRateLimits(resp.Body, graphQLBudgetKey(m.apiBaseURL(), m.cfg.GitHubToken))
+}
+
+// RateLimits returns the REST and GraphQL budgets of the token
+func (p *APIBasedProvider) RateLimits() (*RateLimits, error) {
+	if p.gitea {
+		return nil, fmt.Errorf("Gitea doesn't report rate limits")
+	}
+
+	resp, err := p.makeAPIRequest("GET", "/rate_limit", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rate limits: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeRateLimits(resp.Body, p.graphQLBudgetKey())
+}
@@ -0,0 +1,107 @@
+package github
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordGraphQLRateLimit(t *testing.T) {
+	key := graphQLBudgetKey("https://api.example.com", t.Name())
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	if cost := recordGraphQLRateLimit(key, graphQLRateLimit{Known: true, Limit: 5000, Remaining: 4990, Used: 10, Reset: reset}); cost != 0 {
+		t.Errorf("First query of the window cost = %d, want unknown", cost)
+	}
+	if cost := recordGraphQLRateLimit(key, graphQLRateLimit{Known: true, Limit: 5000, Remaining: 4965, Used: 35, Reset: reset}); cost != 25 {
+		t.Errorf("Cost from X-RateLimit-Used = %d, want 25", cost)
+	}
+	if cost := recordGraphQLRateLimit(key, graphQLRateLimit{Known: true, Remaining: 4960, Reset: reset}); cost != 5 {
+		t.Errorf("Cost from X-RateLimit-Remaining = %d, want 5", cost)
+	}
+
+	rateLimit, cost, ok := graphQLBudget(key)
+	if !ok || rateLimit.Remaining != 4960 || cost != 5 {
+		t.Errorf("graphQLBudget() = %+v, %d, %v", rateLimit, cost, ok)
+	}
+	if err := affordGraphQLQuery(key); err != nil {
+		t.Errorf("affordGraphQLQuery() error = %v", err)
+	}
+
+	recordGraphQLRateLimit(key, graphQLRateLimit{Known: true, Remaining: graphQLMinRemaining + 4, Reset: reset})
+	if err := affordGraphQLQuery(key); err == nil {
+		t.Error("Expected affordGraphQLQuery() to fail when a query would leave less than the minimum")
+	}
+
+	// An ended window says nothing about the budget
+	recordGraphQLRateLimit(key, graphQLRateLimit{Known: true, Remaining: 0, Reset: time.Now().Add(-time.Minute)})
+	if _, _, ok := graphQLBudget(key); ok {
+		t.Error("Expected no budget once the window reset")
+	}
+	if err := affordGraphQLQuery(graphQLBudgetKey("https://api.example.com", "unknown")); err != nil {
+		t.Errorf("affordGraphQLQuery(unknown) error = %v", err)
+	}
+}
+
+// Batches are budgeted by what the previous ones cost, the rest goes through REST
+func TestManager_SyncIssueStatusesGraphQLCost(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetGraphQLRateLimit(70)
+	fake.GraphQLCost = 20
+	numbers := addFakeIssues(fake, 4*graphQLIssueBatchSize)
+
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+
+	statuses, err := manager.SyncIssueStatuses(numbers)
+	if err != nil {
+		t.Fatalf("SyncIssueStatuses() error = %v", err)
+	}
+	if len(statuses) != len(numbers) {
+		t.Errorf("SyncIssueStatuses() returned %d statuses", len(statuses))
+	}
+	// 70 -> 50 -> 30 -> 10 points, a fourth batch would leave less than graphQLMinRemaining
+	if count := graphQLRequestCount(fake); count != 3 {
+		t.Errorf("Expected 3 GraphQL batches, got %d", count)
+	}
+}
+
+func TestRateLimits_FakeGitHub(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetGraphQLRateLimit(100)
+	fake.GraphQLCost = 3
+	numbers := addFakeIssues(fake, 2*graphQLIssueBatchSize)
+
+	manager, err := NewManager(cfg, 0)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	if _, err := manager.SyncIssueStatuses(numbers); err != nil {
+		t.Fatalf("SyncIssueStatuses() error = %v", err)
+	}
+	if _, _, err := manager.CreateIssue("REST", "body"); err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+
+	for _, reader := range []RateLimitReader{manager, provider.(RateLimitReader)} {
+		limits, err := reader.RateLimits()
+		if err != nil {
+			t.Fatalf("RateLimits() error = %v", err)
+		}
+		if limits.Core.Limit != 5000 || limits.Core.Remaining != 4999 || limits.Core.Reset.IsZero() {
+			t.Errorf("RateLimits() core = %+v", limits.Core)
+		}
+		if limits.GraphQL.Remaining != 94 || limits.GraphQL.Used != 4906 {
+			t.Errorf("RateLimits() graphql = %+v", limits.GraphQL)
+		}
+		if limits.LastGraphQLCost != 3 {
+			t.Errorf("RateLimits() last GraphQL cost = %d, want 3", limits.LastGraphQLCost)
+		}
+	}
+}
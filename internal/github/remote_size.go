@@ -0,0 +1,54 @@
+package github
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Remote repository sizes are cached per repository and token: a size fetched within remoteSizeTTL
+// is reused without asking GitHub, an older one is revalidated with its ETag, which GitHub answers
+// with 304 Not Modified without counting it against the rate limit
+
+// remoteSizeTTL is how long a fetched remote size is trusted without revalidation
+const remoteSizeTTL = 5 * time.Minute
+
+// remoteSizeEntry is a remote repository size and the ETag of the response it came from
+type remoteSizeEntry struct {
+	sizeBytes int64
+	etag      string
+	fetchedAt time.Time
+}
+
+var (
+	remoteSizes   = make(map[string]remoteSizeEntry)
+	remoteSizesMu sync.Mutex
+)
+
+// remoteSizeKey identifies a repository API URL as seen with a token, so users without access
+// to a repository never get its size from someone else's request
+func remoteSizeKey(apiURL, token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return apiURL + "|" + hex.EncodeToString(sum[:8])
+}
+
+// getCachedRemoteSize returns the cached size of a repository, ok is false if none is cached
+func getCachedRemoteSize(key string) (remoteSizeEntry, bool) {
+	remoteSizesMu.Lock()
+	defer remoteSizesMu.Unlock()
+	entry, ok := remoteSizes[key]
+	return entry, ok
+}
+
+// storeRemoteSize caches the size of a repository
+func storeRemoteSize(key string, entry remoteSizeEntry) {
+	remoteSizesMu.Lock()
+	defer remoteSizesMu.Unlock()
+	remoteSizes[key] = entry
+}
+
+// isFresh reports whether the size can be used without revalidation
+func (e remoteSizeEntry) isFresh(now time.Time) bool {
+	return now.Sub(e.fetchedAt) < remoteSizeTTL
+}
@@ -0,0 +1,76 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+)
+
+func TestRemoteRepositorySizeCache(t *testing.T) {
+	allowLocalEndpoints(t)
+	var requests, revalidations int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&revalidations, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"size": 2}`))
+	}))
+	defer server.Close()
+
+	m := &Manager{cfg: &gitconfig.Config{
+		GitHubRepo:   "https://github.com/owner/size-cache",
+		GitHubToken:  "token",
+		GitHubAPIURL: server.URL,
+	}}
+
+	for i := 0; i < 2; i++ {
+		size, err := m.getRemoteRepositorySize()
+		if err != nil {
+			t.Fatalf("getRemoteRepositorySize() error = %v", err)
+		}
+		if size != 2048 {
+			t.Errorf("getRemoteRepositorySize() = %d, want 2048", size)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("fresh size should be reused, got %d requests", requests)
+	}
+
+	// Age the cached size past the TTL so it is revalidated
+	key := remoteSizeKey(server.URL+"/repos/owner/size-cache", "token")
+	entry, ok := getCachedRemoteSize(key)
+	if !ok {
+		t.Fatal("size was not cached")
+	}
+	entry.fetchedAt = time.Now().Add(-2 * remoteSizeTTL)
+	storeRemoteSize(key, entry)
+
+	size, err := m.getRemoteRepositorySize()
+	if err != nil || size != 2048 {
+		t.Fatalf("revalidated getRemoteRepositorySize() = %d, %v", size, err)
+	}
+	if revalidations != 1 {
+		t.Errorf("stale size should be revalidated with its ETag, got %d revalidations", revalidations)
+	}
+
+	// Another token never sees the cached size
+	other := &Manager{cfg: &gitconfig.Config{
+		GitHubRepo:   "https://github.com/owner/size-cache",
+		GitHubToken:  "other",
+		GitHubAPIURL: server.URL,
+	}}
+	if _, err := other.getRemoteRepositorySize(); err != nil {
+		t.Fatalf("getRemoteRepositorySize() error = %v", err)
+	}
+	if requests != 3 {
+		t.Errorf("a different token should fetch the size itself, got %d requests", requests)
+	}
+}
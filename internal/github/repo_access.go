@@ -0,0 +1,79 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/consts"
+)
+
+// Repository access: one API request tells whether a token reaches a repository and may push to
+// it, without cloning or touching files. The bot uses it as a preflight before saving to a
+// repository that kept failing.
+
+// repoAccess is what the repository endpoint says about a repository and the token's access
+type repoAccess struct {
+	FullName    string `json:"full_name"`
+	Permissions *struct {
+		Push bool `json:"push"`
+	} `json:"permissions"`
+}
+
+// fetchRepoAccess asks the API at apiURL (empty for github.com) about owner/repo with token,
+// following redirects of moved repositories
+func fetchRepoAccess(apiURL, token, owner, repo string) (*repoAccess, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/repos/%s/%s", ResolveAPIBaseURL(apiURL), owner, repo), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create API request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("token %s", token))
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	client := NewHTTPClient(30 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call GitHub API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, fmt.Errorf(consts.GitHubAuthFailed)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("repository not found or access denied")
+	default:
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+	}
+
+	var info repoAccess
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("failed to parse API response: %w", err)
+	}
+	return &info, nil
+}
+
+// CheckRepoAccess checks that token can push to repoURL through the API at apiURL (empty for
+// github.com), returning why not otherwise
+func CheckRepoAccess(apiURL, token, repoURL string) error {
+	owner, repo, err := parseOwnerRepo(repoURL)
+	if err != nil {
+		return err
+	}
+
+	info, err := fetchRepoAccess(apiURL, token, owner, repo)
+	if err != nil {
+		return err
+	}
+	if info.FullName != "" && !strings.EqualFold(info.FullName, owner+"/"+repo) {
+		return fmt.Errorf("repository moved to %s", info.FullName)
+	}
+	// Tokens without push access still read the repository
+	if info.Permissions != nil && !info.Permissions.Push {
+		return fmt.Errorf("the token can't push to %s/%s", owner, repo)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckRepoAccess(t *testing.T) {
+	allowLocalEndpoints(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "token revoked" {
+			http.Error(w, `{"message": "Bad credentials"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/alice/notes":
+			w.Write([]byte(`{"full_name": "alice/notes", "permissions": {"push": true}}`))
+		case "/repos/alice/shared":
+			w.Write([]byte(`{"full_name": "alice/shared", "permissions": {"push": false}}`))
+		case "/repos/alice/old":
+			w.Write([]byte(`{"full_name": "alice/new", "permissions": {"push": true}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	if err := CheckRepoAccess(server.URL, "token", "https://github.com/alice/notes"); err != nil {
+		t.Errorf("CheckRepoAccess() error = %v", err)
+	}
+
+	tests := map[string]struct {
+		token, repoURL, want string
+	}{
+		"revoked token": {"revoked", "https://github.com/alice/notes", "authorization failed"},
+		"read only":     {"token", "https://github.com/alice/shared", "can't push"},
+		"moved":         {"token", "https://github.com/alice/old", "moved to alice/new"},
+		"deleted":       {"token", "https://github.com/alice/gone", "not found"},
+	}
+	for name, tt := range tests {
+		err := CheckRepoAccess(server.URL, tt.token, tt.repoURL)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: CheckRepoAccess() error = %v, want %q", name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Repository moves: GitHub keeps serving a renamed or transferred repository under its old name
+// through redirects, but git pushes to the old URL can fail with "repository not found". The API
+// follows the redirect to the new repository, so comparing its full_name with the stored URL tells
+// whether the repository moved and where to.
+
+// RepoMove describes a repository that was renamed or transferred after its URL was stored
+type RepoMove struct {
+	OldURL      string // The stored URL
+	OldFullName string // owner/repo of the stored URL
+	NewFullName string // owner/repo the repository lives at now
+	NewURL      string // The stored URL rewritten for NewFullName
+}
+
+// DetectRepoMove asks the GitHub API at apiURL (empty for github.com) where repoURL lives now.
+// Returns nil if the repository didn't move.
+func DetectRepoMove(apiURL, token, repoURL string) (*RepoMove, error) {
+	owner, repo, err := parseOwnerRepo(repoURL)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := fetchRepoAccess(apiURL, token, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+
+	oldFullName := owner + "/" + repo
+	newOwner, newRepo, ok := strings.Cut(info.FullName, "/")
+	if !ok || strings.EqualFold(info.FullName, oldFullName) {
+		return nil, nil
+	}
+
+	return &RepoMove{
+		OldURL:      repoURL,
+		OldFullName: oldFullName,
+		NewFullName: info.FullName,
+		NewURL:      rewriteRepoURL(repoURL, newOwner, newRepo),
+	}, nil
+}
+
+// rewriteRepoURL points repoURL at owner/repo, keeping its host, scheme and .git suffix
+func rewriteRepoURL(repoURL, owner, repo string) string {
+	repoURL = strings.TrimSuffix(strings.TrimSpace(repoURL), "/")
+	suffix := ""
+	if strings.HasSuffix(repoURL, ".git") {
+		suffix = ".git"
+	}
+
+	if strings.HasPrefix(repoURL, "git@") {
+		host, _, _ := strings.Cut(strings.TrimPrefix(repoURL, "git@"), ":")
+		return fmt.Sprintf("git@%s:%s/%s%s", host, owner, repo, suffix)
+	}
+	return repoWebURL(repoURL, owner, repo) + suffix
+}
+
+// MigrateClone moves the local clone of oldURL to where newURL's clone is kept and points its
+// origin at newURL, so a moved repository doesn't have to be cloned again. Nothing happens if
+// there is no clone of oldURL or newURL already has one.
+func MigrateClone(oldURL, newURL string) error {
+	oldPath := generateRepoPath(oldURL)
+	newPath := generateRepoPath(newURL)
+	if oldPath == newPath {
+		return nil
+	}
+
+	// Lock both paths in a deterministic order to prevent deadlocks
+	first, second := oldPath, newPath
+	if second < first {
+		first, second = second, first
+	}
+	defer lockClone(first)()
+	defer lockClone(second)()
+
+	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
+		return nil
+	}
+	if _, err := os.Stat(newPath); err == nil {
+		return nil
+	}
+
+	if err := os.Rename(oldPath, newPath); err != nil {
+		return fmt.Errorf("failed to move clone: %w", err)
+	}
+
+	repo, err := git.PlainOpen(newPath)
+	if err != nil {
+		return fmt.Errorf("failed to open moved clone: %w", err)
+	}
+	cfg, err := repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read clone config: %w", err)
+	}
+	origin, ok := cfg.Remotes["origin"]
+	if !ok {
+		return fmt.Errorf("moved clone has no origin remote")
+	}
+	origin.URLs = []string{newURL}
+	if err := repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to update origin remote: %w", err)
+	}
+
+	logger.Info("Migrated clone of moved repository", map[string]interface{}{
+		"old_path": oldPath,
+		"new_path": newPath,
+	})
+	return nil
+}
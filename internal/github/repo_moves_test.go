@@ -0,0 +1,139 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	gitcfg "github.com/go-git/go-git/v5/config"
+)
+
+func TestDetectRepoMove(t *testing.T) {
+	allowLocalEndpoints(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/alice/notes":
+			w.Write([]byte(`{"full_name": "alice-org/journal"}`))
+		case "/repos/alice/Diary":
+			w.Write([]byte(`{"full_name": "alice/diary"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	move, err := DetectRepoMove(server.URL, "token", "https://github.com/alice/notes.git")
+	if err != nil {
+		t.Fatalf("DetectRepoMove() error = %v", err)
+	}
+	if move == nil || move.OldFullName != "alice/notes" || move.NewFullName != "alice-org/journal" || move.NewURL != "https://github.com/alice-org/journal.git" {
+		t.Errorf("DetectRepoMove() = %+v", move)
+	}
+
+	// Names differing only in case are the same repository
+	if move, err := DetectRepoMove(server.URL, "token", "https://github.com/alice/Diary"); err != nil || move != nil {
+		t.Errorf("DetectRepoMove(same repo) = %+v, %v", move, err)
+	}
+
+	if _, err := DetectRepoMove(server.URL, "token", "https://github.com/alice/gone"); err == nil {
+		t.Error("DetectRepoMove() expected an error for a missing repository")
+	}
+}
+
+func TestRewriteRepoURL(t *testing.T) {
+	tests := []struct {
+		repoURL string
+		want    string
+	}{
+		{"https://github.com/alice/notes", "https://github.com/bob/journal"},
+		{"https://ghe.example.com/alice/notes.git", "https://ghe.example.com/bob/journal.git"},
+		{"git@github.com:alice/notes.git", "git@github.com:bob/journal.git"},
+	}
+
+	for _, tt := range tests {
+		if got := rewriteRepoURL(tt.repoURL, "bob", "journal"); got != tt.want {
+			t.Errorf("rewriteRepoURL(%q) = %q, want %q", tt.repoURL, got, tt.want)
+		}
+	}
+}
+
+func TestMigrateClone(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	oldURL := "https://github.com/alice/notes"
+	newURL := "https://github.com/alice-org/journal"
+
+	repo, err := git.PlainInit(generateRepoPath(oldURL), false)
+	if err != nil {
+		t.Fatalf("PlainInit() error = %v", err)
+	}
+	if _, err := repo.CreateRemote(&gitcfg.RemoteConfig{Name: "origin", URLs: []string{oldURL}}); err != nil {
+		t.Fatalf("CreateRemote() error = %v", err)
+	}
+
+	if err := MigrateClone(oldURL, newURL); err != nil {
+		t.Fatalf("MigrateClone() error = %v", err)
+	}
+
+	if _, err := os.Stat(generateRepoPath(oldURL)); !os.IsNotExist(err) {
+		t.Error("clone still at the old path")
+	}
+	moved, err := git.PlainOpen(generateRepoPath(newURL))
+	if err != nil {
+		t.Fatalf("clone not at the new path: %v", err)
+	}
+	remote, err := moved.Remote("origin")
+	if err != nil {
+		t.Fatalf("Remote() error = %v", err)
+	}
+	if urls := remote.Config().URLs; len(urls) != 1 || urls[0] != newURL {
+		t.Errorf("origin = %v, want %s", urls, newURL)
+	}
+
+	// Without a clone there is nothing to migrate
+	if err := MigrateClone("https://github.com/alice/other", newURL); err != nil {
+		t.Errorf("MigrateClone(no clone) error = %v", err)
+	}
+}
+
+func TestEvictClone(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	repoURL := "https://github.com/alice/notes"
+	repoPath := generateRepoPath(repoURL)
+	if _, err := git.PlainInit(repoPath, false); err != nil {
+		t.Fatalf("PlainInit() error = %v", err)
+	}
+	defaultBranches.Store(repoPath, "main")
+
+	evicted, err := EvictClone(repoURL)
+	if err != nil || !evicted {
+		t.Fatalf("EvictClone() = %v, %v, want true", evicted, err)
+	}
+	if _, err := os.Stat(repoPath); !os.IsNotExist(err) {
+		t.Error("clone still exists")
+	}
+	if _, ok := defaultBranches.Load(repoPath); ok {
+		t.Error("default branch still cached")
+	}
+
+	if evicted, err := EvictClone(repoURL); err != nil || evicted {
+		t.Errorf("EvictClone() without clone = %v, %v, want false", evicted, err)
+	}
+}
@@ -0,0 +1,24 @@
+package github
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// Local clones write repository content, captured messages included, to the bot host's disk.
+// Deployments with a zero content retention policy disable them for the whole process.
+
+// ErrLocalClonesDisabled is returned when a clone-based manager is created while clones are disabled
+var ErrLocalClonesDisabled = errors.New("local repository clones are disabled by the content retention policy")
+
+var localClonesDisabled atomic.Bool
+
+// DisableLocalClones makes every later attempt to create a clone-based manager fail
+func DisableLocalClones() {
+	localClonesDisabled.Store(true)
+}
+
+// LocalClonesDisabled reports whether clone-based managers are disabled
+func LocalClonesDisabled() bool {
+	return localClonesDisabled.Load()
+}
@@ -0,0 +1,223 @@
+package github
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Sandbox mode: SandboxFactory wraps the real factory so every provider it creates reads from
+// GitHub as usual but only simulates writes. Simulated writes are logged and reported through
+// the factory's report function, and return placeholder results so flows run to the end.
+
+// sandboxURL is the base of placeholder URLs for simulated issues and uploads
+const sandboxURL = "https://sandbox.invalid"
+
+// sandboxIssueNumbers numbers simulated issues, far above real issue numbers
+var sandboxIssueNumbers atomic.Int64
+
+func init() {
+	sandboxIssueNumbers.Store(900000)
+}
+
+// SandboxReporter is told about every simulated write, with the chat it was made for
+type SandboxReporter func(chatID int64, action string)
+
+// SandboxFactory creates sandboxed providers
+type SandboxFactory struct {
+	inner  ProviderFactory
+	report SandboxReporter
+}
+
+// NewSandboxFactory wraps inner so its providers simulate writes, reporting them to report
+func NewSandboxFactory(inner ProviderFactory, report SandboxReporter) ProviderFactory {
+	return &SandboxFactory{inner: inner, report: report}
+}
+
+// CreateProvider creates a provider with inner and wraps it in a SandboxProvider
+func (f *SandboxFactory) CreateProvider(providerType ProviderType, config *ProviderConfig) (GitHubProvider, error) {
+	provider, err := f.inner.CreateProvider(providerType, config)
+	if err != nil {
+		return nil, err
+	}
+
+	var chatID int64
+	if config != nil {
+		chatID = config.ChatID
+	}
+	return &SandboxProvider{GitHubProvider: provider, chatID: chatID, report: f.report}, nil
+}
+
+// SandboxProvider reads through the wrapped provider and simulates every write
+type SandboxProvider struct {
+	GitHubProvider
+	chatID int64
+	report SandboxReporter
+}
+
+// simulate logs and reports a write that didn't happen
+func (p *SandboxProvider) simulate(action string) {
+	logger.Info("Sandbox: simulated GitHub write", map[string]interface{}{
+		"chat_id": p.chatID,
+		"action":  action,
+	})
+	if p.report != nil {
+		p.report(p.chatID, action)
+	}
+}
+
+// sandboxSHA returns a commit hash standing in for a simulated commit
+func sandboxSHA(parts ...string) string {
+	h := sha1.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+	}
+	h.Write([]byte(time.Now().String()))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fetch updates the wrapped provider's clone, fetching writes nothing
+func (p *SandboxProvider) Fetch() error {
+	if fetcher, ok := p.GitHubProvider.(Fetcher); ok {
+		return fetcher.Fetch()
+	}
+	return nil
+}
+
+// CommitHistory reads the wrapped provider's history, simulated commits aren't part of it
+func (p *SandboxProvider) CommitHistory(since time.Time) ([]HistoryCommit, error) {
+	if reader, ok := p.GitHubProvider.(HistoryReader); ok {
+		return reader.CommitHistory(since)
+	}
+	return nil, fmt.Errorf("provider keeps no local history")
+}
+
+// SearchFiles searches the wrapped provider's files, simulated commits aren't part of them
+func (p *SandboxProvider) SearchFiles(query string, limit int) ([]SearchMatch, error) {
+	if searcher, ok := p.GitHubProvider.(Searcher); ok {
+		return searcher.SearchFiles(query, limit)
+	}
+	return nil, fmt.Errorf("provider can't search files")
+}
+
+// RateLimits reports the wrapped provider's budgets, simulated operations don't use them
+func (p *SandboxProvider) RateLimits() (*RateLimits, error) {
+	if reader, ok := p.GitHubProvider.(RateLimitReader); ok {
+		return reader.RateLimits()
+	}
+	return nil, fmt.Errorf("provider can't report rate limits")
+}
+
+// EnsureBranch reports whether the branch exists, creating a missing one is simulated
+func (p *SandboxProvider) EnsureBranch(name string) (bool, error) {
+	if api, ok := p.GitHubProvider.(*APIBasedProvider); ok {
+		branch, err := api.getBranch(name)
+		if err != nil || branch != nil {
+			return false, err
+		}
+	}
+	p.simulate("create branch " + name)
+	return true, nil
+}
+
+func (p *SandboxProvider) CreateCommitStatus(sha string, status *CommitStatus) error {
+	return nil // Simulated commits have nothing to attach a status to
+}
+
+func (p *SandboxProvider) CommitFile(filename, content, commitMessage string) error {
+	_, err := p.CommitFileWithResult(filename, content, commitMessage, "", 0)
+	return err
+}
+
+func (p *SandboxProvider) CommitFileWithAuthor(filename, content, commitMessage, customAuthor string) error {
+	_, err := p.CommitFileWithResult(filename, content, commitMessage, customAuthor, 0)
+	return err
+}
+
+func (p *SandboxProvider) CommitFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
+	_, err := p.CommitFileWithResult(filename, content, commitMessage, customAuthor, premiumLevel)
+	return err
+}
+
+func (p *SandboxProvider) CommitFileWithResult(filename, content, commitMessage, customAuthor string, premiumLevel int) (*CommitResult, error) {
+	p.simulate(fmt.Sprintf("would commit %d bytes to %s: %s", len(content), filename, commitMessage))
+	return &CommitResult{
+		Filename: filename,
+		SHA:      sandboxSHA(filename, content),
+		FileSize: int64(len(content)),
+	}, nil
+}
+
+func (p *SandboxProvider) ReplaceFile(filename, content, commitMessage string) error {
+	return p.ReplaceFileWithAuthorAndPremium(filename, content, commitMessage, "", 0)
+}
+
+func (p *SandboxProvider) ReplaceFileWithAuthor(filename, content, commitMessage, customAuthor string) error {
+	return p.ReplaceFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor, 0)
+}
+
+func (p *SandboxProvider) ReplaceFileWithAuthorAndPremium(filename, content, commitMessage, customAuthor string, premiumLevel int) error {
+	p.simulate(fmt.Sprintf("would replace %s with %d bytes: %s", filename, len(content), commitMessage))
+	return nil
+}
+
+func (p *SandboxProvider) ReplaceMultipleFilesWithAuthorAndPremium(files map[string]string, commitMessage, customAuthor string, premiumLevel int) error {
+	p.simulate(fmt.Sprintf("would replace %d files: %s", len(files), commitMessage))
+	return nil
+}
+
+func (p *SandboxProvider) CommitBinaryFile(filename string, data []byte, commitMessage string) error {
+	p.simulate(fmt.Sprintf("would commit %d bytes to %s: %s", len(data), filename, commitMessage))
+	return nil
+}
+
+func (p *SandboxProvider) MoveFile(oldPath, newPath, commitMessage, customAuthor string) error {
+	p.simulate(fmt.Sprintf("would move %s to %s", oldPath, newPath))
+	return nil
+}
+
+func (p *SandboxProvider) DeleteFile(filename, commitMessage, customAuthor string) error {
+	p.simulate(fmt.Sprintf("would delete %s", filename))
+	return nil
+}
+
+func (p *SandboxProvider) CreateIssue(title, body string) (string, int, error) {
+	number := int(sandboxIssueNumbers.Add(1))
+	p.simulate(fmt.Sprintf("would open issue %q", title))
+	return fmt.Sprintf("%s/issues/%d", sandboxURL, number), number, nil
+}
+
+func (p *SandboxProvider) CreateIssueWithLabels(title, body string, labels []string) (string, int, error) {
+	number := int(sandboxIssueNumbers.Add(1))
+	p.simulate(fmt.Sprintf("would open issue %q labeled %v", title, labels))
+	return fmt.Sprintf("%s/issues/%d", sandboxURL, number), number, nil
+}
+
+func (p *SandboxProvider) AddIssueComment(issueNumber int, commentText string) (string, error) {
+	p.simulate(fmt.Sprintf("would comment on issue #%d", issueNumber))
+	return fmt.Sprintf("%s/issues/%d#comment", sandboxURL, issueNumber), nil
+}
+
+func (p *SandboxProvider) AssignIssue(issueNumber int, assignees []string) error {
+	p.simulate(fmt.Sprintf("would assign issue #%d to %v", issueNumber, assignees))
+	return nil
+}
+
+func (p *SandboxProvider) CloseIssue(issueNumber int) error {
+	p.simulate(fmt.Sprintf("would close issue #%d", issueNumber))
+	return nil
+}
+
+func (p *SandboxProvider) UpdateIssueBody(issueNumber int, body string) error {
+	p.simulate(fmt.Sprintf("would update the body of issue #%d", issueNumber))
+	return nil
+}
+
+func (p *SandboxProvider) UploadImageToCDN(filename string, data []byte) (string, error) {
+	p.simulate(fmt.Sprintf("would upload %s (%d bytes)", filename, len(data)))
+	return fmt.Sprintf("%s/images/%s", sandboxURL, filename), nil
+}
@@ -0,0 +1,76 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+// mockProviderFactory always returns its provider
+type mockProviderFactory struct {
+	provider *MockProvider
+}
+
+func (f *mockProviderFactory) CreateProvider(providerType ProviderType, config *ProviderConfig) (GitHubProvider, error) {
+	return f.provider, nil
+}
+
+func TestSandboxProviderSimulatesWrites(t *testing.T) {
+	inner := NewMockProvider()
+	inner.files["note.md"] = "existing"
+
+	var reported []string
+	var reportedChat int64
+	factory := NewSandboxFactory(&mockProviderFactory{provider: inner}, func(chatID int64, action string) {
+		reportedChat = chatID
+		reported = append(reported, action)
+	})
+
+	provider, err := factory.CreateProvider(ProviderTypeAPI, &ProviderConfig{ChatID: 42})
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+
+	result, err := provider.CommitFileWithResult("note.md", "new content", "Add note", "", 0)
+	if err != nil {
+		t.Fatalf("CommitFileWithResult() error = %v", err)
+	}
+	if result.SHA == "" || result.FileSize != int64(len("new content")) {
+		t.Errorf("CommitFileWithResult() = %+v, want a placeholder SHA and the content size", result)
+	}
+	if err := provider.DeleteFile("note.md", "Delete note", ""); err != nil {
+		t.Fatalf("DeleteFile() error = %v", err)
+	}
+	url, number, err := provider.CreateIssue("Title", "Body")
+	if err != nil || number == 0 || !strings.HasPrefix(url, sandboxURL) {
+		t.Errorf("CreateIssue() = %q, %d, %v, want a placeholder issue", url, number, err)
+	}
+
+	if inner.files["note.md"] != "existing" {
+		t.Errorf("inner file = %q, want it untouched", inner.files["note.md"])
+	}
+	if len(inner.issues) != 0 {
+		t.Errorf("inner issues = %d, want none", len(inner.issues))
+	}
+
+	if reportedChat != 42 || len(reported) != 3 {
+		t.Fatalf("reported %v for chat %d, want 3 writes for chat 42", reported, reportedChat)
+	}
+	if !strings.Contains(reported[0], "would commit") || !strings.Contains(reported[0], "note.md") {
+		t.Errorf("reported[0] = %q, want the simulated commit", reported[0])
+	}
+}
+
+func TestSandboxProviderReadsThrough(t *testing.T) {
+	inner := NewMockProvider()
+	inner.files["note.md"] = "existing"
+
+	provider, err := NewSandboxFactory(&mockProviderFactory{provider: inner}, nil).CreateProvider(ProviderTypeAPI, nil)
+	if err != nil {
+		t.Fatalf("CreateProvider() error = %v", err)
+	}
+
+	content, err := provider.ReadFile("note.md")
+	if err != nil || content != "existing" {
+		t.Errorf("ReadFile() = %q, %v, want the inner file", content, err)
+	}
+}
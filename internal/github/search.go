@@ -0,0 +1,177 @@
+package github
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Full-text search: providers implementing Searcher find the lines of markdown files matching a
+// query. The clone-based provider scans its local clone. The API provider asks GitHub code search
+// for candidate files, which only indexes the default branch, and reads them to find the lines.
+
+// searchMaxAPIFiles bounds how many code search results the API provider reads
+const searchMaxAPIFiles = 20
+
+// SearchMatch is a line of a file matching a search
+type SearchMatch struct {
+	Path string
+	Line int    // 1-based line number
+	Text string // The matching line without surrounding whitespace
+}
+
+// isMarkdownFile reports whether name is searched
+func isMarkdownFile(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// MatchLines returns up to limit lines of content containing every word of query, ignoring case
+func MatchLines(filePath, content, query string, limit int) []SearchMatch {
+	terms := strings.Fields(strings.ToLower(query))
+	if len(terms) == 0 || limit <= 0 {
+		return nil
+	}
+
+	var matches []SearchMatch
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		lower := strings.ToLower(text)
+
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(lower, term) {
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		matches = append(matches, SearchMatch{Path: filePath, Line: line, Text: strings.TrimSpace(text)})
+		if len(matches) >= limit {
+			break
+		}
+	}
+	return matches
+}
+
+// SearchFiles returns up to limit lines of the clone's markdown files matching query, in path order
+func (m *Manager) SearchFiles(query string, limit int) ([]SearchMatch, error) {
+	if err := m.ensureRepositoryReadOnly(); err != nil {
+		return nil, fmt.Errorf("failed to ensure repository: %w", err)
+	}
+
+	if err := m.syncWorktree(); err != nil {
+		// Search the local files rather than failing
+		logger.Warn("Failed to pull latest changes before searching", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	unlock, err := m.lockWorktree(false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock repository: %w", err)
+	}
+	defer unlock()
+
+	var matches []SearchMatch
+	err = m.files().WalkDir(".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isMarkdownFile(entry.Name()) {
+			return nil
+		}
+
+		content, err := m.files().ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		matches = append(matches, MatchLines(name, string(content), query, limit-len(matches))...)
+		if len(matches) >= limit {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search repository: %w", err)
+	}
+
+	return matches, nil
+}
+
+type apiCodeSearchResult struct {
+	TotalCount int `json:"total_count"`
+	Items      []struct {
+		Path string `json:"path"`
+	} `json:"items"`
+}
+
+// SearchFiles returns up to limit lines of markdown files matching query, reading the files
+// GitHub code search finds for it
+func (p *APIBasedProvider) SearchFiles(query string, limit int) ([]SearchMatch, error) {
+	if p.gitea {
+		return nil, fmt.Errorf("code search isn't available on Gitea")
+	}
+
+	q := fmt.Sprintf("%s repo:%s/%s language:markdown", query, p.repoOwner, p.repoName)
+	endpoint := fmt.Sprintf("/search/code?q=%s&per_page=%d", url.QueryEscape(q), searchMaxAPIFiles)
+
+	resp, err := p.makeAPIRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result apiCodeSearchResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode code search results: %w", err)
+	}
+
+	var matches []SearchMatch
+	for _, item := range result.Items {
+		if !isMarkdownFile(item.Path) {
+			continue
+		}
+
+		content, err := p.ReadFile(item.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", item.Path, err)
+		}
+
+		// Code search matches words anywhere in the file, lines containing all of them may not exist
+		matches = append(matches, MatchLines(item.Path, content, query, limit-len(matches))...)
+		if len(matches) >= limit {
+			break
+		}
+	}
+
+	logger.Debug("Searched repository via API", map[string]interface{}{
+		"files":   len(result.Items),
+		"matches": len(matches),
+		"user_id": p.config.UserID,
+	})
+
+	return matches, nil
+}
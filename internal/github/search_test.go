@@ -0,0 +1,55 @@
+package github
+
+import "testing"
+
+func TestMatchLines(t *testing.T) {
+	content := "# Ideas\n\n  Buy a Coffee grinder  \nCoffee beans from the market\ncoffee\n"
+
+	matches := MatchLines("ideas.md", content, "coffee", 10)
+	if len(matches) != 3 {
+		t.Fatalf("MatchLines(coffee) = %+v, want 3 matches", matches)
+	}
+	if matches[0] != (SearchMatch{Path: "ideas.md", Line: 3, Text: "Buy a Coffee grinder"}) {
+		t.Errorf("MatchLines(coffee)[0] = %+v", matches[0])
+	}
+
+	// Every word has to be on the line
+	if matches := MatchLines("ideas.md", content, "market COFFEE", 10); len(matches) != 1 || matches[0].Line != 4 {
+		t.Errorf("MatchLines(market coffee) = %+v, want line 4", matches)
+	}
+	if matches := MatchLines("ideas.md", content, "coffee", 2); len(matches) != 2 {
+		t.Errorf("MatchLines() with limit 2 = %d matches", len(matches))
+	}
+	if matches := MatchLines("ideas.md", content, "  ", 10); matches != nil {
+		t.Errorf("MatchLines(blank) = %+v, want none", matches)
+	}
+}
+
+func TestAPIProvider_SearchFiles(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetFile("owner", "notes", "note.md", "first line\nmeeting with Alice\n")
+	fake.SetFile("owner", "notes", "work/todo.md", "- [ ] prepare meeting agenda\n")
+	fake.SetFile("owner", "notes", "meeting.txt", "meeting minutes\n")
+
+	provider, err := NewAPIBasedProvider(NewProviderConfig(cfg, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	matches, err := provider.(Searcher).SearchFiles("meeting", 10)
+	if err != nil {
+		t.Fatalf("SearchFiles() error = %v", err)
+	}
+	want := []SearchMatch{
+		{Path: "note.md", Line: 2, Text: "meeting with Alice"},
+		{Path: "work/todo.md", Line: 1, Text: "- [ ] prepare meeting agenda"},
+	}
+	if len(matches) != len(want) {
+		t.Fatalf("SearchFiles() = %+v, want %+v", matches, want)
+	}
+	for i := range want {
+		if matches[i] != want[i] {
+			t.Errorf("SearchFiles()[%d] = %+v, want %+v", i, matches[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Shallow and sparse clones: prepending a note to one Markdown file needs neither the history nor
+// most files of a repository. Depending on the premium tier, clones fetch only the latest commit
+// (later fetches add new commits on top) and check out only the top-level files.
+//
+// A shallow clone lacks the parents of its boundary commits, so ancestry is walked by
+// walkAncestors, which stops there, instead of go-git's IsAncestor and MergeBase.
+//
+// A sparse clone keeps every file in the index but writes only the top-level ones to the disk;
+// sparseFS writes any other file from the index when it's first used, so a note is never prepended
+// to a file that merely wasn't checked out. go-git v5 drops skip-worktree entries from commits, so
+// resets and checkouts of sparse clones go through sparseReset instead of touching every file.
+// Clones are shared by every user of a repository, so sparseness is recorded in the clone's git
+// config (core.sparseCheckout, like git) rather than taken from the manager at hand.
+
+// sparseCheckoutPatterns is .git/info/sparse-checkout of sparse clones: top-level files only
+const sparseCheckoutPatterns = "/*\n!/*/\n"
+
+// ErrShallowClone is returned by operations that need the full history of the repository
+var ErrShallowClone = errors.New("this needs the full history of the repository, which is not cloned on your tier")
+
+// cloneDepth returns the depth of new clones, 0 for the full history
+func (m *Manager) cloneDepth() int {
+	if m.shallowClone {
+		return 1
+	}
+	return 0
+}
+
+// isShallow reports whether the clone lacks part of the history
+func (m *Manager) isShallow() bool {
+	shallow, err := m.repo.Storer.Shallow()
+	return err == nil && len(shallow) > 0
+}
+
+// sparseWorktree reports whether the clone only checked out its top-level files
+func (m *Manager) sparseWorktree() bool {
+	if m.repo == nil {
+		return false
+	}
+	cfg, err := m.repo.Config()
+	return err == nil && cfg.Raw.Section("core").Option("sparseCheckout") == "true"
+}
+
+// inSparseCheckout reports whether name is checked out by sparse clones
+func inSparseCheckout(name string) bool {
+	return !strings.Contains(name, "/")
+}
+
+// setUpSparseCheckout checks out the top-level files of a clone made without checkout and marks it
+// sparse
+func (m *Manager) setUpSparseCheckout() error {
+	head, err := m.repo.Head()
+	if err != nil {
+		return nil // Nothing to check out
+	}
+	worktree, err := m.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	if err := worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.MixedReset}); err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	cfg, err := m.repo.Config()
+	if err != nil {
+		return fmt.Errorf("failed to read clone config: %w", err)
+	}
+	cfg.Raw.Section("core").SetOption("sparseCheckout", "true")
+	if err := m.repo.SetConfig(cfg); err != nil {
+		return fmt.Errorf("failed to mark clone sparse: %w", err)
+	}
+	infoDir := filepath.Join(m.repoPath, ".git", "info")
+	if err := os.MkdirAll(infoDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", infoDir, err)
+	}
+	if err := os.WriteFile(filepath.Join(infoDir, "sparse-checkout"), []byte(sparseCheckoutPatterns), 0644); err != nil {
+		return fmt.Errorf("failed to write sparse-checkout patterns: %w", err)
+	}
+
+	idx, err := m.repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	for _, entry := range idx.Entries {
+		if inSparseCheckout(entry.Name) {
+			if err := m.writeIndexEntry(m.backendFiles(), entry); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeIndexEntry writes the staged content of an index entry to the worktree files. Symbolic
+// links are not checked out, the worktree never follows them (see ErrSymlink).
+func (m *Manager) writeIndexEntry(files WorktreeFS, entry *index.Entry) error {
+	if entry.Mode == filemode.Submodule || entry.Mode == filemode.Dir {
+		return nil
+	}
+	if entry.Mode == filemode.Symlink {
+		logger.Warn("Not checking out symbolic link", map[string]interface{}{
+			"repo_path": m.repoPath,
+			"file":      entry.Name,
+		})
+		return nil
+	}
+	blob, err := m.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", entry.Name, err)
+	}
+	reader, err := blob.Reader()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", entry.Name, err)
+	}
+	defer reader.Close()
+
+	perm := fs.FileMode(0644)
+	if entry.Mode == filemode.Executable {
+		perm = 0755
+	}
+	return files.WriteFileFunc(entry.Name, perm, func(w io.Writer) error {
+		_, err := io.Copy(w, reader)
+		return err
+	})
+}
+
+// hardReset points the checked out branch, the index and the worktree at hash, like
+// `git reset --hard`
+func (m *Manager) hardReset(worktree *git.Worktree, hash plumbing.Hash) error {
+	defer m.worktreeChanged()
+	if !m.sparseWorktree() {
+		return worktree.Reset(&git.ResetOptions{Commit: hash, Mode: git.HardReset})
+	}
+	return m.sparseReset(worktree, m.headTree(), hash)
+}
+
+// checkout checks out a branch like worktree.Checkout with Force, keeping sparse clones sparse
+func (m *Manager) checkout(worktree *git.Worktree, options *git.CheckoutOptions) error {
+	defer m.worktreeChanged()
+	if !m.sparseWorktree() {
+		return worktree.Checkout(options)
+	}
+
+	from := m.headTree()
+	if options.Create {
+		if err := m.repo.Storer.SetReference(plumbing.NewHashReference(options.Branch, options.Hash)); err != nil {
+			return fmt.Errorf("failed to create branch: %w", err)
+		}
+	}
+	ref, err := m.repo.Reference(options.Branch, true)
+	if err != nil {
+		return fmt.Errorf("failed to find branch: %w", err)
+	}
+	if err := m.repo.Storer.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, options.Branch)); err != nil {
+		return fmt.Errorf("failed to update HEAD: %w", err)
+	}
+	return m.sparseReset(worktree, from, ref.Hash())
+}
+
+// headTree returns the tree of HEAD, nil if there is none
+func (m *Manager) headTree() *object.Tree {
+	head, err := m.repo.Head()
+	if err != nil {
+		return nil
+	}
+	commit, err := m.repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil
+	}
+	return tree
+}
+
+// sparseReset resets a sparse clone from the tree from to hash: the index takes every file, the
+// worktree only the files it has checked out, the top-level files and the files removed since from
+func (m *Manager) sparseReset(worktree *git.Worktree, from *object.Tree, hash plumbing.Hash) error {
+	if err := worktree.Reset(&git.ResetOptions{Commit: hash, Mode: git.MixedReset}); err != nil {
+		return err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree status: %w", err)
+	}
+	idx, err := m.repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+
+	for name, fileStatus := range status {
+		switch fileStatus.Worktree {
+		case git.Modified:
+		case git.Deleted:
+			if !inSparseCheckout(name) {
+				continue // Not checked out
+			}
+		case git.Untracked:
+			if from != nil {
+				if _, err := from.File(name); err == nil {
+					if err := m.backendFiles().Remove(name); err != nil && !errors.Is(err, fs.ErrNotExist) {
+						return fmt.Errorf("failed to remove %s: %w", name, err)
+					}
+				}
+			}
+			continue
+		default:
+			continue
+		}
+
+		entry, err := idx.Entry(name)
+		if err != nil {
+			continue
+		}
+		if err := m.writeIndexEntry(m.backendFiles(), entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sparseFS checks out files of sparse clones from the index when they are first used. Listing or
+// walking a directory checks out all files in it.
+type sparseFS struct {
+	WorktreeFS
+	m *Manager
+}
+
+// sparseLayer is the WorktreeFS layer of the clone's sparse checkout
+func (m *Manager) sparseLayer(backend WorktreeFS) WorktreeFS {
+	return &sparseFS{WorktreeFS: backend, m: m}
+}
+
+// checkOut writes the files at or below name that are in the index but not in the worktree. Unless
+// all is set, names already in the worktree are taken as checked out.
+func (s *sparseFS) checkOut(name string, all bool) error {
+	name = path.Clean(name)
+	if !all {
+		if _, err := s.WorktreeFS.Stat(name); err == nil {
+			return nil
+		}
+	}
+	if !s.m.sparseWorktree() {
+		return nil
+	}
+
+	idx, err := s.m.repo.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	for _, entry := range idx.Entries {
+		if name != "." && entry.Name != name && !strings.HasPrefix(entry.Name, name+"/") {
+			continue
+		}
+		if _, err := s.WorktreeFS.Stat(entry.Name); err == nil {
+			continue
+		}
+		if err := s.m.writeIndexEntry(s.WorktreeFS, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *sparseFS) Open(name string) (io.ReadSeekCloser, error) {
+	if err := s.checkOut(name, false); err != nil {
+		return nil, err
+	}
+	return s.WorktreeFS.Open(name)
+}
+
+func (s *sparseFS) ReadFile(name string) ([]byte, error) {
+	if err := s.checkOut(name, false); err != nil {
+		return nil, err
+	}
+	return s.WorktreeFS.ReadFile(name)
+}
+
+func (s *sparseFS) Stat(name string) (fs.FileInfo, error) {
+	if err := s.checkOut(name, false); err != nil {
+		return nil, err
+	}
+	return s.WorktreeFS.Stat(name)
+}
+
+func (s *sparseFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if err := s.checkOut(name, true); err != nil {
+		return nil, err
+	}
+	return s.WorktreeFS.ReadDir(name)
+}
+
+func (s *sparseFS) WalkDir(name string, fn fs.WalkDirFunc) error {
+	if err := s.checkOut(name, true); err != nil {
+		return err
+	}
+	return s.WorktreeFS.WalkDir(name, fn)
+}
+
+func (s *sparseFS) Remove(name string) error {
+	if err := s.checkOut(name, false); err != nil {
+		return err
+	}
+	return s.WorktreeFS.Remove(name)
+}
+
+func (s *sparseFS) Rename(oldName, newName string) error {
+	if err := s.checkOut(oldName, false); err != nil {
+		return err
+	}
+	return s.WorktreeFS.Rename(oldName, newName)
+}
+
+// contentSize returns the content size of the worktree, of all its files for sparse clones
+func (s *sparseFS) contentSize() (int64, error) {
+	if !s.m.sparseWorktree() {
+		return worktreeContentSize(s.WorktreeFS)
+	}
+	return s.m.indexContentSize()
+}
+
+// indexContentSize returns the size of the files in the index without submodules, the content
+// size of a sparse clone whose worktree lacks most of them
+func (m *Manager) indexContentSize() (int64, error) {
+	idx, err := m.repo.Storer.Index()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	var size int64
+	for _, entry := range idx.Entries {
+		if entry.Mode == filemode.Submodule {
+			continue
+		}
+		blobSize, err := m.repo.Storer.EncodedObjectSize(entry.Hash)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read size of %s: %w", entry.Name, err)
+		}
+		size += blobSize
+	}
+	return size, nil
+}
+
+// contentSize returns the user-facing size of the clone, see worktreeContentSize
+func (m *Manager) contentSize() (int64, error) {
+	return worktreeContentSize(m.sparseLayer(diskFS{root: m.repoPath}))
+}
+
+// walkAncestors visits commit and its ancestors breadth first until visit returns false. The
+// walk ends at the boundary commits of a shallow clone, whose parents it doesn't have.
+func (m *Manager) walkAncestors(commit *object.Commit, visit func(*object.Commit) bool) error {
+	boundary := make(map[plumbing.Hash]bool)
+	if shallow, err := m.repo.Storer.Shallow(); err == nil {
+		for _, hash := range shallow {
+			boundary[hash] = true
+		}
+	}
+
+	seen := map[plumbing.Hash]bool{commit.Hash: true}
+	queue := []*object.Commit{commit}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if !visit(current) {
+			return nil
+		}
+		if boundary[current.Hash] {
+			continue
+		}
+		for _, hash := range current.ParentHashes {
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+			parent, err := m.repo.CommitObject(hash)
+			if err != nil {
+				return fmt.Errorf("failed to read commit %s: %w", hash, err)
+			}
+			queue = append(queue, parent)
+		}
+	}
+	return nil
+}
+
+// isAncestor reports whether ancestor is descendant or one of its ancestors in the clone
+func (m *Manager) isAncestor(ancestor, descendant *object.Commit) (bool, error) {
+	found := false
+	err := m.walkAncestors(descendant, func(commit *object.Commit) bool {
+		found = commit.Hash == ancestor.Hash
+		return !found
+	})
+	return found, err
+}
+
+// mergeBase returns the newest commit both local and remote descend from, nil if the clone has
+// none (unrelated histories, or a shallow boundary hiding it)
+func (m *Manager) mergeBase(local, remote *object.Commit) (*object.Commit, error) {
+	ancestors := make(map[plumbing.Hash]bool)
+	err := m.walkAncestors(local, func(commit *object.Commit) bool {
+		ancestors[commit.Hash] = true
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var base *object.Commit
+	err = m.walkAncestors(remote, func(commit *object.Commit) bool {
+		if ancestors[commit.Hash] {
+			base = commit
+			return false
+		}
+		return true
+	})
+	return base, err
+}
+
+// logShallowClone records how a new clone was made
+func (m *Manager) logShallowClone() {
+	if !m.shallowClone && !m.sparseCheckout {
+		return
+	}
+	logger.Info("Cloned repository partially", map[string]interface{}{
+		"repo_path": m.repoPath,
+		"depth":     m.cloneDepth(),
+		"sparse":    m.sparseCheckout,
+	})
+}
@@ -0,0 +1,173 @@
+package github
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	gitconfig "github.com/msg2git/msg2git/internal/config"
+)
+
+// seedRemote creates a bare repository with one commit per set of files
+func seedRemote(t *testing.T, commits ...map[string]string) string {
+	t.Helper()
+	remote := t.TempDir()
+	if _, err := git.PlainInit(remote, true); err != nil {
+		t.Fatal(err)
+	}
+
+	seedDir := t.TempDir()
+	seedRepo, err := git.PlainInit(seedDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seedWorktree, err := seedRepo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, files := range commits {
+		for name := range files {
+			if err := os.MkdirAll(filepath.Dir(filepath.Join(seedDir, name)), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		commitFiles(t, seedDir, seedWorktree, files)
+	}
+	if _, err := seedRepo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remote}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := seedRepo.Push(&git.PushOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	return remote
+}
+
+func TestPullLatestReplaysOntoShallowClone(t *testing.T) {
+	remote := seedRemote(t, map[string]string{"inbox.md": "## old\n"}, map[string]string{"inbox.md": "## b\n"})
+
+	dir := t.TempDir()
+	repo, err := git.PlainClone(dir, false, &git.CloneOptions{URL: remote, Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { defaultBranches.Delete(dir) })
+	m := &Manager{cfg: &gitconfig.Config{}, repoPath: dir, repo: repo}
+	if !m.isShallow() {
+		t.Fatal("isShallow() = false for a clone of depth 1")
+	}
+
+	otherDir, otherRepo, otherWorktree := cloneForTest(t, remote)
+	commitFiles(t, otherDir, otherWorktree, map[string]string{"inbox.md": "## c\n## b\n"})
+	if err := otherRepo.Push(&git.PushOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	commitFiles(t, dir, worktree, map[string]string{"inbox.md": "## a\n## b\n"})
+
+	if err := m.pullLatest(); err != nil {
+		t.Fatalf("pullLatest() error = %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(dir, "inbox.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "## c\n## a\n## b\n" {
+		t.Errorf("inbox.md = %q, want both insertions", content)
+	}
+	if _, err := m.CompressHistory(CompressOptions{}); !errors.Is(err, ErrShallowClone) {
+		t.Errorf("CompressHistory() error = %v, want ErrShallowClone", err)
+	}
+}
+
+func TestSparseCheckout(t *testing.T) {
+	remote := seedRemote(t, map[string]string{
+		"inbox.md":      "## a\n",
+		"docs/guide.md": "guide\n",
+		"notes/todo.md": "todo\n",
+	})
+
+	dir := t.TempDir()
+	repo, err := git.PlainClone(dir, false, &git.CloneOptions{URL: remote, NoCheckout: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { defaultBranches.Delete(dir) })
+	m := &Manager{cfg: &gitconfig.Config{}, repoPath: dir, repo: repo, sparseCheckout: true}
+	if err := m.setUpSparseCheckout(); err != nil {
+		t.Fatalf("setUpSparseCheckout() error = %v", err)
+	}
+	if !m.sparseWorktree() {
+		t.Fatal("sparseWorktree() = false after setting up a sparse checkout")
+	}
+
+	onDisk := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		return err == nil
+	}
+	if !onDisk("inbox.md") || onDisk("docs/guide.md") || onDisk("notes/todo.md") {
+		t.Fatal("sparse checkout should only write top-level files")
+	}
+
+	size, err := m.contentSize()
+	if err != nil {
+		t.Fatalf("contentSize() error = %v", err)
+	}
+	if want := int64(len("## a\n") + len("guide\n") + len("todo\n")); size != want {
+		t.Errorf("contentSize() = %d, want %d counting files not checked out", size, want)
+	}
+
+	content, err := m.files().ReadFile("docs/guide.md")
+	if err != nil || string(content) != "guide\n" {
+		t.Fatalf("ReadFile(docs/guide.md) = %q, %v, want it checked out on use", content, err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := commitFiles(t, dir, worktree, map[string]string{"inbox.md": "## b\n## a\n"})
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := commit.File("notes/todo.md"); err != nil {
+		t.Errorf("commit dropped a file that is not checked out: %v", err)
+	}
+
+	if err := m.hardReset(worktree, first.Hash()); err != nil {
+		t.Fatalf("hardReset() error = %v", err)
+	}
+	content, err = os.ReadFile(filepath.Join(dir, "inbox.md"))
+	if err != nil || string(content) != "## a\n" {
+		t.Errorf("inbox.md after reset = %q, %v, want the first version", content, err)
+	}
+	if onDisk("notes/todo.md") {
+		t.Error("hardReset() checked out files of a sparse clone")
+	}
+}
+
+func TestWriteIndexEntrySkipsSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	m := &Manager{cfg: &gitconfig.Config{}, repoPath: dir}
+
+	entry := &index.Entry{Name: "docs/link.md", Mode: filemode.Symlink, Hash: plumbing.ZeroHash}
+	if err := m.writeIndexEntry(m.backendFiles(), entry); err != nil {
+		t.Fatalf("writeIndexEntry() error = %v", err)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "docs", "link.md")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("symbolic link was checked out: %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package github
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// GitmodulesFile is the file that declares a repository's submodules
+const GitmodulesFile = ".gitmodules"
+
+// ParseGitmodules returns the submodule paths declared in a .gitmodules file
+func ParseGitmodules(content string) []string {
+	var paths []string
+	for _, line := range strings.Split(content, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if !found || strings.TrimSpace(key) != "path" {
+			continue
+		}
+		if path := strings.Trim(strings.TrimSpace(value), `"`); path != "" {
+			paths = append(paths, filepath.ToSlash(path))
+		}
+	}
+	return paths
+}
+
+// submoduleRecursion returns the clone recursion depth, submodules are skipped unless enabled
+func submoduleRecursion(cloneSubmodules bool) git.SubmoduleRescursivity {
+	if cloneSubmodules {
+		return git.DefaultSubmoduleRecursionDepth
+	}
+	return git.NoRecurseSubmodules
+}
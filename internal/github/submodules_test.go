@@ -0,0 +1,75 @@
+package github
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+)
+
+func TestParseGitmodules(t *testing.T) {
+	content := `[submodule "themes/hugo"]
+	path = themes/hugo
+	url = https://github.com/example/hugo-theme.git
+[submodule "vendor lib"]
+	path = "vendor/lib"
+	url = git@github.com:example/lib.git
+`
+	got := ParseGitmodules(content)
+	want := []string{"themes/hugo", "vendor/lib"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseGitmodules() = %v, want %v", got, want)
+	}
+
+	if got := ParseGitmodules(""); len(got) != 0 {
+		t.Errorf("ParseGitmodules(\"\") = %v, want none", got)
+	}
+}
+
+func TestSubmoduleRecursion(t *testing.T) {
+	if got := submoduleRecursion(false); got != git.NoRecurseSubmodules {
+		t.Errorf("submoduleRecursion(false) = %d, want submodules skipped", got)
+	}
+	if got := submoduleRecursion(true); got != git.DefaultSubmoduleRecursionDepth {
+		t.Errorf("submoduleRecursion(true) = %d, want default depth", got)
+	}
+}
+
+func TestGetRepositoryContentSizeExcludesGitAndSubmodules(t *testing.T) {
+	repoPath := t.TempDir()
+	write := func(rel string, size int) {
+		path := filepath.Join(repoPath, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gitmodules := "[submodule \"theme\"]\n\tpath = theme\n\turl = https://example.com/theme.git\n"
+	if err := os.WriteFile(filepath.Join(repoPath, GitmodulesFile), []byte(gitmodules), 0644); err != nil {
+		t.Fatal(err)
+	}
+	write("note.md", 100)
+	write("theme/big.bin", 5000)
+	write(".git/modules/theme/objects/pack", 7000)
+	write(".git/objects/pack/history.pack", 9000)
+
+	size, err := getRepositoryContentSize(repoPath)
+	if err != nil {
+		t.Fatalf("getRepositoryContentSize() error = %v", err)
+	}
+
+	want := int64(100 + len(gitmodules))
+	if size != want {
+		t.Errorf("getRepositoryContentSize() = %d, want %d", size, want)
+	}
+
+	total, _ := getDirectorySize(repoPath)
+	if total <= size {
+		t.Errorf("getDirectorySize() = %d, expected .git and submodules to be counted there", total)
+	}
+}
@@ -0,0 +1,298 @@
+package github
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/objectstore"
+)
+
+// WorkspaceStore persists local working repositories outside the container so
+// that a fresh deploy can restore ./data instead of re-cloning every repository
+type WorkspaceStore interface {
+	// Restore extracts the snapshot stored under key into destDir.
+	// It returns false (and no error) when no snapshot exists.
+	Restore(key, destDir string) (bool, error)
+	// Snapshot archives srcDir and stores it under key
+	Snapshot(key, srcDir string) error
+}
+
+// workspaceSnapshotInterval limits how often the same repository is uploaded
+const workspaceSnapshotInterval = 10 * time.Minute
+
+var (
+	workspaceStore      WorkspaceStore
+	workspaceStoreMu    sync.RWMutex
+	lastSnapshotTimes   = make(map[string]time.Time)
+	snapshotsRunning    = make(map[string]bool) // Clones being uploaded, guarded by lastSnapshotTimesMu
+	lastSnapshotTimesMu sync.Mutex
+)
+
+// SetWorkspaceStore configures the global workspace store used by clone-based managers.
+// Passing nil disables workspace persistence.
+func SetWorkspaceStore(store WorkspaceStore) {
+	workspaceStoreMu.Lock()
+	defer workspaceStoreMu.Unlock()
+	workspaceStore = store
+}
+
+// GetWorkspaceStore returns the configured workspace store, or nil if none is set
+func GetWorkspaceStore() WorkspaceStore {
+	workspaceStoreMu.RLock()
+	defer workspaceStoreMu.RUnlock()
+	return workspaceStore
+}
+
+// workspaceKey returns the object key for a local repository path
+func workspaceKey(repoPath string) string {
+	return "workspaces/" + filepath.Base(repoPath) + ".tar.gz"
+}
+
+// restoreWorkspace tries to restore repoPath from the workspace store
+func restoreWorkspace(repoPath string) bool {
+	store := GetWorkspaceStore()
+	if store == nil {
+		return false
+	}
+
+	start := time.Now()
+	restored, err := store.Restore(workspaceKey(repoPath), repoPath)
+	if err != nil {
+		logger.Warn("Failed to restore workspace from object storage, falling back to clone", map[string]interface{}{
+			"repo_path": repoPath,
+			"error":     err.Error(),
+		})
+		// Remove partial extraction so the clone starts from a clean directory
+		os.RemoveAll(repoPath)
+		return false
+	}
+
+	if restored {
+		logger.Info("Workspace restored from object storage", map[string]interface{}{
+			"repo_path":   repoPath,
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+	}
+	return restored
+}
+
+// snapshotWorkspace uploads the clone to the workspace store in the background, at most once per
+// interval unless forced, and one upload of a clone at a time. The upload holds the repository
+// lock shared, so it archives a consistent clone and writes wait until it is done.
+func (m *Manager) snapshotWorkspace(force bool) {
+	store := GetWorkspaceStore()
+	if store == nil {
+		return
+	}
+	repoPath := m.repoPath
+
+	lastSnapshotTimesMu.Lock()
+	if last, ok := lastSnapshotTimes[repoPath]; snapshotsRunning[repoPath] || ok && !force && time.Since(last) < workspaceSnapshotInterval {
+		lastSnapshotTimesMu.Unlock()
+		return
+	}
+	lastSnapshotTimes[repoPath] = time.Now()
+	snapshotsRunning[repoPath] = true
+	lastSnapshotTimesMu.Unlock()
+
+	go m.uploadSnapshot(store, repoPath)
+}
+
+// uploadSnapshot stores the snapshot of repoPath, see snapshotWorkspace
+func (m *Manager) uploadSnapshot(store WorkspaceStore, repoPath string) {
+	err := func() error {
+		unlock, err := m.lockWorktree(false)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		return store.Snapshot(workspaceKey(repoPath), repoPath)
+	}()
+
+	lastSnapshotTimesMu.Lock()
+	delete(snapshotsRunning, repoPath)
+	if err != nil {
+		// Allow the next write to retry
+		delete(lastSnapshotTimes, repoPath)
+	}
+	lastSnapshotTimesMu.Unlock()
+
+	if err != nil {
+		logger.Warn("Failed to snapshot workspace to object storage", map[string]interface{}{
+			"repo_path": repoPath,
+			"error":     err.Error(),
+		})
+		return
+	}
+	logger.Debug("Workspace snapshot stored", map[string]interface{}{
+		"repo_path": repoPath,
+	})
+}
+
+// archiveDirectory writes srcDir as a gzip-compressed tar stream
+func archiveDirectory(srcDir string, w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		// Only regular files and directories are needed for a git working tree
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive directory: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize tar archive: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize gzip stream: %w", err)
+	}
+	return nil
+}
+
+// extractArchive extracts a gzip-compressed tar stream into destDir
+func extractArchive(r io.Reader, destDir string) error {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("failed to open gzip stream: %w", err)
+	}
+	defer gr.Close()
+
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return fmt.Errorf("failed to create destination directory: %w", err)
+	}
+
+	cleanDest := filepath.Clean(destDir)
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		target := filepath.Join(cleanDest, filepath.FromSlash(header.Name))
+		// Reject entries that would escape the destination directory
+		if target != cleanDest && !strings.HasPrefix(target, cleanDest+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid archive entry: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode)&0777)
+			if err != nil {
+				return fmt.Errorf("failed to create file: %w", err)
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to write file: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to close file: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// S3WorkspaceStore stores workspace snapshots in an S3-compatible bucket
+// (AWS S3, MinIO, Cloudflare R2, GCS interoperability mode, ...)
+type S3WorkspaceStore struct {
+	client *objectstore.Client
+}
+
+// NewS3WorkspaceStore creates a new S3-compatible workspace store using path-style addressing
+func NewS3WorkspaceStore(endpoint, bucket, region, accessKey, secretKey string) (*S3WorkspaceStore, error) {
+	client, err := objectstore.New(endpoint, bucket, region, accessKey, secretKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace store: %w", err)
+	}
+	return &S3WorkspaceStore{client: client}, nil
+}
+
+// Restore downloads and extracts the snapshot for key into destDir
+func (s *S3WorkspaceStore) Restore(key, destDir string) (bool, error) {
+	body, err := s.client.Get(key)
+	if errors.Is(err, objectstore.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	if err := extractArchive(body, destDir); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Snapshot archives srcDir and uploads it under key, streaming the archive as it is written
+func (s *S3WorkspaceStore) Snapshot(key, srcDir string) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(archiveDirectory(srcDir, writer))
+	}()
+
+	err := s.client.PutStream(key, "application/gzip", reader, -1)
+	// Stops the archive if the upload failed early
+	reader.CloseWithError(err)
+	return err
+}
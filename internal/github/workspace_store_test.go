@@ -0,0 +1,191 @@
+package github
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeObjectStorage is a minimal in-memory S3-compatible server
+type fakeObjectStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeObjectStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method {
+	case "PUT":
+		data, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = data
+		w.WriteHeader(http.StatusOK)
+	case "GET":
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3WorkspaceStore_SnapshotAndRestore(t *testing.T) {
+	fake := &fakeObjectStorage{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3WorkspaceStore(server.URL, "bucket", "", "access", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	srcDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(srcDir, ".git", "refs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "note.md"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	key := workspaceKey("./data/notes-repo-test-12345678")
+	if err := store.Snapshot(key, srcDir); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	destDir := filepath.Join(t.TempDir(), "restored")
+	restored, err := store.Restore(key, destDir)
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if !restored {
+		t.Fatal("Expected snapshot to be restored")
+	}
+
+	data, err := os.ReadFile(filepath.Join(destDir, "note.md"))
+	if err != nil || string(data) != "hello" {
+		t.Errorf("Restored note.md = %q, %v", string(data), err)
+	}
+	data, err = os.ReadFile(filepath.Join(destDir, ".git", "HEAD"))
+	if err != nil || string(data) != "ref: refs/heads/main\n" {
+		t.Errorf("Restored .git/HEAD = %q, %v", string(data), err)
+	}
+}
+
+// blockingStore records snapshots, each waits for release
+type blockingStore struct {
+	started chan string
+	release chan struct{}
+}
+
+func (b *blockingStore) Restore(key, destDir string) (bool, error) { return false, nil }
+
+func (b *blockingStore) Snapshot(key, srcDir string) error {
+	b.started <- srcDir
+	<-b.release
+	return nil
+}
+
+func TestSnapshotWorkspaceRunsInBackground(t *testing.T) {
+	store := &blockingStore{started: make(chan string, 2), release: make(chan struct{})}
+	SetWorkspaceStore(store)
+	defer SetWorkspaceStore(nil)
+
+	dir := t.TempDir()
+	m := &Manager{repoPath: dir}
+	m.snapshotWorkspace(true)
+	select {
+	case <-store.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("snapshot did not start")
+	}
+
+	// snapshotWorkspace returned while the upload runs, another one of the clone is skipped
+	m.snapshotWorkspace(true)
+	close(store.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		lastSnapshotTimesMu.Lock()
+		running := snapshotsRunning[dir]
+		lastSnapshotTimesMu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("snapshot did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(store.started) != 0 {
+		t.Error("a second snapshot of the clone started while one was running")
+	}
+}
+
+func TestS3WorkspaceStore_RestoreMissing(t *testing.T) {
+	fake := &fakeObjectStorage{objects: make(map[string][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3WorkspaceStore(server.URL, "bucket", "us-east-1", "access", "secret")
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	restored, err := store.Restore("workspaces/missing.tar.gz", t.TempDir())
+	if err != nil {
+		t.Fatalf("Restore of missing snapshot should not error: %v", err)
+	}
+	if restored {
+		t.Error("Missing snapshot should not be reported as restored")
+	}
+}
+
+func TestNewS3WorkspaceStore_Validation(t *testing.T) {
+	if _, err := NewS3WorkspaceStore("", "bucket", "", "a", "b"); err == nil {
+		t.Error("Expected error for missing endpoint")
+	}
+	if _, err := NewS3WorkspaceStore("https://s3.example.com", "bucket", "", "", ""); err == nil {
+		t.Error("Expected error for missing credentials")
+	}
+}
+
+func TestExtractArchive_RejectsPathTraversal(t *testing.T) {
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	content := []byte("evil")
+	if err := tw.WriteHeader(&tar.Header{Name: "../escape.md", Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	tw.Write(content)
+	tw.Close()
+	gw.Close()
+
+	destDir := filepath.Join(t.TempDir(), "dest")
+	if err := extractArchive(&buf, destDir); err == nil {
+		t.Error("Expected path traversal entry to be rejected")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(destDir), "escape.md")); err == nil {
+		t.Error("Escaping file should not have been written")
+	}
+}
@@ -0,0 +1,512 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/metrics"
+)
+
+// Worktree filesystem: the files of a clone's worktree are read and written through a WorktreeFS
+// rooted at the clone instead of the os package. The default stack checks every write against the
+// disk quota of the user's premium level and records each operation in the metrics, and
+// SetWorktreeFSFactory swaps the local disk for another backend (object storage, zero retention)
+// without touching call sites. go-git and the housekeeping of ./data still use the disk directly.
+
+// WorktreeFS accesses the files of a worktree by slash-separated names relative to its root
+type WorktreeFS interface {
+	Open(name string) (io.ReadSeekCloser, error)
+	ReadFile(name string) ([]byte, error)
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+	// WalkDir walks the tree at name like fs.WalkDir, passing names relative to the root
+	WalkDir(name string, fn fs.WalkDirFunc) error
+	// WriteFile replaces name with data atomically, creating parent directories as needed
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	// WriteFileFunc replaces name atomically with what write writes, so content can be streamed
+	WriteFileFunc(name string, perm fs.FileMode, write func(w io.Writer) error) error
+	MkdirAll(name string, perm fs.FileMode) error
+	Remove(name string) error
+	Rename(oldName, newName string) error
+}
+
+// WorktreeFSFactory creates the filesystem of the worktree at root
+type WorktreeFSFactory func(root string) WorktreeFS
+
+var (
+	worktreeFSFactory   WorktreeFSFactory
+	worktreeFSFactoryMu sync.RWMutex
+)
+
+// SetWorktreeFSFactory configures the backend of worktree files of clone-based managers created
+// afterwards. Quota checks and metrics wrap whatever it returns; nil restores the local disk.
+func SetWorktreeFSFactory(factory WorktreeFSFactory) {
+	worktreeFSFactoryMu.Lock()
+	defer worktreeFSFactoryMu.Unlock()
+	worktreeFSFactory = factory
+}
+
+// newWorktreeFS creates the filesystem of the worktree at root, with writes limited to quota bytes
+// of content (see worktreeContentSize) if quota returns more than zero. Layers wrap the backend
+// in order, beneath the quota.
+func newWorktreeFS(root string, quota func() int64, layers ...func(WorktreeFS) WorktreeFS) WorktreeFS {
+	worktreeFSFactoryMu.RLock()
+	factory := worktreeFSFactory
+	worktreeFSFactoryMu.RUnlock()
+
+	var backend WorktreeFS = diskFS{root: root}
+	if factory != nil {
+		backend = factory(root)
+	}
+	for _, layer := range layers {
+		backend = layer(backend)
+	}
+	return meteredFS{&quotaFS{WorktreeFS: backend, limit: quota}}
+}
+
+// files returns the filesystem of the clone's worktree
+func (m *Manager) files() WorktreeFS {
+	m.filesOnce.Do(func() {
+		m.fs = newWorktreeFS(m.repoPath, m.diskQuota, func(backend WorktreeFS) WorktreeFS {
+			m.backend = backend
+			return m.sparseLayer(backend)
+		})
+	})
+	return m.fs
+}
+
+// backendFiles returns the backend of files(), without the quota and sparse checkout layers. Files
+// checked out of the index are written to it: they aren't new content, and the sparse layer
+// checks them out while the quota layer holds its lock.
+func (m *Manager) backendFiles() WorktreeFS {
+	m.files()
+	return m.backend
+}
+
+// worktreeChanged tells the worktree filesystem that git changed the files directly, as resets and
+// checkouts do, so the content size kept for the quota is measured again
+func (m *Manager) worktreeChanged() {
+	if metered, ok := m.files().(meteredFS); ok {
+		if quota, ok := metered.WorktreeFS.(*quotaFS); ok {
+			quota.invalidate()
+		}
+	}
+}
+
+// diskQuota returns how many bytes of content the worktree may hold on the user's premium level
+func (m *Manager) diskQuota() int64 {
+	return int64(m.GetRepositoryMaxSizeWithPremium(m.premiumLevel) * 1024 * 1024)
+}
+
+// contentSizer is implemented by filesystems that know their content size without walking it
+type contentSizer interface {
+	contentSize() (int64, error)
+}
+
+// worktreeContentSize returns the user-facing size of a worktree: its files without .git history
+// and submodule working trees
+func worktreeContentSize(fsys WorktreeFS) (int64, error) {
+	if sizer, ok := fsys.(contentSizer); ok {
+		return sizer.contentSize()
+	}
+
+	excluded := map[string]bool{".git": true}
+	if data, err := fsys.ReadFile(GitmodulesFile); err == nil {
+		for _, submodule := range ParseGitmodules(string(data)) {
+			excluded[path.Clean(submodule)] = true
+		}
+	}
+
+	var size int64
+	err := fsys.WalkDir(".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if excluded[name] {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() {
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// diskFS is a worktree on the local disk. Names that leave the root or go through a symbolic link
+// of the worktree are refused: a repository may commit links pointing anywhere on the host.
+type diskFS struct {
+	root string
+}
+
+// ErrSymlink is returned for names of a local worktree that go through a symbolic link
+var ErrSymlink = errors.New("symbolic links are not followed")
+
+// path returns the disk path of name after checking that it stays inside the root and that none of
+// its elements is a symbolic link. The last element is only checked if followLast: removing or
+// replacing a link doesn't follow it.
+func (d diskFS) path(name string, followLast bool) (string, error) {
+	name = path.Clean(filepath.ToSlash(name))
+	if name == "." || name == "/" {
+		return d.root, nil
+	}
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return "", fmt.Errorf("%s: path is outside the repository", name)
+	}
+
+	elements := strings.Split(name, "/")
+	current := d.root
+	for i, element := range elements {
+		if i == len(elements)-1 && !followLast {
+			break
+		}
+		current = filepath.Join(current, element)
+		info, err := os.Lstat(current)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Nothing below a missing element can be a link, the operation reports it missing
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink != 0 {
+			return "", fmt.Errorf("%s: %w", name, ErrSymlink)
+		}
+	}
+	return filepath.Join(d.root, filepath.FromSlash(name)), nil
+}
+
+func (d diskFS) Open(name string) (io.ReadSeekCloser, error) {
+	p, err := d.path(name, true)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+func (d diskFS) ReadFile(name string) ([]byte, error) {
+	p, err := d.path(name, true)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadFile(p)
+}
+
+func (d diskFS) Stat(name string) (fs.FileInfo, error) {
+	p, err := d.path(name, true)
+	if err != nil {
+		return nil, err
+	}
+	return os.Stat(p)
+}
+
+func (d diskFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	p, err := d.path(name, true)
+	if err != nil {
+		return nil, err
+	}
+	return os.ReadDir(p)
+}
+
+func (d diskFS) WalkDir(name string, fn fs.WalkDirFunc) error {
+	// filepath.WalkDir reports links as entries without following them
+	root, err := d.path(name, true)
+	if err != nil {
+		return err
+	}
+	return filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		rel, relErr := filepath.Rel(d.root, p)
+		if relErr != nil {
+			return relErr
+		}
+		return fn(filepath.ToSlash(rel), entry, err)
+	})
+}
+
+func (d diskFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	p, err := d.path(name, false)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(p, data, perm)
+}
+
+func (d diskFS) WriteFileFunc(name string, perm fs.FileMode, write func(w io.Writer) error) error {
+	p, err := d.path(name, false)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomicFunc(p, perm, write)
+}
+
+func (d diskFS) MkdirAll(name string, perm fs.FileMode) error {
+	p, err := d.path(name, true)
+	if err != nil {
+		return err
+	}
+	return os.MkdirAll(p, perm)
+}
+
+func (d diskFS) Remove(name string) error {
+	p, err := d.path(name, false)
+	if err != nil {
+		return err
+	}
+	return os.Remove(p)
+}
+
+func (d diskFS) Rename(oldName, newName string) error {
+	oldPath, err := d.path(oldName, false)
+	if err != nil {
+		return err
+	}
+	newPath, err := d.path(newName, false)
+	if err != nil {
+		return err
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// ErrDiskQuotaExceeded is returned by writes that would grow a worktree beyond the disk quota
+var ErrDiskQuotaExceeded = errors.New("disk quota exceeded")
+
+// quotaFS fails writes that would grow the content of the worktree beyond limit. The content size
+// is measured on the first write and kept up to date by later writes and removals.
+type quotaFS struct {
+	WorktreeFS
+	limit func() int64
+
+	mu       sync.Mutex
+	used     int64
+	measured bool
+}
+
+// invalidate drops the measured content size, it's measured again on the next write
+func (q *quotaFS) invalidate() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.measured = false
+}
+
+// usage returns the content size of the worktree, measuring it once
+func (q *quotaFS) usage() (int64, error) {
+	if !q.measured {
+		used, err := worktreeContentSize(q.WorktreeFS)
+		if err != nil {
+			return 0, err
+		}
+		q.used, q.measured = used, true
+	}
+	return q.used, nil
+}
+
+// fileSize returns the size of name, 0 if it doesn't exist
+func (q *quotaFS) fileSize(name string) int64 {
+	if info, err := q.WorktreeFS.Stat(name); err == nil && !info.IsDir() {
+		return info.Size()
+	}
+	return 0
+}
+
+func (q *quotaFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return q.WriteFileFunc(name, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (q *quotaFS) WriteFileFunc(name string, perm fs.FileMode, write func(w io.Writer) error) error {
+	limit := q.limit()
+	if limit <= 0 {
+		return q.WorktreeFS.WriteFileFunc(name, perm, write)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	used, err := q.usage()
+	if err != nil {
+		// Like the size check before commits, a failed measurement doesn't block writes
+		logger.Warn("Failed to measure worktree for the disk quota", map[string]interface{}{
+			"file":  name,
+			"error": err.Error(),
+		})
+		return q.WorktreeFS.WriteFileFunc(name, perm, write)
+	}
+
+	previous := q.fileSize(name)
+	writer := &quotaWriter{
+		remaining: limit - used + previous,
+		exceeded: fmt.Errorf("%w: writing %s exceeds your tier limit of %.1fMB",
+			ErrDiskQuotaExceeded, name, float64(limit)/1024/1024),
+	}
+	err = q.WorktreeFS.WriteFileFunc(name, perm, func(w io.Writer) error {
+		writer.w = w
+		return write(writer)
+	})
+	if err != nil {
+		return err
+	}
+	q.used = used - previous + writer.written
+	return nil
+}
+
+func (q *quotaFS) Remove(name string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	size := q.fileSize(name)
+	if err := q.WorktreeFS.Remove(name); err != nil {
+		return err
+	}
+	if q.measured {
+		q.used -= size
+	}
+	return nil
+}
+
+// quotaWriter fails once more than remaining bytes are written; as writes go to a temp file first,
+// the file keeps its previous content
+type quotaWriter struct {
+	w         io.Writer
+	remaining int64
+	written   int64
+	exceeded  error
+}
+
+func (q *quotaWriter) Write(p []byte) (int, error) {
+	if q.written+int64(len(p)) > q.remaining {
+		return 0, q.exceeded
+	}
+	n, err := q.w.Write(p)
+	q.written += int64(n)
+	return n, err
+}
+
+// meteredFS records every operation and the bytes read and written in the metrics
+type meteredFS struct {
+	WorktreeFS
+}
+
+func recordFileIO(operation string, err error) {
+	metrics.Default.RecordFileIO(operation, metrics.Status(err))
+}
+
+func (m meteredFS) Open(name string) (io.ReadSeekCloser, error) {
+	file, err := m.WorktreeFS.Open(name)
+	recordFileIO("open", err)
+	if err != nil {
+		return nil, err
+	}
+	return &meteredReader{ReadSeekCloser: file}, nil
+}
+
+func (m meteredFS) ReadFile(name string) ([]byte, error) {
+	data, err := m.WorktreeFS.ReadFile(name)
+	recordFileIO("read", err)
+	metrics.Default.RecordFileIOBytes("read", int64(len(data)))
+	return data, err
+}
+
+func (m meteredFS) Stat(name string) (fs.FileInfo, error) {
+	info, err := m.WorktreeFS.Stat(name)
+	// A missing file is an answer, not a failure
+	if errors.Is(err, fs.ErrNotExist) {
+		recordFileIO("stat", nil)
+	} else {
+		recordFileIO("stat", err)
+	}
+	return info, err
+}
+
+func (m meteredFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := m.WorktreeFS.ReadDir(name)
+	recordFileIO("readdir", err)
+	return entries, err
+}
+
+func (m meteredFS) WalkDir(name string, fn fs.WalkDirFunc) error {
+	err := m.WorktreeFS.WalkDir(name, fn)
+	recordFileIO("walk", err)
+	return err
+}
+
+func (m meteredFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	err := m.WorktreeFS.WriteFile(name, data, perm)
+	recordFileIO("write", err)
+	if err == nil {
+		metrics.Default.RecordFileIOBytes("write", int64(len(data)))
+	}
+	return err
+}
+
+func (m meteredFS) WriteFileFunc(name string, perm fs.FileMode, write func(w io.Writer) error) error {
+	var written int64
+	err := m.WorktreeFS.WriteFileFunc(name, perm, func(w io.Writer) error {
+		counter := &countingWriter{w: w}
+		err := write(counter)
+		written = counter.n
+		return err
+	})
+	recordFileIO("write", err)
+	if err == nil {
+		metrics.Default.RecordFileIOBytes("write", written)
+	}
+	return err
+}
+
+func (m meteredFS) MkdirAll(name string, perm fs.FileMode) error {
+	err := m.WorktreeFS.MkdirAll(name, perm)
+	recordFileIO("mkdir", err)
+	return err
+}
+
+func (m meteredFS) Remove(name string) error {
+	err := m.WorktreeFS.Remove(name)
+	recordFileIO("remove", err)
+	return err
+}
+
+func (m meteredFS) Rename(oldName, newName string) error {
+	err := m.WorktreeFS.Rename(oldName, newName)
+	recordFileIO("rename", err)
+	return err
+}
+
+// meteredReader records the bytes read from an opened file
+type meteredReader struct {
+	io.ReadSeekCloser
+}
+
+func (r *meteredReader) Read(p []byte) (int, error) {
+	n, err := r.ReadSeekCloser.Read(p)
+	metrics.Default.RecordFileIOBytes("read", int64(n))
+	return n, err
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,177 @@
+package github
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuotaFS(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "inbox.md"), []byte(strings.Repeat("a", 60)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	// History doesn't count towards the quota
+	if err := os.WriteFile(filepath.Join(dir, ".git", "pack"), []byte(strings.Repeat("x", 500)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fsys := newWorktreeFS(dir, func() int64 { return 100 })
+
+	// Replacing a file counts only what it grows by
+	if err := fsys.WriteFile("inbox.md", []byte(strings.Repeat("b", 90)), 0644); err != nil {
+		t.Fatalf("WriteFile() within quota error = %v", err)
+	}
+
+	err := fsys.WriteFileFunc("todo.md", 0644, func(w io.Writer) error {
+		_, err := io.WriteString(w, strings.Repeat("c", 20))
+		return err
+	})
+	if !errors.Is(err, ErrDiskQuotaExceeded) || !strings.Contains(err.Error(), "exceeds your tier limit") {
+		t.Fatalf("WriteFileFunc() beyond quota error = %v, want ErrDiskQuotaExceeded", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "todo.md")); !os.IsNotExist(err) {
+		t.Errorf("todo.md exists after a write beyond the quota")
+	}
+
+	// Removing a file frees its space
+	if err := fsys.Remove("inbox.md"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fsys.WriteFile("notes/todo.md", []byte(strings.Repeat("c", 20)), 0644); err != nil {
+		t.Errorf("WriteFile() after removal error = %v", err)
+	}
+	if data, err := fsys.ReadFile("notes/todo.md"); err != nil || len(data) != 20 {
+		t.Errorf("ReadFile() = %d bytes, %v", len(data), err)
+	}
+}
+
+func TestQuotaFSInvalidate(t *testing.T) {
+	dir := t.TempDir()
+	fsys := newWorktreeFS(dir, func() int64 { return 100 })
+	if err := fsys.WriteFile("inbox.md", []byte(strings.Repeat("a", 50)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reset writes files behind the filesystem's back
+	if err := os.WriteFile(filepath.Join(dir, "todo.md"), []byte(strings.Repeat("b", 40)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fsys.(meteredFS).WorktreeFS.(*quotaFS).invalidate()
+
+	if err := fsys.WriteFile("notes.md", []byte(strings.Repeat("c", 20)), 0644); !errors.Is(err, ErrDiskQuotaExceeded) {
+		t.Errorf("WriteFile() beyond the measured quota error = %v, want ErrDiskQuotaExceeded", err)
+	}
+}
+
+func TestQuotaFSUnlimited(t *testing.T) {
+	fsys := newWorktreeFS(t.TempDir(), func() int64 { return 0 })
+	if err := fsys.WriteFile("inbox.md", []byte(strings.Repeat("a", 1000)), 0644); err != nil {
+		t.Errorf("WriteFile() without quota error = %v", err)
+	}
+}
+
+func TestDiskFSRefusesSymlinks(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret"), []byte("host file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Symlink(filepath.Join(outside, "secret"), filepath.Join(dir, "link.md")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "linked")); err != nil {
+		t.Fatal(err)
+	}
+	fsys := diskFS{root: dir}
+
+	for _, name := range []string{"link.md", "linked/secret"} {
+		if _, err := fsys.ReadFile(name); !errors.Is(err, ErrSymlink) {
+			t.Errorf("ReadFile(%q) error = %v, want ErrSymlink", name, err)
+		}
+		if _, err := fsys.Open(name); !errors.Is(err, ErrSymlink) {
+			t.Errorf("Open(%q) error = %v, want ErrSymlink", name, err)
+		}
+	}
+	if _, err := fsys.ReadDir("linked"); !errors.Is(err, ErrSymlink) {
+		t.Errorf("ReadDir(linked) error = %v, want ErrSymlink", err)
+	}
+	if err := fsys.WriteFile("linked/new.md", []byte("x"), 0644); !errors.Is(err, ErrSymlink) {
+		t.Errorf("WriteFile(linked/new.md) error = %v, want ErrSymlink", err)
+	}
+	if _, err := fsys.ReadFile("../secret"); err == nil {
+		t.Errorf("ReadFile(../secret) succeeded, want an error")
+	}
+
+	// Replacing a link writes a regular file in the worktree
+	if err := fsys.WriteFile("link.md", []byte("note"), 0644); err != nil {
+		t.Fatalf("WriteFile(link.md) error = %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(outside, "secret")); string(data) != "host file" {
+		t.Errorf("host file = %q after replacing the link", data)
+	}
+}
+
+func TestWorktreeFSWalkDir(t *testing.T) {
+	dir := t.TempDir()
+	fsys := newWorktreeFS(dir, func() int64 { return 0 })
+	for _, name := range []string{"inbox.md", "notes/idea.md"} {
+		if err := fsys.WriteFile(name, []byte("note\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var files []string
+	err := fsys.WalkDir(".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			files = append(files, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(files, ",") != "inbox.md,notes/idea.md" {
+		t.Errorf("WalkDir() files = %v, want names relative to the root", files)
+	}
+	if size, err := worktreeContentSize(fsys); err != nil || size != 10 {
+		t.Errorf("worktreeContentSize() = %d, %v, want 10", size, err)
+	}
+}
+
+// recordingFS is a backend recording the files written through it
+type recordingFS struct {
+	WorktreeFS
+	written []string
+}
+
+func (r *recordingFS) WriteFileFunc(name string, perm fs.FileMode, write func(w io.Writer) error) error {
+	r.written = append(r.written, name)
+	return r.WorktreeFS.WriteFileFunc(name, perm, write)
+}
+
+func TestSetWorktreeFSFactory(t *testing.T) {
+	backend := &recordingFS{WorktreeFS: diskFS{root: t.TempDir()}}
+	SetWorktreeFSFactory(func(root string) WorktreeFS { return backend })
+	t.Cleanup(func() { SetWorktreeFSFactory(nil) })
+
+	m := &Manager{repoPath: t.TempDir()}
+	if err := m.prependToFile("inbox.md", "## note\n"); err != nil {
+		t.Fatal(err)
+	}
+	if len(backend.written) != 1 || backend.written[0] != "inbox.md" {
+		t.Errorf("backend wrote %v, want inbox.md", backend.written)
+	}
+	if _, err := os.Stat(filepath.Join(m.repoPath, "inbox.md")); !os.IsNotExist(err) {
+		t.Errorf("inbox.md was written to the clone instead of the backend")
+	}
+}
@@ -0,0 +1,13 @@
+package github
+
+// Write quotas: deployments can refuse commits that add content, e.g. once the repositories of a
+// tenant use up their shared disk space. Both providers check ProviderConfig.WriteQuota before
+// every commit and upload adding content; deletes and moves go through so space can be freed.
+
+// checkWriteQuota returns the error of quota, nil without a quota
+func checkWriteQuota(quota func() error) error {
+	if quota == nil {
+		return nil
+	}
+	return quota()
+}
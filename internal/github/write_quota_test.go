@@ -0,0 +1,37 @@
+package github
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAPIProvider_WriteQuota(t *testing.T) {
+	fake, cfg := newFakeGitHub(t)
+	fake.SetFile("owner", "notes", "note.md", "old entry")
+
+	errFull := errors.New("tenant storage full")
+	config := NewProviderConfig(cfg, 0, "42")
+	config.WriteQuota = func() error { return errFull }
+	provider, err := NewAPIBasedProvider(config)
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	if err := provider.CommitFileWithAuthor("note.md", "new entry", "Add note", cfg.CommitAuthor); !errors.Is(err, errFull) {
+		t.Errorf("CommitFileWithAuthor() error = %v, want the quota error", err)
+	}
+	if err := provider.ReplaceMultipleFilesWithAuthorAndPremium(map[string]string{"a.md": "a"}, "Add a", cfg.CommitAuthor, 0); !errors.Is(err, errFull) {
+		t.Errorf("ReplaceMultipleFilesWithAuthorAndPremium() error = %v, want the quota error", err)
+	}
+	if content, _ := fake.File("owner", "notes", "note.md"); content != "old entry" {
+		t.Errorf("note.md = %q, want it unchanged", content)
+	}
+
+	// Deleting frees space, the quota doesn't stop it
+	if err := provider.DeleteFile("note.md", "Remove note", cfg.CommitAuthor); err != nil {
+		t.Fatalf("DeleteFile() error = %v", err)
+	}
+	if _, exists := fake.File("owner", "notes", "note.md"); exists {
+		t.Error("Expected note.md to be deleted")
+	}
+}
@@ -0,0 +1,166 @@
+package limits
+
+import (
+	"fmt"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+// Usage limits: issues, images, attached files and shared LLM tokens are counted per user in user_usage and
+// capped by premium level. Every call site checks them through Service so the comparison, the
+// numbers shown to users and the upgrade hint stay the same everywhere.
+
+// MaxPremiumLevel is the highest premium level (Sponsor)
+const MaxPremiumLevel = 3
+
+// Kind is a counted resource
+type Kind string
+
+const (
+	Issues Kind = "issues"
+	Images Kind = "images"
+	Files  Kind = "files"
+	Tokens Kind = "tokens"
+)
+
+// Store reads usage counters, implemented by *database.DB
+type Store interface {
+	GetUserUsage(uid int64) (*database.UserUsage, error)
+}
+
+// Result is the outcome of a limit check
+type Result struct {
+	Kind    Kind
+	Allowed bool  // Current plus the requested amount fits within Max
+	Current int64 // Used since the last usage reset
+	Max     int64 // Limit of the premium level
+	Reset   ResetHint
+}
+
+// ResetHint tells how more becomes available once a limit is reached: counters go back to zero
+// with /resetusage, and a higher premium level raises the limit
+type ResetHint struct {
+	NextLevel int   // Next premium level, 0 when already on the highest
+	NextMax   int64 // Limit on NextLevel
+}
+
+// Remaining returns how much is left before the limit, never negative
+func (r Result) Remaining() int64 {
+	if r.Current >= r.Max {
+		return 0
+	}
+	return r.Max - r.Current
+}
+
+// Percentage returns the share of the limit used
+func (r Result) Percentage() float64 {
+	if r.Max <= 0 {
+		return 0
+	}
+	return float64(r.Current) / float64(r.Max) * 100
+}
+
+// Max returns the limit of kind on premiumLevel
+func Max(kind Kind, premiumLevel int) int64 {
+	switch kind {
+	case Issues:
+		return database.GetIssueLimit(premiumLevel)
+	case Images:
+		return database.GetImageLimit(premiumLevel)
+	case Files:
+		return database.GetFileLimit(premiumLevel)
+	case Tokens:
+		return database.GetTokenLimit(premiumLevel)
+	default:
+		return 0
+	}
+}
+
+// Service checks usage against the limits of a premium level
+type Service struct {
+	store Store
+}
+
+// New creates a limits service reading counters from store
+func New(store Store) *Service {
+	return &Service{store: store}
+}
+
+// Kinds lists the counted resources
+var Kinds = []Kind{Issues, Images, Files, Tokens}
+
+// Check reports whether amount more of kind fits within the user's limit
+func (s *Service) Check(uid int64, kind Kind, premiumLevel int, amount int64) (Result, error) {
+	if Max(kind, premiumLevel) == 0 {
+		return Result{}, fmt.Errorf("unknown limit kind %q", kind)
+	}
+
+	usage, err := s.store.GetUserUsage(uid)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to get user usage: %w", err)
+	}
+	return evaluate(kind, premiumLevel, usage, amount), nil
+}
+
+// All returns the current usage of every kind, reading the counters once
+func (s *Service) All(uid int64, premiumLevel int) ([]Result, error) {
+	usage, err := s.store.GetUserUsage(uid)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user usage: %w", err)
+	}
+
+	results := make([]Result, 0, len(Kinds))
+	for _, kind := range Kinds {
+		results = append(results, evaluate(kind, premiumLevel, usage, 0))
+	}
+	return results, nil
+}
+
+// evaluate compares usage plus amount against the limit of kind, usage may be nil
+func evaluate(kind Kind, premiumLevel int, usage *database.UserUsage, amount int64) Result {
+	current := int64(0)
+	if usage != nil {
+		switch kind {
+		case Issues:
+			current = usage.IssueCnt
+		case Images:
+			current = usage.ImageCnt
+		case Files:
+			current = usage.FileCnt
+		case Tokens:
+			current = usage.TokenInput + usage.TokenOutput
+		}
+	}
+
+	max := Max(kind, premiumLevel)
+	result := Result{
+		Kind:    kind,
+		Allowed: current+amount <= max,
+		Current: current,
+		Max:     max,
+	}
+	if premiumLevel < MaxPremiumLevel {
+		result.Reset = ResetHint{NextLevel: premiumLevel + 1, NextMax: Max(kind, premiumLevel+1)}
+	}
+	return result
+}
+
+// Issues checks whether the user can create one more issue
+func (s *Service) Issues(uid int64, premiumLevel int) (Result, error) {
+	return s.Check(uid, Issues, premiumLevel, 1)
+}
+
+// Images checks whether the user can upload one more image
+func (s *Service) Images(uid int64, premiumLevel int) (Result, error) {
+	return s.Check(uid, Images, premiumLevel, 1)
+}
+
+// Files checks whether the user can attach one more file
+func (s *Service) Files(uid int64, premiumLevel int) (Result, error) {
+	return s.Check(uid, Files, premiumLevel, 1)
+}
+
+// Tokens checks whether estimatedTokens more shared LLM tokens fit within the user's limit
+func (s *Service) Tokens(uid int64, premiumLevel int, estimatedTokens int64) (Result, error) {
+	return s.Check(uid, Tokens, premiumLevel, estimatedTokens)
+}
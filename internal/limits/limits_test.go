@@ -0,0 +1,124 @@
+package limits
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+type fakeStore struct {
+	usage *database.UserUsage
+	err   error
+	calls int
+}
+
+func (f *fakeStore) GetUserUsage(uid int64) (*database.UserUsage, error) {
+	f.calls++
+	return f.usage, f.err
+}
+
+func TestCheck(t *testing.T) {
+	store := &fakeStore{usage: &database.UserUsage{IssueCnt: 89, ImageCnt: 90, FileCnt: 30, TokenInput: 60000, TokenOutput: 39900}}
+	service := New(store)
+
+	tests := []struct {
+		name         string
+		kind         Kind
+		premiumLevel int
+		amount       int64
+		allowed      bool
+		current      int64
+		max          int64
+	}{
+		{"last issue", Issues, 0, 1, true, 89, 90},
+		{"image limit reached", Images, 0, 1, false, 90, 90},
+		{"image limit on coffee", Images, 1, 1, true, 90, 180},
+		{"file limit reached", Files, 0, 1, false, 30, 30},
+		{"file limit on cake", Files, 2, 1, true, 30, 120},
+		{"tokens within limit", Tokens, 0, 100, true, 99900, 100000},
+		{"tokens over limit", Tokens, 0, 101, false, 99900, 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := service.Check(1, tt.kind, tt.premiumLevel, tt.amount)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if result.Allowed != tt.allowed || result.Current != tt.current || result.Max != tt.max {
+				t.Errorf("Check() = %+v, want allowed %v, %d/%d", result, tt.allowed, tt.current, tt.max)
+			}
+		})
+	}
+}
+
+func TestCheckWithoutUsage(t *testing.T) {
+	result, err := New(&fakeStore{}).Issues(1, 0)
+	if err != nil {
+		t.Fatalf("Issues() error = %v", err)
+	}
+	if !result.Allowed || result.Current != 0 || result.Remaining() != 90 {
+		t.Errorf("Issues() = %+v, want everything available", result)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	if _, err := New(&fakeStore{err: errors.New("connection refused")}).Images(1, 0); err == nil {
+		t.Error("Images() expected the store error")
+	}
+	if _, err := New(&fakeStore{}).Check(1, Kind("repos"), 0, 1); err == nil {
+		t.Error("Check() expected an error for an unknown kind")
+	}
+}
+
+func TestResetHint(t *testing.T) {
+	service := New(&fakeStore{usage: &database.UserUsage{IssueCnt: 500}})
+
+	result, err := service.Issues(1, 2)
+	if err != nil {
+		t.Fatalf("Issues() error = %v", err)
+	}
+	if result.Reset.NextLevel != 3 || result.Reset.NextMax != database.GetIssueLimit(3) {
+		t.Errorf("Reset = %+v, want the Sponsor limit", result.Reset)
+	}
+
+	result, err = service.Issues(1, MaxPremiumLevel)
+	if err != nil {
+		t.Fatalf("Issues() error = %v", err)
+	}
+	if result.Reset != (ResetHint{}) {
+		t.Errorf("Reset = %+v, want none on the highest tier", result.Reset)
+	}
+}
+
+func TestRemainingAndPercentage(t *testing.T) {
+	result := Result{Current: 120, Max: 100}
+	if result.Remaining() != 0 {
+		t.Errorf("Remaining() = %d, want 0 past the limit", result.Remaining())
+	}
+	if result.Percentage() != 120 {
+		t.Errorf("Percentage() = %v, want 120", result.Percentage())
+	}
+}
+
+func TestAll(t *testing.T) {
+	store := &fakeStore{usage: &database.UserUsage{IssueCnt: 45, ImageCnt: 9, FileCnt: 6, TokenInput: 50000}}
+
+	results, err := New(store).All(1, 0)
+	if err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if store.calls != 1 {
+		t.Errorf("All() read usage %d times, want once", store.calls)
+	}
+	if len(results) != len(Kinds) {
+		t.Fatalf("All() returned %d results", len(results))
+	}
+	want := map[Kind]float64{Issues: 50, Images: 10, Files: 20, Tokens: 50}
+	for _, result := range results {
+		if result.Percentage() != want[result.Kind] {
+			t.Errorf("%s percentage = %v, want %v", result.Kind, result.Percentage(), want[result.Kind])
+		}
+	}
+}
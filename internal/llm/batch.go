@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Batched summaries: content too large for one request is split into chunks that are summarized one
+// by one, then the chunk summaries are reduced into a single summary. The whole run is estimated
+// against a token budget before the first request and checked again before every request, so a run
+// that runs out of budget or hits an error stops with the summaries it already has.
+
+const (
+	// DefaultChunkTokens keeps each request well inside the context window of the supported models
+	DefaultChunkTokens = 8000
+	// promptOverheadTokens is the estimated size of the prompt around the content of a request
+	promptOverheadTokens = 50
+	// summaryTokens is the estimated size of one summary
+	summaryTokens = 300
+	// charsPerToken is the rough GPT-style tokenization ratio used for estimates
+	charsPerToken = 4
+)
+
+// ErrTokenBudget is returned when a run does not fit the token budget
+var ErrTokenBudget = errors.New("token budget exceeded")
+
+// Summarizer condenses text into a short summary, implemented by Client
+type Summarizer interface {
+	Summarize(text string) (string, *Usage, error)
+}
+
+// BatchOptions configures SummarizeBatched
+type BatchOptions struct {
+	ChunkTokens int   // Maximum estimated content tokens per request, DefaultChunkTokens if 0
+	Budget      int64 // Maximum tokens the whole run may use, unlimited if 0
+}
+
+// BatchResult is the outcome of SummarizeBatched
+type BatchResult struct {
+	Summary    string // Final summary, or the summaries collected so far if the run stopped early
+	Usage      Usage  // Tokens used by all requests of the run
+	Chunks     int    // Number of chunks the content was split into
+	Summarized int    // Chunks summarized before the run finished or stopped
+	Partial    bool   // The run stopped early, Summary covers the first Summarized chunks
+	Err        error  // Why the run stopped early
+}
+
+// EstimateTokens roughly estimates the number of tokens of text
+func EstimateTokens(text string) int64 {
+	return int64((len(text) + charsPerToken - 1) / charsPerToken)
+}
+
+// requestTokens estimates the cost of summarizing text in one request
+func requestTokens(text string) int64 {
+	return EstimateTokens(text) + promptOverheadTokens + summaryTokens
+}
+
+// ChunkItems joins items, one per line, into chunks of at most maxTokens estimated tokens.
+// Items larger than a chunk are split at line or word boundaries.
+func ChunkItems(items []string, maxTokens int) []string {
+	if maxTokens <= 0 {
+		maxTokens = DefaultChunkTokens
+	}
+	maxBytes := maxTokens * charsPerToken
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		for _, piece := range splitText(item, maxBytes) {
+			if current.Len() > 0 && current.Len()+1+len(piece) > maxBytes {
+				flush()
+			}
+			if current.Len() > 0 {
+				current.WriteByte('\n')
+			}
+			current.WriteString(piece)
+		}
+	}
+	flush()
+
+	return chunks
+}
+
+// splitText cuts text into pieces of at most maxBytes, preferring line, then word boundaries
+func splitText(text string, maxBytes int) []string {
+	var pieces []string
+	for len(text) > maxBytes {
+		cut := strings.LastIndexByte(text[:maxBytes], '\n')
+		if cut <= 0 {
+			cut = strings.LastIndexByte(text[:maxBytes], ' ')
+		}
+		if cut <= 0 {
+			cut = maxBytes
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		pieces = append(pieces, strings.TrimSpace(text[:cut]))
+		text = strings.TrimSpace(text[cut:])
+	}
+	if text != "" {
+		pieces = append(pieces, text)
+	}
+	return pieces
+}
+
+// EstimateBatchTokens estimates the tokens summarizing chunks costs, including the reduce requests
+func EstimateBatchTokens(chunks []string, maxTokens int) int64 {
+	if maxTokens <= 0 {
+		maxTokens = DefaultChunkTokens
+	}
+
+	var total int64
+	for _, chunk := range chunks {
+		total += requestTokens(chunk)
+	}
+
+	// Every reduce level summarizes groups of summaries that fit one chunk
+	perRequest := maxTokens / summaryTokens
+	if perRequest < 2 {
+		perRequest = 2
+	}
+	for n := len(chunks); n > 1; n = (n + perRequest - 1) / perRequest {
+		requests := (n + perRequest - 1) / perRequest
+		total += int64(n)*summaryTokens + int64(requests)*(promptOverheadTokens+summaryTokens)
+	}
+
+	return total
+}
+
+// SummarizeBatched summarizes items that may not fit one request. It returns ErrTokenBudget without
+// any request when the estimated cost exceeds the budget. Running out of budget or failing later
+// returns a partial result with Err set rather than an error.
+func SummarizeBatched(s Summarizer, items []string, opts BatchOptions) (*BatchResult, error) {
+	if opts.ChunkTokens <= 0 {
+		opts.ChunkTokens = DefaultChunkTokens
+	}
+
+	chunks := ChunkItems(items, opts.ChunkTokens)
+	result := &BatchResult{Chunks: len(chunks)}
+	if len(chunks) == 0 {
+		return result, nil
+	}
+
+	if estimate := EstimateBatchTokens(chunks, opts.ChunkTokens); opts.Budget > 0 && estimate > opts.Budget {
+		return nil, fmt.Errorf("%w: about %d tokens needed, %d left", ErrTokenBudget, estimate, opts.Budget)
+	}
+
+	summarize := func(text string) (string, error) {
+		if opts.Budget > 0 && int64(result.Usage.TotalTokens)+requestTokens(text) > opts.Budget {
+			return "", ErrTokenBudget
+		}
+
+		summary, usage, err := s.Summarize(text)
+		if usage != nil {
+			result.Usage.PromptTokens += usage.PromptTokens
+			result.Usage.CompletionTokens += usage.CompletionTokens
+			result.Usage.TotalTokens += usage.TotalTokens
+		} else if err == nil {
+			// Providers without usage reports still count against the budget
+			result.Usage.TotalTokens += int(requestTokens(text))
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize: %w", err)
+		}
+		return summary, nil
+	}
+
+	stop := func(summaries []string, err error) (*BatchResult, error) {
+		result.Summary = strings.Join(summaries, "\n")
+		result.Partial = true
+		result.Err = err
+		return result, nil
+	}
+
+	// Map: summarize every chunk
+	var summaries []string
+	for _, chunk := range chunks {
+		summary, err := summarize(chunk)
+		if err != nil {
+			return stop(summaries, err)
+		}
+		summaries = append(summaries, summary)
+		result.Summarized++
+	}
+
+	// Reduce: summarize the summaries until one is left
+	for len(summaries) > 1 {
+		groups := ChunkItems(summaries, opts.ChunkTokens)
+		if len(groups) >= len(summaries) {
+			// Summaries too long to group, keep them all
+			break
+		}
+
+		var reduced []string
+		for i, group := range groups {
+			summary, err := summarize(group)
+			if err != nil {
+				// Keep the summaries of the groups not reduced yet
+				return stop(append(reduced, groups[i:]...), err)
+			}
+			reduced = append(reduced, summary)
+		}
+		summaries = reduced
+	}
+
+	result.Summary = strings.Join(summaries, "\n")
+	return result, nil
+}
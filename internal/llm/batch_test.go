@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// fakeSummarizer summarizes by counting lines and fails after failAfter calls if set
+type fakeSummarizer struct {
+	calls       int
+	failAfter   int
+	extraTokens int // Added to the reported usage
+}
+
+func (f *fakeSummarizer) Summarize(text string) (string, *Usage, error) {
+	f.calls++
+	if f.failAfter > 0 && f.calls > f.failAfter {
+		return "", nil, errors.New("rate limited")
+	}
+	summary := fmt.Sprintf("- summary %d of %d lines", f.calls, strings.Count(text, "\n")+1)
+	prompt := int(EstimateTokens(text)) + f.extraTokens
+	return summary, &Usage{PromptTokens: prompt, CompletionTokens: 10, TotalTokens: prompt + 10}, nil
+}
+
+func manyItems(n, size int) []string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = fmt.Sprintf("%d: %s", i, strings.Repeat("x", size))
+	}
+	return items
+}
+
+func TestChunkItems(t *testing.T) {
+	chunks := ChunkItems(manyItems(10, 97), 100) // 100 bytes per item, 400 per chunk
+	if len(chunks) != 4 {
+		t.Fatalf("ChunkItems() = %d chunks, want 4", len(chunks))
+	}
+	for _, chunk := range chunks {
+		if EstimateTokens(chunk) > 100 {
+			t.Errorf("chunk of %d tokens exceeds the limit", EstimateTokens(chunk))
+		}
+	}
+
+	long := strings.Repeat("word ", 200) // 1000 bytes, split at spaces
+	pieces := ChunkItems([]string{long}, 50)
+	if len(pieces) < 5 {
+		t.Errorf("ChunkItems() split a long item into %d pieces, want at least 5", len(pieces))
+	}
+	for _, piece := range pieces {
+		if len(piece) > 200 || strings.HasPrefix(piece, "ord") {
+			t.Errorf("piece %q not cut at a word boundary", piece)
+		}
+	}
+
+	if chunks := ChunkItems([]string{"", "  "}, 100); len(chunks) != 0 {
+		t.Errorf("ChunkItems() of blank items = %v, want none", chunks)
+	}
+}
+
+func TestSummarizeBatched(t *testing.T) {
+	t.Run("single chunk", func(t *testing.T) {
+		s := &fakeSummarizer{}
+		result, err := SummarizeBatched(s, []string{"a", "b"}, BatchOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.calls != 1 || result.Partial || result.Summary != "- summary 1 of 2 lines" {
+			t.Errorf("calls = %d, result = %+v", s.calls, result)
+		}
+	})
+
+	t.Run("map and reduce", func(t *testing.T) {
+		s := &fakeSummarizer{}
+		result, err := SummarizeBatched(s, manyItems(10, 97), BatchOptions{ChunkTokens: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Chunks != 4 || result.Summarized != 4 || result.Partial {
+			t.Errorf("result = %+v, want 4 chunks summarized", result)
+		}
+		if strings.Contains(result.Summary, "\n") {
+			t.Errorf("Summary = %q, want the summaries reduced to one", result.Summary)
+		}
+		if result.Usage.TotalTokens == 0 {
+			t.Error("usage not tracked")
+		}
+	})
+
+	t.Run("budget too small to start", func(t *testing.T) {
+		s := &fakeSummarizer{}
+		_, err := SummarizeBatched(s, manyItems(10, 97), BatchOptions{ChunkTokens: 100, Budget: 500})
+		if !errors.Is(err, ErrTokenBudget) {
+			t.Fatalf("error = %v, want ErrTokenBudget", err)
+		}
+		if s.calls != 0 {
+			t.Errorf("%d requests made, want none", s.calls)
+		}
+	})
+
+	t.Run("budget runs out", func(t *testing.T) {
+		// Requests cost more than estimated, so the run stops before the budget is overspent
+		s := &fakeSummarizer{extraTokens: 1000}
+		items := manyItems(10, 97)
+		budget := EstimateBatchTokens(ChunkItems(items, 100), 100)
+		result, err := SummarizeBatched(s, items, BatchOptions{ChunkTokens: 100, Budget: budget})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Partial || !errors.Is(result.Err, ErrTokenBudget) {
+			t.Fatalf("result = %+v, want a partial result", result)
+		}
+		if result.Summarized == 0 || result.Summary == "" {
+			t.Errorf("result = %+v, want the chunks summarized so far", result)
+		}
+		if int64(result.Usage.TotalTokens) > budget {
+			t.Errorf("used %d tokens, budget %d", result.Usage.TotalTokens, budget)
+		}
+	})
+
+	t.Run("failure keeps partial results", func(t *testing.T) {
+		s := &fakeSummarizer{failAfter: 2}
+		result, err := SummarizeBatched(s, manyItems(10, 97), BatchOptions{ChunkTokens: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Partial || result.Summarized != 2 || result.Err == nil {
+			t.Fatalf("result = %+v, want a partial result of 2 chunks", result)
+		}
+		if result.Summary != "- summary 1 of 3 lines\n- summary 2 of 3 lines" {
+			t.Errorf("Summary = %q", result.Summary)
+		}
+	})
+}
@@ -19,8 +19,14 @@ type Client struct {
 }
 
 type ChatRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
+	Model          string          `json:"model"`
+	Messages       []Message       `json:"messages"`
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat asks OpenAI-compatible APIs for JSON answers
+type ResponseFormat struct {
+	Type string `json:"type"` // "json_object"
 }
 
 type Message struct {
@@ -66,59 +72,82 @@ func (c *Client) ProcessMessage(message string) (string, *Usage, error) {
 	// Use Gemini client if available
 	if c.geminiClient != nil {
 		ctx := context.Background()
-		content, usage, err := c.geminiClient.ProcessMessage(ctx, message)
-		return content, usage, err
+		content, usage, err := c.run(TaskTagging, func(model string) (string, *Usage, error) {
+			return c.geminiClient.withModel(model).ProcessMessage(ctx, message)
+		})
+		if err != nil {
+			return message, nil, err
+		}
+		return content, usage, nil
 	}
 
 	// Fallback to OpenAI-compatible API (for Deepseek, etc.)
-	prompt := fmt.Sprintf("Generate a short title (2-4 words) and exactly 2 hashtags for this message. Return ONLY in this exact format: title|#tag1 #tag2\n\nDo not include any explanations, comments, or additional text.\n\nMessage: %s", message)
-	
+	prompt := titleTagsPrompt(message)
+	jsonMode := jsonModeProviders[strings.ToLower(c.cfg.LLMProvider)]
+
+	content, usage, err := c.run(TaskTagging, func(model string) (string, *Usage, error) {
+		send := func(prompt string) (string, *Usage, error) {
+			return c.chatCompletion(model, prompt, jsonMode, 30*time.Second)
+		}
+		answer, usage, err := send(prompt)
+		if err != nil {
+			return "", nil, err
+		}
+		return titleTagsWithRepair(answer, usage, send)
+	})
+	if err != nil {
+		return message, nil, err
+	}
+	return content, usage, nil
+}
+
+// chatCompletion sends a single user message to the OpenAI-compatible API with model,
+// asking for a JSON answer in jsonMode
+func (c *Client) chatCompletion(model, prompt string, jsonMode bool, timeout time.Duration) (string, *Usage, error) {
 	reqBody := ChatRequest{
-		Model: c.cfg.LLMModel,
-		Messages: []Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
+		Model:    model,
+		Messages: []Message{{Role: "user", Content: prompt}},
+	}
+	if jsonMode {
+		reqBody.ResponseFormat = &ResponseFormat{Type: "json_object"}
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return message, nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequest("POST", c.cfg.LLMEndpoint+"/chat/completions", bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return message, nil, fmt.Errorf("failed to create request: %w", err)
+		return "", nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+c.cfg.LLMToken)
 
-	client := &http.Client{Timeout: 30 * time.Second}
+	client := &http.Client{Timeout: timeout}
 	resp, err := client.Do(req)
 	if err != nil {
-		return message, nil, fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
+		return "", nil, fmt.Errorf("failed to send request to %s: %w", req.URL.String(), err)
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return message, nil, fmt.Errorf("failed to read response: %w", err)
+		return "", nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return message, nil, fmt.Errorf("LLM API returned status %d: %s", resp.StatusCode, string(body))
+		return "", nil, fmt.Errorf("LLM API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var chatResp ChatResponse
 	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return message, nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return "", nil, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return message, nil, fmt.Errorf("no choices in LLM response")
+		return "", nil, fmt.Errorf("no choices in LLM response")
 	}
 
 	return chatResp.Choices[0].Message.Content, chatResp.Usage, nil
@@ -141,7 +170,9 @@ func (c *Client) GenerateTitle(message string) (string, *Usage, error) {
 	// Use Gemini client if available
 	if c.geminiClient != nil {
 		ctx := context.Background()
-		return c.geminiClient.GenerateTitle(ctx, message)
+		return c.run(TaskTagging, func(model string) (string, *Usage, error) {
+			return c.geminiClient.withModel(model).GenerateTitle(ctx, message)
+		})
 	}
 
 	// For non-Gemini providers, extract title from ProcessMessage result
@@ -168,7 +199,14 @@ func (c *Client) GenerateHashtags(message string) ([]string, *Usage, error) {
 	// Use Gemini client if available
 	if c.geminiClient != nil {
 		ctx := context.Background()
-		return c.geminiClient.GenerateHashtags(ctx, message)
+		var hashtags []string
+		_, usage, err := c.run(TaskTagging, func(model string) (string, *Usage, error) {
+			var usage *Usage
+			var err error
+			hashtags, usage, err = c.geminiClient.withModel(model).GenerateHashtags(ctx, message)
+			return "", usage, err
+		})
+		return hashtags, usage, err
 	}
 
 	// For non-Gemini providers, extract hashtags from ProcessMessage result
@@ -196,6 +234,34 @@ func (c *Client) GenerateHashtags(message string) ([]string, *Usage, error) {
 	return hashtags, usage, nil
 }
 
+// summarizePrompt builds the prompt shared by all providers for Summarize
+func summarizePrompt(text string) string {
+	return fmt.Sprintf("Summarize the following items in at most 5 short markdown bullet points. Return ONLY the bullet points without any introduction.\n\n%s", text)
+}
+
+// Summarize condenses text into a short markdown summary using the appropriate client
+func (c *Client) Summarize(text string) (string, *Usage, error) {
+	if c.cfg == nil || !c.cfg.HasLLMConfig() {
+		return "", nil, nil
+	}
+
+	// Use Gemini client if available
+	if c.geminiClient != nil {
+		return c.run(TaskSummary, func(model string) (string, *Usage, error) {
+			return c.geminiClient.withModel(model).Summarize(context.Background(), text)
+		})
+	}
+
+	// Fallback to OpenAI-compatible API
+	content, usage, err := c.run(TaskSummary, func(model string) (string, *Usage, error) {
+		return c.chatCompletion(model, summarizePrompt(text), false, 60*time.Second)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(content), usage, nil
+}
+
 // ProcessImageWithMessage processes an image with optional message using multimodal capabilities
 // Currently only supported for Gemini clients
 func (c *Client) ProcessImageWithMessage(imageData []byte, message string) (string, *Usage, error) {
@@ -206,7 +272,9 @@ func (c *Client) ProcessImageWithMessage(imageData []byte, message string) (stri
 	// Use Gemini client if available (multimodal support)
 	if c.geminiClient != nil {
 		ctx := context.Background()
-		return c.geminiClient.ProcessImageWithMessage(ctx, imageData, message)
+		return c.run(TaskMultimodal, func(model string) (string, *Usage, error) {
+			return c.geminiClient.withModel(model).ProcessImageWithMessage(ctx, imageData, message)
+		})
 	}
 
 	// For non-Gemini providers, multimodal is not supported
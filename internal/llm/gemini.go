@@ -44,7 +44,7 @@ func (gc *GeminiSDKClient) ProcessMessage(ctx context.Context, message string) (
 		return "", nil, fmt.Errorf("gemini SDK client not initialized")
 	}
 
-	prompt := fmt.Sprintf("Generate a short title (2-4 words) and exactly 2 hashtags for this message. Return ONLY in this exact format: title|#tag1 #tag2\n\nDo not include any explanations, comments, or additional text.\n\nMessage: %s", message)
+	prompt := titleTagsPrompt(message)
 
 	// Create content for the request
 	contents := genai.Text(prompt)
@@ -59,6 +59,8 @@ func (gc *GeminiSDKClient) ProcessMessage(ctx context.Context, message string) (
 			ThinkingBudget:   genai.Ptr(int32(0)), // Disable thinking mode
 			IncludeThoughts:  false,
 		},
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   titleTagsSchema,
 	}
 
 	resp, err := gc.client.Models.GenerateContent(ctx, gc.modelName, contents, config)
@@ -109,7 +111,7 @@ func (gc *GeminiSDKClient) ProcessMessage(ctx context.Context, message string) (
 		})
 	}
 
-	return content, usage, nil
+	return titleTagsWithRepair(content, usage, gc.titleTagsRepairer(ctx))
 }
 
 // GenerateTitle generates a title for the given message
@@ -289,10 +291,10 @@ func (gc *GeminiSDKClient) ProcessImageWithMessage(ctx context.Context, imageDat
 	var prompt string
 	if message != "" && !strings.HasPrefix(message, "Photo: ") {
 		// If there's a caption/message, include it in the analysis
-		prompt = fmt.Sprintf("Analyze this image and the accompanying text. Generate a short title (2-4 words) and exactly 2 hashtags. Return ONLY in this exact format: title|#tag1 #tag2\n\nDo not include any explanations, comments, or additional text.\n\nAccompanying text: %s", message)
+		prompt = fmt.Sprintf("Analyze this image and the accompanying text. Generate a short title (2-4 words) and exactly 2 hashtags. %s\n\nAccompanying text: %s", titleTagsInstructions, message)
 	} else {
 		// If no caption, analyze only the image
-		prompt = "Analyze this image. Generate a short title (2-4 words) and exactly 2 hashtags based on what you see. Return ONLY in this exact format: title|#tag1 #tag2\n\nDo not include any explanations, comments, or additional text."
+		prompt = "Analyze this image. Generate a short title (2-4 words) and exactly 2 hashtags based on what you see. " + titleTagsInstructions
 	}
 
 	// Create multimodal content with text and image
@@ -316,6 +318,8 @@ func (gc *GeminiSDKClient) ProcessImageWithMessage(ctx context.Context, imageDat
 			ThinkingBudget:   genai.Ptr(int32(0)), // Disable thinking mode
 			IncludeThoughts:  false,
 		},
+		ResponseMIMEType: "application/json",
+		ResponseSchema:   titleTagsSchema,
 	}
 
 	resp, err := gc.client.Models.GenerateContent(ctx, gc.modelName, parts, config)
@@ -368,7 +372,82 @@ func (gc *GeminiSDKClient) ProcessImageWithMessage(ctx context.Context, imageDat
 		})
 	}
 
-	return content, usage, nil
+	return titleTagsWithRepair(content, usage, gc.titleTagsRepairer(ctx))
+}
+
+// Summarize condenses text into a short markdown summary
+func (gc *GeminiSDKClient) Summarize(ctx context.Context, text string) (string, *Usage, error) {
+	return gc.generate(ctx, summarizePrompt(text), textConfig(600))
+}
+
+// Complete answers a free-form prompt
+func (gc *GeminiSDKClient) Complete(ctx context.Context, prompt string) (string, *Usage, error) {
+	return gc.generate(ctx, prompt, textConfig(2048))
+}
+
+// titleTagsRepairer sends repair prompts for title and hashtag answers in JSON mode
+func (gc *GeminiSDKClient) titleTagsRepairer(ctx context.Context) func(prompt string) (string, *Usage, error) {
+	return func(prompt string) (string, *Usage, error) {
+		config := textConfig(100)
+		config.Temperature = genai.Ptr(float32(0.1))
+		config.ResponseMIMEType = "application/json"
+		config.ResponseSchema = titleTagsSchema
+		return gc.generate(ctx, prompt, config)
+	}
+}
+
+// textConfig is the generation config for free-form answers, with thinking disabled
+func textConfig(maxOutputTokens int32) *genai.GenerateContentConfig {
+	return &genai.GenerateContentConfig{
+		Temperature:     genai.Ptr(float32(0.3)),
+		MaxOutputTokens: maxOutputTokens,
+		ThinkingConfig: &genai.ThinkingConfig{
+			ThinkingBudget:  genai.Ptr(int32(0)), // Disable thinking mode
+			IncludeThoughts: false,
+		},
+	}
+}
+
+// generate sends prompt with config and returns the trimmed answer
+func (gc *GeminiSDKClient) generate(ctx context.Context, prompt string, config *genai.GenerateContentConfig) (string, *Usage, error) {
+	if gc.client == nil {
+		return "", nil, fmt.Errorf("gemini SDK client not initialized")
+	}
+
+	resp, err := gc.client.Models.GenerateContent(ctx, gc.modelName, genai.Text(prompt), config)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return "", nil, fmt.Errorf("no candidates in Gemini response")
+	}
+
+	var answer string
+	for _, part := range resp.Candidates[0].Content.Parts {
+		answer += part.Text
+	}
+
+	var usage *Usage
+	if resp.UsageMetadata != nil {
+		usage = &Usage{
+			PromptTokens:     int(resp.UsageMetadata.PromptTokenCount),
+			CompletionTokens: int(resp.UsageMetadata.CandidatesTokenCount),
+			TotalTokens:      int(resp.UsageMetadata.TotalTokenCount),
+		}
+	}
+
+	return strings.TrimSpace(answer), usage, nil
+}
+
+// withModel returns a client sharing the SDK client that uses model
+func (gc *GeminiSDKClient) withModel(model string) *GeminiSDKClient {
+	if model == "" || model == gc.modelName {
+		return gc
+	}
+	clone := *gc
+	clone.modelName = model
+	return &clone
 }
 
 // Close cleans up the Gemini SDK client resources
@@ -0,0 +1,173 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+// Moderation: operators can check content before it goes through their shared LLM token, with
+// keyword lists, an OpenAI-compatible moderation endpoint or both. Keywords are checked first so
+// clearly unwanted content never leaves the host; an unreachable endpoint counts as flagged.
+
+// moderationClient is shared by all moderation requests
+var moderationClient = &http.Client{Timeout: 10 * time.Second}
+
+// Moderator checks content against the operator's moderation settings
+type Moderator struct {
+	keywords []string
+	endpoint string
+	token    string
+}
+
+// ModerationResult is the outcome of a moderation check
+type ModerationResult struct {
+	Flagged bool
+	Reason  string // Matched keyword or flagged categories, never the content
+}
+
+// NewModerator returns a moderator for cfg, nil if moderation isn't configured
+func NewModerator(cfg *config.Config) *Moderator {
+	if cfg == nil || !cfg.HasModerationConfig() {
+		return nil
+	}
+
+	keywords := make([]string, 0, len(cfg.ModerationKeywords))
+	for _, keyword := range cfg.ModerationKeywords {
+		keywords = append(keywords, strings.ToLower(keyword))
+	}
+
+	return &Moderator{
+		keywords: keywords,
+		endpoint: cfg.ModerationEndpoint,
+		token:    cfg.ModerationToken,
+	}
+}
+
+// Check moderates text. Errors from the endpoint are returned with a flagged result.
+func (m *Moderator) Check(text string) (ModerationResult, error) {
+	if m == nil || strings.TrimSpace(text) == "" {
+		return ModerationResult{}, nil
+	}
+
+	if keyword := m.matchKeyword(text); keyword != "" {
+		return ModerationResult{Flagged: true, Reason: fmt.Sprintf("keyword %q", keyword)}, nil
+	}
+
+	if m.endpoint == "" {
+		return ModerationResult{}, nil
+	}
+
+	result, err := m.checkEndpoint(text)
+	if err != nil {
+		return ModerationResult{Flagged: true, Reason: "moderation endpoint unavailable"}, err
+	}
+	return result, nil
+}
+
+// matchKeyword returns the first keyword found in text as whole words, "" if none
+func (m *Moderator) matchKeyword(text string) string {
+	lower := strings.ToLower(text)
+	for _, keyword := range m.keywords {
+		for offset := 0; ; {
+			i := strings.Index(lower[offset:], keyword)
+			if i < 0 {
+				break
+			}
+			start, end := offset+i, offset+i+len(keyword)
+			if isWordBoundary(lower, start-1) && isWordBoundary(lower, end) {
+				return keyword
+			}
+			offset = start + 1
+		}
+	}
+	return ""
+}
+
+// isWordBoundary reports whether the byte at i is outside text or not part of a word
+func isWordBoundary(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return true
+	}
+	r := rune(text[i])
+	if r >= 0x80 {
+		// Inside a multi-byte rune, which is part of a word for non-Latin scripts
+		return false
+	}
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+}
+
+// moderationResponse is the OpenAI moderation API response
+type moderationResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+func (m *Moderator) checkEndpoint(text string) (ModerationResult, error) {
+	body, err := json.Marshal(map[string]string{"input": text})
+	if err != nil {
+		return ModerationResult{}, fmt.Errorf("failed to marshal moderation request: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", m.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return ModerationResult{}, fmt.Errorf("failed to create moderation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if m.token != "" {
+		req.Header.Set("Authorization", "Bearer "+m.token)
+	}
+
+	resp, err := moderationClient.Do(req)
+	if err != nil {
+		return ModerationResult{}, fmt.Errorf("failed to send moderation request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return ModerationResult{}, fmt.Errorf("failed to read moderation response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return ModerationResult{}, fmt.Errorf("moderation API returned status %d", resp.StatusCode)
+	}
+
+	var parsed moderationResponse
+	if err := json.Unmarshal(respBody, &parsed); err != nil {
+		return ModerationResult{}, fmt.Errorf("failed to unmarshal moderation response: %w", err)
+	}
+	if len(parsed.Results) == 0 {
+		return ModerationResult{}, fmt.Errorf("no results in moderation response")
+	}
+
+	var categories []string
+	flagged := false
+	for _, result := range parsed.Results {
+		flagged = flagged || result.Flagged
+		for category, hit := range result.Categories {
+			if hit {
+				categories = append(categories, category)
+			}
+		}
+	}
+	if !flagged {
+		return ModerationResult{}, nil
+	}
+
+	sort.Strings(categories)
+	reason := "flagged by moderation endpoint"
+	if len(categories) > 0 {
+		reason = "categories " + strings.Join(categories, ", ")
+	}
+	return ModerationResult{Flagged: true, Reason: reason}, nil
+}
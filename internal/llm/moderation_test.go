@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestNewModerator_NotConfigured(t *testing.T) {
+	if NewModerator(&config.Config{LLMToken: "token"}) != nil {
+		t.Error("NewModerator() should be nil without moderation settings")
+	}
+
+	var moderator *Moderator
+	if result, err := moderator.Check("anything"); err != nil || result.Flagged {
+		t.Errorf("nil Moderator.Check() = %+v, %v", result, err)
+	}
+}
+
+func TestModeratorKeywords(t *testing.T) {
+	moderator := NewModerator(&config.Config{ModerationKeywords: []string{"Casino", "free money"}})
+
+	tests := []struct {
+		text    string
+		flagged bool
+	}{
+		{"Visit the CASINO tonight", true},
+		{"get free   money", false},
+		{"Get FREE MONEY now!", true},
+		{"occasional notes", false},
+		{"casinos are listed separately", false},
+		{"meeting notes", false},
+	}
+
+	for _, tt := range tests {
+		result, err := moderator.Check(tt.text)
+		if err != nil {
+			t.Fatalf("Check(%q) error = %v", tt.text, err)
+		}
+		if result.Flagged != tt.flagged {
+			t.Errorf("Check(%q) flagged = %v, want %v", tt.text, result.Flagged, tt.flagged)
+		}
+	}
+}
+
+func TestModeratorEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mod-token" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var reqBody struct {
+			Input string `json:"input"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+
+		flagged := reqBody.Input == "threatening text"
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"results": []map[string]interface{}{{
+				"flagged":    flagged,
+				"categories": map[string]bool{"violence": flagged, "harassment": flagged, "sexual": false},
+			}},
+		})
+	}))
+	defer server.Close()
+
+	moderator := NewModerator(&config.Config{ModerationEndpoint: server.URL, ModerationToken: "mod-token"})
+
+	result, err := moderator.Check("threatening text")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if !result.Flagged || result.Reason != "categories harassment, violence" {
+		t.Errorf("Check() = %+v", result)
+	}
+
+	result, err = moderator.Check("grocery list")
+	if err != nil || result.Flagged {
+		t.Errorf("Check() = %+v, %v, want not flagged", result, err)
+	}
+}
+
+func TestModeratorEndpointFailsClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	moderator := NewModerator(&config.Config{ModerationEndpoint: server.URL})
+
+	result, err := moderator.Check("grocery list")
+	if err == nil {
+		t.Fatal("Check() expected an error when the endpoint fails")
+	}
+	if !result.Flagged {
+		t.Error("Check() should flag content when the endpoint fails")
+	}
+}
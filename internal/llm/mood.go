@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Moods: with mood tracking on, the LLM rates the sentiment of each note on a five-step scale.
+// The scale is fixed so moods can be counted and charted across notes and providers.
+
+// Mood is a step on the sentiment scale
+type Mood struct {
+	Name  string // Stable identifier stored with the note and in activity events
+	Emoji string
+	Score int // -2 (awful) to 2 (great)
+}
+
+// Moods is the sentiment scale from worst to best
+var Moods = []Mood{
+	{Name: "awful", Emoji: "😢", Score: -2},
+	{Name: "bad", Emoji: "🙁", Score: -1},
+	{Name: "neutral", Emoji: "😐", Score: 0},
+	{Name: "good", Emoji: "🙂", Score: 1},
+	{Name: "great", Emoji: "😄", Score: 2},
+}
+
+// LookupMood returns the mood called name
+func LookupMood(name string) (Mood, bool) {
+	for _, mood := range Moods {
+		if mood.Name == name {
+			return mood, true
+		}
+	}
+	return Mood{}, false
+}
+
+// moodPrompt asks for the name of the mood of text and nothing else
+func moodPrompt(text string) string {
+	names := make([]string, 0, len(Moods))
+	for _, mood := range Moods {
+		names = append(names, mood.Name)
+	}
+
+	return fmt.Sprintf(`Rate the mood of the author of the following note.
+Answer with exactly one word from this list: %s.
+Use "neutral" for factual notes without any feeling.
+
+Note:
+%s`, strings.Join(names, ", "), text)
+}
+
+// ParseMood finds the mood named in an answer, ignoring case, punctuation and chatter around it
+func ParseMood(answer string) (Mood, bool) {
+	words := strings.FieldsFunc(strings.ToLower(answer), func(r rune) bool {
+		return r < 'a' || r > 'z'
+	})
+	for _, word := range words {
+		if mood, ok := LookupMood(word); ok {
+			return mood, true
+		}
+	}
+	return Mood{}, false
+}
+
+// DetectMood rates the mood of text with the tagging models
+func (c *Client) DetectMood(text string) (Mood, *Usage, error) {
+	if strings.TrimSpace(text) == "" {
+		return Mood{}, nil, fmt.Errorf("no text to rate")
+	}
+
+	answer, usage, err := c.Complete(TaskTagging, moodPrompt(text))
+	if err != nil {
+		return Mood{}, nil, err
+	}
+	if answer == "" {
+		return Mood{}, usage, fmt.Errorf("LLM not configured")
+	}
+
+	mood, ok := ParseMood(answer)
+	if !ok {
+		return Mood{}, usage, fmt.Errorf("unexpected mood answer %q", answer)
+	}
+	return mood, usage, nil
+}
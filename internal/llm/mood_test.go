@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestParseMood(t *testing.T) {
+	tests := []struct {
+		answer string
+		want   string
+		ok     bool
+	}{
+		{"good", "good", true},
+		{"Great!", "great", true},
+		{"Mood: **neutral**", "neutral", true},
+		{"The author feels bad.", "bad", true},
+		{"happy", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		mood, ok := ParseMood(tt.answer)
+		if ok != tt.ok || mood.Name != tt.want {
+			t.Errorf("ParseMood(%q) = %q, %v, want %q, %v", tt.answer, mood.Name, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestDetectMood(t *testing.T) {
+	answer := "awful"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: answer}}},
+			Usage:   &Usage{PromptTokens: 40, CompletionTokens: 1, TotalTokens: 41},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		LLMProvider: "test",
+		LLMEndpoint: server.URL,
+		LLMToken:    "test-token",
+		LLMModel:    "default",
+	})
+
+	mood, usage, err := client.DetectMood("Missed the last train home")
+	if err != nil {
+		t.Fatalf("DetectMood() error = %v", err)
+	}
+	if mood.Name != "awful" || mood.Emoji != "😢" || mood.Score != -2 {
+		t.Errorf("DetectMood() = %+v", mood)
+	}
+	if usage == nil || usage.TotalTokens != 41 {
+		t.Errorf("DetectMood() usage = %+v", usage)
+	}
+
+	answer = "I can't tell"
+	if _, _, err := client.DetectMood("Missed the last train home"); err == nil {
+		t.Error("DetectMood() expected an error for an answer without a mood")
+	}
+}
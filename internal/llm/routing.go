@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Routing: with auto-route on, the LLM picks which of the user's custom files a note belongs in
+// and says how sure it is. Callers commit to the file above their confidence threshold and ask
+// the user otherwise, so a file that doesn't exist in the list is never returned.
+
+// maxRouteNoteRunes caps the note sent for routing, the start of a note is enough to classify it
+const maxRouteNoteRunes = 2000
+
+// Route is the file picked for a note
+type Route struct {
+	File       string // One of the candidate files, "" if none fits
+	Confidence int    // 0 to 100
+}
+
+// routePrompt asks which of files text belongs in
+func routePrompt(text string, files []string) string {
+	if runes := []rune(text); len(runes) > maxRouteNoteRunes {
+		text = string(runes[:maxRouteNoteRunes])
+	}
+
+	var list strings.Builder
+	for _, file := range files {
+		list.WriteString("- " + file + "\n")
+	}
+
+	return fmt.Sprintf(`Pick the file the following note belongs in, judging by the file names and paths.
+Return ONLY a JSON object in this exact format: {"file": "path/from/the/list.md", "confidence": 80}
+"confidence" is how sure you are from 0 to 100. Use {"file": "none", "confidence": 0} if no file fits.
+
+Files:
+%s
+Note:
+%s`, list.String(), text)
+}
+
+// ParseRoute reads a routing answer, possibly fenced or surrounded by text. The file must be one
+// of files, matched ignoring case and the .md extension.
+func ParseRoute(answer string, files []string) (Route, error) {
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return Route{}, errors.New("no JSON object in answer")
+	}
+
+	var parsed struct {
+		File       string  `json:"file"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &parsed); err != nil {
+		return Route{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	// Some models answer with a fraction despite the instructions
+	confidence := parsed.Confidence
+	if confidence > 0 && confidence <= 1 {
+		confidence *= 100
+	}
+	if confidence < 0 || confidence > 100 {
+		return Route{}, fmt.Errorf("confidence %v out of range", parsed.Confidence)
+	}
+
+	name := routeFileKey(parsed.File)
+	if name == "" || name == "none" {
+		return Route{}, nil
+	}
+	for _, file := range files {
+		if routeFileKey(file) == name {
+			return Route{File: file, Confidence: int(confidence + 0.5)}, nil
+		}
+	}
+	return Route{}, fmt.Errorf("file %q is not in the list", parsed.File)
+}
+
+// routeFileKey normalizes a file name for matching an answer against the candidates
+func routeFileKey(file string) string {
+	file = strings.ToLower(strings.Trim(strings.TrimSpace(file), "/`\"'"))
+	return strings.TrimSuffix(file, ".md")
+}
+
+// RouteNote picks the file of files text belongs in with the tagging models
+func (c *Client) RouteNote(text string, files []string) (Route, *Usage, error) {
+	if strings.TrimSpace(text) == "" || len(files) == 0 {
+		return Route{}, nil, fmt.Errorf("nothing to route")
+	}
+
+	answer, usage, err := c.Complete(TaskTagging, routePrompt(text, files))
+	if err != nil {
+		return Route{}, nil, err
+	}
+	if answer == "" {
+		return Route{}, usage, fmt.Errorf("LLM not configured")
+	}
+
+	route, err := ParseRoute(answer, files)
+	if err != nil {
+		return Route{}, usage, fmt.Errorf("unexpected routing answer %q: %w", answer, err)
+	}
+	return route, usage, nil
+}
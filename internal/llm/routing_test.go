@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestParseRoute(t *testing.T) {
+	files := []string{"notes/books.md", "recipes.md", "work/meetings.md"}
+
+	tests := []struct {
+		answer     string
+		file       string
+		confidence int
+		ok         bool
+	}{
+		{`{"file": "recipes.md", "confidence": 85}`, "recipes.md", 85, true},
+		{"```json\n{\"file\": \"Notes/Books\", \"confidence\": 0.9}\n```", "notes/books.md", 90, true},
+		{`Sure! {"file": "none", "confidence": 0}`, "", 0, true},
+		{`{"file": "diary.md", "confidence": 95}`, "", 0, false},
+		{`{"file": "recipes.md", "confidence": 250}`, "", 0, false},
+		{"recipes.md", "", 0, false},
+	}
+
+	for _, tt := range tests {
+		route, err := ParseRoute(tt.answer, files)
+		if (err == nil) != tt.ok || route.File != tt.file || route.Confidence != tt.confidence {
+			t.Errorf("ParseRoute(%q) = %+v, %v, want %q at %d", tt.answer, route, err, tt.file, tt.confidence)
+		}
+	}
+}
+
+func TestRouteNote(t *testing.T) {
+	var prompt string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request ChatRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		prompt = request.Messages[len(request.Messages)-1].Content
+		json.NewEncoder(w).Encode(ChatResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: `{"file": "recipes.md", "confidence": 92}`}}},
+			Usage:   &Usage{PromptTokens: 60, CompletionTokens: 12, TotalTokens: 72},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		LLMProvider: "test",
+		LLMEndpoint: server.URL,
+		LLMToken:    "test-token",
+		LLMModel:    "default",
+	})
+
+	route, usage, err := client.RouteNote("Pancakes: 2 eggs, 250ml milk", []string{"books.md", "recipes.md"})
+	if err != nil {
+		t.Fatalf("RouteNote() error = %v", err)
+	}
+	if route.File != "recipes.md" || route.Confidence != 92 {
+		t.Errorf("RouteNote() = %+v", route)
+	}
+	if usage == nil || usage.TotalTokens != 72 {
+		t.Errorf("RouteNote() usage = %+v", usage)
+	}
+	if !strings.Contains(prompt, "- books.md\n- recipes.md") || !strings.Contains(prompt, "Pancakes") {
+		t.Errorf("Prompt doesn't list the files and the note: %q", prompt)
+	}
+
+	if _, _, err := client.RouteNote("Pancakes", nil); err == nil {
+		t.Error("RouteNote() expected an error without files")
+	}
+}
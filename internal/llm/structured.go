@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/genai"
+)
+
+// Structured titles and hashtags: providers that support it are asked for JSON matching
+// titleTagsSchema instead of free-form text. Every answer is validated and normalized into the
+// "title|#tag1 #tag2" form callers parse; an answer that can't be read (including the old text
+// format) gets one repair request before the caller falls back to a title from the content.
+
+const (
+	maxTitleWords = 8
+	maxTitleRunes = 80
+	maxTags       = 2
+)
+
+// jsonModeProviders are the OpenAI-compatible providers accepting response_format json_object
+var jsonModeProviders = map[string]bool{
+	"deepseek": true,
+	"openai":   true,
+}
+
+// TitleTags is the structured answer for a note's title and hashtags
+type TitleTags struct {
+	Title string   `json:"title"`
+	Tags  []string `json:"tags"`
+}
+
+// titleTagsSchema is the response schema for Gemini
+var titleTagsSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"title": {Type: genai.TypeString, Description: "Short title of 2-4 words"},
+		"tags": {
+			Type:        genai.TypeArray,
+			Description: "Exactly 2 hashtags starting with #",
+			Items:       &genai.Schema{Type: genai.TypeString},
+		},
+	},
+	Required:         []string{"title", "tags"},
+	PropertyOrdering: []string{"title", "tags"},
+}
+
+// titleTagsInstructions is the answer format appended to title and hashtag prompts
+const titleTagsInstructions = `Return ONLY a JSON object in this exact format: {"title": "Short Title", "tags": ["#tag1", "#tag2"]}
+
+Do not include any explanations, comments, or additional text.`
+
+// titleTagsPrompt asks for the title and hashtags of a message
+func titleTagsPrompt(message string) string {
+	return fmt.Sprintf("Generate a short title (2-4 words) and exactly 2 hashtags for this message. %s\n\nMessage: %s", titleTagsInstructions, message)
+}
+
+// repairPrompt asks to turn an unreadable answer into the expected JSON
+func repairPrompt(answer string) string {
+	return fmt.Sprintf("The following answer should contain a short title and 2 hashtags but is malformed. Rewrite it. %s\n\nAnswer: %s", titleTagsInstructions, answer)
+}
+
+// ParseTitleTags reads a title and hashtags answer: JSON, possibly fenced or surrounded by text,
+// or the old "title|#tag1 #tag2" format. The result is validated and normalized.
+func ParseTitleTags(answer string) (TitleTags, error) {
+	answer = strings.TrimSpace(answer)
+
+	var parsed TitleTags
+	start, end := strings.Index(answer, "{"), strings.LastIndex(answer, "}")
+	switch {
+	case start >= 0 && end > start:
+		if err := json.Unmarshal([]byte(answer[start:end+1]), &parsed); err != nil {
+			return TitleTags{}, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case strings.Contains(answer, "|"):
+		title, tags, _ := strings.Cut(answer, "|")
+		parsed = TitleTags{Title: title, Tags: strings.Fields(tags)}
+	default:
+		return TitleTags{}, errors.New("no JSON object in answer")
+	}
+
+	return parsed.normalize()
+}
+
+// normalize cleans up the title and hashtags and checks them against the expected shape
+func (t TitleTags) normalize() (TitleTags, error) {
+	title := strings.Join(strings.Fields(strings.Trim(t.Title, " \t\r\n\"'`*#")), " ")
+	title = strings.ReplaceAll(title, "|", "-")
+	if title == "" {
+		return TitleTags{}, errors.New("empty title")
+	}
+	if words := len(strings.Fields(title)); words > maxTitleWords {
+		return TitleTags{}, fmt.Errorf("title has %d words", words)
+	}
+	if utf8.RuneCountInString(title) > maxTitleRunes {
+		return TitleTags{}, errors.New("title too long")
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, raw := range t.Tags {
+		tag := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				return r
+			}
+			return -1
+		}, raw)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		tags = append(tags, "#"+tag)
+		if len(tags) == maxTags {
+			break
+		}
+	}
+
+	return TitleTags{Title: title, Tags: tags}, nil
+}
+
+// String formats the title and hashtags as "title|#tag1 #tag2"
+func (t TitleTags) String() string {
+	return t.Title + "|" + strings.Join(t.Tags, " ")
+}
+
+// titleTagsWithRepair normalizes answer, asking send once to repair it when it can't be read.
+// Usage includes the repair request.
+func titleTagsWithRepair(answer string, usage *Usage, send func(prompt string) (string, *Usage, error)) (string, *Usage, error) {
+	parsed, parseErr := ParseTitleTags(answer)
+	if parseErr == nil {
+		return parsed.String(), usage, nil
+	}
+
+	repaired, repairUsage, err := send(repairPrompt(answer))
+	usage = addUsage(usage, repairUsage)
+	if err != nil {
+		return "", usage, fmt.Errorf("failed to repair answer (%v): %w", parseErr, err)
+	}
+
+	parsed, err = ParseTitleTags(repaired)
+	if err != nil {
+		return "", usage, fmt.Errorf("malformed answer after repair: %w", err)
+	}
+	return parsed.String(), usage, nil
+}
+
+// addUsage sums the token usage of two requests, either may be nil
+func addUsage(a, b *Usage) *Usage {
+	if a == nil {
+		return b
+	}
+	if b == nil {
+		return a
+	}
+	return &Usage{
+		PromptTokens:     a.PromptTokens + b.PromptTokens,
+		CompletionTokens: a.CompletionTokens + b.CompletionTokens,
+		TotalTokens:      a.TotalTokens + b.TotalTokens,
+	}
+}
@@ -0,0 +1,102 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestParseTitleTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		answer  string
+		want    string
+		wantErr bool
+	}{
+		{"json", `{"title": "Go Programming", "tags": ["#golang", "#coding"]}`, "Go Programming|#golang #coding", false},
+		{"fenced json", "```json\n{\"title\": \"Trip Plan\", \"tags\": [\"travel\", \"#japan\"]}\n```", "Trip Plan|#travel #japan", false},
+		{"text around json", `Sure! {"title": "Recipe", "tags": ["#food"]} Hope it helps.`, "Recipe|#food", false},
+		{"legacy format", "Go Programming|#golang #coding", "Go Programming|#golang #coding", false},
+		{"cleans title and tags", `{"title": " **\"Weekly  Review\"** ", "tags": ["#self care", "#Self-Care", "#notes", "#extra"]}`, "Weekly Review|#selfcare #notes", false},
+		{"pipe in title", `{"title": "A|B", "tags": []}`, "A-B|", false},
+		{"empty title", `{"title": "", "tags": ["#a"]}`, "", true},
+		{"long title", `{"title": "this title is far too long to be a short commit title", "tags": []}`, "", true},
+		{"broken json", `{"title": "Go", "tags": [`, "", true},
+		{"free text", "Here is a title for your note", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTitleTags(tt.answer)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseTitleTags() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseTitleTags() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ParseTitleTags() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessMessage_JSONModeAndRepair(t *testing.T) {
+	var formats []string
+	var prompts []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		format := ""
+		if reqBody.ResponseFormat != nil {
+			format = reqBody.ResponseFormat.Type
+		}
+		formats = append(formats, format)
+		prompts = append(prompts, reqBody.Messages[0].Content)
+
+		// The first answer is malformed, the repair request gets valid JSON
+		content := "Title: Go Programming, tags golang and coding"
+		if len(prompts) > 1 {
+			content = `{"title": "Go Programming", "tags": ["#golang", "#coding"]}`
+		}
+		json.NewEncoder(w).Encode(ChatResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: content}}},
+			Usage:   &Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		LLMProvider: "deepseek",
+		LLMEndpoint: server.URL,
+		LLMToken:    "test-token",
+		LLMModel:    "deepseek-chat",
+	})
+
+	result, usage, err := client.ProcessMessage("I love programming in Go")
+	if err != nil {
+		t.Fatalf("ProcessMessage() error = %v", err)
+	}
+	if result != "Go Programming|#golang #coding" {
+		t.Errorf("ProcessMessage() = %q", result)
+	}
+	if usage == nil || usage.TotalTokens != 30 {
+		t.Errorf("usage = %+v, want both requests counted", usage)
+	}
+	if len(formats) != 2 || formats[0] != "json_object" || formats[1] != "json_object" {
+		t.Errorf("response formats = %v, want JSON mode for both requests", formats)
+	}
+	if len(prompts) == 2 && !strings.Contains(prompts[1], "Title: Go Programming, tags golang and coding") {
+		t.Errorf("repair prompt does not include the malformed answer: %q", prompts[1])
+	}
+}
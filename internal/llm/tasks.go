@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Tasks: every request belongs to a task, and config.LLMTaskModels can give each task its own
+// models, e.g. a cheap model for titles and hashtags and a stronger one for summaries and images.
+// The models of a task are tried in order and the configured LLMModel is the last fallback.
+
+// Task is the kind of work an LLM request does
+type Task string
+
+const (
+	TaskTagging    Task = config.LLMTaskTagging    // Titles and hashtags: ProcessMessage, GenerateTitle, GenerateHashtags
+	TaskSummary    Task = config.LLMTaskSummary    // Digests and reviews: Summarize
+	TaskMultimodal Task = config.LLMTaskMultimodal // Image analysis: ProcessImageWithMessage
+)
+
+// ModelsFor returns the models tried for task in order
+func (c *Client) ModelsFor(task Task) []string {
+	if c.cfg == nil {
+		return nil
+	}
+
+	var models []string
+	seen := make(map[string]bool)
+	for _, model := range append(append([]string{}, c.cfg.LLMTaskModels[string(task)]...), c.cfg.LLMModel) {
+		if model != "" && !seen[model] {
+			seen[model] = true
+			models = append(models, model)
+		}
+	}
+	return models
+}
+
+// run calls call with the models of task until one succeeds and returns the last error otherwise
+func (c *Client) run(task Task, call func(model string) (string, *Usage, error)) (string, *Usage, error) {
+	models := c.ModelsFor(task)
+	if len(models) == 0 {
+		return "", nil, fmt.Errorf("no model configured for LLM task %s", task)
+	}
+
+	var lastErr error
+	for i, model := range models {
+		content, usage, err := call(model)
+		if err == nil {
+			return content, usage, nil
+		}
+		lastErr = err
+
+		if i < len(models)-1 {
+			logger.Warn("LLM model failed, trying the next one", map[string]interface{}{
+				"task":  string(task),
+				"model": model,
+				"next":  models[i+1],
+				"error": err.Error(),
+			})
+		}
+	}
+	return "", nil, lastErr
+}
+
+// Complete sends a free-form prompt to the models of task and returns the answer
+func (c *Client) Complete(task Task, prompt string) (string, *Usage, error) {
+	if c.cfg == nil || !c.cfg.HasLLMConfig() {
+		return "", nil, nil
+	}
+
+	if c.geminiClient != nil {
+		return c.run(task, func(model string) (string, *Usage, error) {
+			return c.geminiClient.withModel(model).Complete(context.Background(), prompt)
+		})
+	}
+
+	return c.run(task, func(model string) (string, *Usage, error) {
+		return c.chatCompletion(model, prompt, false, 60*time.Second)
+	})
+}
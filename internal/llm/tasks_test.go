@@ -0,0 +1,117 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestModelsFor(t *testing.T) {
+	client := NewClient(&config.Config{
+		LLMModel: "default",
+		LLMTaskModels: map[string][]string{
+			config.LLMTaskTagging: {"cheap"},
+			config.LLMTaskSummary: {"strong", "default"},
+		},
+	})
+
+	tests := []struct {
+		task Task
+		want []string
+	}{
+		{TaskTagging, []string{"cheap", "default"}},
+		{TaskSummary, []string{"strong", "default"}},
+		{TaskMultimodal, []string{"default"}},
+	}
+
+	for _, tt := range tests {
+		if got := client.ModelsFor(tt.task); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ModelsFor(%s) = %v, want %v", tt.task, got, tt.want)
+		}
+	}
+}
+
+func TestTaskModelFallback(t *testing.T) {
+	var mu sync.Mutex
+	var requested []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody ChatRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		mu.Lock()
+		requested = append(requested, reqBody.Model)
+		mu.Unlock()
+
+		if reqBody.Model == "overloaded" {
+			http.Error(w, "model overloaded", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(ChatResponse{
+			Choices: []Choice{{Message: Message{Role: "assistant", Content: "Answer|#" + reqBody.Model}}},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		LLMProvider: "test",
+		LLMEndpoint: server.URL,
+		LLMToken:    "test-token",
+		LLMModel:    "default",
+		LLMTaskModels: map[string][]string{
+			config.LLMTaskSummary: {"overloaded", "strong"},
+		},
+	})
+
+	answer, _, err := client.Complete(TaskSummary, "Review my week")
+	if err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	if answer != "Answer|#strong" {
+		t.Errorf("Complete() = %q, want the fallback's answer", answer)
+	}
+
+	summary, _, err := client.Summarize("items")
+	if err != nil || summary != "Answer|#strong" {
+		t.Errorf("Summarize() = %q, %v", summary, err)
+	}
+
+	result, _, err := client.ProcessMessage("note")
+	if err != nil || result != "Answer|#default" {
+		t.Errorf("ProcessMessage() = %q, %v, want the default model for tagging", result, err)
+	}
+
+	want := []string{"overloaded", "strong", "overloaded", "strong", "default"}
+	if !reflect.DeepEqual(requested, want) {
+		t.Errorf("requested models %v, want %v", requested, want)
+	}
+}
+
+func TestTaskModelFallbackAllFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(&config.Config{
+		LLMProvider:   "test",
+		LLMEndpoint:   server.URL,
+		LLMToken:      "test-token",
+		LLMModel:      "default",
+		LLMTaskModels: map[string][]string{config.LLMTaskTagging: {"cheap"}},
+	})
+
+	result, usage, err := client.ProcessMessage("note")
+	if err == nil {
+		t.Fatal("ProcessMessage() expected an error when every model fails")
+	}
+	if result != "note" || usage != nil {
+		t.Errorf("ProcessMessage() = %q, %v, want the message back", result, usage)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -105,28 +106,65 @@ func (hook *FileHook) Levels() []logrus.Level {
 	return logrus.AllLevels
 }
 
+// contentFields are the log fields that may carry message content
+var contentFields = map[string]bool{
+	"body":             true,
+	"caption":          true,
+	"content":          true,
+	"message":          true,
+	"query":            true,
+	"reply_to_message": true,
+	"response":         true,
+	"text":             true,
+	"title":            true,
+}
+
+var redactContent atomic.Bool
+
+// SetContentRedaction replaces the values of content fields with "[redacted]" in every log entry,
+// for deployments that must not retain message content
+func SetContentRedaction(enabled bool) {
+	redactContent.Store(enabled)
+}
+
+// redact returns fields without message content when content redaction is enabled
+func redact(fields map[string]interface{}) map[string]interface{} {
+	if !redactContent.Load() {
+		return fields
+	}
+
+	redacted := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		if contentFields[key] {
+			value = "[redacted]"
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
 // Convenience functions for structured logging
 func Error(msg string, fields map[string]interface{}) {
 	if Logger != nil {
-		Logger.WithFields(fields).Error(msg)
+		Logger.WithFields(redact(fields)).Error(msg)
 	}
 }
 
 func Info(msg string, fields map[string]interface{}) {
 	if Logger != nil {
-		Logger.WithFields(fields).Info(msg)
+		Logger.WithFields(redact(fields)).Info(msg)
 	}
 }
 
 func Debug(msg string, fields map[string]interface{}) {
 	if Logger != nil {
-		Logger.WithFields(fields).Debug(msg)
+		Logger.WithFields(redact(fields)).Debug(msg)
 	}
 }
 
 func Warn(msg string, fields map[string]interface{}) {
 	if Logger != nil {
-		Logger.WithFields(fields).Warn(msg)
+		Logger.WithFields(redact(fields)).Warn(msg)
 	}
 }
 
@@ -145,4 +183,17 @@ func DebugMsg(msg string) {
 
 func WarnMsg(msg string) {
 	Warn(msg, nil)
-}
\ No newline at end of file
+}
+// SetLevel changes the log level at runtime (used by config hot-reload)
+func SetLevel(logLevel string) error {
+	if Logger == nil {
+		return nil
+	}
+
+	level, err := logrus.ParseLevel(logLevel)
+	if err != nil {
+		return err
+	}
+	Logger.SetLevel(level)
+	return nil
+}
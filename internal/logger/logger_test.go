@@ -0,0 +1,29 @@
+package logger
+
+import "testing"
+
+func TestRedact(t *testing.T) {
+	fields := map[string]interface{}{
+		"chat_id": int64(1),
+		"content": "secret note",
+		"title":   "secret title",
+	}
+
+	if got := redact(fields); got["content"] != "secret note" {
+		t.Errorf("redact() without redaction changed content to %v", got["content"])
+	}
+
+	SetContentRedaction(true)
+	defer SetContentRedaction(false)
+
+	got := redact(fields)
+	if got["content"] != "[redacted]" || got["title"] != "[redacted]" {
+		t.Errorf("redact() = %v, want content and title redacted", got)
+	}
+	if got["chat_id"] != int64(1) {
+		t.Errorf("redact() changed chat_id to %v", got["chat_id"])
+	}
+	if fields["content"] != "secret note" {
+		t.Error("redact() modified the caller's fields")
+	}
+}
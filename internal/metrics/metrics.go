@@ -0,0 +1,341 @@
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus metrics of the bot, promoted from experiments/monitoring: Telegram commands and the
+// worker pool queues are recorded by internal/telegram, commits, pushes, GitHub API requests and
+// worktree file I/O by internal/github, and main.go serves them on METRICS_PORT. Unlike the experiment, series aren't
+// labelled by user: with thousands of users every metric would hold thousands of series. Label
+// values coming from users (command names, API paths) are capped by maxLabelValues.
+
+// Statuses of recorded operations
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+const (
+	// maxLabelValues bounds the distinct values of a label fed by user input, later values are
+	// recorded as OtherLabel
+	maxLabelValues = 200
+
+	// OtherLabel replaces label values past maxLabelValues
+	OtherLabel = "other"
+
+	// activeUserWindow is how long a user counts as active after their last command
+	activeUserWindow = 5 * time.Minute
+)
+
+// MetricsCollector manages all Prometheus metrics of the bot
+type MetricsCollector struct {
+	// Telegram command metrics
+	telegramCommandsTotal     *prometheus.CounterVec
+	commandProcessingDuration *prometheus.HistogramVec
+	activeUsersGauge          prometheus.Gauge
+
+	// Git metrics (clones commit and push, the API provider commits through the contents API)
+	gitOperationsTotal   *prometheus.CounterVec
+	gitOperationDuration *prometheus.HistogramVec
+
+	// GitHub API metrics
+	githubAPIRequestsTotal      *prometheus.CounterVec
+	githubAPIRequestDuration    *prometheus.HistogramVec
+	githubAPIRateLimitRemaining *prometheus.GaugeVec
+	githubAPIRateLimitResetTime *prometheus.GaugeVec
+
+	// Worktree file I/O metrics of clones
+	fileIOOperationsTotal *prometheus.CounterVec
+	fileIOBytesTotal      *prometheus.CounterVec
+
+	// Queue metrics of the worker pool
+	queuedRequestsTotal *prometheus.CounterVec
+	queueProcessingTime *prometheus.HistogramVec
+	queueDepth          *prometheus.GaugeVec
+
+	commands  *labelSet
+	endpoints *labelSet
+
+	// Internal state
+	mu          sync.Mutex
+	activeUsers map[int64]time.Time
+}
+
+// NewMetricsCollector creates a collector registered with the default Prometheus registry
+func NewMetricsCollector() *MetricsCollector {
+	return NewMetricsCollectorWithRegistry(prometheus.DefaultRegisterer)
+}
+
+// NewMetricsCollectorWithRegistry creates a collector registered with registry
+func NewMetricsCollectorWithRegistry(registry prometheus.Registerer) *MetricsCollector {
+	factory := promauto.With(registry)
+	return &MetricsCollector{
+		telegramCommandsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "telegram_commands_total",
+				Help: "Total number of Telegram commands, messages and callbacks processed",
+			},
+			[]string{"command", "status"},
+		),
+
+		commandProcessingDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "command_processing_duration_seconds",
+				Help:    "Time spent processing commands",
+				Buckets: prometheus.DefBuckets,
+			},
+			[]string{"command", "status"},
+		),
+
+		activeUsersGauge: factory.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "active_users_gauge",
+				Help: "Number of users who sent a command in the last 5 minutes",
+			},
+		),
+
+		gitOperationsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "git_operations_total",
+				Help: "Total number of commits and pushes",
+			},
+			[]string{"operation", "provider", "status"},
+		),
+
+		gitOperationDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "git_operation_duration_seconds",
+				Help:    "Time spent on commits and pushes",
+				Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"operation", "provider"},
+		),
+
+		githubAPIRequestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "github_api_requests_total",
+				Help: "Total number of GitHub API requests",
+			},
+			[]string{"api_type", "endpoint", "status"},
+		),
+
+		githubAPIRequestDuration: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "github_api_request_duration_seconds",
+				Help:    "Time spent on GitHub API requests",
+				Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+			},
+			[]string{"api_type", "endpoint", "status"},
+		),
+
+		githubAPIRateLimitRemaining: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "github_api_rate_limit_remaining",
+				Help: "Remaining GitHub API rate limit reported by the latest response",
+			},
+			[]string{"resource"},
+		),
+
+		githubAPIRateLimitResetTime: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "github_api_rate_limit_reset_time",
+				Help: "GitHub API rate limit reset time (Unix timestamp) reported by the latest response",
+			},
+			[]string{"resource"},
+		),
+
+		fileIOOperationsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "file_io_operations_total",
+				Help: "Total number of file operations on the worktrees of clones",
+			},
+			[]string{"operation", "status"},
+		),
+
+		fileIOBytesTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "file_io_bytes_total",
+				Help: "Total number of bytes read from and written to the worktrees of clones",
+			},
+			[]string{"direction"},
+		),
+
+		queuedRequestsTotal: factory.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "queued_requests_total",
+				Help: "Total number of updates submitted to the worker pool",
+			},
+			[]string{"request_type", "status"},
+		),
+
+		queueProcessingTime: factory.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "queue_processing_time_seconds",
+				Help:    "Time from queueing an update to finishing it",
+				Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60},
+			},
+			[]string{"request_type"},
+		),
+
+		queueDepth: factory.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "command_queue_depth",
+				Help: "Current depth of the worker pool queues",
+			},
+			[]string{"request_type"},
+		),
+
+		commands:    newLabelSet(),
+		endpoints:   newLabelSet(),
+		activeUsers: make(map[int64]time.Time),
+	}
+}
+
+// RecordTelegramCommand records a processed command, message or callback of a user
+func (m *MetricsCollector) RecordTelegramCommand(userID int64, command, status string) {
+	m.telegramCommandsTotal.WithLabelValues(m.commands.bound(command), status).Inc()
+
+	m.mu.Lock()
+	m.activeUsers[userID] = time.Now()
+	m.mu.Unlock()
+
+	m.updateActiveUsersGauge()
+}
+
+// RecordCommandProcessingTime records time spent processing a command
+func (m *MetricsCollector) RecordCommandProcessingTime(command, status string, duration time.Duration) {
+	m.commandProcessingDuration.WithLabelValues(m.commands.bound(command), status).Observe(duration.Seconds())
+}
+
+// RecordGitOperation records a commit or push of a provider ("clone" or "api")
+func (m *MetricsCollector) RecordGitOperation(operation, provider, status string, duration time.Duration) {
+	m.gitOperationsTotal.WithLabelValues(operation, provider, status).Inc()
+	m.gitOperationDuration.WithLabelValues(operation, provider).Observe(duration.Seconds())
+}
+
+// RecordGitHubAPIRequest records a GitHub API request
+func (m *MetricsCollector) RecordGitHubAPIRequest(apiType, endpoint, status string, duration time.Duration) {
+	endpoint = m.endpoints.bound(endpoint)
+	m.githubAPIRequestsTotal.WithLabelValues(apiType, endpoint, status).Inc()
+	m.githubAPIRequestDuration.WithLabelValues(apiType, endpoint, status).Observe(duration.Seconds())
+}
+
+// UpdateGitHubRateLimit updates the rate limit of a GitHub API resource ("core", "graphql", ...)
+func (m *MetricsCollector) UpdateGitHubRateLimit(resource string, remaining int, resetTime time.Time) {
+	m.githubAPIRateLimitRemaining.WithLabelValues(resource).Set(float64(remaining))
+	m.githubAPIRateLimitResetTime.WithLabelValues(resource).Set(float64(resetTime.Unix()))
+}
+
+// RecordFileIO records a file operation ("read", "write", "remove", ...) on a worktree
+func (m *MetricsCollector) RecordFileIO(operation, status string) {
+	m.fileIOOperationsTotal.WithLabelValues(operation, status).Inc()
+}
+
+// RecordFileIOBytes records bytes read from or written to a worktree, direction "read" or "write"
+func (m *MetricsCollector) RecordFileIOBytes(direction string, bytes int64) {
+	m.fileIOBytesTotal.WithLabelValues(direction).Add(float64(bytes))
+}
+
+// RecordQueuedRequest records an update submitted to a queue, with status "queued" or "dropped"
+func (m *MetricsCollector) RecordQueuedRequest(requestType, status string) {
+	m.queuedRequestsTotal.WithLabelValues(requestType, status).Inc()
+}
+
+// RecordQueueProcessingTime records the time from queueing an update to finishing it
+func (m *MetricsCollector) RecordQueueProcessingTime(requestType string, duration time.Duration) {
+	m.queueProcessingTime.WithLabelValues(requestType).Observe(duration.Seconds())
+}
+
+// UpdateQueueDepth updates the number of updates waiting in a queue
+func (m *MetricsCollector) UpdateQueueDepth(requestType string, depth int) {
+	m.queueDepth.WithLabelValues(requestType).Set(float64(depth))
+}
+
+// updateActiveUsersGauge drops inactive users and updates the active users gauge
+func (m *MetricsCollector) updateActiveUsersGauge() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-activeUserWindow)
+	for userID, lastSeen := range m.activeUsers {
+		if lastSeen.Before(cutoff) {
+			delete(m.activeUsers, userID)
+		}
+	}
+
+	m.activeUsersGauge.Set(float64(len(m.activeUsers)))
+}
+
+// GetActiveUsersCount returns the current number of active users
+func (m *MetricsCollector) GetActiveUsersCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.activeUsers)
+}
+
+// labelSet caps the distinct values of a label
+type labelSet struct {
+	mu     sync.Mutex
+	values map[string]struct{}
+}
+
+func newLabelSet() *labelSet {
+	return &labelSet{values: make(map[string]struct{})}
+}
+
+// bound returns value while fewer than maxLabelValues values were seen, OtherLabel afterwards
+func (s *labelSet) bound(value string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[value]; ok {
+		return value
+	}
+	if len(s.values) >= maxLabelValues {
+		return OtherLabel
+	}
+	s.values[value] = struct{}{}
+	return value
+}
+
+// registry holds the metrics of the bot and of the Go runtime, served by Handler
+var registry = prometheus.NewRegistry()
+
+// Default is the process-wide collector recorded by the bot
+var Default = NewMetricsCollectorWithRegistry(registry)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics of Default in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// Status returns StatusError if err is set, StatusSuccess otherwise
+func Status(err error) string {
+	if err != nil {
+		return StatusError
+	}
+	return StatusSuccess
+}
+
+// HTTPStatus returns StatusError for failed requests and error responses, StatusSuccess otherwise
+func HTTPStatus(resp *http.Response, err error) string {
+	if err != nil || resp.StatusCode >= 400 {
+		return StatusError
+	}
+	return StatusSuccess
+}
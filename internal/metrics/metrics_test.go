@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestCollector(t *testing.T) *MetricsCollector {
+	t.Helper()
+	return NewMetricsCollectorWithRegistry(prometheus.NewRegistry())
+}
+
+func TestRecordTelegramCommand(t *testing.T) {
+	m := newTestCollector(t)
+
+	m.RecordTelegramCommand(1, "/sync", StatusSuccess)
+	m.RecordTelegramCommand(1, "/sync", StatusSuccess)
+	m.RecordTelegramCommand(2, "/sync", StatusError)
+	m.RecordCommandProcessingTime("/sync", StatusSuccess, 300*time.Millisecond)
+
+	if got := testutil.ToFloat64(m.telegramCommandsTotal.WithLabelValues("/sync", StatusSuccess)); got != 2 {
+		t.Errorf("successful commands = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.telegramCommandsTotal.WithLabelValues("/sync", StatusError)); got != 1 {
+		t.Errorf("failed commands = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.activeUsersGauge); got != 2 {
+		t.Errorf("active users = %v, want 2", got)
+	}
+	if got := testutil.CollectAndCount(m.commandProcessingDuration); got != 1 {
+		t.Errorf("duration series = %d, want 1", got)
+	}
+}
+
+func TestRecordGitOperation(t *testing.T) {
+	m := newTestCollector(t)
+
+	m.RecordGitOperation("commit", "clone", Status(nil), time.Second)
+	m.RecordGitOperation("push", "clone", Status(errors.New("rejected")), time.Second)
+
+	if got := testutil.ToFloat64(m.gitOperationsTotal.WithLabelValues("commit", "clone", StatusSuccess)); got != 1 {
+		t.Errorf("commits = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.gitOperationsTotal.WithLabelValues("push", "clone", StatusError)); got != 1 {
+		t.Errorf("failed pushes = %v, want 1", got)
+	}
+}
+
+func TestRecordGitHubAPIRequest(t *testing.T) {
+	m := newTestCollector(t)
+
+	m.RecordGitHubAPIRequest("REST", "/repos/:owner/:repo/contents", StatusSuccess, 200*time.Millisecond)
+	m.UpdateGitHubRateLimit("core", 4999, time.Unix(1700000000, 0))
+
+	if got := testutil.ToFloat64(m.githubAPIRequestsTotal.WithLabelValues("REST", "/repos/:owner/:repo/contents", StatusSuccess)); got != 1 {
+		t.Errorf("requests = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.githubAPIRateLimitRemaining.WithLabelValues("core")); got != 4999 {
+		t.Errorf("remaining = %v, want 4999", got)
+	}
+	if got := testutil.ToFloat64(m.githubAPIRateLimitResetTime.WithLabelValues("core")); got != 1700000000 {
+		t.Errorf("reset time = %v, want 1700000000", got)
+	}
+}
+
+func TestLabelValuesAreBounded(t *testing.T) {
+	m := newTestCollector(t)
+
+	for i := 0; i < maxLabelValues+50; i++ {
+		m.RecordTelegramCommand(1, fmt.Sprintf("/typo%d", i), StatusSuccess)
+	}
+
+	if got := testutil.CollectAndCount(m.telegramCommandsTotal); got != maxLabelValues+1 {
+		t.Errorf("series = %d, want %d plus %q", got, maxLabelValues, OtherLabel)
+	}
+	if got := testutil.ToFloat64(m.telegramCommandsTotal.WithLabelValues(OtherLabel, StatusSuccess)); got != 50 {
+		t.Errorf("%q commands = %v, want 50", OtherLabel, got)
+	}
+	// Values seen before the cap keep their series
+	m.RecordTelegramCommand(1, "/typo0", StatusSuccess)
+	if got := testutil.ToFloat64(m.telegramCommandsTotal.WithLabelValues("/typo0", StatusSuccess)); got != 2 {
+		t.Errorf("/typo0 commands = %v, want 2", got)
+	}
+}
+
+func TestQueueMetrics(t *testing.T) {
+	m := newTestCollector(t)
+
+	m.RecordQueuedRequest("message", "queued")
+	m.RecordQueuedRequest("message", "dropped")
+	m.UpdateQueueDepth("message", 3)
+	m.RecordQueueProcessingTime("message", time.Second)
+
+	if got := testutil.ToFloat64(m.queuedRequestsTotal.WithLabelValues("message", "dropped")); got != 1 {
+		t.Errorf("dropped = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.queueDepth.WithLabelValues("message")); got != 3 {
+		t.Errorf("depth = %v, want 3", got)
+	}
+}
+
+func TestFileIOMetrics(t *testing.T) {
+	m := newTestCollector(t)
+
+	m.RecordFileIO("write", StatusSuccess)
+	m.RecordFileIO("write", StatusError)
+	m.RecordFileIOBytes("write", 512)
+	m.RecordFileIOBytes("write", 512)
+
+	if got := testutil.ToFloat64(m.fileIOOperationsTotal.WithLabelValues("write", StatusError)); got != 1 {
+		t.Errorf("failed writes = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.fileIOBytesTotal.WithLabelValues("write")); got != 1024 {
+		t.Errorf("written bytes = %v, want 1024", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	Default.RecordGitOperation("push", "clone", StatusSuccess, time.Second)
+
+	recorder := httptest.NewRecorder()
+	Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := recorder.Body.String()
+	for _, want := range []string{"git_operations_total", "go_goroutines"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("/metrics misses %s", want)
+		}
+	}
+}
+
+func TestHTTPStatus(t *testing.T) {
+	if got := HTTPStatus(&http.Response{StatusCode: 200}, nil); got != StatusSuccess {
+		t.Errorf("HTTPStatus(200) = %q", got)
+	}
+	if got := HTTPStatus(&http.Response{StatusCode: 404}, nil); got != StatusError {
+		t.Errorf("HTTPStatus(404) = %q", got)
+	}
+	if got := HTTPStatus(nil, errors.New("timeout")); got != StatusError {
+		t.Errorf("HTTPStatus(error) = %q", got)
+	}
+}
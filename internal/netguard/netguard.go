@@ -0,0 +1,108 @@
+// Package netguard keeps requests to URLs users supply, such as webhooks and feeds, off the
+// networks of the host: loopback, private and link-local addresses (cloud metadata endpoints
+// among them) are refused. Addresses are checked when connecting, after DNS resolution, so a name
+// that resolves to a public address when it's saved and to a private one when it's fetched is
+// still refused.
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// maxRedirects bounds the redirects a client follows
+const maxRedirects = 5
+
+// ErrForbiddenAddress is returned for hosts resolving to addresses that aren't publicly routable
+var ErrForbiddenAddress = errors.New("address is not publicly routable")
+
+// sharedAddressSpace is the carrier-grade NAT range, private to the provider's network
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// Allowed reports whether requests may reach ip
+func Allowed(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return ip.IsValid() &&
+		!ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified() &&
+		!sharedAddressSpace.Contains(ip)
+}
+
+// CheckURL resolves the host of an http or https URL and fails if any of its addresses isn't
+// allowed, so a URL can be refused when it's saved. Clients of NewClient check every connection
+// regardless.
+func CheckURL(ctx context.Context, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Hostname() == "" {
+		return fmt.Errorf("invalid URL")
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported URL scheme %q", parsed.Scheme)
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", parsed.Hostname())
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", parsed.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !Allowed(addr) {
+			return fmt.Errorf("%s: %w", parsed.Hostname(), ErrForbiddenAddress)
+		}
+	}
+	return nil
+}
+
+// control refuses connections to addresses that aren't allowed, once they are resolved
+func control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, ErrForbiddenAddress)
+	}
+	if !Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%s: %w", addrPort.Addr(), ErrForbiddenAddress)
+	}
+	return nil
+}
+
+// NewTransport returns an HTTP transport that only connects to allowed addresses, for clients that
+// need their own wrapping transport. It ignores proxy settings, since the proxy would make the
+// connections the guard can't see.
+func NewTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   control,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// NewClient returns an HTTP client whose transport is NewTransport and that checks the URL of
+// every redirect before following it
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewTransport(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return CheckURL(req.Context(), req.URL.String())
+		},
+	}
+}
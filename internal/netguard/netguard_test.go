@@ -0,0 +1,67 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		ip      string
+		allowed bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := Allowed(netip.MustParseAddr(tt.ip)); got != tt.allowed {
+			t.Errorf("Allowed(%s) = %v, want %v", tt.ip, got, tt.allowed)
+		}
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	for _, raw := range []string{"http://127.0.0.1/hook", "https://[::1]:8443/", "http://localhost/feed", "http://169.254.169.254/latest/meta-data"} {
+		if err := CheckURL(ctx, raw); !errors.Is(err, ErrForbiddenAddress) {
+			t.Errorf("CheckURL(%q) error = %v, want ErrForbiddenAddress", raw, err)
+		}
+	}
+	for _, raw := range []string{"ftp://example.com/", "not a url", "https://"} {
+		if err := CheckURL(ctx, raw); err == nil {
+			t.Errorf("CheckURL(%q) succeeded, want an error", raw)
+		}
+	}
+	if err := CheckURL(ctx, "https://93.184.216.34/hook"); err != nil {
+		t.Errorf("CheckURL() of a public address error = %v", err)
+	}
+}
+
+func TestNewClientRefusesLocalServers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(5 * time.Second).Get(server.URL)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Errorf("Get() of a loopback server error = %v, want ErrForbiddenAddress", err)
+	}
+}
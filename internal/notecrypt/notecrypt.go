@@ -0,0 +1,193 @@
+// Package notecrypt encrypts note content before it is committed, so the repository only stores
+// ciphertext. A note is sealed with AES-256-GCM under a key derived from the user's passphrase with
+// scrypt and written as a single line, "🔒 msg2git-enc:v1:<salt>:<nonce+ciphertext>" in base64.
+// The salt travels with every note, so the passphrase alone decrypts a repository.
+package notecrypt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// Marker starts every sealed line
+	Marker = "🔒 msg2git-enc:v1:"
+
+	saltSize = 16
+	keySize  = 32
+
+	// MinPassphraseLength is the shortest passphrase accepted
+	MinPassphraseLength = 12
+)
+
+// scrypt cost parameters, about 50ms and 32MB per derivation
+var scryptN, scryptR, scryptP = 1 << 15, 8, 1
+
+// magic is authenticated with every note so other ciphertexts can't be swapped in
+var magic = []byte("msg2git-note-1")
+
+var encoding = base64.RawStdEncoding
+
+// Key encrypts and decrypts the notes of one passphrase
+type Key struct {
+	salt []byte
+	key  []byte
+}
+
+// SaltFor returns the salt of owner's notes, such as a chat ID. A fixed salt per owner gives the
+// same key for the same passphrase, so setting encryption up again reads the older notes.
+func SaltFor(owner string) []byte {
+	sum := sha256.Sum256(append(append([]byte(nil), magic...), owner...))
+	return sum[:saltSize]
+}
+
+// NewKey derives the key of passphrase and salt
+func NewKey(passphrase string, salt []byte) (*Key, error) {
+	if len([]rune(passphrase)) < MinPassphraseLength {
+		return nil, fmt.Errorf("the passphrase must have at least %d characters", MinPassphraseLength)
+	}
+	if len(salt) != saltSize {
+		return nil, fmt.Errorf("the salt must have %d bytes", saltSize)
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	return &Key{salt: append([]byte(nil), salt...), key: key}, nil
+}
+
+// String serializes the derived key for storage, never the passphrase
+func (k *Key) String() string {
+	return encoding.EncodeToString(k.salt) + ":" + encoding.EncodeToString(k.key)
+}
+
+// ParseKey reads a key serialized with String
+func ParseKey(s string) (*Key, error) {
+	saltText, keyText, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid note key")
+	}
+	salt, err := encoding.DecodeString(saltText)
+	if err != nil || len(salt) != saltSize {
+		return nil, fmt.Errorf("invalid note key salt")
+	}
+	key, err := encoding.DecodeString(keyText)
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("invalid note key")
+	}
+	return &Key{salt: salt, key: key}, nil
+}
+
+// Seal encrypts plaintext into a sealed line
+func (k *Key) Seal(plaintext string) (string, error) {
+	gcm, err := newGCM(k.key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), magic)
+	return Marker + encoding.EncodeToString(k.salt) + ":" + encoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a sealed line, lines sealed under another passphrase can't be opened
+func (k *Key) Open(line string) (string, error) {
+	salt, sealed, err := parseLine(line)
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(salt, k.salt) {
+		return "", fmt.Errorf("note was encrypted with another passphrase")
+	}
+	return open(k.key, sealed)
+}
+
+// IsSealed reports whether line is a sealed note
+func IsSealed(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), Marker)
+}
+
+// OpenAll returns content with every sealed line k can open replaced by its plaintext and the
+// number of lines it couldn't open, which are left as they are
+func (k *Key) OpenAll(content string) (string, int) {
+	if !strings.Contains(content, Marker) {
+		return content, 0
+	}
+
+	failed := 0
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if !IsSealed(line) {
+			continue
+		}
+		plaintext, err := k.Open(line)
+		if err != nil {
+			failed++
+			continue
+		}
+		lines[i] = plaintext
+	}
+	return strings.Join(lines, "\n"), failed
+}
+
+func parseLine(line string) ([]byte, []byte, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, Marker) {
+		return nil, nil, fmt.Errorf("not an encrypted note")
+	}
+	saltText, sealedText, ok := strings.Cut(strings.TrimPrefix(line, Marker), ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("encrypted note is truncated")
+	}
+	salt, err := encoding.DecodeString(saltText)
+	if err != nil || len(salt) != saltSize {
+		return nil, nil, fmt.Errorf("encrypted note has an invalid salt")
+	}
+	sealed, err := encoding.DecodeString(sealedText)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encrypted note is corrupted")
+	}
+	return salt, sealed, nil
+}
+
+func open(key, sealed []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", fmt.Errorf("encrypted note is truncated")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, magic)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt note, wrong passphrase or corrupted note")
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
@@ -0,0 +1,115 @@
+package notecrypt
+
+import (
+	"strings"
+	"testing"
+)
+
+func init() {
+	// Keep key derivation cheap in tests
+	scryptN = 1 << 10
+}
+
+func TestSealOpen(t *testing.T) {
+	key, err := NewKey("correct horse battery", SaltFor("42"))
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+
+	sealed, err := key.Seal("## Title\n\nsecret note  ")
+	if err != nil {
+		t.Fatalf("Seal() error = %v", err)
+	}
+	if !IsSealed(sealed) || strings.Contains(sealed, "secret") || strings.Contains(sealed, "\n") {
+		t.Fatalf("Seal() = %q, want a single sealed line", sealed)
+	}
+
+	plaintext, err := key.Open(sealed)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if plaintext != "## Title\n\nsecret note  " {
+		t.Errorf("Open() = %q", plaintext)
+	}
+
+	// The stored key opens the note like the derived one
+	parsed, err := ParseKey(key.String())
+	if err != nil {
+		t.Fatalf("ParseKey() error = %v", err)
+	}
+	if _, err := parsed.Open(sealed); err != nil {
+		t.Errorf("Open() with the parsed key error = %v", err)
+	}
+}
+
+func TestOpen_WrongKey(t *testing.T) {
+	key, _ := NewKey("correct horse battery", SaltFor("42"))
+	sealed, _ := key.Seal("secret")
+
+	other, _ := NewKey("another passphrase!", SaltFor("7"))
+	if _, err := other.Open(sealed); err == nil {
+		t.Error("Expected a key with another salt to fail")
+	}
+
+	wrong, _ := NewKey("wrong passphrase!!", SaltFor("42"))
+	if _, err := wrong.Open(sealed); err == nil {
+		t.Error("Expected the wrong passphrase to fail")
+	}
+
+	tampered := sealed[:len(sealed)-2] + "AA"
+	if _, err := key.Open(tampered); err == nil {
+		t.Error("Expected a tampered note to fail")
+	}
+}
+
+func TestNewKey_ShortPassphrase(t *testing.T) {
+	if _, err := NewKey("short", SaltFor("42")); err == nil {
+		t.Error("Expected a short passphrase to be refused")
+	}
+}
+
+func TestNewKey_SamePassphrase(t *testing.T) {
+	key, _ := NewKey("correct horse battery", SaltFor("42"))
+	sealed, _ := key.Seal("secret")
+
+	// Setting the same passphrase up again reads the older notes
+	again, err := NewKey("correct horse battery", SaltFor("42"))
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	if plaintext, err := again.Open(sealed); err != nil || plaintext != "secret" {
+		t.Errorf("Open() = %q, %v", plaintext, err)
+	}
+	if string(SaltFor("42")) == string(SaltFor("43")) {
+		t.Error("Expected owners to have different salts")
+	}
+}
+
+func TestOpenAll(t *testing.T) {
+	key, _ := NewKey("correct horse battery", SaltFor("42"))
+	first, _ := key.Seal("## One\n\nfirst")
+	second, _ := key.Seal("## Two\n\nsecond")
+	other, _ := NewKey("another passphrase!", SaltFor("7"))
+	foreign, _ := other.Seal("foreign")
+
+	content := "# Notes\n\n<!--\n[1] [2] [2025-01-01 00:00] \n-->\n\n" + first + "\n\n---\n\n" + second + "\n" + foreign + "\nplain"
+	opened, failed := key.OpenAll(content)
+	if failed != 1 {
+		t.Errorf("OpenAll() failed = %d, want 1", failed)
+	}
+	if !strings.Contains(opened, "## One\n\nfirst") || !strings.Contains(opened, "## Two\n\nsecond") || !strings.Contains(opened, foreign) || !strings.HasSuffix(opened, "\nplain") {
+		t.Errorf("OpenAll() = %q", opened)
+	}
+
+	if opened, failed := key.OpenAll("plain"); opened != "plain" || failed != 0 {
+		t.Errorf("OpenAll(plain) = %q, %d", opened, failed)
+	}
+}
+
+func TestParseKey_Invalid(t *testing.T) {
+	for _, s := range []string{"", "nocolon", "!!:!!", "AAAA:AAAA"} {
+		if _, err := ParseKey(s); err == nil {
+			t.Errorf("ParseKey(%q) expected an error", s)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+// Package objectstore is a minimal client for S3-compatible object storage (AWS S3, MinIO,
+// Cloudflare R2, GCS interoperability mode, ...) using path-style addressing and SigV4 signing.
+package objectstore
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when an object doesn't exist
+var ErrNotFound = errors.New("object not found")
+
+// Object describes a stored object
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Client stores objects in one bucket
+type Client struct {
+	endpoint   string
+	bucket     string
+	region     string
+	accessKey  string
+	secretKey  string
+	httpClient *http.Client
+}
+
+// New creates a client for bucket at endpoint, the region defaults to us-east-1
+func New(endpoint, bucket, region, accessKey, secretKey string) (*Client, error) {
+	if endpoint == "" || bucket == "" {
+		return nil, fmt.Errorf("object storage endpoint and bucket are required")
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("object storage credentials are required")
+	}
+	if _, err := url.Parse(endpoint); err != nil {
+		return nil, fmt.Errorf("invalid object storage endpoint: %w", err)
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &Client{
+		endpoint:   strings.TrimSuffix(endpoint, "/"),
+		bucket:     bucket,
+		region:     region,
+		accessKey:  accessKey,
+		secretKey:  secretKey,
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// Get opens the object stored under key, ErrNotFound if there is none
+func (c *Client) Get(key string) (io.ReadCloser, error) {
+	resp, err := c.do("GET", key, nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError(resp)
+	}
+	return resp.Body, nil
+}
+
+// Put stores body under key
+func (c *Client) Put(key, contentType string, body []byte) error {
+	resp, err := c.do("PUT", key, nil, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return statusError(resp)
+	}
+	return nil
+}
+
+// PutStream stores size bytes read from body under key without holding them in memory. The
+// payload is sent unsigned, so only the request itself is authenticated. A body of unknown length,
+// size -1, is uploaded in parts of partSize (see putMultipart).
+func (c *Client) PutStream(key, contentType string, body io.Reader, size int64) error {
+	if size < 0 {
+		return c.putMultipart(key, contentType, body)
+	}
+	resp, err := c.send("PUT", key, nil, body, size, unsignedPayload, contentType)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return statusError(resp)
+	}
+	return nil
+}
+
+// partSize is the size of the parts of multipart uploads, S3 requires 5MB but for the last part
+var partSize = 8 * 1024 * 1024
+
+// putMultipart uploads body in parts, holding one part in memory. A body that fits in one part is
+// stored with a single request.
+func (c *Client) putMultipart(key, contentType string, body io.Reader) error {
+	part := make([]byte, partSize)
+	n, err := io.ReadFull(body, part)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return c.Put(key, contentType, part[:n])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	uploadID, err := c.createMultipartUpload(key, contentType)
+	if err != nil {
+		return err
+	}
+	var parts []completedPart
+	for number := 1; ; number++ {
+		etag, err := c.uploadPart(key, uploadID, number, part[:n])
+		if err != nil {
+			c.abortMultipartUpload(key, uploadID)
+			return err
+		}
+		parts = append(parts, completedPart{PartNumber: number, ETag: etag})
+
+		n, err = io.ReadFull(body, part)
+		if err == io.EOF {
+			break
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			c.abortMultipartUpload(key, uploadID)
+			return fmt.Errorf("failed to read upload: %w", err)
+		}
+	}
+
+	if err := c.completeMultipartUpload(key, uploadID, parts); err != nil {
+		c.abortMultipartUpload(key, uploadID)
+		return err
+	}
+	return nil
+}
+
+// completedPart is a part listed in a CompleteMultipartUpload request
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// createMultipartUpload starts a multipart upload to key and returns its id
+func (c *Client) createMultipartUpload(key, contentType string) (string, error) {
+	resp, err := c.do("POST", key, url.Values{"uploads": {""}}, nil, contentType)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil || result.UploadID == "" {
+		return "", fmt.Errorf("failed to start multipart upload: %v", err)
+	}
+	return result.UploadID, nil
+}
+
+// uploadPart uploads part number of a multipart upload and returns its ETag
+func (c *Client) uploadPart(key, uploadID string, number int, data []byte) (string, error) {
+	query := url.Values{"partNumber": {fmt.Sprint(number)}, "uploadId": {uploadID}}
+	resp, err := c.do("PUT", key, query, data, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", statusError(resp)
+	}
+	resp.Body.Close()
+	return resp.Header.Get("ETag"), nil
+}
+
+// completeMultipartUpload joins the uploaded parts into the object
+func (c *Client) completeMultipartUpload(key, uploadID string, parts []completedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return fmt.Errorf("failed to encode multipart upload: %w", err)
+	}
+
+	resp, err := c.do("POST", key, url.Values{"uploadId": {uploadID}}, body, "application/xml")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+	defer resp.Body.Close()
+
+	// S3 reports some failures with status 200 and an error document
+	result, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	if bytes.Contains(result, []byte("<Error>")) {
+		return fmt.Errorf("failed to complete multipart upload: %s", string(result))
+	}
+	return nil
+}
+
+// abortMultipartUpload discards the parts of a failed multipart upload
+func (c *Client) abortMultipartUpload(key, uploadID string) {
+	resp, err := c.do("DELETE", key, url.Values{"uploadId": {uploadID}}, nil, "")
+	if err == nil {
+		resp.Body.Close()
+	}
+}
+
+// Delete removes the object stored under key, deleting a missing object is not an error
+func (c *Client) Delete(key string) error {
+	resp, err := c.do("DELETE", key, nil, nil, "")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return statusError(resp)
+	}
+	return nil
+}
+
+// listResult is the part of a ListObjectsV2 response the client uses
+type listResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// List returns the objects whose key starts with prefix, in key order
+func (c *Client) List(prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := c.do("GET", "", query, nil, "")
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, statusError(resp)
+		}
+
+		var result listResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object listing: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, Object{Key: content.Key, Size: content.Size, LastModified: content.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// statusError reads an unexpected response into an error and closes its body
+func statusError(resp *http.Response) error {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("object storage returned status %d: %s", resp.StatusCode, string(body))
+}
+
+// unsignedPayload replaces the payload hash of requests whose body is streamed
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// do sends a SigV4-signed request for the given object key, or for the bucket if key is empty
+func (c *Client) do(method, key string, query url.Values, body []byte, contentType string) (*http.Response, error) {
+	payloadHash := sha256.Sum256(body)
+	return c.send(method, key, query, bytes.NewReader(body), int64(len(body)), hex.EncodeToString(payloadHash[:]), contentType)
+}
+
+// send sends a SigV4-signed request with a body of size bytes, whose SHA-256 is payloadHash
+func (c *Client) send(method, key string, query url.Values, body io.Reader, size int64, payloadHash, contentType string) (*http.Response, error) {
+	objectURL := fmt.Sprintf("%s/%s/%s", c.endpoint, c.bucket, key)
+	if key == "" {
+		objectURL = fmt.Sprintf("%s/%s", c.endpoint, c.bucket)
+	}
+	if size == 0 {
+		body = http.NoBody
+	}
+	req, err := http.NewRequest(method, objectURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.ContentLength = size
+	if query != nil {
+		// Encode sorts by key, as the canonical request requires
+		req.URL.RawQuery = strings.ReplaceAll(query.Encode(), "+", "%20")
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	c.sign(req, payloadHash, time.Now().UTC())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request to object storage: %w", err)
+	}
+	return resp, nil
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (c *Client) sign(req *http.Request, payloadHashHex string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHashHex)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHashHex, amzDate)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHashHex,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", dateStamp, c.region)
+	canonicalHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(canonicalHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+c.secretKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, c.region)
+	signingKey = hmacSHA256(signingKey, "s3")
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
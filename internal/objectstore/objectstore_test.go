@@ -0,0 +1,183 @@
+package objectstore
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeStorage is a minimal in-memory S3-compatible server for bucket "bucket"
+type fakeStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	pageLen int
+	parts   map[string][][]byte // Parts of multipart uploads by upload id
+}
+
+func (f *fakeStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=access/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+	uploadID := r.URL.Query().Get("uploadId")
+	switch {
+	case r.Method == "GET" && r.URL.Path == "/bucket":
+		f.list(w, r)
+	case r.Method == "POST" && r.URL.Query().Has("uploads"):
+		uploadID := fmt.Sprintf("upload-%d", len(f.parts)+1)
+		f.parts[uploadID] = nil
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", uploadID)
+	case r.Method == "PUT" && uploadID != "":
+		data, _ := io.ReadAll(r.Body)
+		f.parts[uploadID] = append(f.parts[uploadID], data)
+		w.Header().Set("ETag", fmt.Sprintf(`"%d"`, len(f.parts[uploadID])))
+	case r.Method == "POST" && uploadID != "":
+		f.objects[key] = bytes.Join(f.parts[uploadID], nil)
+		delete(f.parts, uploadID)
+		io.WriteString(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	case r.Method == "PUT":
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+	case r.Method == "GET":
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == "DELETE":
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeStorage) list(w http.ResponseWriter, r *http.Request) {
+	var keys []string
+	for key := range f.objects {
+		if strings.HasPrefix(key, r.URL.Query().Get("prefix")) && key > r.URL.Query().Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	type content struct{ Key string }
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []content
+		IsTruncated           bool
+		NextContinuationToken string
+	}{}
+	if len(keys) > f.pageLen {
+		keys = keys[:f.pageLen]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{Key: key})
+	}
+	xml.NewEncoder(w).Encode(result)
+}
+
+func TestClient(t *testing.T) {
+	fake := &fakeStorage{objects: make(map[string][]byte), pageLen: 2}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := New(server.URL, "bucket", "", "access", "secret")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for _, key := range []string{"backups/a", "backups/b", "backups/c", "other/d"} {
+		if err := client.Put(key, "application/octet-stream", []byte(key)); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	if err := client.PutStream("streamed", "application/octet-stream", strings.NewReader("streamed body"), 13); err != nil {
+		t.Fatalf("PutStream() error = %v", err)
+	}
+	if string(fake.objects["streamed"]) != "streamed body" {
+		t.Errorf("PutStream() stored %q", fake.objects["streamed"])
+	}
+
+	body, err := client.Get("backups/b")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "backups/b" {
+		t.Errorf("Get() = %q", data)
+	}
+	if _, err := client.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) error = %v, want ErrNotFound", err)
+	}
+
+	objects, err := client.List("backups/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(objects) != 3 || objects[2].Key != "backups/c" {
+		t.Errorf("List() = %+v, want the three backups across pages", objects)
+	}
+
+	if err := client.Delete("backups/a"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if err := client.Delete("backups/a"); err != nil {
+		t.Errorf("Delete(missing) error = %v, want nil", err)
+	}
+	if objects, _ := client.List("backups/"); len(objects) != 2 {
+		t.Errorf("List() after Delete = %+v", objects)
+	}
+}
+
+func TestPutStreamMultipart(t *testing.T) {
+	fake := &fakeStorage{objects: make(map[string][]byte), parts: make(map[string][][]byte)}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := New(server.URL, "bucket", "", "access", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(size int) { partSize = size }(partSize)
+	partSize = 4
+
+	for _, body := range []string{"abc", "abcdefghij", "abcdefgh"} {
+		if err := client.PutStream(body, "application/gzip", strings.NewReader(body), -1); err != nil {
+			t.Fatalf("PutStream(%q) error = %v", body, err)
+		}
+		if string(fake.objects[body]) != body {
+			t.Errorf("PutStream(%q) stored %q", body, fake.objects[body])
+		}
+	}
+	if len(fake.parts) != 0 {
+		t.Errorf("multipart uploads left open: %v", fake.parts)
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	if _, err := New("", "bucket", "", "a", "b"); err == nil {
+		t.Error("Expected error for missing endpoint")
+	}
+	if _, err := New("https://s3.example.com", "bucket", "", "", ""); err == nil {
+		t.Error("Expected error for missing credentials")
+	}
+}
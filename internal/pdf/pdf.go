@@ -0,0 +1,319 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Markdown to PDF without external tools: notes are laid out as text on A4 pages with the
+// standard PDF fonts, which every viewer has built in. Those fonts only cover Latin text
+// (Windows-1252), so emoji are dropped and other characters show as "?". Images are not
+// embedded, they are shown as their alt text.
+
+// ErrTooManyPages is returned when a document needs more pages than allowed
+var ErrTooManyPages = errors.New("document has too many pages")
+
+const (
+	pageWidth    = 595.0 // A4 in points
+	pageHeight   = 842.0
+	marginX      = 50.0
+	marginTop    = 60.0
+	marginBottom = 60.0
+	footerY      = 30.0
+	lineSpacing  = 1.4
+	textWidth    = pageWidth - 2*marginX
+	listIndent   = 16.0
+)
+
+// Fonts of the document, named as in the page resources
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+	fontMono    = "F3"
+	fontItalic  = "F4"
+)
+
+var fontNames = []struct{ key, base string }{
+	{fontRegular, "Helvetica"},
+	{fontBold, "Helvetica-Bold"},
+	{fontMono, "Courier"},
+	{fontItalic, "Helvetica-Oblique"},
+}
+
+// style is the font and size of a line
+type style struct {
+	font string
+	size float64
+}
+
+var (
+	bodyStyle     = style{fontRegular, 11}
+	quoteStyle    = style{fontItalic, 11}
+	codeStyle     = style{fontMono, 9.5}
+	headingStyles = []style{{fontBold, 20}, {fontBold, 16}, {fontBold, 13.5}, {fontBold, 12}}
+)
+
+var (
+	commentRe  = regexp.MustCompile(`(?s)<!--.*?-->`)
+	imageRe    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	linkRe     = regexp.MustCompile(`\[([^\]]+)\]\(([^)]+)\)`)
+	htmlTagRe  = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	headingRe  = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	listItemRe = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	taskRe     = regexp.MustCompile(`^\[([ xX])\]\s+`)
+	ruleRe     = regexp.MustCompile(`^\s*([-*_])(\s*([-*_])){2,}\s*$`)
+)
+
+// Markdown renders markdown as an A4 PDF titled title, failing with ErrTooManyPages beyond maxPages
+func Markdown(title, markdown string, maxPages int) ([]byte, error) {
+	l := newLayout(maxPages)
+	l.text(title, headingStyles[0], 0)
+	l.gap(6)
+
+	inCode := false
+	for _, line := range strings.Split(commentRe.ReplaceAllString(markdown, ""), "\n") {
+		line = strings.TrimRight(line, " \t\r")
+
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCode = !inCode
+			l.gap(4)
+			continue
+		}
+		if inCode {
+			l.code(line)
+			continue
+		}
+
+		switch trimmed := strings.TrimSpace(line); {
+		case trimmed == "":
+			l.gap(bodyStyle.size * 0.6)
+		case ruleRe.MatchString(trimmed):
+			l.rule()
+		case headingRe.MatchString(trimmed):
+			m := headingRe.FindStringSubmatch(trimmed)
+			level := len(m[1]) - 1
+			if level >= len(headingStyles) {
+				level = len(headingStyles) - 1
+			}
+			l.gap(headingStyles[level].size * 0.5)
+			l.text(inline(m[2]), headingStyles[level], 0)
+		case strings.HasPrefix(trimmed, ">"):
+			l.text(inline(strings.TrimSpace(strings.TrimLeft(trimmed, "> "))), quoteStyle, listIndent)
+		case listItemRe.MatchString(line):
+			m := listItemRe.FindStringSubmatch(line)
+			depth := float64(len(strings.ReplaceAll(m[1], "\t", "  "))/2 + 1)
+			marker := "•"
+			if m[2][0] >= '0' && m[2][0] <= '9' {
+				marker = m[2]
+			}
+			item := m[3]
+			if task := taskRe.FindStringSubmatch(item); task != nil {
+				marker = "[ ]"
+				if task[1] != " " {
+					marker = "[x]"
+				}
+				item = item[len(task[0]):]
+			}
+			l.listItem(marker, inline(item), depth*listIndent)
+		default:
+			l.text(inline(trimmed), bodyStyle, 0)
+		}
+
+		if l.err != nil {
+			return nil, l.err
+		}
+	}
+
+	if l.err != nil {
+		return nil, l.err
+	}
+	return l.document(title)
+}
+
+// inline reduces inline markdown and HTML to plain text
+func inline(s string) string {
+	s = imageRe.ReplaceAllStringFunc(s, func(m string) string {
+		alt := imageRe.FindStringSubmatch(m)[1]
+		if alt == "" {
+			alt = "image"
+		}
+		return "[" + alt + "]"
+	})
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := linkRe.FindStringSubmatch(m)
+		if parts[1] == parts[2] {
+			return parts[1]
+		}
+		return parts[1] + " (" + parts[2] + ")"
+	})
+	s = htmlTagRe.ReplaceAllString(s, "")
+	for _, marker := range []string{"**", "__", "~~", "`"} {
+		s = strings.ReplaceAll(s, marker, "")
+	}
+	return s
+}
+
+// layout places lines on pages top to bottom
+type layout struct {
+	pages    []*bytes.Buffer
+	y        float64
+	maxPages int
+	err      error
+}
+
+func newLayout(maxPages int) *layout {
+	l := &layout{maxPages: maxPages}
+	l.newPage()
+	return l
+}
+
+func (l *layout) newPage() {
+	if l.maxPages > 0 && len(l.pages) >= l.maxPages {
+		l.err = ErrTooManyPages
+		return
+	}
+	l.pages = append(l.pages, &bytes.Buffer{})
+	l.y = pageHeight - marginTop
+}
+
+// advance moves down by height, starting a new page if it doesn't fit
+func (l *layout) advance(height float64) {
+	if l.err != nil {
+		return
+	}
+	if l.y-height < marginBottom {
+		l.newPage()
+	}
+	l.y -= height
+}
+
+func (l *layout) gap(height float64) {
+	if l.err != nil || l.y == pageHeight-marginTop {
+		return
+	}
+	if l.y-height < marginBottom {
+		l.newPage()
+		return
+	}
+	l.y -= height
+}
+
+// draw writes text at x on the current line
+func (l *layout) draw(text string, st style, x float64) {
+	if l.err != nil || text == "" {
+		return
+	}
+	fmt.Fprintf(l.pages[len(l.pages)-1], "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", st.font, st.size, x, l.y, escape(text))
+}
+
+// text writes a paragraph wrapped to the text width minus indent
+func (l *layout) text(s string, st style, indent float64) {
+	for _, line := range wrap(encode(s), st, textWidth-indent) {
+		l.advance(st.size * lineSpacing)
+		l.draw(line, st, marginX+indent)
+	}
+}
+
+// listItem writes a paragraph with marker hanging in front of it
+func (l *layout) listItem(marker, s string, indent float64) {
+	lines := wrap(encode(s), bodyStyle, textWidth-indent)
+	for i, line := range lines {
+		l.advance(bodyStyle.size * lineSpacing)
+		if i == 0 {
+			l.draw(encode(marker), bodyStyle, marginX+indent-listIndent+2)
+		}
+		l.draw(line, bodyStyle, marginX+indent)
+	}
+}
+
+// code writes a line of a code block, broken at the text width without word wrapping
+func (l *layout) code(s string) {
+	perLine := int(textWidth / (codeStyle.size * 0.6))
+	text := encode(strings.ReplaceAll(s, "\t", "    "))
+	for first := true; first || text != ""; first = false {
+		n := len(text)
+		if n > perLine {
+			n = perLine
+		}
+		l.advance(codeStyle.size * lineSpacing)
+		l.draw(text[:n], codeStyle, marginX+8)
+		text = text[n:]
+	}
+}
+
+// rule draws a horizontal line
+func (l *layout) rule() {
+	l.advance(bodyStyle.size)
+	if l.err != nil {
+		return
+	}
+	y := l.y + bodyStyle.size/2
+	fmt.Fprintf(l.pages[len(l.pages)-1], "0.75 G 0.5 w %.2f %.2f m %.2f %.2f l S 0 G\n", marginX, y, pageWidth-marginX, y)
+}
+
+// document assembles the pages into a PDF file
+func (l *layout) document(title string) ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	// Objects: catalog, page tree, info, fonts, then a page and its content per page
+	firstPage := 4 + len(fontNames)
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	kids := make([]string, len(l.pages))
+	for i := range l.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(l.pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Producer (msg2git) >>", escape(encode(title))))
+
+	fonts := make([]string, len(fontNames))
+	for i, font := range fontNames {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.base))
+		fonts[i] = fmt.Sprintf("/%s %d 0 R", font.key, 4+i)
+	}
+
+	for i, page := range l.pages {
+		footer := fmt.Sprintf("%d / %d", i+1, len(l.pages))
+		fmt.Fprintf(page, "0.5 g BT /%s 9 Tf %.2f %.2f Td (%s) Tj ET 0 g\n", fontRegular,
+			(pageWidth-measure(footer, style{fontRegular, 9}))/2, footerY, footer)
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(page.Bytes()); err != nil {
+			return nil, fmt.Errorf("failed to compress page: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress page: %w", err)
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, strings.Join(fonts, " "), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes(), nil
+}
+
+// escape makes Windows-1252 text safe inside a PDF string
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `(`, `\(`, `)`, `\)`)
+	return r.Replace(s)
+}
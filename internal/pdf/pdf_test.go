@@ -0,0 +1,118 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// pageContents returns the decompressed content streams of a document
+func pageContents(t *testing.T, doc []byte) []string {
+	t.Helper()
+	streamRe := regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`)
+
+	var contents []string
+	for _, m := range streamRe.FindAllSubmatch(doc, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatalf("zlib.NewReader() error = %v", err)
+		}
+		content, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("ReadAll() error = %v", err)
+		}
+		contents = append(contents, string(content))
+	}
+	return contents
+}
+
+func TestMarkdown(t *testing.T) {
+	md := "<!--\n[1] [42] [2025-03-04 05:06] \n-->\n\n## Trip (day 1) 🚆\n#travel\n\n- [x] Pack\n- see [map](https://example.com/map)\n\n```\ncode \\ here\n    indented\n```\n\n---\n"
+	doc, err := Markdown("notes.md", md, 10)
+	if err != nil {
+		t.Fatalf("Markdown() error = %v", err)
+	}
+
+	if !bytes.HasPrefix(doc, []byte("%PDF-1.4")) || !bytes.HasSuffix(doc, []byte("%%EOF\n")) {
+		t.Error("Markdown() is not a PDF file")
+	}
+	if !bytes.Contains(doc, []byte("/Count 1")) {
+		t.Error("Markdown() should fit on one page")
+	}
+
+	contents := pageContents(t, doc)
+	if len(contents) != 1 {
+		t.Fatalf("got %d content streams, want 1", len(contents))
+	}
+	page := contents[0]
+	for _, want := range []string{
+		"(notes.md)",
+		"/F2 16.0 Tf", "(Trip \\(day 1\\))",
+		"([x])", "(Pack)",
+		"(see map \\(https://example.com/map\\))",
+		"/F3 9.5 Tf", "(code \\\\ here)", "(    indented)",
+		" l S ",
+		"(1 / 1)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page content is missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, "2025-03-04") {
+		t.Error("metadata comments should not be rendered")
+	}
+}
+
+func TestMarkdownTooManyPages(t *testing.T) {
+	md := strings.Repeat("line\n", 500)
+	if _, err := Markdown("long.md", md, 3); !errors.Is(err, ErrTooManyPages) {
+		t.Errorf("Markdown() error = %v, want ErrTooManyPages", err)
+	}
+
+	doc, err := Markdown("long.md", md, 0)
+	if err != nil {
+		t.Fatalf("Markdown() without a page limit error = %v", err)
+	}
+	if n := len(pageContents(t, doc)); n < 4 {
+		t.Errorf("got %d pages, want the document spread over several pages", n)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"café – “quoted”", "caf\xe9 \x96 \x93quoted\x94"},
+		{"🙂 good 👍🏽", " good "},
+		{"日本", "??"},
+		{"tab\there", "tab here"},
+	}
+
+	for _, tt := range tests {
+		if got := encode(tt.in); got != tt.want {
+			t.Errorf("encode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	lines := wrap(strings.Repeat("word ", 40), bodyStyle, 200)
+	if len(lines) < 2 {
+		t.Fatalf("wrap() = %v, want several lines", lines)
+	}
+	for _, line := range lines {
+		if measure(line, bodyStyle) > 200 {
+			t.Errorf("line %q is wider than 200pt", line)
+		}
+	}
+
+	long := strings.Repeat("x", 200)
+	if got := strings.Join(wrap(long, bodyStyle, 100), ""); got != long {
+		t.Error("wrap() lost characters of a word longer than the line")
+	}
+}
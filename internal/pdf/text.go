@@ -0,0 +1,117 @@
+package pdf
+
+import (
+	"strings"
+)
+
+// Text in the standard fonts is Windows-1252 encoded, one byte per character, and measured with
+// the Helvetica metrics of the font's AFM file.
+
+// winAnsiExtra maps the characters Windows-1252 places in 0x80-0x9F
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// encode converts s to Windows-1252, dropping emoji and replacing other characters with "?"
+func encode(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			sb.WriteByte(' ')
+		case r < 0x20:
+			// Control characters have no glyph
+		case r < 0x7F || (r >= 0xA0 && r <= 0xFF):
+			sb.WriteByte(byte(r))
+		case winAnsiExtra[r] != 0:
+			sb.WriteByte(winAnsiExtra[r])
+		case isEmoji(r):
+			// Dropped rather than shown as "?" since they are mostly decoration
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// isEmoji reports whether r is an emoji or a character joining or varying emoji
+func isEmoji(r rune) bool {
+	return r >= 0x1F000 ||
+		(r >= 0x2600 && r <= 0x27BF) ||
+		(r >= 0x2B00 && r <= 0x2BFF) ||
+		(r >= 0xFE00 && r <= 0xFE0F) ||
+		r == 0x200D || r == 0x20E3
+}
+
+// helveticaWidths are the widths of ASCII 0x20-0x7E in Helvetica, in 1/1000 of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// measure returns the width of encoded text in points
+func measure(text string, st style) float64 {
+	if st.font == fontMono {
+		return float64(len(text)) * 600 * st.size / 1000
+	}
+
+	total := 0
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c >= 0x20 && c <= 0x7E:
+			total += helveticaWidths[c-0x20]
+		case c == 0x95:
+			total += 350
+		default:
+			total += 556
+		}
+	}
+
+	width := float64(total) * st.size / 1000
+	if st.font == fontBold {
+		// Bold glyphs are up to a tenth wider, overestimating keeps lines inside the margin
+		width *= 1.1
+	}
+	return width
+}
+
+// wrap breaks encoded text into lines no wider than width, splitting words that don't fit alone
+func wrap(text string, st style, width float64) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if measure(candidate, st) <= width {
+			line = candidate
+			continue
+		}
+
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for measure(word, st) > width {
+			n := len(word) - 1
+			for n > 1 && measure(word[:n], st) > width {
+				n--
+			}
+			lines = append(lines, word[:n])
+			word = word[n:]
+		}
+		line = word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,112 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/consts"
+)
+
+// Plain text for screen readers: formatted replies are turned into concise text without markup,
+// decorative emoji or progress bars drawn with block glyphs, which are read out character by
+// character. The status emoji starting a line become words.
+
+var (
+	plainLinkRegex = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	plainTagRegex  = regexp.MustCompile(`<[^>]*>`)
+	// plainBarRegex matches progress bars like "█████░░░" and "[▓▓▓░░░░░░░]"
+	plainBarRegex   = regexp.MustCompile(`\[?[\x{2580}-\x{259F}]+\]? ?`)
+	plainBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// plainStatusWords are the emoji starting a line that carry meaning, and the words replacing them
+var plainStatusWords = []struct{ emoji, word string }{
+	{"✅", "Done:"},
+	{"❌", "Error:"},
+	{"⚠️", "Warning:"},
+	{"⚠", "Warning:"},
+	{"🚫", "Blocked:"},
+}
+
+// PlainText returns text sent with the Telegram parse mode as concise plain text. HTML loses its
+// tags (links become "text (url)") and must then be sent without a parse mode; MarkdownV2 keeps
+// its markup and parse mode.
+func PlainText(text, parseMode string) string {
+	if strings.EqualFold(parseMode, consts.ParseModeHTML) {
+		text = plainLinkRegex.ReplaceAllStringFunc(text, func(link string) string {
+			match := plainLinkRegex.FindStringSubmatch(link)
+			label := plainTagRegex.ReplaceAllString(match[2], "")
+			if url := html.UnescapeString(match[1]); html.UnescapeString(label) != url {
+				return label + " (" + html.EscapeString(url) + ")"
+			}
+			return label
+		})
+		text = html.UnescapeString(plainTagRegex.ReplaceAllString(text, ""))
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = plainLine(line)
+	}
+	return strings.TrimSpace(plainBlankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+// plainLine drops the emoji and progress bars of a line, the status emoji it starts with become
+// words. Lines without any are left as they are, e.g. indented code.
+func plainLine(line string) string {
+	text := strings.TrimLeft(line, " ")
+	original := text
+	prefix := ""
+	for _, status := range plainStatusWords {
+		if strings.HasPrefix(text, status.emoji) {
+			prefix = status.word + " "
+			text = strings.TrimPrefix(text, status.emoji)
+			break
+		}
+	}
+	if strings.HasPrefix(text, "• ") {
+		text = "- " + strings.TrimPrefix(text, "• ")
+	}
+	text = dropEmoji(plainBarRegex.ReplaceAllString(text, ""))
+
+	if text == original {
+		return line
+	}
+	return strings.TrimSpace(prefix + strings.TrimSpace(text))
+}
+
+// dropEmoji removes the emoji of s along with the space setting each off
+func dropEmoji(s string) string {
+	var sb strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if !isEmoji(runes[i]) {
+			sb.WriteRune(runes[i])
+			continue
+		}
+		for i+1 < len(runes) && isEmoji(runes[i+1]) {
+			i++
+		}
+		if i+1 < len(runes) && runes[i+1] == ' ' && (sb.Len() == 0 || strings.HasSuffix(sb.String(), " ")) {
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// isEmoji reports whether r is a pictograph or part of an emoji sequence. Arrows like → are kept.
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF, // Pictographs, emoticons, flags
+		r >= 0x2600 && r <= 0x27BF,            // Miscellaneous symbols and dingbats
+		r >= 0x2B00 && r <= 0x2BFF,            // Stars and squares drawn as emoji
+		r >= 0x2300 && r <= 0x23FF,            // Hourglasses, clocks and media buttons
+		r >= 0x25A0 && r <= 0x25FF,            // Geometric shapes like ▶ and ●
+		r >= 0xE0020 && r <= 0xE007F,          // Tag sequences of subdivision flags
+		r == 0xFE0F, r == 0x200D, r == 0x20E3, // Variation selector, joiner and keycap
+		r == 0x2139, r == 0x203C, r == 0x2049:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package render
+
+import "testing"
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		parseMode string
+		want      string
+	}{
+		{
+			name:      "status emoji and markup",
+			text:      "✅ <b>Saved</b> to <a href=\"https://github.com/o/r/blob/main/note.md\">note.md</a> 🎉",
+			parseMode: "html",
+			want:      "Done: Saved to note.md (https://github.com/o/r/blob/main/note.md)",
+		},
+		{
+			name:      "links showing their URL",
+			text:      `<a href="https://example.com/?a=1&amp;b=2">https://example.com/?a=1&amp;b=2</a>`,
+			parseMode: "HTML",
+			want:      "https://example.com/?a=1&b=2",
+		},
+		{
+			name:      "progress bars and bullets",
+			text:      "📊 <b>Usage</b>\n\n\n\n• Repository: ██████░░░░░░ 50%\n• Photos: [▓▓▓░░░░░░░] 30%",
+			parseMode: "html",
+			want:      "Usage\n\n- Repository: 50%\n- Photos: 30%",
+		},
+		{
+			name:      "escaped text and errors",
+			text:      "❌ Failed: a &lt; b\n⚠️ Check /repo",
+			parseMode: "html",
+			want:      "Error: Failed: a < b\nWarning: Check /repo",
+		},
+		{
+			name: "lines without emoji keep their indentation",
+			text: "🧾 Result\n    x := 1 → 2",
+			want: "Result\n    x := 1 → 2",
+		},
+		{
+			name: "plain text isn't unescaped",
+			text: "📝 a <b> &amp; 1️⃣",
+			want: "a <b> &amp; 1",
+		},
+		{
+			name:      "MarkdownV2 keeps its markup",
+			text:      "🔥 *Streak* 3 days\\!",
+			parseMode: "MarkdownV2",
+			want:      "*Streak* 3 days\\!",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PlainText(tt.text, tt.parseMode); got != tt.want {
+				t.Errorf("PlainText() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
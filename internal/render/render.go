@@ -0,0 +1,168 @@
+package render
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/consts"
+)
+
+// Typed builders for outgoing Telegram messages: text goes in through methods that escape it
+// for the chosen parse mode instead of hand-built HTML strings
+
+// Format is a Telegram parse mode messages are rendered for
+type Format int
+
+const (
+	HTML Format = iota
+	MarkdownV2
+)
+
+// markdownV2Special lists the characters MarkdownV2 requires to be escaped in text
+const markdownV2Special = "_*[]()~`>#+-=|{}.!\\"
+
+// ParseMode returns the Telegram parse mode of the format
+func (f Format) ParseMode() string {
+	if f == MarkdownV2 {
+		return consts.ParseModeMarkdown
+	}
+	return consts.ParseModeHTML
+}
+
+// Escape makes s safe to show as plain text in the format
+func (f Format) Escape(s string) string {
+	if f == MarkdownV2 {
+		return escapeMarkdownV2(s, markdownV2Special)
+	}
+	return html.EscapeString(s)
+}
+
+// escapeMarkdownV2 prefixes every special character of s with a backslash
+func escapeMarkdownV2(s, special string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Message builds a formatted Telegram message. Every method escapes its arguments, so user
+// content can't break the formatting or inject markup.
+type Message struct {
+	format Format
+	sb     strings.Builder
+}
+
+// New starts an empty message in the given format
+func New(format Format) *Message {
+	return &Message{format: format}
+}
+
+// Format returns the format the message is rendered in
+func (m *Message) Format() Format {
+	return m.format
+}
+
+// ParseMode returns the Telegram parse mode to send the message with
+func (m *Message) ParseMode() string {
+	return m.format.ParseMode()
+}
+
+// String returns the rendered message
+func (m *Message) String() string {
+	return m.sb.String()
+}
+
+// Len returns the length of the rendered message in bytes
+func (m *Message) Len() int {
+	return m.sb.Len()
+}
+
+// Text appends plain text
+func (m *Message) Text(s string) *Message {
+	m.sb.WriteString(m.format.Escape(s))
+	return m
+}
+
+// Textf appends formatted plain text, the result is escaped as a whole
+func (m *Message) Textf(format string, args ...interface{}) *Message {
+	return m.Text(fmt.Sprintf(format, args...))
+}
+
+// Line appends plain text followed by a line break
+func (m *Message) Line(s string) *Message {
+	return m.Text(s).Newline()
+}
+
+// Newline appends a line break
+func (m *Message) Newline() *Message {
+	m.sb.WriteString("\n")
+	return m
+}
+
+// Bold appends bold text
+func (m *Message) Bold(s string) *Message {
+	return m.wrap(s, "<b>", "</b>", "*", "*")
+}
+
+// Italic appends italic text
+func (m *Message) Italic(s string) *Message {
+	return m.wrap(s, "<i>", "</i>", "_", "_")
+}
+
+// Code appends inline code
+func (m *Message) Code(s string) *Message {
+	if m.format == MarkdownV2 {
+		m.sb.WriteString("`" + escapeMarkdownV2(s, "`\\") + "`")
+		return m
+	}
+	m.sb.WriteString("<code>" + html.EscapeString(s) + "</code>")
+	return m
+}
+
+// Pre appends a code block, language enables syntax highlighting when set
+func (m *Message) Pre(s, language string) *Message {
+	if m.format == MarkdownV2 {
+		m.sb.WriteString("```" + language + "\n" + escapeMarkdownV2(s, "`\\") + "\n```")
+		return m
+	}
+	if language == "" {
+		m.sb.WriteString("<pre>" + html.EscapeString(s) + "</pre>")
+		return m
+	}
+	m.sb.WriteString(fmt.Sprintf(`<pre><code class="language-%s">%s</code></pre>`, html.EscapeString(language), html.EscapeString(s)))
+	return m
+}
+
+// Link appends text linking to url
+func (m *Message) Link(text, url string) *Message {
+	if m.format == MarkdownV2 {
+		m.sb.WriteString("[" + m.format.Escape(text) + "](" + escapeMarkdownV2(url, ")\\") + ")")
+		return m
+	}
+	m.sb.WriteString(`<a href="` + html.EscapeString(url) + `">` + html.EscapeString(text) + "</a>")
+	return m
+}
+
+// Append appends another message. A message in another format is appended as plain text,
+// its markup can't be translated.
+func (m *Message) Append(other *Message) *Message {
+	if other.format != m.format {
+		return m.Text(other.String())
+	}
+	m.sb.WriteString(other.String())
+	return m
+}
+
+func (m *Message) wrap(s, htmlOpen, htmlClose, markdownOpen, markdownClose string) *Message {
+	if m.format == MarkdownV2 {
+		m.sb.WriteString(markdownOpen + m.format.Escape(s) + markdownClose)
+		return m
+	}
+	m.sb.WriteString(htmlOpen + html.EscapeString(s) + htmlClose)
+	return m
+}
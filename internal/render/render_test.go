@@ -0,0 +1,61 @@
+package render
+
+import "testing"
+
+func TestHTMLEscaping(t *testing.T) {
+	msg := New(HTML).
+		Bold("a < b").Text(" & ").Italic("<i>").Newline().
+		Code("x := <-ch").Text(" ").
+		Link(`say "hi"`, `https://example.com/?a=1&b="2"`)
+
+	want := "<b>a &lt; b</b> &amp; <i>&lt;i&gt;</i>\n<code>x := &lt;-ch</code> <a href=\"https://example.com/?a=1&amp;b=&#34;2&#34;\">say &#34;hi&#34;</a>"
+	if got := msg.String(); got != want {
+		t.Errorf("HTML message =\n%s\nwant\n%s", got, want)
+	}
+	if msg.ParseMode() != "HTML" {
+		t.Errorf("ParseMode() = %q", msg.ParseMode())
+	}
+}
+
+func TestMarkdownV2Escaping(t *testing.T) {
+	msg := New(MarkdownV2).
+		Bold("v1.2_final").Text(" (draft)!").Newline().
+		Code("a`b\\c").Text(" ").
+		Link("docs [old]", "https://example.com/a_(b)")
+
+	want := "*v1\\.2\\_final* \\(draft\\)\\!\n`a\\`b\\\\c` [docs \\[old\\]](https://example.com/a_(b\\))"
+	if got := msg.String(); got != want {
+		t.Errorf("MarkdownV2 message =\n%s\nwant\n%s", got, want)
+	}
+	if msg.ParseMode() != "MarkdownV2" {
+		t.Errorf("ParseMode() = %q", msg.ParseMode())
+	}
+}
+
+func TestPre(t *testing.T) {
+	tests := []struct {
+		format   Format
+		language string
+		want     string
+	}{
+		{HTML, "", "<pre>if a &lt; b {}</pre>"},
+		{HTML, "go", `<pre><code class="language-go">if a &lt; b {}</code></pre>`},
+		{MarkdownV2, "go", "```go\nif a < b {}\n```"},
+	}
+
+	for _, tt := range tests {
+		if got := New(tt.format).Pre("if a < b {}", tt.language).String(); got != tt.want {
+			t.Errorf("Pre(%q) = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
+
+func TestAppend(t *testing.T) {
+	header := New(HTML).Bold("Title")
+	if got := New(HTML).Text("1. ").Append(header).String(); got != "1. <b>Title</b>" {
+		t.Errorf("Append(same format) = %q", got)
+	}
+	if got := New(MarkdownV2).Append(header).String(); got != "<b\\>Title</b\\>" {
+		t.Errorf("Append(other format) = %q", got)
+	}
+}
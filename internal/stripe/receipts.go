@@ -0,0 +1,90 @@
+package stripe
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stripe/stripe-go/v82"
+	"github.com/stripe/stripe-go/v82/checkout/session"
+	"github.com/stripe/stripe-go/v82/invoice"
+)
+
+// InvoiceSummary is a paid Stripe invoice reduced to what a receipt listing needs
+type InvoiceSummary struct {
+	ID          string
+	Number      string
+	Amount      float64 // In major currency units
+	Currency    string
+	Description string
+	Created     time.Time
+}
+
+// ListPaidInvoices returns the customer's most recent paid invoices, newest first
+func (sm *Manager) ListPaidInvoices(customerID string, limit int64) ([]InvoiceSummary, error) {
+	if customerID == "" {
+		return nil, fmt.Errorf("customer ID is required")
+	}
+
+	params := &stripe.InvoiceListParams{
+		Customer: stripe.String(customerID),
+		Status:   stripe.String(string(stripe.InvoiceStatusPaid)),
+	}
+	params.Limit = stripe.Int64(limit)
+	params.Single = true // Only the first page
+
+	var invoices []InvoiceSummary
+	iter := invoice.List(params)
+	for iter.Next() {
+		inv := iter.Invoice()
+		summary := InvoiceSummary{
+			ID:          inv.ID,
+			Number:      inv.Number,
+			Amount:      float64(inv.AmountPaid) / 100,
+			Currency:    strings.ToUpper(string(inv.Currency)),
+			Description: inv.Description,
+			Created:     time.Unix(inv.Created, 0),
+		}
+		if summary.Description == "" && inv.Lines != nil && len(inv.Lines.Data) > 0 {
+			summary.Description = inv.Lines.Data[0].Description
+		}
+		invoices = append(invoices, summary)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list invoices: %w", err)
+	}
+
+	return invoices, nil
+}
+
+// GetReceiptURL returns the Stripe-hosted receipt for a payment. Subscription payments use the
+// hosted invoice page, one-time checkout payments use the receipt of the underlying charge.
+func (sm *Manager) GetReceiptURL(invoiceID, checkoutSessionID string) (string, error) {
+	if invoiceID != "" {
+		inv, err := invoice.Get(invoiceID, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to get invoice: %w", err)
+		}
+		if inv.HostedInvoiceURL != "" {
+			return inv.HostedInvoiceURL, nil
+		}
+		if inv.InvoicePDF != "" {
+			return inv.InvoicePDF, nil
+		}
+	}
+
+	if strings.HasPrefix(checkoutSessionID, "cs_") {
+		params := &stripe.CheckoutSessionParams{}
+		params.AddExpand("payment_intent.latest_charge")
+
+		cs, err := session.Get(checkoutSessionID, params)
+		if err != nil {
+			return "", fmt.Errorf("failed to get checkout session: %w", err)
+		}
+		if cs.PaymentIntent != nil && cs.PaymentIntent.LatestCharge != nil && cs.PaymentIntent.LatestCharge.ReceiptURL != "" {
+			return cs.PaymentIntent.LatestCharge.ReceiptURL, nil
+		}
+	}
+
+	return "", fmt.Errorf("no receipt available for this payment")
+}
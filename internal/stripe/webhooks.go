@@ -3,7 +3,7 @@ package stripe
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -189,7 +189,7 @@ func (sm *Manager) HandleWebhook(w http.ResponseWriter, r *http.Request) (*Payme
 	const MaxBodyBytes = int64(65536)
 	r.Body = http.MaxBytesReader(w, r.Body, MaxBodyBytes)
 
-	body, err := ioutil.ReadAll(r.Body)
+	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		logger.Error("Error reading webhook body", map[string]interface{}{
 			"error": err.Error(),
@@ -0,0 +1,225 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Access anomalies: the commit log records the repository every push went to, and each commit is
+// checked for pushes to a repository other than the configured one (e.g. a stale or mixed up
+// configuration) and for commit spikes far above the user's usual rate. Either pauses the user's
+// GitHub operations until they confirm it was them, or for anomalyPauseDuration at most.
+
+const (
+	anomalyPauseDuration  = 24 * time.Hour
+	anomalyAckDuration    = 24 * time.Hour // Confirmed activity isn't flagged again for this long
+	anomalySpikeWindow    = time.Hour
+	anomalyBaselineWindow = 7 * 24 * time.Hour
+	anomalySpikeMinimum   = 30 // Commits in anomalySpikeWindow that are never a spike
+	anomalySpikeFactor    = 10 // A spike is this many times the usual commits per window
+	accessLogWindow       = 7 * 24 * time.Hour
+)
+
+// providerRepo returns the owner/repo a provider pushes to, "" if unknown
+func providerRepo(provider github.GitHubProvider) string {
+	if provider == nil {
+		return ""
+	}
+	owner, repo, err := provider.GetRepoInfo()
+	if err != nil || owner == "" || repo == "" {
+		return ""
+	}
+	return owner + "/" + repo
+}
+
+// isCommitSpike reports whether recent commits in anomalySpikeWindow are a spike compared to
+// baseline commits in anomalyBaselineWindow
+func isCommitSpike(recent, baseline int) bool {
+	usual := float64(baseline) / float64(anomalyBaselineWindow/anomalySpikeWindow)
+	threshold := usual * anomalySpikeFactor
+	if threshold < anomalySpikeMinimum {
+		threshold = anomalySpikeMinimum
+	}
+	return float64(recent) > threshold
+}
+
+// checkAccessAnomalies pauses the user's operations if the commit just recorded to pushedRepo is
+// unexpected
+func (b *Bot) checkAccessAnomalies(chatID int64, pushedRepo string) {
+	if b.db == nil {
+		return
+	}
+	if _, acknowledged := b.cache.Get(fmt.Sprintf("access_ack_%d", chatID)); acknowledged {
+		return
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil || !user.HasGitHubConfig() {
+		return
+	}
+
+	if owner, repo, err := b.parseGitHubRepoURL(user.GitHubRepo); err == nil && pushedRepo != "" {
+		if configured := owner + "/" + repo; !strings.EqualFold(pushedRepo, configured) {
+			b.pauseOperations(chatID, fmt.Sprintf("your token pushed to %s, but your repository is %s", pushedRepo, configured))
+			return
+		}
+	}
+
+	now := time.Now()
+	recent, err := b.db.CountCommitsBetween(chatID, now.Add(-anomalySpikeWindow), now.Add(time.Second))
+	if err != nil || recent <= anomalySpikeMinimum {
+		return
+	}
+	baseline, err := b.db.CountCommitsBetween(chatID, now.Add(-anomalySpikeWindow-anomalyBaselineWindow), now.Add(-anomalySpikeWindow))
+	if err != nil {
+		logger.Warn("Failed to count commits for anomaly check", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if isCommitSpike(recent, baseline) {
+		b.pauseOperations(chatID, fmt.Sprintf("%d commits in the last hour, far more than usual", recent))
+	}
+}
+
+// pauseOperations pauses the user's GitHub operations and asks them to confirm the activity
+func (b *Bot) pauseOperations(chatID int64, reason string) {
+	started, err := b.db.PauseOperations(chatID, reason, time.Now().Add(anomalyPauseDuration))
+	if err != nil {
+		logger.Error("Failed to pause operations after access anomaly", map[string]interface{}{
+			"chat_id": chatID,
+			"reason":  reason,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if !started {
+		return // Already paused and alerted
+	}
+
+	logger.Warn("Access anomaly, operations paused", map[string]interface{}{
+		"chat_id": chatID,
+		"reason":  reason,
+	})
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(AccessAnomalyTemplate, html.EscapeString(reason), int(anomalyPauseDuration.Hours())))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = accessResumeKeyboard()
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		logger.Error("Failed to send access anomaly alert", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+func accessResumeKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ It was me, resume", "access_resume"),
+	))
+}
+
+// operationsPaused returns an error explaining the pause if the user's operations are paused
+func (b *Bot) operationsPaused(chatID int64) error {
+	pause, err := b.db.GetOperationPause(chatID)
+	if err != nil {
+		logger.Warn("Failed to check operation pause", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	if pause == nil {
+		return nil
+	}
+	return fmt.Errorf("GitHub operations are paused after unusual activity (%s). Use /access to resume", pause.Reason)
+}
+
+// handleAccessCommand shows the repositories the user's token pushed to lately and any pause
+func (b *Bot) handleAccessCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ The access log requires database configuration")
+		return nil
+	}
+
+	now := time.Now()
+	repos, err := b.db.CountCommitsByRepo(chatID, now.Add(-accessLogWindow), now.Add(time.Second))
+	if err != nil {
+		return fmt.Errorf("failed to count commits by repository: %w", err)
+	}
+	pause, err := b.db.GetOperationPause(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get operation pause: %w", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔐 <b>Access log</b>\n\n<b>Pushes in the last 7 days:</b>\n")
+	if len(repos) == 0 {
+		sb.WriteString("<i>No pushes</i>\n")
+	}
+	names := make([]string, 0, len(repos))
+	for name := range repos {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return repos[names[i]] > repos[names[j]] })
+	for _, name := range names {
+		label := name
+		if label == "" {
+			label = "unknown repository"
+		}
+		sb.WriteString(fmt.Sprintf("• %s: %d\n", html.EscapeString(label), repos[name]))
+	}
+
+	if pause == nil {
+		sb.WriteString("\n✅ Operations are active.")
+		b.sendResponse(chatID, sb.String())
+		return nil
+	}
+
+	sb.WriteString(fmt.Sprintf("\n⏸ <b>Operations are paused</b> until %s UTC: %s\n\nIf this activity was yours, resume below. Otherwise revoke your token on GitHub and set a new one with /repo.",
+		pause.ExpiresAt.UTC().Format("2006-01-02 15:04"), html.EscapeString(pause.Reason)))
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = accessResumeKeyboard()
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		return fmt.Errorf("failed to send access log: %w", err)
+	}
+	return nil
+}
+
+// handleAccessResumeCallback ends a pause the user confirmed
+func (b *Bot) handleAccessResumeCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ The access log requires database configuration")
+		return nil
+	}
+
+	resumed, err := b.db.ResumeOperations(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to resume operations: %w", err)
+	}
+	b.cache.SetWithExpiry(fmt.Sprintf("access_ack_%d", chatID), true, anomalyAckDuration)
+
+	if !resumed {
+		b.editMessage(chatID, callback.Message.MessageID, "✅ Operations are active, nothing to resume.")
+		return nil
+	}
+
+	logger.Info("Operations resumed after access anomaly", map[string]interface{}{
+		"chat_id": chatID,
+	})
+	b.editMessage(chatID, callback.Message.MessageID, "✅ Operations resumed. Similar activity won't be flagged again for a day.")
+	return nil
+}
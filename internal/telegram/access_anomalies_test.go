@@ -0,0 +1,31 @@
+package telegram
+
+import "testing"
+
+func TestIsCommitSpike(t *testing.T) {
+	tests := []struct {
+		name     string
+		recent   int
+		baseline int
+		want     bool
+	}{
+		{"quiet user under the minimum", 30, 0, false},
+		{"quiet user over the minimum", 31, 0, true},
+		{"busy user at usual rate", 40, 40 * 168, false},
+		{"busy user ten times usual", 401, 40 * 168, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCommitSpike(tt.recent, tt.baseline); got != tt.want {
+				t.Errorf("isCommitSpike(%d, %d) = %v, want %v", tt.recent, tt.baseline, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProviderRepoWithoutProvider(t *testing.T) {
+	if got := providerRepo(nil); got != "" {
+		t.Errorf("providerRepo(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Admin API: maintenance that has to run inside the bot process, where the clones and their locks
+// live, called by msg2gitctl with ADMIN_API_TOKEN. Tasks that only touch the database are run by
+// msg2gitctl directly.
+
+const adminAPIMaxBody = 64 << 10 // 64 KB
+
+// evictRequest is the JSON body accepted by POST /admin/v1/evict: the repository URL, or the chat
+// whose configured repository is evicted
+type evictRequest struct {
+	Repo   string `json:"repo,omitempty"`
+	ChatID int64  `json:"chat_id,omitempty"`
+}
+
+// evictResponse is returned after an eviction
+type evictResponse struct {
+	Repo    string `json:"repo"`
+	Evicted bool   `json:"evicted"` // False if there was no clone
+}
+
+// authorizeAdminAPI checks the bearer token of an admin API request, writing the error if it fails
+func (b *Bot) authorizeAdminAPI(w http.ResponseWriter, r *http.Request) bool {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	expected := b.cfg().AdminAPIToken
+	if expected == "" || subtle.ConstantTimeCompare([]byte(token), []byte(expected)) != 1 {
+		writeAPIError(w, http.StatusUnauthorized, "invalid admin token")
+		return false
+	}
+	return true
+}
+
+// handleAdminEvict force-evicts the local clone of a repository
+func (b *Bot) handleAdminEvict(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if !b.authorizeAdminAPI(w, r) {
+		return
+	}
+
+	var req evictRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, adminAPIMaxBody)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	repoURL := strings.TrimSpace(req.Repo)
+	if repoURL == "" && req.ChatID != 0 {
+		if b.db == nil {
+			writeAPIError(w, http.StatusServiceUnavailable, "database not configured")
+			return
+		}
+		user, err := b.db.GetUserByChatID(req.ChatID)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, "failed to look up user")
+			return
+		}
+		if user == nil || user.GitHubRepo == "" {
+			writeAPIError(w, http.StatusNotFound, "user has no repository configured")
+			return
+		}
+		repoURL = user.GitHubRepo
+	}
+	if repoURL == "" {
+		writeAPIError(w, http.StatusBadRequest, "repo or chat_id is required")
+		return
+	}
+
+	evicted, err := github.EvictClone(repoURL)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logger.Info("Clone evicted through the admin API", map[string]interface{}{
+		"chat_id": req.ChatID,
+		"evicted": evicted,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(evictResponse{Repo: repoURL, Evicted: evicted})
+}
@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestHandleAdminEvict(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	b := &Bot{config: &config.Config{AdminAPIToken: "operator-token"}}
+	evict := func(token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/v1/evict", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		b.handleAdminEvict(rec, req)
+		return rec
+	}
+
+	if rec := evict("wrong", `{"repo": "https://github.com/alice/notes"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token status = %d, want 401", rec.Code)
+	}
+	if rec := evict("operator-token", `{}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("empty request status = %d, want 400", rec.Code)
+	}
+	if rec := evict("operator-token", `{"chat_id": 42}`); rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("chat_id without database status = %d, want 503", rec.Code)
+	}
+
+	rec := evict("operator-token", `{"repo": "https://github.com/alice/notes"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp evictResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Repo != "https://github.com/alice/notes" || resp.Evicted {
+		t.Errorf("response = %+v, want nothing evicted without a clone", resp)
+	}
+
+	// Without ADMIN_API_TOKEN every token is refused
+	b.config.AdminAPIToken = ""
+	if rec := evict("", `{"repo": "https://github.com/alice/notes"}`); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unconfigured status = %d, want 401", rec.Code)
+	}
+}
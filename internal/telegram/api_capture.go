@@ -0,0 +1,202 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/logger"
+	"golang.org/x/time/rate"
+)
+
+// Capture API: lets msg2git-cli and scripts commit notes with an API key instead of Telegram
+
+const (
+	apiCaptureDefaultFile = "inbox.md"
+	apiCaptureMaxBody     = 1 << 20 // 1 MB
+
+	// Captures each API key may make, sustained and in a burst
+	apiCaptureRate  = rate.Limit(1.0 / 6) // 10 per minute
+	apiCaptureBurst = 10
+)
+
+// captureRequest is the JSON body accepted by POST /api/v1/capture
+type captureRequest struct {
+	Content string `json:"content"`
+	File    string `json:"file,omitempty"`
+}
+
+// captureResponse is returned after a successful capture
+type captureResponse struct {
+	File      string `json:"file"`
+	CommitSHA string `json:"commit_sha,omitempty"`
+	CommitURL string `json:"commit_url,omitempty"`
+}
+
+// handleAPICapture authenticates the bearer API key and commits the content for its owner
+func (b *Bot) handleAPICapture(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if b.db == nil {
+		writeAPIError(w, http.StatusServiceUnavailable, "database not configured")
+		return
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" || !strings.HasPrefix(token, apiKeyPrefix) {
+		writeAPIError(w, http.StatusUnauthorized, "missing or malformed API key")
+		return
+	}
+
+	key, err := b.db.GetAPIKeyByHash(hashAPIKey(token))
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, "failed to verify API key")
+		return
+	}
+	if key == nil {
+		writeAPIError(w, http.StatusUnauthorized, "invalid API key")
+		return
+	}
+	if !b.chatAllowed(key.ChatID) {
+		writeAPIError(w, http.StatusForbidden, "this bot doesn't serve your chat")
+		return
+	}
+	if !b.apiKeyLimiter(key.ID).Allow() {
+		w.Header().Set("Retry-After", "6")
+		writeAPIError(w, http.StatusTooManyRequests, "too many captures, slow down")
+		return
+	}
+
+	var req captureRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, apiCaptureMaxBody)).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	if err := b.db.TouchAPIKey(key.ID); err != nil {
+		logger.Warn("Failed to record API key usage", map[string]interface{}{
+			"chat_id": key.ChatID,
+			"error":   err.Error(),
+		})
+	}
+
+	resp, status, err := b.captureEntry(key.ChatID, req.File, req.Content)
+	if err != nil {
+		writeAPIError(w, status, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// apiKeyLimiter returns the rate limiter of the API key with id
+func (b *Bot) apiKeyLimiter(id int64) *rate.Limiter {
+	limiter, _ := b.apiKeyLimiters.LoadOrStore(id, rate.NewLimiter(apiCaptureRate, apiCaptureBurst))
+	return limiter.(*rate.Limiter)
+}
+
+// captureEntry formats content like a Telegram note and commits it to file in the user's repository
+func (b *Bot) captureEntry(chatID int64, file, content string) (*captureResponse, int, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("content is empty")
+	}
+
+	if file == "" {
+		file = apiCaptureDefaultFile
+	}
+	filename, err := validateRepoPath(file)
+	if err != nil || filename == "" {
+		return nil, http.StatusBadRequest, fmt.Errorf("invalid file path")
+	}
+	if path.Ext(filename) == "" {
+		filename += ".md"
+	}
+	if strings.EqualFold(filename, "issue.md") {
+		return nil, http.StatusBadRequest, fmt.Errorf("issues can only be created from Telegram")
+	}
+
+	if filename == "todo.md" && strings.Contains(content, "\n") {
+		return nil, http.StatusBadRequest, fmt.Errorf("TODOs cannot contain line breaks")
+	}
+
+	// Degraded repositories and paused operations are refused here
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return nil, http.StatusPreconditionFailed, err
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	if err := provider.EnsureRepositoryWithPremium(premiumLevel); err != nil {
+		return nil, http.StatusBadGateway, fmt.Errorf("repository not available: %v", err)
+	}
+
+	title := b.generateTitleFromContent(content)
+	var formattedContent string
+	statusMessageID := 0
+	if filename == "todo.md" {
+		// TODOs are identified by a Telegram message like the ones sent in the chat, a notice of
+		// the capture stands in for it
+		statusMessageID = b.sendResponseAndGetMessageID(chatID, "⏳ Adding a TODO from the API...")
+		if statusMessageID == 0 {
+			return nil, http.StatusBadGateway, fmt.Errorf("failed to notify the chat of the TODO")
+		}
+		formattedContent = b.formatTodoContent(content, statusMessageID, chatID)
+	} else {
+		formattedContent = b.formatMessageContentWithTitleAndTags(content, filename, 0, chatID, title, "")
+	}
+
+	commitMsg := fmt.Sprintf("Add %s to %s via CLI", b.commitTitle(chatID, title), filename)
+	result, err := provider.CommitFileWithResult(filename, formattedContent, commitMsg, b.getCommitterInfo(chatID), premiumLevel)
+	if err != nil {
+		b.recordRepoFailure(chatID, err)
+		logger.Error("Failed to commit captured entry", map[string]interface{}{
+			"chat_id":  chatID,
+			"filename": filename,
+			"error":    err.Error(),
+		})
+		if statusMessageID != 0 {
+			b.editMessage(chatID, statusMessageID, saveFailureText("Failed to add the TODO from the API", err))
+		}
+		if errors.Is(err, errTenantDiskQuota) {
+			return nil, http.StatusInsufficientStorage, err
+		}
+		return nil, http.StatusBadGateway, fmt.Errorf("failed to commit: %v", err)
+	}
+	if statusMessageID != 0 {
+		b.editMessage(chatID, statusMessageID, "✅ TODO added from the API: "+content)
+	}
+
+	if err := b.db.IncrementCommitCount(chatID); err != nil {
+		logger.Error("Failed to increment commit count", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	}
+	b.recordCommitStats(chatID, provider, result)
+
+	logger.Info("Captured entry via API", map[string]interface{}{
+		"chat_id":  chatID,
+		"filename": filename,
+	})
+
+	resp := &captureResponse{File: filename}
+	if result != nil {
+		resp.CommitSHA = result.SHA
+		resp.CommitURL = result.URL
+	}
+	return resp, http.StatusCreated, nil
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
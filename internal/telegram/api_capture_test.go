@@ -0,0 +1,30 @@
+package telegram
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestCaptureEntryRefusesIssueFile(t *testing.T) {
+	b := &Bot{}
+	for _, file := range []string{"issue.md", "Issue.md", "ISSUE"} {
+		if _, status, err := b.captureEntry(1, file, "note"); err == nil || status != http.StatusBadRequest {
+			t.Errorf("captureEntry(%q) = %d, %v, want a bad request", file, status, err)
+		}
+	}
+}
+
+func TestAPIKeyLimiter(t *testing.T) {
+	b := &Bot{}
+	for i := 0; i < apiCaptureBurst; i++ {
+		if !b.apiKeyLimiter(1).Allow() {
+			t.Fatalf("Capture %d refused within the burst", i+1)
+		}
+	}
+	if b.apiKeyLimiter(1).Allow() {
+		t.Error("Capture beyond the burst allowed")
+	}
+	if !b.apiKeyLimiter(2).Allow() {
+		t.Error("Another key shares the exhausted limit")
+	}
+}
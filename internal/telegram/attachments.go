@@ -0,0 +1,345 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Attachments: a PDF, TXT or ZIP document sent to the bot is committed under assets/ or uploaded
+// to the assets release CDN, as the user chooses, and the note saved next links to it. Every tier
+// caps the size of one file, and attached files count against a usage limit like images do.
+
+const (
+	attachmentFolder       = "assets"
+	attachmentExpiry       = 30 * time.Minute
+	attachmentNameMaxRunes = 40
+)
+
+// attachmentMimeTypes maps the MIME types of supported documents to their extension, for files
+// sent without one in their name
+var attachmentMimeTypes = map[string]string{
+	"application/pdf":              ".pdf",
+	"text/plain":                   ".txt",
+	"application/zip":              ".zip",
+	"application/x-zip-compressed": ".zip",
+}
+
+// pendingAttachment is a document waiting for the user to choose where it goes, kept by chat and
+// prompt message
+type pendingAttachment struct {
+	FileID    string
+	FileName  string
+	FileSize  int64
+	Ext       string // Extension of the supported type, the name may lack it
+	Caption   string // Markdown of the document's caption
+	MessageID int    // The document message, the note refers to it
+}
+
+func attachmentKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("attachment_%d_%d", chatID, messageID)
+}
+
+// attachmentExtension returns the extension of a supported document, empty if it isn't supported
+func attachmentExtension(document *tgbotapi.Document) string {
+	ext := strings.ToLower(filepath.Ext(document.FileName))
+	for _, supported := range attachmentMimeTypes {
+		if ext == supported {
+			return ext
+		}
+	}
+	return attachmentMimeTypes[document.MimeType]
+}
+
+// attachmentFilename returns the name a document is stored under: a timestamp, so names don't
+// collide, followed by the readable part of the original name
+func attachmentFilename(original, ext string, now time.Time) string {
+	base := strings.TrimSuffix(filepath.Base(original), filepath.Ext(original))
+
+	var sb strings.Builder
+	runes := 0
+	for _, r := range strings.ToLower(base) {
+		if runes == attachmentNameMaxRunes {
+			break
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_':
+			sb.WriteRune(r)
+		case r == ' ' || r == '.':
+			sb.WriteRune('-')
+		default:
+			continue
+		}
+		runes++
+	}
+
+	name := strings.Trim(sb.String(), "-_")
+	if name == "" {
+		name = "file"
+	}
+	return fmt.Sprintf("%s_%s%s", now.Format("20060102_150405"), name, ext)
+}
+
+// formatAttachmentEntry returns the note linking to a stored document, followed by its caption
+func formatAttachmentEntry(filename, url, caption string) string {
+	entry := fmt.Sprintf("📎 [%s](%s)", filename, url)
+	if caption = strings.TrimSpace(caption); caption != "" {
+		entry += "\n\n" + caption
+	}
+	return entry
+}
+
+// attachmentKeyboard offers where to store a document
+func attachmentKeyboard() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📁 Commit to assets/", "attach_repo"),
+			tgbotapi.NewInlineKeyboardButtonData("☁️ Upload to CDN", "attach_cdn"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("❌ CANCEL", "attach_cancel"),
+		),
+	)
+}
+
+// handleDocumentMessage checks a document sent to the bot and asks where to store it
+func (b *Bot) handleDocumentMessage(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	document := message.Document
+
+	ext := attachmentExtension(document)
+	if ext == "" {
+		b.sendResponse(chatID, "❌ Only PDF, TXT and ZIP files can be saved. Send images as photos.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if _, err := b.getUserGitHubProvider(chatID); err != nil {
+		errorMsg := "❌ " + err.Error()
+		if b.db != nil {
+			errorMsg += ". " + consts.GitHubSetupPrompt
+		}
+		b.sendResponse(chatID, errorMsg)
+		return nil
+	}
+
+	// Files beyond the tier's size aren't downloaded at all
+	premiumLevel := b.getPremiumLevel(chatID)
+	if maxSize := database.GetFileSizeLimit(premiumLevel); int64(document.FileSize) > maxSize {
+		logger.Info("Document blocked due to file size limit", map[string]interface{}{
+			"chat_id":       chatID,
+			"file_size":     document.FileSize,
+			"size_limit":    maxSize,
+			"premium_level": premiumLevel,
+		})
+
+		var upgradeHint string
+		if premiumLevel < 3 && database.GetFileSizeLimit(premiumLevel+1) > maxSize {
+			upgradeHint = fmt.Sprintf("\n\n💡 Upgrade with /coffee to attach files up to <b>%s</b>.", formatFileSize(database.GetFileSizeLimit(premiumLevel+1)))
+		}
+		tierNames := []string{consts.TierFree, consts.TierCoffee, consts.TierCake, consts.TierSponsor}
+		b.sendResponse(chatID, fmt.Sprintf(FileTooLargeTemplate, html.EscapeString(document.FileName), formatFileSize(int64(document.FileSize)), tierNames[premiumLevel], formatFileSize(maxSize), upgradeHint))
+		return nil
+	}
+
+	attachment := &pendingAttachment{
+		FileID:    document.FileID,
+		FileName:  document.FileName,
+		FileSize:  int64(document.FileSize),
+		Ext:       ext,
+		Caption:   b.telegramToMarkdown(message.Caption, message.CaptionEntities),
+		MessageID: message.MessageID,
+	}
+	if attachment.FileName == "" {
+		attachment.FileName = "file" + ext
+	}
+
+	prompt := tgbotapi.NewMessage(chatID, fmt.Sprintf("📎 %s (%s)\n\nWhere should the file go?", attachment.FileName, formatFileSize(attachment.FileSize)))
+	prompt.ReplyMarkup = attachmentKeyboard()
+	sent, err := b.rateLimitedSend(chatID, prompt)
+	if err != nil {
+		return fmt.Errorf("failed to send attachment options: %w", err)
+	}
+	b.cache.SetWithExpiry(attachmentKey(chatID, sent.MessageID), attachment, attachmentExpiry)
+	return nil
+}
+
+// handleAttachmentCallback handles attach_repo, attach_cdn and attach_cancel from the prompt
+func (b *Bot) handleAttachmentCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	cacheKey := attachmentKey(chatID, messageID)
+	cached, ok := b.cache.Get(cacheKey)
+	if !ok {
+		b.editMessage(chatID, messageID, "⏰ This file choice has expired. Send the file again to save it.")
+		return nil
+	}
+	attachment := cached.(*pendingAttachment)
+	b.cache.Delete(cacheKey)
+
+	switch callback.Data {
+	case "attach_repo":
+		return b.saveAttachment(callback, attachment, false)
+	case "attach_cdn":
+		return b.saveAttachment(callback, attachment, true)
+	default:
+		b.editMessage(chatID, messageID, "❌ Cancelled")
+		return nil
+	}
+}
+
+// saveAttachment stores a document in the repository or on the CDN, then offers the file selection
+// buttons for the note linking to it
+func (b *Bot) saveAttachment(callback *tgbotapi.CallbackQuery, attachment *pendingAttachment, toCDN bool) error {
+	chatID := callback.Message.Chat.ID
+	statusMessageID := callback.Message.MessageID
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.editMessage(chatID, statusMessageID, "❌ "+err.Error())
+		return nil
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	b.updateProgressMessage(chatID, statusMessageID, 10, "📊 Checking repository capacity...")
+
+	if err := userGitHubProvider.EnsureRepositoryWithPremium(premiumLevel); err != nil {
+		logger.Error("Failed to ensure repository for attachment", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+		editMsg := tgbotapi.NewEditMessageText(chatID, statusMessageID, b.formatRepositorySetupError(err, "save files"))
+		editMsg.ParseMode = "html"
+		if _, sendErr := b.rateLimitedSend(chatID, editMsg); sendErr != nil {
+			b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Repository setup failed: %v", err))
+		}
+		return nil
+	}
+
+	isNearCapacity, percentage, err := userGitHubProvider.IsRepositoryNearCapacityWithPremium(premiumLevel)
+	if err != nil {
+		logger.Warn("Failed to check repository capacity before attachment", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	} else if isNearCapacity {
+		errorMsg := fmt.Sprintf(RepoAlmostFullTemplate, percentage)
+		lapsedNotice, renewMarkup := b.lapsedPremiumNotice(chatID)
+		errorMsg += lapsedNotice
+		editMsg := tgbotapi.NewEditMessageText(chatID, statusMessageID, errorMsg)
+		editMsg.ParseMode = "html"
+		editMsg.ReplyMarkup = renewMarkup
+		if _, sendErr := b.rateLimitedSend(chatID, editMsg); sendErr != nil {
+			b.editMessage(chatID, statusMessageID, RepoCapacityLimitSimple)
+		}
+		return nil
+	}
+
+	if b.db != nil {
+		files, err := b.usageLimits().Files(chatID, premiumLevel)
+		if err != nil {
+			logger.Warn("Failed to check file limit before attachment", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		} else if !files.Allowed {
+			tierNames := []string{consts.TierFree, consts.TierCoffee, consts.TierCake, consts.TierSponsor}
+			editMsg := tgbotapi.NewEditMessageText(chatID, statusMessageID, fmt.Sprintf(FileLimitReachedTemplate, files.Current, files.Max, tierNames[premiumLevel]))
+			editMsg.ParseMode = "html"
+			if _, sendErr := b.rateLimitedSend(chatID, editMsg); sendErr != nil {
+				b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ File limit reached (%d/%d). Use /coffee to upgrade.", files.Current, files.Max))
+			}
+			return nil
+		}
+	}
+
+	b.updateProgressMessage(chatID, statusMessageID, 40, "⬇️ Downloading file...")
+	file, err := b.downloadFile(chatID, attachment.FileID, attachment.FileName)
+	if err != nil {
+		logger.Error("Failed to download attachment", map[string]interface{}{
+			"error":   err.Error(),
+			"file_id": attachment.FileID,
+			"chat_id": chatID,
+		})
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Failed to download file: %v", err))
+		return nil
+	}
+
+	filename := attachmentFilename(attachment.FileName, attachment.Ext, time.Now())
+
+	var url string
+	if toCDN {
+		b.updateProgressMessage(chatID, statusMessageID, 70, "☁️ Uploading file to GitHub CDN...")
+		url, err = userGitHubProvider.UploadImageToCDN(filename, file.Data)
+	} else {
+		path := attachmentFolder + "/" + filename
+		b.updateProgressMessage(chatID, statusMessageID, 70, "📝 Committing file to assets/...")
+		if err = userGitHubProvider.CommitBinaryFile(path, file.Data, fmt.Sprintf("Add %s via Telegram", path)); err == nil {
+			url, err = userGitHubProvider.GetGitHubFileURLWithBranch(path)
+		}
+	}
+	if err != nil {
+		logger.Error("Failed to store attachment", map[string]interface{}{
+			"error":    err.Error(),
+			"filename": filename,
+			"size":     len(file.Data),
+			"cdn":      toCDN,
+			"chat_id":  chatID,
+		})
+		b.recordRepoFailure(chatID, err)
+		if strings.Contains(err.Error(), "GitHub authorization failed") {
+			b.editMessage(chatID, statusMessageID, "❌ "+err.Error())
+			return nil
+		}
+		b.checkRepoMoved(chatID, err)
+		b.editMessage(chatID, statusMessageID, saveFailureText("Failed to save file", err))
+		return nil
+	}
+
+	if b.db != nil {
+		if err := b.db.IncrementFileCount(chatID); err != nil {
+			logger.Error("Failed to increment file count", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		}
+		if err := b.db.IncrementUsageFileCount(chatID); err != nil {
+			logger.Error("Failed to increment usage file count", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		}
+	}
+
+	logger.Info("Attachment stored, showing file selection buttons", map[string]interface{}{
+		"chat_id":  chatID,
+		"filename": filename,
+		"url":      url,
+		"cdn":      toCDN,
+	})
+
+	// The note linking to the file goes through the usual file selection
+	content := formatAttachmentEntry(attachment.FileName, url, attachment.Caption)
+	messageKey := fmt.Sprintf("%d_%d", chatID, attachment.MessageID)
+	b.pendingMessages.Set(messageKey, pendingEntryData(content, attachment.MessageID, false))
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, statusMessageID, fmt.Sprintf("📎 %s saved. %s", attachment.FileName, fileSelectionPrompt(false)))
+	keyboard := b.fileSelectionKeyboard(chatID, messageKey, false, strings.Contains(content, "\n"))
+	editMsg.ReplyMarkup = &keyboard
+	if _, err := b.rateLimitedSend(chatID, editMsg); err != nil {
+		return fmt.Errorf("failed to show file selection: %w", err)
+	}
+	return nil
+}
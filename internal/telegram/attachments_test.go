@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestAttachmentExtension(t *testing.T) {
+	tests := []struct {
+		document tgbotapi.Document
+		want     string
+	}{
+		{tgbotapi.Document{FileName: "Report.PDF"}, ".pdf"},
+		{tgbotapi.Document{FileName: "notes.txt", MimeType: "text/plain"}, ".txt"},
+		{tgbotapi.Document{FileName: "backup", MimeType: "application/zip"}, ".zip"},
+		{tgbotapi.Document{FileName: "photo.jpg", MimeType: "image/jpeg"}, ""},
+		{tgbotapi.Document{FileName: "script.sh"}, ""},
+	}
+	for _, tt := range tests {
+		if got := attachmentExtension(&tt.document); got != tt.want {
+			t.Errorf("attachmentExtension(%q) = %q, want %q", tt.document.FileName, got, tt.want)
+		}
+	}
+}
+
+func TestAttachmentFilename(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 30, 5, 0, time.UTC)
+	tests := []struct {
+		original string
+		ext      string
+		want     string
+	}{
+		{"Q3 Report.v2.pdf", ".pdf", "20261015_093005_q3-report-v2.pdf"},
+		{"backup", ".zip", "20261015_093005_backup.zip"},
+		{"../../etc/passwd.txt", ".txt", "20261015_093005_passwd.txt"},
+		{"★★★.pdf", ".pdf", "20261015_093005_file.pdf"},
+	}
+	for _, tt := range tests {
+		if got := attachmentFilename(tt.original, tt.ext, now); got != tt.want {
+			t.Errorf("attachmentFilename(%q) = %q, want %q", tt.original, got, tt.want)
+		}
+	}
+}
+
+func TestFormatAttachmentEntry(t *testing.T) {
+	if got := formatAttachmentEntry("report.pdf", "https://example.com/r.pdf", ""); got != "📎 [report.pdf](https://example.com/r.pdf)" {
+		t.Errorf("formatAttachmentEntry() = %q", got)
+	}
+	if got := formatAttachmentEntry("report.pdf", "https://example.com/r.pdf", " Q3 numbers\n"); got != "📎 [report.pdf](https://example.com/r.pdf)\n\nQ3 numbers" {
+		t.Errorf("formatAttachmentEntry() with caption = %q", got)
+	}
+}
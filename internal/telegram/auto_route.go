@@ -0,0 +1,146 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Auto-route: with /autoroute on, the LLM picks the custom file a text note belongs in and the
+// note is committed there right away. Below autoRouteMinConfidence, and for private entries, the
+// file selection keyboard is shown as usual.
+
+const autoRouteMinConfidence = 70
+
+// autoRouteMessage commits a text note to the custom file the LLM picks for it, false if the
+// keyboard should be shown instead
+func (b *Bot) autoRouteMessage(message *tgbotapi.Message) bool {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		return false
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil || !user.AutoRoute {
+		return false
+	}
+	customFiles := user.GetCustomFiles()
+	if len(customFiles) == 0 {
+		return false
+	}
+
+	content := b.telegramToMarkdown(message.Text, message.Entities)
+	if _, ok := parsePrivatePrefix(content); ok && b.hasPrivateRepo(chatID) {
+		return false
+	}
+
+	userLLMClient, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(chatID, content)
+	if userLLMClient == nil {
+		return false
+	}
+
+	route, usage, err := userLLMClient.RouteNote(content, customFiles)
+	if usage != nil {
+		if isUsingDefaultLLM {
+			err = b.db.IncrementTokenUsageAll(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens))
+		} else {
+			err = b.db.IncrementTokenUsageInsights(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens))
+		}
+		if err != nil {
+			logger.Warn("Failed to record token usage for auto-route", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+	if route.File == "" || route.Confidence < autoRouteMinConfidence {
+		logger.Debug("Note not auto-routed, showing file selection", map[string]interface{}{
+			"chat_id":    chatID,
+			"file":       route.File,
+			"confidence": route.Confidence,
+			"error":      fmt.Sprint(err),
+		})
+		return false
+	}
+
+	logger.Info("Auto-routing note to custom file", map[string]interface{}{
+		"chat_id":    chatID,
+		"file":       route.File,
+		"confidence": route.Confidence,
+	})
+
+	statusMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🧭 Saving to %s (%d%% sure)...", route.File, route.Confidence))
+	sent, err := b.rateLimitedSend(chatID, statusMsg)
+	if err != nil {
+		logger.Error("Failed to send auto-route status message", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return false
+	}
+
+	// Reuse the custom file save flow, which edits the status message as it progresses
+	callback := &tgbotapi.CallbackQuery{
+		From:    message.From,
+		Message: &sent,
+	}
+	if err := b.saveMessageToCustomFile(callback, route.File, content, message.MessageID, "", false, false); err != nil {
+		logger.Error("Failed to save auto-routed note", map[string]interface{}{
+			"chat_id": chatID,
+			"file":    route.File,
+			"error":   err.Error(),
+		})
+	}
+	return true
+}
+
+// handleAutoRouteCommand shows or switches auto-routing of notes to custom files
+func (b *Bot) handleAutoRouteCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/autoroute")))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Auto-routing requires a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch args {
+	case "":
+		if user.AutoRoute {
+			b.sendResponse(chatID, fmt.Sprintf("🧭 Auto-routing is on. Notes go straight to the custom file the LLM picks when it's at least %d%% sure, otherwise you choose the file as usual.\n\nUse <code>/autoroute off</code> to stop.", autoRouteMinConfidence))
+			return nil
+		}
+		b.sendResponse(chatID, "🧭 Auto-routing is off.\n\nUse <code>/autoroute on</code> to let the LLM commit notes to the best matching custom file.")
+		return nil
+	case "on", "off":
+		enabled := args == "on"
+		if err := b.db.UpdateUserAutoRoute(chatID, enabled); err != nil {
+			b.sendResponse(chatID, "❌ Failed to update auto-routing.")
+			return nil
+		}
+		if !enabled {
+			b.sendResponse(chatID, "🧭 Auto-routing disabled.")
+			return nil
+		}
+		response := "🧭 Auto-routing enabled. New notes are matched against your custom files, which uses a few LLM tokens per note."
+		if len(user.GetCustomFiles()) == 0 {
+			response += "\n\n⚠️ You have no custom files yet, add some with /customfile."
+		}
+		if !user.LLMSwitch {
+			response += "\n\n⚠️ LLM processing is off, turn it on with /llm for notes to be routed."
+		}
+		b.sendResponse(chatID, response)
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/autoroute</code>, <code>/autoroute on</code> or <code>/autoroute off</code>")
+		return nil
+	}
+}
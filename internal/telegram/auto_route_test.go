@@ -0,0 +1,10 @@
+package telegram
+
+import "testing"
+
+func TestAutoRouteMessageWithoutDatabase(t *testing.T) {
+	b := &Bot{}
+	if b.autoRouteMessage(commandMessage(123456789, "Pancakes: 2 eggs")) {
+		t.Error("autoRouteMessage() routed a note without a database")
+	}
+}
@@ -6,23 +6,26 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
-	"io"
-	"net/http"
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/experiments/monitoring/ratelimit"
 	"github.com/msg2git/msg2git/internal/cache"
 	"github.com/msg2git/msg2git/internal/config"
 	"github.com/msg2git/msg2git/internal/consts"
 	"github.com/msg2git/msg2git/internal/database"
 	"github.com/msg2git/msg2git/internal/file"
 	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/limits"
 	"github.com/msg2git/msg2git/internal/llm"
 	"github.com/msg2git/msg2git/internal/logger"
 	"github.com/msg2git/msg2git/internal/stripe"
+	"github.com/msg2git/msg2git/internal/webhook"
+	"github.com/redis/go-redis/v9"
 	"golang.org/x/time/rate"
 )
 
@@ -33,10 +36,12 @@ type Bot struct {
 	githubFactory   github.ProviderFactory // New: Factory for creating GitHub providers
 	llmClient       *llm.Client            // Default LLM client (from .env)
 	stripeManager   *stripe.Manager        // Stripe payment manager
-	pendingMessages map[string]string      // messageID -> content
-	config          *config.Config         // Store config for runtime updates
+	webhooks        *webhook.Dispatcher    // Outgoing webhook delivery
+	pendingMessages *cache.Cache           // State of prompts waiting for a reply or callback, key -> string, see pendingMessage
+	config          *config.Config         // Config the bot started with, read through cfg
 	db              *database.DB           // Database for multi-user support
-	cache           *cache.Cache           // Cache for storing frequently accessed data
+	cache           *cache.Cache           // Cache for storing frequently accessed data, shared through Redis if configured
+	redis           *redis.Client          // Redis shared by all instances, nil unless REDIS_URL is set
 
 	// Rate limiting
 	globalLimiter  *rate.Limiter           // Global rate limiter (30 msg/sec)
@@ -44,16 +49,80 @@ type Bot struct {
 	userLimitersMu sync.RWMutex            // Protects userLimiters map
 	cleanupStarted bool                    // Track if cleanup goroutine is started
 
-	// Callback deduplication
-	processedCallbacks map[string]time.Time // callback_id -> timestamp
-	callbacksMu        sync.RWMutex         // Protects processedCallbacks map
+	// Rate limits counted across instances, nil unless REDIS_URL is set (see shared_state.go)
+	sharedLimiter *ratelimit.RedisRateLimiter
 
 	// Worker pool for concurrent processing
 	workerPool *WorkerPool // Handles concurrent message and callback processing
+
+	// Bounded, throttled and resumable Telegram file downloads
+	downloads *downloadManager
+
+	// Config file hot-reload
+	stopConfigWatcher func()
+	// Chat access rules loaded last, used while the database fails, see getChatAccessRules
+	lastChatAccessRules atomic.Pointer[map[int64]string]
+
+	// Config swapped in by reloads and runtime updates, see updateConfig
+	liveConfig atomic.Pointer[config.Config]
+	configMu   sync.Mutex
+
+	// Background purge of expired trash
+	stopTrashPurger func()
+
+	// Background feed digests
+	stopFeedScheduler func()
+
+	// Background premium expiry notices
+	stopTierTransitions func()
+
+	// Background failure digests
+	stopFailureDigest func()
+
+	// Linked notes of notes being committed, chat and file -> []string, see updateBacklinks
+	pendingNoteLinks sync.Map
+
+	// Tags of notes being committed, chat and file -> string, see recordNoteTags
+	pendingNoteTags sync.Map
+
+	// Daily pinned summaries
+	stopDailyPins func()
+	// Weekly changelog issues
+	stopWeeklyChangelogs func()
+	// Weekly or monthly insight reports
+	stopInsightReports func()
+	// Weekly history compression proposals
+	stopHistoryCompressions func()
+
+	// Forum topics of received messages, chat and message -> forumTopicInfo, see takeForumTopic
+	messageTopics sync.Map
+
+	// Last use of each chat's repository, chat -> time.Time, see runWarmFetches
+	hotRepos sync.Map
+	// Consecutive setup failures of each chat's repository, chat -> *repoHealthState
+	repoHealthStates sync.Map
+	// Rate limits of the capture API, API key ID -> *rate.Limiter
+	apiKeyLimiters sync.Map
+	// Periodic fetches of active repositories
+	stopWarmFetches func()
+
+	// Scheduled database backups
+	stopBackups func()
+	// Delivery of messages deferred during quiet hours
+	stopQuietHours func()
+	// Evening reminders of running streaks
+	stopStreakReminders func()
+
+	// When the bot was created, for the uptime reported by /status
+	startedAt time.Time
 }
 
 func NewBot(cfg *config.Config) (*Bot, error) {
-	api, err := tgbotapi.NewBotAPI(cfg.TelegramBotToken)
+	apiEndpoint := tgbotapi.APIEndpoint
+	if cfg.TelegramAPIEndpoint != "" {
+		apiEndpoint = cfg.TelegramAPIEndpoint
+	}
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(cfg.TelegramBotToken, apiEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
 	}
@@ -76,30 +145,73 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 
 	// No default GitHub manager or LLM client - everything is database-controlled
 
-	// Initialize Stripe manager (optional)
+	// Point GitHub requests at a custom API host (optional, e.g. GitHub Enterprise)
+	if cfg.GitHubAPIURL != "" || cfg.GitHubUploadsURL != "" {
+		github.SetAPIBaseURLs(cfg.GitHubAPIURL, cfg.GitHubUploadsURL)
+		logger.Info("Using custom GitHub API endpoints", map[string]interface{}{
+			"api_url":     github.APIBaseURL(),
+			"uploads_url": github.UploadsBaseURL(),
+		})
+	}
+
+	// Keep message content off the host if the operator requires it (implemented in retention.go)
+	applyRetentionPolicy(cfg)
+
+	// Initialize workspace object storage (optional) so clones survive redeploys
+	if cfg.HasWorkspaceStoreConfig() && !cfg.ZeroRetention() {
+		store, err := github.NewS3WorkspaceStore(cfg.WorkspaceS3Endpoint, cfg.WorkspaceS3Bucket, cfg.WorkspaceS3Region, cfg.WorkspaceS3AccessKey, cfg.WorkspaceS3SecretKey)
+		if err != nil {
+			logger.Warn("Failed to initialize workspace store", map[string]interface{}{
+				"error": err.Error(),
+			})
+		} else {
+			github.SetWorkspaceStore(store)
+			logger.Info("Workspace object storage enabled", map[string]interface{}{
+				"endpoint": cfg.WorkspaceS3Endpoint,
+				"bucket":   cfg.WorkspaceS3Bucket,
+			})
+		}
+	}
+
+	// Initialize Stripe manager (optional, never on deployments with payments disabled)
 	var stripeManager *stripe.Manager
-	stripeManager = stripe.NewManager(cfg.BaseURL)
-	if err := stripeManager.Initialize(); err != nil {
-		logger.Warn("Failed to initialize Stripe manager", map[string]interface{}{
-			"error": err.Error(),
+	if cfg.PaymentsDisabled {
+		logger.Info("Payments disabled, premium levels come from config", map[string]interface{}{
+			"default_level": cfg.PremiumDefaultLevel,
+			"overrides":     len(cfg.PremiumOverrides),
 		})
-		logger.InfoMsg("Continuing without Stripe payment support...")
-		stripeManager = nil
 	} else {
-		logger.InfoMsg("Stripe payment manager initialized successfully")
+		stripeManager = stripe.NewManager(cfg.BaseURL)
+		if err := stripeManager.Initialize(); err != nil {
+			logger.Warn("Failed to initialize Stripe manager", map[string]interface{}{
+				"error": err.Error(),
+			})
+			logger.InfoMsg("Continuing without Stripe payment support...")
+			stripeManager = nil
+		} else {
+			logger.InfoMsg("Stripe payment manager initialized successfully")
+		}
 	}
 
-	return &Bot{
+	// Partial downloads are content too, so they stay in memory under zero retention
+	downloadConfig := DefaultDownloadConfig()
+	downloadConfig.Resumable = !cfg.ZeroRetention()
+
+	// Share the cache and pending prompts with other instances through Redis (optional, implemented in shared_state.go)
+	botCache, redisClient := newBotCache(cfg)
+
+	b := &Bot{
 		api:             api,
 		fileManager:     file.NewManager(),
 		githubManager:   nil,
 		githubFactory:   github.NewProviderFactory(), // Initialize GitHub provider factory
 		llmClient:       nil,
 		stripeManager:   stripeManager,
-		pendingMessages: make(map[string]string),
+		webhooks:        webhook.NewDispatcher(),
+		pendingMessages: newPendingMessages(redisClient),
 		config:          cfg,
 		db:              db,
-		cache:           cache.NewWithConfig(1000, 30*time.Minute, 5*time.Minute), // Large cache with 30-minute expiry
+		cache:           botCache, // Large cache with 30-minute expiry
 
 		// Initialize rate limiters
 		globalLimiter:  rate.NewLimiter(rate.Limit(5000), 5000), // 5000 messages per second with burst of 5000
@@ -107,13 +219,25 @@ func NewBot(cfg *config.Config) (*Bot, error) {
 		userLimitersMu: sync.RWMutex{},
 		cleanupStarted: false,
 
-		// Initialize callback deduplication
-		processedCallbacks: make(map[string]time.Time),
-		callbacksMu:        sync.RWMutex{},
-
 		// Worker pool will be initialized in Start() method
 		workerPool: nil,
-	}, nil
+
+		downloads: newDownloadManager(downloadConfig),
+
+		startedAt: time.Now(),
+	}
+
+	// Share rate limits with the other instances (implemented in shared_state.go)
+	if redisClient != nil {
+		b.useSharedState(redisClient)
+	}
+
+	// Simulate GitHub writes in sandbox mode (implemented in sandbox.go)
+	if cfg.Sandbox {
+		b.enableSandbox()
+	}
+
+	return b, nil
 }
 
 func (b *Bot) Start() error {
@@ -123,6 +247,9 @@ func (b *Bot) Start() error {
 		"user_rate_limit":   "30 msg/user/sec",
 	})
 
+	// Time handlers, git operations and queries against the watchdog thresholds
+	b.startWatchdog()
+
 	// Initialize and start worker pool
 	b.workerPool = NewWorkerPool(b, DefaultWorkerPoolConfig())
 	if err := b.workerPool.Start(); err != nil {
@@ -132,75 +259,189 @@ func (b *Bot) Start() error {
 	// Start webhook server for Stripe payments
 	b.StartWebhookServer()
 
-	u := tgbotapi.NewUpdate(0)
-	u.Timeout = 60
-	u.AllowedUpdates = []string{"message", "edited_message", "callback_query"}
+	// Hot-reload non-secret settings when the config file changes
+	b.startConfigWatcher()
+
+	// Permanently delete trashed files past their retention period
+	b.startTrashPurger()
+
+	// Commit daily digests of subscribed feeds
+	b.startFeedScheduler()
+
+	// Notify users whose premium tier is ending or has ended
+	if !b.cfg().PaymentsDisabled {
+		b.startTierTransitions()
+	}
+
+	// Report failed background operations once a day
+	b.startFailureDigest()
+
+	// Pin a summary of yesterday for users who opted in
+	b.startDailyPins()
+
+	// Open last week's changelog issue for users who opted in
+	b.startWeeklyChangelogs()
+
+	// Send insight reports to users who opted in (implemented in insight_report.go)
+	b.startInsightReports()
+
+	// Propose compressed histories to users who consented (implemented in history_compression.go)
+	b.startHistoryCompressions()
+
+	// Keep the clones of active chats fetched
+	b.startWarmFetches()
 
-	updates := b.api.GetUpdatesChan(u)
+	// Back up the database to object storage (implemented in db_backup.go)
+	b.startBackups()
+
+	// Deliver messages held back during users' quiet hours (implemented in quiet_hours.go)
+	b.startQuietHours()
+
+	// Remind users before their capture streak ends (implemented in streaks.go)
+	b.startStreakReminders()
+
+	// Long polling, or a webhook when WEBHOOK_URL is set (implemented in webhook_mode.go)
+	updates, err := b.updatesChan()
+	if err != nil {
+		return err
+	}
 
 	for update := range updates {
-		logger.Debug("Received update", map[string]interface{}{
-			"update_id":    update.UpdateID,
-			"has_message":  update.Message != nil,
-			"has_callback": update.CallbackQuery != nil,
-		})
+		b.dispatchUpdate(update)
+	}
 
-		if update.CallbackQuery != nil {
-			// Submit callback to worker pool for concurrent processing
-			if err := b.workerPool.SubmitCallback(update.CallbackQuery); err != nil {
-				logger.Error("Failed to submit callback to worker pool", map[string]interface{}{
-					"error":       err.Error(),
-					"chat_id":     update.CallbackQuery.Message.Chat.ID,
-					"callback_id": update.CallbackQuery.ID,
-				})
-				// Fallback to direct processing if worker pool is full
-				// if err := b.handleCallbackQuery(update.CallbackQuery); err != nil {
-				// 	logger.Error("Error handling callback query", map[string]interface{}{
-				// 		"error":   err.Error(),
-				// 		"chat_id": update.CallbackQuery.Message.Chat.ID,
-				// 	})
-				// 	b.sendErrorResponse(update.CallbackQuery.Message.Chat.ID, err)
-				// }
-			}
-			continue
-		}
+	return nil
+}
 
-		if update.Message == nil {
-			logger.Debug("Update has no message, skipping", nil)
-			continue
-		}
+// dispatchUpdate submits an update to the worker pool
+func (b *Bot) dispatchUpdate(update tgbotapi.Update) {
+	logger.Debug("Received update", map[string]interface{}{
+		"update_id":    update.UpdateID,
+		"has_message":  update.Message != nil,
+		"has_callback": update.CallbackQuery != nil,
+	})
 
-		logger.Debug("Received message from user", map[string]interface{}{
-			"username": update.Message.From.UserName,
-			"chat_id":  update.Message.Chat.ID,
-		})
+	// Chats the bot doesn't serve never reach the worker pool (implemented in chat_access.go)
+	if b.refuseUpdate(update) {
+		return
+	}
 
-		// Submit message to worker pool for concurrent processing
-		if err := b.workerPool.SubmitMessage(update.Message); err != nil {
-			logger.Error("Failed to submit message to worker pool", map[string]interface{}{
-				"error":    err.Error(),
-				"username": update.Message.From.UserName,
-				"chat_id":  update.Message.Chat.ID,
+	if update.CallbackQuery != nil {
+		// Submit callback to worker pool for concurrent processing
+		if err := b.workerPool.SubmitCallback(update.CallbackQuery); err != nil {
+			logger.Error("Failed to submit callback to worker pool", map[string]interface{}{
+				"error":       err.Error(),
+				"chat_id":     update.CallbackQuery.Message.Chat.ID,
+				"callback_id": update.CallbackQuery.ID,
 			})
 			// Fallback to direct processing if worker pool is full
-			// if err := b.handleMessage(update.Message); err != nil {
-			// 	logger.Error("Error handling message", map[string]interface{}{
-			// 		"error":    err.Error(),
-			// 		"username": update.Message.From.UserName,
-			// 		"chat_id":  update.Message.Chat.ID,
+			// if err := b.handleCallbackQuery(update.CallbackQuery); err != nil {
+			// 	logger.Error("Error handling callback query", map[string]interface{}{
+			// 		"error":   err.Error(),
+			// 		"chat_id": update.CallbackQuery.Message.Chat.ID,
 			// 	})
-			// 	b.sendErrorResponse(update.Message.Chat.ID, err)
+			// 	b.sendErrorResponse(update.CallbackQuery.Message.Chat.ID, err)
 			// }
 		}
+		return
 	}
 
-	return nil
+	// Posts of channels the bot administers (implemented in channels.go)
+	if update.ChannelPost != nil {
+		if err := b.workerPool.SubmitMessage(update.ChannelPost); err != nil {
+			logger.Error("Failed to submit channel post to worker pool", map[string]interface{}{
+				"error":      err.Error(),
+				"channel_id": update.ChannelPost.Chat.ID,
+			})
+		}
+		return
+	}
+
+	if update.Message == nil {
+		logger.Debug("Update has no message, skipping", nil)
+		return
+	}
+
+	logger.Debug("Received message from user", map[string]interface{}{
+		"username": update.Message.From.UserName,
+		"chat_id":  update.Message.Chat.ID,
+	})
+
+	// Submit message to worker pool for concurrent processing
+	if err := b.workerPool.SubmitMessage(update.Message); err != nil {
+		logger.Error("Failed to submit message to worker pool", map[string]interface{}{
+			"error":    err.Error(),
+			"username": update.Message.From.UserName,
+			"chat_id":  update.Message.Chat.ID,
+		})
+		// Fallback to direct processing if worker pool is full
+		// if err := b.handleMessage(update.Message); err != nil {
+		// 	logger.Error("Error handling message", map[string]interface{}{
+		// 		"error":    err.Error(),
+		// 		"username": update.Message.From.UserName,
+		// 		"chat_id":  update.Message.Chat.ID,
+		// 	})
+		// 	b.sendErrorResponse(update.Message.Chat.ID, err)
+		// }
+	}
 }
 
 // Stop gracefully shuts down the bot and its worker pool
 func (b *Bot) Stop() error {
 	logger.InfoMsg("Stopping bot...")
 
+	if b.stopConfigWatcher != nil {
+		b.stopConfigWatcher()
+	}
+
+	if b.stopTrashPurger != nil {
+		b.stopTrashPurger()
+	}
+
+	if b.stopFeedScheduler != nil {
+		b.stopFeedScheduler()
+	}
+
+	if b.stopTierTransitions != nil {
+		b.stopTierTransitions()
+	}
+
+	if b.stopFailureDigest != nil {
+		b.stopFailureDigest()
+	}
+
+	if b.stopDailyPins != nil {
+		b.stopDailyPins()
+	}
+
+	if b.stopWeeklyChangelogs != nil {
+		b.stopWeeklyChangelogs()
+	}
+
+	if b.stopInsightReports != nil {
+		b.stopInsightReports()
+	}
+
+	if b.stopHistoryCompressions != nil {
+		b.stopHistoryCompressions()
+	}
+
+	if b.stopWarmFetches != nil {
+		b.stopWarmFetches()
+	}
+
+	if b.stopBackups != nil {
+		b.stopBackups()
+	}
+
+	if b.stopQuietHours != nil {
+		b.stopQuietHours()
+	}
+
+	if b.stopStreakReminders != nil {
+		b.stopStreakReminders()
+	}
+
 	if b.workerPool != nil {
 		if err := b.workerPool.Stop(); err != nil {
 			logger.Error("Error stopping worker pool", map[string]interface{}{
@@ -210,6 +451,10 @@ func (b *Bot) Stop() error {
 		}
 	}
 
+	if b.redis != nil {
+		b.redis.Close()
+	}
+
 	logger.InfoMsg("Bot stopped successfully")
 	return nil
 }
@@ -226,16 +471,47 @@ func (b *Bot) GetWorkerPoolStats() map[string]interface{} {
 }
 
 func (b *Bot) handleMessage(message *tgbotapi.Message) error {
+	// Channel posts are committed for the channel's owner (implemented in channels.go)
+	if message.Chat != nil && message.Chat.IsChannel() {
+		return b.handleChannelPost(message)
+	}
+
+	// Text posted in a forum topic goes to the topic's file (implemented in forum_topics.go)
+	if topic, ok := b.takeForumTopic(message); ok {
+		if topic.topicRepliesToStart() {
+			// Telegram links topic messages to the topic's first message, that's not a reply to handle
+			message.ReplyToMessage = nil
+		}
+		_, _, isDirectPath := parseDirectPathPrefix(message.Text)
+		if message.ReplyToMessage == nil && message.Text != "" && !strings.HasPrefix(message.Text, "/") && !isDirectPath {
+			return b.commitToTopicFile(message, topic)
+		}
+	}
+
 	// Handle reply commands first (including photo replies to issue comments)
 	if message.ReplyToMessage != nil {
 		return b.handleReplyMessage(message)
 	}
 
+	// Messages sent while composing go to the draft (implemented in compose.go)
+	if b.captureComposePart(message) {
+		return nil
+	}
+
 	// Handle photo messages (only if not a reply)
 	if len(message.Photo) > 0 {
 		return b.handlePhotoMessage(message)
 	}
 
+	// Chat exports sent for /import (implemented in import.go)
+	if message.Document != nil {
+		if folder, ok := b.takeImportRequest(message); ok {
+			return b.handleImportUpload(message, folder)
+		}
+		// Other documents are attached to a note (implemented in attachments.go)
+		return b.handleDocumentMessage(message)
+	}
+
 	if message.Text == "" {
 		return fmt.Errorf("empty message received")
 	}
@@ -245,6 +521,16 @@ func (b *Bot) handleMessage(message *tgbotapi.Message) error {
 		return b.handleCommand(message)
 	}
 
+	// Direct path capture: ">> path/to/file.md: content"
+	if targetPath, content, ok := parseDirectPathPrefix(message.Text); ok {
+		return b.commitToDirectPath(message, targetPath, content)
+	}
+
+	// Notes the LLM can confidently route go straight to a custom file (implemented in auto_route.go)
+	if b.autoRouteMessage(message) {
+		return nil
+	}
+
 	// Regular message - show file selection buttons
 	return b.showFileSelectionButtons(message)
 }
@@ -257,7 +543,7 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 	})
 
 	// Ensure user exists in database if database is configured
-	_, err := b.ensureUser(message)
+	user, err := b.ensureUser(message)
 	if err != nil {
 		return fmt.Errorf("failed to get user: %w", err)
 	}
@@ -334,9 +620,12 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 		})
 
 		errorMsg := fmt.Sprintf(RepoPhotoUploadLimitTemplate, percentage)
+		lapsedNotice, renewMarkup := b.lapsedPremiumNotice(message.Chat.ID)
+		errorMsg += lapsedNotice
 
 		editMsg := tgbotapi.NewEditMessageText(message.Chat.ID, statusMessageID, errorMsg)
 		editMsg.ParseMode = "html"
+		editMsg.ReplyMarkup = renewMarkup
 		if _, sendErr := b.rateLimitedSend(message.Chat.ID, editMsg); sendErr != nil {
 			logger.Error("Failed to edit message with capacity error", map[string]interface{}{
 				"error": sendErr.Error(),
@@ -349,17 +638,17 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 
 	// Check image upload limits
 	if b.db != nil {
-		canUpload, currentCount, imageLimit, err := b.db.CheckUsageImageLimit(message.Chat.ID, premiumLevel)
+		images, err := b.usageLimits().Images(message.Chat.ID, premiumLevel)
 		if err != nil {
 			logger.Warn("Failed to check image limit before photo upload", map[string]interface{}{
 				"error":   err.Error(),
 				"chat_id": message.Chat.ID,
 			})
 			// Continue anyway if image limit check fails - don't block upload
-		} else if !canUpload {
+		} else if !images.Allowed {
 			logger.Info("Photo upload blocked due to image limit", map[string]interface{}{
-				"current_count": currentCount,
-				"image_limit":   imageLimit,
+				"current_count": images.Current,
+				"image_limit":   images.Max,
 				"premium_level": premiumLevel,
 				"chat_id":       message.Chat.ID,
 			})
@@ -368,15 +657,14 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 			currentTier := tierNames[premiumLevel]
 
 			var upgradeHint string
-			if premiumLevel < 3 {
-				nextLimit := database.GetImageLimit(premiumLevel + 1)
-				nextTier := tierNames[premiumLevel+1]
-				upgradeHint = fmt.Sprintf("\n\n💡 <b>Upgrade to %s tier</b> to get <b>%d images</b> (%dx more)!", nextTier, nextLimit, database.GetImageMultiplier(premiumLevel+1))
+			if next := images.Reset; next.NextLevel > 0 {
+				nextTier := tierNames[next.NextLevel]
+				upgradeHint = fmt.Sprintf("\n\n💡 <b>Upgrade to %s tier</b> to get <b>%d images</b> (%dx more)!", nextTier, next.NextMax, database.GetImageMultiplier(next.NextLevel))
 			} else {
 				upgradeHint = "\n\n🎉 You're already on the highest tier with maximum image limits!"
 			}
 
-			errorMsg := fmt.Sprintf(ImageLimitReachedDetailedTemplate, currentCount, imageLimit, currentTier, upgradeHint)
+			errorMsg := fmt.Sprintf(ImageLimitReachedDetailedTemplate, images.Current, images.Max, currentTier, upgradeHint)
 
 			editMsg := tgbotapi.NewEditMessageText(message.Chat.ID, statusMessageID, errorMsg)
 			editMsg.ParseMode = "html"
@@ -385,14 +673,14 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 					"error": sendErr.Error(),
 				})
 				// Fallback to simple message
-				b.editMessage(message.Chat.ID, statusMessageID, fmt.Sprintf(ImageLimitReachedTemplate, currentCount, imageLimit))
+				b.editMessage(message.Chat.ID, statusMessageID, fmt.Sprintf(ImageLimitReachedTemplate, images.Current, images.Max))
 			}
 			return nil
 		}
 
 		logger.Info("Image limit check passed", map[string]interface{}{
-			"current_count": currentCount,
-			"image_limit":   imageLimit,
+			"current_count": images.Current,
+			"image_limit":   images.Max,
 			"premium_level": premiumLevel,
 			"chat_id":       message.Chat.ID,
 		})
@@ -409,7 +697,7 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 
 	// Download the photo with progress
 	b.updateProgressMessage(message.Chat.ID, statusMessageID, 40, "⬇️ Downloading photo...")
-	photoData, filename, err := b.downloadPhoto(photo.FileID)
+	photoData, filename, err := b.downloadPhoto(message.Chat.ID, photo.FileID)
 	if err != nil {
 		logger.Error("Failed to download photo", map[string]interface{}{
 			"error":   err.Error(),
@@ -420,16 +708,20 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 		return fmt.Errorf("failed to download photo: %w", err)
 	}
 
-	// Multimodal analysis will be performed later after user selects location
-
 	// Generate a unique filename with timestamp, microseconds, and random component
 	photoFilename := b.generateUniquePhotoFilename(filename)
 
-	// Upload photo to GitHub CDN with progress
-	b.updateProgressMessage(message.Chat.ID, statusMessageID, 70, "📝 Uploading photo to GitHub CDN...")
+	// Analyze the photo with its caption while it uploads (implemented in photo_analysis.go)
+	var analyzed <-chan struct{}
+	if b.shouldPerformMultimodalAnalysis(message.Chat.ID, user) {
+		analyzed = b.startPhotoAnalysis(message, photoData)
+		b.updateProgressMessage(message.Chat.ID, statusMessageID, 70, "📝 Uploading photo to GitHub CDN · 🔍 Analyzing photo...")
+	} else {
+		b.updateProgressMessage(message.Chat.ID, statusMessageID, 70, "📝 Uploading photo to GitHub CDN...")
+	}
 
-	// Upload to GitHub CDN and get the URL
-	photoURL, err := userGitHubProvider.UploadImageToCDN(photoFilename, photoData)
+	// Upload to GitHub CDN and get the URL, a photo uploaded before reuses its URL (implemented in photo_hashes.go)
+	photoURL, reused, err := b.uploadPhoto(message.Chat.ID, userGitHubProvider, photoFilename, photoData)
 	if err != nil {
 		logger.Error("Failed to upload photo to GitHub CDN", map[string]interface{}{
 			"error":    err.Error(),
@@ -447,7 +739,7 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 	}
 
 	// Increment image count after successful photo upload
-	if b.db != nil {
+	if b.db != nil && !reused {
 		if err := b.db.IncrementImageCount(message.Chat.ID); err != nil {
 			logger.Error("Failed to increment image count", map[string]interface{}{
 				"error":   err.Error(),
@@ -463,9 +755,14 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 		}
 	}
 
+	if analyzed != nil {
+		b.awaitPhotoAnalysis(message.Chat.ID, statusMessageID, analyzed)
+	}
+
 	logger.Info("Photo uploaded to CDN successfully, showing file selection buttons", map[string]interface{}{
 		"filename":    photoFilename,
 		"url":         photoURL,
+		"reused":      reused,
 		"chat_id":     message.Chat.ID,
 		"has_caption": message.Caption != "",
 	})
@@ -488,49 +785,13 @@ func (b *Bot) handlePhotoMessage(message *tgbotapi.Message) error {
 	return nil
 }
 
-func (b *Bot) downloadPhoto(fileID string) ([]byte, string, error) {
-	// Get file info from Telegram
-	fileConfig := tgbotapi.FileConfig{FileID: fileID}
-	file, err := b.api.GetFile(fileConfig)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to get file info: %w", err)
-	}
-
-	// Download the file
-	fileURL := file.Link(b.api.Token)
-	logger.Debug("Downloading photo from Telegram", map[string]interface{}{
-		"file_id":   fileID,
-		"file_url":  fileURL,
-		"file_size": file.FileSize,
-	})
-
-	resp, err := http.Get(fileURL)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to download file: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("failed to download file: HTTP %d", resp.StatusCode)
-	}
-
-	data, err := io.ReadAll(resp.Body)
+// downloadPhoto downloads a photo within the chat's download limits (implemented in downloads.go)
+func (b *Bot) downloadPhoto(chatID int64, fileID string) ([]byte, string, error) {
+	file, err := b.downloadFile(chatID, fileID, "photo.jpg")
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read file data: %w", err)
+		return nil, "", err
 	}
-
-	// Extract filename from the file path or use a default
-	filename := filepath.Base(file.FilePath)
-	if filename == "." || filename == "" {
-		filename = "photo.jpg"
-	}
-
-	logger.Debug("Photo downloaded successfully", map[string]interface{}{
-		"filename": filename,
-		"size":     len(data),
-	})
-
-	return data, filename, nil
+	return file.Data, file.Filename, nil
 }
 
 func (b *Bot) handleReplyMessage(message *tgbotapi.Message) error {
@@ -545,28 +806,35 @@ func (b *Bot) handleReplyMessage(message *tgbotapi.Message) error {
 
 	// Check for custom file addition pending state first
 	stateKey := fmt.Sprintf("add_custom_%d", message.Chat.ID)
-	if stateData, exists := b.pendingMessages[stateKey]; exists {
+	if stateData, exists := b.pendingMessage(stateKey); exists {
 		// Remove the pending state and handle as custom file addition
-		delete(b.pendingMessages, stateKey)
+		b.pendingMessages.Delete(stateKey)
 		return b.handleCustomFilePathReply(message, stateData)
 	}
 
 	// Check for issue comment pending state
 	commentStateKey := fmt.Sprintf("comment_%d_%d", message.Chat.ID, message.ReplyToMessage.MessageID)
-	if commentData, exists := b.pendingMessages[commentStateKey]; exists {
+	if commentData, exists := b.pendingMessage(commentStateKey); exists {
 		// Remove the pending state and handle as issue comment
-		delete(b.pendingMessages, commentStateKey)
+		b.pendingMessages.Delete(commentStateKey)
 		return b.handleIssueCommentReply(message, commentData)
 	}
 
 	// Check for LLM token setup pending state
 	llmTokenStateKey := fmt.Sprintf("llm_token_%d_%d", message.Chat.ID, message.ReplyToMessage.MessageID)
-	if llmTokenData, exists := b.pendingMessages[llmTokenStateKey]; exists {
+	if llmTokenData, exists := b.pendingMessage(llmTokenStateKey); exists {
 		// Remove the pending state and handle as LLM token setup
-		delete(b.pendingMessages, llmTokenStateKey)
+		b.pendingMessages.Delete(llmTokenStateKey)
 		return b.handleLLMTokenSetupReply(message, llmTokenData)
 	}
 
+	// Check for custom file template pending state (implemented in file_templates.go)
+	templateStateKey := fmt.Sprintf("file_template_%d_%d", message.Chat.ID, message.ReplyToMessage.MessageID)
+	if filename, exists := b.pendingMessage(templateStateKey); exists {
+		b.pendingMessages.Delete(templateStateKey)
+		return b.handleFileTemplateReply(message, filename)
+	}
+
 	// Check if this is a reply to one of our command prompts
 	if message.ReplyToMessage != nil && message.ReplyToMessage.Text != "" {
 		replyText := message.ReplyToMessage.Text
@@ -595,6 +863,11 @@ func (b *Bot) sendResponse(chatID int64, text string) {
 	logger.Debug("Sending response to chat", map[string]interface{}{
 		"chat_id": chatID,
 	})
+	// Split replies beyond Telegram's limit instead of failing (implemented in message_chunks.go)
+	if telegramLength(text) > telegramMessageLimit {
+		b.sendLongReply(chatID, longReply{Text: text})
+		return
+	}
 	msg := tgbotapi.NewMessage(chatID, text)
 	msg.ParseMode = "html"
 	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
@@ -710,6 +983,13 @@ func (b *Bot) getUserGitHubProvider(chatID int64) (github.GitHubProvider, error)
 	if user == nil || !user.HasGitHubConfig() {
 		return nil, fmt.Errorf("user not configured or missing GitHub settings")
 	}
+	if err := b.operationsPaused(chatID); err != nil {
+		return nil, err // Implemented in access_anomalies.go
+	}
+	if err := b.repoDegraded(chatID); err != nil {
+		return nil, err
+	}
+	b.markRepoHot(chatID)
 
 	// Get premium level for the user
 	premiumLevel := b.getPremiumLevel(chatID)
@@ -718,24 +998,32 @@ func (b *Bot) getUserGitHubProvider(chatID int64) (github.GitHubProvider, error)
 	userConfig := github.NewConfigAdapter(&config.Config{
 		GitHubToken:    user.GitHubToken,
 		GitHubRepo:     user.GitHubRepo,
-		GitHubUsername: b.config.GitHubUsername, // Use default from .env
-		CommitAuthor:   b.config.CommitAuthor,   // Use default from .env
+		GitHubUsername: b.cfg().GitHubUsername, // Use default from .env
+		CommitAuthor:   b.cfg().CommitAuthor,   // Use default from .env
 	})
 
 	// Create provider config
 	providerConfig := &github.ProviderConfig{
-		Config:       userConfig,
-		PremiumLevel: premiumLevel,
-		UserID:       fmt.Sprintf("user_%d", chatID),
-	}
-
-	// Determine provider type (for now, always use clone, but this can be made configurable)
+		Config:          userConfig,
+		PremiumLevel:    premiumLevel,
+		UserID:          fmt.Sprintf("user_%d", chatID),
+		ChatID:          chatID,
+		CloneSubmodules: b.cfg().CloneSubmodules,
+		ShallowClone:    b.cfg().ShallowClone(premiumLevel),
+		SparseCheckout:  b.cfg().SparseCheckout(premiumLevel),
+		Committer:       b.providerCommitter(user), // Implemented in commit_identity.go
+		APIBaseURL:      user.GitHubAPIURL,
+		Branch:          user.CommitBranch,
+		WriteQuota:      func() error { return b.tenantDiskQuotaError(chatID) },
+	}
+
+	// Determine provider type (feature flags may move users between providers)
 	providerType := b.getProviderType(chatID, premiumLevel)
 
 	// Check if we have a cached provider for this user
 	cacheKey := fmt.Sprintf("github_provider_%d", chatID)
 	if cachedProvider, exists := b.cache.Get(cacheKey); exists {
-		if provider, ok := cachedProvider.(github.GitHubProvider); ok {
+		if provider, ok := cachedProvider.(github.GitHubProvider); ok && provider.GetProviderType() == providerType {
 			logger.Debug("Using cached GitHub provider", map[string]interface{}{
 				"chat_id":       chatID,
 				"provider_type": provider.GetProviderType(),
@@ -831,52 +1119,23 @@ func (b *Bot) getUserGitHubManager(chatID int64) (*github.Manager, error) {
 	return nil, fmt.Errorf("provider type does not support Manager extraction")
 }
 
-// getProviderType determines which GitHub provider to use for a user
+// getProviderType determines which GitHub provider to use for a user.
+// The API provider is the default; the clone provider can be rolled out via the clone_provider feature flag.
 func (b *Bot) getProviderType(chatID int64, premiumLevel int) github.ProviderType {
-	// For now, always return clone provider for stability
-	// In the future, this can be made configurable via:
-	// - Database user preferences
-	// - A/B testing logic
-	// - Feature flags
-	// - Performance-based selection
-
-	// Example future logic for A/B testing:
-	// if b.isAPITestUser(chatID) {
-	//     return github.ProviderTypeAPI
-	// }
-
-	// Example logic for performance-based selection:
-	// userProfile := b.getUserProfile(chatID)
-	// recommendedType := github.GetRecommendedProvider(
-	//     1, // userCount
-	//     userProfile.AvgFilesPerCommit,
-	//     userProfile.AvgCommitsPerDay,
-	// )
-	// return recommendedType
-
+	// Clones keep repository content on disk
+	if b.cfg().ZeroRetention() {
+		return github.ProviderTypeAPI
+	}
+	// Only the API provider speaks Gitea
+	if github.IsGiteaAPIURL(b.userGitHubAPIURL(chatID)) {
+		return github.ProviderTypeAPI
+	}
+	if b.isFeatureEnabledForTier(consts.FeatureCloneProvider, chatID, premiumLevel) {
+		return github.ProviderTypeClone
+	}
 	return github.ProviderTypeAPI
 }
 
-// isAPITestUser determines if a user should be part of API provider testing
-// This can be used for gradual rollout of the new API provider
-func (b *Bot) isAPITestUser(chatID int64) bool {
-	// Example implementation for A/B testing
-	// Could use user ID, percentage rollout, whitelist, etc.
-
-	// For example, enable for users ending in certain digits
-	// return chatID%100 < 10 // 10% of users
-
-	// Or use a whitelist of test users
-	// testUsers := []int64{123456789, 987654321}
-	// for _, testUser := range testUsers {
-	//     if chatID == testUser {
-	//         return true
-	//     }
-	// }
-
-	return false // Disabled for now
-}
-
 // parseLLMToken parses the LLM token from either "provider:token:model" format or just "token"
 func (b *Bot) parseLLMToken(llmToken string) (provider, token, model string) {
 	if strings.Contains(llmToken, ":") {
@@ -960,10 +1219,11 @@ func (b *Bot) getUserLLMClient(chatID int64) *llm.Client {
 
 		// Create user-specific config with parsed values
 		userConfig := &config.Config{
-			LLMProvider: provider,
-			LLMEndpoint: endpoint,
-			LLMToken:    token,
-			LLMModel:    model,
+			LLMProvider:   provider,
+			LLMEndpoint:   endpoint,
+			LLMToken:      token,
+			LLMModel:      model,
+			LLMTaskModels: userTaskModels(user),
 		}
 
 		return llm.NewClient(userConfig)
@@ -973,7 +1233,7 @@ func (b *Bot) getUserLLMClient(chatID int64) *llm.Client {
 	// We'll use a more accurate estimation in the actual processing
 	estimatedTokens := int64(100) // Default estimation for processing
 
-	canUseDefault, err := b.db.CanUseDefaultLLM(chatID, estimatedTokens)
+	tokens, err := b.usageLimits().Tokens(chatID, b.getPremiumLevel(chatID), estimatedTokens)
 	if err != nil {
 		logger.Error("Failed to check if user can use default LLM", map[string]interface{}{
 			"error":   err.Error(),
@@ -982,19 +1242,19 @@ func (b *Bot) getUserLLMClient(chatID int64) *llm.Client {
 		return nil
 	}
 
-	if !canUseDefault {
-		// User has exceeded their token limit for default LLM
+	if !tokens.Allowed || !b.tenantAllowsTokens(chatID, estimatedTokens) {
+		// User (or their tenant) has exceeded the token limit for default LLM
 		return nil
 	}
 
 	// User can use default LLM, check if bot has system-wide LLM config
-	if !b.config.HasLLMConfig() {
+	if !b.cfg().HasLLMConfig() {
 		logger.Debug("No system-wide LLM config available", map[string]interface{}{
 			"chat_id":      chatID,
-			"llm_provider": b.config.LLMProvider,
-			"llm_endpoint": b.config.LLMEndpoint,
-			"llm_token":    b.config.LLMToken != "",
-			"llm_model":    b.config.LLMModel,
+			"llm_provider": b.cfg().LLMProvider,
+			"llm_endpoint": b.cfg().LLMEndpoint,
+			"llm_token":    b.cfg().LLMToken != "",
+			"llm_model":    b.cfg().LLMModel,
 		})
 		return nil // No system-wide LLM config available
 	}
@@ -1002,10 +1262,10 @@ func (b *Bot) getUserLLMClient(chatID int64) *llm.Client {
 	// Return client with bot's default LLM config
 	logger.Info("Using default LLM config for user", map[string]interface{}{
 		"chat_id":      chatID,
-		"llm_provider": b.config.LLMProvider,
-		"llm_model":    b.config.LLMModel,
+		"llm_provider": b.cfg().LLMProvider,
+		"llm_model":    b.cfg().LLMModel,
 	})
-	return llm.NewClient(b.config)
+	return llm.NewClient(b.cfg())
 }
 
 // getUserLLMClientWithMessage gets LLM client with accurate token estimation for the message
@@ -1058,10 +1318,11 @@ func (b *Bot) getUserLLMClientWithMessage(chatID int64, message string) *llm.Cli
 
 		// Create user-specific config with parsed values
 		userConfig := &config.Config{
-			LLMProvider: provider,
-			LLMEndpoint: endpoint,
-			LLMToken:    token,
-			LLMModel:    model,
+			LLMProvider:   provider,
+			LLMEndpoint:   endpoint,
+			LLMToken:      token,
+			LLMModel:      model,
+			LLMTaskModels: userTaskModels(user),
 		}
 
 		return llm.NewClient(userConfig)
@@ -1071,7 +1332,7 @@ func (b *Bot) getUserLLMClientWithMessage(chatID int64, message string) *llm.Cli
 	// Estimate token usage based on actual message content
 	estimatedTokens := b.estimateTokenUsage(message)
 
-	canUseDefault, err := b.db.CanUseDefaultLLM(chatID, estimatedTokens)
+	tokens, err := b.usageLimits().Tokens(chatID, b.getPremiumLevel(chatID), estimatedTokens)
 	if err != nil {
 		logger.Error("Failed to check if user can use default LLM", map[string]interface{}{
 			"error":   err.Error(),
@@ -1080,8 +1341,8 @@ func (b *Bot) getUserLLMClientWithMessage(chatID int64, message string) *llm.Cli
 		return nil
 	}
 
-	if !canUseDefault {
-		// User has exceeded their token limit for default LLM
+	if !tokens.Allowed || !b.tenantAllowsTokens(chatID, estimatedTokens) {
+		// User (or their tenant) has exceeded the token limit for default LLM
 		logger.Info("User cannot use default LLM - token limit exceeded", map[string]interface{}{
 			"chat_id":          chatID,
 			"estimated_tokens": estimatedTokens,
@@ -1090,13 +1351,13 @@ func (b *Bot) getUserLLMClientWithMessage(chatID int64, message string) *llm.Cli
 	}
 
 	// User can use default LLM, check if bot has system-wide LLM config
-	if !b.config.HasLLMConfig() {
+	if !b.cfg().HasLLMConfig() {
 		logger.Debug("No system-wide LLM config available", map[string]interface{}{
 			"chat_id":      chatID,
-			"llm_provider": b.config.LLMProvider,
-			"llm_endpoint": b.config.LLMEndpoint,
-			"llm_token":    b.config.LLMToken != "",
-			"llm_model":    b.config.LLMModel,
+			"llm_provider": b.cfg().LLMProvider,
+			"llm_endpoint": b.cfg().LLMEndpoint,
+			"llm_token":    b.cfg().LLMToken != "",
+			"llm_model":    b.cfg().LLMModel,
 		})
 		return nil // No system-wide LLM config available
 	}
@@ -1104,10 +1365,10 @@ func (b *Bot) getUserLLMClientWithMessage(chatID int64, message string) *llm.Cli
 	// Return client with bot's default LLM config
 	logger.Info("Using default LLM config for user", map[string]interface{}{
 		"chat_id":      chatID,
-		"llm_provider": b.config.LLMProvider,
-		"llm_model":    b.config.LLMModel,
+		"llm_provider": b.cfg().LLMProvider,
+		"llm_model":    b.cfg().LLMModel,
 	})
-	return llm.NewClient(b.config)
+	return llm.NewClient(b.cfg())
 }
 
 // estimateTokenUsage estimates the number of tokens that will be used for processing a message
@@ -1121,6 +1382,30 @@ func (b *Bot) estimateTokenUsage(message string) int64 {
 	return messageTokens + systemPromptTokens + responseTokens
 }
 
+// usageLimits returns the limits service for issue, image and token counters
+func (b *Bot) usageLimits() *limits.Service {
+	return limits.New(b.db)
+}
+
+// remainingTokenBudget returns the default LLM tokens the user and their tenant have left
+func (b *Bot) remainingTokenBudget(chatID int64) int64 {
+	remaining := limits.Max(limits.Tokens, b.getPremiumLevel(chatID))
+	if tokens, err := b.usageLimits().Tokens(chatID, b.getPremiumLevel(chatID), 0); err == nil {
+		remaining = tokens.Remaining()
+	}
+
+	if tenant, usage := b.loadChatTenant(chatID); tenant != nil && tenant.TokenQuota > 0 {
+		if left := tenant.TokenQuota - usage.TokensUsed; left < remaining {
+			remaining = left
+		}
+	}
+
+	if remaining < 1 {
+		return 1 // A budget of 0 would mean unlimited
+	}
+	return remaining
+}
+
 // getUserLLMClientWithUsageTracking gets LLM client and returns whether it's using default LLM for proper token tracking
 func (b *Bot) getUserLLMClientWithUsageTracking(chatID int64, message string) (*llm.Client, bool) {
 	if b.db == nil {
@@ -1171,10 +1456,11 @@ func (b *Bot) getUserLLMClientWithUsageTracking(chatID int64, message string) (*
 
 		// Create user-specific config with parsed values
 		userConfig := &config.Config{
-			LLMProvider: provider,
-			LLMEndpoint: endpoint,
-			LLMToken:    token,
-			LLMModel:    model,
+			LLMProvider:   provider,
+			LLMEndpoint:   endpoint,
+			LLMToken:      token,
+			LLMModel:      model,
+			LLMTaskModels: userTaskModels(user),
 		}
 
 		return llm.NewClient(userConfig), false // false = not using default LLM
@@ -1184,7 +1470,7 @@ func (b *Bot) getUserLLMClientWithUsageTracking(chatID int64, message string) (*
 	// Estimate token usage based on actual message content
 	estimatedTokens := b.estimateTokenUsage(message)
 
-	canUseDefault, err := b.db.CanUseDefaultLLM(chatID, estimatedTokens)
+	tokens, err := b.usageLimits().Tokens(chatID, b.getPremiumLevel(chatID), estimatedTokens)
 	if err != nil {
 		logger.Error("Failed to check if user can use default LLM", map[string]interface{}{
 			"error":   err.Error(),
@@ -1193,8 +1479,8 @@ func (b *Bot) getUserLLMClientWithUsageTracking(chatID int64, message string) (*
 		return nil, false
 	}
 
-	if !canUseDefault {
-		// User has exceeded their token limit for default LLM
+	if !tokens.Allowed || !b.tenantAllowsTokens(chatID, estimatedTokens) {
+		// User (or their tenant) has exceeded the token limit for default LLM
 		logger.Info("User cannot use default LLM - token limit exceeded", map[string]interface{}{
 			"chat_id":          chatID,
 			"estimated_tokens": estimatedTokens,
@@ -1203,24 +1489,29 @@ func (b *Bot) getUserLLMClientWithUsageTracking(chatID int64, message string) (*
 	}
 
 	// User can use default LLM, check if bot has system-wide LLM config
-	if !b.config.HasLLMConfig() {
+	if !b.cfg().HasLLMConfig() {
 		logger.Debug("No system-wide LLM config available", map[string]interface{}{
 			"chat_id":      chatID,
-			"llm_provider": b.config.LLMProvider,
-			"llm_endpoint": b.config.LLMEndpoint,
-			"llm_token":    b.config.LLMToken != "",
-			"llm_model":    b.config.LLMModel,
+			"llm_provider": b.cfg().LLMProvider,
+			"llm_endpoint": b.cfg().LLMEndpoint,
+			"llm_token":    b.cfg().LLMToken != "",
+			"llm_model":    b.cfg().LLMModel,
 		})
 		return nil, false // No system-wide LLM config available
 	}
 
+	// Flagged content is committed without going through the operator's token
+	if b.moderationDeclines(chatID, message) {
+		return nil, false
+	}
+
 	// Return client with bot's default LLM config
 	logger.Info("Using default LLM config for user", map[string]interface{}{
 		"chat_id":      chatID,
-		"llm_provider": b.config.LLMProvider,
-		"llm_model":    b.config.LLMModel,
+		"llm_provider": b.cfg().LLMProvider,
+		"llm_model":    b.cfg().LLMModel,
 	})
-	return llm.NewClient(b.config), true // true = using default LLM
+	return llm.NewClient(b.cfg()), true // true = using default LLM
 }
 
 // generateUniquePhotoFilename generates a unique filename for photo uploads
@@ -1342,15 +1633,19 @@ func (b *Bot) rateLimitedSend(chatID int64, msg tgbotapi.Chattable) (tgbotapi.Me
 		return tgbotapi.Message{}, fmt.Errorf("user rate limiter error: %w", err)
 	}
 
+	// Wait for the limits shared with other instances
+	b.waitSharedSendLimits(chatID)
+
 	logger.Debug("Sending rate-limited message", map[string]interface{}{
 		"chat_id": chatID,
 	})
 
-	return b.api.Send(msg)
+	// Replies of chats in plain mode lose their formatting (implemented in plain_mode.go)
+	return b.api.Send(b.plainChattable(chatID, msg))
 }
 
 // rateLimitedRequest sends a request with rate limiting
-func (b *Bot) rateLimitedRequest(chatID int64, req tgbotapi.CallbackConfig) (*tgbotapi.APIResponse, error) {
+func (b *Bot) rateLimitedRequest(chatID int64, req tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
 	// Wait for global rate limiter
 	if err := b.globalLimiter.Wait(context.Background()); err != nil {
 		return nil, fmt.Errorf("global rate limiter error: %w", err)
@@ -1362,11 +1657,14 @@ func (b *Bot) rateLimitedRequest(chatID int64, req tgbotapi.CallbackConfig) (*tg
 		return nil, fmt.Errorf("user rate limiter error: %w", err)
 	}
 
+	// Wait for the limits shared with other instances
+	b.waitSharedSendLimits(chatID)
+
 	logger.Debug("Sending rate-limited request", map[string]interface{}{
 		"chat_id": chatID,
 	})
 
-	return b.api.Request(req)
+	return b.api.Request(b.plainChattable(chatID, req))
 }
 
 // showFileSelectionButtonsForPhoto shows file selection buttons for photos (with or without captions)
@@ -1392,7 +1690,7 @@ func (b *Bot) showFileSelectionButtonsForPhoto(message *tgbotapi.Message, photoU
 	// Encode image data as base64 for safe storage
 	imageDataBase64 := base64.StdEncoding.EncodeToString(photoData)
 	messageData := fmt.Sprintf("%s|||DELIM|||%d|||DELIM|||%s|||DELIM|||%s", markdownContent, message.MessageID, photoURL, imageDataBase64)
-	b.pendingMessages[messageKey] = messageData
+	b.pendingMessages.Set(messageKey, messageData)
 
 	// Get user's pinned files
 	var pinnedFiles []string
@@ -1492,7 +1790,7 @@ func (b *Bot) ensureUserFromCallback(callback *tgbotapi.CallbackQuery) (*databas
 func (b *Bot) getCommitterInfo(chatID int64) string {
 	if b.db == nil {
 		// No database, use env default
-		return b.config.CommitAuthor
+		return b.cfg().CommitAuthor
 	}
 
 	// Check if user has custom committer
@@ -1502,7 +1800,7 @@ func (b *Bot) getCommitterInfo(chatID int64) string {
 			"error":   err.Error(),
 			"chat_id": chatID,
 		})
-		return b.config.CommitAuthor
+		return b.cfg().CommitAuthor
 	}
 
 	if user != nil && user.Committer != "" {
@@ -1510,13 +1808,15 @@ func (b *Bot) getCommitterInfo(chatID int64) string {
 	}
 
 	// Default to env committer
-	return b.config.CommitAuthor
+	return b.cfg().CommitAuthor
 }
 
-// getPremiumLevel returns the premium level for a user (0 for free/expired users)
+// getPremiumLevel returns the premium level for a user (0 for free/expired users).
+// Levels granted by config (self-hosted deployments) apply even without a database.
 func (b *Bot) getPremiumLevel(chatID int64) int {
+	configuredLevel := b.cfg().ConfiguredPremiumLevel(chatID)
 	if b.db == nil {
-		return 0 // No database, free tier
+		return configuredLevel
 	}
 
 	premiumUser, err := b.db.GetPremiumUser(chatID)
@@ -1525,14 +1825,14 @@ func (b *Bot) getPremiumLevel(chatID int64) int {
 			"error":   err.Error(),
 			"chat_id": chatID,
 		})
-		return 0
+		return configuredLevel
 	}
 
-	if premiumUser != nil && premiumUser.IsPremiumUser() {
+	if premiumUser != nil && premiumUser.IsPremiumUser() && premiumUser.Level > configuredLevel {
 		return premiumUser.Level
 	}
 
-	return 0 // Free tier
+	return configuredLevel
 }
 
 // needsRepositoryClone checks if the repository needs to be cloned (doesn't exist locally)
@@ -1590,33 +1890,3 @@ Please:
 	}
 }
 
-// isDuplicateCallback checks if a callback has already been processed recently
-func (b *Bot) isDuplicateCallback(callbackID string) bool {
-	b.callbacksMu.RLock()
-	_, exists := b.processedCallbacks[callbackID]
-	b.callbacksMu.RUnlock()
-	return exists
-}
-
-// markCallbackProcessed marks a callback as processed and starts cleanup timer
-func (b *Bot) markCallbackProcessed(callbackID string) {
-	b.callbacksMu.Lock()
-	b.processedCallbacks[callbackID] = time.Now()
-	b.callbacksMu.Unlock()
-
-	// Clean up old callbacks after 30 seconds to prevent memory leak
-	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				logger.Error("Panic in callback cleanup", map[string]interface{}{
-					"error": r,
-				})
-			}
-		}()
-
-		time.Sleep(30 * time.Second)
-		b.callbacksMu.Lock()
-		delete(b.processedCallbacks, callbackID)
-		b.callbacksMu.Unlock()
-	}()
-}
@@ -126,7 +126,7 @@ func TestGetUserGitHubManager_NoGitHubManager(t *testing.T) {
 		config:          cfg,
 		githubManager:   nil, // No default GitHub manager
 		db:              nil, // No database
-		pendingMessages: make(map[string]string),
+		pendingMessages: newPendingMessages(nil),
 	}
 	
 	manager, err := bot.getUserGitHubManager(123456)
@@ -0,0 +1,415 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Bulk operations: /bulk plans a mass change to the notes of the repository (new LLM titles for
+// the newest notes of a file, a hashtag rewritten across a file, or the newest notes moved to
+// another file) and shows it as a dry run. Applying the plan commits every changed file in one
+// commit, unless the files changed since the preview.
+
+const (
+	bulkDefaultNotes   = 10
+	bulkMaxNotes       = 50
+	bulkPlanExpiry     = 15 * time.Minute
+	bulkPreviewChanges = 10 // Changes listed in the preview, the rest are counted
+)
+
+// bulkPlan is a previewed bulk operation waiting to be applied
+type bulkPlan struct {
+	ID       string
+	Summary  string            // Commit message
+	Changes  []string          // One line per change, for the preview
+	Original map[string]string // Content each file had when the plan was made
+	Files    map[string]string // New content of each changed file
+}
+
+func bulkPlanKey(chatID int64) string {
+	return fmt.Sprintf("bulk_plan_%d", chatID)
+}
+
+// parseBulkCount parses the optional number of notes, defaulting to bulkDefaultNotes
+func parseBulkCount(arg string) (int, error) {
+	if arg == "" {
+		return bulkDefaultNotes, nil
+	}
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > bulkMaxNotes {
+		return 0, fmt.Errorf("the number of notes must be between 1 and %d", bulkMaxNotes)
+	}
+	return n, nil
+}
+
+// normalizeHashtag adds the leading # to a tag and checks it is a single hashtag
+func normalizeHashtag(tag string) (string, error) {
+	tag = "#" + strings.TrimPrefix(tag, "#")
+	if len(tag) < 2 {
+		return "", fmt.Errorf("empty hashtag")
+	}
+	for _, r := range tag[1:] {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_' && r != '-' {
+			return "", fmt.Errorf("invalid hashtag: %s", tag)
+		}
+	}
+	return tag, nil
+}
+
+// planRetag rewrites a hashtag across a file
+func planRetag(filePath, content, oldTag, newTag string) (*bulkPlan, error) {
+	replaced, count := entry.ReplaceTag(content, oldTag, newTag)
+	if count == 0 {
+		return nil, fmt.Errorf("%s doesn't appear in %s", oldTag, filePath)
+	}
+	return &bulkPlan{
+		Summary:  fmt.Sprintf("Rename %s to %s in %s via Telegram", oldTag, newTag, filePath),
+		Changes:  []string{fmt.Sprintf("%s → %s: %d occurrences in %s", oldTag, newTag, count, filePath)},
+		Original: map[string]string{filePath: content},
+		Files:    map[string]string{filePath: replaced},
+	}, nil
+}
+
+// planMove moves the newest n notes of from to the top of to, keeping their order
+func planMove(from, fromContent, to, toContent string, n int) (*bulkPlan, error) {
+	source := entry.Parse(fromContent)
+	if len(source.Notes) == 0 {
+		return nil, fmt.Errorf("%s has no notes to move", from)
+	}
+	if n > len(source.Notes) {
+		n = len(source.Notes)
+	}
+
+	moved := source.Notes[:n]
+	plan := &bulkPlan{
+		Summary:  fmt.Sprintf("Move %d notes from %s to %s via Telegram", n, from, to),
+		Original: map[string]string{from: fromContent, to: toContent},
+	}
+	for _, note := range moved {
+		plan.Changes = append(plan.Changes, fmt.Sprintf("%s → %s: %s", from, to, entry.NoteTitle(note)))
+	}
+
+	target := entry.Parse(toContent)
+	target.Notes = append(append([]string(nil), moved...), target.Notes...)
+	source.Notes = source.Notes[n:]
+	plan.Files = map[string]string{from: source.String(), to: target.String()}
+	return plan, nil
+}
+
+// handleBulkCommand plans a bulk operation and previews it
+func (b *Bot) handleBulkCommand(message *tgbotapi.Message, arg string) error {
+	chatID := message.Chat.ID
+	args := strings.Fields(arg)
+	if len(args) == 0 {
+		return b.sendBulkMenu(chatID)
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	plan, err := b.planBulk(chatID, userGitHubProvider, args)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if plan == nil {
+		return b.sendBulkMenu(chatID)
+	}
+
+	plan.ID = strconv.FormatInt(time.Now().UnixNano(), 36)
+	b.cache.SetWithExpiry(bulkPlanKey(chatID), plan, bulkPlanExpiry)
+	return b.sendBulkPreview(chatID, plan)
+}
+
+// planBulk plans the operation given by the /bulk arguments, nil for unknown operations
+func (b *Bot) planBulk(chatID int64, provider github.GitHubProvider, args []string) (*bulkPlan, error) {
+	readFile := func(arg string) (string, string, error) {
+		filePath, err := validateRepoPath(arg)
+		if err != nil {
+			return "", "", err
+		}
+		content, err := provider.ReadFile(filePath)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to read %s: %w", filePath, err)
+		}
+		return filePath, content, nil
+	}
+
+	switch {
+	case args[0] == "retitle" && (len(args) == 2 || len(args) == 3):
+		count, err := parseBulkCount(strings.Join(args[2:], ""))
+		if err != nil {
+			return nil, err
+		}
+		filePath, content, err := readFile(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return b.planRetitle(chatID, filePath, content, count)
+
+	case args[0] == "retag" && len(args) == 4:
+		oldTag, err := normalizeHashtag(args[2])
+		if err != nil {
+			return nil, err
+		}
+		newTag, err := normalizeHashtag(args[3])
+		if err != nil {
+			return nil, err
+		}
+		filePath, content, err := readFile(args[1])
+		if err != nil {
+			return nil, err
+		}
+		return planRetag(filePath, content, oldTag, newTag)
+
+	case args[0] == "move" && (len(args) == 3 || len(args) == 4):
+		count, err := parseBulkCount(strings.Join(args[3:], ""))
+		if err != nil {
+			return nil, err
+		}
+		from, fromContent, err := readFile(args[1])
+		if err != nil {
+			return nil, err
+		}
+		to, toContent, err := readFile(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if from == to {
+			return nil, fmt.Errorf("the source and destination are the same file")
+		}
+		return planMove(from, fromContent, to, toContent, count)
+	}
+
+	return nil, nil
+}
+
+// planRetitle asks the LLM for new titles of the newest count notes of a file
+func (b *Bot) planRetitle(chatID int64, filePath, content string, count int) (*bulkPlan, error) {
+	file := entry.Parse(content)
+	if len(file.Notes) == 0 {
+		return nil, fmt.Errorf("%s has no notes to retitle", filePath)
+	}
+	if count > len(file.Notes) {
+		count = len(file.Notes)
+	}
+
+	var estimate strings.Builder
+	for _, note := range file.Notes[:count] {
+		estimate.WriteString(entry.NoteContent(note))
+	}
+	userLLMClient, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(chatID, estimate.String())
+	if userLLMClient == nil {
+		return nil, fmt.Errorf("retitling needs an LLM: set one with /llm, turn LLM processing on, or check your token quota")
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("🧠 Asking the LLM for new titles of %d notes...", count))
+
+	plan := &bulkPlan{Original: map[string]string{filePath: content}}
+	var promptTokens, completionTokens int64
+	failed := 0
+	for i, note := range file.Notes[:count] {
+		body := entry.NoteContent(note)
+		if strings.TrimSpace(body) == "" {
+			continue
+		}
+
+		llmResponse, usage, err := userLLMClient.ProcessMessage(body)
+		if usage != nil {
+			promptTokens += int64(usage.PromptTokens)
+			completionTokens += int64(usage.CompletionTokens)
+		}
+		if err != nil {
+			failed++
+			continue
+		}
+
+		oldTitle := entry.NoteTitle(note)
+		title, _ := b.parseTitleAndTags(llmResponse, body)
+		if title == "" || title == oldTitle {
+			continue
+		}
+		file.Notes[i] = entry.SetNoteTitle(note, title)
+		plan.Changes = append(plan.Changes, fmt.Sprintf("%s → %s", oldTitle, title))
+	}
+
+	if promptTokens > 0 || completionTokens > 0 {
+		var err error
+		if isUsingDefaultLLM {
+			err = b.db.IncrementTokenUsageAll(chatID, promptTokens, completionTokens)
+		} else {
+			err = b.db.IncrementTokenUsageInsights(chatID, promptTokens, completionTokens)
+		}
+		if err != nil {
+			logger.Warn("Failed to record token usage for bulk retitle", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	if len(plan.Changes) == 0 {
+		if failed > 0 {
+			return nil, fmt.Errorf("the LLM failed for %d notes and found no new titles", failed)
+		}
+		return nil, fmt.Errorf("the LLM kept every title of the newest %d notes", count)
+	}
+	plan.Summary = fmt.Sprintf("Retitle %d notes in %s via Telegram", len(plan.Changes), filePath)
+	if failed > 0 {
+		plan.Changes = append(plan.Changes, fmt.Sprintf("(%d notes kept their title after LLM errors)", failed))
+	}
+	plan.Files = map[string]string{filePath: file.String()}
+	return plan, nil
+}
+
+// sendBulkMenu lists the bulk operations
+func (b *Bot) sendBulkMenu(chatID int64) error {
+	msg := tgbotapi.NewMessage(chatID, "🧰 <b>Bulk operations</b>\n\nChange many notes at once. Every operation is previewed first and applied as a single commit.\n\nChoose an operation to see how to use it:")
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🧠 Retitle notes", "bulk_help_retitle")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🏷 Rename a hashtag", "bulk_help_retag")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("📦 Move notes", "bulk_help_move")),
+	)
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		return fmt.Errorf("failed to send bulk menu: %w", err)
+	}
+	return nil
+}
+
+// bulkUsage explains each bulk operation
+var bulkUsage = map[string]string{
+	"retitle": fmt.Sprintf("🧠 <b>Retitle notes</b>\n\n<code>/bulk retitle note.md [count]</code>\n\nAsks the LLM for new titles of the newest notes of a file (%d by default, up to %d). Tags and content stay as they are.", bulkDefaultNotes, bulkMaxNotes),
+	"retag":   "🏷 <b>Rename a hashtag</b>\n\n<code>/bulk retag note.md #old #new</code>\n\nRenames a hashtag everywhere in a file. Only whole tags change: renaming #go leaves #golang alone.",
+	"move":    fmt.Sprintf("📦 <b>Move notes</b>\n\n<code>/bulk move inbox.md note.md [count]</code>\n\nMoves the newest notes of a file (%d by default, up to %d) to the top of another file, keeping their order.", bulkDefaultNotes, bulkMaxNotes),
+}
+
+// handleBulkHelpCallback shows how to use a bulk operation
+func (b *Bot) handleBulkHelpCallback(callback *tgbotapi.CallbackQuery) error {
+	usage, ok := bulkUsage[strings.TrimPrefix(callback.Data, "bulk_help_")]
+	if !ok {
+		return nil
+	}
+	edit := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, usage)
+	edit.ParseMode = "HTML"
+	if _, err := b.rateLimitedSend(callback.Message.Chat.ID, edit); err != nil {
+		return fmt.Errorf("failed to show bulk usage: %w", err)
+	}
+	return nil
+}
+
+// formatBulkPreview renders a plan as a dry run
+func formatBulkPreview(plan *bulkPlan) string {
+	var sb strings.Builder
+	sb.WriteString("🧪 <b>Bulk preview</b> (dry run)\n\n")
+	sb.WriteString(fmt.Sprintf("<b>%s</b>\n\n", html.EscapeString(strings.TrimSuffix(plan.Summary, " via Telegram"))))
+	for i, change := range plan.Changes {
+		if i == bulkPreviewChanges {
+			sb.WriteString(fmt.Sprintf("<i>…and %d more</i>\n", len(plan.Changes)-bulkPreviewChanges))
+			break
+		}
+		sb.WriteString("• " + html.EscapeString(change) + "\n")
+	}
+
+	files := make([]string, 0, len(plan.Files))
+	for filename := range plan.Files {
+		files = append(files, html.EscapeString(filename))
+	}
+	sort.Strings(files)
+	sb.WriteString(fmt.Sprintf("\n📁 %s\n\nNothing is committed until you apply. The preview expires in %d minutes.", strings.Join(files, ", "), int(bulkPlanExpiry.Minutes())))
+	return sb.String()
+}
+
+func (b *Bot) sendBulkPreview(chatID int64, plan *bulkPlan) error {
+	msg := tgbotapi.NewMessage(chatID, formatBulkPreview(plan))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Apply", "bulk_apply_"+plan.ID),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "bulk_cancel_"+plan.ID),
+	))
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		return fmt.Errorf("failed to send bulk preview: %w", err)
+	}
+	return nil
+}
+
+// pendingBulkPlan returns the chat's plan if it is the one with the given ID
+func (b *Bot) pendingBulkPlan(chatID int64, id string) *bulkPlan {
+	cached, ok := b.cache.Get(bulkPlanKey(chatID))
+	if !ok {
+		return nil
+	}
+	plan, ok := cached.(*bulkPlan)
+	if !ok || plan.ID != id {
+		return nil
+	}
+	return plan
+}
+
+// handleBulkCancelCallback drops a previewed plan
+func (b *Bot) handleBulkCancelCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	if b.pendingBulkPlan(chatID, strings.TrimPrefix(callback.Data, "bulk_cancel_")) != nil {
+		b.cache.Delete(bulkPlanKey(chatID))
+	}
+	b.editMessage(chatID, callback.Message.MessageID, "❌ Bulk operation cancelled, nothing was committed.")
+	return nil
+}
+
+// handleBulkApplyCallback commits a previewed plan if its files are unchanged
+func (b *Bot) handleBulkApplyCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	plan := b.pendingBulkPlan(chatID, strings.TrimPrefix(callback.Data, "bulk_apply_"))
+	if plan == nil {
+		b.editMessage(chatID, callback.Message.MessageID, "⌛ This preview expired or was replaced. Run /bulk again.")
+		return nil
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ "+err.Error())
+		return nil
+	}
+
+	for filename, original := range plan.Original {
+		current, err := userGitHubProvider.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		if current != original {
+			b.cache.Delete(bulkPlanKey(chatID))
+			b.editMessage(chatID, callback.Message.MessageID, fmt.Sprintf("⚠️ %s changed since the preview, nothing was committed. Run /bulk again.", filename))
+			return nil
+		}
+	}
+
+	b.editMessage(chatID, callback.Message.MessageID, "⏳ Committing...")
+	files := b.withReadmeTOC(chatID, userGitHubProvider, plan.Files) // Implemented in readme_toc.go
+	if err := userGitHubProvider.ReplaceMultipleFilesWithAuthorAndPremium(files, plan.Summary, b.getCommitterInfo(chatID), b.getPremiumLevel(chatID)); err != nil {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Failed to commit the bulk operation: "+err.Error())
+		return fmt.Errorf("failed to commit bulk operation: %w", err)
+	}
+	b.cache.Delete(bulkPlanKey(chatID))
+
+	logger.Info("Applied bulk operation", map[string]interface{}{
+		"chat_id": chatID,
+		"summary": plan.Summary,
+		"changes": len(plan.Changes),
+		"files":   len(plan.Files),
+	})
+	b.editMessage(chatID, callback.Message.MessageID, fmt.Sprintf("✅ %s, committed at once.", strings.TrimSuffix(plan.Summary, " via Telegram")))
+	return nil
+}
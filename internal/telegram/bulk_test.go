@@ -0,0 +1,84 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/entry"
+)
+
+func TestPlanMove(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	newest := entry.Note("newest", 3, 42, "Newest", "", now)
+	middle := entry.Note("middle", 2, 42, "Middle", "", now)
+	oldest := entry.Note("oldest", 1, 42, "Oldest", "", now)
+	existing := entry.Note("existing", 9, 42, "Existing", "", now)
+
+	plan, err := planMove("inbox.md", newest+middle+oldest, "note.md", "# Notes\n\n"+existing, 2)
+	if err != nil {
+		t.Fatalf("planMove() error = %v", err)
+	}
+	if got := plan.Files["inbox.md"]; got != oldest {
+		t.Errorf("inbox.md = %q, want only the oldest note", got)
+	}
+	if got, want := plan.Files["note.md"], "# Notes\n\n"+newest+middle+existing; got != want {
+		t.Errorf("note.md = %q, want %q", got, want)
+	}
+	if len(plan.Changes) != 2 || !strings.Contains(plan.Changes[0], "Newest") {
+		t.Errorf("Changes = %v", plan.Changes)
+	}
+
+	if _, err := planMove("empty.md", "", "note.md", "", 2); err == nil {
+		t.Error("planMove(empty) error = nil, want an error")
+	}
+}
+
+func TestPlanRetag(t *testing.T) {
+	plan, err := planRetag("note.md", "#go #golang\ntext #go", "#go", "#rust")
+	if err != nil {
+		t.Fatalf("planRetag() error = %v", err)
+	}
+	if got := plan.Files["note.md"]; got != "#rust #golang\ntext #rust" {
+		t.Errorf("note.md = %q", got)
+	}
+
+	if _, err := planRetag("note.md", "#golang", "#go", "#rust"); err == nil {
+		t.Error("planRetag(missing tag) error = nil, want an error")
+	}
+}
+
+func TestParseBulkArguments(t *testing.T) {
+	if n, err := parseBulkCount(""); err != nil || n != bulkDefaultNotes {
+		t.Errorf("parseBulkCount(\"\") = %d, %v", n, err)
+	}
+	for _, arg := range []string{"0", "51", "ten"} {
+		if _, err := parseBulkCount(arg); err == nil {
+			t.Errorf("parseBulkCount(%q) error = nil, want an error", arg)
+		}
+	}
+
+	if tag, err := normalizeHashtag("work"); err != nil || tag != "#work" {
+		t.Errorf("normalizeHashtag(work) = %q, %v", tag, err)
+	}
+	for _, tag := range []string{"#", "#two words", "#a#b"} {
+		if _, err := normalizeHashtag(tag); err == nil {
+			t.Errorf("normalizeHashtag(%q) error = nil, want an error", tag)
+		}
+	}
+}
+
+func TestFormatBulkPreview(t *testing.T) {
+	plan := &bulkPlan{Summary: "Retitle 12 notes in note.md via Telegram", Files: map[string]string{"note.md": ""}}
+	for i := 0; i < 12; i++ {
+		plan.Changes = append(plan.Changes, "old → <new>")
+	}
+
+	preview := formatBulkPreview(plan)
+	if !strings.Contains(preview, "Retitle 12 notes in note.md</b>") || !strings.Contains(preview, "…and 2 more") {
+		t.Errorf("preview = %q", preview)
+	}
+	if strings.Contains(preview, "<new>") {
+		t.Errorf("preview = %q, want changes escaped", preview)
+	}
+}
@@ -84,7 +84,7 @@ func (b *Bot) handleBackToFiles(callback *tgbotapi.CallbackQuery) error {
 	messageKey := strings.TrimPrefix(callback.Data, "back_to_files_")
 
 	// Recreate the original file selection interface
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		return fmt.Errorf("original message not found")
 	}
@@ -260,6 +260,18 @@ func (b *Bot) handleRemoveCustomFile(callback *tgbotapi.CallbackQuery) error {
 		return nil
 	}
 
+	// A removed file doesn't keep its template (implemented in file_templates.go)
+	if _, err := b.db.DeleteCustomFileTemplate(callback.Message.Chat.ID, fileToRemove); err != nil {
+		logger.Warn("Failed to delete file template", map[string]interface{}{
+			"chat_id":  callback.Message.Chat.ID,
+			"filename": fileToRemove,
+			"error":    err.Error(),
+		})
+	}
+
+	// Offer to move the file itself to trash (implemented in commands_trash.go)
+	b.offerMoveToTrash(callback.Message.Chat.ID, fileToRemove)
+
 	// Show success message and refresh the list
 	successMsg := fmt.Sprintf("✅ Removed custom file: <code>%s</code>\n\n", fileToRemove)
 
@@ -270,11 +282,7 @@ func (b *Bot) handleRemoveCustomFile(callback *tgbotapi.CallbackQuery) error {
 		// Determine context based on whether messageKey was provided
 		if messageKey != "" {
 			// We're in message-saving workflow - show empty state with Add New File button
-			var premiumLevel int = 0
-			premiumUser, err := b.db.GetPremiumUser(callback.Message.Chat.ID)
-			if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-				premiumLevel = premiumUser.Level
-			}
+			premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
 
 			customFileLimit := database.GetCustomFileLimit(premiumLevel)
 			successMsg += fmt.Sprintf(`📁 <b>Custom Files (0/%d)</b>
@@ -286,7 +294,7 @@ All custom files removed. You can add a new custom file to save your message.
 			// Create buttons for empty state with Back button
 			var callbackData string
 			// Check if it's a photo message by looking at the pending message data
-			messageData, exists := b.pendingMessages[messageKey]
+			messageData, exists := b.pendingMessage(messageKey)
 			isPhoto := false
 			if exists {
 				// Photo messages have 3 parts: content|messageID|photoURL
@@ -318,11 +326,7 @@ All custom files removed. You can add a new custom file to save your message.
 			return nil
 		} else {
 			// We're in standalone /customfile management - show empty state with Add New File button
-			var premiumLevel int = 0
-			premiumUser, err := b.db.GetPremiumUser(callback.Message.Chat.ID)
-			if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-				premiumLevel = premiumUser.Level
-			}
+			premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
 
 			customFileLimit := database.GetCustomFileLimit(premiumLevel)
 			tierNames := []string{"Free", "☕ Coffee", "🍰 Cake", "🎁 Sponsor"}
@@ -379,7 +383,7 @@ File removed successfully. Choose a file to save your message:`, len(updatedFile
 		}
 
 		// Check if it's a photo message by looking at the pending message data
-		messageData, exists := b.pendingMessages[messageKey]
+		messageData, exists := b.pendingMessage(messageKey)
 		isPhoto := false
 		if exists {
 			// Photo messages have 3 parts: content|messageID|photoURL
@@ -463,11 +467,7 @@ func (b *Bot) refreshCustomFilesList(callback *tgbotapi.CallbackQuery, user *dat
 
 	if len(customFiles) == 0 {
 		// Get premium status for file limits
-		var premiumLevel int = 0
-		premiumUser, err := b.db.GetPremiumUser(callback.Message.Chat.ID)
-		if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-			premiumLevel = premiumUser.Level
-		}
+		premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
 
 		customFileLimit := database.GetCustomFileLimit(premiumLevel)
 		tierNames := []string{"Free", "☕ Coffee", "🍰 Cake", "🎁 Sponsor"}
@@ -504,11 +504,7 @@ func (b *Bot) refreshCustomFilesList(callback *tgbotapi.CallbackQuery, user *dat
 	}
 
 	// Get premium status for file limits
-	var premiumLevel int = 0
-	premiumUser, err := b.db.GetPremiumUser(callback.Message.Chat.ID)
-	if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-		premiumLevel = premiumUser.Level
-	}
+	premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
 
 	customFileLimit := database.GetCustomFileLimit(premiumLevel)
 	tierNames := []string{"Free", "☕ Coffee", "🍰 Cake", "🎁 Sponsor"}
@@ -530,7 +526,7 @@ func (b *Bot) refreshCustomFilesList(callback *tgbotapi.CallbackQuery, user *dat
 	}
 
 	msgText.WriteString("\n<i>Choose an action below:</i>\n")
-	msgText.WriteString("<i>💡 Note: Remove only removes from list, files remain in repository</i>")
+	msgText.WriteString("<i>💡 Note: Remove only removes from list, you can then move the file to trash</i>")
 
 	// Create action buttons
 	row1 := tgbotapi.NewInlineKeyboardRow(
@@ -539,10 +535,13 @@ func (b *Bot) refreshCustomFilesList(callback *tgbotapi.CallbackQuery, user *dat
 	)
 	row2 := tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("📌 Pin File", "customfile_pin"),
+		tgbotapi.NewInlineKeyboardButtonData("📄 Templates", "customfile_templates"),
+	)
+	row3 := tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("✅ Done", "customfile_done"),
 	)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(row1, row2)
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(row1, row2, row3)
 
 	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, msgText.String())
 	editMsg.ParseMode = "HTML"
@@ -568,6 +567,8 @@ func (b *Bot) handleCustomFileAction(callback *tgbotapi.CallbackQuery) error {
 		return b.handleCustomFileRemoveSelect(callback)
 	case "pin":
 		return b.handleCustomFilePinSelect(callback)
+	case "templates":
+		return b.showFileTemplates(callback, "") // Implemented in file_templates.go
 	case "back":
 		user, err := b.ensureUserFromCallback(callback)
 		if err != nil {
@@ -597,11 +598,7 @@ func (b *Bot) handleCustomFileAddNew(callback *tgbotapi.CallbackQuery) error {
 	}
 
 	// Check if user has reached custom file limit
-	var premiumLevel int = 0
-	premiumUser, err := b.db.GetPremiumUser(callback.Message.Chat.ID)
-	if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-		premiumLevel = premiumUser.Level
-	}
+	premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
 
 	currentFiles := user.GetCustomFiles()
 	customFileLimit := database.GetCustomFileLimit(premiumLevel)
@@ -645,7 +642,7 @@ Reply to this message with a file path for your new custom file.
 	// Store state for reply handling (using the same format as existing implementation)
 	stateKey := fmt.Sprintf("add_custom_%d", callback.Message.Chat.ID)
 	stateData := fmt.Sprintf("customfile_standalone|||DELIM|||false") // Mark this as standalone customfile operation
-	b.pendingMessages[stateKey] = stateData
+	b.pendingMessages.Set(stateKey, stateData)
 
 	return nil
 }
@@ -741,7 +738,7 @@ func (b *Bot) handleCustomFileRemoveSelect(callback *tgbotapi.CallbackQuery) err
 // handleCustomFileDone closes the custom file management interface
 func (b *Bot) handleCustomFileDone(callback *tgbotapi.CallbackQuery) error {
 	// Clean up any pending state
-	delete(b.pendingMessages, fmt.Sprintf("add_custom_file_%d", callback.Message.Chat.ID))
+	b.pendingMessages.Delete(fmt.Sprintf("add_custom_file_%d", callback.Message.Chat.ID))
 
 	doneMsg := "✅ Custom file management completed."
 	b.editMessage(callback.Message.Chat.ID, callback.Message.MessageID, doneMsg)
@@ -783,7 +780,7 @@ func (b *Bot) showCustomRemoveFileInterface(callback *tgbotapi.CallbackQuery, me
 		msgText.WriteString(fmt.Sprintf("%d. <code>%s</code>\n", i+1, filePath))
 	}
 
-	msgText.WriteString("\n<i>💡 Note: This only removes from your list, files remain in repository</i>\n")
+	msgText.WriteString("\n<i>💡 Note: This only removes from your list, you can then move the file to trash</i>\n")
 	msgText.WriteString("<i>Click a button below to remove that file:</i>")
 
 	// Create removal buttons (max 20 to fit in message)
@@ -888,11 +885,7 @@ func (b *Bot) handleCustomFilePathReply(message *tgbotapi.Message, stateData str
 	}
 
 	// Check if user has reached custom file limit
-	var premiumLevel int = 0
-	premiumUser, err := b.db.GetPremiumUser(message.Chat.ID)
-	if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-		premiumLevel = premiumUser.Level
-	}
+	premiumLevel := b.getPremiumLevel(message.Chat.ID)
 
 	currentFiles := user.GetCustomFiles()
 	customFileLimit := database.GetCustomFileLimit(premiumLevel)
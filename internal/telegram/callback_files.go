@@ -36,34 +36,27 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 		return b.handleCustomFileSelection(callback, messageKey)
 	}
 
+	// Saving to several files at once (implemented in multi_select.go)
+	if fileType == "multi" {
+		return b.handleFileMultiSelect(callback, messageKey)
+	}
+
 	filename := fileType + ".md"
 
 	// Retrieve the original message content and ID
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		return fmt.Errorf("original message not found")
 	}
 
-	// Parse the stored data (content|||DELIM|||messageID)
-	dataParts := strings.SplitN(messageData, "|||DELIM|||", 2)
-	if len(dataParts) != 2 {
-		return fmt.Errorf("invalid message data format")
-	}
-
-	content := dataParts[0]
-	originalMessageIDStr := dataParts[1]
-
-	// Convert message ID back to int
-	originalMessageID, err := strconv.Atoi(originalMessageIDStr)
+	// Parse the stored data (content|||DELIM|||messageID|||DELIM|||repository)
+	content, originalMessageID, private, err := parsePendingEntry(messageData)
 	if err != nil {
-		logger.Warn("Failed to parse message ID, using 0", map[string]interface{}{
-			"error": err.Error(),
-		})
-		originalMessageID = 0
+		return err
 	}
 
 	// Clean up
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
 
 	// Ensure user exists in database if database is configured
 	_, err = b.ensureUser(callback.Message)
@@ -79,8 +72,8 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 		return nil
 	}
 
-	// Get user-specific GitHub provider (new interface-based approach)
-	userGitHubProvider, err := b.getUserGitHubProvider(callback.Message.Chat.ID)
+	// Get user-specific GitHub provider, private entries go to the private repository
+	userGitHubProvider, err := b.getEntryGitHubProvider(callback.Message.Chat.ID, private)
 	if err != nil {
 		errorMsg := "❌ " + err.Error()
 		if b.db != nil {
@@ -96,9 +89,6 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 		return nil
 	}
 
-	// Get user-specific LLM client
-	userLLMClient, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(callback.Message.Chat.ID, content)
-
 	var formattedContent string
 	var title string
 
@@ -161,9 +151,12 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 			})
 		} else if isNearCapacity {
 			errorMsg := fmt.Sprintf(RepoAlmostFullTemplate, percentage)
+			lapsedNotice, renewMarkup := b.lapsedPremiumNotice(callback.Message.Chat.ID)
+			errorMsg += lapsedNotice
 
 			editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 			editMsg.ParseMode = "html"
+			editMsg.ReplyMarkup = renewMarkup
 			if _, sendErr := b.rateLimitedSend(callback.Message.Chat.ID, editMsg); sendErr != nil {
 				logger.Error("Failed to edit message", map[string]interface{}{
 					"error": sendErr.Error(),
@@ -178,47 +171,7 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 
 		// Other files use LLM processing for title and hashtags (if available)
 		var tags string
-		if userLLMClient != nil {
-			llmResponse, usage, err := userLLMClient.ProcessMessage(content)
-			if err != nil {
-				logger.Warn("LLM processing failed, using content-based title", map[string]interface{}{
-					"error": err.Error(),
-				})
-				title = b.generateTitleFromContent(content)
-				tags = ""
-			} else {
-				title, tags = b.parseTitleAndTags(llmResponse, content)
-
-				// Record token usage in database based on LLM type
-				if usage != nil && b.db != nil {
-					if isUsingDefaultLLM {
-						// Default LLM: record in both user_insights and user_usage
-						if err := b.db.IncrementTokenUsageAll(callback.Message.Chat.ID, int64(usage.PromptTokens), int64(usage.CompletionTokens)); err != nil {
-							logger.Warn("Failed to record token usage (default LLM)", map[string]interface{}{
-								"error":             err.Error(),
-								"chat_id":           callback.Message.Chat.ID,
-								"prompt_tokens":     usage.PromptTokens,
-								"completion_tokens": usage.CompletionTokens,
-							})
-						}
-					} else {
-						// Personal LLM: record only in user_insights
-						if err := b.db.IncrementTokenUsageInsights(callback.Message.Chat.ID, int64(usage.PromptTokens), int64(usage.CompletionTokens)); err != nil {
-							logger.Warn("Failed to record token usage (personal LLM)", map[string]interface{}{
-								"error":             err.Error(),
-								"chat_id":           callback.Message.Chat.ID,
-								"prompt_tokens":     usage.PromptTokens,
-								"completion_tokens": usage.CompletionTokens,
-							})
-						}
-					}
-				}
-			}
-		} else {
-			logger.Debug("No LLM client available, using content-based title", nil)
-			title = b.generateTitleFromContent(content)
-			tags = ""
-		}
+		title, tags = b.entryTitleAndTags(callback.Message.Chat.ID, content)
 		formattedContent = b.formatMessageContentWithTitleAndTags(content, filename, originalMessageID, callback.Message.Chat.ID, title, tags)
 	}
 
@@ -226,10 +179,12 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 	b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 80, "📝 Saving to GitHub...")
 
 	// Commit to GitHub with custom committer info and premium level
-	commitMsg := fmt.Sprintf("Add %s to %s via Telegram", title, filename)
+	commitMsg := fmt.Sprintf("Add %s to %s via Telegram", b.commitTitle(callback.Message.Chat.ID, title), filename)
 	committerInfo := b.getCommitterInfo(callback.Message.Chat.ID)
 	premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
-	if err := userGitHubProvider.CommitFileWithAuthorAndPremium(filename, formattedContent, commitMsg, committerInfo, premiumLevel); err != nil {
+	commitResult, err := userGitHubProvider.CommitFileWithResult(filename, formattedContent, commitMsg, committerInfo, premiumLevel)
+	if err != nil {
+		b.recordRepoFailure(callback.Message.Chat.ID, err)
 		// Check if it's an authorization error and provide helpful message
 		if strings.Contains(err.Error(), "GitHub authorization failed") {
 			// Update the message to show auth error with helpful instructions
@@ -243,8 +198,9 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 			}
 			return nil // Don't return error to avoid double error handling
 		}
+		b.checkRepoMoved(callback.Message.Chat.ID, err)
 		// Edit the existing message to show the error instead of sending a new one
-		errorMsg := fmt.Sprintf("❌ Failed to save: %v", err)
+		errorMsg := saveFailureText("Failed to save", err)
 		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 		if _, sendErr := b.rateLimitedSend(callback.Message.Chat.ID, editMsg); sendErr != nil {
 			logger.Error("Failed to edit message", map[string]interface{}{
@@ -284,6 +240,7 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 	// Update the message to show success with GitHub menu button
 	githubURL, err := userGitHubProvider.GetGitHubFileURLWithBranch(filename)
 	successMsg := fmt.Sprintf("✅ Saved to %s", strings.ToUpper(parts[1]))
+	successMsg += b.recordCommitStats(callback.Message.Chat.ID, userGitHubProvider, commitResult)
 
 	// Create inline keyboard with GitHub link button
 	var keyboard *tgbotapi.InlineKeyboardMarkup
@@ -294,9 +251,7 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 		})
 		// No keyboard if URL generation fails
 	} else {
-		row := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("🔗 View on GitHub", githubURL),
-		)
+		row := commitLinkRow(githubURL, commitResult)
 		keyboardValue := tgbotapi.NewInlineKeyboardMarkup(row)
 		keyboard = &keyboardValue
 	}
@@ -316,6 +271,51 @@ func (b *Bot) handleFileSelection(callback *tgbotapi.CallbackQuery) error {
 	return nil
 }
 
+// entryTitleAndTags returns the title and hashtags of a note, from the user's LLM if available
+func (b *Bot) entryTitleAndTags(chatID int64, content string) (string, string) {
+	userLLMClient, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(chatID, content)
+	if userLLMClient == nil {
+		logger.Debug("No LLM client available, using content-based title", nil)
+		return b.generateTitleFromContent(content), ""
+	}
+
+	llmResponse, usage, err := userLLMClient.ProcessMessage(content)
+	if err != nil {
+		logger.Warn("LLM processing failed, using content-based title", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return b.generateTitleFromContent(content), ""
+	}
+	title, tags := b.parseTitleAndTags(llmResponse, content)
+
+	// Record token usage in database based on LLM type
+	if usage != nil && b.db != nil {
+		if isUsingDefaultLLM {
+			// Default LLM: record in both user_insights and user_usage
+			if err := b.db.IncrementTokenUsageAll(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens)); err != nil {
+				logger.Warn("Failed to record token usage (default LLM)", map[string]interface{}{
+					"error":             err.Error(),
+					"chat_id":           chatID,
+					"prompt_tokens":     usage.PromptTokens,
+					"completion_tokens": usage.CompletionTokens,
+				})
+			}
+		} else {
+			// Personal LLM: record only in user_insights
+			if err := b.db.IncrementTokenUsageInsights(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens)); err != nil {
+				logger.Warn("Failed to record token usage (personal LLM)", map[string]interface{}{
+					"error":             err.Error(),
+					"chat_id":           chatID,
+					"prompt_tokens":     usage.PromptTokens,
+					"completion_tokens": usage.CompletionTokens,
+				})
+			}
+		}
+	}
+
+	return title, tags
+}
+
 func (b *Bot) handleCancel(callback *tgbotapi.CallbackQuery) error {
 	// Extract messageKey from callback data
 	parts := strings.SplitN(callback.Data, "_", 2)
@@ -326,7 +326,7 @@ func (b *Bot) handleCancel(callback *tgbotapi.CallbackQuery) error {
 	messageKey := parts[1]
 
 	// Clean up the pending message
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
 
 	// Update the message to show cancellation
 	cancelMsg := "❌ Cancelled"
@@ -374,35 +374,23 @@ func (b *Bot) handlePinnedFileSelection(callback *tgbotapi.CallbackQuery) error
 	selectedFile := customFiles[pinnedIndex]
 
 	// Retrieve the original message content and ID
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		return fmt.Errorf("original message not found")
 	}
 
-	// Parse the stored data (content|||DELIM|||messageID)
-	dataParts := strings.SplitN(messageData, "|||DELIM|||", 2)
-	if len(dataParts) != 2 {
-		return fmt.Errorf("invalid message data format")
-	}
-
-	content := dataParts[0]
-	originalMessageIDStr := dataParts[1]
-
-	// Convert message ID back to int
-	originalMessageID, err := strconv.Atoi(originalMessageIDStr)
+	// Parse the stored data (content|||DELIM|||messageID|||DELIM|||repository)
+	content, originalMessageID, private, err := parsePendingEntry(messageData)
 	if err != nil {
-		logger.Warn("Failed to parse message ID, using 0", map[string]interface{}{
-			"error": err.Error(),
-		})
-		originalMessageID = 0
+		return err
 	}
 
 	// Update the progress message
 	progressMsg := fmt.Sprintf("📌 Saving to pinned file: %s", selectedFile)
 	b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 0, progressMsg)
 
-	// Get user-specific GitHub provider
-	userGitHubProvider, err := b.getUserGitHubProvider(callback.Message.Chat.ID)
+	// Get user-specific GitHub provider, private entries go to the private repository
+	userGitHubProvider, err := b.getEntryGitHubProvider(callback.Message.Chat.ID, private)
 	if err != nil {
 		errorMsg := "❌ " + err.Error()
 		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
@@ -443,74 +431,36 @@ func (b *Bot) handlePinnedFileSelection(callback *tgbotapi.CallbackQuery) error
 		})
 	} else if isNearCapacity {
 		errorMsg := fmt.Sprintf(RepoAlmostFullTemplate, percentage)
+		lapsedNotice, renewMarkup := b.lapsedPremiumNotice(callback.Message.Chat.ID)
+		errorMsg += lapsedNotice
 		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 		editMsg.ParseMode = "html"
+		editMsg.ReplyMarkup = renewMarkup
 		if _, sendErr := b.rateLimitedSend(callback.Message.Chat.ID, editMsg); sendErr != nil {
 			b.sendResponse(callback.Message.Chat.ID, errorMsg)
 		}
 		return nil
 	}
 
-	// Get user LLM client for processing
-	userLLMClient, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(callback.Message.Chat.ID, content)
-
 	// Process LLM if configured
 	b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 60, "🧠 LLM processing...")
 
 	// Process with LLM for title and hashtags (if available)
 	var formattedContent string
-	var title string
-	var tags string
-	if userLLMClient != nil {
-		llmResponse, usage, err := userLLMClient.ProcessMessage(content)
-		if err != nil {
-			logger.Warn("LLM processing failed, using content-based title", map[string]interface{}{
-				"error": err.Error(),
-			})
-			title = b.generateTitleFromContent(content)
-			tags = ""
-		} else {
-			title, tags = b.parseTitleAndTags(llmResponse, content)
-
-			// Record token usage in database based on LLM type
-			if usage != nil && b.db != nil {
-				if isUsingDefaultLLM {
-					// Default LLM: record in both user_insights and user_usage
-					if err := b.db.IncrementTokenUsageAll(callback.Message.Chat.ID, int64(usage.PromptTokens), int64(usage.CompletionTokens)); err != nil {
-						logger.Warn("Failed to record token usage (default LLM)", map[string]interface{}{
-							"error":             err.Error(),
-							"chat_id":           callback.Message.Chat.ID,
-							"prompt_tokens":     usage.PromptTokens,
-							"completion_tokens": usage.CompletionTokens,
-						})
-					}
-				} else {
-					// Personal LLM: record only in user_insights
-					if err := b.db.IncrementTokenUsageInsights(callback.Message.Chat.ID, int64(usage.PromptTokens), int64(usage.CompletionTokens)); err != nil {
-						logger.Warn("Failed to record token usage (personal LLM)", map[string]interface{}{
-							"error":             err.Error(),
-							"chat_id":           callback.Message.Chat.ID,
-							"prompt_tokens":     usage.PromptTokens,
-							"completion_tokens": usage.CompletionTokens,
-						})
-					}
-				}
-			}
-		}
-	} else {
-		logger.Debug("No LLM client available, using content-based title", nil)
-		title = b.generateTitleFromContent(content)
-		tags = ""
-	}
+	title, tags := b.entryTitleAndTags(callback.Message.Chat.ID, content)
 	formattedContent = b.formatMessageContentWithTitleAndTags(content, selectedFile, originalMessageID, callback.Message.Chat.ID, title, tags)
 
 	// Show GitHub commit status with progress
 	b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 80, "📝 Saving to GitHub...")
 
 	// Commit to GitHub with custom committer info and premium level
-	commitMsg := fmt.Sprintf("Add %s to %s via Telegram", title, selectedFile)
+	commitMsg := fmt.Sprintf("Add %s to %s via Telegram", b.commitTitle(callback.Message.Chat.ID, title), selectedFile)
 	committerInfo := b.getCommitterInfo(callback.Message.Chat.ID)
-	if err := userGitHubProvider.CommitFileWithAuthorAndPremium(selectedFile, formattedContent, commitMsg, committerInfo, premiumLevel); err != nil {
+	// A new file starts with its template (implemented in file_templates.go)
+	formattedContent = b.applyFileTemplate(callback.Message.Chat.ID, userGitHubProvider, selectedFile, formattedContent)
+	commitResult, err := userGitHubProvider.CommitFileWithResult(selectedFile, formattedContent, commitMsg, committerInfo, premiumLevel)
+	if err != nil {
+		b.recordRepoFailure(callback.Message.Chat.ID, err)
 		// Check if it's an authorization error and provide helpful message
 		if strings.Contains(err.Error(), "GitHub authorization failed") {
 			errorMsg := "❌ " + err.Error()
@@ -521,8 +471,9 @@ func (b *Bot) handlePinnedFileSelection(callback *tgbotapi.CallbackQuery) error
 			return nil
 		}
 
+		b.checkRepoMoved(callback.Message.Chat.ID, err)
 		// Generic error handling
-		errorMsg := "❌ Failed to save to GitHub: " + err.Error()
+		errorMsg := saveFailureText("Failed to save to GitHub", err)
 		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 		if _, sendErr := b.rateLimitedSend(callback.Message.Chat.ID, editMsg); sendErr != nil {
 			b.sendResponse(callback.Message.Chat.ID, errorMsg)
@@ -531,11 +482,21 @@ func (b *Bot) handlePinnedFileSelection(callback *tgbotapi.CallbackQuery) error
 	}
 
 	// Clean up pending message
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
+
+	// Increment commit count before the confirmation shows the streak
+	if b.db != nil {
+		if err := b.db.IncrementCommitCount(callback.Message.Chat.ID); err != nil {
+			logger.Error("Failed to increment commit count", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
 
 	// Success message with GitHub link
 	githubURL, err := userGitHubProvider.GetGitHubFileURLWithBranch(selectedFile)
 	successMsg := fmt.Sprintf("✅ Saved to pinned file: %s", selectedFile)
+	successMsg += b.recordCommitStats(callback.Message.Chat.ID, userGitHubProvider, commitResult)
 
 	// Create inline keyboard with GitHub link button
 	var keyboard *tgbotapi.InlineKeyboardMarkup
@@ -546,9 +507,7 @@ func (b *Bot) handlePinnedFileSelection(callback *tgbotapi.CallbackQuery) error
 		})
 		// No keyboard if URL generation fails
 	} else {
-		row := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("🔗 View on GitHub", githubURL),
-		)
+		row := commitLinkRow(githubURL, commitResult)
 		keyboardValue := tgbotapi.NewInlineKeyboardMarkup(row)
 		keyboard = &keyboardValue
 	}
@@ -561,14 +520,5 @@ func (b *Bot) handlePinnedFileSelection(callback *tgbotapi.CallbackQuery) error
 		b.sendResponse(callback.Message.Chat.ID, successMsg)
 	}
 
-	// Increment commit count
-	if b.db != nil {
-		if err := b.db.IncrementCommitCount(callback.Message.Chat.ID); err != nil {
-			logger.Error("Failed to increment commit count", map[string]interface{}{
-				"error": err.Error(),
-			})
-		}
-	}
-
 	return nil
 }
@@ -10,9 +10,10 @@ import (
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/msg2git/msg2git/internal/consts"
-	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/entry"
 	"github.com/msg2git/msg2git/internal/github"
 	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/webhook"
 )
 
 // Issue-related callback handlers
@@ -52,6 +53,9 @@ func (b *Bot) handleIssueComment(callback *tgbotapi.CallbackQuery) error {
 		return fmt.Errorf("invalid issue number: %w", err)
 	}
 
+	// Offer canned replies first so the reply prompt stays last (implemented in canned_replies.go)
+	b.sendCannedReplyPicker(callback.Message.Chat.ID, issueNumber)
+
 	// Send force reply message to get comment
 	forceReplyMsg := fmt.Sprintf("💬 <b>Add comment to issue #%d</b>\n\nPlease reply to this message with your comment:", issueNumber)
 	msg := tgbotapi.NewMessage(callback.Message.Chat.ID, forceReplyMsg)
@@ -72,7 +76,7 @@ func (b *Bot) handleIssueComment(callback *tgbotapi.CallbackQuery) error {
 
 	// Store the issue number with the sent message ID for later processing
 	messageKey := fmt.Sprintf("comment_%d_%d", callback.Message.Chat.ID, sentMsg.MessageID)
-	b.pendingMessages[messageKey] = fmt.Sprintf("issue_comment_%d", issueNumber)
+	b.pendingMessages.Set(messageKey, fmt.Sprintf("issue_comment_%d", issueNumber))
 
 	return nil
 }
@@ -197,8 +201,8 @@ func (b *Bot) handleIssueClose(callback *tgbotapi.CallbackQuery) error {
 		for _, line := range lines {
 			// Look for lines that contain this specific issue number
 			if strings.Contains(line, fmt.Sprintf("#%d", issueNumber)) && strings.Contains(line, "🟢") {
-				// Replace only this specific issue's status
-				updatedLine := strings.Replace(line, "🟢", "🔴", 1)
+				// Replace only this specific issue's status, the close date starts its archive retention
+				updatedLine := strings.Replace(line, "🟢", "🔴", 1) + fmt.Sprintf(" (closed %s)", time.Now().UTC().Format(issueClosedDateLayout))
 				updatedLines = append(updatedLines, updatedLine)
 				logger.Debug("Updated issue status in issue.md", map[string]interface{}{
 					"issue_number": issueNumber,
@@ -246,6 +250,9 @@ func (b *Bot) handleIssueClose(callback *tgbotapi.CallbackQuery) error {
 
 	// Show success message
 	successMsg := fmt.Sprintf("✅ Issue #%d has been closed successfully!", issueNumber)
+	if b.completeLinkedTodos(callback.Message.Chat.ID, userGitHubProvider, []int{issueNumber}) > 0 {
+		successMsg += "\n☑️ The linked TODO was checked off."
+	}
 	if progressMessageID > 0 {
 		editSuccessMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, progressMessageID, successMsg)
 		if _, err := b.rateLimitedSend(callback.Message.Chat.ID, editSuccessMsg); err != nil {
@@ -324,7 +331,7 @@ func (b *Bot) handleIssueCommentReply(message *tgbotapi.Message, commentData str
 		photo := message.Photo[len(message.Photo)-1]
 
 		// Download the photo
-		photoData, filename, err := b.downloadPhoto(photo.FileID)
+		photoData, filename, err := b.downloadPhoto(message.Chat.ID, photo.FileID)
 		if err != nil {
 			logger.Error("Failed to download photo for issue comment", map[string]interface{}{
 				"error":        err.Error(),
@@ -348,7 +355,7 @@ func (b *Bot) handleIssueCommentReply(message *tgbotapi.Message, commentData str
 		b.updateProgressMessage(message.Chat.ID, statusMessageID, 50, "📝 Uploading photo to GitHub CDN...")
 
 		// Upload to GitHub CDN and get the URL - same as handlePhotoMessage
-		photoURL, err := userGitHubProvider.UploadImageToCDN(photoFilename, photoData)
+		photoURL, reused, err := b.uploadPhoto(message.Chat.ID, userGitHubProvider, photoFilename, photoData)
 		if err != nil {
 			logger.Error("Failed to upload photo to GitHub CDN for issue comment", map[string]interface{}{
 				"error":        err.Error(),
@@ -377,7 +384,7 @@ func (b *Bot) handleIssueCommentReply(message *tgbotapi.Message, commentData str
 		}
 
 		// Increment image count for photo upload
-		if b.db != nil {
+		if b.db != nil && !reused {
 			if err := b.db.IncrementImageCount(message.Chat.ID); err != nil {
 				logger.Error("Failed to increment image count for issue comment", map[string]interface{}{
 					"error":   err.Error(),
@@ -391,7 +398,7 @@ func (b *Bot) handleIssueCommentReply(message *tgbotapi.Message, commentData str
 	}
 
 	// Add comment to GitHub issue
-	commentURL, err := userGitHubProvider.AddIssueComment(issueNumber, commentText)
+	commentURL, err := userGitHubProvider.AddIssueComment(issueNumber, b.resolveMentions(message.Chat.ID, commentText))
 	if err != nil {
 		logger.Error("Failed to add comment to GitHub issue", map[string]interface{}{
 			"error":        err.Error(),
@@ -459,34 +466,22 @@ func (b *Bot) handleIssueCommentReply(message *tgbotapi.Message, commentData str
 	return nil
 }
 
-func (b *Bot) handleIssueCreation(callback *tgbotapi.CallbackQuery, messageKey string) error {
+// createIssueFromPending creates an issue with labels from a pending message
+func (b *Bot) createIssueFromPending(callback *tgbotapi.CallbackQuery, messageKey string, labels []string) error {
 	// Retrieve the original message content and ID
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		return fmt.Errorf("original message not found")
 	}
 
-	// Parse the stored data (content|||DELIM|||messageID)
-	dataParts := strings.SplitN(messageData, "|||DELIM|||", 2)
-	if len(dataParts) != 2 {
-		return fmt.Errorf("invalid message data format")
-	}
-
-	content := dataParts[0]
-	originalMessageIDStr := dataParts[1]
-
-	// Convert message ID back to int
-	originalMessageID, err := strconv.Atoi(originalMessageIDStr)
+	// Parse the stored data (content|||DELIM|||messageID|||DELIM|||repository)
+	content, _, _, err := parsePendingEntry(messageData)
 	if err != nil {
-		logger.Warn("Failed to parse message ID, using 0", map[string]interface{}{
-			"error": err.Error(),
-		})
-		originalMessageID = 0
+		return err
 	}
-	_ = originalMessageID
 
 	// Clean up
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
 
 	// Ensure user exists in database if database is configured
 	_, err = b.ensureUser(callback.Message)
@@ -524,28 +519,27 @@ func (b *Bot) handleIssueCreation(callback *tgbotapi.CallbackQuery, messageKey s
 
 	// Check issue creation limits
 	if b.db != nil {
-		canCreate, currentCount, limit, err := b.db.CheckUsageIssueLimit(callback.Message.Chat.ID, premiumLevel)
+		issues, err := b.usageLimits().Issues(callback.Message.Chat.ID, premiumLevel)
 		if err != nil {
 			logger.Error("Failed to check issue limit", map[string]interface{}{
 				"error":   err.Error(),
 				"chat_id": callback.Message.Chat.ID,
 			})
-		} else if !canCreate {
+		} else if !issues.Allowed {
 			// User has reached their issue limit
 			tierNames := map[int]string{0: "free", 1: "☕ Coffee", 2: "🍰 Cake", 3: "🎁 Sponsor"}
 			currentTier := tierNames[premiumLevel]
 
 			var upgradeMsg string
-			if premiumLevel < 3 {
-				nextLimit := database.GetIssueLimit(premiumLevel + 1)
-				upgradeMsg = fmt.Sprintf(IssueLimitUpgradeTemplate, nextLimit)
+			if issues.Reset.NextLevel > 0 {
+				upgradeMsg = fmt.Sprintf(IssueLimitUpgradeTemplate, issues.Reset.NextMax)
 			}
 
 			errorMsg := fmt.Sprintf(`🚫 <b>Issue creation limit reached</b>
 
 You've used %d/%d issues on the %s tier.%s
 
-<i>You can still view and manage existing issues with /issue command</i>`, currentCount, limit, currentTier, upgradeMsg)
+<i>You can still view and manage existing issues with /issue command</i>`, issues.Current, issues.Max, currentTier, upgradeMsg)
 
 			editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 			editMsg.ParseMode = "html"
@@ -553,7 +547,7 @@ You've used %d/%d issues on the %s tier.%s
 				logger.Error("Failed to edit message with issue limit error", map[string]interface{}{
 					"error": sendErr.Error(),
 				})
-				b.sendResponse(callback.Message.Chat.ID, fmt.Sprintf("❌ Issue creation limit reached: %d/%d", currentCount, limit))
+				b.sendResponse(callback.Message.Chat.ID, fmt.Sprintf("❌ Issue creation limit reached: %d/%d", issues.Current, issues.Max))
 			}
 			return nil
 		}
@@ -672,9 +666,10 @@ You've used %d/%d issues on the %s tier.%s
 	// Create GitHub issue
 	logger.Info("Attempting to create GitHub issue", map[string]interface{}{
 		"title":   title,
+		"labels":  labels,
 		"chat_id": callback.Message.Chat.ID,
 	})
-	issueURL, issueNumber, err := userGitHubProvider.CreateIssue(title, content)
+	issueURL, issueNumber, err := userGitHubProvider.CreateIssueWithLabels(title, b.resolveMentions(callback.Message.Chat.ID, content), withIssueMarker(labels))
 	if err != nil {
 		logger.Error("Failed to create GitHub issue", map[string]interface{}{
 			"error":   err.Error(),
@@ -693,6 +688,14 @@ You've used %d/%d issues on the %s tier.%s
 		return nil
 	}
 
+	b.emitWebhookEvent(callback.Message.Chat.ID, webhook.EventIssueCreated, map[string]interface{}{
+		"title":        title,
+		"issue_number": issueNumber,
+		"issue_url":    issueURL,
+		"labels":       labels,
+	})
+	b.assignIssueToSelf(callback.Message.Chat.ID, userGitHubProvider, issueNumber)
+
 	// Increment issue count for successful issue creation
 	if b.db != nil {
 		if err := b.db.IncrementIssueCount(callback.Message.Chat.ID); err != nil {
@@ -708,6 +711,7 @@ You've used %d/%d issues on the %s tier.%s
 				"chat_id": callback.Message.Chat.ID,
 			})
 		}
+		go b.checkQuotaAlerts(callback.Message.Chat.ID, nil)
 	}
 
 	// Create markdown format for issue.md with status tracking
@@ -721,7 +725,7 @@ You've used %d/%d issues on the %s tier.%s
 		// Fallback to simple format
 		linkContent = fmt.Sprintf("- 🟢 [%s](#%d)\n", title, issueNumber)
 	} else {
-		linkContent = fmt.Sprintf("- 🟢 %s/%s#%d [%s]\n", owner, repo, issueNumber, title)
+		linkContent = entry.IssueLine(owner, repo, issueNumber, title)
 	}
 
 	// Save the link to issue.md with custom committer info
@@ -804,7 +808,7 @@ You've used %d/%d issues on the %s tier.%s
 	}
 
 	// Update the message to show success with issue management buttons
-	successMsg := fmt.Sprintf("✅ Issue created: #%d", issueNumber)
+	successMsg := fmt.Sprintf("✅ Issue created: #%d", issueNumber) + formatIssueLabels(labels)
 
 	// Create inline keyboard with issue link, comment, and close buttons
 	row := tgbotapi.NewInlineKeyboardRow(
@@ -829,7 +833,7 @@ You've used %d/%d issues on the %s tier.%s
 
 func (b *Bot) handlePhotoIssueCreation(callback *tgbotapi.CallbackQuery, messageKey string) error {
 	// Retrieve the original message content, ID, and photo URL
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		return fmt.Errorf("original message not found")
 	}
@@ -856,7 +860,7 @@ func (b *Bot) handlePhotoIssueCreation(callback *tgbotapi.CallbackQuery, message
 	_ = originalMessageID
 
 	// Clean up
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
 
 	// Ensure user exists in database if database is configured
 	_, err = b.ensureUser(callback.Message)
@@ -894,28 +898,27 @@ func (b *Bot) handlePhotoIssueCreation(callback *tgbotapi.CallbackQuery, message
 
 	// Check issue creation limits
 	if b.db != nil {
-		canCreate, currentCount, limit, err := b.db.CheckUsageIssueLimit(callback.Message.Chat.ID, premiumLevel)
+		issues, err := b.usageLimits().Issues(callback.Message.Chat.ID, premiumLevel)
 		if err != nil {
 			logger.Error("Failed to check issue limit for photo", map[string]interface{}{
 				"error":   err.Error(),
 				"chat_id": callback.Message.Chat.ID,
 			})
-		} else if !canCreate {
+		} else if !issues.Allowed {
 			// User has reached their issue limit
 			tierNames := map[int]string{0: "free", 1: "☕ Coffee", 2: "🍰 Cake", 3: "🎁 Sponsor"}
 			currentTier := tierNames[premiumLevel]
 
 			var upgradeMsg string
-			if premiumLevel < 3 {
-				nextLimit := database.GetIssueLimit(premiumLevel + 1)
-				upgradeMsg = fmt.Sprintf(IssueLimitUpgradeTemplate, nextLimit)
+			if issues.Reset.NextLevel > 0 {
+				upgradeMsg = fmt.Sprintf(IssueLimitUpgradeTemplate, issues.Reset.NextMax)
 			}
 
 			errorMsg := fmt.Sprintf(`🚫 <b>Issue creation limit reached</b>
 
 You've used %d/%d issues on the %s tier.%s
 
-<i>You can still view and manage existing issues with /issue command</i>`, currentCount, limit, currentTier, upgradeMsg)
+<i>You can still view and manage existing issues with /issue command</i>`, issues.Current, issues.Max, currentTier, upgradeMsg)
 
 			editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 			editMsg.ParseMode = "html"
@@ -923,7 +926,7 @@ You've used %d/%d issues on the %s tier.%s
 				logger.Error("Failed to edit message with photo issue limit error", map[string]interface{}{
 					"error": sendErr.Error(),
 				})
-				b.sendResponse(callback.Message.Chat.ID, fmt.Sprintf("❌ Issue creation limit reached: %d/%d", currentCount, limit))
+				b.sendResponse(callback.Message.Chat.ID, fmt.Sprintf("❌ Issue creation limit reached: %d/%d", issues.Current, issues.Max))
 			}
 			return nil
 		}
@@ -987,7 +990,10 @@ You've used %d/%d issues on the %s tier.%s
 	// Process title and tags based on content type
 	var title, tags string
 
-	if strings.HasPrefix(content, "Photo: ") {
+	if analyzedTitle, analyzedTags, ok := b.takePhotoAnalysis(messageKey, content); ok {
+		// Photo and caption were analyzed during the upload (implemented in photo_analysis.go)
+		title, tags = analyzedTitle, analyzedTags
+	} else if strings.HasPrefix(content, "Photo: ") {
 		// No caption case - try multimodal analysis if supported
 		user, err := b.ensureUser(callback.Message)
 		if err == nil && b.shouldPerformMultimodalAnalysis(callback.Message.Chat.ID, user) {
@@ -1127,12 +1133,14 @@ You've used %d/%d issues on the %s tier.%s
 		issueContent = fmt.Sprintf("![Photo](%s)\n\n%s", photoURL, content)
 	}
 
-	// Create GitHub issue
+	// Create GitHub issue, labeled with the caption's hashtags
+	labels := entry.Tags(content)
 	logger.Info("Attempting to create GitHub issue with photo", map[string]interface{}{
 		"title":   title,
+		"labels":  labels,
 		"chat_id": callback.Message.Chat.ID,
 	})
-	issueURL, issueNumber, err := userGitHubProvider.CreateIssue(title, issueContent)
+	issueURL, issueNumber, err := userGitHubProvider.CreateIssueWithLabels(title, b.resolveMentions(callback.Message.Chat.ID, issueContent), withIssueMarker(labels))
 	if err != nil {
 		logger.Error("Failed to create GitHub issue", map[string]interface{}{
 			"error":   err.Error(),
@@ -1150,6 +1158,14 @@ You've used %d/%d issues on the %s tier.%s
 		return nil
 	}
 
+	b.emitWebhookEvent(callback.Message.Chat.ID, webhook.EventIssueCreated, map[string]interface{}{
+		"title":        title,
+		"issue_number": issueNumber,
+		"issue_url":    issueURL,
+		"labels":       labels,
+	})
+	b.assignIssueToSelf(callback.Message.Chat.ID, userGitHubProvider, issueNumber)
+
 	// Increment issue count for successful photo issue creation
 	if b.db != nil {
 		if err := b.db.IncrementIssueCount(callback.Message.Chat.ID); err != nil {
@@ -1165,6 +1181,7 @@ You've used %d/%d issues on the %s tier.%s
 				"chat_id": callback.Message.Chat.ID,
 			})
 		}
+		go b.checkQuotaAlerts(callback.Message.Chat.ID, nil)
 	}
 
 	// Create markdown format for issue.md with status tracking
@@ -1178,7 +1195,7 @@ You've used %d/%d issues on the %s tier.%s
 		// Fallback to simple format
 		linkContent = fmt.Sprintf("- 🟢 [%s](#%d)\n", title, issueNumber)
 	} else {
-		linkContent = fmt.Sprintf("- 🟢 %s/%s#%d [%s]\n", owner, repo, issueNumber, title)
+		linkContent = entry.IssueLine(owner, repo, issueNumber, title)
 	}
 
 	// Save the link to issue.md with custom committer info
@@ -1261,7 +1278,7 @@ You've used %d/%d issues on the %s tier.%s
 	}
 
 	// Update the message to show success with issue management buttons
-	successMsg := fmt.Sprintf("✅ Photo issue created: #%d", issueNumber)
+	successMsg := fmt.Sprintf("✅ Photo issue created: #%d", issueNumber) + formatIssueLabels(labels)
 
 	// Create inline keyboard with issue link, comment, and close buttons
 	row := tgbotapi.NewInlineKeyboardRow(
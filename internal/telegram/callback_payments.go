@@ -503,7 +503,7 @@ This is a demo version. In production, you would be redirected to a secure Strip
 	})
 
 	if premiumUser.CustomerID != "" && premiumUser.IsSubscription {
-		portalSession, err := b.stripeManager.CreateCustomerPortalSession(premiumUser.CustomerID, fmt.Sprintf("%s/account", b.config.BaseURL))
+		portalSession, err := b.stripeManager.CreateCustomerPortalSession(premiumUser.CustomerID, fmt.Sprintf("%s/account", b.cfg().BaseURL))
 		if err != nil {
 			logger.Error("Failed to create customer portal session", map[string]interface{}{
 				"error":        err.Error(),
@@ -40,7 +40,7 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 	filename := fileType + ".md"
 
 	// Retrieve the original message content, ID, and photo URL
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		return fmt.Errorf("original message not found")
 	}
@@ -66,7 +66,7 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 	}
 
 	// Clean up
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
 
 	// Ensure user exists in database if database is configured
 	_, err = b.ensureUser(callback.Message)
@@ -142,9 +142,12 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 		var tags string
 
 		if strings.HasPrefix(content, "Photo: ") {
-			// No caption case - try multimodal analysis if supported
+			// No caption case - use the analysis made during the upload (implemented in
+			// photo_analysis.go), else try multimodal analysis if supported
 			user, err := b.ensureUser(callback.Message)
-			if err == nil && b.shouldPerformMultimodalAnalysis(callback.Message.Chat.ID, user) {
+			if analyzedTitle, analyzedTags, ok := b.takePhotoAnalysis(messageKey, content); ok {
+				title, tags = analyzedTitle, analyzedTags
+			} else if err == nil && b.shouldPerformMultimodalAnalysis(callback.Message.Chat.ID, user) {
 				// Show processing status with progress
 				b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 30, "🔄 Analyzing photo...")
 				
@@ -252,7 +255,10 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 			// Step 2: With caption case - use LLM processing
 			b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 50, "🧠 LLM processing...")
 
-			if userLLMClient != nil {
+			if analyzedTitle, analyzedTags, ok := b.takePhotoAnalysis(messageKey, content); ok {
+				// The caption was analyzed with the photo during the upload
+				title, tags = analyzedTitle, analyzedTags
+			} else if userLLMClient != nil {
 				llmResponse, usage, err := userLLMClient.ProcessMessage(content)
 				if err != nil {
 					logger.Warn("LLM processing failed, using content-based title", map[string]interface{}{
@@ -306,13 +312,15 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 	// Commit to GitHub with custom committer info
 	var commitMsg string
 	if strings.HasPrefix(content, "Photo: ") {
-		commitMsg = fmt.Sprintf("Add photo reference: %s to %s via Telegram", title, filename)
+		commitMsg = fmt.Sprintf("Add photo reference: %s to %s via Telegram", b.commitTitle(callback.Message.Chat.ID, title), filename)
 	} else {
-		commitMsg = fmt.Sprintf("Add photo with caption: %s to %s via Telegram", title, filename)
+		commitMsg = fmt.Sprintf("Add photo with caption: %s to %s via Telegram", b.commitTitle(callback.Message.Chat.ID, title), filename)
 	}
 	committerInfo := b.getCommitterInfo(callback.Message.Chat.ID)
 	premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
-	if err := userGitHubProvider.CommitFileWithAuthorAndPremium(filename, formattedContent, commitMsg, committerInfo, premiumLevel); err != nil {
+	commitResult, err := userGitHubProvider.CommitFileWithResult(filename, formattedContent, commitMsg, committerInfo, premiumLevel)
+	if err != nil {
+		b.recordRepoFailure(callback.Message.Chat.ID, err)
 		// Check if it's an authorization error and provide helpful message
 		if strings.Contains(err.Error(), "GitHub authorization failed") {
 			// Update the message to show auth error with helpful instructions
@@ -326,8 +334,9 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 			}
 			return nil // Don't return error to avoid double error handling
 		}
+		b.checkRepoMoved(callback.Message.Chat.ID, err)
 		// Edit the existing message to show the error instead of sending a new one
-		errorMsg := fmt.Sprintf("❌ Failed to save photo: %v", err)
+		errorMsg := saveFailureText("Failed to save photo", err)
 		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 		if _, sendErr := b.rateLimitedSend(callback.Message.Chat.ID, editMsg); sendErr != nil {
 			logger.Error("Failed to edit message", map[string]interface{}{
@@ -367,6 +376,7 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 	} else {
 		successMsg = fmt.Sprintf("✅ Photo and caption saved to %s", strings.ToUpper(parts[1]))
 	}
+	successMsg += b.recordCommitStats(callback.Message.Chat.ID, userGitHubProvider, commitResult)
 
 	// Create inline keyboard with GitHub link button
 	var keyboard *tgbotapi.InlineKeyboardMarkup
@@ -377,9 +387,7 @@ func (b *Bot) handlePhotoFileSelection(callback *tgbotapi.CallbackQuery) error {
 		})
 		// No keyboard if URL generation fails
 	} else {
-		row := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("🔗 View on GitHub", githubURL),
-		)
+		row := commitLinkRow(githubURL, commitResult)
 		keyboardValue := tgbotapi.NewInlineKeyboardMarkup(row)
 		keyboard = &keyboardValue
 	}
@@ -513,7 +521,7 @@ func (b *Bot) handlePhotoPinnedFileSelection(callback *tgbotapi.CallbackQuery) e
 	selectedFile := customFiles[pinnedIndex]
 
 	// Retrieve the original message content, ID, and photo URL
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		return fmt.Errorf("original message not found")
 	}
@@ -603,8 +611,11 @@ func (b *Bot) handlePhotoPinnedFileSelection(callback *tgbotapi.CallbackQuery) e
 	var title string
 	var tags string
 	
-	// Check if this is a photo without caption and multimodal analysis is supported
-	if strings.HasPrefix(content, "Photo: ") && b.shouldPerformMultimodalAnalysis(callback.Message.Chat.ID, user) {
+	// Use the analysis made during the upload (implemented in photo_analysis.go), else check if
+	// this is a photo without caption and multimodal analysis is supported
+	if analyzedTitle, analyzedTags, ok := b.takePhotoAnalysis(messageKey, content); ok {
+		title, tags = analyzedTitle, analyzedTags
+	} else if strings.HasPrefix(content, "Photo: ") && b.shouldPerformMultimodalAnalysis(callback.Message.Chat.ID, user) {
 		// Decode image data from base64
 		imageData, err := base64.StdEncoding.DecodeString(imageDataBase64)
 		if err != nil {
@@ -727,9 +738,11 @@ func (b *Bot) handlePhotoPinnedFileSelection(callback *tgbotapi.CallbackQuery) e
 	b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 80, "📝 Saving to GitHub...")
 
 	// Commit to GitHub with custom committer info and premium level
-	commitMsg := fmt.Sprintf("Add photo %s to %s via Telegram", title, selectedFile)
+	commitMsg := fmt.Sprintf("Add photo %s to %s via Telegram", b.commitTitle(callback.Message.Chat.ID, title), selectedFile)
 	committerInfo := b.getCommitterInfo(callback.Message.Chat.ID)
-	if err := userGitHubProvider.CommitFileWithAuthorAndPremium(selectedFile, formattedContent, commitMsg, committerInfo, premiumLevel); err != nil {
+	commitResult, err := userGitHubProvider.CommitFileWithResult(selectedFile, formattedContent, commitMsg, committerInfo, premiumLevel)
+	if err != nil {
+		b.recordRepoFailure(callback.Message.Chat.ID, err)
 		// Check if it's an authorization error and provide helpful message
 		if strings.Contains(err.Error(), "GitHub authorization failed") {
 			errorMsg := "❌ " + err.Error()
@@ -740,8 +753,9 @@ func (b *Bot) handlePhotoPinnedFileSelection(callback *tgbotapi.CallbackQuery) e
 			return nil
 		}
 
+		b.checkRepoMoved(callback.Message.Chat.ID, err)
 		// Generic error handling
-		errorMsg := "❌ Failed to save photo to GitHub: " + err.Error()
+		errorMsg := saveFailureText("Failed to save photo to GitHub", err)
 		editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 		if _, sendErr := b.rateLimitedSend(callback.Message.Chat.ID, editMsg); sendErr != nil {
 			b.sendResponse(callback.Message.Chat.ID, errorMsg)
@@ -750,7 +764,7 @@ func (b *Bot) handlePhotoPinnedFileSelection(callback *tgbotapi.CallbackQuery) e
 	}
 
 	// Clean up pending message
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
 
 	// Increment image and commit count
 	if b.db != nil {
@@ -783,6 +797,7 @@ func (b *Bot) handlePhotoPinnedFileSelection(callback *tgbotapi.CallbackQuery) e
 	// Success message with GitHub link
 	githubURL, err := userGitHubProvider.GetGitHubFileURLWithBranch(selectedFile)
 	successMsg := fmt.Sprintf("✅ Photo saved to pinned file: %s", selectedFile)
+	successMsg += b.recordCommitStats(callback.Message.Chat.ID, userGitHubProvider, commitResult)
 
 	// Create inline keyboard with GitHub link button
 	var keyboard *tgbotapi.InlineKeyboardMarkup
@@ -793,9 +808,7 @@ func (b *Bot) handlePhotoPinnedFileSelection(callback *tgbotapi.CallbackQuery) e
 		})
 		// No keyboard if URL generation fails
 	} else {
-		row := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("🔗 View on GitHub", githubURL),
-		)
+		row := commitLinkRow(githubURL, commitResult)
 		keyboardValue := tgbotapi.NewInlineKeyboardMarkup(row)
 		keyboard = &keyboardValue
 	}
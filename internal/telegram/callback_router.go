@@ -28,8 +28,8 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 		"callback_id":   callback.ID,
 	})
 
-	// Check for duplicate callback processing
-	if b.isDuplicateCallback(callback.ID) {
+	// Skip callbacks another delivery or instance already handles (implemented in shared_state.go)
+	if !b.claimCallback(callback.ID) {
 		logger.Debug("Duplicate callback detected, skipping", map[string]interface{}{
 			"callback_id":   callback.ID,
 			"callback_data": callback.Data,
@@ -40,9 +40,6 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 		return nil
 	}
 
-	// Mark callback as processed
-	b.markCallbackProcessed(callback.ID)
-
 	// Answer the callback query first
 	callbackConfig := tgbotapi.NewCallback(callback.ID, "")
 	if _, err := b.rateLimitedRequest(callback.Message.Chat.ID, callbackConfig); err != nil {
@@ -67,6 +64,14 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 		return b.handleCancel(callback)
 	}
 
+	if strings.HasPrefix(callback.Data, "msel_") {
+		return b.handleFileMultiSelectCallback(callback) // Implemented in multi_select.go
+	}
+
+	if strings.HasPrefix(callback.Data, "attach_") {
+		return b.handleAttachmentCallback(callback) // Implemented in attachments.go
+	}
+
 	if strings.HasPrefix(callback.Data, "todo_more_") {
 		return b.handleTodoMore(callback)
 	}
@@ -79,6 +84,42 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 		return b.handleTodoDone(callback)
 	}
 
+	// TODO list management (implemented in todos.go)
+	if strings.HasPrefix(callback.Data, "todo_tgl_") {
+		return b.handleTodoToggle(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "todo_up_") || strings.HasPrefix(callback.Data, "todo_down_") {
+		return b.handleTodoMove(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "todo_view_") {
+		return b.handleTodoView(callback)
+	}
+
+	// Todo ↔ issue links (implemented in todo_issue_links.go)
+	if strings.HasPrefix(callback.Data, "todo_issue_") {
+		return b.handleTodoToIssue(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "todo_keepissue_") {
+		return b.handleTodoKeepIssue(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "issue_todo_") {
+		return b.handleIssueToTodo(callback)
+	}
+
+	// Payment buttons do nothing on self-hosted deployments (implemented in self_hosted.go)
+	if b.cfg().PaymentsDisabled && isPaymentCallback(callback.Data) {
+		return b.sendPaymentsDisabled(callback.Message.Chat.ID)
+	}
+
+	// Buttons of features storing content do nothing under zero retention (implemented in retention.go)
+	if b.cfg().ZeroRetention() && isRetentionCallback(callback.Data) {
+		return b.sendRetentionDisabled(callback.Message.Chat.ID)
+	}
+
 	if strings.HasPrefix(callback.Data, "coffee_") {
 		return b.handleCoffeeCallback(callback)
 	}
@@ -87,10 +128,26 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 		return b.handleSubscriptionCallback(callback)
 	}
 
+	if callback.Data == "premium_trial_start" {
+		return b.handleStartTrialCallback(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "receipt_") {
+		return b.handleReceiptCallback(callback)
+	}
+
 	if strings.HasPrefix(callback.Data, "issue_open_") {
 		return b.handleIssueOpen(callback)
 	}
 
+	if strings.HasPrefix(callback.Data, "issue_thread_") || strings.HasPrefix(callback.Data, "issue_older_") {
+		return b.handleIssueThread(callback) // Implemented in issue_threads.go
+	}
+
+	if strings.HasPrefix(callback.Data, "canned_") {
+		return b.handleCannedReplyPick(callback) // Implemented in canned_replies.go
+	}
+
 	if strings.HasPrefix(callback.Data, "issue_comment_") {
 		return b.handleIssueComment(callback)
 	}
@@ -147,6 +204,26 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 		return b.handlePinFileAction(callback)
 	}
 
+	if strings.HasPrefix(callback.Data, "tmpl_") {
+		return b.handleFileTemplateCallback(callback) // Implemented in file_templates.go
+	}
+
+	if strings.HasPrefix(callback.Data, "trash_") {
+		return b.handleTrashCallback(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "browse_") {
+		return b.handleBrowseCallback(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "search_page_") {
+		return b.handleSearchCallback(callback) // Implemented in search.go
+	}
+
+	if strings.HasPrefix(callback.Data, "ilabel_") {
+		return b.handleIssueLabelCallback(callback) // Implemented in issue_labels.go
+	}
+
 	if callback.Data == "github_oauth" {
 		return b.handleGitHubOAuthPrivacyConfirmation(callback)
 	}
@@ -196,6 +273,54 @@ func (b *Bot) handleCallbackQuery(callback *tgbotapi.CallbackQuery) error {
 		return b.handleRepoSetCommitterCallback(callback)
 	}
 
+	if callback.Data == "repo_toggle_noreply" {
+		return b.handleRepoToggleNoreplyCallback(callback) // Implemented in commit_email.go
+	}
+
+	if callback.Data == "repo_toggle_bot_committer" {
+		return b.handleRepoToggleBotCommitterCallback(callback) // Implemented in commit_identity.go
+	}
+
+	if callback.Data == "repo_move_update" {
+		return b.handleRepoMoveUpdateCallback(callback)
+	}
+
+	if callback.Data == "repo_health_setup" {
+		return b.handleRepoHealthSetupCallback(callback)
+	}
+
+	if strings.HasPrefix(callback.Data, "bulk_help_") {
+		return b.handleBulkHelpCallback(callback) // Implemented in bulk.go
+	}
+
+	if strings.HasPrefix(callback.Data, "bulk_apply_") {
+		return b.handleBulkApplyCallback(callback) // Implemented in bulk.go
+	}
+
+	if strings.HasPrefix(callback.Data, "bulk_cancel_") {
+		return b.handleBulkCancelCallback(callback) // Implemented in bulk.go
+	}
+
+	if strings.HasPrefix(callback.Data, "compress_") {
+		return b.handleCompressCallback(callback) // Implemented in history_compression.go
+	}
+
+	if callback.Data == "leaderboard_consent" || callback.Data == "leaderboard_decline" {
+		return b.handleLeaderboardCallback(callback) // Implemented in leaderboard.go
+	}
+
+	if strings.HasPrefix(callback.Data, "backup_restore_") {
+		return b.handleBackupRestoreCallback(callback) // Implemented in db_backup.go
+	}
+
+	if strings.HasPrefix(callback.Data, "tenant_accept_") || strings.HasPrefix(callback.Data, "tenant_decline_") {
+		return b.handleTenantInviteCallback(callback) // Implemented in tenants.go
+	}
+
+	if callback.Data == "access_resume" {
+		return b.handleAccessResumeCallback(callback) // Implemented in access_anomalies.go
+	}
+
 	if callback.Data == "repo_revoke_auth" {
 		return b.handleRepoRevokeAuthCallback(callback)
 	}
@@ -78,33 +78,23 @@ func (b *Bot) handleTodoDone(callback *tgbotapi.CallbackQuery) error {
 	todos := b.parseTodoItems(todoContent)
 	var updatedLines []string
 	found := false
+	completedContent := ""
+	linkedIssue := 0
 	currentChatID := callback.Message.Chat.ID
 
 	for _, todo := range todos {
 		// Only allow marking TODOs done if they belong to the current chat (or old format with ChatID 0)
 		if todo.MessageID == messageID && !todo.Done && (todo.ChatID == currentChatID || todo.ChatID == 0) {
-			// Mark as done: change - [ ] to - [x]
-			// Use the new HTML comment format
-			line := fmt.Sprintf("- [x] <!--[%d] [%d]--> %s (%s)", todo.MessageID, currentChatID, todo.Content, todo.Date)
-			updatedLines = append(updatedLines, line)
+			// Mark as done: change - [ ] to - [x], using the new HTML comment format
+			todo.Done = true
+			todo.ChatID = currentChatID
+			updatedLines = append(updatedLines, formatTodoLine(todo))
 			found = true
+			completedContent = todo.Content
+			linkedIssue = todo.IssueNumber
 		} else {
 			// Keep original format (preserve whatever format it was in)
-			checkbox := "[ ]"
-			if todo.Done {
-				checkbox = "[x]"
-			}
-
-			var line string
-			if todo.ChatID != 0 {
-				// Check if this looks like it came from HTML comment format or old bracket format
-				// For new items, use HTML comment format
-				line = fmt.Sprintf("- %s <!--[%d] [%d]--> %s (%s)", checkbox, todo.MessageID, todo.ChatID, todo.Content, todo.Date)
-			} else {
-				// Old format without chat ID - keep as is for backward compatibility
-				line = fmt.Sprintf("- %s [%d] %s (%s)", checkbox, todo.MessageID, todo.Content, todo.Date)
-			}
-			updatedLines = append(updatedLines, line)
+			updatedLines = append(updatedLines, formatTodoLine(todo))
 		}
 	}
 
@@ -144,6 +134,8 @@ func (b *Bot) handleTodoDone(callback *tgbotapi.CallbackQuery) error {
 	// Show completion progress
 	b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 100, "✅ TODO marked as completed!")
 
+	b.todoCompleted(callback.Message.Chat.ID, userGitHubProvider, TodoItem{MessageID: messageID, Content: completedContent, IssueNumber: linkedIssue})
+
 	// Small delay to show completion before refreshing
 	time.Sleep(500 * time.Millisecond)
 
@@ -0,0 +1,246 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Canned replies: reusable issue comments managed with /canned and offered as one-tap
+// buttons whenever the user comments on an issue from Telegram
+
+const (
+	maxCannedRepliesPerUser = 20
+	maxCannedReplyLength    = 4000
+)
+
+var cannedReplyNameRegex = regexp.MustCompile(`^[\p{L}\p{N}_-]{1,32}$`)
+
+// parseCannedAdd splits "<name> <text>" into the reply name and its (possibly multi-line) text
+func parseCannedAdd(args string) (string, string) {
+	args = strings.TrimSpace(args)
+	idx := strings.IndexAny(args, " \n")
+	if idx < 0 {
+		return args, ""
+	}
+	return args[:idx], strings.TrimSpace(args[idx+1:])
+}
+
+// cannedReplyPreview returns the first line of a reply, shortened for the /canned list
+func cannedReplyPreview(body string) string {
+	lines := strings.SplitN(body, "\n", 2)
+	preview, cut := truncateForPreview(lines[0], 80)
+	if cut || len(lines) > 1 {
+		preview += "…"
+	}
+	return preview
+}
+
+// handleCannedCommand manages canned replies:
+// /canned, /canned add <name> <text>, /canned remove <name>
+func (b *Bot) handleCannedCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/canned"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Canned replies require a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if args == "" {
+		return b.showCannedReplies(chatID)
+	}
+
+	action, rest := parseCannedAdd(args)
+	switch action {
+	case "add":
+		name, body := parseCannedAdd(rest)
+		if name == "" || body == "" {
+			b.sendResponse(chatID, "Usage: <code>/canned add &lt;name&gt; &lt;text&gt;</code>")
+			return nil
+		}
+		return b.addCannedReply(chatID, name, body)
+	case "remove":
+		if rest == "" {
+			b.sendResponse(chatID, "Usage: <code>/canned remove &lt;name&gt;</code>")
+			return nil
+		}
+		if err := b.db.DeleteCannedReply(chatID, rest); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("🗑 Canned reply <b>%s</b> removed.", html.EscapeString(rest)))
+		return nil
+	default:
+		b.sendResponse(chatID, fmt.Sprintf("❌ Unknown canned action: %s", html.EscapeString(action)))
+		return nil
+	}
+}
+
+func (b *Bot) showCannedReplies(chatID int64) error {
+	replies, err := b.db.GetCannedReplies(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load canned replies: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("💬 <b>Canned Replies</b>\n")
+	if len(replies) == 0 {
+		sb.WriteString("\nNo canned replies yet.\n")
+	}
+	for _, reply := range replies {
+		sb.WriteString(fmt.Sprintf("\n<b>%s</b>\n  %s\n", html.EscapeString(reply.Name), html.EscapeString(cannedReplyPreview(reply.Body))))
+	}
+
+	sb.WriteString(`
+Canned replies show up as buttons when you comment on an issue.
+
+• /canned add &lt;name&gt; &lt;text&gt; - Save a reply, e.g. <code>/canned add fixed Fixed in the next release.</code>
+• /canned remove &lt;name&gt; - Delete a reply`)
+
+	b.sendResponse(chatID, sb.String())
+	return nil
+}
+
+func (b *Bot) addCannedReply(chatID int64, name, body string) error {
+	if !cannedReplyNameRegex.MatchString(name) {
+		b.sendResponse(chatID, "❌ Names are up to 32 letters, digits, - or _.")
+		return nil
+	}
+	if len(body) > maxCannedReplyLength {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Canned replies can be up to %d characters.", maxCannedReplyLength))
+		return nil
+	}
+
+	existing, err := b.db.GetCannedReplies(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load canned replies: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	replacing := false
+	for _, reply := range existing {
+		replacing = replacing || reply.Name == name
+	}
+	if !replacing && len(existing) >= maxCannedRepliesPerUser {
+		b.sendResponse(chatID, fmt.Sprintf("❌ You can save up to %d canned replies. Remove one first.", maxCannedRepliesPerUser))
+		return nil
+	}
+
+	if _, err := b.db.SaveCannedReply(chatID, name, body); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to save canned reply: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("💬 Canned reply <b>%s</b> saved.", html.EscapeString(name)))
+	return nil
+}
+
+// sendCannedReplyPicker offers the user's canned replies as buttons for commenting on an issue
+func (b *Bot) sendCannedReplyPicker(chatID int64, issueNumber int) {
+	if b.db == nil || b.cfg().ZeroRetention() {
+		return
+	}
+
+	replies, err := b.db.GetCannedReplies(chatID)
+	if err != nil || len(replies) == 0 {
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(replies); i += 2 {
+		var row []tgbotapi.InlineKeyboardButton
+		for _, reply := range replies[i:min(i+2, len(replies))] {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData("💬 "+reply.Name, fmt.Sprintf("canned_%d_%d", issueNumber, reply.ID)))
+		}
+		rows = append(rows, row)
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("⚡ Or pick a canned reply for issue #%d:", issueNumber))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(rows...)
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		logger.Error("Failed to send canned reply picker", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+		})
+	}
+}
+
+// handleCannedReplyPick comments a canned reply on an issue (callback data: canned_<issue>_<reply id>)
+func (b *Bot) handleCannedReplyPick(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	parts := strings.Split(callback.Data, "_")
+	if len(parts) != 3 {
+		return fmt.Errorf("invalid callback data format")
+	}
+	issueNumber, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("invalid issue number: %w", err)
+	}
+	replyID, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid canned reply id: %w", err)
+	}
+
+	if b.db == nil {
+		return fmt.Errorf("database not configured")
+	}
+	reply, err := b.db.GetCannedReply(replyID, chatID)
+	if err != nil {
+		return err
+	}
+	if reply == nil {
+		b.editMessage(chatID, messageID, "❌ This canned reply no longer exists. See /canned")
+		return nil
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.editMessage(chatID, messageID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	b.editMessage(chatID, messageID, fmt.Sprintf("🔄 Adding \"%s\" to issue #%d...", reply.Name, issueNumber))
+
+	commentURL, err := userGitHubProvider.AddIssueComment(issueNumber, b.resolveMentions(chatID, reply.Body))
+	if err != nil {
+		logger.Error("Failed to add canned reply to GitHub issue", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+			"chat_id":      chatID,
+		})
+		b.editMessage(chatID, messageID, fmt.Sprintf("❌ Failed to add comment to issue #%d: %v", issueNumber, err))
+		return nil
+	}
+
+	if err := b.db.IncrementIssueCommentCount(chatID); err != nil {
+		logger.Error("Failed to increment issue comment count", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	}
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf("✅ Comment \"%s\" added to issue #%d successfully!", reply.Name, issueNumber))
+	if commentURL != "" {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("💬 View Comment", commentURL),
+		))
+		editMsg.ReplyMarkup = &keyboard
+	}
+	if _, err := b.rateLimitedSend(chatID, editMsg); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("✅ Comment added to issue #%d successfully!", issueNumber))
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package telegram
+
+import "testing"
+
+func TestParseCannedAdd(t *testing.T) {
+	tests := []struct {
+		input, name, body string
+	}{
+		{"fixed Fixed in the next release.", "fixed", "Fixed in the next release."},
+		{"repro\nCould you share steps?\nThanks!", "repro", "Could you share steps?\nThanks!"},
+		{"  lonely  ", "lonely", ""},
+		{"", "", ""},
+	}
+
+	for _, tt := range tests {
+		name, body := parseCannedAdd(tt.input)
+		if name != tt.name || body != tt.body {
+			t.Errorf("parseCannedAdd(%q) = %q, %q, want %q, %q", tt.input, name, body, tt.name, tt.body)
+		}
+	}
+}
+
+func TestCannedReplyPreview(t *testing.T) {
+	if got := cannedReplyPreview("Thanks!"); got != "Thanks!" {
+		t.Errorf("cannedReplyPreview() = %q", got)
+	}
+	if got := cannedReplyPreview("Thanks!\nSecond line"); got != "Thanks!…" {
+		t.Errorf("cannedReplyPreview(multi-line) = %q", got)
+	}
+}
+
+func TestCannedReplyNames(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"needs-repro":                       true,
+		"fixed_v2":                          true,
+		"две":                               true,
+		"has space":                         false,
+		"":                                  false,
+		"<b>bold</b>":                       false,
+		"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa": false,
+	} {
+		if got := cannedReplyNameRegex.MatchString(name); got != valid {
+			t.Errorf("name %q valid = %v, want %v", name, got, valid)
+		}
+	}
+}
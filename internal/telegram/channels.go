@@ -0,0 +1,403 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Channel ingestion: the bot is an admin of a user's channel and commits every channel post
+// to the file or directory the owner routed the channel to, turning the channel into a log
+
+// maxChannelRoutesPerUser caps how many channels one user can route
+const maxChannelRoutesPerUser = 10
+
+// isBotRelayedPost reports whether a post was sent via an inline bot or forwarded from a bot,
+// so bots posting into each other's channels can't feed the repository in a loop
+func isBotRelayedPost(post *tgbotapi.Message) bool {
+	return post.ViaBot != nil || (post.ForwardFrom != nil && post.ForwardFrom.IsBot)
+}
+
+// channelPostText returns the text or caption of a post plus a line for media that isn't a photo
+func channelPostText(post *tgbotapi.Message) string {
+	text := post.Text
+	if text == "" {
+		text = post.Caption
+	}
+
+	var media string
+	switch {
+	case post.Document != nil:
+		media = "📎 File: " + post.Document.FileName
+	case post.Video != nil:
+		media = "🎬 Video"
+	case post.Audio != nil:
+		media = "🎵 Audio: " + post.Audio.Title
+	case post.Voice != nil:
+		media = "🎤 Voice message"
+	}
+
+	if media == "" {
+		return strings.TrimSpace(text)
+	}
+	return strings.TrimSpace(text + "\n\n" + strings.TrimSpace(media))
+}
+
+// channelPostLink returns the t.me link of a post in a public channel, "" for private channels
+func channelPostLink(post *tgbotapi.Message) string {
+	if post.Chat == nil || post.Chat.UserName == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://t.me/%s/%d", post.Chat.UserName, post.MessageID)
+}
+
+// channelPostFile returns the file a post is committed to: the route's file, or one file
+// per post named after its date and message ID when the route is a directory
+func channelPostFile(route *database.ChannelRoute, post *tgbotapi.Message) string {
+	if !strings.HasSuffix(route.TargetPath, "/") {
+		return route.TargetPath
+	}
+	date := time.Unix(int64(post.Date), 0).Format("2006-01-02")
+	return path.Join(route.TargetPath, fmt.Sprintf("%s-%d.md", date, post.MessageID))
+}
+
+// normalizeChannelTarget validates a route target, keeping the trailing "/" of directories
+// and adding .md to files without an extension
+func normalizeChannelTarget(target string) (string, error) {
+	isDir := strings.HasSuffix(strings.TrimSpace(target), "/")
+	cleaned, err := validateRepoPath(target)
+	if err != nil {
+		return "", err
+	}
+	if cleaned == "" {
+		return "", fmt.Errorf("please provide a file or directory, e.g. channel.md or channel/")
+	}
+	if isDir {
+		return cleaned + "/", nil
+	}
+	if cleaned == "issue.md" || cleaned == "todo.md" {
+		return "", fmt.Errorf("channel posts can't be committed to %s", cleaned)
+	}
+	if path.Ext(cleaned) == "" {
+		cleaned += ".md"
+	}
+	return cleaned, nil
+}
+
+// handleChannelPost commits a post of a routed channel. Errors are reported to the route owner,
+// never to the channel itself.
+func (b *Bot) handleChannelPost(post *tgbotapi.Message) error {
+	if b.db == nil {
+		return nil
+	}
+
+	route, err := b.db.GetChannelRoute(post.Chat.ID)
+	if err != nil {
+		logger.Error("Failed to get channel route", map[string]interface{}{
+			"channel_id": post.Chat.ID,
+			"error":      err.Error(),
+		})
+		return nil
+	}
+	if route == nil {
+		logger.Debug("Ignoring post of unrouted channel", map[string]interface{}{
+			"channel_id": post.Chat.ID,
+		})
+		return nil
+	}
+
+	if route.SkipBots && isBotRelayedPost(post) {
+		logger.Info("Skipping channel post relayed by a bot", map[string]interface{}{
+			"channel_id": post.Chat.ID,
+			"message_id": post.MessageID,
+		})
+		return nil
+	}
+
+	if err := b.commitChannelPost(route, post); err != nil {
+		logger.Error("Failed to commit channel post", map[string]interface{}{
+			"chat_id":    route.ChatID,
+			"channel_id": route.ChannelID,
+			"message_id": post.MessageID,
+			"error":      err.Error(),
+		})
+		if errors.Is(err, github.ErrPushQueued) {
+			b.sendResponse(route.ChatID, saveFailureText("", err))
+			return nil
+		}
+		b.sendResponse(route.ChatID, fmt.Sprintf("❌ Failed to save a post of <b>%s</b>: %s",
+			html.EscapeString(route.Title), html.EscapeString(err.Error())))
+	}
+	return nil
+}
+
+// commitChannelPost formats a post like a note, uploading its photo, and commits it for the route owner
+func (b *Bot) commitChannelPost(route *database.ChannelRoute, post *tgbotapi.Message) error {
+	chatID := route.ChatID
+	content := channelPostText(post)
+	if content == "" && len(post.Photo) == 0 {
+		return nil // service messages such as pinned posts or title changes
+	}
+
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return err
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	if err := provider.EnsureRepositoryWithPremium(premiumLevel); err != nil {
+		return fmt.Errorf("repository not available: %v", err)
+	}
+
+	title := b.generateTitleFromContent(content)
+	if len(post.Photo) > 0 {
+		photoURL, err := b.uploadChannelPhoto(chatID, provider, post.Photo[len(post.Photo)-1].FileID, premiumLevel)
+		if err != nil {
+			return err
+		}
+		if content == "" {
+			title = "Photo"
+		}
+		content = strings.TrimSpace(fmt.Sprintf("![Photo](%s)\n\n%s", photoURL, content))
+	}
+	if link := channelPostLink(post); link != "" {
+		content += fmt.Sprintf("\n\n[View post](%s)", link)
+	}
+
+	filename := channelPostFile(route, post)
+	// Notes are the owner's, encrypted with their key. The source footer would link the post ID in the
+	// owner's chat, public posts link themselves above.
+	formattedContent := b.formatNoteContent(content, filename, post.MessageID, chatID, title, "", false)
+	commitMsg := fmt.Sprintf("Add %s to %s via channel %s", b.commitTitle(chatID, title), filename, route.Title)
+	result, err := provider.CommitFileWithResult(filename, formattedContent, commitMsg, b.getCommitterInfo(chatID), premiumLevel)
+	if err != nil {
+		return err
+	}
+
+	if err := b.db.IncrementCommitCount(chatID); err != nil {
+		logger.Error("Failed to increment commit count", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	}
+	b.recordCommitStats(chatID, provider, result)
+
+	logger.Info("Committed channel post", map[string]interface{}{
+		"chat_id":    chatID,
+		"channel_id": route.ChannelID,
+		"message_id": post.MessageID,
+		"filename":   filename,
+	})
+	return nil
+}
+
+// uploadChannelPhoto uploads a channel photo to the owner's CDN, within the owner's image limit
+func (b *Bot) uploadChannelPhoto(chatID int64, provider github.GitHubProvider, fileID string, premiumLevel int) (string, error) {
+	if images, err := b.usageLimits().Images(chatID, premiumLevel); err == nil && !images.Allowed {
+		return "", fmt.Errorf("image limit reached (%d/%d)", images.Current, images.Max)
+	}
+
+	photoData, filename, err := b.downloadPhoto(chatID, fileID)
+	if err != nil {
+		return "", err
+	}
+
+	photoURL, reused, err := b.uploadPhoto(chatID, provider, b.generateUniquePhotoFilename(filename), photoData)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload photo: %w", err)
+	}
+	if reused {
+		return photoURL, nil
+	}
+
+	if err := b.db.IncrementImageCount(chatID); err != nil {
+		logger.Error("Failed to increment image count", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	}
+	if err := b.db.IncrementUsageImageCount(chatID); err != nil {
+		logger.Error("Failed to increment usage image count", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	}
+	return photoURL, nil
+}
+
+// handleChannelCommand manages channel routes:
+// /channel, /channel add <@channel|id> <file|dir/>, /channel bots <id> on|off, /channel remove <id>
+func (b *Bot) handleChannelCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/channel"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Channel ingestion requires a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if len(args) == 0 {
+		return b.showChannelRoutes(chatID)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 3 {
+			b.sendResponse(chatID, "Usage: <code>/channel add &lt;@channel|channel id&gt; &lt;file.md|directory/&gt;</code>")
+			return nil
+		}
+		return b.addChannelRoute(message, args[1], args[2])
+	case "bots":
+		if len(args) < 3 || (args[2] != "on" && args[2] != "off") {
+			b.sendResponse(chatID, "Usage: <code>/channel bots &lt;id&gt; on|off</code>")
+			return nil
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid channel route id: %s", html.EscapeString(args[1])))
+			return nil
+		}
+		// "on" lets posts relayed by bots through, the route skips them by default
+		if err := b.db.UpdateChannelRouteSkipBots(id, chatID, args[2] == "off"); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if args[2] == "on" {
+			b.sendResponse(chatID, fmt.Sprintf("🤖 Channel <b>#%d</b> now also saves posts sent via or forwarded from bots.", id))
+		} else {
+			b.sendResponse(chatID, fmt.Sprintf("🤖 Channel <b>#%d</b> now skips posts sent via or forwarded from bots.", id))
+		}
+		return nil
+	case "remove":
+		if len(args) < 2 {
+			b.sendResponse(chatID, "Usage: <code>/channel remove &lt;id&gt;</code>")
+			return nil
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid channel route id: %s", html.EscapeString(args[1])))
+			return nil
+		}
+		if err := b.db.DeleteChannelRoute(id, chatID); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("🗑 Channel <b>#%d</b> is no longer saved.", id))
+		return nil
+	default:
+		b.sendResponse(chatID, fmt.Sprintf("❌ Unknown channel action: %s", html.EscapeString(args[0])))
+		return nil
+	}
+}
+
+func (b *Bot) showChannelRoutes(chatID int64) error {
+	routes, err := b.db.GetChannelRoutes(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load channels: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📣 <b>Channel Ingestion</b>\n")
+	if len(routes) == 0 {
+		sb.WriteString("\nNo channels yet.\n")
+	}
+	for _, route := range routes {
+		bots := ""
+		if !route.SkipBots {
+			bots = " · 🤖 includes bot posts"
+		}
+		sb.WriteString(fmt.Sprintf("\n<b>#%d</b> %s%s\n  → <code>%s</code>\n", route.ID, html.EscapeString(route.Title), bots, html.EscapeString(route.TargetPath)))
+	}
+
+	sb.WriteString(`
+Add me as an admin of your channel, then route it to a file (every post is added to it) or a directory ending in / (one file per post).
+
+• /channel add @mychannel channel.md - Save every post to a file
+• /channel add @mychannel journal/ - Save each post as its own file
+• /channel bots &lt;id&gt; on|off - Also save posts relayed by bots
+• /channel remove &lt;id&gt; - Stop saving a channel`)
+
+	b.sendResponse(chatID, sb.String())
+	return nil
+}
+
+func (b *Bot) addChannelRoute(message *tgbotapi.Message, channelRef, target string) error {
+	chatID := message.Chat.ID
+
+	targetPath, err := normalizeChannelTarget(target)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	chatConfig := tgbotapi.ChatConfig{SuperGroupUsername: channelRef}
+	if id, err := strconv.ParseInt(channelRef, 10, 64); err == nil {
+		chatConfig = tgbotapi.ChatConfig{ChatID: id}
+	} else if !strings.HasPrefix(channelRef, "@") {
+		chatConfig.SuperGroupUsername = "@" + channelRef
+	}
+
+	channel, err := b.api.GetChat(tgbotapi.ChatInfoConfig{ChatConfig: chatConfig})
+	if err != nil || !channel.IsChannel() {
+		b.sendResponse(chatID, "❌ Channel not found. Add me as an admin of the channel first.")
+		return nil
+	}
+
+	// Only the channel's admins may route it, and the bot must be an admin to receive posts
+	for _, userID := range []int64{message.From.ID, b.api.Self.ID} {
+		member, err := b.api.GetChatMember(tgbotapi.GetChatMemberConfig{ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: channel.ID, UserID: userID}})
+		if err != nil || !(member.IsAdministrator() || member.IsCreator()) {
+			if userID == b.api.Self.ID {
+				b.sendResponse(chatID, "❌ I'm not an admin of this channel yet. Add me as an admin so I receive its posts.")
+			} else {
+				b.sendResponse(chatID, "❌ Only admins of the channel can save its posts.")
+			}
+			return nil
+		}
+	}
+
+	routes, err := b.db.GetChannelRoutes(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load channels: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	existing := false
+	for _, route := range routes {
+		existing = existing || route.ChannelID == channel.ID
+	}
+	if !existing && len(routes) >= maxChannelRoutesPerUser {
+		b.sendResponse(chatID, fmt.Sprintf("❌ You can save up to %d channels. Remove one first.", maxChannelRoutesPerUser))
+		return nil
+	}
+
+	route, err := b.db.SaveChannelRoute(chatID, channel.ID, channel.Title, targetPath)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	logger.Info("Channel route saved", map[string]interface{}{
+		"chat_id":     chatID,
+		"channel_id":  route.ChannelID,
+		"target_path": route.TargetPath,
+	})
+
+	b.sendResponse(chatID, fmt.Sprintf("📣 Posts of <b>%s</b> are now saved to <code>%s</code> (channel <b>#%d</b>).",
+		html.EscapeString(route.Title), html.EscapeString(route.TargetPath), route.ID))
+	return nil
+}
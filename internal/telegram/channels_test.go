@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestIsBotRelayedPost(t *testing.T) {
+	tests := []struct {
+		name string
+		post *tgbotapi.Message
+		want bool
+	}{
+		{"plain post", &tgbotapi.Message{Text: "hello"}, false},
+		{"via inline bot", &tgbotapi.Message{ViaBot: &tgbotapi.User{IsBot: true}}, true},
+		{"forwarded from bot", &tgbotapi.Message{ForwardFrom: &tgbotapi.User{IsBot: true}}, true},
+		{"forwarded from user", &tgbotapi.Message{ForwardFrom: &tgbotapi.User{}}, false},
+	}
+
+	for _, tt := range tests {
+		if got := isBotRelayedPost(tt.post); got != tt.want {
+			t.Errorf("%s: isBotRelayedPost() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChannelPostText(t *testing.T) {
+	post := &tgbotapi.Message{Caption: "Release notes", Document: &tgbotapi.Document{FileName: "notes.pdf"}}
+	if got, want := channelPostText(post), "Release notes\n\n📎 File: notes.pdf"; got != want {
+		t.Errorf("channelPostText() = %q, want %q", got, want)
+	}
+
+	if got := channelPostText(&tgbotapi.Message{Text: " hi "}); got != "hi" {
+		t.Errorf("channelPostText() = %q, want %q", got, "hi")
+	}
+}
+
+func TestChannelPostFile(t *testing.T) {
+	date := time.Date(2026, 10, 15, 12, 0, 0, 0, time.Local)
+	post := &tgbotapi.Message{MessageID: 42, Date: int(date.Unix())}
+
+	if got := channelPostFile(&database.ChannelRoute{TargetPath: "channel.md"}, post); got != "channel.md" {
+		t.Errorf("channelPostFile(file) = %q", got)
+	}
+	if got := channelPostFile(&database.ChannelRoute{TargetPath: "journal/"}, post); got != "journal/2026-10-15-42.md" {
+		t.Errorf("channelPostFile(directory) = %q", got)
+	}
+}
+
+func TestNormalizeChannelTarget(t *testing.T) {
+	tests := map[string]string{
+		"channel":        "channel.md",
+		"logs/feed.txt":  "logs/feed.txt",
+		"journal/":       "journal/",
+		"/a/b/":          "a/b/",
+		"issue.md":       "",
+		"../outside.md":  "",
+		"a/.git/config/": "",
+	}
+
+	for input, want := range tests {
+		got, err := normalizeChannelTarget(input)
+		if want == "" {
+			if err == nil {
+				t.Errorf("normalizeChannelTarget(%q) = %q, want error", input, got)
+			}
+			continue
+		}
+		if err != nil || got != want {
+			t.Errorf("normalizeChannelTarget(%q) = %q, %v, want %q", input, got, err, want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Chat access: operators of private deployments choose which chats the bot serves. Setting
+// ALLOWED_CHAT_IDS makes the bot private and BLOCKED_CHAT_IDS refuses chats outright. Rules added
+// with /admin access are stored in the database and take precedence over the config; an allow rule
+// makes the bot private like ALLOWED_CHAT_IDS does. Admins are always served. Updates of other
+// chats are dropped at intake, before they reach the worker pool, and scheduled jobs (digests,
+// pins, reminders, reports) skip them.
+
+const (
+	chatAccessCacheKey       = "chat_access_rules"
+	chatAccessCacheExpiry    = 1 * time.Minute
+	chatAccessNoticeInterval = 6 * time.Hour
+)
+
+const chatAccessDeniedMessage = `🔒 Sorry, this msg2git bot is private and only serves chats its operator allowed.
+
+You're welcome to use the public bot @Msg2GitBot, or to run your own: https://github.com/msg2git/msg2git`
+
+// getChatAccessRules returns the runtime access rules by chat, cached briefly as they are
+// checked for every update. If the database fails, the rules loaded last are kept; ok is false
+// if there are none, since which chats are allowed is unknown then.
+func (b *Bot) getChatAccessRules() (map[int64]string, bool) {
+	if b.db == nil {
+		return nil, true
+	}
+
+	if cached, ok := b.cache.Get(chatAccessCacheKey); ok {
+		if ruleMap, ok := cached.(map[int64]string); ok {
+			return ruleMap, true
+		}
+	}
+
+	rules, err := b.db.GetChatAccessRules()
+	if err != nil {
+		logger.Warn("Failed to load chat access rules", map[string]interface{}{
+			"error": err.Error(),
+		})
+		if last := b.lastChatAccessRules.Load(); last != nil {
+			return *last, true
+		}
+		return nil, false
+	}
+
+	ruleMap := make(map[int64]string, len(rules))
+	for _, rule := range rules {
+		ruleMap[rule.ChatID] = rule.Access
+	}
+
+	b.cache.SetWithExpiry(chatAccessCacheKey, ruleMap, chatAccessCacheExpiry)
+	b.lastChatAccessRules.Store(&ruleMap)
+	return ruleMap, true
+}
+
+// chatAllowed reports whether the bot serves chatID. Without access rules to check, only admins are.
+func (b *Bot) chatAllowed(chatID int64) bool {
+	if b.cfg().IsAdmin(chatID) {
+		return true
+	}
+
+	rules, ok := b.getChatAccessRules()
+	if !ok {
+		return false
+	}
+	if access, ok := rules[chatID]; ok {
+		return access == database.ChatAccessAllow
+	}
+	if containsChatID(b.cfg().BlockedChatIDs, chatID) {
+		return false
+	}
+
+	private := len(b.cfg().AllowedChatIDs) > 0
+	for _, access := range rules {
+		if access == database.ChatAccessAllow {
+			private = true
+			break
+		}
+	}
+	return !private || containsChatID(b.cfg().AllowedChatIDs, chatID)
+}
+
+// refuseUpdate drops the update if the bot doesn't serve its chat, telling the chat why at most
+// every chatAccessNoticeInterval. Returns whether the update was refused.
+func (b *Bot) refuseUpdate(update tgbotapi.Update) bool {
+	var chatID int64
+	switch {
+	case update.CallbackQuery != nil && update.CallbackQuery.Message != nil:
+		chatID = update.CallbackQuery.Message.Chat.ID
+	case update.ChannelPost != nil:
+		chatID = update.ChannelPost.Chat.ID
+	case update.Message != nil:
+		chatID = update.Message.Chat.ID
+	default:
+		return false
+	}
+
+	if b.chatAllowed(chatID) {
+		return false
+	}
+
+	logger.Info("Refused update from chat without access", map[string]interface{}{
+		"chat_id":   chatID,
+		"update_id": update.UpdateID,
+	})
+
+	switch {
+	case update.CallbackQuery != nil:
+		answer := tgbotapi.NewCallback(update.CallbackQuery.ID, "🔒 This bot is private.")
+		if _, err := b.rateLimitedRequest(chatID, answer); err != nil {
+			logger.Warn("Failed to answer refused callback", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	case update.Message != nil:
+		// Channels get no notice, posting to them would publish it
+		noticeKey := fmt.Sprintf("chat_access_notice_%d", chatID)
+		if _, noticed := b.cache.Get(noticeKey); !noticed {
+			b.cache.SetWithExpiry(noticeKey, true, chatAccessNoticeInterval)
+			msg := tgbotapi.NewMessage(chatID, chatAccessDeniedMessage)
+			msg.DisableWebPagePreview = true
+			if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+				logger.Warn("Failed to send access notice", map[string]interface{}{
+					"chat_id": chatID,
+					"error":   err.Error(),
+				})
+			}
+		}
+	}
+
+	return true
+}
+
+// handleAdminAccessCommand lists and changes chat access rules:
+// /admin access
+// /admin access allow|block|remove <chat_id>
+func (b *Bot) handleAdminAccessCommand(message *tgbotapi.Message, args []string) error {
+	chatID := message.Chat.ID
+	usage := "Usage: <code>/admin access allow|block|remove &lt;chat_id&gt;</code>"
+
+	if len(args) == 0 {
+		b.sendResponse(chatID, b.formatChatAccess())
+		return nil
+	}
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Changing chat access at runtime requires a database. Use ALLOWED_CHAT_IDS and BLOCKED_CHAT_IDS instead.")
+		return nil
+	}
+	if len(args) < 2 {
+		b.sendResponse(chatID, usage)
+		return nil
+	}
+
+	target, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Invalid chat ID: %s", html.EscapeString(args[1])))
+		return nil
+	}
+
+	var reply string
+	switch args[0] {
+	case database.ChatAccessAllow, database.ChatAccessBlock:
+		if args[0] == database.ChatAccessBlock && b.cfg().IsAdmin(target) {
+			b.sendResponse(chatID, "❌ Admins can't be blocked, remove them from ADMIN_CHAT_IDS first.")
+			return nil
+		}
+		if err := b.db.SetChatAccess(target, args[0], chatID); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		reply = fmt.Sprintf("✅ Chat <code>%d</code> is now %sed.", target, args[0])
+	case "remove":
+		removed, err := b.db.DeleteChatAccess(target)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if !removed {
+			b.sendResponse(chatID, fmt.Sprintf("ℹ️ Chat <code>%d</code> has no runtime rule. Rules from ALLOWED_CHAT_IDS and BLOCKED_CHAT_IDS are changed in the config.", target))
+			return nil
+		}
+		reply = fmt.Sprintf("🗑 Rule for chat <code>%d</code> removed.", target)
+	default:
+		b.sendResponse(chatID, usage)
+		return nil
+	}
+	b.cache.Delete(chatAccessCacheKey)
+
+	logger.Info("Chat access changed by admin", map[string]interface{}{
+		"admin_chat_id": chatID,
+		"chat_id":       target,
+		"action":        args[0],
+	})
+
+	b.sendResponse(chatID, reply+"\n\n"+b.formatChatAccess())
+	return nil
+}
+
+// formatChatAccess describes who the bot serves
+func (b *Bot) formatChatAccess() string {
+	var allowed, blocked []string
+	for _, id := range b.cfg().AllowedChatIDs {
+		allowed = append(allowed, fmt.Sprintf("<code>%d</code> (config)", id))
+	}
+	for _, id := range b.cfg().BlockedChatIDs {
+		blocked = append(blocked, fmt.Sprintf("<code>%d</code> (config)", id))
+	}
+
+	private := len(b.cfg().AllowedChatIDs) > 0
+	if b.db != nil {
+		rules, err := b.db.GetChatAccessRules()
+		if err != nil {
+			return fmt.Sprintf("❌ Failed to load chat access rules: %s", html.EscapeString(err.Error()))
+		}
+		for _, rule := range rules {
+			entry := fmt.Sprintf("<code>%d</code> (since %s)", rule.ChatID, rule.UpdatedAt.Format("2006-01-02"))
+			if rule.Access == database.ChatAccessAllow {
+				allowed = append(allowed, entry)
+				private = true
+			} else {
+				blocked = append(blocked, entry)
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🔒 <b>Chat Access</b>\n\n")
+	if private {
+		sb.WriteString("The bot is <b>private</b>: only admins and allowed chats are served.\n")
+	} else {
+		sb.WriteString("The bot is <b>public</b>: every chat that isn't blocked is served.\n")
+	}
+	if len(allowed) > 0 {
+		sb.WriteString("\n<b>Allowed:</b>\n• " + strings.Join(allowed, "\n• ") + "\n")
+	}
+	if len(blocked) > 0 {
+		sb.WriteString("\n<b>Blocked:</b>\n• " + strings.Join(blocked, "\n• ") + "\n")
+	}
+	sb.WriteString("\n<i>/admin access allow|block|remove &lt;chat_id&gt; changes the rules.</i>")
+	return sb.String()
+}
+
+func containsChatID(ids []int64, id int64) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestChatAllowed(t *testing.T) {
+	bot, _ := newFakeBot(t)
+
+	if !bot.chatAllowed(1) {
+		t.Error("Expected every chat to be served without access lists")
+	}
+
+	bot.config.AdminChatIDs = []int64{1}
+	bot.config.AllowedChatIDs = []int64{2, 3}
+	bot.config.BlockedChatIDs = []int64{3, 4}
+
+	tests := map[int64]bool{
+		1: true,  // admin
+		2: true,  // allowed
+		3: false, // allowed and blocked
+		4: false, // blocked
+		5: false, // not allowed
+	}
+	for chatID, want := range tests {
+		if got := bot.chatAllowed(chatID); got != want {
+			t.Errorf("chatAllowed(%d) = %v, want %v", chatID, got, want)
+		}
+	}
+
+	bot.config.AllowedChatIDs = nil
+	if !bot.chatAllowed(5) || bot.chatAllowed(4) {
+		t.Error("Expected only blocked chats to be refused without an allowlist")
+	}
+}
+
+func TestRefuseUpdate(t *testing.T) {
+	bot, fake := newFakeBot(t)
+	bot.config.AllowedChatIDs = []int64{1}
+
+	if bot.refuseUpdate(tgbotapi.Update{Message: commandMessage(1, "hello")}) {
+		t.Fatal("Expected the allowed chat's message to pass")
+	}
+
+	for i := 0; i < 2; i++ {
+		if !bot.refuseUpdate(tgbotapi.Update{Message: commandMessage(2, "hello")}) {
+			t.Fatal("Expected the other chat's message to be refused")
+		}
+	}
+	var notices int
+	for _, call := range fake.Calls() {
+		if call.Method == "sendMessage" {
+			notices++
+			if call.Params.Get("chat_id") != "2" || !strings.Contains(call.Params.Get("text"), "private") {
+				t.Errorf("Unexpected notice %+v", call)
+			}
+		}
+	}
+	if notices != 1 {
+		t.Errorf("Expected a single access notice, got %d", notices)
+	}
+
+	callback := &tgbotapi.CallbackQuery{ID: "cb", Message: commandMessage(2, "menu"), Data: "todo_more_5"}
+	if !bot.refuseUpdate(tgbotapi.Update{CallbackQuery: callback}) {
+		t.Fatal("Expected the other chat's callback to be refused")
+	}
+	calls := fake.Calls()
+	if last := calls[len(calls)-1]; last.Method != "answerCallbackQuery" || last.Params.Get("callback_query_id") != "cb" {
+		t.Errorf("Expected the callback to be answered, got %+v", last)
+	}
+}
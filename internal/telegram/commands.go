@@ -13,6 +13,164 @@ import (
 func (b *Bot) handleCommand(message *tgbotapi.Message) error {
 	command := strings.TrimSpace(message.Text)
 
+	// Features storing message content are off under zero retention (implemented in retention.go)
+	if b.cfg().ZeroRetention() && isRetentionCommand(command) {
+		return b.sendRetentionDisabled(message.Chat.ID)
+	}
+
+	// Commands with arguments (implemented in commands_browse.go)
+	if command == "/cat" || strings.HasPrefix(command, "/cat ") {
+		return b.handleCatCommand(message, strings.TrimPrefix(command, "/cat"))
+	}
+	if command == "/ls" || strings.HasPrefix(command, "/ls ") {
+		return b.handleLsCommand(message, strings.TrimPrefix(command, "/ls"))
+	}
+	// Full-text search (implemented in search.go)
+	if command == "/search" || strings.HasPrefix(command, "/search ") {
+		return b.handleSearchCommand(message, strings.TrimPrefix(command, "/search"))
+	}
+	// PDF export of markdown files (implemented in pdf_export.go)
+	if command == "/pdf" || strings.HasPrefix(command, "/pdf ") {
+		return b.handlePDFCommand(message, strings.TrimPrefix(command, "/pdf"))
+	}
+	// Bulk maintenance of notes (implemented in bulk.go)
+	if command == "/bulk" || strings.HasPrefix(command, "/bulk ") {
+		return b.handleBulkCommand(message, strings.TrimPrefix(command, "/bulk"))
+	}
+	// Operator commands (implemented in commands_admin.go)
+	if command == "/admin" || strings.HasPrefix(command, "/admin ") {
+		return b.handleAdminCommand(message)
+	}
+	// Feed digests (implemented in feeds.go)
+	if command == "/feeds" || strings.HasPrefix(command, "/feeds ") {
+		return b.handleFeedsCommand(message)
+	}
+	// Capture API keys (implemented in commands_apikey.go)
+	if command == "/apikey" || strings.HasPrefix(command, "/apikey ") {
+		return b.handleAPIKeyCommand(message)
+	}
+	// Moving to another Telegram account (implemented in handover.go)
+	if command == "/handover" || strings.HasPrefix(command, "/handover ") {
+		return b.handleHandoverCommand(message)
+	}
+	// Outgoing webhooks (implemented in webhooks.go)
+	if command == "/webhooks" || strings.HasPrefix(command, "/webhooks ") {
+		return b.handleWebhooksCommand(message)
+	}
+	// Private repository settings (implemented in private_entries.go)
+	if command == "/private" || strings.HasPrefix(command, "/private ") {
+		return b.handlePrivateCommand(message)
+	}
+	// GitHub Enterprise Server settings (implemented in github_enterprise.go)
+	if command == "/enterprise" || strings.HasPrefix(command, "/enterprise ") {
+		return b.handleEnterpriseCommand(message)
+	}
+	// Gitea instance settings (implemented in gitea.go)
+	if command == "/gitea" || strings.HasPrefix(command, "/gitea ") {
+		return b.handleGiteaCommand(message)
+	}
+	// Commit branch (implemented in commit_branch.go)
+	if command == "/branch" || strings.HasPrefix(command, "/branch ") {
+		return b.handleBranchCommand(message)
+	}
+	// README table of contents (implemented in readme_toc.go)
+	if command == "/readme" || strings.HasPrefix(command, "/readme ") {
+		return b.handleReadmeCommand(message)
+	}
+	// Issue archive settings (implemented in issue_archive.go)
+	if command == "/archive" || strings.HasPrefix(command, "/archive ") {
+		return b.handleArchiveCommand(message)
+	}
+	// Note encryption (implemented in note_encryption.go)
+	if command == "/encrypt" || strings.HasPrefix(command, "/encrypt ") {
+		return b.handleEncryptCommand(message)
+	}
+	// Telegram export import (implemented in import.go)
+	if command == "/import" || strings.HasPrefix(command, "/import ") {
+		return b.handleImportCommand(message)
+	}
+	// Issue status sync, optionally as a dry run (implemented in commands_info.go)
+	if command == "/sync" || strings.HasPrefix(command, "/sync ") {
+		return b.handleSyncCommand(message)
+	}
+	// Channel ingestion routes (implemented in channels.go)
+	if command == "/channel" || strings.HasPrefix(command, "/channel ") {
+		return b.handleChannelCommand(message)
+	}
+	// Canned issue comments (implemented in canned_replies.go)
+	if command == "/canned" || strings.HasPrefix(command, "/canned ") || strings.HasPrefix(command, "/canned\n") {
+		return b.handleCannedCommand(message)
+	}
+	// Daily pinned summary (implemented in daily_pin.go)
+	if command == "/pin" || strings.HasPrefix(command, "/pin ") {
+		return b.handlePinCommand(message)
+	}
+	// Source links in notes (implemented in source_footer.go)
+	if command == "/source" || strings.HasPrefix(command, "/source ") {
+		return b.handleSourceCommand(message)
+	}
+	// Mood tracking of notes (implemented in mood.go)
+	if command == "/mood" || strings.HasPrefix(command, "/mood ") {
+		return b.handleMoodCommand(message)
+	}
+	// Auto-routing of notes to custom files (implemented in auto_route.go)
+	if command == "/autoroute" || strings.HasPrefix(command, "/autoroute ") {
+		return b.handleAutoRouteCommand(message)
+	}
+	// Plain text replies for screen readers (implemented in plain_mode.go)
+	if command == "/plain" || strings.HasPrefix(command, "/plain ") {
+		return b.handlePlainCommand(message)
+	}
+	// History compression (implemented in history_compression.go)
+	if command == "/compress" || strings.HasPrefix(command, "/compress ") {
+		return b.handleCompressCommand(message)
+	}
+	// Weekly changelog issues (implemented in weekly_changelog.go)
+	if command == "/changelog" || strings.HasPrefix(command, "/changelog ") {
+		return b.handleChangelogCommand(message)
+	}
+	// Weekly or monthly insight reports (implemented in insight_report.go)
+	if command == "/report" || strings.HasPrefix(command, "/report ") {
+		return b.handleReportCommand(message)
+	}
+	// Quiet hours deferring non-essential messages (implemented in quiet_hours.go)
+	if command == "/quiet" || strings.HasPrefix(command, "/quiet ") {
+		return b.handleQuietCommand(message)
+	}
+	// Commits per author per week (implemented in contributors.go)
+	if command == "/contributors" || strings.HasPrefix(command, "/contributors ") {
+		return b.handleContributorsCommand(message)
+	}
+	// Opt-in community leaderboard (implemented in leaderboard.go)
+	if command == "/leaderboard" || strings.HasPrefix(command, "/leaderboard ") {
+		return b.handleLeaderboardCommand(message)
+	}
+	// Capture streaks and their reminder (implemented in streaks.go)
+	if command == "/streak" || strings.HasPrefix(command, "/streak ") {
+		return b.handleStreakCommand(message)
+	}
+	// Models per LLM task (implemented in llm_models.go)
+	if command == "/models" || strings.HasPrefix(command, "/models ") {
+		return b.handleModelsCommand(message)
+	}
+	// Deep links opening the original message of a note (implemented in source_footer.go)
+	if strings.HasPrefix(command, "/start ") {
+		return b.handleStartPayload(message, strings.TrimSpace(strings.TrimPrefix(command, "/start ")))
+	}
+	// Tenant info and member management (implemented in tenants.go)
+	if command == "/tenant" || strings.HasPrefix(command, "/tenant ") {
+		return b.handleTenantCommand(message)
+	}
+	// Direct path capture (implemented in commands_direct.go)
+	if command == "/to" || strings.HasPrefix(command, "/to ") || strings.HasPrefix(command, "/to\n") {
+		return b.handleToCommand(message)
+	}
+
+	// Self-hosted deployments without payments (implemented in self_hosted.go)
+	if b.cfg().PaymentsDisabled && paymentCommands[command] {
+		return b.sendPaymentsDisabled(message.Chat.ID)
+	}
+
 	switch command {
 	// Basic commands
 	case "/start":
@@ -25,28 +183,48 @@ func (b *Bot) handleCommand(message *tgbotapi.Message) error {
 		return b.handleRepoCommand(message)
 	case "/llm":
 		return b.handleLLMCommand(message)
+	case "/whoami":
+		return b.handleWhoamiCommand(message) // Implemented in github_identity.go
+	case "/topics":
+		return b.handleTopicsCommand(message) // Implemented in forum_topics.go
 
 	// Information commands (implemented in commands_info.go)
-	case "/sync":
-		return b.handleSyncCommand(message)
 	case "/insight":
 		return b.handleInsightCommand(message)
 	case "/stats":
 		return b.handleStatsCommand(message)
+	case "/access":
+		return b.handleAccessCommand(message) // Implemented in access_anomalies.go
+	case "/limits":
+		return b.handleLimitsCommand(message) // Implemented in limits.go
 
 	// Content management commands (implemented in commands_content.go)
 	case "/todo":
 		return b.handleTodoCommand(message, 0) // Start with offset 0
 	case "/issue":
 		return b.handleIssueCommand(message, 0) // Start with offset 0
+	case "/rebuildissues", "/rebuild-issues":
+		return b.handleRebuildIssuesCommand(message) // Implemented in issue_rebuild.go
 	case "/customfile":
 		return b.handleCustomFileCommand(message)
+	case "/trash":
+		return b.handleTrashCommand(message) // Implemented in commands_trash.go
+
+	// Compose mode (implemented in compose.go)
+	case "/compose":
+		return b.handleComposeCommand(message)
+	case "/send":
+		return b.handleSendCommand(message)
+	case "/discard":
+		return b.handleDiscardCommand(message)
 
 	// Premium commands (implemented in commands_premium.go)
 	case "/coffee":
 		return b.handleCoffeeCommand(message)
 	case "/resetusage":
 		return b.handleResetUsageCommand(message)
+	case "/receipts":
+		return b.handleReceiptsCommand(message) // Implemented in commands_receipts.go
 
 	default:
 		return fmt.Errorf("unknown command: %s", message.Text)
@@ -56,12 +234,12 @@ func (b *Bot) handleCommand(message *tgbotapi.Message) error {
 func (b *Bot) handleStartCommand(message *tgbotapi.Message) error {
 	// Build website links if BASE_URL is configured
 	var websiteLinks string
-	if b.config.BaseURL != "" {
+	if b.cfg().BaseURL != "" {
 		websiteLinks = fmt.Sprintf(`
 
 <b>🌐 Learn More:</b>
 • <a href="%s">Visit our homepage</a>
-• <a href="%s/privacy">Privacy Policy</a>`, b.config.BaseURL, b.config.BaseURL)
+• <a href="%s/privacy">Privacy Policy</a>`, b.cfg().BaseURL, b.cfg().BaseURL)
 	}
 
 	welcomeMsg := fmt.Sprintf(`🤖 <b>Welcome to Gitted Messages!</b>
@@ -96,12 +274,12 @@ A minimalist Telegram bot that turns your messages into GitHub commits.
 func (b *Bot) handleHelpCommand(message *tgbotapi.Message) error {
 	// Build website links if BASE_URL is configured
 	var websiteLinks string
-	if b.config.BaseURL != "" {
+	if b.cfg().BaseURL != "" {
 		websiteLinks = fmt.Sprintf(`<b>🌐 Resources:</b>
 • <a href="%s">Homepage & Documentation</a>
 • <a href="%s/privacy">Privacy Policy</a>
 • <a href="%s/refund">Refund Policy</a>
-• <a href="%s/terms">Terms of Service</a>`, b.config.BaseURL, b.config.BaseURL, b.config.BaseURL, b.config.BaseURL)
+• <a href="%s/terms">Terms of Service</a>`, b.cfg().BaseURL, b.cfg().BaseURL, b.cfg().BaseURL, b.cfg().BaseURL)
 	}
 
 	helpMsg := fmt.Sprintf(`📚 <b>Gitted Messages Help</b>
@@ -109,20 +287,66 @@ func (b *Bot) handleHelpCommand(message *tgbotapi.Message) error {
 <b>🔧 Setup Commands:</b>
 • /repo - View repository information and settings
 • /llm - Configure and control AI processing
+• /models - Choose AI models for titles, summaries and images
+• /whoami - Show your linked GitHub account and check your committer
+• /private [owner/repo|off] - Set the repository for private entries
+• /enterprise [api_url|off] - Use a GitHub Enterprise Server
+• /gitea [url|off] - Keep your notes on a Gitea instance
+• /branch [name|default] - Commit notes to another branch
+• /readme [on|off|refresh] - Keep a table of contents of your notes in README.md
+• /feeds - Commit daily digests of RSS feeds and GitHub releases
+• /channel - Save every post of your channel to the repository
+• /webhooks - Send events to Zapier, IFTTT or your own endpoints
+• /apikey - Create an API key for msg2git-cli
+• /handover [new|claim CODE|cancel] - Move your setup and history to another Telegram account
 
 <b>📊 Information Commands:</b>
 • /sync - Synchronize issue statuses from GitHub
+• /sync dry - Preview what a sync would change
+• /sync mode - Commit synced files together or one by one
+• /archive [days|yearly on|off] - Choose when closed issues leave issue.md
+• /rebuildissues - Regenerate a broken issue.md from the issues on GitHub
 • /insight - View usage statistics and repository status
+• /contributors [weeks] - Commits per author per week, for shared repositories
 • /stats - View global bot statistics
-• /todo - Show latest TODO items
-• /issue - Show latest open issues
+• /streak [remind [time [timezone]]|remind off] - Your capture streak and an evening reminder
+• /leaderboard [join|leave] - Opt-in community ranking of commits and streaks
+• /access - See where your token pushed and resume paused operations
+• /limits - Your GitHub REST and GraphQL rate limits
+• /tenant - View your tenant's quotas and statistics
+• /todo - List, check off and reorder TODO items
+• /issue - Show latest open issues and their comments
+• /canned - Save replies you often comment on issues
+• /pin [on|off] - Pin a daily summary of yesterday's captures
+• /topics - Show the files of this group's forum topics
+• /source [on|off] - End notes with a link to their Telegram message
+• /mood [on|off] - Tag notes with their mood and chart it in /insight
+• /autoroute [on|off] - Let the LLM commit notes to the best matching custom file
+• /plain [on|off] - Plain text replies without emoji or formatting, for screen readers
+• /encrypt [setup|off] - Encrypt notes with a passphrase before committing them
+• /import [folder] - Import a Telegram chat export as dated notes
+• /changelog [on|off|now] - Open a weekly GitHub issue summarizing your captures
+• /report [weekly|monthly|off|now] - Get a weekly or monthly report of your insights
+• /compress [on [days]|now|apply|off] - Squash old bot commits into daily rollups
+• /quiet [22:00-07:00 [timezone]|off] - Hold back digests and nudges during quiet hours
+• /ls [folder] - Browse repository files
+• /cat &lt;path&gt; - View a file from your repository
+• /search &lt;words&gt; - Find the lines of your notes containing the words
+• /pdf &lt;path&gt; - Export a markdown file as PDF
 
 <b>📁 File Management:</b>
 • /customfile - Manage custom files and folders
+• /trash - Restore or permanently delete trashed files
+• /to &lt;path&gt; &lt;note&gt; - Save a note directly to any file
+• /compose - Collect several messages and photos into one entry, then /send or /discard it
+• <code>&gt;&gt; path/file.md: note</code> - Same as /to, without the command
+• <code>!note</code> - Save a private entry to your private repository
+• /bulk - Retitle, retag or move many notes at once, previewed first
 
 <b>💎 Premium Commands:</b>
 • /coffee - Support project and unlock premium features
 • /resetusage - Reset usage counters (paid service)
+• /receipts - View payment history and receipts
 
 <b>💡 Pro Tips:</b>
 • Use TODO for task items with checkboxes
@@ -0,0 +1,188 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Operator commands, restricted to chats listed in ADMIN_CHAT_IDS / admin.chat_ids
+
+const configWatchInterval = 30 * time.Second
+
+func (b *Bot) handleAdminCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if !b.cfg().IsAdmin(chatID) {
+		logger.Warn("Unauthorized /admin attempt", map[string]interface{}{
+			"chat_id": chatID,
+		})
+		return fmt.Errorf("unknown command: %s", message.Text)
+	}
+
+	args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/admin"))
+	if len(args) == 0 {
+		b.sendResponse(chatID, `🛠 <b>Admin Commands</b>
+
+• /admin reload - Reload non-secret settings from the config file and environment
+• /admin workers - Show worker pool load and autoscaling
+• /admin slow - Show operations over their watchdog threshold
+• /admin flags - List feature flags
+• /admin flag &lt;name&gt; on|off|delete - Toggle or remove a feature flag
+• /admin flag &lt;name&gt; percent &lt;0-100&gt; - Set percentage rollout
+• /admin flag &lt;name&gt; tier &lt;0-3&gt; - Require a minimum premium tier
+• /admin flag &lt;name&gt; allow|deny &lt;chat_id&gt; - Manage the per-user allowlist
+• /admin tenants - List tenants with their usage
+• /admin tenant &lt;name&gt; create|delete|stats - Manage a tenant
+• /admin tenant &lt;name&gt; disk &lt;MB&gt; | tokens &lt;n&gt; - Set aggregate quotas (0 = unlimited)
+• /admin tenant &lt;name&gt; add &lt;chat_id&gt; [admin] | remove &lt;chat_id&gt; - Manage members
+• /admin access - Show which chats the bot serves
+• /admin access allow|block|remove &lt;chat_id&gt; - Change who may use the bot
+• /admin backup - Back up the database now
+• /admin backups - List database backups
+• /admin restore &lt;name|latest&gt; - Restore the database from a backup`)
+		return nil
+	}
+
+	switch args[0] {
+	case "reload":
+		changed, err := b.reloadConfig()
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Config reload failed: %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if len(changed) == 0 {
+			b.sendResponse(chatID, "✅ Config reloaded, no changes detected.")
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("✅ Config reloaded. Changed: <code>%s</code>", html.EscapeString(strings.Join(changed, ", "))))
+		return nil
+	case "workers":
+		return b.handleAdminWorkersCommand(message)
+	case "slow":
+		return b.handleAdminSlowCommand(message) // Implemented in watchdog.go
+	case "flags":
+		return b.handleAdminFlagsCommand(message)
+	case "flag":
+		return b.handleAdminFlagCommand(message, args[1:])
+	case "tenants":
+		return b.handleAdminTenantsCommand(message) // Implemented in tenants.go
+	case "tenant":
+		return b.handleAdminTenantCommand(message, args[1:])
+	case "access":
+		return b.handleAdminAccessCommand(message, args[1:]) // Implemented in chat_access.go
+	case "backup":
+		return b.handleAdminBackupCommand(message) // Implemented in db_backup.go
+	case "backups":
+		return b.handleAdminBackupsCommand(message) // Implemented in db_backup.go
+	case "restore":
+		return b.handleAdminRestoreCommand(message, args[1:]) // Implemented in db_backup.go
+	default:
+		b.sendResponse(chatID, fmt.Sprintf("❌ Unknown admin command: %s", html.EscapeString(args[0])))
+		return nil
+	}
+}
+
+// handleAdminWorkersCommand shows the worker pool's queues, latencies and autoscaling
+func (b *Bot) handleAdminWorkersCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.workerPool == nil {
+		b.sendResponse(chatID, "❌ Worker pool not initialized.")
+		return nil
+	}
+
+	stats := b.workerPool.GetStats()
+	autoscaling := "off"
+	if stats["autoscaling"].(bool) {
+		autoscaling = fmt.Sprintf("on, scaled up %d and down %d times", stats["scale_ups"], stats["scale_downs"])
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf(`📊 <b>Worker Pool</b>
+
+<b>Messages:</b> %d workers (%d–%d), %d/%d queued, p50 %dms · p95 %dms
+<b>Callbacks:</b> %d workers (%d–%d), %d/%d queued, p50 %dms · p95 %dms
+<b>Operations:</b> %d/%d running
+<b>Autoscaling:</b> %s`,
+		stats["message_workers"], stats["message_workers_min"], stats["message_workers_max"],
+		stats["message_queue_size"], stats["message_queue_capacity"], stats["message_latency_p50_ms"], stats["message_latency_p95_ms"],
+		stats["callback_workers"], stats["callback_workers_min"], stats["callback_workers_max"],
+		stats["callback_queue_size"], stats["callback_queue_capacity"], stats["callback_latency_p50_ms"], stats["callback_latency_p95_ms"],
+		stats["active_operations"], stats["max_concurrent_ops"], autoscaling))
+	return nil
+}
+
+// cfg returns the current config. Reloads and runtime updates swap in a changed copy rather than
+// changing it in place, so a config once read never changes under its reader.
+func (b *Bot) cfg() *config.Config {
+	if cfg := b.liveConfig.Load(); cfg != nil {
+		return cfg
+	}
+	return b.config
+}
+
+// updateConfig swaps in the config update returns for the current one, one update at a time
+func (b *Bot) updateConfig(update func(current *config.Config) (*config.Config, error)) error {
+	b.configMu.Lock()
+	defer b.configMu.Unlock()
+
+	next, err := update(b.cfg())
+	if err != nil {
+		return err
+	}
+	b.liveConfig.Store(next)
+	return nil
+}
+
+// reloadConfig hot-reloads non-secret settings and applies side effects such as the log level
+func (b *Bot) reloadConfig() ([]string, error) {
+	var changed []string
+	err := b.updateConfig(func(current *config.Config) (*config.Config, error) {
+		next, names, err := config.Reload(current)
+		changed = names
+		return next, err
+	})
+	if err != nil {
+		logger.Error("Failed to reload config", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	for _, name := range changed {
+		if strings.HasPrefix(name, "watchdog.") {
+			b.applyWatchdogThresholds()
+		}
+		if name == "log_level" {
+			if err := logger.SetLevel(b.cfg().LogLevel); err != nil {
+				logger.Warn("Invalid log level in reloaded config", map[string]interface{}{
+					"log_level": b.cfg().LogLevel,
+					"error":     err.Error(),
+				})
+			}
+		}
+	}
+
+	logger.Info("Config reloaded", map[string]interface{}{
+		"changed": changed,
+	})
+	return changed, nil
+}
+
+// startConfigWatcher reloads the config automatically when the config file changes
+func (b *Bot) startConfigWatcher() {
+	if b.cfg().ConfigFile == "" {
+		return
+	}
+
+	logger.Info("Watching config file for changes", map[string]interface{}{
+		"path": b.cfg().ConfigFile,
+	})
+	b.stopConfigWatcher = config.WatchFile(b.cfg().ConfigFile, configWatchInterval, func() {
+		b.reloadConfig()
+	})
+}
@@ -0,0 +1,116 @@
+package telegram
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// API key management for the capture API used by msg2git-cli
+
+const apiKeyPrefix = "m2g_"
+
+// generateAPIKey returns a new random API key
+func generateAPIKey() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf), nil
+}
+
+// hashAPIKey returns the hex SHA-256 of key, which is what the database stores
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// handleAPIKeyCommand shows, creates or revokes the user's API key:
+// /apikey, /apikey new, /apikey revoke
+func (b *Bot) handleAPIKeyCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	action := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/apikey"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ API keys require a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch action {
+	case "":
+		key, err := b.db.GetAPIKey(chatID)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+
+		status := "🔑 No API key yet."
+		if key != nil {
+			lastUsed := "never used"
+			if key.LastUsedAt != nil {
+				lastUsed = "last used " + key.LastUsedAt.Format("2006-01-02 15:04")
+			}
+			status = fmt.Sprintf("🔑 API key <code>%s…</code> created %s, %s.", html.EscapeString(key.KeyPrefix), key.CreatedAt.Format("2006-01-02"), lastUsed)
+		}
+
+		b.sendResponse(chatID, status+`
+
+Use the key with <code>msg2git-cli</code> to capture notes from scripts and the terminal.
+
+• /apikey new - Create a key (replaces the current one)
+• /apikey revoke - Revoke the current key`)
+		return nil
+	case "new":
+		key, err := generateAPIKey()
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if _, err := b.db.ReplaceAPIKey(chatID, hashAPIKey(key), key[:len(apiKeyPrefix)+6]); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Failed to save API key: %s", html.EscapeString(err.Error())))
+			return nil
+		}
+
+		logger.Info("API key created", map[string]interface{}{
+			"chat_id": chatID,
+		})
+
+		b.sendResponse(chatID, fmt.Sprintf(`✅ New API key (shown only once, any previous key stops working):
+
+<code>%s</code>
+
+Example:
+<code>echo "hello" | MSG2GIT_API_KEY=%s msg2git-cli -server %s</code>`,
+			key, key, html.EscapeString(b.apiBaseURL())))
+		return nil
+	case "revoke":
+		if err := b.db.DeleteAPIKey(chatID); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		b.sendResponse(chatID, "🗑 API key revoked.")
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/apikey [new|revoke]</code>")
+		return nil
+	}
+}
+
+// apiBaseURL returns the public URL of the capture API for usage hints
+func (b *Bot) apiBaseURL() string {
+	if b.cfg().BaseURL != "" {
+		return b.cfg().BaseURL
+	}
+	return "https://your-msg2git-host"
+}
@@ -0,0 +1,34 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateAPIKey(t *testing.T) {
+	first, err := generateAPIKey()
+	if err != nil {
+		t.Fatalf("generateAPIKey() error = %v", err)
+	}
+	second, _ := generateAPIKey()
+
+	if !strings.HasPrefix(first, apiKeyPrefix) {
+		t.Errorf("key %q does not start with %q", first, apiKeyPrefix)
+	}
+	if first == second {
+		t.Error("generateAPIKey() returned the same key twice")
+	}
+}
+
+func TestHashAPIKey(t *testing.T) {
+	hash := hashAPIKey("m2g_example")
+	if len(hash) != 64 {
+		t.Errorf("hash length = %d, want 64", len(hash))
+	}
+	if hash != hashAPIKey("m2g_example") {
+		t.Error("hashAPIKey() is not deterministic")
+	}
+	if hash == hashAPIKey("m2g_other") {
+		t.Error("different keys produced the same hash")
+	}
+}
@@ -0,0 +1,316 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Read-only repository browsing commands (/cat and /ls)
+
+const (
+	catPreviewLimit   = 3 * 3500 // Sent in up to three messages, longer files are attached
+	catMaxReadBytes   = 2 << 20  // Larger files are attached up to this size
+	lsMaxEntries      = 40       // Maximum entries shown in a single /ls keyboard
+	browseStateExpiry = 30 * time.Minute
+)
+
+// browseState keeps the entries of a /ls listing so callbacks can use short indexes
+// instead of full paths (Telegram limits callback data to 64 bytes)
+type browseState struct {
+	Path    string
+	Entries []github.DirectoryEntry
+}
+
+// validateRepoPath normalizes a user supplied repository path and rejects traversal
+func validateRepoPath(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	p = strings.Trim(p, "/")
+	if p == "" {
+		return "", nil
+	}
+
+	if strings.Contains(p, "\\") {
+		return "", fmt.Errorf("invalid path: backslashes are not allowed")
+	}
+
+	for _, segment := range strings.Split(p, "/") {
+		if segment == ".." || segment == "." || segment == "" {
+			return "", fmt.Errorf("invalid path: %s", p)
+		}
+		if segment == ".git" {
+			return "", fmt.Errorf("invalid path: .git is not accessible")
+		}
+	}
+
+	return path.Clean(p), nil
+}
+
+// syntaxLanguageForFile returns a Telegram code block language hint for the file extension
+func syntaxLanguageForFile(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".md", ".markdown":
+		return "markdown"
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".js":
+		return "javascript"
+	case ".ts":
+		return "typescript"
+	case ".json":
+		return "json"
+	case ".yml", ".yaml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".sh":
+		return "bash"
+	case ".html", ".htm":
+		return "html"
+	case ".css":
+		return "css"
+	case ".sql":
+		return "sql"
+	case ".rs":
+		return "rust"
+	case ".java":
+		return "java"
+	case ".c", ".h":
+		return "c"
+	case ".cpp", ".hpp", ".cc":
+		return "cpp"
+	default:
+		return ""
+	}
+}
+
+// truncateForPreview cuts content to limit bytes on a line boundary when possible
+func truncateForPreview(content string, limit int) (string, bool) {
+	if len(content) <= limit {
+		return content, false
+	}
+
+	truncated := content[:limit]
+	if idx := strings.LastIndex(truncated, "\n"); idx > limit/2 {
+		truncated = truncated[:idx]
+	}
+
+	// Avoid cutting a multi-byte UTF-8 character in half
+	for len(truncated) > 0 && !utf8.ValidString(truncated) {
+		truncated = truncated[:len(truncated)-1]
+	}
+
+	return truncated, true
+}
+
+func (b *Bot) handleCatCommand(message *tgbotapi.Message, arg string) error {
+	chatID := message.Chat.ID
+
+	filePath, err := validateRepoPath(arg)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if filePath == "" {
+		b.sendResponse(chatID, "📄 <b>Usage:</b> <code>/cat path/to/file.md</code>\n\nUse /ls to browse repository files.")
+		return nil
+	}
+
+	return b.sendFileContent(chatID, filePath)
+}
+
+// sendFileContent reads a file through the user's provider and sends it as a message or document
+func (b *Bot) sendFileContent(chatID int64, filePath string) error {
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	// Only the start of large files is read (implemented in github/file_head.go)
+	content, readTruncated, err := github.ReadFileHead(userGitHubProvider, filePath, catMaxReadBytes)
+	if err != nil {
+		logger.Warn("Failed to read file for /cat", map[string]interface{}{
+			"chat_id": chatID,
+			"path":    filePath,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to read <code>%s</code>: %s", html.EscapeString(filePath), html.EscapeString(err.Error())))
+		return nil
+	}
+
+	if strings.TrimSpace(content) == "" {
+		b.sendResponse(chatID, fmt.Sprintf("📄 <code>%s</code> is empty or does not exist.", html.EscapeString(filePath)))
+		return nil
+	}
+	content = b.openNotes(chatID, content)
+
+	preview, truncated := truncateForPreview(content, catPreviewLimit)
+
+	// Language hint lets Telegram clients apply syntax highlighting
+	codeBlock := fmt.Sprintf("<pre>%s</pre>", html.EscapeString(preview))
+	if lang := syntaxLanguageForFile(filePath); lang != "" {
+		codeBlock = fmt.Sprintf(`<pre><code class="language-%s">%s</code></pre>`, lang, html.EscapeString(preview))
+	}
+
+	text := fmt.Sprintf("📄 <b>%s</b>\n%s", html.EscapeString(filePath), codeBlock)
+	reply := longReply{Text: text}
+	if truncated {
+		// Send the complete file as a document when it doesn't fit in a few messages
+		reply.Text += fmt.Sprintf("\n<i>✂️ Truncated: showing %d of %d bytes. Full file attached below.</i>", len(preview), len(content))
+		reply.Document = content
+		reply.DocumentName = path.Base(filePath)
+		reply.DocumentCaption = filePath
+		if readTruncated {
+			reply.Text = text + fmt.Sprintf("\n<i>✂️ Truncated: showing %d bytes. The file is larger than %d MB, its first %d MB are attached below.</i>", len(preview), catMaxReadBytes>>20, catMaxReadBytes>>20)
+			reply.DocumentCaption = fmt.Sprintf("%s (first %d MB)", filePath, catMaxReadBytes>>20)
+		}
+	}
+
+	if err := b.sendLongReply(chatID, reply); err != nil {
+		return fmt.Errorf("failed to send file content: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) handleLsCommand(message *tgbotapi.Message, arg string) error {
+	chatID := message.Chat.ID
+
+	dirPath, err := validateRepoPath(arg)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	statusMessageID := b.sendResponseAndGetMessageID(chatID, "🔄 Listing repository files...")
+	return b.showDirectoryListing(chatID, statusMessageID, dirPath)
+}
+
+// showDirectoryListing renders the directory at dirPath as an inline keyboard in messageID
+func (b *Bot) showDirectoryListing(chatID int64, messageID int, dirPath string) error {
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.editMessage(chatID, messageID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	entries, err := userGitHubProvider.ListDirectory(dirPath)
+	if err != nil {
+		logger.Warn("Failed to list directory for /ls", map[string]interface{}{
+			"chat_id": chatID,
+			"path":    dirPath,
+			"error":   err.Error(),
+		})
+		b.editMessage(chatID, messageID, fmt.Sprintf("❌ Failed to list %s: %v", displayDirPath(dirPath), err))
+		return nil
+	}
+
+	// Folders first, then files, alphabetically
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Type != entries[j].Type {
+			return entries[i].Type == "dir"
+		}
+		return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+	})
+
+	hidden := 0
+	if len(entries) > lsMaxEntries {
+		hidden = len(entries) - lsMaxEntries
+		entries = entries[:lsMaxEntries]
+	}
+
+	b.cache.SetWithExpiry(fmt.Sprintf("browse_%d_%d", chatID, messageID), &browseState{
+		Path:    dirPath,
+		Entries: entries,
+	}, browseStateExpiry)
+
+	var keyboard [][]tgbotapi.InlineKeyboardButton
+	for i, entry := range entries {
+		label := "📄 " + entry.Name
+		if entry.Type == "dir" {
+			label = "📁 " + entry.Name + "/"
+		}
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("browse_open_%d", i)),
+		))
+	}
+	if dirPath != "" {
+		keyboard = append(keyboard, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⬆️ Up", "browse_up"),
+		))
+	}
+
+	text := fmt.Sprintf("📂 <b>%s</b>", html.EscapeString(displayDirPath(dirPath)))
+	if len(entries) == 0 {
+		text += "\n\n<i>This folder is empty.</i>"
+	} else {
+		text += "\n\nTap a file to view it, or a folder to open it."
+	}
+	if hidden > 0 {
+		text += fmt.Sprintf("\n<i>… and %d more entries not shown. Use /ls &lt;folder&gt; to narrow down.</i>", hidden)
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = consts.ParseModeHTML
+	if len(keyboard) > 0 {
+		markup := tgbotapi.NewInlineKeyboardMarkup(keyboard...)
+		edit.ReplyMarkup = &markup
+	}
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to show directory listing: %w", err)
+	}
+
+	return nil
+}
+
+// handleBrowseCallback handles browse_open_<index> and browse_up callbacks from /ls
+func (b *Bot) handleBrowseCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	cached, _ := b.cache.Get(fmt.Sprintf("browse_%d_%d", chatID, messageID))
+	state, ok := cached.(*browseState)
+	if !ok {
+		b.editMessage(chatID, messageID, "⏰ This listing has expired. Use /ls to browse again.")
+		return nil
+	}
+
+	if callback.Data == "browse_up" {
+		parent := path.Dir(state.Path)
+		if parent == "." || parent == "/" {
+			parent = ""
+		}
+		return b.showDirectoryListing(chatID, messageID, parent)
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "browse_open_"))
+	if err != nil || index < 0 || index >= len(state.Entries) {
+		return fmt.Errorf("invalid browse callback data: %s", callback.Data)
+	}
+
+	entry := state.Entries[index]
+	if entry.Type == "dir" {
+		return b.showDirectoryListing(chatID, messageID, entry.Path)
+	}
+
+	return b.sendFileContent(chatID, entry.Path)
+}
+
+func displayDirPath(dirPath string) string {
+	if dirPath == "" {
+		return "/"
+	}
+	return "/" + dirPath
+}
@@ -0,0 +1,78 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestValidateRepoPath(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    string
+		expectError bool
+	}{
+		{input: "", expected: ""},
+		{input: "  note.md  ", expected: "note.md"},
+		{input: "/docs/guide.md", expected: "docs/guide.md"},
+		{input: "docs/", expected: "docs"},
+		{input: "../secret", expectError: true},
+		{input: "docs/../../etc/passwd", expectError: true},
+		{input: "./note.md", expectError: true},
+		{input: ".git/config", expectError: true},
+		{input: "docs//note.md", expectError: true},
+		{input: "docs\\note.md", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := validateRepoPath(tt.input)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error for %q, got %q", tt.input, result)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Unexpected error for %q: %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("validateRepoPath(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTruncateForPreview(t *testing.T) {
+	short := "hello"
+	if result, truncated := truncateForPreview(short, 100); truncated || result != short {
+		t.Errorf("Short content should not be truncated")
+	}
+
+	long := strings.Repeat("line of text\n", 100)
+	result, truncated := truncateForPreview(long, 200)
+	if !truncated {
+		t.Fatal("Long content should be truncated")
+	}
+	if len(result) > 200 || strings.HasSuffix(result, "\n") {
+		t.Errorf("Expected truncation on a line boundary within limit, got %d bytes", len(result))
+	}
+
+	multibyte := strings.Repeat("你好", 100)
+	result, truncated = truncateForPreview(multibyte, 101)
+	if !truncated || !utf8.ValidString(result) {
+		t.Errorf("Truncated multi-byte content should remain valid UTF-8")
+	}
+}
+
+func TestSyntaxLanguageForFile(t *testing.T) {
+	if lang := syntaxLanguageForFile("notes/todo.md"); lang != "markdown" {
+		t.Errorf("Expected markdown, got %q", lang)
+	}
+	if lang := syntaxLanguageForFile("main.GO"); lang != "go" {
+		t.Errorf("Expected go, got %q", lang)
+	}
+	if lang := syntaxLanguageForFile("LICENSE"); lang != "" {
+		t.Errorf("Expected no language hint, got %q", lang)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/msg2git/msg2git/internal/database"
 	"github.com/msg2git/msg2git/internal/github"
 	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/render"
 )
 
 // Content management command handlers
@@ -19,6 +20,11 @@ func (b *Bot) handleTodoCommand(message *tgbotapi.Message, offset int) error {
 }
 
 func (b *Bot) handleTodoCommandWithMessageID(chatID int64, messageID int, offset int) error {
+	return b.showTodos(chatID, messageID, todoViewOpen, offset)
+}
+
+// showTodos sends the chat's TODOs in the view (see todos.go), or edits messageID into them
+func (b *Bot) showTodos(chatID int64, messageID int, view string, offset int) error {
 	// Ensure user exists in database if database is configured
 	_, err := b.ensureUserFromChatID(chatID)
 	if err != nil {
@@ -53,28 +59,34 @@ func (b *Bot) handleTodoCommandWithMessageID(chatID int64, messageID int, offset
 		return nil
 	}
 
-	// Parse TODOs
+	// Parse TODOs and keep the ones of the current chat shown in the view
 	todos := b.parseTodoItems(content)
+	shown := todosInView(todos, chatID, view)
 
-	// Filter undone TODOs for the current chat
-	var undoneTodos []TodoItem
-	for _, todo := range todos {
-		if !todo.Done && (todo.ChatID == chatID || todo.ChatID == 0) {
-			undoneTodos = append(undoneTodos, todo)
-		}
-	}
-
-	if len(undoneTodos) == 0 {
+	if len(shown) == 0 {
 		msg := "✅ <b>No pending TODO items!</b>\n\n<i>All tasks completed or no TODOs found.</i>"
+		if view == todoViewAll {
+			msg = "✅ <b>No TODO items yet!</b>\n\n<i>Send a message and tap ✅ TODO to add one.</i>"
+		}
+		var keyboard *tgbotapi.InlineKeyboardMarkup
+		if view != todoViewAll && len(todosInView(todos, chatID, todoViewAll)) > 0 {
+			keyboard = &tgbotapi.InlineKeyboardMarkup{InlineKeyboard: [][]tgbotapi.InlineKeyboardButton{{
+				tgbotapi.NewInlineKeyboardButtonData("📋 Show done", todoViewCallback(todoViewAll, 0)),
+			}}}
+		}
 		if messageID > 0 {
 			editMsg := tgbotapi.NewEditMessageText(chatID, messageID, msg)
 			editMsg.ParseMode = consts.ParseModeHTML
+			editMsg.ReplyMarkup = keyboard
 			if _, err := b.rateLimitedSend(chatID, editMsg); err != nil {
 				return fmt.Errorf("failed to edit message: %w", err)
 			}
 		} else {
 			responseMsg := tgbotapi.NewMessage(chatID, msg)
 			responseMsg.ParseMode = consts.ParseModeHTML
+			if keyboard != nil {
+				responseMsg.ReplyMarkup = *keyboard
+			}
 			if _, err := b.rateLimitedSend(chatID, responseMsg); err != nil {
 				return fmt.Errorf("failed to send message: %w", err)
 			}
@@ -82,71 +94,122 @@ func (b *Bot) handleTodoCommandWithMessageID(chatID int64, messageID int, offset
 		return nil
 	}
 
-	// Pagination
-	const itemsPerPage = 5
-	totalPages := (len(undoneTodos) + itemsPerPage - 1) / itemsPerPage
-	currentPage := (offset / itemsPerPage) + 1
+	// Pagination, a page emptied by checking off its items shows the last one
+	if offset >= len(shown) {
+		offset = (len(shown) - 1) / todoItemsPerPage * todoItemsPerPage
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	totalPages := (len(shown) + todoItemsPerPage - 1) / todoItemsPerPage
+	currentPage := (offset / todoItemsPerPage) + 1
 
 	start := offset
-	end := offset + itemsPerPage
-	if end > len(undoneTodos) {
-		end = len(undoneTodos)
+	end := offset + todoItemsPerPage
+	if end > len(shown) {
+		end = len(shown)
 	}
 
-	// Build response message
-	msg := fmt.Sprintf("✅ <b>TODO Items</b> (Page %d/%d)\n\n", currentPage, totalPages)
+	// Build response message, TODO content is user text and gets escaped by the renderer
+	title := "TODO Items"
+	switch view {
+	case todoViewAll:
+		title = "All TODO Items"
+	case todoViewSort:
+		title = "Reorder TODO Items"
+	}
+	msg := render.New(render.HTML).Text("✅ ").Bold(title).Textf(" (Page %d/%d)", currentPage, totalPages).Newline().Newline()
 
 	for i := start; i < end; i++ {
-		todo := undoneTodos[i]
+		todo := shown[i]
 		indexNumber := i + 1 // Use 1-based indexing for display
-		msg += fmt.Sprintf("%d. %s\n<i>Added: %s</i>\n\n", indexNumber, todo.Content, todo.Date)
+		if view == todoViewAll && todo.Done {
+			msg.Textf("%d. ", indexNumber).Italic(todo.Content).Text(" (done)").Newline()
+		} else {
+			msg.Textf("%d. %s", indexNumber, todo.Content).Newline()
+		}
+		if todo.IssueNumber > 0 {
+			msg.Italic(fmt.Sprintf("Added: %s · 🔗 #%d", todo.Date, todo.IssueNumber))
+		} else {
+			msg.Italic("Added: " + todo.Date)
+		}
+		msg.Newline().Newline()
+	}
+	if view == todoViewSort {
+		msg.Italic("Move items with ⬆️ and ⬇️, the order is committed to todo.md.")
+	} else {
+		msg.Italic("Tap an item to check it off or reopen it.")
 	}
 
-	// Create navigation buttons
+	// One row per item: tapping it toggles it, in the reorder view arrows move it
 	var keyboard tgbotapi.InlineKeyboardMarkup
+	for i := start; i < end; i++ {
+		todo := shown[i]
+		label := todoButtonLabel(i+1, todo)
+		toggle := tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("todo_tgl_%d_%s_%d", todo.MessageID, view, offset))
+
+		var row []tgbotapi.InlineKeyboardButton
+		switch {
+		case view == todoViewSort:
+			row = []tgbotapi.InlineKeyboardButton{
+				tgbotapi.NewInlineKeyboardButtonData("⬆️", fmt.Sprintf("todo_up_%d_%d", todo.MessageID, offset)),
+				toggle,
+				tgbotapi.NewInlineKeyboardButtonData("⬇️", fmt.Sprintf("todo_down_%d_%d", todo.MessageID, offset)),
+			}
+		case !todo.Done && todo.IssueNumber == 0:
+			row = []tgbotapi.InlineKeyboardButton{toggle, tgbotapi.NewInlineKeyboardButtonData("🐛 Issue", fmt.Sprintf("todo_issue_%d", todo.MessageID))}
+		default:
+			row = []tgbotapi.InlineKeyboardButton{toggle}
+		}
+		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, row)
+	}
+
+	// Create navigation buttons
 	var navButtons []tgbotapi.InlineKeyboardButton
 
 	// Previous button
 	if offset > 0 {
-		prevOffset := offset - itemsPerPage
+		prevOffset := offset - todoItemsPerPage
 		if prevOffset < 0 {
 			prevOffset = 0
 		}
-		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("◀️ Previous", fmt.Sprintf("todo_more_%d", prevOffset)))
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("◀️ Previous", todoViewCallback(view, prevOffset)))
 	}
 
 	// Next button
-	if end < len(undoneTodos) {
-		nextOffset := offset + itemsPerPage
-		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("Next ▶️", fmt.Sprintf("todo_more_%d", nextOffset)))
+	if end < len(shown) {
+		nextOffset := offset + todoItemsPerPage
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("Next ▶️", todoViewCallback(view, nextOffset)))
 	}
 
 	if len(navButtons) > 0 {
 		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, navButtons)
 	}
 
-	// Add action buttons for each TODO item
-	for i := start; i < end; i++ {
-		todo := undoneTodos[i]
-		indexNumber := i + 1 // Use 1-based indexing for display
-		doneButton := tgbotapi.NewInlineKeyboardButtonData(
-			fmt.Sprintf("✅ Mark %d Done", indexNumber),
-			fmt.Sprintf("todo_done_%d", todo.MessageID),
-		)
-		keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, []tgbotapi.InlineKeyboardButton{doneButton})
+	// Switch between the views
+	var viewButtons []tgbotapi.InlineKeyboardButton
+	switch view {
+	case todoViewSort:
+		viewButtons = append(viewButtons, tgbotapi.NewInlineKeyboardButtonData("✔️ Done reordering", todoViewCallback(todoViewOpen, offset)))
+	case todoViewAll:
+		viewButtons = append(viewButtons, tgbotapi.NewInlineKeyboardButtonData("📋 Hide done", todoViewCallback(todoViewOpen, 0)))
+	default:
+		if len(shown) > 1 {
+			viewButtons = append(viewButtons, tgbotapi.NewInlineKeyboardButtonData("🔀 Reorder", todoViewCallback(todoViewSort, offset)))
+		}
+		viewButtons = append(viewButtons, tgbotapi.NewInlineKeyboardButtonData("📋 Show done", todoViewCallback(todoViewAll, 0)))
 	}
+	keyboard.InlineKeyboard = append(keyboard.InlineKeyboard, viewButtons)
 
 	// Send or edit message
 	if messageID > 0 {
-		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, msg)
-		editMsg.ParseMode = consts.ParseModeHTML
+		editMsg := newRenderedEdit(chatID, messageID, msg)
 		editMsg.ReplyMarkup = &keyboard
 		if _, err := b.rateLimitedSend(chatID, editMsg); err != nil {
 			return fmt.Errorf("failed to edit message: %w", err)
 		}
 	} else {
-		responseMsg := tgbotapi.NewMessage(chatID, msg)
-		responseMsg.ParseMode = consts.ParseModeHTML
+		responseMsg := newRenderedMessage(chatID, msg)
 		responseMsg.ReplyMarkup = keyboard
 		if _, err := b.rateLimitedSend(chatID, responseMsg); err != nil {
 			return fmt.Errorf("failed to send message: %w", err)
@@ -272,13 +335,12 @@ func (b *Bot) handleIssueCommandWithMessageID(chatID int64, messageID int, offse
 		end = len(openIssues)
 	}
 
-	// Create message with issue items
-	var msgText strings.Builder
-	msgText.WriteString("🐛 **Latest Open Issues**\n\n")
+	// Create message with issue items, titles are escaped by the renderer
+	msgText := render.New(render.HTML).Text("🐛 ").Bold("Latest Open Issues").Newline().Newline()
 
 	// Add issue titles to message text
 	for i, issue := range openIssues[start:end] {
-		msgText.WriteString(fmt.Sprintf("%d. **#%d** %s\n", i+1, issue.Number, issue.Title))
+		msgText.Textf("%d. ", i+1).Bold(fmt.Sprintf("#%d", issue.Number)).Line(" " + issue.Title)
 	}
 
 	// Create keyboard with issue item buttons and More button
@@ -286,10 +348,11 @@ func (b *Bot) handleIssueCommandWithMessageID(chatID int64, messageID int, offse
 
 	// Add buttons for each issue item (link, comment, close in one row)
 	for _, issue := range openIssues[start:end] {
-		// Single row: Issue link, Comment, and Close buttons
+		// Single row: Thread (implemented in issue_threads.go), Comment, TODO and Close buttons
 		issueRow := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL(fmt.Sprintf("🔗 #%d", issue.Number), issue.HTMLURL),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🧵 #%d", issue.Number), fmt.Sprintf("issue_thread_%d", issue.Number)),
 			tgbotapi.NewInlineKeyboardButtonData("💬", fmt.Sprintf("issue_comment_%d", issue.Number)),
+			tgbotapi.NewInlineKeyboardButtonData("☑️", fmt.Sprintf("issue_todo_%d", issue.Number)),
 			tgbotapi.NewInlineKeyboardButtonData("✅", fmt.Sprintf("issue_close_%d", issue.Number)),
 		)
 		keyboardRows = append(keyboardRows, issueRow)
@@ -325,8 +388,7 @@ func (b *Bot) handleIssueCommandWithMessageID(chatID int64, messageID int, offse
 
 	// Edit the existing message instead of deleting and creating new
 	if messageID > 0 {
-		editMsg := tgbotapi.NewEditMessageText(chatID, messageID, msgText.String())
-		editMsg.ParseMode = "Markdown"
+		editMsg := newRenderedEdit(chatID, messageID, msgText)
 		if keyboard.InlineKeyboard != nil {
 			editMsg.ReplyMarkup = &keyboard
 		}
@@ -336,8 +398,7 @@ func (b *Bot) handleIssueCommandWithMessageID(chatID int64, messageID int, offse
 				"error": err.Error(),
 			})
 			// Fallback: send new message if editing fails
-			msg := tgbotapi.NewMessage(chatID, msgText.String())
-			msg.ParseMode = "Markdown"
+			msg := newRenderedMessage(chatID, msgText)
 			if keyboard.InlineKeyboard != nil {
 				msg.ReplyMarkup = keyboard
 			}
@@ -350,8 +411,7 @@ func (b *Bot) handleIssueCommandWithMessageID(chatID int64, messageID int, offse
 		}
 	} else {
 		// No message ID provided, send new message
-		msg := tgbotapi.NewMessage(chatID, msgText.String())
-		msg.ParseMode = "Markdown"
+		msg := newRenderedMessage(chatID, msgText)
 		if keyboard.InlineKeyboard != nil {
 			msg.ReplyMarkup = keyboard
 		}
@@ -384,11 +444,7 @@ func (b *Bot) handleCustomFileCommand(message *tgbotapi.Message) error {
 
 	if len(customFiles) == 0 {
 		// Get premium status for file limits
-		var premiumLevel int = 0
-		premiumUser, err := b.db.GetPremiumUser(message.Chat.ID)
-		if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-			premiumLevel = premiumUser.Level
-		}
+		premiumLevel := b.getPremiumLevel(message.Chat.ID)
 
 		customFileLimit := database.GetCustomFileLimit(premiumLevel)
 		tierNames := []string{"Free", "☕ Coffee", "🍰 Cake", "🎁 Sponsor"}
@@ -424,11 +480,7 @@ func (b *Bot) handleCustomFileCommand(message *tgbotapi.Message) error {
 	}
 
 	// Get premium status for file limits
-	var premiumLevel int = 0
-	premiumUser, err := b.db.GetPremiumUser(message.Chat.ID)
-	if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-		premiumLevel = premiumUser.Level
-	}
+	premiumLevel := b.getPremiumLevel(message.Chat.ID)
 
 	customFileLimit := database.GetCustomFileLimit(premiumLevel)
 	tierNames := []string{"Free", "☕ Coffee", "🍰 Cake", "🎁 Sponsor"}
@@ -0,0 +1,87 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Direct path capture: ">> path/to/file.md: content" or "/to path/to/file.md content"
+
+var directPathPrefixRegex = regexp.MustCompile(`(?s)^>>\s*([^:\n]+?)\s*:\s*(.*)$`)
+
+// parseDirectPathPrefix extracts the target path and content from a ">> path:" message
+func parseDirectPathPrefix(text string) (string, string, bool) {
+	matches := directPathPrefixRegex.FindStringSubmatch(strings.TrimSpace(text))
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], strings.TrimSpace(matches[2]), true
+}
+
+// parseToCommand extracts the target path and content from "/to <path> <content>"
+func parseToCommand(text string) (string, string) {
+	args := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "/to"))
+	if args == "" {
+		return "", ""
+	}
+
+	// The path ends at the first space or newline, the rest is the note
+	idx := strings.IndexAny(args, " \n")
+	if idx < 0 {
+		return args, ""
+	}
+	return args[:idx], strings.TrimSpace(args[idx+1:])
+}
+
+func (b *Bot) handleToCommand(message *tgbotapi.Message) error {
+	targetPath, content := parseToCommand(message.Text)
+	return b.commitToDirectPath(message, targetPath, content)
+}
+
+// commitToDirectPath validates targetPath and commits content to it, creating the file if needed
+func (b *Bot) commitToDirectPath(message *tgbotapi.Message, targetPath, content string) error {
+	chatID := message.Chat.ID
+
+	if targetPath == "" || content == "" {
+		b.sendResponse(chatID, "📝 <b>Usage:</b>\n<code>&gt;&gt; path/to/file.md: your note</code>\nor\n<code>/to path/to/file.md your note</code>")
+		return nil
+	}
+
+	filePath, err := validateRepoPath(targetPath)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if filePath == "" {
+		b.sendResponse(chatID, "❌ Please provide a file path, e.g. <code>notes/ideas.md</code>")
+		return nil
+	}
+
+	logger.Info("Committing message to direct path", map[string]interface{}{
+		"chat_id": chatID,
+		"path":    filePath,
+	})
+
+	// Ensure user exists with the sender's username before the save flow runs
+	if _, err := b.ensureUser(message); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	statusMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📝 Saving to %s...", filePath))
+	sent, err := b.rateLimitedSend(chatID, statusMsg)
+	if err != nil {
+		return fmt.Errorf("failed to send status message: %w", err)
+	}
+
+	// Reuse the custom file save flow, which edits the status message as it progresses
+	callback := &tgbotapi.CallbackQuery{
+		From:    message.From,
+		Message: &sent,
+	}
+	return b.saveMessageToCustomFile(callback, filePath, content, message.MessageID, "", false, false)
+}
@@ -0,0 +1,50 @@
+package telegram
+
+import "testing"
+
+func TestParseDirectPathPrefix(t *testing.T) {
+	tests := []struct {
+		name            string
+		text            string
+		expectedPath    string
+		expectedContent string
+		expectedOK      bool
+	}{
+		{"basic", ">> notes/ideas.md: build a bot", "notes/ideas.md", "build a bot", true},
+		{"no space", ">>todo.md:buy milk", "todo.md", "buy milk", true},
+		{"multiline content", ">> journal/2024.md:\nline one\nline two", "journal/2024.md", "line one\nline two", true},
+		{"content with colon", ">> a.md: time: 10:00", "a.md", "time: 10:00", true},
+		{"plain message", "just a note", "", "", false},
+		{"quote without path", ">> no colon here", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, content, ok := parseDirectPathPrefix(tt.text)
+			if ok != tt.expectedOK || path != tt.expectedPath || content != tt.expectedContent {
+				t.Errorf("parseDirectPathPrefix(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.text, path, content, ok, tt.expectedPath, tt.expectedContent, tt.expectedOK)
+			}
+		})
+	}
+}
+
+func TestParseToCommand(t *testing.T) {
+	tests := []struct {
+		text            string
+		expectedPath    string
+		expectedContent string
+	}{
+		{"/to notes/a.md hello world", "notes/a.md", "hello world"},
+		{"/to notes/a.md\nmultiline\nnote", "notes/a.md", "multiline\nnote"},
+		{"/to notes/a.md", "notes/a.md", ""},
+		{"/to", "", ""},
+	}
+
+	for _, tt := range tests {
+		path, content := parseToCommand(tt.text)
+		if path != tt.expectedPath || content != tt.expectedContent {
+			t.Errorf("parseToCommand(%q) = (%q, %q), want (%q, %q)", tt.text, path, content, tt.expectedPath, tt.expectedContent)
+		}
+	}
+}
@@ -94,6 +94,13 @@ func (b *Bot) handleInsightCommand(message *tgbotapi.Message) error {
 		}
 	}
 
+	// Self-hosted deployments grant premium levels from config
+	if configuredLevel := b.cfg().ConfiguredPremiumLevel(message.Chat.ID); configuredLevel > premiumLevel {
+		premiumLevel = configuredLevel
+		isPremium = true
+		premiumInfo = GetTierName(premiumLevel) + " (configured)"
+	}
+
 	// Get repository status information (integrated from /repostatus)
 	var repoStatusSection string
 	userGitHubProvider, err := b.getUserGitHubProvider(message.Chat.ID)
@@ -141,7 +148,7 @@ Use /repo to setup`
 		} else if userGitHubProvider.NeedsClone() {
 			sizeSource = "(Remote API)"
 		} else {
-			sizeSource = "(Actual cloned size)"
+			sizeSource = "(Cloned content, excludes .git)"
 		}
 		
 		// Get max size for display
@@ -192,6 +199,7 @@ Use /repo to setup`
 	issueCloses := int64(0)
 	currentIssues := int64(0)
 	currentImages := int64(0)
+	currentFiles := int64(0)
 
 	if insights != nil {
 		totalCommits = insights.CommitCnt
@@ -205,11 +213,13 @@ Use /repo to setup`
 	if usage != nil {
 		currentIssues = usage.IssueCnt
 		currentImages = usage.ImageCnt
+		currentFiles = usage.FileCnt
 	}
 
 	// Get limits
 	issueLimit := database.GetIssueLimit(premiumLevel)
 	imageLimit := database.GetImageLimit(premiumLevel)
+	fileLimit := database.GetFileLimit(premiumLevel)
 
 	// Get token limit and calculate percentage
 	tokenLimit := database.GetTokenLimit(premiumLevel)
@@ -221,6 +231,7 @@ Use /repo to setup`
 	// Calculate percentages
 	issuePercentage := float64(currentIssues) / float64(issueLimit) * 100
 	imagePercentage := float64(currentImages) / float64(imageLimit) * 100
+	filePercentage := float64(currentFiles) / float64(fileLimit) * 100
 	tokenPercentage := float64(currentTokens) / float64(tokenLimit) * 100
 
 	// Generate commit graph
@@ -234,9 +245,15 @@ Use /repo to setup`
 		commitGraph = "📊 <b>30-Day Commit Activity</b>\n<i>Unable to fetch commit data</i>\n"
 	}
 
+	// Monthly mood chart of chats tracking moods (implemented in mood.go)
+	if moodChart := b.moodChartSection(message.Chat.ID, time.Now()); moodChart != "" {
+		commitGraph = strings.TrimRight(commitGraph, "\n") + "\n\n" + moodChart
+	}
+
 	// Format right-aligned usage lines
 	issuesLine := b.formatUsageLine("📝 Issues:", currentIssues, issueLimit, issuePercentage)
 	imagesLine := b.formatUsageLine("📷 Images:", currentImages, imageLimit, imagePercentage)
+	filesLine := b.formatUsageLine("📎 Files:", currentFiles, fileLimit, filePercentage)
 	tokensLine := b.formatTokenUsageLine("🧠 Tokens:", currentTokens, tokenLimit, tokenPercentage)
 
 	// Format insight token usage information (all-time stats)
@@ -259,6 +276,7 @@ Use /repo to setup`
 %s
 %s
 %s
+%s
 
 <b>🎯 All-Time Stats:</b>
 💾 Commits: %d | 📝 Issues: %d
@@ -273,6 +291,7 @@ Use /repo to setup`
 		repoStatusSection,
 		issuesLine,
 		imagesLine,
+		filesLine,
 		tokensLine,
 		totalCommits,
 		totalIssues,
@@ -296,8 +315,23 @@ Use /repo to setup`
 }
 
 func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
+	// "/sync dry" reports what a sync would change without committing anything
+	dryRun := false
+	if args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/sync")); len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "dry", "dry-run", "preview":
+			dryRun = true
+		case "mode":
+			return b.handleSyncModeCommand(message, args[1:]) // Implemented in sync_commit.go
+		default:
+			b.sendResponse(message.Chat.ID, "❓ Usage:\n\n• /sync - Synchronize issue statuses from GitHub\n• /sync dry - Preview what a sync would change\n• /sync mode - Commit changed files together or one by one")
+			return nil
+		}
+	}
+
 	logger.Info("Starting issue status sync", map[string]interface{}{
 		"chat_id": message.Chat.ID,
+		"dry_run": dryRun,
 	})
 
 	// Get user-specific GitHub manager
@@ -322,7 +356,11 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 	}
 
 	// Send status message and get message ID for later editing
-	statusMessageID := b.sendResponseAndGetMessageID(message.Chat.ID, "🔄 Syncing issue statuses...")
+	startText := "🔄 Syncing issue statuses..."
+	if dryRun {
+		startText = "🔍 Previewing issue status sync..."
+	}
+	statusMessageID := b.sendResponseAndGetMessageID(message.Chat.ID, startText)
 
 	// Acquire locks for both issue files before reading anything
 	userID, err := b.getUserIDForLocking(message.Chat.ID)
@@ -397,7 +435,10 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 		"timeout":    "5 minutes",
 	})
 
-	// NOW safe to read current issue.md file (with locks held)
+	// Phase 1: read issue.md (with locks held)
+	if statusMessageID > 0 {
+		b.updateProgressMessage(message.Chat.ID, statusMessageID, 10, "📖 Reading issue.md...")
+	}
 	issueContent, err := userGitHubProvider.ReadFile("issue.md")
 	if err != nil {
 		logger.Error("Failed to read issue.md", map[string]interface{}{
@@ -438,13 +479,37 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 		"count": len(currentStatuses),
 	})
 
-	// Prepare archiving - move ALL closed issues to archive for maximum GraphQL optimization
-	var archivedCount, archivedOpen, archivedClosed int
-	var activeIssueNumbers []int
-	var archiveContent string
+	// Plan archiving - open issues get synced, closed issues past the user's retention get archived
+	archiveDays, archiveYearly := b.issueArchiveSettings(message.Chat.ID)
+	if statusMessageID > 0 {
+		b.updateProgressMessage(message.Chat.ID, statusMessageID, 25, "📦 Planning issue archive...")
+	}
+	plan := planIssueArchive(currentStatuses, archiveDays, archiveYearly, time.Now())
+	archivedCount := plan.ArchivedCount()
 
-	// Always check for closed issues to archive (no threshold needed)
-	archivedCount, archivedOpen, archivedClosed, activeIssueNumbers, archiveContent, err = b.prepareArchiveOptimized(message.Chat.ID, userGitHubProvider, currentStatuses, statusMessageID)
+	// Per-year archive files are only known now, lock them like issue_archived.md
+	for _, filename := range plan.ArchiveFiles() {
+		if filename == consts.IssueArchiveFile {
+			continue
+		}
+		yearHandle, err := flm.AcquireFileLock(ctx, userID, repoURL, filename, true)
+		if err != nil {
+			logger.Error("Failed to acquire lock for archive file", map[string]interface{}{
+				"error":   err.Error(),
+				"file":    filename,
+				"chat_id": message.Chat.ID,
+			})
+			if statusMessageID > 0 {
+				b.editMessage(message.Chat.ID, statusMessageID, fmt.Sprintf("❌ Failed to acquire lock for %s - another sync may be in progress", filename))
+			} else {
+				b.sendResponse(message.Chat.ID, fmt.Sprintf("❌ Failed to acquire lock for %s - another sync may be in progress", filename))
+			}
+			return err
+		}
+		defer yearHandle.Release()
+	}
+
+	archiveFiles, err := b.prepareArchiveFiles(userGitHubProvider, plan)
 	if err != nil {
 		logger.Error("Failed to prepare archiving", map[string]interface{}{
 			"error": err.Error(),
@@ -458,15 +523,19 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 	}
 
 	logger.Info("Issues prepared for efficient sync", map[string]interface{}{
-		"original_count":  len(currentStatuses),
-		"active_count":    len(activeIssueNumbers),
-		"archived_count":  archivedCount,
-		"archived_open":   archivedOpen,
-		"archived_closed": archivedClosed,
+		"original_count": len(currentStatuses),
+		"active_count":   len(plan.Active),
+		"retained_count": len(plan.Retained),
+		"archived_count": archivedCount,
+		"archive_days":   archiveDays,
+		"archive_files":  plan.ArchiveFiles(),
 	})
 
-	// NOW make GraphQL call for ONLY the open issues (much fewer than 121!)
-	statuses, err := userGitHubProvider.SyncIssueStatuses(activeIssueNumbers)
+	// Phase 2: fetch statuses for ONLY the open issues (much fewer than 121!)
+	if statusMessageID > 0 {
+		b.updateProgressMessage(message.Chat.ID, statusMessageID, 40, fmt.Sprintf("🔍 Fetching %d issue statuses from GitHub...", len(plan.Active)))
+	}
+	statuses, err := userGitHubProvider.SyncIssueStatuses(plan.Active)
 	if err != nil {
 		logger.Error("Failed to sync issue statuses", map[string]interface{}{
 			"error": err.Error(),
@@ -491,30 +560,74 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 		})
 	}
 
-	// Generate completely new issue.md content with current statuses
+	// Compare with issue.md before merging; issues GitHub didn't return (deleted, transferred,
+	// or cut off by the rate limit) are kept unchanged instead of silently dropped
+	report := buildSyncReport(currentStatuses, statuses, plan.Active)
+	report.DryRun = dryRun
+	report.Unrecognized = countUnrecognizedIssueLines(issueContent)
+	for filename, issues := range plan.Archived {
+		report.Archived[filename] = len(issues)
+	}
+	for _, number := range report.Skipped {
+		statuses[number] = currentStatuses[number]
+	}
+	if len(report.Skipped) > 0 {
+		logger.Warn("Issues not returned by GitHub during sync", map[string]interface{}{
+			"chat_id": message.Chat.ID,
+			"issues":  report.Skipped,
+		})
+	}
+
+	// Issues closed since the last sync start their retention period now, retained ones stay listed
+	stampClosedAt(statuses, time.Now())
+	for _, issue := range plan.Retained {
+		if _, ok := statuses[issue.Number]; !ok {
+			statuses[issue.Number] = issue
+		}
+	}
+
+	// Phase 3: generate completely new issue.md content with current statuses
+	if statusMessageID > 0 {
+		b.updateProgressMessage(message.Chat.ID, statusMessageID, 70, "📝 Updating issue.md...")
+	}
 	newContent := b.generateIssueContent(statuses, userGitHubProvider)
 
+	var closedIssues []int
+	for _, status := range statuses {
+		if strings.ToLower(status.State) == "open" {
+			report.Open++
+		} else {
+			report.Closed++
+		}
+		if strings.ToLower(status.State) == "closed" {
+			closedIssues = append(closedIssues, status.Number)
+		}
+	}
+
+	if dryRun {
+		report.TodosChecked = b.countLinkedTodos(message.Chat.ID, userGitHubProvider, closedIssues)
+		logger.Info("Sync dry run finished", map[string]interface{}{
+			"chat_id":     message.Chat.ID,
+			"has_changes": report.HasChanges(),
+			"skipped":     len(report.Skipped),
+		})
+		b.editOrSendHTML(message.Chat.ID, statusMessageID, report.Format("", nil))
+		return nil
+	}
+
+	// Phase 4: commit
+	if statusMessageID > 0 {
+		b.updateProgressMessage(message.Chat.ID, statusMessageID, 85, "💾 Committing changes...")
+	}
+
 	// Handle commit - single file or multiple files depending on whether archiving occurred
 	commitMsg := "Sync issue statuses via Telegram"
-	committerInfo := b.getCommitterInfo(message.Chat.ID)
-	premiumLevel := b.getPremiumLevel(message.Chat.ID)
 
 	if archivedCount > 0 {
-		// If archiving occurred, commit both files together using prepared archive content
+		// If archiving occurred, commit issue.md and the archive files in the user's sync commit mode
 		commitMsg = fmt.Sprintf("Sync issue statuses via Telegram (archived %d issues)", archivedCount)
-		// Use locked version since we already hold the file locks
-		if apiProvider, ok := userGitHubProvider.(*github.APIBasedProvider); ok {
-			err = apiProvider.ReplaceMultipleFilesWithAuthorAndPremiumLocked(map[string]string{
-				"issue.md":              newContent,
-				consts.IssueArchiveFile: archiveContent,
-			}, commitMsg, committerInfo, premiumLevel)
-		} else {
-			err = userGitHubProvider.ReplaceMultipleFilesWithAuthorAndPremium(map[string]string{
-				"issue.md":              newContent,
-				consts.IssueArchiveFile: archiveContent,
-			}, commitMsg, committerInfo, premiumLevel)
-		}
-		if err != nil {
+		archiveFiles["issue.md"] = newContent
+		if err := b.commitSyncFiles(message.Chat.ID, userGitHubProvider, archiveFiles, commitMsg); err != nil {
 			logger.Error("Failed to commit updated files", map[string]interface{}{
 				"error": err.Error(),
 			})
@@ -525,8 +638,10 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 			}
 			return err
 		}
-	} else {
+	} else if newContent != issueContent {
 		// Normal single file commit
+		committerInfo := b.getCommitterInfo(message.Chat.ID)
+		premiumLevel := b.getPremiumLevel(message.Chat.ID)
 		if err := userGitHubProvider.ReplaceFileWithAuthorAndPremium("issue.md", newContent, commitMsg, committerInfo, premiumLevel); err != nil {
 			logger.Error("Failed to commit updated issue.md", map[string]interface{}{
 				"error": err.Error(),
@@ -540,27 +655,14 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 		}
 	}
 
-	// Count issues for success message
-	openCount := 0
-	closedCount := 0
-	for _, status := range statuses {
-		logger.Debug("Sync counting issue", map[string]interface{}{
-			"number":       status.Number,
-			"state":        status.State,
-			"state_length": len(status.State),
-			"is_open":      strings.ToLower(status.State) == "open",
-		})
-		if strings.ToLower(strings.TrimSpace(status.State)) == "open" {
-			openCount++
-		} else {
-			closedCount++
-		}
-	}
+	// Check off TODOs linked to issues closed on GitHub (implemented in todo_issue_links.go)
+	report.TodosChecked = b.completeLinkedTodos(message.Chat.ID, userGitHubProvider, closedIssues)
 
 	logger.Info("Sync final counts", map[string]interface{}{
-		"open_count":   openCount,
-		"closed_count": closedCount,
+		"open_count":   report.Open,
+		"closed_count": report.Closed,
 		"total_count":  len(statuses),
+		"skipped":      len(report.Skipped),
 	})
 
 	// Generate GitHub links for the files using proper branch detection
@@ -572,23 +674,21 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 		issueFileLink = "issue.md" // Fallback to filename only
 	}
 
-	archiveFileLink, err := userGitHubProvider.GetGitHubFileURLWithBranch(consts.IssueArchiveFile)
-	if err != nil {
-		logger.Warn("Failed to get archive file GitHub URL", map[string]interface{}{
-			"error": err.Error(),
-		})
-		archiveFileLink = consts.IssueArchiveFile // Fallback to filename only
+	archiveLinks := make(map[string]string)
+	for _, filename := range plan.ArchiveFiles() {
+		archiveFileLink, err := userGitHubProvider.GetGitHubFileURLWithBranch(filename)
+		if err != nil {
+			logger.Warn("Failed to get archive file GitHub URL", map[string]interface{}{
+				"error": err.Error(),
+				"file":  filename,
+			})
+			archiveFileLink = filename // Fallback to filename only
+		}
+		archiveLinks[filename] = archiveFileLink
 	}
 
-	// Edit the status message to show success with archiving info and GitHub links
-	var successMsg string
-	if archivedCount > 0 {
-		successMsg = fmt.Sprintf("✅ Synced %d open issues 🟢\n📦 Archived all %d closed issues 🔴 to <a href=\"%s\">%s</a>\n\n🔗 <a href=\"%s\">View issue.md</a> (open issues only)",
-			len(statuses), archivedCount, archiveFileLink, consts.IssueArchiveFile, issueFileLink)
-	} else {
-		successMsg = fmt.Sprintf("✅ Synced %d issues: %d open 🟢, %d closed 🔴\n\n🔗 <a href=\"%s\">View issue.md</a>",
-			len(statuses), openCount, closedCount, issueFileLink)
-	}
+	// Edit the status message with the sync report and GitHub links
+	successMsg := report.Format(issueFileLink, archiveLinks)
 
 	if statusMessageID > 0 {
 		editMsg := tgbotapi.NewEditMessageText(message.Chat.ID, statusMessageID, successMsg)
@@ -615,86 +715,6 @@ func (b *Bot) handleSyncCommand(message *tgbotapi.Message) error {
 	return nil
 }
 
-// prepareArchiveOptimized handles archiving prep using current file statuses and returns active issue numbers + archive content
-func (b *Bot) prepareArchiveOptimized(chatID int64, githubProvider github.GitHubProvider, currentStatuses map[int]*github.IssueStatus, statusMessageID int) (archivedCount, archivedOpen, archivedClosed int, activeIssueNumbers []int, archiveContent string, err error) {
-	logger.Info("Starting optimized issue truncation and archiving", map[string]interface{}{
-		"chat_id":      chatID,
-		"total_issues": len(currentStatuses),
-	})
-
-	// Update status message to show archiving in progress
-	if statusMessageID > 0 {
-		b.editMessage(chatID, statusMessageID, "🔄 Archiving old issues to optimize sync...")
-	}
-
-	// Convert to slice for sorting (using current state info from file)
-	var issueList []*github.IssueStatus
-	for _, status := range currentStatuses {
-		issueList = append(issueList, status)
-	}
-
-	// Step 1: Sort issues: open issues first, then closed issues, both by number (descending)
-	b.sortIssuesForArchiving(issueList)
-
-	// Step 2: Split issues - ALL open issues stay active, ALL closed issues get archived
-	var activeIssues []*github.IssueStatus
-	var archivedIssues []*github.IssueStatus
-
-	for _, issue := range issueList {
-		if strings.ToLower(issue.State) == "open" {
-			activeIssues = append(activeIssues, issue)
-		} else {
-			archivedIssues = append(archivedIssues, issue)
-		}
-	}
-
-	if len(archivedIssues) == 0 {
-		logger.Info("No closed issues to archive", map[string]interface{}{
-			"total_issues": len(issueList),
-			"open_issues":  len(activeIssues),
-		})
-		// Return all issue numbers for GraphQL (all are open)
-		activeIssueNumbers = make([]int, 0, len(activeIssues))
-		for _, issue := range activeIssues {
-			activeIssueNumbers = append(activeIssueNumbers, issue.Number)
-		}
-		return 0, 0, 0, activeIssueNumbers, "", nil
-	}
-
-	// Extract active issue numbers for GraphQL call
-	activeIssueNumbers = make([]int, len(activeIssues))
-	for i, issue := range activeIssues {
-		activeIssueNumbers[i] = issue.Number
-	}
-
-	// Count archived issue states (all archived issues are closed by design)
-	archivedCount = len(archivedIssues)
-	archivedClosed = len(archivedIssues)
-	archivedOpen = 0 // No open issues should be archived with this strategy
-
-	logger.Info("Issue archiving breakdown", map[string]interface{}{
-		"total_archived":   archivedCount,
-		"archived_open":    archivedOpen,
-		"archived_closed":  archivedClosed,
-		"remaining_active": len(activeIssues),
-	})
-
-	// Step 3: Prepare archive content (in bullet format) using current status info
-	newArchiveContent, err := b.prepareArchiveContent(githubProvider, archivedIssues)
-	if err != nil {
-		return 0, 0, 0, nil, "", fmt.Errorf("failed to prepare archive content: %w", err)
-	}
-
-	logger.Info("Issue archiving prepared successfully", map[string]interface{}{
-		"archived_count":  archivedCount,
-		"archived_open":   archivedOpen,
-		"archived_closed": archivedClosed,
-		"active_numbers":  len(activeIssueNumbers),
-	})
-
-	return archivedCount, archivedOpen, archivedClosed, activeIssueNumbers, newArchiveContent, nil
-}
-
 // sortIssuesForArchiving sorts issues with open issues first, then closed issues
 // Within each group, sort by issue number descending (newest first)
 // Uses Go's built-in sort.Slice for O(n log n) performance
@@ -723,41 +743,42 @@ func (b *Bot) generateIssueContentFromStatuses(issues []*github.IssueStatus, git
 	var lines []string
 
 	for _, issue := range issues {
-		emoji := "🟢"
-		if strings.ToLower(issue.State) == "closed" {
-			emoji = "🔴"
-		}
-		line := fmt.Sprintf("- %s %s/%s#%d [%s]", emoji, owner, repo, issue.Number, issue.Title)
-		lines = append(lines, line)
+		lines = append(lines, formatIssueLine(owner, repo, issue))
 	}
 
 	return strings.Join(lines, "\n") + "\n"
 }
 
+// prepareArchiveFiles returns the new content of each archive file in the plan
+func (b *Bot) prepareArchiveFiles(githubProvider github.GitHubProvider, plan *issueArchivePlan) (map[string]string, error) {
+	files := make(map[string]string)
+	for _, filename := range plan.ArchiveFiles() {
+		content, err := b.prepareArchiveContent(githubProvider, filename, plan.Archived[filename])
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare archive content: %w", err)
+		}
+		files[filename] = content
+	}
+	return files, nil
+}
+
 // prepareArchiveContent prepares archive content by prepending archived issues in bullet format
-func (b *Bot) prepareArchiveContent(githubProvider github.GitHubProvider, archivedIssues []*github.IssueStatus) (string, error) {
+func (b *Bot) prepareArchiveContent(githubProvider github.GitHubProvider, filename string, archivedIssues []*github.IssueStatus) (string, error) {
 	// Read existing archive content
-	existingContent, err := githubProvider.ReadFile(consts.IssueArchiveFile)
+	existingContent, err := githubProvider.ReadFile(filename)
 	if err != nil {
 		// If file doesn't exist, start with empty content
 		existingContent = ""
 		logger.Info("Archive file doesn't exist, will create new one", map[string]interface{}{
-			"filename": consts.IssueArchiveFile,
+			"filename": filename,
 		})
 	}
 
 	// Generate archived issues in bullet format (same as issue.md)
+	owner, repo, _ := githubProvider.GetRepoInfo()
 	var archiveContent strings.Builder
 	for _, issue := range archivedIssues {
-		emoji := "🟢"
-		if strings.ToLower(issue.State) == "closed" {
-			emoji = "🔴"
-		}
-
-		// Get repo info for consistent formatting
-		owner, repo, _ := githubProvider.GetRepoInfo()
-		line := fmt.Sprintf("- %s %s/%s#%d [%s]", emoji, owner, repo, issue.Number, issue.Title)
-		archiveContent.WriteString(line + "\n")
+		archiveContent.WriteString(formatIssueLine(owner, repo, issue) + "\n")
 	}
 
 	// Prepend archived issues to existing content
@@ -787,7 +808,14 @@ func (b *Bot) generateRepositoryInsights(githubProvider github.GitHubProvider) s
 	commonFiles := []string{"note.md", "todo.md", "issue.md", "idea.md", "inbox.md", "tool.md"}
 
 	for _, filename := range commonFiles {
-		content, err := githubProvider.ReadFile(filename)
+		// Only TODOs and issues are counted, the start of other files tells whether they have content
+		var content string
+		var err error
+		if filename == "todo.md" || filename == "issue.md" {
+			content, err = githubProvider.ReadFile(filename)
+		} else {
+			content, _, err = github.ReadFileHead(githubProvider, filename, 64)
+		}
 		if err == nil && content != "" {
 			insights.TotalFiles++
 
@@ -913,11 +941,25 @@ func (b *Bot) handleStatsCommand(message *tgbotapi.Message) error {
 		})
 	}
 
-	// Calculate total token usage
+	// Format the statistics message
+	statsMsg := formatStatsMessage("Global Bot Statistics", stats) + "\n\n🏆 Opt in to the community ranking with /leaderboard"
+
+	// Edit the loading message with the complete statistics
+	editMsg := tgbotapi.NewEditMessageText(message.Chat.ID, statusMessageID, statsMsg)
+	editMsg.ParseMode = "HTML"
+
+	if _, err := b.rateLimitedSend(message.Chat.ID, editMsg); err != nil {
+		return fmt.Errorf("failed to edit stats message: %w", err)
+	}
+
+	return nil
+}
+
+// formatStatsMessage renders aggregated statistics, for all users or a tenant's members
+func formatStatsMessage(title string, stats *database.GlobalStats) string {
 	totalTokens := stats.TotalTokenInput + stats.TotalTokenOutput
 
-	// Format the statistics message
-	statsMsg := fmt.Sprintf(`📊 <b>Global Bot Statistics</b>
+	return fmt.Sprintf(`📊 <b>%s</b>
 
 🌍 <b>Total Users:</b> %d
 💾 <b>Total Commits:</b> %d
@@ -931,6 +973,7 @@ func (b *Bot) handleStatsCommand(message *tgbotapi.Message) error {
 📁 <b>Total Repo Size:</b> %.2f MB
 
 <i>Statistics are updated in real-time</i>`,
+		title,
 		stats.TotalUsers,
 		stats.TotalCommits,
 		stats.TotalIssues,
@@ -943,14 +986,4 @@ func (b *Bot) handleStatsCommand(message *tgbotapi.Message) error {
 		stats.TotalSyncCmds,
 		stats.TotalInsightCmds,
 		stats.TotalRepoSizeMB)
-
-	// Edit the loading message with the complete statistics
-	editMsg := tgbotapi.NewEditMessageText(message.Chat.ID, statusMessageID, statsMsg)
-	editMsg.ParseMode = "HTML"
-
-	if _, err := b.rateLimitedSend(message.Chat.ID, editMsg); err != nil {
-		return fmt.Errorf("failed to edit stats message: %w", err)
-	}
-
-	return nil
 }
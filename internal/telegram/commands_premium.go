@@ -145,9 +145,9 @@ Thank you for supporting the project! 🙏
 		))
 
 		// Add website contact link if BASE_URL is configured
-		if b.config.BaseURL != "" {
+		if b.cfg().BaseURL != "" {
 			keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
-				tgbotapi.NewInlineKeyboardButtonURL("🌐 Contact Us", b.config.BaseURL+"/contact"),
+				tgbotapi.NewInlineKeyboardButtonURL("🌐 Contact Us", b.cfg().BaseURL+"/contact"),
 			))
 		}
 
@@ -235,10 +235,17 @@ Support the development of this bot and unlock premium features!
 		tgbotapi.NewInlineKeyboardButtonData("🎁 Sponsor Annual", "subscription_sponsor_annual"),
 	))
 
+	// One free trial per user
+	if canStartTrial(premiumUser) {
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🎁 Try %s free for %d days", GetTierName(premiumTrialLevel), int(premiumTrialDuration.Hours()/24)), "premium_trial_start"),
+		))
+	}
+
 	// Add website contact link if BASE_URL is configured
 	contactRow := make([]tgbotapi.InlineKeyboardButton, 0, 2)
-	if b.config.BaseURL != "" {
-		contactRow = append(contactRow, tgbotapi.NewInlineKeyboardButtonURL("🌐 Contact Us", b.config.BaseURL+"/contact"))
+	if b.cfg().BaseURL != "" {
+		contactRow = append(contactRow, tgbotapi.NewInlineKeyboardButtonURL("🌐 Contact Us", b.cfg().BaseURL+"/contact"))
 	}
 	contactRow = append(contactRow, tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "coffee_cancel"))
 
@@ -271,19 +278,7 @@ func (b *Bot) handleResetUsageCommand(message *tgbotapi.Message) error {
 	}
 
 	// Get premium user level (if any) for limits
-	premiumUser, err := b.db.GetPremiumUser(message.Chat.ID)
-	if err != nil {
-		logger.Warn("Failed to get premium user status, using free tier limits", map[string]interface{}{
-			"error":   err.Error(),
-			"chat_id": message.Chat.ID,
-		})
-	}
-
-	// Determine user level (default to free tier)
-	var userLevel int = 0
-	if premiumUser != nil && premiumUser.IsPremiumUser() {
-		userLevel = premiumUser.Level
-	}
+	userLevel := b.getPremiumLevel(message.Chat.ID)
 
 	// Get current usage statistics
 	usage, err := b.db.GetUserUsage(message.Chat.ID)
@@ -369,20 +364,8 @@ func (b *Bot) handleResetUsageConfirmation(callback *tgbotapi.CallbackQuery) err
 		return nil
 	}
 
-	// Get premium user status for tier display
-	premiumUser, err := b.db.GetPremiumUser(callback.Message.Chat.ID)
-	if err != nil {
-		logger.Warn("Failed to get premium user status for reset, using free tier", map[string]interface{}{
-			"error":   err.Error(),
-			"chat_id": callback.Message.Chat.ID,
-		})
-	}
-
-	// Determine user level (default to free tier)
-	var userLevel int = 0
-	if premiumUser != nil && premiumUser.IsPremiumUser() {
-		userLevel = premiumUser.Level
-	}
+	// Get premium user level (if any) for limits
+	userLevel := b.getPremiumLevel(callback.Message.Chat.ID)
 
 	// Check if Stripe is available
 	if b.stripeManager == nil {
@@ -602,23 +585,7 @@ func (b *Bot) handleUsageCheck(callback *tgbotapi.CallbackQuery) error {
 	}
 
 	// Get premium user info
-	premiumUser, err := b.db.GetPremiumUser(callback.Message.Chat.ID)
-	if err != nil {
-		logger.Error("Failed to get premium user for usage check", map[string]interface{}{
-			"error":   err.Error(),
-			"chat_id": callback.Message.Chat.ID,
-		})
-		b.editMessage(callback.Message.Chat.ID, callback.Message.MessageID, "❌ Failed to check usage")
-		return nil
-	}
-
-	premiumLevel := 0
-
-	if premiumUser != nil {
-		if premiumUser.IsPremiumUser() {
-			premiumLevel = premiumUser.Level
-		}
-	}
+	premiumLevel := b.getPremiumLevel(callback.Message.Chat.ID)
 
 	// Get limits
 	imageLimit := database.GetImageLimit(premiumLevel)
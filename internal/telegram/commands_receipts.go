@@ -0,0 +1,313 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Receipts: payment history from user_topup_log and Stripe, with hosted receipt links
+
+const (
+	maxReceiptsListed     = 10
+	maxStripeInvoices     = 20
+	receiptTotalsMonths   = 12
+	receiptCallbackLog    = "receipt_log_"
+	receiptCallbackStripe = "receipt_inv_"
+)
+
+// receiptEntry is one payment, from the topup log or from Stripe directly
+type receiptEntry struct {
+	LogID     int    // user_topup_log id, 0 for invoices only known to Stripe
+	InvoiceID string // Stripe invoice, for subscription payments
+	SessionID string // Stripe checkout session, for one-time payments
+	Service   string
+	Amount    float64
+	Currency  string // ISO code from Stripe, empty for the bot's own prices in dollars
+	Date      time.Time
+}
+
+// monthTotal is the amount paid in one calendar month and currency
+type monthTotal struct {
+	Month    string // YYYY-MM
+	Currency string
+	Amount   float64
+	Count    int
+}
+
+// formatReceiptAmount formats an amount in its currency, "$" for dollars
+func formatReceiptAmount(amount float64, currency string) string {
+	if currency == "" || currency == "USD" {
+		return fmt.Sprintf("$%.2f", amount)
+	}
+	return fmt.Sprintf("%.2f %s", amount, currency)
+}
+
+// mergeReceiptEntries adds Stripe invoices missing from the topup log and sorts newest first.
+// Logged payments take the currency of their invoice, the topup log doesn't record one.
+func mergeReceiptEntries(logged []receiptEntry, invoices []receiptEntry) []receiptEntry {
+	currencies := make(map[string]string)
+	for _, inv := range invoices {
+		currencies[inv.InvoiceID] = inv.Currency
+	}
+
+	known := make(map[string]bool)
+	entries := make([]receiptEntry, 0, len(logged)+len(invoices))
+	for _, e := range logged {
+		if e.InvoiceID != "" {
+			known[e.InvoiceID] = true
+			if currency, ok := currencies[e.InvoiceID]; ok {
+				e.Currency = currency
+			}
+		}
+		entries = append(entries, e)
+	}
+	for _, inv := range invoices {
+		if !known[inv.InvoiceID] {
+			entries = append(entries, inv)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.After(entries[j].Date)
+	})
+	return entries
+}
+
+// monthlyReceiptTotals sums entries per calendar month and currency, newest month first, limited
+// to months
+func monthlyReceiptTotals(entries []receiptEntry, months int) []monthTotal {
+	type key struct{ month, currency string }
+	byKey := make(map[key]*monthTotal)
+	var totals []*monthTotal
+	for _, e := range entries {
+		k := key{e.Date.Format("2006-01"), e.Currency}
+		total, ok := byKey[k]
+		if !ok {
+			total = &monthTotal{Month: k.month, Currency: k.currency}
+			byKey[k] = total
+			totals = append(totals, total)
+		}
+		total.Amount += e.Amount
+		total.Count++
+	}
+
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].Month != totals[j].Month {
+			return totals[i].Month > totals[j].Month
+		}
+		return totals[i].Currency < totals[j].Currency
+	})
+
+	result := make([]monthTotal, 0, len(totals))
+	seenMonths := 0
+	for i, total := range totals {
+		if i == 0 || total.Month != totals[i-1].Month {
+			seenMonths++
+		}
+		if seenMonths > months {
+			break
+		}
+		result = append(result, *total)
+	}
+	return result
+}
+
+// loadReceiptEntries collects a user's payments from the topup log and, when available, Stripe
+func (b *Bot) loadReceiptEntries(chatID int64) ([]receiptEntry, error) {
+	logs, err := b.db.GetUserTopupLogs(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	logged := make([]receiptEntry, 0, len(logs))
+	for _, l := range logs {
+		logged = append(logged, receiptEntry{
+			LogID:     l.ID,
+			InvoiceID: l.InvoiceID,
+			SessionID: l.TransactionID,
+			Service:   l.Service,
+			Amount:    l.Amount,
+			Date:      l.CreatedAt,
+		})
+	}
+
+	var invoices []receiptEntry
+	if b.stripeManager != nil {
+		if premiumUser, err := b.db.GetPremiumUser(chatID); err == nil && premiumUser != nil && premiumUser.CustomerID != "" {
+			stripeInvoices, err := b.stripeManager.ListPaidInvoices(premiumUser.CustomerID, maxStripeInvoices)
+			if err != nil {
+				logger.Warn("Failed to list Stripe invoices", map[string]interface{}{
+					"chat_id": chatID,
+					"error":   err.Error(),
+				})
+			}
+			for _, inv := range stripeInvoices {
+				invoices = append(invoices, receiptEntry{
+					InvoiceID: inv.ID,
+					Service:   inv.Description,
+					Amount:    inv.Amount,
+					Currency:  inv.Currency,
+					Date:      inv.Created,
+				})
+			}
+		}
+	}
+
+	return mergeReceiptEntries(logged, invoices), nil
+}
+
+// handleReceiptsCommand lists payments with monthly totals and receipt buttons
+func (b *Bot) handleReceiptsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Receipts require database configuration. Please contact the administrator.")
+		return nil
+	}
+
+	entries, err := b.loadReceiptEntries(chatID)
+	if err != nil {
+		logger.Error("Failed to load receipts", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, "❌ Failed to load your payment history")
+		return nil
+	}
+
+	if len(entries) == 0 {
+		b.sendResponse(chatID, "🧾 <b>Receipts</b>\n\nNo payments yet. Use /coffee to support the project!")
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🧾 <b>Receipts</b>\n\n<b>Recent payments:</b>\n")
+
+	var keyboardRows [][]tgbotapi.InlineKeyboardButton
+	for i, e := range entries {
+		if i >= maxReceiptsListed {
+			sb.WriteString(fmt.Sprintf("<i>…and %d older payments</i>\n", len(entries)-maxReceiptsListed))
+			break
+		}
+
+		service := e.Service
+		if service == "" {
+			service = "Payment"
+		}
+		sb.WriteString(fmt.Sprintf("• %s · %s · %s\n", e.Date.Format("2006-01-02"), html.EscapeString(service), formatReceiptAmount(e.Amount, e.Currency)))
+
+		if data := receiptCallbackData(e); data != "" && b.stripeManager != nil {
+			label := fmt.Sprintf("🧾 %s · %s", e.Date.Format("2006-01-02"), formatReceiptAmount(e.Amount, e.Currency))
+			keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+				tgbotapi.NewInlineKeyboardButtonData(label, data),
+			))
+		}
+	}
+
+	sb.WriteString("\n<b>Monthly totals:</b>\n")
+	for _, total := range monthlyReceiptTotals(entries, receiptTotalsMonths) {
+		sb.WriteString(fmt.Sprintf("• %s: %s (%d)\n", total.Month, formatReceiptAmount(total.Amount, total.Currency), total.Count))
+	}
+
+	if len(keyboardRows) > 0 {
+		sb.WriteString("\n<i>Tap a payment to get its Stripe receipt.</i>")
+	}
+
+	msg := tgbotapi.NewMessage(chatID, sb.String())
+	msg.ParseMode = "HTML"
+	if len(keyboardRows) > 0 {
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(keyboardRows...)
+	}
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		return fmt.Errorf("failed to send receipts: %w", err)
+	}
+	return nil
+}
+
+// receiptCallbackData identifies an entry by log id, since checkout session ids exceed the 64-byte callback limit
+func receiptCallbackData(e receiptEntry) string {
+	if e.LogID > 0 && (e.InvoiceID != "" || e.SessionID != "") {
+		return receiptCallbackLog + strconv.Itoa(e.LogID)
+	}
+	if e.LogID == 0 && e.InvoiceID != "" {
+		return receiptCallbackStripe + e.InvoiceID
+	}
+	return ""
+}
+
+// handleReceiptCallback resends the Stripe-hosted receipt link for a payment
+func (b *Bot) handleReceiptCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	if b.db == nil || b.stripeManager == nil {
+		b.sendResponse(chatID, "❌ Receipts are not available right now.")
+		return nil
+	}
+
+	var invoiceID, sessionID string
+	switch {
+	case strings.HasPrefix(callback.Data, receiptCallbackLog):
+		logID, err := strconv.Atoi(strings.TrimPrefix(callback.Data, receiptCallbackLog))
+		if err != nil {
+			return nil
+		}
+		// Look the entry up among the user's own logs so other users' receipts can't be requested
+		logs, err := b.db.GetUserTopupLogs(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load your payment history")
+			return nil
+		}
+		for _, l := range logs {
+			if l.ID == logID {
+				invoiceID, sessionID = l.InvoiceID, l.TransactionID
+				break
+			}
+		}
+	case strings.HasPrefix(callback.Data, receiptCallbackStripe):
+		invoiceID = strings.TrimPrefix(callback.Data, receiptCallbackStripe)
+		entries, err := b.loadReceiptEntries(chatID)
+		if err != nil || !hasReceiptInvoice(entries, invoiceID) {
+			invoiceID = ""
+		}
+	}
+
+	if invoiceID == "" && sessionID == "" {
+		b.sendResponse(chatID, "❌ Payment not found.")
+		return nil
+	}
+
+	receiptURL, err := b.stripeManager.GetReceiptURL(invoiceID, sessionID)
+	if err != nil {
+		logger.Warn("Failed to get receipt URL", map[string]interface{}{
+			"chat_id":    chatID,
+			"invoice_id": invoiceID,
+			"error":      err.Error(),
+		})
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	msg := tgbotapi.NewMessage(chatID, "🧾 Here is your receipt:")
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL("Open receipt", receiptURL),
+	))
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		return fmt.Errorf("failed to send receipt link: %w", err)
+	}
+	return nil
+}
+
+func hasReceiptInvoice(entries []receiptEntry, invoiceID string) bool {
+	for _, e := range entries {
+		if e.InvoiceID == invoiceID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeReceiptEntries(t *testing.T) {
+	logged := []receiptEntry{
+		{LogID: 1, InvoiceID: "in_1", Amount: 5, Date: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{LogID: 2, SessionID: "cs_test_2", Amount: 3, Date: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)},
+	}
+	invoices := []receiptEntry{
+		{InvoiceID: "in_1", Amount: 5, Currency: "EUR", Date: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{InvoiceID: "in_3", Amount: 5, Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	entries := mergeReceiptEntries(logged, invoices)
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3 (duplicate invoice dropped)", len(entries))
+	}
+	if entries[0].LogID != 2 || entries[1].InvoiceID != "in_3" || entries[2].LogID != 1 {
+		t.Errorf("entries not sorted newest first: %+v", entries)
+	}
+	if entries[2].Currency != "EUR" {
+		t.Errorf("logged payment currency = %q, want its invoice's EUR", entries[2].Currency)
+	}
+}
+
+func TestMonthlyReceiptTotals(t *testing.T) {
+	entries := []receiptEntry{
+		{Amount: 3, Date: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)},
+		{Amount: 5, Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{Amount: 5, Date: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{Amount: 9, Date: time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	totals := monthlyReceiptTotals(entries, 2)
+	if len(totals) != 2 {
+		t.Fatalf("got %d months, want 2", len(totals))
+	}
+	if totals[0].Month != "2025-06" || totals[0].Amount != 8 || totals[0].Count != 2 {
+		t.Errorf("unexpected June total: %+v", totals[0])
+	}
+	if totals[1].Month != "2025-05" || totals[1].Amount != 5 {
+		t.Errorf("unexpected May total: %+v", totals[1])
+	}
+}
+
+func TestMonthlyReceiptTotalsPerCurrency(t *testing.T) {
+	entries := []receiptEntry{
+		{Amount: 3, Date: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)},
+		{Amount: 5, Currency: "EUR", Date: time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{Amount: 4, Currency: "EUR", Date: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	totals := monthlyReceiptTotals(entries, 1)
+	if len(totals) != 2 {
+		t.Fatalf("got %d totals, want June in two currencies: %+v", len(totals), totals)
+	}
+	if totals[0].Currency != "" || totals[0].Amount != 3 || totals[1].Currency != "EUR" || totals[1].Amount != 5 {
+		t.Errorf("unexpected June totals: %+v", totals)
+	}
+}
+
+func TestFormatReceiptAmount(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string
+	}{
+		{6, "", "$6.00"},
+		{12, "USD", "$12.00"},
+		{9.5, "EUR", "9.50 EUR"},
+	}
+
+	for _, tt := range tests {
+		if got := formatReceiptAmount(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("formatReceiptAmount(%v, %q) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestReceiptCallbackData(t *testing.T) {
+	tests := []struct {
+		entry receiptEntry
+		want  string
+	}{
+		{receiptEntry{LogID: 7, SessionID: "cs_live_a1b2c3d4e5f6g7h8i9j0k1l2m3n4o5p6q7r8s9t0u1v2w3x4y5z6"}, "receipt_log_7"},
+		{receiptEntry{InvoiceID: "in_123"}, "receipt_inv_in_123"},
+		{receiptEntry{LogID: 8}, ""},
+	}
+
+	for _, tt := range tests {
+		got := receiptCallbackData(tt.entry)
+		if got != tt.want {
+			t.Errorf("receiptCallbackData(%+v) = %q, want %q", tt.entry, got, tt.want)
+		}
+		if len(got) > 64 {
+			t.Errorf("callback data %q exceeds Telegram's 64-byte limit", got)
+		}
+	}
+}
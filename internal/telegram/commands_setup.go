@@ -38,6 +38,14 @@ func parseGitHubRepoURL(repoURL string) (owner, repo string, err error) {
 		}
 	}
 
+	// Handle GitHub Enterprise Server URLs: https://github.example.com/owner/repo
+	if strings.HasPrefix(repoURL, "https://") {
+		parts := strings.Split(strings.TrimPrefix(repoURL, "https://"), "/")
+		if len(parts) >= 3 && parts[1] != "" && parts[2] != "" {
+			return parts[1], parts[2], nil
+		}
+	}
+
 	return "", "", fmt.Errorf("invalid GitHub repository URL format")
 }
 
@@ -157,8 +165,8 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 		}
 	} else {
 		// Single-user mode - use global config
-		repoURL = b.config.GitHubRepo
-		committer = b.config.CommitAuthor
+		repoURL = b.cfg().GitHubRepo
+		committer = b.cfg().CommitAuthor
 	}
 
 	// Format repository URL for display (username/reponame)
@@ -183,13 +191,7 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 📁 Repository: ❌ Not set`
 	} else {
 		// Get premium level for proper calculations
-		premiumLevel := 0
-		if b.db != nil {
-			premiumUser, err := b.db.GetPremiumUser(message.Chat.ID)
-			if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-				premiumLevel = premiumUser.Level
-			}
-		}
+		premiumLevel := b.getPremiumLevel(message.Chat.ID)
 
 		// Get repository size information with caching
 		var statusEmoji, sizeSource string
@@ -229,7 +231,7 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 		} else if userGitHubProvider.NeedsClone() {
 			sizeSource = "(Remote API)"
 		} else {
-			sizeSource = "(Actual cloned size)"
+			sizeSource = "(Cloned content, excludes .git)"
 		}
 
 		// Get max size for display
@@ -259,6 +261,10 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 📊 %.2f MB / %.1f MB
 %s
 <i>Size Source: %s</i>`, statusEmoji, percentage, sizeMB, maxSizeMB, progressBar, sizeSource)
+
+			if note := b.submoduleStatusNote(userGitHubProvider); note != "" {
+				repoStatusSection += "\n" + note
+			}
 		}
 	}
 
@@ -267,7 +273,7 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 	if committer != "" {
 		committerText = html.EscapeString(committer)
 	} else {
-		defaultCommitter := b.config.CommitAuthor // Show default from config
+		defaultCommitter := b.cfg().CommitAuthor // Show default from config
 		if defaultCommitter == "" {
 			committerText = "❌ Not configured"
 		} else {
@@ -275,6 +281,13 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 		}
 	}
 
+	// Noreply email and commit identity settings (implemented in commit_email.go and commit_identity.go)
+	var committerSettingsText string
+	if user != nil {
+		committerSettingsText = "\n<i>" + describeNoreplyEmail(user.NoreplyEmail) + "</i>"
+		committerSettingsText += "\n<i>" + describeBotCommitter(user.BotCommitter) + "</i>"
+	}
+
 	// Format GitHub token status
 	var tokenStatusText string
 	var githubToken string
@@ -286,7 +299,7 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 		}
 	} else {
 		// Single-user mode - use global config
-		githubToken = b.config.GitHubToken
+		githubToken = b.cfg().GitHubToken
 	}
 
 	if githubToken != "" {
@@ -297,11 +310,11 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 
 	// Build website links if BASE_URL is configured
 	var websiteLinks string
-	if b.config.BaseURL != "" {
+	if b.cfg().BaseURL != "" {
 		websiteLinks = fmt.Sprintf(`
 
 <b>🌐 Resources:</b>
-• <a href="%s">Homepage</a> | <a href="%s/privacy">Privacy</a>`, b.config.BaseURL, b.config.BaseURL)
+• <a href="%s">Homepage</a> | <a href="%s/privacy">Privacy</a>`, b.cfg().BaseURL, b.cfg().BaseURL)
 	}
 
 	// Create the main message
@@ -317,11 +330,12 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 %s
 
 <b>👤 Committer:</b>
-%s%s`,
+%s%s%s`,
 		repoStatusSection,
 		repoDisplayText,
 		tokenStatusText,
 		committerText,
+		committerSettingsText,
 		websiteLinks)
 
 	// Create inline keyboard - include OAuth button only if configured
@@ -329,7 +343,7 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 
 	// Add GitHub OAuth button if configured
 	authRow := make([]tgbotapi.InlineKeyboardButton, 0, 2)
-	if b.config.HasGitHubOAuthConfig() {
+	if b.cfg().HasGitHubOAuthConfig() {
 		authRow = append(authRow, tgbotapi.NewInlineKeyboardButtonData(consts.ButtonGitHubOAuth, "github_oauth"))
 	}
 	authRow = append(authRow, tgbotapi.NewInlineKeyboardButtonData(consts.ButtonSetRepoToken, "repo_set_token"))
@@ -343,6 +357,21 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 		tgbotapi.NewInlineKeyboardRow(authRow...),
 	)
 
+	if user != nil {
+		noreplyButton := consts.ButtonNoreplyOn
+		if user.NoreplyEmail {
+			noreplyButton = consts.ButtonNoreplyOff
+		}
+		botCommitterButton := consts.ButtonBotCommitterOn
+		if user.BotCommitter {
+			botCommitterButton = consts.ButtonBotCommitterOff
+		}
+		keyboardRows = append(keyboardRows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(noreplyButton, "repo_toggle_noreply"),
+			tgbotapi.NewInlineKeyboardButtonData(botCommitterButton, "repo_toggle_bot_committer"),
+		))
+	}
+
 	// Add revoke auth button if GitHub token is configured
 	if githubToken != "" {
 		keyboardRows = append(keyboardRows,
@@ -366,6 +395,30 @@ func (b *Bot) handleRepoCommand(message *tgbotapi.Message) error {
 	return nil
 }
 
+// submoduleStatusNote describes how the repository's submodules are handled, or "" if it has none
+func (b *Bot) submoduleStatusNote(provider github.GitHubProvider) string {
+	// Reading through a clone-based provider that isn't cloned yet would trigger a full clone
+	if provider.GetProviderType() != github.ProviderTypeAPI && provider.NeedsClone() {
+		return ""
+	}
+
+	content, err := provider.ReadFile(github.GitmodulesFile)
+	if err != nil {
+		return ""
+	}
+
+	paths := github.ParseGitmodules(content)
+	if len(paths) == 0 {
+		return ""
+	}
+
+	cloneBehavior := "skipped on clone"
+	if b.cfg().CloneSubmodules {
+		cloneBehavior = "cloned"
+	}
+	return fmt.Sprintf("🧩 %d submodule(s): %s, excluded from size", len(paths), cloneBehavior)
+}
+
 func (b *Bot) handleCommitterCommand(message *tgbotapi.Message) error {
 	// Ensure user exists in database if database is configured
 	_, err := b.ensureUser(message)
@@ -408,16 +461,17 @@ Examples:
 func (b *Bot) handleSetRepoReply(message *tgbotapi.Message) error {
 	repoURL := strings.TrimSpace(message.Text)
 
-	// Basic validation
-	if !strings.HasPrefix(repoURL, "https://github.com/") {
-		b.sendResponse(message.Chat.ID, fmt.Sprintf("%s Invalid repository URL. Please use format: https://github.com/username/repository", consts.EmojiError))
+	// Basic validation (GitHub Enterprise users enter repositories on their own host)
+	repoPrefix := fmt.Sprintf("https://%s/", github.WebHost(b.userGitHubAPIURL(message.Chat.ID)))
+	if !strings.HasPrefix(repoURL, repoPrefix) {
+		b.sendResponse(message.Chat.ID, fmt.Sprintf("%s Invalid repository URL. Please use format: %susername/repository", consts.EmojiError, repoPrefix))
 		return nil
 	}
 
 	// Extract username and repo name from URL
-	parts := strings.Split(strings.TrimPrefix(repoURL, "https://github.com/"), "/")
+	parts := strings.Split(strings.TrimPrefix(repoURL, repoPrefix), "/")
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		b.sendResponse(message.Chat.ID, fmt.Sprintf("%s Invalid repository URL format. Please use: https://github.com/username/repository", consts.EmojiError))
+		b.sendResponse(message.Chat.ID, fmt.Sprintf("%s Invalid repository URL format. Please use: %susername/repository", consts.EmojiError, repoPrefix))
 		return nil
 	}
 
@@ -463,6 +517,9 @@ func (b *Bot) handleSetRepoReply(message *tgbotapi.Message) error {
 		})
 		successMsg := fmt.Sprintf("%s Repository updated to: %s/%s\n\n%s Configuration saved to database.", consts.EmojiSuccess, username, repoName, consts.EmojiPremium)
 		b.sendResponse(message.Chat.ID, successMsg)
+
+		// Clone now so the first note doesn't wait for it (implemented in warm_clones.go)
+		b.warmRepository(message.Chat.ID)
 	} else {
 		// Fallback to single-user mode (update global config)
 		if err := b.updateGitHubRepo(repoURL, username, message.Chat.ID); err != nil {
@@ -492,13 +549,14 @@ func (b *Bot) handleRepoTokenReply(message *tgbotapi.Message) error {
 	}
 
 	// Validate the token by making a test API call
-	if err := b.validateGitHubToken(token); err != nil {
+	githubUser, err := b.validateGitHubToken(token, b.userGitHubAPIURL(message.Chat.ID))
+	if err != nil {
 		b.sendResponse(message.Chat.ID, fmt.Sprintf("❌ Invalid GitHub token: %v", err))
 		return nil
 	}
 
 	// Ensure user exists in database if database is configured
-	_, err := b.ensureUser(message)
+	_, err = b.ensureUser(message)
 	if err != nil && b.db != nil {
 		b.sendResponse(message.Chat.ID, fmt.Sprintf("❌ Failed to get user: %v", err))
 		return nil
@@ -527,9 +585,16 @@ func (b *Bot) handleRepoTokenReply(message *tgbotapi.Message) error {
 		// Invalidate cached GitHub provider since token configuration changed
 		cacheKey := fmt.Sprintf("github_provider_%d", message.Chat.ID)
 		b.cache.Delete(cacheKey)
+		b.cache.Delete(fmt.Sprintf("github_private_provider_%d", message.Chat.ID))
+
+		// Remember whose token it is (implemented in github_identity.go)
+		b.linkGitHubAccount(message.Chat.ID, githubUser)
 
 		successMsg := fmt.Sprintf("%s GitHub token has been updated and validated!\n\n%s Configuration saved to database.", consts.EmojiSuccess, consts.EmojiPremium)
 		b.sendResponse(message.Chat.ID, successMsg)
+
+		// Clone now so the first note doesn't wait for it (implemented in warm_clones.go)
+		b.warmRepository(message.Chat.ID)
 	} else {
 		// Fallback to single-user mode (update global config)
 		if err := b.updateGitHubToken(token, message.Chat.ID); err != nil {
@@ -945,30 +1010,15 @@ func (b *Bot) getTokenUsageInfo(chatID int64) string {
 		return ""
 	}
 
-	// Get user's current token usage
-	usage, err := b.db.GetUserUsage(chatID)
+	// Get user's current token usage against their premium level's limit
+	tokens, err := b.usageLimits().Tokens(chatID, b.getPremiumLevel(chatID), 0)
 	if err != nil {
 		return ""
 	}
 
-	// Get user's premium level
-	premiumLevel := 0
-	premiumUser, err := b.db.GetPremiumUser(chatID)
-	if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-		premiumLevel = premiumUser.Level
-	}
-
-	// Calculate token limit
-	tokenLimit := database.GetTokenLimit(premiumLevel)
-
-	var currentUsage int64
-	if usage != nil {
-		currentUsage = usage.TokenInput + usage.TokenOutput
-	}
-
 	// Format token usage with millions format
-	usageText := formatTokenCount(currentUsage)
-	limitText := formatTokenCount(tokenLimit)
+	usageText := formatTokenCount(tokens.Current)
+	limitText := formatTokenCount(tokens.Max)
 
 	return fmt.Sprintf(` %s/%s tokens`, usageText, limitText)
 }
@@ -979,35 +1029,17 @@ func (b *Bot) getDetailedTokenUsageInfo(chatID int64) (string, float64) {
 		return "No database connection", 0
 	}
 
-	// Get user's current token usage
-	usage, err := b.db.GetUserUsage(chatID)
+	// Get user's current token usage against their premium level's limit
+	tokens, err := b.usageLimits().Tokens(chatID, b.getPremiumLevel(chatID), 0)
 	if err != nil {
 		return "Error getting usage", 0
 	}
 
-	// Get user's premium level
-	premiumLevel := 0
-	premiumUser, err := b.db.GetPremiumUser(chatID)
-	if err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
-		premiumLevel = premiumUser.Level
-	}
-
-	// Calculate token limit
-	tokenLimit := database.GetTokenLimit(premiumLevel)
-
-	var currentUsage int64
-	if usage != nil {
-		currentUsage = usage.TokenInput + usage.TokenOutput
-	}
-
-	// Calculate percentage
-	percentage := float64(currentUsage) / float64(tokenLimit) * 100
-
 	// Format token usage with millions format
-	usageText := formatTokenCount(currentUsage)
-	limitText := formatTokenCount(tokenLimit)
+	usageText := formatTokenCount(tokens.Current)
+	limitText := formatTokenCount(tokens.Max)
 
-	return fmt.Sprintf("%s / %s tokens", usageText, limitText), percentage
+	return fmt.Sprintf("%s / %s tokens", usageText, limitText), tokens.Percentage()
 }
 
 // handleLLMEnableCallback handles the enable AI processing button click
@@ -1149,7 +1181,7 @@ To enable AI features with your own token, reply to this message with your LLM A
 
 	// Store the message context for later processing
 	messageKey := fmt.Sprintf("llm_token_%d_%d", callback.Message.Chat.ID, sentMsg.MessageID)
-	b.pendingMessages[messageKey] = "llm_token_setup"
+	b.pendingMessages.Set(messageKey, "llm_token_setup")
 
 	return nil
 }
@@ -1313,10 +1345,10 @@ func (b *Bot) handleRepoTokenPrivacyConfirmation(callback *tgbotapi.CallbackQuer
 
 	// Build privacy policy link if BASE_URL is configured
 	var privacyLink string
-	if b.config.BaseURL != "" {
+	if b.cfg().BaseURL != "" {
 		privacyLink = fmt.Sprintf(`
 
-📋 <a href="%s/privacy">Read our Privacy Policy</a>`, b.config.BaseURL)
+📋 <a href="%s/privacy">Read our Privacy Policy</a>`, b.cfg().BaseURL)
 	} else {
 		privacyLink = `
 
@@ -1324,8 +1356,8 @@ func (b *Bot) handleRepoTokenPrivacyConfirmation(callback *tgbotapi.CallbackQuer
 	}
 
 	var privacyText string
-	if b.config.BaseURL != "" {
-		privacyText = fmt.Sprintf(`<a href="%s/privacy">Privacy Policy</a>`, b.config.BaseURL)
+	if b.cfg().BaseURL != "" {
+		privacyText = fmt.Sprintf(`<a href="%s/privacy">Privacy Policy</a>`, b.cfg().BaseURL)
 	} else {
 		privacyText = "Privacy Policy"
 	}
@@ -1520,9 +1552,17 @@ func (b *Bot) handleRepoRevokeAuthConfirmed(callback *tgbotapi.CallbackQuery) er
 		return nil
 	}
 
+	if err := b.db.UpdateUserGitHubAccount(callback.Message.Chat.ID, "", 0); err != nil {
+		logger.Warn("Failed to unlink GitHub account", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": callback.Message.Chat.ID,
+		})
+	}
+
 	// Invalidate cached GitHub provider since token has been revoked
 	cacheKey := fmt.Sprintf("github_provider_%d", callback.Message.Chat.ID)
 	b.cache.Delete(cacheKey)
+	b.cache.Delete(fmt.Sprintf("github_private_provider_%d", callback.Message.Chat.ID))
 
 	successMsg := `✅ <b>Authentication Revoked</b>
 
@@ -0,0 +1,481 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Soft-delete for custom files: files are moved to trash/ and purged after the retention period
+
+const (
+	trashOfferExpiry    = 1 * time.Hour
+	trashPurgeInterval  = 6 * time.Hour
+	trashMaxListEntries = 20
+)
+
+// trashPathFor returns the trash location for a file, keeping its original folder structure
+func trashPathFor(originalPath string, trashedAt time.Time) string {
+	return fmt.Sprintf("%s/%s/%s", consts.TrashFolder, trashedAt.Format("20060102-150405"), strings.TrimPrefix(originalPath, "/"))
+}
+
+// offerMoveToTrash asks whether a file removed from the custom files list should also be trashed
+func (b *Bot) offerMoveToTrash(chatID int64, filePath string) {
+	text := fmt.Sprintf(`🗂 <code>%s</code> is no longer in your custom files list, but the file is still in your repository.
+
+Move it to <code>%s/</code>? You can restore it with /trash for %d days.`,
+		html.EscapeString(filePath), consts.TrashFolder, consts.TrashRetentionDays)
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = consts.ParseModeHTML
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🗑 Move to Trash", "trash_move"),
+			tgbotapi.NewInlineKeyboardButtonData("📄 Keep File", "trash_keep"),
+		),
+	)
+	msg.ReplyMarkup = keyboard
+
+	sent, err := b.rateLimitedSend(chatID, msg)
+	if err != nil {
+		logger.Error("Failed to send trash offer", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	b.cache.SetWithExpiry(fmt.Sprintf("trash_offer_%d_%d", chatID, sent.MessageID), filePath, trashOfferExpiry)
+}
+
+func (b *Bot) handleTrashCommand(message *tgbotapi.Message) error {
+	if b.db == nil {
+		b.sendResponse(message.Chat.ID, "❌ Trash requires database configuration.")
+		return nil
+	}
+
+	text, keyboard, err := b.buildTrashListing(message.Chat.ID)
+	if err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, text)
+	msg.ParseMode = consts.ParseModeHTML
+	if keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	if _, err := b.rateLimitedSend(message.Chat.ID, msg); err != nil {
+		return fmt.Errorf("failed to send trash listing: %w", err)
+	}
+	return nil
+}
+
+// buildTrashListing renders the user's trashed files with restore and delete buttons
+func (b *Bot) buildTrashListing(chatID int64) (string, *tgbotapi.InlineKeyboardMarkup, error) {
+	files, err := b.db.GetTrashedFiles(chatID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get trashed files: %w", err)
+	}
+
+	if len(files) == 0 {
+		return "🗑 <b>Trash</b>\n\nYour trash is empty.", nil, nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🗑 <b>Trash</b> (%d)\n\n", len(files)))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, trashed := range files {
+		if i >= trashMaxListEntries {
+			sb.WriteString(fmt.Sprintf("\n<i>… and %d more</i>\n", len(files)-trashMaxListEntries))
+			break
+		}
+
+		daysLeft := int(time.Until(trashed.ExpiresAt).Hours() / 24)
+		if daysLeft < 0 {
+			daysLeft = 0
+		}
+		sb.WriteString(fmt.Sprintf("%d. <code>%s</code>\n   <i>deleted forever in %d days</i>\n", i+1, html.EscapeString(trashed.OriginalPath), daysLeft))
+
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("♻️ Restore %d", i+1), fmt.Sprintf("trash_restore_%d", trashed.ID)),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔥 Delete %d", i+1), fmt.Sprintf("trash_purge_%d", trashed.ID)),
+		))
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return sb.String(), &keyboard, nil
+}
+
+// handleTrashCallback handles trash_move, trash_keep, trash_restore_<id>, trash_purge_<id> and trash_purgeok_<id>
+func (b *Bot) handleTrashCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	if b.db == nil {
+		b.editMessage(chatID, messageID, "❌ Trash requires database configuration.")
+		return nil
+	}
+
+	switch {
+	case callback.Data == "trash_move":
+		return b.handleTrashMove(callback)
+	case callback.Data == "trash_keep":
+		b.cache.Delete(fmt.Sprintf("trash_offer_%d_%d", chatID, messageID))
+		b.editMessage(chatID, messageID, "📄 File kept in your repository.")
+		return nil
+	case callback.Data == "trash_list":
+		return b.refreshTrashListing(chatID, messageID, "")
+	case strings.HasPrefix(callback.Data, "trash_restore_"):
+		return b.handleTrashRestore(callback, strings.TrimPrefix(callback.Data, "trash_restore_"))
+	case strings.HasPrefix(callback.Data, "trash_purgeok_"):
+		return b.handleTrashPurge(callback, strings.TrimPrefix(callback.Data, "trash_purgeok_"))
+	case strings.HasPrefix(callback.Data, "trash_purge_"):
+		return b.confirmTrashPurge(callback, strings.TrimPrefix(callback.Data, "trash_purge_"))
+	default:
+		return fmt.Errorf("unknown trash callback: %s", callback.Data)
+	}
+}
+
+func (b *Bot) handleTrashMove(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	cacheKey := fmt.Sprintf("trash_offer_%d_%d", chatID, messageID)
+	cached, ok := b.cache.Get(cacheKey)
+	if !ok {
+		b.editMessage(chatID, messageID, "⏰ This request has expired. The file was kept in your repository.")
+		return nil
+	}
+	originalPath, ok := cached.(string)
+	if !ok {
+		b.editMessage(chatID, messageID, "⏰ This request has expired. The file was kept in your repository.")
+		return nil
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		b.editMessage(chatID, messageID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.editMessage(chatID, messageID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	b.editMessage(chatID, messageID, "🔄 Moving file to trash...")
+
+	now := time.Now()
+	trashPath := trashPathFor(originalPath, now)
+	commitMsg := fmt.Sprintf("Move %s to trash via Telegram", originalPath)
+	if err := userGitHubProvider.MoveFile(originalPath, trashPath, commitMsg, b.getCommitterInfo(chatID)); err != nil {
+		logger.Error("Failed to move file to trash", map[string]interface{}{
+			"chat_id": chatID,
+			"path":    originalPath,
+			"error":   err.Error(),
+		})
+		b.editMessage(chatID, messageID, fmt.Sprintf("❌ Failed to move %s to trash: %v", originalPath, err))
+		return nil
+	}
+	b.cache.Delete(cacheKey)
+
+	expiresAt := now.AddDate(0, 0, consts.TrashRetentionDays)
+	if _, err := b.db.CreateTrashedFile(chatID, user.GitHubRepo, originalPath, trashPath, expiresAt); err != nil {
+		logger.Error("Failed to record trashed file", map[string]interface{}{
+			"chat_id":    chatID,
+			"trash_path": trashPath,
+			"error":      err.Error(),
+		})
+		b.editMessage(chatID, messageID, fmt.Sprintf("⚠️ Moved to %s, but it could not be added to /trash: %v", trashPath, err))
+		return nil
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf(
+		"🗑 Moved <code>%s</code> to <code>%s</code>.\n\nRestore it with /trash within %d days.",
+		html.EscapeString(originalPath), html.EscapeString(trashPath), consts.TrashRetentionDays))
+	edit.ParseMode = consts.ParseModeHTML
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	return nil
+}
+
+// getTrashedFileFromCallback parses the ID from callback data and loads the user's trashed file
+func (b *Bot) getTrashedFileFromCallback(chatID int64, idStr string) (*database.TrashedFile, error) {
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trash item: %w", err)
+	}
+
+	trashed, err := b.db.GetTrashedFile(id, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if trashed == nil {
+		return nil, fmt.Errorf("trash item not found, it may already have been restored or deleted")
+	}
+	return trashed, nil
+}
+
+func (b *Bot) handleTrashRestore(callback *tgbotapi.CallbackQuery, idStr string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	trashed, err := b.getTrashedFileFromCallback(chatID, idStr)
+	if err != nil {
+		return b.refreshTrashListing(chatID, messageID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.editMessage(chatID, messageID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	commitMsg := fmt.Sprintf("Restore %s from trash via Telegram", trashed.OriginalPath)
+	if err := userGitHubProvider.MoveFile(trashed.TrashPath, trashed.OriginalPath, commitMsg, b.getCommitterInfo(chatID)); err != nil {
+		logger.Error("Failed to restore file from trash", map[string]interface{}{
+			"chat_id":    chatID,
+			"trash_path": trashed.TrashPath,
+			"error":      err.Error(),
+		})
+		return b.refreshTrashListing(chatID, messageID, fmt.Sprintf("❌ Failed to restore <code>%s</code>: %s",
+			html.EscapeString(trashed.OriginalPath), html.EscapeString(err.Error())))
+	}
+
+	if err := b.db.DeleteTrashedFile(trashed.ID); err != nil {
+		logger.Error("Failed to delete trash record after restore", map[string]interface{}{
+			"chat_id": chatID,
+			"id":      trashed.ID,
+			"error":   err.Error(),
+		})
+	}
+
+	status := fmt.Sprintf("♻️ Restored <code>%s</code>.", html.EscapeString(trashed.OriginalPath))
+	if b.restoreCustomFileEntry(callback, trashed.OriginalPath) {
+		status += " It is back in your custom files list."
+	}
+
+	return b.refreshTrashListing(chatID, messageID, status)
+}
+
+// restoreCustomFileEntry re-adds a restored file to the custom files list when the tier limit allows
+func (b *Bot) restoreCustomFileEntry(callback *tgbotapi.CallbackQuery, filePath string) bool {
+	chatID := callback.Message.Chat.ID
+
+	user, err := b.ensureUserFromCallback(callback)
+	if err != nil || user == nil {
+		return false
+	}
+
+	customFiles := user.GetCustomFiles()
+	if len(customFiles) >= database.GetCustomFileLimit(b.getPremiumLevel(chatID)) {
+		return false
+	}
+
+	if err := user.AddCustomFile(filePath); err != nil {
+		return false
+	}
+	if err := b.db.UpdateUserCustomFiles(chatID, user.CustomFiles); err != nil {
+		logger.Warn("Failed to re-add restored custom file", map[string]interface{}{
+			"chat_id": chatID,
+			"path":    filePath,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func (b *Bot) confirmTrashPurge(callback *tgbotapi.CallbackQuery, idStr string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	trashed, err := b.getTrashedFileFromCallback(chatID, idStr)
+	if err != nil {
+		return b.refreshTrashListing(chatID, messageID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, fmt.Sprintf(
+		"🔥 Permanently delete <code>%s</code>?\n\nThis removes it from your repository and cannot be undone from the bot.",
+		html.EscapeString(trashed.OriginalPath)))
+	edit.ParseMode = consts.ParseModeHTML
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔥 Delete Forever", fmt.Sprintf("trash_purgeok_%d", trashed.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Back", "trash_list"),
+		),
+	)
+	edit.ReplyMarkup = &keyboard
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	return nil
+}
+
+func (b *Bot) handleTrashPurge(callback *tgbotapi.CallbackQuery, idStr string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	trashed, err := b.getTrashedFileFromCallback(chatID, idStr)
+	if err != nil {
+		return b.refreshTrashListing(chatID, messageID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+	}
+
+	if err := b.purgeTrashedFile(trashed); err != nil {
+		return b.refreshTrashListing(chatID, messageID, fmt.Sprintf("❌ Failed to delete <code>%s</code>: %s",
+			html.EscapeString(trashed.OriginalPath), html.EscapeString(err.Error())))
+	}
+
+	return b.refreshTrashListing(chatID, messageID, fmt.Sprintf("🔥 Permanently deleted <code>%s</code>.", html.EscapeString(trashed.OriginalPath)))
+}
+
+// refreshTrashListing redraws the trash listing in place with an optional status line on top
+func (b *Bot) refreshTrashListing(chatID int64, messageID int, status string) error {
+	text, keyboard, err := b.buildTrashListing(chatID)
+	if err != nil {
+		return err
+	}
+	if status != "" {
+		text = status + "\n\n" + text
+	}
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = consts.ParseModeHTML
+	edit.ReplyMarkup = keyboard
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	return nil
+}
+
+// errTrashOwnerGone is returned when the user a trashed file belongs to no longer has GitHub set up
+var errTrashOwnerGone = errors.New("user not configured or missing GitHub settings")
+
+// trashRepoProvider returns a provider for the repository a file was trashed in. Users may have
+// switched repositories since, the file then stays in the old one until it's purged there.
+func (b *Bot) trashRepoProvider(trashed *database.TrashedFile) (github.GitHubProvider, error) {
+	user, err := b.db.GetUserByChatID(trashed.ChatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !user.HasGitHubConfig() {
+		return nil, errTrashOwnerGone
+	}
+	if trashed.Repo == "" || trashed.Repo == user.GitHubRepo {
+		return b.getUserGitHubProvider(trashed.ChatID)
+	}
+
+	premiumLevel := b.getPremiumLevel(trashed.ChatID)
+	return b.githubFactory.CreateProvider(github.ProviderTypeAPI, &github.ProviderConfig{
+		Config: github.NewConfigAdapter(&config.Config{
+			GitHubToken:    user.GitHubToken,
+			GitHubRepo:     trashed.Repo,
+			GitHubUsername: b.cfg().GitHubUsername,
+			CommitAuthor:   b.cfg().CommitAuthor,
+		}),
+		PremiumLevel: premiumLevel,
+		UserID:       fmt.Sprintf("user_%d_trash", trashed.ChatID),
+		ChatID:       trashed.ChatID,
+		Committer:    b.providerCommitter(user),
+		APIBaseURL:   user.GitHubAPIURL,
+	})
+}
+
+// isPermanentTrashError reports whether purging a trashed file can never succeed, because its
+// owner or repository is gone or no longer accessible
+func isPermanentTrashError(err error) bool {
+	return errors.Is(err, errTrashOwnerGone) || isRepoSetupError(err)
+}
+
+// purgeTrashedFile deletes a trashed file from the repository it was trashed in and drops its record
+func (b *Bot) purgeTrashedFile(trashed *database.TrashedFile) error {
+	userGitHubProvider, err := b.trashRepoProvider(trashed)
+	if err != nil {
+		return fmt.Errorf("GitHub not configured: %w", err)
+	}
+
+	commitMsg := fmt.Sprintf("Permanently delete %s from trash via Telegram", trashed.OriginalPath)
+	if err := userGitHubProvider.DeleteFile(trashed.TrashPath, commitMsg, b.getCommitterInfo(trashed.ChatID)); err != nil {
+		// The file may have been removed outside the bot, in which case only the record is stale
+		if content, readErr := userGitHubProvider.ReadFile(trashed.TrashPath); readErr != nil || content != "" {
+			return err
+		}
+	}
+
+	return b.db.DeleteTrashedFile(trashed.ID)
+}
+
+// startTrashPurger periodically deletes trashed files past their retention period
+func (b *Bot) startTrashPurger() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopTrashPurger = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			b.purgeExpiredTrash()
+			b.purgeExpiredComposeSessions() // Implemented in compose.go
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (b *Bot) purgeExpiredTrash() {
+	expired, err := b.db.GetExpiredTrashedFiles(time.Now())
+	if err != nil {
+		logger.Error("Failed to load expired trash", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, trashed := range expired {
+		if err := b.purgeTrashedFile(trashed); err != nil {
+			if isPermanentTrashError(err) {
+				// Retrying would fail the same way on every run, so forget the item
+				if dropErr := b.db.DeleteTrashedFile(trashed.ID); dropErr == nil {
+					logger.Warn("Dropped expired trash item that can't be purged", map[string]interface{}{
+						"chat_id":    trashed.ChatID,
+						"repo":       trashed.Repo,
+						"trash_path": trashed.TrashPath,
+						"error":      err.Error(),
+					})
+					continue
+				}
+			}
+			logger.Warn("Failed to purge expired trash item", map[string]interface{}{
+				"chat_id":    trashed.ChatID,
+				"trash_path": trashed.TrashPath,
+				"error":      err.Error(),
+			})
+			continue
+		}
+		logger.Info("Purged expired trash item", map[string]interface{}{
+			"chat_id":    trashed.ChatID,
+			"trash_path": trashed.TrashPath,
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTrashPathFor(t *testing.T) {
+	trashedAt := time.Date(2025, 3, 14, 9, 26, 53, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "notes.md", expected: "trash/20250314-092653/notes.md"},
+		{input: "projects/alpha/log.md", expected: "trash/20250314-092653/projects/alpha/log.md"},
+		{input: "/leading.md", expected: "trash/20250314-092653/leading.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if result := trashPathFor(tt.input, trashedAt); result != tt.expected {
+				t.Errorf("trashPathFor(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsPermanentTrashError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "owner gone", err: fmt.Errorf("GitHub not configured: %w", errTrashOwnerGone), want: true},
+		{name: "repository deleted", err: errors.New("repository not found"), want: true},
+		{name: "rate limited", err: errors.New("API rate limit exceeded"), want: false},
+		{name: "degraded", err: errRepoDegraded, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPermanentTrashError(tt.err); got != tt.want {
+				t.Errorf("isPermanentTrashError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
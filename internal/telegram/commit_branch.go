@@ -0,0 +1,95 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Commit branch: /branch makes the bot commit notes to a branch of the user's choosing instead of
+// the repository's default branch, e.g. to review them in pull requests. A missing branch is
+// created from the default branch.
+
+// handleBranchCommand shows or changes the commit branch: /branch, /branch <name>, /branch default
+func (b *Bot) handleBranchCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	arg := strings.TrimSpace(message.CommandArguments())
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Branch settings require a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if arg == "" {
+		status := "🌿 Notes are committed to the repository's default branch."
+		if user != nil && user.CommitBranch != "" {
+			status = fmt.Sprintf("🌿 Notes are committed to <code>%s</code>.", html.EscapeString(user.CommitBranch))
+		}
+		b.sendResponse(chatID, status+`
+
+• /branch notes - Commit to the branch <code>notes</code>, created from the default branch if missing
+• /branch default - Go back to the default branch`)
+		return nil
+	}
+
+	branch := ""
+	if arg != "default" {
+		branch = strings.TrimPrefix(arg, "refs/heads/")
+		if err := github.ValidateBranchName(branch); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+	}
+
+	created := false
+	if branch != "" {
+		provider, err := b.getUserGitHubProvider(chatID)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if creator, ok := provider.(github.BranchCreator); ok {
+			if created, err = creator.EnsureBranch(branch); err != nil {
+				b.sendResponse(chatID, fmt.Sprintf("❌ Failed to create branch: %s", html.EscapeString(err.Error())))
+				return nil
+			}
+		}
+	}
+
+	if err := b.db.UpdateUserCommitBranch(chatID, branch); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to update branch: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	// Invalidate the cached provider since the branch changed
+	b.cache.Delete(fmt.Sprintf("github_provider_%d", chatID))
+
+	logger.Info("User changed commit branch", map[string]interface{}{
+		"chat_id": chatID,
+		"branch":  branch,
+		"created": created,
+	})
+
+	if branch == "" {
+		b.sendResponse(chatID, fmt.Sprintf("%s Notes are committed to the default branch again.", consts.EmojiSuccess))
+		return nil
+	}
+
+	reply := fmt.Sprintf("%s Notes are now committed to <code>%s</code>.", consts.EmojiSuccess, html.EscapeString(branch))
+	if created {
+		reply += "\n\n🌱 The branch didn't exist and was created from the default branch."
+	}
+	b.sendResponse(chatID, reply)
+	return nil
+}
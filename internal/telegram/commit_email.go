@@ -0,0 +1,107 @@
+package telegram
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Commit email privacy: GitHub OAuth sets the committer to the account's noreply address
+// (<id>+<login>@users.noreply.github.com) unless the user turned it off in /repo
+
+// githubNoreplyEmail returns the noreply address GitHub attributes to the account on webHost
+func githubNoreplyEmail(githubUser *GitHubUser, webHost string) string {
+	return fmt.Sprintf("%d+%s@users.noreply.%s", githubUser.ID, githubUser.Login, webHost)
+}
+
+// publicCommitter returns the committer built from the account's public profile email,
+// what OAuth set before noreply addresses were used, or "" without a public email
+func publicCommitter(githubUser *GitHubUser) string {
+	if githubUser.Name == "" || githubUser.Email == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s <%s>", githubUser.Name, githubUser.Email)
+}
+
+// oauthCommitter returns the committer GitHub OAuth sets for the account, "" if there is none
+func oauthCommitter(githubUser *GitHubUser, noreply bool, webHost string) string {
+	if !noreply {
+		return publicCommitter(githubUser)
+	}
+	name := githubUser.Name
+	if name == "" {
+		name = githubUser.Login
+	}
+	return fmt.Sprintf("%s <%s>", name, githubNoreplyEmail(githubUser, webHost))
+}
+
+// applyOAuthCommitter sets the committer from the GitHub account unless the user chose one themselves
+func (b *Bot) applyOAuthCommitter(chatID int64, currentCommitter string, githubUser *GitHubUser, noreply bool) {
+	if currentCommitter != "" && currentCommitter != publicCommitter(githubUser) {
+		return
+	}
+
+	committer := oauthCommitter(githubUser, noreply, github.WebHost(github.APIBaseURL()))
+	if committer == "" || committer == currentCommitter {
+		return
+	}
+
+	if err := b.db.UpdateUserCommitter(chatID, committer); err != nil {
+		logger.Warn("Failed to update committer info", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// describeNoreplyEmail describes the noreply setting for /repo
+func describeNoreplyEmail(enabled bool) string {
+	if enabled {
+		return "🔒 GitHub OAuth commits with your GitHub noreply email"
+	}
+	return "🔓 GitHub OAuth commits with your public profile email"
+}
+
+// handleRepoToggleNoreplyCallback turns the noreply committer email on or off
+func (b *Bot) handleRepoToggleNoreplyCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Committer feature requires database configuration")
+		return nil
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		b.sendResponse(chatID, "❌ Failed to get user")
+		return nil
+	}
+
+	enabled := !user.NoreplyEmail
+	if err := b.db.UpdateUserNoreplyEmail(chatID, enabled); err != nil {
+		logger.Error("Failed to update noreply email setting", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, "❌ Failed to update noreply email setting")
+		return nil
+	}
+
+	// Switch a committer set by OAuth right away, custom committers stay untouched
+	if enabled && user.GitHubToken != "" {
+		if githubUser, err := b.getGitHubUser(user.GitHubToken); err == nil {
+			b.applyOAuthCommitter(chatID, user.Committer, githubUser, true)
+		} else {
+			logger.Warn("Failed to get GitHub user for noreply email", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("%s %s. Use /repo to see your committer.", consts.EmojiSuccess, describeNoreplyEmail(enabled)))
+	return nil
+}
@@ -0,0 +1,27 @@
+package telegram
+
+import "testing"
+
+func TestOAuthCommitter(t *testing.T) {
+	githubUser := &GitHubUser{Login: "octocat", ID: 583231, Name: "The Octocat", Email: "octocat@example.com"}
+
+	tests := []struct {
+		name    string
+		user    *GitHubUser
+		noreply bool
+		host    string
+		want    string
+	}{
+		{"noreply", githubUser, true, "github.com", "The Octocat <583231+octocat@users.noreply.github.com>"},
+		{"enterprise noreply", githubUser, true, "github.example.com", "The Octocat <583231+octocat@users.noreply.github.example.com>"},
+		{"public email", githubUser, false, "github.com", "The Octocat <octocat@example.com>"},
+		{"noreply without name", &GitHubUser{Login: "octocat", ID: 1}, true, "github.com", "octocat <1+octocat@users.noreply.github.com>"},
+		{"no public email", &GitHubUser{Login: "octocat", ID: 1, Name: "The Octocat"}, false, "github.com", ""},
+	}
+
+	for _, tt := range tests {
+		if got := oauthCommitter(tt.user, tt.noreply, tt.host); got != tt.want {
+			t.Errorf("%s: oauthCommitter() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
 )
 
 // GitHubCommit represents a commit from GitHub API
@@ -58,7 +60,7 @@ func (b *Bot) generateCommitGraph(chatID int64) (string, error) {
 	}
 
 	// Fetch commits from GitHub API
-	commits, err := b.fetchCommitsForGraph(owner, repo, user.GitHubToken)
+	commits, err := b.fetchCommitsForGraph(github.ResolveAPIBaseURL(user.GitHubAPIURL), owner, repo, user.GitHubToken)
 	if err != nil {
 		// Handle rate limiting gracefully
 		if strings.Contains(err.Error(), "rate limit exceeded") {
@@ -84,27 +86,15 @@ func (b *Bot) generateCommitGraph(chatID int64) (string, error) {
 
 // parseGitHubRepoURL extracts owner and repo name from GitHub URL
 func (b *Bot) parseGitHubRepoURL(url string) (string, string, error) {
-	// Remove trailing .git if present
-	url = strings.TrimSuffix(url, ".git")
-
-	// Handle different URL formats
-	if strings.HasPrefix(url, "https://github.com/") {
-		parts := strings.Split(strings.TrimPrefix(url, "https://github.com/"), "/")
-		if len(parts) >= 2 {
-			return parts[0], parts[1], nil
-		}
-	} else if strings.HasPrefix(url, "git@github.com:") {
-		parts := strings.Split(strings.TrimPrefix(url, "git@github.com:"), "/")
-		if len(parts) >= 2 {
-			return parts[0], parts[1], nil
-		}
+	owner, repo, err := parseGitHubRepoURL(url)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid GitHub URL format")
 	}
-
-	return "", "", fmt.Errorf("invalid GitHub URL format")
+	return owner, repo, nil
 }
 
 // fetchCommitsForGraph retrieves commits from GitHub API with smart pagination
-func (b *Bot) fetchCommitsForGraph(owner, repo, token string) ([]GitHubCommit, error) {
+func (b *Bot) fetchCommitsForGraph(apiURL, owner, repo, token string) ([]GitHubCommit, error) {
 	// Calculate date 30 days ago
 	since := time.Now().AddDate(0, 0, -30).Format(time.RFC3339)
 
@@ -114,8 +104,8 @@ func (b *Bot) fetchCommitsForGraph(owner, repo, token string) ([]GitHubCommit, e
 
 	for page <= maxPages {
 		// GitHub API URL for commits with pagination
-		url := fmt.Sprintf("https://api.github.com/repos/%s/%s/commits?since=%s&per_page=100&page=%d",
-			owner, repo, since, page)
+		url := fmt.Sprintf("%s/repos/%s/%s/commits?since=%s&per_page=100&page=%d",
+			apiURL, owner, repo, since, page)
 
 		commits, hasMore, err := b.fetchCommitsPageForGraph(url, token, since)
 		if err != nil {
@@ -150,7 +140,7 @@ func (b *Bot) fetchCommitsPageForGraph(url, token, since string) ([]GitHubCommit
 	req.Header.Set("Authorization", "token "+token)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{}
+	client := github.NewHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, false, fmt.Errorf("making request: %w", err)
@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Commit identity: by default the user's committer both authors and commits. Users can switch in
+// /repo to commits authored by them but committed by the bot, so git history shows what msg2git wrote.
+
+// defaultBotIdentity commits for the bot when the deployment has no COMMIT_AUTHOR
+const defaultBotIdentity = "Msg2Git Bot <bot@msg2git.com>"
+
+// botIdentity returns the identity the bot commits as
+func (b *Bot) botIdentity() string {
+	if b.cfg().CommitAuthor != "" {
+		return b.cfg().CommitAuthor
+	}
+	return defaultBotIdentity
+}
+
+// providerCommitter returns the committer for the user's providers, "" if the author commits
+func (b *Bot) providerCommitter(user *database.User) string {
+	if user == nil || !user.BotCommitter {
+		return ""
+	}
+	return b.botIdentity()
+}
+
+// describeBotCommitter describes the commit identity setting for /repo
+func describeBotCommitter(enabled bool) string {
+	if enabled {
+		return "🤖 Commits are authored by you and committed by the bot"
+	}
+	return "👤 Commits are authored and committed by you"
+}
+
+// handleRepoToggleBotCommitterCallback switches between user and bot committed commits
+func (b *Bot) handleRepoToggleBotCommitterCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Committer feature requires database configuration")
+		return nil
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		b.sendResponse(chatID, "❌ Failed to get user")
+		return nil
+	}
+
+	enabled := !user.BotCommitter
+	if err := b.db.UpdateUserBotCommitter(chatID, enabled); err != nil {
+		logger.Error("Failed to update bot committer setting", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, "❌ Failed to update commit identity setting")
+		return nil
+	}
+
+	// Providers carry the committer, so cached ones must be rebuilt
+	b.cache.Delete(fmt.Sprintf("github_provider_%d", chatID))
+	b.cache.Delete(fmt.Sprintf("github_private_provider_%d", chatID))
+
+	b.sendResponse(chatID, fmt.Sprintf("%s %s. Use /repo to see your committer.", consts.EmojiSuccess, describeBotCommitter(enabled)))
+	return nil
+}
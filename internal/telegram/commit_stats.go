@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/webhook"
+)
+
+// Post-commit statistics shown in save confirmations and recorded in the commit log
+
+const (
+	commitStatusContext     = "msg2git"
+	commitStatusDescription = "Captured from Telegram"
+)
+
+// formatFileSize renders a byte count in a compact human readable form
+func formatFileSize(bytes int64) string {
+	switch {
+	case bytes >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(bytes)/(1024*1024))
+	case bytes >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(bytes)/1024)
+	default:
+		return fmt.Sprintf("%d B", bytes)
+	}
+}
+
+// formatCommitStats builds the one-line summary appended to save confirmations
+func formatCommitStats(result *github.CommitResult, entriesToday int) string {
+	if result == nil {
+		return ""
+	}
+
+	parts := []string{"📄 " + path.Base(result.Filename)}
+	if result.FileSize > 0 {
+		parts = append(parts, formatFileSize(result.FileSize))
+	}
+	if entriesToday == 1 {
+		parts = append(parts, "1 entry today")
+	} else if entriesToday > 1 {
+		parts = append(parts, fmt.Sprintf("%d entries today", entriesToday))
+	}
+	if result.SHA != "" {
+		parts = append(parts, result.ShortSHA())
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// recordCommitStats stores the commit in the commit log and returns the stats line for the confirmation
+func (b *Bot) recordCommitStats(chatID int64, provider github.GitHubProvider, result *github.CommitResult) string {
+	if result == nil {
+		return ""
+	}
+	b.recordRepoSuccess(chatID)
+
+	if b.isFeatureEnabled(consts.FeatureCommitStatus, chatID) {
+		go b.attachCommitStatus(chatID, provider, result)
+	}
+
+	b.emitWebhookEvent(chatID, webhook.EventNoteCommitted, map[string]interface{}{
+		"filename":   result.Filename,
+		"commit_sha": result.SHA,
+		"commit_url": result.URL,
+		"file_size":  result.FileSize,
+	})
+
+	go b.checkQuotaAlerts(chatID, provider)
+	go b.updateBacklinks(chatID, provider, result.Filename)
+	go b.recordNoteTags(chatID, result.Filename)
+
+	entriesToday := 0
+	if b.db != nil {
+		repo := providerRepo(provider)
+		if err := b.db.RecordCommit(chatID, repo, result.Filename, result.SHA, result.URL, result.FileSize); err != nil {
+			logger.Warn("Failed to record commit in commit log", map[string]interface{}{
+				"chat_id":    chatID,
+				"filename":   result.Filename,
+				"commit_sha": result.SHA,
+				"error":      err.Error(),
+			})
+		} else {
+			go b.checkAccessAnomalies(chatID, repo) // Implemented in access_anomalies.go
+		}
+
+		now := time.Now()
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if count, err := b.db.CountCommitsSince(chatID, result.Filename, startOfDay); err == nil {
+			entriesToday = count
+		}
+	}
+
+	return "\n" + formatCommitStats(result, entriesToday) + b.streakConfirmation(chatID)
+}
+
+// attachCommitStatus marks a bot commit on GitHub so it can be told apart from manual commits
+func (b *Bot) attachCommitStatus(chatID int64, provider github.GitHubProvider, result *github.CommitResult) {
+	if provider == nil || result.SHA == "" {
+		return
+	}
+
+	status := &github.CommitStatus{
+		State:       "success",
+		Description: commitStatusDescription,
+		Context:     commitStatusContext,
+	}
+	if b.api != nil && b.api.Self.UserName != "" {
+		status.TargetURL = "https://t.me/" + b.api.Self.UserName
+	}
+
+	if err := provider.CreateCommitStatus(result.SHA, status); err != nil {
+		logger.Warn("Failed to attach commit status", map[string]interface{}{
+			"chat_id":    chatID,
+			"commit_sha": result.SHA,
+			"error":      err.Error(),
+		})
+	}
+}
+
+// commitLinkRow returns the keyboard row linking to the file and, when known, the commit
+func commitLinkRow(fileURL string, result *github.CommitResult) []tgbotapi.InlineKeyboardButton {
+	row := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonURL("🔗 View on GitHub", fileURL),
+	)
+	if result != nil && result.URL != "" {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL("🧾 Commit "+result.ShortSHA(), result.URL))
+	}
+	return row
+}
@@ -0,0 +1,55 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestFormatFileSize(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1536, "1.5 KB"},
+		{5 * 1024 * 1024, "5.0 MB"},
+	}
+
+	for _, tt := range tests {
+		if result := formatFileSize(tt.bytes); result != tt.expected {
+			t.Errorf("formatFileSize(%d) = %q, want %q", tt.bytes, result, tt.expected)
+		}
+	}
+}
+
+func TestFormatCommitStats(t *testing.T) {
+	result := &github.CommitResult{
+		Filename: "notes/journal.md",
+		SHA:      "a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+		URL:      "https://github.com/user/repo/commit/a1b2c3d4e5f60718293a4b5c6d7e8f9012345678",
+		FileSize: 2048,
+	}
+
+	tests := []struct {
+		name         string
+		result       *github.CommitResult
+		entriesToday int
+		expected     string
+	}{
+		{"nil result", nil, 3, ""},
+		{"full stats", result, 3, "📄 journal.md · 2.0 KB · 3 entries today · a1b2c3d"},
+		{"single entry", result, 1, "📄 journal.md · 2.0 KB · 1 entry today · a1b2c3d"},
+		{"no commit log", result, 0, "📄 journal.md · 2.0 KB · a1b2c3d"},
+		{"unknown size", &github.CommitResult{Filename: "todo.md", SHA: "abc"}, 2, "📄 todo.md · 2 entries today · abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if stats := formatCommitStats(tt.result, tt.entriesToday); stats != tt.expected {
+				t.Errorf("formatCommitStats() = %q, want %q", stats, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/limits"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Compose mode: /compose starts a draft collecting the following text and photo messages, /send
+// turns the draft into a single entry and /discard drops it. Drafts live in the database so they
+// survive restarts, and expire composeSessionTTL after the last message added to them.
+
+// composeSessionTTL is how long a draft is kept after it was started or last added to
+const composeSessionTTL = 6 * time.Hour
+
+// handleComposeCommand starts a draft, or tells the user about the one in progress
+func (b *Bot) handleComposeCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Compose mode requires database configuration")
+		return nil
+	}
+
+	session, err := b.db.GetComposeSession(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get compose session: %w", err)
+	}
+	if session != nil {
+		b.sendResponse(chatID, fmt.Sprintf("✍️ You're already composing a draft with %d message(s).\n\nKeep sending messages, then /send to save them or /discard to drop them.", len(session.GetParts())))
+		return nil
+	}
+
+	if _, err := b.db.StartComposeSession(chatID, time.Now().Add(composeSessionTTL)); err != nil {
+		return fmt.Errorf("failed to start compose session: %w", err)
+	}
+
+	b.sendResponse(chatID, "✍️ <b>Compose mode</b>\n\nSend text and photos, they are collected into one draft.\n\n• /send - Save the draft as a single entry\n• /discard - Drop the draft\n\n<i>Drafts are kept for 6 hours after the last message.</i>")
+	return nil
+}
+
+// captureComposePart adds a message to the chat's draft, reporting false if there is no draft
+// and the message should be handled as usual
+func (b *Bot) captureComposePart(message *tgbotapi.Message) bool {
+	if b.db == nil || b.cfg().ZeroRetention() || strings.HasPrefix(message.Text, "/") {
+		return false
+	}
+
+	var part database.ComposePart
+	switch {
+	case len(message.Photo) > 0:
+		part.PhotoFileID = message.Photo[len(message.Photo)-1].FileID
+		part.Text = b.telegramToMarkdown(message.Caption, message.CaptionEntities)
+	case message.Text != "":
+		part.Text = b.telegramToMarkdown(message.Text, message.Entities)
+	default:
+		return false
+	}
+
+	count, err := b.db.AppendComposePart(message.Chat.ID, part, time.Now().Add(composeSessionTTL))
+	if err != nil {
+		logger.Error("Failed to add message to compose session", map[string]interface{}{
+			"chat_id": message.Chat.ID,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	if count == 0 {
+		return false
+	}
+
+	b.sendResponse(message.Chat.ID, fmt.Sprintf("📎 Added to draft (%d). /send when you're done.", count))
+	return true
+}
+
+// handleSendCommand turns the draft into a single entry and offers the usual save locations
+func (b *Bot) handleSendCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Compose mode requires database configuration")
+		return nil
+	}
+
+	session, err := b.db.GetComposeSession(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get compose session: %w", err)
+	}
+	if session == nil {
+		b.sendResponse(chatID, "✍️ No draft in progress. Start one with /compose.")
+		return nil
+	}
+	parts := session.GetParts()
+	if len(parts) == 0 {
+		b.sendResponse(chatID, "✍️ Your draft is empty. Send some messages first, or /discard it.")
+		return nil
+	}
+
+	content, err := b.buildComposeEntry(chatID, parts)
+	if err != nil {
+		// The draft is kept so the user can retry
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to send the draft, it is kept so you can try again: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	if _, err := b.db.DeleteComposeSession(chatID); err != nil {
+		return fmt.Errorf("failed to delete compose session: %w", err)
+	}
+
+	logger.Info("Compose session sent", map[string]interface{}{
+		"chat_id": chatID,
+		"parts":   len(parts),
+	})
+
+	// Continue like a single text message (implemented in utils.go)
+	return b.showFileSelectionButtons(&tgbotapi.Message{
+		MessageID: message.MessageID,
+		From:      message.From,
+		Chat:      message.Chat,
+		Date:      message.Date,
+		Text:      content,
+	})
+}
+
+// handleDiscardCommand drops the draft
+func (b *Bot) handleDiscardCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Compose mode requires database configuration")
+		return nil
+	}
+
+	session, err := b.db.DeleteComposeSession(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to delete compose session: %w", err)
+	}
+	if session == nil {
+		b.sendResponse(chatID, "✍️ No draft to discard.")
+		return nil
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("🗑 Draft with %d message(s) discarded.", len(session.GetParts())))
+	return nil
+}
+
+// buildComposeEntry joins the parts of a draft into markdown, uploading its photos to the CDN
+func (b *Bot) buildComposeEntry(chatID int64, parts []database.ComposePart) (string, error) {
+	photos := 0
+	for _, part := range parts {
+		if part.PhotoFileID != "" {
+			photos++
+		}
+	}
+
+	var photoURLs []string
+	if photos > 0 {
+		urls, err := b.uploadComposePhotos(chatID, parts, photos)
+		if err != nil {
+			return "", err
+		}
+		photoURLs = urls
+	}
+
+	blocks := make([]string, 0, len(parts))
+	for _, part := range parts {
+		text := strings.TrimSpace(part.Text)
+		switch {
+		case part.PhotoFileID != "":
+			blocks = append(blocks, entry.Photo(photoURLs[0], text))
+			photoURLs = photoURLs[1:]
+		case text != "":
+			blocks = append(blocks, text)
+		}
+	}
+
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// uploadComposePhotos uploads the photos of a draft in order after checking the image limit
+func (b *Bot) uploadComposePhotos(chatID int64, parts []database.ComposePart, photos int) ([]string, error) {
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return nil, fmt.Errorf("%s. %s", err.Error(), consts.GitHubSetupPrompt)
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	images, err := b.usageLimits().Check(chatID, limits.Images, premiumLevel, int64(photos))
+	if err != nil {
+		logger.Warn("Failed to check image limit before compose upload", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	} else if !images.Allowed {
+		return nil, fmt.Errorf("it has %d photo(s) but only %d image upload(s) are left this period, use /coffee for higher limits", photos, images.Remaining())
+	}
+
+	if err := provider.EnsureRepositoryWithPremium(premiumLevel); err != nil {
+		b.checkRepoMoved(chatID, err)
+		b.recordRepoFailure(chatID, err)
+		return nil, fmt.Errorf("failed to set up repository: %w", err)
+	}
+
+	urls := make([]string, 0, photos)
+	for _, part := range parts {
+		if part.PhotoFileID == "" {
+			continue
+		}
+
+		data, filename, err := b.downloadPhoto(chatID, part.PhotoFileID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to download photo: %w", err)
+		}
+		url, reused, err := b.uploadPhoto(chatID, provider, b.generateUniquePhotoFilename(filename), data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload photo: %w", err)
+		}
+		urls = append(urls, url)
+		if reused {
+			continue
+		}
+
+		if err := b.db.IncrementImageCount(chatID); err != nil {
+			logger.Error("Failed to increment image count", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		}
+		if err := b.db.IncrementUsageImageCount(chatID); err != nil {
+			logger.Error("Failed to increment usage image count", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		}
+	}
+
+	return urls, nil
+}
+
+// purgeExpiredComposeSessions deletes drafts nobody sent or discarded in time
+func (b *Bot) purgeExpiredComposeSessions() {
+	deleted, err := b.db.DeleteExpiredComposeSessions(time.Now())
+	if err != nil {
+		logger.Error("Failed to purge expired compose sessions", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	if deleted > 0 {
+		logger.Info("Purged expired compose sessions", map[string]interface{}{
+			"count": deleted,
+		})
+	}
+}
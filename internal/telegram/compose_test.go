@@ -0,0 +1,49 @@
+package telegram
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestBuildComposeEntry(t *testing.T) {
+	b := &Bot{}
+	parts := []database.ComposePart{
+		{Text: "First thought"},
+		{Text: "  "},
+		{Text: "Second **thought**\nwith details"},
+	}
+
+	content, err := b.buildComposeEntry(1, parts)
+	if err != nil {
+		t.Fatalf("buildComposeEntry() error = %v", err)
+	}
+	want := "First thought\n\nSecond **thought**\nwith details"
+	if content != want {
+		t.Errorf("buildComposeEntry() = %q, want %q", content, want)
+	}
+}
+
+func TestCaptureComposePart_WithoutDraft(t *testing.T) {
+	b := &Bot{config: &config.Config{}}
+	message := &tgbotapi.Message{Text: "hello", Chat: &tgbotapi.Chat{ID: 1}}
+
+	// Without a database there are no drafts and messages are handled as usual
+	if b.captureComposePart(message) {
+		t.Error("captureComposePart() captured a message without a database")
+	}
+}
+
+func TestComposeSessionParts(t *testing.T) {
+	session := &database.ComposeSession{Parts: `[{"text":"note"},{"text":"caption","photo_file_id":"AgAD"}]`}
+	parts := session.GetParts()
+	if len(parts) != 2 || parts[0].Text != "note" || parts[1].PhotoFileID != "AgAD" {
+		t.Errorf("GetParts() = %+v", parts)
+	}
+
+	if parts := (&database.ComposeSession{Parts: "not json"}).GetParts(); parts != nil {
+		t.Errorf("GetParts() = %+v, want nil for invalid parts", parts)
+	}
+}
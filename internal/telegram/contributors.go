@@ -0,0 +1,176 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Contributors: /contributors analyzes the history of the notes repository's local clone and shows
+// the commits of every author per week, for repositories shared by several people. Results are
+// cached per chat and number of weeks.
+
+const (
+	contributorsDefaultWeeks = 8
+	contributorsMaxWeeks     = 26
+	contributorsMaxAuthors   = 10
+	contributorsCacheTTL     = 30 * time.Minute
+)
+
+// contributorStats are the commits of one author, by week
+type contributorStats struct {
+	Name   string
+	Email  string
+	Weekly []int // Oldest week first
+	Total  int
+	LastAt time.Time
+}
+
+// handleContributorsCommand shows commits per author per week
+func (b *Bot) handleContributorsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	weeks := contributorsDefaultWeeks
+	if arg := strings.TrimSpace(message.CommandArguments()); arg != "" {
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 1 || n > contributorsMaxWeeks {
+			b.sendResponse(chatID, fmt.Sprintf("Usage: <code>/contributors [weeks]</code>, up to %d weeks.", contributorsMaxWeeks))
+			return nil
+		}
+		weeks = n
+	}
+
+	cacheKey := fmt.Sprintf("contributors_%d_%d", chatID, weeks)
+	if cached, exists := b.cache.Get(cacheKey); exists {
+		if text, ok := cached.(string); ok {
+			b.sendResponse(chatID, text)
+			return nil
+		}
+	}
+
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	reader, ok := provider.(github.HistoryReader)
+	if !ok {
+		b.sendResponse(chatID, "👥 /contributors can't read the history of your repository. The GitHub Insights tab of your repository shows contributors too.")
+		return nil
+	}
+
+	loading := tgbotapi.NewMessage(chatID, "👥 Analyzing repository history...")
+	sent, err := b.rateLimitedSend(chatID, loading)
+	if err != nil {
+		return fmt.Errorf("failed to send loading message: %w", err)
+	}
+
+	now := time.Now()
+	from := weekStart(now).AddDate(0, 0, -7*(weeks-1))
+	commits, err := reader.CommitHistory(from)
+	if err != nil {
+		logger.Warn("Failed to read repository history", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.editMessage(chatID, sent.MessageID, "❌ Failed to read repository history: "+err.Error())
+		return nil
+	}
+
+	text := formatContributors(contributorsByWeek(commits, from, weeks), from, weeks)
+	b.cache.SetWithExpiry(cacheKey, text, contributorsCacheTTL)
+	return b.sendLongReply(chatID, longReply{Text: text, EditMessageID: sent.MessageID})
+}
+
+// weekStart returns local midnight of the Monday of t's week
+func weekStart(t time.Time) time.Time {
+	day := startOfDay(t)
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// contributorsByWeek counts the commits of every author in each of the weeks starting at from,
+// most active author first. Authors are told apart by email, shown with their latest name.
+func contributorsByWeek(commits []github.HistoryCommit, from time.Time, weeks int) []*contributorStats {
+	byEmail := make(map[string]*contributorStats)
+	for _, commit := range commits {
+		if commit.When.Before(from) {
+			continue
+		}
+		// Days are rounded as weeks with a DST change are an hour shorter or longer
+		days := int((weekStart(commit.When.In(from.Location())).Sub(from) + 12*time.Hour) / (24 * time.Hour))
+		week := days / 7
+		if week >= weeks {
+			continue
+		}
+
+		key := strings.ToLower(commit.AuthorEmail)
+		if key == "" {
+			key = commit.AuthorName
+		}
+		stats := byEmail[key]
+		if stats == nil {
+			stats = &contributorStats{Email: commit.AuthorEmail, Weekly: make([]int, weeks)}
+			byEmail[key] = stats
+		}
+		if commit.When.After(stats.LastAt) {
+			stats.LastAt = commit.When
+			stats.Name = commit.AuthorName
+		}
+		stats.Weekly[week]++
+		stats.Total++
+	}
+
+	contributors := make([]*contributorStats, 0, len(byEmail))
+	for _, stats := range byEmail {
+		contributors = append(contributors, stats)
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Total != contributors[j].Total {
+			return contributors[i].Total > contributors[j].Total
+		}
+		return contributors[i].Name < contributors[j].Name
+	})
+	return contributors
+}
+
+// formatContributors renders the weekly commit counts of the most active authors
+func formatContributors(contributors []*contributorStats, from time.Time, weeks int) string {
+	last := from.AddDate(0, 0, 7*(weeks-1))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("👥 <b>Contributors</b> · last %d weeks\n", weeks))
+	sb.WriteString(fmt.Sprintf("<i>Commits per week, weeks of %s to %s</i>\n\n", from.Format("Jan 2"), last.Format("Jan 2")))
+
+	if len(contributors) == 0 {
+		sb.WriteString("No commits in this period.")
+		return sb.String()
+	}
+
+	for i, stats := range contributors {
+		if i == contributorsMaxAuthors {
+			sb.WriteString(fmt.Sprintf("<i>…and %d more</i>\n", len(contributors)-contributorsMaxAuthors))
+			break
+		}
+		counts := make([]string, len(stats.Weekly))
+		for week, count := range stats.Weekly {
+			counts[week] = strconv.Itoa(count)
+		}
+		name := stats.Name
+		if name == "" {
+			name = stats.Email
+		}
+		commitsLabel := "commits"
+		if stats.Total == 1 {
+			commitsLabel = "commit"
+		}
+		sb.WriteString(fmt.Sprintf("• <b>%s</b> · %d %s\n<code>%s</code>\n", html.EscapeString(name), stats.Total, commitsLabel, strings.Join(counts, " ")))
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestWeekStart(t *testing.T) {
+	sunday := time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC)
+	if got, want := weekStart(sunday), time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("weekStart(Sunday) = %v, want %v", got, want)
+	}
+	monday := time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)
+	if got := weekStart(monday); !got.Equal(monday) {
+		t.Errorf("weekStart(Monday) = %v, want %v", got, monday)
+	}
+}
+
+func TestContributorsByWeek(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	commits := []github.HistoryCommit{
+		{AuthorName: "Alice B", AuthorEmail: "alice@example.com", When: from.AddDate(0, 0, 15)},
+		{AuthorName: "Alice", AuthorEmail: "Alice@example.com", When: from.AddDate(0, 0, 1)},
+		{AuthorName: "Alice", AuthorEmail: "alice@example.com", When: from.AddDate(0, 0, 2)},
+		{AuthorName: "Bob", AuthorEmail: "bob@example.com", When: from.AddDate(0, 0, 8)},
+		{AuthorName: "Old", AuthorEmail: "old@example.com", When: from.AddDate(0, 0, -1)},
+	}
+
+	contributors := contributorsByWeek(commits, from, 3)
+	if len(contributors) != 2 {
+		t.Fatalf("contributorsByWeek() returned %d authors, want 2", len(contributors))
+	}
+	alice := contributors[0]
+	if alice.Name != "Alice B" || alice.Total != 3 {
+		t.Errorf("first author = %+v, want Alice B with 3 commits under her latest name", alice)
+	}
+	if want := []int{2, 0, 1}; alice.Weekly[0] != want[0] || alice.Weekly[1] != want[1] || alice.Weekly[2] != want[2] {
+		t.Errorf("Alice weekly = %v, want %v", alice.Weekly, want)
+	}
+	if bob := contributors[1]; bob.Weekly[1] != 1 {
+		t.Errorf("Bob weekly = %v, want his commit in the second week", bob.Weekly)
+	}
+}
+
+func TestFormatContributors(t *testing.T) {
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	empty := formatContributors(nil, from, 4)
+	if !strings.Contains(empty, "No commits") {
+		t.Errorf("formatContributors(nil) = %q", empty)
+	}
+
+	text := formatContributors([]*contributorStats{
+		{Name: "<Alice>", Weekly: []int{2, 0, 1, 4}, Total: 7},
+		{Name: "Bob", Weekly: []int{0, 0, 1, 0}, Total: 1},
+	}, from, 4)
+	for _, want := range []string{"weeks of Mar 2 to Mar 23", "<b>&lt;Alice&gt;</b> · 7 commits", "<code>2 0 1 4</code>", "Bob</b> · 1 commit\n"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatContributors() = %q, want it to contain %q", text, want)
+		}
+	}
+}
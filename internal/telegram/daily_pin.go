@@ -0,0 +1,313 @@
+package telegram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/render"
+)
+
+// Daily pin: an opt-in summary of yesterday's captures, completed TODOs and tags, posted and pinned
+// once a day in place of the previous one as an ambient dashboard of the chat
+
+const (
+	dailyPinCheckInterval = 1 * time.Hour
+	dailyPinRetention     = 7 * 24 * time.Hour // Activity is only summarized for the previous day
+	dailyPinMaxFiles      = 5
+	dailyPinMaxTags       = 5
+)
+
+// dailyCount is a file or tag with the number of times it was used on a day
+type dailyCount struct {
+	Name  string
+	Count int
+}
+
+// rememberNoteTags keeps the tags of a note being committed until recordNoteTags
+func (b *Bot) rememberNoteTags(chatID int64, filename, tags string) {
+	key := noteLinksKey(chatID, filename)
+	if strings.TrimSpace(tags) == "" {
+		b.pendingNoteTags.Delete(key)
+		return
+	}
+	b.pendingNoteTags.Store(key, tags)
+}
+
+// recordNoteTags records the tags of a note just committed to filename for the daily summary
+func (b *Bot) recordNoteTags(chatID int64, filename string) {
+	value, ok := b.pendingNoteTags.LoadAndDelete(noteLinksKey(chatID, filename))
+	if !ok || b.db == nil || b.cfg().ZeroRetention() {
+		return
+	}
+
+	for _, tag := range strings.Fields(value.(string)) {
+		if err := b.db.RecordActivity(chatID, database.ActivityTag, strings.ToLower(tag)); err != nil {
+			logger.Warn("Failed to record tag activity", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			return
+		}
+	}
+}
+
+// recordTodoCompleted records a completed TODO for the daily summary
+func (b *Bot) recordTodoCompleted(chatID int64) {
+	if b.db == nil {
+		return
+	}
+	if err := b.db.RecordActivity(chatID, database.ActivityTodoCompleted, ""); err != nil {
+		logger.Warn("Failed to record completed TODO", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// handlePinCommand handles /pin, /pin on and /pin off
+func (b *Bot) handlePinCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/pin")))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ The daily pin requires a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch args {
+	case "":
+		pin, err := b.db.GetDailyPin(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load the daily pin.")
+			return nil
+		}
+		if pin == nil {
+			b.sendResponse(chatID, "📌 The daily pin is off.\n\nUse <code>/pin on</code> to pin a summary of yesterday's captures every day.")
+			return nil
+		}
+		b.sendResponse(chatID, "📌 The daily pin is on. A summary of yesterday is pinned every day after midnight.\n\nUse <code>/pin off</code> to stop.")
+		return nil
+	case "on":
+		if err := b.db.EnableDailyPin(chatID); err != nil {
+			b.sendResponse(chatID, "❌ Failed to enable the daily pin.")
+			return nil
+		}
+		pin, err := b.db.GetDailyPin(chatID)
+		if err != nil || pin == nil {
+			b.sendResponse(chatID, "❌ Failed to enable the daily pin.")
+			return nil
+		}
+		if err := b.postDailyPin(pin, time.Now()); err != nil {
+			b.sendResponse(chatID, "✅ Daily pin enabled, but pinning failed. Make sure the bot is allowed to pin messages in this chat.")
+			return nil
+		}
+		return nil
+	case "off":
+		pin, err := b.db.DisableDailyPin(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to disable the daily pin.")
+			return nil
+		}
+		if pin != nil && pin.MessageID != 0 {
+			b.unpinMessage(chatID, pin.MessageID)
+		}
+		b.sendResponse(chatID, "📌 Daily pin disabled.")
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/pin</code>, <code>/pin on</code> or <code>/pin off</code>")
+		return nil
+	}
+}
+
+// startDailyPins periodically replaces the pinned summaries once the day has changed
+func (b *Bot) startDailyPins() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopDailyPins = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(dailyPinCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runDailyPins()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runDailyPins() {
+	now := time.Now()
+	pins, err := b.db.GetDueDailyPins(startOfDay(now))
+	if err != nil {
+		logger.Error("Failed to load daily pins", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, pin := range pins {
+		if !b.chatAllowed(pin.ChatID) {
+			continue
+		}
+		if err := b.postDailyPin(pin, now); err != nil {
+			logger.Warn("Failed to post daily pin", map[string]interface{}{
+				"chat_id": pin.ChatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	if _, err := b.db.DeleteActivityBefore(now.Add(-dailyPinRetention), database.ActivityTodoCompleted, database.ActivityTag); err != nil {
+		logger.Warn("Failed to prune activity", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	if _, err := b.db.DeleteActivityBefore(now.Add(-moodRetention), database.ActivityMood); err != nil {
+		logger.Warn("Failed to prune moods", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// postDailyPin sends and pins the summary of the day before now, unpinning the previous summary
+func (b *Bot) postDailyPin(pin *database.DailyPin, now time.Time) error {
+	chatID := pin.ChatID
+	to := startOfDay(now)
+	from := to.AddDate(0, 0, -1)
+
+	files, err := b.db.CountCommitsByFile(chatID, from, to)
+	if err != nil {
+		return err
+	}
+	todos, err := b.db.CountActivity(chatID, database.ActivityTodoCompleted, from, to)
+	if err != nil {
+		return err
+	}
+	tags, err := b.db.CountActivity(chatID, database.ActivityTag, from, to)
+	if err != nil {
+		return err
+	}
+
+	summary := formatDailySummary(from, files, todos[""], tags)
+	msg := newRenderedMessage(chatID, summary)
+	msg.DisableNotification = true
+	sent, err := b.rateLimitedSend(chatID, msg)
+	if err != nil {
+		return fmt.Errorf("failed to send summary: %w", err)
+	}
+
+	if _, err := b.rateLimitedRequest(chatID, tgbotapi.PinChatMessageConfig{
+		ChatID:              chatID,
+		MessageID:           sent.MessageID,
+		DisableNotification: true,
+	}); err != nil {
+		return fmt.Errorf("failed to pin summary: %w", err)
+	}
+
+	if pin.MessageID != 0 && pin.MessageID != sent.MessageID {
+		b.unpinMessage(chatID, pin.MessageID)
+	}
+
+	return b.db.UpdateDailyPin(chatID, sent.MessageID, now)
+}
+
+// unpinMessage unpins a previous summary, which may already be unpinned or deleted
+func (b *Bot) unpinMessage(chatID int64, messageID int) {
+	if _, err := b.rateLimitedRequest(chatID, tgbotapi.UnpinChatMessageConfig{
+		ChatID:    chatID,
+		MessageID: messageID,
+	}); err != nil {
+		logger.Debug("Failed to unpin previous daily summary", map[string]interface{}{
+			"chat_id":    chatID,
+			"message_id": messageID,
+			"error":      err.Error(),
+		})
+	}
+}
+
+// startOfDay returns local midnight of the day of t
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// topCounts sorts counts by count, then name, and keeps the first limit
+func topCounts(counts map[string]int, limit int) []dailyCount {
+	top := make([]dailyCount, 0, len(counts))
+	for name, count := range counts {
+		top = append(top, dailyCount{Name: name, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Name < top[j].Name
+	})
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+// formatDailySummary renders the pinned summary of a day
+func formatDailySummary(day time.Time, files map[string]int, todosCompleted int, tags map[string]int) *render.Message {
+	captures := 0
+	for _, count := range files {
+		captures += count
+	}
+
+	msg := render.New(render.HTML).Text("📌 ").Bold("Yesterday, " + day.Format("Mon Jan 2")).Newline()
+	if captures == 0 && todosCompleted == 0 {
+		msg.Line("Nothing captured. A fresh start today!")
+		return msg
+	}
+
+	switch captures {
+	case 0:
+		msg.Line("📝 No captures")
+	case 1:
+		msg.Line("📝 1 capture")
+	default:
+		msg.Line(fmt.Sprintf("📝 %d captures", captures))
+	}
+	for _, file := range topCounts(files, dailyPinMaxFiles) {
+		msg.Text("  • ").Code(file.Name).Text(fmt.Sprintf(" ×%d", file.Count)).Newline()
+	}
+	if hidden := len(files) - dailyPinMaxFiles; hidden > 0 {
+		msg.Italic(fmt.Sprintf("  …and %d more files", hidden)).Newline()
+	}
+
+	if todosCompleted == 1 {
+		msg.Line("✅ 1 TODO completed")
+	} else if todosCompleted > 1 {
+		msg.Line(fmt.Sprintf("✅ %d TODOs completed", todosCompleted))
+	}
+
+	if len(tags) > 0 {
+		names := make([]string, 0, dailyPinMaxTags)
+		for _, tag := range topCounts(tags, dailyPinMaxTags) {
+			names = append(names, tag.Name)
+		}
+		msg.Line("🏷 " + strings.Join(names, " "))
+	}
+
+	return msg
+}
@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTopCounts(t *testing.T) {
+	counts := map[string]int{"b.md": 2, "a.md": 2, "c.md": 5, "d.md": 1}
+
+	top := topCounts(counts, 3)
+	want := []string{"c.md", "a.md", "b.md"}
+	if len(top) != len(want) {
+		t.Fatalf("topCounts() returned %d entries, want %d", len(top), len(want))
+	}
+	for i, name := range want {
+		if top[i].Name != name {
+			t.Errorf("topCounts()[%d] = %s, want %s", i, top[i].Name, name)
+		}
+	}
+}
+
+func TestStartOfDay(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	got := startOfDay(time.Date(2025, 3, 4, 1, 30, 0, 0, loc))
+	want := time.Date(2025, 3, 4, 0, 0, 0, 0, loc)
+	if !got.Equal(want) {
+		t.Errorf("startOfDay() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatDailySummary(t *testing.T) {
+	day := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		files    map[string]int
+		todos    int
+		tags     map[string]int
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "empty day",
+			contains: []string{"Yesterday, Mon Mar 3", "Nothing captured"},
+			excludes: []string{"TODO"},
+		},
+		{
+			name:     "captures, todos and tags",
+			files:    map[string]int{"inbox.md": 3, "notes/<draft>.md": 1},
+			todos:    2,
+			tags:     map[string]int{"#idea": 2, "#work": 1},
+			contains: []string{"4 captures", "<code>inbox.md</code> ×3", "notes/&lt;draft&gt;.md", "2 TODOs completed", "#idea #work"},
+			excludes: []string{"Nothing captured"},
+		},
+		{
+			name:     "only todos",
+			todos:    1,
+			contains: []string{"No captures", "1 TODO completed"},
+		},
+		{
+			name:     "many files",
+			files:    map[string]int{"a.md": 1, "b.md": 1, "c.md": 1, "d.md": 1, "e.md": 1, "f.md": 1, "g.md": 1},
+			contains: []string{"7 captures", "and 2 more files"},
+			excludes: []string{"g.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatDailySummary(day, tt.files, tt.todos, tt.tags).String()
+			for _, want := range tt.contains {
+				if !strings.Contains(got, want) {
+					t.Errorf("formatDailySummary() = %q, want it to contain %q", got, want)
+				}
+			}
+			for _, unwanted := range tt.excludes {
+				if strings.Contains(got, unwanted) {
+					t.Errorf("formatDailySummary() = %q, should not contain %q", got, unwanted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,282 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/backup"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/objectstore"
+)
+
+// Database backups: with the BACKUP_* settings, the database (users, insights, premium and every
+// other table) is dumped, encrypted and uploaded to object storage every BackupInterval, keeping
+// the newest BackupRetention backups (see internal/backup). Admins back up on demand with
+// /admin backup, list backups with /admin backups and restore one with /admin restore, which
+// backs up the current database first so a restore can be undone.
+
+const backupStartupDelay = time.Minute // First scheduled backup after a start without recent backups
+
+// backupStore returns the configured backup store
+func (b *Bot) backupStore() (*backup.Store, error) {
+	if !b.cfg().HasBackupConfig() {
+		return nil, fmt.Errorf("backups are not configured, set the BACKUP_* settings")
+	}
+	client, err := objectstore.New(b.cfg().BackupS3Endpoint, b.cfg().BackupS3Bucket, b.cfg().BackupS3Region, b.cfg().BackupS3AccessKey, b.cfg().BackupS3SecretKey)
+	if err != nil {
+		return nil, err
+	}
+	return backup.NewStore(client, b.cfg().BackupPassword), nil
+}
+
+// startBackups backs up the database every BackupInterval, continuing from the latest backup
+func (b *Bot) startBackups() {
+	if b.db == nil || !b.cfg().HasBackupConfig() || b.cfg().BackupInterval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopBackups = func() { close(stop) }
+
+	go func() {
+		wait := b.nextBackupWait(time.Now())
+		for {
+			timer := time.NewTimer(wait)
+			select {
+			case <-stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+
+			if _, err := b.runBackup(); err != nil {
+				logger.Error("Scheduled database backup failed", map[string]interface{}{
+					"error": err.Error(),
+				})
+				b.notifyAdmins(fmt.Sprintf("⚠️ <b>Database backup failed</b>\n\n%s", html.EscapeString(err.Error())))
+			}
+			wait = b.cfg().BackupInterval
+		}
+	}()
+
+	logger.Info("Database backups scheduled", map[string]interface{}{
+		"interval":  b.cfg().BackupInterval.String(),
+		"retention": b.cfg().BackupRetention,
+	})
+}
+
+// nextBackupWait returns how long until the next scheduled backup is due, so restarts don't
+// back up again before the interval passed
+func (b *Bot) nextBackupWait(now time.Time) time.Duration {
+	store, err := b.backupStore()
+	if err != nil {
+		return backupStartupDelay
+	}
+	backups, err := store.List()
+	if err != nil || len(backups) == 0 {
+		return backupStartupDelay
+	}
+	if wait := backups[0].CreatedAt.Add(b.cfg().BackupInterval).Sub(now); wait > backupStartupDelay {
+		return wait
+	}
+	return backupStartupDelay
+}
+
+// runBackup dumps the database, uploads it and prunes old backups
+func (b *Bot) runBackup() (backup.Backup, error) {
+	store, err := b.backupStore()
+	if err != nil {
+		return backup.Backup{}, err
+	}
+
+	start := time.Now()
+	var rows int
+	saved, err := store.Save(time.Now(), func(w io.Writer) error {
+		var err error
+		rows, err = b.db.Dump(w)
+		return err
+	})
+	if err != nil {
+		return backup.Backup{}, err
+	}
+
+	pruned, err := store.Prune(b.cfg().BackupRetention)
+	if err != nil {
+		logger.Warn("Failed to prune old database backups", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	logger.Info("Database backed up", map[string]interface{}{
+		"backup":      saved.Name,
+		"rows":        rows,
+		"bytes":       saved.Size,
+		"pruned":      pruned,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	return saved, nil
+}
+
+// notifyAdmins sends text to every admin chat
+func (b *Bot) notifyAdmins(text string) {
+	for _, adminID := range b.cfg().AdminChatIDs {
+		b.sendResponse(adminID, text)
+	}
+}
+
+// handleAdminBackupCommand backs up the database now
+func (b *Bot) handleAdminBackupCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ No database configured, there is nothing to back up.")
+		return nil
+	}
+
+	saved, err := b.runBackup()
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Backup failed: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	b.sendResponse(chatID, fmt.Sprintf("✅ Database backed up to <code>%s</code> (%s).", saved.Name, formatBackupSize(saved.Size)))
+	return nil
+}
+
+// handleAdminBackupsCommand lists the stored backups
+func (b *Bot) handleAdminBackupsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	store, err := b.backupStore()
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	backups, err := store.List()
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if len(backups) == 0 {
+		b.sendResponse(chatID, "💾 No backups yet. Run /admin backup to take one.")
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("💾 <b>Database backups</b> (keeping %d)\n\n", b.cfg().BackupRetention))
+	for _, stored := range backups {
+		sb.WriteString(fmt.Sprintf("• <code>%s</code> - %s\n", stored.Name, formatBackupSize(stored.Size)))
+	}
+	sb.WriteString("\nRestore one with <code>/admin restore &lt;name&gt;</code> or <code>/admin restore latest</code>.")
+	b.sendResponse(chatID, sb.String())
+	return nil
+}
+
+// handleAdminRestoreCommand asks to confirm restoring a backup
+func (b *Bot) handleAdminRestoreCommand(message *tgbotapi.Message, args []string) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ No database configured to restore into.")
+		return nil
+	}
+	if len(args) != 1 {
+		b.sendResponse(chatID, "Usage: <code>/admin restore &lt;name&gt;</code> or <code>/admin restore latest</code>. List backups with /admin backups.")
+		return nil
+	}
+
+	store, err := b.backupStore()
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	name := args[0]
+	if name == "latest" {
+		backups, err := store.List()
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if len(backups) == 0 {
+			b.sendResponse(chatID, "💾 No backups to restore.")
+			return nil
+		}
+		name = backups[0].Name
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("⚠️ <b>Restore %s?</b>\n\nEvery table is replaced with the backup's content, changes since the backup are lost. The current database is backed up first.", html.EscapeString(name)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("♻️ Restore", "backup_restore_"+name),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "backup_restore_cancel"),
+	))
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		return fmt.Errorf("failed to send restore confirmation: %w", err)
+	}
+	return nil
+}
+
+// handleBackupRestoreCallback restores a confirmed backup
+func (b *Bot) handleBackupRestoreCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+	if !b.cfg().IsAdmin(chatID) {
+		logger.Warn("Unauthorized backup restore attempt", map[string]interface{}{
+			"chat_id": chatID,
+		})
+		return nil
+	}
+
+	name := strings.TrimPrefix(callback.Data, "backup_restore_")
+	if name == "cancel" {
+		b.editMessage(chatID, messageID, "❌ Restore cancelled.")
+		return nil
+	}
+
+	store, err := b.backupStore()
+	if err != nil {
+		b.editOrSendHTML(chatID, messageID, "❌ "+html.EscapeString(err.Error()))
+		return nil
+	}
+	b.editMessage(chatID, messageID, fmt.Sprintf("⏳ Restoring %s...", name))
+
+	safety, err := b.runBackup()
+	if err != nil {
+		b.editOrSendHTML(chatID, messageID, "❌ Restore aborted, backing up the current database failed: "+html.EscapeString(err.Error()))
+		return nil
+	}
+
+	var rows int
+	err = store.Load(name, func(dump io.Reader) error {
+		rows, err = b.db.Restore(dump)
+		return err
+	})
+	if err != nil {
+		logger.Error("Database restore failed", map[string]interface{}{
+			"backup": name,
+			"error":  err.Error(),
+		})
+		b.editOrSendHTML(chatID, messageID, "❌ Restore failed, the database is unchanged: "+html.EscapeString(err.Error()))
+		return nil
+	}
+
+	// Cached users and settings predate the restore
+	b.cache.Clear()
+
+	logger.Warn("Database restored from backup", map[string]interface{}{
+		"backup":   name,
+		"rows":     rows,
+		"admin_id": chatID,
+		"previous": safety.Name,
+	})
+	b.editMessage(chatID, messageID, fmt.Sprintf("✅ Restored %d rows from %s. The database before the restore is in %s.", rows, name, safety.Name))
+	return nil
+}
+
+// formatBackupSize formats a backup size in KB or MB
+func formatBackupSize(size int64) string {
+	if size < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(size)/1024/1024)
+}
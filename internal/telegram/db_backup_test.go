@@ -0,0 +1,33 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestBackupsNeedConfig(t *testing.T) {
+	b := &Bot{config: &config.Config{BackupInterval: time.Hour}}
+
+	if _, err := b.backupStore(); err == nil {
+		t.Error("backupStore() error = nil without backup settings")
+	}
+	if wait := b.nextBackupWait(time.Now()); wait != backupStartupDelay {
+		t.Errorf("nextBackupWait() = %v, want %v", wait, backupStartupDelay)
+	}
+
+	b.startBackups()
+	if b.stopBackups != nil {
+		t.Error("startBackups() scheduled backups without a database")
+	}
+}
+
+func TestFormatBackupSize(t *testing.T) {
+	if got := formatBackupSize(2048); got != "2.0 KB" {
+		t.Errorf("formatBackupSize(2048) = %q", got)
+	}
+	if got := formatBackupSize(3 * 1024 * 1024); got != "3.0 MB" {
+		t.Errorf("formatBackupSize(3MB) = %q", got)
+	}
+}
@@ -0,0 +1,400 @@
+package telegram
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+	"golang.org/x/time/rate"
+)
+
+// Downloads: Telegram files (photos, documents, videos, album items) are fetched through one
+// manager that bounds concurrent downloads per chat and overall, throttles total bandwidth and
+// keeps partial downloads in temp files so a retry resumes instead of starting over
+
+// partialFileTTL is how long an abandoned partial download is kept
+const partialFileTTL = 24 * time.Hour
+
+// DownloadConfig bounds Telegram file downloads
+type DownloadConfig struct {
+	PerChatDownloads int    // Concurrent downloads per chat
+	GlobalDownloads  int    // Concurrent downloads across all chats
+	BytesPerSecond   int    // Total download bandwidth, unlimited if 0
+	MaxAttempts      int    // Tries per file, each resuming the partial download
+	Resumable        bool   // Keep partial downloads on disk; in memory only if false
+	TempDir          string // Directory for partial downloads, a msg2git-downloads dir in os.TempDir() if empty
+}
+
+// DefaultDownloadConfig returns a sensible default configuration
+func DefaultDownloadConfig() DownloadConfig {
+	return DownloadConfig{
+		PerChatDownloads: 3,
+		GlobalDownloads:  20,
+		BytesPerSecond:   20 << 20, // 20 MB/s
+		MaxAttempts:      3,
+		Resumable:        true,
+	}
+}
+
+// downloadedFile is a downloaded Telegram file
+type downloadedFile struct {
+	FileID   string
+	Filename string
+	Data     []byte
+}
+
+// chatSlots limits the downloads of one chat, refs counts the goroutines holding or waiting for a slot
+type chatSlots struct {
+	slots chan struct{}
+	refs  int
+}
+
+// downloadManager bounds, throttles and resumes downloads
+type downloadManager struct {
+	config  DownloadConfig
+	client  *http.Client
+	global  chan struct{}
+	limiter *rate.Limiter // nil if bandwidth is unlimited
+
+	mu    sync.Mutex
+	chats map[int64]*chatSlots
+}
+
+// newDownloadManager creates a download manager and removes abandoned partial downloads
+func newDownloadManager(config DownloadConfig) *downloadManager {
+	if config.PerChatDownloads < 1 {
+		config.PerChatDownloads = 1
+	}
+	if config.GlobalDownloads < config.PerChatDownloads {
+		config.GlobalDownloads = config.PerChatDownloads
+	}
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	if config.TempDir == "" {
+		config.TempDir = filepath.Join(os.TempDir(), "msg2git-downloads")
+	}
+
+	dm := &downloadManager{
+		config: config,
+		client: &http.Client{Timeout: 5 * time.Minute},
+		global: make(chan struct{}, config.GlobalDownloads),
+		chats:  make(map[int64]*chatSlots),
+	}
+	if config.BytesPerSecond > 0 {
+		dm.limiter = rate.NewLimiter(rate.Limit(config.BytesPerSecond), config.BytesPerSecond)
+	}
+	if config.Resumable {
+		dm.purgePartials(time.Now().Add(-partialFileTTL))
+	}
+	return dm
+}
+
+// acquire waits for a download slot of the chat and a global one, release gives both back
+func (dm *downloadManager) acquire(ctx context.Context, chatID int64) (release func(), err error) {
+	dm.mu.Lock()
+	chat := dm.chats[chatID]
+	if chat == nil {
+		chat = &chatSlots{slots: make(chan struct{}, dm.config.PerChatDownloads)}
+		dm.chats[chatID] = chat
+	}
+	chat.refs++
+	dm.mu.Unlock()
+
+	done := func() {
+		dm.mu.Lock()
+		chat.refs--
+		if chat.refs == 0 {
+			delete(dm.chats, chatID)
+		}
+		dm.mu.Unlock()
+	}
+
+	// The chat slot is taken first so one chat's queue never holds global slots
+	select {
+	case chat.slots <- struct{}{}:
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+	select {
+	case dm.global <- struct{}{}:
+	case <-ctx.Done():
+		<-chat.slots
+		done()
+		return nil, ctx.Err()
+	}
+
+	return func() {
+		<-dm.global
+		<-chat.slots
+		done()
+	}, nil
+}
+
+// fetch downloads url for a chat, retrying from the partial download. key identifies the file
+// across attempts and size is its expected size, 0 if unknown.
+func (dm *downloadManager) fetch(ctx context.Context, chatID int64, key, url string, size int64) ([]byte, error) {
+	release, err := dm.acquire(ctx, chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for download slot: %w", err)
+	}
+	defer release()
+
+	if !dm.config.Resumable {
+		return dm.fetchToMemory(ctx, url, size)
+	}
+
+	if err := os.MkdirAll(dm.config.TempDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create download directory: %w", err)
+	}
+	partial := dm.partialPath(key)
+
+	var lastErr error
+	for attempt := 1; attempt <= dm.config.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			logger.Debug("Resuming download", map[string]interface{}{
+				"chat_id": chatID,
+				"attempt": attempt,
+				"error":   lastErr.Error(),
+			})
+		}
+		if lastErr = dm.fetchToFile(ctx, url, partial, size); lastErr == nil {
+			break
+		}
+		if ctx.Err() != nil {
+			return nil, lastErr
+		}
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+
+	data, err := os.ReadFile(partial)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read downloaded file: %w", err)
+	}
+	os.Remove(partial)
+	return data, nil
+}
+
+// fetchToFile continues the partial download at path, starting over if the server ignores the range
+func (dm *downloadManager) fetchToFile(ctx context.Context, url, path string, size int64) error {
+	var offset int64
+	if info, err := os.Stat(path); err == nil {
+		offset = info.Size()
+	}
+	if size > 0 && offset == size {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create download request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := dm.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to download file: %w", redactToken(err))
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		flags |= os.O_APPEND
+	case resp.StatusCode == http.StatusOK:
+		flags |= os.O_TRUNC
+		offset = 0
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is longer than the file, start over on the next attempt
+		os.Remove(path)
+		return fmt.Errorf("failed to resume download: HTTP %d", resp.StatusCode)
+	default:
+		return fmt.Errorf("failed to download file: HTTP %d", resp.StatusCode)
+	}
+
+	f, err := os.OpenFile(path, flags, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open partial download: %w", err)
+	}
+	written, copyErr := io.Copy(f, dm.throttle(ctx, resp.Body))
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		return fmt.Errorf("failed to read file data: %w", redactToken(copyErr))
+	}
+	if size > 0 && offset+written != size {
+		return fmt.Errorf("incomplete download: %d of %d bytes", offset+written, size)
+	}
+	return nil
+}
+
+// fetchToMemory downloads url without touching the disk
+func (dm *downloadManager) fetchToMemory(ctx context.Context, url string, size int64) ([]byte, error) {
+	var lastErr error
+	for attempt := 1; attempt <= dm.config.MaxAttempts; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create download request: %w", err)
+		}
+		resp, err := dm.client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to download file: %w", redactToken(err))
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			lastErr = fmt.Errorf("failed to download file: HTTP %d", resp.StatusCode)
+			continue
+		}
+		data, err := io.ReadAll(dm.throttle(ctx, resp.Body))
+		resp.Body.Close()
+		if err != nil {
+			lastErr = fmt.Errorf("failed to read file data: %w", redactToken(err))
+			continue
+		}
+		if size > 0 && int64(len(data)) != size {
+			lastErr = fmt.Errorf("incomplete download: %d of %d bytes", len(data), size)
+			continue
+		}
+		return data, nil
+	}
+	return nil, lastErr
+}
+
+// partialPath is the temp file of a partial download, named after a hash of its key
+func (dm *downloadManager) partialPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dm.config.TempDir, hex.EncodeToString(sum[:12])+".part")
+}
+
+// purgePartials removes partial downloads last written before cutoff
+func (dm *downloadManager) purgePartials(cutoff time.Time) {
+	entries, err := os.ReadDir(dm.config.TempDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".part") {
+			continue
+		}
+		if info, err := entry.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(dm.config.TempDir, entry.Name()))
+		}
+	}
+}
+
+// throttle limits reads from r to the global bandwidth
+func (dm *downloadManager) throttle(ctx context.Context, r io.Reader) io.Reader {
+	if dm.limiter == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, limiter: dm.limiter}
+}
+
+// throttledReader waits for the limiter before handing out bytes
+type throttledReader struct {
+	ctx     context.Context
+	r       io.Reader
+	limiter *rate.Limiter
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if burst := t.limiter.Burst(); len(p) > burst {
+		p = p[:burst]
+	}
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if waitErr := t.limiter.WaitN(t.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+// redactToken strips the bot token from errors carrying a file URL
+func redactToken(err error) error {
+	message := err.Error()
+	if i := strings.Index(message, "/file/bot"); i >= 0 {
+		end := strings.Index(message[i+len("/file/bot"):], "/")
+		if end >= 0 {
+			return fmt.Errorf("%s/file/bot<token>%s", message[:i], message[i+len("/file/bot")+end:])
+		}
+	}
+	return err
+}
+
+// downloadFile resolves a Telegram file and downloads it within the chat's download limits,
+// fallbackName is used if Telegram has no file path
+func (b *Bot) downloadFile(chatID int64, fileID, fallbackName string) (*downloadedFile, error) {
+	file, err := b.api.GetFile(tgbotapi.FileConfig{FileID: fileID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file info: %w", err)
+	}
+
+	logger.Debug("Downloading file from Telegram", map[string]interface{}{
+		"chat_id":   chatID,
+		"file_id":   fileID,
+		"file_size": file.FileSize,
+	})
+
+	key := file.FileUniqueID
+	if key == "" {
+		key = fileID
+	}
+	data, err := b.downloads.fetch(context.Background(), chatID, key, file.Link(b.api.Token), int64(file.FileSize))
+	if err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Base(file.FilePath)
+	if filename == "." || filename == "" || filename == "/" {
+		filename = fallbackName
+	}
+
+	logger.Debug("File downloaded successfully", map[string]interface{}{
+		"filename": filename,
+		"size":     len(data),
+	})
+
+	return &downloadedFile{FileID: fileID, Filename: filename, Data: data}, nil
+}
+
+// downloadFiles downloads several files of a chat at once, e.g. an album, keeping their order.
+// The chat's download limit bounds how many run in parallel; the first error is returned.
+func (b *Bot) downloadFiles(chatID int64, fileIDs []string, fallbackName string) ([]*downloadedFile, error) {
+	files := make([]*downloadedFile, len(fileIDs))
+	errs := make([]error, len(fileIDs))
+
+	var wg sync.WaitGroup
+	for i, fileID := range fileIDs {
+		wg.Add(1)
+		go func(i int, fileID string) {
+			defer wg.Done()
+			files[i], errs[i] = b.downloadFile(chatID, fileID, fallbackName)
+		}(i, fileID)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("failed to download file %d of %d: %w", i+1, len(fileIDs), err)
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,177 @@
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testDownloadManager(t *testing.T, resumable bool) *downloadManager {
+	t.Helper()
+	return newDownloadManager(DownloadConfig{
+		PerChatDownloads: 2,
+		GlobalDownloads:  4,
+		MaxAttempts:      3,
+		Resumable:        resumable,
+		TempDir:          t.TempDir(),
+	})
+}
+
+func TestDownloadResumesPartialFile(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first response breaks off halfway
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Content-Length", "10000")
+			w.Write(content[:4000])
+			return
+		}
+		if r.Header.Get("Range") != "bytes=4000-" {
+			t.Errorf("Range = %q, want bytes=4000-", r.Header.Get("Range"))
+		}
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	dm := testDownloadManager(t, true)
+	data, err := dm.fetch(context.Background(), 1, "file", server.URL, int64(len(content)))
+	if err != nil {
+		t.Fatalf("fetch() error = %v", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("fetch() returned %d bytes, want the %d byte file", len(data), len(content))
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+	if _, err := os.Stat(dm.partialPath("file")); !os.IsNotExist(err) {
+		t.Errorf("partial file kept after download, stat error = %v", err)
+	}
+}
+
+func TestDownloadRestartsWhenRangeIgnored(t *testing.T) {
+	content := []byte("complete file")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	dm := testDownloadManager(t, true)
+	if err := os.WriteFile(dm.partialPath("file"), []byte("stale"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := dm.fetch(context.Background(), 1, "file", server.URL, int64(len(content)))
+	if err != nil {
+		t.Fatalf("fetch() error = %v", err)
+	}
+	if string(data) != string(content) {
+		t.Errorf("fetch() = %q, want %q", data, content)
+	}
+}
+
+func TestDownloadInMemory(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte("photo"))
+	}))
+	defer server.Close()
+
+	dm := testDownloadManager(t, false)
+	data, err := dm.fetch(context.Background(), 1, "file", server.URL, 5)
+	if err != nil {
+		t.Fatalf("fetch() error = %v", err)
+	}
+	if string(data) != "photo" {
+		t.Errorf("fetch() = %q, want photo", data)
+	}
+
+	entries, _ := os.ReadDir(dm.config.TempDir)
+	if len(entries) != 0 {
+		t.Errorf("in-memory download wrote %d files", len(entries))
+	}
+}
+
+func TestDownloadPerChatLimit(t *testing.T) {
+	dm := testDownloadManager(t, false)
+	ctx := context.Background()
+
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, err := dm.acquire(ctx, 1)
+		if err != nil {
+			t.Fatalf("acquire() error = %v", err)
+		}
+		releases = append(releases, release)
+	}
+
+	// A third download of the same chat waits, another chat doesn't
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := dm.acquire(waitCtx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquire() over the chat limit error = %v, want deadline exceeded", err)
+	}
+	release, err := dm.acquire(ctx, 2)
+	if err != nil {
+		t.Fatalf("acquire() for another chat error = %v", err)
+	}
+	release()
+
+	for _, release := range releases {
+		release()
+	}
+	if len(dm.chats) != 0 {
+		t.Errorf("chats = %d after all downloads finished, want 0", len(dm.chats))
+	}
+}
+
+func TestDownloadThrottle(t *testing.T) {
+	content := bytes.Repeat([]byte("x"), 3000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	dm := newDownloadManager(DownloadConfig{PerChatDownloads: 1, BytesPerSecond: 10000})
+	start := time.Now()
+	if _, err := dm.fetchToMemory(context.Background(), server.URL, 0); err != nil {
+		t.Fatalf("fetchToMemory() error = %v", err)
+	}
+	if _, err := dm.fetchToMemory(context.Background(), server.URL, 0); err != nil {
+		t.Fatalf("fetchToMemory() error = %v", err)
+	}
+	// 6000 bytes at 10000 bytes/s with a 10000 byte burst fit into the burst
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("downloads within the burst took %v", elapsed)
+	}
+
+	start = time.Now()
+	if _, err := dm.fetchToMemory(context.Background(), server.URL, 0); err != nil {
+		t.Fatalf("fetchToMemory() error = %v", err)
+	}
+	if _, err := dm.fetchToMemory(context.Background(), server.URL, 0); err != nil {
+		t.Fatalf("fetchToMemory() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("downloads beyond the burst took %v, want throttling", elapsed)
+	}
+}
+
+func TestRedactToken(t *testing.T) {
+	err := redactToken(errors.New(`Get "https://api.telegram.org/file/bot123:ABC/photos/file_1.jpg": EOF`))
+	want := `Get "https://api.telegram.org/file/bot<token>/photos/file_1.jpg": EOF`
+	if err.Error() != want {
+		t.Errorf("redactToken() = %q, want %q", err.Error(), want)
+	}
+}
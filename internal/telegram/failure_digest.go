@@ -0,0 +1,178 @@
+package telegram
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/render"
+)
+
+// Failure digests: background operations that fail while nobody is watching (feed digests, webhook
+// deliveries) are recorded and reported once a day in a single "needs your attention" message
+
+const (
+	failureDigestCheckInterval = 1 * time.Hour
+	failureDigestInterval      = 24 * time.Hour
+	failureDigestSettleDelay   = 1 * time.Hour // Lets failures of the same outage land in one digest
+	failureDigestRetention     = 30 * 24 * time.Hour
+	failureDigestMaxPerSource  = 5
+	failureDetailLength        = 200
+)
+
+// Background failure sources
+const (
+	failureSourceFeed    = "feed"
+	failureSourceWebhook = "webhook"
+)
+
+// failureSourceTitles names the sources in the digest, in the order they are listed
+var failureSourceTitles = []struct {
+	source string
+	title  string
+}{
+	{failureSourceFeed, "📰 Feed digests"},
+	{failureSourceWebhook, "🪝 Webhook deliveries"},
+}
+
+// recordBackgroundFailure stores a background failure for the user's next failure digest
+func (b *Bot) recordBackgroundFailure(chatID int64, source string, detail string) {
+	if b.db == nil {
+		return
+	}
+	detail, _ = truncateForPreview(detail, failureDetailLength)
+	if err := b.db.RecordBackgroundFailure(chatID, source, detail); err != nil {
+		logger.Warn("Failed to record background failure", map[string]interface{}{
+			"chat_id": chatID,
+			"source":  source,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// startFailureDigest periodically sends users a digest of their unreported background failures
+func (b *Bot) startFailureDigest() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopFailureDigest = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(failureDigestCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runFailureDigests()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runFailureDigests() {
+	now := time.Now()
+	chatIDs, err := b.db.GetChatsDueForFailureDigest(now.Add(-failureDigestSettleDelay), now.Add(-failureDigestInterval))
+	if err != nil {
+		logger.Error("Failed to load failure digest chats", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, chatID := range chatIDs {
+		if !b.chatAllowed(chatID) {
+			continue
+		}
+		failures, err := b.db.GetPendingBackgroundFailures(chatID)
+		if err != nil {
+			logger.Warn("Failed to load background failures", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		if len(failures) == 0 {
+			continue
+		}
+
+		if err := b.sendNonEssential(chatID, deferredFailureDigest, formatFailureDigest(failures).String()); err != nil {
+			logger.Warn("Failed to send failure digest", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+
+		if err := b.db.MarkBackgroundFailuresNotified(chatID, failures[len(failures)-1].ID); err != nil {
+			logger.Warn("Failed to mark background failures notified", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	if removed, err := b.db.DeleteNotifiedBackgroundFailures(now.Add(-failureDigestRetention)); err != nil {
+		logger.Warn("Failed to prune background failures", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else if removed > 0 {
+		logger.Info("Pruned reported background failures", map[string]interface{}{
+			"count": removed,
+		})
+	}
+}
+
+// formatFailureDigest renders failures grouped by source, listing the latest few of each
+func formatFailureDigest(failures []*database.BackgroundFailure) *render.Message {
+	bySource := make(map[string][]*database.BackgroundFailure)
+	for _, failure := range failures {
+		bySource[failure.Source] = append(bySource[failure.Source], failure)
+	}
+
+	msg := render.New(render.HTML).Text("⚠️ ").Bold("Things that need your attention").Newline()
+	if len(failures) == 1 {
+		msg.Line("1 background operation failed since the last report.")
+	} else {
+		msg.Line(fmt.Sprintf("%d background operations failed since the last report.", len(failures)))
+	}
+
+	for _, entry := range failureSourceTitles {
+		group := bySource[entry.source]
+		if len(group) == 0 {
+			continue
+		}
+		delete(bySource, entry.source)
+		writeFailureGroup(msg, entry.title, group)
+	}
+	// Sources without a title are listed last, under their raw name
+	for _, failure := range failures {
+		if group, ok := bySource[failure.Source]; ok {
+			delete(bySource, failure.Source)
+			writeFailureGroup(msg, failure.Source, group)
+		}
+	}
+
+	return msg
+}
+
+// writeFailureGroup appends a source heading with its failure count and the latest failures
+func writeFailureGroup(msg *render.Message, title string, group []*database.BackgroundFailure) {
+	msg.Newline().Bold(fmt.Sprintf("%s (%d)", title, len(group))).Newline()
+
+	shown := group
+	if len(shown) > failureDigestMaxPerSource {
+		shown = shown[len(shown)-failureDigestMaxPerSource:]
+	}
+	for _, failure := range shown {
+		msg.Text("• ").Code(failure.CreatedAt.UTC().Format("Jan 2 15:04")).Text(" " + failure.Detail).Newline()
+	}
+	if hidden := len(group) - len(shown); hidden > 0 {
+		msg.Italic(fmt.Sprintf("…and %d earlier", hidden)).Newline()
+	}
+}
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestFormatFailureDigest(t *testing.T) {
+	at := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	failures := []*database.BackgroundFailure{
+		{ID: 1, Source: failureSourceWebhook, Detail: "push delivery to https://example.com failed: 500", CreatedAt: at},
+		{ID: 2, Source: failureSourceFeed, Detail: "Fetching <feed> failed", CreatedAt: at},
+		{ID: 3, Source: "custom", Detail: "Something else", CreatedAt: at},
+	}
+
+	got := formatFailureDigest(failures).String()
+
+	for _, want := range []string{
+		"3 background operations failed",
+		"<b>📰 Feed digests (1)</b>",
+		"<b>🪝 Webhook deliveries (1)</b>",
+		"<b>custom (1)</b>",
+		"Fetching &lt;feed&gt; failed",
+		"<code>Mar 4 05:06</code>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatFailureDigest() missing %q in:\n%s", want, got)
+		}
+	}
+
+	// Known sources keep their order, unknown ones come last
+	feedAt, webhookAt, customAt := strings.Index(got, "Feed digests"), strings.Index(got, "Webhook deliveries"), strings.Index(got, "custom")
+	if !(feedAt < webhookAt && webhookAt < customAt) {
+		t.Errorf("formatFailureDigest() groups out of order:\n%s", got)
+	}
+}
+
+func TestFormatFailureDigestCapsGroups(t *testing.T) {
+	var failures []*database.BackgroundFailure
+	for i := 1; i <= failureDigestMaxPerSource+3; i++ {
+		failures = append(failures, &database.BackgroundFailure{
+			ID:     int64(i),
+			Source: failureSourceFeed,
+			Detail: fmt.Sprintf("failure %d", i),
+		})
+	}
+
+	got := formatFailureDigest(failures).String()
+
+	if strings.Contains(got, "failure 3\n") || !strings.Contains(got, "failure 4\n") {
+		t.Errorf("formatFailureDigest() should list the latest failures:\n%s", got)
+	}
+	if !strings.Contains(got, "…and 3 earlier") {
+		t.Errorf("formatFailureDigest() should count hidden failures:\n%s", got)
+	}
+	if !strings.Contains(got, fmt.Sprintf("(%d)", len(failures))) {
+		t.Errorf("formatFailureDigest() should count all failures:\n%s", got)
+	}
+}
@@ -0,0 +1,70 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/testutil"
+)
+
+// newFakeBot builds a Bot through NewBot against a fake Telegram API, without database or payments
+func newFakeBot(t *testing.T) (*Bot, *testutil.FakeTelegram) {
+	t.Helper()
+
+	fake := testutil.NewFakeTelegram(t)
+	bot, err := NewBot(&config.Config{
+		TelegramBotToken:    testutil.FakeTelegramToken,
+		TelegramAPIEndpoint: fake.Endpoint(),
+		PaymentsDisabled:    true,
+	})
+	if err != nil {
+		t.Fatalf("NewBot() error = %v", err)
+	}
+
+	return bot, fake
+}
+
+func commandMessage(chatID int64, text string) *tgbotapi.Message {
+	return &tgbotapi.Message{
+		MessageID: 1,
+		Chat:      &tgbotapi.Chat{ID: chatID},
+		From:      &tgbotapi.User{ID: chatID},
+		Text:      text,
+	}
+}
+
+func TestNewBot_FakeTelegram(t *testing.T) {
+	bot, fake := newFakeBot(t)
+
+	if bot.api.Self.UserName != "msg2git_test_bot" {
+		t.Errorf("Expected bot to authorize against the fake API, got username %q", bot.api.Self.UserName)
+	}
+	if calls := fake.Calls(); len(calls) != 1 || calls[0].Method != "getMe" {
+		t.Errorf("Expected a single getMe call, got %+v", calls)
+	}
+}
+
+func TestHandleCommand_FakeTelegram(t *testing.T) {
+	bot, fake := newFakeBot(t)
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"/help", "Gitted Messages Help"},
+		{"/coffee", "Payments are disabled"},
+	}
+
+	for _, tt := range tests {
+		if err := bot.handleCommand(commandMessage(42, tt.command)); err != nil {
+			t.Fatalf("handleCommand(%q) error = %v", tt.command, err)
+		}
+
+		msg, ok := fake.LastMessage()
+		if !ok || msg.ChatID != 42 || !strings.Contains(msg.Text, tt.want) {
+			t.Errorf("handleCommand(%q) sent %+v, want text containing %q", tt.command, msg, tt.want)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+const (
+	featureFlagsCacheKey    = "feature_flags"
+	featureFlagsCacheExpiry = 1 * time.Minute
+)
+
+// getFeatureFlags returns all feature flags keyed by name, cached briefly to avoid a DB hit per message
+func (b *Bot) getFeatureFlags() map[string]*database.FeatureFlag {
+	if b.db == nil {
+		return nil
+	}
+
+	if cached, ok := b.cache.Get(featureFlagsCacheKey); ok {
+		if flagMap, ok := cached.(map[string]*database.FeatureFlag); ok {
+			return flagMap
+		}
+		// Anything else stored under the key is replaced by the flags loaded below
+	}
+
+	flags, err := b.db.GetFeatureFlags()
+	if err != nil {
+		logger.Warn("Failed to load feature flags", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return nil
+	}
+
+	flagMap := make(map[string]*database.FeatureFlag, len(flags))
+	for _, flag := range flags {
+		flagMap[flag.Name] = flag
+	}
+
+	b.cache.SetWithExpiry(featureFlagsCacheKey, flagMap, featureFlagsCacheExpiry)
+	return flagMap
+}
+
+// isFeatureEnabled evaluates a feature flag for a user. Unknown flags are disabled.
+func (b *Bot) isFeatureEnabled(name string, chatID int64) bool {
+	if _, ok := b.getFeatureFlags()[name]; !ok {
+		return false
+	}
+	return b.isFeatureEnabledForTier(name, chatID, b.getPremiumLevel(chatID))
+}
+
+// isFeatureEnabledForTier evaluates a feature flag when the caller already knows the user's premium level
+func (b *Bot) isFeatureEnabledForTier(name string, chatID int64, premiumLevel int) bool {
+	flag, ok := b.getFeatureFlags()[name]
+	if !ok {
+		return false
+	}
+	return flag.IsEnabledFor(chatID, premiumLevel)
+}
+
+// invalidateFeatureFlags drops cached flags so admin changes apply immediately
+func (b *Bot) invalidateFeatureFlags() {
+	b.cache.Delete(featureFlagsCacheKey)
+}
+
+// handleAdminFlagsCommand lists feature flags
+func (b *Bot) handleAdminFlagsCommand(message *tgbotapi.Message) error {
+	if b.db == nil {
+		b.sendResponse(message.Chat.ID, "❌ Feature flags require a database.")
+		return nil
+	}
+
+	flags, err := b.db.GetFeatureFlags()
+	if err != nil {
+		b.sendResponse(message.Chat.ID, fmt.Sprintf("❌ Failed to load feature flags: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	if len(flags) == 0 {
+		b.sendResponse(message.Chat.ID, "🚩 No feature flags defined.\n\nCreate one with <code>/admin flag &lt;name&gt; on</code>")
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🚩 <b>Feature Flags</b>\n")
+	for _, flag := range flags {
+		status := "🔴 off"
+		if flag.Enabled {
+			status = "🟢 on"
+		}
+		sb.WriteString(fmt.Sprintf("\n<b>%s</b> %s\n", html.EscapeString(flag.Name), status))
+		sb.WriteString(fmt.Sprintf("  rollout: %d%% | min tier: %d | allowlist: %d users\n", flag.Percentage, flag.MinTier, len(flag.GetUserIDs())))
+	}
+
+	b.sendResponse(message.Chat.ID, sb.String())
+	return nil
+}
+
+// handleAdminFlagCommand updates a single feature flag:
+// /admin flag <name> on|off|delete
+// /admin flag <name> percent <0-100>
+// /admin flag <name> tier <0-3>
+// /admin flag <name> allow|deny <chat_id>
+func (b *Bot) handleAdminFlagCommand(message *tgbotapi.Message, args []string) error {
+	chatID := message.Chat.ID
+	usage := "Usage: <code>/admin flag &lt;name&gt; on|off|delete|percent &lt;0-100&gt;|tier &lt;0-3&gt;|allow &lt;chat_id&gt;|deny &lt;chat_id&gt;</code>"
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Feature flags require a database.")
+		return nil
+	}
+	if len(args) < 2 {
+		b.sendResponse(chatID, usage)
+		return nil
+	}
+
+	name, action := args[0], args[1]
+
+	if action == "delete" {
+		if err := b.db.DeleteFeatureFlag(name); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		b.invalidateFeatureFlags()
+		b.sendResponse(chatID, fmt.Sprintf("🗑 Feature flag <b>%s</b> deleted.", html.EscapeString(name)))
+		return nil
+	}
+
+	flag, err := b.db.GetFeatureFlag(name)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if flag == nil {
+		flag = &database.FeatureFlag{Name: name, Percentage: 100}
+	}
+
+	switch action {
+	case "on":
+		flag.Enabled = true
+	case "off":
+		flag.Enabled = false
+	case "percent", "tier", "allow", "deny":
+		if len(args) < 3 {
+			b.sendResponse(chatID, usage)
+			return nil
+		}
+		value, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid number: %s", html.EscapeString(args[2])))
+			return nil
+		}
+		switch action {
+		case "percent":
+			if value < 0 || value > 100 {
+				b.sendResponse(chatID, usage)
+				return nil
+			}
+			flag.Percentage = int(value)
+		case "tier":
+			if value < 0 || value > 3 {
+				b.sendResponse(chatID, usage)
+				return nil
+			}
+			flag.MinTier = int(value)
+		case "allow":
+			flag.SetUserIDs(appendUniqueID(flag.GetUserIDs(), value))
+		case "deny":
+			flag.SetUserIDs(removeID(flag.GetUserIDs(), value))
+		}
+	default:
+		b.sendResponse(chatID, usage)
+		return nil
+	}
+
+	if err := b.db.UpsertFeatureFlag(flag); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	b.invalidateFeatureFlags()
+
+	logger.Info("Feature flag changed by admin", map[string]interface{}{
+		"admin_chat_id": chatID,
+		"flag":          name,
+		"action":        action,
+	})
+
+	b.sendResponse(chatID, fmt.Sprintf("✅ <b>%s</b>: enabled=%v, rollout=%d%%, min tier=%d, allowlist=%d users",
+		html.EscapeString(flag.Name), flag.Enabled, flag.Percentage, flag.MinTier, len(flag.GetUserIDs())))
+	return nil
+}
+
+func appendUniqueID(ids []int64, id int64) []int64 {
+	for _, existing := range ids {
+		if existing == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+func removeID(ids []int64, id int64) []int64 {
+	result := make([]int64, 0, len(ids))
+	for _, existing := range ids {
+		if existing != id {
+			result = append(result, existing)
+		}
+	}
+	return result
+}
@@ -0,0 +1,395 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/feed"
+	"github.com/msg2git/msg2git/internal/llm"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/netguard"
+)
+
+// Feed digests: subscribed RSS/Atom feeds are fetched daily and committed as a digest entry
+
+const (
+	feedCheckInterval     = 1 * time.Hour
+	feedDigestInterval    = 24 * time.Hour
+	feedDigestFile        = "digest.md"
+	maxFeedsPerUser       = 10
+	maxDigestItemsPerFeed = 10
+)
+
+// feedHTTPClient only fetches feeds from public addresses
+var feedHTTPClient = netguard.NewClient(30 * time.Second)
+
+// startFeedScheduler periodically builds digests for feeds that are due
+func (b *Bot) startFeedScheduler() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopFeedScheduler = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(feedCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runDueFeeds()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runDueFeeds() {
+	due, err := b.db.GetDueFeeds(time.Now().Add(-feedDigestInterval))
+	if err != nil {
+		logger.Error("Failed to load due feeds", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	byChat := make(map[int64][]*database.Feed)
+	var order []int64
+	for _, f := range due {
+		if !b.chatAllowed(f.ChatID) {
+			continue
+		}
+		if _, ok := byChat[f.ChatID]; !ok {
+			order = append(order, f.ChatID)
+		}
+		byChat[f.ChatID] = append(byChat[f.ChatID], f)
+	}
+
+	for _, chatID := range order {
+		count, err := b.commitFeedDigest(chatID, byChat[chatID])
+		if err != nil {
+			logger.Warn("Failed to commit feed digest", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			b.recordBackgroundFailure(chatID, failureSourceFeed, "Digest commit failed: "+err.Error())
+			continue
+		}
+		if count > 0 {
+			b.sendNonEssential(chatID, deferredFeedDigest, fmt.Sprintf("📰 Feed digest with %d new items committed to <code>%s</code>.", count, feedDigestFile))
+		}
+	}
+}
+
+// feedFetch is the state a fetched feed is updated to once its items are committed
+type feedFetch struct {
+	feed       *database.Feed
+	title      string
+	lastItemAt time.Time
+}
+
+// commitFeedDigest fetches feeds, commits one digest entry with their new items and returns the item
+// count. Feeds only move past their items once the digest is committed, so a failed commit retries
+// them next time.
+func (b *Bot) commitFeedDigest(chatID int64, feeds []*database.Feed) (int, error) {
+	now := time.Now()
+	var sections []string
+	var fetches []feedFetch
+	total := 0
+
+	for _, f := range feeds {
+		parsed, err := feed.Fetch(feedHTTPClient, f.URL)
+		if err != nil {
+			logger.Warn("Failed to fetch feed", map[string]interface{}{
+				"chat_id": chatID,
+				"feed_id": f.ID,
+				"error":   err.Error(),
+			})
+			b.recordBackgroundFailure(chatID, failureSourceFeed, fmt.Sprintf("Fetching %s failed: %s", f.URL, err.Error()))
+			continue
+		}
+
+		// Items are newest first: the oldest ones go first, newer ones beyond the limit wait for
+		// the next digest
+		items := parsed.NewItemsSince(f.LastItemAt)
+		if len(items) > maxDigestItemsPerFeed {
+			items = items[len(items)-maxDigestItemsPerFeed:]
+		}
+
+		lastItemAt := f.LastItemAt
+		if len(items) > 0 {
+			lastItemAt = items[0].Published
+			sections = append(sections, b.formatFeedSection(chatID, f, parsed.Title, items))
+			total += len(items)
+		}
+		fetches = append(fetches, feedFetch{feed: f, title: parsed.Title, lastItemAt: lastItemAt})
+	}
+
+	if total == 0 {
+		b.updateFeedsFetched(fetches, now)
+		return 0, nil
+	}
+
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return 0, err
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	if err := provider.EnsureRepositoryWithPremium(premiumLevel); err != nil {
+		return 0, err
+	}
+
+	title := fmt.Sprintf("Feed digest %s", now.Format("2006-01-02"))
+	content := b.formatMessageContentWithTitleAndTags(strings.Join(sections, "\n\n"), feedDigestFile, 0, chatID, title, "#digest")
+	commitMsg := fmt.Sprintf("Add %s to %s", title, feedDigestFile)
+
+	result, err := provider.CommitFileWithResult(feedDigestFile, content, commitMsg, b.getCommitterInfo(chatID), premiumLevel)
+	if err != nil {
+		return 0, err
+	}
+	b.recordCommitStats(chatID, provider, result)
+	b.updateFeedsFetched(fetches, now)
+
+	return total, nil
+}
+
+// updateFeedsFetched records the fetches of feeds whose items are committed
+func (b *Bot) updateFeedsFetched(fetches []feedFetch, fetchedAt time.Time) {
+	for _, fetch := range fetches {
+		if err := b.db.UpdateFeedFetched(fetch.feed.ID, fetch.title, fetch.lastItemAt, fetchedAt); err != nil {
+			logger.Warn("Failed to update feed", map[string]interface{}{
+				"feed_id": fetch.feed.ID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// formatFeedSection renders the new items of one feed, prefixed with an LLM summary when enabled
+func (b *Bot) formatFeedSection(chatID int64, f *database.Feed, feedTitle string, items []feed.Item) string {
+	if feedTitle == "" {
+		feedTitle = f.Title
+	}
+	if feedTitle == "" {
+		feedTitle = f.URL
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**%s**\n", feedTitle))
+
+	itemTexts := make([]string, 0, len(items))
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("- [%s](%s)\n", item.Title, item.Link))
+		itemTexts = append(itemTexts, fmt.Sprintf("%s: %s", item.Title, item.Summary))
+	}
+
+	if f.Summarize {
+		if summary := b.summarizeForUser(chatID, itemTexts); summary != "" {
+			sb.WriteString("\n" + summary + "\n")
+		}
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// summarizeForUser summarizes items with the user's LLM client in batches that fit the model's
+// context, recording token usage. Default LLM runs are limited to the tokens the user has left.
+func (b *Bot) summarizeForUser(chatID int64, items []string) string {
+	userLLMClient, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(chatID, strings.Join(items, "\n"))
+	if userLLMClient == nil {
+		return ""
+	}
+
+	opts := llm.BatchOptions{ChunkTokens: llm.DefaultChunkTokens}
+	if isUsingDefaultLLM {
+		opts.Budget = b.remainingTokenBudget(chatID)
+	}
+
+	result, err := llm.SummarizeBatched(userLLMClient, items, opts)
+	if err != nil {
+		logger.Warn("Feed summary skipped", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return ""
+	}
+
+	if result.Usage.TotalTokens > 0 && b.db != nil {
+		if isUsingDefaultLLM {
+			err = b.db.IncrementTokenUsageAll(chatID, int64(result.Usage.PromptTokens), int64(result.Usage.CompletionTokens))
+		} else {
+			err = b.db.IncrementTokenUsageInsights(chatID, int64(result.Usage.PromptTokens), int64(result.Usage.CompletionTokens))
+		}
+		if err != nil {
+			logger.Warn("Failed to record token usage for feed summary", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	if result.Partial {
+		logger.Warn("Feed summary stopped early", map[string]interface{}{
+			"chat_id":    chatID,
+			"chunks":     result.Chunks,
+			"summarized": result.Summarized,
+			"error":      result.Err.Error(),
+		})
+		if result.Summary == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s\n\n_Summary of %d of %d parts, the rest was skipped._", result.Summary, result.Summarized, result.Chunks)
+	}
+
+	return result.Summary
+}
+
+// handleFeedsCommand manages feed subscriptions:
+// /feeds, /feeds add <url|github:owner/repo> [summarize], /feeds remove <id>, /feeds run
+func (b *Bot) handleFeedsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/feeds"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Feeds require a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if len(args) == 0 {
+		return b.showFeeds(chatID)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			b.sendResponse(chatID, "Usage: <code>/feeds add &lt;url|github:owner/repo&gt; [summarize]</code>")
+			return nil
+		}
+		summarize := len(args) > 2 && args[2] == "summarize"
+		return b.addFeed(chatID, args[1], summarize)
+	case "remove":
+		if len(args) < 2 {
+			b.sendResponse(chatID, "Usage: <code>/feeds remove &lt;id&gt;</code>")
+			return nil
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid feed id: %s", html.EscapeString(args[1])))
+			return nil
+		}
+		if err := b.db.DeleteFeed(id, chatID); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("🗑 Feed <b>#%d</b> removed.", id))
+		return nil
+	case "run":
+		feeds, err := b.db.GetFeeds(chatID)
+		if err != nil || len(feeds) == 0 {
+			b.sendResponse(chatID, "📰 No feeds to fetch. Add one with <code>/feeds add &lt;url&gt;</code>")
+			return nil
+		}
+		count, err := b.commitFeedDigest(chatID, feeds)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Failed to commit digest: %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if count == 0 {
+			b.sendResponse(chatID, "📰 No new feed items since the last digest.")
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("📰 Feed digest with %d new items committed to <code>%s</code>.", count, feedDigestFile))
+		return nil
+	default:
+		b.sendResponse(chatID, fmt.Sprintf("❌ Unknown feeds action: %s", html.EscapeString(args[0])))
+		return nil
+	}
+}
+
+func (b *Bot) showFeeds(chatID int64) error {
+	feeds, err := b.db.GetFeeds(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load feeds: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("📰 <b>Feed Digests</b>\n")
+	if len(feeds) == 0 {
+		sb.WriteString("\nNo feeds yet.\n")
+	}
+	for _, f := range feeds {
+		name := f.Title
+		if name == "" {
+			name = f.URL
+		}
+		summary := ""
+		if f.Summarize {
+			summary = " · 🧠 summarized"
+		}
+		sb.WriteString(fmt.Sprintf("\n<b>#%d</b> %s%s\n  <code>%s</code>\n", f.ID, html.EscapeString(name), summary, html.EscapeString(f.URL)))
+	}
+
+	sb.WriteString(fmt.Sprintf(`
+New items are committed once a day to <code>%s</code>.
+
+• /feeds add &lt;url&gt; [summarize] - Subscribe to an RSS or Atom feed
+• /feeds add github:owner/repo - Follow a repository's releases
+• /feeds run - Fetch now and commit a digest
+• /feeds remove &lt;id&gt; - Unsubscribe`, feedDigestFile))
+
+	b.sendResponse(chatID, sb.String())
+	return nil
+}
+
+func (b *Bot) addFeed(chatID int64, input string, summarize bool) error {
+	feedURL := feed.ResolveURL(input)
+	if !strings.HasPrefix(feedURL, "https://") && !strings.HasPrefix(feedURL, "http://") {
+		b.sendResponse(chatID, "❌ Please provide an http(s) feed URL or <code>github:owner/repo</code>.")
+		return nil
+	}
+
+	existing, err := b.db.GetFeeds(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load feeds: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if len(existing) >= maxFeedsPerUser {
+		b.sendResponse(chatID, fmt.Sprintf("❌ You can follow up to %d feeds. Remove one first.", maxFeedsPerUser))
+		return nil
+	}
+
+	// Validate the feed before saving it
+	parsed, err := feed.Fetch(feedHTTPClient, feedURL)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Could not read feed: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	created, err := b.db.CreateFeed(chatID, feedURL, parsed.Title, summarize)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to save feed: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	name := parsed.Title
+	if name == "" {
+		name = feedURL
+	}
+	b.sendResponse(chatID, fmt.Sprintf("✅ Following <b>%s</b> (#%d). New items will appear in your daily digest.", html.EscapeString(name), created.ID))
+	return nil
+}
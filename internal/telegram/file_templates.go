@@ -0,0 +1,321 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// File templates: a custom file can be given a template in the /customfile management UI, which
+// the file starts with when the first note creates it, e.g. front matter or an "## Inbox" heading.
+// Templates end with github.EntriesMarker, below which notes are prepended from then on so the
+// template stays at the top. Files that already exist are never changed.
+
+const fileTemplateMaxLength = 2000
+
+// fileTemplate is a built-in template, {{title}}, {{date}} and {{entries}} are filled in on creation
+type fileTemplate struct {
+	Key  string
+	Name string
+	Body string
+}
+
+var fileTemplates = []fileTemplate{
+	{"frontmatter", "Front matter", "---\ntitle: {{title}}\ncreated: {{date}}\ntags: []\n---\n\n{{entries}}"},
+	{"inbox", "Inbox", "# {{title}}\n\n## Inbox\n\n{{entries}}"},
+	{"journal", "Journal", "---\ntitle: {{title}}\ncreated: {{date}}\n---\n\n# {{title}}\n\n## Entries\n\n{{entries}}"},
+	{"project", "Project", "# {{title}}\n\n## Goals\n\n_What is this project about?_\n\n## Log\n\n{{entries}}"},
+}
+
+// fileTemplateName returns the name of the built-in template with this body, "Custom" for others
+func fileTemplateName(body string) string {
+	for _, template := range fileTemplates {
+		if template.Body == body {
+			return template.Name
+		}
+	}
+	return "Custom"
+}
+
+// fileTemplateTitle turns a file path like work/meeting-notes.md into "Meeting Notes"
+func fileTemplateTitle(filename string) string {
+	base := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	words := strings.FieldsFunc(base, func(r rune) bool { return r == '-' || r == '_' || unicode.IsSpace(r) })
+	for i, word := range words {
+		r, size := utf8.DecodeRuneInString(word)
+		words[i] = string(unicode.ToUpper(r)) + word[size:]
+	}
+	return strings.Join(words, " ")
+}
+
+// renderFileTemplate fills in a template for a file created at now, placing the entries marker at
+// {{entries}} or, without one, at the end
+func renderFileTemplate(body, filename string, now time.Time) string {
+	rendered := strings.NewReplacer("{{title}}", fileTemplateTitle(filename), "{{date}}", now.Format("2006-01-02")).Replace(body)
+	if !strings.Contains(rendered, "{{entries}}") {
+		return strings.TrimRight(rendered, "\n") + "\n\n" + github.EntriesMarker + "\n"
+	}
+	rendered = strings.Replace(rendered, "{{entries}}", github.EntriesMarker+"\n", 1)
+	return strings.ReplaceAll(rendered, "{{entries}}", "")
+}
+
+// applyFileTemplate returns what to commit for a note to a custom file: the note itself, or the
+// file's template with the note in it if the note creates the file
+func (b *Bot) applyFileTemplate(chatID int64, provider github.GitHubProvider, filename, entry string) string {
+	if b.db == nil {
+		return entry
+	}
+
+	template, err := b.db.GetCustomFileTemplate(chatID, filename)
+	if err != nil {
+		logger.Warn("Failed to load file template", map[string]interface{}{
+			"chat_id":  chatID,
+			"filename": filename,
+			"error":    err.Error(),
+		})
+		return entry
+	}
+	if template == nil {
+		return entry
+	}
+
+	// Templates only apply on creation, a file that can't be read is left as it is
+	existing, err := provider.ReadFile(filename)
+	if err != nil && !strings.Contains(err.Error(), "does not exist") {
+		return entry
+	}
+	if existing != "" {
+		return entry
+	}
+
+	logger.Info("Creating custom file from template", map[string]interface{}{
+		"chat_id":  chatID,
+		"filename": filename,
+	})
+	return github.PrependEntry(renderFileTemplate(template.Template, filename, time.Now()), entry, "\n")
+}
+
+// showFileTemplates lists the custom files with their templates for the /customfile management UI
+func (b *Bot) showFileTemplates(callback *tgbotapi.CallbackQuery, notice string) error {
+	chatID := callback.Message.Chat.ID
+
+	user, err := b.ensureUserFromCallback(callback)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if b.db == nil {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Custom files require database configuration.")
+		return nil
+	}
+
+	templates, err := b.db.GetCustomFileTemplates(chatID)
+	if err != nil {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Failed to load file templates.")
+		return nil
+	}
+
+	var text strings.Builder
+	if notice != "" {
+		text.WriteString(notice + "\n\n")
+	}
+	text.WriteString("📄 <b>File Templates</b>\n\nA new custom file starts with its template instead of empty, e.g. front matter or headings. Existing files are left as they are.\n\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i, filename := range user.GetCustomFiles() {
+		name := "<i>none</i>"
+		if template, ok := templates[filename]; ok {
+			name = html.EscapeString(fileTemplateName(template.Template))
+		}
+		text.WriteString(fmt.Sprintf("%d. <code>%s</code> · %s\n", i+1, html.EscapeString(filename), name))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📄 "+filename, fmt.Sprintf("tmpl_file_%d", i)),
+		))
+	}
+	text.WriteString("\n<i>Choose a file to set its template:</i>")
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔙 Back", "customfile_back"),
+	))
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text.String())
+	editMsg.ParseMode = "HTML"
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	editMsg.ReplyMarkup = &keyboard
+	if _, err := b.rateLimitedSend(chatID, editMsg); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	return nil
+}
+
+// handleFileTemplateCallback handles tmpl_file_{index}, tmpl_set_{index}_{key} and tmpl_own_{index}
+func (b *Bot) handleFileTemplateCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	action, remainder, _ := strings.Cut(strings.TrimPrefix(callback.Data, "tmpl_"), "_")
+	indexStr, key, _ := strings.Cut(remainder, "_")
+
+	index, err := strconv.Atoi(indexStr)
+	if err != nil {
+		return fmt.Errorf("invalid file index: %w", err)
+	}
+
+	user, err := b.ensureUserFromCallback(callback)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if b.db == nil {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Custom files require database configuration.")
+		return nil
+	}
+
+	customFiles := user.GetCustomFiles()
+	if index < 0 || index >= len(customFiles) {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Invalid file selection. Please try again.")
+		return nil
+	}
+	filename := customFiles[index]
+
+	switch action {
+	case "file":
+		return b.showFileTemplateChoices(callback, index, filename)
+
+	case "set":
+		if key == "none" {
+			if _, err := b.db.DeleteCustomFileTemplate(chatID, filename); err != nil {
+				b.editMessage(chatID, callback.Message.MessageID, "❌ Failed to remove the template.")
+				return nil
+			}
+			return b.showFileTemplates(callback, fmt.Sprintf("✅ <code>%s</code> starts empty.", html.EscapeString(filename)))
+		}
+		for _, template := range fileTemplates {
+			if template.Key != key {
+				continue
+			}
+			if err := b.db.SetCustomFileTemplate(chatID, filename, template.Body); err != nil {
+				b.editMessage(chatID, callback.Message.MessageID, "❌ Failed to set the template.")
+				return nil
+			}
+			logger.Info("User set file template", map[string]interface{}{
+				"chat_id":  chatID,
+				"filename": filename,
+				"template": template.Key,
+			})
+			return b.showFileTemplates(callback, fmt.Sprintf("✅ <code>%s</code> starts with the %s template.", html.EscapeString(filename), template.Name))
+		}
+		return fmt.Errorf("unknown file template: %s", key)
+
+	case "own":
+		prompt := fmt.Sprintf(`📄 <b>Custom Template</b>
+
+Reply to this message with the template <code>%s</code> should start with. Use <code>{{title}}</code> for the file's title, <code>{{date}}</code> for the day it's created and <code>{{entries}}</code> where notes go, the end if left out.
+
+<b>Example:</b>
+<pre>---
+title: {{title}}
+---
+
+## Inbox
+
+{{entries}}</pre>`, html.EscapeString(filename))
+
+		msg := tgbotapi.NewMessage(chatID, prompt)
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.ForceReply{ForceReply: true, Selective: true}
+		sent, err := b.rateLimitedSend(chatID, msg)
+		if err != nil {
+			return fmt.Errorf("failed to send template prompt: %w", err)
+		}
+		b.pendingMessages.Set(fmt.Sprintf("file_template_%d_%d", chatID, sent.MessageID), filename)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown file template action: %s", action)
+	}
+}
+
+// showFileTemplateChoices offers the built-in templates for a custom file
+func (b *Bot) showFileTemplateChoices(callback *tgbotapi.CallbackQuery, index int, filename string) error {
+	chatID := callback.Message.Chat.ID
+
+	current, err := b.db.GetCustomFileTemplate(chatID, filename)
+	if err != nil {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Failed to load the template.")
+		return nil
+	}
+
+	text := fmt.Sprintf("📄 <b>Template for</b> <code>%s</code>\n\n", html.EscapeString(filename))
+	if current == nil {
+		text += "The file starts empty."
+	} else {
+		text += fmt.Sprintf("Current template, %s:\n<pre>%s</pre>", html.EscapeString(fileTemplateName(current.Template)), html.EscapeString(current.Template))
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for i := 0; i < len(fileTemplates); i += 2 {
+		row := tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(fileTemplates[i].Name, fmt.Sprintf("tmpl_set_%d_%s", index, fileTemplates[i].Key)),
+		)
+		if i+1 < len(fileTemplates) {
+			row = append(row, tgbotapi.NewInlineKeyboardButtonData(fileTemplates[i+1].Name, fmt.Sprintf("tmpl_set_%d_%s", index, fileTemplates[i+1].Key)))
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✍️ Write My Own", fmt.Sprintf("tmpl_own_%d", index)),
+			tgbotapi.NewInlineKeyboardButtonData("🚫 No Template", fmt.Sprintf("tmpl_set_%d_none", index)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🔙 Back", "customfile_templates"),
+		),
+	)
+
+	editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text)
+	editMsg.ParseMode = "HTML"
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	editMsg.ReplyMarkup = &keyboard
+	if _, err := b.rateLimitedSend(chatID, editMsg); err != nil {
+		return fmt.Errorf("failed to edit message: %w", err)
+	}
+	return nil
+}
+
+// handleFileTemplateReply saves a template written by the user for a custom file
+func (b *Bot) handleFileTemplateReply(message *tgbotapi.Message, filename string) error {
+	chatID := message.Chat.ID
+	body := strings.TrimSpace(message.Text)
+
+	if body == "" {
+		b.sendResponse(chatID, "❌ The template is empty. Choose 🚫 No Template in /customfile to start the file empty.")
+		return nil
+	}
+	if len(body) > fileTemplateMaxLength {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Templates can be at most %d characters long.", fileTemplateMaxLength))
+		return nil
+	}
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Custom files require database configuration.")
+		return nil
+	}
+
+	if err := b.db.SetCustomFileTemplate(chatID, filename, body+"\n"); err != nil {
+		b.sendResponse(chatID, "❌ Failed to set the template.")
+		return nil
+	}
+
+	logger.Info("User set file template", map[string]interface{}{
+		"chat_id":  chatID,
+		"filename": filename,
+		"template": "custom",
+	})
+	b.sendResponse(chatID, fmt.Sprintf("✅ <code>%s</code> will start like this when it's created:\n<pre>%s</pre>",
+		html.EscapeString(filename), html.EscapeString(renderFileTemplate(body+"\n", filename, time.Now()))))
+	return nil
+}
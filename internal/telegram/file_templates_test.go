@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestFileTemplateTitle(t *testing.T) {
+	tests := map[string]string{
+		"work/meeting-notes.md": "Meeting Notes",
+		"journal.md":            "Journal",
+		"ideas/startup_ideas":   "Startup Ideas",
+	}
+	for filename, want := range tests {
+		if got := fileTemplateTitle(filename); got != want {
+			t.Errorf("fileTemplateTitle(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestRenderFileTemplate(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+
+	got := renderFileTemplate(fileTemplates[0].Body, "work/meeting-notes.md", now)
+	want := "---\ntitle: Meeting Notes\ncreated: 2026-10-15\ntags: []\n---\n\n" + github.EntriesMarker + "\n"
+	if got != want {
+		t.Errorf("renderFileTemplate(front matter) = %q, want %q", got, want)
+	}
+
+	// Without {{entries}}, notes go below the whole template
+	got = renderFileTemplate("# {{title}}\n\n## Inbox\n", "inbox-zero.md", now)
+	want = "# Inbox Zero\n\n## Inbox\n\n" + github.EntriesMarker + "\n"
+	if got != want {
+		t.Errorf("renderFileTemplate(no entries) = %q, want %q", got, want)
+	}
+
+	// Notes prepended later stay below the template
+	created := github.PrependEntry(got, "first\n", "\n")
+	if updated := github.PrependEntry(created, "second\n", "\n"); updated != "# Inbox Zero\n\n## Inbox\n\n"+github.EntriesMarker+"\nsecond\n\nfirst\n" {
+		t.Errorf("PrependEntry() on a templated file = %q", updated)
+	}
+}
+
+func TestFileTemplateName(t *testing.T) {
+	if got := fileTemplateName(fileTemplates[1].Body); got != "Inbox" {
+		t.Errorf("fileTemplateName(inbox) = %q, want Inbox", got)
+	}
+	if got := fileTemplateName("# Mine\n"); got != "Custom" {
+		t.Errorf("fileTemplateName(other) = %q, want Custom", got)
+	}
+}
@@ -0,0 +1,230 @@
+package telegram
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Forum topics: in forum groups every topic maps to one file ("Reading" -> reading.md), created on
+// first use, and text posted in a topic is committed there without the file selection keyboard.
+// tgbotapi v5.5.1 predates forums, so the topic fields are decoded from the raw updates.
+
+var topicSlugRegex = regexp.MustCompile(`[^\p{L}\p{N}\s_-]+`)
+
+// forumTopicMessage holds the forum fields of an incoming message
+type forumTopicMessage struct {
+	MessageID       int  `json:"message_id"`
+	MessageThreadID int  `json:"message_thread_id"`
+	IsTopicMessage  bool `json:"is_topic_message"`
+	Chat            struct {
+		ID int64 `json:"id"`
+	} `json:"chat"`
+	ReplyToMessage *struct {
+		MessageID         int `json:"message_id"`
+		ForumTopicCreated *struct {
+			Name string `json:"name"`
+		} `json:"forum_topic_created"`
+	} `json:"reply_to_message"`
+}
+
+// forumTopicInfo is the topic a message was posted in
+type forumTopicInfo struct {
+	ThreadID int
+	Name     string // Empty unless the message replies to the topic's creation, i.e. isn't an explicit reply
+}
+
+// topicRepliesToStart reports whether the message's reply is only Telegram linking it to its topic
+func (t forumTopicInfo) topicRepliesToStart() bool {
+	return t.Name != ""
+}
+
+// getUpdatesChan polls updates like tgbotapi's GetUpdatesChan and remembers the forum topic of
+// every topic message for handleMessage
+func (b *Bot) getUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	ch := make(chan tgbotapi.Update, b.api.Buffer)
+
+	go func() {
+		for {
+			resp, err := b.api.Request(config)
+			if err != nil {
+				logger.Warn("Failed to get updates, retrying in 3 seconds", map[string]interface{}{
+					"error": err.Error(),
+				})
+				time.Sleep(3 * time.Second)
+				continue
+			}
+
+			var updates []tgbotapi.Update
+			if err := json.Unmarshal(resp.Result, &updates); err != nil {
+				logger.Error("Failed to decode updates", map[string]interface{}{
+					"error": err.Error(),
+				})
+				time.Sleep(3 * time.Second)
+				continue
+			}
+			b.rememberForumTopics(resp.Result)
+
+			for _, update := range updates {
+				if update.UpdateID >= config.Offset {
+					config.Offset = update.UpdateID + 1
+					ch <- update
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+// rememberForumTopics stores the topic of each new topic message in the raw updates
+func (b *Bot) rememberForumTopics(raw json.RawMessage) {
+	var updates []struct {
+		Message *forumTopicMessage `json:"message"`
+	}
+	if err := json.Unmarshal(raw, &updates); err != nil {
+		return
+	}
+
+	for _, update := range updates {
+		message := update.Message
+		if message == nil || !message.IsTopicMessage || message.MessageThreadID == 0 {
+			continue
+		}
+
+		info := forumTopicInfo{ThreadID: message.MessageThreadID}
+		if reply := message.ReplyToMessage; reply != nil && reply.MessageID == message.MessageThreadID && reply.ForumTopicCreated != nil {
+			info.Name = reply.ForumTopicCreated.Name
+		}
+		b.messageTopics.Store(messageTopicKey(message.Chat.ID, message.MessageID), info)
+	}
+}
+
+// messageTopicKey identifies a message of a chat
+func messageTopicKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("%d:%d", chatID, messageID)
+}
+
+// takeForumTopic returns and forgets the topic a message was posted in
+func (b *Bot) takeForumTopic(message *tgbotapi.Message) (forumTopicInfo, bool) {
+	value, ok := b.messageTopics.LoadAndDelete(messageTopicKey(message.Chat.ID, message.MessageID))
+	if !ok {
+		return forumTopicInfo{}, false
+	}
+	return value.(forumTopicInfo), true
+}
+
+// topicFileName derives the file of a topic from its name, e.g. "Reading List" -> reading-list.md
+func topicFileName(name string, threadID int) string {
+	slug := topicSlugRegex.ReplaceAllString(strings.ToLower(name), "")
+	slug = strings.Trim(strings.Join(strings.Fields(slug), "-"), "-_")
+
+	switch slug {
+	case "":
+		return fmt.Sprintf("topic-%d.md", threadID)
+	case "todo", "issue":
+		// todo.md and issue.md have their own formats
+		return "topic-" + slug + ".md"
+	}
+	return slug + ".md"
+}
+
+// resolveTopicFile returns the file a topic's messages are committed to, mapping the topic on
+// first use. Without a database the file is derived from the topic name every time.
+func (b *Bot) resolveTopicFile(chatID int64, topic forumTopicInfo) (string, error) {
+	if b.db == nil {
+		if topic.Name == "" {
+			return "", fmt.Errorf("topic name unknown")
+		}
+		return topicFileName(topic.Name, topic.ThreadID), nil
+	}
+
+	mapping, err := b.db.GetForumTopic(chatID, topic.ThreadID)
+	if err != nil {
+		return "", err
+	}
+	if mapping != nil {
+		return mapping.FilePath, nil
+	}
+
+	mapping, err = b.db.CreateForumTopic(chatID, topic.ThreadID, topic.Name, topicFileName(topic.Name, topic.ThreadID))
+	if err != nil {
+		return "", err
+	}
+
+	logger.Info("Mapped forum topic to file", map[string]interface{}{
+		"chat_id":   chatID,
+		"thread_id": topic.ThreadID,
+		"file":      mapping.FilePath,
+	})
+	return mapping.FilePath, nil
+}
+
+// commitToTopicFile commits a text message posted in a forum topic to the topic's file
+func (b *Bot) commitToTopicFile(message *tgbotapi.Message, topic forumTopicInfo) error {
+	chatID := message.Chat.ID
+
+	filePath, err := b.resolveTopicFile(chatID, topic)
+	if err != nil {
+		logger.Warn("Failed to resolve forum topic file", map[string]interface{}{
+			"chat_id":   chatID,
+			"thread_id": topic.ThreadID,
+			"error":     err.Error(),
+		})
+		return b.showFileSelectionButtons(message)
+	}
+
+	// Ensure user exists with the sender's username before the save flow runs
+	if _, err := b.ensureUser(message); err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	// Replying keeps the status message in the topic
+	statusMsg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📝 Saving to %s...", filePath))
+	statusMsg.ReplyToMessageID = message.MessageID
+	sent, err := b.rateLimitedSend(chatID, statusMsg)
+	if err != nil {
+		return fmt.Errorf("failed to send status message: %w", err)
+	}
+
+	content := b.telegramToMarkdown(message.Text, message.Entities)
+	callback := &tgbotapi.CallbackQuery{
+		From:    message.From,
+		Message: &sent,
+	}
+	return b.saveMessageToCustomFile(callback, filePath, content, message.MessageID, "", false, false)
+}
+
+// handleTopicsCommand lists the forum topics of the group and their files
+func (b *Bot) handleTopicsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Topic files require a database.")
+		return nil
+	}
+
+	topics, err := b.db.GetForumTopics(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ Failed to load topics.")
+		return nil
+	}
+	if len(topics) == 0 {
+		b.sendResponse(chatID, "🗂 No topic files yet.\n\nIn a group with topics, messages posted in a topic are saved to a file named after it, e.g. <b>Reading</b> → <code>reading.md</code>.")
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🗂 <b>Topic files</b>\n\n")
+	for _, topic := range topics {
+		sb.WriteString(fmt.Sprintf("• %s → <code>%s</code>\n", html.EscapeString(topic.Name), html.EscapeString(topic.FilePath)))
+	}
+	b.sendResponse(chatID, sb.String())
+	return nil
+}
@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"encoding/json"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestTopicFileName(t *testing.T) {
+	tests := []struct {
+		name     string
+		topic    string
+		threadID int
+		want     string
+	}{
+		{"simple", "Reading", 5, "reading.md"},
+		{"spaces", "  Reading   List ", 5, "reading-list.md"},
+		{"punctuation", "Books & Papers!", 5, "books-papers.md"},
+		{"unicode", "Идеи", 5, "идеи.md"},
+		{"emoji only", "📚", 7, "topic-7.md"},
+		{"reserved todo", "TODO", 9, "topic-todo.md"},
+		{"reserved issue", "Issue", 9, "topic-issue.md"},
+		{"path", "../secrets", 3, "secrets.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := topicFileName(tt.topic, tt.threadID); got != tt.want {
+				t.Errorf("topicFileName(%q) = %q, want %q", tt.topic, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRememberForumTopics(t *testing.T) {
+	raw := json.RawMessage(`[
+		{"update_id": 1, "message": {"message_id": 20, "message_thread_id": 10, "is_topic_message": true, "chat": {"id": -100},
+			"reply_to_message": {"message_id": 10, "forum_topic_created": {"name": "Reading"}}}},
+		{"update_id": 2, "message": {"message_id": 21, "message_thread_id": 10, "is_topic_message": true, "chat": {"id": -100},
+			"reply_to_message": {"message_id": 15}}},
+		{"update_id": 3, "message": {"message_id": 22, "chat": {"id": -100}}}
+	]`)
+
+	b := &Bot{}
+	b.rememberForumTopics(raw)
+
+	message := func(id int) *tgbotapi.Message {
+		return &tgbotapi.Message{MessageID: id, Chat: &tgbotapi.Chat{ID: -100}}
+	}
+
+	topic, ok := b.takeForumTopic(message(20))
+	if !ok || topic.ThreadID != 10 || topic.Name != "Reading" || !topic.topicRepliesToStart() {
+		t.Errorf("topic message = %+v, %v", topic, ok)
+	}
+	if _, ok := b.takeForumTopic(message(20)); ok {
+		t.Error("takeForumTopic() should forget the topic")
+	}
+
+	topic, ok = b.takeForumTopic(message(21))
+	if !ok || topic.ThreadID != 10 || topic.topicRepliesToStart() {
+		t.Errorf("explicit reply in topic = %+v, %v", topic, ok)
+	}
+
+	if _, ok := b.takeForumTopic(message(22)); ok {
+		t.Error("message outside topics should have no topic")
+	}
+}
@@ -0,0 +1,79 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+// Gitea and Forgejo: users keeping notes on their own instance set it with /gitea. Its API URL is
+// stored like a GitHub Enterprise one, the /api/v1 path tells the API provider to speak Gitea.
+
+// handleGiteaCommand shows or changes the Gitea instance: /gitea, /gitea <url>, /gitea off
+func (b *Bot) handleGiteaCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	arg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/gitea"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Gitea settings require a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if arg == "" {
+		status := "🌐 Not using Gitea"
+		if user != nil && github.IsGiteaAPIURL(user.GitHubAPIURL) {
+			status = fmt.Sprintf("🍵 Gitea API: <code>%s</code>", html.EscapeString(user.GitHubAPIURL))
+		}
+		b.sendResponse(chatID, status+`
+
+On a Gitea or Forgejo instance, set its URL so notes, files and issues go through its API. Your repository and an access token must then be on that instance too. Search and /limits aren't available on Gitea.
+
+• /gitea https://git.example.com - Use a Gitea instance
+• /gitea off - Go back to github.com`)
+		return nil
+	}
+
+	apiURL := ""
+	if arg != "off" {
+		apiURL = github.GiteaAPIURL(arg)
+		if err := github.ValidateAPIURL(apiURL); err != nil {
+			b.sendResponse(chatID, "❌ Invalid Gitea URL, use an https URL like https://git.example.com")
+			return nil
+		}
+		if err := checkInstanceURL(apiURL); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ The Gitea URL must be on a public host: %s", html.EscapeString(err.Error())))
+			return nil
+		}
+	} else if user != nil && !github.IsGiteaAPIURL(user.GitHubAPIURL) {
+		b.sendResponse(chatID, "ℹ️ You're not using Gitea.")
+		return nil
+	}
+
+	if err := b.db.UpdateUserGitHubAPIURL(chatID, apiURL); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to update Gitea URL: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	// Invalidate cached providers since the endpoints changed
+	b.cache.Delete(fmt.Sprintf("github_provider_%d", chatID))
+	b.cache.Delete(fmt.Sprintf("github_private_provider_%d", chatID))
+
+	if apiURL == "" {
+		b.sendResponse(chatID, fmt.Sprintf("%s Back on github.com. Set a github.com repository with /repo if needed.", consts.EmojiSuccess))
+		return nil
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("%s Gitea API set to <code>%s</code>\n\nNow set a repository on <code>%s</code> and an access token from that instance with /repo.",
+		consts.EmojiSuccess, html.EscapeString(apiURL), html.EscapeString(github.WebHost(apiURL))))
+	return nil
+}
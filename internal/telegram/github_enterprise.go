@@ -0,0 +1,105 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/netguard"
+)
+
+// instanceResolveTimeout bounds the DNS lookup of a GitHub Enterprise or Gitea URL being set
+const instanceResolveTimeout = 5 * time.Second
+
+// GitHub Enterprise Server: users on their own GitHub instance set its API URL with /enterprise,
+// their providers then send issue, release and size requests there instead of api.github.com
+
+// userGitHubAPIURL returns the user's GitHub Enterprise API URL, empty for github.com or without a database
+func (b *Bot) userGitHubAPIURL(chatID int64) string {
+	if b.db == nil {
+		return ""
+	}
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.GitHubAPIURL
+}
+
+// checkInstanceURL refuses API URLs of instances on the host's networks before they are saved,
+// requests check the addresses again when connecting
+func checkInstanceURL(apiURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), instanceResolveTimeout)
+	defer cancel()
+	return netguard.CheckURL(ctx, apiURL)
+}
+
+// handleEnterpriseCommand shows or changes the GitHub Enterprise API URL:
+// /enterprise, /enterprise <api_url>, /enterprise off
+func (b *Bot) handleEnterpriseCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	arg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/enterprise"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ GitHub Enterprise settings require a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if arg == "" {
+		status := fmt.Sprintf("🌐 Using <code>%s</code>", html.EscapeString(github.APIBaseURL()))
+		if user != nil && github.IsGiteaAPIURL(user.GitHubAPIURL) {
+			status = fmt.Sprintf("🍵 Using Gitea at <code>%s</code>, see /gitea", html.EscapeString(user.GitHubAPIURL))
+		} else if user != nil && user.GitHubAPIURL != "" {
+			status = fmt.Sprintf("🏢 GitHub Enterprise API: <code>%s</code>", html.EscapeString(user.GitHubAPIURL))
+		}
+		b.sendResponse(chatID, status+`
+
+On GitHub Enterprise Server, set your instance's API URL so issues, releases and size checks use it. Your repository and token must then be on that instance too.
+
+• /enterprise https://github.example.com/api/v3 - Use a GitHub Enterprise Server
+• /enterprise off - Go back to github.com`)
+		return nil
+	}
+
+	apiURL := ""
+	if arg != "off" {
+		apiURL = strings.TrimSuffix(arg, "/")
+		if err := github.ValidateAPIURL(apiURL); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if err := checkInstanceURL(apiURL); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ The GitHub API URL must be on a public host: %s", html.EscapeString(err.Error())))
+			return nil
+		}
+	}
+
+	if err := b.db.UpdateUserGitHubAPIURL(chatID, apiURL); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to update GitHub API URL: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	// Invalidate cached providers since the endpoints changed
+	b.cache.Delete(fmt.Sprintf("github_provider_%d", chatID))
+	b.cache.Delete(fmt.Sprintf("github_private_provider_%d", chatID))
+
+	if apiURL == "" {
+		b.sendResponse(chatID, fmt.Sprintf("%s Back on github.com. Set a github.com repository with /repo if needed.", consts.EmojiSuccess))
+		return nil
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("%s GitHub Enterprise API set to <code>%s</code>\n\nNow set a repository on <code>%s</code> and a token from that instance with /repo.",
+		consts.EmojiSuccess, html.EscapeString(apiURL), html.EscapeString(github.WebHost(apiURL))))
+	return nil
+}
@@ -0,0 +1,180 @@
+package telegram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/render"
+)
+
+// GitHub identity: the GitHub account behind the user's token is stored after OAuth or token
+// validation, created issues are assigned to it, "@me" resolves to it and /whoami checks the committer
+
+// meMentionRegex matches "@me" as a mention, not inside emails or longer handles
+var meMentionRegex = regexp.MustCompile(`(^|[^\w@.])@me\b`)
+
+// resolveMeMentions replaces "@me" with the user's GitHub handle, text stays unchanged without one
+func resolveMeMentions(text, login string) string {
+	if login == "" {
+		return text
+	}
+	return meMentionRegex.ReplaceAllString(text, "${1}@"+login)
+}
+
+// committerEmail returns the email of a "Name <email>" committer, "" if there is none
+func committerEmail(committer string) string {
+	start := strings.LastIndex(committer, "<")
+	end := strings.LastIndex(committer, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(committer[start+1 : end])
+}
+
+// describeCommitterIdentity tells, as plain text, whether commits by the committer are attributed to the GitHub
+// account. Only noreply addresses can be checked, other emails need to be verified on GitHub.
+func describeCommitterIdentity(committer, login string, githubUserID int64, webHost string) string {
+	email := strings.ToLower(committerEmail(committer))
+	switch {
+	case committer == "":
+		return "⚠️ No committer set, commits use the default author"
+	case email == "":
+		return "⚠️ The committer has no email, GitHub can't link commits to an account"
+	case login == "":
+		return "⚠️ Link your GitHub account in /repo to check the committer"
+	}
+
+	noreplySuffix := "@users.noreply." + strings.ToLower(webHost)
+	if !strings.HasSuffix(email, noreplySuffix) {
+		return fmt.Sprintf("ℹ️ Commits are linked to @%s only if %s is a verified email of the account", login, email)
+	}
+
+	ownAddresses := []string{
+		strings.ToLower(githubNoreplyEmail(&GitHubUser{ID: int(githubUserID), Login: login}, webHost)),
+		strings.ToLower(login) + noreplySuffix, // Accounts created before July 2017 also use the short form
+	}
+	for _, address := range ownAddresses {
+		if email == address {
+			return fmt.Sprintf("✅ Commits are linked to @%s", login)
+		}
+	}
+	return fmt.Sprintf("❌ %s is the noreply email of another GitHub account", email)
+}
+
+// linkGitHubAccount stores the GitHub account the user's token belongs to
+func (b *Bot) linkGitHubAccount(chatID int64, githubUser *GitHubUser) {
+	if b.db == nil || githubUser == nil || githubUser.Login == "" {
+		return
+	}
+	if err := b.db.UpdateUserGitHubAccount(chatID, githubUser.Login, int64(githubUser.ID)); err != nil {
+		logger.Warn("Failed to store GitHub account", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// githubLogin returns the user's linked GitHub login, "" if unknown
+func (b *Bot) githubLogin(chatID int64) string {
+	if b.db == nil {
+		return ""
+	}
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		return ""
+	}
+	return user.GitHubLogin
+}
+
+// resolveMentions replaces "@me" with the user's GitHub handle
+func (b *Bot) resolveMentions(chatID int64, text string) string {
+	if !strings.Contains(text, "@me") {
+		return text
+	}
+	return resolveMeMentions(text, b.githubLogin(chatID))
+}
+
+// assignIssueToSelf assigns an issue the user just created to their GitHub account
+func (b *Bot) assignIssueToSelf(chatID int64, provider github.GitHubProvider, issueNumber int) {
+	login := b.githubLogin(chatID)
+	if login == "" {
+		return
+	}
+	if err := provider.AssignIssue(issueNumber, []string{login}); err != nil {
+		logger.Warn("Failed to assign issue to its creator", map[string]interface{}{
+			"chat_id":      chatID,
+			"issue_number": issueNumber,
+			"error":        err.Error(),
+		})
+	}
+}
+
+// handleWhoamiCommand shows the Telegram chat, the linked GitHub account and whether commits are attributed to it
+func (b *Bot) handleWhoamiCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ /whoami requires a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil || user == nil {
+		b.sendResponse(chatID, "❌ Failed to get user")
+		return nil
+	}
+
+	// Accounts linked before logins were stored get their login on first use
+	if user.GitHubLogin == "" && user.GitHubToken != "" {
+		if githubUser, err := b.validateGitHubToken(user.GitHubToken, user.GitHubAPIURL); err == nil {
+			b.linkGitHubAccount(chatID, githubUser)
+			user.GitHubLogin, user.GitHubUserID = githubUser.Login, int64(githubUser.ID)
+		} else {
+			logger.Warn("Failed to look up GitHub account for /whoami", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return b.sendRendered(chatID, formatWhoami(message, user))
+}
+
+// formatWhoami renders the /whoami message
+func formatWhoami(message *tgbotapi.Message, user *database.User) *render.Message {
+	msg := render.New(render.HTML).Text("🪪 ").Bold("Who am I").Newline().Newline()
+
+	msg.Bold("Telegram:").Text(" ")
+	if message.From != nil && message.From.UserName != "" {
+		msg.Text("@" + message.From.UserName + " (chat ").Code(fmt.Sprintf("%d", message.Chat.ID)).Line(")")
+	} else {
+		msg.Text("chat ").Code(fmt.Sprintf("%d", message.Chat.ID)).Newline()
+	}
+
+	webHost := github.WebHost(github.ResolveAPIBaseURL(user.GitHubAPIURL))
+	msg.Bold("GitHub:").Text(" ")
+	if user.GitHubLogin != "" {
+		msg.Link("@"+user.GitHubLogin, fmt.Sprintf("https://%s/%s", webHost, user.GitHubLogin)).Newline()
+	} else {
+		msg.Line("not linked, set your GitHub auth in /repo")
+	}
+
+	committer := user.Committer
+	if committer == "" {
+		committer = "not set"
+	}
+	msg.Bold("Committer:").Text(" ").Code(committer).Newline()
+	msg.Text(describeCommitterIdentity(user.Committer, user.GitHubLogin, user.GitHubUserID, webHost))
+
+	if user.GitHubLogin != "" {
+		msg.Newline().Newline().Textf("Issues you create are assigned to @%s, and ", user.GitHubLogin).
+			Code("@me").Textf(" in issues and canned replies becomes @%s.", user.GitHubLogin)
+	}
+
+	return msg
+}
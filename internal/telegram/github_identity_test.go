@@ -0,0 +1,62 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResolveMeMentions(t *testing.T) {
+	tests := []struct {
+		text, login, want string
+	}{
+		{"cc @me", "octocat", "cc @octocat"},
+		{"@me please look", "octocat", "@octocat please look"},
+		{"(@me)", "octocat", "(@octocat)"},
+		{"write to me@me.com", "octocat", "write to me@me.com"},
+		{"ping @meow and @@me", "octocat", "ping @meow and @@me"},
+		{"cc @me", "", "cc @me"},
+	}
+
+	for _, tt := range tests {
+		if got := resolveMeMentions(tt.text, tt.login); got != tt.want {
+			t.Errorf("resolveMeMentions(%q, %q) = %q, want %q", tt.text, tt.login, got, tt.want)
+		}
+	}
+}
+
+func TestCommitterEmail(t *testing.T) {
+	tests := map[string]string{
+		"Mona Lisa <mona@example.com>":   "mona@example.com",
+		"Mona <Lisa> <mona@example.com>": "mona@example.com",
+		"Mona Lisa":                      "",
+		"":                               "",
+	}
+
+	for committer, want := range tests {
+		if got := committerEmail(committer); got != want {
+			t.Errorf("committerEmail(%q) = %q, want %q", committer, got, want)
+		}
+	}
+}
+
+func TestDescribeCommitterIdentity(t *testing.T) {
+	tests := []struct {
+		committer string
+		login     string
+		want      string
+	}{
+		{"Octo <583231+octocat@users.noreply.github.com>", "octocat", "✅"},
+		{"Octo <Octocat@users.noreply.github.com>", "octocat", "✅"},
+		{"Octo <1+someone@users.noreply.github.com>", "octocat", "❌"},
+		{"Octo <octo@example.com>", "octocat", "verified email"},
+		{"Octo <octo@example.com>", "", "Link your GitHub account"},
+		{"Octo", "octocat", "has no email"},
+		{"", "octocat", "No committer"},
+	}
+
+	for _, tt := range tests {
+		if got := describeCommitterIdentity(tt.committer, tt.login, 583231, "github.com"); !strings.Contains(got, tt.want) {
+			t.Errorf("describeCommitterIdentity(%q, %q) = %q, want it to contain %q", tt.committer, tt.login, got, tt.want)
+		}
+	}
+}
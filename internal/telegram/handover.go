@@ -0,0 +1,215 @@
+package telegram
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/cache"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Handover: users switching Telegram accounts (a new phone number, a new account) move their
+// configuration and note history to the new chat. /handover new in the old chat shows a one-time
+// code, /handover claim CODE in the new chat moves every row of the old chat in one transaction
+// (see database.ClaimHandover) and drops what this instance cached for either chat.
+
+// handoverCodeTTL is how long a handover code can be claimed
+const handoverCodeTTL = 15 * time.Minute
+
+// handoverAlphabet leaves out characters that are easily confused when typed from another screen
+const handoverAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const handoverUsage = "Usage: <code>/handover new</code>, <code>/handover cancel</code> or <code>/handover claim CODE</code>"
+
+// generateHandoverCode returns a new random code like "K7QD-M2XH"
+func generateHandoverCode() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate handover code: %w", err)
+	}
+	code := make([]byte, 0, 9)
+	for i, value := range buf {
+		if i == 4 {
+			code = append(code, '-')
+		}
+		code = append(code, handoverAlphabet[int(value)%len(handoverAlphabet)])
+	}
+	return string(code), nil
+}
+
+// hashHandoverCode returns the hex SHA-256 of a code as typed by the user, which is what the
+// database stores. Case, spaces and dashes don't matter.
+func hashHandoverCode(code string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte("msg2git-handover:" + normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// handleHandoverCommand creates, cancels or claims a handover:
+// /handover, /handover new, /handover cancel, /handover claim CODE
+func (b *Bot) handleHandoverCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Handovers require a database.")
+		return nil
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	switch {
+	case len(args) == 0:
+		pending, err := b.db.GetHandoverCode(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load your handover.")
+			return nil
+		}
+		status := "🔁 No handover pending."
+		if pending != nil {
+			status = fmt.Sprintf("🔁 A handover code is pending until %s UTC.", pending.ExpiresAt.UTC().Format("15:04"))
+		}
+		b.sendResponse(chatID, status+`
+
+Moving to another Telegram account? Your repository settings, premium status, history and other settings can follow you.
+
+• /handover new - Create a one-time code in this chat
+• /handover claim CODE - Claim it from your new account
+• /handover cancel - Cancel a pending code`)
+		return nil
+
+	case args[0] == "new" && len(args) == 1:
+		return b.createHandover(message)
+
+	case args[0] == "cancel" && len(args) == 1:
+		cancelled, err := b.db.CancelHandoverCode(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to cancel the handover.")
+			return nil
+		}
+		if !cancelled {
+			b.sendResponse(chatID, "🔁 No handover pending.")
+			return nil
+		}
+		b.sendResponse(chatID, "🗑 Handover code cancelled.")
+		return nil
+
+	case args[0] == "claim" && len(args) >= 2:
+		return b.claimHandover(message, strings.Join(args[1:], ""))
+
+	default:
+		b.sendResponse(chatID, handoverUsage)
+		return nil
+	}
+}
+
+// createHandover shows a new handover code of the chat, replacing a pending one
+func (b *Bot) createHandover(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ Failed to get user.")
+		return nil
+	}
+	if user == nil {
+		b.sendResponse(chatID, "❌ There is nothing to hand over yet, set up your repository with /repo first.")
+		return nil
+	}
+	if premiumUser, err := b.db.GetPremiumUser(chatID); err == nil && premiumUser != nil && premiumUser.IsPremiumUser() && premiumUser.IsSubscription {
+		b.sendResponse(chatID, "❌ Your subscription is billed to this account, so it can't move to another one. Cancel it with /coffee first, then hand over.")
+		return nil
+	}
+
+	code, err := generateHandoverCode()
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if err := b.db.CreateHandoverCode(chatID, hashHandoverCode(code), time.Now().Add(handoverCodeTTL)); err != nil {
+		b.sendResponse(chatID, "❌ Failed to save the handover code.")
+		return nil
+	}
+
+	logger.Info("Handover code created", map[string]interface{}{
+		"chat_id": chatID,
+	})
+	b.sendResponse(chatID, fmt.Sprintf(`🔁 Handover code (valid for %d minutes, usable once):
+
+<code>%s</code>
+
+From your new account, send this to the bot:
+<code>/handover claim %s</code>
+
+Everything moves to the new account and this chat is no longer set up. Settings the new account already made are replaced.`,
+		int(handoverCodeTTL.Minutes()), code, code))
+	return nil
+}
+
+// claimHandover moves the configuration and history of the chat that created code to this chat
+func (b *Bot) claimHandover(message *tgbotapi.Message, code string) error {
+	chatID := message.Chat.ID
+
+	// A configured repository or premium level of this chat would be replaced, refuse instead
+	if user, err := b.db.GetUserByChatID(chatID); err != nil {
+		b.sendResponse(chatID, "❌ Failed to get user.")
+		return nil
+	} else if user != nil && user.GitHubRepo != "" {
+		b.sendResponse(chatID, "❌ This account already has a repository configured. Claim the handover from a fresh account.")
+		return nil
+	}
+	if premiumUser, err := b.db.GetPremiumUser(chatID); err == nil && premiumUser != nil && premiumUser.IsPremiumUser() {
+		b.sendResponse(chatID, "❌ This account has its own premium level, which the handover would replace. Claim it from a fresh account.")
+		return nil
+	}
+
+	fromChatID, err := b.db.ClaimHandover(hashHandoverCode(code), chatID)
+	if err != nil {
+		logger.Warn("Handover claim failed", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, fmt.Sprintf("❌ Handover failed: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if fromChatID == 0 {
+		b.sendResponse(chatID, "❌ Unknown or expired handover code. Create a new one with <code>/handover new</code> in your old account.")
+		return nil
+	}
+
+	b.forgetChatState(fromChatID, chatID)
+	b.forgetChatState(chatID, 0)
+
+	logger.Info("Handover claimed", map[string]interface{}{
+		"from_chat_id": fromChatID,
+		"to_chat_id":   chatID,
+	})
+	b.sendResponse(chatID, "✅ Handover complete: your repository, settings and history are now on this account. Send a message to check that everything works.")
+	b.sendResponse(fromChatID, "🔁 This chat was handed over to another account. Its repository settings and history moved there; set up the repository again with /repo to use this chat.")
+	return nil
+}
+
+// forgetChatState drops what was cached for chatID: providers and other per-chat cache entries,
+// including those shared with other instances, pending prompts, repository health and the hot
+// mark of warm clones, which moves to movedTo unless it's 0
+func (b *Bot) forgetChatState(chatID, movedTo int64) {
+	id := strconv.FormatInt(chatID, 10)
+	for _, store := range []*cache.Cache{b.cache, b.pendingMessages} {
+		for _, key := range store.Keys() {
+			for _, part := range strings.Split(key, "_") {
+				if part == id {
+					store.Delete(key)
+					break
+				}
+			}
+		}
+	}
+	b.repoHealthStates.Delete(chatID)
+	if lastUsed, ok := b.hotRepos.LoadAndDelete(chatID); ok && movedTo != 0 {
+		b.hotRepos.Store(movedTo, lastUsed)
+	}
+}
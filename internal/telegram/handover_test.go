@@ -0,0 +1,38 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateHandoverCode(t *testing.T) {
+	first, err := generateHandoverCode()
+	if err != nil {
+		t.Fatalf("generateHandoverCode() error = %v", err)
+	}
+	second, _ := generateHandoverCode()
+
+	if len(first) != 9 || first[4] != '-' {
+		t.Errorf("code %q is not formatted like XXXX-XXXX", first)
+	}
+	for _, c := range strings.ReplaceAll(first, "-", "") {
+		if !strings.ContainsRune(handoverAlphabet, c) {
+			t.Errorf("code %q contains %q, which is not in the alphabet", first, c)
+		}
+	}
+	if first == second {
+		t.Error("generateHandoverCode() returned the same code twice")
+	}
+}
+
+func TestHashHandoverCode(t *testing.T) {
+	hash := hashHandoverCode("K7QD-M2XH")
+	for _, typed := range []string{"k7qd-m2xh", "K7QDM2XH", "k7qd m2xh"} {
+		if hashHandoverCode(typed) != hash {
+			t.Errorf("hashHandoverCode(%q) differs from the code as shown", typed)
+		}
+	}
+	if hash == hashHandoverCode("K7QD-M2XJ") {
+		t.Error("different codes produced the same hash")
+	}
+}
@@ -0,0 +1,311 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// History compression: users who consent with /compress on get their bot commits older than a
+// threshold squashed into daily rollups once a week (see github.CompressHistory). The compressed
+// history is only proposed, on a maintenance branch with a pull request explaining the rewrite;
+// the commit branch is force-pushed to it after a second confirmation of /compress apply.
+
+const (
+	historyCompressionCheckInterval = 6 * time.Hour
+	historyCompressionInterval      = 7 * 24 * time.Hour
+	defaultCompressOlderThanDays    = 30
+	minCompressOlderThanDays        = 7
+	maxCompressOlderThanDays        = 3650
+)
+
+// historyCompressionConsentText explains what compression rewrites before the user opts in
+const historyCompressionConsentText = `🗜 <b>Compress your commit history?</b>
+
+Once a week, bot commits older than %d days are squashed into one commit per day. This rewrites the history of your repository:
+
+• Nothing changes until you confirm: the compressed history is proposed on the <code>msg2git/compress-…</code> branch with a pull request, and only <code>/compress apply</code> replaces your branch with a force push
+• Your files stay exactly the same, only commits change
+• Commits after the first compressed day get new hashes, so links to them stop working
+• Other clones have to be reset to the new history, pulling would merge both
+• Commits you made yourself are kept, and repositories with merge commits can't be compressed
+
+Compression needs clone-based storage. You can turn it off at any time with <code>/compress off</code>.`
+
+// handleCompressCommand shows or changes history compression: /compress, /compress on [days],
+// /compress off, /compress now, /compress apply
+func (b *Bot) handleCompressCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.Fields(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(message.Text), "/compress")))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ History compression requires a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	compression, err := b.db.GetHistoryCompression(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ Failed to load history compression.")
+		return nil
+	}
+
+	action := ""
+	if len(args) > 0 {
+		action = args[0]
+	}
+
+	switch action {
+	case "":
+		if compression == nil {
+			b.sendResponse(chatID, fmt.Sprintf("🗜 History compression is off.\n\nUse <code>/compress on</code> to squash bot commits older than %d days into daily rollups, proposed in a pull request before anything is rewritten.", defaultCompressOlderThanDays))
+			return nil
+		}
+		status := fmt.Sprintf("🗜 History compression is on for bot commits older than %d days.", compression.OlderThanDays)
+		if compression.PullNumber > 0 {
+			status += fmt.Sprintf("\n\nThe last proposal is pull request #%d. Review it, then use <code>/compress apply</code> to replace your history with it.", compression.PullNumber)
+		}
+		b.sendResponse(chatID, status+"\n\nUse <code>/compress now</code> to propose it now or <code>/compress off</code> to stop.")
+		return nil
+
+	case "on":
+		days := defaultCompressOlderThanDays
+		if len(args) > 1 {
+			days, err = strconv.Atoi(args[1])
+			if err != nil || days < minCompressOlderThanDays || days > maxCompressOlderThanDays {
+				b.sendResponse(chatID, fmt.Sprintf("❌ The age must be a number of days from %d to %d.", minCompressOlderThanDays, maxCompressOlderThanDays))
+				return nil
+			}
+		}
+		if _, ok := b.historyCompressor(chatID); !ok {
+			return nil
+		}
+
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(historyCompressionConsentText, days))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ I understand, enable", fmt.Sprintf("compress_consent_%d", days)),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "compress_cancel"),
+		))
+		if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+			return fmt.Errorf("failed to send history compression consent: %w", err)
+		}
+		return nil
+
+	case "off":
+		if _, err := b.db.DisableHistoryCompression(chatID); err != nil {
+			b.sendResponse(chatID, "❌ Failed to disable history compression.")
+			return nil
+		}
+		b.sendResponse(chatID, "🗜 History compression disabled. Your history stays as it is; close a pull request that is still open to discard its proposal.")
+		return nil
+
+	case "now":
+		if compression == nil {
+			b.sendResponse(chatID, "🗜 Enable history compression with <code>/compress on</code> first.")
+			return nil
+		}
+		statusMessageID := b.sendResponseAndGetMessageID(chatID, "🗜 Compressing history...")
+		result, err := b.proposeHistoryCompression(compression, time.Now())
+		if err != nil {
+			b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Failed to compress history: %v", err))
+			return nil
+		}
+		b.editMessage(chatID, statusMessageID, describeCompressResult(result))
+		return nil
+
+	case "apply":
+		if compression == nil || compression.PullNumber == 0 {
+			b.sendResponse(chatID, "🗜 There is no compressed history to apply. Use <code>/compress now</code> to propose one.")
+			return nil
+		}
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("⚠️ <b>Replace your history?</b>\n\nYour branch is force-pushed to the compressed history of pull request #%d. Your files stay the same and notes committed since the proposal are kept, but old commit hashes stop working and other clones have to be reset. This can't be undone by the bot.", compression.PullNumber))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚠️ Rewrite history", "compress_apply"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", "compress_cancel"),
+		))
+		if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+			return fmt.Errorf("failed to send history compression confirmation: %w", err)
+		}
+		return nil
+
+	default:
+		b.sendResponse(chatID, "Usage: <code>/compress</code>, <code>/compress on [days]</code>, <code>/compress now</code>, <code>/compress apply</code> or <code>/compress off</code>")
+		return nil
+	}
+}
+
+// handleCompressCallback handles the consent and apply confirmations of /compress
+func (b *Bot) handleCompressCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	switch {
+	case callback.Data == "compress_cancel":
+		b.editMessage(chatID, messageID, "👍 Cancelled, your history stays as it is.")
+		return nil
+
+	case strings.HasPrefix(callback.Data, "compress_consent_"):
+		days, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "compress_consent_"))
+		if err != nil || days < minCompressOlderThanDays {
+			return fmt.Errorf("invalid history compression consent: %s", callback.Data)
+		}
+		if err := b.db.EnableHistoryCompression(chatID, days); err != nil {
+			b.editMessage(chatID, messageID, "❌ Failed to enable history compression.")
+			return nil
+		}
+		logger.Info("User enabled history compression", map[string]interface{}{
+			"chat_id": chatID,
+			"days":    days,
+		})
+		b.editMessage(chatID, messageID, fmt.Sprintf("🗜 History compression enabled for bot commits older than %d days. A pull request proposing the compressed history is opened within a day, or now with /compress now. Nothing is rewritten until you use /compress apply.", days))
+		return nil
+
+	case callback.Data == "compress_apply":
+		provider, ok := b.historyCompressor(chatID)
+		if !ok {
+			return nil
+		}
+		b.editMessage(chatID, messageID, "⏳ Rewriting history...")
+		replayed, err := provider.ApplyCompressedHistory()
+		switch {
+		case errors.Is(err, github.ErrNoCompressedHistory):
+			b.editMessage(chatID, messageID, "🗜 The compressed history is gone, its branch was deleted. Use /compress now to propose it again.")
+		case errors.Is(err, github.ErrCompressedHistoryOutdated):
+			b.editMessage(chatID, messageID, "🗜 Your branch changed too much since the proposal, nothing was rewritten. Use /compress now to propose it again.")
+		case err != nil:
+			b.editMessage(chatID, messageID, fmt.Sprintf("❌ Failed to rewrite history, nothing was changed: %v", err))
+		default:
+			b.db.UpdateHistoryCompressionRun(chatID, 0, time.Now())
+			logger.Info("User applied compressed history", map[string]interface{}{
+				"chat_id":  chatID,
+				"replayed": replayed,
+			})
+			b.editMessage(chatID, messageID, fmt.Sprintf("✅ History rewritten, %d notes committed since the proposal were kept on top. Reset other clones with git fetch && git reset --hard.", replayed))
+		}
+		return nil
+	}
+
+	return nil
+}
+
+// historyCompressor returns the user's provider if it can rewrite history, telling them otherwise
+func (b *Bot) historyCompressor(chatID int64) (github.HistoryCompressor, bool) {
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil, false
+	}
+	compressor, ok := provider.(github.HistoryCompressor)
+	if !ok {
+		b.sendResponse(chatID, "❌ History compression needs clone-based storage, which keeps the local clone the history is rewritten in.")
+		return nil, false
+	}
+	return compressor, true
+}
+
+// proposeHistoryCompression compresses the user's history into a pull request and records the run
+func (b *Bot) proposeHistoryCompression(compression *database.HistoryCompression, now time.Time) (*github.CompressResult, error) {
+	chatID := compression.ChatID
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return nil, err
+	}
+	compressor, ok := provider.(github.HistoryCompressor)
+	if !ok {
+		return nil, fmt.Errorf("history compression needs clone-based storage")
+	}
+
+	result, err := compressor.CompressHistory(github.CompressOptions{
+		Before:    now.AddDate(0, 0, -compression.OlderThanDays),
+		BotEmails: []string{committerEmail(b.getCommitterInfo(chatID)), committerEmail(b.botIdentity())},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := b.db.UpdateHistoryCompressionRun(chatID, result.PullNumber, now); err != nil {
+		logger.Warn("Failed to record history compression", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+	return result, nil
+}
+
+// describeCompressResult tells the user what a compression proposed, as plain text
+func describeCompressResult(result *github.CompressResult) string {
+	if result.Rollups == 0 {
+		return "🗜 Nothing to compress: no day before the threshold has more than one bot commit in a row."
+	}
+	return fmt.Sprintf("🗜 Pull request #%d proposes squashing %d bot commits into %d daily rollups (%d commits instead of %d):\n%s\n\nReview it, then use /compress apply to replace your history. Don't merge it on GitHub.",
+		result.PullNumber, result.Squashed, result.Rollups, result.Commits-result.Squashed+result.Rollups, result.Commits, result.PullURL)
+}
+
+// startHistoryCompressions periodically proposes compressed histories to users who consented
+func (b *Bot) startHistoryCompressions() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopHistoryCompressions = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(historyCompressionCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runHistoryCompressions()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runHistoryCompressions() {
+	now := time.Now()
+	compressions, err := b.db.GetDueHistoryCompressions(now.Add(-historyCompressionInterval))
+	if err != nil {
+		logger.Error("Failed to load history compressions", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, compression := range compressions {
+		if !b.chatAllowed(compression.ChatID) {
+			continue
+		}
+		result, err := b.proposeHistoryCompression(compression, now)
+		if err != nil {
+			logger.Warn("Failed to compress history", map[string]interface{}{
+				"chat_id": compression.ChatID,
+				"error":   err.Error(),
+			})
+			// Try again next week rather than at every check
+			b.db.UpdateHistoryCompressionRun(compression.ChatID, compression.PullNumber, now)
+			continue
+		}
+		// Users hear about new proposals only, not about weeks without anything to compress
+		if result.Rollups > 0 && result.PullNumber != compression.PullNumber {
+			b.sendResponse(compression.ChatID, html.EscapeString(describeCompressResult(result)))
+		}
+	}
+}
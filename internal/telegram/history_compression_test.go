@@ -0,0 +1,21 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestDescribeCompressResult(t *testing.T) {
+	if got := describeCompressResult(&github.CompressResult{Commits: 12}); !strings.Contains(got, "Nothing to compress") {
+		t.Errorf("describeCompressResult() = %q, want nothing to compress", got)
+	}
+
+	got := describeCompressResult(&github.CompressResult{Commits: 120, Squashed: 100, Rollups: 10, PullNumber: 7, PullURL: "https://github.com/o/r/pull/7"})
+	for _, want := range []string{"#7", "100 bot commits into 10 daily rollups", "30 commits instead of 120", "https://github.com/o/r/pull/7", "/compress apply"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("describeCompressResult() = %q, want %q in it", got, want)
+		}
+	}
+}
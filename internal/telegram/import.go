@@ -0,0 +1,321 @@
+package telegram
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/tgexport"
+)
+
+// Import: /import takes the JSON export of a Telegram chat, typically Saved Messages, sent as a
+// file and commits its text messages as notes into one file per day (saved/2024-03-01.md). Day
+// files are committed importBatchFiles at a time in one commit each, pausing between batches and
+// waiting for GitHub's rate limit to reset when it runs low. Messages already in a day file are
+// skipped, so an interrupted import is resumed by sending the same file again.
+
+const (
+	importDefaultFolder  = "saved"
+	importMaxFileSize    = 20 << 20 // Telegram doesn't let bots download larger files
+	importRequestExpiry  = 15 * time.Minute
+	importBatchFiles     = 10
+	importMinRemaining   = 100 // REST requests left below which the import waits for the reset
+	importMaxRateWait    = 15 * time.Minute
+	importRunningTimeout = 2 * time.Hour
+)
+
+// importBatchPause spaces the commits of an import
+var importBatchPause = 2 * time.Second
+
+// importDay is the messages of one day file of an import, oldest first
+type importDay struct {
+	Path     string
+	Messages []tgexport.Message
+}
+
+// groupImportDays groups messages into the day files of folder, ordered by date
+func groupImportDays(messages []tgexport.Message, folder string) []importDay {
+	byDate := make(map[string][]tgexport.Message)
+	for _, message := range messages {
+		date := message.Date.Format("2006-01-02")
+		byDate[date] = append(byDate[date], message)
+	}
+
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+
+	days := make([]importDay, 0, len(dates))
+	for _, date := range dates {
+		dayMessages := byDate[date]
+		sort.SliceStable(dayMessages, func(i, j int) bool { return dayMessages[i].Date.Before(dayMessages[j].Date) })
+		days = append(days, importDay{Path: path.Join(folder, date+".md"), Messages: dayMessages})
+	}
+	return days
+}
+
+// importMeta is the start of the metadata line of an imported message, found in files that already hold it
+func importMeta(messageID int, chatID int64) string {
+	return fmt.Sprintf("[%d] [%d] [", messageID, chatID)
+}
+
+// formatImportedNote formats an imported message as a note, sealed if the user encrypts notes
+func (b *Bot) formatImportedNote(chatID int64, message tgexport.Message) string {
+	title := entry.Title(message.Text)
+	var tags []string
+	for _, tag := range entry.Tags(message.Text) {
+		tags = append(tags, "#"+tag)
+	}
+
+	// Encrypted notes are sealed like new ones (implemented in note_encryption.go)
+	if key, encrypted := b.userNoteKey(chatID); encrypted {
+		return sealNote(key, message.Text, message.ID, chatID, title, strings.Join(tags, " "), message.Date)
+	}
+	return entry.Note(message.Text, message.ID, chatID, title, strings.Join(tags, " "), message.Date)
+}
+
+// importDayContent returns the day file with the messages it doesn't hold yet on top, newest
+// first, and how many messages were added
+func (b *Bot) importDayContent(chatID int64, existing string, day importDay) (string, int) {
+	var notes strings.Builder
+	added := 0
+	for i := len(day.Messages) - 1; i >= 0; i-- {
+		message := day.Messages[i]
+		if strings.Contains(existing, importMeta(message.ID, chatID)) {
+			continue
+		}
+		notes.WriteString(b.formatImportedNote(chatID, message))
+		added++
+	}
+	return notes.String() + existing, added
+}
+
+// handleImportCommand asks for the export file: /import [folder]
+func (b *Bot) handleImportCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	folder, err := parseImportFolder(strings.TrimPrefix(strings.TrimSpace(message.Text), "/import"))
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if _, err := b.getUserGitHubProvider(chatID); err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	b.cache.SetWithExpiry(fmt.Sprintf("import_request_%d", chatID), folder, importRequestExpiry)
+	b.sendResponse(chatID, fmt.Sprintf(`📥 <b>Import Telegram messages</b>
+
+Send the <code>result.json</code> of a chat export as a file within 15 minutes. In Telegram Desktop, open Saved Messages (or any chat), choose ⋮ → Export chat history, untick media and pick the JSON format.
+
+Text messages become notes in one file per day, like <code>%s/2024-03-01.md</code>. Media isn't imported, and messages imported before are skipped. Files up to 20 MB are supported.`,
+		html.EscapeString(folder)))
+	return nil
+}
+
+// parseImportFolder validates the folder of /import, importDefaultFolder if empty
+func parseImportFolder(arg string) (string, error) {
+	folder, err := validateRepoPath(arg)
+	if err != nil {
+		return "", err
+	}
+	if folder == "" {
+		return importDefaultFolder, nil
+	}
+	return folder, nil
+}
+
+// takeImportRequest returns the folder to import an uploaded document into, if it was captioned
+// /import or sent after /import
+func (b *Bot) takeImportRequest(message *tgbotapi.Message) (string, bool) {
+	if caption := strings.TrimSpace(message.Caption); caption == "/import" || strings.HasPrefix(caption, "/import ") {
+		folder, err := parseImportFolder(strings.TrimPrefix(caption, "/import"))
+		if err != nil {
+			b.sendResponse(message.Chat.ID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return "", false
+		}
+		return folder, true
+	}
+
+	cacheKey := fmt.Sprintf("import_request_%d", message.Chat.ID)
+	folder, ok := b.cache.Get(cacheKey)
+	if !ok {
+		return "", false
+	}
+	b.cache.Delete(cacheKey)
+	return folder.(string), true
+}
+
+// handleImportUpload reads an uploaded export and starts committing it in the background
+func (b *Bot) handleImportUpload(message *tgbotapi.Message, folder string) error {
+	chatID := message.Chat.ID
+	document := message.Document
+
+	if !strings.HasSuffix(strings.ToLower(document.FileName), ".json") {
+		b.sendResponse(chatID, "❌ Send the <code>result.json</code> of a chat export in JSON format.")
+		return nil
+	}
+	if document.FileSize > importMaxFileSize {
+		b.sendResponse(chatID, "❌ The export is larger than 20 MB, which Telegram doesn't let bots download. Export without media or a shorter date range.")
+		return nil
+	}
+
+	runningKey := fmt.Sprintf("import_running_%d", chatID)
+	if _, running := b.cache.Get(runningKey); running {
+		b.sendResponse(chatID, "⏳ An import is already running, wait for it to finish.")
+		return nil
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	statusMessageID := b.sendResponseAndGetMessageID(chatID, "📥 Reading the export...")
+
+	file, err := b.downloadFile(chatID, document.FileID, document.FileName)
+	if err != nil {
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Failed to download the export: %v", err))
+		return nil
+	}
+
+	var messages []tgexport.Message
+	skipped, err := tgexport.Read(bytes.NewReader(file.Data), func(m tgexport.Message) error {
+		messages = append(messages, m)
+		return nil
+	})
+	if err != nil {
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Failed to read the export: %v", err))
+		return nil
+	}
+	if len(messages) == 0 {
+		b.editMessage(chatID, statusMessageID, "ℹ️ The export holds no text messages to import.")
+		return nil
+	}
+
+	logger.Info("Starting Telegram export import", map[string]interface{}{
+		"chat_id":  chatID,
+		"folder":   folder,
+		"messages": len(messages),
+		"skipped":  skipped,
+	})
+
+	b.cache.SetWithExpiry(runningKey, true, importRunningTimeout)
+	go func() {
+		defer b.cache.Delete(runningKey)
+		b.runImport(chatID, statusMessageID, userGitHubProvider, folder, groupImportDays(messages, folder), len(messages), skipped)
+	}()
+	return nil
+}
+
+// runImport commits the day files in batches, reporting progress in statusMessageID
+func (b *Bot) runImport(chatID int64, statusMessageID int, provider github.GitHubProvider, folder string, days []importDay, total, skipped int) {
+	premiumLevel := b.getPremiumLevel(chatID)
+	if err := provider.EnsureRepositoryWithPremium(premiumLevel); err != nil {
+		b.recordRepoFailure(chatID, err)
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Failed to set up repository: %v", err))
+		return
+	}
+
+	batches := (len(days) + importBatchFiles - 1) / importBatchFiles
+	imported, processed := 0, 0
+	for batch := 0; batch < batches; batch++ {
+		if batch > 0 {
+			time.Sleep(importBatchPause)
+			if err := b.waitForImportBudget(chatID, statusMessageID, provider); err != nil {
+				b.editMessage(chatID, statusMessageID, fmt.Sprintf("⏸ Import paused after %d of %d notes: %v\n\nSend the same file with /import later to continue, imported notes are skipped.", imported, total, err))
+				return
+			}
+		}
+
+		end := (batch + 1) * importBatchFiles
+		if end > len(days) {
+			end = len(days)
+		}
+
+		files := make(map[string]string)
+		added := 0
+		for _, day := range days[batch*importBatchFiles : end] {
+			processed += len(day.Messages)
+			existing, err := provider.ReadFile(day.Path)
+			if err != nil && !strings.Contains(err.Error(), "does not exist") {
+				b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Import stopped after %d of %d notes, failed to read %s: %v", imported, total, day.Path, err))
+				return
+			}
+			content, dayAdded := b.importDayContent(chatID, existing, day)
+			if dayAdded > 0 {
+				files[day.Path] = content
+				added += dayAdded
+			}
+		}
+
+		if len(files) > 0 {
+			commitMsg := fmt.Sprintf("Import %d notes from Telegram into %s (%d/%d)", added, folder, batch+1, batches)
+			files = b.withReadmeTOC(chatID, provider, files) // Implemented in readme_toc.go
+			if err := provider.ReplaceMultipleFilesWithAuthorAndPremium(files, commitMsg, b.getCommitterInfo(chatID), premiumLevel); err != nil {
+				b.recordRepoFailure(chatID, err)
+				logger.Error("Failed to commit import batch", map[string]interface{}{
+					"chat_id": chatID,
+					"batch":   batch + 1,
+					"error":   err.Error(),
+				})
+				b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Import stopped after %d of %d notes: %v\n\nSend the same file with /import to continue, imported notes are skipped.", imported, total, err))
+				return
+			}
+			b.recordRepoSuccess(chatID)
+			imported += added
+		}
+
+		b.updateProgressMessage(chatID, statusMessageID, processed*100/total, fmt.Sprintf("📥 Imported %d of %d notes...", imported, total))
+	}
+
+	logger.Info("Finished Telegram export import", map[string]interface{}{
+		"chat_id":  chatID,
+		"imported": imported,
+		"files":    len(days),
+	})
+
+	result := fmt.Sprintf("✅ Imported %d notes into %d day files under %s/.", imported, len(days), folder)
+	if existing := total - imported; existing > 0 {
+		result += fmt.Sprintf("\n%d notes were already imported.", existing)
+	}
+	if skipped > 0 {
+		result += fmt.Sprintf("\n%d service or media-only messages were skipped.", skipped)
+	}
+	b.editMessage(chatID, statusMessageID, result)
+}
+
+// waitForImportBudget waits for GitHub's rate limit to reset if little of it is left, failing if
+// the reset is too far away
+func (b *Bot) waitForImportBudget(chatID int64, statusMessageID int, provider github.GitHubProvider) error {
+	reader, ok := provider.(github.RateLimitReader)
+	if !ok {
+		return nil
+	}
+	limits, err := reader.RateLimits()
+	if err != nil || limits.Core.Remaining >= importMinRemaining {
+		return nil
+	}
+
+	wait := time.Until(limits.Core.Reset)
+	if wait > importMaxRateWait {
+		return fmt.Errorf("GitHub rate limit almost used up until %s UTC", limits.Core.Reset.UTC().Format("15:04"))
+	}
+	if wait > 0 {
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("⏳ GitHub rate limit almost used up, resuming at %s UTC...", limits.Core.Reset.UTC().Format("15:04")))
+		time.Sleep(wait)
+	}
+	return nil
+}
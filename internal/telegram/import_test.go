@@ -0,0 +1,54 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/tgexport"
+)
+
+func TestGroupImportDays(t *testing.T) {
+	messages := []tgexport.Message{
+		{ID: 3, Date: time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC), Text: "third"},
+		{ID: 1, Date: time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC), Text: "second"},
+		{ID: 2, Date: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), Text: "first"},
+	}
+
+	days := groupImportDays(messages, "saved")
+	if len(days) != 2 {
+		t.Fatalf("groupImportDays() = %d days, want 2", len(days))
+	}
+	if days[0].Path != "saved/2024-03-01.md" || days[1].Path != "saved/2024-03-02.md" {
+		t.Errorf("day paths = %q, %q", days[0].Path, days[1].Path)
+	}
+	if len(days[0].Messages) != 2 || days[0].Messages[0].ID != 2 || days[0].Messages[1].ID != 1 {
+		t.Errorf("days[0].Messages = %+v, want oldest first", days[0].Messages)
+	}
+}
+
+func TestImportDayContent(t *testing.T) {
+	b := &Bot{}
+	chatID := int64(42)
+	day := importDay{Path: "saved/2024-03-01.md", Messages: []tgexport.Message{
+		{ID: 1, Date: time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), Text: "morning #idea"},
+		{ID: 2, Date: time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC), Text: "evening"},
+	}}
+
+	content, added := b.importDayContent(chatID, "", day)
+	if added != 2 {
+		t.Fatalf("importDayContent() added %d, want 2", added)
+	}
+	if strings.Index(content, "evening") > strings.Index(content, "morning") {
+		t.Errorf("Expected the newest note first, got %q", content)
+	}
+	if !strings.Contains(content, "#idea") || !strings.Contains(content, "2024-03-01 08:00") {
+		t.Errorf("Expected the tags and original date in %q", content)
+	}
+
+	// Importing again adds nothing
+	again, added := b.importDayContent(chatID, content, day)
+	if added != 0 || again != content {
+		t.Errorf("importDayContent() again added %d, want 0", added)
+	}
+}
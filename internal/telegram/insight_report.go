@@ -0,0 +1,340 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/render"
+)
+
+// Insight reports: users who opt in with /report weekly or /report monthly get a summary of what
+// changed in their insights since the last report, every Sunday evening or on the 1st of the month.
+// The report keeps the insights it was computed from, so the next one shows the difference. With
+// /report commit on each report is also committed to reports/ in the repository.
+
+const (
+	insightReportCheckInterval = 1 * time.Hour
+	insightReportHour          = 18 // Local hour the reports are sent at
+	insightReportsDir          = "reports"
+
+	insightReportWeekly  = "weekly"
+	insightReportMonthly = "monthly"
+)
+
+// insightDelta is what changed in a user's insights between two reports
+type insightDelta struct {
+	Commits      int64
+	IssuesOpened int64
+	IssuesClosed int64
+	Tokens       int64
+	RepoGrowth   float64 // MB, negative if the repository shrank
+	RepoSize     float64 // MB
+}
+
+// handleReportCommand shows or switches the insight reports:
+// /report, /report weekly|monthly|off|now, /report commit on|off
+func (b *Bot) handleReportCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.Join(strings.Fields(strings.ToLower(strings.TrimPrefix(strings.TrimSpace(message.Text), "/report"))), " ")
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Insight reports require a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	report, err := b.db.GetInsightReport(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ Failed to load the insight report.")
+		return nil
+	}
+
+	switch args {
+	case "":
+		if report == nil {
+			b.sendResponse(chatID, "📊 Insight reports are off.\n\nUse <code>/report weekly</code> to get your commits, issues, tokens and repository growth every Sunday evening, or <code>/report monthly</code> on the 1st of each month.")
+			return nil
+		}
+		committed := "Add <code>/report commit on</code> to also commit each report to <code>" + insightReportsDir + "/</code>."
+		if report.CommitToRepo {
+			committed = "Each report is also committed to <code>" + insightReportsDir + "/</code>, <code>/report commit off</code> stops that."
+		}
+		b.sendResponse(chatID, fmt.Sprintf("📊 You get a %s insight report %s.\n\n%s\n\nUse <code>/report now</code> to see the report so far or <code>/report off</code> to stop.", report.Period, insightReportSchedule(report.Period), committed))
+		return nil
+	case "on", insightReportWeekly, insightReportMonthly:
+		period := args
+		if period == "on" {
+			period = insightReportWeekly
+		}
+		if err := b.db.EnableInsightReport(chatID, period); err != nil {
+			b.sendResponse(chatID, "❌ Failed to enable insight reports.")
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("📊 %s insight reports enabled. The first one is sent %s and covers the time from now.", strings.Title(period), insightReportSchedule(period)))
+		return nil
+	case "off":
+		if _, err := b.db.DisableInsightReport(chatID); err != nil {
+			b.sendResponse(chatID, "❌ Failed to disable insight reports.")
+			return nil
+		}
+		b.sendResponse(chatID, "📊 Insight reports disabled. Reports already committed stay in your repository.")
+		return nil
+	case "now":
+		if report == nil {
+			b.sendResponse(chatID, "📊 Enable insight reports with <code>/report weekly</code> or <code>/report monthly</code> first.")
+			return nil
+		}
+		insights, err := b.db.GetUserInsights(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to get insights data.")
+			return nil
+		}
+		from := report.CreatedAt
+		if report.LastSentAt != nil {
+			from = *report.LastSentAt
+		}
+		b.sendResponse(chatID, formatInsightReport(report.Period, from, time.Now(), insightReportDelta(report, insights)).String())
+		return nil
+	case "commit on", "commit off":
+		if report == nil {
+			b.sendResponse(chatID, "📊 Enable insight reports with <code>/report weekly</code> or <code>/report monthly</code> first.")
+			return nil
+		}
+		enabled := args == "commit on"
+		if err := b.db.SetInsightReportCommit(chatID, enabled); err != nil {
+			b.sendResponse(chatID, "❌ Failed to update insight reports.")
+			return nil
+		}
+		if enabled {
+			b.sendResponse(chatID, "📊 Reports are now also committed to <code>"+insightReportsDir+"/</code> in your repository.")
+			return nil
+		}
+		b.sendResponse(chatID, "📊 Reports are no longer committed to your repository.")
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/report</code>, <code>/report weekly</code>, <code>/report monthly</code>, <code>/report now</code>, <code>/report commit on|off</code> or <code>/report off</code>")
+		return nil
+	}
+}
+
+// insightReportSchedule describes when reports of the period are sent
+func insightReportSchedule(period string) string {
+	if period == insightReportMonthly {
+		return "on the 1st of each month"
+	}
+	return "every Sunday evening"
+}
+
+// startInsightReports periodically sends the reports that are due
+func (b *Bot) startInsightReports() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopInsightReports = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(insightReportCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runInsightReports()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runInsightReports() {
+	now := time.Now()
+	before := lastInsightReportTime(insightReportWeekly, now)
+	if monthly := lastInsightReportTime(insightReportMonthly, now); monthly.After(before) {
+		before = monthly
+	}
+
+	reports, err := b.db.GetDueInsightReports(before)
+	if err != nil {
+		logger.Error("Failed to load insight reports", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, report := range reports {
+		if !b.chatAllowed(report.ChatID) || report.LastSentAt != nil && !report.LastSentAt.Before(lastInsightReportTime(report.Period, now)) {
+			continue
+		}
+		if err := b.sendInsightReport(report, now); err != nil {
+			logger.Warn("Failed to send insight report", map[string]interface{}{
+				"chat_id": report.ChatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// sendInsightReport sends the report of the time since the last one, commits it if the user asked
+// for it and counts the next report from the current insights
+func (b *Bot) sendInsightReport(report *database.InsightReport, now time.Time) error {
+	chatID := report.ChatID
+
+	insights, err := b.db.GetUserInsights(chatID)
+	if err != nil {
+		return err
+	}
+
+	from := report.CreatedAt
+	if report.LastSentAt != nil {
+		from = *report.LastSentAt
+	}
+	delta := insightReportDelta(report, insights)
+	msg := formatInsightReport(report.Period, from, now, delta)
+
+	if report.CommitToRepo {
+		path := insightReportPath(report.Period, lastInsightReportTime(report.Period, now))
+		if err := b.commitInsightReport(chatID, path, formatInsightReportMarkdown(report.Period, from, now, delta)); err != nil {
+			logger.Warn("Failed to commit insight report", map[string]interface{}{
+				"chat_id": chatID,
+				"path":    path,
+				"error":   err.Error(),
+			})
+			msg.Newline().Italic("⚠️ The report couldn't be committed to your repository.")
+		} else {
+			msg.Newline().Text("📁 Saved to ").Code(path)
+		}
+	}
+
+	if err := b.sendNonEssential(chatID, deferredInsightReport, msg.String()); err != nil {
+		return fmt.Errorf("failed to send report: %w", err)
+	}
+
+	logger.Info("Sent insight report", map[string]interface{}{
+		"chat_id": chatID,
+		"period":  report.Period,
+		"commits": delta.Commits,
+	})
+
+	return b.db.UpdateInsightReportSent(chatID, insights, now)
+}
+
+// commitInsightReport commits the markdown report to path in the user's repository
+func (b *Bot) commitInsightReport(chatID int64, path, content string) error {
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get GitHub provider: %w", err)
+	}
+
+	commitMsg := fmt.Sprintf("Add insight report %s via Telegram", strings.TrimSuffix(strings.TrimPrefix(path, insightReportsDir+"/"), ".md"))
+	return provider.ReplaceFileWithAuthorAndPremium(path, content, commitMsg, b.getCommitterInfo(chatID), b.getPremiumLevel(chatID))
+}
+
+// lastInsightReportTime returns when reports of the period were last due at or before now: Sunday
+// at insightReportHour for weekly reports, the 1st of the month for monthly ones
+func lastInsightReportTime(period string, now time.Time) time.Time {
+	year, month, day := now.Date()
+	if period == insightReportMonthly {
+		due := time.Date(year, month, 1, insightReportHour, 0, 0, 0, now.Location())
+		if due.After(now) {
+			due = due.AddDate(0, -1, 0)
+		}
+		return due
+	}
+
+	due := time.Date(year, month, day-int(now.Weekday()), insightReportHour, 0, 0, 0, now.Location())
+	if due.After(now) {
+		due = due.AddDate(0, 0, -7)
+	}
+	return due
+}
+
+// insightReportDelta returns what changed in insights since the report's counts. Insights only
+// grow, a smaller count (e.g. insights recreated) counts from zero.
+func insightReportDelta(report *database.InsightReport, insights *database.UserInsights) insightDelta {
+	if insights == nil {
+		insights = &database.UserInsights{}
+	}
+	since := func(current, previous int64) int64 {
+		if current < previous {
+			return current
+		}
+		return current - previous
+	}
+
+	return insightDelta{
+		Commits:      since(insights.CommitCnt, report.CommitCnt),
+		IssuesOpened: since(insights.IssueCnt, report.IssueCnt),
+		IssuesClosed: since(insights.IssueCloseCnt, report.IssueCloseCnt),
+		Tokens:       since(insights.TokenInput, report.TokenInput) + since(insights.TokenOutput, report.TokenOutput),
+		RepoGrowth:   insights.RepoSize - report.RepoSize,
+		RepoSize:     insights.RepoSize,
+	}
+}
+
+// insightReportPath returns the file the report due at due is committed to, e.g. reports/2026-W42.md
+// for the week ending on Sunday, October 18 and reports/2026-09.md for September
+func insightReportPath(period string, due time.Time) string {
+	if period == insightReportMonthly {
+		return fmt.Sprintf("%s/%s.md", insightReportsDir, due.AddDate(0, 0, -1).Format("2006-01"))
+	}
+	year, week := due.ISOWeek()
+	return fmt.Sprintf("%s/%d-W%02d.md", insightReportsDir, year, week)
+}
+
+// formatInsightReportRange formats the time a report covers
+func formatInsightReportRange(from, to time.Time) string {
+	if from.Year() != to.Year() {
+		return fmt.Sprintf("%s – %s", from.Format("Jan 2, 2006"), to.Format("Jan 2, 2006"))
+	}
+	return fmt.Sprintf("%s – %s", from.Format("Jan 2"), to.Format("Jan 2, 2006"))
+}
+
+// formatInsightReportGrowth formats the repository growth, e.g. "+1.25 MB (now 40.00 MB)"
+func formatInsightReportGrowth(delta insightDelta) string {
+	return fmt.Sprintf("%+.2f MB (now %.2f MB)", delta.RepoGrowth, delta.RepoSize)
+}
+
+// formatInsightReport renders the report sent to the chat
+func formatInsightReport(period string, from, to time.Time, delta insightDelta) *render.Message {
+	msg := render.New(render.HTML).Text("📊 ").Bold(strings.Title(period) + " report").Text(", " + formatInsightReportRange(from, to)).Newline().Newline()
+
+	if delta.Commits == 0 && delta.IssuesOpened == 0 && delta.IssuesClosed == 0 && delta.Tokens == 0 {
+		msg.Line("Nothing captured this time. Your repository is waiting for you!")
+		return msg
+	}
+
+	msg.Line(fmt.Sprintf("📝 %d %s", delta.Commits, plural(int(delta.Commits), "commit", "commits")))
+	msg.Line(fmt.Sprintf("🐛 %d %s opened, %d closed", delta.IssuesOpened, plural(int(delta.IssuesOpened), "issue", "issues"), delta.IssuesClosed))
+	if delta.Tokens > 0 {
+		msg.Line("🧠 " + formatTokenCount(delta.Tokens) + " tokens used")
+	}
+	if delta.RepoSize > 0 {
+		msg.Line("📦 Repository " + formatInsightReportGrowth(delta))
+	}
+	return msg
+}
+
+// formatInsightReportMarkdown formats the report committed to the repository
+func formatInsightReportMarkdown(period string, from, to time.Time, delta insightDelta) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# %s report, %s\n\n", strings.Title(period), formatInsightReportRange(from, to)))
+	sb.WriteString("| | |\n|---|---|\n")
+	sb.WriteString(fmt.Sprintf("| Commits | %d |\n", delta.Commits))
+	sb.WriteString(fmt.Sprintf("| Issues opened | %d |\n", delta.IssuesOpened))
+	sb.WriteString(fmt.Sprintf("| Issues closed | %d |\n", delta.IssuesClosed))
+	sb.WriteString(fmt.Sprintf("| Tokens used | %s |\n", formatTokenCount(delta.Tokens)))
+	sb.WriteString(fmt.Sprintf("| Repository size | %s |\n", formatInsightReportGrowth(delta)))
+	sb.WriteString("\n<sub>Generated by msg2git. Turn it off with /report off.</sub>\n")
+	return sb.String()
+}
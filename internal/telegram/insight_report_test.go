@@ -0,0 +1,91 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestLastInsightReportTime(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, insightReportHour, 0, 0, 0, time.Local)
+	firstOfMonth := time.Date(2026, 10, 1, insightReportHour, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name   string
+		period string
+		now    time.Time
+		want   time.Time
+	}{
+		{"weekly on sunday evening", insightReportWeekly, sunday, sunday},
+		{"weekly on monday", insightReportWeekly, sunday.Add(12 * time.Hour), sunday},
+		{"weekly on sunday morning", insightReportWeekly, sunday.Add(-8 * time.Hour), sunday.AddDate(0, 0, -7)},
+		{"monthly later in the month", insightReportMonthly, sunday, firstOfMonth},
+		{"monthly on the 1st morning", insightReportMonthly, firstOfMonth.Add(-8 * time.Hour), firstOfMonth.AddDate(0, -1, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lastInsightReportTime(tt.period, tt.now); !got.Equal(tt.want) {
+				t.Errorf("lastInsightReportTime(%s, %v) = %v, want %v", tt.period, tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsightReportDelta(t *testing.T) {
+	report := &database.InsightReport{CommitCnt: 10, IssueCnt: 2, IssueCloseCnt: 1, TokenInput: 100, TokenOutput: 50, RepoSize: 4}
+	insights := &database.UserInsights{CommitCnt: 15, IssueCnt: 5, IssueCloseCnt: 1, TokenInput: 1100, TokenOutput: 550, RepoSize: 5.5}
+
+	want := insightDelta{Commits: 5, IssuesOpened: 3, IssuesClosed: 0, Tokens: 1500, RepoGrowth: 1.5, RepoSize: 5.5}
+	if got := insightReportDelta(report, insights); got != want {
+		t.Errorf("insightReportDelta() = %+v, want %+v", got, want)
+	}
+
+	// Recreated insights count from zero
+	if got := insightReportDelta(report, &database.UserInsights{CommitCnt: 3}); got.Commits != 3 {
+		t.Errorf("insightReportDelta() of recreated insights = %+v", got)
+	}
+	if got := insightReportDelta(report, nil); got.Commits != 0 || got.Tokens != 0 {
+		t.Errorf("insightReportDelta() without insights = %+v", got)
+	}
+}
+
+func TestInsightReportPath(t *testing.T) {
+	sunday := time.Date(2026, 10, 18, insightReportHour, 0, 0, 0, time.Local)
+	if got := insightReportPath(insightReportWeekly, sunday); got != "reports/2026-W42.md" {
+		t.Errorf("weekly path = %q", got)
+	}
+	if got := insightReportPath(insightReportMonthly, time.Date(2026, 10, 1, insightReportHour, 0, 0, 0, time.Local)); got != "reports/2026-09.md" {
+		t.Errorf("monthly path = %q", got)
+	}
+}
+
+func TestFormatInsightReport(t *testing.T) {
+	from := time.Date(2026, 10, 11, insightReportHour, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 0, 7)
+	delta := insightDelta{Commits: 1, IssuesOpened: 2, IssuesClosed: 1, Tokens: 12345, RepoGrowth: 0.25, RepoSize: 40}
+
+	text := formatInsightReport(insightReportWeekly, from, to, delta).String()
+	for _, want := range []string{
+		"<b>Weekly report</b>, Oct 11 – Oct 18, 2026",
+		"📝 1 commit\n",
+		"🐛 2 issues opened, 1 closed",
+		"tokens used",
+		"📦 Repository +0.25 MB (now 40.00 MB)",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatInsightReport() = %q, missing %q", text, want)
+		}
+	}
+
+	if text := formatInsightReport(insightReportMonthly, from, to, insightDelta{}).String(); !strings.Contains(text, "Nothing captured") {
+		t.Errorf("formatInsightReport() of an empty period = %q", text)
+	}
+
+	markdown := formatInsightReportMarkdown(insightReportWeekly, from, to, delta)
+	if !strings.HasPrefix(markdown, "# Weekly report, Oct 11 – Oct 18, 2026\n") || !strings.Contains(markdown, "| Commits | 1 |") {
+		t.Errorf("formatInsightReportMarkdown() = %q", markdown)
+	}
+}
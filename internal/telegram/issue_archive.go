@@ -0,0 +1,204 @@
+package telegram
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Issue archiving: /sync moves closed issues out of issue.md once they have been closed for the
+// user's retention period, into issue_archived.md or one issue_archived_<year>.md per year
+
+// issueClosedDateLayout is the date format of the "(closed 2006-01-02)" suffix on closed issue lines
+const issueClosedDateLayout = "2006-01-02"
+
+// maxIssueArchiveDays caps the retention so issue.md can't grow forever again
+const maxIssueArchiveDays = 365
+
+// issueArchivePlan splits the issues of issue.md for a sync
+type issueArchivePlan struct {
+	Active   []int                            // open issues, synced with GitHub
+	Retained []*github.IssueStatus            // closed issues still within the retention period
+	Archived map[string][]*github.IssueStatus // archive file -> closed issues moved there
+}
+
+// ArchivedCount returns the number of issues moved to archive files
+func (p *issueArchivePlan) ArchivedCount() int {
+	count := 0
+	for _, issues := range p.Archived {
+		count += len(issues)
+	}
+	return count
+}
+
+// ArchiveFiles returns the archive files written by the plan, sorted
+func (p *issueArchivePlan) ArchiveFiles() []string {
+	files := make([]string, 0, len(p.Archived))
+	for filename := range p.Archived {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// issueArchiveFileName returns the archive file for an issue closed in year
+func issueArchiveFileName(year int, yearly bool) string {
+	if !yearly {
+		return consts.IssueArchiveFile
+	}
+	return fmt.Sprintf("%s_%d.md", strings.TrimSuffix(consts.IssueArchiveFile, ".md"), year)
+}
+
+// planIssueArchive decides which issues stay in issue.md. Closed issues without a close date
+// (written before retention existed) are archived right away, like closed issues with days == 0.
+func planIssueArchive(statuses map[int]*github.IssueStatus, days int, yearly bool, now time.Time) *issueArchivePlan {
+	plan := &issueArchivePlan{Archived: make(map[string][]*github.IssueStatus)}
+	cutoff := now.AddDate(0, 0, -days)
+
+	var issueList []*github.IssueStatus
+	for _, status := range statuses {
+		issueList = append(issueList, status)
+	}
+	sort.Slice(issueList, func(i, j int) bool {
+		return issueList[i].Number > issueList[j].Number
+	})
+
+	for _, issue := range issueList {
+		if strings.ToLower(issue.State) == "open" {
+			plan.Active = append(plan.Active, issue.Number)
+			continue
+		}
+
+		if days > 0 && issue.ClosedAt != nil && issue.ClosedAt.After(cutoff) {
+			plan.Retained = append(plan.Retained, issue)
+			continue
+		}
+
+		year := now.Year()
+		if issue.ClosedAt != nil {
+			year = issue.ClosedAt.Year()
+		}
+		filename := issueArchiveFileName(year, yearly)
+		plan.Archived[filename] = append(plan.Archived[filename], issue)
+	}
+
+	return plan
+}
+
+// stampClosedAt sets the close date of closed issues GitHub returned without one to now,
+// so their retention period starts with this sync
+func stampClosedAt(statuses map[int]*github.IssueStatus, now time.Time) {
+	for _, status := range statuses {
+		if strings.ToLower(status.State) == "closed" && status.ClosedAt == nil {
+			closedAt := now
+			status.ClosedAt = &closedAt
+		}
+	}
+}
+
+// formatIssueLine formats an issue the way issue.md and the archive files list it
+func formatIssueLine(owner, repo string, issue *github.IssueStatus) string {
+	if strings.ToLower(issue.State) != "closed" {
+		return fmt.Sprintf("- 🟢 %s/%s#%d [%s]", owner, repo, issue.Number, issue.Title)
+	}
+	line := fmt.Sprintf("- 🔴 %s/%s#%d [%s]", owner, repo, issue.Number, issue.Title)
+	if issue.ClosedAt != nil {
+		line += fmt.Sprintf(" (closed %s)", issue.ClosedAt.UTC().Format(issueClosedDateLayout))
+	}
+	return line
+}
+
+// issueArchiveSettings returns the user's retention in days and whether archives are split by year
+func (b *Bot) issueArchiveSettings(chatID int64) (int, bool) {
+	if b.db == nil {
+		return 0, false
+	}
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		return 0, false
+	}
+	return user.IssueArchiveDays, user.IssueArchiveYearly
+}
+
+// describeIssueArchive describes archive settings for /archive and /sync
+func describeIssueArchive(days int, yearly bool) string {
+	retention := "as soon as they are closed"
+	if days == 1 {
+		retention = "1 day after they are closed"
+	} else if days > 1 {
+		retention = fmt.Sprintf("%d days after they are closed", days)
+	}
+
+	target := consts.IssueArchiveFile
+	if yearly {
+		target = issueArchiveFileName(time.Now().Year(), true) + " (one file per year)"
+	}
+
+	return fmt.Sprintf("Closed issues move from issue.md to <code>%s</code> on /sync %s.", target, retention)
+}
+
+// handleArchiveCommand shows or changes issue archiving:
+// /archive, /archive <days>, /archive yearly on|off
+func (b *Bot) handleArchiveCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/archive"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Issue archive settings require a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	days, yearly := 0, false
+	if user != nil {
+		days, yearly = user.IssueArchiveDays, user.IssueArchiveYearly
+	}
+
+	usage := fmt.Sprintf(`
+
+• /archive 30 - Keep closed issues in issue.md for 30 days (0 - %d)
+• /archive yearly on - Archive into one file per year
+• /archive yearly off - Archive into %s`, maxIssueArchiveDays, consts.IssueArchiveFile)
+
+	switch {
+	case len(args) == 0:
+		b.sendResponse(chatID, "📦 "+describeIssueArchive(days, yearly)+usage)
+		return nil
+	case len(args) == 2 && args[0] == "yearly" && (args[1] == "on" || args[1] == "off"):
+		yearly = args[1] == "on"
+	case len(args) == 1:
+		parsed, err := strconv.Atoi(args[0])
+		if err != nil || parsed < 0 || parsed > maxIssueArchiveDays {
+			b.sendResponse(chatID, "❌ Invalid number of days."+usage)
+			return nil
+		}
+		days = parsed
+	default:
+		b.sendResponse(chatID, "❌ Unknown archive setting."+usage)
+		return nil
+	}
+
+	if err := b.db.UpdateUserIssueArchive(chatID, days, yearly); err != nil {
+		logger.Error("Failed to update issue archive settings", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, "❌ Failed to update archive settings")
+		return nil
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("%s %s", consts.EmojiSuccess, describeIssueArchive(days, yearly)))
+	return nil
+}
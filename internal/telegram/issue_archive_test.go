@@ -0,0 +1,92 @@
+package telegram
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestPlanIssueArchive(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		closedAt := now.AddDate(0, 0, -days)
+		return &closedAt
+	}
+
+	statuses := map[int]*github.IssueStatus{
+		1: {Number: 1, State: "closed"}, // legacy line without a close date
+		2: {Number: 2, State: "closed", ClosedAt: daysAgo(90)},
+		3: {Number: 3, State: "closed", ClosedAt: daysAgo(3)},
+		4: {Number: 4, State: "open"},
+		5: {Number: 5, State: "OPEN"},
+	}
+
+	plan := planIssueArchive(statuses, 0, false, now)
+	if !reflect.DeepEqual(plan.Active, []int{5, 4}) || len(plan.Retained) != 0 || plan.ArchivedCount() != 3 {
+		t.Errorf("planIssueArchive(0 days) = %+v", plan)
+	}
+	if files := plan.ArchiveFiles(); !reflect.DeepEqual(files, []string{consts.IssueArchiveFile}) {
+		t.Errorf("ArchiveFiles() = %v", files)
+	}
+
+	plan = planIssueArchive(statuses, 30, false, now)
+	if len(plan.Retained) != 1 || plan.Retained[0].Number != 3 || plan.ArchivedCount() != 2 {
+		t.Errorf("planIssueArchive(30 days) = %+v", plan)
+	}
+
+	plan = planIssueArchive(statuses, 30, true, now)
+	if files := plan.ArchiveFiles(); !reflect.DeepEqual(files, []string{"issue_archived_2025.md", "issue_archived_2026.md"}) {
+		t.Errorf("ArchiveFiles(yearly) = %v", files)
+	}
+	if archived := plan.Archived["issue_archived_2025.md"]; len(archived) != 1 || archived[0].Number != 2 {
+		t.Errorf("Archived[2025] = %+v", archived)
+	}
+}
+
+func TestIssueLineRoundTrip(t *testing.T) {
+	bot := &Bot{}
+	githubManager, _ := github.NewManager(&config.Config{GitHubRepo: "https://github.com/owner/repo"}, 0)
+
+	closedAt := time.Date(2026, 1, 31, 8, 0, 0, 0, time.UTC)
+	issues := []*github.IssueStatus{
+		{Number: 2, Title: "Open issue", State: "open"},
+		{Number: 1, Title: "Closed issue", State: "closed", ClosedAt: &closedAt},
+	}
+
+	content := bot.generateIssueContentFromStatuses(issues, githubManager)
+	want := "- 🟢 owner/repo#2 [Open issue]\n- 🔴 owner/repo#1 [Closed issue] (closed 2026-01-31)\n"
+	if content != want {
+		t.Errorf("generateIssueContentFromStatuses() = %q, want %q", content, want)
+	}
+
+	statuses := bot.parseIssueStatusesFromContent(content, githubManager)
+	if statuses[2] == nil || statuses[2].ClosedAt != nil {
+		t.Errorf("open issue = %+v", statuses[2])
+	}
+	if statuses[1] == nil || statuses[1].Title != "Closed issue" || statuses[1].ClosedAt == nil || !statuses[1].ClosedAt.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("closed issue = %+v", statuses[1])
+	}
+}
+
+func TestStampClosedAt(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	earlier := now.AddDate(0, 0, -5)
+	statuses := map[int]*github.IssueStatus{
+		1: {Number: 1, State: "CLOSED"},
+		2: {Number: 2, State: "closed", ClosedAt: &earlier},
+		3: {Number: 3, State: "open"},
+	}
+
+	stampClosedAt(statuses, now)
+
+	if statuses[1].ClosedAt == nil || !statuses[1].ClosedAt.Equal(now) {
+		t.Errorf("issue 1 ClosedAt = %v, want now", statuses[1].ClosedAt)
+	}
+	if !statuses[2].ClosedAt.Equal(earlier) || statuses[3].ClosedAt != nil {
+		t.Errorf("stampClosedAt() changed issues it shouldn't: %+v, %+v", statuses[2], statuses[3])
+	}
+}
@@ -0,0 +1,192 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Issue labels: hashtags of a message turned into an issue become its labels, GitHub creates the
+// ones the repository lacks. A message without hashtags gets a keyboard of the repository's labels
+// to pick from before the issue is created. Every issue listed in issue.md also gets the marker
+// label, which /rebuildissues finds them by.
+
+const (
+	issueLabelPickerMax    = 12 // Labels offered in the keyboard
+	issueLabelPickerExpiry = 30 * time.Minute
+)
+
+// issueLabelPicker is the state of a label keyboard, kept by chat and message so callbacks can
+// refer to labels by index (Telegram limits callback data to 64 bytes)
+type issueLabelPicker struct {
+	MessageKey string
+	Labels     []string
+	Selected   []bool
+}
+
+func issueLabelPickerKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("issue_labels_%d_%d", chatID, messageID)
+}
+
+// handleIssueCreation creates an issue from a pending message once the ISSUE option is chosen,
+// labeled with the message's hashtags or after choosing labels of the repository
+func (b *Bot) handleIssueCreation(callback *tgbotapi.CallbackQuery, messageKey string) error {
+	chatID := callback.Message.Chat.ID
+
+	messageData, exists := b.pendingMessage(messageKey)
+	if !exists {
+		return fmt.Errorf("original message not found")
+	}
+	content, _, _ := strings.Cut(messageData, "|||DELIM|||")
+
+	if labels := entry.Tags(content); len(labels) > 0 {
+		return b.createIssueFromPending(callback, messageKey, labels)
+	}
+
+	// Errors are reported by createIssueFromPending, which needs the provider too
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return b.createIssueFromPending(callback, messageKey, nil)
+	}
+	repoLabels, err := provider.ListLabels()
+	if err != nil {
+		logger.Warn("Failed to list repository labels, creating issue without labels", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+	repoLabels = withoutIssueMarker(repoLabels)
+	if len(repoLabels) == 0 {
+		return b.createIssueFromPending(callback, messageKey, nil)
+	}
+	if len(repoLabels) > issueLabelPickerMax {
+		repoLabels = repoLabels[:issueLabelPickerMax]
+	}
+
+	picker := &issueLabelPicker{
+		MessageKey: messageKey,
+		Labels:     repoLabels,
+		Selected:   make([]bool, len(repoLabels)),
+	}
+	b.cache.SetWithExpiry(issueLabelPickerKey(chatID, callback.Message.MessageID), picker, issueLabelPickerExpiry)
+	return b.showIssueLabelPicker(chatID, callback.Message.MessageID, picker)
+}
+
+// showIssueLabelPicker shows the label keyboard in messageID
+func (b *Bot) showIssueLabelPicker(chatID int64, messageID int, picker *issueLabelPicker) error {
+	text := "🏷 <b>Add labels to this issue?</b>\n\nTap labels to select them, then create the issue.\n\n<i>💡 Hashtags such as #bug in your message become labels right away.</i>"
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = consts.ParseModeHTML
+	keyboard := issueLabelKeyboard(picker)
+	edit.ReplyMarkup = &keyboard
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to show issue labels: %w", err)
+	}
+	return nil
+}
+
+// issueLabelKeyboard lists the picker's labels three per row, followed by the create button
+func issueLabelKeyboard(picker *issueLabelPicker) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, label := range picker.Labels {
+		text := label
+		if picker.Selected[i] {
+			text = "✅ " + label
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(text, fmt.Sprintf("ilabel_%d", i)))
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	create := "❓ Create issue"
+	if len(picker.selectedLabels()) == 0 {
+		create = "❓ Create without labels"
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData(create, "ilabel_create")))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+func (p *issueLabelPicker) selectedLabels() []string {
+	var labels []string
+	for i, label := range p.Labels {
+		if p.Selected[i] {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// handleIssueLabelCallback handles ilabel_<index> toggles and ilabel_create from the label keyboard
+func (b *Bot) handleIssueLabelCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	cacheKey := issueLabelPickerKey(chatID, messageID)
+	cached, ok := b.cache.Get(cacheKey)
+	if !ok {
+		b.editMessage(chatID, messageID, "⏰ This label choice has expired. Send your message again to create the issue.")
+		return nil
+	}
+	picker := cached.(*issueLabelPicker)
+
+	if callback.Data == "ilabel_create" {
+		b.cache.Delete(cacheKey)
+		return b.createIssueFromPending(callback, picker.MessageKey, picker.selectedLabels())
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "ilabel_"))
+	if err != nil || index < 0 || index >= len(picker.Labels) {
+		return fmt.Errorf("invalid issue label callback data: %s", callback.Data)
+	}
+	picker.Selected[index] = !picker.Selected[index]
+	b.cache.SetWithExpiry(cacheKey, picker, issueLabelPickerExpiry)
+
+	keyboard := issueLabelKeyboard(picker)
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to update issue labels: %w", err)
+	}
+	return nil
+}
+
+// withIssueMarker returns labels with the marker label of issues listed in issue.md
+func withIssueMarker(labels []string) []string {
+	for _, label := range labels {
+		if strings.EqualFold(label, consts.IssueMarkerLabel) {
+			return labels
+		}
+	}
+	return append(append([]string(nil), labels...), consts.IssueMarkerLabel)
+}
+
+// withoutIssueMarker returns labels without the marker label, which users don't pick
+func withoutIssueMarker(labels []string) []string {
+	var filtered []string
+	for _, label := range labels {
+		if !strings.EqualFold(label, consts.IssueMarkerLabel) {
+			filtered = append(filtered, label)
+		}
+	}
+	return filtered
+}
+
+// formatIssueLabels describes the labels of a created issue for its confirmation
+func formatIssueLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	return " · 🏷 " + strings.Join(labels, ", ")
+}
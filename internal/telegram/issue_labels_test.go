@@ -0,0 +1,73 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestFormatIssueLabels(t *testing.T) {
+	if got := formatIssueLabels(nil); got != "" {
+		t.Errorf("formatIssueLabels(nil) = %q, want empty", got)
+	}
+	if got := formatIssueLabels([]string{"bug", "idea"}); got != " · 🏷 bug, idea" {
+		t.Errorf("formatIssueLabels() = %q", got)
+	}
+}
+
+func TestIssueLabelKeyboard(t *testing.T) {
+	picker := &issueLabelPicker{
+		Labels:   []string{"bug", "idea", "docs", "later"},
+		Selected: []bool{false, true, false, false},
+	}
+
+	keyboard := issueLabelKeyboard(picker)
+	if len(keyboard.InlineKeyboard) != 3 || len(keyboard.InlineKeyboard[0]) != 3 || len(keyboard.InlineKeyboard[1]) != 1 {
+		t.Fatalf("Expected two rows of labels and a create row, got %+v", keyboard.InlineKeyboard)
+	}
+	if button := keyboard.InlineKeyboard[0][1]; button.Text != "✅ idea" || *button.CallbackData != "ilabel_1" {
+		t.Errorf("Expected idea to be selected, got %+v", button)
+	}
+	if button := keyboard.InlineKeyboard[2][0]; button.Text != "❓ Create issue" || *button.CallbackData != "ilabel_create" {
+		t.Errorf("Expected the create button, got %+v", button)
+	}
+
+	picker.Selected[1] = false
+	keyboard = issueLabelKeyboard(picker)
+	if button := keyboard.InlineKeyboard[2][0]; button.Text != "❓ Create without labels" {
+		t.Errorf("Expected create without labels, got %+v", button)
+	}
+}
+
+func TestHandleIssueLabelCallback(t *testing.T) {
+	bot, fake := newFakeBot(t)
+
+	callback := &tgbotapi.CallbackQuery{ID: "cb", Message: commandMessage(42, "menu"), Data: "ilabel_0"}
+	if err := bot.handleIssueLabelCallback(callback); err != nil {
+		t.Fatalf("handleIssueLabelCallback(expired) error = %v", err)
+	}
+	calls := fake.Calls()
+	if last := calls[len(calls)-1]; last.Method != "editMessageText" || !strings.Contains(last.Params.Get("text"), "expired") {
+		t.Errorf("Expected an expiry notice, got %+v", last)
+	}
+
+	picker := &issueLabelPicker{MessageKey: "42_1", Labels: []string{"bug", "idea"}, Selected: make([]bool, 2)}
+	bot.cache.SetWithExpiry(issueLabelPickerKey(42, 1), picker, issueLabelPickerExpiry)
+
+	if err := bot.handleIssueLabelCallback(callback); err != nil {
+		t.Fatalf("handleIssueLabelCallback() error = %v", err)
+	}
+	if !picker.Selected[0] || picker.Selected[1] {
+		t.Errorf("Expected bug to be selected, got %v", picker.Selected)
+	}
+	calls = fake.Calls()
+	if last := calls[len(calls)-1]; last.Method != "editMessageReplyMarkup" || !strings.Contains(last.Params.Get("reply_markup"), "✅ bug") {
+		t.Errorf("Expected the keyboard to be updated, got %+v", last)
+	}
+
+	callback.Data = "ilabel_7"
+	if err := bot.handleIssueLabelCallback(callback); err == nil {
+		t.Error("Expected an error for an unknown label")
+	}
+}
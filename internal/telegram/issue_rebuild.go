@@ -0,0 +1,216 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Issue rebuild: /rebuildissues regenerates issue.md from GitHub when it got corrupted or badly
+// edited. It lists the issues carrying the marker label, which the bot adds to every issue it
+// lists in issue.md, plus issues issue.md still mentions (created before the label existed).
+// Closed issues already in an archive file stay there; closed ones past the retention period are
+// archived by the next /sync as usual.
+
+// issueArchiveFilePrefix is the name shared by issue_archived.md and the yearly archive files
+var issueArchiveFilePrefix = strings.TrimSuffix(consts.IssueArchiveFile, ".md")
+
+// issueRebuild is what /rebuildissues found on GitHub
+type issueRebuild struct {
+	Statuses  map[int]*github.IssueStatus
+	Labeled   int // issues found by the marker label
+	Recovered int // issues without the label that issue.md still mentioned
+	Archived  int // closed issues left out because an archive file lists them
+}
+
+// handleRebuildIssuesCommand handles /rebuildissues (also accepted as /rebuild-issues)
+func (b *Bot) handleRebuildIssuesCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	statusMessageID := b.sendResponseAndGetMessageID(chatID, "🔧 Rebuilding issue.md from GitHub...")
+
+	userID, err := b.getUserIDForLocking(chatID)
+	if err != nil {
+		b.editMessage(chatID, statusMessageID, "❌ Failed to initialize file locking")
+		return err
+	}
+	repoURL, err := b.getRepositoryURL(chatID)
+	if err != nil {
+		b.editMessage(chatID, statusMessageID, "❌ Failed to get repository information")
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	issueHandle, err := github.GetFileLockManager().AcquireFileLock(ctx, userID, repoURL, consts.FileNameIssue, true)
+	if err != nil {
+		b.editMessage(chatID, statusMessageID, "❌ Failed to acquire lock for issue.md - a sync may be in progress")
+		return err
+	}
+	defer issueHandle.Release()
+
+	issueContent, err := userGitHubProvider.ReadFile(consts.FileNameIssue)
+	if err != nil {
+		// A deleted issue.md is rebuilt from the labeled issues alone
+		issueContent = ""
+	}
+
+	rebuild, err := b.collectIssuesForRebuild(userGitHubProvider, issueContent)
+	if err != nil {
+		logger.Error("Failed to collect issues for rebuild", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Failed to list issues from GitHub: %v", err))
+		return nil
+	}
+	if len(rebuild.Statuses) == 0 {
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("ℹ️ No issues of the bot were found on GitHub (labeled %s or mentioned in issue.md), issue.md was left unchanged.", consts.IssueMarkerLabel))
+		return nil
+	}
+
+	newContent := b.generateIssueContent(rebuild.Statuses, userGitHubProvider)
+	if newContent != issueContent {
+		commitMsg := fmt.Sprintf("Rebuild issue.md from %d GitHub issues via Telegram", len(rebuild.Statuses))
+		if err := userGitHubProvider.ReplaceFileWithAuthorAndPremium(consts.FileNameIssue, newContent, commitMsg, b.getCommitterInfo(chatID), b.getPremiumLevel(chatID)); err != nil {
+			logger.Error("Failed to commit rebuilt issue.md", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			b.editMessage(chatID, statusMessageID, "❌ Failed to update issue.md")
+			return err
+		}
+	}
+
+	logger.Info("Rebuilt issue.md from GitHub", map[string]interface{}{
+		"chat_id":   chatID,
+		"issues":    len(rebuild.Statuses),
+		"labeled":   rebuild.Labeled,
+		"recovered": rebuild.Recovered,
+		"archived":  rebuild.Archived,
+		"changed":   newContent != issueContent,
+	})
+
+	issueFileLink, err := userGitHubProvider.GetGitHubFileURLWithBranch(consts.FileNameIssue)
+	if err != nil {
+		issueFileLink = ""
+	}
+	b.editOrSendHTML(chatID, statusMessageID, formatIssueRebuild(rebuild, newContent != issueContent, issueFileLink))
+	return nil
+}
+
+// collectIssuesForRebuild returns the issues issue.md should list according to GitHub
+func (b *Bot) collectIssuesForRebuild(githubProvider github.GitHubProvider, issueContent string) (*issueRebuild, error) {
+	labeled, err := githubProvider.ListIssuesByLabel(consts.IssueMarkerLabel)
+	if err != nil {
+		return nil, err
+	}
+
+	rebuild := &issueRebuild{Statuses: make(map[int]*github.IssueStatus), Labeled: len(labeled)}
+	for _, issue := range labeled {
+		rebuild.Statuses[issue.Number] = issue
+	}
+
+	// Issues created before the marker label, as far as issue.md still mentions them
+	var unlabeled []int
+	for _, number := range b.parseIssueNumbers(issueContent) {
+		if _, ok := rebuild.Statuses[number]; !ok {
+			unlabeled = append(unlabeled, number)
+		}
+	}
+	if len(unlabeled) > 0 {
+		recovered, err := githubProvider.SyncIssueStatuses(unlabeled)
+		if err != nil {
+			logger.Warn("Failed to look up issues mentioned in issue.md", map[string]interface{}{
+				"issues": len(unlabeled),
+				"error":  err.Error(),
+			})
+		}
+		for number, status := range recovered {
+			if status.PullRequest == nil {
+				rebuild.Statuses[number] = status
+				rebuild.Recovered++
+			}
+		}
+	}
+
+	for number := range b.archivedIssueNumbers(githubProvider) {
+		if status, ok := rebuild.Statuses[number]; ok && strings.ToLower(status.State) == "closed" {
+			delete(rebuild.Statuses, number)
+			rebuild.Archived++
+		}
+	}
+
+	return rebuild, nil
+}
+
+// archivedIssueNumbers returns the issues listed in issue_archived.md and the yearly archive files
+func (b *Bot) archivedIssueNumbers(githubProvider github.GitHubProvider) map[int]bool {
+	archived := make(map[int]bool)
+
+	entries, err := githubProvider.ListDirectory("")
+	if err != nil {
+		logger.Warn("Failed to list archive files", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return archived
+	}
+
+	for _, entry := range entries {
+		if entry.Type == "dir" || !strings.HasPrefix(entry.Name, issueArchiveFilePrefix) || !strings.HasSuffix(entry.Name, ".md") {
+			continue
+		}
+		content, err := githubProvider.ReadFile(entry.Name)
+		if err != nil {
+			continue
+		}
+		for _, number := range b.parseIssueNumbers(content) {
+			archived[number] = true
+		}
+	}
+	return archived
+}
+
+// formatIssueRebuild renders the /rebuildissues report
+func formatIssueRebuild(rebuild *issueRebuild, changed bool, issueFileLink string) string {
+	open, closed := 0, 0
+	for _, status := range rebuild.Statuses {
+		if strings.ToLower(status.State) == "open" {
+			open++
+		} else {
+			closed++
+		}
+	}
+
+	var sb strings.Builder
+	if changed {
+		sb.WriteString("✅ <b>issue.md rebuilt from GitHub</b>\n\n")
+	} else {
+		sb.WriteString("✅ <b>issue.md already matches GitHub</b>\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("• %d open and %d closed issues listed\n", open, closed))
+	sb.WriteString(fmt.Sprintf("• %d found by the <code>%s</code> label\n", rebuild.Labeled, html.EscapeString(consts.IssueMarkerLabel)))
+	if rebuild.Recovered > 0 {
+		sb.WriteString(fmt.Sprintf("• %d recovered from issue.md without the label\n", rebuild.Recovered))
+	}
+	if rebuild.Archived > 0 {
+		sb.WriteString(fmt.Sprintf("• %d closed issues left in their archive file\n", rebuild.Archived))
+	}
+	if issueFileLink != "" {
+		sb.WriteString(fmt.Sprintf("\n🔗 <a href=\"%s\">View issue.md</a>", html.EscapeString(issueFileLink)))
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/testutil"
+)
+
+func TestCollectIssuesForRebuild(t *testing.T) {
+	fake := testutil.NewFakeGitHub(t)
+	fake.AddRepo("owner", "notes")
+	github.SetAPIBaseURLs(fake.URL(), fake.URL())
+	t.Cleanup(func() { github.SetAPIBaseURLs("", "") })
+
+	labeledOpen := fake.AddIssue("owner", "notes", "Labeled open", "open")
+	labeledOpen.Labels = []string{consts.IssueMarkerLabel, "bug"}
+	labeledArchived := fake.AddIssue("owner", "notes", "Labeled archived", "closed")
+	labeledArchived.Labels = []string{consts.IssueMarkerLabel}
+	legacy := fake.AddIssue("owner", "notes", "Before the label", "closed")
+	fake.AddIssue("owner", "notes", "Not from the bot", "open")
+	fake.SetFile("owner", "notes", "issue_archived_2025.md", "- 🔴 owner/notes#2 [Labeled archived] (closed 2025-03-01)\n")
+
+	provider, err := github.NewAPIBasedProvider(github.NewProviderConfig(&config.Config{
+		GitHubToken: "ghp_fake",
+		GitHubRepo:  "https://github.com/owner/notes",
+	}, 0, "42"))
+	if err != nil {
+		t.Fatalf("NewAPIBasedProvider() error = %v", err)
+	}
+
+	bot := &Bot{}
+	brokenIssueFile := "<<<<<<< HEAD\n- 🟢 owner/notes#1 [Labeled open]\n=======\nsee owner/notes#3 garbled"
+	rebuild, err := bot.collectIssuesForRebuild(provider, brokenIssueFile)
+	if err != nil {
+		t.Fatalf("collectIssuesForRebuild() error = %v", err)
+	}
+
+	if len(rebuild.Statuses) != 2 || rebuild.Statuses[labeledOpen.Number] == nil || rebuild.Statuses[legacy.Number] == nil {
+		t.Errorf("collectIssuesForRebuild() statuses = %+v", rebuild.Statuses)
+	}
+	if rebuild.Labeled != 2 || rebuild.Recovered != 1 || rebuild.Archived != 1 {
+		t.Errorf("collectIssuesForRebuild() = %+v", rebuild)
+	}
+
+	report := formatIssueRebuild(rebuild, true, "https://github.com/owner/notes/blob/main/issue.md")
+	for _, want := range []string{"rebuilt", "1 open and 1 closed", "1 recovered", "1 closed issues left"} {
+		if !strings.Contains(report, want) {
+			t.Errorf("Expected %q in %q", want, report)
+		}
+	}
+}
+
+func TestWithIssueMarker(t *testing.T) {
+	labels := []string{"bug"}
+	if got := withIssueMarker(labels); len(got) != 2 || got[1] != consts.IssueMarkerLabel || len(labels) != 1 {
+		t.Errorf("withIssueMarker() = %v", got)
+	}
+	if got := withIssueMarker([]string{"MSG2GIT"}); len(got) != 1 {
+		t.Errorf("withIssueMarker(marker) = %v, want no duplicate", got)
+	}
+	if got := withoutIssueMarker([]string{"bug", consts.IssueMarkerLabel}); len(got) != 1 || got[0] != "bug" {
+		t.Errorf("withoutIssueMarker() = %v", got)
+	}
+}
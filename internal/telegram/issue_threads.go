@@ -0,0 +1,156 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/render"
+)
+
+// Issue threads: opening an issue from /issue shows its latest comments, older ones page in on demand
+
+const (
+	issueThreadPageSize      = 5
+	issueThreadCommentLength = 600
+	// Telegram rejects callback data longer than 64 bytes
+	maxCallbackDataLength = 64
+)
+
+// issueThreadPageCallback returns the callback data paging an issue thread to the comments before
+// the cursor (the latest without one), "" if the cursor doesn't fit into callback data
+func issueThreadPageCallback(issueNumber int, before string) string {
+	data := fmt.Sprintf("issue_older_%d", issueNumber)
+	if before != "" {
+		data += "_" + before
+	}
+	if len(data) > maxCallbackDataLength {
+		return ""
+	}
+	return data
+}
+
+// parseIssueThreadCallback parses issue_thread_<issue> and issue_older_<issue>[_<cursor>]
+func parseIssueThreadCallback(data string) (int, string, error) {
+	parts := strings.SplitN(data, "_", 4)
+	if len(parts) < 3 {
+		return 0, "", fmt.Errorf("invalid callback data format")
+	}
+	issueNumber, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid issue number: %w", err)
+	}
+	if len(parts) == 4 {
+		return issueNumber, parts[3], nil
+	}
+	return issueNumber, "", nil
+}
+
+// formatIssueThread renders a page of an issue thread as Telegram HTML
+func formatIssueThread(page *github.IssueCommentPage) string {
+	stateEmoji := "🟢"
+	if page.State == "closed" {
+		stateEmoji = "🟣"
+	}
+	msg := render.New(render.HTML).Text(stateEmoji + " ").Bold(fmt.Sprintf("#%d %s", page.Number, page.Title)).Newline()
+
+	if page.TotalCount == 0 {
+		return msg.Newline().Italic("No comments yet.").String()
+	}
+
+	if page.TotalCount == 1 {
+		msg.Line("💬 1 comment")
+	} else {
+		msg.Line(fmt.Sprintf("💬 %d comments", page.TotalCount))
+	}
+
+	for _, comment := range page.Comments {
+		body, cut := truncateForPreview(strings.TrimSpace(comment.Body), issueThreadCommentLength)
+		if cut {
+			body += "…"
+		}
+		msg.Newline().Bold(comment.Author).Text(" · ").Link(comment.CreatedAt.UTC().Format("2006-01-02 15:04 UTC"), comment.URL).Newline()
+		msg.Line(body)
+	}
+
+	return msg.String()
+}
+
+// issueThreadKeyboard links the issue, offers commenting and pages to older comments
+func issueThreadKeyboard(page *github.IssueCommentPage, before string) tgbotapi.InlineKeyboardMarkup {
+	rows := [][]tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonURL("🔗 Open on GitHub", page.URL),
+			tgbotapi.NewInlineKeyboardButtonData("💬 Comment", fmt.Sprintf("issue_comment_%d", page.Number)),
+		),
+	}
+
+	var navButtons []tgbotapi.InlineKeyboardButton
+	if page.OlderCursor != "" {
+		if data := issueThreadPageCallback(page.Number, page.OlderCursor); data != "" {
+			navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("⬅️ Load older", data))
+		}
+	}
+	if before != "" {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("🔄 Latest", issueThreadPageCallback(page.Number, "")))
+	}
+	if len(navButtons) > 0 {
+		rows = append(rows, navButtons)
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleIssueThread shows the latest comments of an issue (callback data: issue_thread_<issue>) in a
+// new message, keeping the issue list intact, and pages it in place (issue_older_<issue>[_<cursor>])
+func (b *Bot) handleIssueThread(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	issueNumber, before, err := parseIssueThreadCallback(callback.Data)
+	if err != nil {
+		return err
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	page, err := userGitHubProvider.GetIssueComments(issueNumber, issueThreadPageSize, before)
+	if err != nil {
+		logger.Error("Failed to fetch issue comments", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+			"chat_id":      chatID,
+		})
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load comments of issue #%d: %s", issueNumber, html.EscapeString(err.Error())))
+		return nil
+	}
+
+	text := formatIssueThread(page)
+	keyboard := issueThreadKeyboard(page, before)
+
+	if strings.HasPrefix(callback.Data, "issue_older_") {
+		editMsg := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, text)
+		editMsg.ParseMode = "html"
+		editMsg.DisableWebPagePreview = true
+		editMsg.ReplyMarkup = &keyboard
+		if _, err := b.rateLimitedSend(chatID, editMsg); err == nil {
+			return nil
+		}
+	}
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "html"
+	msg.DisableWebPagePreview = true
+	msg.ReplyMarkup = keyboard
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		return fmt.Errorf("failed to send issue thread: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestIssueThreadCallbacks(t *testing.T) {
+	tests := []struct {
+		data   string
+		number int
+		before string
+	}{
+		{"issue_thread_42", 42, ""},
+		{"issue_older_42", 42, ""},
+		{"issue_older_42_Y3Vyc29yOnYyOpHOAAAB", 42, "Y3Vyc29yOnYyOpHOAAAB"},
+	}
+
+	for _, tt := range tests {
+		number, before, err := parseIssueThreadCallback(tt.data)
+		if err != nil || number != tt.number || before != tt.before {
+			t.Errorf("parseIssueThreadCallback(%q) = %d, %q, %v", tt.data, number, before, err)
+		}
+	}
+
+	if _, _, err := parseIssueThreadCallback("issue_thread_abc"); err == nil {
+		t.Error("parseIssueThreadCallback(invalid) expected error")
+	}
+
+	if got := issueThreadPageCallback(42, "Y3Vyc29y"); got != "issue_older_42_Y3Vyc29y" {
+		t.Errorf("issueThreadPageCallback() = %q", got)
+	}
+	if got := issueThreadPageCallback(42, strings.Repeat("a", 60)); got != "" {
+		t.Errorf("issueThreadPageCallback(long cursor) = %q, want empty", got)
+	}
+}
+
+func TestFormatIssueThread(t *testing.T) {
+	page := &github.IssueCommentPage{
+		Number:     7,
+		Title:      "Crash <on> start",
+		State:      "open",
+		TotalCount: 2,
+		Comments: []github.IssueComment{
+			{Author: "alice", Body: "Seeing this & more", CreatedAt: time.Date(2026, 10, 1, 14, 3, 0, 0, time.UTC)},
+			{Author: "bob", Body: strings.Repeat("x", issueThreadCommentLength+10)},
+		},
+	}
+
+	text := formatIssueThread(page)
+	for _, want := range []string{"#7 Crash &lt;on&gt; start", "💬 2 comments", "<b>alice</b>", "2026-10-01 14:03 UTC", "Seeing this &amp; more", "x…"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("formatIssueThread() missing %q in:\n%s", want, text)
+		}
+	}
+
+	if text := formatIssueThread(&github.IssueCommentPage{Number: 7, State: "closed"}); !strings.Contains(text, "No comments yet") || !strings.HasPrefix(text, "🟣") {
+		t.Errorf("formatIssueThread(no comments) = %q", text)
+	}
+}
@@ -0,0 +1,223 @@
+package telegram
+
+import (
+	"crypto/rand"
+	"fmt"
+	"html"
+	"math/big"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Leaderboard: an opt-in community ranking by total commits and current daily streak, taken from
+// user_insights. Nobody is listed without consent given through the confirmation button of
+// /leaderboard join, and members appear under a random pseudonym, never their Telegram name.
+// /leaderboard leave withdraws consent and removes the user at once.
+
+const (
+	leaderboardSize     = 10
+	leaderboardCacheKey = "leaderboard"
+	leaderboardCacheTTL = 5 * time.Minute
+)
+
+// Words of leaderboard pseudonyms
+var (
+	pseudonymAdjectives = []string{
+		"Amber", "Brave", "Calm", "Clever", "Curious", "Gentle", "Golden", "Happy", "Humble", "Lucky",
+		"Mellow", "Nimble", "Quiet", "Rapid", "Silver", "Steady", "Sunny", "Swift", "Tidy", "Witty",
+	}
+	pseudonymAnimals = []string{
+		"Badger", "Beaver", "Crane", "Dolphin", "Falcon", "Fox", "Heron", "Koala", "Lynx", "Marten",
+		"Otter", "Owl", "Panda", "Puffin", "Raven", "Robin", "Seal", "Sparrow", "Tiger", "Wren",
+	}
+)
+
+// leaderboardConsentText explains what joining shares
+const leaderboardConsentText = `🏆 <b>Join the community leaderboard?</b>
+
+If you agree, these are shown to every user of the bot:
+• A random pseudonym, e.g. <i>Quiet Otter 42</i>, never your name or username
+• Your total number of commits
+• Your current streak of days with commits
+
+Nothing else is shared. You can leave at any time with <code>/leaderboard leave</code>.`
+
+// handleLeaderboardCommand shows the leaderboard, or joins or leaves it
+func (b *Bot) handleLeaderboardCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ The leaderboard requires a database.")
+		return nil
+	}
+
+	switch strings.TrimSpace(message.CommandArguments()) {
+	case "":
+		return b.showLeaderboard(chatID)
+
+	case "join":
+		consent, err := b.db.GetLeaderboardConsent(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load the leaderboard.")
+			return nil
+		}
+		if consent != nil {
+			b.sendResponse(chatID, fmt.Sprintf("🏆 You're on the leaderboard as <b>%s</b>.", html.EscapeString(consent.DisplayName)))
+			return nil
+		}
+
+		msg := tgbotapi.NewMessage(chatID, leaderboardConsentText)
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ I agree, join", "leaderboard_consent"),
+			tgbotapi.NewInlineKeyboardButtonData("❌ No thanks", "leaderboard_decline"),
+		))
+		if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+			return fmt.Errorf("failed to send leaderboard consent: %w", err)
+		}
+		return nil
+
+	case "leave":
+		left, err := b.db.LeaveLeaderboard(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to leave the leaderboard.")
+			return nil
+		}
+		if !left {
+			b.sendResponse(chatID, "🏆 You're not on the leaderboard.")
+			return nil
+		}
+		b.cache.Delete(leaderboardCacheKey)
+		logger.Info("User left the leaderboard", map[string]interface{}{
+			"chat_id": chatID,
+		})
+		b.sendResponse(chatID, "👋 You left the leaderboard and are no longer listed.")
+		return nil
+
+	default:
+		b.sendResponse(chatID, "Usage: <code>/leaderboard</code>, <code>/leaderboard join</code> or <code>/leaderboard leave</code>")
+		return nil
+	}
+}
+
+// handleLeaderboardCallback records or declines consent from the join confirmation
+func (b *Bot) handleLeaderboardCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	if callback.Data != "leaderboard_consent" {
+		b.editMessage(chatID, messageID, "👍 Not joined. You're not listed on the leaderboard.")
+		return nil
+	}
+
+	joined, err := b.db.JoinLeaderboard(chatID, newPseudonym())
+	if err != nil {
+		b.editMessage(chatID, messageID, "❌ Failed to join the leaderboard.")
+		return nil
+	}
+	consent, err := b.db.GetLeaderboardConsent(chatID)
+	if err != nil || consent == nil {
+		b.editMessage(chatID, messageID, "❌ Failed to join the leaderboard.")
+		return nil
+	}
+
+	if joined {
+		b.cache.Delete(leaderboardCacheKey)
+		logger.Info("User joined the leaderboard", map[string]interface{}{
+			"chat_id": chatID,
+		})
+	}
+	b.editMessage(chatID, messageID, fmt.Sprintf("✅ You joined the leaderboard as %s. Use /leaderboard to see it and /leaderboard leave to leave.", consent.DisplayName))
+	return nil
+}
+
+// showLeaderboard sends both rankings and the user's own standing
+func (b *Bot) showLeaderboard(chatID int64) error {
+	board, ok := b.cache.Get(leaderboardCacheKey)
+	if !ok {
+		byCommits, err := b.db.GetLeaderboard(database.LeaderboardByCommits, leaderboardSize)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load the leaderboard.")
+			return nil
+		}
+		byStreak, err := b.db.GetLeaderboard(database.LeaderboardByStreak, leaderboardSize)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load the leaderboard.")
+			return nil
+		}
+		board = formatLeaderboard(byCommits, byStreak)
+		b.cache.SetWithExpiry(leaderboardCacheKey, board, leaderboardCacheTTL)
+	}
+
+	text := board.(string)
+	entry, err := b.db.GetLeaderboardEntry(chatID)
+	switch {
+	case err != nil:
+	case entry == nil:
+		text += "\n\nYou're not listed. Join with <code>/leaderboard join</code>, it's opt-in and uses a pseudonym."
+	default:
+		text += fmt.Sprintf("\n\n<b>You</b> (%s): %d commits · %s", html.EscapeString(entry.DisplayName), entry.Commits, formatStreak(entry.Streak))
+	}
+
+	b.sendResponse(chatID, text)
+	return nil
+}
+
+// formatLeaderboard renders the commit and streak rankings
+func formatLeaderboard(byCommits, byStreak []*database.LeaderboardEntry) string {
+	if len(byCommits) == 0 {
+		return "🏆 <b>Community Leaderboard</b>\n\nNobody has joined yet."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🏆 <b>Community Leaderboard</b>\n\n<b>📝 Most commits</b>\n")
+	for i, entry := range byCommits {
+		sb.WriteString(fmt.Sprintf("%s %s · %d\n", leaderboardRank(i), html.EscapeString(entry.DisplayName), entry.Commits))
+	}
+
+	sb.WriteString("\n<b>🔥 Longest current streak</b>\n")
+	listed := 0
+	for _, entry := range byStreak {
+		if entry.Streak == 0 {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("%s %s · %s\n", leaderboardRank(listed), html.EscapeString(entry.DisplayName), formatStreak(entry.Streak)))
+		listed++
+	}
+	if listed == 0 {
+		sb.WriteString("<i>No active streaks</i>\n")
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// leaderboardRank returns a medal for the first three places and the place number after
+func leaderboardRank(i int) string {
+	if medals := []string{"🥇", "🥈", "🥉"}; i < len(medals) {
+		return medals[i]
+	}
+	return fmt.Sprintf("%d.", i+1)
+}
+
+// formatStreak formats a streak length in days
+func formatStreak(days int) string {
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// newPseudonym returns a random leaderboard name like "Quiet Otter 42"
+func newPseudonym() string {
+	pick := func(n int) int {
+		i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+		if err != nil {
+			return int(time.Now().UnixNano() % int64(n))
+		}
+		return int(i.Int64())
+	}
+	return fmt.Sprintf("%s %s %d", pseudonymAdjectives[pick(len(pseudonymAdjectives))], pseudonymAnimals[pick(len(pseudonymAnimals))], 10+pick(90))
+}
@@ -0,0 +1,55 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestFormatLeaderboard(t *testing.T) {
+	if got := formatLeaderboard(nil, nil); !strings.Contains(got, "Nobody has joined yet") {
+		t.Errorf("formatLeaderboard(empty) = %q", got)
+	}
+
+	byCommits := []*database.LeaderboardEntry{
+		{DisplayName: "Quiet Otter 42", Commits: 120, Streak: 0},
+		{DisplayName: "Swift Fox 17", Commits: 80, Streak: 5},
+		{DisplayName: "Calm <Owl> 11", Commits: 3, Streak: 1},
+		{DisplayName: "Brave Seal 30", Commits: 2, Streak: 0},
+	}
+	byStreak := []*database.LeaderboardEntry{byCommits[1], byCommits[2], byCommits[0], byCommits[3]}
+
+	got := formatLeaderboard(byCommits, byStreak)
+	for _, want := range []string{
+		"🥇 Quiet Otter 42 · 120",
+		"🥉 Calm &lt;Owl&gt; 11 · 3",
+		"4. Brave Seal 30 · 2",
+		"🥇 Swift Fox 17 · 5 days",
+		"🥈 Calm &lt;Owl&gt; 11 · 1 day",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("formatLeaderboard() = %q, want it to contain %q", got, want)
+		}
+	}
+	if strings.Contains(got, "Quiet Otter 42 · 0 days") {
+		t.Errorf("formatLeaderboard() lists a user without a streak: %q", got)
+	}
+
+	noStreaks := formatLeaderboard(byCommits[:1], byCommits[:1])
+	if !strings.Contains(noStreaks, "No active streaks") {
+		t.Errorf("formatLeaderboard() without streaks = %q", noStreaks)
+	}
+}
+
+func TestNewPseudonym(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		parts := strings.Fields(newPseudonym())
+		if len(parts) != 3 {
+			t.Fatalf("newPseudonym() = %q, want adjective, animal and number", strings.Join(parts, " "))
+		}
+		if len(parts[2]) != 2 {
+			t.Errorf("newPseudonym() number = %q, want two digits", parts[2])
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// GitHub API budgets of the chat's token (/limits): REST requests and GraphQL points
+
+func (b *Bot) handleLimitsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+	reader, ok := userGitHubProvider.(github.RateLimitReader)
+	if !ok {
+		b.sendResponse(chatID, "❌ Rate limits aren't available for your repository.")
+		return nil
+	}
+
+	limits, err := reader.RateLimits()
+	if err != nil {
+		logger.Warn("Failed to get GitHub rate limits", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get your GitHub rate limits: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	b.sendResponse(chatID, formatRateLimits(limits, time.Now()))
+	return nil
+}
+
+// formatRateLimits renders the /limits message
+func formatRateLimits(limits *github.RateLimits, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("🚦 <b>GitHub API Limits</b>\n\n")
+	sb.WriteString(fmt.Sprintf("<b>REST:</b> %d/%d requests left, %s\n", limits.Core.Remaining, limits.Core.Limit, formatRateLimitReset(limits.Core.Reset, now)))
+	sb.WriteString(fmt.Sprintf("<b>GraphQL:</b> %d/%d points left, %s\n", limits.GraphQL.Remaining, limits.GraphQL.Limit, formatRateLimitReset(limits.GraphQL.Reset, now)))
+	if limits.LastGraphQLCost > 0 {
+		unit := "points"
+		if limits.LastGraphQLCost == 1 {
+			unit = "point"
+		}
+		sb.WriteString(fmt.Sprintf("Your last GraphQL query cost %d %s.\n", limits.LastGraphQLCost, unit))
+	}
+	sb.WriteString("\n<i>/sync looks up issues with GraphQL and switches to REST before your GraphQL points run out.</i>")
+	return sb.String()
+}
+
+// formatRateLimitReset describes when a budget resets, e.g. "resets in 42m"
+func formatRateLimitReset(reset, now time.Time) string {
+	wait := reset.Sub(now)
+	if wait < time.Minute {
+		return "resets in under a minute"
+	}
+	return "resets in " + strings.TrimSuffix(wait.Truncate(time.Minute).String(), "0s")
+}
@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestFormatRateLimits(t *testing.T) {
+	now := time.Date(2026, 10, 15, 9, 0, 0, 0, time.UTC)
+	limits := &github.RateLimits{
+		Core:            github.RateLimit{Limit: 5000, Remaining: 4990, Reset: now.Add(42*time.Minute + 30*time.Second)},
+		GraphQL:         github.RateLimit{Limit: 5000, Remaining: 120, Reset: now.Add(20 * time.Second)},
+		LastGraphQLCost: 25,
+	}
+
+	text := formatRateLimits(limits, now)
+	for _, want := range []string{
+		"<b>REST:</b> 4990/5000 requests left, resets in 42m",
+		"<b>GraphQL:</b> 120/5000 points left, resets in under a minute",
+		"cost 25 points",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in %q", want, text)
+		}
+	}
+
+	limits.LastGraphQLCost = 0
+	limits.Core.Reset = now.Add(time.Hour + 5*time.Minute)
+	text = formatRateLimits(limits, now)
+	if strings.Contains(text, "cost") || !strings.Contains(text, "resets in 1h5m") {
+		t.Errorf("formatRateLimits(unknown cost) = %q", text)
+	}
+}
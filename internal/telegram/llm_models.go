@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Models per task: users with a personal LLM token choose models for each LLM task with /models,
+// e.g. a cheap model for titles and hashtags and a stronger one for summaries. The shared LLM uses
+// the operator's llm.task_models instead.
+
+// llmTaskDescriptions describes the LLM tasks for /models
+var llmTaskDescriptions = map[string]string{
+	config.LLMTaskTagging:    "titles and hashtags",
+	config.LLMTaskSummary:    "digests and reviews",
+	config.LLMTaskMultimodal: "image analysis",
+}
+
+// userTaskModels returns the models per task of the user's personal LLM, nil if none are set
+func userTaskModels(user *database.User) map[string][]string {
+	if user.LLMTaskModels == "" {
+		return nil
+	}
+
+	taskModels, err := config.ParseTaskModels(user.LLMTaskModels)
+	if err != nil {
+		logger.Warn("Ignoring invalid LLM task models", map[string]interface{}{
+			"chat_id": user.ChatId,
+			"error":   err.Error(),
+		})
+		return nil
+	}
+	return taskModels
+}
+
+// formatTaskModels lists the models of every task, defaultModel for tasks without their own
+func formatTaskModels(taskModels map[string][]string, defaultModel string) string {
+	var sb strings.Builder
+	for _, task := range config.LLMTasks {
+		models := taskModels[task]
+		value := "<i>default</i>"
+		if defaultModel != "" {
+			value = fmt.Sprintf("<code>%s</code> <i>(default)</i>", html.EscapeString(defaultModel))
+		}
+		if len(models) > 0 {
+			value = fmt.Sprintf("<code>%s</code>", html.EscapeString(strings.Join(models, " → ")))
+		}
+		sb.WriteString(fmt.Sprintf("• <b>%s</b> (%s): %s\n", task, llmTaskDescriptions[task], value))
+	}
+	return sb.String()
+}
+
+// handleModelsCommand shows or sets the models per LLM task:
+// /models, /models <task> <model>[,<fallback>...], /models <task> default
+func (b *Bot) handleModelsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/models"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Models per task require a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if len(args) == 0 {
+		if !user.HasLLMConfig() {
+			b.sendResponse(chatID, fmt.Sprintf("🧠 <b>Models per task</b> (shared LLM)\n\n%s\nSet a personal LLM token with /llm to choose your own models.",
+				formatTaskModels(b.cfg().LLMTaskModels, b.cfg().LLMModel)))
+			return nil
+		}
+		_, _, model := b.parseLLMToken(user.LLMToken)
+		b.sendResponse(chatID, fmt.Sprintf("🧠 <b>Models per task</b>\n\n%s\nUse <code>/models &lt;task&gt; &lt;model&gt;[,fallback]</code> to choose models, <code>/models &lt;task&gt; default</code> to use your default model again.",
+			formatTaskModels(userTaskModels(user), model)))
+		return nil
+	}
+
+	if len(args) < 2 {
+		b.sendResponse(chatID, "Usage: <code>/models &lt;task&gt; &lt;model&gt;[,fallback]</code> or <code>/models &lt;task&gt; default</code>")
+		return nil
+	}
+
+	if !user.HasLLMConfig() {
+		b.sendResponse(chatID, "❌ Choosing models requires a personal LLM token, set one with /llm.")
+		return nil
+	}
+
+	task := strings.ToLower(args[0])
+	if _, ok := llmTaskDescriptions[task]; !ok {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Unknown task. Use one of: %s", strings.Join(config.LLMTasks, ", ")))
+		return nil
+	}
+
+	taskModels := userTaskModels(user)
+	if taskModels == nil {
+		taskModels = make(map[string][]string)
+	}
+
+	if strings.ToLower(args[1]) == "default" {
+		delete(taskModels, task)
+	} else {
+		parsed, err := config.ParseTaskModels(task + "=" + strings.Join(args[1:], ","))
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		taskModels[task] = parsed[task]
+	}
+
+	if err := b.db.UpdateUserLLMTaskModels(chatID, config.FormatTaskModels(taskModels)); err != nil {
+		b.sendResponse(chatID, "❌ Failed to save the models.")
+		return nil
+	}
+
+	if models := taskModels[task]; len(models) > 0 {
+		b.sendResponse(chatID, fmt.Sprintf("🧠 %s now uses <code>%s</code>, falling back to your default model.", task, html.EscapeString(strings.Join(models, " → "))))
+	} else {
+		b.sendResponse(chatID, fmt.Sprintf("🧠 %s uses your default model again.", task))
+	}
+	return nil
+}
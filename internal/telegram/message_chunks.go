@@ -0,0 +1,209 @@
+package telegram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Long replies: HTML text beyond Telegram's 4096 character limit is split into numbered parts on
+// paragraph, line or word boundaries, keeping formatting tags balanced in every part
+
+const (
+	telegramMessageLimit = 4096
+	messagePartLimit     = telegramMessageLimit - 96 // Room for the part number and closing tags
+	maxMessageParts      = 5                         // Longer replies are cut, see longReply.Document
+)
+
+var htmlTagRegex = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)[^>]*>`)
+
+// longReply is an HTML reply that may not fit into a single Telegram message
+type longReply struct {
+	Text            string
+	EditMessageID   int    // Replaces this message with the first part, 0 sends all parts
+	Document        string // Attached after the text when set, e.g. the full file behind a preview
+	DocumentName    string
+	DocumentCaption string
+}
+
+// telegramLength returns the length Telegram counts for text, in UTF-16 code units. Tags are
+// counted too, which keeps HTML text on the safe side of the limit.
+func telegramLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// htmlTag is an open formatting tag carried over into the next part
+type htmlTag struct {
+	name    string
+	opening string
+}
+
+// openHTMLTags returns the tags left open at the end of text, outermost first
+func openHTMLTags(text string) []htmlTag {
+	var open []htmlTag
+	for _, match := range htmlTagRegex.FindAllStringSubmatch(text, -1) {
+		name := strings.ToLower(match[2])
+		if match[1] == "" {
+			open = append(open, htmlTag{name: name, opening: match[0]})
+			continue
+		}
+		for i := len(open) - 1; i >= 0; i-- {
+			if open[i].name == name {
+				open = open[:i]
+				break
+			}
+		}
+	}
+	return open
+}
+
+// htmlCutPoint picks where to split text so the first part stays within limit, preferring
+// paragraph, line and word boundaries and never cutting through a tag or an entity.
+// It returns the cut position and the length of the separator dropped there.
+func htmlCutPoint(text string, limit int) (int, int) {
+	maxCut, length := 0, 0
+	for i, r := range text {
+		width := 1
+		if r >= 0x10000 {
+			width = 2
+		}
+		if length+width > limit {
+			break
+		}
+		length += width
+		maxCut = i + len(string(r))
+	}
+	if maxCut == 0 && text != "" {
+		// Always make progress, even if not a single character fits
+		_, size := utf8.DecodeRuneInString(text)
+		return size, 0
+	}
+
+	cut, skip := maxCut, 0
+	for _, separator := range []string{"\n\n", "\n", " "} {
+		if idx := strings.LastIndex(text[:maxCut], separator); idx > maxCut/2 {
+			cut, skip = idx, len(separator)
+			break
+		}
+	}
+
+	if lt := strings.LastIndex(text[:cut], "<"); lt > strings.LastIndex(text[:cut], ">") && lt > 0 {
+		cut, skip = lt, 0
+	}
+	if amp := strings.LastIndex(text[:cut], "&"); amp > strings.LastIndex(text[:cut], ";") && cut-amp <= 10 && amp > 0 {
+		cut, skip = amp, 0
+	}
+	if cut == 0 {
+		cut, skip = maxCut, 0
+	}
+	return cut, skip
+}
+
+// splitHTMLMessage splits HTML text into parts of at most limit characters, closing the tags
+// open at the end of a part and reopening them at the start of the next one
+func splitHTMLMessage(text string, limit int) []string {
+	var parts []string
+	var carried []htmlTag
+
+	rest := text
+	for {
+		var prefix strings.Builder
+		for _, tag := range carried {
+			prefix.WriteString(tag.opening)
+		}
+
+		if telegramLength(prefix.String()+rest) <= limit {
+			return append(parts, prefix.String()+rest)
+		}
+
+		// Leave room for the closing tags, tags rarely nest more than a couple of levels
+		budget := limit - telegramLength(prefix.String()) - 32
+		cut, skip := htmlCutPoint(rest, budget)
+
+		part := prefix.String() + rest[:cut]
+		carried = openHTMLTags(part)
+		for i := len(carried) - 1; i >= 0; i-- {
+			part += "</" + carried[i].name + ">"
+		}
+		parts = append(parts, part)
+		rest = rest[cut+skip:]
+	}
+}
+
+// numberMessageParts appends "(i/n)" to every part of a split reply
+func numberMessageParts(parts []string) []string {
+	if len(parts) < 2 {
+		return parts
+	}
+	numbered := make([]string, len(parts))
+	for i, part := range parts {
+		numbered[i] = fmt.Sprintf("%s\n<i>(%d/%d)</i>", part, i+1, len(parts))
+	}
+	return numbered
+}
+
+// chunkReplyText splits a reply into the numbered parts to send, at most maxMessageParts
+func chunkReplyText(text string, hasDocument bool) []string {
+	parts := splitHTMLMessage(text, messagePartLimit)
+	if len(parts) > maxMessageParts {
+		parts = parts[:maxMessageParts]
+		if hasDocument {
+			parts[len(parts)-1] += "\n<i>✂️ Too long for Telegram, the full content is attached below.</i>"
+		} else {
+			parts[len(parts)-1] += "\n<i>✂️ Too long for Telegram, the rest was cut.</i>"
+		}
+	}
+	return numberMessageParts(parts)
+}
+
+// sendLongReply sends an HTML reply in as many messages as it needs, then its document if any
+func (b *Bot) sendLongReply(chatID int64, reply longReply) error {
+	parts := chunkReplyText(reply.Text, reply.Document != "")
+
+	for i, part := range parts {
+		if i == 0 && reply.EditMessageID != 0 {
+			edit := tgbotapi.NewEditMessageText(chatID, reply.EditMessageID, part)
+			edit.ParseMode = consts.ParseModeHTML
+			edit.DisableWebPagePreview = true
+			if _, err := b.rateLimitedSend(chatID, edit); err == nil {
+				continue
+			}
+		}
+
+		msg := tgbotapi.NewMessage(chatID, part)
+		msg.ParseMode = consts.ParseModeHTML
+		msg.DisableWebPagePreview = true
+		if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+			logger.Error("Failed to send reply part", map[string]interface{}{
+				"chat_id": chatID,
+				"part":    i + 1,
+				"parts":   len(parts),
+				"error":   err.Error(),
+			})
+			return fmt.Errorf("failed to send reply part %d/%d: %w", i+1, len(parts), err)
+		}
+	}
+
+	if reply.Document != "" {
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{
+			Name:  reply.DocumentName,
+			Bytes: []byte(reply.Document),
+		})
+		doc.Caption = reply.DocumentCaption
+		if _, err := b.rateLimitedSend(chatID, doc); err != nil {
+			logger.Error("Failed to send reply document", map[string]interface{}{
+				"chat_id": chatID,
+				"name":    reply.DocumentName,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return nil
+}
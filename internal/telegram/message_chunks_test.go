@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitHTMLMessageShort(t *testing.T) {
+	parts := splitHTMLMessage("<b>Hello</b> world", 100)
+	if len(parts) != 1 || parts[0] != "<b>Hello</b> world" {
+		t.Errorf("splitHTMLMessage(short) = %q", parts)
+	}
+}
+
+func TestSplitHTMLMessageBoundaries(t *testing.T) {
+	paragraph := strings.Repeat("word ", 15) // 75 characters
+	text := paragraph + "\n\n" + paragraph + "\n\n" + paragraph
+
+	parts := splitHTMLMessage(text, 120)
+	if len(parts) != 3 {
+		t.Fatalf("splitHTMLMessage() = %d parts, want 3: %q", len(parts), parts)
+	}
+	for _, part := range parts {
+		if part != paragraph {
+			t.Errorf("part = %q, want a whole paragraph", part)
+		}
+	}
+}
+
+func TestSplitHTMLMessageKeepsTagsBalanced(t *testing.T) {
+	line := "x := 1 &amp;&amp; y &lt; 2\n"
+	text := `📄 <b>main.go</b>` + "\n" + `<pre><code class="language-go">` + strings.Repeat(line, 40) + `</code></pre>`
+
+	parts := splitHTMLMessage(text, 200)
+	if len(parts) < 2 {
+		t.Fatalf("splitHTMLMessage() = %d parts, want several", len(parts))
+	}
+
+	var content strings.Builder
+	for i, part := range parts {
+		if telegramLength(part) > 200 {
+			t.Errorf("part %d is %d characters long", i, telegramLength(part))
+		}
+		if open := openHTMLTags(part); len(open) != 0 {
+			t.Errorf("part %d leaves %v open: %q", i, open, part)
+		}
+		if i > 0 && !strings.HasPrefix(part, `<pre><code class="language-go">`) {
+			t.Errorf("part %d doesn't reopen the code block: %q", i, part)
+		}
+		if strings.Count(part, "&") != strings.Count(part, ";") {
+			t.Errorf("part %d cuts through an entity: %q", i, part)
+		}
+		content.WriteString(part)
+	}
+
+	if got := strings.Count(content.String(), "x := 1"); got != 40 {
+		t.Errorf("split lost lines: %d of 40 left", got)
+	}
+}
+
+func TestSplitHTMLMessageLongWord(t *testing.T) {
+	text := strings.Repeat("🙂", 100)     // 200 UTF-16 code units without any boundary
+	parts := splitHTMLMessage(text, 132) // 100 after the room kept for closing tags
+	if len(parts) != 2 || parts[0] != strings.Repeat("🙂", 50) {
+		t.Errorf("splitHTMLMessage(emoji) = %q", parts)
+	}
+	if strings.Join(parts, "") != text {
+		t.Error("splitHTMLMessage(emoji) changed the text")
+	}
+}
+
+func TestChunkReplyText(t *testing.T) {
+	if parts := chunkReplyText("short", false); len(parts) != 1 || parts[0] != "short" {
+		t.Errorf("chunkReplyText(short) = %q", parts)
+	}
+
+	text := strings.Repeat(strings.Repeat("a", 99)+"\n", 100) // About 2.5 messages
+	parts := chunkReplyText(text, false)
+	if len(parts) != 3 || !strings.HasSuffix(parts[0], "<i>(1/3)</i>") || !strings.HasSuffix(parts[2], "<i>(3/3)</i>") {
+		t.Errorf("chunkReplyText() = %d parts", len(parts))
+	}
+	for _, part := range parts {
+		if telegramLength(part) > telegramMessageLimit {
+			t.Errorf("part is %d characters long", telegramLength(part))
+		}
+	}
+
+	huge := strings.Repeat(text, 4)
+	parts = chunkReplyText(huge, true)
+	if len(parts) != maxMessageParts || !strings.Contains(parts[maxMessageParts-1], "attached below") {
+		t.Errorf("chunkReplyText(huge) = %d parts", len(parts))
+	}
+}
@@ -55,6 +55,18 @@ You've used <b>%d/%d images</b> on the %s tier.%s
 Use /coffee to upgrade your plan for higher limits!
 
 <i>Note: You can still save text messages and read existing content</i>`
+
+	// File attachment limit messages
+	FileLimitReachedTemplate = `📎 <b>File attachment limit reached</b>
+
+You've used <b>%d/%d files</b> on the %s tier.
+
+Use /coffee to upgrade your plan for higher limits!
+
+<i>Note: You can still save text messages and read existing content</i>`
+	FileTooLargeTemplate = `📎 <b>File too large</b>
+
+<b>%s</b> is %s, the %s tier attaches files up to <b>%s</b>.%s`
 	
 	// Tier upgrade hints with specific benefits
 	TierUpgradeHintTemplate = "\n\n⚠️ You've reached the %s tier limit (%d %s). Use /coffee to upgrade and get up to %d %s!"
@@ -80,6 +92,64 @@ Note: You can still save text messages and read existing content`
 	
 	// Repository setup error with upgrade hint
 	RepoSetupUpgradeHint = "\n💡 <i>Upgrade with /coffee for more space!</i>"
+
+	// Premium trial and tier transition messages
+	TrialStartedTemplate = `🎁 <b>Your %s trial has started!</b>
+
+All %s limits are unlocked until <b>%s</b>.
+When the trial ends your notes stay safe, only the higher limits go away.`
+
+	TrialUnavailableMessage = "❌ The free trial is only available once, to users without active premium."
+
+	TierEndingTemplate = `⏳ <b>Your %s %s ends %s</b>
+
+Your repository and notes stay untouched. After that, free tier limits apply again.
+Renew now to keep your higher limits.`
+
+	TierExpiredTemplate = `📉 <b>Your %s %s has ended</b>
+
+You're back on the free tier. Nothing has been deleted.%s`
+
+	TierExpiredReadOnlyTemplate = `
+
+🔒 Your repository (%.1f MB) is larger than the free limit (%.1f MB), so it is now <b>read-only</b>:
+• /cat, /ls, /todo and /issue keep working
+• New saves are paused until you free space or renew`
+
+	TierLapsedNotice = "\n\n📉 <i>Your %s tier has ended, which lowered your limits. Renew to continue saving.</i>"
+
+	// Self-hosted deployments without payments
+	PaymentsDisabledTemplate = `💳 <b>Payments are disabled on this deployment</b>
+
+Your plan: <b>%s</b>
+Premium levels are managed by the operator of this bot.`
+
+	// Deployments with zero content retention
+	RetentionDisabledMessage = `🔒 <b>Not available on this deployment</b>
+
+This bot is operated with zero content retention: your messages go straight to GitHub and are never stored on the bot's server, so features that keep content on the server are turned off.`
+
+	// Admin DM about an operation over its watchdog threshold
+	SlowOperationAlertTemplate = `🐢 <b>Slow %s</b>
+
+<code>%s</code> took <b>%s</b> (threshold %s)
+Chat: <code>%d</code> · Correlation ID: <code>%s</code>
+
+<i>See /admin slow for all slow operations</i>`
+
+	// Offer to follow a renamed or transferred repository
+	RepoMovedTemplate = `📦 <b>Your repository has moved</b>
+
+<code>%s</code> is now <code>%s</code> on GitHub, so saving to the stored URL fails.
+
+Update the stored URL to keep saving notes there, your local copy moves along.`
+
+	// Alert about unexpected use of the user's token, see access_anomalies.go
+	AccessAnomalyTemplate = `🚨 <b>Unusual activity, operations paused</b>
+
+%s.
+
+Saving and other GitHub operations are paused for up to %d hours. If this was you, resume below. If not, revoke your token on GitHub and set a new one with /repo.`
 )
 
 // Tier names for consistent display
@@ -0,0 +1,36 @@
+package telegram
+
+import (
+	"github.com/msg2git/msg2git/internal/llm"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Moderation: when the operator configures moderation, content is checked before it goes
+// through the shared LLM token. Flagged notes are still committed, only without LLM titles and
+// hashtags. Users with a personal LLM token are not checked.
+
+// moderationDeclines reports whether text must not be sent to the shared LLM
+func (b *Bot) moderationDeclines(chatID int64, text string) bool {
+	// Built per call so reloaded settings apply right away
+	moderator := llm.NewModerator(b.cfg())
+	if moderator == nil {
+		return false
+	}
+
+	result, err := moderator.Check(text)
+	if err != nil {
+		logger.Warn("Moderation check failed, skipping shared LLM", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return true
+	}
+
+	if result.Flagged {
+		logger.Info("Content flagged by moderation, skipping shared LLM", map[string]interface{}{
+			"chat_id": chatID,
+			"reason":  result.Reason,
+		})
+	}
+	return result.Flagged
+}
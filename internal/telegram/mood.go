@@ -0,0 +1,181 @@
+package telegram
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/llm"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Mood tracking: with /mood on, the LLM rates the mood of every note on the llm.Moods scale. The
+// mood goes into the note's metadata comment and is counted as an activity event, which /insight
+// turns into a chart of the current month.
+
+const (
+	moodRetention   = 35 * 24 * time.Hour // Mood events are kept for the monthly chart
+	moodChartLength = 10
+)
+
+// noteMoodMeta returns the mood metadata line of a note's content, nothing if the chat doesn't
+// track moods or the mood couldn't be detected
+func (b *Bot) noteMoodMeta(chatID int64, content string) []string {
+	if b.db == nil || strings.TrimSpace(content) == "" {
+		return nil
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil || !user.MoodTracking {
+		return nil
+	}
+
+	userLLMClient, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(chatID, content)
+	if userLLMClient == nil {
+		return nil
+	}
+
+	mood, usage, err := userLLMClient.DetectMood(content)
+	if usage != nil {
+		if isUsingDefaultLLM {
+			err = b.db.IncrementTokenUsageAll(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens))
+		} else {
+			err = b.db.IncrementTokenUsageInsights(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens))
+		}
+		if err != nil {
+			logger.Warn("Failed to record token usage for mood", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+	if mood.Name == "" {
+		logger.Warn("Mood detection failed, saving note without mood", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   fmt.Sprint(err),
+		})
+		return nil
+	}
+
+	if err := b.db.RecordActivity(chatID, database.ActivityMood, mood.Name); err != nil {
+		logger.Warn("Failed to record mood", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+
+	return []string{entry.MoodMeta(mood.Emoji, mood.Name)}
+}
+
+// formatMoodChart renders mood counts as one bar per mood, best first, with the average mood.
+// Returns "" without any moods.
+func formatMoodChart(counts map[string]int) string {
+	total, scoreSum, maxCount := 0, 0, 0
+	for _, mood := range llm.Moods {
+		count := counts[mood.Name]
+		total += count
+		scoreSum += count * mood.Score
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for i := len(llm.Moods) - 1; i >= 0; i-- {
+		mood := llm.Moods[i]
+		count := counts[mood.Name]
+		filled := (count*moodChartLength + maxCount - 1) / maxCount
+		sb.WriteString(fmt.Sprintf("%s <code>%s%s</code> %d\n", mood.Emoji,
+			strings.Repeat("█", filled), strings.Repeat("░", moodChartLength-filled), count))
+	}
+
+	// The step nearest to the average score
+	averageScore := float64(scoreSum) / float64(total)
+	average := llm.Moods[0]
+	for _, mood := range llm.Moods[1:] {
+		if math.Abs(float64(mood.Score)-averageScore) < math.Abs(float64(average.Score)-averageScore) {
+			average = mood
+		}
+	}
+	sb.WriteString(fmt.Sprintf("<i>Average: %s %s over %d notes</i>", average.Emoji, average.Name, total))
+
+	return sb.String()
+}
+
+// moodChartSection returns the /insight section with this month's moods, "" if the chat doesn't
+// track moods
+func (b *Bot) moodChartSection(chatID int64, now time.Time) string {
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil || !user.MoodTracking {
+		return ""
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	counts, err := b.db.CountActivity(chatID, database.ActivityMood, monthStart, now.Add(time.Second))
+	if err != nil {
+		logger.Warn("Failed to count moods for insights", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return ""
+	}
+
+	chart := formatMoodChart(counts)
+	if chart == "" {
+		chart = "<i>No moods yet this month</i>"
+	}
+	return fmt.Sprintf("<b>🎭 Mood in %s:</b>\n%s", now.Format("January"), chart)
+}
+
+// handleMoodCommand shows or switches mood tracking
+func (b *Bot) handleMoodCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/mood")))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Mood tracking requires a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch args {
+	case "":
+		if user.MoodTracking {
+			b.sendResponse(chatID, "🎭 Mood tracking is on. Notes are tagged with their mood and /insight shows a monthly mood chart.\n\nUse <code>/mood off</code> to stop.")
+			return nil
+		}
+		b.sendResponse(chatID, "🎭 Mood tracking is off.\n\nUse <code>/mood on</code> to tag notes with a mood detected by the LLM and see a monthly mood chart in /insight.")
+		return nil
+	case "on", "off":
+		enabled := args == "on"
+		if err := b.db.UpdateUserMoodTracking(chatID, enabled); err != nil {
+			b.sendResponse(chatID, "❌ Failed to update mood tracking.")
+			return nil
+		}
+		if !enabled {
+			b.sendResponse(chatID, "🎭 Mood tracking disabled.")
+			return nil
+		}
+		response := "🎭 Mood tracking enabled. New notes are tagged with their mood, which uses a few LLM tokens per note."
+		if !user.LLMSwitch {
+			response += "\n\n⚠️ LLM processing is off, turn it on with /llm for moods to be detected."
+		}
+		b.sendResponse(chatID, response)
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/mood</code>, <code>/mood on</code> or <code>/mood off</code>")
+		return nil
+	}
+}
@@ -0,0 +1,39 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatMoodChart(t *testing.T) {
+	if got := formatMoodChart(map[string]int{}); got != "" {
+		t.Errorf("formatMoodChart(empty) = %q, want empty", got)
+	}
+
+	got := formatMoodChart(map[string]int{"great": 2, "good": 4, "bad": 1, "unknown": 9})
+	lines := strings.Split(got, "\n")
+	if len(lines) != 6 {
+		t.Fatalf("formatMoodChart() = %q, want 5 bars and the average", got)
+	}
+
+	want := []string{
+		"😄 <code>█████░░░░░</code> 2",
+		"🙂 <code>██████████</code> 4",
+		"😐 <code>░░░░░░░░░░</code> 0",
+		"🙁 <code>███░░░░░░░</code> 1",
+		"😢 <code>░░░░░░░░░░</code> 0",
+		"<i>Average: 🙂 good over 7 notes</i>",
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestNoteMoodMetaWithoutDatabase(t *testing.T) {
+	b := &Bot{}
+	if got := b.noteMoodMeta(123456789, "note"); got != nil {
+		t.Errorf("noteMoodMeta() = %v, want no metadata", got)
+	}
+}
@@ -0,0 +1,332 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Multi-select: ☑️ MULTI in the file selection keyboard turns the pinned and built-in note files
+// into checkboxes, and the message is saved to every checked file in one commit. ISSUE, TODO and
+// CUSTOM have flows of their own, so they aren't offered.
+
+const fileMultiSelectExpiry = 30 * time.Minute
+
+// fileMultiSelect is the state of a multi-select keyboard, kept by chat and message so callbacks
+// can refer to files by index (Telegram limits callback data to 64 bytes)
+type fileMultiSelect struct {
+	MessageKey string
+	Files      []string
+	Labels     []string
+	Selected   []bool
+	Private    bool // Restores the keyboard of a private entry on Back
+	Multiline  bool // Restores the keyboard without TODO on Back
+}
+
+func fileMultiSelectKey(chatID int64, messageID int) string {
+	return fmt.Sprintf("multi_select_%d_%d", chatID, messageID)
+}
+
+// newFileMultiSelect offers the pinned files followed by the built-in note files, each file once
+func newFileMultiSelect(messageKey string, pinnedFiles []string) *fileMultiSelect {
+	selection := &fileMultiSelect{MessageKey: messageKey}
+	add := func(file, label string) {
+		for _, existing := range selection.Files {
+			if existing == file {
+				return
+			}
+		}
+		selection.Files = append(selection.Files, file)
+		selection.Labels = append(selection.Labels, label)
+	}
+
+	for _, file := range pinnedFiles {
+		add(file, pinnedFileLabel(file))
+	}
+	add("note.md", "📝 NOTE")
+	add("idea.md", "💡 IDEA")
+	add("inbox.md", "📥 INBOX")
+	add("tool.md", "🔧 TOOL")
+
+	selection.Selected = make([]bool, len(selection.Files))
+	return selection
+}
+
+func (s *fileMultiSelect) selectedFiles() []string {
+	var files []string
+	for i, file := range s.Files {
+		if s.Selected[i] {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// fileMultiSelectKeyboard lists the files two per row, followed by the save and back buttons
+func fileMultiSelectKeyboard(selection *fileMultiSelect) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	for i, label := range selection.Labels {
+		box := "⬜ "
+		if selection.Selected[i] {
+			box = "✅ "
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(box+label, fmt.Sprintf("msel_%d", i)))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	save := "💾 Select files to save"
+	if count := len(selection.selectedFiles()); count == 1 {
+		save = "💾 Save to 1 file"
+	} else if count > 1 {
+		save = fmt.Sprintf("💾 Save to %d files", count)
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("⬅️ Back", "msel_back"),
+		tgbotapi.NewInlineKeyboardButtonData(save, "msel_save"),
+	))
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// handleFileMultiSelect shows the multi-select keyboard once ☑️ MULTI is chosen
+func (b *Bot) handleFileMultiSelect(callback *tgbotapi.CallbackQuery, messageKey string) error {
+	chatID := callback.Message.Chat.ID
+
+	messageData, exists := b.pendingMessage(messageKey)
+	if !exists {
+		return fmt.Errorf("original message not found")
+	}
+	content, _, private, err := parsePendingEntry(messageData)
+	if err != nil {
+		return err
+	}
+
+	selection := newFileMultiSelect(messageKey, b.pinnedFiles(chatID))
+	selection.Private = private
+	selection.Multiline = strings.Contains(content, "\n")
+	b.cache.SetWithExpiry(fileMultiSelectKey(chatID, callback.Message.MessageID), selection, fileMultiSelectExpiry)
+
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, "☑️ Tap the files to save this message to, then save:")
+	keyboard := fileMultiSelectKeyboard(selection)
+	edit.ReplyMarkup = &keyboard
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to show multi-select: %w", err)
+	}
+	return nil
+}
+
+// handleFileMultiSelectCallback handles msel_<index> toggles, msel_save and msel_back from the
+// multi-select keyboard
+func (b *Bot) handleFileMultiSelectCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	cacheKey := fileMultiSelectKey(chatID, messageID)
+	cached, ok := b.cache.Get(cacheKey)
+	if !ok {
+		b.editMessage(chatID, messageID, "⏰ This file choice has expired. Send your message again to save it.")
+		return nil
+	}
+	selection := cached.(*fileMultiSelect)
+
+	switch callback.Data {
+	case "msel_back":
+		b.cache.Delete(cacheKey)
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, fileSelectionPrompt(selection.Private))
+		keyboard := b.fileSelectionKeyboard(chatID, selection.MessageKey, selection.Private, selection.Multiline)
+		edit.ReplyMarkup = &keyboard
+		if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+			return fmt.Errorf("failed to show file selection: %w", err)
+		}
+		return nil
+	case "msel_save":
+		if len(selection.selectedFiles()) == 0 {
+			return nil
+		}
+		b.cache.Delete(cacheKey)
+		return b.saveToMultipleFiles(callback, selection.MessageKey, selection.selectedFiles())
+	}
+
+	index, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "msel_"))
+	if err != nil || index < 0 || index >= len(selection.Files) {
+		return fmt.Errorf("invalid multi-select callback data: %s", callback.Data)
+	}
+	selection.Selected[index] = !selection.Selected[index]
+	b.cache.SetWithExpiry(cacheKey, selection, fileMultiSelectExpiry)
+
+	keyboard := fileMultiSelectKeyboard(selection)
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, keyboard)
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to update multi-select: %w", err)
+	}
+	return nil
+}
+
+// saveToMultipleFiles saves a pending message to every file in one commit
+func (b *Bot) saveToMultipleFiles(callback *tgbotapi.CallbackQuery, messageKey string, filenames []string) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	messageData, exists := b.pendingMessage(messageKey)
+	if !exists {
+		return fmt.Errorf("original message not found")
+	}
+	content, originalMessageID, private, err := parsePendingEntry(messageData)
+	if err != nil {
+		return err
+	}
+
+	if _, err := b.ensureUser(callback.Message); err != nil {
+		b.editMessage(chatID, messageID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	b.updateProgressMessage(chatID, messageID, 0, "🔄 Starting process...")
+
+	// Private entries go to the private repository
+	userGitHubProvider, err := b.getEntryGitHubProvider(chatID, private)
+	if err != nil {
+		b.editMessage(chatID, messageID, "❌ "+err.Error())
+		return nil
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	b.updateProgressMessage(chatID, messageID, 30, "📊 Checking repository capacity...")
+
+	if userGitHubProvider.NeedsClone() {
+		if err := userGitHubProvider.EnsureRepositoryWithPremium(premiumLevel); err != nil {
+			logger.Error("Failed to ensure repository", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+			edit := tgbotapi.NewEditMessageText(chatID, messageID, b.formatRepositorySetupError(err, "save content"))
+			edit.ParseMode = "html"
+			if _, sendErr := b.rateLimitedSend(chatID, edit); sendErr != nil {
+				b.sendResponse(chatID, fmt.Sprintf("❌ Repository setup failed: %v", err))
+			}
+			return nil
+		}
+	}
+
+	isNearCapacity, percentage, err := userGitHubProvider.IsRepositoryNearCapacityWithPremium(premiumLevel)
+	if err != nil {
+		logger.Warn("Failed to check repository capacity", map[string]interface{}{
+			"error": err.Error(),
+		})
+	} else if isNearCapacity {
+		errorMsg := fmt.Sprintf(RepoAlmostFullTemplate, percentage)
+		lapsedNotice, renewMarkup := b.lapsedPremiumNotice(chatID)
+		errorMsg += lapsedNotice
+		edit := tgbotapi.NewEditMessageText(chatID, messageID, errorMsg)
+		edit.ParseMode = "html"
+		edit.ReplyMarkup = renewMarkup
+		if _, sendErr := b.rateLimitedSend(chatID, edit); sendErr != nil {
+			b.sendResponse(chatID, errorMsg)
+		}
+		return nil
+	}
+
+	b.updateProgressMessage(chatID, messageID, 60, "🧠 LLM processing...")
+	title, tags := b.entryTitleAndTags(chatID, content)
+
+	b.updateProgressMessage(chatID, messageID, 80, "📝 Saving to GitHub...")
+
+	// Every file gets the entry prepended, a new custom file starts with its template
+	files := make(map[string]string)
+	for _, filename := range filenames {
+		existing, err := userGitHubProvider.ReadFile(filename)
+		if err != nil && !strings.Contains(err.Error(), "does not exist") {
+			b.editMessage(chatID, messageID, fmt.Sprintf("❌ Failed to read %s: %v", filename, err))
+			return nil
+		}
+		entry := b.formatMessageContentWithTitleAndTags(content, filename, originalMessageID, chatID, title, tags)
+		files[filename] = github.PrependEntry(existing, b.applyFileTemplate(chatID, userGitHubProvider, filename, entry), "\n")
+	}
+
+	commitMsg := fmt.Sprintf("Add %s to %s via Telegram", b.commitTitle(chatID, title), strings.Join(filenames, ", "))
+	commitFiles := b.withReadmeTOC(chatID, userGitHubProvider, files) // Implemented in readme_toc.go
+	if err := userGitHubProvider.ReplaceMultipleFilesWithAuthorAndPremium(commitFiles, commitMsg, b.getCommitterInfo(chatID), premiumLevel); err != nil {
+		b.recordRepoFailure(chatID, err)
+		if strings.Contains(err.Error(), "GitHub authorization failed") {
+			b.editMessage(chatID, messageID, "❌ "+err.Error())
+			return nil
+		}
+		b.checkRepoMoved(chatID, err)
+		b.editMessage(chatID, messageID, fmt.Sprintf("❌ Failed to save: %v", err))
+		return nil
+	}
+
+	b.pendingMessages.Delete(messageKey)
+
+	logger.Info("Saved message to multiple files", map[string]interface{}{
+		"chat_id": chatID,
+		"files":   filenames,
+	})
+
+	// Increment commit count before the confirmation shows the streak
+	if b.db != nil {
+		if err := b.db.IncrementCommitCount(chatID); err != nil {
+			logger.Error("Failed to increment commit count", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		}
+	}
+
+	var names []string
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var row []tgbotapi.InlineKeyboardButton
+	stats := ""
+	for _, filename := range filenames {
+		names = append(names, strings.ToUpper(strings.TrimSuffix(filename, ".md")))
+
+		// The multi-file commit has no result of its own, each file is logged with its new size
+		line := b.recordCommitStats(chatID, userGitHubProvider, &github.CommitResult{
+			Filename: filename,
+			FileSize: int64(len(files[filename])),
+		})
+		// Every stats line ends with the streak, which is shown once below them
+		line, _, _ = strings.Cut(strings.TrimPrefix(line, "\n"), "\n")
+		stats += "\n" + line
+
+		fileURL, err := userGitHubProvider.GetGitHubFileURLWithBranch(filename)
+		if err != nil {
+			logger.Warn("Failed to generate GitHub file URL", map[string]interface{}{
+				"error":    err.Error(),
+				"filename": filename,
+			})
+			continue
+		}
+		row = append(row, tgbotapi.NewInlineKeyboardButtonURL("🔗 "+filename, fileURL))
+		if len(row) == 2 {
+			rows = append(rows, row)
+			row = nil
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	successMsg := "✅ Saved to " + strings.Join(names, ", ") + stats + b.streakConfirmation(chatID)
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, successMsg)
+	if len(rows) > 0 {
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+		edit.ReplyMarkup = &keyboard
+	}
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		b.sendResponse(chatID, successMsg)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package telegram
+
+import (
+	"testing"
+)
+
+func TestNewFileMultiSelect(t *testing.T) {
+	selection := newFileMultiSelect("42_1", []string{"journal/work.md", "idea.md"})
+
+	want := []string{"journal/work.md", "idea.md", "note.md", "inbox.md", "tool.md"}
+	if len(selection.Files) != len(want) {
+		t.Fatalf("Files = %v, want %v", selection.Files, want)
+	}
+	for i := range want {
+		if selection.Files[i] != want[i] {
+			t.Fatalf("Files = %v, want %v", selection.Files, want)
+		}
+	}
+	if selection.Labels[1] != "📌 idea" || selection.Labels[2] != "📝 NOTE" {
+		t.Errorf("Labels = %v", selection.Labels)
+	}
+	if len(selection.selectedFiles()) != 0 {
+		t.Errorf("Expected nothing selected, got %v", selection.selectedFiles())
+	}
+}
+
+func TestFileMultiSelectKeyboard(t *testing.T) {
+	selection := newFileMultiSelect("42_1", nil)
+
+	keyboard := fileMultiSelectKeyboard(selection)
+	if len(keyboard.InlineKeyboard) != 3 || len(keyboard.InlineKeyboard[0]) != 2 {
+		t.Fatalf("Expected two rows of files and a save row, got %+v", keyboard.InlineKeyboard)
+	}
+	if button := keyboard.InlineKeyboard[2][1]; button.Text != "💾 Select files to save" || *button.CallbackData != "msel_save" {
+		t.Errorf("Expected the save button, got %+v", button)
+	}
+
+	selection.Selected[0] = true
+	selection.Selected[3] = true
+	keyboard = fileMultiSelectKeyboard(selection)
+	if button := keyboard.InlineKeyboard[0][0]; button.Text != "✅ 📝 NOTE" || *button.CallbackData != "msel_0" {
+		t.Errorf("Expected NOTE to be selected, got %+v", button)
+	}
+	if button := keyboard.InlineKeyboard[0][1]; button.Text != "⬜ 💡 IDEA" {
+		t.Errorf("Expected IDEA not to be selected, got %+v", button)
+	}
+	if button := keyboard.InlineKeyboard[2][1]; button.Text != "💾 Save to 2 files" {
+		t.Errorf("Expected save to 2 files, got %+v", button)
+	}
+	if files := selection.selectedFiles(); len(files) != 2 || files[0] != "note.md" || files[1] != "tool.md" {
+		t.Errorf("selectedFiles() = %v", files)
+	}
+}
@@ -0,0 +1,228 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/notecrypt"
+)
+
+// Note encryption: with /encrypt setup, the title, tags and content of new notes are sealed with a
+// key derived from the user's passphrase before they are committed, leaving only the metadata
+// comment readable. The passphrase is never stored, the derived key is, encrypted like tokens.
+// TODOs and issues stay plaintext since they mirror GitHub issues.
+
+const (
+	// encryptedSearchMaxFiles bounds the files read by a /search of encrypted notes
+	encryptedSearchMaxFiles = 30
+
+	// encryptedCommitTitle replaces note titles in commit messages
+	encryptedCommitTitle = "encrypted note"
+
+	// unsealedNotePlaceholder replaces a note that couldn't be encrypted, never committed in plaintext
+	unsealedNotePlaceholder = "> ⚠️ This note couldn't be encrypted, so its content wasn't saved."
+)
+
+// userNoteKey returns the user's note key and whether their notes are encrypted. A key that can't
+// be loaded counts as encrypted with a nil key, so notes are never committed in plaintext by mistake.
+func (b *Bot) userNoteKey(chatID int64) (*notecrypt.Key, bool) {
+	if b.db == nil {
+		return nil, false
+	}
+
+	stored, err := b.db.GetUserNoteKey(chatID)
+	if err != nil {
+		logger.Error("Failed to get note key", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return nil, true
+	}
+	if stored == "" {
+		return nil, false
+	}
+
+	key, err := notecrypt.ParseKey(stored)
+	if err != nil {
+		logger.Error("Failed to parse note key", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return nil, true
+	}
+	return key, true
+}
+
+// sealNote formats an encrypted note entry, a placeholder if key is nil or sealing fails
+func sealNote(key *notecrypt.Key, content string, messageID int, chatID int64, title, tags string, now time.Time) string {
+	sealed := unsealedNotePlaceholder
+	if key != nil {
+		var err error
+		if sealed, err = key.Seal(entry.NoteBody(content, title, tags)); err != nil {
+			logger.Error("Failed to encrypt note", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			sealed = unsealedNotePlaceholder
+		}
+	}
+	return entry.SealedNote(sealed, messageID, chatID, now)
+}
+
+// commitTitle returns the title to put in a note's commit message, which stays readable in the
+// repository history
+func (b *Bot) commitTitle(chatID int64, title string) string {
+	if _, encrypted := b.userNoteKey(chatID); encrypted {
+		return encryptedCommitTitle
+	}
+	return title
+}
+
+// openNotes decrypts the encrypted notes of content the user's key can open
+func (b *Bot) openNotes(chatID int64, content string) string {
+	if !strings.Contains(content, notecrypt.Marker) {
+		return content
+	}
+	key, _ := b.userNoteKey(chatID)
+	if key == nil {
+		return content
+	}
+
+	opened, failed := key.OpenAll(content)
+	if failed > 0 {
+		logger.Warn("Failed to decrypt some notes", map[string]interface{}{
+			"chat_id": chatID,
+			"failed":  failed,
+		})
+	}
+	return opened
+}
+
+// searchEncryptedNotes searches the decrypted notes of the root markdown files and custom files,
+// which GitHub code search and the clone can only see as ciphertext. Matches point at the line of
+// the sealed note.
+func (b *Bot) searchEncryptedNotes(chatID int64, provider github.GitHubProvider, key *notecrypt.Key, query string, limit int) ([]github.SearchMatch, error) {
+	entries, err := provider.ListDirectory("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+
+	var files []string
+	seen := make(map[string]bool)
+	add := func(filename string) {
+		if !seen[filename] && strings.HasSuffix(strings.ToLower(filename), ".md") && len(files) < encryptedSearchMaxFiles {
+			seen[filename] = true
+			files = append(files, filename)
+		}
+	}
+	for _, dirEntry := range entries {
+		if dirEntry.Type == "file" {
+			add(dirEntry.Path)
+		}
+	}
+	if user, err := b.db.GetUserByChatID(chatID); err == nil && user != nil {
+		for _, filename := range user.GetCustomFiles() {
+			add(filename)
+		}
+	}
+
+	var matches []github.SearchMatch
+	for _, filename := range files {
+		content, err := provider.ReadFile(filename)
+		if err != nil {
+			continue
+		}
+
+		for i, line := range strings.Split(content, "\n") {
+			if len(matches) >= limit {
+				return matches, nil
+			}
+			if notecrypt.IsSealed(line) {
+				if plaintext, err := key.Open(line); err == nil {
+					line = plaintext
+				}
+			}
+			for _, match := range github.MatchLines(filename, line, query, limit-len(matches)) {
+				match.Line = i + 1
+				matches = append(matches, match)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// handleEncryptCommand shows or changes note encryption: /encrypt, /encrypt setup <passphrase>, /encrypt off
+func (b *Bot) handleEncryptCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/encrypt"))
+	action, passphrase, _ := strings.Cut(args, " ")
+	passphrase = strings.TrimSpace(passphrase)
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Note encryption requires a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	_, encrypted := b.userNoteKey(chatID)
+
+	switch strings.ToLower(action) {
+	case "":
+		if encrypted {
+			b.sendResponse(chatID, "🔒 Note encryption is on. New notes are encrypted before they're committed, /search and /cat decrypt them.\n\nUse <code>/encrypt off</code> to stop.")
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("🔓 Note encryption is off.\n\nUse <code>/encrypt setup your passphrase</code> to encrypt new notes so your repository only stores ciphertext. The passphrase needs at least %d characters and can't be recovered, keep it safe.", notecrypt.MinPassphraseLength))
+		return nil
+	case "setup":
+		// The passphrase shouldn't stay in the chat history
+		b.deleteMessage(chatID, message.MessageID)
+
+		if encrypted {
+			b.sendResponse(chatID, "🔒 Note encryption is already on. Use <code>/encrypt off</code> first to change the passphrase.")
+			return nil
+		}
+		if passphrase == "" {
+			b.sendResponse(chatID, "Usage: <code>/encrypt setup your passphrase</code>")
+			return nil
+		}
+
+		key, err := notecrypt.NewKey(passphrase, notecrypt.SaltFor(strconv.FormatInt(chatID, 10)))
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %v", err))
+			return nil
+		}
+		if err := b.db.UpdateUserNoteKey(chatID, key.String()); err != nil {
+			b.sendResponse(chatID, "❌ Failed to save the note key.")
+			return nil
+		}
+
+		b.sendResponse(chatID, fmt.Sprintf("%s Note encryption enabled, your passphrase message was deleted.\n\nNew notes are encrypted before they're committed. TODOs, issues and photos stay readable, and mood tracking is skipped for encrypted notes.\n\n⚠️ Keep your passphrase: it's the only way to read your notes outside of this bot.", consts.EmojiSuccess))
+		return nil
+	case "off":
+		if !encrypted {
+			b.sendResponse(chatID, "ℹ️ Note encryption is already off.")
+			return nil
+		}
+		if err := b.db.UpdateUserNoteKey(chatID, ""); err != nil {
+			b.sendResponse(chatID, "❌ Failed to turn note encryption off.")
+			return nil
+		}
+		b.sendResponse(chatID, "🔓 Note encryption disabled. Notes encrypted so far stay encrypted, set up the same passphrase again to read them with /search and /cat.")
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/encrypt</code>, <code>/encrypt setup your passphrase</code> or <code>/encrypt off</code>")
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/notecrypt"
+)
+
+func TestSealNote(t *testing.T) {
+	key, err := notecrypt.NewKey("correct horse battery", notecrypt.SaltFor("42"))
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+
+	note := sealNote(key, "secret plans", 7, 42, "Plans", "#work", time.Now())
+	if strings.Contains(note, "secret") || strings.Contains(note, "Plans") || !strings.HasPrefix(note, "<!--\n[7] [42] [") {
+		t.Fatalf("sealNote() = %q, want only the metadata comment readable", note)
+	}
+
+	opened, failed := key.OpenAll(note)
+	if failed != 0 || !strings.Contains(opened, entry.NoteBody("secret plans", "Plans", "#work")) {
+		t.Errorf("OpenAll(sealNote()) = %q, %d failed", opened, failed)
+	}
+
+	// Without a key the content is dropped rather than committed in plaintext
+	if note := sealNote(nil, "secret plans", 7, 42, "Plans", "", time.Now()); strings.Contains(note, "secret") || !strings.Contains(note, unsealedNotePlaceholder) {
+		t.Errorf("sealNote(nil) = %q", note)
+	}
+}
+
+func TestUserNoteKeyWithoutDatabase(t *testing.T) {
+	b := &Bot{}
+	if key, encrypted := b.userNoteKey(123456789); key != nil || encrypted {
+		t.Errorf("userNoteKey() = %v, %v without a database", key, encrypted)
+	}
+	if title := b.commitTitle(123456789, "Plans"); title != "Plans" {
+		t.Errorf("commitTitle() = %q", title)
+	}
+}
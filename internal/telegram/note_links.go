@@ -0,0 +1,71 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/entry"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Note links: [[links]] are resolved when a note is formatted, and the notes it links to are kept
+// until the commit succeeds, then added to backlinks.md
+
+// noteLinksKey identifies the note being committed by a chat to a file
+func noteLinksKey(chatID int64, filename string) string {
+	return fmt.Sprintf("%d:%s", chatID, filename)
+}
+
+// resolveNoteLinks resolves the [[links]] of a note and remembers the linked notes for updateBacklinks
+func (b *Bot) resolveNoteLinks(chatID int64, content, filename string) string {
+	resolved, targets := entry.ResolveWikiLinks(content, filename)
+
+	key := noteLinksKey(chatID, filename)
+	if len(targets) == 0 || filename == entry.BacklinksFile {
+		b.pendingNoteLinks.Delete(key)
+		return resolved
+	}
+	b.pendingNoteLinks.Store(key, targets)
+	return resolved
+}
+
+// updateBacklinks adds the links of a note just committed to filename to backlinks.md
+func (b *Bot) updateBacklinks(chatID int64, provider github.GitHubProvider, filename string) {
+	value, ok := b.pendingNoteLinks.LoadAndDelete(noteLinksKey(chatID, filename))
+	if !ok {
+		return
+	}
+	targets := value.([]string)
+
+	// A missing index is created with the first link
+	current, err := provider.ReadFile(entry.BacklinksFile)
+	if err != nil && !strings.Contains(err.Error(), "does not exist") {
+		logger.Warn("Failed to read backlinks", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return
+	}
+
+	backlinks := entry.ParseBacklinks(current)
+	if !backlinks.Add(filename, targets) {
+		return
+	}
+
+	commitMsg := fmt.Sprintf("Update backlinks from %s", filename)
+	if err := provider.ReplaceFileWithAuthorAndPremium(entry.BacklinksFile, backlinks.Markdown(), commitMsg, b.getCommitterInfo(chatID), b.getPremiumLevel(chatID)); err != nil {
+		logger.Warn("Failed to update backlinks", map[string]interface{}{
+			"chat_id":  chatID,
+			"filename": filename,
+			"error":    err.Error(),
+		})
+		return
+	}
+
+	logger.Info("Updated backlinks", map[string]interface{}{
+		"chat_id":  chatID,
+		"filename": filename,
+		"links":    len(targets),
+	})
+}
@@ -0,0 +1,24 @@
+package telegram
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestResolveNoteLinksRemembersTargets(t *testing.T) {
+	b := &Bot{}
+
+	if got := b.resolveNoteLinks(1, "see [[ideas]]", "inbox.md"); got != "see [ideas](ideas.md)" {
+		t.Errorf("resolveNoteLinks() = %q", got)
+	}
+	value, ok := b.pendingNoteLinks.Load(noteLinksKey(1, "inbox.md"))
+	if !ok || !reflect.DeepEqual(value, []string{"ideas.md"}) {
+		t.Errorf("pending links = %v, %v", value, ok)
+	}
+
+	// A later note without links to the same file must not inherit them
+	b.resolveNoteLinks(1, "no links", "inbox.md")
+	if _, ok := b.pendingNoteLinks.Load(noteLinksKey(1, "inbox.md")); ok {
+		t.Error("pending links should be cleared by a note without links")
+	}
+}
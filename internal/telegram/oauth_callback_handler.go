@@ -9,6 +9,7 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/msg2git/msg2git/internal/github"
 	"github.com/msg2git/msg2git/internal/logger"
 )
 
@@ -58,8 +59,8 @@ func (b *Bot) HandleGitHubOAuthCallback(w http.ResponseWriter, r *http.Request)
 
 		// Redirect to error page
 		redirectURL := "/auth-error?error=" + url.QueryEscape(errorParam)
-		if b.config.BaseURL != "" {
-			redirectURL = b.config.BaseURL + redirectURL
+		if b.cfg().BaseURL != "" {
+			redirectURL = b.cfg().BaseURL + redirectURL
 		}
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 		return
@@ -73,8 +74,8 @@ func (b *Bot) HandleGitHubOAuthCallback(w http.ResponseWriter, r *http.Request)
 
 		// Redirect to cancel page
 		redirectURL := "/auth-cancel"
-		if b.config.BaseURL != "" {
-			redirectURL = b.config.BaseURL + redirectURL
+		if b.cfg().BaseURL != "" {
+			redirectURL = b.cfg().BaseURL + redirectURL
 		}
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 		return
@@ -89,8 +90,8 @@ func (b *Bot) HandleGitHubOAuthCallback(w http.ResponseWriter, r *http.Request)
 		})
 
 		redirectURL := "/auth-error?error=invalid_state"
-		if b.config.BaseURL != "" {
-			redirectURL = b.config.BaseURL + redirectURL
+		if b.cfg().BaseURL != "" {
+			redirectURL = b.cfg().BaseURL + redirectURL
 		}
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 		return
@@ -105,8 +106,8 @@ func (b *Bot) HandleGitHubOAuthCallback(w http.ResponseWriter, r *http.Request)
 		})
 
 		redirectURL := "/auth-error?error=token_exchange_failed"
-		if b.config.BaseURL != "" {
-			redirectURL = b.config.BaseURL + redirectURL
+		if b.cfg().BaseURL != "" {
+			redirectURL = b.cfg().BaseURL + redirectURL
 		}
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 		return
@@ -121,8 +122,8 @@ func (b *Bot) HandleGitHubOAuthCallback(w http.ResponseWriter, r *http.Request)
 		})
 
 		redirectURL := "/auth-error?error=user_info_failed"
-		if b.config.BaseURL != "" {
-			redirectURL = b.config.BaseURL + redirectURL
+		if b.cfg().BaseURL != "" {
+			redirectURL = b.cfg().BaseURL + redirectURL
 		}
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 		return
@@ -138,8 +139,8 @@ func (b *Bot) HandleGitHubOAuthCallback(w http.ResponseWriter, r *http.Request)
 		})
 
 		redirectURL := "/auth-error?error=database_save_failed"
-		if b.config.BaseURL != "" {
-			redirectURL = b.config.BaseURL + redirectURL
+		if b.cfg().BaseURL != "" {
+			redirectURL = b.cfg().BaseURL + redirectURL
 		}
 		http.Redirect(w, r, redirectURL, http.StatusFound)
 		return
@@ -150,8 +151,8 @@ func (b *Bot) HandleGitHubOAuthCallback(w http.ResponseWriter, r *http.Request)
 
 	// Redirect to success page
 	successURL := fmt.Sprintf("/auth-success?user=%s", url.QueryEscape(githubUser.Login))
-	if b.config.BaseURL != "" {
-		successURL = b.config.BaseURL + successURL
+	if b.cfg().BaseURL != "" {
+		successURL = b.cfg().BaseURL + successURL
 	}
 	http.Redirect(w, r, successURL, http.StatusFound)
 }
@@ -185,8 +186,8 @@ func (b *Bot) parseOAuthState(state string) (chatID int64, userID int64, err err
 func (b *Bot) exchangeOAuthCode(code string) (string, error) {
 	// Prepare token exchange request
 	data := url.Values{}
-	data.Set("client_id", b.config.GitHubOAuthClientID)
-	data.Set("client_secret", b.config.GitHubOAuthClientSecret)
+	data.Set("client_id", b.cfg().GitHubOAuthClientID)
+	data.Set("client_secret", b.cfg().GitHubOAuthClientSecret)
 	data.Set("code", code)
 
 	req, err := http.NewRequest("POST", "https://github.com/login/oauth/access_token", strings.NewReader(data.Encode()))
@@ -235,7 +236,7 @@ func (b *Bot) exchangeOAuthCode(code string) (string, error) {
 
 // getGitHubUser gets the authenticated user's GitHub profile
 func (b *Bot) getGitHubUser(accessToken string) (*GitHubUser, error) {
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	req, err := http.NewRequest("GET", github.APIBaseURL()+"/user", nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user request: %w", err)
 	}
@@ -244,7 +245,7 @@ func (b *Bot) getGitHubUser(accessToken string) (*GitHubUser, error) {
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 
-	client := &http.Client{}
+	client := github.NewHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
@@ -288,17 +289,13 @@ func (b *Bot) saveGitHubTokenToDatabase(chatID int64, accessToken string, github
 	// Invalidate cached GitHub provider since token configuration changed
 	cacheKey := fmt.Sprintf("github_provider_%d", chatID)
 	b.cache.Delete(cacheKey)
+	b.cache.Delete(fmt.Sprintf("github_private_provider_%d", chatID))
 
-	// Optionally update committer info if not set
-	if user.Committer == "" && githubUser.Name != "" && githubUser.Email != "" {
-		committer := fmt.Sprintf("%s <%s>", githubUser.Name, githubUser.Email)
-		if err := b.db.UpdateUserCommitter(chatID, committer); err != nil {
-			logger.Warn("Failed to update committer info", map[string]interface{}{
-				"chat_id": chatID,
-				"error":   err.Error(),
-			})
-		}
-	}
+	// Update committer info unless the user set their own (implemented in commit_email.go)
+	b.applyOAuthCommitter(chatID, user.Committer, githubUser, user.NoreplyEmail)
+
+	// Remember whose token it is (implemented in github_identity.go)
+	b.linkGitHubAccount(chatID, githubUser)
 
 	logger.Info("Successfully saved GitHub token to database", map[string]interface{}{
 		"chat_id":     chatID,
@@ -306,6 +303,9 @@ func (b *Bot) saveGitHubTokenToDatabase(chatID int64, accessToken string, github
 		"github_id":   githubUser.ID,
 	})
 
+	// Clone now so the first note doesn't wait for it (implemented in warm_clones.go)
+	b.warmRepository(chatID)
+
 	return nil
 }
 
@@ -39,7 +39,7 @@ func (b *Bot) handleGitHubOAuthPrivacyConfirmation(callback *tgbotapi.CallbackQu
 	})
 
 	// Check if OAuth is configured
-	if !b.config.HasGitHubOAuthConfig() {
+	if !b.cfg().HasGitHubOAuthConfig() {
 		notConfiguredMsg := `❌ <b>GitHub OAuth Not Configured</b>
 
 GitHub OAuth is not set up on this bot. You can still setup manually using /repo`
@@ -57,18 +57,18 @@ GitHub OAuth is not set up on this bot. You can still setup manually using /repo
 	state := fmt.Sprintf("telegram_%d_%d", callback.Message.Chat.ID, callback.From.ID)
 	authURL := fmt.Sprintf(
 		"https://github.com/login/oauth/authorize?client_id=%s&redirect_uri=%s&scope=%s&state=%s",
-		b.config.GitHubOAuthClientID,
-		b.config.GitHubOAuthRedirectURI,
+		b.cfg().GitHubOAuthClientID,
+		b.cfg().GitHubOAuthRedirectURI,
 		GitHubOAuthScopes,
 		state,
 	)
 
 	// Build privacy policy link if BASE_URL is configured
 	var privacyLink string
-	if b.config.BaseURL != "" {
+	if b.cfg().BaseURL != "" {
 		privacyLink = fmt.Sprintf(`
 
-📋 <a href="%s/privacy">Read our Privacy Policy</a>`, b.config.BaseURL)
+📋 <a href="%s/privacy">Read our Privacy Policy</a>`, b.cfg().BaseURL)
 	} else {
 		privacyLink = `
 
@@ -76,8 +76,8 @@ GitHub OAuth is not set up on this bot. You can still setup manually using /repo
 	}
 
 	var privacyText string
-	if b.config.BaseURL != "" {
-		privacyText = fmt.Sprintf(`<a href="%s/privacy">Privacy Policy</a>`, b.config.BaseURL)
+	if b.cfg().BaseURL != "" {
+		privacyText = fmt.Sprintf(`<a href="%s/privacy">Privacy Policy</a>`, b.cfg().BaseURL)
 	} else {
 		privacyText = "Privacy Policy"
 	}
@@ -140,7 +140,7 @@ func (b *Bot) handleGitHubOAuthCallback(callback *tgbotapi.CallbackQuery) error
 	})
 
 	// Check if OAuth is configured
-	if !b.config.HasGitHubOAuthConfig() {
+	if !b.cfg().HasGitHubOAuthConfig() {
 		notConfiguredMsg := `❌ <b>GitHub OAuth Not Configured</b>
 
 GitHub OAuth is not set up on this bot. You still can manual setup by /repo`
@@ -160,8 +160,8 @@ GitHub OAuth is not set up on this bot. You still can manual setup by /repo`
 	// Build GitHub OAuth authorization URL using config
 	authURL := fmt.Sprintf(
 		"https://github.com/login/oauth/authorize?client_id=%s&redirect_uri=%s&scope=%s&state=%s",
-		b.config.GitHubOAuthClientID,
-		b.config.GitHubOAuthRedirectURI,
+		b.cfg().GitHubOAuthClientID,
+		b.cfg().GitHubOAuthRedirectURI,
 		GitHubOAuthScopes,
 		state,
 	)
@@ -281,7 +281,7 @@ Your GitHub account has been successfully linked to Msg2Git.
 
 // Helper function to validate GitHub OAuth configuration
 func (b *Bot) isGitHubOAuthConfigured() bool {
-	return b.config.HasGitHubOAuthConfig()
+	return b.cfg().HasGitHubOAuthConfig()
 }
 
 // generateGitHubOAuthURL generates a GitHub OAuth authorization URL
@@ -290,8 +290,8 @@ func (b *Bot) generateGitHubOAuthURL(chatID int64, userID int64) string {
 
 	return fmt.Sprintf(
 		"https://github.com/login/oauth/authorize?client_id=%s&redirect_uri=%s&scope=%s&state=%s",
-		b.config.GitHubOAuthClientID,
-		b.config.GitHubOAuthRedirectURI,
+		b.cfg().GitHubOAuthClientID,
+		b.cfg().GitHubOAuthRedirectURI,
 		GitHubOAuthScopes,
 		state,
 	)
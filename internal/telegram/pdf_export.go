@@ -0,0 +1,111 @@
+package telegram
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"html"
+	"path"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/pdf"
+)
+
+// PDF export: /pdf renders a markdown file of the repository to PDF (see internal/pdf) and sends
+// it as a document. Telegram keeps sent files, so instead of the rendered bytes only the file ID
+// is cached, keyed by the file's content: asking again for an unchanged file resends it without
+// rendering or uploading.
+
+const (
+	pdfMaxSourceBytes = 512 * 1024 // Larger files are refused before rendering
+	pdfMaxPages       = 200
+	pdfCacheExpiry    = 24 * time.Hour
+)
+
+// handlePDFCommand renders a markdown file to PDF and sends it
+func (b *Bot) handlePDFCommand(message *tgbotapi.Message, arg string) error {
+	chatID := message.Chat.ID
+
+	filePath, err := validateRepoPath(arg)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if filePath == "" {
+		b.sendResponse(chatID, "📄 <b>Usage:</b> <code>/pdf path/to/file.md</code>\n\nRenders a markdown file of your repository to PDF. Use /ls to browse repository files.")
+		return nil
+	}
+	if !strings.EqualFold(path.Ext(filePath), ".md") {
+		b.sendResponse(chatID, "❌ Only markdown (.md) files can be exported to PDF.")
+		return nil
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	// Reading stops past the limit, larger files are refused anyway
+	content, tooLarge, err := github.ReadFileHead(userGitHubProvider, filePath, pdfMaxSourceBytes)
+	if err != nil {
+		logger.Warn("Failed to read file for /pdf", map[string]interface{}{
+			"chat_id": chatID,
+			"path":    filePath,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to read <code>%s</code>: %s", html.EscapeString(filePath), html.EscapeString(err.Error())))
+		return nil
+	}
+	if strings.TrimSpace(content) == "" {
+		b.sendResponse(chatID, fmt.Sprintf("📄 <code>%s</code> is empty or does not exist.", html.EscapeString(filePath)))
+		return nil
+	}
+	if tooLarge {
+		b.sendResponse(chatID, fmt.Sprintf("❌ <code>%s</code> is too large to export (over %d KB). Use /cat to download it as markdown.",
+			html.EscapeString(filePath), pdfMaxSourceBytes/1024))
+		return nil
+	}
+
+	name := strings.TrimSuffix(path.Base(filePath), path.Ext(filePath)) + ".pdf"
+	cacheKey := fmt.Sprintf("pdf_%d_%x", chatID, sha256.Sum256([]byte(filePath+"\x00"+content)))
+	if fileID, ok := b.cache.Get(cacheKey); ok {
+		doc := tgbotapi.NewDocument(chatID, tgbotapi.FileID(fileID.(string)))
+		doc.Caption = filePath
+		if _, err := b.rateLimitedSend(chatID, doc); err == nil {
+			return nil
+		}
+		// Fall back to rendering, e.g. if Telegram dropped the file
+		b.cache.Delete(cacheKey)
+	}
+
+	rendered, err := pdf.Markdown(filePath, content, pdfMaxPages)
+	if errors.Is(err, pdf.ErrTooManyPages) {
+		b.sendResponse(chatID, fmt.Sprintf("❌ <code>%s</code> is longer than %d pages and can't be exported.", html.EscapeString(filePath), pdfMaxPages))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to render PDF: %w", err)
+	}
+
+	doc := tgbotapi.NewDocument(chatID, tgbotapi.FileBytes{Name: name, Bytes: rendered})
+	doc.Caption = filePath
+	sent, err := b.rateLimitedSend(chatID, doc)
+	if err != nil {
+		return fmt.Errorf("failed to send PDF: %w", err)
+	}
+	if sent.Document != nil {
+		b.cache.SetWithExpiry(cacheKey, sent.Document.FileID, pdfCacheExpiry)
+	}
+
+	logger.Info("Exported file to PDF", map[string]interface{}{
+		"chat_id": chatID,
+		"path":    filePath,
+		"bytes":   len(rendered),
+	})
+	return nil
+}
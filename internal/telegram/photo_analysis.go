@@ -0,0 +1,127 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/llm"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Photo analysis: with multimodal analysis on, a photo is analyzed together with its caption
+// while it uploads to the CDN, instead of after the user picks where to save it. The title and
+// tags of the analysis are cached under the photo's message key, so whichever file the user picks
+// merges what the photo shows and what the caption says into one entry without another LLM call.
+
+const (
+	// photoAnalysisExpiry is how long an analysis waits for the user to pick a file
+	photoAnalysisExpiry = 30 * time.Minute
+
+	// photoAnalysisWait is how long the upload progress waits for a slower analysis
+	photoAnalysisWait = 20 * time.Second
+)
+
+func photoAnalysisKey(messageKey string) string {
+	return "photo_analysis_" + messageKey
+}
+
+// startPhotoAnalysis analyzes photoData with the caption of message in the background, caching
+// the result for the file selection. The returned channel is closed once the analysis finished.
+func (b *Bot) startPhotoAnalysis(message *tgbotapi.Message, photoData []byte) <-chan struct{} {
+	done := make(chan struct{})
+	chatID := message.Chat.ID
+	messageKey := fmt.Sprintf("%d_%d", chatID, message.MessageID)
+
+	go func() {
+		defer close(done)
+
+		client, isUsingDefaultLLM := b.getUserLLMClientWithUsageTracking(chatID, message.Caption)
+		if client == nil || !client.SupportsMultimodal() {
+			return
+		}
+
+		start := time.Now()
+		analysis, usage, err := client.ProcessImageWithMessage(photoData, message.Caption)
+		if err != nil || analysis == "" {
+			logger.Warn("Multimodal analysis failed during photo upload", map[string]interface{}{
+				"error":   fmt.Sprint(err),
+				"chat_id": chatID,
+			})
+			return
+		}
+		b.recordPhotoAnalysisUsage(chatID, usage, isUsingDefaultLLM)
+		b.cache.SetWithExpiry(photoAnalysisKey(messageKey), analysis, photoAnalysisExpiry)
+
+		logger.Info("Multimodal analysis completed during photo upload", map[string]interface{}{
+			"chat_id":     chatID,
+			"has_caption": message.Caption != "",
+			"duration":    time.Since(start).String(),
+			"analysis":    analysis,
+		})
+	}()
+
+	return done
+}
+
+// awaitPhotoAnalysis shows the analysis as the last step of the upload progress until it
+// finished or photoAnalysisWait passed; a later result is still used if the user picks a file after it
+func (b *Bot) awaitPhotoAnalysis(chatID int64, statusMessageID int, done <-chan struct{}) {
+	select {
+	case <-done:
+		return
+	default:
+	}
+
+	b.updateProgressMessage(chatID, statusMessageID, 85, "✅ Photo uploaded · 🔍 Analyzing photo...")
+	select {
+	case <-done:
+	case <-time.After(photoAnalysisWait):
+		logger.Warn("Photo analysis still running after upload", map[string]interface{}{
+			"chat_id": chatID,
+		})
+	}
+}
+
+// takePhotoAnalysis returns the title and tags of the analysis of a photo made during its upload.
+// content is the pending content of the photo, its caption or "Photo: <url>" without one.
+func (b *Bot) takePhotoAnalysis(messageKey, content string) (title, tags string, ok bool) {
+	value, found := b.cache.Get(photoAnalysisKey(messageKey))
+	analysis, isString := value.(string)
+	if !found || !isString {
+		return "", "", false
+	}
+	b.cache.Delete(photoAnalysisKey(messageKey))
+
+	fallback := content
+	if strings.HasPrefix(content, "Photo: ") {
+		fallback = "Photo"
+	}
+	title, tags = b.parseTitleAndTags(analysis, fallback)
+	return title, tags, true
+}
+
+// recordPhotoAnalysisUsage records the tokens of an analysis like those of other LLM calls:
+// the default LLM counts towards user_insights and user_usage, a personal one only user_insights
+func (b *Bot) recordPhotoAnalysisUsage(chatID int64, usage *llm.Usage, isUsingDefaultLLM bool) {
+	if usage == nil || b.db == nil {
+		return
+	}
+
+	var err error
+	if isUsingDefaultLLM {
+		err = b.db.IncrementTokenUsageAll(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens))
+	} else {
+		err = b.db.IncrementTokenUsageInsights(chatID, int64(usage.PromptTokens), int64(usage.CompletionTokens))
+	}
+	if err != nil {
+		logger.Warn("Failed to record token usage of photo analysis", map[string]interface{}{
+			"error":             err.Error(),
+			"chat_id":           chatID,
+			"default_llm":       isUsingDefaultLLM,
+			"prompt_tokens":     usage.PromptTokens,
+			"completion_tokens": usage.CompletionTokens,
+		})
+	}
+}
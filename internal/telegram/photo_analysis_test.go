@@ -0,0 +1,46 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/cache"
+)
+
+func TestTakePhotoAnalysis(t *testing.T) {
+	b := &Bot{cache: cache.NewWithConfig(10, time.Minute, time.Minute)}
+	defer b.cache.Close()
+
+	if _, _, ok := b.takePhotoAnalysis("1_2", "Photo: https://cdn/x.jpg"); ok {
+		t.Fatal("Expected no analysis before one was cached")
+	}
+
+	b.cache.SetWithExpiry(photoAnalysisKey("1_2"), "Sunset Beach|#travel #sea", time.Minute)
+	title, tags, ok := b.takePhotoAnalysis("1_2", "Evening walk")
+	if !ok || title != "Sunset Beach" || tags != "#travel #sea" {
+		t.Errorf("takePhotoAnalysis() = %q, %q, %v, want the cached title and tags", title, tags, ok)
+	}
+	if _, _, ok := b.takePhotoAnalysis("1_2", "Evening walk"); ok {
+		t.Error("Expected the analysis to be used once")
+	}
+}
+
+func TestStartPhotoAnalysisWithoutLLM(t *testing.T) {
+	b := &Bot{cache: cache.NewWithConfig(10, time.Minute, time.Minute)}
+	defer b.cache.Close()
+
+	message := &tgbotapi.Message{MessageID: 2, Chat: &tgbotapi.Chat{ID: 1}, Caption: "Evening walk"}
+	done := b.startPhotoAnalysis(message, []byte("jpeg"))
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected the analysis to finish without an LLM")
+	}
+	b.awaitPhotoAnalysis(1, 0, done)
+
+	if _, _, ok := b.takePhotoAnalysis("1_2", "Evening walk"); ok {
+		t.Error("Expected no analysis without an LLM")
+	}
+}
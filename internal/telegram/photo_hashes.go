@@ -0,0 +1,65 @@
+package telegram
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Duplicate photos: the bot remembers the CDN URL of every photo a user uploads by the repository
+// it went to and the SHA-256 of its bytes. Sending the same image to that repository again reuses
+// the URL, which neither creates another asset nor counts against the image limit. A photo sent to
+// another repository, or after switching repositories, is uploaded there.
+
+// photoContentHash returns the hex SHA-256 of the photo bytes
+func photoContentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// uploadPhoto returns the CDN URL of the photo, reporting whether the user uploaded the same image
+// to the provider's repository before and its URL was reused. Callers only count new uploads
+// against the image limit.
+func (b *Bot) uploadPhoto(chatID int64, provider github.GitHubProvider, filename string, data []byte) (string, bool, error) {
+	if b.db == nil {
+		url, err := provider.UploadImageToCDN(filename, data)
+		return url, false, err
+	}
+	owner, repoName, err := provider.GetRepoInfo()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to get repository info: %w", err)
+	}
+	repo := strings.ToLower(owner + "/" + repoName)
+
+	hash := photoContentHash(data)
+	if url, err := b.db.GetPhotoURL(chatID, repo, hash); err != nil {
+		logger.Warn("Failed to look up photo hash, uploading it", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	} else if url != "" {
+		logger.Info("Reusing CDN URL of duplicate photo", map[string]interface{}{
+			"chat_id": chatID,
+			"repo":    repo,
+			"url":     url,
+		})
+		return url, true, nil
+	}
+
+	url, err := provider.UploadImageToCDN(filename, data)
+	if err != nil {
+		return "", false, err
+	}
+
+	if err := b.db.SavePhotoURL(chatID, repo, hash, url); err != nil {
+		logger.Error("Failed to save photo hash", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+	}
+	return url, false, nil
+}
@@ -0,0 +1,165 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/render"
+)
+
+// Plain mode: for screen reader users, /plain on makes every reply of the chat concise plain text.
+// rateLimitedSend and rateLimitedRequest pass outgoing messages through plainChattable, which drops
+// markup, decorative emoji and progress bars (see render.PlainText), so handlers keep building
+// their replies as usual.
+
+const plainModeCacheExpiry = 10 * time.Minute
+
+func plainModeCacheKey(chatID int64) string {
+	return fmt.Sprintf("plain_mode_%d", chatID)
+}
+
+// plainMode reports whether the chat turned plain mode on
+func (b *Bot) plainMode(chatID int64) bool {
+	if b.cache != nil {
+		if cached, ok := b.cache.Get(plainModeCacheKey(chatID)); ok {
+			enabled, _ := cached.(bool)
+			return enabled
+		}
+	}
+	if b.db == nil {
+		return false
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil {
+		return false
+	}
+	enabled := user != nil && user.PlainMode
+	if b.cache != nil {
+		b.cache.SetWithExpiry(plainModeCacheKey(chatID), enabled, plainModeCacheExpiry)
+	}
+	return enabled
+}
+
+// plainChattable returns msg in plain text if the chat turned plain mode on
+func (b *Bot) plainChattable(chatID int64, msg tgbotapi.Chattable) tgbotapi.Chattable {
+	if !b.plainMode(chatID) {
+		return msg
+	}
+
+	switch m := msg.(type) {
+	case tgbotapi.MessageConfig:
+		m.Text, m.ParseMode = plainText(m.Text, m.ParseMode)
+		m.ReplyMarkup = plainReplyMarkup(m.ReplyMarkup)
+		return m
+	case tgbotapi.EditMessageTextConfig:
+		m.Text, m.ParseMode = plainText(m.Text, m.ParseMode)
+		m.ReplyMarkup = plainInlineKeyboard(m.ReplyMarkup)
+		return m
+	case tgbotapi.EditMessageCaptionConfig:
+		m.Caption, m.ParseMode = plainText(m.Caption, m.ParseMode)
+		m.ReplyMarkup = plainInlineKeyboard(m.ReplyMarkup)
+		return m
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		m.ReplyMarkup = plainInlineKeyboard(m.ReplyMarkup)
+		return m
+	case tgbotapi.PhotoConfig:
+		m.Caption, m.ParseMode = plainText(m.Caption, m.ParseMode)
+		m.ReplyMarkup = plainReplyMarkup(m.ReplyMarkup)
+		return m
+	case tgbotapi.DocumentConfig:
+		m.Caption, m.ParseMode = plainText(m.Caption, m.ParseMode)
+		m.ReplyMarkup = plainReplyMarkup(m.ReplyMarkup)
+		return m
+	}
+	return msg
+}
+
+// plainText returns text in plain text and the parse mode to send it with
+func plainText(text, parseMode string) (string, string) {
+	if text == "" {
+		return text, parseMode
+	}
+	plain := render.PlainText(text, parseMode)
+	if plain == "" {
+		plain = text // Nothing but emoji, e.g. a reaction
+	}
+	if strings.EqualFold(parseMode, "html") {
+		parseMode = ""
+	}
+	return plain, parseMode
+}
+
+// plainReplyMarkup returns the markup with plain inline keyboard labels. Reply keyboards are left
+// alone, their labels come back as the user's message.
+func plainReplyMarkup(markup interface{}) interface{} {
+	switch keyboard := markup.(type) {
+	case tgbotapi.InlineKeyboardMarkup:
+		return *plainInlineKeyboard(&keyboard)
+	case *tgbotapi.InlineKeyboardMarkup:
+		return plainInlineKeyboard(keyboard)
+	}
+	return markup
+}
+
+// plainInlineKeyboard returns a copy of the keyboard with plain button labels, keyboards are
+// often shared between replies
+func plainInlineKeyboard(keyboard *tgbotapi.InlineKeyboardMarkup) *tgbotapi.InlineKeyboardMarkup {
+	if keyboard == nil {
+		return nil
+	}
+	plain := tgbotapi.InlineKeyboardMarkup{InlineKeyboard: make([][]tgbotapi.InlineKeyboardButton, len(keyboard.InlineKeyboard))}
+	for i, row := range keyboard.InlineKeyboard {
+		plain.InlineKeyboard[i] = make([]tgbotapi.InlineKeyboardButton, len(row))
+		for j, button := range row {
+			button.Text, _ = plainText(button.Text, "")
+			plain.InlineKeyboard[i][j] = button
+		}
+	}
+	return &plain
+}
+
+// handlePlainCommand shows or switches plain mode
+func (b *Bot) handlePlainCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.ToLower(strings.TrimSpace(message.CommandArguments()))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Plain mode requires a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch args {
+	case "":
+		if user.PlainMode {
+			b.sendResponse(chatID, "Plain mode is on: replies are plain text without emoji, formatting or progress bars.\n\nUse <code>/plain off</code> to go back to formatted replies.")
+			return nil
+		}
+		b.sendResponse(chatID, "🔤 Plain mode is off.\n\nUse <code>/plain on</code> for concise plain text replies without emoji, formatting or progress bars, e.g. for screen readers.")
+		return nil
+	case "on", "off":
+		enabled := args == "on"
+		if err := b.db.UpdateUserPlainMode(chatID, enabled); err != nil {
+			b.sendResponse(chatID, "❌ Failed to update plain mode.")
+			return nil
+		}
+		b.cache.SetWithExpiry(plainModeCacheKey(chatID), enabled, plainModeCacheExpiry)
+		if enabled {
+			b.sendResponse(chatID, "Plain mode enabled. Replies are now plain text.")
+			return nil
+		}
+		b.sendResponse(chatID, "🔤 Plain mode disabled.")
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/plain</code>, <code>/plain on</code> or <code>/plain off</code>")
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/cache"
+)
+
+func TestPlainChattable(t *testing.T) {
+	b := &Bot{cache: cache.NewWithConfig(10, time.Minute, time.Minute)}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("📝 NOTE", "note"),
+	))
+	msg := tgbotapi.NewMessage(42, "✅ <b>Saved</b> 🎉")
+	msg.ParseMode = "html"
+	msg.ReplyMarkup = keyboard
+
+	if got := b.plainChattable(42, msg); got.(tgbotapi.MessageConfig).Text != msg.Text {
+		t.Errorf("plainChattable() changed a message of a chat without plain mode: %+v", got)
+	}
+
+	b.cache.Set(plainModeCacheKey(42), true)
+	got := b.plainChattable(42, msg).(tgbotapi.MessageConfig)
+	if got.Text != "Done: Saved" || got.ParseMode != "" {
+		t.Errorf("plainChattable() = %q with parse mode %q, want plain text", got.Text, got.ParseMode)
+	}
+	button := got.ReplyMarkup.(tgbotapi.InlineKeyboardMarkup).InlineKeyboard[0][0]
+	if button.Text != "NOTE" || *button.CallbackData != "note" {
+		t.Errorf("button = %q (%s), want its label without emoji", button.Text, *button.CallbackData)
+	}
+	if keyboard.InlineKeyboard[0][0].Text != "📝 NOTE" {
+		t.Error("plainChattable() changed the shared keyboard")
+	}
+
+	edit := tgbotapi.NewEditMessageText(42, 7, "⏳ Processing... [▓▓▓░░░░░░░] 30%")
+	if got := b.plainChattable(42, edit).(tgbotapi.EditMessageTextConfig); got.Text != "Processing... 30%" {
+		t.Errorf("plainChattable(edit) = %q", got.Text)
+	}
+}
@@ -0,0 +1,230 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Private entries: messages prefixed with "!" are committed to the user's private repository
+
+const (
+	privateEntryPrefix = "!"
+
+	// Destination repository of a pending entry
+	entryRepoMain    = "main"
+	entryRepoPrivate = "private"
+)
+
+// parsePrivatePrefix strips the private marker from a message, reporting whether it was present
+func parsePrivatePrefix(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, privateEntryPrefix) {
+		return text, false
+	}
+
+	content := strings.TrimSpace(strings.TrimPrefix(trimmed, privateEntryPrefix))
+	if content == "" {
+		return text, false
+	}
+	return content, true
+}
+
+// normalizeRepoURL accepts "owner/repo" or a GitHub URL and returns the HTTPS URL on host
+func normalizeRepoURL(input, host string) (string, error) {
+	input = strings.TrimSpace(input)
+	if !strings.HasPrefix(input, "https://") && !strings.HasPrefix(input, "git@") {
+		input = "https://" + host + "/" + strings.Trim(input, "/")
+	}
+
+	owner, repo, err := parseGitHubRepoURL(input)
+	if err != nil || owner == "" || repo == "" {
+		return "", fmt.Errorf("invalid GitHub repository, use owner/repo or https://%s/owner/repo", host)
+	}
+	return fmt.Sprintf("https://%s/%s/%s", host, owner, repo), nil
+}
+
+// pendingEntryData returns the pending data of an entry waiting for its file:
+// content|||DELIM|||messageID|||DELIM|||repository. The destination is kept with the content, so it
+// can't get lost while the entry waits.
+func pendingEntryData(content string, messageID int, private bool) string {
+	repo := entryRepoMain
+	if private {
+		repo = entryRepoPrivate
+	}
+	return fmt.Sprintf("%s|||DELIM|||%d|||DELIM|||%s", content, messageID, repo)
+}
+
+// parsePendingEntry returns the content and message ID of pending entry data, and whether the entry
+// goes to the private repository. Data that doesn't name its repository is refused rather than
+// saved to the main repository.
+func parsePendingEntry(messageData string) (string, int, bool, error) {
+	parts := strings.SplitN(messageData, "|||DELIM|||", 3)
+	if len(parts) != 3 {
+		return "", 0, false, fmt.Errorf("invalid message data format")
+	}
+
+	messageID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, false, fmt.Errorf("invalid message ID: %w", err)
+	}
+
+	switch parts[2] {
+	case entryRepoMain:
+		return parts[0], messageID, false, nil
+	case entryRepoPrivate:
+		return parts[0], messageID, true, nil
+	default:
+		return "", 0, false, fmt.Errorf("unknown destination repository %q", parts[2])
+	}
+}
+
+// hasPrivateRepo reports whether the user has configured a private repository
+func (b *Bot) hasPrivateRepo(chatID int64) bool {
+	if b.db == nil {
+		return false
+	}
+	user, err := b.db.GetUserByChatID(chatID)
+	return err == nil && user != nil && user.HasPrivateRepo()
+}
+
+// getEntryGitHubProvider returns the provider an entry should be committed with:
+// the private repository for private entries, the main repository otherwise
+func (b *Bot) getEntryGitHubProvider(chatID int64, private bool) (github.GitHubProvider, error) {
+	if private {
+		return b.getUserPrivateGitHubProvider(chatID)
+	}
+	return b.getUserGitHubProvider(chatID)
+}
+
+// getUserPrivateGitHubProvider creates a provider for the user's private repository using their GitHub token
+func (b *Bot) getUserPrivateGitHubProvider(chatID int64) (github.GitHubProvider, error) {
+	if b.db == nil {
+		return nil, fmt.Errorf("database is required for GitHub configuration")
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user == nil || !user.HasGitHubConfig() {
+		return nil, fmt.Errorf("user not configured or missing GitHub settings")
+	}
+	if !user.HasPrivateRepo() {
+		return nil, fmt.Errorf("no private repository configured, set one with /private owner/repo")
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	providerType := b.getProviderType(chatID, premiumLevel)
+
+	cacheKey := fmt.Sprintf("github_private_provider_%d", chatID)
+	if cachedProvider, exists := b.cache.Get(cacheKey); exists {
+		if provider, ok := cachedProvider.(github.GitHubProvider); ok && provider.GetProviderType() == providerType {
+			return provider, nil
+		}
+	}
+
+	userConfig := github.NewConfigAdapter(&config.Config{
+		GitHubToken:    user.GitHubToken,
+		GitHubRepo:     user.PrivateRepo,
+		GitHubUsername: b.cfg().GitHubUsername,
+		CommitAuthor:   b.cfg().CommitAuthor,
+	})
+
+	provider, err := b.githubFactory.CreateProvider(providerType, &github.ProviderConfig{
+		Config:          userConfig,
+		PremiumLevel:    premiumLevel,
+		UserID:          fmt.Sprintf("user_%d_private", chatID),
+		ChatID:          chatID,
+		CloneSubmodules: b.cfg().CloneSubmodules,
+		ShallowClone:    b.cfg().ShallowClone(premiumLevel),
+		SparseCheckout:  b.cfg().SparseCheckout(premiumLevel),
+		Committer:       b.providerCommitter(user),
+		APIBaseURL:      user.GitHubAPIURL,
+		Branch:          user.CommitBranch,
+		WriteQuota:      func() error { return b.tenantDiskQuotaError(chatID) },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	b.cache.SetWithExpiry(cacheKey, provider, 30*time.Minute)
+
+	logger.Debug("Created and cached private GitHub provider", map[string]interface{}{
+		"chat_id":       chatID,
+		"provider_type": provider.GetProviderType(),
+	})
+
+	return provider, nil
+}
+
+// handlePrivateCommand shows or changes the private repository:
+// /private, /private <owner/repo>, /private off
+func (b *Bot) handlePrivateCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	arg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/private"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Private entries require a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if arg == "" {
+		status := "🔓 No private repository configured."
+		if user != nil && user.HasPrivateRepo() {
+			status = fmt.Sprintf("🔒 Private repository: <code>%s</code>", html.EscapeString(user.PrivateRepo))
+		}
+		b.sendResponse(chatID, status+`
+
+Start a message with <code>!</code> to save it to your private repository instead of the main one.
+
+• /private owner/repo - Set the private repository
+• /private off - Stop routing private entries`)
+		return nil
+	}
+
+	privateRepo := ""
+	if arg != "off" {
+		privateRepo, err = normalizeRepoURL(arg, github.WebHost(b.userGitHubAPIURL(chatID)))
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		if user != nil && privateRepo == strings.TrimSuffix(user.GitHubRepo, ".git") {
+			b.sendResponse(chatID, "❌ The private repository must differ from your main repository.")
+			return nil
+		}
+	}
+
+	if err := b.db.UpdateUserPrivateRepo(chatID, privateRepo); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to update private repository: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	// Invalidate cached private provider since the repository changed
+	b.cache.Delete(fmt.Sprintf("github_private_provider_%d", chatID))
+
+	if privateRepo == "" {
+		b.sendResponse(chatID, fmt.Sprintf("%s Private repository removed. Messages starting with <code>!</code> are now saved normally.", consts.EmojiSuccess))
+		return nil
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("%s Private repository set to <code>%s</code>\n\nStart a message with <code>!</code> to save it there. Make sure your GitHub token can access this repository.",
+		consts.EmojiSuccess, html.EscapeString(privateRepo)))
+	return nil
+}
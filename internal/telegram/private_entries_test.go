@@ -0,0 +1,83 @@
+package telegram
+
+import "testing"
+
+func TestParsePrivatePrefix(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedContent string
+		expectedPrivate bool
+	}{
+		{input: "!my secret", expectedContent: "my secret", expectedPrivate: true},
+		{input: "  ! spaced out ", expectedContent: "spaced out", expectedPrivate: true},
+		{input: "!\nmultiline\nnote", expectedContent: "multiline\nnote", expectedPrivate: true},
+		{input: "regular note", expectedContent: "regular note", expectedPrivate: false},
+		{input: "wow!", expectedContent: "wow!", expectedPrivate: false},
+		{input: "!", expectedContent: "!", expectedPrivate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			content, private := parsePrivatePrefix(tt.input)
+			if content != tt.expectedContent || private != tt.expectedPrivate {
+				t.Errorf("parsePrivatePrefix(%q) = (%q, %v), want (%q, %v)",
+					tt.input, content, private, tt.expectedContent, tt.expectedPrivate)
+			}
+		})
+	}
+}
+
+func TestNormalizeRepoURL(t *testing.T) {
+	tests := []struct {
+		input     string
+		host      string
+		expected  string
+		expectErr bool
+	}{
+		{input: "alice/journal", expected: "https://github.com/alice/journal"},
+		{input: "https://github.com/alice/journal.git", expected: "https://github.com/alice/journal"},
+		{input: "git@github.com:alice/journal.git", expected: "https://github.com/alice/journal"},
+		{input: "journal", expectErr: true},
+		{input: "alice/journal", host: "github.example.com", expected: "https://github.example.com/alice/journal"},
+		{input: "https://github.example.com/alice/journal", host: "github.example.com", expected: "https://github.example.com/alice/journal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			host := tt.host
+			if host == "" {
+				host = "github.com"
+			}
+			result, err := normalizeRepoURL(tt.input, host)
+			if tt.expectErr {
+				if err == nil {
+					t.Errorf("normalizeRepoURL(%q) expected error, got %q", tt.input, result)
+				}
+				return
+			}
+			if err != nil || result != tt.expected {
+				t.Errorf("normalizeRepoURL(%q) = (%q, %v), want %q", tt.input, result, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPendingEntryRoundTrip(t *testing.T) {
+	for _, private := range []bool{false, true} {
+		content, messageID, gotPrivate, err := parsePendingEntry(pendingEntryData("line 1\nline 2", 42, private))
+		if err != nil {
+			t.Fatalf("parsePendingEntry() error = %v", err)
+		}
+		if content != "line 1\nline 2" || messageID != 42 || gotPrivate != private {
+			t.Errorf("parsePendingEntry() = %q, %d, %v, want %q, 42, %v", content, messageID, gotPrivate, "line 1\nline 2", private)
+		}
+	}
+}
+
+func TestParsePendingEntryRefusesMissingRepository(t *testing.T) {
+	for _, data := range []string{"note|||DELIM|||42", "note|||DELIM|||42|||DELIM|||", "note|||DELIM|||42|||DELIM|||other", "note|||DELIM|||abc|||DELIM|||main"} {
+		if _, _, _, err := parsePendingEntry(data); err == nil {
+			t.Errorf("parsePendingEntry(%q) succeeded, want an error", data)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Timezones of quiet hours, the runtime image has no zoneinfo
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Quiet hours: a daily window in the user's timezone, set with /quiet, during which non-essential
+// messages (quota nudges, failure digests, feed digest notices, insight reports) are stored instead
+// of sent. They are delivered together in one batch once the window ends. Replies to the user's own
+// messages and payment notifications are never deferred.
+
+const quietHoursCheckInterval = 5 * time.Minute
+
+// Kinds of deferrable messages
+const (
+	deferredQuotaAlert    = "quota_alert"
+	deferredFailureDigest = "failure_digest"
+	deferredFeedDigest    = "feed_digest"
+	deferredInsightReport = "insight_report"
+)
+
+// deferredSeparator separates the messages of a batch
+const deferredSeparator = "\n\n───────────\n\n"
+
+// handleQuietCommand shows, sets or removes the user's quiet hours
+func (b *Bot) handleQuietCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Quiet hours require a database.")
+		return nil
+	}
+
+	quiet, err := b.db.GetQuietHours(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ Failed to load your quiet hours.")
+		return nil
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	switch {
+	case len(args) == 0:
+		b.sendResponse(chatID, b.formatQuietHoursStatus(chatID, quiet, time.Now()))
+		return nil
+
+	case len(args) == 1 && args[0] == "off":
+		if _, err := b.db.DeleteQuietHours(chatID); err != nil {
+			b.sendResponse(chatID, "❌ Failed to turn off quiet hours.")
+			return nil
+		}
+		b.sendResponse(chatID, "🔔 Quiet hours are off. Messages held back so far are delivered now.")
+		if err := b.deliverDeferredMessages(chatID); err != nil {
+			logger.Warn("Failed to deliver deferred messages", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+		return nil
+
+	case len(args) <= 2:
+		start, end, err := parseQuietWindow(args[0])
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s\n\n%s", html.EscapeString(err.Error()), quietHoursUsage))
+			return nil
+		}
+		timezone := "UTC"
+		if quiet != nil {
+			timezone = quiet.Timezone
+		}
+		if len(args) == 2 {
+			if _, err := time.LoadLocation(args[1]); err != nil || args[1] == "Local" {
+				b.sendResponse(chatID, fmt.Sprintf("❌ Unknown timezone <code>%s</code>. Use a name like <code>Europe/Berlin</code> or <code>America/New_York</code>.", html.EscapeString(args[1])))
+				return nil
+			}
+			timezone = args[1]
+		}
+
+		if err := b.db.SetQuietHours(chatID, start, end, timezone); err != nil {
+			b.sendResponse(chatID, "❌ Failed to save your quiet hours.")
+			return nil
+		}
+		quiet, _ = b.db.GetQuietHours(chatID)
+		b.sendResponse(chatID, b.formatQuietHoursStatus(chatID, quiet, time.Now()))
+		return nil
+
+	default:
+		b.sendResponse(chatID, quietHoursUsage)
+		return nil
+	}
+}
+
+const quietHoursUsage = "Usage: <code>/quiet 22:00-07:00 [timezone]</code> or <code>/quiet off</code>"
+
+// formatQuietHoursStatus describes the user's quiet hours and how many messages are waiting
+func (b *Bot) formatQuietHoursStatus(chatID int64, quiet *database.QuietHours, now time.Time) string {
+	if quiet == nil {
+		return "🔔 Quiet hours are off.\n\nUse <code>/quiet 22:00-07:00 Europe/Berlin</code> to hold back reminders, digests and quota nudges at night and get them together in the morning."
+	}
+
+	local := now.In(quietHoursLocation(quiet))
+	state := "not quiet now"
+	if quietHoursActive(quiet, now) {
+		state = "quiet now"
+	}
+	text := fmt.Sprintf("🌙 Quiet hours: <b>%s–%s</b> (%s)\nIt's %s there, %s.",
+		formatQuietMinute(quiet.StartMinute), formatQuietMinute(quiet.EndMinute),
+		html.EscapeString(quiet.Timezone), local.Format("15:04"), state)
+
+	if messages, err := b.db.GetDeferredMessages(chatID); err == nil && len(messages) > 0 {
+		text += fmt.Sprintf("\n\n📬 %d messages are waiting for the end of your quiet hours.", len(messages))
+	}
+	return text + "\n\nUse <code>/quiet off</code> to turn them off."
+}
+
+// parseQuietWindow parses a window like "22:00-07:00" or "22-7" into minutes after midnight
+func parseQuietWindow(window string) (int, int, error) {
+	from, to, ok := strings.Cut(window, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("quiet hours look like 22:00-07:00")
+	}
+	start, err := parseQuietClock(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseQuietClock(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if start == end {
+		return 0, 0, fmt.Errorf("quiet hours must start and end at different times")
+	}
+	return start, end, nil
+}
+
+// parseQuietClock parses "7", "07:30" or "22:00" into minutes after midnight
+func parseQuietClock(clock string) (int, error) {
+	hourText, minuteText, hasMinutes := strings.Cut(clock, ":")
+	hour, err := strconv.Atoi(hourText)
+	if err != nil || hour < 0 || hour > 23 {
+		return 0, fmt.Errorf("invalid time %q", clock)
+	}
+	minute := 0
+	if hasMinutes {
+		minute, err = strconv.Atoi(minuteText)
+		if err != nil || len(minuteText) != 2 || minute < 0 || minute > 59 {
+			return 0, fmt.Errorf("invalid time %q", clock)
+		}
+	}
+	return hour*60 + minute, nil
+}
+
+// formatQuietMinute formats minutes after midnight as HH:MM
+func formatQuietMinute(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// quietHoursLocation returns the timezone of the quiet hours, UTC if it can't be loaded
+func quietHoursLocation(quiet *database.QuietHours) *time.Location {
+	loc, err := time.LoadLocation(quiet.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// quietHoursActive reports whether now falls into the quiet hours, which may span midnight
+func quietHoursActive(quiet *database.QuietHours, now time.Time) bool {
+	local := now.In(quietHoursLocation(quiet))
+	minute := local.Hour()*60 + local.Minute()
+	if quiet.StartMinute < quiet.EndMinute {
+		return minute >= quiet.StartMinute && minute < quiet.EndMinute
+	}
+	return minute >= quiet.StartMinute || minute < quiet.EndMinute
+}
+
+// inQuietHours reports whether the user is in their quiet hours at now
+func (b *Bot) inQuietHours(chatID int64, now time.Time) bool {
+	if b.db == nil {
+		return false
+	}
+	quiet, err := b.db.GetQuietHours(chatID)
+	if err != nil {
+		logger.Warn("Failed to load quiet hours", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return false
+	}
+	return quiet != nil && quietHoursActive(quiet, now)
+}
+
+// sendNonEssential sends an HTML message, or defers it until the user's quiet hours end
+func (b *Bot) sendNonEssential(chatID int64, kind, text string) error {
+	if b.inQuietHours(chatID, time.Now()) {
+		err := b.db.DeferMessage(chatID, kind, text)
+		if err == nil {
+			logger.Debug("Message deferred for quiet hours", map[string]interface{}{
+				"chat_id": chatID,
+				"kind":    kind,
+			})
+			return nil
+		}
+		logger.Warn("Failed to defer message, sending it now", map[string]interface{}{
+			"chat_id": chatID,
+			"kind":    kind,
+			"error":   err.Error(),
+		})
+	}
+	return b.sendLongReply(chatID, longReply{Text: text})
+}
+
+// startQuietHours periodically delivers the messages of users whose quiet hours ended
+func (b *Bot) startQuietHours() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopQuietHours = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(quietHoursCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runDeferredDeliveries()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runDeferredDeliveries() {
+	chatIDs, err := b.db.GetChatsWithDeferredMessages()
+	if err != nil {
+		logger.Error("Failed to load chats with deferred messages", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	for _, chatID := range chatIDs {
+		if !b.chatAllowed(chatID) || b.inQuietHours(chatID, now) {
+			continue
+		}
+		if err := b.deliverDeferredMessages(chatID); err != nil {
+			logger.Warn("Failed to deliver deferred messages", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// deliverDeferredMessages sends the user's deferred messages as one batch
+func (b *Bot) deliverDeferredMessages(chatID int64) error {
+	messages, err := b.db.GetDeferredMessages(chatID)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if err := b.sendLongReply(chatID, longReply{Text: formatDeferredMessages(messages)}); err != nil {
+		return err
+	}
+
+	logger.Info("Delivered deferred messages", map[string]interface{}{
+		"chat_id": chatID,
+		"count":   len(messages),
+	})
+	return b.db.DeleteDeferredMessages(chatID, messages[len(messages)-1].ID)
+}
+
+// formatDeferredMessages joins deferred messages into one, under a heading if there are several
+func formatDeferredMessages(messages []*database.DeferredMessage) string {
+	if len(messages) == 1 {
+		return messages[0].Text
+	}
+
+	texts := make([]string, len(messages))
+	for i, message := range messages {
+		texts[i] = message.Text
+	}
+	return fmt.Sprintf("🌙 <b>%d messages from your quiet hours</b>%s%s", len(messages), deferredSeparator, strings.Join(texts, deferredSeparator))
+}
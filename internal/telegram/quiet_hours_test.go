@@ -0,0 +1,76 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestParseQuietWindow(t *testing.T) {
+	tests := []struct {
+		window     string
+		start, end int
+		wantErr    bool
+	}{
+		{window: "22:00-07:00", start: 22 * 60, end: 7 * 60},
+		{window: "22-7", start: 22 * 60, end: 7 * 60},
+		{window: "13:30-14:15", start: 13*60 + 30, end: 14*60 + 15},
+		{window: "22:00", wantErr: true},
+		{window: "24:00-07:00", wantErr: true},
+		{window: "22:5-07:00", wantErr: true},
+		{window: "08:00-08:00", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := parseQuietWindow(tt.window)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseQuietWindow(%q) error = %v, wantErr %v", tt.window, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (start != tt.start || end != tt.end) {
+			t.Errorf("parseQuietWindow(%q) = %d, %d, want %d, %d", tt.window, start, end, tt.start, tt.end)
+		}
+	}
+}
+
+func TestQuietHoursActive(t *testing.T) {
+	overnight := &database.QuietHours{StartMinute: 22 * 60, EndMinute: 7 * 60, Timezone: "Europe/Berlin"}
+	afternoon := &database.QuietHours{StartMinute: 13 * 60, EndMinute: 14 * 60, Timezone: "UTC"}
+
+	tests := []struct {
+		name  string
+		quiet *database.QuietHours
+		now   time.Time
+		want  bool
+	}{
+		{"before midnight in Berlin", overnight, time.Date(2026, 1, 10, 22, 30, 0, 0, time.UTC), true}, // 23:30 CET
+		{"early morning in Berlin", overnight, time.Date(2026, 1, 10, 5, 59, 0, 0, time.UTC), true},    // 06:59 CET
+		{"window end in Berlin", overnight, time.Date(2026, 1, 10, 6, 0, 0, 0, time.UTC), false},       // 07:00 CET
+		{"summer time in Berlin", overnight, time.Date(2026, 7, 10, 20, 0, 0, 0, time.UTC), true},      // 22:00 CEST
+		{"daytime in Berlin", overnight, time.Date(2026, 7, 10, 12, 0, 0, 0, time.UTC), false},
+		{"inside same-day window", afternoon, time.Date(2026, 1, 10, 13, 30, 0, 0, time.UTC), true},
+		{"outside same-day window", afternoon, time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		if got := quietHoursActive(tt.quiet, tt.now); got != tt.want {
+			t.Errorf("%s: quietHoursActive() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDeferredMessages(t *testing.T) {
+	single := []*database.DeferredMessage{{ID: 1, Kind: deferredQuotaAlert, Text: "🟡 <b>Quota</b>"}}
+	if got := formatDeferredMessages(single); got != "🟡 <b>Quota</b>" {
+		t.Errorf("formatDeferredMessages(single) = %q, want the message unchanged", got)
+	}
+
+	batch := append(single, &database.DeferredMessage{ID: 2, Kind: deferredFeedDigest, Text: "📰 Feed digest"})
+	got := formatDeferredMessages(batch)
+	if !strings.HasPrefix(got, "🌙 <b>2 messages from your quiet hours</b>") {
+		t.Errorf("formatDeferredMessages(batch) heading = %q", got)
+	}
+	if strings.Index(got, "Quota") > strings.Index(got, "Feed digest") {
+		t.Errorf("formatDeferredMessages(batch) = %q, want oldest first", got)
+	}
+}
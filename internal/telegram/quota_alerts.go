@@ -0,0 +1,146 @@
+package telegram
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/limits"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Quota alerts: users are nudged at 80% and 95% of a limit instead of only being blocked at 100%
+
+// quotaAlertThresholds are checked highest first so a jump past both only sends one alert
+var quotaAlertThresholds = []int{95, 80}
+
+// quotaAlertRepoPeriod is how long a repository size alert stays quiet, usage alerts re-arm on usage reset
+const quotaAlertRepoPeriod = 30 * 24 * time.Hour
+
+// quotaAlertThreshold returns the highest threshold reached by percentage, or 0 if none
+func quotaAlertThreshold(percentage float64) int {
+	for _, threshold := range quotaAlertThresholds {
+		if percentage >= float64(threshold) {
+			return threshold
+		}
+	}
+	return 0
+}
+
+// checkQuotaAlerts sends a one-time nudge for every quota that crossed a threshold.
+// provider may be nil when the repository size doesn't need checking.
+func (b *Bot) checkQuotaAlerts(chatID int64, provider github.GitHubProvider) {
+	if b.db == nil {
+		return
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+
+	if provider != nil {
+		maxSizeMB := provider.GetRepositoryMaxSizeWithPremium(premiumLevel)
+		if _, percentage, _, _, err := b.getRepositorySizeWithCache(chatID, provider, premiumLevel); err == nil {
+			b.sendQuotaAlert(chatID, database.QuotaMetricRepo, percentage, int64(maxSizeMB*1024*1024), premiumLevel, time.Now().Add(-quotaAlertRepoPeriod))
+		}
+	}
+
+	// Usage alerts are cleared when usage is reset, so any earlier alert belongs to this period
+	var sinceUsageReset time.Time
+	results, err := b.usageLimits().All(chatID, premiumLevel)
+	if err != nil {
+		return
+	}
+
+	metrics := map[limits.Kind]string{
+		limits.Issues: database.QuotaMetricIssues,
+		limits.Images: database.QuotaMetricImages,
+		limits.Files:  database.QuotaMetricFiles,
+		limits.Tokens: database.QuotaMetricTokens,
+	}
+	for _, result := range results {
+		b.sendQuotaAlert(chatID, metrics[result.Kind], result.Percentage(), result.Max, premiumLevel, sinceUsageReset)
+	}
+}
+
+func (b *Bot) sendQuotaAlert(chatID int64, metric string, percentage float64, limit int64, premiumLevel int, since time.Time) {
+	threshold := quotaAlertThreshold(percentage)
+	if threshold == 0 || limit <= 0 {
+		return
+	}
+
+	shouldSend, err := b.db.RecordQuotaAlert(chatID, metric, threshold, limit, since)
+	if err != nil {
+		logger.Warn("Failed to record quota alert", map[string]interface{}{
+			"chat_id": chatID,
+			"metric":  metric,
+			"error":   err.Error(),
+		})
+		return
+	}
+	if !shouldSend {
+		return
+	}
+
+	// Reaching the higher threshold first also settles the lower one
+	for _, lower := range quotaAlertThresholds {
+		if lower < threshold {
+			b.db.RecordQuotaAlert(chatID, metric, lower, limit, since)
+		}
+	}
+
+	logger.Info("Sending quota alert", map[string]interface{}{
+		"chat_id":    chatID,
+		"metric":     metric,
+		"threshold":  threshold,
+		"percentage": percentage,
+	})
+
+	if err := b.sendNonEssential(chatID, deferredQuotaAlert, formatQuotaAlert(metric, threshold, percentage, premiumLevel)); err != nil {
+		logger.Warn("Failed to send quota alert", map[string]interface{}{
+			"chat_id": chatID,
+			"metric":  metric,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// formatQuotaAlert builds the nudge message with suggestions for the metric
+func formatQuotaAlert(metric string, threshold int, percentage float64, premiumLevel int) string {
+	var label, suggestions string
+	switch metric {
+	case database.QuotaMetricRepo:
+		label = "📊 Repository size"
+		suggestions = `• Move older notes out of large files into dated archive files
+• Empty /trash to drop deleted files for good
+• Remove images and assets you no longer need`
+	case database.QuotaMetricIssues:
+		label = "📝 Issue creation"
+		suggestions = "• Use /resetusage to reset your usage counters"
+	case database.QuotaMetricImages:
+		label = "📷 Image uploads"
+		suggestions = "• Use /resetusage to reset your usage counters"
+	case database.QuotaMetricFiles:
+		label = "📎 File attachments"
+		suggestions = "• Use /resetusage to reset your usage counters"
+	case database.QuotaMetricTokens:
+		label = "🧠 LLM tokens"
+		suggestions = `• Set your own LLM token with /llm to stop using the shared quota
+• Use /resetusage to reset your usage counters`
+	default:
+		return ""
+	}
+
+	if premiumLevel < 3 {
+		suggestions += "\n• Upgrade with /coffee for higher limits"
+	}
+
+	emoji := "🟡"
+	if threshold >= 95 {
+		emoji = "🔴"
+	}
+
+	return fmt.Sprintf(`%s <b>%s at %.0f%% of your limit</b>
+
+You'll be blocked once it reaches 100%%. Some options:
+%s`, emoji, label, percentage, suggestions)
+}
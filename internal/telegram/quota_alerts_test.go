@@ -0,0 +1,47 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestQuotaAlertThreshold(t *testing.T) {
+	tests := []struct {
+		percentage float64
+		want       int
+	}{
+		{0, 0},
+		{79.9, 0},
+		{80, 80},
+		{94.5, 80},
+		{95, 95},
+		{120, 95},
+	}
+
+	for _, tt := range tests {
+		if got := quotaAlertThreshold(tt.percentage); got != tt.want {
+			t.Errorf("quotaAlertThreshold(%v) = %d, want %d", tt.percentage, got, tt.want)
+		}
+	}
+}
+
+func TestFormatQuotaAlert(t *testing.T) {
+	msg := formatQuotaAlert(database.QuotaMetricRepo, 80, 82.4, 0)
+	if !strings.Contains(msg, "Repository size at 82%") || !strings.Contains(msg, "/trash") || !strings.Contains(msg, "/coffee") {
+		t.Errorf("unexpected repo alert: %s", msg)
+	}
+
+	msg = formatQuotaAlert(database.QuotaMetricTokens, 95, 96, 3)
+	if !strings.Contains(msg, "🔴") || !strings.Contains(msg, "/llm") {
+		t.Errorf("unexpected token alert: %s", msg)
+	}
+	if strings.Contains(msg, "/coffee") {
+		t.Errorf("highest tier should not get an upgrade suggestion: %s", msg)
+	}
+
+	if msg := formatQuotaAlert("unknown", 80, 80, 0); msg != "" {
+		t.Errorf("unknown metric should produce no alert, got %q", msg)
+	}
+}
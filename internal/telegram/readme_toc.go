@@ -0,0 +1,373 @@
+package telegram
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// README table of contents: with /readme on, the bot maintains a generated section of README.md in
+// the notes repository linking every tracked file, the latest captures and a few stats. The section
+// sits between two HTML comment markers, so the rest of README.md is left as the user wrote it.
+// It is written when turned on and on /readme refresh, and added to multi-file commits (/sync,
+// /bulk) whenever the tracked files differ from the ones it lists.
+
+const (
+	readmeFilename   = "README.md"
+	readmeTOCStart   = "<!-- msg2git:toc:start -->"
+	readmeTOCEnd     = "<!-- msg2git:toc:end -->"
+	readmeRecentSize = 5
+	readmeRecentDays = 30
+)
+
+// readmeFileTitles describes the default files in the order they are listed
+var readmeFileTitles = []struct {
+	Filename string
+	Title    string
+}{
+	{consts.FileNameNote, "Notes"},
+	{consts.FileNameTodo, "TODOs"},
+	{consts.FileNameIssue, "Issues"},
+	{consts.FileNameIdea, "Ideas"},
+	{consts.FileNameInbox, "Inbox"},
+	{consts.FileNameTool, "Tools"},
+}
+
+// readmeTOC is the content of the generated README section
+type readmeTOC struct {
+	Files    []string
+	Recent   []*database.CommitLogEntry // Newest first
+	Insights *database.UserInsights     // nil if unknown
+	Streak   *database.Streak           // nil if unknown
+	Date     time.Time
+}
+
+const readmeUsage = `
+
+• /readme on - Maintain the table of contents
+• /readme refresh - Regenerate it now
+• /readme off - Stop maintaining it, README.md is left as is`
+
+// handleReadmeCommand shows or changes README maintenance: /readme [on|off|refresh]
+func (b *Bot) handleReadmeCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ README maintenance requires a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch strings.ToLower(strings.TrimSpace(message.CommandArguments())) {
+	case "":
+		if user != nil && user.ReadmeTOC {
+			b.sendResponse(chatID, "📚 README.md of your repository has a table of contents of your files, recent entries and stats, updated when files are added or removed."+readmeUsage)
+			return nil
+		}
+		b.sendResponse(chatID, "📚 Want an overview of your notes on your repository's front page? I can maintain a table of contents in README.md linking all your files, your recent entries and stats. The rest of README.md stays untouched."+readmeUsage)
+		return nil
+
+	case "on":
+		if err := b.db.UpdateUserReadmeTOC(chatID, true); err != nil {
+			b.sendResponse(chatID, "❌ Failed to turn on README maintenance.")
+			return nil
+		}
+		return b.refreshReadmeTOC(chatID, "✅ README maintenance turned on.")
+
+	case "refresh":
+		if user == nil || !user.ReadmeTOC {
+			b.sendResponse(chatID, "📚 README maintenance is off. Turn it on with <code>/readme on</code>.")
+			return nil
+		}
+		return b.refreshReadmeTOC(chatID, "✅ README.md regenerated.")
+
+	case "off":
+		if err := b.db.UpdateUserReadmeTOC(chatID, false); err != nil {
+			b.sendResponse(chatID, "❌ Failed to turn off README maintenance.")
+			return nil
+		}
+		b.sendResponse(chatID, "📚 README maintenance turned off. The table of contents stays in README.md until you remove it.")
+		return nil
+
+	default:
+		b.sendResponse(chatID, "❌ Unknown option."+readmeUsage)
+		return nil
+	}
+}
+
+// refreshReadmeTOC regenerates the section of README.md and commits it if it changed
+func (b *Bot) refreshReadmeTOC(chatID int64, done string) error {
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("%s\n\n❌ %v", done, err))
+		return nil
+	}
+
+	readme, err := provider.ReadFile(readmeFilename)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("%s\n\n❌ Failed to read README.md: %v", done, err))
+		return nil
+	}
+	toc, err := b.collectReadmeTOC(chatID, provider, nil)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("%s\n\n❌ %v", done, err))
+		return nil
+	}
+
+	updated := mergeReadmeTOC(readme, formatReadmeTOC(toc))
+	if updated == readme {
+		b.sendResponse(chatID, done+" README.md is already up to date.")
+		return nil
+	}
+	if err := provider.ReplaceFileWithAuthorAndPremium(readmeFilename, updated, "Update README table of contents via Telegram", b.getCommitterInfo(chatID), b.getPremiumLevel(chatID)); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("%s\n\n❌ Failed to commit README.md: %v", done, err))
+		return nil
+	}
+
+	logger.Info("Regenerated README table of contents", map[string]interface{}{
+		"chat_id": chatID,
+		"files":   len(toc.Files),
+	})
+	b.sendResponse(chatID, fmt.Sprintf("%s README.md lists %d files.", done, len(toc.Files)))
+	return nil
+}
+
+// withReadmeTOC returns the files of a multi-file commit with README.md added when the user has
+// README maintenance on and the tracked files changed. Failures are logged and never block the commit.
+func (b *Bot) withReadmeTOC(chatID int64, provider github.GitHubProvider, files map[string]string) map[string]string {
+	if b.db == nil {
+		return files
+	}
+	if _, ok := files[readmeFilename]; ok {
+		return files
+	}
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil || !user.ReadmeTOC {
+		return files
+	}
+
+	warn := func(err error) map[string]string {
+		logger.Warn("Failed to update README table of contents", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return files
+	}
+
+	readme, err := provider.ReadFile(readmeFilename)
+	if err != nil {
+		return warn(err)
+	}
+	toc, err := b.collectReadmeTOC(chatID, provider, files)
+	if err != nil {
+		return warn(err)
+	}
+	if !readmeStructureChanged(readme, toc.Files) {
+		return files
+	}
+
+	withReadme := make(map[string]string, len(files)+1)
+	for filename, content := range files {
+		withReadme[filename] = content
+	}
+	withReadme[readmeFilename] = mergeReadmeTOC(readme, formatReadmeTOC(toc))
+	return withReadme
+}
+
+// collectReadmeTOC gathers the tracked files, recent entries and stats of the section. pending
+// holds the files about to be committed, which count as tracked unless empty.
+func (b *Bot) collectReadmeTOC(chatID int64, provider github.GitHubProvider, pending map[string]string) (*readmeTOC, error) {
+	entries, err := provider.ListDirectory("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+
+	tracked := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.Type == "file" && isReadmeTrackedFile(entry.Path) {
+			tracked[entry.Path] = true
+		}
+	}
+	for filename, content := range pending {
+		if isReadmeTrackedFile(filename) {
+			tracked[filename] = content != ""
+		}
+	}
+
+	// Custom files may live in folders, which the root listing doesn't cover
+	if user, err := b.db.GetUserByChatID(chatID); err == nil && user != nil {
+		for _, filename := range user.GetCustomFiles() {
+			if _, known := tracked[filename]; known || !strings.Contains(filename, "/") {
+				continue
+			}
+			// The start of a file tells whether it has content
+			if content, _, err := github.ReadFileHead(provider, filename, 64); err == nil && content != "" {
+				tracked[filename] = true
+			}
+		}
+	}
+
+	var files []string
+	for filename, present := range tracked {
+		if present {
+			files = append(files, filename)
+		}
+	}
+
+	now := time.Now()
+	toc := &readmeTOC{Files: sortReadmeFiles(files), Date: now}
+
+	repo := providerRepo(provider)
+	if commits, err := b.db.GetCommitsBetween(chatID, now.AddDate(0, 0, -readmeRecentDays), now); err == nil {
+		for i := len(commits) - 1; i >= 0 && len(toc.Recent) < readmeRecentSize; i-- {
+			if commits[i].Repo == "" || commits[i].Repo == repo {
+				toc.Recent = append(toc.Recent, commits[i])
+			}
+		}
+	}
+	if insights, err := b.db.GetUserInsights(chatID); err == nil {
+		toc.Insights = insights
+	}
+	if streak, err := b.db.GetStreak(chatID); err == nil {
+		toc.Streak = streak
+	}
+
+	return toc, nil
+}
+
+// isReadmeTrackedFile reports whether a file belongs in the table of contents
+func isReadmeTrackedFile(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".md") && !strings.EqualFold(filename, readmeFilename)
+}
+
+// sortReadmeFiles orders the default files first, in their usual order, and the others by path
+func sortReadmeFiles(files []string) []string {
+	rank := func(filename string) int {
+		for i, file := range readmeFileTitles {
+			if file.Filename == filename {
+				return i
+			}
+		}
+		return len(readmeFileTitles)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		ri, rj := rank(files[i]), rank(files[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return files[i] < files[j]
+	})
+	return files
+}
+
+// readmeLink returns a Markdown link to a file of the repository, relative to README.md
+func readmeLink(filename string) string {
+	return fmt.Sprintf("[%s](%s)", filename, (&url.URL{Path: filename}).EscapedPath())
+}
+
+// formatReadmeContents formats the list of tracked files, which tells whether the structure changed
+func formatReadmeContents(files []string) string {
+	if len(files) == 0 {
+		return "_No files yet._\n"
+	}
+
+	var sb strings.Builder
+	for _, filename := range files {
+		line := "- " + readmeLink(filename)
+		for _, file := range readmeFileTitles {
+			if file.Filename == filename {
+				line += " · " + file.Title
+			}
+		}
+		if dir := path.Dir(filename); dir != "." {
+			line += " · in " + dir + "/"
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
+// formatReadmeTOC renders the generated section of README.md, markers included
+func formatReadmeTOC(toc *readmeTOC) string {
+	var sb strings.Builder
+	sb.WriteString(readmeTOCStart + "\n")
+	sb.WriteString("## 📚 Contents\n\n")
+	sb.WriteString(formatReadmeContents(toc.Files))
+
+	if len(toc.Recent) > 0 {
+		sb.WriteString("\n## 🕒 Recent entries\n\n")
+		for _, entry := range toc.Recent {
+			line := fmt.Sprintf("- %s · %s", entry.CreatedAt.Format("2006-01-02"), readmeLink(entry.Filename))
+			if entry.CommitURL != "" && len(entry.CommitSHA) >= 7 {
+				line += fmt.Sprintf(" · [`%s`](%s)", entry.CommitSHA[:7], entry.CommitURL)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	sb.WriteString("\n## 📊 Stats\n\n")
+	sb.WriteString(fmt.Sprintf("- Files: %d\n", len(toc.Files)))
+	if toc.Insights != nil {
+		sb.WriteString(fmt.Sprintf("- Commits: %d\n", toc.Insights.CommitCnt))
+		if toc.Insights.IssueCnt > 0 {
+			sb.WriteString(fmt.Sprintf("- Issues created: %d\n", toc.Insights.IssueCnt))
+		}
+		if toc.Insights.ImageCnt > 0 {
+			sb.WriteString(fmt.Sprintf("- Images uploaded: %d\n", toc.Insights.ImageCnt))
+		}
+	}
+	if toc.Streak != nil && toc.Streak.Best > 0 {
+		sb.WriteString(fmt.Sprintf("- Streak: %s (best: %s)\n", formatStreak(toc.Streak.Current), formatStreak(toc.Streak.Best)))
+	}
+
+	sb.WriteString(fmt.Sprintf("\n_Generated by msg2git on %s when files are added or removed. Changes between the msg2git markers are overwritten._\n", toc.Date.Format("2006-01-02")))
+	sb.WriteString(readmeTOCEnd)
+	return sb.String()
+}
+
+// readmeTOCSection returns the bounds of the generated section of readme, markers included
+func readmeTOCSection(readme string) (start, end int, ok bool) {
+	start = strings.Index(readme, readmeTOCStart)
+	if start < 0 {
+		return 0, 0, false
+	}
+	end = strings.Index(readme[start:], readmeTOCEnd)
+	if end < 0 {
+		return 0, 0, false
+	}
+	return start, start + end + len(readmeTOCEnd), true
+}
+
+// readmeStructureChanged reports whether the section of readme doesn't list exactly these files
+func readmeStructureChanged(readme string, files []string) bool {
+	start, end, ok := readmeTOCSection(readme)
+	if !ok {
+		return true
+	}
+	return !strings.Contains(readme[start:end], "## 📚 Contents\n\n"+formatReadmeContents(files)+"\n")
+}
+
+// mergeReadmeTOC replaces the generated section of readme, or appends it if there is none
+func mergeReadmeTOC(readme, section string) string {
+	if start, end, ok := readmeTOCSection(readme); ok {
+		return readme[:start] + section + readme[end:]
+	}
+	if strings.TrimSpace(readme) == "" {
+		return "# Notes\n\n" + section + "\n"
+	}
+	return strings.TrimRight(readme, "\n") + "\n\n" + section + "\n"
+}
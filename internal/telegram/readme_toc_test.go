@@ -0,0 +1,86 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestSortReadmeFiles(t *testing.T) {
+	got := sortReadmeFiles([]string{"work/meeting.md", "idea.md", "issue_archived.md", "note.md", "journal.md"})
+	want := []string{"note.md", "idea.md", "issue_archived.md", "journal.md", "work/meeting.md"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("sortReadmeFiles() = %v, want %v", got, want)
+	}
+}
+
+func TestFormatReadmeTOC(t *testing.T) {
+	toc := &readmeTOC{
+		Files: []string{"note.md", "work/team notes.md"},
+		Recent: []*database.CommitLogEntry{{
+			Filename:  "note.md",
+			CommitSHA: "0123456789abcdef",
+			CommitURL: "https://github.com/owner/notes/commit/0123456789abcdef",
+			CreatedAt: time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC),
+		}},
+		Insights: &database.UserInsights{CommitCnt: 42},
+		Streak:   &database.Streak{Current: 3, Best: 8},
+		Date:     time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	section := formatReadmeTOC(toc)
+	for _, want := range []string{
+		"- [note.md](note.md) · Notes\n",
+		"- [work/team notes.md](work/team%20notes.md) · in work/\n",
+		"- 2026-10-14 · [note.md](note.md) · [`0123456`](https://github.com/owner/notes/commit/0123456789abcdef)\n",
+		"- Commits: 42\n",
+		"- Streak: 3 days (best: 8 days)\n",
+	} {
+		if !strings.Contains(section, want) {
+			t.Errorf("formatReadmeTOC() is missing %q in:\n%s", want, section)
+		}
+	}
+	if !strings.HasPrefix(section, readmeTOCStart) || !strings.HasSuffix(section, readmeTOCEnd) {
+		t.Errorf("formatReadmeTOC() isn't enclosed in the markers:\n%s", section)
+	}
+}
+
+func TestMergeReadmeTOC(t *testing.T) {
+	section := readmeTOCStart + "\nnew\n" + readmeTOCEnd
+
+	tests := []struct {
+		name   string
+		readme string
+		want   string
+	}{
+		{"empty", "", "# Notes\n\n" + section + "\n"},
+		{"appended", "# My notes\n\nHello\n\n", "# My notes\n\nHello\n\n" + section + "\n"},
+		{"replaced", "# My notes\n" + readmeTOCStart + "\nold\n" + readmeTOCEnd + "\nFooter\n", "# My notes\n" + section + "\nFooter\n"},
+	}
+
+	for _, tt := range tests {
+		if got := mergeReadmeTOC(tt.readme, section); got != tt.want {
+			t.Errorf("%s: mergeReadmeTOC() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadmeStructureChanged(t *testing.T) {
+	files := []string{"note.md", "todo.md"}
+	readme := mergeReadmeTOC("# Notes\n", formatReadmeTOC(&readmeTOC{Files: files, Date: time.Now()}))
+
+	if readmeStructureChanged(readme, files) {
+		t.Error("readmeStructureChanged() = true for the files the README lists")
+	}
+	if !readmeStructureChanged(readme, []string{"note.md"}) {
+		t.Error("readmeStructureChanged() = false after a file was removed")
+	}
+	if !readmeStructureChanged(readme, append(files, "journal.md")) {
+		t.Error("readmeStructureChanged() = false after a file was added")
+	}
+	if !readmeStructureChanged("# Notes\n", files) {
+		t.Error("readmeStructureChanged() = false for a README without the section")
+	}
+}
@@ -0,0 +1,33 @@
+package telegram
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/render"
+)
+
+// Sending messages built with the render package
+
+// newRenderedMessage returns a message config sending msg with its parse mode
+func newRenderedMessage(chatID int64, msg *render.Message) tgbotapi.MessageConfig {
+	config := tgbotapi.NewMessage(chatID, msg.String())
+	config.ParseMode = msg.ParseMode()
+	config.DisableWebPagePreview = true
+	return config
+}
+
+// newRenderedEdit returns an edit config replacing a message's text with msg
+func newRenderedEdit(chatID int64, messageID int, msg *render.Message) tgbotapi.EditMessageTextConfig {
+	config := tgbotapi.NewEditMessageText(chatID, messageID, msg.String())
+	config.ParseMode = msg.ParseMode()
+	config.DisableWebPagePreview = true
+	return config
+}
+
+// sendRendered sends msg, HTML beyond Telegram's limit goes out in several parts
+func (b *Bot) sendRendered(chatID int64, msg *render.Message) error {
+	if msg.Format() == render.HTML && telegramLength(msg.String()) > telegramMessageLimit {
+		return b.sendLongReply(chatID, longReply{Text: msg.String()})
+	}
+	_, err := b.rateLimitedSend(chatID, newRenderedMessage(chatID, msg))
+	return err
+}
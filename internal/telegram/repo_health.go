@@ -0,0 +1,232 @@
+package telegram
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Repository health: saving to a repository that keeps failing for reasons only the user can fix,
+// like a revoked token or a deleted repository, is pointless. After repoFailureThreshold such
+// failures in a row the chat is degraded and getUserGitHubProvider refuses it with an error
+// pointing to /repo. A preflight (see github.CheckRepoAccess) checks the repository again at most
+// every repoPreflightInterval, or right away once the settings changed, and a success recovers.
+
+const (
+	repoFailureThreshold  = 3
+	repoPreflightInterval = 5 * time.Minute
+)
+
+// repoHealthState tracks the consecutive setup failures of a chat's repository
+type repoHealthState struct {
+	mu            sync.Mutex
+	failures      int
+	lastError     string
+	degraded      bool
+	settings      string // Fingerprint of the settings that failed, see repoSettingsFingerprint
+	lastPreflight time.Time
+	notified      bool // Whether the degraded notice was sent
+}
+
+// errRepoDegraded is returned instead of a provider while the chat's repository is degraded
+var errRepoDegraded = errors.New("your repository needs attention")
+
+// isRepoSetupError reports whether err is a failure the user has to fix in their settings, as
+// opposed to GitHub being down or rate limits. Refusals of a degraded repository don't count again.
+func isRepoSetupError(err error) bool {
+	if err == nil || errors.Is(err, errRepoDegraded) {
+		return false
+	}
+	errStr := strings.ToLower(err.Error())
+	if strings.Contains(errStr, "rate limit") {
+		return false
+	}
+	for _, pattern := range []string{"authorization failed", "authentication", "bad credentials", "unauthorized", "forbidden", "repository not found", "access denied", "can't push"} {
+		if strings.Contains(errStr, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// saveFailureText describes a failed save as "❌ <failure>: <err>", unless the note was committed
+// and only its push is queued for a retry, which isn't a failure the user has to act on
+func saveFailureText(failure string, err error) string {
+	if errors.Is(err, github.ErrPushQueued) {
+		return "⏳ Saved. GitHub didn't accept the push yet, it's retried in the background."
+	}
+	return fmt.Sprintf("❌ %s: %v", failure, err)
+}
+
+// repoHealth returns the health state of chatID's repository
+func (b *Bot) repoHealth(chatID int64) *repoHealthState {
+	state, _ := b.repoHealthStates.LoadOrStore(chatID, &repoHealthState{})
+	return state.(*repoHealthState)
+}
+
+// repoSettingsFingerprint identifies chatID's repository settings without keeping the token
+func (b *Bot) repoSettingsFingerprint(chatID int64) string {
+	if b.db == nil {
+		return ""
+	}
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(user.GitHubRepo + "\x00" + user.GitHubToken + "\x00" + user.GitHubAPIURL))
+	return hex.EncodeToString(sum[:8])
+}
+
+// recordRepoFailure counts a failed save, degrading the chat after repoFailureThreshold setup
+// failures in a row. Other failures don't count.
+func (b *Bot) recordRepoFailure(chatID int64, err error) {
+	if !isRepoSetupError(err) {
+		return
+	}
+
+	state := b.repoHealth(chatID)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	state.failures++
+	state.lastError = err.Error()
+	if state.degraded || state.failures < repoFailureThreshold {
+		return
+	}
+
+	state.degraded = true
+	state.settings = b.repoSettingsFingerprint(chatID)
+	state.lastPreflight = time.Now()
+	logger.Warn("Repository degraded after consecutive failures", map[string]interface{}{
+		"chat_id":  chatID,
+		"failures": state.failures,
+		"error":    state.lastError,
+	})
+}
+
+// recordRepoSuccess resets the failures of chatID's repository after a successful save
+func (b *Bot) recordRepoSuccess(chatID int64) {
+	value, exists := b.repoHealthStates.Load(chatID)
+	if !exists {
+		return
+	}
+
+	state := value.(*repoHealthState)
+	state.mu.Lock()
+	defer state.mu.Unlock()
+
+	if state.degraded {
+		logger.Info("Repository recovered", map[string]interface{}{
+			"chat_id": chatID,
+		})
+	}
+	state.failures = 0
+	state.lastError = ""
+	state.degraded = false
+	state.notified = false
+}
+
+// preflightRepo checks that chatID's settings reach a repository the token can push to
+func (b *Bot) preflightRepo(chatID int64) error {
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user == nil || !user.HasGitHubConfig() {
+		return fmt.Errorf("repository not configured")
+	}
+	return github.CheckRepoAccess(user.GitHubAPIURL, user.GitHubToken, user.GitHubRepo)
+}
+
+// repoDegraded returns an error pointing to /repo if chatID's repository is degraded and the
+// preflight, when due, still fails. The notice with a shortcut to /repo is only sent the first time.
+func (b *Bot) repoDegraded(chatID int64) error {
+	value, exists := b.repoHealthStates.Load(chatID)
+	if !exists || b.db == nil {
+		return nil
+	}
+	state := value.(*repoHealthState)
+
+	state.mu.Lock()
+	if !state.degraded {
+		state.mu.Unlock()
+		return nil
+	}
+	settings := b.repoSettingsFingerprint(chatID)
+	if settings == state.settings && time.Since(state.lastPreflight) < repoPreflightInterval {
+		failures, lastError, notify := state.failures, state.lastError, !state.notified
+		state.notified = true
+		state.mu.Unlock()
+		return b.repoDegradedError(chatID, failures, lastError, notify)
+	}
+	state.lastPreflight = time.Now()
+	state.mu.Unlock()
+
+	err := b.preflightRepo(chatID)
+	if err == nil {
+		b.recordRepoSuccess(chatID)
+		b.sendResponse(chatID, "✅ Your repository is reachable again, saving notes resumed.")
+		return nil
+	}
+
+	logger.Debug("Repository preflight failed", map[string]interface{}{
+		"chat_id": chatID,
+		"error":   err.Error(),
+	})
+	state.mu.Lock()
+	state.settings = settings
+	state.lastError = err.Error()
+	failures, notify := state.failures, !state.notified
+	state.notified = true
+	state.mu.Unlock()
+
+	return b.repoDegradedError(chatID, failures, err.Error(), notify)
+}
+
+// repoDegradedError returns the error refusing operations on a degraded repository, sending the
+// notice first if notify is set
+func (b *Bot) repoDegradedError(chatID int64, failures int, lastError string, notify bool) error {
+	if notify {
+		b.sendRepoDegradedNotice(chatID, failures, lastError)
+	}
+	return fmt.Errorf("%w: saving failed %d times in a row. Check your token and repository with /repo", errRepoDegraded, failures)
+}
+
+// sendRepoDegradedNotice explains why notes aren't saved, with a shortcut to /repo
+func (b *Bot) sendRepoDegradedNotice(chatID int64, failures int, lastError string) {
+	text := fmt.Sprintf(`⚠️ <b>Your repository needs attention</b>
+
+Saving failed %d times in a row: <i>%s</i>
+
+New notes aren't saved until this is fixed. Check your token and repository with /repo, the bot checks again every few minutes and resumes by itself once they work.`,
+		failures, html.EscapeString(lastError))
+
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("🔧 Fix setup", "repo_health_setup"),
+	))
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		logger.Error("Failed to send repository health notice", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// handleRepoHealthSetupCallback opens /repo from the degraded notice
+func (b *Bot) handleRepoHealthSetupCallback(callback *tgbotapi.CallbackQuery) error {
+	return b.handleRepoCommand(&tgbotapi.Message{
+		Chat: callback.Message.Chat,
+		From: callback.From,
+	})
+}
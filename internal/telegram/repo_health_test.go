@@ -0,0 +1,68 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestIsRepoSetupError(t *testing.T) {
+	tests := map[string]bool{
+		consts.GitHubAuthFailed: true,
+		consts.GitHubRepoNotFound + " - check repository URL and permissions": true,
+		"forbidden - token may not have required permissions":                 true,
+		"failed to clone repository: authentication required":                 true,
+		"GitHub API rate limit exceeded - please try again later":             false,
+		"GitHub API error 502: Bad Gateway":                                   false,
+		"API request failed: dial tcp: i/o timeout":                           false,
+	}
+	for message, want := range tests {
+		if got := isRepoSetupError(errors.New(message)); got != want {
+			t.Errorf("isRepoSetupError(%q) = %v, want %v", message, got, want)
+		}
+	}
+	if isRepoSetupError(nil) {
+		t.Error("isRepoSetupError(nil) = true")
+	}
+	if isRepoSetupError(fmt.Errorf("%w: %s", errRepoDegraded, consts.GitHubAuthFailed)) {
+		t.Error("isRepoSetupError() of a degraded refusal = true, want it not to count again")
+	}
+}
+
+func TestSaveFailureText(t *testing.T) {
+	queued := fmt.Errorf("%w: %w", github.ErrPushQueued, errors.New("connection reset"))
+	if got := saveFailureText("Failed to save", queued); !strings.HasPrefix(got, "⏳ Saved.") {
+		t.Errorf("saveFailureText() of a queued push = %q", got)
+	}
+	if got, want := saveFailureText("Failed to save", errors.New("boom")), "❌ Failed to save: boom"; got != want {
+		t.Errorf("saveFailureText() = %q, want %q", got, want)
+	}
+}
+
+func TestRecordRepoFailure(t *testing.T) {
+	b := &Bot{}
+	chatID := int64(123456789)
+	authErr := errors.New(consts.GitHubAuthFailed)
+
+	for i := 1; i < repoFailureThreshold; i++ {
+		b.recordRepoFailure(chatID, authErr)
+	}
+	b.recordRepoFailure(chatID, errors.New("GitHub API error 502: Bad Gateway"))
+	if b.repoHealth(chatID).degraded {
+		t.Fatal("Expected the repository not to be degraded before the threshold")
+	}
+
+	b.recordRepoFailure(chatID, authErr)
+	if state := b.repoHealth(chatID); !state.degraded || state.failures != repoFailureThreshold || state.lastError != consts.GitHubAuthFailed {
+		t.Fatalf("State after %d failures = %+v", repoFailureThreshold, state)
+	}
+
+	b.recordRepoSuccess(chatID)
+	if state := b.repoHealth(chatID); state.degraded || state.failures != 0 {
+		t.Errorf("State after a success = %+v", state)
+	}
+}
@@ -0,0 +1,136 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Repository moves: when saving fails because the repository can't be found, GitHub is asked
+// whether it was renamed or transferred (see github.DetectRepoMove). If so, the user gets a
+// button that updates the stored URL and moves the local clone along.
+
+// repoMoveCheckCooldown is how long a chat's repository isn't checked for a move again
+const repoMoveCheckCooldown = 10 * time.Minute
+
+// repoMoveOfferExpiry is how long the update button of a detected move works
+const repoMoveOfferExpiry = 24 * time.Hour
+
+// isMissingRepoError reports whether err looks like the repository doesn't exist at its URL
+func isMissingRepoError(err error) bool {
+	errStr := strings.ToLower(err.Error())
+	return strings.Contains(errStr, "repository not found") || strings.Contains(errStr, "404")
+}
+
+// checkRepoMoved looks for a move of chatID's repository in the background after err, offering
+// to update the stored URL if it moved
+func (b *Bot) checkRepoMoved(chatID int64, err error) {
+	if err == nil || b.db == nil || !isMissingRepoError(err) {
+		return
+	}
+
+	cacheKey := fmt.Sprintf("repo_move_check_%d", chatID)
+	if _, exists := b.cache.Get(cacheKey); exists {
+		return
+	}
+	b.cache.SetWithExpiry(cacheKey, true, repoMoveCheckCooldown)
+
+	go func() {
+		user, err := b.db.GetUserByChatID(chatID)
+		if err != nil || user == nil || !user.HasGitHubConfig() {
+			return
+		}
+
+		move, err := github.DetectRepoMove(user.GitHubAPIURL, user.GitHubToken, user.GitHubRepo)
+		if err != nil {
+			logger.Debug("Failed to check for a repository move", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			return
+		}
+		if move == nil {
+			return
+		}
+
+		logger.Info("Repository moved", map[string]interface{}{
+			"chat_id":  chatID,
+			"old_repo": move.OldFullName,
+			"new_repo": move.NewFullName,
+		})
+		b.cache.SetWithExpiry(fmt.Sprintf("repo_move_%d", chatID), move, repoMoveOfferExpiry)
+
+		msg := tgbotapi.NewMessage(chatID, fmt.Sprintf(RepoMovedTemplate,
+			html.EscapeString(move.OldFullName), html.EscapeString(move.NewFullName)))
+		msg.ParseMode = "HTML"
+		msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Update stored URL", "repo_move_update"),
+		))
+		if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+			logger.Error("Failed to send repository move notice", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+	}()
+}
+
+// handleRepoMoveUpdateCallback stores the new URL of a moved repository and migrates its clone
+func (b *Bot) handleRepoMoveUpdateCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Repository configuration requires database configuration")
+		return nil
+	}
+
+	cacheKey := fmt.Sprintf("repo_move_%d", chatID)
+	cached, exists := b.cache.Get(cacheKey)
+	move, ok := cached.(*github.RepoMove)
+	if !exists || !ok {
+		b.editMessage(chatID, callback.Message.MessageID, "❌ This update has expired. Use /repo to set your repository URL.")
+		return nil
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil {
+		b.sendResponse(chatID, "❌ Failed to get user")
+		return nil
+	}
+
+	// The URL may have been changed with /repo since the move was detected
+	if user.GitHubRepo != move.OldURL {
+		b.cache.Delete(cacheKey)
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Your repository was changed in the meantime, nothing to update.")
+		return nil
+	}
+
+	if err := b.db.UpdateUserGitHubConfig(chatID, user.GitHubToken, move.NewURL); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to update repository configuration: %v", err))
+		return nil
+	}
+	b.cache.Delete(cacheKey)
+	b.cache.Delete(fmt.Sprintf("github_provider_%d", chatID))
+
+	if err := github.MigrateClone(move.OldURL, move.NewURL); err != nil {
+		// The next save clones the repository again instead
+		logger.Warn("Failed to migrate clone of moved repository", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+
+	logger.Info("Repository URL updated after move", map[string]interface{}{
+		"chat_id":  chatID,
+		"repo_url": move.NewURL,
+	})
+	b.editMessage(chatID, callback.Message.MessageID, fmt.Sprintf("%s Repository updated to: %s\n\nSaving works again, try your note once more.",
+		consts.EmojiSuccess, move.NewFullName))
+	return nil
+}
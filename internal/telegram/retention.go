@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Content retention: with CONTENT_RETENTION=none message content never stays on the bot host.
+// Repositories are never cloned (content streams to GitHub through the API provider), content is
+// redacted from logs and features that store content in the database are disabled.
+
+// retentionCommands are the commands of features that store message content
+var retentionCommands = map[string]bool{
+	"/canned":  true,
+	"/compose": true,
+	"/send":    true,
+	"/discard": true,
+}
+
+// retentionCallbackPrefixes cover buttons of those features sent before the policy changed
+var retentionCallbackPrefixes = []string{
+	"canned_",
+}
+
+// applyRetentionPolicy enforces the deployment's content retention policy for the whole process
+func applyRetentionPolicy(cfg *config.Config) {
+	if !cfg.ZeroRetention() {
+		return
+	}
+
+	logger.SetContentRedaction(true)
+	github.DisableLocalClones()
+	logger.Info("Zero content retention: repositories are not cloned and content is kept out of logs", map[string]interface{}{
+		"disabled_commands": len(retentionCommands),
+	})
+}
+
+// isRetentionCommand reports whether a command (with its arguments) belongs to a feature storing content
+func isRetentionCommand(command string) bool {
+	fields := strings.Fields(command)
+	return len(fields) > 0 && retentionCommands[fields[0]]
+}
+
+func isRetentionCallback(data string) bool {
+	for _, prefix := range retentionCallbackPrefixes {
+		if strings.HasPrefix(data, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendRetentionDisabled tells the user a feature is unavailable under the retention policy
+func (b *Bot) sendRetentionDisabled(chatID int64) error {
+	b.sendResponse(chatID, RetentionDisabledMessage)
+	return nil
+}
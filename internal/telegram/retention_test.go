@@ -0,0 +1,33 @@
+package telegram
+
+import "testing"
+
+func TestIsRetentionCommand(t *testing.T) {
+	tests := []struct {
+		command string
+		want    bool
+	}{
+		{"/canned", true},
+		{"/canned add hi Hello", true},
+		{"/cannedx", false},
+		{"/compose", true},
+		{"/send", true},
+		{"/todo", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isRetentionCommand(tt.command); got != tt.want {
+			t.Errorf("isRetentionCommand(%q) = %v, want %v", tt.command, got, tt.want)
+		}
+	}
+}
+
+func TestIsRetentionCallback(t *testing.T) {
+	if !isRetentionCallback("canned_12_3") {
+		t.Error("canned reply buttons should be disabled under zero retention")
+	}
+	if isRetentionCallback("todo_done_1") {
+		t.Error("TODO buttons should not be disabled under zero retention")
+	}
+}
@@ -0,0 +1,27 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Sandbox mode: with SANDBOX=true every provider is wrapped by github.NewSandboxFactory, so
+// reads hit GitHub but writes are only simulated. Each simulated write is echoed to the user, so
+// operators and users can try flows without touching their repositories.
+
+// enableSandbox wraps the bot's provider factory to simulate GitHub writes
+func (b *Bot) enableSandbox() {
+	b.githubFactory = github.NewSandboxFactory(b.githubFactory, b.reportSandboxWrite)
+	logger.WarnMsg("Sandbox mode enabled, GitHub writes are simulated")
+}
+
+// reportSandboxWrite tells the user about a write sandbox mode didn't make
+func (b *Bot) reportSandboxWrite(chatID int64, action string) {
+	if chatID == 0 {
+		return
+	}
+	b.sendResponse(chatID, fmt.Sprintf("🧪 <b>Sandbox:</b> %s", html.EscapeString(action)))
+}
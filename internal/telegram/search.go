@@ -0,0 +1,182 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Full-text search of the repository's markdown files (/search)
+
+const (
+	searchMaxMatches   = 100
+	searchPerPage      = 8
+	searchLineLimit    = 200 // Bytes of a matching line shown
+	searchStateExpiry  = 30 * time.Minute
+	searchUsageMessage = "🔍 <b>Usage:</b> <code>/search meeting notes</code>\n\nFinds the lines of your markdown files containing all the words."
+)
+
+// searchState keeps the matches of a /search so page callbacks don't search again
+// (Telegram limits callback data to 64 bytes, too short for the query)
+type searchState struct {
+	Query    string
+	Matches  []github.SearchMatch
+	FileURLs map[string]string
+}
+
+func (b *Bot) handleSearchCommand(message *tgbotapi.Message, query string) error {
+	chatID := message.Chat.ID
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		b.sendResponse(chatID, searchUsageMessage)
+		return nil
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+	// Encrypted notes are searched after decrypting them (implemented in note_encryption.go)
+	noteKey, _ := b.userNoteKey(chatID)
+	searcher, ok := userGitHubProvider.(github.Searcher)
+	if !ok && noteKey == nil {
+		b.sendResponse(chatID, "❌ Searching isn't available for your repository.")
+		return nil
+	}
+
+	statusMessageID := b.sendResponseAndGetMessageID(chatID, "🔍 Searching your notes...")
+
+	var matches []github.SearchMatch
+	if noteKey != nil {
+		matches, err = b.searchEncryptedNotes(chatID, userGitHubProvider, noteKey, query, searchMaxMatches)
+	} else {
+		matches, err = searcher.SearchFiles(query, searchMaxMatches)
+	}
+	if err != nil {
+		logger.Warn("Failed to search repository", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.editMessage(chatID, statusMessageID, fmt.Sprintf("❌ Search failed: %v", err))
+		return nil
+	}
+
+	state := &searchState{
+		Query:    query,
+		Matches:  matches,
+		FileURLs: make(map[string]string),
+	}
+	for _, match := range matches {
+		if _, done := state.FileURLs[match.Path]; done {
+			continue
+		}
+		if fileURL, err := userGitHubProvider.GetGitHubFileURLWithBranch(match.Path); err == nil {
+			state.FileURLs[match.Path] = fileURL
+		}
+	}
+	b.cache.SetWithExpiry(fmt.Sprintf("search_%d_%d", chatID, statusMessageID), state, searchStateExpiry)
+
+	return b.showSearchPage(chatID, statusMessageID, state, 0)
+}
+
+// showSearchPage renders page of the search results in messageID
+func (b *Bot) showSearchPage(chatID int64, messageID int, state *searchState, page int) error {
+	text, keyboard := formatSearchPage(state, page)
+
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = consts.ParseModeHTML
+	edit.DisableWebPagePreview = true
+	if keyboard != nil {
+		edit.ReplyMarkup = keyboard
+	}
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		return fmt.Errorf("failed to show search results: %w", err)
+	}
+	return nil
+}
+
+// formatSearchPage renders page of the search results and the keyboard moving between pages
+func formatSearchPage(state *searchState, page int) (string, *tgbotapi.InlineKeyboardMarkup) {
+	query := html.EscapeString(state.Query)
+	if len(state.Matches) == 0 {
+		return fmt.Sprintf("🔍 No lines of your notes contain <b>%s</b>.", query), nil
+	}
+
+	totalPages := (len(state.Matches) + searchPerPage - 1) / searchPerPage
+	if page < 0 || page >= totalPages {
+		page = 0
+	}
+	start := page * searchPerPage
+	end := start + searchPerPage
+	if end > len(state.Matches) {
+		end = len(state.Matches)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔍 <b>%d matches</b> for <b>%s</b>", len(state.Matches), query))
+	if totalPages > 1 {
+		sb.WriteString(fmt.Sprintf(" (Page %d/%d)", page+1, totalPages))
+	}
+	sb.WriteString("\n\n")
+
+	for _, match := range state.Matches[start:end] {
+		location := html.EscapeString(fmt.Sprintf("%s:%d", match.Path, match.Line))
+		if fileURL, ok := state.FileURLs[match.Path]; ok {
+			sb.WriteString(fmt.Sprintf("📄 <a href=\"%s#L%d\">%s</a>\n", html.EscapeString(fileURL), match.Line, location))
+		} else {
+			sb.WriteString(fmt.Sprintf("📄 %s\n", location))
+		}
+
+		line, truncated := truncateForPreview(match.Text, searchLineLimit)
+		if truncated {
+			line += "…"
+		}
+		sb.WriteString(fmt.Sprintf("<code>%s</code>\n\n", html.EscapeString(line)))
+	}
+
+	if len(state.Matches) >= searchMaxMatches {
+		sb.WriteString(fmt.Sprintf("<i>Showing the first %d matches, add words to narrow down your search.</i>", searchMaxMatches))
+	}
+
+	var navButtons []tgbotapi.InlineKeyboardButton
+	if page > 0 {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("◀️ Previous", fmt.Sprintf("search_page_%d", page-1)))
+	}
+	if end < len(state.Matches) {
+		navButtons = append(navButtons, tgbotapi.NewInlineKeyboardButtonData("Next ▶️", fmt.Sprintf("search_page_%d", page+1)))
+	}
+	if len(navButtons) == 0 {
+		return strings.TrimSpace(sb.String()), nil
+	}
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(navButtons)
+	return strings.TrimSpace(sb.String()), &keyboard
+}
+
+// handleSearchCallback handles search_page_<page> callbacks from /search results
+func (b *Bot) handleSearchCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	cached, ok := b.cache.Get(fmt.Sprintf("search_%d_%d", chatID, messageID))
+	if !ok {
+		b.editMessage(chatID, messageID, "⏰ These search results have expired. Use /search again.")
+		return nil
+	}
+
+	page, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "search_page_"))
+	if err != nil {
+		return fmt.Errorf("invalid search callback data: %s", callback.Data)
+	}
+
+	return b.showSearchPage(chatID, messageID, cached.(*searchState), page)
+}
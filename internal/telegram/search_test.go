@@ -0,0 +1,60 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestFormatSearchPage(t *testing.T) {
+	state := &searchState{
+		Query:    "<b>ideas",
+		FileURLs: map[string]string{"notes/ideas.md": "https://github.com/owner/notes/blob/main/notes/ideas.md"},
+	}
+	for i := 1; i <= searchPerPage+2; i++ {
+		state.Matches = append(state.Matches, github.SearchMatch{Path: "notes/ideas.md", Line: i, Text: fmt.Sprintf("idea <%d>", i)})
+	}
+
+	text, keyboard := formatSearchPage(state, 0)
+	if !strings.Contains(text, "for <b>&lt;b&gt;ideas</b> (Page 1/2)") {
+		t.Errorf("Expected the escaped query and page in %q", text)
+	}
+	if !strings.Contains(text, `<a href="https://github.com/owner/notes/blob/main/notes/ideas.md#L1">notes/ideas.md:1</a>`) {
+		t.Errorf("Expected a link to the line in %q", text)
+	}
+	if !strings.Contains(text, "<code>idea &lt;1&gt;</code>") || strings.Contains(text, "idea &lt;9&gt;") {
+		t.Errorf("Expected only the first page of matches in %q", text)
+	}
+	if keyboard == nil || len(keyboard.InlineKeyboard[0]) != 1 || *keyboard.InlineKeyboard[0][0].CallbackData != "search_page_1" {
+		t.Fatalf("Expected a single Next button, got %+v", keyboard)
+	}
+
+	text, keyboard = formatSearchPage(state, 1)
+	if !strings.Contains(text, "notes/ideas.md:10") || strings.Contains(text, "notes/ideas.md:8<") {
+		t.Errorf("Expected the second page of matches in %q", text)
+	}
+	if keyboard == nil || *keyboard.InlineKeyboard[0][0].CallbackData != "search_page_0" || len(keyboard.InlineKeyboard[0]) != 1 {
+		t.Errorf("Expected a single Previous button, got %+v", keyboard)
+	}
+
+	text, keyboard = formatSearchPage(&searchState{Query: "nothing"}, 0)
+	if !strings.Contains(text, "No lines") || keyboard != nil {
+		t.Errorf("formatSearchPage(no matches) = %q, %+v", text, keyboard)
+	}
+}
+
+func TestHandleSearchCommand_Usage(t *testing.T) {
+	bot, fake := newFakeBot(t)
+
+	if err := bot.handleCommand(commandMessage(42, "/search")); err != nil {
+		t.Fatalf("handleCommand() error = %v", err)
+	}
+
+	calls := fake.Calls()
+	last := calls[len(calls)-1]
+	if last.Method != "sendMessage" || !strings.Contains(last.Params.Get("text"), "Usage:") {
+		t.Errorf("Expected usage, got %+v", last)
+	}
+}
@@ -0,0 +1,40 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Self-hosted deployments: with payments disabled premium levels come from config
+// (PREMIUM_DEFAULT_LEVEL, PREMIUM_OVERRIDES) and no Stripe flow is ever offered.
+
+// paymentCommands are the commands that start or show payments
+var paymentCommands = map[string]bool{
+	"/coffee":     true,
+	"/resetusage": true,
+	"/receipts":   true,
+}
+
+// paymentCallbackPrefixes cover buttons of payment messages sent before payments were disabled
+var paymentCallbackPrefixes = []string{
+	"coffee_",
+	"subscription_",
+	"receipt_",
+	"premium_trial_start",
+	"confirm_reset_usage",
+}
+
+func isPaymentCallback(data string) bool {
+	for _, prefix := range paymentCallbackPrefixes {
+		if strings.HasPrefix(data, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendPaymentsDisabled tells the user payments are off and which level the operator granted them
+func (b *Bot) sendPaymentsDisabled(chatID int64) error {
+	b.sendResponse(chatID, fmt.Sprintf(PaymentsDisabledTemplate, GetTierName(b.getPremiumLevel(chatID))))
+	return nil
+}
@@ -0,0 +1,37 @@
+package telegram
+
+import (
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+func TestIsPaymentCallback(t *testing.T) {
+	for _, data := range []string{"coffee_cancel", "subscription_cake_monthly", "receipt_log_12", "premium_trial_start", "confirm_reset_usage"} {
+		if !isPaymentCallback(data) {
+			t.Errorf("isPaymentCallback(%q) = false, want true", data)
+		}
+	}
+	for _, data := range []string{"file_note", "cancel_reset_usage", "trash_restore_1"} {
+		if isPaymentCallback(data) {
+			t.Errorf("isPaymentCallback(%q) = true, want false", data)
+		}
+	}
+}
+
+func TestGetPremiumLevel_ConfiguredWithoutDatabase(t *testing.T) {
+	bot := &Bot{
+		config: &config.Config{
+			PaymentsDisabled:    true,
+			PremiumDefaultLevel: 2,
+			PremiumOverrides:    map[int64]int{42: 3, 43: 0},
+		},
+	}
+
+	tests := map[int64]int{42: 3, 43: 0, 44: 2}
+	for chatID, want := range tests {
+		if got := bot.getPremiumLevel(chatID); got != want {
+			t.Errorf("getPremiumLevel(%d) = %d, want %d", chatID, got, want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/msg2git/msg2git/experiments/monitoring/ratelimit"
+	"github.com/msg2git/msg2git/internal/cache"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/distributed"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// Shared state: with REDIS_URL set, instances serving the same bot behind a load balancer share
+// the cache and the pending messages of prompts, so a callback or reply reaching another instance
+// finds the state its prompt left, claim each callback query once, and count Telegram rate limits
+// together. Without Redis, or if it can't be reached at startup, all of it stays in the memory of
+// each instance.
+
+const (
+	sharedCachePrefix   = "msg2git:cache:"
+	sharedPendingPrefix = "msg2git:pending:"
+
+	// callbackClaimExpiry is how long a handled callback query is remembered
+	callbackClaimExpiry = 30 * time.Second
+
+	// pendingMessageExpiry is how long a prompt waits for its callback or reply
+	pendingMessageExpiry = 7 * 24 * time.Hour
+	// pendingMessagesMaxSize bounds the pending messages kept in memory without Redis
+	pendingMessagesMaxSize = 100000
+
+	// sharedGlobalSendID counts the sends to all chats in the shared limiter, no chat has ID 0
+	sharedGlobalSendID = 0
+	// sharedLimitMinWait is the shortest wait before trying a reached shared limit again
+	sharedLimitMinWait = 10 * time.Millisecond
+)
+
+func init() {
+	// State of prompts and menus, shared so whichever instance receives the next callback can use it
+	cache.Register(&browseState{})
+	cache.Register(&bulkPlan{})
+	cache.Register(&searchState{})
+	cache.Register(&issueLabelPicker{})
+	cache.Register(&fileMultiSelect{})
+	cache.Register(&pendingAttachment{})
+	cache.Register(&github.RepoMove{})
+}
+
+// newBotCache creates the cache of the bot, shared through Redis when it's configured and reachable
+func newBotCache(cfg *config.Config) (*cache.Cache, *redis.Client) {
+	if cfg.HasRedisConfig() {
+		client, err := distributed.Connect(cfg.RedisURL)
+		if err == nil {
+			logger.InfoMsg("Sharing cache and rate limits through Redis")
+			return cache.NewRedis(client, sharedCachePrefix, 1000, 30*time.Minute, 5*time.Minute), client
+		}
+		logger.Warn("Failed to connect to Redis", map[string]interface{}{
+			"error": err.Error(),
+		})
+		logger.InfoMsg("Continuing with cache and rate limits local to this instance...")
+	}
+	return cache.NewWithConfig(1000, 30*time.Minute, 5*time.Minute), nil
+}
+
+// newPendingMessages creates the store of pending messages, shared through client if it's not nil
+func newPendingMessages(client *redis.Client) *cache.Cache {
+	if client != nil {
+		return cache.NewRedis(client, sharedPendingPrefix, pendingMessagesMaxSize, pendingMessageExpiry, time.Hour)
+	}
+	return cache.NewWithConfig(pendingMessagesMaxSize, pendingMessageExpiry, time.Hour)
+}
+
+// pendingMessage returns the pending message stored under key by a prompt
+func (b *Bot) pendingMessage(key string) (string, bool) {
+	value, ok := b.pendingMessages.Get(key)
+	data, isString := value.(string)
+	return data, ok && isString
+}
+
+// sharedSendLimits are the Telegram send limits counted across instances, the rates of the
+// limiters of each instance: LimitTypeGlobal for all chats and LimitTypeCommand for each chat
+func sharedSendLimits() ratelimit.Config {
+	return ratelimit.Config{
+		GlobalLimit:  ratelimit.RateLimit{Requests: 5000, Window: time.Second},
+		CommandLimit: ratelimit.RateLimit{Requests: 30, Window: time.Second},
+	}
+}
+
+// useSharedState counts rate limits in client, on top of the limiters of this instance
+func (b *Bot) useSharedState(client *redis.Client) {
+	b.redis = client
+	b.sharedLimiter = ratelimit.NewRedisRateLimiterWithClient(client, sharedSendLimits(), nil)
+}
+
+// claimCallback reports whether this instance should handle a callback query: Telegram may deliver
+// one twice, and with a shared cache exactly one instance claims it
+func (b *Bot) claimCallback(callbackID string) bool {
+	return b.cache.SetIfAbsent("callback_"+callbackID, true, callbackClaimExpiry)
+}
+
+// waitSharedSendLimits waits for the global and per-chat send limits counted across instances.
+// A failing Redis doesn't block sending, the limiters of this instance still apply.
+func (b *Bot) waitSharedSendLimits(chatID int64) {
+	if b.sharedLimiter == nil {
+		return
+	}
+
+	err := b.waitSharedLimit(sharedGlobalSendID, ratelimit.LimitTypeGlobal)
+	if err == nil {
+		err = b.waitSharedLimit(chatID, ratelimit.LimitTypeCommand)
+	}
+	if err != nil {
+		logger.Warn("Failed to check shared rate limits", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// waitSharedLimit counts a request of id against limitType, waiting for the window to move on
+// while the limit is reached
+func (b *Bot) waitSharedLimit(id int64, limitType ratelimit.LimitType) error {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), distributed.Timeout)
+		err := b.sharedLimiter.ConsumeLimit(ctx, id, limitType, 0)
+		if !errors.Is(err, ratelimit.ErrRateLimitExceeded) {
+			cancel()
+			return err
+		}
+		resetAt, err := b.sharedLimiter.GetResetTime(ctx, id, limitType)
+		cancel()
+		if err != nil {
+			return err
+		}
+		time.Sleep(max(time.Until(resetAt), sharedLimitMinWait))
+	}
+}
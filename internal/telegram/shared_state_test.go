@@ -0,0 +1,93 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/msg2git/msg2git/internal/cache"
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestSharedState_TwoInstances(t *testing.T) {
+	server := miniredis.RunT(t)
+	cfg := &config.Config{RedisURL: "redis://" + server.Addr()}
+
+	var bots []*Bot
+	for i := 0; i < 2; i++ {
+		botCache, client := newBotCache(cfg)
+		if client == nil {
+			t.Fatal("Expected newBotCache to connect to Redis")
+		}
+		b := &Bot{cache: botCache, pendingMessages: newPendingMessages(client)}
+		b.useSharedState(client)
+		t.Cleanup(func() {
+			botCache.Close()
+			client.Close()
+		})
+		bots = append(bots, b)
+	}
+
+	if !bots[0].claimCallback("42") {
+		t.Fatal("Expected the first instance to claim the callback")
+	}
+	if bots[1].claimCallback("42") {
+		t.Error("Expected the other instance not to handle a claimed callback")
+	}
+
+	// Menu state cached by one instance is used by the other
+	bots[0].cache.SetWithExpiry("search_1_2", &searchState{
+		Query:   "todo",
+		Matches: []github.SearchMatch{{Path: "inbox.md", Line: 3, Text: "todo: call"}},
+	}, searchStateExpiry)
+	cached, ok := bots[1].cache.Get("search_1_2")
+	if state, isState := cached.(*searchState); !ok || !isState || state.Matches[0].Line != 3 {
+		t.Errorf("Get() = %#v, %v, want the search state of the other instance", cached, ok)
+	}
+
+	// A message waiting for its file on one instance is saved by the other
+	bots[0].pendingMessages.Set("1_2", pendingEntryData("note", 2, false))
+	if data, ok := bots[1].pendingMessage("1_2"); !ok || data != pendingEntryData("note", 2, false) {
+		t.Errorf("pendingMessage() = %q, %v, want the pending message of the other instance", data, ok)
+	}
+
+	// Forgetting a chat drops what every instance cached for it
+	bots[1].forgetChatState(1, 0)
+	if _, ok := bots[0].cache.Get("search_1_2"); ok {
+		t.Error("Expected forgetChatState to drop shared cache entries of the chat")
+	}
+	if _, ok := bots[0].pendingMessage("1_2"); ok {
+		t.Error("Expected forgetChatState to drop shared pending messages of the chat")
+	}
+
+	bots[1].waitSharedSendLimits(1)
+	if keys := server.Keys(); len(keys) < 4 {
+		t.Errorf("Redis keys = %v, want shared send limit counters", keys)
+	}
+}
+
+func TestNewBotCache_WithoutRedis(t *testing.T) {
+	botCache, client := newBotCache(&config.Config{})
+	defer botCache.Close()
+	if client != nil {
+		t.Error("Expected no Redis client without REDIS_URL")
+	}
+
+	// An unreachable Redis falls back to a local cache
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+	botCache, client = newBotCache(&config.Config{RedisURL: "redis://" + addr})
+	defer botCache.Close()
+	if client != nil {
+		t.Error("Expected no Redis client when Redis is unreachable")
+	}
+
+	b := &Bot{cache: cache.NewWithConfig(10, time.Minute, time.Minute)}
+	defer b.cache.Close()
+	if !b.claimCallback("1") || b.claimCallback("1") {
+		t.Error("Expected callbacks to be claimed once with a local cache")
+	}
+	b.waitSharedSendLimits(1) // No shared limits, returns immediately
+}
@@ -0,0 +1,131 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Source footers: with /source on, notes end with a small link back to the Telegram message they
+// came from. Supergroups and channels get t.me/c links; private chats have none, so they get a
+// /start deep link the bot resolves by replying to the original message.
+
+const (
+	// sourceStartPrefix starts the /start payload of links to messages in private chats
+	sourceStartPrefix = "msg_"
+	// supergroupIDOffset is subtracted from supergroup and channel IDs in t.me/c links
+	supergroupIDOffset = 1000000000000
+)
+
+// messageLink returns a link to a Telegram message, "" for messages without one (basic groups,
+// captures not from a message)
+func messageLink(chatID int64, messageID int, botUsername string) string {
+	switch {
+	case messageID <= 0:
+		return ""
+	case chatID > 0:
+		if botUsername == "" {
+			return ""
+		}
+		return fmt.Sprintf("https://t.me/%s?start=%s%d", botUsername, sourceStartPrefix, messageID)
+	case chatID < -supergroupIDOffset:
+		return fmt.Sprintf("https://t.me/c/%d/%d", -chatID-supergroupIDOffset, messageID)
+	default:
+		return ""
+	}
+}
+
+// sourceFooter formats the footer linking a note to its message
+func sourceFooter(link string) string {
+	return fmt.Sprintf("<sub>[📨 Telegram message](%s)</sub>", link)
+}
+
+// withSourceFooter appends the source footer to a note's content if the chat turned it on
+func (b *Bot) withSourceFooter(chatID int64, messageID int, content string) string {
+	if b.db == nil || b.api == nil {
+		return content
+	}
+
+	link := messageLink(chatID, messageID, b.api.Self.UserName)
+	if link == "" {
+		return content
+	}
+
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil || !user.SourceFooter {
+		return content
+	}
+
+	return strings.TrimRight(content, "\n") + "\n\n" + sourceFooter(link)
+}
+
+// handleSourceCommand shows or switches the source footer
+func (b *Bot) handleSourceCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/source")))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Source links require a database.")
+		return nil
+	}
+
+	user, err := b.ensureUser(message)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch args {
+	case "":
+		if user.SourceFooter {
+			b.sendResponse(chatID, "📨 Source links are on. Notes end with a link back to their Telegram message.\n\nUse <code>/source off</code> to stop.")
+			return nil
+		}
+		b.sendResponse(chatID, "📨 Source links are off.\n\nUse <code>/source on</code> to end notes with a link back to their Telegram message.")
+		return nil
+	case "on", "off":
+		enabled := args == "on"
+		if err := b.db.UpdateUserSourceFooter(chatID, enabled); err != nil {
+			b.sendResponse(chatID, "❌ Failed to update source links.")
+			return nil
+		}
+		if enabled {
+			b.sendResponse(chatID, "📨 Source links enabled. New notes end with a link back to their Telegram message.")
+		} else {
+			b.sendResponse(chatID, "📨 Source links disabled.")
+		}
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/source</code>, <code>/source on</code> or <code>/source off</code>")
+		return nil
+	}
+}
+
+// handleStartPayload opens the original message of a source link by replying to it,
+// other payloads get the welcome message
+func (b *Bot) handleStartPayload(message *tgbotapi.Message, payload string) error {
+	if !strings.HasPrefix(payload, sourceStartPrefix) {
+		return b.handleStartCommand(message)
+	}
+
+	messageID, err := strconv.Atoi(strings.TrimPrefix(payload, sourceStartPrefix))
+	if err != nil || messageID <= 0 {
+		return b.handleStartCommand(message)
+	}
+
+	chatID := message.Chat.ID
+	msg := tgbotapi.NewMessage(chatID, "📨 Original message")
+	msg.ReplyToMessageID = messageID
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		logger.Debug("Failed to reply to source message", map[string]interface{}{
+			"chat_id":    chatID,
+			"message_id": messageID,
+			"error":      err.Error(),
+		})
+		b.sendResponse(chatID, "❌ The original message no longer exists in this chat.")
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package telegram
+
+import "testing"
+
+func TestMessageLink(t *testing.T) {
+	tests := []struct {
+		name        string
+		chatID      int64
+		messageID   int
+		botUsername string
+		want        string
+	}{
+		{"private chat", 123456789, 42, "msg2git_bot", "https://t.me/msg2git_bot?start=msg_42"},
+		{"private chat without bot username", 123456789, 42, "", ""},
+		{"supergroup", -1001234567890, 7, "msg2git_bot", "https://t.me/c/1234567890/7"},
+		{"channel", -1009876543210, 99, "", "https://t.me/c/9876543210/99"},
+		{"basic group", -123456789, 7, "msg2git_bot", ""},
+		{"no message", 123456789, 0, "msg2git_bot", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := messageLink(tt.chatID, tt.messageID, tt.botUsername); got != tt.want {
+				t.Errorf("messageLink() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSourceFooter(t *testing.T) {
+	want := "<sub>[📨 Telegram message](https://t.me/c/1234567890/7)</sub>"
+	if got := sourceFooter("https://t.me/c/1234567890/7"); got != want {
+		t.Errorf("sourceFooter() = %q, want %q", got, want)
+	}
+}
+
+func TestWithSourceFooterWithoutDatabase(t *testing.T) {
+	b := &Bot{}
+	if got := b.withSourceFooter(123456789, 42, "note"); got != "note" {
+		t.Errorf("withSourceFooter() = %q, want the content unchanged", got)
+	}
+}
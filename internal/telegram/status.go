@@ -0,0 +1,111 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+// Public status: GET /status returns service health for a status page to poll, without
+// authentication, so hosted-service users can tell whether slowness is global. It holds no user
+// data: uptime, how backed up the queues are as a bucket rather than a count, the GitHub circuit
+// state (see internal/github/circuit.go) and the kind and time of the last incident.
+
+// Queue depth buckets, by how full the message and callback queues are
+const (
+	queueIdle     = "idle"
+	queueNormal   = "normal"
+	queueBusy     = "busy"
+	queueBackedUp = "backed_up"
+)
+
+// Incident kinds
+const (
+	incidentGitHubUnavailable = "github_unavailable" // The GitHub circuit opened
+	incidentQueueFull         = "queue_full"         // A message or callback was dropped
+)
+
+// serviceStatus is the body of GET /status
+type serviceStatus struct {
+	Status        string    `json:"status"` // "ok" or "degraded"
+	UptimeSeconds int64     `json:"uptime_seconds"`
+	Queue         string    `json:"queue"`
+	GitHub        string    `json:"github"`
+	PendingPushes int       `json:"pending_pushes"` // Branches whose push is retried in the background
+	LastIncident  *incident `json:"last_incident"`
+}
+
+// incident is the most recent event that affected every user
+type incident struct {
+	Kind string    `json:"kind"`
+	At   time.Time `json:"at"`
+}
+
+// queueDepthBucket buckets the queued tasks against the queue capacity
+func queueDepthBucket(depth, capacity int) string {
+	switch {
+	case depth == 0 || capacity <= 0:
+		return queueIdle
+	case depth*4 < capacity:
+		return queueNormal
+	case depth*4 < capacity*3:
+		return queueBusy
+	default:
+		return queueBackedUp
+	}
+}
+
+// buildServiceStatus combines the health signals into a status
+func buildServiceStatus(uptime time.Duration, depth, capacity int, circuit github.CircuitState, githubOpened, queueFull time.Time) serviceStatus {
+	status := serviceStatus{
+		Status:        "ok",
+		UptimeSeconds: int64(uptime / time.Second),
+		Queue:         queueDepthBucket(depth, capacity),
+		GitHub:        string(circuit),
+	}
+	if circuit != github.CircuitClosed || status.Queue == queueBackedUp {
+		status.Status = "degraded"
+	}
+
+	switch {
+	case !githubOpened.IsZero() && !githubOpened.Before(queueFull):
+		status.LastIncident = &incident{Kind: incidentGitHubUnavailable, At: githubOpened.UTC()}
+	case !queueFull.IsZero():
+		status.LastIncident = &incident{Kind: incidentQueueFull, At: queueFull.UTC()}
+	}
+	return status
+}
+
+// handleStatus serves the public service status
+func (b *Bot) handleStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeAPIError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var depth, capacity int
+	var queueFull time.Time
+	if b.workerPool != nil {
+		depth, capacity, queueFull = b.workerPool.queueStatus()
+	}
+	circuit, githubOpened := github.CircuitStatus()
+	status := buildServiceStatus(time.Since(b.startedAt), depth, capacity, circuit, githubOpened, queueFull)
+	status.PendingPushes = github.PendingPushes()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(status)
+}
+
+// queueStatus returns the queued tasks, the queue capacity and when a task was last dropped
+func (wp *WorkerPool) queueStatus() (depth, capacity int, lastFull time.Time) {
+	depth = len(wp.messageQueue) + len(wp.callbackQueue)
+	capacity = cap(wp.messageQueue) + cap(wp.callbackQueue)
+	if nanos := wp.lastQueueFull.Load(); nanos != 0 {
+		lastFull = time.Unix(0, nanos)
+	}
+	return depth, capacity, lastFull
+}
@@ -0,0 +1,82 @@
+package telegram
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestQueueDepthBucket(t *testing.T) {
+	tests := []struct {
+		depth, capacity int
+		want            string
+	}{
+		{0, 300, queueIdle},
+		{10, 300, queueNormal},
+		{100, 300, queueBusy},
+		{250, 300, queueBackedUp},
+		{300, 300, queueBackedUp},
+		{5, 0, queueIdle},
+	}
+	for _, tt := range tests {
+		if got := queueDepthBucket(tt.depth, tt.capacity); got != tt.want {
+			t.Errorf("queueDepthBucket(%d, %d) = %s, want %s", tt.depth, tt.capacity, got, tt.want)
+		}
+	}
+}
+
+func TestBuildServiceStatus(t *testing.T) {
+	opened := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	full := opened.Add(time.Hour)
+
+	status := buildServiceStatus(90*time.Minute, 0, 300, github.CircuitClosed, time.Time{}, time.Time{})
+	if status.Status != "ok" || status.UptimeSeconds != 5400 || status.Queue != queueIdle || status.LastIncident != nil {
+		t.Errorf("healthy status = %+v", status)
+	}
+
+	status = buildServiceStatus(time.Minute, 0, 300, github.CircuitOpen, opened, full)
+	if status.Status != "degraded" || status.GitHub != "open" {
+		t.Errorf("open circuit status = %+v, want degraded", status)
+	}
+	if status.LastIncident == nil || status.LastIncident.Kind != incidentQueueFull || !status.LastIncident.At.Equal(full) {
+		t.Errorf("LastIncident = %+v, want the later queue_full", status.LastIncident)
+	}
+
+	status = buildServiceStatus(time.Minute, 280, 300, github.CircuitClosed, opened, time.Time{})
+	if status.Status != "degraded" || status.LastIncident.Kind != incidentGitHubUnavailable {
+		t.Errorf("backed up status = %+v", status)
+	}
+}
+
+func TestHandleStatus(t *testing.T) {
+	b := &Bot{startedAt: time.Now().Add(-time.Minute)}
+
+	rec := httptest.NewRecorder()
+	b.handleStatus(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status code = %d", rec.Code)
+	}
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("status isn't readable cross-origin")
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON %q: %v", rec.Body.String(), err)
+	}
+	for _, field := range []string{"status", "uptime_seconds", "queue", "github", "last_incident"} {
+		if _, ok := body[field]; !ok {
+			t.Errorf("missing field %s in %s", field, strings.TrimSpace(rec.Body.String()))
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	b.handleStatus(rec, httptest.NewRequest(http.MethodPost, "/status", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status code = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,256 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Streaks: consecutive days with at least one capture, tracked in user_insights on every commit.
+// Save confirmations show the current streak, /streak shows it with the user's best, and an opt-in
+// reminder set with /streak remind nudges users in the evening when today hasn't extended their
+// streak yet. Reminders respect quiet hours and are sent at most once a day.
+
+const (
+	streakReminderCheckInterval = 5 * time.Minute
+	streakReminderDefaultMinute = 20 * 60 // 20:00
+)
+
+// streakMilestones are the streak lengths celebrated in save confirmations
+var streakMilestones = []int{7, 30, 100, 365}
+
+const streakUsage = "Usage: <code>/streak</code>, <code>/streak remind [20:00 [timezone]]</code> or <code>/streak remind off</code>"
+
+// handleStreakCommand shows the user's streak or sets their evening reminder
+func (b *Bot) handleStreakCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Streaks require a database.")
+		return nil
+	}
+
+	args := strings.Fields(message.CommandArguments())
+	switch {
+	case len(args) == 0:
+		streak, err := b.db.GetStreak(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load your streak.")
+			return nil
+		}
+		reminder, err := b.db.GetStreakReminder(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load your streak.")
+			return nil
+		}
+		b.sendResponse(chatID, formatStreakStatus(streak, reminder))
+		return nil
+
+	case args[0] != "remind" || len(args) > 3:
+		b.sendResponse(chatID, streakUsage)
+		return nil
+
+	case len(args) == 2 && args[1] == "off":
+		removed, err := b.db.DeleteStreakReminder(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to turn off the streak reminder.")
+			return nil
+		}
+		if !removed {
+			b.sendResponse(chatID, "🔕 The streak reminder is already off.")
+			return nil
+		}
+		b.sendResponse(chatID, "🔕 Streak reminder turned off.")
+		return nil
+	}
+
+	minute := streakReminderDefaultMinute
+	if len(args) >= 2 {
+		parsed, err := parseQuietClock(args[1])
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s\n\n%s", html.EscapeString(err.Error()), streakUsage))
+			return nil
+		}
+		minute = parsed
+	}
+
+	// Remind in the timezone of the quiet hours unless one is given
+	timezone := "UTC"
+	if quiet, err := b.db.GetQuietHours(chatID); err == nil && quiet != nil {
+		timezone = quiet.Timezone
+	}
+	if len(args) == 3 {
+		if _, err := time.LoadLocation(args[2]); err != nil || args[2] == "Local" {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Unknown timezone <code>%s</code>. Use a name like <code>Europe/Berlin</code> or <code>America/New_York</code>.", html.EscapeString(args[2])))
+			return nil
+		}
+		timezone = args[2]
+	}
+
+	if err := b.db.SetStreakReminder(chatID, minute, timezone); err != nil {
+		b.sendResponse(chatID, "❌ Failed to set the streak reminder.")
+		return nil
+	}
+
+	logger.Info("User set streak reminder", map[string]interface{}{
+		"chat_id":  chatID,
+		"minute":   minute,
+		"timezone": timezone,
+	})
+	b.sendResponse(chatID, fmt.Sprintf("🔔 I'll remind you at <b>%s</b> (%s) on days you haven't captured anything yet while a streak is running.\n\nTurn it off with <code>/streak remind off</code>.",
+		formatQuietMinute(minute), html.EscapeString(timezone)))
+	return nil
+}
+
+// formatStreakStatus describes the user's streak and reminder for /streak
+func formatStreakStatus(streak *database.Streak, reminder *database.StreakReminder) string {
+	var sb strings.Builder
+	switch {
+	case streak.Current == 0:
+		sb.WriteString("🔥 <b>No streak running</b>\nCapture something today to start one.")
+	case streak.CapturedToday:
+		sb.WriteString(fmt.Sprintf("🔥 <b>Streak: %s</b>\nToday is done, keep it going tomorrow.", formatStreak(streak.Current)))
+	default:
+		sb.WriteString(fmt.Sprintf("🔥 <b>Streak: %s</b>\nCapture something today to keep it.", formatStreak(streak.Current)))
+	}
+	if streak.Best > 0 {
+		sb.WriteString(fmt.Sprintf("\n🏅 Best: %s", formatStreak(streak.Best)))
+	}
+
+	if reminder == nil {
+		sb.WriteString("\n\nGet an evening reminder before a streak ends with <code>/streak remind 20:00 Europe/Berlin</code>.")
+	} else {
+		sb.WriteString(fmt.Sprintf("\n\n🔔 Reminder at %s (%s). Turn it off with <code>/streak remind off</code>.",
+			formatQuietMinute(reminder.RemindMinute), html.EscapeString(reminder.Timezone)))
+	}
+	return sb.String()
+}
+
+// formatStreakConfirmation returns the plain text streak line of save confirmations, empty
+// until a streak reaches two days
+func formatStreakConfirmation(streak *database.Streak) string {
+	if streak == nil || streak.Current < 2 {
+		return ""
+	}
+	for _, milestone := range streakMilestones {
+		if streak.Current == milestone {
+			return fmt.Sprintf("🏅 %d-day streak, milestone reached!", streak.Current)
+		}
+	}
+	line := fmt.Sprintf("🔥 %d-day streak", streak.Current)
+	if streak.Current == streak.Best {
+		line += " · personal best"
+	}
+	return line
+}
+
+// streakConfirmation returns the streak line appended to save confirmations, with its newline
+func (b *Bot) streakConfirmation(chatID int64) string {
+	if b.db == nil {
+		return ""
+	}
+	streak, err := b.db.GetStreak(chatID)
+	if err != nil {
+		logger.Warn("Failed to load streak", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		return ""
+	}
+	if line := formatStreakConfirmation(streak); line != "" {
+		return "\n" + line
+	}
+	return ""
+}
+
+// streakReminderLocation returns the timezone of the reminder, UTC if it can't be loaded
+func streakReminderLocation(reminder *database.StreakReminder) *time.Location {
+	loc, err := time.LoadLocation(reminder.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// streakReminderDue reports whether the reminder's time has come today and it wasn't sent today
+func streakReminderDue(reminder *database.StreakReminder, now time.Time) bool {
+	local := now.In(streakReminderLocation(reminder))
+	if local.Hour()*60+local.Minute() < reminder.RemindMinute {
+		return false
+	}
+	return reminder.LastSentOn == nil || reminder.LastSentOn.Format("2006-01-02") != local.Format("2006-01-02")
+}
+
+// startStreakReminders periodically reminds users whose streak is about to end
+func (b *Bot) startStreakReminders() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopStreakReminders = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(streakReminderCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runStreakReminders()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runStreakReminders() {
+	reminders, err := b.db.GetStreakReminders()
+	if err != nil {
+		logger.Error("Failed to load streak reminders", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	for _, reminder := range reminders {
+		if !b.chatAllowed(reminder.ChatID) || !streakReminderDue(reminder, now) || b.inQuietHours(reminder.ChatID, now) {
+			continue
+		}
+		if err := b.sendStreakReminder(reminder.ChatID); err != nil {
+			logger.Warn("Failed to send streak reminder", map[string]interface{}{
+				"chat_id": reminder.ChatID,
+				"error":   err.Error(),
+			})
+			continue
+		}
+		// Checked once a day, whether or not a reminder was needed
+		if err := b.db.MarkStreakReminderSent(reminder.ChatID, now.In(streakReminderLocation(reminder))); err != nil {
+			logger.Warn("Failed to mark streak reminder sent", map[string]interface{}{
+				"chat_id": reminder.ChatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// sendStreakReminder reminds the user to capture something if their running streak misses today
+func (b *Bot) sendStreakReminder(chatID int64) error {
+	streak, err := b.db.GetStreak(chatID)
+	if err != nil {
+		return err
+	}
+	if streak.Current == 0 || streak.CapturedToday {
+		return nil
+	}
+
+	text := fmt.Sprintf("🔥 Your streak of <b>%s</b> ends today. Capture a quick note to keep it going!", formatStreak(streak.Current))
+	b.sendResponse(chatID, text)
+	return nil
+}
@@ -0,0 +1,66 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestFormatStreakConfirmation(t *testing.T) {
+	tests := []struct {
+		streak *database.Streak
+		want   string
+	}{
+		{nil, ""},
+		{&database.Streak{Current: 1, Best: 4}, ""},
+		{&database.Streak{Current: 3, Best: 9}, "🔥 3-day streak"},
+		{&database.Streak{Current: 5, Best: 5}, "🔥 5-day streak · personal best"},
+		{&database.Streak{Current: 30, Best: 30}, "🏅 30-day streak, milestone reached!"},
+	}
+
+	for _, tt := range tests {
+		if got := formatStreakConfirmation(tt.streak); got != tt.want {
+			t.Errorf("formatStreakConfirmation(%+v) = %q, want %q", tt.streak, got, tt.want)
+		}
+	}
+}
+
+func TestStreakReminderDue(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reminder := &database.StreakReminder{RemindMinute: 20 * 60, Timezone: "Europe/Berlin"}
+
+	// 18:30 UTC is 20:30 in Berlin in summer
+	now := time.Date(2026, 7, 1, 18, 30, 0, 0, time.UTC)
+	if !streakReminderDue(reminder, now) {
+		t.Error("streakReminderDue() = false after the reminder time")
+	}
+	if streakReminderDue(reminder, now.Add(-time.Hour)) {
+		t.Error("streakReminderDue() = true before the reminder time")
+	}
+
+	sentToday := time.Date(2026, 7, 1, 0, 0, 0, 0, berlin)
+	reminder.LastSentOn = &sentToday
+	if streakReminderDue(reminder, now) {
+		t.Error("streakReminderDue() = true after today's reminder was sent")
+	}
+	if !streakReminderDue(reminder, now.AddDate(0, 0, 1)) {
+		t.Error("streakReminderDue() = false the day after a reminder")
+	}
+}
+
+func TestFormatStreakStatus(t *testing.T) {
+	text := formatStreakStatus(&database.Streak{Current: 4, Best: 10}, nil)
+	if !strings.Contains(text, "Streak: 4 days") || !strings.Contains(text, "Best: 10 days") || !strings.Contains(text, "/streak remind") {
+		t.Errorf("formatStreakStatus() = %q", text)
+	}
+
+	text = formatStreakStatus(&database.Streak{}, &database.StreakReminder{RemindMinute: 21*60 + 30, Timezone: "UTC"})
+	if !strings.Contains(text, "No streak running") || !strings.Contains(text, "Reminder at 21:30 (UTC)") {
+		t.Errorf("formatStreakStatus() = %q", text)
+	}
+}
@@ -2,19 +2,18 @@ package telegram
 
 import (
 	"net/http"
-	"os"
 
 	"github.com/msg2git/msg2git/internal/logger"
 )
 
-// StartWebhookServer starts an HTTP server for Stripe webhooks
+// StartWebhookServer starts an HTTP server for Stripe and Telegram webhooks and the capture API
 func (b *Bot) StartWebhookServer() {
-	if b.stripeManager == nil {
-		logger.Info("Stripe not configured, webhook server not started", nil)
+	if b.stripeManager == nil && b.db == nil && !b.cfg().HasTelegramWebhookConfig() {
+		logger.Info("Stripe, database and Telegram webhook not configured, webhook server not started", nil)
 		return
 	}
 
-	port := os.Getenv("WEBHOOK_PORT")
+	port := b.cfg().WebhookPort
 	if port == "" {
 		port = "8080"
 	}
@@ -22,6 +21,11 @@ func (b *Bot) StartWebhookServer() {
 	http.HandleFunc("/stripe/webhook", b.handleStripeWebhook)
 	http.HandleFunc("/health", b.handleHealth)
 	http.HandleFunc("/github/oauth", b.HandleGitHubOAuthCallback)
+	http.HandleFunc("/api/v1/capture", b.handleAPICapture)
+	http.HandleFunc("/status", b.handleStatus)
+	if b.cfg().HasAdminAPIConfig() {
+		http.HandleFunc("/admin/v1/evict", b.handleAdminEvict)
+	}
 	
 	// Note: Auth pages are served by BASE_URL service (nginx), no handlers needed in container
 	
@@ -33,7 +37,7 @@ func (b *Bot) StartWebhookServer() {
 		})
 		if r.URL.Path == "/" {
 			w.WriteHeader(http.StatusOK)
-			w.Write([]byte("Webhook server is running. Available endpoints:\n/stripe/webhook\n/health\n/github/oauth\n\nNote: Auth pages are served by BASE_URL service"))
+			w.Write([]byte("Webhook server is running. Available endpoints:\n/stripe/webhook\n/health\n/github/oauth\n/api/v1/capture\n/status\n\nNote: Auth pages are served by BASE_URL service"))
 		} else {
 			w.WriteHeader(http.StatusNotFound)
 			w.Write([]byte("Not Found"))
@@ -43,9 +47,16 @@ func (b *Bot) StartWebhookServer() {
 	go func() {
 		logger.Info("Webhook server starting", map[string]interface{}{
 			"port": port,
-			"endpoints": []string{"/stripe/webhook", "/health", "/github/oauth"},
+			"endpoints": []string{"/stripe/webhook", "/health", "/github/oauth", "/api/v1/capture", "/status"},
 		})
-		if err := http.ListenAndServe(":"+port, nil); err != nil {
+		var err error
+		if b.cfg().WebhookCertFile != "" {
+			// Serve HTTPS directly instead of behind a TLS-terminating load balancer
+			err = http.ListenAndServeTLS(":"+port, b.cfg().WebhookCertFile, b.cfg().WebhookKeyFile, nil)
+		} else {
+			err = http.ListenAndServe(":"+port, nil)
+		}
+		if err != nil {
 			logger.Error("Webhook server error", map[string]interface{}{
 				"error": err.Error(),
 			})
@@ -0,0 +1,133 @@
+package telegram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Sync commit mode: /sync commits issue.md and the archive files it changed either together
+// (squash, the default) or one commit per file, as chosen with /sync mode
+
+// syncCommitMode returns the user's sync commit mode, squash when unset or without a database
+func (b *Bot) syncCommitMode(chatID int64) string {
+	if b.db == nil {
+		return database.SyncCommitModeSquash
+	}
+	user, err := b.db.GetUserByChatID(chatID)
+	if err != nil || user == nil || user.SyncCommitMode != database.SyncCommitModePerFile {
+		return database.SyncCommitModeSquash
+	}
+	return user.SyncCommitMode
+}
+
+// syncCommitOrder returns the files in per-file commit order: archive files first, issue.md last,
+// so a failure halfway never drops issues from issue.md before they reach an archive
+func syncCommitOrder(files map[string]string) []string {
+	order := make([]string, 0, len(files))
+	for filename := range files {
+		if filename != "issue.md" {
+			order = append(order, filename)
+		}
+	}
+	sort.Strings(order)
+	if _, ok := files["issue.md"]; ok {
+		order = append(order, "issue.md")
+	}
+	return order
+}
+
+// commitSyncFiles commits the files changed by /sync in the user's sync commit mode.
+// The caller holds the file locks, so API providers use their locked write methods.
+func (b *Bot) commitSyncFiles(chatID int64, githubProvider github.GitHubProvider, files map[string]string, commitMsg string) error {
+	committerInfo := b.getCommitterInfo(chatID)
+	premiumLevel := b.getPremiumLevel(chatID)
+	apiProvider, isAPI := githubProvider.(*github.APIBasedProvider)
+	files = b.withReadmeTOC(chatID, githubProvider, files) // Implemented in readme_toc.go
+
+	if len(files) == 1 || b.syncCommitMode(chatID) != database.SyncCommitModePerFile {
+		if isAPI {
+			return apiProvider.ReplaceMultipleFilesWithAuthorAndPremiumLocked(files, commitMsg, committerInfo, premiumLevel)
+		}
+		return githubProvider.ReplaceMultipleFilesWithAuthorAndPremium(files, commitMsg, committerInfo, premiumLevel)
+	}
+
+	for _, filename := range syncCommitOrder(files) {
+		fileMsg := fmt.Sprintf("%s - %s", commitMsg, filename)
+		var err error
+		if isAPI {
+			err = apiProvider.ReplaceFileWithAuthorAndPremiumLocked(filename, files[filename], fileMsg, committerInfo, premiumLevel)
+		} else {
+			err = githubProvider.ReplaceFileWithAuthorAndPremium(filename, files[filename], fileMsg, committerInfo, premiumLevel)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to commit %s: %w", filename, err)
+		}
+	}
+
+	logger.Info("Committed sync files one by one", map[string]interface{}{
+		"chat_id": chatID,
+		"files":   len(files),
+	})
+	return nil
+}
+
+// describeSyncCommitMode describes a sync commit mode for /sync mode
+func describeSyncCommitMode(mode string) string {
+	if mode == database.SyncCommitModePerFile {
+		return "/sync commits each changed file separately."
+	}
+	return "/sync commits all changed files in a single commit."
+}
+
+// handleSyncModeCommand shows or sets the sync commit mode: /sync mode [squash|per-file]
+func (b *Bot) handleSyncModeCommand(message *tgbotapi.Message, args []string) error {
+	chatID := message.Chat.ID
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Sync commit settings require a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	usage := `
+
+• /sync mode squash - One commit for all changed files
+• /sync mode per-file - One commit per changed file`
+
+	var mode string
+	switch {
+	case len(args) == 0:
+		b.sendResponse(chatID, "🔀 "+describeSyncCommitMode(b.syncCommitMode(chatID))+usage)
+		return nil
+	case len(args) == 1 && strings.ToLower(args[0]) == "squash":
+		mode = database.SyncCommitModeSquash
+	case len(args) == 1 && (strings.ToLower(args[0]) == "per-file" || strings.ToLower(args[0]) == "per_file"):
+		mode = database.SyncCommitModePerFile
+	default:
+		b.sendResponse(chatID, "❌ Unknown sync commit mode."+usage)
+		return nil
+	}
+
+	if err := b.db.UpdateUserSyncCommitMode(chatID, mode); err != nil {
+		logger.Error("Failed to update sync commit mode", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, "❌ Failed to update sync commit mode")
+		return nil
+	}
+
+	b.sendResponse(chatID, fmt.Sprintf("%s %s", consts.EmojiSuccess, describeSyncCommitMode(mode)))
+	return nil
+}
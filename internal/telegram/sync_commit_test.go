@@ -0,0 +1,28 @@
+package telegram
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestSyncCommitOrder(t *testing.T) {
+	files := map[string]string{
+		"issue.md":               "",
+		"issue_archived_2026.md": "",
+		"issue_archived_2025.md": "",
+	}
+
+	want := []string{"issue_archived_2025.md", "issue_archived_2026.md", "issue.md"}
+	if order := syncCommitOrder(files); !reflect.DeepEqual(order, want) {
+		t.Errorf("syncCommitOrder() = %v, want %v", order, want)
+	}
+}
+
+func TestSyncCommitModeWithoutDatabase(t *testing.T) {
+	bot := &Bot{}
+	if mode := bot.syncCommitMode(42); mode != database.SyncCommitModeSquash {
+		t.Errorf("syncCommitMode() = %q, want %q", mode, database.SyncCommitModeSquash)
+	}
+}
@@ -0,0 +1,160 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+// Sync reports: /sync (and the /sync dry run) ends with a summary of what changed in issue.md
+// and what could not be synced, instead of only the final open/closed counts
+
+// maxSyncReportIssues caps how many issue numbers are listed per report line
+const maxSyncReportIssues = 10
+
+// syncReport summarizes the changes of one sync
+type syncReport struct {
+	DryRun       bool
+	Open         int
+	Closed       int
+	NowClosed    []int          // open in issue.md, closed on GitHub
+	Reopened     []int          // closed in issue.md, open on GitHub
+	Renamed      []int          // title changed on GitHub
+	Skipped      []int          // not returned by GitHub, kept unchanged
+	Unrecognized int            // issue.md lines that are not issue lines, dropped on rewrite
+	Archived     map[string]int // archive file -> issues moved there
+	TodosChecked int            // linked TODOs checked off
+}
+
+// buildSyncReport compares the issues of issue.md with the statuses fetched for the active ones
+func buildSyncReport(current, fetched map[int]*github.IssueStatus, active []int) *syncReport {
+	report := &syncReport{Archived: make(map[string]int)}
+
+	for _, number := range active {
+		status, ok := fetched[number]
+		if !ok {
+			report.Skipped = append(report.Skipped, number)
+			continue
+		}
+		old := current[number]
+		if old == nil {
+			continue
+		}
+
+		wasOpen := strings.ToLower(old.State) == "open"
+		isOpen := strings.ToLower(status.State) == "open"
+		switch {
+		case wasOpen && !isOpen:
+			report.NowClosed = append(report.NowClosed, number)
+		case !wasOpen && isOpen:
+			report.Reopened = append(report.Reopened, number)
+		}
+		if status.Title != "" && status.Title != old.Title {
+			report.Renamed = append(report.Renamed, number)
+		}
+	}
+
+	sort.Sort(sort.Reverse(sort.IntSlice(report.NowClosed)))
+	sort.Sort(sort.Reverse(sort.IntSlice(report.Reopened)))
+	sort.Sort(sort.Reverse(sort.IntSlice(report.Renamed)))
+	sort.Sort(sort.Reverse(sort.IntSlice(report.Skipped)))
+	return report
+}
+
+// countUnrecognizedIssueLines counts the non-empty issue.md lines a sync would drop
+func countUnrecognizedIssueLines(content string) int {
+	count := 0
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !issueLinePattern.MatchString(line) {
+			count++
+		}
+	}
+	return count
+}
+
+// HasChanges reports whether the sync rewrites any file
+func (r *syncReport) HasChanges() bool {
+	return len(r.NowClosed) > 0 || len(r.Reopened) > 0 || len(r.Renamed) > 0 ||
+		r.Unrecognized > 0 || len(r.Archived) > 0 || r.TodosChecked > 0
+}
+
+// Format renders the report as Telegram HTML; archiveLinks maps archive files to their links
+func (r *syncReport) Format(issueFileLink string, archiveLinks map[string]string) string {
+	var sb strings.Builder
+	verb := func(done, planned string) string {
+		if r.DryRun {
+			return planned
+		}
+		return done
+	}
+
+	if r.DryRun {
+		sb.WriteString("🔍 <b>Sync dry run</b> - nothing was committed\n\n")
+	}
+	sb.WriteString(fmt.Sprintf("%s %d issues: %d open 🟢, %d closed 🔴\n",
+		verb("✅ Synced", "📋 Would sync"), r.Open+r.Closed, r.Open, r.Closed))
+
+	if !r.HasChanges() {
+		sb.WriteString("\nNo changes - issue.md is up to date\n")
+	}
+	if len(r.NowClosed) > 0 {
+		sb.WriteString(fmt.Sprintf("🔴 %s: %s\n", verb("Closed", "Would close"), formatIssueNumbers(r.NowClosed)))
+	}
+	if len(r.Reopened) > 0 {
+		sb.WriteString(fmt.Sprintf("🟢 %s: %s\n", verb("Reopened", "Would reopen"), formatIssueNumbers(r.Reopened)))
+	}
+	if len(r.Renamed) > 0 {
+		sb.WriteString(fmt.Sprintf("✏️ %s: %s\n", verb("Renamed", "Would rename"), formatIssueNumbers(r.Renamed)))
+	}
+
+	files := make([]string, 0, len(r.Archived))
+	for filename := range r.Archived {
+		files = append(files, filename)
+	}
+	sort.Strings(files)
+	for _, filename := range files {
+		name := html.EscapeString(filename)
+		if link, ok := archiveLinks[filename]; ok && link != "" {
+			name = fmt.Sprintf("<a href=\"%s\">%s</a>", link, name)
+		}
+		sb.WriteString(fmt.Sprintf("📦 %s %d closed issues to %s\n", verb("Archived", "Would archive"), r.Archived[filename], name))
+	}
+	if r.TodosChecked > 0 {
+		sb.WriteString(fmt.Sprintf("☑️ %s %d linked TODOs\n", verb("Checked off", "Would check off"), r.TodosChecked))
+	}
+
+	if len(r.Skipped) > 0 || r.Unrecognized > 0 {
+		sb.WriteString("\n⚠️ <b>Needs attention</b>\n")
+		if len(r.Skipped) > 0 {
+			sb.WriteString(fmt.Sprintf("• Not returned by GitHub, kept unchanged: %s\n", formatIssueNumbers(r.Skipped)))
+		}
+		if r.Unrecognized > 0 {
+			sb.WriteString(fmt.Sprintf("• %d unrecognized lines in issue.md %s\n", r.Unrecognized, verb("were dropped", "would be dropped")))
+		}
+	}
+
+	if !r.DryRun && issueFileLink != "" {
+		sb.WriteString(fmt.Sprintf("\n🔗 <a href=\"%s\">View issue.md</a>", issueFileLink))
+	} else if r.DryRun && r.HasChanges() {
+		sb.WriteString("\nRun /sync to apply these changes.")
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// formatIssueNumbers lists issue numbers as #12, #7, ... capped at maxSyncReportIssues
+func formatIssueNumbers(numbers []int) string {
+	var parts []string
+	for i, number := range numbers {
+		if i == maxSyncReportIssues {
+			parts = append(parts, fmt.Sprintf("and %d more", len(numbers)-i))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("#%d", number))
+	}
+	return strings.Join(parts, ", ")
+}
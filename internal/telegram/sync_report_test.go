@@ -0,0 +1,96 @@
+package telegram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+func TestBuildSyncReport(t *testing.T) {
+	current := map[int]*github.IssueStatus{
+		1: {Number: 1, Title: "Unchanged", State: "open"},
+		2: {Number: 2, Title: "Fixed", State: "open"},
+		3: {Number: 3, Title: "Old title", State: "open"},
+		4: {Number: 4, Title: "Deleted", State: "open"},
+	}
+	fetched := map[int]*github.IssueStatus{
+		1: {Number: 1, Title: "Unchanged", State: "OPEN"},
+		2: {Number: 2, Title: "Fixed", State: "CLOSED"},
+		3: {Number: 3, Title: "New title", State: "OPEN"},
+	}
+
+	report := buildSyncReport(current, fetched, []int{4, 3, 2, 1})
+	if !reflect.DeepEqual(report.NowClosed, []int{2}) || report.Reopened != nil {
+		t.Errorf("state changes = closed %v, reopened %v", report.NowClosed, report.Reopened)
+	}
+	if !reflect.DeepEqual(report.Renamed, []int{3}) || !reflect.DeepEqual(report.Skipped, []int{4}) {
+		t.Errorf("renamed %v, skipped %v", report.Renamed, report.Skipped)
+	}
+	if !report.HasChanges() {
+		t.Error("HasChanges() = false, want true")
+	}
+
+	if report := buildSyncReport(current, fetched, []int{1}); report.HasChanges() {
+		t.Errorf("HasChanges() = true for unchanged issue: %+v", report)
+	}
+}
+
+func TestCountUnrecognizedIssueLines(t *testing.T) {
+	content := "- 🟢 owner/repo#2 [Open issue]\n" +
+		"\n" +
+		"# Notes\n" +
+		"- 🔴 owner/repo#1 [Closed issue] (closed 2026-01-31)\n" +
+		"- a stray bullet\n"
+
+	if count := countUnrecognizedIssueLines(content); count != 2 {
+		t.Errorf("countUnrecognizedIssueLines() = %d, want 2", count)
+	}
+}
+
+func TestSyncReportFormat(t *testing.T) {
+	report := &syncReport{
+		Open:      3,
+		Closed:    1,
+		NowClosed: []int{2},
+		Skipped:   []int{4},
+		Archived:  map[string]int{"issue_archived_2025.md": 5},
+	}
+
+	text := report.Format("https://example.com/issue.md", map[string]string{"issue_archived_2025.md": "https://example.com/a.md"})
+	for _, want := range []string{
+		"✅ Synced 4 issues: 3 open 🟢, 1 closed 🔴",
+		"🔴 Closed: #2",
+		"📦 Archived 5 closed issues to <a href=\"https://example.com/a.md\">issue_archived_2025.md</a>",
+		"kept unchanged: #4",
+		"View issue.md",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Format() = %q, missing %q", text, want)
+		}
+	}
+
+	report.DryRun = true
+	text = report.Format("", nil)
+	for _, want := range []string{"nothing was committed", "🔴 Would close: #2", "Would archive 5", "Run /sync to apply"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Format(dry run) = %q, missing %q", text, want)
+		}
+	}
+	if strings.Contains(text, "View issue.md") {
+		t.Errorf("Format(dry run) links issue.md: %q", text)
+	}
+}
+
+func TestFormatIssueNumbers(t *testing.T) {
+	var numbers []int
+	for i := 12; i > 0; i-- {
+		numbers = append(numbers, i)
+	}
+
+	want := "#12, #11, #10, #9, #8, #7, #6, #5, #4, #3, and 2 more"
+	if got := formatIssueNumbers(numbers); got != want {
+		t.Errorf("formatIssueNumbers() = %q, want %q", got, want)
+	}
+}
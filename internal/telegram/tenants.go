@@ -0,0 +1,518 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Tenants: operators running the bot for a community group chats into tenants with aggregate
+// disk and token quotas. Operators manage tenants with /admin tenant, tenant admins with /tenant.
+
+var tenantNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// errTenantDiskQuota refuses commits once a tenant used up its disk quota
+var errTenantDiskQuota = errors.New("tenant disk quota used up")
+
+// tenantInviteExpiry is how long a chat invited by a tenant admin has to accept
+const tenantInviteExpiry = 24 * time.Hour
+
+// tenantInvite is a tenant admin's pending invitation of a chat
+type tenantInvite struct {
+	TenantID    int
+	TenantName  string
+	AdminChatID int64
+}
+
+func tenantInviteKey(chatID int64) string {
+	return fmt.Sprintf("tenant_invite_%d", chatID)
+}
+
+// tenantDiskExceeded reports whether the members' combined repository size reached the disk quota
+func tenantDiskExceeded(tenant *database.Tenant, usage *database.TenantUsage) bool {
+	return tenant.DiskQuotaMB > 0 && usage.RepoSizeMB >= tenant.DiskQuotaMB
+}
+
+// tenantTokensExceeded reports whether estimatedTokens more would exceed the token quota
+func tenantTokensExceeded(tenant *database.Tenant, usage *database.TenantUsage, estimatedTokens int64) bool {
+	return tenant.TokenQuota > 0 && usage.TokensUsed+estimatedTokens > tenant.TokenQuota
+}
+
+// loadChatTenant returns the chat's tenant and its usage, or nil for chats without a tenant
+func (b *Bot) loadChatTenant(chatID int64) (*database.Tenant, *database.TenantUsage) {
+	if b.db == nil {
+		return nil, nil
+	}
+
+	tenant, err := b.db.GetTenantForChat(chatID)
+	if err != nil || tenant == nil {
+		if err != nil {
+			logger.Warn("Failed to get tenant for chat", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+		}
+		return nil, nil
+	}
+
+	usage, err := b.db.GetTenantUsage(tenant.ID)
+	if err != nil {
+		logger.Warn("Failed to get tenant usage", map[string]interface{}{
+			"chat_id": chatID,
+			"tenant":  tenant.Name,
+			"error":   err.Error(),
+		})
+		return nil, nil
+	}
+
+	return tenant, usage
+}
+
+// tenantAllowsTokens reports whether the chat's tenant still has default LLM tokens left
+func (b *Bot) tenantAllowsTokens(chatID int64, estimatedTokens int64) bool {
+	tenant, usage := b.loadChatTenant(chatID)
+	if tenant == nil || !tenantTokensExceeded(tenant, usage, estimatedTokens) {
+		return true
+	}
+
+	logger.Info("Default LLM blocked by tenant token quota", map[string]interface{}{
+		"chat_id":     chatID,
+		"tenant":      tenant.Name,
+		"tokens_used": usage.TokensUsed,
+		"token_quota": tenant.TokenQuota,
+	})
+	return false
+}
+
+// tenantDiskQuotaError returns an error once the chat's tenant used up its combined disk quota.
+// It's the WriteQuota of the chat's providers, so every commit adding content checks it.
+func (b *Bot) tenantDiskQuotaError(chatID int64) error {
+	tenant, usage := b.loadChatTenant(chatID)
+	if tenant == nil || !tenantDiskExceeded(tenant, usage) {
+		return nil
+	}
+
+	logger.Info("Save blocked by tenant disk quota", map[string]interface{}{
+		"chat_id":       chatID,
+		"tenant":        tenant.Name,
+		"repo_size_mb":  usage.RepoSizeMB,
+		"disk_quota_mb": tenant.DiskQuotaMB,
+	})
+	return fmt.Errorf("%w: the repositories of tenant %s use %.1f MB of the shared %.1f MB quota, ask your tenant admin to free space",
+		errTenantDiskQuota, tenant.Name, usage.RepoSizeMB, tenant.DiskQuotaMB)
+}
+
+// formatTenantUsage renders a tenant's usage against its quotas
+func formatTenantUsage(tenant *database.Tenant, usage *database.TenantUsage) string {
+	disk := "unlimited"
+	if tenant.DiskQuotaMB > 0 {
+		disk = fmt.Sprintf("%.1f MB (%.0f%%)", tenant.DiskQuotaMB, usage.RepoSizeMB/tenant.DiskQuotaMB*100)
+	}
+	tokens := "unlimited"
+	if tenant.TokenQuota > 0 {
+		tokens = fmt.Sprintf("%s (%.0f%%)", formatTokenCount(tenant.TokenQuota), float64(usage.TokensUsed)/float64(tenant.TokenQuota)*100)
+	}
+
+	return fmt.Sprintf(`👥 <b>Members:</b> %d
+💾 <b>Disk:</b> %.1f MB / %s
+🧠 <b>Tokens:</b> %s / %s`, usage.Members, usage.RepoSizeMB, disk, formatTokenCount(usage.TokensUsed), tokens)
+}
+
+// formatTenantMembers lists member chat IDs, marking tenant admins
+func formatTenantMembers(members []*database.TenantMember) string {
+	if len(members) == 0 {
+		return "<i>No members yet</i>"
+	}
+
+	var sb strings.Builder
+	for _, m := range members {
+		sb.WriteString(fmt.Sprintf("• <code>%d</code>", m.ChatID))
+		if m.IsAdmin {
+			sb.WriteString(" 🛡 admin")
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// sendTenantDetails shows a tenant's usage and, for admins, its members
+func (b *Bot) sendTenantDetails(chatID int64, tenant *database.Tenant, showMembers bool) {
+	usage, err := b.db.GetTenantUsage(tenant.ID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return
+	}
+
+	text := fmt.Sprintf("🏢 <b>Tenant %s</b>\n\n%s", html.EscapeString(tenant.Name), formatTenantUsage(tenant, usage))
+	if showMembers {
+		members, err := b.db.GetTenantMembers(tenant.ID)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return
+		}
+		text += "\n\n<b>Members:</b>\n" + formatTenantMembers(members)
+	}
+
+	b.sendResponse(chatID, text)
+}
+
+// sendTenantStats shows global statistics restricted to a tenant's members
+func (b *Bot) sendTenantStats(chatID int64, tenant *database.Tenant) {
+	stats, err := b.db.GetTenantStats(tenant.ID)
+	if err != nil {
+		logger.Error("Failed to get tenant stats", map[string]interface{}{
+			"chat_id": chatID,
+			"tenant":  tenant.Name,
+			"error":   err.Error(),
+		})
+		b.sendResponse(chatID, "❌ Failed to get tenant statistics")
+		return
+	}
+
+	b.sendResponse(chatID, formatStatsMessage(fmt.Sprintf("%s Tenant Statistics", html.EscapeString(tenant.Name)), stats))
+}
+
+// handleAdminTenantsCommand lists all tenants with their usage
+func (b *Bot) handleAdminTenantsCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Tenants require a database.")
+		return nil
+	}
+
+	tenants, err := b.db.GetTenants()
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load tenants: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	if len(tenants) == 0 {
+		b.sendResponse(chatID, "🏢 No tenants defined.\n\nCreate one with <code>/admin tenant &lt;name&gt; create</code>")
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🏢 <b>Tenants</b>\n")
+	for _, tenant := range tenants {
+		usage, err := b.db.GetTenantUsage(tenant.ID)
+		if err != nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n<b>%s</b>\n%s\n", html.EscapeString(tenant.Name), formatTenantUsage(tenant, usage)))
+	}
+
+	b.sendResponse(chatID, sb.String())
+	return nil
+}
+
+// handleAdminTenantCommand manages a single tenant:
+// /admin tenant <name> [create|delete|stats]
+// /admin tenant <name> disk <MB> | tokens <n>
+// /admin tenant <name> add <chat_id> [admin] | remove <chat_id>
+func (b *Bot) handleAdminTenantCommand(message *tgbotapi.Message, args []string) error {
+	chatID := message.Chat.ID
+	usage := "Usage: <code>/admin tenant &lt;name&gt; create|delete|stats|disk &lt;MB&gt;|tokens &lt;n&gt;|add &lt;chat_id&gt; [admin]|remove &lt;chat_id&gt;</code>"
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Tenants require a database.")
+		return nil
+	}
+	if len(args) < 1 {
+		b.sendResponse(chatID, usage)
+		return nil
+	}
+
+	name := strings.ToLower(args[0])
+	action := ""
+	if len(args) > 1 {
+		action = args[1]
+	}
+
+	if action == "create" {
+		if !tenantNamePattern.MatchString(name) {
+			b.sendResponse(chatID, "❌ Tenant names use lowercase letters, digits, '-' and '_' (max 64).")
+			return nil
+		}
+		if _, err := b.db.CreateTenant(name); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("✅ Tenant <b>%s</b> created with unlimited quotas.", html.EscapeString(name)))
+		return nil
+	}
+
+	tenant, err := b.db.GetTenantByName(name)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if tenant == nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Tenant <b>%s</b> not found.", html.EscapeString(name)))
+		return nil
+	}
+
+	switch action {
+	case "":
+		b.sendTenantDetails(chatID, tenant, true)
+		return nil
+	case "stats":
+		b.sendTenantStats(chatID, tenant)
+		return nil
+	case "delete":
+		if err := b.db.DeleteTenant(tenant.ID); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("🗑 Tenant <b>%s</b> deleted. Its chats keep their data.", html.EscapeString(tenant.Name)))
+		return nil
+	}
+
+	if len(args) < 3 {
+		b.sendResponse(chatID, usage)
+		return nil
+	}
+
+	switch action {
+	case "disk":
+		diskQuotaMB, err := strconv.ParseFloat(args[2], 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid number: %s", html.EscapeString(args[2])))
+			return nil
+		}
+		err = b.db.SetTenantQuotas(tenant.ID, diskQuotaMB, tenant.TokenQuota)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+	case "tokens":
+		tokenQuota, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid number: %s", html.EscapeString(args[2])))
+			return nil
+		}
+		err = b.db.SetTenantQuotas(tenant.ID, tenant.DiskQuotaMB, tokenQuota)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+	case "add", "remove":
+		memberID, err := strconv.ParseInt(args[2], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid chat ID: %s", html.EscapeString(args[2])))
+			return nil
+		}
+		if action == "add" {
+			isAdmin := len(args) > 3 && args[3] == "admin"
+			err = b.db.SetTenantMember(tenant.ID, memberID, isAdmin)
+		} else {
+			err = b.db.RemoveTenantMember(tenant.ID, memberID)
+		}
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+			return nil
+		}
+	default:
+		b.sendResponse(chatID, usage)
+		return nil
+	}
+
+	logger.Info("Tenant changed by admin", map[string]interface{}{
+		"admin_chat_id": chatID,
+		"tenant":        tenant.Name,
+		"action":        action,
+	})
+
+	// Show the result with fresh quotas
+	if tenant, err = b.db.GetTenantByName(name); err == nil && tenant != nil {
+		b.sendTenantDetails(chatID, tenant, true)
+	}
+	return nil
+}
+
+// handleTenantCommand shows the user's tenant and lets tenant admins manage members:
+// /tenant [stats]
+// /tenant add|remove <chat_id>
+func (b *Bot) handleTenantCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Tenants require database configuration. Please contact the administrator.")
+		return nil
+	}
+
+	member, err := b.db.GetTenantMember(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ Failed to load your tenant")
+		return nil
+	}
+	if member == nil {
+		b.sendResponse(chatID, "🏢 This chat doesn't belong to a tenant.")
+		return nil
+	}
+
+	tenant, err := b.db.GetTenantForChat(chatID)
+	if err != nil || tenant == nil {
+		b.sendResponse(chatID, "❌ Failed to load your tenant")
+		return nil
+	}
+
+	args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/tenant"))
+	if len(args) == 0 {
+		b.sendTenantDetails(chatID, tenant, member.IsAdmin)
+		return nil
+	}
+
+	switch args[0] {
+	case "stats":
+		b.sendTenantStats(chatID, tenant)
+		return nil
+	case "add", "remove":
+		if !member.IsAdmin {
+			b.sendResponse(chatID, "❌ Only tenant admins can manage members.")
+			return nil
+		}
+		if len(args) < 2 {
+			b.sendResponse(chatID, "Usage: <code>/tenant add|remove &lt;chat_id&gt;</code>")
+			return nil
+		}
+		memberID, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid chat ID: %s", html.EscapeString(args[1])))
+			return nil
+		}
+		return b.updateTenantMemberByAdmin(chatID, tenant, args[0], memberID)
+	default:
+		b.sendResponse(chatID, "Usage: <code>/tenant [stats|add &lt;chat_id&gt;|remove &lt;chat_id&gt;]</code>")
+		return nil
+	}
+}
+
+// updateTenantMemberByAdmin invites or removes a regular member. Invited chats only join once they
+// accept. Tenant admins can't take chats from other tenants or remove admins, both are left to the
+// operator.
+func (b *Bot) updateTenantMemberByAdmin(chatID int64, tenant *database.Tenant, action string, memberID int64) error {
+	existing, err := b.db.GetTenantMember(memberID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	if action == "add" {
+		if existing != nil {
+			b.sendResponse(chatID, "❌ This chat already belongs to a tenant.")
+			return nil
+		}
+		return b.sendTenantInvite(chatID, tenant, memberID)
+	}
+
+	if existing == nil || existing.TenantID != tenant.ID {
+		b.sendResponse(chatID, "❌ This chat is not a member of your tenant.")
+		return nil
+	}
+	if existing.IsAdmin {
+		b.sendResponse(chatID, "❌ Tenant admins can only be removed by the bot operator.")
+		return nil
+	}
+	if err := b.db.RemoveTenantMember(tenant.ID, memberID); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	logger.Info("Tenant member removed by tenant admin", map[string]interface{}{
+		"chat_id":   chatID,
+		"tenant":    tenant.Name,
+		"member_id": memberID,
+	})
+
+	b.sendTenantDetails(chatID, tenant, true)
+	return nil
+}
+
+// sendTenantInvite asks memberID to join the tenant, its repository only counts toward the
+// tenant's quotas once the chat accepts
+func (b *Bot) sendTenantInvite(chatID int64, tenant *database.Tenant, memberID int64) error {
+	msg := tgbotapi.NewMessage(memberID, fmt.Sprintf(`🏢 <b>Tenant invitation</b>
+
+The admins of tenant <b>%s</b> invite this chat. Members share the tenant's disk and token quotas, and tenant admins see the chat in the member list.
+
+The invitation expires in 24 hours.`, html.EscapeString(tenant.Name)))
+	msg.ParseMode = "HTML"
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Join", fmt.Sprintf("tenant_accept_%d", tenant.ID)),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Decline", fmt.Sprintf("tenant_decline_%d", tenant.ID)),
+	))
+	if _, err := b.rateLimitedSend(memberID, msg); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to invite <code>%d</code>, the chat has to start the bot first: %s", memberID, html.EscapeString(err.Error())))
+		return nil
+	}
+
+	b.cache.SetWithExpiry(tenantInviteKey(memberID), &tenantInvite{
+		TenantID:    tenant.ID,
+		TenantName:  tenant.Name,
+		AdminChatID: chatID,
+	}, tenantInviteExpiry)
+
+	logger.Info("Tenant invitation sent", map[string]interface{}{
+		"chat_id":   chatID,
+		"tenant":    tenant.Name,
+		"member_id": memberID,
+	})
+	b.sendResponse(chatID, fmt.Sprintf("📨 Invitation sent to <code>%d</code>, the chat joins once it accepts.", memberID))
+	return nil
+}
+
+// handleTenantInviteCallback accepts or declines a tenant invitation:
+// tenant_accept_<tenant_id>, tenant_decline_<tenant_id>
+func (b *Bot) handleTenantInviteCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID := callback.Message.MessageID
+
+	accept := strings.HasPrefix(callback.Data, "tenant_accept_")
+	tenantID, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(callback.Data, "tenant_accept_"), "tenant_decline_"))
+	if err != nil {
+		return fmt.Errorf("invalid tenant invitation: %w", err)
+	}
+
+	cached, exists := b.cache.Get(tenantInviteKey(chatID))
+	invite, ok := cached.(*tenantInvite)
+	if !exists || !ok || invite.TenantID != tenantID || b.db == nil {
+		b.editMessage(chatID, messageID, "⌛ This invitation expired. Ask the tenant admin to invite this chat again.")
+		return nil
+	}
+	b.cache.Delete(tenantInviteKey(chatID))
+
+	if !accept {
+		b.editMessage(chatID, messageID, fmt.Sprintf("Declined the invitation of tenant %s.", invite.TenantName))
+		b.sendResponse(invite.AdminChatID, fmt.Sprintf("🚫 <code>%d</code> declined the invitation to <b>%s</b>.", chatID, html.EscapeString(invite.TenantName)))
+		return nil
+	}
+
+	existing, err := b.db.GetTenantMember(chatID)
+	if err != nil {
+		b.editMessage(chatID, messageID, "❌ "+err.Error())
+		return nil
+	}
+	if existing != nil {
+		b.editMessage(chatID, messageID, "❌ This chat already belongs to a tenant.")
+		return nil
+	}
+	if err := b.db.SetTenantMember(invite.TenantID, chatID, false); err != nil {
+		b.editMessage(chatID, messageID, "❌ Failed to join: "+err.Error())
+		return nil
+	}
+
+	logger.Info("Tenant invitation accepted", map[string]interface{}{
+		"chat_id": chatID,
+		"tenant":  invite.TenantName,
+	})
+	b.editMessage(chatID, messageID, fmt.Sprintf("✅ This chat joined tenant %s. See its quotas with /tenant.", invite.TenantName))
+	b.sendResponse(invite.AdminChatID, fmt.Sprintf("✅ <code>%d</code> joined <b>%s</b>.", chatID, html.EscapeString(invite.TenantName)))
+	return nil
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestTenantQuotaChecks(t *testing.T) {
+	usage := &database.TenantUsage{Members: 3, RepoSizeMB: 250, TokensUsed: 900}
+
+	tests := []struct {
+		name       string
+		tenant     *database.Tenant
+		estimated  int64
+		wantDisk   bool
+		wantTokens bool
+	}{
+		{"unlimited", &database.Tenant{}, 1000, false, false},
+		{"under quotas", &database.Tenant{DiskQuotaMB: 500, TokenQuota: 2000}, 100, false, false},
+		{"disk reached", &database.Tenant{DiskQuotaMB: 250, TokenQuota: 2000}, 100, true, false},
+		{"tokens would exceed", &database.Tenant{DiskQuotaMB: 500, TokenQuota: 950}, 100, false, true},
+	}
+
+	for _, tt := range tests {
+		if got := tenantDiskExceeded(tt.tenant, usage); got != tt.wantDisk {
+			t.Errorf("%s: tenantDiskExceeded() = %v, want %v", tt.name, got, tt.wantDisk)
+		}
+		if got := tenantTokensExceeded(tt.tenant, usage, tt.estimated); got != tt.wantTokens {
+			t.Errorf("%s: tenantTokensExceeded() = %v, want %v", tt.name, got, tt.wantTokens)
+		}
+	}
+}
+
+func TestFormatTenantUsage(t *testing.T) {
+	usage := &database.TenantUsage{Members: 2, RepoSizeMB: 50, TokensUsed: 1000}
+
+	text := formatTenantUsage(&database.Tenant{DiskQuotaMB: 200}, usage)
+	if !strings.Contains(text, "50.0 MB / 200.0 MB (25%)") || !strings.Contains(text, "/ unlimited") {
+		t.Errorf("formatTenantUsage() = %q", text)
+	}
+}
+
+func TestTenantNamePattern(t *testing.T) {
+	for _, name := range []string{"acme", "book-club_2"} {
+		if !tenantNamePattern.MatchString(name) {
+			t.Errorf("Expected %q to be a valid tenant name", name)
+		}
+	}
+	for _, name := range []string{"", "-acme", "Acme", "a b", strings.Repeat("a", 65)} {
+		if tenantNamePattern.MatchString(name) {
+			t.Errorf("Expected %q to be an invalid tenant name", name)
+		}
+	}
+}
+
+func TestTenantInviteCallbackRequiresInvite(t *testing.T) {
+	bot, fake := newFakeBot(t)
+	bot.cache.Set(tenantInviteKey(42), &tenantInvite{TenantID: 7, TenantName: "club", AdminChatID: 1})
+
+	// Accepting another tenant than the one that invited the chat doesn't join it
+	callback := &tgbotapi.CallbackQuery{ID: "cb", Message: commandMessage(42, "invite"), Data: "tenant_accept_8"}
+	if err := bot.handleTenantInviteCallback(callback); err != nil {
+		t.Fatalf("handleTenantInviteCallback() error = %v", err)
+	}
+	last, ok := fake.LastMessage()
+	if !ok || !strings.Contains(last.Text, "expired") {
+		t.Errorf("Last message = %q, want the expired notice", last.Text)
+	}
+}
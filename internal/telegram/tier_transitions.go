@@ -0,0 +1,202 @@
+package telegram
+
+import (
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Tier transitions: free trials of a higher tier, and a graceful downgrade when premium ends.
+// A downgraded repository larger than the new limit is kept as is and becomes read-only.
+
+const (
+	premiumTrialLevel      = consts.PremiumLevelCoffee
+	premiumTrialDuration   = 7 * 24 * time.Hour
+	tierTransitionInterval = 1 * time.Hour
+	tierEndingNoticeLead   = 24 * time.Hour
+)
+
+// tierCallbackKeys maps premium levels to the tier names used in subscription_ callbacks
+var tierCallbackKeys = map[int]string{
+	consts.PremiumLevelCoffee:  "coffee",
+	consts.PremiumLevelCake:    "cake",
+	consts.PremiumLevelSponsor: "sponsor",
+}
+
+// startTierTransitions periodically notifies users whose premium is ending or has ended
+func (b *Bot) startTierTransitions() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopTierTransitions = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(tierTransitionInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runTierTransitions()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runTierTransitions() {
+	now := time.Now()
+	users, err := b.db.GetPremiumUsersForExpiryNotice(now.Add(tierEndingNoticeLead).Unix())
+	if err != nil {
+		logger.Error("Failed to load expiring premium users", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, pu := range users {
+		var notice string
+		switch {
+		case pu.IsExpired():
+			notice = database.ExpiryNoticeExpired
+			b.notifyTierExpired(pu)
+		case pu.ExpiryNotice == "" && !pu.IsSubscription:
+			// Subscriptions renew automatically, they are only told when renewal didn't happen
+			notice = database.ExpiryNoticeEnding
+			b.notifyTierEnding(pu)
+		default:
+			continue
+		}
+
+		if err := b.db.SetPremiumExpiryNotice(pu.UID, notice); err != nil {
+			logger.Warn("Failed to record expiry notice", map[string]interface{}{
+				"chat_id": pu.UID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+func (b *Bot) notifyTierEnding(pu *database.PremiumUser) {
+	when := fmt.Sprintf("on %s", time.Unix(pu.ExpireAt, 0).Format("2006-01-02 15:04"))
+	text := fmt.Sprintf(TierEndingTemplate, GetTierName(pu.Level), tierKind(pu), when)
+	b.sendWithRenewButton(pu.UID, text, pu.Level)
+}
+
+func (b *Bot) notifyTierExpired(pu *database.PremiumUser) {
+	var readOnly string
+	if provider, err := b.getUserGitHubProvider(pu.UID); err == nil {
+		sizeMB, percentage, err := provider.GetRepositorySizeInfoWithPremium(0)
+		if err == nil && percentage >= 100 {
+			readOnly = fmt.Sprintf(TierExpiredReadOnlyTemplate, sizeMB, provider.GetRepositoryMaxSizeWithPremium(0))
+		}
+	}
+
+	logger.Info("Premium tier ended", map[string]interface{}{
+		"chat_id":   pu.UID,
+		"level":     pu.Level,
+		"trial":     pu.IsTrial,
+		"read_only": readOnly != "",
+	})
+
+	text := fmt.Sprintf(TierExpiredTemplate, GetTierName(pu.Level), tierKind(pu), readOnly)
+	b.sendWithRenewButton(pu.UID, text, pu.Level)
+}
+
+func tierKind(pu *database.PremiumUser) string {
+	if pu.IsTrial {
+		return "trial"
+	}
+	if pu.IsSubscription {
+		return "subscription"
+	}
+	return "access"
+}
+
+// renewKeyboard offers a one-tap re-upgrade to the tier the user had
+func renewKeyboard(level int) *tgbotapi.InlineKeyboardMarkup {
+	key, ok := tierCallbackKeys[level]
+	if !ok {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("🔄 Renew %s", GetTierName(level)), fmt.Sprintf("subscription_%s_monthly", key)),
+	))
+	return &keyboard
+}
+
+func (b *Bot) sendWithRenewButton(chatID int64, text string, level int) {
+	msg := tgbotapi.NewMessage(chatID, text)
+	msg.ParseMode = "HTML"
+	if keyboard := renewKeyboard(level); keyboard != nil {
+		msg.ReplyMarkup = *keyboard
+	}
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		logger.Warn("Failed to send tier transition notice", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+}
+
+// lapsedPremiumNotice explains a capacity block caused by an ended premium tier,
+// returning the extra text and a renew keyboard, or "" and nil for other users
+func (b *Bot) lapsedPremiumNotice(chatID int64) (string, *tgbotapi.InlineKeyboardMarkup) {
+	if b.db == nil || b.cfg().PaymentsDisabled {
+		return "", nil
+	}
+
+	pu, err := b.db.GetPremiumUser(chatID)
+	if err != nil || pu == nil || !pu.IsLapsed() {
+		return "", nil
+	}
+
+	return fmt.Sprintf(TierLapsedNotice, GetTierName(pu.Level)), renewKeyboard(pu.Level)
+}
+
+// canStartTrial reports whether the free trial button should be offered
+func canStartTrial(pu *database.PremiumUser) bool {
+	return pu == nil || (!pu.TrialUsed && !pu.IsPremiumUser())
+}
+
+// handleStartTrialCallback grants the one-time premium trial
+func (b *Bot) handleStartTrialCallback(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Premium features require database configuration. Please contact the administrator.")
+		return nil
+	}
+
+	expireAt := time.Now().Add(premiumTrialDuration)
+	pu, err := b.db.StartPremiumTrial(chatID, callback.From.UserName, premiumTrialLevel, expireAt.Unix())
+	if err != nil {
+		logger.Error("Failed to start premium trial", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+		b.editMessage(chatID, callback.Message.MessageID, "❌ Failed to start the trial. Please try again later.")
+		return nil
+	}
+	if pu == nil {
+		b.editMessage(chatID, callback.Message.MessageID, TrialUnavailableMessage)
+		return nil
+	}
+
+	tierName := GetTierName(premiumTrialLevel)
+	edit := tgbotapi.NewEditMessageText(chatID, callback.Message.MessageID, fmt.Sprintf(TrialStartedTemplate, tierName, tierName, expireAt.Format("2006-01-02")))
+	edit.ParseMode = "HTML"
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		logger.Warn("Failed to confirm premium trial", map[string]interface{}{
+			"chat_id": chatID,
+			"error":   err.Error(),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestCanStartTrial(t *testing.T) {
+	past := time.Now().Add(-time.Hour).Unix()
+	future := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name string
+		pu   *database.PremiumUser
+		want bool
+	}{
+		{"new user", nil, true},
+		{"free user without trial", &database.PremiumUser{ID: 1, Level: 0, ExpireAt: -1}, true},
+		{"lapsed paid user", &database.PremiumUser{ID: 1, Level: 2, ExpireAt: past}, true},
+		{"active premium", &database.PremiumUser{ID: 1, Level: 1, ExpireAt: future}, false},
+		{"trial already used", &database.PremiumUser{ID: 1, Level: 1, ExpireAt: past, TrialUsed: true}, false},
+	}
+
+	for _, tt := range tests {
+		if got := canStartTrial(tt.pu); got != tt.want {
+			t.Errorf("%s: canStartTrial() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRenewKeyboard(t *testing.T) {
+	keyboard := renewKeyboard(consts.PremiumLevelCake)
+	if keyboard == nil || len(keyboard.InlineKeyboard) != 1 {
+		t.Fatalf("expected a single renew row, got %+v", keyboard)
+	}
+	if data := keyboard.InlineKeyboard[0][0].CallbackData; data == nil || *data != "subscription_cake_monthly" {
+		t.Errorf("renew callback = %v, want subscription_cake_monthly", data)
+	}
+
+	if renewKeyboard(0) != nil {
+		t.Error("free tier should not get a renew button")
+	}
+}
+
+func TestTierKind(t *testing.T) {
+	if got := tierKind(&database.PremiumUser{IsTrial: true}); got != "trial" {
+		t.Errorf("tierKind(trial) = %q", got)
+	}
+	if got := tierKind(&database.PremiumUser{IsSubscription: true}); got != "subscription" {
+		t.Errorf("tierKind(subscription) = %q", got)
+	}
+	if got := tierKind(&database.PremiumUser{}); got != "access" {
+		t.Errorf("tierKind(one-time) = %q", got)
+	}
+}
@@ -0,0 +1,341 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/consts"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/webhook"
+)
+
+// Todo ↔ issue links: a TODO converted to an issue (or an issue added as a TODO) references it in
+// todo.md as <!--[msg_id] [chat_id] [#123]-->, and the issue links back to todo.md. Closing the
+// issue checks the TODO off, checking the TODO offers to close the issue.
+
+// findTodo returns the index of the TODO with messageID belonging to chatID, or -1
+func findTodo(todos []TodoItem, messageID int, chatID int64) int {
+	for i, todo := range todos {
+		if todo.MessageID == messageID && (todo.ChatID == chatID || todo.ChatID == 0) {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatTodoFile formats parsed TODO items back into todo.md content
+func formatTodoFile(todos []TodoItem) string {
+	lines := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		lines = append(lines, formatTodoLine(todo))
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// checkLinkedTodos checks off the open TODOs of chatID linked to one of the issue numbers,
+// returning the updated items and how many changed
+func checkLinkedTodos(todos []TodoItem, chatID int64, issueNumbers []int) ([]TodoItem, int) {
+	closed := make(map[int]bool, len(issueNumbers))
+	for _, number := range issueNumbers {
+		closed[number] = true
+	}
+
+	checked := 0
+	for i, todo := range todos {
+		if !todo.Done && todo.IssueNumber > 0 && closed[todo.IssueNumber] && (todo.ChatID == chatID || todo.ChatID == 0) {
+			todos[i].Done = true
+			checked++
+		}
+	}
+	return todos, checked
+}
+
+// completeLinkedTodos checks off the TODOs linked to closed issues and commits todo.md,
+// returning how many were checked off
+func (b *Bot) completeLinkedTodos(chatID int64, githubProvider github.GitHubProvider, closedIssues []int) int {
+	if len(closedIssues) == 0 {
+		return 0
+	}
+
+	content, err := githubProvider.ReadFile("todo.md")
+	if err != nil || !strings.Contains(content, "[#") {
+		return 0
+	}
+
+	todos, checked := checkLinkedTodos(b.parseTodoItems(content), chatID, closedIssues)
+	if checked == 0 {
+		return 0
+	}
+
+	commitMsg := fmt.Sprintf("Check off %d TODOs of closed issues via Telegram", checked)
+	if err := githubProvider.ReplaceFileWithAuthorAndPremium("todo.md", formatTodoFile(todos), commitMsg, b.getCommitterInfo(chatID), b.getPremiumLevel(chatID)); err != nil {
+		logger.Error("Failed to check off linked TODOs", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+			"issues":  closedIssues,
+		})
+		return 0
+	}
+
+	logger.Info("Checked off TODOs linked to closed issues", map[string]interface{}{
+		"chat_id": chatID,
+		"checked": checked,
+	})
+	return checked
+}
+
+// countLinkedTodos returns how many TODOs completeLinkedTodos would check off, without committing
+func (b *Bot) countLinkedTodos(chatID int64, githubProvider github.GitHubProvider, closedIssues []int) int {
+	if len(closedIssues) == 0 {
+		return 0
+	}
+
+	content, err := githubProvider.ReadFile("todo.md")
+	if err != nil || !strings.Contains(content, "[#") {
+		return 0
+	}
+
+	_, checked := checkLinkedTodos(b.parseTodoItems(content), chatID, closedIssues)
+	return checked
+}
+
+// confirmCloseLinkedIssue asks whether to close the issue linked to a TODO that was just checked off
+func (b *Bot) confirmCloseLinkedIssue(chatID int64, githubProvider github.GitHubProvider, issueNumber int) {
+	if status, err := githubProvider.GetIssueStatus(issueNumber); err == nil && strings.ToLower(status.State) != "open" {
+		return
+	}
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("🔗 This TODO is linked to issue #%d. Close the issue too?", issueNumber))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("✅ Close #%d", issueNumber), fmt.Sprintf("issue_close_%d", issueNumber)),
+		tgbotapi.NewInlineKeyboardButtonData("Keep open", fmt.Sprintf("todo_keepissue_%d", issueNumber)),
+	))
+	if _, err := b.rateLimitedSend(chatID, msg); err != nil {
+		logger.Error("Failed to send linked issue confirmation", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+		})
+	}
+}
+
+// editOrSendHTML edits the status message with HTML text, or sends it when there is no status message
+func (b *Bot) editOrSendHTML(chatID int64, messageID int, text string) {
+	if messageID == 0 {
+		b.sendResponse(chatID, text)
+		return
+	}
+	if telegramLength(text) > telegramMessageLimit {
+		b.sendLongReply(chatID, longReply{Text: text, EditMessageID: messageID})
+		return
+	}
+	edit := tgbotapi.NewEditMessageText(chatID, messageID, text)
+	edit.ParseMode = consts.ParseModeHTML
+	if _, err := b.rateLimitedSend(chatID, edit); err != nil {
+		logger.Error("Failed to edit message", map[string]interface{}{
+			"error":      err.Error(),
+			"chat_id":    chatID,
+			"message_id": messageID,
+		})
+		b.sendResponse(chatID, text)
+	}
+}
+
+// handleTodoKeepIssue answers "Keep open" on the linked issue confirmation
+func (b *Bot) handleTodoKeepIssue(callback *tgbotapi.CallbackQuery) error {
+	issueNumber, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "todo_keepissue_"))
+	if err != nil {
+		return fmt.Errorf("invalid issue number: %w", err)
+	}
+
+	b.editMessage(callback.Message.Chat.ID, callback.Message.MessageID, fmt.Sprintf("👍 Issue #%d stays open", issueNumber))
+	return nil
+}
+
+// handleTodoToIssue converts a TODO into a GitHub issue and links the two
+func (b *Bot) handleTodoToIssue(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	messageID, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "todo_issue_"))
+	if err != nil {
+		return fmt.Errorf("invalid message ID: %w", err)
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	todoContent, err := userGitHubProvider.ReadFile("todo.md")
+	if err != nil {
+		b.sendResponse(chatID, "❌ Failed to read TODO file")
+		return nil
+	}
+
+	todos := b.parseTodoItems(todoContent)
+	index := findTodo(todos, messageID, chatID)
+	if index < 0 {
+		b.sendResponse(chatID, "❌ TODO item not found")
+		return nil
+	}
+	if todos[index].IssueNumber > 0 {
+		b.sendResponse(chatID, fmt.Sprintf("🔗 This TODO is already linked to issue #%d", todos[index].IssueNumber))
+		return nil
+	}
+
+	premiumLevel := b.getPremiumLevel(chatID)
+	if b.db != nil {
+		issues, err := b.usageLimits().Issues(chatID, premiumLevel)
+		if err != nil {
+			logger.Error("Failed to check issue limit", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		} else if !issues.Allowed {
+			b.sendResponse(chatID, fmt.Sprintf("🚫 Issue creation limit reached: %d/%d", issues.Current, issues.Max))
+			return nil
+		}
+	}
+
+	statusMessageID := b.sendResponseAndGetMessageID(chatID, "❓ Creating GitHub issue from TODO...")
+
+	todo := todos[index]
+	body := "From a TODO in todo.md"
+	if todoURL, err := userGitHubProvider.GetGitHubFileURLWithBranch("todo.md"); err == nil {
+		body = fmt.Sprintf("From a TODO in [todo.md](%s)", todoURL)
+	}
+	body += fmt.Sprintf("\n\n<!--todo:[%d] [%d]-->", todo.MessageID, chatID)
+
+	issueURL, issueNumber, err := userGitHubProvider.CreateIssueWithLabels(todo.Content, body, withIssueMarker(nil))
+	if err != nil {
+		logger.Error("Failed to create issue from TODO", map[string]interface{}{
+			"error":   err.Error(),
+			"chat_id": chatID,
+		})
+		b.editMessage(chatID, statusMessageID, "⚠️ Issue creation failed")
+		return nil
+	}
+
+	b.emitWebhookEvent(chatID, webhook.EventIssueCreated, map[string]interface{}{
+		"title":        todo.Content,
+		"issue_number": issueNumber,
+		"issue_url":    issueURL,
+	})
+	b.assignIssueToSelf(chatID, userGitHubProvider, issueNumber)
+	if b.db != nil {
+		if err := b.db.IncrementIssueCount(chatID); err != nil {
+			logger.Error("Failed to increment issue count", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		}
+		if err := b.db.IncrementUsageIssueCount(chatID); err != nil {
+			logger.Error("Failed to increment usage issue count", map[string]interface{}{
+				"error":   err.Error(),
+				"chat_id": chatID,
+			})
+		}
+		go b.checkQuotaAlerts(chatID, nil)
+	}
+
+	// Link the TODO to the issue and list the issue in issue.md
+	todos[index].IssueNumber = issueNumber
+	todos[index].ChatID = chatID
+	committerInfo := b.getCommitterInfo(chatID)
+	commitMsg := fmt.Sprintf("Link TODO to issue #%d via Telegram", issueNumber)
+	if err := userGitHubProvider.ReplaceFileWithAuthorAndPremium("todo.md", formatTodoFile(todos), commitMsg, committerInfo, premiumLevel); err != nil {
+		logger.Error("Failed to link TODO to issue", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+		})
+	}
+
+	owner, repo, _ := userGitHubProvider.GetRepoInfo()
+	issueLine := formatIssueLine(owner, repo, &github.IssueStatus{Number: issueNumber, Title: todo.Content, State: "open"}) + "\n"
+	if err := userGitHubProvider.CommitFileWithAuthorAndPremium("issue.md", issueLine, fmt.Sprintf("Add issue link: %s to issue.md via Telegram", todo.Content), committerInfo, premiumLevel); err != nil {
+		logger.Error("Failed to add issue to issue.md", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+		})
+	}
+
+	b.editOrSendHTML(chatID, statusMessageID, fmt.Sprintf("%s Created <a href=\"%s\">issue #%d</a> from the TODO and linked them.\n\nClosing the issue checks the TODO off.",
+		consts.EmojiSuccess, issueURL, issueNumber))
+	return nil
+}
+
+// handleIssueToTodo adds an open issue to todo.md as a linked TODO
+func (b *Bot) handleIssueToTodo(callback *tgbotapi.CallbackQuery) error {
+	chatID := callback.Message.Chat.ID
+	issueNumber, err := strconv.Atoi(strings.TrimPrefix(callback.Data, "issue_todo_"))
+	if err != nil {
+		return fmt.Errorf("invalid issue number: %w", err)
+	}
+
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		b.sendResponse(chatID, "❌ GitHub not configured. Please use /repo to settle repo first.")
+		return nil
+	}
+
+	if todoContent, err := userGitHubProvider.ReadFile("todo.md"); err == nil {
+		for _, todo := range b.parseTodoItems(todoContent) {
+			if todo.IssueNumber == issueNumber && (todo.ChatID == chatID || todo.ChatID == 0) {
+				b.sendResponse(chatID, fmt.Sprintf("🔗 Issue #%d already has a TODO", issueNumber))
+				return nil
+			}
+		}
+	}
+
+	title := fmt.Sprintf("Issue #%d", issueNumber)
+	if issueContent, err := userGitHubProvider.ReadFile("issue.md"); err == nil {
+		if status, ok := b.parseIssueStatusesFromContent(issueContent, userGitHubProvider)[issueNumber]; ok && status.Title != "" {
+			title = status.Title
+		}
+	}
+
+	// The status message's ID identifies the new TODO, like the message ID of a captured one
+	statusMessageID := b.sendResponseAndGetMessageID(chatID, fmt.Sprintf("🔄 Adding issue #%d to todo.md...", issueNumber))
+	if statusMessageID == 0 {
+		statusMessageID = int(time.Now().Unix())
+	}
+
+	todo := TodoItem{
+		MessageID:   statusMessageID,
+		ChatID:      chatID,
+		Content:     strings.ReplaceAll(title, "\n", " "),
+		Date:        time.Now().Format("2006-01-02"),
+		IssueNumber: issueNumber,
+	}
+	committerInfo := b.getCommitterInfo(chatID)
+	premiumLevel := b.getPremiumLevel(chatID)
+	commitMsg := fmt.Sprintf("Add TODO for issue #%d via Telegram", issueNumber)
+	if err := userGitHubProvider.CommitFileWithAuthorAndPremium("todo.md", formatTodoLine(todo)+"\n", commitMsg, committerInfo, premiumLevel); err != nil {
+		logger.Error("Failed to add TODO for issue", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+		})
+		b.editMessage(chatID, statusMessageID, "❌ Failed to update TODO")
+		return nil
+	}
+
+	// Link back from the issue to the TODO
+	comment := "Tracked as a TODO in todo.md"
+	if todoURL, err := userGitHubProvider.GetGitHubFileURLWithBranch("todo.md"); err == nil {
+		comment = fmt.Sprintf("Tracked as a TODO in [todo.md](%s)", todoURL)
+	}
+	if _, err := userGitHubProvider.AddIssueComment(issueNumber, comment+fmt.Sprintf("\n\n<!--todo:[%d] [%d]-->", todo.MessageID, chatID)); err != nil {
+		logger.Warn("Failed to link issue back to TODO", map[string]interface{}{
+			"error":        err.Error(),
+			"issue_number": issueNumber,
+		})
+	}
+
+	b.editOrSendHTML(chatID, statusMessageID, fmt.Sprintf("%s Added <b>%s</b> to todo.md, linked to issue #%d.\n\nChecking the TODO off offers to close the issue.",
+		consts.EmojiSuccess, html.EscapeString(todo.Content), issueNumber))
+	return nil
+}
@@ -0,0 +1,63 @@
+package telegram
+
+import (
+	"testing"
+)
+
+func TestTodoLineIssueLink(t *testing.T) {
+	bot := &Bot{}
+	content := "- [ ] <!--[10] [42] [#7]--> Fix the login page (2026-01-02)\n" +
+		"- [x] <!--[11] [42]--> Buy milk (2026-01-03)\n" +
+		"- [ ] [12] Old format (2025-12-31)\n"
+
+	todos := bot.parseTodoItems(content)
+	if len(todos) != 3 {
+		t.Fatalf("parseTodoItems() found %d todos, want 3", len(todos))
+	}
+	if todos[0].IssueNumber != 7 || todos[0].Content != "Fix the login page" || todos[0].Date != "2026-01-02" {
+		t.Errorf("linked todo = %+v", todos[0])
+	}
+	if todos[1].IssueNumber != 0 || !todos[1].Done {
+		t.Errorf("unlinked todo = %+v", todos[1])
+	}
+
+	if formatted := formatTodoFile(todos); formatted != content {
+		t.Errorf("formatTodoFile() = %q, want %q", formatted, content)
+	}
+}
+
+func TestCheckLinkedTodos(t *testing.T) {
+	todos := []TodoItem{
+		{MessageID: 1, ChatID: 42, Content: "Linked", IssueNumber: 7},
+		{MessageID: 2, ChatID: 42, Content: "Other issue", IssueNumber: 8},
+		{MessageID: 3, ChatID: 99, Content: "Other chat", IssueNumber: 7},
+		{MessageID: 4, ChatID: 42, Content: "Not linked"},
+	}
+
+	todos, checked := checkLinkedTodos(todos, 42, []int{7})
+	if checked != 1 || !todos[0].Done || todos[1].Done || todos[2].Done || todos[3].Done {
+		t.Errorf("checkLinkedTodos() checked %d: %+v", checked, todos)
+	}
+
+	if _, checked := checkLinkedTodos(todos, 42, []int{7}); checked != 0 {
+		t.Errorf("checkLinkedTodos() checked %d already done todos", checked)
+	}
+}
+
+func TestFindTodo(t *testing.T) {
+	todos := []TodoItem{
+		{MessageID: 1, ChatID: 99},
+		{MessageID: 1, ChatID: 42},
+		{MessageID: 2},
+	}
+
+	if index := findTodo(todos, 1, 42); index != 1 {
+		t.Errorf("findTodo(1, 42) = %d, want 1", index)
+	}
+	if index := findTodo(todos, 2, 42); index != 2 {
+		t.Errorf("findTodo(2, 42) = %d, want old format item 2", index)
+	}
+	if index := findTodo(todos, 3, 42); index != -1 {
+		t.Errorf("findTodo(3, 42) = %d, want -1", index)
+	}
+}
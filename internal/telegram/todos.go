@@ -0,0 +1,243 @@
+package telegram
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/webhook"
+)
+
+// TODO management from the /todo list: every item is a button that checks it off or reopens it,
+// the reorder view moves open items up and down. Each change rewrites todo.md from the parsed
+// items and commits it, so the file stays the source of truth.
+
+const (
+	todoViewOpen = "open" // Open items of the chat
+	todoViewAll  = "all"  // Open and done items of the chat
+	todoViewSort = "sort" // Open items of the chat with move buttons
+
+	todoItemsPerPage   = 5
+	todoButtonLabelMax = 40
+)
+
+// todoViewCallback returns the callback data showing the view at offset
+func todoViewCallback(view string, offset int) string {
+	return fmt.Sprintf("todo_view_%s_%d", view, offset)
+}
+
+// todosInView returns the TODOs of chatID shown in the view, in file order
+func todosInView(todos []TodoItem, chatID int64, view string) []TodoItem {
+	var shown []TodoItem
+	for _, todo := range todos {
+		if todo.ChatID != chatID && todo.ChatID != 0 {
+			continue
+		}
+		if todo.Done && view != todoViewAll {
+			continue
+		}
+		shown = append(shown, todo)
+	}
+	return shown
+}
+
+// todoButtonLabel returns the label of the button toggling the TODO numbered n
+func todoButtonLabel(n int, todo TodoItem) string {
+	box := "⬜"
+	if todo.Done {
+		box = "✅"
+	}
+	content := strings.Join(strings.Fields(todo.Content), " ")
+	if truncated, cut := truncateForPreview(content, todoButtonLabelMax); cut {
+		content = truncated + "…"
+	}
+	return fmt.Sprintf("%s %d. %s", box, n, content)
+}
+
+// moveTodo swaps the open TODO with messageID of chatID with the previous (up) or next open TODO
+// of the chat, returning its new position among them or -1 if it can't move
+func moveTodo(todos []TodoItem, messageID int, chatID int64, up bool) int {
+	var positions []int
+	current := -1
+	for i, todo := range todos {
+		if todo.Done || (todo.ChatID != chatID && todo.ChatID != 0) {
+			continue
+		}
+		if todo.MessageID == messageID {
+			current = len(positions)
+		}
+		positions = append(positions, i)
+	}
+	if current < 0 {
+		return -1
+	}
+
+	target := current + 1
+	if up {
+		target = current - 1
+	}
+	if target < 0 || target >= len(positions) {
+		return -1
+	}
+
+	a, b := positions[current], positions[target]
+	todos[a], todos[b] = todos[b], todos[a]
+	return target
+}
+
+// updateTodos reads todo.md, applies change and commits the result with the commit message it
+// returns. An empty commit message leaves the file alone.
+func (b *Bot) updateTodos(chatID int64, change func([]TodoItem) ([]TodoItem, string)) (github.GitHubProvider, error) {
+	userGitHubProvider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := userGitHubProvider.ReadFile("todo.md")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read todo.md: %w", err)
+	}
+
+	todos, commitMsg := change(b.parseTodoItems(content))
+	if commitMsg == "" {
+		return userGitHubProvider, nil
+	}
+
+	if err := userGitHubProvider.ReplaceFileWithAuthorAndPremium("todo.md", formatTodoFile(todos), commitMsg, b.getCommitterInfo(chatID), b.getPremiumLevel(chatID)); err != nil {
+		return nil, err
+	}
+	return userGitHubProvider, nil
+}
+
+// todoCompleted reports a TODO that was just checked off
+func (b *Bot) todoCompleted(chatID int64, githubProvider github.GitHubProvider, todo TodoItem) {
+	b.emitWebhookEvent(chatID, webhook.EventTodoCompleted, map[string]interface{}{
+		"message_id": todo.MessageID,
+		"content":    todo.Content,
+	})
+	b.recordTodoCompleted(chatID)
+
+	// Offer to close the linked issue too (implemented in todo_issue_links.go)
+	if todo.IssueNumber > 0 {
+		b.confirmCloseLinkedIssue(chatID, githubProvider, todo.IssueNumber)
+	}
+}
+
+// todoUpdateFailed shows why a change of todo.md failed
+func (b *Bot) todoUpdateFailed(callback *tgbotapi.CallbackQuery, err error) error {
+	logger.Error("Failed to update todo.md", map[string]interface{}{
+		"chat_id": callback.Message.Chat.ID,
+		"error":   err.Error(),
+	})
+
+	errorMsg := "❌ Failed to update TODO"
+	if strings.Contains(err.Error(), "GitHub authorization failed") {
+		errorMsg = "❌ " + err.Error()
+	}
+	b.editMessage(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
+	return err
+}
+
+// handleTodoToggle checks off or reopens a TODO: todo_tgl_<message_id>_<view>_<offset>
+func (b *Bot) handleTodoToggle(callback *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callback.Data, "_")
+	if len(parts) != 5 {
+		return fmt.Errorf("invalid callback data format")
+	}
+	messageID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid message ID: %w", err)
+	}
+	view := parts[3]
+	offset, err := strconv.Atoi(parts[4])
+	if err != nil {
+		return fmt.Errorf("invalid offset: %w", err)
+	}
+
+	chatID := callback.Message.Chat.ID
+	var toggled TodoItem
+	found := false
+	provider, err := b.updateTodos(chatID, func(todos []TodoItem) ([]TodoItem, string) {
+		i := findTodo(todos, messageID, chatID)
+		if i < 0 {
+			return todos, ""
+		}
+		todos[i].Done = !todos[i].Done
+		todos[i].ChatID = chatID
+		toggled, found = todos[i], true
+		if toggled.Done {
+			return todos, fmt.Sprintf("Mark TODO #%d as completed via Telegram", messageID)
+		}
+		return todos, fmt.Sprintf("Reopen TODO #%d via Telegram", messageID)
+	})
+	if err != nil {
+		return b.todoUpdateFailed(callback, err)
+	}
+
+	if found {
+		logger.Info("Toggled TODO", map[string]interface{}{
+			"message_id": messageID,
+			"chat_id":    chatID,
+			"done":       toggled.Done,
+		})
+		if toggled.Done {
+			b.todoCompleted(chatID, provider, toggled)
+		}
+	}
+
+	// Refresh the list, a stale button of a deleted item just shows the current state
+	return b.showTodos(chatID, callback.Message.MessageID, view, offset)
+}
+
+// handleTodoMove moves an open TODO: todo_up_<message_id>_<offset> or todo_down_<message_id>_<offset>
+func (b *Bot) handleTodoMove(callback *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callback.Data, "_")
+	if len(parts) != 4 {
+		return fmt.Errorf("invalid callback data format")
+	}
+	up := parts[1] == "up"
+	messageID, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return fmt.Errorf("invalid message ID: %w", err)
+	}
+	offset, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return fmt.Errorf("invalid offset: %w", err)
+	}
+
+	chatID := callback.Message.Chat.ID
+	_, err = b.updateTodos(chatID, func(todos []TodoItem) ([]TodoItem, string) {
+		position := moveTodo(todos, messageID, chatID, up)
+		if position < 0 {
+			return todos, ""
+		}
+		// Keep the moved item on screen
+		offset = position / todoItemsPerPage * todoItemsPerPage
+		if up {
+			return todos, fmt.Sprintf("Move TODO #%d up via Telegram", messageID)
+		}
+		return todos, fmt.Sprintf("Move TODO #%d down via Telegram", messageID)
+	})
+	if err != nil {
+		return b.todoUpdateFailed(callback, err)
+	}
+
+	return b.showTodos(chatID, callback.Message.MessageID, todoViewSort, offset)
+}
+
+// handleTodoView shows another view or page of the list: todo_view_<view>_<offset>
+func (b *Bot) handleTodoView(callback *tgbotapi.CallbackQuery) error {
+	parts := strings.Split(callback.Data, "_")
+	if len(parts) != 4 {
+		return fmt.Errorf("invalid callback data format")
+	}
+	offset, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return fmt.Errorf("invalid offset: %w", err)
+	}
+
+	return b.showTodos(callback.Message.Chat.ID, callback.Message.MessageID, parts[2], offset)
+}
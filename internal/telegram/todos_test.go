@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"testing"
+)
+
+func TestMoveTodo(t *testing.T) {
+	newTodos := func() []TodoItem {
+		return []TodoItem{
+			{MessageID: 1, ChatID: 42, Content: "First"},
+			{MessageID: 2, ChatID: 99, Content: "Other chat"},
+			{MessageID: 3, ChatID: 42, Content: "Done", Done: true},
+			{MessageID: 4, ChatID: 42, Content: "Second"},
+			{MessageID: 5, ChatID: 0, Content: "Old format"},
+		}
+	}
+	order := func(todos []TodoItem) []int {
+		var ids []int
+		for _, todo := range todos {
+			ids = append(ids, todo.MessageID)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name      string
+		messageID int
+		up        bool
+		want      int
+		wantOrder []int
+	}{
+		{"down skips other chats and done items", 1, false, 1, []int{4, 2, 3, 1, 5}},
+		{"up", 5, true, 1, []int{1, 2, 3, 5, 4}},
+		{"first can't move up", 1, true, -1, []int{1, 2, 3, 4, 5}},
+		{"last can't move down", 5, false, -1, []int{1, 2, 3, 4, 5}},
+		{"done items don't move", 3, true, -1, []int{1, 2, 3, 4, 5}},
+		{"other chat's items don't move", 2, false, -1, []int{1, 2, 3, 4, 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos := newTodos()
+			if got := moveTodo(todos, tt.messageID, 42, tt.up); got != tt.want {
+				t.Errorf("moveTodo() = %d, want %d", got, tt.want)
+			}
+			got := order(todos)
+			for i := range got {
+				if got[i] != tt.wantOrder[i] {
+					t.Fatalf("order = %v, want %v", got, tt.wantOrder)
+				}
+			}
+		})
+	}
+}
+
+func TestTodosInView(t *testing.T) {
+	todos := []TodoItem{
+		{MessageID: 1, ChatID: 42, Content: "Open"},
+		{MessageID: 2, ChatID: 42, Content: "Done", Done: true},
+		{MessageID: 3, ChatID: 99, Content: "Other chat"},
+	}
+
+	if shown := todosInView(todos, 42, todoViewOpen); len(shown) != 1 || shown[0].MessageID != 1 {
+		t.Errorf("open view = %+v", shown)
+	}
+	if shown := todosInView(todos, 42, todoViewSort); len(shown) != 1 {
+		t.Errorf("sort view = %+v", shown)
+	}
+	if shown := todosInView(todos, 42, todoViewAll); len(shown) != 2 || !shown[1].Done {
+		t.Errorf("all view = %+v", shown)
+	}
+}
+
+func TestTodoButtonLabel(t *testing.T) {
+	if got := todoButtonLabel(3, TodoItem{Content: "Buy\nmilk"}); got != "⬜ 3. Buy milk" {
+		t.Errorf("todoButtonLabel() = %q", got)
+	}
+	if got := todoButtonLabel(1, TodoItem{Content: "Done", Done: true}); got != "✅ 1. Done" {
+		t.Errorf("todoButtonLabel() = %q", got)
+	}
+
+	long := todoButtonLabel(1, TodoItem{Content: "Review the pull request documentation and update the README"})
+	if want := "⬜ 1. Review the pull request documentation an…"; long != want {
+		t.Errorf("todoButtonLabel() = %q, want %q", long, want)
+	}
+}
@@ -13,8 +13,10 @@ import (
 	"unicode/utf16"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/config"
 	"github.com/msg2git/msg2git/internal/consts"
 	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/entry"
 	"github.com/msg2git/msg2git/internal/github"
 	"github.com/msg2git/msg2git/internal/llm"
 	"github.com/msg2git/msg2git/internal/logger"
@@ -22,11 +24,12 @@ import (
 
 // TodoItem represents a parsed TODO item
 type TodoItem struct {
-	MessageID int
-	ChatID    int64
-	Content   string
-	Date      string
-	Done      bool
+	MessageID   int
+	ChatID      int64
+	Content     string
+	Date        string
+	Done        bool
+	IssueNumber int // Linked GitHub issue, 0 if none
 }
 
 // Message formatting utilities
@@ -48,43 +51,11 @@ func formatTokenCount(count int64) string {
 }
 
 func (b *Bot) generateTitleFromContent(content string) string {
-	// Get the first line of content
-	lines := strings.Split(strings.TrimSpace(content), "\n")
-	if len(lines) == 0 || lines[0] == "" {
-		return "untitled"
-	}
-
-	firstLine := strings.TrimSpace(lines[0])
-	if firstLine == "" {
-		return "untitled"
-	}
-
-	// Limit to 50 characters
-	if len(firstLine) > 50 {
-		// Try to cut at a word boundary near 50 chars
-		if cutIndex := strings.LastIndex(firstLine[:50], " "); cutIndex > 20 {
-			return firstLine[:cutIndex] + "..."
-		}
-		// If no good word boundary, just cut at 47 chars and add "..."
-		return firstLine[:47] + "..."
-	}
-
-	return firstLine
+	return entry.Title(content)
 }
 
 func (b *Bot) addMarkdownLineBreaks(content string) string {
-	// Split content into lines
-	lines := strings.Split(content, "\n")
-
-	// Add two spaces at the end of each line (except the last one if it's empty)
-	for i, line := range lines {
-		// Don't add spaces to empty lines or the last line if it's empty
-		if line != "" || (i < len(lines)-1) {
-			lines[i] = strings.TrimRight(line, " ") + "  "
-		}
-	}
-
-	return strings.Join(lines, "\n")
+	return entry.MarkdownLineBreaks(content)
 }
 
 func (b *Bot) parseTitleAndTags(llmResponse, content string) (title, tags string) {
@@ -108,54 +79,58 @@ func (b *Bot) parseTitleAndTags(llmResponse, content string) (title, tags string
 }
 
 func (b *Bot) formatMessageContentWithTitleAndTags(content, filename string, messageID int, chatID int64, title, tags string) string {
-	timestamp := time.Now().Format("2006-01-02 15:04")
+	return b.formatNoteContent(content, filename, messageID, chatID, title, tags, true)
+}
 
-	// Clean up tags and ensure proper format
-	cleanTags := ""
-	if tags != "" {
-		cleanTags = strings.TrimSpace(tags)
+// formatNoteContent formats a note of chatID, with the source footer if sourceFooter and the chat
+// turned it on
+func (b *Bot) formatNoteContent(content, filename string, messageID int, chatID int64, title, tags string, sourceFooter bool) string {
+	b.rememberNoteTags(chatID, filename, tags)
+	// Encrypted notes skip the mood, which would be readable in the comment (implemented in note_encryption.go)
+	key, encrypted := b.userNoteKey(chatID)
+	var mood []string
+	if !encrypted {
+		mood = b.noteMoodMeta(chatID, content)
 	}
-
-	// Improve markdown formatting by adding two spaces at the end of each line
-	// This creates proper line breaks in markdown rendering
-	formattedContent := b.addMarkdownLineBreaks(content)
-
-	// New format: HTML comment for metadata, title on separate line, tags on line below title
-	var result strings.Builder
-
-	// HTML comment with metadata
-	result.WriteString("<!--\n")
-	result.WriteString(fmt.Sprintf("[%d] [%d] [%s] \n", messageID, chatID, timestamp))
-	result.WriteString("-->\n\n")
-
-	// Title
-	result.WriteString(fmt.Sprintf("## %s\n", title))
-
-	// Tags on separate line (if any)
-	if cleanTags != "" {
-		result.WriteString(fmt.Sprintf("%s\n", cleanTags))
+	content = b.resolveNoteLinks(chatID, content, filename)
+	if sourceFooter {
+		content = b.withSourceFooter(chatID, messageID, content)
 	}
-	result.WriteString("\n")
-
-	// Content
-	result.WriteString(formattedContent)
-	result.WriteString("\n\n---\n\n")
-
-	return result.String()
+	if encrypted {
+		return sealNote(key, content, messageID, chatID, title, tags, time.Now())
+	}
+	return entry.Note(content, messageID, chatID, title, tags, time.Now(), mood...)
 }
 
 func (b *Bot) formatTodoContent(content string, messageID int, chatID int64) string {
-	timestamp := time.Now().Format("2006-01-02")
-
-	// New TODO format: - [ ] <!--[msg_id] [chat_id]--> message (timestamp)
-	// Check if content contains line breaks
+	// TODO format: - [ ] <!--[msg_id] [chat_id]--> message (timestamp)
 	if strings.Contains(content, "\n") {
 		// If content has line breaks, it cannot be saved to TODO.md
 		logger.Debug("Content contains line breaks, cannot save to TODO.md", nil)
 		return ""
 	}
 
-	return fmt.Sprintf("- [ ] <!--[%d] [%d]--> %s (%s)\n", messageID, chatID, content, timestamp)
+	return entry.Todo(content, messageID, chatID, time.Now())
+}
+
+// formatTodoLine formats a parsed TODO item back into its todo.md line, keeping the old
+// bracket format for items without a chat ID
+func formatTodoLine(todo TodoItem) string {
+	checkbox := "[ ]"
+	if todo.Done {
+		checkbox = "[x]"
+	}
+
+	if todo.ChatID == 0 {
+		// Old format without chat ID - keep as is for backward compatibility
+		return fmt.Sprintf("- %s [%d] %s (%s)", checkbox, todo.MessageID, todo.Content, todo.Date)
+	}
+
+	issueRef := ""
+	if todo.IssueNumber > 0 {
+		issueRef = fmt.Sprintf(" [#%d]", todo.IssueNumber)
+	}
+	return fmt.Sprintf("- %s <!--[%d] [%d]%s--> %s (%s)", checkbox, todo.MessageID, todo.ChatID, issueRef, todo.Content, todo.Date)
 }
 
 // Parsing utilities
@@ -179,18 +154,20 @@ func (b *Bot) parseTodoItems(content string) []TodoItem {
 			continue
 		}
 
-		// Try new HTML comment format first: - [ ] <!--[msg_id] [chat_id]--> message (date)
-		htmlCommentRe := regexp.MustCompile(`^- \[[ x]\] <!--\[(\d+)\] \[(\d+)\]--> (.+) \(([^)]+)\)$`)
+		// Try new HTML comment format first: - [ ] <!--[msg_id] [chat_id] [#issue]--> message (date)
+		htmlCommentRe := regexp.MustCompile(`^- \[[ x]\] <!--\[(\d+)\] \[(\d+)\](?: \[#(\d+)\])?--> (.+) \(([^)]+)\)$`)
 		matches := htmlCommentRe.FindStringSubmatch(line)
-		if len(matches) == 5 {
+		if len(matches) == 6 {
 			if msgID, err := strconv.Atoi(matches[1]); err == nil {
 				if chatID, err := strconv.ParseInt(matches[2], 10, 64); err == nil {
+					issueNumber, _ := strconv.Atoi(matches[3])
 					todos = append(todos, TodoItem{
-						MessageID: msgID,
-						ChatID:    chatID,
-						Content:   matches[3],
-						Date:      matches[4],
-						Done:      done,
+						MessageID:   msgID,
+						ChatID:      chatID,
+						Content:     matches[4],
+						Date:        matches[5],
+						Done:        done,
+						IssueNumber: issueNumber,
 					})
 					continue
 				}
@@ -341,6 +318,9 @@ func (b *Bot) parseIssueNumbers(content string) []int {
 	return numbers
 }
 
+// issueLinePattern matches issue.md lines: - 🟢 owner/repo#123 [title] or - 🔴 owner/repo#456 [title] (closed 2024-01-31)
+var issueLinePattern = regexp.MustCompile(`^- ([🟢🔴]) [^/\s]+/[^/\s]+#(\d+) \[([^\]]*)\](?: \(closed (\d{4}-\d{2}-\d{2})\))?`)
+
 // parseIssueStatusesFromContent parses issue numbers and their states directly from issue.md content
 func (b *Bot) parseIssueStatusesFromContent(content string, githubProvider github.GitHubProvider) map[int]*github.IssueStatus {
 	statuses := make(map[int]*github.IssueStatus)
@@ -353,7 +333,7 @@ func (b *Bot) parseIssueStatusesFromContent(content string, githubProvider githu
 		return statuses
 	}
 
-	// Parse lines that look like: - 🟢 owner/repo#123 [title] or - 🔴 owner/repo#456 [title]
+	// Parse lines that look like: - 🟢 owner/repo#123 [title] or - 🔴 owner/repo#456 [title] (closed 2024-01-31)
 	lines := strings.Split(content, "\n")
 
 	for _, line := range lines {
@@ -363,11 +343,9 @@ func (b *Bot) parseIssueStatusesFromContent(content string, githubProvider githu
 		}
 
 		// Extract emoji, issue number, and title
-		// Pattern: - 🟢 owner/repo#123 [title]
-		re := regexp.MustCompile(`^- ([🟢🔴]) [^/\s]+/[^/\s]+#(\d+) \[([^\]]*)\]`)
-		matches := re.FindStringSubmatch(line)
+		matches := issueLinePattern.FindStringSubmatch(line)
 
-		if len(matches) == 4 {
+		if len(matches) == 5 {
 			emoji := matches[1]
 			numberStr := matches[2]
 			title := matches[3]
@@ -387,6 +365,9 @@ func (b *Bot) parseIssueStatusesFromContent(content string, githubProvider githu
 					State:   state,
 					HTMLURL: url,
 				}
+				if closedAt, err := time.Parse(issueClosedDateLayout, matches[4]); err == nil && state == "closed" {
+					statuses[number].ClosedAt = &closedAt
+				}
 			}
 		}
 	}
@@ -523,15 +504,43 @@ func (b *Bot) showFileSelectionButtons(message *tgbotapi.Message) error {
 	// Convert Telegram message to markdown format
 	markdownContent := b.telegramToMarkdown(message.Text, message.Entities)
 
+	// A leading "!" routes the entry to the user's private repository, if one is configured
+	isPrivate := false
+	if content, ok := parsePrivatePrefix(markdownContent); ok && b.hasPrivateRepo(message.Chat.ID) {
+		markdownContent = content
+		isPrivate = true
+	}
+
 	// Store the formatted message content AND original message ID for later use
 	messageKey := fmt.Sprintf("%d_%d", message.Chat.ID, message.MessageID)
-	messageData := fmt.Sprintf("%s|||DELIM|||%d", markdownContent, message.MessageID)
-	b.pendingMessages[messageKey] = messageData
+	messageData := pendingEntryData(markdownContent, message.MessageID, isPrivate)
+	b.pendingMessages.Set(messageKey, messageData)
 
-	// Get user's pinned custom files (first 2 items in custom_files array)
+	keyboard := b.fileSelectionKeyboard(message.Chat.ID, messageKey, isPrivate, strings.Contains(messageData, "\n"))
+
+	msg := tgbotapi.NewMessage(message.Chat.ID, fileSelectionPrompt(isPrivate))
+	msg.ReplyMarkup = keyboard
+
+	if _, err := b.rateLimitedSend(message.Chat.ID, msg); err != nil {
+		return fmt.Errorf("failed to send file selection message: %w", err)
+	}
+
+	return nil
+}
+
+// fileSelectionPrompt returns the text above the file selection keyboard
+func fileSelectionPrompt(isPrivate bool) string {
+	if isPrivate {
+		return "🔒 Private entry, please choose a location in your private repository:"
+	}
+	return "Please choose a location:"
+}
+
+// pinnedFiles returns the user's pinned custom files (first 2 items in custom_files array)
+func (b *Bot) pinnedFiles(chatID int64) []string {
 	var pinnedFiles []string
 	if b.db != nil {
-		user, err := b.db.GetUserByChatID(message.Chat.ID)
+		user, err := b.db.GetUserByChatID(chatID)
 		if err == nil && user != nil {
 			customFiles := user.GetCustomFiles()
 			// Take up to 2 pinned files (first 2 items in the array)
@@ -544,13 +553,31 @@ func (b *Bot) showFileSelectionButtons(message *tgbotapi.Message) error {
 			}
 		}
 	}
+	return pinnedFiles
+}
+
+// pinnedFileLabel returns the display name of a pinned file (remove .md extension and truncate if needed)
+func pinnedFileLabel(filePath string) string {
+	displayName := strings.TrimSuffix(filePath, ".md")
+	if len(displayName) > 15 {
+		displayName = displayName[:12] + "..."
+	}
+	return "📌 " + displayName
+}
+
+// fileSelectionKeyboard builds the file selection keyboard of a pending message
+func (b *Bot) fileSelectionKeyboard(chatID int64, messageKey string, isPrivate, multiline bool) tgbotapi.InlineKeyboardMarkup {
+	pinnedFiles := b.pinnedFiles(chatID)
 
 	// Create inline keyboard with file options
 	row1 := tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("📝 NOTE", fmt.Sprintf("file_NOTE_%s", messageKey)),
-		tgbotapi.NewInlineKeyboardButtonData("❓ ISSUE", fmt.Sprintf("file_ISSUE_%s", messageKey)),
 	)
-	if !strings.Contains(messageData, "\n") {
+	// Issues are created in the main repository, so they are not offered for private entries
+	if !isPrivate {
+		row1 = append(row1, tgbotapi.NewInlineKeyboardButtonData("❓ ISSUE", fmt.Sprintf("file_ISSUE_%s", messageKey)))
+	}
+	if !multiline {
 		row1 = append(row1, tgbotapi.NewInlineKeyboardButtonData("✅ TODO", fmt.Sprintf("file_TODO_%s", messageKey)))
 	}
 	row2 := tgbotapi.NewInlineKeyboardRow(
@@ -564,13 +591,8 @@ func (b *Bot) showFileSelectionButtons(message *tgbotapi.Message) error {
 	if len(pinnedFiles) > 0 {
 		pinnedRow := []tgbotapi.InlineKeyboardButton{}
 		for i, filePath := range pinnedFiles {
-			// Get display name (remove .md extension and truncate if needed)
-			displayName := strings.TrimSuffix(filePath, ".md")
-			if len(displayName) > 15 {
-				displayName = displayName[:12] + "..."
-			}
 			pinnedRow = append(pinnedRow, tgbotapi.NewInlineKeyboardButtonData(
-				fmt.Sprintf("📌 %s", displayName),
+				pinnedFileLabel(filePath),
 				fmt.Sprintf("file_PINNED_%d_%s", i, messageKey),
 			))
 		}
@@ -578,49 +600,47 @@ func (b *Bot) showFileSelectionButtons(message *tgbotapi.Message) error {
 	}
 	rows = append(rows, row1, row2)
 
-	// Final row with CUSTOM and CANCEL
+	// Final row with CUSTOM, MULTI (implemented in multi_select.go) and CANCEL
 	row3 := tgbotapi.NewInlineKeyboardRow(
 		tgbotapi.NewInlineKeyboardButtonData("📁 CUSTOM", fmt.Sprintf("file_CUSTOM_%s", messageKey)),
+		tgbotapi.NewInlineKeyboardButtonData("☑️ MULTI", fmt.Sprintf("file_MULTI_%s", messageKey)),
 		tgbotapi.NewInlineKeyboardButtonData("❌ CANCEL", fmt.Sprintf("cancel_%s", messageKey)),
 	)
 	rows = append(rows, row3)
 
-	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
-
-	msg := tgbotapi.NewMessage(message.Chat.ID, "Please choose a location:")
-	msg.ReplyMarkup = keyboard
-
-	if _, err := b.rateLimitedSend(message.Chat.ID, msg); err != nil {
-		return fmt.Errorf("failed to send file selection message: %w", err)
-	}
-
-	return nil
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
 // Configuration update methods
 
-func (b *Bot) validateGitHubToken(token string) error {
+// validateGitHubToken checks the token with a test API call and returns the account it belongs to
+func (b *Bot) validateGitHubToken(token, apiURL string) (*GitHubUser, error) {
 	// Make a test API call to validate the token
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	req, err := http.NewRequest("GET", github.ResolveAPIBaseURL(apiURL)+"/user", nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "token "+token)
 	req.Header.Set("Accept", "application/vnd.github.v3+json")
 
-	client := &http.Client{}
+	client := github.NewHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to make API call: %w", err)
+		return nil, fmt.Errorf("failed to make API call: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
 	}
 
-	return nil
+	var githubUser GitHubUser
+	if err := json.NewDecoder(resp.Body).Decode(&githubUser); err != nil {
+		return nil, fmt.Errorf("failed to parse user response: %w", err)
+	}
+
+	return &githubUser, nil
 }
 
 func (b *Bot) updateGitHubRepo(repoURL, username string, chatID int64) error {
@@ -633,14 +653,18 @@ func (b *Bot) updateGitHubRepo(repoURL, username string, chatID int64) error {
 	}
 
 	// Update the config
-	b.config.GitHubRepo = repoURL
-	b.config.GitHubUsername = username
+	b.updateConfig(func(current *config.Config) (*config.Config, error) {
+		next := *current
+		next.GitHubRepo = repoURL
+		next.GitHubUsername = username
+		return &next, nil
+	})
 
 	// Get premium level for the user
 	premiumLevel := b.getPremiumLevel(chatID)
 
 	// Create new GitHub manager with updated config
-	githubManager, err := github.NewManager(b.config, premiumLevel)
+	githubManager, err := github.NewManager(b.cfg(), premiumLevel)
 	if err != nil {
 		return fmt.Errorf("failed to create new GitHub manager: %w", err)
 	}
@@ -651,10 +675,14 @@ func (b *Bot) updateGitHubRepo(repoURL, username string, chatID int64) error {
 
 func (b *Bot) updateGitHubToken(token string, chatID int64) error {
 	// Update the config
-	b.config.GitHubToken = token
+	b.updateConfig(func(current *config.Config) (*config.Config, error) {
+		next := *current
+		next.GitHubToken = token
+		return &next, nil
+	})
 
 	// Clean up old repository directories (in case repo URL was also changed)
-	if err := github.CleanupOldRepositories(b.config.GitHubRepo); err != nil {
+	if err := github.CleanupOldRepositories(b.cfg().GitHubRepo); err != nil {
 		logger.Warn("Failed to cleanup old repositories", map[string]interface{}{
 			"error": err.Error(),
 		})
@@ -665,7 +693,7 @@ func (b *Bot) updateGitHubToken(token string, chatID int64) error {
 	premiumLevel := b.getPremiumLevel(chatID)
 
 	// Create new GitHub manager with updated config
-	githubManager, err := github.NewManager(b.config, premiumLevel)
+	githubManager, err := github.NewManager(b.cfg(), premiumLevel)
 	if err != nil {
 		return fmt.Errorf("failed to create new GitHub manager: %w", err)
 	}
@@ -783,25 +811,29 @@ func (b *Bot) validateGeminiToken(token, model string) error {
 
 func (b *Bot) updateLLMConfig(provider, endpoint, token, model string) error {
 	// Update the config
-	b.config.LLMProvider = provider
-	b.config.LLMEndpoint = endpoint
-	b.config.LLMToken = token
-	b.config.LLMModel = model
+	b.updateConfig(func(current *config.Config) (*config.Config, error) {
+		next := *current
+		next.LLMProvider = provider
+		next.LLMEndpoint = endpoint
+		next.LLMToken = token
+		next.LLMModel = model
+		return &next, nil
+	})
 
 	// Create new LLM client with updated config
-	b.llmClient = llm.NewClient(b.config)
+	b.llmClient = llm.NewClient(b.cfg())
 	return nil
 }
 
 // Helper method to check if GitHub configuration is valid
 func (b *Bot) validateGitHubConfig() error {
-	if b.config.GitHubToken == "" {
+	if b.cfg().GitHubToken == "" {
 		return fmt.Errorf("GitHub token is not set. Use /repo to configure it")
 	}
-	if b.config.GitHubRepo == "" {
+	if b.cfg().GitHubRepo == "" {
 		return fmt.Errorf("GitHub repository is not set. Use /repo to configure it")
 	}
-	if b.config.GitHubUsername == "" {
+	if b.cfg().GitHubUsername == "" {
 		return fmt.Errorf("GitHub username is not set. Use /repo to configure it")
 	}
 	// Note: We don't validate repository existence here since we use lazy initialization
@@ -1232,12 +1264,12 @@ func (b *Bot) processCustomFileSelection(callback *tgbotapi.CallbackQuery, messa
 	filename := customFiles[fileIndex]
 
 	// Retrieve the original message content
-	messageData, exists := b.pendingMessages[messageKey]
+	messageData, exists := b.pendingMessage(messageKey)
 	if !exists {
 		logger.Error("Original message not found in pending messages", map[string]interface{}{
 			"message_key":  messageKey,
 			"chat_id":      callback.Message.Chat.ID,
-			"pending_keys": len(b.pendingMessages),
+			"pending_keys": b.pendingMessages.Size(),
 		})
 		return fmt.Errorf("original message not found")
 	}
@@ -1251,6 +1283,7 @@ func (b *Bot) processCustomFileSelection(callback *tgbotapi.CallbackQuery, messa
 	var content string
 	var originalMessageID int
 	var photoURL string
+	var private bool
 
 	if isPhoto {
 		// Parse photo data (content|||DELIM|||messageID|||DELIM|||photoURL)
@@ -1271,26 +1304,16 @@ func (b *Bot) processCustomFileSelection(callback *tgbotapi.CallbackQuery, messa
 			originalMessageID = 0
 		}
 	} else {
-		// Parse regular message data (content|||DELIM|||messageID)
-		dataParts := strings.SplitN(messageData, "|||DELIM|||", 2)
-		if len(dataParts) != 2 {
-			return fmt.Errorf("invalid message data format")
-		}
-		content = dataParts[0]
-		originalMessageIDStr := dataParts[1]
-
+		// Parse regular message data (content|||DELIM|||messageID|||DELIM|||repository)
 		var err error
-		originalMessageID, err = strconv.Atoi(originalMessageIDStr)
+		content, originalMessageID, private, err = parsePendingEntry(messageData)
 		if err != nil {
-			logger.Warn("Failed to parse message ID, using 0", map[string]interface{}{
-				"error": err.Error(),
-			})
-			originalMessageID = 0
+			return err
 		}
 	}
 
 	// Clean up pending message
-	delete(b.pendingMessages, messageKey)
+	b.pendingMessages.Delete(messageKey)
 
 	logger.Info("About to save message to custom file", map[string]interface{}{
 		"filename":            filename,
@@ -1302,7 +1325,7 @@ func (b *Bot) processCustomFileSelection(callback *tgbotapi.CallbackQuery, messa
 	})
 
 	// Process the file save similar to regular file handling
-	err = b.saveMessageToCustomFile(callback, filename, content, originalMessageID, photoURL, isPhoto)
+	err = b.saveMessageToCustomFile(callback, filename, content, originalMessageID, photoURL, isPhoto, private)
 	if err != nil {
 		logger.Error("Failed to save message to custom file", map[string]interface{}{
 			"error":               err.Error(),
@@ -1352,13 +1375,13 @@ Reply to this message with a file path for your new custom file.
 	// Store state for reply handling
 	stateKey := fmt.Sprintf("add_custom_%d", callback.Message.Chat.ID)
 	stateData := fmt.Sprintf("%s|||DELIM|||%t", messageKey, isPhoto)
-	b.pendingMessages[stateKey] = stateData
+	b.pendingMessages.Set(stateKey, stateData)
 
 	return nil
 }
 
 // saveMessageToCustomFile saves a message to a custom file
-func (b *Bot) saveMessageToCustomFile(callback *tgbotapi.CallbackQuery, filename, content string, originalMessageID int, photoURL string, isPhoto bool) error {
+func (b *Bot) saveMessageToCustomFile(callback *tgbotapi.CallbackQuery, filename, content string, originalMessageID int, photoURL string, isPhoto, private bool) error {
 	// Ensure user exists in database if database is configured
 	_, err := b.ensureUser(callback.Message)
 	if err != nil {
@@ -1373,8 +1396,8 @@ func (b *Bot) saveMessageToCustomFile(callback *tgbotapi.CallbackQuery, filename
 		return nil
 	}
 
-	// Get user-specific GitHub manager
-	userGitHubProvider, err := b.getUserGitHubProvider(callback.Message.Chat.ID)
+	// Get user-specific GitHub manager, private entries go to the private repository
+	userGitHubProvider, err := b.getEntryGitHubProvider(callback.Message.Chat.ID, private)
 	if err != nil {
 		errorMsg := "❌ " + err.Error()
 		if b.db != nil {
@@ -1492,7 +1515,7 @@ func (b *Bot) saveMessageToCustomFile(callback *tgbotapi.CallbackQuery, filename
 	// Save to GitHub
 	b.updateProgressMessage(callback.Message.Chat.ID, callback.Message.MessageID, 75, "📤 Saving to GitHub...")
 
-	commitMsg := fmt.Sprintf("Add %s to %s via Telegram", title, filename)
+	commitMsg := fmt.Sprintf("Add %s to %s via Telegram", b.commitTitle(callback.Message.Chat.ID, title), filename)
 	committerInfo := b.getCommitterInfo(callback.Message.Chat.ID)
 
 	logger.Info("Committing content to custom file", map[string]interface{}{
@@ -1503,7 +1526,11 @@ func (b *Bot) saveMessageToCustomFile(callback *tgbotapi.CallbackQuery, filename
 		"chat_id":     callback.Message.Chat.ID,
 	})
 
-	if err := userGitHubProvider.CommitFileWithAuthorAndPremium(filename, formattedContent, commitMsg, committerInfo, premiumLevel); err != nil {
+	// A new file starts with its template (implemented in file_templates.go)
+	formattedContent = b.applyFileTemplate(callback.Message.Chat.ID, userGitHubProvider, filename, formattedContent)
+	commitResult, err := userGitHubProvider.CommitFileWithResult(filename, formattedContent, commitMsg, committerInfo, premiumLevel)
+	if err != nil {
+		b.recordRepoFailure(callback.Message.Chat.ID, err)
 		if strings.Contains(err.Error(), "GitHub authorization failed") {
 			errorMsg := "❌ " + err.Error()
 			editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
@@ -1514,7 +1541,7 @@ func (b *Bot) saveMessageToCustomFile(callback *tgbotapi.CallbackQuery, filename
 				b.sendResponse(callback.Message.Chat.ID, errorMsg)
 			}
 		} else {
-			errorMsg := fmt.Sprintf("❌ Failed to save to %s: %v", filename, err)
+			errorMsg := saveFailureText("Failed to save to "+filename, err)
 			editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, errorMsg)
 			if _, sendErr := b.rateLimitedSend(callback.Message.Chat.ID, editMsg); sendErr != nil {
 				logger.Error("Failed to edit message", map[string]interface{}{
@@ -1560,14 +1587,13 @@ func (b *Bot) saveMessageToCustomFile(callback *tgbotapi.CallbackQuery, filename
 	}
 
 	successMsg := fmt.Sprintf("✅ Saved to %s", filename)
+	successMsg += b.recordCommitStats(callback.Message.Chat.ID, userGitHubProvider, commitResult)
 
 	// Try to get GitHub URL for the file
 	githubURL, urlErr := userGitHubProvider.GetGitHubFileURLWithBranch(filename)
 	var keyboard *tgbotapi.InlineKeyboardMarkup
 	if urlErr == nil {
-		row := tgbotapi.NewInlineKeyboardRow(
-			tgbotapi.NewInlineKeyboardButtonURL("🔗 View on GitHub", githubURL),
-		)
+		row := commitLinkRow(githubURL, commitResult)
 		keyboardValue := tgbotapi.NewInlineKeyboardMarkup(row)
 		keyboard = &keyboardValue
 	}
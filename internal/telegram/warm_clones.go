@@ -0,0 +1,151 @@
+package telegram
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/github"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Warm clones: a new user's first message would otherwise wait for a full clone, so the
+// repository is cloned in the background as soon as it is configured. With WARM_FETCH_INTERVAL,
+// clones of chats active within warmHotWindow are also fetched periodically so the pull before
+// a commit stays small. Both stop once ./data reaches WARM_DISK_QUOTA_MB.
+
+// warmHotWindow is how long after its last use a chat's repository keeps being fetched
+const warmHotWindow = 2 * time.Hour
+
+// warmCloneTimeout bounds how long a pre-clone keeps others of the same chat from starting
+const warmCloneTimeout = 10 * time.Minute
+
+// markRepoHot records that chatID's repository was just used
+func (b *Bot) markRepoHot(chatID int64) {
+	b.hotRepos.Store(chatID, time.Now())
+}
+
+// warmRepository clones chatID's repository in the background if it isn't cloned yet
+func (b *Bot) warmRepository(chatID int64) {
+	if github.LocalClonesDisabled() || b.warmDiskQuotaReached() {
+		return
+	}
+
+	cacheKey := fmt.Sprintf("warm_clone_%d", chatID)
+	if _, exists := b.cache.Get(cacheKey); exists {
+		return
+	}
+	b.cache.SetWithExpiry(cacheKey, true, warmCloneTimeout)
+
+	go func() {
+		defer b.cache.Delete(cacheKey)
+
+		provider, err := b.getUserGitHubProvider(chatID)
+		if err != nil {
+			logger.Debug("Skipping repository pre-clone", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			return
+		}
+		if !provider.NeedsClone() {
+			return
+		}
+
+		start := time.Now()
+		if err := provider.EnsureRepositoryWithPremium(b.getPremiumLevel(chatID)); err != nil {
+			logger.Warn("Failed to pre-clone repository", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			return
+		}
+		logger.Info("Pre-cloned repository", map[string]interface{}{
+			"chat_id":     chatID,
+			"duration_ms": time.Since(start).Milliseconds(),
+		})
+	}()
+}
+
+// warmDiskQuotaReached reports whether ./data is too large for background clones and fetches
+func (b *Bot) warmDiskQuotaReached() bool {
+	quota := int64(b.cfg().WarmDiskQuotaMB) * 1024 * 1024
+	if quota <= 0 {
+		return true
+	}
+
+	size, err := github.DataDirectorySize()
+	if err != nil {
+		logger.Warn("Failed to measure repository data directory", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return true
+	}
+	return size >= quota
+}
+
+// startWarmFetches periodically fetches the repositories of recently active chats
+func (b *Bot) startWarmFetches() {
+	if b.cfg().WarmFetchInterval <= 0 || github.LocalClonesDisabled() {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopWarmFetches = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(b.cfg().WarmFetchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runWarmFetches()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runWarmFetches() {
+	cutoff := time.Now().Add(-warmHotWindow)
+	fetched := 0
+
+	b.hotRepos.Range(func(key, value interface{}) bool {
+		chatID := key.(int64)
+		if value.(time.Time).Before(cutoff) {
+			b.hotRepos.Delete(chatID)
+			return true
+		}
+		if b.warmDiskQuotaReached() {
+			return false
+		}
+
+		// Only providers still cached are fetched, going through getUserGitHubProvider would keep
+		// the chat hot forever
+		cached, exists := b.cache.Get(fmt.Sprintf("github_provider_%d", chatID))
+		if !exists {
+			return true
+		}
+		fetcher, ok := cached.(github.Fetcher)
+		if !ok {
+			return true
+		}
+
+		if err := fetcher.Fetch(); err != nil {
+			logger.Warn("Failed to fetch warm repository", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			return true
+		}
+		fetched++
+		return true
+	})
+
+	if fetched > 0 {
+		logger.Debug("Fetched warm repositories", map[string]interface{}{
+			"count": fetched,
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package telegram
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/cache"
+	"github.com/msg2git/msg2git/internal/config"
+)
+
+type fakeFetcher struct {
+	fetches int
+	err     error
+}
+
+func (f *fakeFetcher) Fetch() error {
+	f.fetches++
+	return f.err
+}
+
+// inTempDir runs the test from an empty directory, ./data is relative to it
+func inTempDir(t *testing.T) string {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Chdir() error = %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	return dir
+}
+
+func TestWarmDiskQuotaReached(t *testing.T) {
+	dir := inTempDir(t)
+	b := &Bot{config: &config.Config{WarmDiskQuotaMB: 1}}
+
+	if b.warmDiskQuotaReached() {
+		t.Error("quota reached without a data directory")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "data", "user_1"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "data", "user_1", "blob"), make([]byte, 2*1024*1024), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !b.warmDiskQuotaReached() {
+		t.Error("quota not reached with 2 MB in ./data")
+	}
+
+	b.config.WarmDiskQuotaMB = 0
+	if err := os.RemoveAll(filepath.Join(dir, "data")); err != nil {
+		t.Fatal(err)
+	}
+	if !b.warmDiskQuotaReached() {
+		t.Error("a zero quota should disable background clones")
+	}
+}
+
+func TestRunWarmFetches(t *testing.T) {
+	inTempDir(t)
+	b := &Bot{
+		config: &config.Config{WarmDiskQuotaMB: 100},
+		cache:  cache.NewWithConfig(100, 30*time.Minute, 5*time.Minute),
+	}
+
+	active := &fakeFetcher{}
+	failing := &fakeFetcher{err: errors.New("connection reset")}
+	stale := &fakeFetcher{}
+	b.cache.Set(fmt.Sprintf("github_provider_%d", 1), active)
+	b.cache.Set(fmt.Sprintf("github_provider_%d", 2), failing)
+	b.cache.Set(fmt.Sprintf("github_provider_%d", 3), stale)
+
+	b.markRepoHot(1)
+	b.markRepoHot(2)
+	b.hotRepos.Store(int64(3), time.Now().Add(-warmHotWindow-time.Minute))
+	b.markRepoHot(4) // No cached provider
+
+	b.runWarmFetches()
+
+	if active.fetches != 1 || failing.fetches != 1 {
+		t.Errorf("fetches = %d, %d, want active repositories fetched once", active.fetches, failing.fetches)
+	}
+	if stale.fetches != 0 {
+		t.Error("repository unused for longer than the hot window was fetched")
+	}
+	if _, ok := b.hotRepos.Load(int64(3)); ok {
+		t.Error("stale repository wasn't pruned")
+	}
+	if _, ok := b.hotRepos.Load(int64(4)); !ok {
+		t.Error("active repository without a cached provider was pruned")
+	}
+}
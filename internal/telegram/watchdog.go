@@ -0,0 +1,117 @@
+package telegram
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/watchdog"
+)
+
+// Slow operation watchdog (see internal/watchdog): handlers are timed by the worker pool, git
+// operations by the github package and queries by the database package. Admins see the slowest
+// paths with /admin slow and, with SLOW_NOTIFY_ADMINS, get a DM per slow path at most every
+// slowAlertCooldown.
+
+// slowAlertCooldown is how long admins aren't DMed again about the same slow operation
+const slowAlertCooldown = 15 * time.Minute
+
+// slowStatsShown is how many slow operations /admin slow lists
+const slowStatsShown = 15
+
+// startWatchdog applies the configured thresholds and reports slow operations to admins
+func (b *Bot) startWatchdog() {
+	b.applyWatchdogThresholds()
+	watchdog.SetReporter(b.reportSlowOperation)
+}
+
+// applyWatchdogThresholds copies the thresholds from config, also after a reload
+func (b *Bot) applyWatchdogThresholds() {
+	watchdog.SetThreshold(watchdog.Handler, b.cfg().SlowHandlerThreshold)
+	watchdog.SetThreshold(watchdog.Git, b.cfg().SlowGitThreshold)
+	watchdog.SetThreshold(watchdog.Query, b.cfg().SlowQueryThreshold)
+}
+
+// reportSlowOperation DMs admins about a slow operation unless they were told about it recently
+func (b *Bot) reportSlowOperation(event watchdog.Event) {
+	if !b.cfg().SlowNotifyAdmins || len(b.cfg().AdminChatIDs) == 0 {
+		return
+	}
+
+	cacheKey := fmt.Sprintf("slow_alert_%s_%s", event.Kind, event.Name)
+	if _, exists := b.cache.Get(cacheKey); exists {
+		return
+	}
+	b.cache.SetWithExpiry(cacheKey, true, slowAlertCooldown)
+
+	correlation := "none"
+	if event.CorrelationID != "" {
+		correlation = event.CorrelationID
+	}
+	text := fmt.Sprintf(SlowOperationAlertTemplate,
+		event.Kind, html.EscapeString(event.Name), formatWatchdogDuration(event.Duration),
+		formatWatchdogDuration(event.Threshold), event.ChatID, correlation)
+
+	for _, adminID := range b.cfg().AdminChatIDs {
+		b.sendResponse(adminID, text)
+	}
+}
+
+// handleAdminSlowCommand lists the slow operations seen since the bot started
+func (b *Bot) handleAdminSlowCommand(message *tgbotapi.Message) error {
+	stats := watchdog.Stats()
+	if len(stats) == 0 {
+		b.sendResponse(message.Chat.ID, "🐢 No slow operations since the bot started.")
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🐢 <b>Slow Operations</b>\n\n")
+	for i, stat := range stats {
+		if i == slowStatsShown {
+			sb.WriteString(fmt.Sprintf("<i>…and %d more</i>\n", len(stats)-slowStatsShown))
+			break
+		}
+		avg := stat.Total / time.Duration(stat.Count)
+		sb.WriteString(fmt.Sprintf("• <b>%s</b> <code>%s</code>: %d× · avg %s · max %s · last %s ago",
+			stat.Kind, html.EscapeString(stat.Name), stat.Count, formatWatchdogDuration(avg),
+			formatWatchdogDuration(stat.Max), formatWatchdogDuration(time.Since(stat.LastAt))))
+		if stat.LastCorrelationID != "" {
+			sb.WriteString(fmt.Sprintf(" (<code>%s</code>)", stat.LastCorrelationID))
+		}
+		sb.WriteString("\n")
+	}
+
+	b.sendResponse(message.Chat.ID, sb.String())
+	return nil
+}
+
+// messageHandlerName names a message handler for the watchdog: the command, or the kind of content
+func messageHandlerName(message *tgbotapi.Message) string {
+	switch {
+	case message.IsCommand():
+		return "/" + message.Command()
+	case message.Photo != nil:
+		return "photo"
+	case message.Document != nil:
+		return "document"
+	default:
+		return "message"
+	}
+}
+
+// callbackHandlerName names a callback handler for the watchdog by the prefix of its data
+func callbackHandlerName(callback *tgbotapi.CallbackQuery) string {
+	prefix, _, _ := strings.Cut(callback.Data, "_")
+	return "callback:" + prefix
+}
+
+// formatWatchdogDuration rounds a duration for display, e.g. "1.2s" or "350ms"
+func formatWatchdogDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
@@ -0,0 +1,141 @@
+package telegram
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Webhook mode: with WEBHOOK_URL set, the bot registers a Telegram webhook and receives updates
+// on the HTTP server of StartWebhookServer instead of long polling them. Every instance behind a
+// load balancer registers the same URL and secret, so any of them can take any update, which is
+// handed to the worker pool exactly like a polled one. Without WEBHOOK_URL a webhook left over
+// from webhook mode is removed, since Telegram refuses getUpdates while one is set.
+
+const (
+	defaultWebhookPath  = "/telegram/webhook"
+	webhookSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+	webhookMaxBodyBytes = 1 << 20
+)
+
+// updateTypes are the updates the bot asks Telegram for
+var updateTypes = []string{"message", "edited_message", "callback_query", "channel_post"}
+
+// updatesChan returns the channel the bot receives updates on, polled or pushed by the webhook
+func (b *Bot) updatesChan() (tgbotapi.UpdatesChannel, error) {
+	if !b.cfg().HasTelegramWebhookConfig() {
+		if _, err := b.api.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
+			logger.Warn("Failed to remove Telegram webhook before polling", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+
+		u := tgbotapi.NewUpdate(0)
+		u.Timeout = 60
+		u.AllowedUpdates = updateTypes
+
+		// Polled by the bot to see the forum topics of messages (implemented in forum_topics.go)
+		return b.getUpdatesChan(u), nil
+	}
+
+	webhookURL, err := parseWebhookURL(b.cfg().WebhookURL)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(chan tgbotapi.Update, b.api.Buffer)
+	http.HandleFunc(webhookURL.Path, b.telegramWebhookHandler(updates))
+
+	if err := b.setTelegramWebhook(webhookURL.String()); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Receiving Telegram updates through webhook", map[string]interface{}{
+		"path": webhookURL.Path,
+		"port": b.cfg().WebhookPort,
+	})
+	return updates, nil
+}
+
+// parseWebhookURL parses WEBHOOK_URL, using defaultWebhookPath if it has no path
+func parseWebhookURL(raw string) (*url.URL, error) {
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WEBHOOK_URL: %w", err)
+	}
+	if parsed.Path == "" || parsed.Path == "/" {
+		parsed.Path = defaultWebhookPath
+	}
+	return parsed, nil
+}
+
+// webhookSecret returns the secret token Telegram sends with every webhook request. It's derived
+// from the bot token so every instance of the bot agrees on it without extra configuration.
+func (b *Bot) webhookSecret() string {
+	sum := sha256.Sum256([]byte("msg2git-webhook:" + b.cfg().TelegramBotToken))
+	return hex.EncodeToString(sum[:])
+}
+
+// setTelegramWebhook registers the webhook URL with Telegram. The library's WebhookConfig lacks
+// secret_token, so the request is made with raw parameters.
+func (b *Bot) setTelegramWebhook(webhookURL string) error {
+	params := tgbotapi.Params{
+		"url":          webhookURL,
+		"secret_token": b.webhookSecret(),
+	}
+	if err := params.AddInterface("allowed_updates", updateTypes); err != nil {
+		return fmt.Errorf("failed to encode allowed updates: %w", err)
+	}
+
+	if _, err := b.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set Telegram webhook: %w", err)
+	}
+	return nil
+}
+
+// telegramWebhookHandler accepts updates posted by Telegram and hands them to updates
+func (b *Bot) telegramWebhookHandler(updates chan<- tgbotapi.Update) http.HandlerFunc {
+	secret := []byte(b.webhookSecret())
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get(webhookSecretHeader)), secret) != 1 {
+			logger.Warn("Rejected Telegram webhook request with a wrong secret", map[string]interface{}{
+				"remote_addr": r.RemoteAddr,
+			})
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, webhookMaxBodyBytes))
+		if err != nil {
+			http.Error(w, "Failed to read update", http.StatusBadRequest)
+			return
+		}
+		var update tgbotapi.Update
+		if err := json.Unmarshal(body, &update); err != nil {
+			logger.Error("Failed to decode webhook update", map[string]interface{}{
+				"error": err.Error(),
+			})
+			http.Error(w, "Invalid update", http.StatusBadRequest)
+			return
+		}
+
+		// Forum topics are only in the raw update (implemented in forum_topics.go)
+		b.rememberForumTopics(json.RawMessage("[" + string(body) + "]"))
+
+		updates <- update
+		w.WriteHeader(http.StatusOK)
+	}
+}
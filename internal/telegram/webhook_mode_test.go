@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestParseWebhookURL(t *testing.T) {
+	tests := map[string]string{
+		"https://bot.example.com":         "https://bot.example.com/telegram/webhook",
+		"https://bot.example.com/":        "https://bot.example.com/telegram/webhook",
+		"https://bot.example.com/tg/hook": "https://bot.example.com/tg/hook",
+	}
+	for raw, want := range tests {
+		got, err := parseWebhookURL(raw)
+		if err != nil {
+			t.Fatalf("parseWebhookURL(%q) error = %v", raw, err)
+		}
+		if got.String() != want {
+			t.Errorf("parseWebhookURL(%q) = %q, want %q", raw, got.String(), want)
+		}
+	}
+}
+
+func TestSetTelegramWebhook(t *testing.T) {
+	bot, fake := newFakeBot(t)
+
+	if err := bot.setTelegramWebhook("https://bot.example.com/telegram/webhook"); err != nil {
+		t.Fatalf("setTelegramWebhook() error = %v", err)
+	}
+
+	calls := fake.Calls()
+	last := calls[len(calls)-1]
+	if last.Method != "setWebhook" {
+		t.Fatalf("Expected a setWebhook call, got %+v", last)
+	}
+	if got := last.Params.Get("url"); got != "https://bot.example.com/telegram/webhook" {
+		t.Errorf("setWebhook url = %q", got)
+	}
+	if got := last.Params.Get("secret_token"); got != bot.webhookSecret() {
+		t.Errorf("setWebhook secret_token = %q, want %q", got, bot.webhookSecret())
+	}
+}
+
+func TestTelegramWebhookHandler(t *testing.T) {
+	bot, _ := newFakeBot(t)
+	updates := make(chan tgbotapi.Update, 1)
+	handler := bot.telegramWebhookHandler(updates)
+
+	post := func(method, secret, body string) int {
+		req := httptest.NewRequest(method, defaultWebhookPath, strings.NewReader(body))
+		if secret != "" {
+			req.Header.Set(webhookSecretHeader, secret)
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec.Code
+	}
+
+	update := `{"update_id":42,"message":{"message_id":1,"chat":{"id":7},"text":"hello"}}`
+	if code := post(http.MethodGet, bot.webhookSecret(), ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", code, http.StatusMethodNotAllowed)
+	}
+	if code := post(http.MethodPost, "wrong", update); code != http.StatusUnauthorized {
+		t.Errorf("wrong secret status = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := post(http.MethodPost, bot.webhookSecret(), "not json"); code != http.StatusBadRequest {
+		t.Errorf("invalid body status = %d, want %d", code, http.StatusBadRequest)
+	}
+	if len(updates) != 0 {
+		t.Fatalf("Expected rejected requests to queue no updates, got %d", len(updates))
+	}
+
+	if code := post(http.MethodPost, bot.webhookSecret(), update); code != http.StatusOK {
+		t.Fatalf("valid update status = %d, want %d", code, http.StatusOK)
+	}
+	got := <-updates
+	if got.UpdateID != 42 || got.Message == nil || got.Message.Text != "hello" {
+		t.Errorf("Queued update = %+v", got)
+	}
+}
@@ -0,0 +1,259 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/netguard"
+	"github.com/msg2git/msg2git/internal/webhook"
+)
+
+// Outgoing webhooks: users register URLs that receive signed JSON events for automations
+
+const (
+	maxWebhooksPerUser = 5
+
+	// webhookResolveTimeout bounds the DNS lookup of a webhook URL being added
+	webhookResolveTimeout = 5 * time.Second
+)
+
+// emitWebhookEvent delivers event to the user's subscribed webhooks in the background
+func (b *Bot) emitWebhookEvent(chatID int64, event string, data map[string]interface{}) {
+	if b.db == nil || b.webhooks == nil {
+		return
+	}
+
+	go func() {
+		hooks, err := b.db.GetWebhooks(chatID)
+		if err != nil {
+			logger.Warn("Failed to load webhooks", map[string]interface{}{
+				"chat_id": chatID,
+				"error":   err.Error(),
+			})
+			return
+		}
+
+		payload := webhook.NewPayload(event, chatID, data)
+		for _, hook := range hooks {
+			if !hook.Subscribes(event) {
+				continue
+			}
+			if err := b.webhooks.Deliver(hook.URL, hook.Secret, payload); err != nil {
+				logger.Warn("Webhook delivery failed", map[string]interface{}{
+					"chat_id":    chatID,
+					"webhook_id": hook.ID,
+					"event":      event,
+					"error":      err.Error(),
+				})
+				b.recordBackgroundFailure(chatID, failureSourceWebhook, fmt.Sprintf("%s delivery to %s failed: %s", event, hook.URL, err.Error()))
+			}
+		}
+	}()
+}
+
+// validateWebhookURL only accepts absolute HTTPS URLs of public hosts
+func validateWebhookURL(raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" {
+		return fmt.Errorf("invalid URL")
+	}
+	if parsed.Scheme != "https" {
+		return fmt.Errorf("webhook URLs must use https")
+	}
+
+	// Deliveries check addresses again when connecting, the host may resolve elsewhere by then
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+	return netguard.CheckURL(ctx, raw)
+}
+
+// parseWebhookEvents validates a comma separated event list, empty means all events
+func parseWebhookEvents(raw string) (string, error) {
+	var events []string
+	for _, event := range strings.Split(raw, ",") {
+		event = strings.TrimSpace(event)
+		if event == "" {
+			continue
+		}
+		if !webhook.IsValidEvent(event) {
+			return "", fmt.Errorf("unknown event %q, valid events: %s", event, strings.Join(webhook.Events, ", "))
+		}
+		events = append(events, event)
+	}
+	return strings.Join(events, ","), nil
+}
+
+// handleWebhooksCommand manages outgoing webhooks:
+// /webhooks, /webhooks add <url> [events], /webhooks remove <id>, /webhooks test <id>
+func (b *Bot) handleWebhooksCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.Fields(strings.TrimPrefix(strings.TrimSpace(message.Text), "/webhooks"))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ Webhooks require a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	if len(args) == 0 {
+		return b.showWebhooks(chatID)
+	}
+
+	switch args[0] {
+	case "add":
+		if len(args) < 2 {
+			b.sendResponse(chatID, "Usage: <code>/webhooks add https://example.com/hook [event,event]</code>")
+			return nil
+		}
+		events := ""
+		if len(args) > 2 {
+			events = strings.Join(args[2:], ",")
+		}
+		return b.addWebhook(chatID, args[1], events)
+	case "remove", "test":
+		if len(args) < 2 {
+			b.sendResponse(chatID, fmt.Sprintf("Usage: <code>/webhooks %s &lt;id&gt;</code>", args[0]))
+			return nil
+		}
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Invalid webhook id: %s", html.EscapeString(args[1])))
+			return nil
+		}
+		if args[0] == "remove" {
+			return b.removeWebhook(chatID, id)
+		}
+		return b.testWebhook(chatID, id)
+	default:
+		b.sendResponse(chatID, fmt.Sprintf("❌ Unknown webhooks action: %s", html.EscapeString(args[0])))
+		return nil
+	}
+}
+
+func (b *Bot) showWebhooks(chatID int64) error {
+	hooks, err := b.db.GetWebhooks(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load webhooks: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🪝 <b>Outgoing Webhooks</b>\n")
+	if len(hooks) == 0 {
+		sb.WriteString("\nNo webhooks configured.\n")
+	}
+	for _, hook := range hooks {
+		events := "all events"
+		if hook.Events != "" {
+			events = strings.ReplaceAll(hook.Events, ",", ", ")
+		}
+		sb.WriteString(fmt.Sprintf("\n<b>#%d</b> <code>%s</code>\n  %s\n", hook.ID, html.EscapeString(hook.URL), html.EscapeString(events)))
+	}
+
+	sb.WriteString(fmt.Sprintf(`
+Events: <code>%s</code>
+Each request is a JSON POST signed with HMAC-SHA256 in the <code>%s</code> header.
+
+• /webhooks add &lt;url&gt; [events] - Register a webhook
+• /webhooks test &lt;id&gt; - Send a ping event
+• /webhooks remove &lt;id&gt; - Delete a webhook`, strings.Join(webhook.Events, ", "), webhook.HeaderSignature))
+
+	b.sendResponse(chatID, sb.String())
+	return nil
+}
+
+func (b *Bot) addWebhook(chatID int64, rawURL, rawEvents string) error {
+	if err := validateWebhookURL(rawURL); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	events, err := parseWebhookEvents(rawEvents)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	existing, err := b.db.GetWebhooks(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load webhooks: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	if len(existing) >= maxWebhooksPerUser {
+		b.sendResponse(chatID, fmt.Sprintf("❌ You can register up to %d webhooks. Remove one first.", maxWebhooksPerUser))
+		return nil
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	hook, err := b.db.CreateWebhook(chatID, rawURL, secret, events)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to save webhook: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	logger.Info("Webhook registered", map[string]interface{}{
+		"chat_id":    chatID,
+		"webhook_id": hook.ID,
+		"events":     events,
+	})
+
+	b.sendResponse(chatID, fmt.Sprintf(`✅ Webhook <b>#%d</b> registered.
+
+Signing secret (shown only once):
+<code>%s</code>
+
+Verify deliveries by comparing the <code>%s</code> header with <code>sha256=</code> + hex HMAC-SHA256 of the request body.`,
+		hook.ID, secret, webhook.HeaderSignature))
+	return nil
+}
+
+func (b *Bot) removeWebhook(chatID, id int64) error {
+	if err := b.db.DeleteWebhook(id, chatID); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ %s", html.EscapeString(err.Error())))
+		return nil
+	}
+	b.sendResponse(chatID, fmt.Sprintf("🗑 Webhook <b>#%d</b> removed.", id))
+	return nil
+}
+
+func (b *Bot) testWebhook(chatID, id int64) error {
+	hooks, err := b.db.GetWebhooks(chatID)
+	if err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to load webhooks: %s", html.EscapeString(err.Error())))
+		return nil
+	}
+
+	for _, hook := range hooks {
+		if hook.ID != id {
+			continue
+		}
+		payload := webhook.NewPayload(webhook.EventPing, chatID, map[string]interface{}{
+			"webhook_id": hook.ID,
+		})
+		if err := b.webhooks.Deliver(hook.URL, hook.Secret, payload); err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Ping to webhook #%d failed: %s", id, html.EscapeString(err.Error())))
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("✅ Ping delivered to webhook <b>#%d</b>.", id))
+		return nil
+	}
+
+	b.sendResponse(chatID, "❌ webhook not found")
+	return nil
+}
@@ -0,0 +1,258 @@
+package telegram
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/database"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Weekly changelog: users who opt in with /changelog on get a GitHub issue in their repository
+// every Monday listing last week's captures with links to the commits. GitHub's own notifications
+// deliver it by email, and the issue is a place to review the week. /changelog now opens the issue
+// of the current week early; it is updated instead of duplicated once the week is over.
+
+const (
+	weeklyChangelogCheckInterval = 1 * time.Hour
+	changelogMaxFiles            = 10
+	changelogMaxEntries          = 300 // Commits listed in one issue, later ones are only counted
+)
+
+// handleChangelogCommand shows, switches or posts the weekly changelog
+func (b *Bot) handleChangelogCommand(message *tgbotapi.Message) error {
+	chatID := message.Chat.ID
+	args := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(message.Text), "/changelog")))
+
+	if b.db == nil {
+		b.sendResponse(chatID, "❌ The weekly changelog requires a database.")
+		return nil
+	}
+
+	if _, err := b.ensureUser(message); err != nil {
+		b.sendResponse(chatID, fmt.Sprintf("❌ Failed to get user: %v", err))
+		return nil
+	}
+
+	switch args {
+	case "":
+		changelog, err := b.db.GetWeeklyChangelog(chatID)
+		if err != nil {
+			b.sendResponse(chatID, "❌ Failed to load the weekly changelog.")
+			return nil
+		}
+		if changelog == nil {
+			b.sendResponse(chatID, "📓 The weekly changelog is off.\n\nUse <code>/changelog on</code> to get a GitHub issue summarizing each week's captures every Monday.")
+			return nil
+		}
+		b.sendResponse(chatID, "📓 The weekly changelog is on. Every Monday an issue summarizing last week's captures is opened in your repository.\n\nUse <code>/changelog now</code> to open this week's issue early or <code>/changelog off</code> to stop.")
+		return nil
+	case "on":
+		if err := b.db.EnableWeeklyChangelog(chatID); err != nil {
+			b.sendResponse(chatID, "❌ Failed to enable the weekly changelog.")
+			return nil
+		}
+		b.sendResponse(chatID, "📓 Weekly changelog enabled. The first issue is opened next Monday; GitHub notifies you about it like about any issue.")
+		return nil
+	case "off":
+		if _, err := b.db.DisableWeeklyChangelog(chatID); err != nil {
+			b.sendResponse(chatID, "❌ Failed to disable the weekly changelog.")
+			return nil
+		}
+		b.sendResponse(chatID, "📓 Weekly changelog disabled. Issues already opened stay in your repository.")
+		return nil
+	case "now":
+		changelog, err := b.db.GetWeeklyChangelog(chatID)
+		if err != nil || changelog == nil {
+			b.sendResponse(chatID, "📓 Enable the weekly changelog with <code>/changelog on</code> first.")
+			return nil
+		}
+		now := time.Now()
+		issueNumber, err := b.postWeeklyChangelog(changelog, startOfWeek(now), now, now)
+		if err != nil {
+			b.sendResponse(chatID, fmt.Sprintf("❌ Failed to post the weekly changelog: %v", err))
+			return nil
+		}
+		if issueNumber == 0 {
+			b.sendResponse(chatID, "📓 Nothing captured this week yet.")
+			return nil
+		}
+		b.sendResponse(chatID, fmt.Sprintf("📓 This week's changelog is issue #%d. It is updated with the rest of the week on Monday.", issueNumber))
+		return nil
+	default:
+		b.sendResponse(chatID, "Usage: <code>/changelog</code>, <code>/changelog on</code>, <code>/changelog off</code> or <code>/changelog now</code>")
+		return nil
+	}
+}
+
+// startWeeklyChangelogs periodically posts last week's changelog once a new week has started
+func (b *Bot) startWeeklyChangelogs() {
+	if b.db == nil {
+		return
+	}
+
+	stop := make(chan struct{})
+	b.stopWeeklyChangelogs = func() { close(stop) }
+
+	go func() {
+		ticker := time.NewTicker(weeklyChangelogCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				b.runWeeklyChangelogs()
+			}
+		}
+	}()
+}
+
+func (b *Bot) runWeeklyChangelogs() {
+	now := time.Now()
+	weekStart := startOfWeek(now)
+	changelogs, err := b.db.GetDueWeeklyChangelogs(weekStart)
+	if err != nil {
+		logger.Error("Failed to load weekly changelogs", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	for _, changelog := range changelogs {
+		if !b.chatAllowed(changelog.ChatID) {
+			continue
+		}
+		if _, err := b.postWeeklyChangelog(changelog, weekStart.AddDate(0, 0, -7), weekStart, now); err != nil {
+			logger.Warn("Failed to post weekly changelog", map[string]interface{}{
+				"chat_id": changelog.ChatID,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// postWeeklyChangelog opens or updates the issue of the week starting at weekStart with the commits
+// before to and returns its number, 0 if the week had no captures and no issue yet
+func (b *Bot) postWeeklyChangelog(changelog *database.WeeklyChangelog, weekStart, to, now time.Time) (int, error) {
+	chatID := changelog.ChatID
+
+	entries, err := b.db.GetCommitsBetween(chatID, weekStart, to)
+	if err != nil {
+		return 0, err
+	}
+
+	issueNumber := 0
+	if changelog.WeekStart != nil && changelog.WeekStart.Equal(weekStart) {
+		issueNumber = changelog.IssueNumber
+	}
+	if len(entries) == 0 && issueNumber == 0 {
+		return 0, b.db.UpdateWeeklyChangelog(chatID, 0, weekStart, now)
+	}
+
+	provider, err := b.getUserGitHubProvider(chatID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get GitHub provider: %w", err)
+	}
+
+	body := formatChangelogBody(entries, weekStart, to)
+	if issueNumber != 0 {
+		if err := provider.UpdateIssueBody(issueNumber, body); err != nil {
+			return 0, fmt.Errorf("failed to update changelog issue: %w", err)
+		}
+	} else {
+		_, number, err := provider.CreateIssue(formatChangelogTitle(weekStart), body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to open changelog issue: %w", err)
+		}
+		issueNumber = number
+	}
+
+	logger.Info("Posted weekly changelog", map[string]interface{}{
+		"chat_id":      chatID,
+		"issue_number": issueNumber,
+		"commits":      len(entries),
+	})
+
+	return issueNumber, b.db.UpdateWeeklyChangelog(chatID, issueNumber, weekStart, now)
+}
+
+// startOfWeek returns local midnight of the Monday of the week of t
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return startOfDay(t).AddDate(0, 0, -daysSinceMonday)
+}
+
+// formatChangelogTitle formats the issue title of the week starting at weekStart
+func formatChangelogTitle(weekStart time.Time) string {
+	weekEnd := weekStart.AddDate(0, 0, 6)
+	return fmt.Sprintf("📓 Weekly changelog: %s – %s", weekStart.Format("Jan 2"), weekEnd.Format("Jan 2, 2006"))
+}
+
+// formatChangelogBody formats the issue body: commits per file, then every commit by day with a link
+func formatChangelogBody(entries []*database.CommitLogEntry, weekStart, to time.Time) string {
+	var sb strings.Builder
+
+	last := to.Add(-time.Second)
+	if weekEnd := weekStart.AddDate(0, 0, 7); !to.Before(weekEnd) {
+		last = weekEnd.Add(-time.Second)
+	}
+	sb.WriteString(fmt.Sprintf("Captures from %s to %s.\n\n", weekStart.Format("Monday, Jan 2"), last.Format("Monday, Jan 2, 2006")))
+
+	if len(entries) == 0 {
+		sb.WriteString("Nothing was captured this week.\n")
+		return sb.String()
+	}
+
+	files := make(map[string]int)
+	for _, e := range entries {
+		files[e.Filename]++
+	}
+	sb.WriteString(fmt.Sprintf("**%d %s** to **%d %s**\n\n", len(entries), plural(len(entries), "commit", "commits"), len(files), plural(len(files), "file", "files")))
+
+	sb.WriteString("| File | Commits |\n|---|---|\n")
+	for _, file := range topCounts(files, changelogMaxFiles) {
+		sb.WriteString(fmt.Sprintf("| %s | %d |\n", file.Name, file.Count))
+	}
+	if len(files) > changelogMaxFiles {
+		sb.WriteString(fmt.Sprintf("| …%d more | |\n", len(files)-changelogMaxFiles))
+	}
+
+	day := ""
+	for i, e := range entries {
+		if i == changelogMaxEntries {
+			sb.WriteString(fmt.Sprintf("- …and %d more\n", len(entries)-changelogMaxEntries))
+			break
+		}
+
+		created := e.CreatedAt.In(weekStart.Location())
+		if d := created.Format("Monday, Jan 2"); d != day {
+			day = d
+			sb.WriteString(fmt.Sprintf("\n### %s\n\n", day))
+		}
+
+		ref := e.Filename
+		if e.CommitURL != "" {
+			sha := e.CommitSHA
+			if len(sha) > 7 {
+				sha = sha[:7]
+			}
+			ref = fmt.Sprintf("%s ([`%s`](%s))", e.Filename, sha, e.CommitURL)
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s\n", created.Format("15:04"), ref))
+	}
+
+	sb.WriteString("\n<sub>Posted by msg2git. Turn it off with /changelog off.</sub>\n")
+	return sb.String()
+}
+
+// plural picks the singular or plural form for n
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
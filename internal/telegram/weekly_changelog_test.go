@@ -0,0 +1,85 @@
+package telegram
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/database"
+)
+
+func TestStartOfWeek(t *testing.T) {
+	monday := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name string
+		t    time.Time
+	}{
+		{"monday midnight", monday},
+		{"monday evening", time.Date(2026, 10, 12, 21, 30, 0, 0, time.Local)},
+		{"thursday", time.Date(2026, 10, 15, 9, 0, 0, 0, time.Local)},
+		{"sunday night", time.Date(2026, 10, 18, 23, 59, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := startOfWeek(tt.t); !got.Equal(monday) {
+				t.Errorf("startOfWeek(%v) = %v, want %v", tt.t, got, monday)
+			}
+		})
+	}
+}
+
+func TestFormatChangelogTitle(t *testing.T) {
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	want := "📓 Weekly changelog: Oct 12 – Oct 18, 2026"
+	if got := formatChangelogTitle(weekStart); got != want {
+		t.Errorf("formatChangelogTitle() = %q, want %q", got, want)
+	}
+}
+
+func TestFormatChangelogBody(t *testing.T) {
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	entries := []*database.CommitLogEntry{
+		{Filename: "inbox.md", CommitSHA: "0123456789abcdef", CommitURL: "https://github.com/u/notes/commit/0123456789abcdef", CreatedAt: weekStart.Add(9 * time.Hour)},
+		{Filename: "inbox.md", CommitSHA: "abcdef0123456789", CommitURL: "https://github.com/u/notes/commit/abcdef0123456789", CreatedAt: weekStart.Add(10 * time.Hour)},
+		{Filename: "todo.md", CreatedAt: weekStart.Add(50 * time.Hour)},
+	}
+
+	body := formatChangelogBody(entries, weekStart, weekEnd)
+	for _, want := range []string{
+		"Captures from Monday, Oct 12 to Sunday, Oct 18, 2026.",
+		"**3 commits** to **2 files**",
+		"| inbox.md | 2 |",
+		"| todo.md | 1 |",
+		"### Monday, Oct 12",
+		"- 09:00 inbox.md ([`0123456`](https://github.com/u/notes/commit/0123456789abcdef))",
+		"### Wednesday, Oct 14",
+		"- 02:00 todo.md\n",
+		"/changelog off",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	partial := formatChangelogBody(nil, weekStart, weekStart.Add(3*24*time.Hour+time.Hour))
+	if !strings.Contains(partial, "to Thursday, Oct 15, 2026.") || !strings.Contains(partial, "Nothing was captured") {
+		t.Errorf("partial week body = %q", partial)
+	}
+}
+
+func TestFormatChangelogBodyCapsEntries(t *testing.T) {
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.Local)
+	entries := make([]*database.CommitLogEntry, changelogMaxEntries+5)
+	for i := range entries {
+		entries[i] = &database.CommitLogEntry{Filename: "inbox.md", CreatedAt: weekStart.Add(time.Duration(i) * time.Minute)}
+	}
+
+	body := formatChangelogBody(entries, weekStart, weekStart.AddDate(0, 0, 7))
+	if !strings.Contains(body, "- …and 5 more") {
+		t.Errorf("body does not cap entries:\n%s", body[len(body)-200:])
+	}
+}
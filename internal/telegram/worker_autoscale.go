@@ -0,0 +1,218 @@
+package telegram
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Worker pool autoscaling: every ScaleInterval the message and callback workers are adjusted
+// between their min and max. A backed up queue adds workers, a queue that stayed empty for
+// ScaleDownDelay retires idle ones.
+
+const (
+	latencyWindowSize = 256
+	latencyWindowAge  = 1 * time.Minute // Older samples don't describe the current load
+)
+
+// queuedMessage is a message waiting for a worker
+type queuedMessage struct {
+	message  *tgbotapi.Message
+	queuedAt time.Time
+}
+
+// queuedCallback is a callback query waiting for a worker
+type queuedCallback struct {
+	callback *tgbotapi.CallbackQuery
+	queuedAt time.Time
+}
+
+// latencySample is the time a task took from submission to completion
+type latencySample struct {
+	at       time.Time
+	duration time.Duration
+}
+
+// latencyWindow keeps the latencies of the most recent tasks
+type latencyWindow struct {
+	mu      sync.Mutex
+	samples []latencySample
+	next    int
+}
+
+func newLatencyWindow() *latencyWindow {
+	return &latencyWindow{samples: make([]latencySample, 0, latencyWindowSize)}
+}
+
+// add records the latency of a finished task
+func (w *latencyWindow) add(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sample := latencySample{at: time.Now(), duration: d}
+	if len(w.samples) < latencyWindowSize {
+		w.samples = append(w.samples, sample)
+		return
+	}
+	w.samples[w.next] = sample
+	w.next = (w.next + 1) % latencyWindowSize
+}
+
+// percentiles returns the median and 95th percentile of the latencies of the last minute
+func (w *latencyWindow) percentiles() (p50, p95 time.Duration) {
+	w.mu.Lock()
+	recent := make([]time.Duration, 0, len(w.samples))
+	since := time.Now().Add(-latencyWindowAge)
+	for _, sample := range w.samples {
+		if sample.at.After(since) {
+			recent = append(recent, sample.duration)
+		}
+	}
+	w.mu.Unlock()
+
+	if len(recent) == 0 {
+		return 0, 0
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i] < recent[j] })
+	return recent[len(recent)*50/100], recent[len(recent)*95/100]
+}
+
+// workerBounds returns min and max worker counts around the initial count. Unset or inconsistent
+// bounds pin the count to the initial one.
+func workerBounds(initial, min, max int) (int, int) {
+	if min <= 0 || min > initial {
+		min = initial
+	}
+	if max < initial {
+		max = initial
+	}
+	return min, max
+}
+
+// scaleTarget returns the worker count for a queue given its depth and p95 latency. idleFor is
+// how long the queue has been empty.
+func scaleTarget(workers, min, max, depth int, p95, idleFor time.Duration, cfg WorkerPoolConfig) int {
+	backedUp := depth > 0 && (depth >= cfg.ScaleUpQueueDepth || p95 > cfg.TargetLatency)
+	switch {
+	case backedUp && workers < max:
+		// Grow by a quarter so large bursts are absorbed in a few intervals
+		step := workers / 4
+		if step < 1 {
+			step = 1
+		}
+		if workers+step > max {
+			return max
+		}
+		return workers + step
+	case depth == 0 && idleFor >= cfg.ScaleDownDelay && workers > min:
+		// Shrink gently, a tenth at a time
+		step := workers / 10
+		if step < 1 {
+			step = 1
+		}
+		if workers-step < min {
+			return min
+		}
+		return workers - step
+	}
+	return workers
+}
+
+// spawnMessageWorker starts a message worker, callers hold wp.mu
+func (wp *WorkerPool) spawnMessageWorker() {
+	wp.wg.Add(1)
+	go wp.messageWorker(wp.nextWorkerID)
+	wp.nextWorkerID++
+}
+
+// spawnCallbackWorker starts a callback worker, callers hold wp.mu
+func (wp *WorkerPool) spawnCallbackWorker() {
+	wp.wg.Add(1)
+	go wp.callbackWorker(wp.nextWorkerID)
+	wp.nextWorkerID++
+}
+
+// retire asks up to n idle workers to exit and returns how many did. Busy workers are left alone.
+func retire(retireCh chan struct{}, n int) int {
+	retired := 0
+	for ; retired < n; retired++ {
+		select {
+		case retireCh <- struct{}{}:
+		default:
+			return retired
+		}
+	}
+	return retired
+}
+
+// autoscale adjusts the worker counts every ScaleInterval until the pool stops
+func (wp *WorkerPool) autoscale() {
+	ticker := time.NewTicker(wp.config.ScaleInterval)
+	defer ticker.Stop()
+
+	messageBusyAt, callbackBusyAt := time.Now(), time.Now()
+	for {
+		select {
+		case <-wp.ctx.Done():
+			return
+		case now := <-ticker.C:
+			if len(wp.messageQueue) > 0 {
+				messageBusyAt = now
+			}
+			if len(wp.callbackQueue) > 0 {
+				callbackBusyAt = now
+			}
+			wp.scale(now.Sub(messageBusyAt), now.Sub(callbackBusyAt))
+		}
+	}
+}
+
+// scale applies scaleTarget to both queues
+func (wp *WorkerPool) scale(messageIdleFor, callbackIdleFor time.Duration) {
+	wp.mu.Lock()
+	defer wp.mu.Unlock()
+
+	if !wp.started {
+		return
+	}
+
+	_, messageP95 := wp.messageLatency.percentiles()
+	target := scaleTarget(wp.messageWorkerCount, wp.config.MinMessageWorkers, wp.config.MaxMessageWorkers,
+		len(wp.messageQueue), messageP95, messageIdleFor, wp.config)
+	wp.messageWorkerCount = wp.resize("message", wp.messageWorkerCount, target, len(wp.messageQueue), wp.spawnMessageWorker, wp.messageRetire)
+
+	_, callbackP95 := wp.callbackLatency.percentiles()
+	target = scaleTarget(wp.callbackWorkerCount, wp.config.MinCallbackWorkers, wp.config.MaxCallbackWorkers,
+		len(wp.callbackQueue), callbackP95, callbackIdleFor, wp.config)
+	wp.callbackWorkerCount = wp.resize("callback", wp.callbackWorkerCount, target, len(wp.callbackQueue), wp.spawnCallbackWorker, wp.callbackRetire)
+}
+
+// resize starts or retires workers of one queue and returns the new count
+func (wp *WorkerPool) resize(kind string, workers, target, queued int, spawn func(), retireCh chan struct{}) int {
+	switch {
+	case target > workers:
+		for i := workers; i < target; i++ {
+			spawn()
+		}
+		wp.scaleUps++
+	case target < workers:
+		target = workers - retire(retireCh, workers-target)
+		if target == workers {
+			return workers
+		}
+		wp.scaleDowns++
+	default:
+		return workers
+	}
+
+	logger.Info("Worker pool scaled", map[string]interface{}{
+		"kind":       kind,
+		"from":       workers,
+		"to":         target,
+		"queue_size": queued,
+	})
+	return target
+}
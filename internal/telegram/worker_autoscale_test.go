@@ -0,0 +1,109 @@
+package telegram
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScaleTarget(t *testing.T) {
+	cfg := WorkerPoolConfig{
+		ScaleUpQueueDepth: 10,
+		TargetLatency:     2 * time.Second,
+		ScaleDownDelay:    time.Minute,
+	}
+
+	tests := []struct {
+		name    string
+		workers int
+		depth   int
+		p95     time.Duration
+		idleFor time.Duration
+		want    int
+	}{
+		{"deep queue grows by a quarter", 20, 10, 0, 0, 25},
+		{"slow queue grows", 20, 1, 3 * time.Second, 0, 25},
+		{"small fast queue stays", 20, 1, time.Second, 0, 20},
+		{"slow tasks without a queue stay", 20, 0, 10 * time.Second, 0, 20},
+		{"growth capped at max", 38, 50, 0, 0, 40},
+		{"at max stays", 40, 50, 0, 0, 40},
+		{"few workers grow by one", 2, 10, 0, 0, 3},
+		{"recently busy stays", 20, 0, 0, 30 * time.Second, 20},
+		{"idle shrinks by a tenth", 20, 0, 0, time.Minute, 18},
+		{"shrink floored at min", 6, 0, 0, time.Hour, 5},
+		{"at min stays", 5, 0, 0, time.Hour, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scaleTarget(tt.workers, 5, 40, tt.depth, tt.p95, tt.idleFor, cfg)
+			if got != tt.want {
+				t.Errorf("scaleTarget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkerBounds(t *testing.T) {
+	tests := []struct {
+		initial, min, max int
+		wantMin, wantMax  int
+	}{
+		{10, 5, 20, 5, 20},
+		{10, 0, 0, 10, 10},   // Unset bounds keep the pool static
+		{10, 15, 20, 10, 20}, // Min above the initial count
+		{10, 5, 8, 5, 10},    // Max below the initial count
+	}
+
+	for _, tt := range tests {
+		min, max := workerBounds(tt.initial, tt.min, tt.max)
+		if min != tt.wantMin || max != tt.wantMax {
+			t.Errorf("workerBounds(%d, %d, %d) = %d, %d, want %d, %d", tt.initial, tt.min, tt.max, min, max, tt.wantMin, tt.wantMax)
+		}
+	}
+}
+
+func TestLatencyWindow(t *testing.T) {
+	w := newLatencyWindow()
+	if p50, p95 := w.percentiles(); p50 != 0 || p95 != 0 {
+		t.Errorf("empty window percentiles = %v, %v", p50, p95)
+	}
+
+	for i := 1; i <= 100; i++ {
+		w.add(time.Duration(i) * time.Millisecond)
+	}
+	p50, p95 := w.percentiles()
+	if p50 != 51*time.Millisecond || p95 != 96*time.Millisecond {
+		t.Errorf("percentiles() = %v, %v, want 51ms, 96ms", p50, p95)
+	}
+
+	// The window keeps only the latest samples
+	for i := 0; i < latencyWindowSize; i++ {
+		w.add(time.Second)
+	}
+	if p50, _ := w.percentiles(); p50 != time.Second {
+		t.Errorf("percentiles() after overflow p50 = %v, want 1s", p50)
+	}
+}
+
+func TestRetire(t *testing.T) {
+	retireCh := make(chan struct{})
+	if got := retire(retireCh, 3); got != 0 {
+		t.Errorf("retire() without idle workers = %d, want 0", got)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		<-retireCh
+		close(done)
+	}()
+	// Wait for the idle worker to be ready to receive
+	deadline := time.Now().Add(time.Second)
+	retired := 0
+	for retired == 0 && time.Now().Before(deadline) {
+		retired = retire(retireCh, 3)
+	}
+	if retired != 1 {
+		t.Errorf("retire() with one idle worker = %d, want 1", retired)
+	}
+	<-done
+}
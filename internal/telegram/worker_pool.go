@@ -4,20 +4,36 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/metrics"
+	"github.com/msg2git/msg2git/internal/watchdog"
 )
 
 // WorkerPool manages concurrent processing of messages and callbacks
 type WorkerPool struct {
 	bot                 *Bot
-	messageQueue        chan *tgbotapi.Message
-	callbackQueue       chan *tgbotapi.CallbackQuery
+	messageQueue        chan queuedMessage
+	callbackQueue       chan queuedCallback
 	messageWorkerCount  int
 	callbackWorkerCount int
 
+	// Autoscaling (implemented in worker_autoscale.go)
+	config          WorkerPoolConfig
+	messageRetire   chan struct{} // An idle message worker receiving from it exits
+	callbackRetire  chan struct{}
+	messageLatency  *latencyWindow
+	callbackLatency *latencyWindow
+	nextWorkerID    int
+	scaleUps        int
+	scaleDowns      int
+
+	// Unix nanoseconds a full queue last dropped a task, reported by /status (see status.go)
+	lastQueueFull atomic.Int64
+
 	// Concurrency control
 	maxConcurrentOps int
 	opSemaphore      chan struct{}
@@ -37,6 +53,17 @@ type WorkerPoolConfig struct {
 	MessageQueueSize  int // Size of message queue buffer
 	CallbackQueueSize int // Size of callback queue buffer
 	MaxConcurrentOps  int // Maximum concurrent operations (GitHub/LLM calls)
+
+	// Autoscaling between min and max workers, disabled if ScaleInterval is 0. The worker counts
+	// above are the initial counts.
+	MinMessageWorkers  int
+	MaxMessageWorkers  int
+	MinCallbackWorkers int
+	MaxCallbackWorkers int
+	ScaleInterval      time.Duration // How often queue depth and latency are checked
+	ScaleUpQueueDepth  int           // Queued tasks that add workers regardless of latency
+	TargetLatency      time.Duration // p95 latency from submission to completion a backed up queue should stay under
+	ScaleDownDelay     time.Duration // How long a queue must be idle before workers are retired
 }
 
 // DefaultWorkerPoolConfig returns a sensible default configuration
@@ -47,19 +74,35 @@ func DefaultWorkerPoolConfig() WorkerPoolConfig {
 		MessageQueueSize:  200, // Buffer up to 100 messages
 		CallbackQueueSize: 100, // Buffer up to 50 callbacks
 		MaxConcurrentOps:  20,  // Max 10 concurrent GitHub/LLM operations
+
+		MinMessageWorkers:  5,
+		MaxMessageWorkers:  100,
+		MinCallbackWorkers: 3,
+		MaxCallbackWorkers: 80,
+		ScaleInterval:      5 * time.Second,
+		ScaleUpQueueDepth:  10,
+		TargetLatency:      2 * time.Second,
+		ScaleDownDelay:     2 * time.Minute,
 	}
 }
 
 // NewWorkerPool creates a new worker pool
 func NewWorkerPool(bot *Bot, config WorkerPoolConfig) *WorkerPool {
 	ctx, cancel := context.WithCancel(context.Background())
+	config.MinMessageWorkers, config.MaxMessageWorkers = workerBounds(config.MessageWorkers, config.MinMessageWorkers, config.MaxMessageWorkers)
+	config.MinCallbackWorkers, config.MaxCallbackWorkers = workerBounds(config.CallbackWorkers, config.MinCallbackWorkers, config.MaxCallbackWorkers)
 
 	return &WorkerPool{
 		bot:                 bot,
-		messageQueue:        make(chan *tgbotapi.Message, config.MessageQueueSize),
-		callbackQueue:       make(chan *tgbotapi.CallbackQuery, config.CallbackQueueSize),
+		messageQueue:        make(chan queuedMessage, config.MessageQueueSize),
+		callbackQueue:       make(chan queuedCallback, config.CallbackQueueSize),
 		messageWorkerCount:  config.MessageWorkers,
 		callbackWorkerCount: config.CallbackWorkers,
+		config:              config,
+		messageRetire:       make(chan struct{}),
+		callbackRetire:      make(chan struct{}),
+		messageLatency:      newLatencyWindow(),
+		callbackLatency:     newLatencyWindow(),
 		maxConcurrentOps:    config.MaxConcurrentOps,
 		opSemaphore:         make(chan struct{}, config.MaxConcurrentOps),
 		ctx:                 ctx,
@@ -87,14 +130,17 @@ func (wp *WorkerPool) Start() error {
 
 	// Start message workers
 	for i := 0; i < wp.messageWorkerCount; i++ {
-		wp.wg.Add(1)
-		go wp.messageWorker(i)
+		wp.spawnMessageWorker()
 	}
 
 	// Start callback workers
 	for i := 0; i < wp.callbackWorkerCount; i++ {
-		wp.wg.Add(1)
-		go wp.callbackWorker(i)
+		wp.spawnCallbackWorker()
+	}
+
+	// Adjust the worker counts to the load (implemented in worker_autoscale.go)
+	if wp.config.ScaleInterval > 0 {
+		go wp.autoscale()
 	}
 
 	wp.started = true
@@ -149,10 +195,12 @@ func (wp *WorkerPool) SubmitMessage(message *tgbotapi.Message) error {
 	}
 
 	select {
-	case wp.messageQueue <- message:
+	case wp.messageQueue <- queuedMessage{message: message, queuedAt: time.Now()}:
+		metrics.Default.RecordQueuedRequest("message", "queued")
+		metrics.Default.UpdateQueueDepth("message", len(wp.messageQueue))
 		logger.Debug("Message queued for processing", map[string]interface{}{
 			"chat_id":    message.Chat.ID,
-			"username":   message.From.UserName,
+			"username":   senderUsername(message),
 			"queue_size": len(wp.messageQueue),
 		})
 		return nil
@@ -160,9 +208,11 @@ func (wp *WorkerPool) SubmitMessage(message *tgbotapi.Message) error {
 		return fmt.Errorf("worker pool is shutting down")
 	default:
 		// Queue is full
+		wp.lastQueueFull.Store(time.Now().UnixNano())
+		metrics.Default.RecordQueuedRequest("message", "dropped")
 		logger.Warn("Message queue full, dropping message", map[string]interface{}{
 			"chat_id":  message.Chat.ID,
-			"username": message.From.UserName,
+			"username": senderUsername(message),
 		})
 		return fmt.Errorf("message queue full")
 	}
@@ -178,7 +228,9 @@ func (wp *WorkerPool) SubmitCallback(callback *tgbotapi.CallbackQuery) error {
 	}
 
 	select {
-	case wp.callbackQueue <- callback:
+	case wp.callbackQueue <- queuedCallback{callback: callback, queuedAt: time.Now()}:
+		metrics.Default.RecordQueuedRequest("callback", "queued")
+		metrics.Default.UpdateQueueDepth("callback", len(wp.callbackQueue))
 		logger.Debug("Callback queued for processing", map[string]interface{}{
 			"chat_id":       callback.Message.Chat.ID,
 			"callback_id":   callback.ID,
@@ -190,6 +242,8 @@ func (wp *WorkerPool) SubmitCallback(callback *tgbotapi.CallbackQuery) error {
 		return fmt.Errorf("worker pool is shutting down")
 	default:
 		// Queue is full
+		wp.lastQueueFull.Store(time.Now().UnixNano())
+		metrics.Default.RecordQueuedRequest("callback", "dropped")
 		logger.Warn("Callback queue full, dropping callback", map[string]interface{}{
 			"chat_id":     callback.Message.Chat.ID,
 			"callback_id": callback.ID,
@@ -216,7 +270,7 @@ func (wp *WorkerPool) messageWorker(workerID int) {
 
 	for {
 		select {
-		case message, ok := <-wp.messageQueue:
+		case queued, ok := <-wp.messageQueue:
 			if !ok {
 				// Queue closed, worker should exit
 				logger.Debug("Message worker stopping", map[string]interface{}{
@@ -225,7 +279,17 @@ func (wp *WorkerPool) messageWorker(workerID int) {
 				return
 			}
 
-			wp.processMessageWithConcurrencyControl(message, workerID)
+			wp.processMessageWithConcurrencyControl(queued.message, workerID)
+			wp.messageLatency.add(time.Since(queued.queuedAt))
+			metrics.Default.RecordQueueProcessingTime("message", time.Since(queued.queuedAt))
+			metrics.Default.UpdateQueueDepth("message", len(wp.messageQueue))
+
+		case <-wp.messageRetire:
+			// Retired by the autoscaler
+			logger.Debug("Message worker retired", map[string]interface{}{
+				"worker_id": workerID,
+			})
+			return
 
 		case <-wp.ctx.Done():
 			// Context cancelled, worker should exit
@@ -255,7 +319,7 @@ func (wp *WorkerPool) callbackWorker(workerID int) {
 
 	for {
 		select {
-		case callback, ok := <-wp.callbackQueue:
+		case queued, ok := <-wp.callbackQueue:
 			if !ok {
 				// Queue closed, worker should exit
 				logger.Debug("Callback worker stopping", map[string]interface{}{
@@ -264,7 +328,17 @@ func (wp *WorkerPool) callbackWorker(workerID int) {
 				return
 			}
 
-			wp.processCallbackWithConcurrencyControl(callback, workerID)
+			wp.processCallbackWithConcurrencyControl(queued.callback, workerID)
+			wp.callbackLatency.add(time.Since(queued.queuedAt))
+			metrics.Default.RecordQueueProcessingTime("callback", time.Since(queued.queuedAt))
+			metrics.Default.UpdateQueueDepth("callback", len(wp.callbackQueue))
+
+		case <-wp.callbackRetire:
+			// Retired by the autoscaler
+			logger.Debug("Callback worker retired", map[string]interface{}{
+				"worker_id": workerID,
+			})
+			return
 
 		case <-wp.ctx.Done():
 			// Context cancelled, worker should exit
@@ -287,24 +361,31 @@ func (wp *WorkerPool) processMessageWithConcurrencyControl(message *tgbotapi.Mes
 	}
 
 	startTime := time.Now()
+	correlationID, end := watchdog.Begin(message.Chat.ID)
+	defer end()
 
 	logger.Debug("Processing message", map[string]interface{}{
-		"worker_id": workerID,
-		"chat_id":   message.Chat.ID,
-		"username":  message.From.UserName,
+		"worker_id":      workerID,
+		"chat_id":        message.Chat.ID,
+		"username":       senderUsername(message),
+		"correlation_id": correlationID,
 	})
 
-	if err := wp.bot.handleMessage(message); err != nil {
+	err := wp.bot.handleMessage(message)
+	if err != nil {
 		logger.Error("Error processing message", map[string]interface{}{
-			"worker_id": workerID,
-			"error":     err.Error(),
-			"chat_id":   message.Chat.ID,
-			"username":  message.From.UserName,
+			"worker_id":      workerID,
+			"error":          err.Error(),
+			"chat_id":        message.Chat.ID,
+			"username":       senderUsername(message),
+			"correlation_id": correlationID,
 		})
 		wp.bot.sendErrorResponse(message.Chat.ID, err)
 	}
 
 	duration := time.Since(startTime)
+	watchdog.Observe(watchdog.Handler, messageHandlerName(message), message.Chat.ID, duration)
+	recordHandlerMetrics(message.Chat.ID, messageHandlerName(message), err, duration)
 	logger.Debug("Message processed", map[string]interface{}{
 		"worker_id": workerID,
 		"chat_id":   message.Chat.ID,
@@ -323,25 +404,32 @@ func (wp *WorkerPool) processCallbackWithConcurrencyControl(callback *tgbotapi.C
 	}
 
 	startTime := time.Now()
+	correlationID, end := watchdog.Begin(callback.Message.Chat.ID)
+	defer end()
 
 	logger.Debug("Processing callback", map[string]interface{}{
-		"worker_id":     workerID,
-		"chat_id":       callback.Message.Chat.ID,
-		"callback_id":   callback.ID,
-		"callback_data": callback.Data,
+		"worker_id":      workerID,
+		"chat_id":        callback.Message.Chat.ID,
+		"callback_id":    callback.ID,
+		"callback_data":  callback.Data,
+		"correlation_id": correlationID,
 	})
 
-	if err := wp.bot.handleCallbackQuery(callback); err != nil {
+	err := wp.bot.handleCallbackQuery(callback)
+	if err != nil {
 		logger.Error("Error processing callback", map[string]interface{}{
-			"worker_id":   workerID,
-			"error":       err.Error(),
-			"chat_id":     callback.Message.Chat.ID,
-			"callback_id": callback.ID,
+			"worker_id":      workerID,
+			"error":          err.Error(),
+			"chat_id":        callback.Message.Chat.ID,
+			"callback_id":    callback.ID,
+			"correlation_id": correlationID,
 		})
 		wp.bot.sendErrorResponse(callback.Message.Chat.ID, err)
 	}
 
 	duration := time.Since(startTime)
+	watchdog.Observe(watchdog.Handler, callbackHandlerName(callback), callback.Message.Chat.ID, duration)
+	recordHandlerMetrics(callback.Message.Chat.ID, callbackHandlerName(callback), err, duration)
 	logger.Debug("Callback processed", map[string]interface{}{
 		"worker_id": workerID,
 		"chat_id":   callback.Message.Chat.ID,
@@ -354,6 +442,9 @@ func (wp *WorkerPool) GetStats() map[string]interface{} {
 	wp.mu.RLock()
 	defer wp.mu.RUnlock()
 
+	messageP50, messageP95 := wp.messageLatency.percentiles()
+	callbackP50, callbackP95 := wp.callbackLatency.percentiles()
+
 	return map[string]interface{}{
 		"started":                 wp.started,
 		"message_queue_size":      len(wp.messageQueue),
@@ -364,6 +455,32 @@ func (wp *WorkerPool) GetStats() map[string]interface{} {
 		"max_concurrent_ops":      wp.maxConcurrentOps,
 		"message_workers":         wp.messageWorkerCount,
 		"callback_workers":        wp.callbackWorkerCount,
+		"autoscaling":             wp.config.ScaleInterval > 0,
+		"message_workers_min":     wp.config.MinMessageWorkers,
+		"message_workers_max":     wp.config.MaxMessageWorkers,
+		"callback_workers_min":    wp.config.MinCallbackWorkers,
+		"callback_workers_max":    wp.config.MaxCallbackWorkers,
+		"message_latency_p50_ms":  messageP50.Milliseconds(),
+		"message_latency_p95_ms":  messageP95.Milliseconds(),
+		"callback_latency_p50_ms": callbackP50.Milliseconds(),
+		"callback_latency_p95_ms": callbackP95.Milliseconds(),
+		"scale_ups":               wp.scaleUps,
+		"scale_downs":             wp.scaleDowns,
 	}
 }
 
+
+// recordHandlerMetrics records a handled command, message or callback of a chat in the metrics
+func recordHandlerMetrics(chatID int64, name string, err error, duration time.Duration) {
+	status := metrics.Status(err)
+	metrics.Default.RecordTelegramCommand(chatID, name, status)
+	metrics.Default.RecordCommandProcessingTime(name, status, duration)
+}
+
+// senderUsername returns the username of a message's sender, "" for channel posts which have none
+func senderUsername(message *tgbotapi.Message) string {
+	if message.From == nil {
+		return ""
+	}
+	return message.From.UserName
+}
@@ -0,0 +1,1094 @@
+package testutil
+
+import (
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeGitHub is an in-memory GitHub API (contents, branches, Git Data, issues, labels, releases,
+// statuses, code search, rate limits and GraphQL issue lookups) served over httptest. Point the github package at it with
+// github.SetAPIBaseURLs(fake.URL(), fake.URL()).
+type FakeGitHub struct {
+	Server *httptest.Server
+
+	// Token, when set, is the only token accepted, any other gets 401 Bad credentials
+	Token string
+
+	// LargeFileSize, when set, is the size from which the contents API leaves out the content of
+	// files, like GitHub does for files over 1 MB
+	LargeFileSize int
+
+	// GraphQLUnavailable makes /graphql answer 502, as during a GraphQL outage
+	GraphQLUnavailable bool
+	// GraphQLMaxAliases, when set, rejects queries with more issue aliases as too complex
+	GraphQLMaxAliases int
+	// GraphQLCost is the points a GraphQL query costs once SetGraphQLRateLimit was called, 1 if unset
+	GraphQLCost int
+
+	mu               sync.Mutex
+	repos            map[string]*FakeRepo
+	requests         []RecordedRequest
+	nextID           int
+	graphQLLimited   bool
+	graphQLRemaining int
+	graphQLReset     time.Time
+}
+
+// fakeRateLimit is the budget of each rate limit resource of a FakeGitHub
+const fakeRateLimit = 5000
+
+// FakeRepo is the state of one repository on a FakeGitHub
+type FakeRepo struct {
+	Owner         string
+	Name          string
+	DefaultBranch string
+	Branches      []string          // Branches besides DefaultBranch, they share its files
+	Files         map[string]string // path -> content
+	Issues        []*FakeIssue
+	Releases      []*FakeRelease
+	Commits       []FakeCommit
+	Statuses      map[string][]string // sha -> states
+	Labels        []string            // Labels defined in the repository
+
+	blobs   map[string]string            // sha -> content of blobs created through the Git Data API
+	trees   map[string]fakeTree          // sha -> trees created through the Git Data API
+	pending map[string]fakePendingCommit // sha -> commits created but not yet on a branch
+}
+
+// fakeTree is a tree created on top of the tree of a commit, changes maps paths to their new
+// content, nil deletes the file
+type fakeTree struct {
+	base    string
+	changes map[string]*string
+}
+
+// fakePendingCommit is a commit created through the Git Data API, applied when a ref moves to it
+type fakePendingCommit struct {
+	message string
+	tree    string
+	parent  string
+}
+
+// FakeIssue is an issue on a FakeRepo, State is "open" or "closed"
+type FakeIssue struct {
+	ID       int
+	Number   int
+	Title    string
+	Body     string
+	State    string
+	ClosedAt time.Time // zero while open
+	Comments []string
+	Labels   []string
+}
+
+// FakeRelease is a release on a FakeRepo
+type FakeRelease struct {
+	ID      int
+	TagName string
+	Name    string
+	Assets  []FakeAsset
+}
+
+// FakeAsset is a file uploaded to a FakeRelease
+type FakeAsset struct {
+	ID   int
+	Name string
+	Data []byte
+}
+
+// FakeCommit is a commit made through the contents API
+type FakeCommit struct {
+	SHA     string
+	Message string
+	Date    time.Time
+}
+
+// RecordedRequest is a request received by a fake server
+type RecordedRequest struct {
+	Method string
+	Path   string
+	Query  string
+	Body   []byte
+}
+
+// NewFakeGitHub starts a FakeGitHub that is closed when the test finishes
+func NewFakeGitHub(t testing.TB) *FakeGitHub {
+	t.Helper()
+
+	f := &FakeGitHub{
+		repos: make(map[string]*FakeRepo),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Server.Close)
+
+	return f
+}
+
+// URL returns the base URL of the fake API
+func (f *FakeGitHub) URL() string {
+	return f.Server.URL
+}
+
+// AddRepo creates an empty repository on the "main" branch, requests for unknown repositories get 404
+func (f *FakeGitHub) AddRepo(owner, name string) *FakeRepo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	repo := &FakeRepo{
+		Owner:         owner,
+		Name:          name,
+		DefaultBranch: "main",
+		Files:         make(map[string]string),
+		Statuses:      make(map[string][]string),
+		blobs:         make(map[string]string),
+		trees:         make(map[string]fakeTree),
+		pending:       make(map[string]fakePendingCommit),
+	}
+	f.repos[owner+"/"+name] = repo
+	return repo
+}
+
+// Repo returns a repository added with AddRepo, or nil
+func (f *FakeGitHub) Repo(owner, name string) *FakeRepo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.repos[owner+"/"+name]
+}
+
+// SetFile writes a file without recording a commit
+func (f *FakeGitHub) SetFile(owner, name, path, content string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if repo := f.repos[owner+"/"+name]; repo != nil {
+		repo.Files[path] = content
+	}
+}
+
+// File returns a file's content and whether it exists
+func (f *FakeGitHub) File(owner, name, path string) (string, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo := f.repos[owner+"/"+name]
+	if repo == nil {
+		return "", false
+	}
+	content, ok := repo.Files[path]
+	return content, ok
+}
+
+// AddIssue creates an issue directly, e.g. to be synced or closed by the code under test
+func (f *FakeGitHub) AddIssue(owner, name, title, state string) *FakeIssue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo := f.repos[owner+"/"+name]
+	if repo == nil {
+		return nil
+	}
+	return f.newIssue(repo, title, "", state)
+}
+
+// Issue returns a copy of an issue, or nil
+func (f *FakeGitHub) Issue(owner, name string, number int) *FakeIssue {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	repo := f.repos[owner+"/"+name]
+	if repo == nil {
+		return nil
+	}
+	if issue := repo.issue(number); issue != nil {
+		copied := *issue
+		copied.Comments = append([]string(nil), issue.Comments...)
+		copied.Labels = append([]string(nil), issue.Labels...)
+		return &copied
+	}
+	return nil
+}
+
+// SetGraphQLRateLimit limits the GraphQL API to remaining more queries, reported in
+// X-RateLimit-Remaining; once exhausted, queries get 403 with a rate limit error
+func (f *FakeGitHub) SetGraphQLRateLimit(remaining int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.graphQLLimited = true
+	f.graphQLRemaining = remaining
+	f.graphQLReset = time.Now().Add(time.Hour).Truncate(time.Second)
+}
+
+// Requests returns the requests received so far
+func (f *FakeGitHub) Requests() []RecordedRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]RecordedRequest(nil), f.requests...)
+}
+
+func (f *FakeGitHub) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests = append(f.requests, RecordedRequest{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery, Body: body})
+
+	if f.Token != "" && !strings.HasSuffix(r.Header.Get("Authorization"), " "+f.Token) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"message": "Bad credentials"})
+		return
+	}
+
+	switch {
+	case r.URL.Path == "/user":
+		writeJSON(w, http.StatusOK, map[string]interface{}{"login": "fake-user", "id": 1})
+	case r.URL.Path == "/graphql" && r.Method == http.MethodPost:
+		f.serveGraphQL(w, body)
+	case r.URL.Path == "/search/code":
+		f.serveCodeSearch(w, r)
+	case r.URL.Path == "/rate_limit":
+		f.serveRateLimit(w)
+	case strings.HasPrefix(r.URL.Path, "/repos/"):
+		f.serveRepo(w, r, body)
+	default:
+		notFound(w)
+	}
+}
+
+func (f *FakeGitHub) serveRepo(w http.ResponseWriter, r *http.Request, body []byte) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/repos/"), "/", 4)
+	if len(parts) < 2 {
+		notFound(w)
+		return
+	}
+
+	repo := f.repos[parts[0]+"/"+parts[1]]
+	if repo == nil {
+		notFound(w)
+		return
+	}
+
+	if len(parts) == 2 {
+		f.serveRepoInfo(w, repo)
+		return
+	}
+
+	rest := ""
+	if len(parts) == 4 {
+		rest = parts[3]
+	}
+
+	switch parts[2] {
+	case "contents":
+		f.serveContents(w, r, repo, strings.Trim(rest, "/"), body)
+	case "issues":
+		f.serveIssues(w, r, repo, rest, body)
+	case "releases":
+		f.serveReleases(w, r, repo, rest, body)
+	case "labels":
+		labels := []map[string]interface{}{}
+		for _, name := range repo.Labels {
+			labels = append(labels, map[string]interface{}{"name": name})
+		}
+		writeJSON(w, http.StatusOK, paginate(w, r, labels))
+	case "statuses":
+		repo.Statuses[rest] = append(repo.Statuses[rest], jsonField(body, "state"))
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"id": f.id(), "state": jsonField(body, "state")})
+	case "commits":
+		f.serveCommits(w, repo)
+	case "branches":
+		if !repo.hasBranch(rest) {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"name": rest, "commit": map[string]string{"sha": repo.headSHA()}})
+	case "git":
+		f.serveGit(w, r, repo, rest, body)
+	default:
+		notFound(w)
+	}
+}
+
+// serveGit serves the Git Data API. Trees record changes to the tree of the head, which is the only
+// commit readable here, and the changes apply when a branch ref moves to a commit of the tree.
+func (f *FakeGitHub) serveGit(w http.ResponseWriter, r *http.Request, repo *FakeRepo, rest string, body []byte) {
+	kind, name, _ := strings.Cut(rest, "/")
+	if kind == "refs" && name == "" && r.Method == http.MethodPost {
+		branch := strings.TrimPrefix(jsonField(body, "ref"), "refs/heads/")
+		if repo.hasBranch(branch) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Reference already exists"})
+			return
+		}
+		repo.Branches = append(repo.Branches, branch)
+		writeJSON(w, http.StatusCreated, map[string]interface{}{"ref": "refs/heads/" + branch, "object": map[string]string{"sha": jsonField(body, "sha")}})
+		return
+	}
+	if len(repo.Commits) == 0 && len(repo.Files) == 0 {
+		writeJSON(w, http.StatusConflict, map[string]string{"message": "Git Repository is empty."})
+		return
+	}
+
+	switch {
+	case kind == "ref" && r.Method == http.MethodGet:
+		branch := strings.TrimPrefix(name, "heads/")
+		if !repo.hasBranch(branch) {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"ref": "refs/heads/" + branch, "object": map[string]string{"sha": repo.headSHA(), "type": "commit"}})
+
+	case kind == "refs" && r.Method == http.MethodPatch:
+		branch := strings.TrimPrefix(name, "heads/")
+		sha := jsonField(body, "sha")
+		commit, ok := repo.pending[sha]
+		switch {
+		case !repo.hasBranch(branch):
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Reference does not exist"})
+			return
+		case !ok:
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Object does not exist"})
+			return
+		case commit.parent != repo.headSHA():
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Update is not a fast forward"})
+			return
+		}
+		for path, content := range repo.trees[commit.tree].changes {
+			if content == nil {
+				delete(repo.Files, path)
+			} else {
+				repo.Files[path] = *content
+			}
+		}
+		delete(repo.pending, sha)
+		repo.Commits = append(repo.Commits, FakeCommit{SHA: sha, Message: commit.message, Date: time.Now()})
+		writeJSON(w, http.StatusOK, map[string]interface{}{"ref": "refs/heads/" + branch, "object": map[string]string{"sha": sha, "type": "commit"}})
+
+	case kind == "blobs" && name == "" && r.Method == http.MethodPost:
+		content := jsonField(body, "content")
+		if jsonField(body, "encoding") == "base64" {
+			decoded, err := base64.StdEncoding.DecodeString(content)
+			if err != nil {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "content is not valid Base64"})
+				return
+			}
+			content = string(decoded)
+		}
+		sha := blobSHA(content)
+		repo.blobs[sha] = content
+		writeJSON(w, http.StatusCreated, map[string]string{"sha": sha})
+
+	case kind == "blobs" && r.Method == http.MethodGet:
+		content, ok := repo.blobs[name]
+		for _, file := range repo.Files {
+			if !ok && blobSHA(file) == name {
+				content, ok = file, true
+			}
+		}
+		if !ok {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"sha":      name,
+			"size":     len(content),
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte(content)),
+		})
+
+	case kind == "commits" && r.Method == http.MethodGet:
+		if name != repo.headSHA() {
+			notFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"sha": name, "tree": map[string]string{"sha": treeSHA(name)}})
+
+	case kind == "trees" && name == "" && r.Method == http.MethodPost:
+		var req struct {
+			BaseTree string `json:"base_tree"`
+			Tree     []struct {
+				Path string  `json:"path"`
+				Mode string  `json:"mode"`
+				Type string  `json:"type"`
+				SHA  *string `json:"sha"`
+			} `json:"tree"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+			return
+		}
+		if req.BaseTree != treeSHA(repo.headSHA()) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "base_tree is not the tree of the head"})
+			return
+		}
+		tree := fakeTree{base: req.BaseTree, changes: make(map[string]*string)}
+		for _, entry := range req.Tree {
+			if entry.SHA == nil {
+				if _, exists := repo.Files[entry.Path]; !exists {
+					writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "GitRPC::BadObjectState"})
+					return
+				}
+				tree.changes[entry.Path] = nil
+				continue
+			}
+			content, ok := repo.blobs[*entry.SHA]
+			if !ok || entry.Mode != "100644" || entry.Type != "blob" {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "tree.sha " + *entry.SHA + " is not a valid blob"})
+				return
+			}
+			tree.changes[entry.Path] = &content
+		}
+		sha := fmt.Sprintf("%040x", f.id())
+		repo.trees[sha] = tree
+		writeJSON(w, http.StatusCreated, map[string]string{"sha": sha})
+
+	case kind == "commits" && name == "" && r.Method == http.MethodPost:
+		var req struct {
+			Message string   `json:"message"`
+			Tree    string   `json:"tree"`
+			Parents []string `json:"parents"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+			return
+		}
+		tree, ok := repo.trees[req.Tree]
+		if !ok || len(req.Parents) != 1 || tree.base != treeSHA(req.Parents[0]) {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Tree or parents are invalid"})
+			return
+		}
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s:%s:%s", req.Parents[0], req.Tree, req.Message)))
+		sha := hex.EncodeToString(sum[:])
+		repo.pending[sha] = fakePendingCommit{message: req.Message, tree: req.Tree, parent: req.Parents[0]}
+		writeJSON(w, http.StatusCreated, map[string]string{"sha": sha, "html_url": repo.htmlURL() + "/commit/" + sha})
+
+	default:
+		notFound(w)
+	}
+}
+
+func (f *FakeGitHub) serveRepoInfo(w http.ResponseWriter, repo *FakeRepo) {
+	size := 0
+	for _, content := range repo.Files {
+		size += len(content)
+	}
+	for _, release := range repo.Releases {
+		for _, asset := range release.Assets {
+			size += len(asset.Data)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":             1,
+		"name":           repo.Name,
+		"full_name":      repo.Owner + "/" + repo.Name,
+		"size":           (size + 1023) / 1024,
+		"default_branch": repo.DefaultBranch,
+		"private":        false,
+		"html_url":       repo.htmlURL(),
+	})
+}
+
+func (f *FakeGitHub) serveContents(w http.ResponseWriter, r *http.Request, repo *FakeRepo, path string, body []byte) {
+	switch r.Method {
+	case http.MethodGet:
+		if content, ok := repo.Files[path]; ok {
+			if r.Header.Get("Accept") == "application/vnd.github.raw" {
+				w.WriteHeader(http.StatusOK)
+				io.WriteString(w, content)
+				return
+			}
+			file := repo.fileJSON(path, content, true)
+			if f.LargeFileSize > 0 && len(content) >= f.LargeFileSize {
+				file["content"] = ""
+				file["encoding"] = "none"
+			}
+			writeJSON(w, http.StatusOK, file)
+			return
+		}
+		if entries := repo.list(path); len(entries) > 0 {
+			writeJSON(w, http.StatusOK, entries)
+			return
+		}
+		notFound(w)
+
+	case http.MethodPut:
+		var req struct {
+			Message string `json:"message"`
+			Content string `json:"content"`
+			SHA     string `json:"sha"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+			return
+		}
+		decoded, err := base64.StdEncoding.DecodeString(req.Content)
+		if err != nil {
+			writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "content is not valid Base64"})
+			return
+		}
+
+		existing, exists := repo.Files[path]
+		if exists && req.SHA != blobSHA(existing) {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": fmt.Sprintf("%s does not match", req.SHA)})
+			return
+		}
+
+		repo.Files[path] = string(decoded)
+		commit := repo.commit(req.Message)
+		status := http.StatusCreated
+		if exists {
+			status = http.StatusOK
+		}
+		writeJSON(w, status, map[string]interface{}{
+			"content": repo.fileJSON(path, string(decoded), false),
+			"commit":  map[string]string{"sha": commit.SHA, "html_url": repo.htmlURL() + "/commit/" + commit.SHA},
+		})
+
+	case http.MethodDelete:
+		existing, exists := repo.Files[path]
+		if !exists {
+			notFound(w)
+			return
+		}
+		if sha := jsonField(body, "sha"); sha != blobSHA(existing) {
+			writeJSON(w, http.StatusConflict, map[string]string{"message": fmt.Sprintf("%s does not match", sha)})
+			return
+		}
+
+		delete(repo.Files, path)
+		commit := repo.commit(jsonField(body, "message"))
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"content": nil,
+			"commit":  map[string]string{"sha": commit.SHA, "html_url": repo.htmlURL() + "/commit/" + commit.SHA},
+		})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *FakeGitHub) serveIssues(w http.ResponseWriter, r *http.Request, repo *FakeRepo, rest string, body []byte) {
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			state := r.URL.Query().Get("state")
+			if state == "" {
+				state = "open"
+			}
+			var labels []string
+			if filter := r.URL.Query().Get("labels"); filter != "" {
+				labels = strings.Split(filter, ",")
+			}
+			issues := []map[string]interface{}{}
+			for i := len(repo.Issues) - 1; i >= 0; i-- {
+				if (state == "all" || repo.Issues[i].State == state) && repo.Issues[i].hasLabels(labels) {
+					issues = append(issues, repo.issueJSON(repo.Issues[i]))
+				}
+			}
+			writeJSON(w, http.StatusOK, paginate(w, r, issues))
+		case http.MethodPost:
+			var req struct {
+				Title  string   `json:"title"`
+				Body   string   `json:"body"`
+				Labels []string `json:"labels"`
+			}
+			if err := json.Unmarshal(body, &req); err != nil || req.Title == "" {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+				return
+			}
+			issue := f.newIssue(repo, req.Title, req.Body, "open")
+			issue.Labels = req.Labels
+			writeJSON(w, http.StatusCreated, repo.issueJSON(issue))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	numberPart, sub, _ := strings.Cut(rest, "/")
+	number, err := strconv.Atoi(numberPart)
+	issue := repo.issue(number)
+	if err != nil || issue == nil {
+		notFound(w)
+		return
+	}
+
+	switch {
+	case sub == "" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, repo.issueJSON(issue))
+	case sub == "" && r.Method == http.MethodPatch:
+		if state := jsonField(body, "state"); state != "" {
+			issue.setState(state)
+		}
+		if title := jsonField(body, "title"); title != "" {
+			issue.Title = title
+		}
+		writeJSON(w, http.StatusOK, repo.issueJSON(issue))
+	case sub == "comments" && r.Method == http.MethodPost:
+		issue.Comments = append(issue.Comments, jsonField(body, "body"))
+		id := f.id()
+		writeJSON(w, http.StatusCreated, map[string]interface{}{
+			"id":       id,
+			"html_url": fmt.Sprintf("%s/issues/%d#issuecomment-%d", repo.htmlURL(), issue.Number, id),
+		})
+	default:
+		notFound(w)
+	}
+}
+
+func (f *FakeGitHub) serveReleases(w http.ResponseWriter, r *http.Request, repo *FakeRepo, rest string, body []byte) {
+	if rest == "" {
+		switch r.Method {
+		case http.MethodGet:
+			releases := []map[string]interface{}{}
+			for i := len(repo.Releases) - 1; i >= 0; i-- {
+				releases = append(releases, f.releaseJSON(repo, repo.Releases[i]))
+			}
+			writeJSON(w, http.StatusOK, releases)
+		case http.MethodPost:
+			release := &FakeRelease{ID: f.id(), TagName: jsonField(body, "tag_name"), Name: jsonField(body, "name")}
+			repo.Releases = append(repo.Releases, release)
+			writeJSON(w, http.StatusCreated, f.releaseJSON(repo, release))
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	idPart, sub, _ := strings.Cut(rest, "/")
+	id, _ := strconv.Atoi(idPart)
+	var release *FakeRelease
+	for _, candidate := range repo.Releases {
+		if candidate.ID == id {
+			release = candidate
+		}
+	}
+	if release == nil || sub != "assets" {
+		notFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		assets := []map[string]interface{}{}
+		for _, asset := range release.Assets {
+			assets = append(assets, f.assetJSON(repo, release, asset))
+		}
+		writeJSON(w, http.StatusOK, assets)
+	case http.MethodPost:
+		name := r.URL.Query().Get("name")
+		for _, asset := range release.Assets {
+			if asset.Name == name {
+				writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+					"message": "Validation Failed",
+					"errors":  []map[string]string{{"code": "already_exists"}},
+				})
+				return
+			}
+		}
+		asset := FakeAsset{ID: f.id(), Name: name, Data: body}
+		release.Assets = append(release.Assets, asset)
+		writeJSON(w, http.StatusCreated, f.assetJSON(repo, release, asset))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *FakeGitHub) serveCommits(w http.ResponseWriter, repo *FakeRepo) {
+	commits := []map[string]interface{}{}
+	for i := len(repo.Commits) - 1; i >= 0; i-- {
+		commit := repo.Commits[i]
+		commits = append(commits, map[string]interface{}{
+			"sha": commit.SHA,
+			"commit": map[string]interface{}{
+				"message": commit.Message,
+				"author":  map[string]string{"date": commit.Date.UTC().Format(time.RFC3339)},
+			},
+		})
+	}
+	writeJSON(w, http.StatusOK, commits)
+}
+
+// serveCodeSearch finds the files of the repo: qualifier containing every other word of the query,
+// ignoring other qualifiers
+func (f *FakeGitHub) serveCodeSearch(w http.ResponseWriter, r *http.Request) {
+	var repo *FakeRepo
+	var terms []string
+	for _, field := range strings.Fields(r.URL.Query().Get("q")) {
+		qualifier, value, ok := strings.Cut(field, ":")
+		switch {
+		case ok && qualifier == "repo":
+			repo = f.repos[value]
+		case !ok:
+			terms = append(terms, strings.ToLower(field))
+		}
+	}
+	if repo == nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+
+	items := []map[string]interface{}{}
+	for path, content := range repo.Files {
+		lower := strings.ToLower(content)
+		matched := true
+		for _, term := range terms {
+			if !strings.Contains(lower, term) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			items = append(items, repo.fileJSON(path, content, false))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i]["path"].(string) < items[j]["path"].(string)
+	})
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"total_count": len(items), "items": items})
+}
+
+// serveRateLimit reports the REST budget used by the requests so far and the GraphQL budget
+func (f *FakeGitHub) serveRateLimit(w http.ResponseWriter) {
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	restUsed := 0
+	for _, req := range f.requests {
+		if req.Path != "/graphql" && req.Path != "/rate_limit" {
+			restUsed++
+		}
+	}
+
+	graphQLRemaining, graphQLReset := fakeRateLimit, reset
+	if f.graphQLLimited {
+		graphQLRemaining, graphQLReset = f.graphQLRemaining, f.graphQLReset
+	}
+
+	resource := func(remaining int, reset time.Time) map[string]interface{} {
+		return map[string]interface{}{
+			"limit":     fakeRateLimit,
+			"remaining": remaining,
+			"used":      fakeRateLimit - remaining,
+			"reset":     reset.Unix(),
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"resources": map[string]interface{}{
+			"core":    resource(fakeRateLimit-restUsed, reset),
+			"graphql": resource(graphQLRemaining, graphQLReset),
+		},
+	})
+}
+
+var (
+	graphQLRepoPattern  = regexp.MustCompile(`repository\(owner:\s*"([^"]+)",\s*name:\s*"([^"]+)"\)`)
+	graphQLIssuePattern = regexp.MustCompile(`(\w+):\s*issue\(number:\s*(\d+)\)`)
+)
+
+// serveGraphQL answers the aliased issue lookups used to sync issue statuses
+func (f *FakeGitHub) serveGraphQL(w http.ResponseWriter, body []byte) {
+	if f.GraphQLUnavailable {
+		writeJSON(w, http.StatusBadGateway, map[string]string{"message": "Server Error"})
+		return
+	}
+	if f.graphQLLimited {
+		if f.graphQLRemaining <= 0 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			writeJSON(w, http.StatusForbidden, map[string]string{"message": "API rate limit exceeded"})
+			return
+		}
+		cost := f.GraphQLCost
+		if cost < 1 {
+			cost = 1
+		}
+		f.graphQLRemaining -= cost
+		if f.graphQLRemaining < 0 {
+			f.graphQLRemaining = 0
+		}
+		w.Header().Set("X-RateLimit-Resource", "graphql")
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(fakeRateLimit))
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(f.graphQLRemaining))
+		w.Header().Set("X-RateLimit-Used", strconv.Itoa(fakeRateLimit-f.graphQLRemaining))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(f.graphQLReset.Unix(), 10))
+	}
+
+	query := jsonField(body, "query")
+
+	match := graphQLRepoPattern.FindStringSubmatch(query)
+	if match == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"errors": []map[string]string{{"message": "unsupported query"}}})
+		return
+	}
+	repo := f.repos[match[1]+"/"+match[2]]
+	if repo == nil {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"data":   map[string]interface{}{"repository": nil},
+			"errors": []map[string]string{{"message": fmt.Sprintf("Could not resolve to a Repository with the name '%s/%s'.", match[1], match[2])}},
+		})
+		return
+	}
+
+	aliases := graphQLIssuePattern.FindAllStringSubmatch(query, -1)
+	if f.GraphQLMaxAliases > 0 && len(aliases) > f.GraphQLMaxAliases {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"errors": []map[string]string{{"message": fmt.Sprintf("Query has complexity of %d, which exceeds max complexity of %d", len(aliases), f.GraphQLMaxAliases)}},
+		})
+		return
+	}
+
+	result := make(map[string]interface{})
+	for _, alias := range aliases {
+		number, _ := strconv.Atoi(alias[2])
+		issue := repo.issue(number)
+		if issue == nil {
+			result[alias[1]] = nil
+			continue
+		}
+		result[alias[1]] = map[string]interface{}{
+			"number":   issue.Number,
+			"title":    issue.Title,
+			"state":    strings.ToUpper(issue.State),
+			"url":      fmt.Sprintf("%s/issues/%d", repo.htmlURL(), issue.Number),
+			"closedAt": issue.closedAt(),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"data": map[string]interface{}{"repository": result}})
+}
+
+func (f *FakeGitHub) id() int {
+	f.nextID++
+	return f.nextID
+}
+
+func (f *FakeGitHub) newIssue(repo *FakeRepo, title, body, state string) *FakeIssue {
+	issue := &FakeIssue{ID: f.id(), Number: len(repo.Issues) + 1, Title: title, Body: body}
+	issue.setState(state)
+	repo.Issues = append(repo.Issues, issue)
+	return issue
+}
+
+func (f *FakeGitHub) releaseJSON(repo *FakeRepo, release *FakeRelease) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         release.ID,
+		"tag_name":   release.TagName,
+		"name":       release.Name,
+		"html_url":   repo.htmlURL() + "/releases/tag/" + release.TagName,
+		"upload_url": fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets{?name,label}", f.URL(), repo.Owner, repo.Name, release.ID),
+		"assets_url": fmt.Sprintf("%s/repos/%s/%s/releases/%d/assets", f.URL(), repo.Owner, repo.Name, release.ID),
+	}
+}
+
+func (f *FakeGitHub) assetJSON(repo *FakeRepo, release *FakeRelease, asset FakeAsset) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                   asset.ID,
+		"name":                 asset.Name,
+		"state":                "uploaded",
+		"size":                 len(asset.Data),
+		"browser_download_url": fmt.Sprintf("%s/releases/download/%s/%s", repo.htmlURL(), release.TagName, asset.Name),
+	}
+}
+
+func (repo *FakeRepo) htmlURL() string {
+	return "https://github.com/" + repo.Owner + "/" + repo.Name
+}
+
+func (repo *FakeRepo) hasBranch(name string) bool {
+	if name == repo.DefaultBranch {
+		return true
+	}
+	for _, branch := range repo.Branches {
+		if branch == name {
+			return true
+		}
+	}
+	return false
+}
+
+// headSHA is the latest commit, or a zero hash before the first
+func (repo *FakeRepo) headSHA() string {
+	if len(repo.Commits) == 0 {
+		return strings.Repeat("0", 40)
+	}
+	return repo.Commits[len(repo.Commits)-1].SHA
+}
+
+func (repo *FakeRepo) issue(number int) *FakeIssue {
+	if number < 1 || number > len(repo.Issues) {
+		return nil
+	}
+	return repo.Issues[number-1]
+}
+
+func (repo *FakeRepo) issueJSON(issue *FakeIssue) map[string]interface{} {
+	labels := []map[string]string{}
+	for _, label := range issue.Labels {
+		labels = append(labels, map[string]string{"name": label})
+	}
+	return map[string]interface{}{
+		"id":        issue.ID,
+		"number":    issue.Number,
+		"title":     issue.Title,
+		"body":      issue.Body,
+		"state":     issue.State,
+		"html_url":  fmt.Sprintf("%s/issues/%d", repo.htmlURL(), issue.Number),
+		"closed_at": issue.closedAt(),
+		"labels":    labels,
+	}
+}
+
+// hasLabels reports whether the issue carries all labels, as GitHub's labels filter does
+func (issue *FakeIssue) hasLabels(labels []string) bool {
+	for _, label := range labels {
+		found := false
+		for _, existing := range issue.Labels {
+			if existing == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (issue *FakeIssue) setState(state string) {
+	if state == "closed" && issue.State != "closed" {
+		issue.ClosedAt = time.Now().UTC().Truncate(time.Second)
+	} else if state != "closed" {
+		issue.ClosedAt = time.Time{}
+	}
+	issue.State = state
+}
+
+// closedAt is the close time in GitHub's JSON format, nil while open
+func (issue *FakeIssue) closedAt() interface{} {
+	if issue.ClosedAt.IsZero() {
+		return nil
+	}
+	return issue.ClosedAt.Format(time.RFC3339)
+}
+
+func (repo *FakeRepo) commit(message string) FakeCommit {
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s/%s#%d:%s", repo.Owner, repo.Name, len(repo.Commits), message)))
+	commit := FakeCommit{SHA: hex.EncodeToString(sum[:]), Message: message, Date: time.Now()}
+	repo.Commits = append(repo.Commits, commit)
+	return commit
+}
+
+func (repo *FakeRepo) fileJSON(path, content string, withContent bool) map[string]interface{} {
+	name := path[strings.LastIndex(path, "/")+1:]
+	file := map[string]interface{}{
+		"name":     name,
+		"path":     path,
+		"sha":      blobSHA(content),
+		"size":     len(content),
+		"type":     "file",
+		"html_url": fmt.Sprintf("%s/blob/%s/%s", repo.htmlURL(), repo.DefaultBranch, path),
+	}
+	if withContent {
+		file["content"] = base64.StdEncoding.EncodeToString([]byte(content))
+		file["encoding"] = "base64"
+	}
+	return file
+}
+
+// list returns the directory entries under dir ("" is the root), sorted by name
+func (repo *FakeRepo) list(dir string) []map[string]interface{} {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+
+	seen := make(map[string]bool)
+	var entries []map[string]interface{}
+	for path, content := range repo.Files {
+		if !strings.HasPrefix(path, prefix) {
+			continue
+		}
+		name, _, isDir := strings.Cut(strings.TrimPrefix(path, prefix), "/")
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if isDir {
+			entries = append(entries, map[string]interface{}{"name": name, "path": prefix + name, "type": "dir", "size": 0})
+		} else {
+			entries = append(entries, repo.fileJSON(path, content, false))
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i]["name"].(string) < entries[j]["name"].(string)
+	})
+	return entries
+}
+
+// treeSHA is the made-up sha of the tree of a commit
+func treeSHA(commitSHA string) string {
+	sum := sha1.Sum([]byte("tree " + commitSHA))
+	return hex.EncodeToString(sum[:])
+}
+
+// blobSHA computes the git blob SHA of content, like GitHub's contents API reports
+func blobSHA(content string) string {
+	sum := sha1.Sum([]byte(fmt.Sprintf("blob %d\x00%s", len(content), content)))
+	return hex.EncodeToString(sum[:])
+}
+
+func jsonField(body []byte, field string) string {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return ""
+	}
+	value, _ := fields[field].(string)
+	return value
+}
+
+// paginate returns the page of items selected by the page and per_page query parameters
+// (default 30 per page), setting a Link header to the next page like GitHub
+func paginate(w http.ResponseWriter, r *http.Request, items []map[string]interface{}) []map[string]interface{} {
+	query := r.URL.Query()
+	perPage, err := strconv.Atoi(query.Get("per_page"))
+	if err != nil || perPage <= 0 {
+		perPage = 30
+	}
+	page, err := strconv.Atoi(query.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	start := (page - 1) * perPage
+	if start >= len(items) {
+		return []map[string]interface{}{}
+	}
+	end := start + perPage
+	if end < len(items) {
+		query.Set("page", strconv.Itoa(page+1))
+		w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?%s>; rel="next"`, r.Host, r.URL.Path, query.Encode()))
+	} else {
+		end = len(items)
+	}
+	return items[start:end]
+}
+
+func notFound(w http.ResponseWriter) {
+	writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
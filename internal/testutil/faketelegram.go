@@ -0,0 +1,152 @@
+package testutil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// FakeTelegramToken is the bot token FakeTelegram accepts
+const FakeTelegramToken = "123456:fake-telegram-token"
+
+// FakeTelegram is a Telegram Bot API served over httptest. It answers getMe, acknowledges
+// every other method and records the calls, so handlers can be run against a real tgbotapi.BotAPI:
+//
+//	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(testutil.FakeTelegramToken, fake.Endpoint())
+type FakeTelegram struct {
+	Server *httptest.Server
+
+	mu            sync.Mutex
+	calls         []TelegramCall
+	nextMessageID int
+}
+
+// TelegramCall is a Bot API method call received by FakeTelegram
+type TelegramCall struct {
+	Method string
+	Params url.Values
+}
+
+// SentMessage is a message sent or edited through FakeTelegram
+type SentMessage struct {
+	Method      string
+	ChatID      int64
+	MessageID   int
+	Text        string
+	ParseMode   string
+	ReplyMarkup string
+}
+
+// NewFakeTelegram starts a FakeTelegram that is closed when the test finishes
+func NewFakeTelegram(t testing.TB) *FakeTelegram {
+	t.Helper()
+
+	f := &FakeTelegram{}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	t.Cleanup(f.Server.Close)
+
+	return f
+}
+
+// Endpoint returns the API endpoint format expected by tgbotapi ("<url>/bot%s/%s")
+func (f *FakeTelegram) Endpoint() string {
+	return f.Server.URL + "/bot%s/%s"
+}
+
+// Calls returns the method calls received so far
+func (f *FakeTelegram) Calls() []TelegramCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]TelegramCall(nil), f.calls...)
+}
+
+// Messages returns the messages sent or edited so far, in order
+func (f *FakeTelegram) Messages() []SentMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var messages []SentMessage
+	for _, call := range f.calls {
+		if !isMessageMethod(call.Method) {
+			continue
+		}
+		chatID, _ := strconv.ParseInt(call.Params.Get("chat_id"), 10, 64)
+		messageID, _ := strconv.Atoi(call.Params.Get("message_id"))
+		text := call.Params.Get("text")
+		if text == "" {
+			text = call.Params.Get("caption")
+		}
+		messages = append(messages, SentMessage{
+			Method:      call.Method,
+			ChatID:      chatID,
+			MessageID:   messageID,
+			Text:        text,
+			ParseMode:   call.Params.Get("parse_mode"),
+			ReplyMarkup: call.Params.Get("reply_markup"),
+		})
+	}
+	return messages
+}
+
+// LastMessage returns the most recent message sent or edited, and false if there is none
+func (f *FakeTelegram) LastMessage() (SentMessage, bool) {
+	messages := f.Messages()
+	if len(messages) == 0 {
+		return SentMessage{}, false
+	}
+	return messages[len(messages)-1], true
+}
+
+func (f *FakeTelegram) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	token, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+	if !ok || token != FakeTelegramToken {
+		writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"ok": false, "error_code": 401, "description": "Unauthorized"})
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		r.ParseMultipartForm(32 << 20)
+	} else {
+		r.ParseForm()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, TelegramCall{Method: method, Params: r.Form})
+
+	var result interface{} = true
+	switch {
+	case method == "getMe":
+		result = map[string]interface{}{"id": 123456, "is_bot": true, "first_name": "Msg2Git", "username": "msg2git_test_bot"}
+	case method == "getUpdates":
+		result = []interface{}{}
+	case isMessageMethod(method):
+		chatID, _ := strconv.ParseInt(r.Form.Get("chat_id"), 10, 64)
+		messageID, _ := strconv.Atoi(r.Form.Get("message_id"))
+		if messageID == 0 {
+			f.nextMessageID++
+			messageID = f.nextMessageID
+		}
+		result = map[string]interface{}{
+			"message_id": messageID,
+			"date":       time.Now().Unix(),
+			"chat":       map[string]interface{}{"id": chatID, "type": "private"},
+			"text":       r.Form.Get("text"),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"ok": true, "result": result})
+}
+
+func isMessageMethod(method string) bool {
+	if method == "sendChatAction" {
+		return false
+	}
+	return strings.HasPrefix(method, "send") || strings.HasPrefix(method, "editMessage")
+}
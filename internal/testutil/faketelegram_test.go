@@ -0,0 +1,41 @@
+package testutil
+
+import (
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+func TestFakeTelegram_SendAndEdit(t *testing.T) {
+	fake := NewFakeTelegram(t)
+
+	api, err := tgbotapi.NewBotAPIWithAPIEndpoint(FakeTelegramToken, fake.Endpoint())
+	if err != nil {
+		t.Fatalf("NewBotAPIWithAPIEndpoint() error = %v", err)
+	}
+
+	sent, err := api.Send(tgbotapi.NewMessage(7, "hello"))
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if sent.MessageID != 1 || sent.Chat.ID != 7 {
+		t.Errorf("Send() = %+v", sent)
+	}
+
+	if _, err := api.Send(tgbotapi.NewEditMessageText(7, sent.MessageID, "edited")); err != nil {
+		t.Fatalf("Send(edit) error = %v", err)
+	}
+
+	messages := fake.Messages()
+	if len(messages) != 2 || messages[1].Method != "editMessageText" || messages[1].MessageID != 1 || messages[1].Text != "edited" {
+		t.Errorf("Messages() = %+v", messages)
+	}
+}
+
+func TestFakeTelegram_WrongToken(t *testing.T) {
+	fake := NewFakeTelegram(t)
+
+	if _, err := tgbotapi.NewBotAPIWithAPIEndpoint("1:wrong", fake.Endpoint()); err == nil {
+		t.Error("Expected a wrong token to be rejected")
+	}
+}
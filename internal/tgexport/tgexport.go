@@ -0,0 +1,191 @@
+// Package tgexport reads chat exports of Telegram Desktop (Export chat history, JSON format), such
+// as the history of Saved Messages. Messages are decoded one at a time from the "messages" array,
+// so an export is never held in memory as a whole, and their formatting is converted to markdown.
+package tgexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Message is a text message of an export
+type Message struct {
+	ID   int
+	Date time.Time // Wall clock time of the exporting device, in UTC as the export has no zone
+	Text string    // Markdown
+}
+
+// exportMessage is a message as exported, text is a string or a list of strings and entities
+type exportMessage struct {
+	ID            int             `json:"id"`
+	Type          string          `json:"type"`
+	Date          string          `json:"date"`
+	DateUnixtime  string          `json:"date_unixtime"`
+	ForwardedFrom string          `json:"forwarded_from"`
+	Text          json.RawMessage `json:"text"`
+	TextEntities  []entity        `json:"text_entities"`
+}
+
+// entity is a formatted part of a message's text
+type entity struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Href     string `json:"href"`
+	Language string `json:"language"`
+}
+
+// Read calls fn with every text message of the export in r, in export order (oldest first), and
+// returns how many messages were skipped: service messages and media without text
+func Read(r io.Reader, fn func(Message) error) (int, error) {
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return 0, err
+	}
+
+	found := false
+	skipped := 0
+	for dec.More() {
+		token, err := dec.Token()
+		if err != nil {
+			return skipped, fmt.Errorf("invalid export: %w", err)
+		}
+		if key, _ := token.(string); key != "messages" {
+			var ignored json.RawMessage
+			if err := dec.Decode(&ignored); err != nil {
+				return skipped, fmt.Errorf("invalid export: %w", err)
+			}
+			continue
+		}
+
+		found = true
+		if err := expectDelim(dec, '['); err != nil {
+			return skipped, err
+		}
+		for dec.More() {
+			var raw exportMessage
+			if err := dec.Decode(&raw); err != nil {
+				return skipped, fmt.Errorf("invalid message: %w", err)
+			}
+			message, ok := raw.message()
+			if !ok {
+				skipped++
+				continue
+			}
+			if err := fn(message); err != nil {
+				return skipped, err
+			}
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return skipped, err
+		}
+	}
+
+	if !found {
+		return skipped, fmt.Errorf("no messages in the export, export a single chat such as Saved Messages in JSON format")
+	}
+	return skipped, nil
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("invalid export: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("invalid export: expected %q", want)
+	}
+	return nil
+}
+
+// message converts an exported message, false for messages without text
+func (m exportMessage) message() (Message, bool) {
+	if m.Type != "message" {
+		return Message{}, false
+	}
+
+	text := strings.TrimSpace(m.markdown())
+	if text == "" {
+		return Message{}, false
+	}
+	if m.ForwardedFrom != "" {
+		text = fmt.Sprintf("↪️ Forwarded from %s\n\n%s", m.ForwardedFrom, text)
+	}
+
+	date, err := time.Parse("2006-01-02T15:04:05", m.Date)
+	if err != nil {
+		seconds, err := strconv.ParseInt(m.DateUnixtime, 10, 64)
+		if err != nil {
+			return Message{}, false
+		}
+		date = time.Unix(seconds, 0).UTC()
+	}
+
+	return Message{ID: m.ID, Date: date, Text: text}, true
+}
+
+// markdown returns the text of the message with its formatting as markdown
+func (m exportMessage) markdown() string {
+	entities := m.TextEntities
+	if entities == nil {
+		entities = parseText(m.Text)
+	}
+
+	var sb strings.Builder
+	for _, e := range entities {
+		sb.WriteString(e.markdown())
+	}
+	return sb.String()
+}
+
+// parseText reads the text field of exports without text_entities
+func parseText(raw json.RawMessage) []entity {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return []entity{{Type: "plain", Text: text}}
+	}
+
+	var parts []json.RawMessage
+	if json.Unmarshal(raw, &parts) != nil {
+		return nil
+	}
+	entities := make([]entity, 0, len(parts))
+	for _, part := range parts {
+		var e entity
+		if json.Unmarshal(part, &text) == nil {
+			e = entity{Type: "plain", Text: text}
+		} else if json.Unmarshal(part, &e) != nil {
+			continue
+		}
+		entities = append(entities, e)
+	}
+	return entities
+}
+
+func (e entity) markdown() string {
+	if strings.TrimSpace(e.Text) == "" {
+		return e.Text
+	}
+
+	switch e.Type {
+	case "bold":
+		return "**" + e.Text + "**"
+	case "italic":
+		return "_" + e.Text + "_"
+	case "strikethrough":
+		return "~~" + e.Text + "~~"
+	case "code":
+		return "`" + e.Text + "`"
+	case "pre":
+		return "\n```" + e.Language + "\n" + strings.Trim(e.Text, "\n") + "\n```\n"
+	case "text_link":
+		return "[" + e.Text + "](" + e.Href + ")"
+	case "blockquote":
+		return "> " + strings.ReplaceAll(e.Text, "\n", "\n> ")
+	default:
+		return e.Text
+	}
+}
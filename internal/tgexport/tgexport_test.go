@@ -0,0 +1,58 @@
+package tgexport
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleExport = `{
+ "name": "Saved Messages",
+ "type": "saved_messages",
+ "id": 42,
+ "messages": [
+  {"id": 1, "type": "service", "date": "2024-03-01T08:00:00", "action": "create_group", "text": ""},
+  {"id": 2, "type": "message", "date": "2024-03-01T09:30:00", "date_unixtime": "1709285400", "text": "plain note", "text_entities": [{"type": "plain", "text": "plain note"}]},
+  {"id": 3, "type": "message", "date": "2024-03-02T10:00:00", "text": ["Read ", {"type": "bold", "text": "this"}, " at ", {"type": "text_link", "text": "the site", "href": "https://example.com"}]},
+  {"id": 4, "type": "message", "date": "2024-03-02T11:00:00", "photo": "photos/photo_1.jpg", "text": ""},
+  {"id": 5, "type": "message", "date_unixtime": "1709460000", "forwarded_from": "Alice", "text": "", "text_entities": [{"type": "code", "text": "go test"}]}
+ ]
+}`
+
+func TestRead(t *testing.T) {
+	var messages []Message
+	skipped, err := Read(strings.NewReader(sampleExport), func(m Message) error {
+		messages = append(messages, m)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if skipped != 2 || len(messages) != 3 {
+		t.Fatalf("Read() = %d messages, %d skipped, want 3 and 2", len(messages), skipped)
+	}
+
+	if messages[0].ID != 2 || messages[0].Text != "plain note" || !messages[0].Date.Equal(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)) {
+		t.Errorf("messages[0] = %+v", messages[0])
+	}
+	if messages[1].Text != "Read **this** at [the site](https://example.com)" {
+		t.Errorf("messages[1].Text = %q", messages[1].Text)
+	}
+	if messages[2].Text != "↪️ Forwarded from Alice\n\n`go test`" || !messages[2].Date.Equal(time.Unix(1709460000, 0)) {
+		t.Errorf("messages[2] = %+v", messages[2])
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	noop := func(Message) error { return nil }
+	for name, input := range map[string]string{
+		"not json":    "hello",
+		"array":       "[]",
+		"no messages": `{"about": "full export", "chats": {"list": []}}`,
+		"truncated":   `{"messages": [{"id": 1, "type": "message", "text": "a"}, {"id": 2`,
+	} {
+		if _, err := Read(strings.NewReader(input), noop); err == nil {
+			t.Errorf("%s: Read() expected an error", name)
+		}
+	}
+}
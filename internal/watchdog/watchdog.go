@@ -0,0 +1,258 @@
+package watchdog
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/logger"
+)
+
+// Slow operation watchdog: handlers, git operations and database queries report their duration
+// here. Operations over their kind's threshold are logged with the correlation ID of the request
+// they belong to, counted for /admin slow and passed to the reporter (admin DMs), so chronic slow
+// paths show up without tracing infrastructure.
+//
+// Requests bind a correlation ID to their chat with Begin; git operations and queries of that
+// chat pick it up through the chat ID they report.
+
+// Kind is a class of timed operations with its own threshold
+type Kind string
+
+const (
+	Handler Kind = "handler" // Telegram message and callback handlers
+	Git     Kind = "git"     // Clones, fetches and pushes
+	Query   Kind = "query"   // Database queries
+)
+
+// DefaultThresholds are used for kinds without a configured threshold
+var DefaultThresholds = map[Kind]time.Duration{
+	Handler: 10 * time.Second,
+	Git:     time.Minute,
+	Query:   time.Second,
+}
+
+// Event describes an operation that exceeded its threshold
+type Event struct {
+	Kind          Kind
+	Name          string
+	ChatID        int64  // 0 if the operation isn't tied to a chat
+	CorrelationID string // Request the operation belongs to, empty if unknown
+	Duration      time.Duration
+	Threshold     time.Duration
+}
+
+// Stat aggregates the slow operations of one kind and name
+type Stat struct {
+	Kind              Kind
+	Name              string
+	Count             int64
+	Total             time.Duration
+	Max               time.Duration
+	LastAt            time.Time
+	LastCorrelationID string
+}
+
+// binding is the correlation ID of a chat's in-flight request
+type binding struct {
+	id   string
+	refs int
+}
+
+// Watchdog tracks operation durations against per-kind thresholds
+type Watchdog struct {
+	mu         sync.Mutex
+	thresholds map[Kind]time.Duration
+	stats      map[Kind]map[string]*Stat
+	bindings   map[int64]*binding
+	reporter   func(Event)
+}
+
+// New creates a watchdog using DefaultThresholds
+func New() *Watchdog {
+	w := &Watchdog{
+		thresholds: make(map[Kind]time.Duration),
+		stats:      make(map[Kind]map[string]*Stat),
+		bindings:   make(map[int64]*binding),
+	}
+	for kind, threshold := range DefaultThresholds {
+		w.thresholds[kind] = threshold
+	}
+	return w
+}
+
+// SetThreshold changes the threshold of kind, a zero duration restores the default
+func (w *Watchdog) SetThreshold(kind Kind, threshold time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if threshold <= 0 {
+		threshold = DefaultThresholds[kind]
+	}
+	w.thresholds[kind] = threshold
+}
+
+// SetReporter sets the function called for every slow operation, outside the watchdog's lock
+func (w *Watchdog) SetReporter(reporter func(Event)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.reporter = reporter
+}
+
+// Begin binds a new correlation ID to chatID until end is called
+func (w *Watchdog) Begin(chatID int64) (correlationID string, end func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	b := w.bindings[chatID]
+	if b == nil {
+		b = &binding{}
+		w.bindings[chatID] = b
+	}
+	// Concurrent requests of a chat share the binding, the newest ID wins
+	b.id = newCorrelationID()
+	b.refs++
+	correlationID = b.id
+
+	var once sync.Once
+	return correlationID, func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if b.refs--; b.refs <= 0 {
+				delete(w.bindings, chatID)
+			}
+		})
+	}
+}
+
+// CorrelationID returns the correlation ID of chatID's in-flight request, empty if none
+func (w *Watchdog) CorrelationID(chatID int64) string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if b := w.bindings[chatID]; b != nil {
+		return b.id
+	}
+	return ""
+}
+
+// Track starts timing an operation, call the returned function when it finishes
+func (w *Watchdog) Track(kind Kind, name string, chatID int64) func() {
+	start := time.Now()
+	return func() {
+		w.Observe(kind, name, chatID, time.Since(start))
+	}
+}
+
+// Observe records an operation's duration and reports it if it exceeded its threshold
+func (w *Watchdog) Observe(kind Kind, name string, chatID int64, duration time.Duration) {
+	w.mu.Lock()
+	threshold := w.thresholds[kind]
+	if threshold <= 0 || duration < threshold {
+		w.mu.Unlock()
+		return
+	}
+
+	event := Event{
+		Kind:      kind,
+		Name:      name,
+		ChatID:    chatID,
+		Duration:  duration,
+		Threshold: threshold,
+	}
+	if b := w.bindings[chatID]; chatID != 0 && b != nil {
+		event.CorrelationID = b.id
+	}
+
+	byName := w.stats[kind]
+	if byName == nil {
+		byName = make(map[string]*Stat)
+		w.stats[kind] = byName
+	}
+	stat := byName[name]
+	if stat == nil {
+		stat = &Stat{Kind: kind, Name: name}
+		byName[name] = stat
+	}
+	stat.Count++
+	stat.Total += duration
+	if duration > stat.Max {
+		stat.Max = duration
+	}
+	stat.LastAt = time.Now()
+	stat.LastCorrelationID = event.CorrelationID
+
+	reporter := w.reporter
+	w.mu.Unlock()
+
+	logger.Warn("Slow operation", map[string]interface{}{
+		"kind":           string(kind),
+		"name":           name,
+		"chat_id":        chatID,
+		"correlation_id": event.CorrelationID,
+		"duration_ms":    duration.Milliseconds(),
+		"threshold_ms":   threshold.Milliseconds(),
+	})
+
+	if reporter != nil {
+		reporter(event)
+	}
+}
+
+// Stats returns the slow operations seen so far, most frequent first
+func (w *Watchdog) Stats() []Stat {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	var stats []Stat
+	for _, byName := range w.stats {
+		for _, stat := range byName {
+			stats = append(stats, *stat)
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		return stats[i].Max > stats[j].Max
+	})
+	return stats
+}
+
+// newCorrelationID returns a short random ID for log correlation
+func newCorrelationID() string {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("150405.000000")
+	}
+	return hex.EncodeToString(buf)
+}
+
+// std is the process-wide watchdog used by the package functions
+var std = New()
+
+// SetThreshold changes the threshold of kind on the process-wide watchdog
+func SetThreshold(kind Kind, threshold time.Duration) { std.SetThreshold(kind, threshold) }
+
+// SetReporter sets the reporter of the process-wide watchdog
+func SetReporter(reporter func(Event)) { std.SetReporter(reporter) }
+
+// Begin binds a new correlation ID to chatID on the process-wide watchdog
+func Begin(chatID int64) (string, func()) { return std.Begin(chatID) }
+
+// CorrelationID returns the correlation ID of chatID's in-flight request
+func CorrelationID(chatID int64) string { return std.CorrelationID(chatID) }
+
+// Track starts timing an operation on the process-wide watchdog
+func Track(kind Kind, name string, chatID int64) func() { return std.Track(kind, name, chatID) }
+
+// Observe records an operation's duration on the process-wide watchdog
+func Observe(kind Kind, name string, chatID int64, duration time.Duration) {
+	std.Observe(kind, name, chatID, duration)
+}
+
+// Stats returns the slow operations seen by the process-wide watchdog
+func Stats() []Stat { return std.Stats() }
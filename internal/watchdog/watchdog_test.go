@@ -0,0 +1,89 @@
+package watchdog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestObserveThresholds(t *testing.T) {
+	w := New()
+	w.SetThreshold(Query, 100*time.Millisecond)
+
+	var events []Event
+	w.SetReporter(func(event Event) { events = append(events, event) })
+
+	w.Observe(Query, "SELECT 1", 0, 50*time.Millisecond)
+	w.Observe(Query, "SELECT 1", 0, 150*time.Millisecond)
+	w.Observe(Query, "SELECT 1", 0, 300*time.Millisecond)
+	w.Observe(Handler, "/sync", 0, 5*time.Second)
+
+	if len(events) != 2 {
+		t.Fatalf("reported %d events, want the 2 slow queries", len(events))
+	}
+	if events[1].Duration != 300*time.Millisecond || events[1].Threshold != 100*time.Millisecond {
+		t.Errorf("event = %+v", events[1])
+	}
+
+	stats := w.Stats()
+	if len(stats) != 1 {
+		t.Fatalf("Stats() = %+v, want one slow operation", stats)
+	}
+	if stats[0].Count != 2 || stats[0].Max != 300*time.Millisecond || stats[0].Total != 450*time.Millisecond {
+		t.Errorf("stat = %+v", stats[0])
+	}
+}
+
+func TestSetThresholdDefault(t *testing.T) {
+	w := New()
+	w.SetThreshold(Git, time.Second)
+	w.SetThreshold(Git, 0)
+
+	w.Observe(Git, "clone", 0, 30*time.Second)
+	if len(w.Stats()) != 0 {
+		t.Error("a zero threshold should restore the default")
+	}
+}
+
+func TestCorrelation(t *testing.T) {
+	w := New()
+	w.SetThreshold(Git, time.Millisecond)
+
+	var events []Event
+	w.SetReporter(func(event Event) { events = append(events, event) })
+
+	id, end := w.Begin(42)
+	if id == "" || w.CorrelationID(42) != id {
+		t.Fatalf("CorrelationID(42) = %q, want %q", w.CorrelationID(42), id)
+	}
+
+	w.Observe(Git, "push", 42, time.Second)
+	w.Observe(Git, "push", 7, time.Second)
+
+	_, endSecond := w.Begin(42)
+	end()
+	if w.CorrelationID(42) == "" {
+		t.Error("the binding should stay while another request of the chat is in flight")
+	}
+	endSecond()
+	end() // Calling end twice has no effect
+	if w.CorrelationID(42) != "" {
+		t.Error("the binding should be removed once every request ended")
+	}
+
+	if len(events) != 2 || events[0].CorrelationID != id || events[1].CorrelationID != "" {
+		t.Errorf("events = %+v, want the correlation ID only for chat 42", events)
+	}
+}
+
+func TestTrack(t *testing.T) {
+	w := New()
+	w.SetThreshold(Handler, time.Nanosecond)
+
+	done := w.Track(Handler, "/sync", 0)
+	time.Sleep(time.Millisecond)
+	done()
+
+	if stats := w.Stats(); len(stats) != 1 || stats[0].Name != "/sync" {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
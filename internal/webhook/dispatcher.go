@@ -0,0 +1,157 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/netguard"
+)
+
+// Event names emitted to outgoing webhooks
+const (
+	EventNoteCommitted = "note.committed"
+	EventTodoCompleted = "todo.completed"
+	EventIssueCreated  = "issue.created"
+	EventPing          = "ping"
+)
+
+// Events lists the events users can subscribe to
+var Events = []string{EventNoteCommitted, EventTodoCompleted, EventIssueCreated}
+
+// Request headers sent with every delivery
+const (
+	HeaderEvent     = "X-Msg2git-Event"
+	HeaderSignature = "X-Msg2git-Signature"
+	HeaderDelivery  = "X-Msg2git-Delivery"
+)
+
+// Payload is the JSON body posted to webhook URLs
+type Payload struct {
+	Event     string                 `json:"event"`
+	Timestamp time.Time              `json:"timestamp"`
+	ChatID    int64                  `json:"chat_id"`
+	Data      map[string]interface{} `json:"data"`
+}
+
+// NewPayload creates a payload for event stamped with the current time
+func NewPayload(event string, chatID int64, data map[string]interface{}) *Payload {
+	return &Payload{
+		Event:     event,
+		Timestamp: time.Now().UTC(),
+		ChatID:    chatID,
+		Data:      data,
+	}
+}
+
+// IsValidEvent reports whether event is one users can subscribe to
+func IsValidEvent(event string) bool {
+	for _, e := range Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateSecret returns a random hex encoded signing secret
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// Sign returns the signature header value for body: "sha256=" followed by the hex HMAC-SHA256
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers payloads to webhook URLs, retrying failed deliveries with exponential backoff
+type Dispatcher struct {
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+}
+
+// NewDispatcher creates a dispatcher with default timeout and retry settings. Deliveries only reach
+// public addresses and don't follow redirects: a redirect fails the delivery like other 3xx.
+func NewDispatcher() *Dispatcher {
+	client := netguard.NewClient(10 * time.Second)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	return &Dispatcher{
+		client:     client,
+		maxRetries: 3,
+		backoff:    2 * time.Second,
+	}
+}
+
+// Deliver posts payload to url signed with secret. Network errors, 429 and 5xx responses
+// are retried; other 4xx responses fail immediately.
+func (d *Dispatcher) Deliver(url, secret string, payload *Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	signature := Sign(secret, body)
+	deliveryID := fmt.Sprintf("%d", time.Now().UnixNano())
+
+	var lastErr error
+	for attempt := 0; attempt <= d.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(d.backoff * time.Duration(1<<(attempt-1)))
+		}
+
+		retry, err := d.post(url, body, payload.Event, signature, deliveryID)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+
+	return fmt.Errorf("webhook delivery failed: %w", lastErr)
+}
+
+func (d *Dispatcher) post(url string, body []byte, event, signature, deliveryID string) (bool, error) {
+	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "msg2git-webhook")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderSignature, signature)
+	req.Header.Set(HeaderDelivery, deliveryID)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+}
@@ -0,0 +1,126 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestDispatcher returns a dispatcher allowed to reach the loopback servers of the tests
+func newTestDispatcher() *Dispatcher {
+	d := NewDispatcher()
+	d.client = &http.Client{Timeout: 10 * time.Second}
+	d.backoff = time.Millisecond
+	return d
+}
+
+func TestSign(t *testing.T) {
+	// Known HMAC-SHA256 of "hello" with key "secret"
+	expected := "sha256=88aab3ede8d3adf94d26ab90d3bafd4a2083070c3bcce9c014ee04a443847c0b"
+	if got := Sign("secret", []byte("hello")); got != expected {
+		t.Errorf("Sign() = %q, want %q", got, expected)
+	}
+}
+
+func TestDeliverSignsPayload(t *testing.T) {
+	var received Payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get(HeaderSignature) != Sign("topsecret", body) {
+			t.Errorf("signature header does not match body")
+		}
+		if r.Header.Get(HeaderEvent) != EventNoteCommitted {
+			t.Errorf("event header = %q, want %q", r.Header.Get(HeaderEvent), EventNoteCommitted)
+		}
+		json.Unmarshal(body, &received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	payload := NewPayload(EventNoteCommitted, 42, map[string]interface{}{"filename": "note.md"})
+	if err := newTestDispatcher().Deliver(server.URL, "topsecret", payload); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+
+	if received.ChatID != 42 || received.Data["filename"] != "note.md" {
+		t.Errorf("unexpected payload received: %+v", received)
+	}
+}
+
+func TestDeliverRetriesServerErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	if err := newTestDispatcher().Deliver(server.URL, "s", NewPayload(EventPing, 1, nil)); err != nil {
+		t.Fatalf("Deliver() error = %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer server.Close()
+
+	if err := newTestDispatcher().Deliver(server.URL, "s", NewPayload(EventPing, 1, nil)); err == nil {
+		t.Fatal("Deliver() expected error for 410 response")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestDeliverRefusesLocalAddresses(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher()
+	d.maxRetries = 0
+	if err := d.Deliver(server.URL, "secret", NewPayload(EventPing, 1, nil)); err == nil {
+		t.Error("Deliver() to a loopback address succeeded")
+	}
+	if atomic.LoadInt32(&requests) != 0 {
+		t.Errorf("server received %d requests, want none", atomic.LoadInt32(&requests))
+	}
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	var redirected int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&redirected, 1)
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	d := newTestDispatcher()
+	d.client.CheckRedirect = NewDispatcher().client.CheckRedirect
+	if err := d.Deliver(server.URL, "secret", NewPayload(EventPing, 1, nil)); err == nil {
+		t.Error("Deliver() succeeded through a redirect")
+	}
+	if atomic.LoadInt32(&redirected) != 0 {
+		t.Error("Deliver() followed a redirect")
+	}
+}
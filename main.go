@@ -1,16 +1,36 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"time"
 
 	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/database"
 	"github.com/msg2git/msg2git/internal/logger"
+	"github.com/msg2git/msg2git/internal/metrics"
 	"github.com/msg2git/msg2git/internal/telegram"
 )
 
 func main() {
+	checkConfig := flag.Bool("check-config", false, "validate the configuration, test the endpoints and the database, then exit")
+	flag.Parse()
+
+	if *checkConfig {
+		os.Exit(runConfigCheck())
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
+		var invalid *config.InvalidConfigError
+		if errors.As(err, &invalid) {
+			invalid.Report.Write(os.Stderr)
+			log.Fatal("Refusing to start with a broken configuration, run with --check-config for details")
+		}
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
@@ -19,6 +39,13 @@ func main() {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
 
+	for _, d := range cfg.Check(config.CheckOptions{}).Filter(config.SeverityWarning) {
+		logger.Warn("Configuration: "+d.Message, map[string]interface{}{
+			"setting": d.Setting,
+			"hint":    d.Hint,
+		})
+	}
+
 	logger.Info("msg2git is starting", map[string]interface{}{
 		"log_level":    cfg.LogLevel,
 		"has_database": cfg.HasDatabaseConfig(),
@@ -33,6 +60,11 @@ func main() {
 		log.Fatalf("Failed to create Telegram bot: %v", err)
 	}
 
+	if cfg.HasMetricsConfig() {
+		metricsServer := startMetricsServer(cfg.MetricsPort)
+		defer metricsServer.Close()
+	}
+
 	logger.InfoMsg("📝 Ready to turn your messages into GitHub commits!")
 
 	defer bot.Stop()
@@ -42,3 +74,53 @@ func main() {
 		})
 	}
 }
+
+// startMetricsServer serves the Prometheus metrics of the bot on /metrics of port
+func startMetricsServer(port string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	server := &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("Metrics server error", map[string]interface{}{
+				"port":  port,
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	logger.Info("Serving Prometheus metrics", map[string]interface{}{
+		"port": port,
+		"path": "/metrics",
+	})
+	return server
+}
+
+// runConfigCheck prints a diagnostic report of the configuration and returns the exit code
+func runConfigCheck() int {
+	cfg, err := config.LoadUnchecked()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	if cfg.ConfigFile != "" {
+		fmt.Printf("Config file: %s\n\n", cfg.ConfigFile)
+	}
+
+	report := cfg.Check(config.CheckOptions{
+		Connectivity: true,
+		PingDatabase: database.Ping,
+	})
+	report.Write(os.Stdout)
+
+	if report.HasErrors() {
+		return 1
+	}
+	return 0
+}
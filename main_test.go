@@ -1,7 +1,9 @@
 package main
 
 import (
+	"errors"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/msg2git/msg2git/internal/config"
@@ -110,16 +112,15 @@ func TestMain_InvalidTelegramToken(t *testing.T) {
 	os.Setenv("COMMIT_AUTHOR", "User <user@example.com>")
 	
 	cfg, err := loadConfig()
-	if err != nil {
-		t.Errorf("loadConfig() should not fail with invalid telegram token format, validation happens later. Got error: %v", err)
-	}
-	if cfg == nil {
-		t.Errorf("Expected config to be non-nil")
+	if err == nil {
+		t.Fatalf("loadConfig() should refuse a malformed telegram token, got config %+v", cfg)
 	}
 	
-	// Test that creating a bot with invalid token would fail
-	// (We can't test this directly without making actual API calls)
-	if cfg.TelegramBotToken != "invalid" {
-		t.Errorf("Expected TelegramBotToken to be 'invalid', got %s", cfg.TelegramBotToken)
+	var invalid *config.InvalidConfigError
+	if !errors.As(err, &invalid) {
+		t.Fatalf("Expected an InvalidConfigError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "TELEGRAM_BOT_TOKEN") {
+		t.Errorf("Expected the error to name TELEGRAM_BOT_TOKEN, got %v", err)
 	}
-}
\ No newline at end of file
+}
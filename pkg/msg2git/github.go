@@ -0,0 +1,98 @@
+package msg2git
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/config"
+	"github.com/msg2git/msg2git/internal/github"
+)
+
+// GitHubConfig points a GitHubStorage at a repository
+type GitHubConfig struct {
+	Token      string // Personal access token with write access to the repository
+	Repo       string // Repository URL, e.g. "https://github.com/me/notes"
+	Author     string // Default commit author as "Name <email>"
+	APIURL     string // GitHub Enterprise REST and GraphQL base URL, github.com if empty
+	UploadsURL string // GitHub Enterprise release asset upload URL, derived from APIURL if empty
+}
+
+// GitHubStorage stores entries in a GitHub repository through the GitHub API
+type GitHubStorage struct {
+	provider github.GitHubProvider
+}
+
+var _ Storage = (*GitHubStorage)(nil)
+
+// NewGitHubStorage creates a storage for the configured repository
+func NewGitHubStorage(cfg GitHubConfig) (*GitHubStorage, error) {
+	if cfg.Token == "" || cfg.Repo == "" {
+		return nil, fmt.Errorf("GitHub token and repository are required")
+	}
+
+	provider, err := github.NewAPIBasedProvider(&github.ProviderConfig{
+		Config: github.NewConfigAdapter(&config.Config{
+			GitHubToken:  cfg.Token,
+			GitHubRepo:   cfg.Repo,
+			CommitAuthor: cfg.Author,
+		}),
+		UserID:         "library",
+		APIBaseURL:     cfg.APIURL,
+		UploadsBaseURL: cfg.UploadsURL,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub storage: %w", err)
+	}
+
+	return &GitHubStorage{provider: provider}, nil
+}
+
+// PrependFile adds content to the top of a file in one commit
+func (s *GitHubStorage) PrependFile(path, content, commitMessage, author string) (*Commit, error) {
+	result, err := s.provider.CommitFileWithResult(path, content, commitMessage, author, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	commit := &Commit{File: path}
+	if result != nil {
+		commit.SHA = result.SHA
+		commit.URL = result.URL
+	}
+	return commit, nil
+}
+
+// CreateIssue opens an issue in the repository
+func (s *GitHubStorage) CreateIssue(title, body string) (*Issue, error) {
+	url, number, err := s.provider.CreateIssue(title, body)
+	if err != nil {
+		return nil, err
+	}
+	return &Issue{Number: number, Title: title, URL: url}, nil
+}
+
+// UploadMedia uploads data to the GitHub CDN under a unique name keeping the extension of name
+func (s *GitHubStorage) UploadMedia(name string, data []byte) (string, error) {
+	return s.provider.UploadImageToCDN(uniqueMediaName(name, time.Now()), data)
+}
+
+// Repository returns the owner and name of the repository
+func (s *GitHubStorage) Repository() (string, string, error) {
+	return s.provider.GetRepoInfo()
+}
+
+// uniqueMediaName names an upload after its time with a random suffix, like the bot names photos
+func uniqueMediaName(name string, now time.Time) string {
+	extension := filepath.Ext(name)
+	if extension == "" {
+		extension = ".jpg"
+	}
+
+	randBytes := make([]byte, 3)
+	rand.Read(randBytes)
+
+	return fmt.Sprintf("photo_%s_%06d_%s%s", now.Format("20060102_150405"), now.Nanosecond()/1000, hex.EncodeToString(randBytes), extension)
+}
@@ -0,0 +1,251 @@
+// Package msg2git embeds the msg2git capture engine in other Go programs.
+//
+// An Engine formats notes, TODOs, issues and media exactly like the Telegram bot does and
+// hands them to a Storage, by default a GitHub repository. A Messenger, if set, is told about
+// every capture, the way the bot replies in the chat:
+//
+//	storage, err := msg2git.NewGitHubStorage(msg2git.GitHubConfig{
+//		Token: os.Getenv("GITHUB_TOKEN"),
+//		Repo:  "https://github.com/me/notes",
+//	})
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	engine := msg2git.New(storage, msg2git.Options{Author: "Me <me@example.com>"})
+//	commit, err := engine.CommitNote(msg2git.Note{Content: "buy milk", File: "todo.md"})
+package msg2git
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/msg2git/msg2git/internal/entry"
+)
+
+// DefaultFile receives notes that don't name a file
+const DefaultFile = "inbox.md"
+
+const (
+	todoFile  = "todo.md"
+	issueFile = "issue.md"
+)
+
+var (
+	// ErrEmptyContent is returned for notes and issues without content
+	ErrEmptyContent = errors.New("content is empty")
+	// ErrInvalidPath is returned for files outside the repository
+	ErrInvalidPath = errors.New("invalid file path")
+)
+
+// Storage keeps captured entries. Paths are relative to the repository root.
+type Storage interface {
+	// PrependFile adds content to the top of a file, creating the file if needed
+	PrependFile(path, content, commitMessage, author string) (*Commit, error)
+	// CreateIssue opens an issue and returns it
+	CreateIssue(title, body string) (*Issue, error)
+	// UploadMedia stores a binary file under a unique name and returns its public URL
+	UploadMedia(name string, data []byte) (string, error)
+	// Repository returns the owner and name of the repository, used in issue.md links
+	Repository() (owner, name string, err error)
+}
+
+// Messenger receives a short confirmation of every capture, e.g. a chat or a log
+type Messenger interface {
+	Send(text string) error
+}
+
+// Commit is a stored change
+type Commit struct {
+	File string
+	SHA  string // Empty if the storage doesn't track commits
+	URL  string
+}
+
+// Issue is a created issue
+type Issue struct {
+	Number int
+	Title  string
+	URL    string
+}
+
+// Note is content to capture. Title defaults to the first line of Content.
+type Note struct {
+	Content string
+	File    string // DefaultFile if empty, ".md" is added to names without an extension
+	Title   string
+	Tags    string // Space separated, e.g. "#idea #work"
+}
+
+// Media is a binary file to upload, optionally embedded into a note
+type Media struct {
+	Name    string // Original file name, its extension is kept
+	Data    []byte
+	Caption string
+	File    string // Note file to embed the media into, only uploaded if empty
+}
+
+// MediaResult is an uploaded media file and the note embedding it, if any
+type MediaResult struct {
+	URL    string
+	Commit *Commit
+}
+
+// Options configure an Engine
+type Options struct {
+	Author    string           // Commit author as "Name <email>", the storage's default if empty
+	ChatID    int64            // Recorded in the entry metadata, 0 for entries from outside Telegram
+	Messenger Messenger        // Optional
+	Now       func() time.Time // Clock for timestamps, time.Now if nil
+}
+
+// Engine captures notes, issues and media into a Storage
+type Engine struct {
+	storage Storage
+	opts    Options
+}
+
+// New creates an engine writing to storage
+func New(storage Storage, opts Options) *Engine {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &Engine{storage: storage, opts: opts}
+}
+
+// CommitNote adds a note to the top of its file, resolving [[links]] to relative markdown links.
+// Notes for todo.md become a TODO item and must fit on one line, issues are created with CreateIssue.
+func (e *Engine) CommitNote(note Note) (*Commit, error) {
+	content := strings.TrimSpace(note.Content)
+	if content == "" {
+		return nil, ErrEmptyContent
+	}
+
+	file, err := cleanPath(note.File, DefaultFile)
+	if err != nil {
+		return nil, err
+	}
+	if file == issueFile {
+		return nil, fmt.Errorf("issues are created with CreateIssue, not committed to %s", issueFile)
+	}
+
+	title := note.Title
+	if title == "" {
+		title = entry.Title(content)
+	}
+
+	now := e.opts.Now()
+	var formatted string
+	if file == todoFile {
+		// There is no Telegram message, so a timestamp stands in as the unique TODO id
+		formatted = entry.Todo(content, int(now.Unix()), e.opts.ChatID, now)
+		if formatted == "" {
+			return nil, fmt.Errorf("TODOs cannot contain line breaks")
+		}
+	} else {
+		resolved, _ := entry.ResolveWikiLinks(content, file)
+		formatted = entry.Note(resolved, 0, e.opts.ChatID, title, note.Tags, now)
+	}
+
+	commit, err := e.storage.PrependFile(file, formatted, fmt.Sprintf("Add %s to %s", title, file), e.opts.Author)
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit %s: %w", file, err)
+	}
+
+	e.notify(fmt.Sprintf("✅ Saved to %s", file), commit.URL)
+	return commit, nil
+}
+
+// CreateIssue opens an issue and lists it in issue.md. The issue is returned even if listing it
+// fails, together with the error.
+func (e *Engine) CreateIssue(title, body string) (*Issue, error) {
+	body = strings.TrimSpace(body)
+	if body == "" && title == "" {
+		return nil, ErrEmptyContent
+	}
+	if title == "" {
+		title = entry.Title(body)
+	}
+
+	issue, err := e.storage.CreateIssue(title, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	e.notify(fmt.Sprintf("✅ Issue #%d created", issue.Number), issue.URL)
+
+	owner, repo, err := e.storage.Repository()
+	if err != nil {
+		return issue, fmt.Errorf("issue #%d created but not listed in %s: %w", issue.Number, issueFile, err)
+	}
+	line := entry.IssueLine(owner, repo, issue.Number, issue.Title)
+	if _, err := e.storage.PrependFile(issueFile, line, fmt.Sprintf("Add issue #%d to %s", issue.Number, issueFile), e.opts.Author); err != nil {
+		return issue, fmt.Errorf("issue #%d created but not listed in %s: %w", issue.Number, issueFile, err)
+	}
+
+	return issue, nil
+}
+
+// UploadMedia uploads a file and, when media.File is set, embeds it into a note of that file
+func (e *Engine) UploadMedia(media Media) (*MediaResult, error) {
+	if len(media.Data) == 0 {
+		return nil, ErrEmptyContent
+	}
+
+	url, err := e.storage.UploadMedia(media.Name, media.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload media: %w", err)
+	}
+	result := &MediaResult{URL: url}
+
+	if media.File == "" {
+		e.notify("✅ Media uploaded", url)
+		return result, nil
+	}
+
+	title := "Photo"
+	if caption := strings.TrimSpace(media.Caption); caption != "" {
+		title = entry.Title(caption)
+	}
+	commit, err := e.CommitNote(Note{
+		Content: entry.Photo(url, strings.TrimSpace(media.Caption)),
+		File:    media.File,
+		Title:   title,
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Commit = commit
+	return result, nil
+}
+
+// notify tells the messenger about a capture, a failing messenger never fails the capture
+func (e *Engine) notify(text, url string) {
+	if e.opts.Messenger == nil {
+		return
+	}
+	if url != "" {
+		text += ": " + url
+	}
+	_ = e.opts.Messenger.Send(text)
+}
+
+// cleanPath validates a repository path, returning fallback for an empty one
+func cleanPath(file, fallback string) (string, error) {
+	file = strings.TrimSpace(file)
+	if file == "" {
+		return fallback, nil
+	}
+	if strings.HasPrefix(file, "/") || strings.Contains(file, "\\") {
+		return "", ErrInvalidPath
+	}
+	cleaned := path.Clean(file)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") || strings.HasPrefix(cleaned, ".git/") || cleaned == ".git" {
+		return "", ErrInvalidPath
+	}
+	if path.Ext(cleaned) == "" {
+		cleaned += ".md"
+	}
+	return cleaned, nil
+}
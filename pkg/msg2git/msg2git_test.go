@@ -0,0 +1,183 @@
+package msg2git
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeStorage struct {
+	files     map[string]string
+	issues    []*Issue
+	uploads   []string
+	failFiles bool
+}
+
+func newFakeStorage() *fakeStorage {
+	return &fakeStorage{files: make(map[string]string)}
+}
+
+func (s *fakeStorage) PrependFile(path, content, commitMessage, author string) (*Commit, error) {
+	if s.failFiles {
+		return nil, errors.New("storage offline")
+	}
+	s.files[path] = content + s.files[path]
+	return &Commit{File: path, URL: "https://example.com/commit/" + path}, nil
+}
+
+func (s *fakeStorage) CreateIssue(title, body string) (*Issue, error) {
+	issue := &Issue{Number: len(s.issues) + 1, Title: title, URL: "https://example.com/issues"}
+	s.issues = append(s.issues, issue)
+	return issue, nil
+}
+
+func (s *fakeStorage) UploadMedia(name string, data []byte) (string, error) {
+	s.uploads = append(s.uploads, name)
+	return "https://cdn.example.com/" + name, nil
+}
+
+func (s *fakeStorage) Repository() (string, string, error) {
+	return "me", "notes", nil
+}
+
+type fakeMessenger struct {
+	sent []string
+}
+
+func (m *fakeMessenger) Send(text string) error {
+	m.sent = append(m.sent, text)
+	return nil
+}
+
+func newTestEngine(storage Storage, messenger Messenger) *Engine {
+	now := time.Date(2025, 3, 4, 5, 6, 0, 0, time.UTC)
+	return New(storage, Options{ChatID: 42, Messenger: messenger, Now: func() time.Time { return now }})
+}
+
+func TestCommitNote(t *testing.T) {
+	storage := newFakeStorage()
+	messenger := &fakeMessenger{}
+	engine := newTestEngine(storage, messenger)
+
+	commit, err := engine.CommitNote(Note{Content: "Shopping list\nmilk", File: "notes", Tags: "#home"})
+	if err != nil {
+		t.Fatalf("CommitNote() error = %v", err)
+	}
+	if commit.File != "notes.md" {
+		t.Errorf("CommitNote() file = %q, want notes.md", commit.File)
+	}
+
+	got := storage.files["notes.md"]
+	for _, want := range []string{"[0] [42] [2025-03-04 05:06]", "## Shopping list\n#home\n", "Shopping list  \nmilk"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("note missing %q in:\n%s", want, got)
+		}
+	}
+	if len(messenger.sent) != 1 || !strings.Contains(messenger.sent[0], "notes.md") {
+		t.Errorf("messenger got %v", messenger.sent)
+	}
+}
+
+func TestCommitNoteDefaultsAndTodos(t *testing.T) {
+	storage := newFakeStorage()
+	engine := newTestEngine(storage, nil)
+
+	if _, err := engine.CommitNote(Note{Content: "idea"}); err != nil {
+		t.Fatalf("CommitNote() error = %v", err)
+	}
+	if _, ok := storage.files[DefaultFile]; !ok {
+		t.Errorf("CommitNote() without file should write %s", DefaultFile)
+	}
+
+	if _, err := engine.CommitNote(Note{Content: "buy milk", File: "todo.md"}); err != nil {
+		t.Fatalf("CommitNote(todo) error = %v", err)
+	}
+	if got := storage.files["todo.md"]; !strings.HasPrefix(got, "- [ ] <!--[") || !strings.Contains(got, "buy milk (2025-03-04)") {
+		t.Errorf("todo line = %q", got)
+	}
+
+	if _, err := engine.CommitNote(Note{Content: "two\nlines", File: "todo.md"}); err == nil {
+		t.Error("CommitNote() should reject multi-line TODOs")
+	}
+}
+
+func TestCommitNoteRejects(t *testing.T) {
+	engine := newTestEngine(newFakeStorage(), nil)
+
+	tests := []struct {
+		name string
+		note Note
+		want error
+	}{
+		{"empty", Note{Content: "  "}, ErrEmptyContent},
+		{"parent directory", Note{Content: "x", File: "../secrets.md"}, ErrInvalidPath},
+		{"absolute", Note{Content: "x", File: "/etc/passwd"}, ErrInvalidPath},
+		{"git directory", Note{Content: "x", File: ".git/config"}, ErrInvalidPath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := engine.CommitNote(tt.note); !errors.Is(err, tt.want) {
+				t.Errorf("CommitNote() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := engine.CommitNote(Note{Content: "x", File: "issue.md"}); err == nil {
+		t.Error("CommitNote() should refuse issue.md")
+	}
+}
+
+func TestCreateIssue(t *testing.T) {
+	storage := newFakeStorage()
+	engine := newTestEngine(storage, nil)
+
+	issue, err := engine.CreateIssue("", "Crash on startup\nstack trace")
+	if err != nil {
+		t.Fatalf("CreateIssue() error = %v", err)
+	}
+	if issue.Number != 1 || issue.Title != "Crash on startup" {
+		t.Errorf("CreateIssue() = %+v", issue)
+	}
+	if got := storage.files["issue.md"]; got != "- 🟢 me/notes#1 [Crash on startup]\n" {
+		t.Errorf("issue.md = %q", got)
+	}
+
+	storage.failFiles = true
+	issue, err = engine.CreateIssue("Second", "body")
+	if err == nil || issue == nil {
+		t.Errorf("CreateIssue() should return the issue and the listing error, got %+v, %v", issue, err)
+	}
+}
+
+func TestUploadMedia(t *testing.T) {
+	storage := newFakeStorage()
+	engine := newTestEngine(storage, nil)
+
+	result, err := engine.UploadMedia(Media{Name: "cat.png", Data: []byte{1}, Caption: "My cat", File: "photos.md"})
+	if err != nil {
+		t.Fatalf("UploadMedia() error = %v", err)
+	}
+	if note := storage.files["photos.md"]; result.Commit == nil || !strings.Contains(note, "![Photo]("+result.URL+")") || !strings.Contains(note, "## My cat") {
+		t.Errorf("UploadMedia() note = %q", storage.files["photos.md"])
+	}
+
+	result, err = engine.UploadMedia(Media{Name: "cat.png", Data: []byte{1}})
+	if err != nil || result.Commit != nil {
+		t.Errorf("UploadMedia() without file = %+v, %v", result, err)
+	}
+
+	if _, err := engine.UploadMedia(Media{Name: "empty.png"}); !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("UploadMedia() error = %v, want ErrEmptyContent", err)
+	}
+}
+
+func TestUniqueMediaName(t *testing.T) {
+	now := time.Date(2025, 3, 4, 5, 6, 7, 8000, time.UTC)
+	if got := uniqueMediaName("cat.png", now); !strings.HasPrefix(got, "photo_20250304_050607_000008_") || !strings.HasSuffix(got, ".png") {
+		t.Errorf("uniqueMediaName() = %q", got)
+	}
+	if got := uniqueMediaName("blob", now); !strings.HasSuffix(got, ".jpg") {
+		t.Errorf("uniqueMediaName() without extension = %q", got)
+	}
+}